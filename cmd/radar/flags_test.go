@@ -355,6 +355,7 @@ func TestEnsureValidForwardMode(t *testing.T) {
 type stubReplayPublisher struct {
 	active  bool
 	stopped bool
+	resets  int
 }
 
 func (s *stubReplayPublisher) IsVRLogActive() bool {
@@ -365,6 +366,10 @@ func (s *stubReplayPublisher) StopVRLogReplay() {
 	s.stopped = true
 }
 
+func (s *stubReplayPublisher) ResetFrameHistory() {
+	s.resets++
+}
+
 type stubReplayServer struct {
 	vrlogModes  []bool
 	replayModes []bool
@@ -397,6 +402,9 @@ func TestHandlePCAPStartedVisualiserAndPublishProgress(t *testing.T) {
 	publisher := &stubReplayPublisher{active: true}
 	server := &stubReplayServer{}
 	handlePCAPStartedVisualiser(publisher, server, logf)
+	if publisher.resets != 1 {
+		t.Fatalf("frame history reset %d times, want 1", publisher.resets)
+	}
 	if !publisher.stopped {
 		t.Fatal("expected active VRLOG replay to be stopped")
 	}
@@ -26,6 +26,7 @@ type ringElevationsSetter interface {
 type vrlogReplayController interface {
 	IsVRLogActive() bool
 	StopVRLogReplay()
+	ResetFrameHistory()
 }
 
 type replayModeController interface {
@@ -164,6 +165,9 @@ func handlePCAPStartedVisualiser(publisher vrlogReplayController, server replayM
 		publisher.StopVRLogReplay()
 		logf("[Visualiser] Stopped VRLOG replay before PCAP start")
 	}
+	if !isNilHelperTarget(publisher) {
+		publisher.ResetFrameHistory()
+	}
 	if !isNilHelperTarget(server) {
 		server.SetVRLogMode(false)
 		server.SetReplayMode(false)
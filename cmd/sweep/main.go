@@ -2,14 +2,15 @@ package main
 
 import (
 	"encoding/csv"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/banshee-data/velocity.report/internal/lidar/adapters/httpretry"
 	"github.com/banshee-data/velocity.report/internal/lidar/server"
 	"github.com/banshee-data/velocity.report/internal/lidar/sweep"
 	"github.com/banshee-data/velocity.report/internal/security"
@@ -21,6 +22,15 @@ func main() {
 	sensorID := flag.String("sensor", "hesai-pandar40p", "Sensor ID")
 	output := flag.String("output", "", "Output CSV filename (defaults to sweep-<timestamp>.csv)")
 
+	// HTTP retry behaviour: transient failures (timeouts, refused
+	// connections, 5xx) are retried; an outage longer than the deadline
+	// ends the sweep early, keeping the rows written so far.
+	retryDefaults := httpretry.DefaultConfig()
+	retryAttempts := flag.Int("retry-attempts", retryDefaults.MaxAttempts, "Tries per HTTP request on transient errors (1 disables retries)")
+	retryBackoff := flag.Duration("retry-backoff", retryDefaults.InitialBackoff, "Initial backoff between retries (doubles up to -retry-max-backoff)")
+	retryMaxBackoff := flag.Duration("retry-max-backoff", retryDefaults.MaxBackoff, "Maximum backoff between retries")
+	retryDeadline := flag.Duration("retry-deadline", retryDefaults.Deadline, "Give up the sweep after the server has been failing this long (0 = never)")
+
 	// PCAP support
 	pcapFile := flag.String("pcap", "", "PCAP file to replay (enables PCAP mode)")
 	pcapSettle := flag.Duration("pcap-settle", 20*time.Second, "Time to wait after PCAP replay before sampling")
@@ -73,8 +83,13 @@ func main() {
 	flag.Parse()
 
 	// Create monitor client
-	httpClient := &http.Client{Timeout: 30 * time.Second}
-	client := server.NewClient(httpClient, *monitorURL, *sensorID)
+	retryCfg := retryDefaults
+	retryCfg.MaxAttempts = *retryAttempts
+	retryCfg.InitialBackoff = *retryBackoff
+	retryCfg.MaxBackoff = *retryMaxBackoff
+	retryCfg.Deadline = *retryDeadline
+	retryCfg.Logf = log.Printf
+	client := server.NewClient(httpretry.NewClient(retryCfg), *monitorURL, *sensorID)
 
 	// Tracking sweep mode: dedicated flow that replays PCAP per combination
 	if *sweepMode == "tracking" {
@@ -193,7 +208,9 @@ func main() {
 	// Run sweep
 	comboNum := 0
 	seedToggle := false
+	stopped := false
 
+combos:
 	for _, noise := range noiseCombos {
 		for _, closeness := range closenessCombos {
 			for _, neighbour := range neighbourCombos {
@@ -224,6 +241,10 @@ func main() {
 				}
 				if err := client.SetParams(params); err != nil {
 					log.Printf("ERROR: Failed to set params: %v", err)
+					if serverLost(err) {
+						stopped = true
+						break combos
+					}
 					continue
 				}
 
@@ -240,12 +261,12 @@ func main() {
 				// PCAP mode: trigger replay and wait for settle
 				if *pcapFile != "" {
 					if err := client.StartPCAPReplay(*pcapFile, 60); err != nil {
-						log.Printf("WARNING: PCAP replay failed: %v (will retry)", err)
-						time.Sleep(5 * time.Second)
-						if err := client.StartPCAPReplay(*pcapFile, 60); err != nil {
-							log.Printf("ERROR: PCAP replay failed again: %v", err)
-							continue
+						log.Printf("ERROR: PCAP replay failed: %v", err)
+						if serverLost(err) {
+							stopped = true
+							break combos
 						}
+						continue
 					}
 					time.Sleep(*pcapSettle)
 				} else {
@@ -269,7 +290,11 @@ func main() {
 		}
 	}
 
-	log.Printf("\nSweep complete!")
+	if stopped {
+		log.Printf("\nSweep stopped at combination %d/%d", comboNum, totalCombos)
+	} else {
+		log.Printf("\nSweep complete!")
+	}
 	log.Printf("Summary: %s", filename)
 	log.Printf("Raw data: %s", rawFilename)
 }
@@ -347,6 +372,10 @@ func runTrackingSweep(
 	w.Write(header)
 
 	comboNum := 0
+	completed := 0
+	stopped := false
+
+combos:
 	for _, gating := range gatingCombos {
 		for _, pnoisePos := range pnoisePosCombos {
 			for _, mnoise := range mnoiseCombos {
@@ -362,12 +391,20 @@ func runTrackingSweep(
 				}
 				if err := client.SetTrackerConfig(params); err != nil {
 					log.Printf("ERROR: Failed to set tracker config: %v", err)
+					if serverLost(err) {
+						stopped = true
+						break combos
+					}
 					continue
 				}
 
 				// Replay PCAP (golden file)
 				if err := client.StartPCAPReplay(pcapFile, 60); err != nil {
 					log.Printf("ERROR: PCAP replay failed: %v", err)
+					if serverLost(err) {
+						stopped = true
+						break combos
+					}
 					continue
 				}
 
@@ -378,6 +415,10 @@ func runTrackingSweep(
 				metrics, err := client.FetchTrackingMetrics()
 				if err != nil {
 					log.Printf("ERROR: Failed to fetch tracking metrics: %v", err)
+					if serverLost(err) {
+						stopped = true
+						break combos
+					}
 					continue
 				}
 
@@ -406,14 +447,30 @@ func runTrackingSweep(
 				}
 				w.Write(row)
 				w.Flush()
+				completed++
 			}
 		}
 	}
 
-	log.Printf("\nTracking sweep complete!")
+	if stopped {
+		log.Printf("\nTracking sweep stopped at combination %d/%d (%d rows written)", comboNum, totalCombos, completed)
+	} else {
+		log.Printf("\nTracking sweep complete!")
+	}
 	log.Printf("Results: %s", filename)
 }
 
+// serverLost reports whether err means the monitor has been unreachable
+// for longer than the retry deadline, in which case the sweep stops and
+// keeps the rows already written.
+func serverLost(err error) bool {
+	if errors.Is(err, httpretry.ErrCircuitOpen) {
+		log.Printf("ERROR: Giving up: monitor unreachable past -retry-deadline")
+		return true
+	}
+	return false
+}
+
 // toFloat64 safely converts an interface{} (typically from JSON) to float64.
 func toFloat64(v interface{}) float64 {
 	switch val := v.(type) {
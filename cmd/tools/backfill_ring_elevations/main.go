@@ -1,3 +1,7 @@
+// Command backfill_ring_elevations is a one-time migration that fills the
+// ring elevation table of background snapshots written before snapshots
+// always stored it, using the embedded Pandar40P parser configuration.
+// Snapshots persisted since then carry the table they were built with.
 package main
 
 import (
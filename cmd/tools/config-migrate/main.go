@@ -142,8 +142,9 @@ func migrateLegacyConfig(legacy legacyTuningConfig) *cfgpkg.TuningConfig {
 	return &cfgpkg.TuningConfig{
 		Version: cfgpkg.CurrentConfigVersion,
 		L1: cfgpkg.L1Config{
-			Sensor:     "hesai-pandar40p",
-			DataSource: "live",
+			Sensor:        "hesai-pandar40p",
+			DataSource:    "live",
+			RingRangeClip: map[int]cfgpkg.RangeClipWindow{},
 		},
 		L3: cfgpkg.L3Config{
 			Engine: "ema_baseline_v1",
@@ -175,6 +176,7 @@ func migrateLegacyConfig(legacy legacyTuningConfig) *cfgpkg.TuningConfig {
 					SettlingMaxSpreadDelta:            0.001,
 					SettlingMinRegionStability:        0.95,
 					SettlingMinConfidence:             10.0,
+					BackgroundModel:                   "mean",
 				},
 			},
 		},
@@ -191,6 +193,13 @@ func migrateLegacyConfig(legacy legacyTuningConfig) *cfgpkg.TuningConfig {
 					MaxClusterDiameter:         legacy.MaxClusterDiameter,
 					MinClusterDiameter:         legacy.MinClusterDiameter,
 					MaxClusterAspectRatio:      legacy.MaxClusterAspectRatio,
+					MinPtsFloor:                2,
+					ClusterMergeMaxLength:      12.0,
+					ClusterMergeMaxWidth:       3.0,
+					BloomMaxExtent:             1.0,
+					BloomMinPoints:             5,
+					BloomStaticFrames:          10,
+					SlowMoverMinFrames:         6,
 				},
 			},
 		},
@@ -207,6 +216,7 @@ func migrateLegacyConfig(legacy legacyTuningConfig) *cfgpkg.TuningConfig {
 					MaxMisses:                        legacy.MaxMisses,
 					MaxMissesConfirmed:               legacy.MaxMissesConfirmed,
 					MaxTracks:                        legacy.MaxTracks,
+					EvictionPolicy:                   "none",
 					MaxReasonableSpeedMps:            legacy.MaxReasonableSpeedMps,
 					MaxPositionJumpMetres:            legacy.MaxPositionJumpMeters,
 					MaxPredictDt:                     legacy.MaxPredictDt,
@@ -220,6 +230,10 @@ func migrateLegacyConfig(legacy legacyTuningConfig) *cfgpkg.TuningConfig {
 					SplitSizeRatio:                   legacy.SplitSizeRatio,
 					DeletedTrackGracePeriod:          legacy.DeletedTrackGracePeriod,
 					MinObservationsForClassification: legacy.MinObservationsForClassification,
+					SizeInstabilityWeight:            0.5,
+					GatingMode:                       "isotropic",
+					GatingChiSquare:                  9.21,
+					GatingAlongTrackFraction:         0.5,
 				},
 			},
 		},
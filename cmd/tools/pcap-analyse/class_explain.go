@@ -0,0 +1,68 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+)
+
+// classExplanationsPath is <output>/<pcap>_class_explanations.jsonl.
+func classExplanationsPath(config Config) string {
+	return filepath.Join(config.OutputDir, network.PCAPBaseName(config.PCAPFile)+"_class_explanations.jsonl")
+}
+
+// classExplanations is the open -explain-classes file.
+type classExplanations struct {
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+	rows int
+}
+
+// startClassExplanations opens the explanation file and has the classifier
+// write one JSON line per decision to it.
+func (fb *analysisFrameBuilder) startClassExplanations() error {
+	f, err := os.Create(classExplanationsPath(fb.config))
+	if err != nil {
+		return fmt.Errorf("create class explanations file: %w", err)
+	}
+	buf := bufio.NewWriter(f)
+	x := &classExplanations{file: f, buf: buf, enc: json.NewEncoder(buf)}
+	fb.explain = x
+	fb.classifier.ExplanationSink = func(e l6objects.ClassificationExplanation) {
+		if err := x.enc.Encode(e); err != nil {
+			log.Printf("[WARN] class explanations: %v", err)
+			return
+		}
+		x.rows++
+	}
+	return nil
+}
+
+// closeClassExplanations flushes and closes the explanation file, if open.
+// Tracks are classified until the results are collected, so call it after
+// collectTrackResults.
+func (fb *analysisFrameBuilder) closeClassExplanations() {
+	x := fb.explain
+	if x == nil {
+		return
+	}
+	fb.explain = nil
+	fb.classifier.ExplanationSink = nil
+	if err := x.buf.Flush(); err != nil {
+		log.Printf("[WARN] class explanations: %v", err)
+	}
+	if err := x.file.Close(); err != nil {
+		log.Printf("[WARN] class explanations: %v", err)
+	}
+	fb.result.ClassExplanations = x.rows
+}
@@ -0,0 +1,66 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+)
+
+func TestClassExplanations_WrittenDuringCollection(t *testing.T) {
+	tracks := map[string]*l5tracks.TrackedObject{
+		"car": {
+			TrackID: "car",
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:           l5tracks.TrackConfirmed,
+				ObservationCount:     30,
+				BoundingBoxLengthAvg: 4.5,
+				BoundingBoxWidthAvg:  1.8,
+				BoundingBoxHeightAvg: 1.5,
+				AvgSpeedMps:          12,
+				MaxSpeedMps:          15,
+				StartUnixNanos:       1_000_000_000,
+				EndUnixNanos:         4_000_000_000,
+			},
+		},
+	}
+	fb := makeFrameBuilder(tracks)
+	fb.config = Config{PCAPFile: "capture.pcap", OutputDir: t.TempDir(), ExplainClasses: true}
+	fb.result = newResult()
+
+	if err := fb.startClassExplanations(); err != nil {
+		t.Fatalf("startClassExplanations: %v", err)
+	}
+	collectTrackResults(fb, fb.result)
+	fb.closeClassExplanations()
+
+	f, err := os.Open(classExplanationsPath(fb.config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines []l6objects.ClassificationExplanation
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e l6objects.ClassificationExplanation
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, e)
+	}
+	if len(lines) != 1 || fb.result.ClassExplanations != 1 {
+		t.Fatalf("got %d lines, %d counted; want 1", len(lines), fb.result.ClassExplanations)
+	}
+	if e := lines[0]; e.TrackID != "car" || e.Class != l6objects.ClassCar || e.Deciding == nil || e.Deciding.Feature != "avg_length" {
+		t.Errorf("explanation = %+v", e)
+	}
+	if fb.classifier.ExplanationSink != nil {
+		t.Error("sink still set after close")
+	}
+}
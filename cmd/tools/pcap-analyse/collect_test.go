@@ -4,6 +4,11 @@
 package main
 
 import (
+	"encoding/csv"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -151,3 +156,315 @@ func TestCollectTrackResults_ClassFallback(t *testing.T) {
 		t.Errorf("TracksByClass[other]: want 1, got %d", result.TracksByClass["other"])
 	}
 }
+
+func TestCollectTrackResults_UnknownVersusNoise(t *testing.T) {
+	// With gates configured, a sub-gate track is noise while a plausible
+	// one the classifier could not label is unknown; the combined view
+	// still counts both as "other".
+	tracks := map[string]*l5tracks.TrackedObject{
+		"blip": {
+			TrackID: "blip",
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:           l5tracks.TrackTentative,
+				ObservationCount:     2,
+				StartUnixNanos:       1_000_000_000,
+				EndUnixNanos:         1_100_000_000,
+				BoundingBoxHeightAvg: 0.1,
+			},
+		},
+		"ambiguous": {
+			TrackID: "ambiguous",
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:           l5tracks.TrackConfirmed,
+				ObjectClass:          "",
+				ObservationCount:     4, // below the classifier threshold
+				StartUnixNanos:       2_000_000_000,
+				EndUnixNanos:         5_000_000_000,
+				BoundingBoxHeightAvg: 1.1,
+				BoundingBoxLengthAvg: 1.8,
+				MaxSpeedMps:          6,
+			},
+		},
+		"car": {
+			TrackID: "car",
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:     l5tracks.TrackConfirmed,
+				ObjectClass:    "car",
+				StartUnixNanos: 3_000_000_000,
+				EndUnixNanos:   6_000_000_000,
+			},
+		},
+	}
+
+	fb := makeFrameBuilder(tracks)
+	gates := l6objects.DefaultFallbackClassConfig()
+	fb.config.FallbackClasses = &gates
+	result := newResult()
+	collectTrackResults(fb, result)
+
+	classes := make(map[string]string)
+	for _, tr := range result.Tracks {
+		classes[tr.TrackID] = tr.Class
+	}
+	if classes["blip"] != "noise" || classes["ambiguous"] != "unknown" || classes["car"] != "car" {
+		t.Errorf("classes = %v, want blip noise, ambiguous unknown, car car", classes)
+	}
+	if result.TracksByClass["noise"] != 1 || result.TracksByClass["unknown"] != 1 || result.TracksByClass["other"] != 0 {
+		t.Errorf("TracksByClass = %v", result.TracksByClass)
+	}
+	if result.ClassificationDist["noise"].Count != 1 || result.ClassificationDist["unknown"].Count != 1 {
+		t.Errorf("ClassificationDist = %+v", result.ClassificationDist)
+	}
+	if result.CombinedByClass["other"] != 2 || result.CombinedByClass["car"] != 1 {
+		t.Errorf("CombinedByClass = %v, want other 2 and car 1", result.CombinedByClass)
+	}
+	if result.CombinedClassDist["other"].Count != 2 {
+		t.Errorf("CombinedClassDist = %+v", result.CombinedClassDist)
+	}
+}
+
+func TestCollectTrackResults_DetectionReliability(t *testing.T) {
+	tracks := map[string]*l5tracks.TrackedObject{
+		"t1": {
+			TrackID: "t1",
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:       l5tracks.TrackConfirmed,
+				ObjectClass:      "vehicle",
+				ObservationCount: 6,
+			},
+			CoastedFrames: 2,
+		},
+	}
+
+	fb := makeFrameBuilder(tracks)
+	result := newResult()
+	collectTrackResults(fb, result)
+
+	if len(result.Tracks) != 1 {
+		t.Fatalf("expected 1 track export, got %d", len(result.Tracks))
+	}
+	if got := result.Tracks[0].DetectionReliability; got != 0.75 {
+		t.Errorf("DetectionReliability: want 0.75, got %v", got)
+	}
+}
+
+func TestExportFeaturesCSV(t *testing.T) {
+	track := &l5tracks.TrackedObject{
+		TrackID: "t1",
+		TrackMeasurement: l5tracks.TrackMeasurement{
+			TrackState:           l5tracks.TrackConfirmed,
+			ObjectClass:          "car",
+			ObjectConfidence:     0.8,
+			ObservationCount:     10,
+			BoundingBoxLengthAvg: 4.5,
+		},
+	}
+	fb := makeFrameBuilder(map[string]*l5tracks.TrackedObject{"t1": track})
+	result := newResult()
+	collectTrackResults(fb, result)
+
+	path := filepath.Join(t.TempDir(), "features.csv")
+	if err := exportFeaturesCSV(path, result.Tracks); err != nil {
+		t.Fatalf("exportFeaturesCSV: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected header and 1 row, got %d records", len(records))
+	}
+
+	header, row := records[0], records[1]
+	columns := l6objects.FeatureVectorColumns()
+	if len(header) != 4+len(columns) || header[4] != columns[0] {
+		t.Fatalf("unexpected header: %v", header)
+	}
+	if row[0] != "t1" || row[1] != "car" || row[2] != "0.800" || row[3] != "1" {
+		t.Errorf("unexpected label columns: %v", row[:4])
+	}
+	if row[4] != "4.5" {
+		t.Errorf("length_avg_m: want 4.5, got %s", row[4])
+	}
+}
+
+func TestCollectTrackResults_SpeedLimits(t *testing.T) {
+	speedTrack := func(id, class string, kph float32) *l5tracks.TrackedObject {
+		track := &l5tracks.TrackedObject{
+			TrackID: id,
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:  l5tracks.TrackConfirmed,
+				ObjectClass: class,
+			},
+			History: []l5tracks.TrackPoint{{X: 0, Y: 0}, {X: 0, Y: 20}},
+		}
+		track.SetSpeedHistory([]float32{kph / 3.6, kph / 3.6, kph / 3.6, kph / 3.6, kph / 3.6})
+		return track
+	}
+	tracks := map[string]*l5tracks.TrackedObject{
+		"fast": speedTrack("fast", "car", 42),
+		"slow": speedTrack("slow", "car", 25),
+		"ped":  speedTrack("ped", "pedestrian", 6),
+	}
+	tagger, err := l6objects.NewSpeedLimitTagger(l6objects.SpeedLimitConfig{DefaultLimit: 30})
+	if err != nil {
+		t.Fatalf("NewSpeedLimitTagger: %v", err)
+	}
+	fb := makeFrameBuilder(tracks)
+	fb.config.SpeedLimits = tagger
+	result := newResult()
+	collectTrackResults(fb, result)
+
+	if result.SpeedLimits == nil || result.SpeedLimits.Tagged != 2 || result.SpeedLimits.OverLimit != 1 {
+		t.Fatalf("speed-limit summary = %+v, want 2 tagged, 1 over", result.SpeedLimits)
+	}
+	if got := result.SpeedLimits.ByDirection["northbound"]; got.OverLimit != 1 {
+		t.Errorf("northbound = %+v, want 1 over", got)
+	}
+
+	byID := make(map[string]*TrackExport)
+	for _, te := range result.Tracks {
+		byID[te.TrackID] = te
+	}
+	if byID["ped"].SpeedLimit != nil {
+		t.Error("pedestrian should not carry a speed-limit tag")
+	}
+	if got := speedLimitColumns(byID["fast"].SpeedLimit); got[1] != "30.0" || got[2] != "42.0" || got[3] != "12.0" || got[4] != "true" || got[5] != "northbound" {
+		t.Errorf("fast track columns = %v", got)
+	}
+	if got := speedLimitColumns(byID["ped"].SpeedLimit); len(got) != 6 || got[4] != "" {
+		t.Errorf("untagged columns = %v, want 6 empty", got)
+	}
+}
+
+func TestCollectTrackResults_ODMatrix(t *testing.T) {
+	crossing := func(id string, fromX, toX float32) *l5tracks.TrackedObject {
+		return &l5tracks.TrackedObject{
+			TrackID: id,
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:     l5tracks.TrackConfirmed,
+				ObjectClass:    "car",
+				StartUnixNanos: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC).UnixNano(),
+			},
+			History: []l5tracks.TrackPoint{{X: fromX}, {X: toX}},
+		}
+	}
+	tracks := map[string]*l5tracks.TrackedObject{
+		"through": crossing("through", -20, 20),
+		"parked":  crossing("parked", -20, 0),
+	}
+	matrix, err := l6objects.NewODMatrix(l6objects.ODConfig{Zones: []l6objects.ODZone{
+		{ID: "west", MinX: -25, MinY: -5, MaxX: -15, MaxY: 5},
+		{ID: "east", MinX: 15, MinY: -5, MaxX: 25, MaxY: 5},
+	}})
+	if err != nil {
+		t.Fatalf("NewODMatrix: %v", err)
+	}
+	fb := makeFrameBuilder(tracks)
+	fb.config.ODMatrix = matrix
+	result := newResult()
+	collectTrackResults(fb, result)
+
+	if result.ODTrips != 2 || matrix.Total() != 2 {
+		t.Fatalf("OD trips = %d (matrix %d), want 2", result.ODTrips, matrix.Total())
+	}
+	for _, te := range result.Tracks {
+		want := map[string]string{"through": "east", "parked": l6objects.ODMidScene}[te.TrackID]
+		if te.Origin != "west" || te.Destination != want {
+			t.Errorf("%s: %s → %s, want west → %s", te.TrackID, te.Origin, te.Destination, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "od.csv")
+	if err := exportODMatrixCSV(path, matrix); err != nil {
+		t.Fatalf("exportODMatrixCSV: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "2026-10-18T08:00:00Z,west,0,1,1,2") {
+		t.Errorf("OD CSV missing the west row:\n%s", data)
+	}
+}
+
+func TestCollectTrackResults_MinDuration(t *testing.T) {
+	track := func(id string, secs float64, speed float32) *l5tracks.TrackedObject {
+		return &l5tracks.TrackedObject{
+			TrackID: id,
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:     l5tracks.TrackConfirmed,
+				ObjectClass:    "car",
+				StartUnixNanos: 1_000_000_000,
+				EndUnixNanos:   1_000_000_000 + int64(secs*1e9),
+				AvgSpeedMps:    speed,
+			},
+		}
+	}
+	cfg := &l6objects.MinDurationConfig{MinDurationSecs: map[string]float64{"car": 1}}
+
+	for _, include := range []bool{false, true} {
+		fb := makeFrameBuilder(map[string]*l5tracks.TrackedObject{
+			"flicker": track("flicker", 0.3, 30),
+			"real":    track("real", 5, 10),
+		})
+		fb.config.MinDuration = cfg
+		fb.config.IncludeFlicker = include
+		result := newResult()
+		collectTrackResults(fb, result)
+
+		if result.TotalTracks != 1 || result.ConfirmedTracks != 1 || result.TracksByClass["car"] != 1 {
+			t.Errorf("include=%v: counts = %d total, %d confirmed, %v", include, result.TotalTracks, result.ConfirmedTracks, result.TracksByClass)
+		}
+		if result.ClassificationDist["car"].Count != 1 || result.SpeedStats.MaxSpeed != 10 {
+			t.Errorf("include=%v: distributions include the flicker track: %+v %+v", include, result.ClassificationDist["car"], result.SpeedStats)
+		}
+		if result.FlickerTracks["car"] != 1 {
+			t.Errorf("include=%v: flicker tracks = %v, want car:1", include, result.FlickerTracks)
+		}
+		wantExported := 1
+		if include {
+			wantExported = 2
+		}
+		if len(result.Tracks) != wantExported {
+			t.Fatalf("include=%v: exported %d tracks, want %d", include, len(result.Tracks), wantExported)
+		}
+		for _, te := range result.Tracks {
+			if te.Flicker != (te.TrackID == "flicker") {
+				t.Errorf("include=%v: track %s flicker=%v", include, te.TrackID, te.Flicker)
+			}
+		}
+	}
+}
+
+func TestCollectTrackResults_RoadAxis(t *testing.T) {
+	// A track moving 3 m east and 4 m north in one second, against a road
+	// running north: 4 m/s along, 3 m/s to the right (negative cross).
+	track := &l5tracks.TrackedObject{
+		TrackID: "diag",
+		TrackMeasurement: l5tracks.TrackMeasurement{
+			TrackState:  l5tracks.TrackConfirmed,
+			ObjectClass: "car",
+		},
+		History: []l5tracks.TrackPoint{
+			{X: 0, Y: 0, Timestamp: 1_000_000_000},
+			{X: 1, Y: 2, Timestamp: 1_400_000_000},
+			{X: 3, Y: 4, Timestamp: 2_000_000_000},
+		},
+	}
+	heading := 90.0
+	fb := makeFrameBuilder(map[string]*l5tracks.TrackedObject{"diag": track})
+	fb.config.RoadAxis = &l5tracks.RoadAxis{HeadingDeg: &heading}
+	result := newResult()
+	collectTrackResults(fb, result)
+
+	rv := result.Tracks[0].RoadVelocity
+	if rv == nil {
+		t.Fatal("expected road velocity with a road axis")
+	}
+	if math.Abs(float64(rv.AlongMps-4)) > 1e-5 || math.Abs(float64(rv.CrossMps+3)) > 1e-5 || math.Abs(float64(rv.SpeedMps-5)) > 1e-5 {
+		t.Errorf("road velocity = %+v, want along 4, cross -3, speed 5", *rv)
+	}
+}
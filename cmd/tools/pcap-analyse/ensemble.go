@@ -0,0 +1,220 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+)
+
+// Seed modes for -ensemble-seed, matching the sweep tool's -seed flag.
+const (
+	ensembleSeedTrue   = "true"
+	ensembleSeedFalse  = "false"
+	ensembleSeedToggle = "toggle"
+)
+
+// EnsembleRun holds the key metrics of one ensemble run.
+type EnsembleRun struct {
+	Run             int            `json:"run"`
+	NoiseSeed       int64          `json:"noise_seed"`
+	SeedFromFirst   bool           `json:"seed_from_first"`
+	TotalTracks     int            `json:"total_tracks"`
+	ConfirmedTracks int            `json:"confirmed_tracks"`
+	TracksByClass   map[string]int `json:"tracks_by_class"`
+	MeanSpeedMps    float64        `json:"mean_speed_mps"`
+}
+
+// MetricSpread summarises one metric across ensemble runs. StdDev is the
+// sample standard deviation (zero for a single run).
+type MetricSpread struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// EnsembleReport is the stability report for -ensemble: the spread of each
+// metric plus the raw per-run values.
+type EnsembleReport struct {
+	PCAPFile        string                  `json:"pcap_file"`
+	Runs            int                     `json:"runs"`
+	SeedMode        string                  `json:"seed_mode"`
+	TotalTracks     MetricSpread            `json:"total_tracks"`
+	ConfirmedTracks MetricSpread            `json:"confirmed_tracks"`
+	TracksByClass   map[string]MetricSpread `json:"tracks_by_class"`
+	MeanSpeedMps    MetricSpread            `json:"mean_speed_mps"`
+	PerRun          []EnsembleRun           `json:"per_run"`
+}
+
+// validEnsembleSeed reports whether mode is an -ensemble-seed value.
+func validEnsembleSeed(mode string) bool {
+	switch mode {
+	case ensembleSeedTrue, ensembleSeedFalse, ensembleSeedToggle:
+		return true
+	}
+	return false
+}
+
+// ensembleRunConfigs returns the configuration of each ensemble run. Runs
+// differ only in their seeds: the noise seed advances by one per run and
+// seed-from-first follows config.EnsembleSeed, alternating from true in
+// toggle mode. Exports and database persistence are disabled so earlier
+// runs cannot influence later ones.
+func ensembleRunConfigs(config Config) []Config {
+	runs := make([]Config, config.EnsembleRuns)
+	for i := range runs {
+		c := config
+		c.ExportCSV = false
+		c.ExportJSON = false
+		c.ExportTraining = false
+		c.ExportFeatures = false
+		c.ExportMOT = false
+		c.ExportFrames = nil
+		c.DebugRingMin, c.DebugRingMax, c.DebugAzMin, c.DebugAzMax = 0, 0, 0, 0
+		c.DBPath = ""
+		c.Noise.Seed = config.Noise.Seed + int64(i)
+		switch config.EnsembleSeed {
+		case ensembleSeedFalse:
+			c.SeedFromFirst = false
+		case ensembleSeedToggle:
+			c.SeedFromFirst = i%2 == 0
+		default:
+			c.SeedFromFirst = true
+		}
+		runs[i] = c
+	}
+	return runs
+}
+
+// runEnsemble analyses the PCAP once per ensemble run and aggregates the
+// results.
+func runEnsemble(config Config) (*EnsembleReport, error) {
+	var runs []EnsembleRun
+	for i, c := range ensembleRunConfigs(config) {
+		log.Printf("Ensemble run %d/%d: noise_seed=%d seed_from_first=%t", i+1, config.EnsembleRuns, c.Noise.Seed, c.SeedFromFirst)
+		result, err := analyzePCAP(c)
+		if err != nil {
+			return nil, fmt.Errorf("ensemble run %d: %w", i+1, err)
+		}
+		runs = append(runs, ensembleRunFromResult(i+1, c, result))
+	}
+	report := summariseEnsemble(config.PCAPFile, config.EnsembleSeed, runs)
+	return &report, nil
+}
+
+// ensembleRunFromResult extracts the ensemble metrics from one analysis.
+func ensembleRunFromResult(run int, config Config, result *AnalysisResult) EnsembleRun {
+	byClass := make(map[string]int, len(result.TracksByClass))
+	for class, n := range result.TracksByClass {
+		byClass[class] = n
+	}
+	return EnsembleRun{
+		Run:             run,
+		NoiseSeed:       config.Noise.Seed,
+		SeedFromFirst:   config.SeedFromFirst,
+		TotalTracks:     result.TotalTracks,
+		ConfirmedTracks: result.ConfirmedTracks,
+		TracksByClass:   byClass,
+		MeanSpeedMps:    float64(result.SpeedStats.AvgSpeed),
+	}
+}
+
+// summariseEnsemble computes the spread of each metric across runs. A
+// class missing from a run counts as zero tracks in that run.
+func summariseEnsemble(pcapFile, seedMode string, runs []EnsembleRun) EnsembleReport {
+	report := EnsembleReport{
+		PCAPFile:      pcapFile,
+		Runs:          len(runs),
+		SeedMode:      seedMode,
+		TracksByClass: make(map[string]MetricSpread),
+		PerRun:        runs,
+	}
+	metric := func(value func(EnsembleRun) float64) MetricSpread {
+		values := make([]float64, len(runs))
+		for i, r := range runs {
+			values[i] = value(r)
+		}
+		return spread(values)
+	}
+	report.TotalTracks = metric(func(r EnsembleRun) float64 { return float64(r.TotalTracks) })
+	report.ConfirmedTracks = metric(func(r EnsembleRun) float64 { return float64(r.ConfirmedTracks) })
+	report.MeanSpeedMps = metric(func(r EnsembleRun) float64 { return r.MeanSpeedMps })
+	for _, r := range runs {
+		for class := range r.TracksByClass {
+			if _, done := report.TracksByClass[class]; done {
+				continue
+			}
+			report.TracksByClass[class] = metric(func(r EnsembleRun) float64 { return float64(r.TracksByClass[class]) })
+		}
+	}
+	return report
+}
+
+// spread returns the mean, sample standard deviation and range of values.
+func spread(values []float64) MetricSpread {
+	if len(values) == 0 {
+		return MetricSpread{}
+	}
+	s := MetricSpread{Min: values[0], Max: values[0]}
+	var sum float64
+	for _, v := range values {
+		sum += v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	s.Mean = sum / float64(len(values))
+	if len(values) > 1 {
+		var sq float64
+		for _, v := range values {
+			sq += (v - s.Mean) * (v - s.Mean)
+		}
+		s.StdDev = math.Sqrt(sq / float64(len(values)-1))
+	}
+	return s
+}
+
+func printEnsembleReport(report *EnsembleReport) {
+	row := func(name string, s MetricSpread) {
+		fmt.Printf("  %-20s %9.2f ± %-7.2f [%.2f, %.2f]\n", name, s.Mean, s.StdDev, s.Min, s.Max)
+	}
+	fmt.Println("\n========== Ensemble Stability ==========")
+	fmt.Printf("File: %s\n", report.PCAPFile)
+	fmt.Printf("Runs: %d (seed mode %s)\n\n", report.Runs, report.SeedMode)
+	fmt.Printf("  %-20s %9s   %-7s %s\n", "metric", "mean", "stddev", "[min, max]")
+	row("tracks", report.TotalTracks)
+	row("confirmed", report.ConfirmedTracks)
+	row("mean speed (m/s)", report.MeanSpeedMps)
+	classes := make([]string, 0, len(report.TracksByClass))
+	for class := range report.TracksByClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		row("class "+class, report.TracksByClass[class])
+	}
+	fmt.Println("=========================================")
+}
+
+// exportEnsembleReport writes the report, including per-run values, to
+// <output>/<pcap>_ensemble.json.
+func exportEnsembleReport(config Config, report *EnsembleReport) error {
+	path := filepath.Join(config.OutputDir, network.PCAPBaseName(config.PCAPFile)+"_ensemble.json")
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON marshal: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write ensemble report: %w", err)
+	}
+	fmt.Printf("Ensemble report: %s\n", path)
+	return nil
+}
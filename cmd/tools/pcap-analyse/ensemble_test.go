@@ -0,0 +1,72 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEnsembleRunConfigs_VariesSeedsAndDisablesExports(t *testing.T) {
+	base := Config{
+		PCAPFile: "capture.pcap", DBPath: "runs.db", ExportCSV: true, ExportJSON: true,
+		ExportFrames:  &frameRange{StartFrame: 1, EndFrame: 2},
+		SeedFromFirst: true, EnsembleRuns: 4, EnsembleSeed: ensembleSeedToggle,
+	}
+	base.Noise.Seed = 7
+
+	runs := ensembleRunConfigs(base)
+	if len(runs) != 4 {
+		t.Fatalf("got %d run configs, want 4", len(runs))
+	}
+	for i, c := range runs {
+		if c.Noise.Seed != 7+int64(i) {
+			t.Errorf("run %d noise seed = %d, want %d", i, c.Noise.Seed, 7+i)
+		}
+		if want := i%2 == 0; c.SeedFromFirst != want {
+			t.Errorf("run %d seed_from_first = %t, want %t", i, c.SeedFromFirst, want)
+		}
+		if c.ExportCSV || c.ExportJSON || c.ExportFrames != nil || c.DBPath != "" {
+			t.Errorf("run %d should not export or persist: %+v", i, c)
+		}
+	}
+
+	base.EnsembleSeed = ensembleSeedFalse
+	for i, c := range ensembleRunConfigs(base) {
+		if c.SeedFromFirst {
+			t.Errorf("run %d: seed mode false still seeds from first", i)
+		}
+	}
+}
+
+func TestSummariseEnsemble(t *testing.T) {
+	runs := []EnsembleRun{
+		{Run: 1, TotalTracks: 10, ConfirmedTracks: 8, TracksByClass: map[string]int{"car": 6, "pedestrian": 2}, MeanSpeedMps: 9},
+		{Run: 2, TotalTracks: 12, ConfirmedTracks: 10, TracksByClass: map[string]int{"car": 8}, MeanSpeedMps: 11},
+		{Run: 3, TotalTracks: 14, ConfirmedTracks: 9, TracksByClass: map[string]int{"car": 7, "pedestrian": 1}, MeanSpeedMps: 10},
+	}
+	report := summariseEnsemble("capture.pcap", ensembleSeedTrue, runs)
+
+	near := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	near("total mean", report.TotalTracks.Mean, 12)
+	near("total stddev", report.TotalTracks.StdDev, 2)
+	near("total min", report.TotalTracks.Min, 10)
+	near("total max", report.TotalTracks.Max, 14)
+	near("confirmed mean", report.ConfirmedTracks.Mean, 9)
+	near("speed stddev", report.MeanSpeedMps.StdDev, 1)
+	// Run 2 saw no pedestrians, which counts as zero.
+	near("pedestrian mean", report.TracksByClass["pedestrian"].Mean, 1)
+	near("pedestrian min", report.TracksByClass["pedestrian"].Min, 0)
+	if report.Runs != 3 || len(report.PerRun) != 3 {
+		t.Errorf("report should keep all %d runs, got %d (%d per-run)", 3, report.Runs, len(report.PerRun))
+	}
+	if s := spread([]float64{5}); s.StdDev != 0 || s.Mean != 5 {
+		t.Errorf("single-run spread = %+v", s)
+	}
+}
@@ -0,0 +1,51 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// evaluationPath is <output>/<pcap>_evaluation.json.
+func evaluationPath(config Config) string {
+	return filepath.Join(config.OutputDir, network.PCAPBaseName(config.PCAPFile)+"_evaluation.json")
+}
+
+// evaluateFrame scores this frame's confirmed tracks against -truth. Frames
+// that never reach the tracker (no foreground, no clusters) are scored with
+// no tracks, so truth present in them counts as missed. Frame numbers match
+// the -mot export.
+func (fb *analysisFrameBuilder) evaluateFrame(tracks []*l5tracks.TrackedObject) {
+	if fb.eval == nil {
+		return
+	}
+	fb.eval.Frame(fb.frameCount+1, fb.frameStartTime, tracks)
+}
+
+// closeEvaluation writes the evaluation JSON, if -truth is set, and puts
+// the summary in the analysis result.
+func (fb *analysisFrameBuilder) closeEvaluation() {
+	e := fb.eval
+	if e == nil {
+		return
+	}
+	fb.eval = nil
+	summary := e.Summary()
+	fb.result.Evaluation = &summary
+
+	f, err := os.Create(evaluationPath(fb.config))
+	if err != nil {
+		log.Printf("[WARN] evaluation: %v", err)
+		return
+	}
+	defer f.Close()
+	if err := e.WriteJSON(f, fb.config.TruthFile); err != nil {
+		log.Printf("[WARN] evaluation: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
+)
+
+// frameExportColumns describes the extra ASC columns written per point.
+const frameExportColumns = " Foreground Channel Azimuth Distance"
+
+// frameRange selects frames for -export-frames, either by frame index or by
+// PCAP time offset from the first frame. Both bounds are inclusive.
+type frameRange struct {
+	ByTime               bool
+	StartFrame, EndFrame int
+	Start, End           time.Duration
+}
+
+// parseFrameRange parses "start:end" as frame indices ("1200:1220") or, when
+// both bounds carry a unit, as offsets into the capture ("62.5s:64.5s").
+func parseFrameRange(s string) (*frameRange, error) {
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return nil, fmt.Errorf("frame range %q: want start:end", s)
+	}
+	startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+	if start, err := strconv.Atoi(startStr); err == nil {
+		end, err := strconv.Atoi(endStr)
+		if err != nil {
+			return nil, fmt.Errorf("frame range %q: end must be a frame index like the start", s)
+		}
+		if start < 0 || end < start {
+			return nil, fmt.Errorf("frame range %q: want 0 <= start <= end", s)
+		}
+		return &frameRange{StartFrame: start, EndFrame: end}, nil
+	}
+
+	start, err := time.ParseDuration(startStr)
+	if err != nil {
+		return nil, fmt.Errorf("frame range %q: start is neither a frame index nor a duration", s)
+	}
+	end, err := time.ParseDuration(endStr)
+	if err != nil {
+		return nil, fmt.Errorf("frame range %q: end must be a duration like the start", s)
+	}
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("frame range %q: want 0 <= start <= end", s)
+	}
+	return &frameRange{ByTime: true, Start: start, End: end}, nil
+}
+
+// contains reports whether a frame with the given index and offset from the
+// first frame falls in the range.
+func (r *frameRange) contains(frameIndex int, offset time.Duration) bool {
+	if r.ByTime {
+		return offset >= r.Start && offset <= r.End
+	}
+	return frameIndex >= r.StartFrame && frameIndex <= r.EndFrame
+}
+
+// String formats the range as accepted by parseFrameRange.
+func (r *frameRange) String() string {
+	if r.ByTime {
+		return fmt.Sprintf("%s:%s", r.Start, r.End)
+	}
+	return fmt.Sprintf("%d:%d", r.StartFrame, r.EndFrame)
+}
+
+// writeFramePointCloud writes every point of one frame to an ASC file,
+// labelled 1 for foreground and 0 for background by mask. Points are in the
+// same frame as clusters and tracks: the site frame with a sensor pose,
+// otherwise the sensor frame.
+func writeFramePointCloud(path string, frameIndex int, frameTime time.Time, points []l2frames.PointPolar, mask []bool, pose *l4perception.Pose, sensorID string) error {
+	world := l4perception.TransformToWorld(points, pose, sensorID)
+	out := make([]l2frames.PointASC, len(points))
+	for i, p := range points {
+		fg := 0
+		if i < len(mask) && mask[i] {
+			fg = 1
+		}
+		out[i] = l2frames.PointASC{
+			X: world[i].X, Y: world[i].Y, Z: world[i].Z,
+			Intensity: int(p.Intensity),
+			Extra:     []interface{}{fg, p.Channel, p.Azimuth, p.Distance},
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, "# Frame %d at %s, sensor %s\n", frameIndex, frameTime.UTC().Format(time.RFC3339Nano), sensorID)
+	if err := l2frames.WritePointsASC(f, out, frameExportColumns); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exportFrame writes the current frame if it falls in -export-frames.
+// MUST be called while holding fb.mu lock.
+func (fb *analysisFrameBuilder) exportFrame(mask []bool) {
+	r := fb.config.ExportFrames
+	if r == nil {
+		return
+	}
+	if !r.contains(fb.frameCount, fb.frameStartTime.Sub(fb.firstFrameTime)) {
+		return
+	}
+
+	dir := filepath.Join(fb.config.OutputDir, "frames")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("[pcap-analyse] frame export: %v", err)
+		return
+	}
+	path := filepath.Join(dir, fmt.Sprintf("frame_%06d.asc", fb.frameCount))
+	if err := writeFramePointCloud(path, fb.frameCount, fb.frameStartTime, fb.points, mask, fb.config.SensorPose, fb.config.SensorID); err != nil {
+		log.Printf("[pcap-analyse] frame export %d: %v", fb.frameCount, err)
+		return
+	}
+	fb.exportedFrames++
+}
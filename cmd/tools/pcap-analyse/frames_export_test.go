@@ -0,0 +1,121 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+func TestParseFrameRange(t *testing.T) {
+	r, err := parseFrameRange("1190:1210")
+	if err != nil || r.ByTime || r.StartFrame != 1190 || r.EndFrame != 1210 {
+		t.Errorf("index range: got %+v, %v", r, err)
+	}
+	r, err = parseFrameRange(" 62.5s : 1m4s ")
+	if err != nil || !r.ByTime || r.Start != 62500*time.Millisecond || r.End != 64*time.Second {
+		t.Errorf("time range: got %+v, %v", r, err)
+	}
+	for _, bad := range []string{"", "12", "5:3", "-1:3", "1:2s", "2s:1s", "a:b"} {
+		if _, err := parseFrameRange(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+// exportTestBuilder returns a frame builder holding one three-point frame
+// whose middle point is background.
+func exportTestBuilder(t *testing.T, r *frameRange) (*analysisFrameBuilder, []bool) {
+	t.Helper()
+	start := time.Unix(1700000000, 0)
+	fb := &analysisFrameBuilder{
+		config: Config{OutputDir: t.TempDir(), SensorID: "test-sensor", ExportFrames: r},
+		points: []l2frames.PointPolar{
+			{Channel: 1, Azimuth: 0, Distance: 10, Intensity: 40},
+			{Channel: 2, Azimuth: 90, Distance: 20, Intensity: 50},
+			{Channel: 3, Azimuth: 180, Distance: 30, Intensity: 60},
+		},
+		firstFrameTime: start,
+		frameStartTime: start,
+	}
+	return fb, []bool{true, false, true}
+}
+
+func exportedFrameFiles(t *testing.T, fb *analysisFrameBuilder) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(fb.config.OutputDir, "frames", "*.asc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range matches {
+		names = append(names, filepath.Base(m))
+	}
+	return names
+}
+
+func TestExportFrame_WritesOnlyFramesInRange(t *testing.T) {
+	fb, mask := exportTestBuilder(t, &frameRange{StartFrame: 2, EndFrame: 3})
+	for fb.frameCount = 0; fb.frameCount < 6; fb.frameCount++ {
+		fb.exportFrame(mask)
+	}
+
+	got := exportedFrameFiles(t, fb)
+	if strings.Join(got, ",") != "frame_000002.asc,frame_000003.asc" || fb.exportedFrames != 2 {
+		t.Fatalf("exported %v (count %d), want frames 2 and 3", got, fb.exportedFrames)
+	}
+
+	data, err := os.ReadFile(filepath.Join(fb.config.OutputDir, "frames", "frame_000002.asc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.HasPrefix(line, "#") {
+			if strings.HasPrefix(line, "# Format:") && !strings.HasSuffix(line, frameExportColumns) {
+				t.Errorf("header %q lacks the label columns", line)
+			}
+			continue
+		}
+		rows = append(rows, strings.Fields(line))
+	}
+	if len(rows) != len(fb.points) {
+		t.Fatalf("got %d points, want every point of the frame (%d)", len(rows), len(fb.points))
+	}
+	for i, row := range rows {
+		// X Y Z Intensity Foreground Channel Azimuth Distance
+		want := "0"
+		if mask[i] {
+			want = "1"
+		}
+		if row[4] != want {
+			t.Errorf("point %d foreground label = %s, want %s", i, row[4], want)
+		}
+	}
+}
+
+func TestExportFrame_TimeRange(t *testing.T) {
+	fb, mask := exportTestBuilder(t, &frameRange{ByTime: true, Start: 150 * time.Millisecond, End: 350 * time.Millisecond})
+	start := fb.firstFrameTime
+	for fb.frameCount = 0; fb.frameCount < 6; fb.frameCount++ {
+		fb.frameStartTime = start.Add(time.Duration(fb.frameCount) * 100 * time.Millisecond)
+		fb.exportFrame(mask)
+	}
+	if got := exportedFrameFiles(t, fb); strings.Join(got, ",") != "frame_000002.asc,frame_000003.asc" {
+		t.Errorf("exported %v, want the frames 200 ms and 300 ms into the capture", got)
+	}
+}
+
+func TestExportFrame_Disabled(t *testing.T) {
+	fb, mask := exportTestBuilder(t, nil)
+	fb.exportFrame(mask)
+	if got := exportedFrameFiles(t, fb); len(got) != 0 {
+		t.Errorf("exported %v without -export-frames", got)
+	}
+}
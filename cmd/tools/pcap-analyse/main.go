@@ -20,11 +20,11 @@ import (
 	"runtime/debug"
 	"sort"
 	"strconv"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	cfgpkg "github.com/banshee-data/velocity.report/internal/config"
 	"github.com/banshee-data/velocity.report/internal/db"
 	"github.com/banshee-data/velocity.report/internal/lidar"
 	"github.com/banshee-data/velocity.report/internal/lidar/adapters"
@@ -56,10 +56,14 @@ type Config struct {
 	OutputDir      string
 	SensorID       string
 	UDPPort        int
+	PacketFilter   bool // skip non-Pandar40P payloads before parsing (-packet-filter)
 	DBPath         string
 	ExportCSV      bool
 	ExportJSON     bool
 	ExportTraining bool
+	ExportFeatures bool
+	ExportMOT      bool // per-frame confirmed tracks in MOTChallenge format (-mot)
+	ExplainClasses bool // one JSON line per classification decision (-explain-classes)
 	Verbose        bool
 	FrameRate      float64 // Expected frame rate in Hz
 	Stats          bool    // Display concise capture statistics only
@@ -71,6 +75,87 @@ type Config struct {
 	Quiet               bool
 	CompareBaseline     string
 	RegressionThreshold float64
+
+	// Synthetic noise injected before frame assembly (robustness testing)
+	Noise network.NoiseConfig
+
+	// Sensor extrinsics: with a pose, clusters and tracks are in the site
+	// frame instead of the sensor frame
+	ExtrinsicsFile string
+	SensorPose     *l4perception.Pose
+
+	// Returns kept from dual-return packets (-return-mode)
+	ReturnMode      string
+	ReturnSelection parse.ReturnSelection
+
+	// Ring/elevation band for clustering (-ring-roi)
+	RingROIFile string
+	RingROI     *l4perception.RingROI
+
+	// Goroutines for DBSCAN neighbour queries (-cluster-workers)
+	ClusterWorkers int
+
+	// Rejoin fragments split across background regions (-region-continuity)
+	RegionContinuity bool
+
+	// Track birth/death zones (-lifecycle-zones)
+	LifecycleZonesFile string
+	LifecycleZones     *l5tracks.LifecycleZoneConfig
+
+	// Multipath ghost reflectors (-ghost-reflectors)
+	GhostReflectorsFile string
+	GhostSuppression    *l5tracks.GhostSuppressionConfig
+
+	// Road axis for along/cross-road track velocity (-road-axis)
+	RoadAxisFile string
+	RoadAxis     *l5tracks.RoadAxis
+
+	// Speed-limit tagging of confirmed vehicle tracks (-speed-limits)
+	SpeedLimitsFile string
+	SpeedLimits     *l6objects.SpeedLimitTagger
+
+	// Origin–destination zones for the hourly OD matrix (-od-zones)
+	ODZonesFile string
+	ODMatrix    *l6objects.ODMatrix
+
+	// Per-class minimum track durations (-min-duration): shorter tracks are
+	// dropped from counts and distributions, and from the track exports
+	// unless IncludeFlicker is set (-include-flicker)
+	MinDurationFile string
+	MinDuration     *l6objects.MinDurationConfig
+	IncludeFlicker  bool
+
+	// Plausibility gates splitting unclassified tracks into "unknown" and
+	// "noise" (-unclassified-gates); nil reports them all as "other"
+	FallbackClassesFile string
+	FallbackClasses     *l6objects.FallbackClassConfig
+
+	// Ground truth scored frame by frame with CLEAR MOT metrics (-truth,
+	// -truth-match-dist)
+	TruthFile      string
+	Truth          *adapters.TruthSeries
+	TruthMatchDist float64
+
+	// Per-frame point cloud export for a flagged range (-export-frames)
+	ExportFrames *frameRange
+
+	// Background seeding from the first observation (-seed-from-first)
+	SeedFromFirst bool
+
+	// Multi-run stability assessment (-ensemble): runs > 1 analyses the
+	// PCAP that many times with varied seeds and reports metric spread
+	EnsembleRuns int
+	EnsembleSeed string // "true", "false" or "toggle" per run
+
+	// Roll a directory of *_analysis.json results up into one report
+	// instead of analysing a PCAP (-summarise)
+	SummariseDir string
+
+	// Per-point foreground diagnostics for a ring/azimuth box
+	// (-debug-ring-min/max, -debug-az-min/max), written to
+	// <pcap>_point_debug.csv. Off when all four are zero.
+	DebugRingMin, DebugRingMax int
+	DebugAzMin, DebugAzMax     float64
 }
 
 // AnalysisResult holds the results of PCAP analysis.
@@ -79,6 +164,9 @@ type AnalysisResult struct {
 	Duration           time.Duration         `json:"duration_ns"`
 	DurationSecs       float64               `json:"duration_secs"`
 	TotalPackets       int                   `json:"total_packets"`
+	SkippedPackets     int64                 `json:"skipped_packets,omitempty"`
+	ClippedNearPoints  int64                 `json:"clipped_near_points,omitempty"`
+	ClippedFarPoints   int64                 `json:"clipped_far_points,omitempty"`
 	TotalPoints        int                   `json:"total_points"`
 	TotalFrames        int                   `json:"total_frames"`
 	ForegroundPoints   int                   `json:"foreground_points"`
@@ -87,12 +175,26 @@ type AnalysisResult struct {
 	TotalTracks        int                   `json:"total_tracks"`
 	ConfirmedTracks    int                   `json:"confirmed_tracks"`
 	TracksByClass      map[string]int        `json:"tracks_by_class"`
+	CombinedByClass    map[string]int        `json:"tracks_by_class_combined,omitempty"`
 	ProcessingTimeMs   int64                 `json:"processing_time_ms"`
 	Tracks             []*TrackExport        `json:"tracks,omitempty"`
 	ClassificationDist map[string]ClassStats `json:"classification_distribution"`
+	CombinedClassDist  map[string]ClassStats `json:"classification_distribution_combined,omitempty"`
 	SpeedStats         SpeedStatistics       `json:"speed_statistics"`
 	TrainingFrames     int                   `json:"training_frames,omitempty"`
+	ExportedFrames     int                   `json:"exported_frames,omitempty"`
+	PointDebugRows     int                   `json:"point_debug_rows,omitempty"`
+	MOTRows            int                   `json:"mot_rows,omitempty"`
+	ClassExplanations  int                   `json:"class_explanations,omitempty"`
+	Evaluation         *adapters.MOTSummary  `json:"evaluation,omitempty"`
+	SpeedLimits        *SpeedLimitSummary    `json:"speed_limits,omitempty"`
+	ODTrips            int                   `json:"od_trips,omitempty"`
+	FlickerTracks      map[string]int        `json:"flicker_tracks_by_class,omitempty"`
 	CaptureStats       *CaptureStats         `json:"capture_stats,omitempty"`
+
+	// How long tracks took from first observation to confirmation; nil
+	// when no track was confirmed.
+	ConfirmationLatency *l5tracks.ConfirmationLatencySummary `json:"confirmation_latency,omitempty"`
 }
 
 // TrackExport represents a track for export.
@@ -115,25 +217,47 @@ type TrackExport struct {
 	EndX          float32 `json:"end_x_m"`
 	EndY          float32 `json:"end_y_m"`
 	TotalDistance float32 `json:"total_distance_m"`
+
+	// Confidence-weighted speed to report; see
+	// docs/lidar/operations/composite-speed.md.
+	CompositeSpeedMps float32 `json:"composite_speed_mps"`
+
+	// Fraction of frames between first and last observation in which the
+	// track was observed rather than coasted.
+	DetectionReliability float32 `json:"detection_reliability"`
+
+	// Speed-limit verdict; nil unless -speed-limits is set and the track
+	// is a confirmed vehicle with a limit where it was seen.
+	SpeedLimit *l6objects.SpeedLimitTag `json:"speed_limit,omitempty"`
+
+	// Entry and exit zones; empty unless -od-zones is set.
+	Origin      string `json:"origin,omitempty"`
+	Destination string `json:"destination,omitempty"`
+
+	// Mean velocity over the track decomposed against the road axis; nil
+	// unless -road-axis is set.
+	RoadVelocity *l5tracks.RoadVelocity `json:"road_velocity,omitempty"`
+
+	// Confirmation latency; nil for a track never confirmed.
+	Confirmation *l5tracks.ConfirmationLatency `json:"confirmation,omitempty"`
+
+	// Flicker marks a track shorter than its class minimum, exported only
+	// with -include-flicker and left out of every count.
+	Flicker bool `json:"flicker,omitempty"`
+
+	// Classification feature vector in l6objects.FeatureVectorColumns order,
+	// written to the features CSV rather than the JSON results.
+	Features []float32 `json:"-"`
 }
 
 // ClassStats holds statistics for a classification category.
-type ClassStats struct {
-	Count           int     `json:"count"`
-	AvgSpeed        float32 `json:"avg_speed_mps"`
-	AvgDuration     float32 `json:"avg_duration_secs"`
-	AvgObservations float32 `json:"avg_observations"`
-}
+type ClassStats = l6objects.ClassStats
 
 // SpeedStatistics holds overall speed statistics.
-type SpeedStatistics struct {
-	MinSpeed float32 `json:"min_speed_mps"`
-	MaxSpeed float32 `json:"max_speed_mps"`
-	AvgSpeed float32 `json:"avg_speed_mps"`
-	P50Speed float32 `json:"p50_speed_mps"`
-	P85Speed float32 `json:"p85_speed_mps"`
-	P95Speed float32 `json:"p95_speed_mps"`
-}
+type SpeedStatistics = l6objects.SpeedStatistics
+
+// SpeedLimitSummary counts tracks tagged over and under the speed limit.
+type SpeedLimitSummary = l6objects.SpeedLimitSummary
 
 // TrainingFrame represents a frame prepared for ML ingestion.
 type TrainingFrame struct {
@@ -245,6 +369,19 @@ type MetricDifference struct {
 func main() {
 	config := parseFlags()
 
+	// Summary mode: aggregate earlier analysis results and exit
+	if config.SummariseDir != "" {
+		if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+			log.Fatalf("Failed to create output directory: %v", err)
+		}
+		report, err := runSummarise(config.SummariseDir, config)
+		if err != nil {
+			log.Fatalf("Summary failed: %v", err)
+		}
+		printSummaryReport(report)
+		return
+	}
+
 	if config.PCAPFile == "" {
 		fmt.Fprintln(os.Stderr, "Error: PCAP file is required")
 		flag.Usage()
@@ -256,6 +393,92 @@ func main() {
 		os.Exit(1)
 	}
 
+	if config.ExtrinsicsFile != "" {
+		pose, err := l4perception.LoadSensorPose(config.ExtrinsicsFile, config.SensorID)
+		if err != nil {
+			log.Fatalf("Failed to load extrinsics: %v", err)
+		}
+		config.SensorPose = pose
+	}
+	if sel, err := parse.ParseReturnSelection(config.ReturnMode); err != nil {
+		log.Fatalf("Invalid -return-mode: %v", err)
+	} else {
+		config.ReturnSelection = sel
+	}
+	if config.RingROIFile != "" {
+		roi, err := l4perception.LoadSensorRingROI(config.RingROIFile, config.SensorID)
+		if err != nil {
+			log.Fatalf("Failed to load ring ROI: %v", err)
+		}
+		config.RingROI = roi
+	}
+	if config.LifecycleZonesFile != "" {
+		zones, err := l5tracks.LoadLifecycleZoneConfig(config.LifecycleZonesFile)
+		if err != nil {
+			log.Fatalf("Failed to load lifecycle zones: %v", err)
+		}
+		config.LifecycleZones = zones
+	}
+	if config.GhostReflectorsFile != "" {
+		ghosts, err := l5tracks.LoadGhostSuppressionConfig(config.GhostReflectorsFile)
+		if err != nil {
+			log.Fatalf("Failed to load ghost reflectors: %v", err)
+		}
+		config.GhostSuppression = ghosts
+	}
+	if config.RoadAxisFile != "" {
+		axis, err := l5tracks.LoadRoadAxis(config.RoadAxisFile)
+		if err != nil {
+			log.Fatalf("Failed to load road axis: %v", err)
+		}
+		config.RoadAxis = axis
+	}
+	if config.SpeedLimitsFile != "" {
+		cfg, err := l6objects.LoadSpeedLimitConfig(config.SpeedLimitsFile)
+		if err != nil {
+			log.Fatalf("Failed to load speed limits: %v", err)
+		}
+		tagger, err := l6objects.NewSpeedLimitTagger(cfg)
+		if err != nil {
+			log.Fatalf("Failed to load speed limits: %v", err)
+		}
+		config.SpeedLimits = tagger
+	}
+	if config.ODZonesFile != "" {
+		cfg, err := l6objects.LoadODConfig(config.ODZonesFile)
+		if err != nil {
+			log.Fatalf("Failed to load OD zones: %v", err)
+		}
+		matrix, err := l6objects.NewODMatrix(cfg)
+		if err != nil {
+			log.Fatalf("Failed to load OD zones: %v", err)
+		}
+		config.ODMatrix = matrix
+	}
+	if config.TruthFile != "" {
+		truth, err := adapters.LoadTruthCSV(config.TruthFile)
+		if err != nil {
+			log.Fatalf("Failed to load ground truth: %v", err)
+		}
+		config.Truth = truth
+	}
+	if config.MinDurationFile != "" {
+		cfg, err := l6objects.LoadMinDurationConfig(config.MinDurationFile)
+		if err != nil {
+			log.Fatalf("Failed to load min durations: %v", err)
+		}
+		config.MinDuration = &cfg
+	}
+	fallback := l6objects.DefaultFallbackClassConfig()
+	if config.FallbackClassesFile != "" {
+		cfg, err := l6objects.LoadFallbackClassConfig(config.FallbackClassesFile)
+		if err != nil {
+			log.Fatalf("Failed to load unclassified gates: %v", err)
+		}
+		fallback = cfg
+	}
+	config.FallbackClasses = &fallback
+
 	// Create output directory
 	if config.OutputDir != "" {
 		if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
@@ -263,6 +486,22 @@ func main() {
 		}
 	}
 
+	// Ensemble mode: repeat the analysis, print the stability report and exit
+	if config.EnsembleRuns > 1 {
+		if config.Quiet {
+			log.SetOutput(io.Discard)
+		}
+		report, err := runEnsemble(config)
+		if err != nil {
+			log.Fatalf("Ensemble failed: %v", err)
+		}
+		printEnsembleReport(report)
+		if err := exportEnsembleReport(config, report); err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		return
+	}
+
 	// In benchmark mode with quiet flag, suppress verbose output
 	if config.Benchmark && config.Quiet {
 		config.Verbose = false
@@ -275,6 +514,11 @@ func main() {
 		config.ExportCSV = false
 		config.ExportJSON = false
 		config.ExportTraining = false
+		config.ExportFeatures = false
+		config.ExportMOT = false
+		config.ExplainClasses = false
+		config.Truth = nil
+		config.ExportFrames = nil
 		log.SetOutput(io.Discard)
 	}
 
@@ -327,15 +571,35 @@ func main() {
 
 func parseFlags() Config {
 	config := Config{}
+	tuning := cfgpkg.MustLoadDefaultConfig()
 
-	flag.StringVar(&config.PCAPFile, "pcap", "", "Path to PCAP file (required)")
+	flag.StringVar(&config.PCAPFile, "pcap", "", "Path to PCAP file, optionally .gz or .zst compressed (required)")
 	flag.StringVar(&config.OutputDir, "output", ".", "Output directory for results")
 	flag.StringVar(&config.SensorID, "sensor-id", "hesai-pandar40p", "Sensor ID")
-	flag.IntVar(&config.UDPPort, "port", 2369, "UDP port for LIDAR data")
+	flag.StringVar(&config.ExtrinsicsFile, "extrinsics", "", "JSON file of sensor extrinsics keyed by sensor ID; transforms tracks into the site frame (default: sensor frame)")
+	flag.StringVar(&config.ReturnMode, "return-mode", "all", "Returns to keep from dual-return packets: all, strongest, last or first")
+	flag.StringVar(&config.RingROIFile, "ring-roi", "", "JSON file of ring/elevation bands keyed by sensor ID; clustering skips points outside the band (default: all rings)")
+	flag.IntVar(&config.ClusterWorkers, "cluster-workers", tuning.GetClusterWorkers(), "Goroutines for DBSCAN neighbour queries; clusters match a serial run (0 or 1 = serial; default: L4 tuning cluster_workers)")
+	flag.BoolVar(&config.RegionContinuity, "region-continuity", tuning.GetRegionContinuity(), "Rejoin cluster fragments split across a background region boundary before track association (default: L4 tuning region_continuity)")
+	flag.StringVar(&config.LifecycleZonesFile, "lifecycle-zones", "", "JSON file of birth and death zones; tracks born outside birth zones are penalised or dropped, and tracks lost outside death zones coast longer")
+	flag.StringVar(&config.GhostReflectorsFile, "ghost-reflectors", "", "JSON file of reflective surfaces; tracks that mirror a real track across one are held tentative as multipath ghosts")
+	flag.StringVar(&config.SpeedLimitsFile, "speed-limits", "", "JSON file of speed limits (default, per sensor and per zone); tags confirmed vehicle tracks as over or under the limit")
+	flag.StringVar(&config.RoadAxisFile, "road-axis", "", "JSON file giving a road axis (heading_deg, or from/to points in the tracker frame); tracks gain along- and cross-road mean velocity")
+	flag.StringVar(&config.ODZonesFile, "od-zones", "", "JSON file of scene-edge zones; counts tracks by entry and exit zone per hour and writes an origin-destination matrix CSV")
+	flag.StringVar(&config.MinDurationFile, "min-duration", "", "JSON file of per-class minimum track durations in seconds; shorter tracks are dropped from counts, distributions and exports")
+	flag.BoolVar(&config.IncludeFlicker, "include-flicker", false, "Keep tracks dropped by -min-duration in the track exports, marked flicker, for debugging (they stay out of counts)")
+	flag.StringVar(&config.FallbackClassesFile, "unclassified-gates", "", "JSON file of plausibility gates (min_observations, min_duration_secs, min/max_height_m, max_length_m, max_speed_mps) splitting unclassified tracks into unknown and noise (default: built-in gates)")
+	flag.IntVar(&config.UDPPort, "port", 0, "UDP port for LIDAR data (0 = detect from the capture)")
+	flag.BoolVar(&config.PacketFilter, "packet-filter", true, "Skip UDP payloads on the port that are not Pandar40P data packets (by size and block preamble) instead of parsing them")
 	flag.StringVar(&config.DBPath, "db", "", "SQLite database path (optional, for persistence)")
 	flag.BoolVar(&config.ExportCSV, "csv", true, "Export tracks to CSV")
 	flag.BoolVar(&config.ExportJSON, "json", true, "Export full results to JSON")
 	flag.BoolVar(&config.ExportTraining, "training", false, "Export training data (foreground blobs)")
+	flag.BoolVar(&config.ExportFeatures, "features", false, "Export per-track classification feature vectors to CSV")
+	flag.BoolVar(&config.ExportMOT, "mot", false, "Export confirmed tracks per frame in MOTChallenge format to <pcap>_mot.txt (ground-plane boxes, see docs)")
+	flag.BoolVar(&config.ExplainClasses, "explain-classes", false, "Write each classification decision (features, rule checks with margins, deciding feature) to <pcap>_class_explanations.jsonl")
+	flag.StringVar(&config.TruthFile, "truth", "", "Ground-truth CSV (timestamp_unix_nanos,id,x,y[,class], as gen-pcap -truth writes); scores confirmed tracks per frame with MOTA/MOTP/ID switches into <pcap>_evaluation.json")
+	flag.Float64Var(&config.TruthMatchDist, "truth-match-dist", adapters.DefaultMOTMatchDistanceMetres, "Largest track-to-truth centre distance in metres that counts as a match for -truth")
 	flag.BoolVar(&config.Verbose, "v", false, "Verbose output")
 	flag.Float64Var(&config.FrameRate, "fps", 10.0, "Expected frame rate in Hz")
 	flag.BoolVar(&config.Stats, "stats", false, "Display concise capture statistics (frame rate, RPM, duration)")
@@ -349,6 +613,21 @@ func parseFlags() Config {
 	flag.BoolVar(&config.Quiet, "q", false, "Suppress verbose output (alias for -quiet)")
 	flag.StringVar(&config.CompareBaseline, "compare-baseline", "", "Compare against a baseline benchmark file")
 	flag.Float64Var(&config.RegressionThreshold, "regression-threshold", 0.10, "Threshold for flagging regressions (default: 0.10 = 10%)")
+	flag.Int64Var(&config.Noise.Seed, "noise-seed", 1, "Seed for synthetic noise injection (same seed reproduces the same perturbations)")
+	flag.Float64Var(&config.Noise.DropoutRate, "noise-dropout", 0, "Fraction of returns to drop at random (0-1)")
+	flag.Float64Var(&config.Noise.RangeJitterM, "noise-range-jitter", 0, "Std-dev of Gaussian range jitter in metres")
+	flag.Float64Var(&config.Noise.SpuriousRate, "noise-spurious", 0, "Spurious returns injected per real return")
+	flag.Float64Var(&config.Noise.SpuriousMinRangeM, "noise-spurious-min-range", 1, "Minimum range of spurious returns in metres")
+	flag.Float64Var(&config.Noise.SpuriousMaxRangeM, "noise-spurious-max-range", 60, "Maximum range of spurious returns in metres")
+	flag.BoolVar(&config.SeedFromFirst, "seed-from-first", true, "Seed the background model from the first observation of each cell")
+	flag.IntVar(&config.EnsembleRuns, "ensemble", 0, "Analyse the PCAP this many times with varied seeds and report the mean and stddev of key metrics (0 or 1 = single run)")
+	flag.StringVar(&config.EnsembleSeed, "ensemble-seed", ensembleSeedToggle, "Seed-from-first per ensemble run: 'true', 'false', or 'toggle' (alternates per run); the noise seed advances by one per run")
+	flag.StringVar(&config.SummariseDir, "summarise", "", "Directory of *_analysis.json results to roll up into summary.json and summary CSVs in -output (no PCAP needed)")
+	flag.IntVar(&config.DebugRingMin, "debug-ring-min", 0, "Log per-point foreground decisions for rings from this index (0-based, inclusive) to <pcap>_point_debug.csv")
+	flag.IntVar(&config.DebugRingMax, "debug-ring-max", 0, "Last ring index (inclusive) of the debug box; 0 for both ring flags means all rings")
+	flag.Float64Var(&config.DebugAzMin, "debug-az-min", 0, "First azimuth in degrees (inclusive) of the debug box")
+	flag.Float64Var(&config.DebugAzMax, "debug-az-max", 0, "Last azimuth in degrees (inclusive) of the debug box; 0 for both azimuth flags means all azimuths")
+	exportFrames := flag.String("export-frames", "", "Write frames in start:end (frame indices, or offsets like 62.5s:64.5s) as ASC point clouds with foreground labels to <output>/frames")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
@@ -366,6 +645,19 @@ func parseFlags() Config {
 		fmt.Fprintf(os.Stderr, "  -benchmark-output FILE  Output benchmark JSON to FILE\n")
 		fmt.Fprintf(os.Stderr, "  -quiet                  Suppress output to reduce measurement noise\n")
 		fmt.Fprintf(os.Stderr, "  -compare-baseline FILE  Compare against baseline, exit 1 on regression\n\n")
+		fmt.Fprintf(os.Stderr, "Noise Injection:\n")
+		fmt.Fprintf(os.Stderr, "  -noise-dropout, -noise-range-jitter and -noise-spurious perturb points\n")
+		fmt.Fprintf(os.Stderr, "  before frame assembly; -noise-seed makes runs reproducible\n\n")
+		fmt.Fprintf(os.Stderr, "Ensemble Mode:\n")
+		fmt.Fprintf(os.Stderr, "  -ensemble N runs the analysis N times, varying -noise-seed and\n")
+		fmt.Fprintf(os.Stderr, "  seed-from-first, and writes <pcap>_ensemble.json with per-run values\n\n")
+		fmt.Fprintf(os.Stderr, "Summary Mode:\n")
+		fmt.Fprintf(os.Stderr, "  -summarise DIR reads every *_analysis.json under DIR and writes\n")
+		fmt.Fprintf(os.Stderr, "  summary.json plus capture, daily and hourly CSVs; no PCAP is needed\n\n")
+		fmt.Fprintf(os.Stderr, "Point Diagnostics:\n")
+		fmt.Fprintf(os.Stderr, "  -debug-ring-min/max and -debug-az-min/max select a ring/azimuth box;\n")
+		fmt.Fprintf(os.Stderr, "  every point in it is written to <pcap>_point_debug.csv with its range,\n")
+		fmt.Fprintf(os.Stderr, "  the cell background and the foreground decision\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
@@ -373,9 +665,34 @@ func parseFlags() Config {
 		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -training -output ./ml_data\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -benchmark -quiet -benchmark-output perf.json\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -benchmark -compare-baseline baseline.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -noise-dropout 0.1 -noise-range-jitter 0.03 -noise-seed 7\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -extrinsics site-extrinsics.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -extrinsics site-extrinsics.json -speed-limits limits.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -min-duration min-durations.json -include-flicker\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -export-frames 1190:1210 -output ./incident\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -ensemble 10 -ensemble-seed toggle\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -summarise ./week-results -output ./week-summary\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -pcap capture.pcap -debug-ring-min 12 -debug-ring-max 14 -debug-az-min 85 -debug-az-max 95\n", os.Args[0])
 	}
 
 	flag.Parse()
+
+	if *exportFrames != "" {
+		r, err := parseFrameRange(*exportFrames)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -export-frames: %v\n", err)
+			os.Exit(1)
+		}
+		config.ExportFrames = r
+	}
+	if err := config.validatePointDebug(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !validEnsembleSeed(config.EnsembleSeed) {
+		fmt.Fprintf(os.Stderr, "Error: -ensemble-seed must be 'true', 'false' or 'toggle', got %q\n", config.EnsembleSeed)
+		os.Exit(1)
+	}
 	return config
 }
 
@@ -422,17 +739,20 @@ func (s *analysisStats) getStats() (packets, points int, duration time.Duration)
 	return s.packets, s.points, s.lastPkt.Sub(s.firstPkt)
 }
 
-// analysisFrameBuilder implements network.FrameBuilder for collecting frames and processing.
+// analysisFrameBuilder implements network.FrameBuilder, assembling frames
+// with an l2frames.FrameIterator and running each through the pipeline.
 type analysisFrameBuilder struct {
-	mu             sync.Mutex
+	mu     sync.Mutex
+	frames *l2frames.FrameIterator
+
+	// Frame being processed: set by the iterator callback
 	points         []l2frames.PointPolar
-	lastAzimuth    float64
 	frameStartTime time.Time
 	frameCount     int
-	motorSpeed     uint16
 
 	// Processing components
 	bgManager  *l3grid.BackgroundManager
+	slowMover  *l4perception.SlowMoverAccumulator // nil unless the tuning defaults enable it
 	tracker    *l5tracks.Tracker
 	classifier *l6objects.TrackClassifier
 	config     Config
@@ -448,14 +768,25 @@ type analysisFrameBuilder struct {
 	trackTimeNs    int64     // Cumulative tracking time
 	classifyTimeNs int64     // Cumulative classification time
 
-	// RPM tracking (always populated)
-	rpmValues  []uint16 // All RPM values received from SetMotorSpeed
-	lastRPM    uint16   // Most recent RPM value
-	rpmChanges int      // Number of RPM value changes
-
 	// Per-frame PCAP timestamps (always populated, used by -stats-10s)
 	frameTimestamps []time.Time
 
+	// -export-frames state: time offsets are measured from firstFrameTime
+	firstFrameTime time.Time
+	exportedFrames int
+
+	// -debug-ring/-debug-az CSV, nil when off
+	pointDebug *pointDebugWriter
+
+	// -mot result file, nil when off
+	mot *motExport
+
+	// -truth evaluator, nil when off
+	eval *adapters.MOTEvaluator
+
+	// -explain-classes output, nil when off
+	explain *classExplanations
+
 	// Database connection for background/region persistence
 	dbConn *db.DB
 }
@@ -479,18 +810,50 @@ func newAnalysisFrameBuilder(config Config, result *AnalysisResult) *analysisFra
 		store = dbConn
 	}
 
+	bgManager := createBackgroundManager(config.SensorID, config.SeedFromFirst, store)
+	trackerCfg := l5tracks.DefaultTrackerConfig()
+	trackerCfg.LifecycleZones = config.LifecycleZones
+	trackerCfg.GhostSuppression = config.GhostSuppression
+	if config.RegionContinuity {
+		// Tracks are in the extrinsics' site frame when a pose is set, so
+		// positions go back through it before the sensor-frame lookup.
+		lookup := l3grid.WorldRegionLookup{Manager: bgManager}
+		if config.SensorPose != nil {
+			lookup.SensorToWorld = &config.SensorPose.T
+		}
+		trackerCfg.RegionContinuity = &l5tracks.RegionContinuityConfig{Enabled: true, Regions: lookup}
+	}
+
 	fb := &analysisFrameBuilder{
-		points:          make([]l2frames.PointPolar, 0, 50000),
-		bgManager:       createBackgroundManager(config.SensorID, store),
-		tracker:         l5tracks.NewTracker(l5tracks.DefaultTrackerConfig()),
+		bgManager:       bgManager,
+		slowMover:       l4perception.NewSlowMoverAccumulator(l4perception.DefaultSlowMoverConfig()),
+		tracker:         l5tracks.NewTracker(trackerCfg),
 		classifier:      l6objects.NewTrackClassifier(),
 		config:          config,
 		result:          result,
 		benchmarkMode:   config.Benchmark,
-		rpmValues:       make([]uint16, 0, 64),
 		frameTimestamps: make([]time.Time, 0, defaultFrameCapacity),
 		dbConn:          dbConn,
 	}
+	fb.frames = l2frames.NewFrameIterator(config.SensorID, fb.handleFrame)
+	if config.pointDebugEnabled() {
+		if err := fb.startPointDebug(); err != nil {
+			log.Printf("[WARN] Point debug disabled: %v", err)
+		}
+	}
+	if config.ExportMOT {
+		if err := fb.startMOTExport(); err != nil {
+			log.Printf("[WARN] MOT export disabled: %v", err)
+		}
+	}
+	if config.Truth != nil {
+		fb.eval = adapters.NewMOTEvaluator(config.Truth, config.TruthMatchDist)
+	}
+	if config.ExplainClasses {
+		if err := fb.startClassExplanations(); err != nil {
+			log.Printf("[WARN] Class explanations disabled: %v", err)
+		}
+	}
 	if config.Benchmark {
 		// Pre-allocate frame times array (estimate based on typical PCAP duration)
 		fb.frameTimes = make([]float64, 0, defaultFrameCapacity)
@@ -501,46 +864,26 @@ func newAnalysisFrameBuilder(config Config, result *AnalysisResult) *analysisFra
 func (fb *analysisFrameBuilder) AddPointsPolar(points []l2frames.PointPolar) {
 	fb.mu.Lock()
 	defer fb.mu.Unlock()
-
-	if len(points) == 0 {
-		return
-	}
-
-	// Track packet timestamps
-	pktTime := time.Unix(0, points[0].Timestamp)
-	if fb.frameStartTime.IsZero() {
-		fb.frameStartTime = pktTime
-	}
-
-	// Detect frame completion (360° rotation)
-	for _, p := range points {
-		// Check for azimuth wrap (new frame)
-		if fb.lastAzimuth > 270 && p.Azimuth < 90 {
-			// Frame complete - process it
-			if len(fb.points) > 0 {
-				fb.processCurrentFrame()
-				fb.frameCount++
-			}
-
-			// Start new frame
-			fb.points = fb.points[:0]
-			fb.frameStartTime = pktTime
-		}
-
-		fb.points = append(fb.points, p)
-		fb.lastAzimuth = p.Azimuth
-	}
+	fb.frames.AddPointsPolar(points)
 }
 
 func (fb *analysisFrameBuilder) SetMotorSpeed(rpm uint16) {
-	fb.mu.Lock()
-	defer fb.mu.Unlock()
-	if rpm != fb.lastRPM && fb.lastRPM != 0 {
-		fb.rpmChanges++
+	fb.frames.SetMotorSpeed(rpm)
+}
+
+// handleFrame is the FrameIterator callback. It runs while AddPointsPolar
+// or finalise holds fb.mu. The partial frame flushed at the end of the
+// capture is processed but not counted, so frameCount is complete rotations.
+func (fb *analysisFrameBuilder) handleFrame(frame *l2frames.LiDARFrame) {
+	if fb.firstFrameTime.IsZero() {
+		fb.firstFrameTime = frame.StartTimestamp
+	}
+	fb.points = frame.PolarPoints
+	fb.frameStartTime = frame.StartTimestamp
+	fb.processCurrentFrame()
+	if frame.SpinComplete {
+		fb.frameCount++
 	}
-	fb.lastRPM = rpm
-	fb.rpmValues = append(fb.rpmValues, rpm)
-	fb.motorSpeed = rpm
 }
 
 // processCurrentFrame processes the accumulated points as a complete frame.
@@ -548,6 +891,10 @@ func (fb *analysisFrameBuilder) SetMotorSpeed(rpm uint16) {
 func (fb *analysisFrameBuilder) processCurrentFrame() {
 	frameStart := time.Now()
 
+	if roi := fb.config.RingROI; roi != nil && roi.WholePipeline {
+		fb.points = roi.Filter(fb.points)
+	}
+
 	// Step 1: Foreground extraction
 	mask, err := fb.bgManager.ProcessFramePolarWithMask(fb.points)
 	if err != nil || mask == nil {
@@ -557,6 +904,8 @@ func (fb *analysisFrameBuilder) processCurrentFrame() {
 		return
 	}
 
+	fb.exportFrame(mask)
+
 	// Extract foreground points
 	foregroundPoints := l3grid.ExtractForegroundPoints(fb.points, mask)
 	foregroundCount := len(foregroundPoints)
@@ -570,6 +919,7 @@ func (fb *analysisFrameBuilder) processCurrentFrame() {
 	fb.frameTimestamps = append(fb.frameTimestamps, fb.frameStartTime)
 
 	if foregroundCount == 0 {
+		fb.evaluateFrame(nil)
 		if fb.benchmarkMode {
 			fb.frameTimes = append(fb.frameTimes, float64(time.Since(frameStart).Nanoseconds())/1e6)
 		}
@@ -578,7 +928,13 @@ func (fb *analysisFrameBuilder) processCurrentFrame() {
 
 	// Step 2: Transform to world frame
 	transformStart := time.Now()
-	worldPoints := l4perception.TransformToWorld(foregroundPoints, nil, fb.config.SensorID)
+	if roi := fb.config.RingROI; roi != nil && !roi.WholePipeline {
+		foregroundPoints = roi.Filter(foregroundPoints)
+	}
+	worldPoints := l4perception.TransformToWorld(foregroundPoints, fb.config.SensorPose, fb.config.SensorID)
+	// Slow-mover accumulation keeps state across frames, as in the live
+	// pipeline; a nil accumulator passes the points through.
+	worldPoints = fb.slowMover.Accumulate(worldPoints)
 	transformDuration := time.Since(transformStart)
 
 	// Step 3: Cluster (respect runtime foreground clustering params)
@@ -593,6 +949,8 @@ func (fb *analysisFrameBuilder) processCurrentFrame() {
 			dbscanParams.Eps = float64(p.ForegroundDBSCANEps)
 		}
 	}
+	dbscanParams.Workers = fb.config.ClusterWorkers
+	dbscanParams = dbscanParams.WithSensorPose(fb.config.SensorPose)
 	clusters := l4perception.DBSCAN(worldPoints, dbscanParams)
 	clusterDuration := time.Since(clusterStart)
 	if fb.benchmarkMode {
@@ -601,6 +959,7 @@ func (fb *analysisFrameBuilder) processCurrentFrame() {
 	fb.result.TotalClusters += len(clusters)
 
 	if len(clusters) == 0 {
+		fb.evaluateFrame(nil)
 		if fb.benchmarkMode {
 			fb.frameTimes = append(fb.frameTimes, float64(time.Since(frameStart).Nanoseconds())/1e6)
 		}
@@ -614,6 +973,8 @@ func (fb *analysisFrameBuilder) processCurrentFrame() {
 	if fb.benchmarkMode {
 		atomic.AddInt64(&fb.trackTimeNs, trackDuration.Nanoseconds())
 	}
+	fb.writeMOTFrame()
+	fb.evaluateFrame(fb.tracker.GetConfirmedTracks())
 
 	// Step 5: Classify confirmed tracks
 	classifyStart := time.Now()
@@ -669,9 +1030,10 @@ func (fb *analysisFrameBuilder) finalise() {
 	defer fb.mu.Unlock()
 
 	// Process final partial frame
-	if len(fb.points) > 0 {
-		fb.processCurrentFrame()
-	}
+	fb.frames.Flush()
+	fb.closePointDebug()
+	fb.closeMOTExport()
+	fb.closeEvaluation()
 }
 
 func (fb *analysisFrameBuilder) getTracker() *l5tracks.Tracker {
@@ -717,18 +1079,10 @@ func (fb *analysisFrameBuilder) getCaptureStats(result *AnalysisResult) CaptureS
 	// RPM stats — derive frame rate (Hz) directly from RPM (RPM / 60).
 	// This is more accurate than inter-frame interval timing because the
 	// azimuth-wrap frame counter over-counts for multi-return sensors.
-	if len(fb.rpmValues) > 0 {
-		stats.MinRPM = fb.rpmValues[0]
-		stats.MaxRPM = fb.rpmValues[0]
-		for _, rpm := range fb.rpmValues[1:] {
-			if rpm > 0 && (rpm < stats.MinRPM || stats.MinRPM == 0) {
-				stats.MinRPM = rpm
-			}
-			if rpm > stats.MaxRPM {
-				stats.MaxRPM = rpm
-			}
-		}
-		stats.RPMChanges = fb.rpmChanges
+	if rpm := fb.frames.RPMStats(); rpm.Samples > 0 {
+		stats.MinRPM = rpm.Min
+		stats.MaxRPM = rpm.Max
+		stats.RPMChanges = rpm.Changes
 
 		// Hz = RPM / 60
 		if stats.MinRPM > 0 {
@@ -737,18 +1091,8 @@ func (fb *analysisFrameBuilder) getCaptureStats(result *AnalysisResult) CaptureS
 		if stats.MaxRPM > 0 {
 			stats.MaxFrameRateHz = float64(stats.MaxRPM) / 60.0
 		}
-		// Compute true average Hz from all recorded RPM samples.
-		var rpmSum float64
-		var rpmCount int
-		for _, rpm := range fb.rpmValues {
-			if rpm > 0 {
-				rpmSum += float64(rpm)
-				rpmCount++
-			}
-		}
-		if rpmCount > 0 {
-			stats.AvgFrameRateHz = (rpmSum / float64(rpmCount)) / 60.0
-		}
+		// True average Hz from all recorded non-zero RPM samples.
+		stats.AvgFrameRateHz = rpm.Mean / 60.0
 	}
 
 	// Compute 10-second frame-rate buckets from per-frame PCAP timestamps
@@ -829,11 +1173,18 @@ func collectTrackResults(frameBuilder *analysisFrameBuilder, result *AnalysisRes
 	tracker := frameBuilder.getTracker()
 	classifier := frameBuilder.getClassifier()
 	allTracks := tracker.GetAllTracks()
+	minDuration := frameBuilder.config.MinDuration
+	fallback := frameBuilder.config.FallbackClasses
+	reportClass := func(track *l5tracks.TrackedObject) string {
+		if fallback != nil {
+			return fallback.ReportClass(track)
+		}
+		return l6objects.CombinedClass(track.ObjectClass)
+	}
 
-	result.TotalTracks = len(allTracks)
 	result.Tracks = make([]*TrackExport, 0, len(allTracks))
-
-	var speedSamples []float32
+	reported := make([]*l5tracks.TrackedObject, 0, len(allTracks))
+	var speedLimitTags []l6objects.SpeedLimitTag
 
 	for _, track := range allTracks {
 		// Classify if not already done
@@ -841,15 +1192,24 @@ func collectTrackResults(frameBuilder *analysisFrameBuilder, result *AnalysisRes
 			classifier.ClassifyAndUpdate(track)
 		}
 
-		if track.TrackState == l5tracks.TrackConfirmed {
-			result.ConfirmedTracks++
-		}
+		class := reportClass(track)
 
-		class := track.ObjectClass
-		if class == "" {
-			class = "other"
+		flicker := minDuration != nil && minDuration.IsFlicker(track)
+		if flicker {
+			if result.FlickerTracks == nil {
+				result.FlickerTracks = make(map[string]int)
+			}
+			result.FlickerTracks[class]++
+			if !frameBuilder.config.IncludeFlicker {
+				continue
+			}
+		} else {
+			reported = append(reported, track)
+			if track.TrackState == l5tracks.TrackConfirmed {
+				result.ConfirmedTracks++
+			}
+			result.TracksByClass[class]++
 		}
-		result.TracksByClass[class]++
 
 		trackExport := &TrackExport{
 			TrackID:      track.TrackID,
@@ -867,17 +1227,51 @@ func collectTrackResults(frameBuilder *analysisFrameBuilder, result *AnalysisRes
 			HeightP95Max: track.HeightP95Max,
 			StartX:       track.X,
 			StartY:       track.Y,
+
+			CompositeSpeedMps:    track.CompositeSpeedMps,
+			DetectionReliability: track.DetectionReliability(),
+			Confirmation:         track.Confirmation,
+			Features:             l6objects.TrackFeatureVector(track),
+			Flicker:              flicker,
+		}
+		if axis := frameBuilder.config.RoadAxis; axis != nil {
+			vx, vy := meanTrackVelocity(track)
+			rv := axis.Decompose(vx, vy)
+			trackExport.RoadVelocity = &rv
+		}
+		if tagger := frameBuilder.config.SpeedLimits; tagger != nil && !flicker {
+			if tag, ok := tagger.Tag(track); ok {
+				trackExport.SpeedLimit = &tag
+				speedLimitTags = append(speedLimitTags, tag)
+			}
+		}
+		if od := frameBuilder.config.ODMatrix; od != nil && !flicker {
+			if trip, ok := od.Record(track); ok {
+				trackExport.Origin, trackExport.Destination = trip.Origin, trip.Destination
+				result.ODTrips++
+			}
 		}
 		result.Tracks = append(result.Tracks, trackExport)
+	}
 
-		if track.AvgSpeedMps > 0 {
-			speedSamples = append(speedSamples, track.AvgSpeedMps)
-		}
+	if frameBuilder.config.SpeedLimits != nil {
+		summary := l6objects.SummariseSpeedLimitTags(speedLimitTags)
+		result.SpeedLimits = &summary
 	}
 
 	// Compute classification distribution and speed statistics
-	result.ClassificationDist = computeClassStats(result.Tracks)
-	result.SpeedStats = computeSpeedStats(speedSamples)
+	result.TotalTracks = len(reported)
+	result.ConfirmationLatency = tracker.ConfirmationLatencySummary()
+	result.ClassificationDist = l6objects.ComputeClassStatsBy(reported, reportClass)
+	if fallback != nil {
+		// The combined view folds unknown and noise back into "other".
+		result.CombinedByClass = make(map[string]int, len(result.TracksByClass))
+		for class, n := range result.TracksByClass {
+			result.CombinedByClass[l6objects.CombinedClass(class)] += n
+		}
+		result.CombinedClassDist = l6objects.ComputeClassStats(reported)
+	}
+	result.SpeedStats = l6objects.ComputeSpeedStatistics(l6objects.TrackSpeedSamples(reported))
 
 	return allTracks
 }
@@ -889,6 +1283,7 @@ func analyzePCAP(config Config) (*AnalysisResult, error) {
 	parserConfig, _ := parse.LoadEmbeddedPandar40PConfig()
 	parser := parse.NewPandar40PParser(*parserConfig)
 	parser.SetTimestampMode(parse.TimestampModeSystemTime)
+	parser.SetReturnSelection(config.ReturnSelection)
 
 	// Result tracking
 	result := &AnalysisResult{
@@ -899,13 +1294,28 @@ func analyzePCAP(config Config) (*AnalysisResult, error) {
 	// Create analysis-specific frame builder that processes tracking pipeline
 	stats := &analysisStats{}
 	frameBuilder := newAnalysisFrameBuilder(config, result)
+	clipped, clipper, err := withRangeClip(frameBuilder)
+	if err != nil {
+		return nil, err
+	}
+	input, noise, err := withNoise(config.Noise, clipped)
+	if err != nil {
+		return nil, err
+	}
+	packetParser, filter, err := withPacketFilter(config.PacketFilter, parser)
+	if err != nil {
+		return nil, err
+	}
 
 	// Use shared PCAP reading infrastructure from internal/lidar/network
 	// No forwarder needed for offline analysis
 	ctx := context.Background()
-	if err := network.ReadPCAPFile(ctx, config.PCAPFile, config.UDPPort, parser, frameBuilder, stats, nil, 0, -1, 0, 0, nil); err != nil {
+	if err := network.ReadPCAPFile(ctx, config.PCAPFile, config.UDPPort, packetParser, input, stats, nil, 0, -1, 0, 0, nil); err != nil {
 		return nil, fmt.Errorf("failed to read PCAP: %w", err)
 	}
+	logNoiseCounts(noise)
+	result.SkippedPackets = skippedPackets(filter)
+	result.ClippedNearPoints, result.ClippedFarPoints = clippedPoints(clipper)
 
 	// Finalise any remaining frame data
 	frameBuilder.finalise()
@@ -919,6 +1329,7 @@ func analyzePCAP(config Config) (*AnalysisResult, error) {
 
 	// Collect track results using shared helper
 	allTracks := collectTrackResults(frameBuilder, result)
+	frameBuilder.closeClassExplanations()
 
 	// Processing time
 	result.ProcessingTimeMs = time.Since(startTime).Milliseconds()
@@ -926,6 +1337,7 @@ func analyzePCAP(config Config) (*AnalysisResult, error) {
 	// Training frame count
 	trainingFrames := frameBuilder.getTrainingFrames()
 	result.TrainingFrames = len(trainingFrames)
+	result.ExportedFrames = frameBuilder.exportedFrames
 
 	// Persist to DB if requested
 	if config.DBPath != "" {
@@ -968,6 +1380,7 @@ func analyzePCAPWithBenchmark(config Config) (*AnalysisResult, *PerformanceMetri
 	parserConfig, _ := parse.LoadEmbeddedPandar40PConfig()
 	parser := parse.NewPandar40PParser(*parserConfig)
 	parser.SetTimestampMode(parse.TimestampModeSystemTime)
+	parser.SetReturnSelection(config.ReturnSelection)
 
 	parseStart := time.Now()
 
@@ -980,12 +1393,27 @@ func analyzePCAPWithBenchmark(config Config) (*AnalysisResult, *PerformanceMetri
 	// Create analysis-specific frame builder that processes tracking pipeline
 	stats := &analysisStats{}
 	frameBuilder := newAnalysisFrameBuilder(config, result)
+	clipped, clipper, err := withRangeClip(frameBuilder)
+	if err != nil {
+		return nil, nil, err
+	}
+	input, noise, err := withNoise(config.Noise, clipped)
+	if err != nil {
+		return nil, nil, err
+	}
+	packetParser, filter, err := withPacketFilter(config.PacketFilter, parser)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Use shared PCAP reading infrastructure from internal/lidar/network
 	ctx := context.Background()
-	if err := network.ReadPCAPFile(ctx, config.PCAPFile, config.UDPPort, parser, frameBuilder, stats, nil, 0, -1, 0, 0, nil); err != nil {
+	if err := network.ReadPCAPFile(ctx, config.PCAPFile, config.UDPPort, packetParser, input, stats, nil, 0, -1, 0, 0, nil); err != nil {
 		return nil, nil, fmt.Errorf("failed to read PCAP: %w", err)
 	}
+	logNoiseCounts(noise)
+	result.SkippedPackets = skippedPackets(filter)
+	result.ClippedNearPoints, result.ClippedFarPoints = clippedPoints(clipper)
 
 	// Finalise any remaining frame data
 	frameBuilder.finalise()
@@ -1001,12 +1429,14 @@ func analyzePCAPWithBenchmark(config Config) (*AnalysisResult, *PerformanceMetri
 
 	// Collect track results using shared helper
 	allTracks := collectTrackResults(frameBuilder, result)
+	frameBuilder.closeClassExplanations()
 
 	wallClockMs := time.Since(startTime).Milliseconds()
 	result.ProcessingTimeMs = wallClockMs
 
 	trainingFrames := frameBuilder.getTrainingFrames()
 	result.TrainingFrames = len(trainingFrames)
+	result.ExportedFrames = frameBuilder.exportedFrames
 
 	// Collect memory stats BEFORE persistence/export operations
 	// to get accurate LIDAR pipeline memory footprint
@@ -1066,7 +1496,79 @@ func analyzePCAPWithBenchmark(config Config) (*AnalysisResult, *PerformanceMetri
 	return result, metrics, nil
 }
 
-func createBackgroundManager(sensorID string, store l3grid.BgStore) *l3grid.BackgroundManager {
+// withNoise inserts a seeded noise injector ahead of frame assembly when
+// any perturbation is configured. The returned injector is nil otherwise.
+func withNoise(cfg network.NoiseConfig, fb network.FrameBuilder) (network.FrameBuilder, *network.NoiseInjector, error) {
+	if !cfg.Enabled() {
+		return fb, nil, nil
+	}
+	inj, err := network.NewNoiseInjector(fb, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid noise config: %w", err)
+	}
+	log.Printf("Noise injection enabled: seed=%d dropout=%.3f jitter=%.3fm spurious=%.3f [%.1f, %.1f]m",
+		cfg.Seed, cfg.DropoutRate, cfg.RangeJitterM, cfg.SpuriousRate, cfg.SpuriousMinRangeM, cfg.SpuriousMaxRangeM)
+	return inj, inj, nil
+}
+
+// withRangeClip puts range clipping from the L1 tuning defaults in front of
+// frame assembly, as the radar binary does. The returned clipper is nil when
+// no bound is configured.
+func withRangeClip(fb network.FrameBuilder) (network.FrameBuilder, *network.RangeClipper, error) {
+	cfg := network.RangeClipConfigFromTuning(cfgpkg.MustLoadDefaultConfig().L1)
+	if !cfg.Enabled() {
+		return fb, nil, nil
+	}
+	clipper, err := network.NewRangeClipper(fb, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid range clipping: %w", err)
+	}
+	log.Printf("Range clipping enabled: [%.2f, %.2f] m, %d per-ring overrides",
+		cfg.Global.MinM, cfg.Global.MaxM, len(cfg.PerRing))
+	return clipper, clipper, nil
+}
+
+// clippedPoints returns how many returns the clipper dropped as too near and
+// too far, or zeros without one.
+func clippedPoints(clipper *network.RangeClipper) (near, far int64) {
+	if clipper == nil {
+		return 0, 0
+	}
+	return clipper.Counts()
+}
+
+// withPacketFilter puts the Pandar40P signature filter in front of the
+// parser when enabled. The returned filter is nil otherwise.
+func withPacketFilter(enabled bool, parser network.Parser) (network.Parser, *network.SignatureFilter, error) {
+	if !enabled {
+		return parser, nil, nil
+	}
+	filter, err := network.NewSignatureFilter(parser, network.Pandar40PSignature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid packet filter: %w", err)
+	}
+	return filter, filter, nil
+}
+
+// skippedPackets returns the packets the filter kept from the parser, or
+// zero without one.
+func skippedPackets(filter *network.SignatureFilter) int64 {
+	if filter == nil {
+		return 0
+	}
+	_, skipped := filter.Counts()
+	return skipped
+}
+
+func logNoiseCounts(inj *network.NoiseInjector) {
+	if inj == nil {
+		return
+	}
+	dropped, spurious := inj.Counts()
+	log.Printf("Noise injection: dropped %d points, added %d spurious points", dropped, spurious)
+}
+
+func createBackgroundManager(sensorID string, seedFromFirst bool, store l3grid.BgStore) *l3grid.BackgroundManager {
 	// Use NewBackgroundManager to ensure proper initialization including
 	// region persistence/restoration when a store is provided.
 	params := l3grid.BackgroundParams{
@@ -1075,7 +1577,7 @@ func createBackgroundManager(sensorID string, store l3grid.BgStore) *l3grid.Back
 		SafetyMarginMetres:             0.5,
 		NeighbourConfirmationCount:     3,
 		NoiseRelativeFraction:          0.315,
-		SeedFromFirstObservation:       true, // Important for PCAP replay
+		SeedFromFirstObservation:       seedFromFirst, // Important for PCAP replay
 		FreezeDurationNanos:            int64(5 * time.Second),
 		// Enable region identification for PCAP analysis
 		WarmupMinFrames:     100,
@@ -1087,63 +1589,6 @@ func createBackgroundManager(sensorID string, store l3grid.BgStore) *l3grid.Back
 	return l3grid.NewBackgroundManager(sensorID, 40, 1800, params, store)
 }
 
-func computeClassStats(tracks []*TrackExport) map[string]ClassStats {
-	stats := make(map[string]ClassStats)
-	byClass := make(map[string][]*TrackExport)
-
-	for _, t := range tracks {
-		byClass[t.Class] = append(byClass[t.Class], t)
-	}
-
-	for class, classTracks := range byClass {
-		var sumSpeed, sumDuration float32
-		var sumObs int
-		for _, t := range classTracks {
-			sumSpeed += t.AvgSpeedMps
-			sumDuration += float32(t.DurationSecs)
-			sumObs += t.Observations
-		}
-		n := float32(len(classTracks))
-		stats[class] = ClassStats{
-			Count:           len(classTracks),
-			AvgSpeed:        sumSpeed / n,
-			AvgDuration:     sumDuration / n,
-			AvgObservations: float32(sumObs) / n,
-		}
-	}
-
-	return stats
-}
-
-func computeSpeedStats(samples []float32) SpeedStatistics {
-	if len(samples) == 0 {
-		return SpeedStatistics{}
-	}
-
-	// Sort for min/max and average
-	sorted := make([]float32, len(samples))
-	copy(sorted, samples)
-	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
-
-	var sum float32
-	for _, s := range sorted {
-		sum += s
-	}
-
-	// Use the shared percentile computation from lidar package
-	p50, p85, p95 := l6objects.ComputeSpeedPercentiles(samples)
-
-	n := len(sorted)
-	return SpeedStatistics{
-		MinSpeed: sorted[0],
-		MaxSpeed: sorted[n-1],
-		AvgSpeed: sum / float32(n),
-		P50Speed: p50,
-		P85Speed: p85,
-		P95Speed: p95,
-	}
-}
-
 func printSummary(result *AnalysisResult) {
 	fmt.Println("\n========== PCAP Analysis Summary ==========")
 	fmt.Printf("File: %s\n", result.PCAPFile)
@@ -1151,6 +1596,12 @@ func printSummary(result *AnalysisResult) {
 	fmt.Printf("Processing time: %d ms\n", result.ProcessingTimeMs)
 	fmt.Println()
 	fmt.Printf("Packets: %d\n", result.TotalPackets)
+	if result.SkippedPackets > 0 {
+		fmt.Printf("Non-LiDAR packets skipped: %d\n", result.SkippedPackets)
+	}
+	if result.ClippedNearPoints > 0 || result.ClippedFarPoints > 0 {
+		fmt.Printf("Returns clipped by range: %d too near, %d too far\n", result.ClippedNearPoints, result.ClippedFarPoints)
+	}
 	fmt.Printf("Points: %d total, %d foreground (%.1f%%), %d background\n",
 		result.TotalPoints, result.ForegroundPoints,
 		100*float64(result.ForegroundPoints)/float64(result.TotalPoints),
@@ -1159,16 +1610,48 @@ func printSummary(result *AnalysisResult) {
 	fmt.Printf("Clusters: %d\n", result.TotalClusters)
 	fmt.Println()
 	fmt.Printf("Tracks: %d total, %d confirmed\n", result.TotalTracks, result.ConfirmedTracks)
+	if cl := result.ConfirmationLatency; cl != nil {
+		fmt.Printf("Confirmation (HitsToConfirm=%d): median %d frames, p95 %d, max %d; mean %.2f s, max %.2f s\n",
+			cl.HitsToConfirm, cl.P50Frames, cl.P95Frames, cl.MaxFrames, cl.MeanSecs, cl.MaxSecs)
+		if cl.EntryEstimated > 0 {
+			fmt.Printf("  Entry to first detection (%d tracks born in a birth zone): mean %.2f s, max %.2f s\n",
+				cl.EntryEstimated, cl.MeanEntrySecs, cl.MaxEntrySecs)
+		}
+	}
 	fmt.Println("\nTracks by Class:")
-	for class, count := range result.TracksByClass {
+	classes := make([]string, 0, len(result.TracksByClass))
+	for class := range result.TracksByClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		count := result.TracksByClass[class]
 		pct := 100 * float64(count) / float64(result.TotalTracks)
 		fmt.Printf("  %s: %d (%.1f%%)\n", class, count, pct)
 	}
+	if n := result.CombinedByClass["other"]; n > 0 {
+		fmt.Printf("  (combined other: %d = %d unknown + %d noise)\n",
+			n, result.TracksByClass["unknown"], result.TracksByClass["noise"])
+	}
 	fmt.Println("\nSpeed Statistics (confirmed tracks):")
 	fmt.Printf("  Min: %.2f m/s (%.1f km/h)\n", result.SpeedStats.MinSpeed, result.SpeedStats.MinSpeed*3.6)
 	fmt.Printf("  Max: %.2f m/s (%.1f km/h)\n", result.SpeedStats.MaxSpeed, result.SpeedStats.MaxSpeed*3.6)
 	fmt.Printf("  Avg: %.2f m/s (%.1f km/h)\n", result.SpeedStats.AvgSpeed, result.SpeedStats.AvgSpeed*3.6)
 	fmt.Printf("  P85: %.2f m/s (%.1f km/h)\n", result.SpeedStats.P85Speed, result.SpeedStats.P85Speed*3.6)
+	if sl := result.SpeedLimits; sl != nil {
+		fmt.Printf("\nSpeed Limits: %d vehicles tagged, %d over the limit\n", sl.Tagged, sl.OverLimit)
+		dirs := make([]string, 0, len(sl.ByDirection))
+		for dir := range sl.ByDirection {
+			if dir != "" {
+				dirs = append(dirs, dir)
+			}
+		}
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			c := sl.ByDirection[dir]
+			fmt.Printf("  %s: %d over of %d\n", dir, c.OverLimit, c.Tagged)
+		}
+	}
 	fmt.Println()
 	if result.TrainingFrames > 0 {
 		fmt.Printf("Training frames exported: %d\n", result.TrainingFrames)
@@ -1177,7 +1660,7 @@ func printSummary(result *AnalysisResult) {
 }
 
 func exportResults(config Config, result *AnalysisResult) error {
-	baseName := strings.TrimSuffix(filepath.Base(config.PCAPFile), filepath.Ext(config.PCAPFile))
+	baseName := network.PCAPBaseName(config.PCAPFile)
 
 	// Export JSON
 	if config.ExportJSON {
@@ -1201,9 +1684,60 @@ func exportResults(config Config, result *AnalysisResult) error {
 		fmt.Printf("CSV tracks: %s\n", csvPath)
 	}
 
+	if config.pointDebugEnabled() {
+		fmt.Printf("Point debug CSV: %s (%d rows)\n", pointDebugPath(config), result.PointDebugRows)
+	}
+
+	if config.ExportMOT {
+		fmt.Printf("MOT tracks: %s (%d rows, IDs in %s)\n", motPath(config), result.MOTRows, motIDsPath(config))
+	}
+
+	if result.ClassExplanations > 0 {
+		fmt.Printf("Class explanations: %s (%d decisions)\n", classExplanationsPath(config), result.ClassExplanations)
+	}
+
+	if e := result.Evaluation; e != nil {
+		fmt.Printf("Evaluation: %s (MOTA %.3f, MOTP %.2f m, %d misses, %d false positives, %d ID switches over %d frames)\n",
+			evaluationPath(config), e.MOTA, e.MOTPMetres, e.Misses, e.FalsePositives, e.IDSwitches, e.Frames)
+	}
+
+	if config.ODMatrix != nil {
+		odPath := filepath.Join(config.OutputDir, baseName+"_od_matrix.csv")
+		if err := exportODMatrixCSV(odPath, config.ODMatrix); err != nil {
+			return fmt.Errorf("write OD matrix CSV: %w", err)
+		}
+		fmt.Printf("OD matrix: %s (%d trips)\n", odPath, result.ODTrips)
+	}
+
+	if config.ExportFrames != nil {
+		fmt.Printf("Frame point clouds: %s (%d frames in %s)\n",
+			filepath.Join(config.OutputDir, "frames"), result.ExportedFrames, config.ExportFrames)
+	}
+
+	// Export feature vectors
+	if config.ExportFeatures && len(result.Tracks) > 0 {
+		featuresPath := filepath.Join(config.OutputDir, baseName+"_features.csv")
+		if err := exportFeaturesCSV(featuresPath, result.Tracks); err != nil {
+			return fmt.Errorf("write features CSV: %w", err)
+		}
+		fmt.Printf("CSV features: %s\n", featuresPath)
+	}
+
 	return nil
 }
 
+func exportODMatrixCSV(path string, matrix *l6objects.ODMatrix) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := matrix.WriteCSV(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func exportTracksCSV(path string, tracks []*TrackExport) error {
 	f, err := os.Create(path)
 	if err != nil {
@@ -1219,6 +1753,9 @@ func exportTracksCSV(path string, tracks []*TrackExport) error {
 		"track_id", "class", "confidence", "start_time", "end_time",
 		"duration_secs", "observations", "avg_speed_mps", "max_speed_mps",
 		"avg_height_m", "avg_length_m", "avg_width_m", "height_p95_max_m",
+		"detection_reliability", "composite_speed_mps",
+		"speed_zone", "speed_limit", "limit_speed", "limit_margin", "over_limit", "direction",
+		"flicker", "along_road_mps", "cross_road_mps",
 	}
 	if err := w.Write(header); err != nil {
 		return err
@@ -1240,6 +1777,17 @@ func exportTracksCSV(path string, tracks []*TrackExport) error {
 			strconv.FormatFloat(float64(t.AvgLength), 'f', 3, 32),
 			strconv.FormatFloat(float64(t.AvgWidth), 'f', 3, 32),
 			strconv.FormatFloat(float64(t.HeightP95Max), 'f', 3, 32),
+			strconv.FormatFloat(float64(t.DetectionReliability), 'f', 3, 32),
+			strconv.FormatFloat(float64(t.CompositeSpeedMps), 'f', 2, 32),
+		}
+		row = append(row, speedLimitColumns(t.SpeedLimit)...)
+		row = append(row, strconv.FormatBool(t.Flicker))
+		if rv := t.RoadVelocity; rv != nil {
+			row = append(row,
+				strconv.FormatFloat(float64(rv.AlongMps), 'f', 2, 32),
+				strconv.FormatFloat(float64(rv.CrossMps), 'f', 2, 32))
+		} else {
+			row = append(row, "", "")
 		}
 		if err := w.Write(row); err != nil {
 			return err
@@ -1249,6 +1797,79 @@ func exportTracksCSV(path string, tracks []*TrackExport) error {
 	return nil
 }
 
+// meanTrackVelocity returns the track's mean velocity: net displacement
+// between its first and last history points over the time between them.
+// Tracks without a usable history fall back to the current estimate.
+func meanTrackVelocity(track *l5tracks.TrackedObject) (vx, vy float32) {
+	if n := len(track.History); n >= 2 {
+		first, last := track.History[0], track.History[n-1]
+		if dt := float32(last.Timestamp-first.Timestamp) / 1e9; dt > 0 {
+			return (last.X - first.X) / dt, (last.Y - first.Y) / dt
+		}
+	}
+	return track.VX, track.VY
+}
+
+// speedLimitColumns returns the speed-limit CSV columns for a track: zone,
+// limit, compared speed and margin in the configured units, over_limit and
+// direction. They are empty for untagged tracks.
+func speedLimitColumns(tag *l6objects.SpeedLimitTag) []string {
+	if tag == nil {
+		return make([]string, 6)
+	}
+	return []string{
+		tag.Zone,
+		strconv.FormatFloat(tag.Limit, 'f', 1, 64),
+		strconv.FormatFloat(tag.Speed, 'f', 1, 64),
+		strconv.FormatFloat(tag.Margin, 'f', 1, 64),
+		strconv.FormatBool(tag.OverLimit),
+		tag.Direction,
+	}
+}
+
+// exportFeaturesCSV writes one row per track: its identity and assigned
+// class as the label, then the feature vector under the column names from
+// l6objects.FeatureVectorColumns.
+func exportFeaturesCSV(path string, tracks []*TrackExport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	columns := l6objects.FeatureVectorColumns()
+	header := append([]string{"track_id", "class", "confidence", "feature_version"}, columns...)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	version := strconv.Itoa(l6objects.FeatureVectorVersion)
+	for _, t := range tracks {
+		if len(t.Features) != len(columns) {
+			return fmt.Errorf("track %s has %d features, want %d", t.TrackID, len(t.Features), len(columns))
+		}
+		row := make([]string, 0, len(header))
+		row = append(row,
+			t.TrackID,
+			t.Class,
+			strconv.FormatFloat(float64(t.Confidence), 'f', 3, 32),
+			version,
+		)
+		for _, v := range t.Features {
+			row = append(row, strconv.FormatFloat(float64(v), 'g', -1, 32))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
 func exportTrainingData(outputDir string, frames []*TrainingFrame) error {
 	trainingDir := filepath.Join(outputDir, "training_data")
 	if err := os.MkdirAll(trainingDir, 0755); err != nil {
@@ -1447,7 +2068,7 @@ func handleBenchmarkOutput(config Config, result *AnalysisResult, metrics *Perfo
 	// Determine output path
 	outputPath := config.BenchmarkOutput
 	if outputPath == "" {
-		baseName := strings.TrimSuffix(filepath.Base(config.PCAPFile), filepath.Ext(config.PCAPFile))
+		baseName := network.PCAPBaseName(config.PCAPFile)
 		outputPath = filepath.Join(config.OutputDir, baseName+"_benchmark.json")
 	}
 
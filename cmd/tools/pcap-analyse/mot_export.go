@@ -0,0 +1,79 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/adapters"
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+)
+
+// motPath is <output>/<pcap>_mot.txt; motIDsPath is the matching
+// <output>/<pcap>_mot_ids.csv that maps MOT integer IDs to track IDs.
+func motPath(config Config) string {
+	return filepath.Join(config.OutputDir, network.PCAPBaseName(config.PCAPFile)+"_mot.txt")
+}
+
+func motIDsPath(config Config) string {
+	return filepath.Join(config.OutputDir, network.PCAPBaseName(config.PCAPFile)+"_mot_ids.csv")
+}
+
+// motExport holds the open -mot result file.
+type motExport struct {
+	file   *os.File
+	writer *adapters.MOTWriter
+}
+
+// startMOTExport opens the MOTChallenge result file.
+func (fb *analysisFrameBuilder) startMOTExport() error {
+	f, err := os.Create(motPath(fb.config))
+	if err != nil {
+		return fmt.Errorf("create MOT file: %w", err)
+	}
+	fb.mot = &motExport{file: f, writer: adapters.NewMOTWriter(f)}
+	return nil
+}
+
+// writeMOTFrame writes the confirmed tracks after this frame's tracker
+// update. MOT frame numbers are the analysis frame index plus one, so they
+// line up with -export-frames indices offset by one.
+func (fb *analysisFrameBuilder) writeMOTFrame() {
+	if fb.mot == nil {
+		return
+	}
+	if err := fb.mot.writer.WriteFrame(fb.frameCount+1, fb.tracker.GetConfirmedTracks()); err != nil {
+		log.Printf("[WARN] MOT export: %v", err)
+	}
+}
+
+// closeMOTExport flushes and closes the MOT file, if open, and writes the
+// ID map next to it.
+func (fb *analysisFrameBuilder) closeMOTExport() {
+	m := fb.mot
+	if m == nil {
+		return
+	}
+	fb.mot = nil
+	if err := m.writer.Flush(); err != nil {
+		log.Printf("[WARN] MOT export: %v", err)
+	}
+	if err := m.file.Close(); err != nil {
+		log.Printf("[WARN] MOT export: %v", err)
+	}
+	fb.result.MOTRows = m.writer.Rows()
+
+	ids, err := os.Create(motIDsPath(fb.config))
+	if err != nil {
+		log.Printf("[WARN] MOT ID map: %v", err)
+		return
+	}
+	defer ids.Close()
+	if err := m.writer.WriteIDMap(ids); err != nil {
+		log.Printf("[WARN] MOT ID map: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func TestMOTExport_WritesConfirmedTracksAndIDMap(t *testing.T) {
+	tracks := map[string]*l5tracks.TrackedObject{
+		"trk_a": {
+			TrackID:          "trk_a",
+			TrackMeasurement: l5tracks.TrackMeasurement{TrackState: l5tracks.TrackConfirmed},
+			X:                10, Y: 2, OBBLength: 4, OBBWidth: 2,
+		},
+		"trk_b": {
+			TrackID:          "trk_b",
+			TrackMeasurement: l5tracks.TrackMeasurement{TrackState: l5tracks.TrackTentative},
+		},
+	}
+	fb := makeFrameBuilder(tracks)
+	fb.config = Config{PCAPFile: "capture.pcap", OutputDir: t.TempDir(), ExportMOT: true}
+	fb.result = newResult()
+
+	if err := fb.startMOTExport(); err != nil {
+		t.Fatalf("startMOTExport: %v", err)
+	}
+	for fb.frameCount = 0; fb.frameCount < 2; fb.frameCount++ {
+		fb.writeMOTFrame()
+	}
+	fb.closeMOTExport()
+
+	data, err := os.ReadFile(motPath(fb.config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "1,1,8.000,1.000,4.000,2.000,1.000,10.000,2.000,0.000\n" +
+		"2,1,8.000,1.000,4.000,2.000,1.000,10.000,2.000,0.000\n"
+	if string(data) != want {
+		t.Errorf("MOT file:\n%s\nwant:\n%s", data, want)
+	}
+	if fb.result.MOTRows != 2 {
+		t.Errorf("expected 2 MOT rows counted, got %d", fb.result.MOTRows)
+	}
+
+	ids, err := os.ReadFile(motIDsPath(fb.config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(ids), "1,trk_a") || strings.Contains(string(ids), "trk_b") {
+		t.Errorf("unexpected ID map %q", ids)
+	}
+}
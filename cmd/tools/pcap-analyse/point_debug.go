@@ -0,0 +1,118 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+)
+
+// pointDebugHeader is the column list of the -debug-ring/-debug-az CSV.
+var pointDebugHeader = []string{
+	"frame", "timestamp_unix_nanos", "ring", "azimuth_deg", "range_m",
+	"bg_range_m", "bg_spread_m", "threshold_m", "times_seen", "frozen", "foreground",
+}
+
+// pointDebugEnabled reports whether a ring/azimuth debug box is set. As in
+// the monitor's PCAP form, zero on both ends of a pair leaves that axis
+// unrestricted.
+func (c Config) pointDebugEnabled() bool {
+	return c.DebugRingMin > 0 || c.DebugRingMax > 0 || c.DebugAzMin > 0 || c.DebugAzMax > 0
+}
+
+// validatePointDebug checks each configured pair is ordered.
+func (c Config) validatePointDebug() error {
+	if (c.DebugRingMin > 0 || c.DebugRingMax > 0) && c.DebugRingMin > c.DebugRingMax {
+		return fmt.Errorf("-debug-ring-min %d is above -debug-ring-max %d", c.DebugRingMin, c.DebugRingMax)
+	}
+	if (c.DebugAzMin > 0 || c.DebugAzMax > 0) && c.DebugAzMin > c.DebugAzMax {
+		return fmt.Errorf("-debug-az-min %.1f is above -debug-az-max %.1f", c.DebugAzMin, c.DebugAzMax)
+	}
+	return nil
+}
+
+// pointDebugPath is <output>/<pcap>_point_debug.csv.
+func pointDebugPath(config Config) string {
+	return filepath.Join(config.OutputDir, network.PCAPBaseName(config.PCAPFile)+"_point_debug.csv")
+}
+
+// pointDebugWriter writes one CSV row per point in the debug box.
+type pointDebugWriter struct {
+	path string
+	file *os.File
+	csv  *csv.Writer
+	rows int
+}
+
+// startPointDebug opens the debug CSV and hooks the background manager so
+// every point in the box is logged with its raw range, the cell background
+// it was compared against and the foreground decision.
+func (fb *analysisFrameBuilder) startPointDebug() error {
+	path := pointDebugPath(fb.config)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create point debug CSV: %w", err)
+	}
+	w := &pointDebugWriter{path: path, file: f, csv: csv.NewWriter(f)}
+	if err := w.csv.Write(pointDebugHeader); err != nil {
+		f.Close()
+		return fmt.Errorf("write point debug CSV: %w", err)
+	}
+
+	params := fb.bgManager.GetParams()
+	params.DebugRingMin, params.DebugRingMax = fb.config.DebugRingMin, fb.config.DebugRingMax
+	params.DebugAzMin, params.DebugAzMax = float32(fb.config.DebugAzMin), float32(fb.config.DebugAzMax)
+	if err := fb.bgManager.SetParams(params); err != nil {
+		f.Close()
+		return err
+	}
+	// The callback runs inside processCurrentFrame, under fb.mu.
+	fb.bgManager.PointDebugCallback = fb.writePointDebug
+	fb.pointDebug = w
+	return nil
+}
+
+func (fb *analysisFrameBuilder) writePointDebug(rec l3grid.PointDebugRecord) {
+	w := fb.pointDebug
+	_ = w.csv.Write([]string{
+		strconv.Itoa(fb.frameCount),
+		strconv.FormatInt(fb.frameStartTime.UnixNano(), 10),
+		strconv.Itoa(rec.Ring),
+		strconv.FormatFloat(rec.AzimuthDeg, 'f', 3, 64),
+		strconv.FormatFloat(rec.RangeM, 'f', 3, 64),
+		strconv.FormatFloat(float64(rec.BackgroundRangeM), 'f', 3, 32),
+		strconv.FormatFloat(float64(rec.SpreadM), 'f', 3, 32),
+		strconv.FormatFloat(rec.ThresholdM, 'f', 3, 64),
+		strconv.FormatUint(uint64(rec.TimesSeen), 10),
+		strconv.FormatBool(rec.Frozen),
+		strconv.FormatBool(rec.Foreground),
+	})
+	w.rows++
+}
+
+// closePointDebug flushes and closes the debug CSV, if open.
+func (fb *analysisFrameBuilder) closePointDebug() {
+	w := fb.pointDebug
+	if w == nil {
+		return
+	}
+	fb.bgManager.PointDebugCallback = nil
+	fb.pointDebug = nil
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		log.Printf("[WARN] point debug CSV: %v", err)
+	}
+	if err := w.file.Close(); err != nil {
+		log.Printf("[WARN] point debug CSV: %v", err)
+	}
+	fb.result.PointDebugRows = w.rows
+	log.Printf("Point debug: %d rows written to %s", w.rows, w.path)
+}
@@ -0,0 +1,83 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+func TestValidatePointDebug(t *testing.T) {
+	for _, c := range []Config{
+		{},
+		{DebugRingMin: 3, DebugRingMax: 5},
+		{DebugAzMin: 0, DebugAzMax: 90},
+	} {
+		if err := c.validatePointDebug(); err != nil {
+			t.Errorf("%+v: unexpected error %v", c, err)
+		}
+	}
+	for _, c := range []Config{
+		{DebugRingMin: 5, DebugRingMax: 3},
+		{DebugRingMin: 2},
+		{DebugAzMin: 90, DebugAzMax: 10},
+	} {
+		if err := c.validatePointDebug(); err == nil {
+			t.Errorf("%+v: expected an error", c)
+		}
+	}
+	if (Config{}).pointDebugEnabled() || !(Config{DebugAzMax: 10}).pointDebugEnabled() {
+		t.Error("pointDebugEnabled should only be true with a box set")
+	}
+}
+
+func TestPointDebug_WritesPointsInBox(t *testing.T) {
+	config := Config{
+		PCAPFile: "capture.pcap", OutputDir: t.TempDir(), SensorID: "test-sensor",
+		DebugRingMin: 1, DebugRingMax: 1, DebugAzMin: 80, DebugAzMax: 100,
+	}
+	fb := &analysisFrameBuilder{
+		bgManager:      createBackgroundManager("point-debug-test", true, nil),
+		config:         config,
+		result:         &AnalysisResult{},
+		frameStartTime: time.Unix(1700000000, 0),
+	}
+	if err := fb.startPointDebug(); err != nil {
+		t.Fatalf("startPointDebug: %v", err)
+	}
+	points := []l2frames.PointPolar{
+		{Channel: 2, Azimuth: 90, Distance: 12},  // ring 1, in the box
+		{Channel: 2, Azimuth: 120, Distance: 12}, // azimuth outside
+		{Channel: 3, Azimuth: 90, Distance: 12},  // ring outside
+	}
+	for fb.frameCount = 0; fb.frameCount < 3; fb.frameCount++ {
+		if _, err := fb.bgManager.ProcessFramePolarWithMask(points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fb.closePointDebug()
+
+	f, err := os.Open(pointDebugPath(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || fb.result.PointDebugRows != 3 {
+		t.Fatalf("got %d CSV rows (%d counted), want header + 3", len(rows), fb.result.PointDebugRows)
+	}
+	if rows[1][0] != "0" || rows[3][0] != "2" || rows[1][2] != "1" || rows[1][4] != "12.000" {
+		t.Errorf("unexpected rows %v", rows)
+	}
+	if rows[1][10] != "false" {
+		t.Errorf("seeded first observation should be background, got %v", rows[1])
+	}
+}
@@ -0,0 +1,455 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+)
+
+// analysisSuffix names the per-capture results written by -json.
+const analysisSuffix = "_analysis.json"
+
+// Outlier detection for -summarise: a capture is flagged when its modified
+// z-score (0.6745·(x − median) / MAD) on a noise metric exceeds
+// summaryOutlierScore. Fewer than summaryOutlierMinCaptures captures are too
+// few to call anything abnormal.
+const (
+	summaryOutlierScore       = 3.5
+	summaryOutlierMinCaptures = 5
+)
+
+// CaptureSummary is one capture's row in the -summarise report.
+type CaptureSummary struct {
+	File             string  `json:"file"`
+	PCAPFile         string  `json:"pcap_file"`
+	Start            string  `json:"start,omitempty"` // earliest track start
+	DurationSecs     float64 `json:"duration_secs"`
+	TotalFrames      int     `json:"total_frames"`
+	TotalTracks      int     `json:"total_tracks"`
+	ConfirmedTracks  int     `json:"confirmed_tracks"`
+	ForegroundPct    float64 `json:"foreground_pct"`
+	ClustersPerFrame float64 `json:"clusters_per_frame"`
+	UnconfirmedPct   float64 `json:"unconfirmed_pct"` // tracks never confirmed
+}
+
+// VolumeBucket counts tracks starting in one day or hour. Speed statistics
+// are aggregates over the bucket's moving tracks.
+type VolumeBucket struct {
+	Period        string           `json:"period"`
+	Tracks        int              `json:"tracks"`
+	TracksByClass map[string]int   `json:"tracks_by_class"`
+	SpeedStats    *SpeedStatistics `json:"speed_statistics,omitempty"`
+}
+
+// CaptureOutlier is a capture whose metric is far from the median of all
+// captures. Direction is "high" or "low".
+type CaptureOutlier struct {
+	File      string  `json:"file"`
+	PCAPFile  string  `json:"pcap_file"`
+	Metric    string  `json:"metric"`
+	Value     float64 `json:"value"`
+	Median    float64 `json:"median"`
+	Score     float64 `json:"score"`
+	Direction string  `json:"direction"`
+}
+
+// SkippedFile is an analysis JSON left out of the report, with the reason.
+type SkippedFile struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// SummaryReport rolls many analysis results up into one report for
+// -summarise.
+type SummaryReport struct {
+	Captures          int              `json:"captures"`
+	TotalDurationSecs float64          `json:"total_duration_secs"`
+	TotalTracks       int              `json:"total_tracks"`
+	ConfirmedTracks   int              `json:"confirmed_tracks"`
+	TracksByClass     map[string]int   `json:"tracks_by_class"`
+	SpeedStats        SpeedStatistics  `json:"speed_statistics"`
+	Daily             []VolumeBucket   `json:"daily"`
+	Hourly            []VolumeBucket   `json:"hourly"`
+	Outliers          []CaptureOutlier `json:"outliers,omitempty"`
+	Skipped           []SkippedFile    `json:"skipped,omitempty"`
+	PerCapture        []CaptureSummary `json:"per_capture"`
+}
+
+// loadedResult is an analysis result and the file it was read from.
+type loadedResult struct {
+	file   string
+	result *AnalysisResult
+}
+
+// loadAnalysisResults reads every *_analysis.json under dir. Files that do
+// not parse, or parse to something other than an analysis result, are
+// skipped with a warning rather than failing the whole report.
+func loadAnalysisResults(dir string) ([]loadedResult, []SkippedFile, error) {
+	var loaded []loadedResult
+	var skipped []SkippedFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), analysisSuffix) {
+			return nil
+		}
+		skip := func(reason string) {
+			log.Printf("[WARN] Skipping %s: %s", path, reason)
+			skipped = append(skipped, SkippedFile{File: path, Reason: reason})
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			skip(err.Error())
+			return nil
+		}
+		var result AnalysisResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			skip(fmt.Sprintf("invalid JSON: %v", err))
+			return nil
+		}
+		if result.PCAPFile == "" && result.TotalFrames == 0 {
+			skip("not a pcap-analyse result (no pcap_file or frames)")
+			return nil
+		}
+		loaded = append(loaded, loadedResult{file: path, result: &result})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return loaded, skipped, nil
+}
+
+// summariseCapture derives the per-capture row and noise metrics.
+func summariseCapture(lr loadedResult) CaptureSummary {
+	r := lr.result
+	c := CaptureSummary{
+		File:            lr.file,
+		PCAPFile:        r.PCAPFile,
+		DurationSecs:    r.DurationSecs,
+		TotalFrames:     r.TotalFrames,
+		TotalTracks:     r.TotalTracks,
+		ConfirmedTracks: r.ConfirmedTracks,
+	}
+	if r.TotalPoints > 0 {
+		c.ForegroundPct = 100 * float64(r.ForegroundPoints) / float64(r.TotalPoints)
+	}
+	if r.TotalFrames > 0 {
+		c.ClustersPerFrame = float64(r.TotalClusters) / float64(r.TotalFrames)
+	}
+	if r.TotalTracks > 0 {
+		c.UnconfirmedPct = 100 * float64(r.TotalTracks-r.ConfirmedTracks) / float64(r.TotalTracks)
+	}
+	var earliest time.Time
+	for _, t := range r.Tracks {
+		if ts, err := time.Parse(time.RFC3339, t.StartTime); err == nil && (earliest.IsZero() || ts.Before(earliest)) {
+			earliest = ts
+		}
+	}
+	if !earliest.IsZero() {
+		c.Start = earliest.Format(time.RFC3339)
+	}
+	return c
+}
+
+// volumeAccumulator gathers per-period track counts and speed samples.
+type volumeAccumulator struct {
+	buckets map[string]*VolumeBucket
+	speeds  map[string][]float32
+}
+
+func newVolumeAccumulator() *volumeAccumulator {
+	return &volumeAccumulator{buckets: make(map[string]*VolumeBucket), speeds: make(map[string][]float32)}
+}
+
+func (a *volumeAccumulator) add(period string, t *TrackExport) {
+	b := a.buckets[period]
+	if b == nil {
+		b = &VolumeBucket{Period: period, TracksByClass: make(map[string]int)}
+		a.buckets[period] = b
+	}
+	b.Tracks++
+	b.TracksByClass[t.Class]++
+	if t.AvgSpeedMps > 0 {
+		a.speeds[period] = append(a.speeds[period], t.AvgSpeedMps)
+	}
+}
+
+// sorted returns the buckets in period order, with speed statistics when
+// withSpeed is set.
+func (a *volumeAccumulator) sorted(withSpeed bool) []VolumeBucket {
+	out := make([]VolumeBucket, 0, len(a.buckets))
+	for period, b := range a.buckets {
+		if withSpeed && len(a.speeds[period]) > 0 {
+			stats := l6objects.ComputeSpeedStatistics(a.speeds[period])
+			b.SpeedStats = &stats
+		}
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Period < out[j].Period })
+	return out
+}
+
+// summariseResults aggregates loaded analysis results. Class totals come
+// from each result's tracks_by_class; the daily and hourly trends bucket
+// the exported tracks by start time, in the offset the time was written
+// with. Speed statistics use the same per-track mean speeds as a single
+// analysis, pooled across captures.
+func summariseResults(results []loadedResult, skipped []SkippedFile) SummaryReport {
+	report := SummaryReport{
+		Captures:      len(results),
+		TracksByClass: make(map[string]int),
+		Skipped:       skipped,
+	}
+	daily, hourly := newVolumeAccumulator(), newVolumeAccumulator()
+	var speeds []float32
+	for _, lr := range results {
+		r := lr.result
+		report.TotalDurationSecs += r.DurationSecs
+		report.TotalTracks += r.TotalTracks
+		report.ConfirmedTracks += r.ConfirmedTracks
+		for class, n := range r.TracksByClass {
+			report.TracksByClass[class] += n
+		}
+		for _, t := range r.Tracks {
+			if t.AvgSpeedMps > 0 {
+				speeds = append(speeds, t.AvgSpeedMps)
+			}
+			ts, err := time.Parse(time.RFC3339, t.StartTime)
+			if err != nil {
+				continue
+			}
+			daily.add(ts.Format("2006-01-02"), t)
+			hourly.add(ts.Format("2006-01-02T15:00"), t)
+		}
+		report.PerCapture = append(report.PerCapture, summariseCapture(lr))
+	}
+	report.SpeedStats = l6objects.ComputeSpeedStatistics(speeds)
+	report.Daily = daily.sorted(true)
+	report.Hourly = hourly.sorted(false)
+	report.Outliers = findCaptureOutliers(report.PerCapture)
+	return report
+}
+
+// captureOutlierMetrics are the per-capture noise metrics checked for
+// outliers.
+var captureOutlierMetrics = []struct {
+	name  string
+	value func(CaptureSummary) (float64, bool)
+}{
+	{"foreground_pct", func(c CaptureSummary) (float64, bool) { return c.ForegroundPct, true }},
+	{"clusters_per_frame", func(c CaptureSummary) (float64, bool) { return c.ClustersPerFrame, c.TotalFrames > 0 }},
+	{"unconfirmed_pct", func(c CaptureSummary) (float64, bool) { return c.UnconfirmedPct, c.TotalTracks > 0 }},
+}
+
+// findCaptureOutliers flags captures whose noise metrics have a modified
+// z-score above summaryOutlierScore. A metric with no spread (MAD of zero)
+// flags nothing.
+func findCaptureOutliers(captures []CaptureSummary) []CaptureOutlier {
+	var outliers []CaptureOutlier
+	for _, m := range captureOutlierMetrics {
+		var values []float64
+		var idx []int
+		for i, c := range captures {
+			if v, ok := m.value(c); ok {
+				values = append(values, v)
+				idx = append(idx, i)
+			}
+		}
+		if len(values) < summaryOutlierMinCaptures {
+			continue
+		}
+		med := median(values)
+		deviations := make([]float64, len(values))
+		for i, v := range values {
+			deviations[i] = math.Abs(v - med)
+		}
+		mad := median(deviations)
+		if mad == 0 {
+			continue
+		}
+		for i, v := range values {
+			score := 0.6745 * (v - med) / mad
+			if math.Abs(score) <= summaryOutlierScore {
+				continue
+			}
+			direction := "high"
+			if score < 0 {
+				direction = "low"
+			}
+			c := captures[idx[i]]
+			outliers = append(outliers, CaptureOutlier{
+				File: c.File, PCAPFile: c.PCAPFile, Metric: m.name,
+				Value: v, Median: med, Score: score, Direction: direction,
+			})
+		}
+	}
+	return outliers
+}
+
+// median returns the median of values without reordering them.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// runSummarise builds the -summarise report for dir and writes it to
+// config.OutputDir.
+func runSummarise(dir string, config Config) (*SummaryReport, error) {
+	results, skipped, err := loadAnalysisResults(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no readable *%s files under %s (%d skipped)", analysisSuffix, dir, len(skipped))
+	}
+	report := summariseResults(results, skipped)
+	if err := exportSummaryReport(config.OutputDir, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// summaryClasses returns every class in the report, sorted, for the CSV
+// columns.
+func summaryClasses(report *SummaryReport) []string {
+	seen := make(map[string]bool, len(report.TracksByClass))
+	for class := range report.TracksByClass {
+		seen[class] = true
+	}
+	for _, b := range report.Hourly {
+		for class := range b.TracksByClass {
+			seen[class] = true
+		}
+	}
+	classes := make([]string, 0, len(seen))
+	for class := range seen {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// exportSummaryReport writes summary.json, summary_captures.csv,
+// summary_daily.csv and summary_hourly.csv to outDir.
+func exportSummaryReport(outDir string, report *SummaryReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON marshal: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "summary.json"), data, 0644); err != nil {
+		return fmt.Errorf("write summary: %w", err)
+	}
+
+	outliers := make(map[string][]string)
+	for _, o := range report.Outliers {
+		outliers[o.File] = append(outliers[o.File], o.Metric+" "+o.Direction)
+	}
+	f2 := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	rows := [][]string{{
+		"file", "pcap_file", "start", "duration_secs", "total_frames", "total_tracks",
+		"confirmed_tracks", "foreground_pct", "clusters_per_frame", "unconfirmed_pct", "outliers",
+	}}
+	for _, c := range report.PerCapture {
+		rows = append(rows, []string{
+			c.File, c.PCAPFile, c.Start, f2(c.DurationSecs), strconv.Itoa(c.TotalFrames),
+			strconv.Itoa(c.TotalTracks), strconv.Itoa(c.ConfirmedTracks), f2(c.ForegroundPct),
+			f2(c.ClustersPerFrame), f2(c.UnconfirmedPct), strings.Join(outliers[c.File], ";"),
+		})
+	}
+	if err := writeCSV(filepath.Join(outDir, "summary_captures.csv"), rows); err != nil {
+		return err
+	}
+
+	classes := summaryClasses(report)
+	volumeRows := func(buckets []VolumeBucket, withSpeed bool) [][]string {
+		header := append([]string{"period", "tracks"}, classes...)
+		if withSpeed {
+			header = append(header, "p50_speed_mps", "p85_speed_mps", "p95_speed_mps")
+		}
+		rows := [][]string{header}
+		for _, b := range buckets {
+			row := []string{b.Period, strconv.Itoa(b.Tracks)}
+			for _, class := range classes {
+				row = append(row, strconv.Itoa(b.TracksByClass[class]))
+			}
+			if withSpeed {
+				if s := b.SpeedStats; s != nil {
+					row = append(row, f2(float64(s.P50Speed)), f2(float64(s.P85Speed)), f2(float64(s.P95Speed)))
+				} else {
+					row = append(row, "", "", "")
+				}
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+	if err := writeCSV(filepath.Join(outDir, "summary_daily.csv"), volumeRows(report.Daily, true)); err != nil {
+		return err
+	}
+	if err := writeCSV(filepath.Join(outDir, "summary_hourly.csv"), volumeRows(report.Hourly, false)); err != nil {
+		return err
+	}
+	fmt.Printf("Summary report: %s\n", filepath.Join(outDir, "summary.json"))
+	return nil
+}
+
+// writeCSV writes rows to path.
+func writeCSV(path string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// printSummaryReport prints the headline numbers of a -summarise report.
+func printSummaryReport(report *SummaryReport) {
+	fmt.Println("\n=== Capture Summary ===")
+	fmt.Printf("Captures:          %d (%d skipped)\n", report.Captures, len(report.Skipped))
+	fmt.Printf("Total duration:    %.1f h\n", report.TotalDurationSecs/3600)
+	fmt.Printf("Tracks:            %d (%d confirmed)\n", report.TotalTracks, report.ConfirmedTracks)
+	classes := make([]string, 0, len(report.TracksByClass))
+	for class := range report.TracksByClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		fmt.Printf("  %-15s %d\n", class+":", report.TracksByClass[class])
+	}
+	fmt.Printf("Speed p50/p85/p95: %.1f / %.1f / %.1f m/s\n",
+		report.SpeedStats.P50Speed, report.SpeedStats.P85Speed, report.SpeedStats.P95Speed)
+	if len(report.Outliers) > 0 {
+		fmt.Println("Outlier captures:")
+		for _, o := range report.Outliers {
+			fmt.Printf("  %s: %s %s (%.2f vs median %.2f)\n",
+				filepath.Base(o.File), o.Metric, o.Direction, o.Value, o.Median)
+		}
+	}
+	fmt.Println("=======================")
+}
@@ -0,0 +1,172 @@
+//go:build pcap
+// +build pcap
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSummaryFixture writes an analysis result for capture i: two car
+// tracks and a pedestrian starting at hour 8+i on 12 October (hours 8-10)
+// or 13 October (later), with 10% foreground unless noisy.
+func writeSummaryFixture(t *testing.T, dir string, i int, noisy bool) {
+	t.Helper()
+	day, hour := "2026-10-12", 8+i
+	if hour > 10 {
+		day, hour = "2026-10-13", hour-3
+	}
+	start := func(min int) string {
+		return fmt.Sprintf("%sT%02d:%02d:00+01:00", day, hour, min)
+	}
+	fg := 1000
+	if noisy {
+		fg = 6000
+	}
+	result := AnalysisResult{
+		PCAPFile:         fmt.Sprintf("capture%02d.pcap", i),
+		DurationSecs:     600,
+		TotalPoints:      10000,
+		ForegroundPoints: fg + 10*i, // small spread so MAD is non-zero
+		TotalFrames:      6000,
+		TotalClusters:    12000,
+		TotalTracks:      3,
+		ConfirmedTracks:  3,
+		TracksByClass:    map[string]int{"car": 2, "pedestrian": 1},
+		Tracks: []*TrackExport{
+			{TrackID: "a", Class: "car", StartTime: start(1), AvgSpeedMps: 10},
+			{TrackID: "b", Class: "car", StartTime: start(2), AvgSpeedMps: 12},
+			{TrackID: "c", Class: "pedestrian", StartTime: start(3), AvgSpeedMps: 1.5},
+		},
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("capture%02d%s", i, analysisSuffix)), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunSummarise(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	for i := 0; i < 6; i++ {
+		writeSummaryFixture(t, in, i, i == 4)
+	}
+	// Malformed, partial and unrelated files.
+	if err := os.WriteFile(filepath.Join(in, "truncated"+analysisSuffix), []byte(`{"pcap_file": "x.pcap", "total_`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(in, "empty"+analysisSuffix), []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(in, "capture_ensemble.json"), []byte(`not json`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := runSummarise(in, Config{OutputDir: out})
+	if err != nil {
+		t.Fatalf("runSummarise: %v", err)
+	}
+	if report.Captures != 6 || len(report.Skipped) != 2 {
+		t.Fatalf("captures = %d, skipped = %d; want 6 and 2", report.Captures, len(report.Skipped))
+	}
+	if report.TotalTracks != 18 || report.TracksByClass["car"] != 12 || report.TracksByClass["pedestrian"] != 6 {
+		t.Errorf("totals = %d tracks, %v", report.TotalTracks, report.TracksByClass)
+	}
+	if math.Abs(report.TotalDurationSecs-3600) > 1e-9 {
+		t.Errorf("total duration = %v, want 3600", report.TotalDurationSecs)
+	}
+
+	if len(report.Daily) != 2 || report.Daily[0].Period != "2026-10-12" || report.Daily[0].Tracks != 9 {
+		t.Fatalf("daily = %+v", report.Daily)
+	}
+	if s := report.Daily[1].SpeedStats; s == nil || s.P50Speed != 10 || s.MaxSpeed != 12 {
+		t.Errorf("daily speed stats = %+v", s)
+	}
+	if len(report.Hourly) != 6 || report.Hourly[0].Period != "2026-10-12T08:00" || report.Hourly[0].TracksByClass["car"] != 2 {
+		t.Errorf("hourly = %+v", report.Hourly)
+	}
+
+	if len(report.Outliers) != 1 {
+		t.Fatalf("outliers = %+v, want only the noisy capture", report.Outliers)
+	}
+	if o := report.Outliers[0]; o.PCAPFile != "capture04.pcap" || o.Metric != "foreground_pct" || o.Direction != "high" {
+		t.Errorf("outlier = %+v", o)
+	}
+
+	for _, name := range []string{"summary.json", "summary_captures.csv", "summary_daily.csv", "summary_hourly.csv"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+	f, err := os.Open(filepath.Join(out, "summary_daily.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"period", "tracks", "car", "pedestrian", "p50_speed_mps", "p85_speed_mps", "p95_speed_mps"}
+	if len(rows) != 3 || len(rows[0]) != len(want) {
+		t.Fatalf("daily CSV = %v", rows)
+	}
+	for i := range want {
+		if rows[0][i] != want[i] {
+			t.Errorf("daily CSV header = %v, want %v", rows[0], want)
+			break
+		}
+	}
+	if rows[1][2] != "6" || rows[1][3] != "3" {
+		t.Errorf("daily CSV first row = %v", rows[1])
+	}
+}
+
+func TestRunSummarise_NothingReadable(t *testing.T) {
+	in := t.TempDir()
+	if err := os.WriteFile(filepath.Join(in, "bad"+analysisSuffix), []byte(`[`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runSummarise(in, Config{OutputDir: t.TempDir()}); err == nil {
+		t.Error("expected an error when no analysis results can be read")
+	}
+}
+
+func TestFindCaptureOutliers(t *testing.T) {
+	captures := []CaptureSummary{
+		{File: "a", ClustersPerFrame: 2.0, TotalFrames: 1},
+		{File: "b", ClustersPerFrame: 2.1, TotalFrames: 1},
+		{File: "c", ClustersPerFrame: 1.9, TotalFrames: 1},
+		{File: "d", ClustersPerFrame: 2.0, TotalFrames: 1},
+		{File: "e", ClustersPerFrame: 0.1, TotalFrames: 1},
+	}
+	got := findCaptureOutliers(captures)
+	if len(got) != 1 || got[0].File != "e" || got[0].Metric != "clusters_per_frame" || got[0].Direction != "low" {
+		t.Errorf("outliers = %+v, want e low on clusters_per_frame", got)
+	}
+	// Too few captures to judge.
+	if got := findCaptureOutliers(captures[:4]); len(got) != 0 {
+		t.Errorf("outliers with 4 captures = %+v, want none", got)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	values := []float64{3, 1, 2, 10}
+	if got := median(values); got != 2.5 {
+		t.Errorf("median = %v, want 2.5", got)
+	}
+	if values[0] != 3 {
+		t.Error("median reordered its input")
+	}
+	if got := median([]float64{5, 1, 3}); got != 3 {
+		t.Errorf("odd median = %v, want 3", got)
+	}
+}
@@ -21,7 +21,7 @@ func main() {
 	output := flag.String("output", "", "output JSON path (default: stdout)")
 	sensor := flag.String("sensor", "pcap-eval", "sensor ID")
 	tuningFile := flag.String("tuning", "", "tuning config JSON path (default: config/tuning.defaults.json)")
-	udpPort := flag.Int("port", 2368, "UDP port filter for PCAP packets")
+	udpPort := flag.Int("port", 0, "UDP port filter for PCAP packets (0 = detect from the capture)")
 
 	flag.Parse()
 
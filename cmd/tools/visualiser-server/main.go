@@ -13,6 +13,11 @@
 //
 //	-addr    Listen address (default: localhost:50051)
 //	-mode    Server mode: synthetic, replay, live (default: synthetic)
+//	-port-fallback       Following ports to try when -addr is in use (default: 0)
+//	-ephemeral-fallback  Fall back to an OS-assigned port (default: false)
+//
+// The address actually bound is printed once the server is listening, so
+// several servers can share a host.
 //
 // Synthetic Mode Flags:
 //
@@ -27,6 +32,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"log"
 	"os"
@@ -34,14 +40,16 @@ import (
 	"syscall"
 
 	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints"
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
 	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/recorder"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 )
 
 func main() {
 	// Common flags
 	addr := flag.String("addr", "localhost:50051", "Listen address")
 	mode := flag.String("mode", "synthetic", "Server mode: synthetic, replay, live")
+	portFallback := flag.Int("port-fallback", 0, "Following ports to try when -addr is in use")
+	ephemeralFallback := flag.Bool("ephemeral-fallback", false, "Fall back to an OS-assigned port when no tried port is free")
 
 	// Synthetic mode flags
 	rate := flag.Float64("rate", 10, "Frame rate in Hz (synthetic mode)")
@@ -55,14 +63,19 @@ func main() {
 
 	flag.Parse()
 
+	cfg := l9endpoints.DefaultConfig()
+	cfg.ListenAddr = *addr
+	cfg.PortFallback = *portFallback
+	cfg.EphemeralFallback = *ephemeralFallback
+
 	switch *mode {
 	case "synthetic":
-		runSyntheticMode(*addr, *rate, *points, *tracks)
+		runSyntheticMode(cfg, *rate, *points, *tracks)
 	case "replay":
 		if *logPath == "" {
 			log.Fatal("Error: -log flag is required for replay mode")
 		}
-		runReplayMode(*addr, *logPath)
+		runReplayMode(cfg, *logPath)
 	case "live":
 		log.Fatal("Live mode not yet implemented")
 	default:
@@ -70,13 +83,11 @@ func main() {
 	}
 }
 
-func runSyntheticMode(addr string, rate float64, points, tracks int) {
-	log.Printf("Starting visualiser server in SYNTHETIC mode on %s", addr)
+func runSyntheticMode(cfg l9endpoints.Config, rate float64, points, tracks int) {
+	log.Printf("Starting visualiser server in SYNTHETIC mode on %s", cfg.ListenAddr)
 	log.Printf("Configuration: %d points, %d tracks, %.1f Hz", points, tracks, rate)
 
 	// Create publisher
-	cfg := l9endpoints.DefaultConfig()
-	cfg.ListenAddr = addr
 	cfg.SensorID = "synthetic-01"
 	publisher := l9endpoints.NewPublisher(cfg)
 
@@ -92,20 +103,18 @@ func runSyntheticMode(addr string, rate float64, points, tracks int) {
 	}
 
 	// Start publisher (this starts the gRPC listener)
-	if err := publisher.Start(); err != nil {
-		log.Fatalf("Failed to start publisher: %v", err)
-	}
+	startPublisher(publisher)
 
 	// Register the gRPC service
 	pb.RegisterVisualiserServiceServer(publisher.GRPCServer(), server)
 
-	log.Printf("Server ready, waiting for connections...")
+	log.Printf("Server ready on %s, waiting for connections...", publisher.Addr())
 
 	waitForShutdown(func() { publisher.Stop() })
 }
 
-func runReplayMode(addr, logPath string) {
-	log.Printf("Starting visualiser server in REPLAY mode on %s", addr)
+func runReplayMode(cfg l9endpoints.Config, logPath string) {
+	log.Printf("Starting visualiser server in REPLAY mode on %s", cfg.ListenAddr)
 	log.Printf("Log file: %s", logPath)
 
 	// Open replayer
@@ -127,8 +136,6 @@ func runReplayMode(addr, logPath string) {
 	}
 
 	// Create publisher (for gRPC server infrastructure)
-	cfg := l9endpoints.DefaultConfig()
-	cfg.ListenAddr = addr
 	cfg.SensorID = header.SensorID
 	publisher := l9endpoints.NewPublisher(cfg)
 
@@ -136,19 +143,31 @@ func runReplayMode(addr, logPath string) {
 	replayServer := l9endpoints.NewReplayServer(publisher, replayer)
 
 	// Start publisher (this starts the gRPC listener)
-	if err := publisher.Start(); err != nil {
-		log.Fatalf("Failed to start publisher: %v", err)
-	}
+	startPublisher(publisher)
 
 	// Register the gRPC service
 	pb.RegisterVisualiserServiceServer(publisher.GRPCServer(), replayServer)
 
 	log.Printf("Server ready, waiting for connections...")
-	log.Printf("Connect visualiser to %s to start replay", addr)
+	log.Printf("Connect visualiser to %s to start replay", publisher.Addr())
 
 	waitForShutdown(func() { publisher.Stop() })
 }
 
+// startPublisher starts the gRPC listener, exiting with a hint when every
+// tried address is taken.
+func startPublisher(publisher *l9endpoints.Publisher) {
+	err := publisher.Start()
+	if err == nil {
+		return
+	}
+	var lerr *l9endpoints.ListenError
+	if errors.As(err, &lerr) && lerr.AddrInUse() {
+		log.Fatalf("Failed to start publisher: %v (use -port-fallback or -ephemeral-fallback to pick another port)", err)
+	}
+	log.Fatalf("Failed to start publisher: %v", err)
+}
+
 func waitForShutdown(cleanup func()) {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/banshee-data/velocity.report/internal/db"
+)
+
+func runDBMaintenance(args []string) error {
+	fs := flag.NewFlagSet("db-maintenance", flag.ContinueOnError)
+	dbPath := fs.String("db", ctlManager.DBPath(), "SQLite database to inspect")
+	vacuum := fs.Bool("vacuum", false, "Run VACUUM and PRAGMA optimize after the report (stop the service first)")
+	optimize := fs.Bool("optimize", false, "Run PRAGMA optimize only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := os.Stat(*dbPath); err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+
+	database, err := db.OpenDB(*dbPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer database.Close()
+
+	stats, err := database.GetDatabaseStats()
+	if err != nil {
+		return err
+	}
+	printDatabaseStats(os.Stdout, *dbPath, stats)
+
+	if !*vacuum && !*optimize {
+		return nil
+	}
+	result, err := database.RunMaintenance(*vacuum)
+	if err != nil {
+		return err
+	}
+	printMaintenanceResult(os.Stdout, result)
+	return nil
+}
+
+// printDatabaseStats writes the size report: totals, free pages, then one
+// line per table, largest first.
+func printDatabaseStats(w io.Writer, path string, stats *db.DatabaseStats) {
+	fmt.Fprintf(w, "Database: %s\n", path)
+	fmt.Fprintf(w, "Size:     %.2f MB (%d pages of %d bytes)\n", stats.TotalSizeMB, stats.PageCount, stats.PageSize)
+	fmt.Fprintf(w, "Free:     %.2f MB (%d pages, %.1f%%)\n\n", stats.FreeSizeMB, stats.FreePages, stats.FreeFraction*100)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "TABLE\tROWS\tDATA MB\tINDEX MB\t")
+	for _, t := range stats.Tables {
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\t%.2f\t\n", t.Name, t.RowCount, t.SizeMB, t.IndexSizeMB)
+	}
+	tw.Flush()
+
+	for _, warning := range stats.Warnings {
+		fmt.Fprintf(w, "\nWarning: %s\n", warning)
+	}
+}
+
+func printMaintenanceResult(w io.Writer, result *db.MaintenanceResult) {
+	if !result.Vacuumed {
+		fmt.Fprintf(w, "\nPRAGMA optimize completed in %d ms\n", result.DurationMS)
+		return
+	}
+	fmt.Fprintf(w, "\nVACUUM reclaimed %.2f MB (%.2f MB -> %.2f MB) in %d ms\n",
+		result.ReclaimedMB, result.BeforeSizeMB, result.AfterSizeMB, result.DurationMS)
+}
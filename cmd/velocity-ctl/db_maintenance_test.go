@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dbpkg "github.com/banshee-data/velocity.report/internal/db"
+)
+
+func TestRunDBMaintenanceMissingDatabase(t *testing.T) {
+	if err := runDBMaintenance([]string{"--db", filepath.Join(t.TempDir(), "missing.db")}); err == nil {
+		t.Error("expected error for a missing database")
+	}
+}
+
+func TestRunDBMaintenanceVacuum(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "sensor_data.db")
+	database, err := dbpkg.NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	pad := strings.Repeat("x", 800)
+	for i := 0; i < 1000; i++ {
+		if _, err := database.Exec(`INSERT INTO radar_data (raw_event) VALUES (?)`,
+			fmt.Sprintf(`{"magnitude":%d,"pad":%q}`, i, pad)); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if _, err := database.Exec(`DELETE FROM radar_data`); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	database.Close()
+
+	if err := runDBMaintenance([]string{"--db", dbPath, "--vacuum"}); err != nil {
+		t.Fatalf("runDBMaintenance: %v", err)
+	}
+
+	database, err = dbpkg.OpenDB(dbPath)
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer database.Close()
+	stats, err := database.GetDatabaseStats()
+	if err != nil {
+		t.Fatalf("GetDatabaseStats: %v", err)
+	}
+	if stats.FreePages != 0 {
+		t.Errorf("FreePages after vacuum = %d, want 0", stats.FreePages)
+	}
+}
+
+func TestPrintDatabaseStats(t *testing.T) {
+	stats := &dbpkg.DatabaseStats{
+		TotalSizeMB: 12.5, PageSize: 4096, PageCount: 3200, FreePages: 1000, FreeSizeMB: 3.9, FreeFraction: 0.3125,
+		Tables:   []dbpkg.TableStats{{Name: "radar_data", RowCount: 42, SizeMB: 8.1, IndexSizeMB: 0.4}},
+		Warnings: []string{"free pages are 31.2% of the database"},
+	}
+	var buf bytes.Buffer
+	printDatabaseStats(&buf, "sensor_data.db", stats)
+	out := buf.String()
+	for _, want := range []string{"31.2%", "radar_data", "42", "8.10", "0.40", "Warning: free pages"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
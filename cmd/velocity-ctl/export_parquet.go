@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/db"
+	"github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
+)
+
+func runExportParquet(args []string) error {
+	fs := flag.NewFlagSet("export-parquet", flag.ContinueOnError)
+	dbPath := fs.String("db", ctlManager.DBPath(), "SQLite database to export from")
+	outputDir := fs.String("output", "", "Directory for the Parquet dataset (required)")
+	sensorID := fs.String("sensor", "", "Export only this sensor (default: all sensors)")
+	startArg := fs.String("start", "", "Start of the range, RFC3339 or YYYY-MM-DD (required)")
+	endArg := fs.String("end", "", "End of the range, exclusive; RFC3339 or YYYY-MM-DD (default: now)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *outputDir == "" {
+		return fmt.Errorf("--output is required")
+	}
+	if *startArg == "" {
+		return fmt.Errorf("--start is required")
+	}
+	start, err := parseExportTime(*startArg)
+	if err != nil {
+		return fmt.Errorf("--start: %w", err)
+	}
+	end := time.Now()
+	if *endArg != "" {
+		if end, err = parseExportTime(*endArg); err != nil {
+			return fmt.Errorf("--end: %w", err)
+		}
+	}
+
+	database, err := db.OpenDB(*dbPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer database.Close()
+
+	result, err := sqlite.ExportParquet(database.DB, sqlite.ParquetExportOptions{
+		Dir:        *outputDir,
+		SensorID:   *sensorID,
+		StartNanos: start.UnixNano(),
+		EndNanos:   end.UnixNano(),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d tracks and %d observations to %d files in %s\n",
+		result.Tracks, result.Observations, len(result.Files), *outputDir)
+	return nil
+}
+
+// parseExportTime accepts an RFC3339 timestamp or a UTC date.
+func parseExportTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not RFC3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dbpkg "github.com/banshee-data/velocity.report/internal/db"
+)
+
+func TestParseExportTime(t *testing.T) {
+	got, err := parseExportTime("2026-03-01")
+	if err != nil || !got.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("date: got %v, %v", got, err)
+	}
+	got, err = parseExportTime("2026-03-01T12:00:00+01:00")
+	if err != nil || !got.Equal(time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("RFC3339: got %v, %v", got, err)
+	}
+	if _, err := parseExportTime("yesterday"); err == nil {
+		t.Fatal("expected error for unparseable time")
+	}
+}
+
+func TestRunExportParquetRequiresFlags(t *testing.T) {
+	if err := runExportParquet([]string{"--start", "2026-03-01"}); err == nil {
+		t.Error("expected error without --output")
+	}
+	if err := runExportParquet([]string{"--output", t.TempDir()}); err == nil {
+		t.Error("expected error without --start")
+	}
+}
+
+func TestRunExportParquetEmptyDatabase(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "sensor_data.db")
+	database, err := dbpkg.NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	database.Close()
+
+	out := filepath.Join(tmp, "parquet")
+	if err := runExportParquet([]string{"--db", dbPath, "--output", out, "--start", "2026-03-01", "--end", "2026-03-02"}); err != nil {
+		t.Fatalf("runExportParquet: %v", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("expected no output for an empty range, stat err = %v", err)
+	}
+}
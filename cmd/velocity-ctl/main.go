@@ -10,6 +10,8 @@
 //	rollback  Restore the previous version from a timestamped backup
 //	backup    Create a manual snapshot of binary + database
 //	status    Show systemd service status
+//	export-parquet  Export tracks and observations to a Parquet dataset
+//	db-maintenance  Report database table sizes; optionally VACUUM
 //	version   Print installed version information
 package main
 
@@ -30,6 +32,10 @@ Commands:
   rollback  Restore previous version from backup
   backup    Snapshot binary + database
   status    Show service status
+  export-parquet
+            Export tracks and observations to Parquet
+  db-maintenance
+            Report table sizes and free space; --vacuum to reclaim it
   version   Print version information
 
 Run 'velocity-ctl <command> --help' for command-specific usage.`
@@ -64,6 +70,16 @@ func main() {
 			fmt.Fprintf(os.Stderr, "status failed: %v\n", err)
 			os.Exit(1)
 		}
+	case "export-parquet":
+		if err := runExportParquet(args); err != nil {
+			fmt.Fprintf(os.Stderr, "export-parquet failed: %v\n", err)
+			os.Exit(1)
+		}
+	case "db-maintenance":
+		if err := runDBMaintenance(args); err != nil {
+			fmt.Fprintf(os.Stderr, "db-maintenance failed: %v\n", err)
+			os.Exit(1)
+		}
 	case "version":
 		runVersion()
 	case "--help", "-h", "help":
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLogMiddleware_WritesStructuredLine(t *testing.T) {
+	var buf bytes.Buffer
+	handler := AccessLogMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello"))
+		w.Write([]byte(" world"))
+	}), AccessLogConfig{Level: AccessLogAll, Writer: &buf})
+
+	for _, target := range []string{"/api/lidar/tracks?sensor_id=hesai-01&limit=5", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one per request:\n%s", len(lines), buf.String())
+	}
+	var ok, notFound accessLogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &ok); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if ok.Method != http.MethodGet || ok.Path != "/api/lidar/tracks" || ok.Status != http.StatusOK ||
+		ok.Bytes != int64(len("hello world")) || ok.SensorID != "hesai-01" || ok.DurationMs < 0 || ok.Time == "" {
+		t.Errorf("unexpected entry %+v", ok)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &notFound); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if notFound.Status != http.StatusNotFound || notFound.SensorID != "" {
+		t.Errorf("unexpected entry %+v", notFound)
+	}
+}
+
+func TestAccessLogMiddleware_ErrorsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	handler := AccessLogMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}), AccessLogConfig{Level: AccessLogErrors, Writer: &buf})
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/fail", nil))
+
+	out := strings.TrimSpace(buf.String())
+	if strings.Count(out, "\n") != 0 || !strings.Contains(out, `"status":500`) || !strings.Contains(out, `"method":"POST"`) {
+		t.Errorf("errors level should record only the failed request, got:\n%s", out)
+	}
+}
+
+func TestAccessLogMiddleware_DisabledFallsBack(t *testing.T) {
+	var buf bytes.Buffer
+	for _, cfg := range []AccessLogConfig{{Level: AccessLogOff, Writer: &buf}, {Level: AccessLogAll}} {
+		rec := httptest.NewRecorder()
+		AccessLogMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}), cfg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("status = %d, want the handler's 202", rec.Code)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("disabled access log wrote %q", buf.String())
+	}
+}
+
+func TestParseAccessLogLevel(t *testing.T) {
+	for in, want := range map[string]AccessLogLevel{"": AccessLogOff, "off": AccessLogOff, "errors": AccessLogErrors, "all": AccessLogAll} {
+		if got, err := ParseAccessLogLevel(in); err != nil || got != want {
+			t.Errorf("ParseAccessLogLevel(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseAccessLogLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -803,6 +803,11 @@ func TestLoggingResponseWriter(t *testing.T) {
 
 	// Test Flush
 	lrw.Flush() // Should not panic
+
+	// Unwrap lets WebSocket upgrades reach the hijackable writer.
+	if lrw.Unwrap() != rec {
+		t.Error("Unwrap did not return the wrapped writer")
+	}
 }
 
 // TestLoggingMiddleware tests the logging middleware
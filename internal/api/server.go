@@ -28,6 +28,7 @@ type Server struct {
 	debugMode            bool
 	transitController    TransitController    // Interface for transit worker control
 	capabilitiesProvider CapabilitiesProvider // Interface for sensor capability reporting
+	accessLog            AccessLogConfig      // Structured access log; off by default
 	// mux holds the HTTP handlers; storing it here ensures callers that
 	// obtain the mux via ServeMux() and register additional admin routes
 	// will have those routes preserved when Start uses the mux to run the
@@ -87,6 +88,12 @@ func (s *Server) SetCapabilitiesProvider(cp CapabilitiesProvider) {
 	s.capabilitiesProvider = cp
 }
 
+// SetAccessLog enables structured access logging for requests served by
+// Start. When the config is disabled the plain per-request line is kept.
+func (s *Server) SetAccessLog(cfg AccessLogConfig) {
+	s.accessLog = cfg
+}
+
 func (s *Server) ServeMux() *http.ServeMux {
 	if s.mux != nil {
 		return s.mux
@@ -106,12 +113,13 @@ func (s *Server) ServeMux() *http.ServeMux {
 	s.mux.HandleFunc("/api/sites/", s.handleSites) // Note trailing slash to match /api/sites and /api/sites/*
 	s.mux.HandleFunc("/api/site_config_periods", s.handleSiteConfigPeriods)
 	s.mux.HandleFunc("/api/timeline", s.handleTimeline)
-	s.mux.HandleFunc("/api/reports/", s.handleReports)                  // Report management endpoints
-	s.mux.HandleFunc("/api/transit_worker", s.handleTransitWorker)      // Transit worker control
-	s.mux.HandleFunc("/api/db_stats", s.handleDatabaseStats)            // Database table sizes and disk usage
-	s.mux.HandleFunc("/api/charts/timeseries", s.handleChartTimeSeries) // SVG time-series chart
-	s.mux.HandleFunc("/api/charts/histogram", s.handleChartHistogram)   // SVG histogram chart
-	s.mux.HandleFunc("/api/charts/comparison", s.handleChartComparison) // SVG comparison chart
+	s.mux.HandleFunc("/api/reports/", s.handleReports)                   // Report management endpoints
+	s.mux.HandleFunc("/api/transit_worker", s.handleTransitWorker)       // Transit worker control
+	s.mux.HandleFunc("/api/db_stats", s.handleDatabaseStats)             // Database table sizes and disk usage
+	s.mux.HandleFunc("/api/db_maintenance", s.handleDatabaseMaintenance) // VACUUM and PRAGMA optimize
+	s.mux.HandleFunc("/api/charts/timeseries", s.handleChartTimeSeries)  // SVG time-series chart
+	s.mux.HandleFunc("/api/charts/histogram", s.handleChartHistogram)    // SVG histogram chart
+	s.mux.HandleFunc("/api/charts/comparison", s.handleChartComparison)  // SVG comparison chart
 	return s.mux
 }
 
@@ -292,7 +300,7 @@ func (s *Server) startWithListener(ctx context.Context, listener net.Listener, d
 		http.NotFound(w, r)
 	})
 
-	server := &http.Server{Handler: LoggingMiddleware(mux)}
+	server := &http.Server{Handler: AccessLogMiddleware(mux, s.accessLog)}
 
 	log.Printf("HTTP server listening on %s", listener.Addr())
 
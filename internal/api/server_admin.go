@@ -113,7 +113,8 @@ func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleDatabaseStats returns database table sizes and disk usage statistics.
-// GET: returns { total_size_mb: float, tables: [{name, row_count, size_mb}, ...] }
+// GET: returns { total_size_mb: float, tables: [{name, row_count, size_mb, index_size_mb}, ...],
+// page_size, page_count, free_pages, free_size_mb, free_fraction, warnings }
 func (s *Server) handleDatabaseStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -139,6 +140,47 @@ func (s *Server) handleDatabaseStats(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleDatabaseMaintenance runs database maintenance: VACUUM, to return
+// free pages to the filesystem, then PRAGMA optimize. Other queries wait
+// while VACUUM runs.
+// POST: optional { vacuum: false } to run PRAGMA optimize only; returns
+// { vacuumed, before_size_mb, after_size_mb, reclaimed_mb, free_pages_before, duration_ms }
+func (s *Server) handleDatabaseMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		s.writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if s.db == nil {
+		s.writeJSONError(w, http.StatusInternalServerError, "Database not configured")
+		return
+	}
+
+	var req struct {
+		Vacuum *bool `json:"vacuum"`
+	}
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+	}
+	vacuum := req.Vacuum == nil || *req.Vacuum
+
+	result, err := s.db.RunMaintenance(vacuum)
+	if err != nil {
+		s.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Database maintenance failed: %v", err))
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.writeJSONError(w, http.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+}
+
 // handleTransitWorker provides API endpoints for controlling the transit worker.
 // GET: returns current state, including current and last run details.
 // POST: with { enabled: bool } to update state, optionally { trigger: true } for manual run
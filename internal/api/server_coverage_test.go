@@ -1826,6 +1826,46 @@ func TestHandleDatabaseStats_Healthy(t *testing.T) {
 	}
 }
 
+// TestHandleDatabaseMaintenance tests the maintenance endpoint's method
+// check, body handling and response.
+func TestHandleDatabaseMaintenance(t *testing.T) {
+	server, dbInst := setupTestServer(t)
+	defer cleanupTestServer(t, dbInst)
+
+	w := httptest.NewRecorder()
+	server.handleDatabaseMaintenance(w, httptest.NewRequest(http.MethodGet, "/api/db_maintenance", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleDatabaseMaintenance(w, httptest.NewRequest(http.MethodPost, "/api/db_maintenance", strings.NewReader("{")))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("malformed body: expected 400, got %d", w.Code)
+	}
+
+	for _, tc := range []struct {
+		body       string
+		wantVacuum bool
+	}{
+		{"", true},
+		{`{"vacuum": false}`, false},
+	} {
+		w = httptest.NewRecorder()
+		server.handleDatabaseMaintenance(w, httptest.NewRequest(http.MethodPost, "/api/db_maintenance", strings.NewReader(tc.body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("body %q: expected 200, got %d: %s", tc.body, w.Code, w.Body.String())
+		}
+		var result db.MaintenanceResult
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("body %q: decode response: %v", tc.body, err)
+		}
+		if result.Vacuumed != tc.wantVacuum || result.BeforeSizeMB <= 0 {
+			t.Errorf("body %q: result = %+v, want vacuumed=%t", tc.body, result, tc.wantVacuum)
+		}
+	}
+}
+
 // TestListEvents_ValidWithParams tests listEvents with valid units and timezone.
 func TestListEvents_ValidWithParams(t *testing.T) {
 	server, dbInst := setupTestServer(t)
@@ -13,6 +13,7 @@ type loggingResponseWriter struct {
 	http.ResponseWriter
 	statusCode  int
 	wroteHeader bool
+	bytes       int64 // Response body bytes written
 }
 
 func (lrw *loggingResponseWriter) WriteHeader(code int) {
@@ -27,7 +28,9 @@ func (lrw *loggingResponseWriter) Write(p []byte) (int, error) {
 	if !lrw.wroteHeader {
 		lrw.WriteHeader(http.StatusOK)
 	}
-	return lrw.ResponseWriter.Write(p)
+	n, err := lrw.ResponseWriter.Write(p)
+	lrw.bytes += int64(n)
+	return n, err
 }
 
 func (lrw *loggingResponseWriter) Flush() {
@@ -36,6 +39,12 @@ func (lrw *loggingResponseWriter) Flush() {
 	}
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController and
+// WebSocket upgrades, which need to hijack the connection.
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
+
 func statusCodeColor(statusCode int) string {
 	// Return plain status code without color codes
 	return strconv.Itoa(statusCode)
@@ -22,10 +22,22 @@ type TuningConfig struct {
 	Pipeline PipelineConfig `json:"pipeline"`
 }
 
-// L1Config holds sensor identity and data-source settings.
+// L1Config holds sensor identity, data-source, range-clipping and packet
+// timestamp settings.
 type L1Config struct {
-	Sensor     string `json:"sensor"`
-	DataSource string `json:"data_source"`
+	Sensor               string                  `json:"sensor"`
+	DataSource           string                  `json:"data_source"`
+	MinRangeMetres       float64                 `json:"min_range_metres"`
+	MaxRangeMetres       float64                 `json:"max_range_metres"`
+	RingRangeClip        map[int]RangeClipWindow `json:"ring_range_clip"`
+	TimestampMaxBackstep string                  `json:"timestamp_max_backstep"`
+}
+
+// RangeClipWindow overrides the L1 range bounds for one ring, keyed by the
+// 1-based channel number. A zero bound inherits the L1 one.
+type RangeClipWindow struct {
+	MinMetres float64 `json:"min_metres"`
+	MaxMetres float64 `json:"max_metres"`
 }
 
 // PipelineConfig holds cross-cutting runtime settings already exposed pre-restructure.
@@ -71,6 +83,11 @@ type L3Common struct {
 	SettlingMaxSpreadDelta            float64 `json:"settling_max_spread_delta"`
 	SettlingMinRegionStability        float64 `json:"settling_min_region_stability"`
 	SettlingMinConfidence             float64 `json:"settling_min_confidence"`
+	AzimuthToleranceDeg               float64 `json:"azimuth_tolerance_deg"`
+	RangeToleranceSigmaK              float64 `json:"range_tolerance_sigma_k"`
+	BackgroundModel                   string  `json:"background_model"`
+	VarianceThresholdK                float64 `json:"variance_threshold_k"`
+	RegionWarmupMinFrames             int     `json:"region_warmup_min_frames"`
 }
 
 // L3EmaBaselineV1 is the current production L3 engine.
@@ -96,15 +113,33 @@ type L4Config struct {
 
 // L4Common contains fields shared by all L4 engines.
 type L4Common struct {
-	ForegroundDBSCANEps        float64 `json:"foreground_dbscan_eps"`
-	ForegroundMinClusterPoints int     `json:"foreground_min_cluster_points"`
-	ForegroundMaxInputPoints   int     `json:"foreground_max_input_points"`
-	HeightBandFloor            float64 `json:"height_band_floor"`
-	HeightBandCeiling          float64 `json:"height_band_ceiling"`
-	RemoveGround               bool    `json:"remove_ground"`
-	MaxClusterDiameter         float64 `json:"max_cluster_diameter"`
-	MinClusterDiameter         float64 `json:"min_cluster_diameter"`
-	MaxClusterAspectRatio      float64 `json:"max_cluster_aspect_ratio"`
+	ForegroundDBSCANEps        float64    `json:"foreground_dbscan_eps"`
+	ForegroundMinClusterPoints int        `json:"foreground_min_cluster_points"`
+	ForegroundMaxInputPoints   int        `json:"foreground_max_input_points"`
+	HeightBandFloor            float64    `json:"height_band_floor"`
+	HeightBandCeiling          float64    `json:"height_band_ceiling"`
+	RemoveGround               bool       `json:"remove_ground"`
+	MaxClusterDiameter         float64    `json:"max_cluster_diameter"`
+	MinClusterDiameter         float64    `json:"min_cluster_diameter"`
+	MaxClusterAspectRatio      float64    `json:"max_cluster_aspect_ratio"`
+	MinPtsReferenceRange       float64    `json:"min_pts_reference_range"`
+	MinPtsFloor                int        `json:"min_pts_floor"`
+	VoxelOrigin                [3]float64 `json:"voxel_origin"`
+	VoxelSnapToGrid            bool       `json:"voxel_snap_to_grid"`
+	ClusterHeightWeight        float64    `json:"cluster_height_weight"`
+	ClusterIntensityWeight     float64    `json:"cluster_intensity_weight"`
+	ClusterMergeSeparation     float64    `json:"cluster_merge_separation"`
+	ClusterMergeMaxLength      float64    `json:"cluster_merge_max_length"`
+	ClusterMergeMaxWidth       float64    `json:"cluster_merge_max_width"`
+	BloomMinIntensity          int        `json:"bloom_min_intensity"`
+	BloomMaxExtent             float64    `json:"bloom_max_extent"`
+	BloomMinPoints             int        `json:"bloom_min_points"`
+	BloomStaticFrames          int        `json:"bloom_static_frames"`
+	SlowMoverWindow            int        `json:"slow_mover_window"`
+	SlowMoverMinFrames         int        `json:"slow_mover_min_frames"`
+	RegionContinuity           bool       `json:"region_continuity"`
+	ClusterStableIDs           bool       `json:"cluster_stable_ids"`
+	ClusterWorkers             int        `json:"cluster_workers"`
 }
 
 // L4DbscanXyV1 is the current production L4 engine.
@@ -158,6 +193,13 @@ type L5Common struct {
 	SplitSizeRatio                   float64 `json:"split_size_ratio"`
 	DeletedTrackGracePeriod          string  `json:"deleted_track_grace_period"`
 	MinObservationsForClassification int     `json:"min_observations_for_classification"`
+	EvictionPolicy                   string  `json:"eviction_policy"`
+	SizeInstabilityWeight            float64 `json:"size_instability_weight"`
+	GatingMode                       string  `json:"gating_mode"`
+	GatingChiSquare                  float64 `json:"gating_chi_square"`
+	GatingAlongTrackFraction         float64 `json:"gating_along_track_fraction"`
+	SpeedSmoothingFrames             int     `json:"speed_smoothing_frames"`
+	ShapeAssociationWeight           float64 `json:"shape_association_weight"`
 }
 
 // L5CvKfV1 is the current production L5 engine.
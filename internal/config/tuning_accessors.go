@@ -101,6 +101,22 @@ func (c *TuningConfig) GetSensor() string { return c.L1.Sensor }
 // GetDataSource returns the configured initial data source.
 func (c *TuningConfig) GetDataSource() string { return c.L1.DataSource }
 
+// GetMinRangeMetres returns the L1 near range clip; zero leaves it open.
+func (c *TuningConfig) GetMinRangeMetres() float64 { return c.L1.MinRangeMetres }
+
+// GetMaxRangeMetres returns the L1 far range clip; zero leaves it open.
+func (c *TuningConfig) GetMaxRangeMetres() float64 { return c.L1.MaxRangeMetres }
+
+// GetRingRangeClip returns the L1 per-ring range windows.
+func (c *TuningConfig) GetRingRangeClip() map[int]RangeClipWindow { return c.L1.RingRangeClip }
+
+// GetTimestampMaxBackstep parses and returns the L1 packet timestamp jitter
+// bound; zero disables jitter correction.
+func (c *TuningConfig) GetTimestampMaxBackstep() time.Duration {
+	d, _ := time.ParseDuration(c.L1.TimestampMaxBackstep)
+	return d
+}
+
 // GetFlushInterval parses and returns the flush interval.
 func (c *TuningConfig) GetFlushInterval() time.Duration {
 	d, _ := time.ParseDuration(c.Pipeline.FlushInterval)
@@ -189,9 +205,12 @@ func (c *TuningConfig) GetMaxMisses() int { return c.L5.ActiveCommon().MaxMisses
 // GetMaxMissesConfirmed returns the active L5 confirmed miss threshold.
 func (c *TuningConfig) GetMaxMissesConfirmed() int { return c.L5.ActiveCommon().MaxMissesConfirmed }
 
-// GetMaxTracks returns the active L5 max_tracks value.
+// GetMaxTracks returns the active L5 max_tracks value; 0 means unlimited.
 func (c *TuningConfig) GetMaxTracks() int { return c.L5.ActiveCommon().MaxTracks }
 
+// GetEvictionPolicy returns the active L5 eviction policy used at max_tracks.
+func (c *TuningConfig) GetEvictionPolicy() string { return c.L5.ActiveCommon().EvictionPolicy }
+
 // GetBackgroundUpdateFraction returns the active L3 update alpha.
 func (c *TuningConfig) GetBackgroundUpdateFraction() float64 {
 	return c.L3.ActiveCommon().BackgroundUpdateFraction
@@ -284,6 +303,32 @@ func (c *TuningConfig) GetSettlingMinConfidence() float64 {
 	return c.L3.ActiveCommon().SettlingMinConfidence
 }
 
+// GetAzimuthToleranceDeg returns the active L3 anisotropic azimuth tolerance.
+func (c *TuningConfig) GetAzimuthToleranceDeg() float64 {
+	return c.L3.ActiveCommon().AzimuthToleranceDeg
+}
+
+// GetRangeToleranceSigmaK returns the active L3 anisotropic range tolerance
+// in per-cell standard deviations.
+func (c *TuningConfig) GetRangeToleranceSigmaK() float64 {
+	return c.L3.ActiveCommon().RangeToleranceSigmaK
+}
+
+// GetBackgroundModel returns the active L3 per-cell background model.
+func (c *TuningConfig) GetBackgroundModel() string {
+	return c.L3.ActiveCommon().BackgroundModel
+}
+
+// GetVarianceThresholdK returns the active L3 per-cell variance gate multiplier.
+func (c *TuningConfig) GetVarianceThresholdK() float64 {
+	return c.L3.ActiveCommon().VarianceThresholdK
+}
+
+// GetRegionWarmupMinFrames returns the active L3 per-region warmup frame count.
+func (c *TuningConfig) GetRegionWarmupMinFrames() int {
+	return c.L3.ActiveCommon().RegionWarmupMinFrames
+}
+
 // GetHeightBandFloor returns the active L4 lower height-band bound.
 func (c *TuningConfig) GetHeightBandFloor() float64 { return c.L4.ActiveCommon().HeightBandFloor }
 
@@ -304,6 +349,98 @@ func (c *TuningConfig) GetMaxClusterAspectRatio() float64 {
 	return c.L4.ActiveCommon().MaxClusterAspectRatio
 }
 
+// GetMinPtsReferenceRange returns the active L4 range beyond which DBSCAN
+// MinPts decays. Zero disables range-adaptive MinPts.
+func (c *TuningConfig) GetMinPtsReferenceRange() float64 {
+	return c.L4.ActiveCommon().MinPtsReferenceRange
+}
+
+// GetMinPtsFloor returns the active L4 lower bound for range-adaptive MinPts.
+func (c *TuningConfig) GetMinPtsFloor() int { return c.L4.ActiveCommon().MinPtsFloor }
+
+// GetVoxelOrigin returns the active L4 world-frame voxel grid corner.
+func (c *TuningConfig) GetVoxelOrigin() [3]float64 { return c.L4.ActiveCommon().VoxelOrigin }
+
+// GetVoxelSnapToGrid returns whether voxel downsampling emits voxel centres.
+func (c *TuningConfig) GetVoxelSnapToGrid() bool { return c.L4.ActiveCommon().VoxelSnapToGrid }
+
+// GetClusterHeightWeight returns the active L4 DBSCAN height feature weight.
+func (c *TuningConfig) GetClusterHeightWeight() float64 {
+	return c.L4.ActiveCommon().ClusterHeightWeight
+}
+
+// GetClusterIntensityWeight returns the active L4 DBSCAN intensity feature weight.
+func (c *TuningConfig) GetClusterIntensityWeight() float64 {
+	return c.L4.ActiveCommon().ClusterIntensityWeight
+}
+
+// GetClusterMergeSeparation returns the active L4 fragment-merge centroid
+// distance; zero disables merging.
+func (c *TuningConfig) GetClusterMergeSeparation() float64 {
+	return c.L4.ActiveCommon().ClusterMergeSeparation
+}
+
+// GetClusterMergeMaxLength returns the active L4 largest merged cluster length.
+func (c *TuningConfig) GetClusterMergeMaxLength() float64 {
+	return c.L4.ActiveCommon().ClusterMergeMaxLength
+}
+
+// GetClusterMergeMaxWidth returns the active L4 largest merged cluster width.
+func (c *TuningConfig) GetClusterMergeMaxWidth() float64 {
+	return c.L4.ActiveCommon().ClusterMergeMaxWidth
+}
+
+// GetBloomMinIntensity returns the active L4 saturated-return intensity for
+// bloom removal; zero disables the filter.
+func (c *TuningConfig) GetBloomMinIntensity() int {
+	return c.L4.ActiveCommon().BloomMinIntensity
+}
+
+// GetBloomMaxExtent returns the active L4 largest bloom size in metres.
+func (c *TuningConfig) GetBloomMaxExtent() float64 {
+	return c.L4.ActiveCommon().BloomMaxExtent
+}
+
+// GetBloomMinPoints returns the active L4 fewest saturated points in a bloom.
+func (c *TuningConfig) GetBloomMinPoints() int {
+	return c.L4.ActiveCommon().BloomMinPoints
+}
+
+// GetBloomStaticFrames returns the active L4 frames a bloom must stay put
+// before it is removed.
+func (c *TuningConfig) GetBloomStaticFrames() int {
+	return c.L4.ActiveCommon().BloomStaticFrames
+}
+
+// GetSlowMoverWindow returns the active L4 slow-mover accumulation window in
+// frames; zero or one disables accumulation.
+func (c *TuningConfig) GetSlowMoverWindow() int {
+	return c.L4.ActiveCommon().SlowMoverWindow
+}
+
+// GetSlowMoverMinFrames returns the active L4 frames in the window a cell
+// needs foreground in to count as persistent.
+func (c *TuningConfig) GetSlowMoverMinFrames() int {
+	return c.L4.ActiveCommon().SlowMoverMinFrames
+}
+
+// GetRegionContinuity reports whether cluster fragments split across a
+// background region boundary are rejoined before track association.
+func (c *TuningConfig) GetRegionContinuity() bool {
+	return c.L4.ActiveCommon().RegionContinuity
+}
+
+// GetClusterStableIDs reports whether clusters carry stable IDs across frames.
+func (c *TuningConfig) GetClusterStableIDs() bool {
+	return c.L4.ActiveCommon().ClusterStableIDs
+}
+
+// GetClusterWorkers returns the active L4 goroutine count for DBSCAN
+// neighbour queries; 0 or 1 runs serially.
+func (c *TuningConfig) GetClusterWorkers() int {
+	return c.L4.ActiveCommon().ClusterWorkers
+}
+
 // GetMaxReasonableSpeedMps returns the active L5 max speed limit.
 func (c *TuningConfig) GetMaxReasonableSpeedMps() float64 {
 	return c.L5.ActiveCommon().MaxReasonableSpeedMps
@@ -359,3 +496,32 @@ func (c *TuningConfig) GetDeletedTrackGracePeriod() time.Duration {
 func (c *TuningConfig) GetMinObservationsForClassification() int {
 	return c.L5.ActiveCommon().MinObservationsForClassification
 }
+
+// GetSizeInstabilityWeight returns the classifier's confidence penalty
+// weight for tracks with unstable bounding-box dimensions.
+func (c *TuningConfig) GetSizeInstabilityWeight() float64 {
+	return c.L5.ActiveCommon().SizeInstabilityWeight
+}
+
+// GetGatingMode returns the active L5 association gate shape.
+func (c *TuningConfig) GetGatingMode() string { return c.L5.ActiveCommon().GatingMode }
+
+// GetGatingChiSquare returns the active L5 chi-square gate for mahalanobis gating.
+func (c *TuningConfig) GetGatingChiSquare() float64 { return c.L5.ActiveCommon().GatingChiSquare }
+
+// GetGatingAlongTrackFraction returns the along-track 1σ of the mahalanobis
+// gate as a fraction of the predicted displacement.
+func (c *TuningConfig) GetGatingAlongTrackFraction() float64 {
+	return c.L5.ActiveCommon().GatingAlongTrackFraction
+}
+
+// GetSpeedSmoothingFrames returns the active L5 reported-speed smoothing window.
+func (c *TuningConfig) GetSpeedSmoothingFrames() int {
+	return c.L5.ActiveCommon().SpeedSmoothingFrames
+}
+
+// GetShapeAssociationWeight returns the active L5 weight of predicted box
+// overlap in association costs.
+func (c *TuningConfig) GetShapeAssociationWeight() float64 {
+	return c.L5.ActiveCommon().ShapeAssociationWeight
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -131,6 +132,49 @@ func TestL1ConfigValidateErrors(t *testing.T) {
 			},
 			wantText: "data_source must be one of live, pcap, pcap_analysis",
 		},
+		{
+			name: "negative min range",
+			mutate: func(cfg *L1Config) {
+				cfg.MinRangeMetres = -1
+			},
+			wantText: "range bounds must be non-negative",
+		},
+		{
+			name: "inverted range",
+			mutate: func(cfg *L1Config) {
+				cfg.MinRangeMetres, cfg.MaxRangeMetres = 5, 2
+			},
+			wantText: "min_range_metres 5.000000 must be below max_range_metres 2.000000",
+		},
+		{
+			name: "ring zero",
+			mutate: func(cfg *L1Config) {
+				cfg.RingRangeClip = map[int]RangeClipWindow{0: {MinMetres: 1}}
+			},
+			wantText: "ring_range_clip: channel numbers start at 1",
+		},
+		{
+			name: "ring inverted against inherited bound",
+			mutate: func(cfg *L1Config) {
+				cfg.MaxRangeMetres = 80
+				cfg.RingRangeClip = map[int]RangeClipWindow{3: {MinMetres: 90}}
+			},
+			wantText: "ring_range_clip ring 3: min_range_metres",
+		},
+		{
+			name: "bad timestamp backstep",
+			mutate: func(cfg *L1Config) {
+				cfg.TimestampMaxBackstep = "soon"
+			},
+			wantText: "invalid timestamp_max_backstep",
+		},
+		{
+			name: "negative timestamp backstep",
+			mutate: func(cfg *L1Config) {
+				cfg.TimestampMaxBackstep = "-1ms"
+			},
+			wantText: "timestamp_max_backstep must be non-negative",
+		},
 	}
 
 	for _, tc := range tests {
@@ -383,6 +427,11 @@ func TestCommonAndVariantValidateErrors(t *testing.T) {
 		{"spread delta", func(cfg *L3Common) { cfg.SettlingMaxSpreadDelta = -1 }, "settling_max_spread_delta must be non-negative"},
 		{"region stability", func(cfg *L3Common) { cfg.SettlingMinRegionStability = 2 }, "settling_min_region_stability must be in [0, 1]"},
 		{"settling confidence", func(cfg *L3Common) { cfg.SettlingMinConfidence = -1 }, "settling_min_confidence must be non-negative"},
+		{"azimuth tolerance", func(cfg *L3Common) { cfg.AzimuthToleranceDeg = 6 }, "azimuth_tolerance_deg must be in [0, 5]"},
+		{"range tolerance", func(cfg *L3Common) { cfg.RangeToleranceSigmaK = -1 }, "range_tolerance_sigma_k must be non-negative"},
+		{"background model", func(cfg *L3Common) { cfg.BackgroundModel = "kde" }, "background_model must be one of mean, median, gmm"},
+		{"variance threshold k", func(cfg *L3Common) { cfg.VarianceThresholdK = -1 }, "variance_threshold_k must be non-negative"},
+		{"region warmup frames", func(cfg *L3Common) { cfg.RegionWarmupMinFrames = -1 }, "region_warmup_min_frames must be non-negative"},
 	}
 
 	for _, tc := range l3Tests {
@@ -440,6 +489,22 @@ func TestCommonAndVariantValidateErrors(t *testing.T) {
 		{"max diameter", func(cfg *L4Common) { cfg.MaxClusterDiameter = 0 }, "max_cluster_diameter must be positive"},
 		{"min diameter", func(cfg *L4Common) { cfg.MinClusterDiameter = 0 }, "min_cluster_diameter must be positive"},
 		{"aspect ratio", func(cfg *L4Common) { cfg.MaxClusterAspectRatio = 0 }, "max_cluster_aspect_ratio must be positive"},
+		{"min pts reference range", func(cfg *L4Common) { cfg.MinPtsReferenceRange = -1 }, "min_pts_reference_range must be non-negative"},
+		{"min pts floor low", func(cfg *L4Common) { cfg.MinPtsFloor = 1 }, "min_pts_floor must be in [2, foreground_min_cluster_points]"},
+		{"min pts floor high", func(cfg *L4Common) { cfg.MinPtsFloor = cfg.ForegroundMinClusterPoints + 1 }, "min_pts_floor must be in [2, foreground_min_cluster_points]"},
+		{"voxel origin", func(cfg *L4Common) { cfg.VoxelOrigin[1] = math.Inf(1) }, "voxel_origin must be finite"},
+		{"height weight", func(cfg *L4Common) { cfg.ClusterHeightWeight = -1 }, "cluster_height_weight must be non-negative"},
+		{"intensity weight", func(cfg *L4Common) { cfg.ClusterIntensityWeight = -0.1 }, "cluster_intensity_weight must be non-negative"},
+		{"merge separation", func(cfg *L4Common) { cfg.ClusterMergeSeparation = -1 }, "cluster_merge_separation must be non-negative"},
+		{"merge max length", func(cfg *L4Common) { cfg.ClusterMergeMaxLength = 0 }, "cluster_merge_max_length must be positive"},
+		{"merge max width", func(cfg *L4Common) { cfg.ClusterMergeMaxWidth = cfg.ClusterMergeMaxLength + 1 }, "cluster_merge_max_width must be in (0, cluster_merge_max_length]"},
+		{"bloom intensity", func(cfg *L4Common) { cfg.BloomMinIntensity = 256 }, "bloom_min_intensity must be in [0, 255]"},
+		{"bloom extent", func(cfg *L4Common) { cfg.BloomMaxExtent = 0 }, "bloom_max_extent must be positive"},
+		{"bloom points", func(cfg *L4Common) { cfg.BloomMinPoints = 0 }, "bloom_min_points must be at least 1"},
+		{"bloom frames", func(cfg *L4Common) { cfg.BloomStaticFrames = 0 }, "bloom_static_frames must be at least 1"},
+		{"slow mover window", func(cfg *L4Common) { cfg.SlowMoverWindow = -1 }, "slow_mover_window must be non-negative"},
+		{"slow mover min frames", func(cfg *L4Common) { cfg.SlowMoverWindow = 4; cfg.SlowMoverMinFrames = 5 }, "slow_mover_min_frames must be in [1, slow_mover_window]"},
+		{"cluster workers", func(cfg *L4Common) { cfg.ClusterWorkers = -1 }, "cluster_workers must be non-negative"},
 	}
 
 	for _, tc := range l4Tests {
@@ -523,7 +588,8 @@ func TestCommonAndVariantValidateErrors(t *testing.T) {
 		{"hits", func(cfg *L5Common) { cfg.HitsToConfirm = 0 }, "hits_to_confirm must be >= 1"},
 		{"max misses", func(cfg *L5Common) { cfg.MaxMisses = 0 }, "max_misses must be >= 1"},
 		{"max misses confirmed", func(cfg *L5Common) { cfg.MaxMissesConfirmed = 0 }, "max_misses_confirmed must be >= 1"},
-		{"max tracks", func(cfg *L5Common) { cfg.MaxTracks = 1001 }, "max_tracks must be in [1, 1000]"},
+		{"max tracks", func(cfg *L5Common) { cfg.MaxTracks = 1001 }, "max_tracks must be in [0, 1000]"},
+		{"eviction policy", func(cfg *L5Common) { cfg.EvictionPolicy = "newest" }, "eviction_policy must be one of"},
 		{"max speed", func(cfg *L5Common) { cfg.MaxReasonableSpeedMps = 0 }, "max_reasonable_speed_mps must be positive"},
 		{"max jump", func(cfg *L5Common) { cfg.MaxPositionJumpMetres = 0 }, "max_position_jump_metres must be positive"},
 		{"predict dt", func(cfg *L5Common) { cfg.MaxPredictDt = 0 }, "max_predict_dt must be positive"},
@@ -537,6 +603,12 @@ func TestCommonAndVariantValidateErrors(t *testing.T) {
 		{"split ratio", func(cfg *L5Common) { cfg.SplitSizeRatio = 0 }, "split_size_ratio must be positive"},
 		{"grace period", func(cfg *L5Common) { cfg.DeletedTrackGracePeriod = "bad" }, "invalid deleted_track_grace_period"},
 		{"min observations", func(cfg *L5Common) { cfg.MinObservationsForClassification = 0 }, "min_observations_for_classification must be >= 1"},
+		{"size instability weight", func(cfg *L5Common) { cfg.SizeInstabilityWeight = -0.1 }, "size_instability_weight must be >= 0"},
+		{"gating mode", func(cfg *L5Common) { cfg.GatingMode = "elliptic" }, "gating_mode must be one of"},
+		{"gating chi square", func(cfg *L5Common) { cfg.GatingChiSquare = 0 }, "gating_chi_square must be positive"},
+		{"gating along track", func(cfg *L5Common) { cfg.GatingAlongTrackFraction = -1 }, "gating_along_track_fraction must be positive"},
+		{"speed smoothing frames", func(cfg *L5Common) { cfg.SpeedSmoothingFrames = -1 }, "speed_smoothing_frames must be >= 0"},
+		{"shape association weight", func(cfg *L5Common) { cfg.ShapeAssociationWeight = -0.1 }, "shape_association_weight must be >= 0"},
 	}
 
 	for _, tc := range l5Tests {
@@ -648,7 +720,7 @@ func TestStrictEngineUnmarshalAndHelpers(t *testing.T) {
 	t.Parallel()
 
 	t.Run("l3 baseline", func(t *testing.T) {
-		raw := []byte(`{"engine":"ema_baseline_v1","ema_baseline_v1":{"background_update_fraction":0.02,"closeness_multiplier":3,"safety_margin_metres":0.15,"noise_relative":0.02,"neighbour_confirmation_count":3,"seed_from_first":true,"warmup_duration_nanos":30000000000,"warmup_min_frames":100,"post_settle_update_fraction":0,"enable_diagnostics":false,"freeze_duration":"5s","freeze_threshold_multiplier":3,"settling_period":"5m","snapshot_interval":"2h","change_threshold_snapshot":100,"reacquisition_boost_multiplier":5,"min_confidence_floor":3,"locked_baseline_threshold":50,"locked_baseline_multiplier":4,"sensor_movement_foreground_threshold":0.2,"background_drift_threshold_metres":0.5,"background_drift_ratio_threshold":0.1,"settling_min_coverage":0.8,"settling_max_spread_delta":0.001,"settling_min_region_stability":0.95,"settling_min_confidence":10}}`)
+		raw := []byte(`{"engine":"ema_baseline_v1","ema_baseline_v1":{"background_model":"mean","variance_threshold_k":0,"region_warmup_min_frames":0,"background_update_fraction":0.02,"closeness_multiplier":3,"safety_margin_metres":0.15,"noise_relative":0.02,"neighbour_confirmation_count":3,"seed_from_first":true,"warmup_duration_nanos":30000000000,"warmup_min_frames":100,"post_settle_update_fraction":0,"enable_diagnostics":false,"freeze_duration":"5s","freeze_threshold_multiplier":3,"settling_period":"5m","snapshot_interval":"2h","change_threshold_snapshot":100,"reacquisition_boost_multiplier":5,"min_confidence_floor":3,"locked_baseline_threshold":50,"locked_baseline_multiplier":4,"sensor_movement_foreground_threshold":0.2,"background_drift_threshold_metres":0.5,"background_drift_ratio_threshold":0.1,"settling_min_coverage":0.8,"settling_max_spread_delta":0.001,"settling_min_region_stability":0.95,"settling_min_confidence":10,"azimuth_tolerance_deg":0,"range_tolerance_sigma_k":0}}`)
 		var cfg L3Config
 		if err := json.Unmarshal(raw, &cfg); err != nil {
 			t.Fatalf("unmarshal l3 baseline: %v", err)
@@ -659,7 +731,7 @@ func TestStrictEngineUnmarshalAndHelpers(t *testing.T) {
 	})
 
 	t.Run("l3 track assist", func(t *testing.T) {
-		raw := []byte(`{"engine":"ema_track_assist_v2","ema_track_assist_v2":{"background_update_fraction":0.02,"closeness_multiplier":3,"safety_margin_metres":0.15,"noise_relative":0.02,"neighbour_confirmation_count":3,"seed_from_first":true,"warmup_duration_nanos":30000000000,"warmup_min_frames":100,"post_settle_update_fraction":0,"enable_diagnostics":false,"freeze_duration":"5s","freeze_threshold_multiplier":3,"settling_period":"5m","snapshot_interval":"2h","change_threshold_snapshot":100,"reacquisition_boost_multiplier":5,"min_confidence_floor":3,"locked_baseline_threshold":50,"locked_baseline_multiplier":4,"sensor_movement_foreground_threshold":0.2,"background_drift_threshold_metres":0.5,"background_drift_ratio_threshold":0.1,"settling_min_coverage":0.8,"settling_max_spread_delta":0.001,"settling_min_region_stability":0.95,"settling_min_confidence":10,"promotion_near_gate_low":0.1,"promotion_near_gate_high":0.2,"promotion_threshold":0.3}}`)
+		raw := []byte(`{"engine":"ema_track_assist_v2","ema_track_assist_v2":{"background_model":"mean","variance_threshold_k":0,"region_warmup_min_frames":0,"background_update_fraction":0.02,"closeness_multiplier":3,"safety_margin_metres":0.15,"noise_relative":0.02,"neighbour_confirmation_count":3,"seed_from_first":true,"warmup_duration_nanos":30000000000,"warmup_min_frames":100,"post_settle_update_fraction":0,"enable_diagnostics":false,"freeze_duration":"5s","freeze_threshold_multiplier":3,"settling_period":"5m","snapshot_interval":"2h","change_threshold_snapshot":100,"reacquisition_boost_multiplier":5,"min_confidence_floor":3,"locked_baseline_threshold":50,"locked_baseline_multiplier":4,"sensor_movement_foreground_threshold":0.2,"background_drift_threshold_metres":0.5,"background_drift_ratio_threshold":0.1,"settling_min_coverage":0.8,"settling_max_spread_delta":0.001,"settling_min_region_stability":0.95,"settling_min_confidence":10,"azimuth_tolerance_deg":0,"range_tolerance_sigma_k":0,"promotion_near_gate_low":0.1,"promotion_near_gate_high":0.2,"promotion_threshold":0.3}}`)
 		var cfg L3Config
 		if err := json.Unmarshal(raw, &cfg); err != nil {
 			t.Fatalf("unmarshal l3 track assist: %v", err)
@@ -671,9 +743,9 @@ func TestStrictEngineUnmarshalAndHelpers(t *testing.T) {
 
 	t.Run("l4 variants", func(t *testing.T) {
 		cases := []string{
-			`{"engine":"dbscan_xy_v1","dbscan_xy_v1":{"foreground_dbscan_eps":0.8,"foreground_min_cluster_points":5,"foreground_max_input_points":8000,"height_band_floor":-2.8,"height_band_ceiling":1.5,"remove_ground":true,"max_cluster_diameter":12,"min_cluster_diameter":0.05,"max_cluster_aspect_ratio":15}}`,
-			`{"engine":"two_stage_mahalanobis_v2","two_stage_mahalanobis_v2":{"foreground_dbscan_eps":0.8,"foreground_min_cluster_points":5,"foreground_max_input_points":8000,"height_band_floor":-2.8,"height_band_ceiling":1.5,"remove_ground":true,"max_cluster_diameter":12,"min_cluster_diameter":0.05,"max_cluster_aspect_ratio":15,"velocity_coherence_gate":1,"min_velocity_confidence":0.5}}`,
-			`{"engine":"hdbscan_adaptive_v1","hdbscan_adaptive_v1":{"foreground_dbscan_eps":0.8,"foreground_min_cluster_points":5,"foreground_max_input_points":8000,"height_band_floor":-2.8,"height_band_ceiling":1.5,"remove_ground":true,"max_cluster_diameter":12,"min_cluster_diameter":0.05,"max_cluster_aspect_ratio":15,"min_cluster_size":4,"min_samples":2}}`,
+			`{"engine":"dbscan_xy_v1","dbscan_xy_v1":{"cluster_merge_separation":0,"cluster_merge_max_length":12,"cluster_merge_max_width":3,"bloom_min_intensity":0,"bloom_max_extent":1.0,"bloom_min_points":5,"bloom_static_frames":10,"slow_mover_window":0,"slow_mover_min_frames":6,"region_continuity":false,"cluster_stable_ids":false,"cluster_workers":0,"foreground_dbscan_eps":0.8,"foreground_min_cluster_points":5,"foreground_max_input_points":8000,"height_band_floor":-2.8,"height_band_ceiling":1.5,"remove_ground":true,"max_cluster_diameter":12,"min_cluster_diameter":0.05,"max_cluster_aspect_ratio":15,"cluster_intensity_weight":0,"cluster_height_weight":0,"voxel_snap_to_grid":false,"voxel_origin":[0,0,0],"min_pts_floor":2,"min_pts_reference_range":0}}`,
+			`{"engine":"two_stage_mahalanobis_v2","two_stage_mahalanobis_v2":{"cluster_merge_separation":0,"cluster_merge_max_length":12,"cluster_merge_max_width":3,"bloom_min_intensity":0,"bloom_max_extent":1.0,"bloom_min_points":5,"bloom_static_frames":10,"slow_mover_window":0,"slow_mover_min_frames":6,"region_continuity":false,"cluster_stable_ids":false,"cluster_workers":0,"foreground_dbscan_eps":0.8,"foreground_min_cluster_points":5,"foreground_max_input_points":8000,"height_band_floor":-2.8,"height_band_ceiling":1.5,"remove_ground":true,"max_cluster_diameter":12,"min_cluster_diameter":0.05,"max_cluster_aspect_ratio":15,"cluster_intensity_weight":0,"cluster_height_weight":0,"voxel_snap_to_grid":false,"voxel_origin":[0,0,0],"min_pts_floor":2,"min_pts_reference_range":0,"velocity_coherence_gate":1,"min_velocity_confidence":0.5}}`,
+			`{"engine":"hdbscan_adaptive_v1","hdbscan_adaptive_v1":{"cluster_merge_separation":0,"cluster_merge_max_length":12,"cluster_merge_max_width":3,"bloom_min_intensity":0,"bloom_max_extent":1.0,"bloom_min_points":5,"bloom_static_frames":10,"slow_mover_window":0,"slow_mover_min_frames":6,"region_continuity":false,"cluster_stable_ids":false,"cluster_workers":0,"foreground_dbscan_eps":0.8,"foreground_min_cluster_points":5,"foreground_max_input_points":8000,"height_band_floor":-2.8,"height_band_ceiling":1.5,"remove_ground":true,"max_cluster_diameter":12,"min_cluster_diameter":0.05,"max_cluster_aspect_ratio":15,"cluster_intensity_weight":0,"cluster_height_weight":0,"voxel_snap_to_grid":false,"voxel_origin":[0,0,0],"min_pts_floor":2,"min_pts_reference_range":0,"min_cluster_size":4,"min_samples":2}}`,
 		}
 		for _, raw := range cases {
 			var cfg L4Config
@@ -688,9 +760,9 @@ func TestStrictEngineUnmarshalAndHelpers(t *testing.T) {
 
 	t.Run("l5 variants", func(t *testing.T) {
 		cases := []string{
-			`{"engine":"cv_kf_v1","cv_kf_v1":{"gating_distance_squared":36,"process_noise_pos":0.05,"process_noise_vel":0.2,"measurement_noise":0.05,"occlusion_cov_inflation":0.5,"hits_to_confirm":4,"max_misses":3,"max_misses_confirmed":15,"max_tracks":100,"max_reasonable_speed_mps":30,"max_position_jump_metres":5,"max_predict_dt":0.5,"max_covariance_diag":100,"min_points_for_pca":4,"obb_heading_smoothing_alpha":0.08,"obb_aspect_ratio_lock_threshold":0.25,"max_track_history_length":200,"max_speed_history_length":100,"merge_size_ratio":2.5,"split_size_ratio":0.3,"deleted_track_grace_period":"5s","min_observations_for_classification":5}}`,
-			`{"engine":"imm_cv_ca_v2","imm_cv_ca_v2":{"gating_distance_squared":36,"process_noise_pos":0.05,"process_noise_vel":0.2,"measurement_noise":0.05,"occlusion_cov_inflation":0.5,"hits_to_confirm":4,"max_misses":3,"max_misses_confirmed":15,"max_tracks":100,"max_reasonable_speed_mps":30,"max_position_jump_metres":5,"max_predict_dt":0.5,"max_covariance_diag":100,"min_points_for_pca":4,"obb_heading_smoothing_alpha":0.08,"obb_aspect_ratio_lock_threshold":0.25,"max_track_history_length":200,"max_speed_history_length":100,"merge_size_ratio":2.5,"split_size_ratio":0.3,"deleted_track_grace_period":"5s","min_observations_for_classification":5,"transition_cv_to_ca":0.2,"transition_ca_to_cv":0.1,"ca_process_noise_acc":1,"low_speed_heading_freeze_mps":0.5}}`,
-			`{"engine":"imm_cv_ca_rts_eval_v2","imm_cv_ca_rts_eval_v2":{"gating_distance_squared":36,"process_noise_pos":0.05,"process_noise_vel":0.2,"measurement_noise":0.05,"occlusion_cov_inflation":0.5,"hits_to_confirm":4,"max_misses":3,"max_misses_confirmed":15,"max_tracks":100,"max_reasonable_speed_mps":30,"max_position_jump_metres":5,"max_predict_dt":0.5,"max_covariance_diag":100,"min_points_for_pca":4,"obb_heading_smoothing_alpha":0.08,"obb_aspect_ratio_lock_threshold":0.25,"max_track_history_length":200,"max_speed_history_length":100,"merge_size_ratio":2.5,"split_size_ratio":0.3,"deleted_track_grace_period":"5s","min_observations_for_classification":5,"transition_cv_to_ca":0.2,"transition_ca_to_cv":0.1,"ca_process_noise_acc":1,"low_speed_heading_freeze_mps":0.5,"rts_smoothing_window":4}}`,
+			`{"engine":"cv_kf_v1","cv_kf_v1":{"gating_mode":"isotropic","gating_chi_square":9.21,"gating_along_track_fraction":0.5,"speed_smoothing_frames":0,"shape_association_weight":0,"gating_distance_squared":36,"process_noise_pos":0.05,"process_noise_vel":0.2,"measurement_noise":0.05,"occlusion_cov_inflation":0.5,"hits_to_confirm":4,"max_misses":3,"max_misses_confirmed":15,"max_tracks":100,"max_reasonable_speed_mps":30,"max_position_jump_metres":5,"max_predict_dt":0.5,"max_covariance_diag":100,"min_points_for_pca":4,"obb_heading_smoothing_alpha":0.08,"obb_aspect_ratio_lock_threshold":0.25,"max_track_history_length":200,"max_speed_history_length":100,"merge_size_ratio":2.5,"split_size_ratio":0.3,"deleted_track_grace_period":"5s","min_observations_for_classification":5,"size_instability_weight":0.5,"eviction_policy":"none"}}`,
+			`{"engine":"imm_cv_ca_v2","imm_cv_ca_v2":{"gating_mode":"isotropic","gating_chi_square":9.21,"gating_along_track_fraction":0.5,"speed_smoothing_frames":0,"shape_association_weight":0,"gating_distance_squared":36,"process_noise_pos":0.05,"process_noise_vel":0.2,"measurement_noise":0.05,"occlusion_cov_inflation":0.5,"hits_to_confirm":4,"max_misses":3,"max_misses_confirmed":15,"max_tracks":100,"max_reasonable_speed_mps":30,"max_position_jump_metres":5,"max_predict_dt":0.5,"max_covariance_diag":100,"min_points_for_pca":4,"obb_heading_smoothing_alpha":0.08,"obb_aspect_ratio_lock_threshold":0.25,"max_track_history_length":200,"max_speed_history_length":100,"merge_size_ratio":2.5,"split_size_ratio":0.3,"deleted_track_grace_period":"5s","min_observations_for_classification":5,"size_instability_weight":0.5,"eviction_policy":"none","transition_cv_to_ca":0.2,"transition_ca_to_cv":0.1,"ca_process_noise_acc":1,"low_speed_heading_freeze_mps":0.5}}`,
+			`{"engine":"imm_cv_ca_rts_eval_v2","imm_cv_ca_rts_eval_v2":{"gating_mode":"isotropic","gating_chi_square":9.21,"gating_along_track_fraction":0.5,"speed_smoothing_frames":0,"shape_association_weight":0,"gating_distance_squared":36,"process_noise_pos":0.05,"process_noise_vel":0.2,"measurement_noise":0.05,"occlusion_cov_inflation":0.5,"hits_to_confirm":4,"max_misses":3,"max_misses_confirmed":15,"max_tracks":100,"max_reasonable_speed_mps":30,"max_position_jump_metres":5,"max_predict_dt":0.5,"max_covariance_diag":100,"min_points_for_pca":4,"obb_heading_smoothing_alpha":0.08,"obb_aspect_ratio_lock_threshold":0.25,"max_track_history_length":200,"max_speed_history_length":100,"merge_size_ratio":2.5,"split_size_ratio":0.3,"deleted_track_grace_period":"5s","min_observations_for_classification":5,"size_instability_weight":0.5,"eviction_policy":"none","transition_cv_to_ca":0.2,"transition_ca_to_cv":0.1,"ca_process_noise_acc":1,"low_speed_heading_freeze_mps":0.5,"rts_smoothing_window":4}}`,
 		}
 		for _, raw := range cases {
 			var cfg L5Config
@@ -764,7 +836,7 @@ func TestDecodeAndReflectionHelpers(t *testing.T) {
 	t.Run("decodeSelectedEngineBlock", func(t *testing.T) {
 		raw := map[string]json.RawMessage{
 			"engine":          json.RawMessage(`"ema_baseline_v1"`),
-			"ema_baseline_v1": json.RawMessage(`{"background_update_fraction":0.02,"closeness_multiplier":3,"safety_margin_metres":0.15,"noise_relative":0.02,"neighbour_confirmation_count":3,"seed_from_first":true,"warmup_duration_nanos":30000000000,"warmup_min_frames":100,"post_settle_update_fraction":0,"enable_diagnostics":false,"freeze_duration":"5s","freeze_threshold_multiplier":3,"settling_period":"5m","snapshot_interval":"2h","change_threshold_snapshot":100,"reacquisition_boost_multiplier":5,"min_confidence_floor":3,"locked_baseline_threshold":50,"locked_baseline_multiplier":4,"sensor_movement_foreground_threshold":0.2,"background_drift_threshold_metres":0.5,"background_drift_ratio_threshold":0.1,"settling_min_coverage":0.8,"settling_max_spread_delta":0.001,"settling_min_region_stability":0.95,"settling_min_confidence":10}`),
+			"ema_baseline_v1": json.RawMessage(`{"background_model":"mean","variance_threshold_k":0,"region_warmup_min_frames":0,"background_update_fraction":0.02,"closeness_multiplier":3,"safety_margin_metres":0.15,"noise_relative":0.02,"neighbour_confirmation_count":3,"seed_from_first":true,"warmup_duration_nanos":30000000000,"warmup_min_frames":100,"post_settle_update_fraction":0,"enable_diagnostics":false,"freeze_duration":"5s","freeze_threshold_multiplier":3,"settling_period":"5m","snapshot_interval":"2h","change_threshold_snapshot":100,"reacquisition_boost_multiplier":5,"min_confidence_floor":3,"locked_baseline_threshold":50,"locked_baseline_multiplier":4,"sensor_movement_foreground_threshold":0.2,"background_drift_threshold_metres":0.5,"background_drift_ratio_threshold":0.1,"settling_min_coverage":0.8,"settling_max_spread_delta":0.001,"settling_min_region_stability":0.95,"settling_min_confidence":10,"azimuth_tolerance_deg":0,"range_tolerance_sigma_k":0}`),
 		}
 		block, err := decodeSelectedEngineBlock[L3EmaBaselineV1](raw, "l3", "ema_baseline_v1")
 		if err != nil || block == nil {
@@ -836,6 +908,10 @@ func TestTuningConfigGettersAndActiveConfig(t *testing.T) {
 	cfg := sampleValidConfig()
 	if cfg.GetSensor() != cfg.L1.Sensor ||
 		cfg.GetDataSource() != cfg.L1.DataSource ||
+		cfg.GetMinRangeMetres() != cfg.L1.MinRangeMetres ||
+		cfg.GetMaxRangeMetres() != cfg.L1.MaxRangeMetres ||
+		len(cfg.GetRingRangeClip()) != len(cfg.L1.RingRangeClip) ||
+		cfg.GetTimestampMaxBackstep() != 0 ||
 		cfg.GetMinFramePoints() != cfg.Pipeline.MinFramePoints ||
 		cfg.GetBackgroundFlush() != cfg.Pipeline.BackgroundFlush ||
 		cfg.GetNoiseRelative() != cfg.L3.EmaBaselineV1.NoiseRelative ||
@@ -857,6 +933,7 @@ func TestTuningConfigGettersAndActiveConfig(t *testing.T) {
 		cfg.GetMaxMisses() != cfg.L5.CvKfV1.MaxMisses ||
 		cfg.GetMaxMissesConfirmed() != cfg.L5.CvKfV1.MaxMissesConfirmed ||
 		cfg.GetMaxTracks() != cfg.L5.CvKfV1.MaxTracks ||
+		cfg.GetEvictionPolicy() != cfg.L5.CvKfV1.EvictionPolicy ||
 		cfg.GetBackgroundUpdateFraction() != cfg.L3.EmaBaselineV1.BackgroundUpdateFraction ||
 		cfg.GetSafetyMarginMetres() != cfg.L3.EmaBaselineV1.SafetyMarginMetres ||
 		cfg.GetEnableDiagnostics() != cfg.L3.EmaBaselineV1.EnableDiagnostics ||
@@ -873,12 +950,35 @@ func TestTuningConfigGettersAndActiveConfig(t *testing.T) {
 		cfg.GetSettlingMaxSpreadDelta() != cfg.L3.EmaBaselineV1.SettlingMaxSpreadDelta ||
 		cfg.GetSettlingMinRegionStability() != cfg.L3.EmaBaselineV1.SettlingMinRegionStability ||
 		cfg.GetSettlingMinConfidence() != cfg.L3.EmaBaselineV1.SettlingMinConfidence ||
+		cfg.GetAzimuthToleranceDeg() != cfg.L3.EmaBaselineV1.AzimuthToleranceDeg ||
+		cfg.GetRangeToleranceSigmaK() != cfg.L3.EmaBaselineV1.RangeToleranceSigmaK ||
+		cfg.GetBackgroundModel() != cfg.L3.EmaBaselineV1.BackgroundModel ||
+		cfg.GetVarianceThresholdK() != cfg.L3.EmaBaselineV1.VarianceThresholdK ||
+		cfg.GetRegionWarmupMinFrames() != cfg.L3.EmaBaselineV1.RegionWarmupMinFrames ||
 		cfg.GetHeightBandFloor() != cfg.L4.DbscanXyV1.HeightBandFloor ||
 		cfg.GetHeightBandCeiling() != cfg.L4.DbscanXyV1.HeightBandCeiling ||
 		cfg.GetRemoveGround() != cfg.L4.DbscanXyV1.RemoveGround ||
 		cfg.GetMaxClusterDiameter() != cfg.L4.DbscanXyV1.MaxClusterDiameter ||
 		cfg.GetMinClusterDiameter() != cfg.L4.DbscanXyV1.MinClusterDiameter ||
 		cfg.GetMaxClusterAspectRatio() != cfg.L4.DbscanXyV1.MaxClusterAspectRatio ||
+		cfg.GetMinPtsReferenceRange() != cfg.L4.DbscanXyV1.MinPtsReferenceRange ||
+		cfg.GetMinPtsFloor() != cfg.L4.DbscanXyV1.MinPtsFloor ||
+		cfg.GetVoxelOrigin() != cfg.L4.DbscanXyV1.VoxelOrigin ||
+		cfg.GetVoxelSnapToGrid() != cfg.L4.DbscanXyV1.VoxelSnapToGrid ||
+		cfg.GetClusterHeightWeight() != cfg.L4.DbscanXyV1.ClusterHeightWeight ||
+		cfg.GetClusterIntensityWeight() != cfg.L4.DbscanXyV1.ClusterIntensityWeight ||
+		cfg.GetClusterMergeSeparation() != cfg.L4.DbscanXyV1.ClusterMergeSeparation ||
+		cfg.GetClusterMergeMaxLength() != cfg.L4.DbscanXyV1.ClusterMergeMaxLength ||
+		cfg.GetClusterMergeMaxWidth() != cfg.L4.DbscanXyV1.ClusterMergeMaxWidth ||
+		cfg.GetBloomMinIntensity() != cfg.L4.DbscanXyV1.BloomMinIntensity ||
+		cfg.GetBloomMaxExtent() != cfg.L4.DbscanXyV1.BloomMaxExtent ||
+		cfg.GetBloomMinPoints() != cfg.L4.DbscanXyV1.BloomMinPoints ||
+		cfg.GetBloomStaticFrames() != cfg.L4.DbscanXyV1.BloomStaticFrames ||
+		cfg.GetSlowMoverWindow() != cfg.L4.DbscanXyV1.SlowMoverWindow ||
+		cfg.GetSlowMoverMinFrames() != cfg.L4.DbscanXyV1.SlowMoverMinFrames ||
+		cfg.GetRegionContinuity() != cfg.L4.DbscanXyV1.RegionContinuity ||
+		cfg.GetClusterStableIDs() != cfg.L4.DbscanXyV1.ClusterStableIDs ||
+		cfg.GetClusterWorkers() != cfg.L4.DbscanXyV1.ClusterWorkers ||
 		cfg.GetMaxReasonableSpeedMps() != cfg.L5.CvKfV1.MaxReasonableSpeedMps ||
 		cfg.GetMaxPositionJumpMetres() != cfg.L5.CvKfV1.MaxPositionJumpMetres ||
 		cfg.GetMaxPredictDt() != cfg.L5.CvKfV1.MaxPredictDt ||
@@ -890,7 +990,13 @@ func TestTuningConfigGettersAndActiveConfig(t *testing.T) {
 		cfg.GetMaxSpeedHistoryLength() != cfg.L5.CvKfV1.MaxSpeedHistoryLength ||
 		cfg.GetMergeSizeRatio() != cfg.L5.CvKfV1.MergeSizeRatio ||
 		cfg.GetSplitSizeRatio() != cfg.L5.CvKfV1.SplitSizeRatio ||
-		cfg.GetMinObservationsForClassification() != cfg.L5.CvKfV1.MinObservationsForClassification {
+		cfg.GetMinObservationsForClassification() != cfg.L5.CvKfV1.MinObservationsForClassification ||
+		cfg.GetSizeInstabilityWeight() != cfg.L5.CvKfV1.SizeInstabilityWeight ||
+		cfg.GetGatingMode() != cfg.L5.CvKfV1.GatingMode ||
+		cfg.GetGatingChiSquare() != cfg.L5.CvKfV1.GatingChiSquare ||
+		cfg.GetGatingAlongTrackFraction() != cfg.L5.CvKfV1.GatingAlongTrackFraction ||
+		cfg.GetSpeedSmoothingFrames() != cfg.L5.CvKfV1.SpeedSmoothingFrames ||
+		cfg.GetShapeAssociationWeight() != cfg.L5.CvKfV1.ShapeAssociationWeight {
 		t.Fatal("getter mismatch")
 	}
 	if cfg.GetFlushInterval() != time.Minute ||
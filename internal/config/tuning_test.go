@@ -139,7 +139,11 @@ func TestLoadTuningConfigRejectsMissingSelectedEngineBlock(t *testing.T) {
   "version": 2,
   "l1": {
     "sensor": "hesai-pandar40p",
-    "data_source": "live"
+    "data_source": "live",
+    "min_range_metres": 0,
+    "max_range_metres": 0,
+    "ring_range_clip": {},
+    "timestamp_max_backstep": "0s"
   },
   "l3": {
     "engine": "ema_baseline_v1"
@@ -155,7 +159,25 @@ func TestLoadTuningConfigRejectsMissingSelectedEngineBlock(t *testing.T) {
       "remove_ground": true,
       "max_cluster_diameter": 12.0,
       "min_cluster_diameter": 0.05,
-      "max_cluster_aspect_ratio": 15.0
+      "max_cluster_aspect_ratio": 15.0,
+      "min_pts_reference_range": 0,
+      "min_pts_floor": 2,
+      "voxel_origin": [0, 0, 0],
+      "voxel_snap_to_grid": false,
+      "cluster_height_weight": 0,
+      "cluster_intensity_weight": 0,
+      "cluster_merge_separation": 0,
+      "cluster_merge_max_length": 12,
+      "cluster_merge_max_width": 3,
+      "bloom_min_intensity": 0,
+      "bloom_max_extent": 1.0,
+      "bloom_min_points": 5,
+      "bloom_static_frames": 10,
+      "slow_mover_window": 0,
+      "slow_mover_min_frames": 6,
+      "region_continuity": false,
+      "cluster_stable_ids": false,
+      "cluster_workers": 0
     }
   },
   "l5": {
@@ -182,7 +204,14 @@ func TestLoadTuningConfigRejectsMissingSelectedEngineBlock(t *testing.T) {
       "merge_size_ratio": 2.5,
       "split_size_ratio": 0.3,
       "deleted_track_grace_period": "5s",
-      "min_observations_for_classification": 5
+      "min_observations_for_classification": 5,
+      "eviction_policy": "none",
+      "size_instability_weight": 0.5,
+      "gating_mode": "isotropic",
+      "gating_chi_square": 9.21,
+      "gating_along_track_fraction": 0.5,
+      "speed_smoothing_frames": 0,
+      "shape_association_weight": 0
     }
   },
   "pipeline": {
@@ -223,7 +252,11 @@ func TestLoadTuningConfigRejectsLegacySpellings(t *testing.T) {
   "version": 2,
   "l1": {
     "sensor": "hesai-pandar40p",
-    "data_source": "live"
+    "data_source": "live",
+    "min_range_metres": 0,
+    "max_range_metres": 0,
+    "ring_range_clip": {},
+    "timestamp_max_backstep": "0s"
   },
   "l3": {
     "engine": "ema_baseline_v1",
@@ -253,7 +286,12 @@ func TestLoadTuningConfigRejectsLegacySpellings(t *testing.T) {
       "settling_min_coverage": 0.8,
       "settling_max_spread_delta": 0.001,
       "settling_min_region_stability": 0.95,
-      "settling_min_confidence": 10.0
+      "settling_min_confidence": 10.0,
+      "azimuth_tolerance_deg": 0,
+      "range_tolerance_sigma_k": 0,
+      "background_model": "mean",
+      "variance_threshold_k": 0,
+      "region_warmup_min_frames": 0
     }
   },
   "l4": {
@@ -267,7 +305,25 @@ func TestLoadTuningConfigRejectsLegacySpellings(t *testing.T) {
       "remove_ground": true,
       "max_cluster_diameter": 12.0,
       "min_cluster_diameter": 0.05,
-      "max_cluster_aspect_ratio": 15.0
+      "max_cluster_aspect_ratio": 15.0,
+      "min_pts_reference_range": 0,
+      "min_pts_floor": 2,
+      "voxel_origin": [0, 0, 0],
+      "voxel_snap_to_grid": false,
+      "cluster_height_weight": 0,
+      "cluster_intensity_weight": 0,
+      "cluster_merge_separation": 0,
+      "cluster_merge_max_length": 12,
+      "cluster_merge_max_width": 3,
+      "bloom_min_intensity": 0,
+      "bloom_max_extent": 1.0,
+      "bloom_min_points": 5,
+      "bloom_static_frames": 10,
+      "slow_mover_window": 0,
+      "slow_mover_min_frames": 6,
+      "region_continuity": false,
+      "cluster_stable_ids": false,
+      "cluster_workers": 0
     }
   },
   "l5": {
@@ -294,7 +350,14 @@ func TestLoadTuningConfigRejectsLegacySpellings(t *testing.T) {
       "merge_size_ratio": 2.5,
       "split_size_ratio": 0.3,
       "deleted_track_grace_period": "5s",
-      "min_observations_for_classification": 5
+      "min_observations_for_classification": 5,
+      "eviction_policy": "none",
+      "size_instability_weight": 0.5,
+      "gating_mode": "isotropic",
+      "gating_chi_square": 9.21,
+      "gating_along_track_fraction": 0.5,
+      "speed_smoothing_frames": 0,
+      "shape_association_weight": 0
     }
   },
   "pipeline": {
@@ -370,6 +433,11 @@ func sampleValidConfig() *TuningConfig {
 					SettlingMaxSpreadDelta:            0.001,
 					SettlingMinRegionStability:        0.95,
 					SettlingMinConfidence:             10.0,
+					AzimuthToleranceDeg:               0,
+					RangeToleranceSigmaK:              0,
+					BackgroundModel:                   "mean",
+					VarianceThresholdK:                0,
+					RegionWarmupMinFrames:             0,
 				},
 			},
 		},
@@ -386,6 +454,20 @@ func sampleValidConfig() *TuningConfig {
 					MaxClusterDiameter:         12.0,
 					MinClusterDiameter:         0.05,
 					MaxClusterAspectRatio:      15.0,
+					MinPtsReferenceRange:       0,
+					MinPtsFloor:                2,
+					ClusterMergeSeparation:     0,
+					ClusterMergeMaxLength:      12.0,
+					ClusterMergeMaxWidth:       3.0,
+					BloomMinIntensity:          0,
+					BloomMaxExtent:             1.0,
+					BloomMinPoints:             5,
+					BloomStaticFrames:          10,
+					SlowMoverWindow:            0,
+					SlowMoverMinFrames:         6,
+					RegionContinuity:           false,
+					ClusterStableIDs:           false,
+					ClusterWorkers:             0,
 				},
 			},
 		},
@@ -415,6 +497,13 @@ func sampleValidConfig() *TuningConfig {
 					SplitSizeRatio:                   0.3,
 					DeletedTrackGracePeriod:          "5s",
 					MinObservationsForClassification: 5,
+					EvictionPolicy:                   "none",
+					SizeInstabilityWeight:            0.5,
+					GatingMode:                       "isotropic",
+					GatingChiSquare:                  9.21,
+					GatingAlongTrackFraction:         0.5,
+					SpeedSmoothingFrames:             0,
+					ShapeAssociationWeight:           0,
 				},
 			},
 		},
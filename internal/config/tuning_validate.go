@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 )
@@ -44,6 +45,45 @@ func (c *L1Config) Validate() error {
 	default:
 		return fmt.Errorf("data_source must be one of live, pcap, pcap_analysis, got %q", c.DataSource)
 	}
+	if err := validateRangeWindow("", c.MinRangeMetres, c.MaxRangeMetres); err != nil {
+		return err
+	}
+	for ring, w := range c.RingRangeClip {
+		if ring < 1 {
+			return fmt.Errorf("ring_range_clip: channel numbers start at 1, got %d", ring)
+		}
+		lo, hi := w.MinMetres, w.MaxMetres
+		if lo == 0 {
+			lo = c.MinRangeMetres
+		}
+		if hi == 0 {
+			hi = c.MaxRangeMetres
+		}
+		if err := validateRangeWindow(fmt.Sprintf("ring_range_clip ring %d: ", ring), lo, hi); err != nil {
+			return err
+		}
+	}
+	if c.TimestampMaxBackstep != "" {
+		d, err := time.ParseDuration(c.TimestampMaxBackstep)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp_max_backstep %q: %w", c.TimestampMaxBackstep, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("timestamp_max_backstep must be non-negative, got %s", c.TimestampMaxBackstep)
+		}
+	}
+	return nil
+}
+
+// validateRangeWindow checks range bounds are non-negative and ordered; a
+// zero bound is open.
+func validateRangeWindow(prefix string, lo, hi float64) error {
+	if lo < 0 || hi < 0 {
+		return fmt.Errorf("%srange bounds must be non-negative, got [%f, %f]", prefix, lo, hi)
+	}
+	if hi > 0 && lo >= hi {
+		return fmt.Errorf("%smin_range_metres %f must be below max_range_metres %f", prefix, lo, hi)
+	}
 	return nil
 }
 
@@ -199,6 +239,23 @@ func (c *L3Common) Validate() error {
 	if c.SettlingMinConfidence < 0 {
 		return fmt.Errorf("settling_min_confidence must be non-negative, got %f", c.SettlingMinConfidence)
 	}
+	if c.AzimuthToleranceDeg < 0 || c.AzimuthToleranceDeg > 5 {
+		return fmt.Errorf("azimuth_tolerance_deg must be in [0, 5], got %f", c.AzimuthToleranceDeg)
+	}
+	if c.RangeToleranceSigmaK < 0 {
+		return fmt.Errorf("range_tolerance_sigma_k must be non-negative, got %f", c.RangeToleranceSigmaK)
+	}
+	switch c.BackgroundModel {
+	case "mean", "median", "gmm":
+	default:
+		return fmt.Errorf("background_model must be one of mean, median, gmm, got %q", c.BackgroundModel)
+	}
+	if c.VarianceThresholdK < 0 {
+		return fmt.Errorf("variance_threshold_k must be non-negative, got %f", c.VarianceThresholdK)
+	}
+	if c.RegionWarmupMinFrames < 0 {
+		return fmt.Errorf("region_warmup_min_frames must be non-negative, got %d", c.RegionWarmupMinFrames)
+	}
 	return nil
 }
 
@@ -247,6 +304,53 @@ func (c *L4Common) Validate() error {
 	if c.MaxClusterAspectRatio <= 0 {
 		return fmt.Errorf("max_cluster_aspect_ratio must be positive, got %f", c.MaxClusterAspectRatio)
 	}
+	if c.MinPtsReferenceRange < 0 {
+		return fmt.Errorf("min_pts_reference_range must be non-negative, got %f", c.MinPtsReferenceRange)
+	}
+	if c.MinPtsFloor < 2 || c.MinPtsFloor > c.ForegroundMinClusterPoints {
+		return fmt.Errorf("min_pts_floor must be in [2, foreground_min_cluster_points], got %d", c.MinPtsFloor)
+	}
+	for _, v := range c.VoxelOrigin {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("voxel_origin must be finite, got %v", c.VoxelOrigin)
+		}
+	}
+	if c.ClusterHeightWeight < 0 {
+		return fmt.Errorf("cluster_height_weight must be non-negative, got %f", c.ClusterHeightWeight)
+	}
+	if c.ClusterIntensityWeight < 0 {
+		return fmt.Errorf("cluster_intensity_weight must be non-negative, got %f", c.ClusterIntensityWeight)
+	}
+	if c.ClusterMergeSeparation < 0 {
+		return fmt.Errorf("cluster_merge_separation must be non-negative, got %f", c.ClusterMergeSeparation)
+	}
+	if c.ClusterMergeMaxLength <= 0 {
+		return fmt.Errorf("cluster_merge_max_length must be positive, got %f", c.ClusterMergeMaxLength)
+	}
+	if c.ClusterMergeMaxWidth <= 0 || c.ClusterMergeMaxWidth > c.ClusterMergeMaxLength {
+		return fmt.Errorf("cluster_merge_max_width must be in (0, cluster_merge_max_length], got %f", c.ClusterMergeMaxWidth)
+	}
+	if c.BloomMinIntensity < 0 || c.BloomMinIntensity > 255 {
+		return fmt.Errorf("bloom_min_intensity must be in [0, 255], got %d", c.BloomMinIntensity)
+	}
+	if c.BloomMaxExtent <= 0 {
+		return fmt.Errorf("bloom_max_extent must be positive, got %f", c.BloomMaxExtent)
+	}
+	if c.BloomMinPoints < 1 {
+		return fmt.Errorf("bloom_min_points must be at least 1, got %d", c.BloomMinPoints)
+	}
+	if c.BloomStaticFrames < 1 {
+		return fmt.Errorf("bloom_static_frames must be at least 1, got %d", c.BloomStaticFrames)
+	}
+	if c.SlowMoverWindow < 0 {
+		return fmt.Errorf("slow_mover_window must be non-negative, got %d", c.SlowMoverWindow)
+	}
+	if c.SlowMoverMinFrames < 1 || (c.SlowMoverWindow > 1 && c.SlowMoverMinFrames > c.SlowMoverWindow) {
+		return fmt.Errorf("slow_mover_min_frames must be in [1, slow_mover_window], got %d", c.SlowMoverMinFrames)
+	}
+	if c.ClusterWorkers < 0 {
+		return fmt.Errorf("cluster_workers must be non-negative, got %d", c.ClusterWorkers)
+	}
 	return nil
 }
 
@@ -309,8 +413,8 @@ func (c *L5Common) Validate() error {
 	if c.MaxMissesConfirmed < 1 {
 		return fmt.Errorf("max_misses_confirmed must be >= 1, got %d", c.MaxMissesConfirmed)
 	}
-	if c.MaxTracks < 1 || c.MaxTracks > 1000 {
-		return fmt.Errorf("max_tracks must be in [1, 1000], got %d", c.MaxTracks)
+	if c.MaxTracks < 0 || c.MaxTracks > 1000 {
+		return fmt.Errorf("max_tracks must be in [0, 1000], got %d", c.MaxTracks)
 	}
 	if c.MaxReasonableSpeedMps <= 0 {
 		return fmt.Errorf("max_reasonable_speed_mps must be positive, got %f", c.MaxReasonableSpeedMps)
@@ -351,6 +455,31 @@ func (c *L5Common) Validate() error {
 	if c.MinObservationsForClassification < 1 {
 		return fmt.Errorf("min_observations_for_classification must be >= 1, got %d", c.MinObservationsForClassification)
 	}
+	switch c.EvictionPolicy {
+	case "none", "lowest_quality", "oldest_coasting":
+	default:
+		return fmt.Errorf("eviction_policy must be one of none, lowest_quality, oldest_coasting, got %q", c.EvictionPolicy)
+	}
+	if c.SizeInstabilityWeight < 0 {
+		return fmt.Errorf("size_instability_weight must be >= 0, got %f", c.SizeInstabilityWeight)
+	}
+	switch c.GatingMode {
+	case "isotropic", "mahalanobis":
+	default:
+		return fmt.Errorf("gating_mode must be one of isotropic, mahalanobis, got %q", c.GatingMode)
+	}
+	if c.GatingChiSquare <= 0 {
+		return fmt.Errorf("gating_chi_square must be positive, got %f", c.GatingChiSquare)
+	}
+	if c.GatingAlongTrackFraction <= 0 {
+		return fmt.Errorf("gating_along_track_fraction must be positive, got %f", c.GatingAlongTrackFraction)
+	}
+	if c.SpeedSmoothingFrames < 0 {
+		return fmt.Errorf("speed_smoothing_frames must be >= 0, got %d", c.SpeedSmoothingFrames)
+	}
+	if c.ShapeAssociationWeight < 0 {
+		return fmt.Errorf("shape_association_weight must be >= 0, got %f", c.ShapeAssociationWeight)
+	}
 	return nil
 }
 
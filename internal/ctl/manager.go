@@ -173,6 +173,10 @@ func (m *Manager) ServiceName() string {
 	return m.cfg.ServiceName
 }
 
+func (m *Manager) DBPath() string {
+	return m.cfg.DBPath
+}
+
 func (m *Manager) RunUpgrade(checkOnly bool, binaryFile string) error {
 	return m.RunUpgradeWithOptions(checkOnly, binaryFile, UpgradeOptions{})
 }
@@ -1,8 +1,12 @@
 package db
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -156,6 +160,126 @@ func TestGetLatestBgSnapshot(t *testing.T) {
 	}
 }
 
+// TestGetLatestBgSnapshot_ConcurrentInserts loads the latest snapshot while
+// another goroutine inserts, and checks every load is a complete committed
+// row that never goes backwards.
+func TestGetLatestBgSnapshot_ConcurrentInserts(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test_latest_concurrent.db")
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	defer db.Close()
+
+	// Each snapshot's fields are derived from its sequence number so a
+	// torn or mismatched row is detectable.
+	blobFor := func(seq int) []byte { return bytes.Repeat([]byte{byte(seq)}, 1024+seq) }
+	const inserts = 200
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for seq := 1; seq <= inserts; seq++ {
+			snap := &l3grid.BgSnapshot{
+				SensorID:          "race-sensor",
+				TakenUnixNanos:    int64(seq),
+				Rings:             40,
+				AzimuthBins:       1800,
+				GridBlob:          blobFor(seq),
+				ChangedCellsCount: seq,
+				SnapshotReason:    "race",
+			}
+			if _, err := db.InsertBgSnapshot(snap); err != nil {
+				t.Errorf("InsertBgSnapshot %d: %v", seq, err)
+				return
+			}
+		}
+	}()
+
+	errs := make(chan error, 4)
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lastSeq := 0
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snap, err := db.GetLatestBgSnapshot("race-sensor")
+				if err != nil {
+					errs <- err
+					return
+				}
+				if snap == nil {
+					continue
+				}
+				seq := snap.ChangedCellsCount
+				if snap.TakenUnixNanos != int64(seq) || !bytes.Equal(snap.GridBlob, blobFor(seq)) {
+					errs <- fmt.Errorf("loaded snapshot %d is incomplete or mismatched", seq)
+					return
+				}
+				if seq < lastSeq {
+					errs <- fmt.Errorf("latest snapshot went backwards: %d after %d", seq, lastSeq)
+					return
+				}
+				lastSeq = seq
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	latest, err := db.GetLatestBgSnapshot("race-sensor")
+	if err != nil || latest == nil || latest.ChangedCellsCount != inserts {
+		t.Fatalf("final latest = %+v, %v; want snapshot %d", latest, err, inserts)
+	}
+}
+
+// TestGetLatestBgSnapshot_RepointsAfterDelete checks deleting the latest
+// snapshot moves the pointer back to the newest remaining one.
+func TestGetLatestBgSnapshot_RepointsAfterDelete(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/test_latest_delete.db")
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	defer db.Close()
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, err := db.InsertBgSnapshot(&l3grid.BgSnapshot{
+			SensorID: "s1", TakenUnixNanos: int64(i), Rings: 40, AzimuthBins: 1800,
+			GridBlob: []byte{byte(i)}, ChangedCellsCount: i,
+		})
+		if err != nil {
+			t.Fatalf("InsertBgSnapshot: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if _, err := db.DeleteBgSnapshots([]int64{ids[2]}); err != nil {
+		t.Fatalf("DeleteBgSnapshots: %v", err)
+	}
+	latest, err := db.GetLatestBgSnapshot("s1")
+	if err != nil || latest == nil || *latest.SnapshotID != ids[1] {
+		t.Fatalf("after deleting the latest: got %+v, %v; want snapshot %d", latest, err, ids[1])
+	}
+
+	if _, err := db.DeleteBgSnapshots(ids[:2]); err != nil {
+		t.Fatalf("DeleteBgSnapshots: %v", err)
+	}
+	if latest, err := db.GetLatestBgSnapshot("s1"); err != nil || latest != nil {
+		t.Errorf("after deleting all: got %+v, %v; want nil", latest, err)
+	}
+}
+
 // TestGetLatestBgSnapshot_NotFound tests getting snapshot when none exist
 func TestGetLatestBgSnapshot_NotFound(t *testing.T) {
 	fname := t.TempDir() + "/test_latest_notfound.db"
@@ -1063,3 +1187,67 @@ func TestGetRegionSnapshotBySourcePath_MostRecent(t *testing.T) {
 		t.Errorf("Expected most recent snapshot (RegionCount=300), got RegionCount=%d", retrieved.RegionCount)
 	}
 }
+
+// TestBgSnapshotRingElevations_RoundTrip persists a snapshot from a live
+// background manager that learned its elevations from the points it saw and
+// loads the same table back.
+func TestBgSnapshotRingElevations_RoundTrip(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/ring_elevations.db")
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	defer db.Close()
+
+	want := []float64{-16, -7.5, 0, 2.25, 15}
+	mgr := l3grid.NewBackgroundManager("elev-sensor", len(want), 36, l3grid.BackgroundParams{}, db)
+	points := make([]l3grid.PointPolar, 0, len(want))
+	for ring, elev := range want {
+		points = append(points, l3grid.PointPolar{Channel: ring + 1, Azimuth: 10, Elevation: elev, Distance: 12})
+	}
+	mgr.ProcessFramePolar(points)
+	if err := mgr.Persist(db, "manual"); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	snap, err := db.GetLatestBgSnapshot("elev-sensor")
+	if err != nil || snap == nil {
+		t.Fatalf("GetLatestBgSnapshot: snap=%v err=%v", snap, err)
+	}
+	got, err := db.GetBgSnapshotRingElevations(*snap.SnapshotID)
+	if err != nil {
+		t.Fatalf("GetBgSnapshotRingElevations failed: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d elevations, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ring %d elevation = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGetBgSnapshotRingElevations_Missing(t *testing.T) {
+	db, err := NewDB(t.TempDir() + "/ring_elevations_missing.db")
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	defer db.Close()
+
+	id, err := db.InsertBgSnapshot(&l3grid.BgSnapshot{
+		SensorID:    "legacy-sensor",
+		Rings:       40,
+		AzimuthBins: 1800,
+		ParamsJSON:  `{}`,
+		GridBlob:    []byte("legacy-blob"),
+	})
+	if err != nil {
+		t.Fatalf("InsertBgSnapshot failed: %v", err)
+	}
+	if _, err := db.GetBgSnapshotRingElevations(id); !errors.Is(err, l3grid.ErrNoRingElevations) {
+		t.Errorf("legacy snapshot: err = %v, want ErrNoRingElevations", err)
+	}
+	if _, err := db.GetBgSnapshotRingElevations(id + 100); err == nil {
+		t.Error("expected error for unknown snapshot")
+	}
+}
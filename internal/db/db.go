@@ -7,7 +7,9 @@ import (
 	"io/fs"
 	"log"
 	"math"
+	"net/url"
 	"os"
+	"strings"
 	"sync"
 
 	_ "modernc.org/sqlite"
@@ -136,6 +138,27 @@ func validateSchemaSQLConsistency(migrationsFS fs.FS) (uint, error) {
 	return latestVersion, nil
 }
 
+// connPragmas are the per-connection PRAGMAs, in the DSN _pragma form.
+// SQLite scopes foreign_keys, busy_timeout, synchronous and temp_store to
+// one connection, so they are set through the DSN, which the driver applies
+// to every connection the pool opens, not just the first.
+var connPragmas = []string{
+	"busy_timeout(30000)",
+	"foreign_keys(1)",
+	"synchronous(NORMAL)",
+	"temp_store(MEMORY)",
+}
+
+// pragmaDSN returns path with connPragmas appended as _pragma query
+// parameters.
+func pragmaDSN(path string) string {
+	q := url.Values{"_pragma": connPragmas}.Encode()
+	if strings.Contains(path, "?") {
+		return path + "&" + q
+	}
+	return path + "?" + q
+}
+
 // applyPragmas applies essential SQLite PRAGMAs for performance and concurrency.
 // These settings are extracted from schema.sql and applied to all databases
 // regardless of whether they were created from scratch or via migrations.
@@ -170,10 +193,11 @@ func NewDB(path string) (*DB, error) {
 // NewDBWithMigrationCheck opens a database and optionally checks for pending migrations.
 // If checkMigrations is true and migrations are pending, returns an error prompting user to run migrations.
 func NewDBWithMigrationCheck(path string, checkMigrations bool) (*DB, error) {
-	db, err := sql.Open("sqlite", path)
+	db, err := sql.Open("sqlite", pragmaDSN(path))
 	if err != nil {
 		return nil, err
 	}
+	configurePool(db, DefaultPoolConfig())
 	closeOnError := true
 	defer func() {
 		if closeOnError {
@@ -336,10 +360,11 @@ func NewDBWithMigrationCheck(path string, checkMigrations bool) (*DB, error) {
 // This is useful for migration commands that manage schema independently.
 // Note: PRAGMAs are still applied for performance and concurrency.
 func OpenDB(path string) (*DB, error) {
-	db, err := sql.Open("sqlite", path)
+	db, err := sql.Open("sqlite", pragmaDSN(path))
 	if err != nil {
 		return nil, err
 	}
+	configurePool(db, DefaultPoolConfig())
 
 	// Apply PRAGMAs even for migration commands
 	if err := applyPragmas(db); err != nil {
@@ -19,16 +19,27 @@ import (
 )
 
 // TableStats contains size and row count information for a database table.
+// SizeMB covers the table's own pages; IndexSizeMB covers its indexes.
 type TableStats struct {
-	Name     string  `json:"name"`
-	RowCount int64   `json:"row_count"`
-	SizeMB   float64 `json:"size_mb"`
+	Name        string  `json:"name"`
+	RowCount    int64   `json:"row_count"`
+	SizeMB      float64 `json:"size_mb"`
+	IndexSizeMB float64 `json:"index_size_mb"`
 }
 
 // DatabaseStats contains overall database statistics.
 type DatabaseStats struct {
 	TotalSizeMB float64      `json:"total_size_mb"`
 	Tables      []TableStats `json:"tables"`
+
+	// Page accounting. Free pages are left behind by deletes (for example
+	// after pruning) and are only returned to the filesystem by VACUUM.
+	PageSize     int64    `json:"page_size"`
+	PageCount    int64    `json:"page_count"`
+	FreePages    int64    `json:"free_pages"`
+	FreeSizeMB   float64  `json:"free_size_mb"`
+	FreeFraction float64  `json:"free_fraction"`
+	Warnings     []string `json:"warnings,omitempty"`
 }
 
 func quoteSQLiteIdentifier(name string) string {
@@ -52,6 +63,11 @@ func (db *DB) GetDatabaseStats() (*DatabaseStats, error) {
 	}
 	totalSizeMB := float64(totalPages*pageSize) / (1024 * 1024)
 
+	var freePages int64
+	if err := db.QueryRow("PRAGMA freelist_count").Scan(&freePages); err != nil {
+		return nil, fmt.Errorf("failed to get freelist count: %w", err)
+	}
+
 	// Get list of tables
 	tablesQuery := `SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name`
 	rows, err := db.Query(tablesQuery)
@@ -69,6 +85,21 @@ func (db *DB) GetDatabaseStats() (*DatabaseStats, error) {
 		tableNames = append(tableNames, name)
 	}
 
+	// Index sizes per table from dbstat (if available)
+	indexSizes := make(map[string]float64)
+	if rows, err := db.Query(`SELECT m.tbl_name, COALESCE(SUM(s.pgsize), 0) / 1048576.0
+		FROM dbstat s JOIN sqlite_master m ON m.name = s.name
+		WHERE m.type = 'index' GROUP BY m.tbl_name`); err == nil {
+		for rows.Next() {
+			var name string
+			var sizeMB float64
+			if err := rows.Scan(&name, &sizeMB); err == nil {
+				indexSizes[name] = sizeMB
+			}
+		}
+		rows.Close()
+	}
+
 	// Get stats for each table
 	var tables []TableStats
 	for _, tableName := range tableNames {
@@ -93,9 +124,10 @@ func (db *DB) GetDatabaseStats() (*DatabaseStats, error) {
 		}
 
 		tables = append(tables, TableStats{
-			Name:     tableName,
-			RowCount: rowCount,
-			SizeMB:   math.Round(sizeMB*100) / 100, // Round to 2 decimal places
+			Name:        tableName,
+			RowCount:    rowCount,
+			SizeMB:      math.Round(sizeMB*100) / 100, // Round to 2 decimal places
+			IndexSizeMB: math.Round(indexSizes[tableName]*100) / 100,
 		})
 	}
 
@@ -104,10 +136,23 @@ func (db *DB) GetDatabaseStats() (*DatabaseStats, error) {
 		return tables[i].SizeMB > tables[j].SizeMB
 	})
 
-	return &DatabaseStats{
+	stats := &DatabaseStats{
 		TotalSizeMB: math.Round(totalSizeMB*100) / 100,
 		Tables:      tables,
-	}, nil
+		PageSize:    pageSize,
+		PageCount:   totalPages,
+		FreePages:   freePages,
+		FreeSizeMB:  math.Round(float64(freePages*pageSize)/(1024*1024)*100) / 100,
+	}
+	if totalPages > 0 {
+		stats.FreeFraction = math.Round(float64(freePages)/float64(totalPages)*1000) / 1000
+	}
+	if stats.FreeFraction >= FreePageWarnFraction {
+		stats.Warnings = append(stats.Warnings, fmt.Sprintf(
+			"%.0f%% of the database (%.2f MB) is free pages; run VACUUM to reclaim the space",
+			stats.FreeFraction*100, stats.FreeSizeMB))
+	}
+	return stats, nil
 }
 
 func (db *DB) AttachAdminRoutes(mux *http.ServeMux) {
@@ -126,6 +171,8 @@ func (db *DB) AttachAdminRoutes(mux *http.ServeMux) {
 	// mount the tailSQL server on the debug /tailsql path
 	debug.Handle("tailsql/", "SQL live debugging", tsql.NewMux())
 
+	exportPoolMetrics(db)
+
 	debug.Handle("db-stats", "Database table sizes and disk usage (JSON)", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		stats, err := db.GetDatabaseStats()
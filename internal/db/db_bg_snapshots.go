@@ -66,32 +66,82 @@ func (db *DB) DeleteDuplicateBgSnapshots(sensorID string) (int64, error) {
              WHERE sensor_id = ?
              GROUP BY grid_blob
           )`
-	res, err := db.Exec(q, sensorID, sensorID)
+	tx, err := db.Begin()
 	if err != nil {
 		return 0, err
 	}
-	return res.RowsAffected()
+	defer tx.Rollback()
+
+	res, err := tx.Exec(q, sensorID, sensorID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := repointLatestBgSnapshots(tx); err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
 }
 
 // InsertBgSnapshot persists a Background snapshot into the lidar_bg_snapshot table
-// and returns the new snapshot_id.
+// and returns the new snapshot_id. The sensor's lidar_bg_snapshot_latest
+// pointer moves to the new row in the same transaction.
 func (db *DB) InsertBgSnapshot(s *l3grid.BgSnapshot) (int64, error) {
 	if s == nil {
 		return 0, nil
 	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
 	stmt := `INSERT INTO lidar_bg_snapshot (sensor_id, taken_unix_nanos, rings, azimuth_bins, params_json, ring_elevations_json, grid_blob, changed_cells_count, snapshot_reason)
 			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	res, err := db.Exec(stmt, s.SensorID, s.TakenUnixNanos, s.Rings, s.AzimuthBins, s.ParamsJSON, s.RingElevationsJSON, s.GridBlob, s.ChangedCellsCount, s.SnapshotReason)
+	res, err := tx.Exec(stmt, s.SensorID, s.TakenUnixNanos, s.Rings, s.AzimuthBins, s.ParamsJSON, s.RingElevationsJSON, s.GridBlob, s.ChangedCellsCount, s.SnapshotReason)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
 	if err != nil {
 		return 0, err
 	}
-	return res.LastInsertId()
+	if _, err := tx.Exec(`INSERT INTO lidar_bg_snapshot_latest (sensor_id, snapshot_id) VALUES (?, ?)
+			 ON CONFLICT (sensor_id) DO UPDATE SET snapshot_id = excluded.snapshot_id`, s.SensorID, id); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// repointLatestBgSnapshots points every sensor whose latest snapshot was
+// deleted at its newest remaining snapshot. The foreign key cascade already
+// drops such pointers; the explicit delete covers connections opened
+// without foreign_keys enabled.
+func repointLatestBgSnapshots(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM lidar_bg_snapshot_latest
+		 WHERE snapshot_id NOT IN (SELECT snapshot_id FROM lidar_bg_snapshot)`); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`INSERT INTO lidar_bg_snapshot_latest (sensor_id, snapshot_id)
+		SELECT sensor_id, MAX(snapshot_id) FROM lidar_bg_snapshot
+		 WHERE sensor_id NOT IN (SELECT sensor_id FROM lidar_bg_snapshot_latest)
+		 GROUP BY sensor_id`)
+	return err
 }
 
 // GetLatestBgSnapshot returns the most recent BgSnapshot for the given sensor_id, or nil if none.
+// It reads through the lidar_bg_snapshot_latest pointer, which only ever
+// names a committed snapshot.
 func (db *DB) GetLatestBgSnapshot(sensorID string) (*l3grid.BgSnapshot, error) {
-	q := `SELECT snapshot_id, sensor_id, taken_unix_nanos, rings, azimuth_bins, params_json, ring_elevations_json, grid_blob, changed_cells_count, snapshot_reason
-		  FROM lidar_bg_snapshot WHERE sensor_id = ? ORDER BY snapshot_id DESC LIMIT 1` // nolint:lll
+	q := `SELECT s.snapshot_id, s.sensor_id, s.taken_unix_nanos, s.rings, s.azimuth_bins, s.params_json, s.ring_elevations_json, s.grid_blob, s.changed_cells_count, s.snapshot_reason
+		  FROM lidar_bg_snapshot_latest l JOIN lidar_bg_snapshot s ON s.snapshot_id = l.snapshot_id
+		  WHERE l.sensor_id = ?` // nolint:lll
 
 	row := db.QueryRow(q, sensorID)
 	return scanBgSnapshot(row)
@@ -109,6 +159,25 @@ func (db *DB) GetBgSnapshotByID(snapshotID int64) (*l3grid.BgSnapshot, error) {
 	return scanBgSnapshot(row)
 }
 
+// GetBgSnapshotRingElevations returns the per-ring elevation table (degrees)
+// stored with a snapshot, so its cells can be projected to world coordinates
+// without guessing the sensor configuration. Snapshots written before
+// elevations were always persisted return l3grid.ErrNoRingElevations.
+func (db *DB) GetBgSnapshotRingElevations(snapshotID int64) ([]float64, error) {
+	var rings int
+	var ringElevations sql.NullString
+	err := db.QueryRow(`SELECT rings, ring_elevations_json FROM lidar_bg_snapshot WHERE snapshot_id = ?`, snapshotID).
+		Scan(&rings, &ringElevations)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("bg snapshot %d not found", snapshotID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	snap := &l3grid.BgSnapshot{SnapshotID: &snapshotID, Rings: rings, RingElevationsJSON: ringElevations.String}
+	return snap.RingElevations()
+}
+
 // scanBgSnapshot scans a row into a BgSnapshot struct.
 func scanBgSnapshot(row *sql.Row) (*l3grid.BgSnapshot, error) {
 	var snapID int64
@@ -269,10 +338,22 @@ func (db *DB) DeleteBgSnapshots(snapshotIDs []int64) (int64, error) {
 	q := fmt.Sprintf("DELETE FROM lidar_bg_snapshot WHERE snapshot_id IN (%s)",
 		strings.Join(placeholders, ","))
 
-	res, err := db.Exec(q, args...)
+	tx, err := db.Begin()
 	if err != nil {
 		return 0, err
 	}
+	defer tx.Rollback()
 
-	return res.RowsAffected()
+	res, err := tx.Exec(q, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := repointLatestBgSnapshots(tx); err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
 }
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// seedRadarData inserts n radar_data rows with padded payloads so the
+// table spans many pages.
+func seedRadarData(t *testing.T, db *DB, n int) {
+	t.Helper()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	pad := strings.Repeat("x", 800)
+	for i := 0; i < n; i++ {
+		raw := fmt.Sprintf(`{"uptime": %d, "magnitude": 40, "speed": 12.5, "pad": %q}`, i, pad)
+		if _, err := tx.Exec(`INSERT INTO radar_data (raw_event) VALUES (?)`, raw); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+}
+
+func TestRunMaintenance_VacuumReclaimsPrunedPages(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	seedRadarData(t, db, 4000)
+	stats, err := db.GetDatabaseStats()
+	if err != nil {
+		t.Fatalf("GetDatabaseStats: %v", err)
+	}
+	var seeded TableStats
+	for _, table := range stats.Tables {
+		if table.Name == "radar_data" {
+			seeded = table
+		}
+	}
+	if seeded.RowCount != 4000 || seeded.SizeMB < 2 {
+		t.Fatalf("radar_data = %d rows, %.2f MB; want 4000 rows over 2 MB", seeded.RowCount, seeded.SizeMB)
+	}
+	if stats.FreePages != 0 || len(stats.Warnings) != 0 {
+		t.Fatalf("fresh database: free_pages=%d warnings=%v, want none", stats.FreePages, stats.Warnings)
+	}
+
+	// Prune three quarters of the rows: the pages stay in the file.
+	if _, err := db.Exec(`DELETE FROM radar_data WHERE data_id % 4 != 0`); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	stats, err = db.GetDatabaseStats()
+	if err != nil {
+		t.Fatalf("GetDatabaseStats after prune: %v", err)
+	}
+	if stats.FreeFraction < FreePageWarnFraction || len(stats.Warnings) != 1 {
+		t.Fatalf("after prune: free_fraction=%.3f warnings=%v, want a free-page warning", stats.FreeFraction, stats.Warnings)
+	}
+	if stats.PageSize <= 0 || stats.FreeSizeMB <= 0 {
+		t.Errorf("page_size=%d free_size_mb=%.2f, want both positive", stats.PageSize, stats.FreeSizeMB)
+	}
+
+	result, err := db.RunMaintenance(true)
+	if err != nil {
+		t.Fatalf("RunMaintenance: %v", err)
+	}
+	if !result.Vacuumed || result.FreePages != stats.FreePages {
+		t.Errorf("result = %+v, want vacuumed with %d free pages before", result, stats.FreePages)
+	}
+	if result.ReclaimedMB < stats.FreeSizeMB*0.9 || result.AfterSizeMB >= result.BeforeSizeMB {
+		t.Errorf("reclaimed %.2f MB (%.2f -> %.2f), want about the %.2f MB of free pages",
+			result.ReclaimedMB, result.BeforeSizeMB, result.AfterSizeMB, stats.FreeSizeMB)
+	}
+
+	stats, err = db.GetDatabaseStats()
+	if err != nil {
+		t.Fatalf("GetDatabaseStats after vacuum: %v", err)
+	}
+	if stats.FreePages != 0 || len(stats.Warnings) != 0 {
+		t.Errorf("after vacuum: free_pages=%d warnings=%v, want none", stats.FreePages, stats.Warnings)
+	}
+	for _, table := range stats.Tables {
+		if table.Name == "radar_data" && table.RowCount != 1000 {
+			t.Errorf("radar_data rows after vacuum = %d, want 1000", table.RowCount)
+		}
+	}
+}
+
+func TestRunMaintenance_OptimizeOnly(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	seedRadarData(t, db, 200)
+	if _, err := db.Exec(`DELETE FROM radar_data`); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	result, err := db.RunMaintenance(false)
+	if err != nil {
+		t.Fatalf("RunMaintenance: %v", err)
+	}
+	if result.Vacuumed || result.ReclaimedMB != 0 {
+		t.Errorf("result = %+v, want no vacuum and nothing reclaimed", result)
+	}
+}
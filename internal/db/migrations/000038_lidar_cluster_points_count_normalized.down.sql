@@ -0,0 +1,2 @@
+    ALTER TABLE lidar_clusters
+     DROP COLUMN points_count_normalized;
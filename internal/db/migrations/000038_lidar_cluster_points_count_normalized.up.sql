@@ -0,0 +1,6 @@
+-- Range-normalised cluster point count: points_count rescaled by the
+-- effective sampling density at the cluster's range (see
+-- l4perception.SamplingDensity). NULL for clusters recorded before this
+-- migration and for clusters recorded without a density model.
+    ALTER TABLE lidar_clusters
+      ADD COLUMN points_count_normalized REAL;
@@ -0,0 +1 @@
+     DROP TABLE IF EXISTS lidar_bg_snapshot_latest;
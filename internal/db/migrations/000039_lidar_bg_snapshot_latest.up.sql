@@ -0,0 +1,15 @@
+-- Explicit pointer to the newest background snapshot per sensor, updated in
+-- the same transaction as each snapshot insert so loaders never race a
+-- concurrent write or scan lidar_bg_snapshot for the newest row.
+   CREATE TABLE lidar_bg_snapshot_latest (
+          sensor_id TEXT PRIMARY KEY
+        , snapshot_id INTEGER NOT NULL
+        , FOREIGN KEY (snapshot_id) REFERENCES lidar_bg_snapshot (snapshot_id) ON DELETE CASCADE
+          );
+
+-- Point each existing sensor at its newest snapshot.
+   INSERT INTO lidar_bg_snapshot_latest (sensor_id, snapshot_id)
+   SELECT sensor_id
+        , MAX(snapshot_id)
+     FROM lidar_bg_snapshot
+ GROUP BY sensor_id;
@@ -0,0 +1,104 @@
+PRAGMA foreign_keys = OFF;
+
+-- Allow 'pending' so runs queued by POST /api/lidar/analyze are visible
+-- before replay starts. SQLite cannot alter a CHECK constraint in place,
+-- so the table is rebuilt.
+   CREATE TABLE lidar_run_records_new (
+          run_id TEXT PRIMARY KEY
+        , created_at INTEGER NOT NULL
+        , source_type TEXT NOT NULL
+        , source_path TEXT
+        , sensor_id TEXT NOT NULL
+        , duration_secs REAL
+        , total_frames INTEGER
+        , total_clusters INTEGER
+        , total_tracks INTEGER
+        , confirmed_tracks INTEGER
+        , processing_time_ms INTEGER
+        , status TEXT NOT NULL DEFAULT 'running'
+        , error_message TEXT
+        , parent_run_id TEXT
+        , notes TEXT
+        , statistics_json TEXT
+        , vrlog_path TEXT
+        , run_config_id TEXT REFERENCES lidar_run_configs (run_config_id) ON DELETE SET NULL
+        , requested_param_set_id TEXT REFERENCES lidar_param_sets (param_set_id) ON DELETE SET NULL
+        , replay_case_id TEXT REFERENCES lidar_replay_cases (replay_case_id) ON DELETE SET NULL
+        , completed_at INTEGER
+        , frame_start_ns INTEGER
+        , frame_end_ns INTEGER
+        , CHECK (source_type IN ('live', 'pcap'))
+        , CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+        , FOREIGN KEY (parent_run_id) REFERENCES lidar_run_records_new (run_id) ON DELETE SET NULL
+          );
+
+   INSERT INTO lidar_run_records_new (
+          run_id
+        , created_at
+        , source_type
+        , source_path
+        , sensor_id
+        , duration_secs
+        , total_frames
+        , total_clusters
+        , total_tracks
+        , confirmed_tracks
+        , processing_time_ms
+        , status
+        , error_message
+        , parent_run_id
+        , notes
+        , statistics_json
+        , vrlog_path
+        , run_config_id
+        , requested_param_set_id
+        , replay_case_id
+        , completed_at
+        , frame_start_ns
+        , frame_end_ns
+          )
+   SELECT run_id
+        , created_at
+        , source_type
+        , source_path
+        , sensor_id
+        , duration_secs
+        , total_frames
+        , total_clusters
+        , total_tracks
+        , confirmed_tracks
+        , processing_time_ms
+        , status
+        , error_message
+        , parent_run_id
+        , notes
+        , statistics_json
+        , vrlog_path
+        , run_config_id
+        , requested_param_set_id
+        , replay_case_id
+        , completed_at
+        , frame_start_ns
+        , frame_end_ns
+     FROM lidar_run_records;
+
+     DROP TABLE lidar_run_records;
+
+    ALTER TABLE lidar_run_records_new
+RENAME TO lidar_run_records;
+
+CREATE INDEX idx_lidar_runs_created ON lidar_run_records (created_at);
+
+CREATE INDEX idx_lidar_runs_source ON lidar_run_records (source_path);
+
+CREATE INDEX idx_lidar_runs_parent ON lidar_run_records (parent_run_id);
+
+CREATE INDEX idx_lidar_runs_status ON lidar_run_records (status);
+
+CREATE INDEX idx_lidar_run_records_run_config ON lidar_run_records (run_config_id);
+
+CREATE INDEX idx_lidar_run_records_requested_param_set ON lidar_run_records (requested_param_set_id);
+
+CREATE INDEX idx_lidar_run_records_replay_case ON lidar_run_records (replay_case_id);
+
+PRAGMA foreign_keys = ON;
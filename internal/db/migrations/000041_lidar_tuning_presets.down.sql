@@ -0,0 +1 @@
+     DROP TABLE IF EXISTS lidar_tuning_presets;
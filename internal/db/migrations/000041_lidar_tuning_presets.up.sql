@@ -0,0 +1,9 @@
+-- Named tuning presets saved from the monitor dashboard. params_json holds a
+-- partial or full tuning config that is applied through the same atomic path
+-- as POST /api/lidar/params.
+   CREATE TABLE lidar_tuning_presets (
+          name TEXT PRIMARY KEY
+        , params_json TEXT NOT NULL
+        , created_at INTEGER NOT NULL
+        , updated_at INTEGER NOT NULL
+          );
@@ -0,0 +1,2 @@
+    ALTER TABLE lidar_tracks
+     DROP COLUMN archived_unix_nanos;
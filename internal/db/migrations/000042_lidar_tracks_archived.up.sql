@@ -0,0 +1,5 @@
+-- Soft-delete for tracks: archived_unix_nanos records when an operator
+-- archived the track, NULL while it is live. Archived tracks are left out of
+-- track listings until unarchived, and purged only on request.
+    ALTER TABLE lidar_tracks
+      ADD COLUMN archived_unix_nanos INTEGER;
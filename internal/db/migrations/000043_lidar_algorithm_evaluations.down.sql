@@ -0,0 +1,7 @@
+     DROP INDEX IF EXISTS idx_lidar_algorithm_runs_source;
+
+     DROP INDEX IF EXISTS idx_lidar_algorithm_runs_created;
+
+     DROP TABLE IF EXISTS lidar_algorithm_frame_results;
+
+     DROP TABLE IF EXISTS lidar_algorithm_runs;
@@ -0,0 +1,33 @@
+-- Foreground-extractor comparison results from the evaluation harness. One
+-- lidar_algorithm_runs row per capture compared, with its per-frame agreement
+-- in lidar_algorithm_frame_results, so agreement can be trended across
+-- captures. extractors_json is the ordered list of extractor names compared;
+-- foreground_ratios_json maps each extractor to the fraction of points it
+-- marked foreground.
+   CREATE TABLE lidar_algorithm_runs (
+          run_id TEXT PRIMARY KEY
+        , source_path TEXT
+        , sensor_id TEXT
+        , extractors_json TEXT NOT NULL
+        , merge_mode TEXT
+        , frame_count INTEGER NOT NULL DEFAULT 0
+        , mean_agreement REAL
+        , mean_iou REAL
+        , foreground_ratios_json TEXT
+        , created_at INTEGER NOT NULL
+          );
+
+   CREATE TABLE lidar_algorithm_frame_results (
+          run_id TEXT NOT NULL
+        , frame_index INTEGER NOT NULL
+        , timestamp_ns INTEGER
+        , agreement REAL
+        , iou REAL
+        , foreground_ratios_json TEXT
+        , PRIMARY KEY (run_id, frame_index)
+        , FOREIGN KEY (run_id) REFERENCES lidar_algorithm_runs (run_id) ON DELETE CASCADE
+          );
+
+CREATE INDEX idx_lidar_algorithm_runs_created ON lidar_algorithm_runs (created_at);
+
+CREATE INDEX idx_lidar_algorithm_runs_source ON lidar_algorithm_runs (source_path);
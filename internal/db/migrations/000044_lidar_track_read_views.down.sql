@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS lidar_track_observations_all;
+
+DROP VIEW IF EXISTS lidar_tracks_all;
@@ -0,0 +1,13 @@
+-- Read views over the live track tables. Track queries read these instead of
+-- the tables. When track partitioning attaches older periods it creates TEMP
+-- views of the same names, which shadow these and extend them across the
+-- partitions. partition_source names where each row is stored: 'main' here.
+   CREATE VIEW IF NOT EXISTS lidar_tracks_all AS
+   SELECT *
+        , 'main' AS partition_source
+     FROM lidar_tracks;
+
+   CREATE VIEW IF NOT EXISTS lidar_track_observations_all AS
+   SELECT *
+        , 'main' AS partition_source
+     FROM lidar_track_observations;
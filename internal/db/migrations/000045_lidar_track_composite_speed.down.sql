@@ -0,0 +1,2 @@
+    ALTER TABLE lidar_tracks
+     DROP COLUMN composite_speed_mps;
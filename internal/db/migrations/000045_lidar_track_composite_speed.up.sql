@@ -0,0 +1,5 @@
+-- Confidence-weighted composite speed (see l5tracks composite_speed.go),
+-- the speed to report for a track. NULL for tracks recorded before this
+-- migration.
+    ALTER TABLE lidar_tracks
+      ADD COLUMN composite_speed_mps REAL;
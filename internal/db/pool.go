@@ -0,0 +1,125 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PoolConfig controls the database/sql connection pool behind a DB.
+type PoolConfig struct {
+	MaxOpenConns    int           // Zero or negative means unlimited
+	MaxIdleConns    int           // Zero or negative keeps no idle connections
+	ConnMaxLifetime time.Duration // Zero keeps connections open indefinitely
+}
+
+// DefaultPoolConfig returns the pool used by NewDB and OpenDB: a single
+// connection that is never recycled.
+//
+// SQLite serialises writers anyway, so extra connections only add
+// SQLITE_BUSY retries under load. Raise MaxOpenConns only for read-heavy
+// workloads. Every connection, including recycled and re-opened ones, gets
+// the per-connection PRAGMAs from the DSN (see connPragmas), but connection
+// state such as ATTACHed databases and TEMP objects does not carry over.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{MaxOpenConns: 1, MaxIdleConns: 1}
+}
+
+func configurePool(db *sql.DB, cfg PoolConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+}
+
+// ConfigurePool replaces the pool settings applied when the DB was opened.
+func (db *DB) ConfigurePool(cfg PoolConfig) {
+	configurePool(db.DB, cfg)
+}
+
+// PoolStats is a JSON-friendly view of sql.DBStats.
+type PoolStats struct {
+	MaxOpenConnections int     `json:"max_open_connections"`
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationSecs   float64 `json:"wait_duration_secs"`
+	MaxIdleClosed      int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64   `json:"max_lifetime_closed"`
+}
+
+// PoolStats reports connection pool usage. A growing WaitCount means callers
+// are queueing for a connection.
+func (db *DB) PoolStats() PoolStats {
+	s := db.Stats()
+	return PoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationSecs:   s.WaitDuration.Seconds(),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+}
+
+// poolMetricsDB is the DB whose pool is exported as the "db_pool" expvar.
+// expvar names are process-global, so the variable is published once and
+// follows the most recent AttachAdminRoutes call.
+var (
+	poolMetricsDB      atomic.Pointer[DB]
+	publishPoolMetrics sync.Once
+)
+
+func exportPoolMetrics(db *DB) {
+	poolMetricsDB.Store(db)
+	publishPoolMetrics.Do(func() { expvar.Publish("db_pool", poolStatsVar{}) })
+}
+
+// poolStatsVar serves PoolStats as JSON at /debug/vars and, through
+// WritePrometheus, as gauges and counters at /debug/varz.
+type poolStatsVar struct{}
+
+func (poolStatsVar) stats() (PoolStats, bool) {
+	db := poolMetricsDB.Load()
+	if db == nil {
+		return PoolStats{}, false
+	}
+	return db.PoolStats(), true
+}
+
+func (v poolStatsVar) String() string {
+	s, _ := v.stats()
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+func (v poolStatsVar) WritePrometheus(w io.Writer, name string) {
+	s, ok := v.stats()
+	if !ok {
+		return
+	}
+	write := func(metric, typ string, val any) {
+		fmt.Fprintf(w, "# TYPE %s_%s %s\n%s_%s %v\n", name, metric, typ, name, metric, val)
+	}
+	write("max_open_connections", "gauge", s.MaxOpenConnections)
+	write("open_connections", "gauge", s.OpenConnections)
+	write("in_use", "gauge", s.InUse)
+	write("idle", "gauge", s.Idle)
+	write("wait_count", "counter", s.WaitCount)
+	write("wait_duration_seconds", "counter", s.WaitDurationSecs)
+	write("max_idle_closed", "counter", s.MaxIdleClosed)
+	write("max_idle_time_closed", "counter", s.MaxIdleTimeClosed)
+	write("max_lifetime_closed", "counter", s.MaxLifetimeClosed)
+}
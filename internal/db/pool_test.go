@@ -0,0 +1,161 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDB_AppliesDefaultPoolConfig(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "pool.db"))
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	if got := db.PoolStats().MaxOpenConnections; got != 1 {
+		t.Errorf("MaxOpenConnections: want 1, got %d", got)
+	}
+	// The single connection is reused, so it stays idle between queries.
+	for i := 0; i < 3; i++ {
+		var n int
+		if err := db.QueryRow("SELECT 1").Scan(&n); err != nil {
+			t.Fatalf("query: %v", err)
+		}
+	}
+	if s := db.PoolStats(); s.OpenConnections != 1 || s.Idle != 1 || s.InUse != 0 {
+		t.Errorf("expected one idle connection, got %+v", s)
+	}
+}
+
+func TestDB_ConfigurePool(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "pool.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+
+	db.ConfigurePool(PoolConfig{MaxOpenConns: 3, MaxIdleConns: 0, ConnMaxLifetime: time.Minute})
+	if got := db.PoolStats().MaxOpenConnections; got != 3 {
+		t.Errorf("MaxOpenConnections: want 3, got %d", got)
+	}
+
+	// With no idle connections allowed, each released connection is closed.
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec("SELECT 1"); err != nil {
+			t.Fatalf("exec: %v", err)
+		}
+	}
+	if s := db.PoolStats(); s.Idle != 0 || s.MaxIdleClosed < 2 {
+		t.Errorf("expected released connections to be closed, got %+v", s)
+	}
+}
+
+func TestDB_PragmasOnEveryConnection(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "pool.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+
+	// No idle connections: every query runs on a freshly opened one.
+	db.ConfigurePool(PoolConfig{MaxOpenConns: 2, MaxIdleConns: 0})
+	for i := 0; i < 3; i++ {
+		var fk, timeout, sync int
+		if err := db.QueryRow("PRAGMA foreign_keys").Scan(&fk); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.QueryRow("PRAGMA synchronous").Scan(&sync); err != nil {
+			t.Fatal(err)
+		}
+		if fk != 1 || timeout != 30000 || sync != 1 {
+			t.Errorf("connection %d: foreign_keys=%d busy_timeout=%d synchronous=%d, want 1, 30000, 1 (NORMAL)", i, fk, timeout, sync)
+		}
+	}
+	if s := db.PoolStats(); s.MaxIdleClosed < 3 {
+		t.Errorf("expected a new connection per query, got %+v", s)
+	}
+}
+
+func TestPragmaDSN(t *testing.T) {
+	if got := pragmaDSN("file:x.db?mode=ro"); !strings.HasPrefix(got, "file:x.db?mode=ro&_pragma=busy_timeout%2830000%29") {
+		t.Errorf("pragmaDSN with a query = %q", got)
+	}
+	if got := pragmaDSN("x.db"); !strings.HasPrefix(got, "x.db?_pragma=") {
+		t.Errorf("pragmaDSN = %q", got)
+	}
+}
+
+func TestDB_PoolStatsWaitCount(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "pool.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = db.Exec("SELECT 1") // waits for the transaction's connection
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for db.PoolStats().WaitCount == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("query never waited for a connection")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	<-done
+
+	if s := db.PoolStats(); s.WaitCount != 1 || s.WaitDurationSecs <= 0 {
+		t.Errorf("expected one recorded wait, got %+v", s)
+	}
+}
+
+func TestAttachAdminRoutes_ExportsPoolMetrics(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "pool.db"))
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+	db.AttachAdminRoutes(http.NewServeMux())
+
+	v := expvar.Get("db_pool")
+	if v == nil {
+		t.Fatal("db_pool expvar not published")
+	}
+	var stats PoolStats
+	if err := json.Unmarshal([]byte(v.String()), &stats); err != nil {
+		t.Fatalf("decode db_pool: %v", err)
+	}
+	if stats.MaxOpenConnections != 1 {
+		t.Errorf("db_pool max_open_connections: want 1, got %d", stats.MaxOpenConnections)
+	}
+
+	var buf bytes.Buffer
+	v.(poolStatsVar).WritePrometheus(&buf, "db_pool")
+	for _, want := range []string{
+		"# TYPE db_pool_in_use gauge\ndb_pool_in_use 0\n",
+		"# TYPE db_pool_wait_count counter\ndb_pool_wait_count 0\n",
+		"db_pool_max_open_connections 1\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Prometheus output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
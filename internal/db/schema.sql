@@ -27,6 +27,12 @@
         , snapshot_reason TEXT
           );
 
+   CREATE TABLE lidar_bg_snapshot_latest (
+          sensor_id TEXT PRIMARY KEY
+        , snapshot_id INTEGER NOT NULL
+        , FOREIGN KEY (snapshot_id) REFERENCES lidar_bg_snapshot (snapshot_id) ON DELETE CASCADE
+          );
+
    CREATE TABLE lidar_clusters (
           lidar_cluster_id INTEGER PRIMARY KEY
         , sensor_id TEXT NOT NULL
@@ -44,6 +50,7 @@
         , noise_points_count INTEGER DEFAULT 0
         , cluster_density REAL
         , aspect_ratio REAL
+        , points_count_normalized REAL
           );
 
    CREATE TABLE lidar_param_sets (
@@ -91,7 +98,7 @@
         , frame_start_ns INTEGER
         , frame_end_ns INTEGER
         , CHECK (source_type IN ('live', 'pcap'))
-        , CHECK (status IN ('running', 'completed', 'failed'))
+        , CHECK (status IN ('pending', 'running', 'completed', 'failed'))
         , FOREIGN KEY (parent_run_id) REFERENCES "lidar_run_records" (run_id) ON DELETE SET NULL
           );
 
@@ -275,6 +282,8 @@
         , max_occlusion_frames INTEGER DEFAULT 0
         , spatial_coverage REAL
         , noise_point_ratio REAL
+        , archived_unix_nanos INTEGER
+        , composite_speed_mps REAL
         , CHECK (track_state IN ('tentative', 'confirmed', 'deleted'))
         , CHECK (
           end_unix_nanos IS NULL
@@ -339,6 +348,37 @@
         , CHECK (status IN ('running', 'completed', 'failed', 'suspended'))
           );
 
+   CREATE TABLE lidar_tuning_presets (
+          name TEXT PRIMARY KEY
+        , params_json TEXT NOT NULL
+        , created_at INTEGER NOT NULL
+        , updated_at INTEGER NOT NULL
+          );
+
+   CREATE TABLE lidar_algorithm_runs (
+          run_id TEXT PRIMARY KEY
+        , source_path TEXT
+        , sensor_id TEXT
+        , extractors_json TEXT NOT NULL
+        , merge_mode TEXT
+        , frame_count INTEGER NOT NULL DEFAULT 0
+        , mean_agreement REAL
+        , mean_iou REAL
+        , foreground_ratios_json TEXT
+        , created_at INTEGER NOT NULL
+          );
+
+   CREATE TABLE lidar_algorithm_frame_results (
+          run_id TEXT NOT NULL
+        , frame_index INTEGER NOT NULL
+        , timestamp_ns INTEGER
+        , agreement REAL
+        , iou REAL
+        , foreground_ratios_json TEXT
+        , PRIMARY KEY (run_id, frame_index)
+        , FOREIGN KEY (run_id) REFERENCES lidar_algorithm_runs (run_id) ON DELETE CASCADE
+          );
+
    CREATE TABLE IF NOT EXISTS "radar_commands" (
           command_id BIGINT PRIMARY KEY
         , command TEXT
@@ -536,6 +576,10 @@ CREATE INDEX idx_lidar_tuning_sweeps_sensor ON lidar_tuning_sweeps (sensor_id);
 
 CREATE INDEX idx_lidar_tuning_sweeps_status ON lidar_tuning_sweeps (status);
 
+CREATE INDEX idx_lidar_algorithm_runs_created ON lidar_algorithm_runs (created_at);
+
+CREATE INDEX idx_lidar_algorithm_runs_source ON lidar_algorithm_runs (source_path);
+
 CREATE INDEX idx_transit_links_transit ON radar_transit_links (transit_id);
 
 CREATE INDEX idx_transit_links_data ON radar_transit_links (data_rowid);
@@ -630,6 +674,16 @@ UNION ALL
         , quality_label
      FROM lidar_run_tracks;
 
+   CREATE VIEW lidar_tracks_all AS
+   SELECT *
+        , 'main' AS partition_source
+     FROM lidar_tracks;
+
+   CREATE VIEW lidar_track_observations_all AS
+   SELECT *
+        , 'main' AS partition_source
+     FROM lidar_track_observations;
+
 CREATE INDEX idx_lidar_param_sets_params_hash ON lidar_param_sets (params_hash);
 
 CREATE INDEX idx_lidar_run_configs_config_hash ON lidar_run_configs (config_hash);
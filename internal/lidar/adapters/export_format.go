@@ -0,0 +1,243 @@
+package adapters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Export content negotiation.
+//
+// Track and observation query endpoints serve one response model in several
+// wire formats. The model is the handler's JSON-tagged response struct:
+// JSON and MessagePack encode the whole response with the JSON field names,
+// and CSV writes one row per record of its list, with nested structs
+// flattened into underscore-joined columns (position_x, bounding_box_length).
+// Slices and maps inside a record, such as a track's history, have no CSV
+// column and are left out.
+
+// ExportFormat is a negotiated response format.
+type ExportFormat string
+
+const (
+	FormatJSON    ExportFormat = "json"
+	FormatCSV     ExportFormat = "csv"
+	FormatMsgPack ExportFormat = "msgpack"
+)
+
+// ErrUnsupportedFormat is returned by NegotiateFormat when neither the
+// format parameter nor the Accept header names a supported format.
+var ErrUnsupportedFormat = errors.New("unsupported format: use json, csv or msgpack")
+
+// ContentType returns the MIME type written for the format.
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatMsgPack:
+		return "application/msgpack"
+	default:
+		return "application/json"
+	}
+}
+
+// formatsByMediaType maps Accept media types to formats. Wildcards fall
+// back to JSON.
+var formatsByMediaType = map[string]ExportFormat{
+	"application/json":        FormatJSON,
+	"application/*":           FormatJSON,
+	"*/*":                     FormatJSON,
+	"text/csv":                FormatCSV,
+	"text/*":                  FormatCSV,
+	"application/msgpack":     FormatMsgPack,
+	"application/x-msgpack":   FormatMsgPack,
+	"application/vnd.msgpack": FormatMsgPack,
+}
+
+// NegotiateFormat picks the response format for r. A format query
+// parameter (json, csv or msgpack) takes precedence for browser
+// convenience; otherwise the Accept entry with the highest q-value that
+// names a supported type wins, earlier entries winning ties. A missing or
+// empty Accept header means JSON.
+func NegotiateFormat(r *http.Request) (ExportFormat, error) {
+	if f := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))); f != "" {
+		switch ExportFormat(f) {
+		case FormatJSON, FormatCSV, FormatMsgPack:
+			return ExportFormat(f), nil
+		}
+		return "", ErrUnsupportedFormat
+	}
+
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	if accept == "" {
+		return FormatJSON, nil
+	}
+	best, bestQ := ExportFormat(""), 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		format, ok := formatsByMediaType[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(qs, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	if best == "" {
+		return "", ErrUnsupportedFormat
+	}
+	return best, nil
+}
+
+// WriteExport writes body in format with status 200. For CSV, rows (a
+// slice of structs or struct pointers) is written instead of body; JSON
+// and MessagePack ignore rows.
+func WriteExport(w http.ResponseWriter, format ExportFormat, body, rows interface{}) error {
+	w.Header().Set("Content-Type", format.ContentType())
+	switch format {
+	case FormatCSV:
+		return writeCSVRows(w, rows)
+	case FormatMsgPack:
+		enc := msgpack.NewEncoder(w)
+		enc.SetCustomStructTag("json")
+		return enc.Encode(body)
+	default:
+		return json.NewEncoder(w).Encode(body)
+	}
+}
+
+// writeCSVRows writes a header from the element type of rows and one line
+// per element.
+func writeCSVRows(w http.ResponseWriter, rows interface{}) error {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("csv export: rows must be a slice, got %T", rows)
+	}
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("csv export: rows must hold structs, got %s", elem)
+	}
+
+	cw := csv.NewWriter(w)
+	var header []string
+	flattenCSV(elem, reflect.Value{}, "", func(name, _ string) { header = append(header, name) })
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, 0, len(header))
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				row = reflect.Value{}
+			} else {
+				row = row.Elem()
+			}
+		}
+		record = record[:0]
+		flattenCSV(elem, row, "", func(_, value string) { record = append(record, value) })
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// flattenCSV calls emit for each CSV column of struct type t in field
+// order, with the formatted value from v. v may be the zero Value (for the
+// header, or a nil nested pointer), giving empty cells.
+func flattenCSV(t reflect.Type, v reflect.Value, prefix string, emit func(name, value string)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		ft := field.Type
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			if fv.IsValid() {
+				if fv.IsNil() {
+					fv = reflect.Value{}
+				} else {
+					fv = fv.Elem()
+				}
+			}
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			if field.Anonymous && name == field.Name {
+				flattenCSV(ft, fv, prefix, emit)
+			} else {
+				flattenCSV(ft, fv, prefix+name+"_", emit)
+			}
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func:
+			// No scalar column.
+		default:
+			emit(prefix+name, formatCSVValue(fv))
+		}
+	}
+}
+
+// jsonFieldName returns the field's JSON name, or the Go name if untagged.
+func jsonFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func formatCSVValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
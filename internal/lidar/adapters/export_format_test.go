@@ -0,0 +1,140 @@
+package adapters
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		accept  string
+		want    ExportFormat
+		wantErr bool
+	}{
+		{name: "no accept header", want: FormatJSON},
+		{name: "json", accept: "application/json", want: FormatJSON},
+		{name: "csv", accept: "text/csv", want: FormatCSV},
+		{name: "msgpack", accept: "application/msgpack", want: FormatMsgPack},
+		{name: "msgpack alias", accept: "application/x-msgpack", want: FormatMsgPack},
+		{name: "wildcard", accept: "*/*", want: FormatJSON},
+		{name: "browser default", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", want: FormatJSON},
+		{name: "highest q wins", accept: "application/json;q=0.5, text/csv;q=0.9", want: FormatCSV},
+		{name: "first wins ties", accept: "application/msgpack, application/json", want: FormatMsgPack},
+		{name: "q=0 excluded", accept: "text/csv;q=0, application/json;q=0.1", want: FormatJSON},
+		{name: "unsupported", accept: "application/xml", wantErr: true},
+		{name: "query overrides header", query: "format=csv", accept: "application/json", want: FormatCSV},
+		{name: "query is case-insensitive", query: "format=MsgPack", want: FormatMsgPack},
+		{name: "unknown query format", query: "format=xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/lidar/tracks?"+tt.query, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			got, err := NegotiateFormat(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NegotiateFormat = %q, want error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NegotiateFormat = (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+type exportPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type exportRecord struct {
+	ID       string       `json:"id"`
+	Count    int          `json:"count"`
+	Speed    float32      `json:"speed_mps"`
+	Position exportPoint  `json:"position"`
+	Spread   *exportPoint `json:"spread,omitempty"`
+	History  []float32    `json:"history,omitempty"`
+	Internal string       `json:"-"`
+	Untagged bool
+}
+
+type exportList struct {
+	Records []exportRecord `json:"records"`
+	Count   int            `json:"count"`
+}
+
+func TestWriteExport_CSVFlattensRecords(t *testing.T) {
+	list := exportList{
+		Records: []exportRecord{
+			{ID: "a", Count: 3, Speed: 1.25, Position: exportPoint{X: 1, Y: -2.5}, Spread: &exportPoint{X: 0.1, Y: 0.2}, History: []float32{1}},
+			{ID: "b,c", Count: 1, Untagged: true},
+		},
+		Count: 2,
+	}
+	rec := httptest.NewRecorder()
+	if err := WriteExport(rec, FormatCSV, list, list.Records); err != nil {
+		t.Fatalf("WriteExport: %v", err)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	want := [][]string{
+		{"id", "count", "speed_mps", "position_x", "position_y", "spread_x", "spread_y", "Untagged"},
+		{"a", "3", "1.25", "1", "-2.5", "0.1", "0.2", "false"},
+		{"b,c", "1", "0", "0", "0", "", "", "true"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("CSV rows = %v, want %v", rows, want)
+	}
+}
+
+func TestWriteExport_MsgPackUsesJSONNames(t *testing.T) {
+	list := exportList{Records: []exportRecord{{ID: "a", Count: 3, Position: exportPoint{X: 1}}}, Count: 1}
+	rec := httptest.NewRecorder()
+	if err := WriteExport(rec, FormatMsgPack, list, list.Records); err != nil {
+		t.Fatalf("WriteExport: %v", err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/msgpack" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var decoded map[string]interface{}
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode msgpack: %v", err)
+	}
+	records, _ := decoded["records"].([]interface{})
+	if len(records) != 1 {
+		t.Fatalf("records = %v", decoded["records"])
+	}
+	first, _ := records[0].(map[string]interface{})
+	if first["id"] != "a" {
+		t.Errorf("record = %v", first)
+	}
+	if _, ok := first["spread"]; ok {
+		t.Error("omitempty field was encoded")
+	}
+	if _, ok := first["Internal"]; ok {
+		t.Error(`json:"-" field was encoded`)
+	}
+}
+
+func TestWriteExport_CSVRejectsNonSlice(t *testing.T) {
+	if err := WriteExport(httptest.NewRecorder(), FormatCSV, nil, exportList{}); err == nil {
+		t.Error("expected error for non-slice rows")
+	}
+}
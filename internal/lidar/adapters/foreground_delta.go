@@ -0,0 +1,304 @@
+package adapters
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+// Delta-encoded foreground blobs.
+//
+// A mostly static scene produces nearly the same foreground from one frame
+// to the next, so encoding every frame in full (EncodeForegroundBlob) spends
+// most of its bytes repeating the previous frame. The delta variant encodes
+// a frame as the points removed from and added to the previous frame's
+// foreground, with a full keyframe every KeyframeInterval frames so a reader
+// can start decoding part-way through a sequence.
+//
+// Points are compared after quantisation to the compact 8-byte layout, so a
+// point is "unchanged" when its quantised distance, azimuth, elevation,
+// intensity and ring all match a point in the previous frame. Decoded points
+// are returned in canonical order (ring, then azimuth, elevation, distance,
+// intensity) rather than input order; the full encoding remains the default
+// wherever input order matters.
+//
+// Wire format (byte 0 is the blob kind):
+//
+//	keyframe: 0x01 | N × 8-byte compact points (EncodeForegroundBlob layout)
+//	delta:    0x02 | uvarint removed | uvarint added
+//	               | removed × uvarint index gap into the previous frame
+//	               | added × 8-byte compact points
+
+// Foreground blob kinds written as the first byte of a delta-stream blob.
+const (
+	ForegroundBlobKeyframe byte = 0x01
+	ForegroundBlobDelta    byte = 0x02
+)
+
+// DefaultForegroundKeyframeInterval is the number of frames between
+// keyframes (3 s at 10 Hz).
+const DefaultForegroundKeyframeInterval = 30
+
+// maxForegroundBlobPoints bounds the number of points a decoder will
+// allocate for from untrusted input. At 40+ bytes per PointPolar struct,
+// 1M points = ~40MB memory.
+const maxForegroundBlobPoints = 1000000
+
+// ForegroundDeltaEncoder encodes a sequence of foreground frames as
+// keyframes and deltas. It is not safe for concurrent use; use one encoder
+// per sensor stream.
+type ForegroundDeltaEncoder struct {
+	// KeyframeInterval is the maximum number of frames between keyframes.
+	// Values below 1 make every frame a keyframe.
+	KeyframeInterval int
+
+	prev          []uint64 // previous frame, packed and sorted
+	havePrev      bool
+	sinceKeyframe int
+}
+
+// NewForegroundDeltaEncoder creates an encoder that emits a keyframe at
+// least every keyframeInterval frames.
+func NewForegroundDeltaEncoder(keyframeInterval int) *ForegroundDeltaEncoder {
+	return &ForegroundDeltaEncoder{KeyframeInterval: keyframeInterval}
+}
+
+// Reset forces the next frame to be encoded as a keyframe.
+func (e *ForegroundDeltaEncoder) Reset() {
+	e.prev = nil
+	e.havePrev = false
+	e.sinceKeyframe = 0
+}
+
+// Encode encodes one frame. The first frame, every KeyframeInterval-th frame,
+// and any frame whose delta would not be smaller than a keyframe are encoded
+// as keyframes.
+func (e *ForegroundDeltaEncoder) Encode(points []l2frames.PointPolar) []byte {
+	cur := packForegroundPoints(points)
+
+	if e.havePrev && e.KeyframeInterval > 1 && e.sinceKeyframe < e.KeyframeInterval-1 {
+		delta := encodeForegroundDelta(e.prev, cur)
+		if len(delta) < 1+len(cur)*CompactPointSize {
+			e.prev = cur
+			e.sinceKeyframe++
+			return delta
+		}
+	}
+
+	e.prev = cur
+	e.havePrev = true
+	e.sinceKeyframe = 0
+	return encodeForegroundKeyframe(cur)
+}
+
+// ForegroundDeltaDecoder decodes blobs produced by ForegroundDeltaEncoder.
+// Blobs must be decoded in the order they were encoded.
+type ForegroundDeltaDecoder struct {
+	prev     []uint64
+	havePrev bool
+}
+
+// NewForegroundDeltaDecoder creates a decoder.
+func NewForegroundDeltaDecoder() *ForegroundDeltaDecoder {
+	return &ForegroundDeltaDecoder{}
+}
+
+// ErrForegroundDeltaWithoutKeyframe is returned when a delta blob arrives
+// before any keyframe has been decoded.
+var ErrForegroundDeltaWithoutKeyframe = errors.New("foreground delta blob before first keyframe")
+
+// Decode decodes one blob and returns the frame's foreground points in
+// canonical order. On error the decoder state is unchanged.
+func (d *ForegroundDeltaDecoder) Decode(blob []byte) ([]l2frames.PointPolar, error) {
+	if len(blob) == 0 {
+		return nil, fmt.Errorf("empty foreground blob")
+	}
+
+	var cur []uint64
+	switch blob[0] {
+	case ForegroundBlobKeyframe:
+		packed, err := decodeCompactPacked(blob[1:])
+		if err != nil {
+			return nil, err
+		}
+		slices.Sort(packed)
+		cur = packed
+	case ForegroundBlobDelta:
+		if !d.havePrev {
+			return nil, ErrForegroundDeltaWithoutKeyframe
+		}
+		next, err := applyForegroundDelta(d.prev, blob[1:])
+		if err != nil {
+			return nil, err
+		}
+		cur = next
+	default:
+		return nil, fmt.Errorf("unknown foreground blob kind 0x%02x", blob[0])
+	}
+
+	d.prev = cur
+	d.havePrev = true
+	return unpackForegroundPoints(cur), nil
+}
+
+// packCompactPoint packs a compact point (EncodeForegroundBlob layout) into
+// a uint64 whose natural order is ring, azimuth, elevation, distance,
+// intensity. The packing is lossless.
+func packCompactPoint(b []byte) uint64 {
+	dist := uint64(binary.LittleEndian.Uint16(b[0:]))
+	az := uint64(binary.LittleEndian.Uint16(b[2:]))
+	el := uint64(binary.LittleEndian.Uint16(b[4:]))
+	intensity := uint64(b[6])
+	ring := uint64(b[7])
+	return ring<<56 | az<<40 | el<<24 | dist<<8 | intensity
+}
+
+// putCompactPoint writes a packed point back in the compact layout.
+func putCompactPoint(b []byte, v uint64) {
+	binary.LittleEndian.PutUint16(b[0:], uint16(v>>8))
+	binary.LittleEndian.PutUint16(b[2:], uint16(v>>40))
+	binary.LittleEndian.PutUint16(b[4:], uint16(v>>24))
+	b[6] = uint8(v)
+	b[7] = uint8(v >> 56)
+}
+
+// packForegroundPoints quantises points exactly as EncodeForegroundBlob does
+// and returns them packed and sorted.
+func packForegroundPoints(points []l2frames.PointPolar) []uint64 {
+	compact := EncodeForegroundBlob(points)
+	packed := make([]uint64, len(points))
+	for i := range packed {
+		packed[i] = packCompactPoint(compact[i*CompactPointSize:])
+	}
+	slices.Sort(packed)
+	return packed
+}
+
+func unpackForegroundPoints(packed []uint64) []l2frames.PointPolar {
+	compact := make([]byte, len(packed)*CompactPointSize)
+	for i, v := range packed {
+		putCompactPoint(compact[i*CompactPointSize:], v)
+	}
+	return DecodeForegroundBlob(compact)
+}
+
+func decodeCompactPacked(b []byte) ([]uint64, error) {
+	if len(b)%CompactPointSize != 0 {
+		return nil, fmt.Errorf("foreground blob length %d is not a multiple of %d", len(b), CompactPointSize)
+	}
+	n := len(b) / CompactPointSize
+	if n > maxForegroundBlobPoints {
+		return nil, fmt.Errorf("foreground blob has %d points, limit %d", n, maxForegroundBlobPoints)
+	}
+	packed := make([]uint64, n)
+	for i := range packed {
+		packed[i] = packCompactPoint(b[i*CompactPointSize:])
+	}
+	return packed, nil
+}
+
+func encodeForegroundKeyframe(cur []uint64) []byte {
+	blob := make([]byte, 1+len(cur)*CompactPointSize)
+	blob[0] = ForegroundBlobKeyframe
+	for i, v := range cur {
+		putCompactPoint(blob[1+i*CompactPointSize:], v)
+	}
+	return blob
+}
+
+// encodeForegroundDelta diffs two sorted multisets with a merge walk.
+func encodeForegroundDelta(prev, cur []uint64) []byte {
+	var removed []int
+	var added []uint64
+	i, j := 0, 0
+	for i < len(prev) || j < len(cur) {
+		switch {
+		case j == len(cur) || (i < len(prev) && prev[i] < cur[j]):
+			removed = append(removed, i)
+			i++
+		case i == len(prev) || cur[j] < prev[i]:
+			added = append(added, cur[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+
+	blob := make([]byte, 0, 1+2*binary.MaxVarintLen32+len(removed)*2+len(added)*CompactPointSize)
+	blob = append(blob, ForegroundBlobDelta)
+	blob = binary.AppendUvarint(blob, uint64(len(removed)))
+	blob = binary.AppendUvarint(blob, uint64(len(added)))
+	last := -1
+	for _, idx := range removed {
+		blob = binary.AppendUvarint(blob, uint64(idx-last-1))
+		last = idx
+	}
+	var buf [CompactPointSize]byte
+	for _, v := range added {
+		putCompactPoint(buf[:], v)
+		blob = append(blob, buf[:]...)
+	}
+	return blob
+}
+
+func applyForegroundDelta(prev []uint64, b []byte) ([]uint64, error) {
+	readUvarint := func(what string) (uint64, error) {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return 0, fmt.Errorf("foreground delta: truncated %s", what)
+		}
+		b = b[n:]
+		return v, nil
+	}
+
+	nRemoved, err := readUvarint("removed count")
+	if err != nil {
+		return nil, err
+	}
+	nAdded, err := readUvarint("added count")
+	if err != nil {
+		return nil, err
+	}
+	if nRemoved > uint64(len(prev)) {
+		return nil, fmt.Errorf("foreground delta removes %d of %d points", nRemoved, len(prev))
+	}
+	if nAdded > maxForegroundBlobPoints || uint64(len(prev))-nRemoved+nAdded > maxForegroundBlobPoints {
+		return nil, fmt.Errorf("foreground delta exceeds %d points", maxForegroundBlobPoints)
+	}
+
+	drop := make([]bool, len(prev))
+	next := -1
+	for k := uint64(0); k < nRemoved; k++ {
+		gap, err := readUvarint("removed index")
+		if err != nil {
+			return nil, err
+		}
+		if gap >= uint64(len(prev)) || next+1+int(gap) >= len(prev) {
+			return nil, fmt.Errorf("foreground delta removed index out of range")
+		}
+		next += 1 + int(gap)
+		drop[next] = true
+	}
+
+	if uint64(len(b)) != nAdded*CompactPointSize {
+		return nil, fmt.Errorf("foreground delta has %d trailing bytes for %d added points", len(b), nAdded)
+	}
+	added, err := decodeCompactPacked(b)
+	if err != nil {
+		return nil, err
+	}
+
+	cur := make([]uint64, 0, len(prev)-int(nRemoved)+len(added))
+	for i, v := range prev {
+		if !drop[i] {
+			cur = append(cur, v)
+		}
+	}
+	cur = append(cur, added...)
+	slices.Sort(cur)
+	return cur, nil
+}
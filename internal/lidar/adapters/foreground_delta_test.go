@@ -0,0 +1,231 @@
+package adapters
+
+import (
+	"cmp"
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+// staticSceneSequence builds a mostly static foreground sequence: a fixed
+// set of points (parked vehicle, persistent clutter), a few points that
+// flicker in and out, and one small object moving across the scene.
+func staticSceneSequence(frames int) [][]l2frames.PointPolar {
+	rng := rand.New(rand.NewSource(7))
+
+	static := make([]l2frames.PointPolar, 0, 1500)
+	for ring := 0; ring < 30; ring++ {
+		for k := 0; k < 50; k++ {
+			static = append(static, l2frames.PointPolar{
+				Channel:   ring,
+				Azimuth:   120 + float64(k)*0.2,
+				Elevation: -15 + float64(ring)*0.5,
+				Distance:  12.5 + float64(k)*0.01,
+				Intensity: uint8(40 + ring),
+			})
+		}
+	}
+
+	seq := make([][]l2frames.PointPolar, frames)
+	for f := range seq {
+		frame := slices.Clone(static)
+		for k := 0; k < 20; k++ {
+			if rng.Float64() < 0.5 {
+				frame = append(frame, l2frames.PointPolar{Channel: 35, Azimuth: 300 + float64(k), Distance: 30, Intensity: 5})
+			}
+		}
+		for ring := 0; ring < 10; ring++ {
+			for k := 0; k < 8; k++ {
+				frame = append(frame, l2frames.PointPolar{
+					Channel:   ring,
+					Azimuth:   10 + float64(f)*0.4 + float64(k)*0.2,
+					Elevation: -15 + float64(ring)*0.5,
+					Distance:  20,
+					Intensity: 90,
+				})
+			}
+		}
+		rng.Shuffle(len(frame), func(i, j int) { frame[i], frame[j] = frame[j], frame[i] })
+		seq[f] = frame
+	}
+	return seq
+}
+
+// quantised returns points as the full encoding would round-trip them,
+// sorted for order-insensitive comparison.
+func quantised(points []l2frames.PointPolar) []l2frames.PointPolar {
+	return sortedPoints(DecodeForegroundBlob(EncodeForegroundBlob(points)))
+}
+
+func sortedPoints(points []l2frames.PointPolar) []l2frames.PointPolar {
+	out := slices.Clone(points)
+	slices.SortFunc(out, func(a, b l2frames.PointPolar) int {
+		return cmp.Or(
+			cmp.Compare(a.Channel, b.Channel),
+			cmp.Compare(a.Azimuth, b.Azimuth),
+			cmp.Compare(a.Elevation, b.Elevation),
+			cmp.Compare(a.Distance, b.Distance),
+			cmp.Compare(a.Intensity, b.Intensity),
+		)
+	})
+	return out
+}
+
+func samePoints(a, b []l2frames.PointPolar) bool {
+	return slices.EqualFunc(sortedPoints(a), sortedPoints(b), func(x, y l2frames.PointPolar) bool {
+		return x.Channel == y.Channel && x.Azimuth == y.Azimuth && x.Elevation == y.Elevation &&
+			x.Distance == y.Distance && x.Intensity == y.Intensity
+	})
+}
+
+func TestForegroundDelta_RoundTripAndSize(t *testing.T) {
+	seq := staticSceneSequence(120)
+
+	enc := NewForegroundDeltaEncoder(DefaultForegroundKeyframeInterval)
+	dec := NewForegroundDeltaDecoder()
+
+	fullBytes, deltaBytes, keyframes := 0, 0, 0
+	for i, frame := range seq {
+		blob := enc.Encode(frame)
+		if blob[0] == ForegroundBlobKeyframe {
+			keyframes++
+		}
+		deltaBytes += len(blob)
+		fullBytes += len(EncodeForegroundBlob(frame))
+
+		decoded, err := dec.Decode(blob)
+		if err != nil {
+			t.Fatalf("frame %d: decode: %v", i, err)
+		}
+		if !samePoints(decoded, quantised(frame)) {
+			t.Fatalf("frame %d: decoded %d points, want %d matching the full encoding", i, len(decoded), len(frame))
+		}
+	}
+
+	if want := len(seq) / DefaultForegroundKeyframeInterval; keyframes != want {
+		t.Errorf("expected %d keyframes, got %d", want, keyframes)
+	}
+	ratio := float64(deltaBytes) / float64(fullBytes)
+	t.Logf("mostly static sequence: %d frames, full %d bytes, delta %d bytes (%.1f%% of full, %d keyframes)",
+		len(seq), fullBytes, deltaBytes, 100*ratio, keyframes)
+	if ratio > 0.25 {
+		t.Errorf("expected delta encoding under 25%% of full size, got %.1f%%", 100*ratio)
+	}
+}
+
+func TestForegroundDelta_DuplicatesAndEmptyFrames(t *testing.T) {
+	p := l2frames.PointPolar{Channel: 3, Azimuth: 10, Elevation: -2, Distance: 5, Intensity: 9}
+	q := l2frames.PointPolar{Channel: 3, Azimuth: 10.5, Elevation: 1, Distance: 5, Intensity: 9}
+	seq := [][]l2frames.PointPolar{
+		{p, p, q},
+		{p, q},
+		{},
+		{p, p, p},
+		{q},
+	}
+
+	enc := NewForegroundDeltaEncoder(10)
+	dec := NewForegroundDeltaDecoder()
+	for i, frame := range seq {
+		decoded, err := dec.Decode(enc.Encode(frame))
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if !samePoints(decoded, quantised(frame)) {
+			t.Fatalf("frame %d: got %+v, want %+v", i, decoded, frame)
+		}
+	}
+}
+
+func TestForegroundDelta_StartAtKeyframe(t *testing.T) {
+	seq := staticSceneSequence(12)
+	enc := NewForegroundDeltaEncoder(5)
+	blobs := make([][]byte, len(seq))
+	for i, frame := range seq {
+		blobs[i] = enc.Encode(frame)
+	}
+
+	dec := NewForegroundDeltaDecoder()
+	if _, err := dec.Decode(blobs[1]); !errors.Is(err, ErrForegroundDeltaWithoutKeyframe) {
+		t.Fatalf("expected ErrForegroundDeltaWithoutKeyframe, got %v", err)
+	}
+	if blobs[5][0] != ForegroundBlobKeyframe {
+		t.Fatalf("expected frame 5 to be a keyframe")
+	}
+	for i := 5; i < len(blobs); i++ {
+		decoded, err := dec.Decode(blobs[i])
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if !samePoints(decoded, quantised(seq[i])) {
+			t.Fatalf("frame %d: mismatch after joining at keyframe", i)
+		}
+	}
+}
+
+func TestForegroundDelta_ResetAndKeyframeOnly(t *testing.T) {
+	seq := staticSceneSequence(4)
+
+	enc := NewForegroundDeltaEncoder(DefaultForegroundKeyframeInterval)
+	enc.Encode(seq[0])
+	if blob := enc.Encode(seq[1]); blob[0] != ForegroundBlobDelta {
+		t.Fatalf("expected a delta after the first frame")
+	}
+	enc.Reset()
+	if blob := enc.Encode(seq[2]); blob[0] != ForegroundBlobKeyframe {
+		t.Fatalf("expected a keyframe after Reset")
+	}
+
+	every := NewForegroundDeltaEncoder(1)
+	for i, frame := range seq {
+		if blob := every.Encode(frame); blob[0] != ForegroundBlobKeyframe {
+			t.Fatalf("frame %d: interval 1 should only emit keyframes", i)
+		}
+	}
+}
+
+func TestForegroundDeltaDecoder_Malformed(t *testing.T) {
+	points := []l2frames.PointPolar{
+		{Channel: 1, Azimuth: 1, Distance: 1},
+		{Channel: 1, Azimuth: 2, Distance: 1},
+		{Channel: 1, Azimuth: 3, Distance: 1},
+	}
+	enc := NewForegroundDeltaEncoder(10)
+	key := enc.Encode(points)
+	delta := enc.Encode(points[:2])
+	if delta[0] != ForegroundBlobDelta {
+		t.Fatal("expected dropping one point to encode as a delta")
+	}
+
+	tests := []struct {
+		name string
+		blob []byte
+	}{
+		{"empty", nil},
+		{"unknown kind", []byte{0x7f}},
+		{"keyframe bad length", append(slices.Clone(key), 0x00)},
+		{"delta truncated", delta[:1]},
+		{"delta removes too many", []byte{ForegroundBlobDelta, 5, 0}},
+		{"delta index out of range", []byte{ForegroundBlobDelta, 1, 0, 3}},
+		{"delta missing added bytes", []byte{ForegroundBlobDelta, 0, 1, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewForegroundDeltaDecoder()
+			if _, err := dec.Decode(key); err != nil {
+				t.Fatalf("keyframe: %v", err)
+			}
+			if _, err := dec.Decode(tt.blob); err == nil {
+				t.Fatal("expected error")
+			}
+			// State is unchanged after an error: the valid delta still applies.
+			got, err := dec.Decode(delta)
+			if err != nil || len(got) != 2 {
+				t.Fatalf("expected 2 points after valid delta, got %v, %v", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+// Package httpretry retries transient HTTP failures for the clients that
+// drive a LiDAR monitor from outside the process, such as the sweep tool.
+//
+// A sweep can run for hours against a server on the same network; a
+// dropped connection or a 503 while the server restarts its replay should
+// cost a few seconds of backoff, not the whole run. Transport retries
+// timeouts, refused or reset connections and 5xx responses with
+// exponential backoff, and passes everything else (4xx responses, bad
+// URLs) straight through so permanent errors surface on the first try.
+//
+// Only idempotent requests are retried: GET, HEAD, OPTIONS, TRACE, PUT
+// and DELETE, and other methods the caller marks with Idempotent. A POST
+// that reached the server before the connection dropped may already have
+// taken effect, so an unmarked one gets a single try unless
+// Config.RetryNonIdempotent is set.
+//
+// The retry budget is bounded twice: each request gives up after
+// MaxAttempts, and once the server has failed continuously for longer than
+// Deadline the circuit opens and requests fail fast with ErrCircuitOpen
+// until one succeeds. Callers check for ErrCircuitOpen to stop a run
+// cleanly and keep the results gathered so far.
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrCircuitOpen is returned once the server has been failing for longer
+// than Config.Deadline.
+var ErrCircuitOpen = errors.New("server unreachable past the retry deadline")
+
+// Config controls retries and the circuit deadline.
+type Config struct {
+	// MaxAttempts is the number of tries per request, including the
+	// first. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles on
+	// each further retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Deadline is how long the server may fail continuously before the
+	// circuit opens. Zero keeps the circuit closed.
+	Deadline time.Duration
+	// AttemptTimeout bounds each try separately, so a hung connection is
+	// retried rather than consuming the whole request. Zero means no
+	// per-attempt timeout beyond the request's own context.
+	AttemptTimeout time.Duration
+	// RetryNonIdempotent retries every method, for clients whose
+	// receivers tolerate a request arriving twice. Without it only
+	// idempotent requests are retried.
+	RetryNonIdempotent bool
+
+	// Logf, when set, is called for each retry and when the circuit
+	// opens or closes.
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultConfig returns the retry settings used by the sweep tool: five
+// tries per request backing off from half a second, a 30 s limit per try,
+// and a five-minute outage before giving up.
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:    5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Deadline:       5 * time.Minute,
+		AttemptTimeout: 30 * time.Second,
+	}
+}
+
+// Transport is an http.RoundTripper that retries transient failures of
+// an underlying transport. It is safe for concurrent use; the circuit is
+// shared by every request through the same Transport.
+type Transport struct {
+	Base http.RoundTripper
+	cfg  Config
+
+	mu           sync.Mutex
+	failingSince time.Time // zero while the server is healthy
+	open         bool
+}
+
+// NewTransport wraps base (http.DefaultTransport when nil) with retries.
+func NewTransport(base http.RoundTripper, cfg Config) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base, cfg: cfg}
+}
+
+// NewClient returns an http.Client whose requests are retried per cfg.
+// The client has no overall Timeout: Config.AttemptTimeout bounds each
+// try and Config.Deadline bounds an outage.
+func NewClient(cfg Config) *http.Client {
+	return &http.Client{Transport: NewTransport(nil, cfg)}
+}
+
+// Idempotent marks req as safe to retry whatever its method, such as a
+// POST that sets state rather than creating it. Like net/http, it records
+// this as an Idempotency-Key header with no value, which is not sent.
+func Idempotent(req *http.Request) {
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if _, ok := req.Header["Idempotency-Key"]; !ok {
+		req.Header["Idempotency-Key"] = nil
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := t.cfg.MaxAttempts
+	switch {
+	case !t.cfg.RetryNonIdempotent && !idempotent(req):
+		attempts = 1 // a repeat might apply the request twice
+	case req.Body != nil && req.Body != http.NoBody && req.GetBody == nil:
+		attempts = 1 // the body cannot be replayed
+	}
+	backoff := t.cfg.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := t.try(r)
+		if !t.transient(req, resp, err) {
+			if err == nil {
+				t.succeeded()
+			}
+			return resp, err
+		}
+
+		cause := describe(resp, err)
+		if t.failed() {
+			discard(resp)
+			return nil, fmt.Errorf("%s %s: %w (last error: %s)", req.Method, req.URL.Redacted(), ErrCircuitOpen, cause)
+		}
+		if attempt >= attempts {
+			return resp, err // the last transient failure, as the caller would have seen it
+		}
+		discard(resp)
+
+		t.logf("%s %s failed (%s), retry %d/%d in %s", req.Method, req.URL.Redacted(), cause, attempt, attempts-1, backoff)
+		if err := sleep(req.Context(), backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+		if t.cfg.MaxBackoff > 0 && backoff > t.cfg.MaxBackoff {
+			backoff = t.cfg.MaxBackoff
+		}
+	}
+}
+
+// idempotent reports whether req may be sent twice with the same effect
+// as once, following net/http's rule for its own connection retries.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, key := req.Header["Idempotency-Key"]
+	_, xkey := req.Header["X-Idempotency-Key"]
+	return key || xkey
+}
+
+// try makes one attempt under the per-attempt timeout. The timeout's
+// context is released when the response body is closed.
+func (t *Transport) try(req *http.Request) (*http.Response, error) {
+	if t.cfg.AttemptTimeout <= 0 {
+		return t.Base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.cfg.AttemptTimeout)
+	resp, err := t.Base.RoundTrip(req.Clone(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// transient reports whether a failed attempt is worth retrying. Failures
+// caused by the caller's own context are not.
+func (t *Transport) transient(req *http.Request, resp *http.Response, err error) bool {
+	if err == nil {
+		return resp.StatusCode >= 500
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+	var ne net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &ne) && ne.Timeout(),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+	return false
+}
+
+// succeeded closes the circuit.
+func (t *Transport) succeeded() {
+	t.mu.Lock()
+	wasOpen := t.open
+	t.failingSince, t.open = time.Time{}, false
+	t.mu.Unlock()
+	if wasOpen {
+		t.logf("server reachable again, circuit closed")
+	}
+}
+
+// failed records a transient failure and reports whether the circuit is
+// open.
+func (t *Transport) failed() bool {
+	t.mu.Lock()
+	now := time.Now()
+	if t.failingSince.IsZero() {
+		t.failingSince = now
+	}
+	opening := !t.open && t.cfg.Deadline > 0 && now.Sub(t.failingSince) >= t.cfg.Deadline
+	if opening {
+		t.open = true
+	}
+	open, since := t.open, t.failingSince
+	t.mu.Unlock()
+	if opening {
+		t.logf("server failing for %s (deadline %s), circuit open", now.Sub(since).Round(time.Second), t.cfg.Deadline)
+	}
+	return open
+}
+
+func (t *Transport) logf(format string, args ...interface{}) {
+	if t.cfg.Logf != nil {
+		t.cfg.Logf(format, args...)
+	}
+}
+
+func describe(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+// discard drains and closes a response that will not be returned, so the
+// connection can be reused.
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,220 @@
+package httpretry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastConfig() Config {
+	return Config{
+		MaxAttempts:    4,
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		AttemptTimeout: time.Second,
+	}
+}
+
+// flakyServer fails the first n requests with status, then echoes the
+// request body with 200.
+func flakyServer(t *testing.T, n int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= n {
+			http.Error(w, "restarting", status)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestTransport_RetriesTransientFailures(t *testing.T) {
+	srv, calls := flakyServer(t, 2, http.StatusServiceUnavailable)
+	var retries int
+	cfg := fastConfig()
+	cfg.Logf = func(string, ...interface{}) { retries++ }
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"noise":0.01}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	Idempotent(req)
+	resp, err := NewClient(cfg).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != `{"noise":0.01}` {
+		t.Errorf("response %d %q, want 200 with the replayed body", resp.StatusCode, body)
+	}
+	if calls.Load() != 3 || retries != 2 {
+		t.Errorf("calls %d, retries %d; want 3 and 2", calls.Load(), retries)
+	}
+}
+
+func TestTransport_NonIdempotentRequests(t *testing.T) {
+	// An unmarked POST gets one try.
+	srv, calls := flakyServer(t, 2, http.StatusServiceUnavailable)
+	resp, err := NewClient(fastConfig()).Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("status %d after %d calls, want 503 after 1", resp.StatusCode, calls.Load())
+	}
+
+	// RetryNonIdempotent opts every request in.
+	srv, calls = flakyServer(t, 2, http.StatusServiceUnavailable)
+	cfg := fastConfig()
+	cfg.RetryNonIdempotent = true
+	resp, err = NewClient(cfg).Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("status %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+
+	// The Idempotent marker is not sent to the server.
+	var sent atomic.Bool
+	marked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.Header["Idempotency-Key"]
+		sent.Store(ok)
+	}))
+	defer marked.Close()
+	req, _ := http.NewRequest(http.MethodPost, marked.URL, nil)
+	Idempotent(req)
+	resp, err = NewClient(fastConfig()).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if sent.Load() {
+		t.Error("Idempotency-Key header sent")
+	}
+}
+
+func TestTransport_SurfacesPermanentErrors(t *testing.T) {
+	srv, calls := flakyServer(t, 100, http.StatusBadRequest)
+	resp, err := NewClient(fastConfig()).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || calls.Load() != 1 {
+		t.Errorf("status %d after %d calls, want 400 after 1", resp.StatusCode, calls.Load())
+	}
+
+	// A 5xx that outlasts the attempts is returned as the last response.
+	srv, calls = flakyServer(t, 100, http.StatusInternalServerError)
+	resp, err = NewClient(fastConfig()).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || calls.Load() != 4 {
+		t.Errorf("status %d after %d calls, want 500 after 4", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestTransport_ConnectionRefused(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	start := time.Now()
+	_, err := NewClient(fastConfig()).Get(url)
+	if err == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("circuit opened without a deadline: %v", err)
+	}
+	// Three backoffs: 5 + 10 + 20 ms.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("gave up after %v, before the retries", elapsed)
+	}
+}
+
+func TestTransport_AttemptTimeout(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(300 * time.Millisecond)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cfg := fastConfig()
+	cfg.AttemptTimeout = 50 * time.Millisecond
+	resp, err := NewClient(cfg).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" || calls.Load() != 2 {
+		t.Errorf("body %q after %d calls, want ok after 2", body, calls.Load())
+	}
+}
+
+// TestTransport_CircuitDeadline checks a long outage opens the circuit,
+// later requests fail fast, and the first success closes it again.
+func TestTransport_CircuitDeadline(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cfg := fastConfig()
+	cfg.MaxAttempts = 100
+	cfg.Deadline = 150 * time.Millisecond
+	client := NewClient(cfg)
+
+	_, err := client.Get(srv.URL)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if !strings.Contains(err.Error(), "502") {
+		t.Errorf("error %q does not name the last failure", err)
+	}
+
+	calls.Store(0)
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 1 {
+		t.Errorf("open circuit: err %v after %d calls, want ErrCircuitOpen after 1", err, calls.Load())
+	}
+
+	down.Store(false)
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("after recovery: %v", err)
+	}
+	resp.Body.Close()
+	// Closed again: the next outage is retried until the deadline passes.
+	down.Store(true)
+	calls.Store(0)
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrCircuitOpen) || calls.Load() < 5 {
+		t.Errorf("closed circuit: err %v after %d calls, want retries before ErrCircuitOpen", err, calls.Load())
+	}
+}
@@ -0,0 +1,410 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// SubjectSensorPlaceholder is replaced with the sensor ID in
+// JetStreamConfig.SubjectTemplate.
+const SubjectSensorPlaceholder = "{sensor_id}"
+
+// Defaults applied by NewJetStreamSink for zero-valued config fields.
+const (
+	DefaultJetStreamSubjectTemplate = "velocity.tracks." + SubjectSensorPlaceholder
+	DefaultJetStreamBufferSize      = 1000
+	DefaultJetStreamPublishTimeout  = 5 * time.Second
+)
+
+// jetStreamRetryBackoffMax caps the delay between publish retries while
+// the server is unreachable.
+const jetStreamRetryBackoffMax = 10 * time.Second
+
+// JetStreamConfig configures a JetStreamSink.
+type JetStreamConfig struct {
+	URL string // NATS server URL(s), comma separated
+
+	// Credentials: at most one of CredsFile, User/Password or Token.
+	CredsFile string
+	User      string
+	Password  string
+	Token     string
+
+	// Stream, when set, is created with a wildcard subject covering all
+	// sensors if it does not already exist, and publishes are required to
+	// land in it. Existing streams are left untouched.
+	Stream string
+
+	// SubjectTemplate is the publish subject; {sensor_id} is replaced with
+	// the track's sensor ID. Default: velocity.tracks.{sensor_id}.
+	SubjectTemplate string
+
+	// SensorID is used when a track carries no sensor ID of its own.
+	SensorID string
+
+	// BufferSize bounds the events held locally while the server is
+	// unreachable. When full, the oldest event is dropped.
+	BufferSize int
+
+	// PublishTimeout bounds each publish attempt, including the wait for
+	// the stream acknowledgement.
+	PublishTimeout time.Duration
+}
+
+// TrackEventMessage is the JSON payload published for each track
+// lifecycle event.
+type TrackEventMessage struct {
+	Event   string  `json:"event"`
+	TrackID string  `json:"track_id"`
+	X       float32 `json:"x"`
+	Y       float32 `json:"y"`
+	l5tracks.TrackMeasurement
+}
+
+// CrossingEventName is the event published for count-line crossings.
+const CrossingEventName = "track.crossed"
+
+// CrossingEventMessage is the JSON payload published for each count-line
+// crossing.
+type CrossingEventMessage struct {
+	Event string `json:"event"`
+	l6objects.LineCrossing
+}
+
+// NearMissEventName is the event published for near-miss encounters.
+const NearMissEventName = "track.near_miss"
+
+// NearMissEventMessage is the JSON payload published for each near miss.
+type NearMissEventMessage struct {
+	Event string `json:"event"`
+	l6objects.NearMiss
+}
+
+type jetStreamEvent struct {
+	subject string
+	msgID   string
+	data    []byte
+}
+
+// JetStreamSink publishes track lifecycle events to NATS JetStream with
+// at-least-once delivery: each event is retried until the stream
+// acknowledges it, and a stable message ID lets the stream discard
+// duplicates from retries. It implements pipeline.TrackSink.
+// PublishTrackEvent never blocks; events queue in a bounded buffer that
+// a single goroutine drains in order.
+type JetStreamSink struct {
+	cfg JetStreamConfig
+	nc  *nats.Conn
+	js  jetstream.JetStream
+
+	mu     sync.Mutex // guards queue sends and closed
+	queue  chan jetStreamEvent
+	closed bool
+
+	ctx         context.Context
+	cancel      context.CancelFunc
+	done        chan struct{}
+	streamReady bool // worker goroutine only
+
+	published atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// NewJetStreamSink connects to NATS and starts the delivery goroutine.
+// An unreachable server is not an error: the client keeps reconnecting
+// and events buffer until it succeeds.
+func NewJetStreamSink(cfg JetStreamConfig) (*JetStreamSink, error) {
+	if strings.TrimSpace(cfg.URL) == "" {
+		return nil, errors.New("jetstream sink requires a server URL")
+	}
+	if cfg.CredsFile != "" && (cfg.User != "" || cfg.Token != "") || cfg.User != "" && cfg.Token != "" {
+		return nil, errors.New("jetstream sink: set only one of creds file, user/password or token")
+	}
+	if cfg.SubjectTemplate == "" {
+		cfg.SubjectTemplate = DefaultJetStreamSubjectTemplate
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultJetStreamBufferSize
+	}
+	if cfg.PublishTimeout <= 0 {
+		cfg.PublishTimeout = DefaultJetStreamPublishTimeout
+	}
+
+	opts := []nats.Option{
+		nats.Name("velocity.report"),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+		nats.ReconnectWait(time.Second),
+		// Fail publishes fast while disconnected; our own buffer holds
+		// the backlog so its bound is the only one that applies.
+		nats.ReconnectBufSize(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Printf("jetstream sink: disconnected: %v", err)
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			log.Printf("jetstream sink: reconnected to %s", nc.ConnectedUrl())
+		}),
+	}
+	switch {
+	case cfg.CredsFile != "":
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	case cfg.User != "":
+		opts = append(opts, nats.UserInfo(cfg.User, cfg.Password))
+	case cfg.Token != "":
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+
+	nc, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("jetstream sink: connect: %w", err)
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("jetstream sink: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &JetStreamSink{
+		cfg:    cfg,
+		nc:     nc,
+		js:     js,
+		queue:  make(chan jetStreamEvent, cfg.BufferSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Subject returns the publish subject for sensorID.
+func (s *JetStreamSink) Subject(sensorID string) string {
+	return strings.ReplaceAll(s.cfg.SubjectTemplate, SubjectSensorPlaceholder, subjectToken(sensorID))
+}
+
+// subjectToken makes s safe to use as a single NATS subject token.
+func subjectToken(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// PublishTrackEvent serialises the track and queues it for delivery.
+func (s *JetStreamSink) PublishTrackEvent(event string, track *l5tracks.TrackedObject) {
+	msg := TrackEventMessage{
+		Event:            event,
+		TrackID:          track.TrackID,
+		X:                track.X,
+		Y:                track.Y,
+		TrackMeasurement: track.TrackMeasurement,
+	}
+	if msg.SensorID == "" {
+		msg.SensorID = s.cfg.SensorID
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("jetstream sink: encode %s for track %s: %v", event, track.TrackID, err)
+		return
+	}
+	s.enqueue(jetStreamEvent{
+		subject: s.Subject(msg.SensorID),
+		// Track IDs restart with the process, so the start time keeps the
+		// ID unique across restarts within the duplicate window.
+		msgID: msg.SensorID + ":" + track.TrackID + ":" + strconv.FormatInt(track.StartUnixNanos, 10) + ":" + event,
+		data:  data,
+	})
+}
+
+// PublishCrossing serialises a count-line crossing and queues it for
+// delivery on the same subject as track events. It implements
+// pipeline.CrossingSink.
+func (s *JetStreamSink) PublishCrossing(crossing l6objects.LineCrossing) {
+	if crossing.SensorID == "" {
+		crossing.SensorID = s.cfg.SensorID
+	}
+	data, err := json.Marshal(CrossingEventMessage{Event: CrossingEventName, LineCrossing: crossing})
+	if err != nil {
+		log.Printf("jetstream sink: encode crossing of %s by track %s: %v", crossing.LineID, crossing.TrackID, err)
+		return
+	}
+	s.enqueue(jetStreamEvent{
+		subject: s.Subject(crossing.SensorID),
+		// A track is counted once per line, so line and track identify
+		// the crossing; the timestamp separates restarts.
+		msgID: crossing.SensorID + ":" + crossing.TrackID + ":" + crossing.LineID + ":" + strconv.FormatInt(crossing.TimestampNanos, 10) + ":" + CrossingEventName,
+		data:  data,
+	})
+}
+
+// PublishNearMiss serialises a near-miss encounter and queues it for
+// delivery on the same subject as track events. It implements
+// pipeline.NearMissSink.
+func (s *JetStreamSink) PublishNearMiss(nearMiss l6objects.NearMiss) {
+	if nearMiss.SensorID == "" {
+		nearMiss.SensorID = s.cfg.SensorID
+	}
+	data, err := json.Marshal(NearMissEventMessage{Event: NearMissEventName, NearMiss: nearMiss})
+	if err != nil {
+		log.Printf("jetstream sink: encode near miss of tracks %s/%s: %v", nearMiss.TrackA, nearMiss.TrackB, err)
+		return
+	}
+	s.enqueue(jetStreamEvent{
+		subject: s.Subject(nearMiss.SensorID),
+		// An encounter is reported once, so the pair and its start time
+		// identify it.
+		msgID: nearMiss.SensorID + ":" + nearMiss.TrackA + ":" + nearMiss.TrackB + ":" + strconv.FormatInt(nearMiss.StartNanos, 10) + ":" + NearMissEventName,
+		data:  data,
+	})
+}
+
+func (s *JetStreamSink) enqueue(ev jetStreamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		s.dropped.Add(1)
+		return
+	}
+	select {
+	case s.queue <- ev:
+		return
+	default:
+	}
+	// Buffer full: drop the oldest event to make room. Only enqueue
+	// sends, and it holds mu, so the retry send cannot block.
+	select {
+	case <-s.queue:
+		s.dropped.Add(1)
+	default:
+	}
+	select {
+	case s.queue <- ev:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+func (s *JetStreamSink) run() {
+	defer close(s.done)
+	for ev := range s.queue {
+		if !s.deliver(ev) {
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// deliver publishes ev, retrying with backoff until it is acknowledged
+// or the sink is torn down.
+func (s *JetStreamSink) deliver(ev jetStreamEvent) bool {
+	backoff := 100 * time.Millisecond
+	for {
+		if s.ctx.Err() != nil {
+			return false
+		}
+		err := s.publish(ev)
+		if err == nil {
+			s.published.Add(1)
+			return true
+		}
+		log.Printf("jetstream sink: publish to %s failed (%d buffered), retrying in %v: %v",
+			ev.subject, len(s.queue), backoff, err)
+		select {
+		case <-s.ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, jetStreamRetryBackoffMax)
+	}
+}
+
+func (s *JetStreamSink) publish(ev jetStreamEvent) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.cfg.PublishTimeout)
+	defer cancel()
+
+	opts := []jetstream.PublishOpt{jetstream.WithMsgID(ev.msgID)}
+	if s.cfg.Stream != "" {
+		if !s.streamReady {
+			if err := s.ensureStream(ctx); err != nil {
+				return err
+			}
+			s.streamReady = true
+		}
+		opts = append(opts, jetstream.WithExpectStream(s.cfg.Stream))
+	}
+	_, err := s.js.PublishMsg(ctx, &nats.Msg{Subject: ev.subject, Data: ev.data}, opts...)
+	return err
+}
+
+func (s *JetStreamSink) ensureStream(ctx context.Context) error {
+	_, err := s.js.Stream(ctx, s.cfg.Stream)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("look up stream %s: %w", s.cfg.Stream, err)
+	}
+	subject := strings.ReplaceAll(s.cfg.SubjectTemplate, SubjectSensorPlaceholder, "*")
+	if _, err := s.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     s.cfg.Stream,
+		Subjects: []string{subject},
+	}); err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("create stream %s: %w", s.cfg.Stream, err)
+	}
+	log.Printf("jetstream sink: using stream %s for subject %s", s.cfg.Stream, subject)
+	return nil
+}
+
+// Stats reports events acknowledged by the server, events dropped
+// (buffer overflow, shutdown) and events currently buffered.
+func (s *JetStreamSink) Stats() (published, dropped uint64, buffered int) {
+	return s.published.Load(), s.dropped.Load(), len(s.queue)
+}
+
+// Close stops accepting events and waits for buffered events to be
+// delivered until ctx expires, then closes the connection. Events still
+// undelivered at the deadline are dropped and reported in the error.
+func (s *JetStreamSink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	var err error
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		pending := len(s.queue) + 1 // plus the event in flight
+		s.cancel()
+		<-s.done
+		err = fmt.Errorf("jetstream sink: %d events undelivered: %w", pending, ctx.Err())
+	}
+	s.cancel()
+	s.nc.Close()
+	return err
+}
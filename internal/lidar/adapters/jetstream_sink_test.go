@@ -0,0 +1,295 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+	natsserver "github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// startJetStreamServer runs an in-process NATS server with JetStream
+// enabled. port -1 picks a free port.
+func startJetStreamServer(t *testing.T, port int, storeDir string) *natsserver.Server {
+	t.Helper()
+	srv, err := natsserver.NewServer(&natsserver.Options{
+		Host:      "127.0.0.1",
+		Port:      port,
+		JetStream: true,
+		StoreDir:  storeDir,
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("new nats server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	return srv
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func sinkTrack(sensorID, trackID string) *l5tracks.TrackedObject {
+	track := &l5tracks.TrackedObject{TrackID: trackID, X: 1.5, Y: -2}
+	track.SensorID = sensorID
+	track.TrackState = l5tracks.TrackConfirmed
+	track.StartUnixNanos = 1_700_000_000_000_000_000
+	track.ObjectClass = "car"
+	track.AvgSpeedMps = 8.5
+	return track
+}
+
+// fetchStream returns every message currently in stream.
+func fetchStream(t *testing.T, url, stream string, want int) []jetstream.Msg {
+	t.Helper()
+	nc, err := nats.Connect(url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatalf("jetstream: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cons, err := js.OrderedConsumer(ctx, stream, jetstream.OrderedConsumerConfig{})
+	if err != nil {
+		t.Fatalf("consumer: %v", err)
+	}
+	batch, err := cons.Fetch(want, jetstream.FetchMaxWait(2*time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var msgs []jetstream.Msg
+	for m := range batch.Messages() {
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func TestJetStreamSink_PublishesToStream(t *testing.T) {
+	srv := startJetStreamServer(t, -1, t.TempDir())
+	defer srv.Shutdown()
+
+	sink, err := NewJetStreamSink(JetStreamConfig{
+		URL:             srv.ClientURL(),
+		Stream:          "TRACKS",
+		SubjectTemplate: "site.{sensor_id}.tracks",
+	})
+	if err != nil {
+		t.Fatalf("NewJetStreamSink: %v", err)
+	}
+	sink.PublishTrackEvent("track.confirmed", sinkTrack("hesai-01", "trk_1"))
+	sink.PublishTrackEvent("track.ended", sinkTrack("hesai.02", "trk_2"))
+	// A retried duplicate is discarded by the stream.
+	sink.PublishTrackEvent("track.ended", sinkTrack("hesai.02", "trk_2"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if published, dropped, _ := sink.Stats(); published != 3 || dropped != 0 {
+		t.Errorf("stats published=%d dropped=%d, want 3, 0", published, dropped)
+	}
+
+	msgs := fetchStream(t, srv.ClientURL(), "TRACKS", 10)
+	if len(msgs) != 2 {
+		t.Fatalf("stream holds %d messages, want 2", len(msgs))
+	}
+	if got := msgs[0].Subject(); got != "site.hesai-01.tracks" {
+		t.Errorf("subject = %q", got)
+	}
+	if got := msgs[1].Subject(); got != "site.hesai_02.tracks" {
+		t.Errorf("sensor ID not sanitised: subject = %q", got)
+	}
+	var payload TrackEventMessage
+	if err := json.Unmarshal(msgs[0].Data(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if payload.Event != "track.confirmed" || payload.TrackID != "trk_1" || payload.SensorID != "hesai-01" ||
+		payload.ObjectClass != "car" || payload.AvgSpeedMps != 8.5 || payload.X != 1.5 {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestJetStreamSink_PublishesCrossings(t *testing.T) {
+	srv := startJetStreamServer(t, -1, t.TempDir())
+	defer srv.Shutdown()
+
+	sink, err := NewJetStreamSink(JetStreamConfig{URL: srv.ClientURL(), Stream: "TRACKS", SensorID: "hesai-01"})
+	if err != nil {
+		t.Fatalf("NewJetStreamSink: %v", err)
+	}
+	crossing := l6objects.LineCrossing{
+		LineID:         "north-lane",
+		TrackID:        "trk_1",
+		Class:          "car",
+		Direction:      l6objects.CrossingLeftToRight,
+		TimestampNanos: 1_700_000_000_500_000_000,
+		SpeedMps:       11,
+	}
+	sink.PublishCrossing(crossing)
+	sink.PublishCrossing(crossing) // retried duplicate
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	msgs := fetchStream(t, srv.ClientURL(), "TRACKS", 10)
+	if len(msgs) != 1 {
+		t.Fatalf("stream holds %d messages, want 1", len(msgs))
+	}
+	if got := msgs[0].Subject(); got != "velocity.tracks.hesai-01" {
+		t.Errorf("subject = %q", got)
+	}
+	var payload CrossingEventMessage
+	if err := json.Unmarshal(msgs[0].Data(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if payload.Event != CrossingEventName || payload.LineID != "north-lane" || payload.SensorID != "hesai-01" ||
+		payload.Direction != l6objects.CrossingLeftToRight || payload.SpeedMps != 11 {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestJetStreamSink_PublishesNearMisses(t *testing.T) {
+	srv := startJetStreamServer(t, -1, t.TempDir())
+	defer srv.Shutdown()
+
+	sink, err := NewJetStreamSink(JetStreamConfig{URL: srv.ClientURL(), Stream: "TRACKS", SensorID: "hesai-01"})
+	if err != nil {
+		t.Fatalf("NewJetStreamSink: %v", err)
+	}
+	sink.PublishNearMiss(l6objects.NearMiss{
+		TrackA: "trk_1", TrackB: "trk_2", ClassA: "car", ClassB: "pedestrian",
+		MinDistanceM: 1.2, StartNanos: 1_700_000_000_000_000_000,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	msgs := fetchStream(t, srv.ClientURL(), "TRACKS", 10)
+	if len(msgs) != 1 {
+		t.Fatalf("stream holds %d messages, want 1", len(msgs))
+	}
+	var payload NearMissEventMessage
+	if err := json.Unmarshal(msgs[0].Data(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if payload.Event != NearMissEventName || payload.TrackA != "trk_1" || payload.TrackB != "trk_2" ||
+		payload.SensorID != "hesai-01" || payload.MinDistanceM != 1.2 {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestJetStreamSink_BuffersWhileDisconnected(t *testing.T) {
+	port := freePort(t)
+	url := fmt.Sprintf("nats://127.0.0.1:%d", port)
+
+	// The server is not up yet: the sink must still start and buffer.
+	sink, err := NewJetStreamSink(JetStreamConfig{
+		URL:            url,
+		Stream:         "TRACKS",
+		SensorID:       "fallback",
+		BufferSize:     3,
+		PublishTimeout: 200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewJetStreamSink: %v", err)
+	}
+	for i := 0; i < 6; i++ {
+		sink.PublishTrackEvent("track.ended", sinkTrack("", fmt.Sprintf("trk_%d", i)))
+	}
+	// One event is held by the delivery goroutine, three are buffered and
+	// the oldest buffered events beyond that were dropped.
+	_, dropped, buffered := sink.Stats()
+	if buffered > 3 || dropped < 2 {
+		t.Errorf("buffered=%d dropped=%d, want <=3 buffered and >=2 dropped", buffered, dropped)
+	}
+
+	srv := startJetStreamServer(t, port, t.TempDir())
+	defer srv.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	published, dropped, _ := sink.Stats()
+	if published+dropped != 6 || published < 3 {
+		t.Errorf("published=%d dropped=%d, want all 6 accounted for and >=3 published", published, dropped)
+	}
+
+	msgs := fetchStream(t, url, "TRACKS", 10)
+	if uint64(len(msgs)) != published {
+		t.Fatalf("stream holds %d messages, want %d", len(msgs), published)
+	}
+	// The newest event always survives overflow.
+	var last TrackEventMessage
+	if err := json.Unmarshal(msgs[len(msgs)-1].Data(), &last); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if last.TrackID != "trk_5" || last.SensorID != "fallback" {
+		t.Errorf("last delivered = %+v, want trk_5 from fallback sensor", last)
+	}
+	if got := msgs[0].Subject(); got != "velocity.tracks.fallback" {
+		t.Errorf("default subject = %q", got)
+	}
+}
+
+func TestJetStreamSink_CloseDeadlineReportsUndelivered(t *testing.T) {
+	sink, err := NewJetStreamSink(JetStreamConfig{
+		URL:            fmt.Sprintf("nats://127.0.0.1:%d", freePort(t)),
+		PublishTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewJetStreamSink: %v", err)
+	}
+	sink.PublishTrackEvent("track.ended", sinkTrack("s", "trk_1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := sink.Close(ctx); err == nil {
+		t.Fatal("expected error for undelivered events")
+	}
+	if _, dropped, _ := sink.Stats(); dropped != 1 {
+		t.Errorf("dropped = %d, want 1", dropped)
+	}
+	// Events after Close are dropped rather than panicking.
+	sink.PublishTrackEvent("track.ended", sinkTrack("s", "trk_2"))
+	if err := sink.Close(context.Background()); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestNewJetStreamSink_Validation(t *testing.T) {
+	if _, err := NewJetStreamSink(JetStreamConfig{}); err == nil {
+		t.Error("expected error without URL")
+	}
+	if _, err := NewJetStreamSink(JetStreamConfig{URL: "nats://127.0.0.1:1", CredsFile: "x.creds", Token: "t"}); err == nil {
+		t.Error("expected error for conflicting credentials")
+	}
+}
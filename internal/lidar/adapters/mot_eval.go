@@ -0,0 +1,403 @@
+package adapters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// Ground-truth evaluation (CLEAR MOT).
+//
+// Scores tracker output frame by frame against a ground-truth series, as
+// the MOTChallenge tools would score a -mot export, but inline during an
+// analysis run. Truth and tracks are compared as ground-plane points in
+// the tracker's world frame: a confirmed track matches a truth object
+// when its Kalman centre is within MatchDistanceMetres.
+//
+// Each frame, a truth object keeps the track it matched in the previous
+// frame while that track is still within the match distance; the
+// remaining truth objects and tracks are then paired by minimum total
+// distance (Hungarian assignment). A truth object matched to a different
+// track than last time is an ID switch. Unmatched truth objects are
+// misses and unmatched tracks are false positives.
+//
+//	MOTA = 1 − (misses + false positives + ID switches) / truth objects
+//	MOTP = mean centre distance over matches, in metres (lower is better)
+//
+// Truth objects count as mostly tracked when matched in at least 80 % of
+// the frames they were present in, and mostly lost at 20 % or less.
+
+// DefaultMOTMatchDistanceMetres is the default truth-to-track match
+// distance. The sensor sees only an object's near faces, so track centres
+// sit up to half a vehicle width short of the true box centre.
+const DefaultMOTMatchDistanceMetres = 2.0
+
+const (
+	mostlyTrackedFraction = 0.8
+	mostlyLostFraction    = 0.2
+)
+
+// TruthObject is one ground-truth object at one instant.
+type TruthObject struct {
+	ID    string  `json:"id"`
+	Class string  `json:"class,omitempty"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+}
+
+// TruthSeries is ground truth sampled over time, in the tracker's world
+// frame.
+type TruthSeries struct {
+	times   []int64         // sample times, ascending
+	samples [][]TruthObject // objects present at each sample
+}
+
+// ReadTruthCSV reads a ground-truth CSV with a header row naming at least
+// timestamp_unix_nanos, id, x and y, and optionally class: the format
+// gen-pcap -truth writes. Rows may come in any order; rows sharing a
+// timestamp form one sample.
+func ReadTruthCSV(r io.Reader) (*TruthSeries, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read truth header: %w", err)
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[name] = i
+	}
+	for _, name := range []string{"timestamp_unix_nanos", "id", "x", "y"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("truth CSV has no %s column", name)
+		}
+	}
+	classCol, hasClass := col["class"]
+
+	byTime := map[int64][]TruthObject{}
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read truth: %w", err)
+		}
+		ts, err := strconv.ParseInt(rec[col["timestamp_unix_nanos"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("truth line %d: timestamp_unix_nanos: %w", line, err)
+		}
+		x, errX := strconv.ParseFloat(rec[col["x"]], 64)
+		y, errY := strconv.ParseFloat(rec[col["y"]], 64)
+		if err := errors.Join(errX, errY); err != nil {
+			return nil, fmt.Errorf("truth line %d: %w", line, err)
+		}
+		obj := TruthObject{ID: rec[col["id"]], X: x, Y: y}
+		if obj.ID == "" {
+			return nil, fmt.Errorf("truth line %d: empty id", line)
+		}
+		if hasClass {
+			obj.Class = rec[classCol]
+		}
+		byTime[ts] = append(byTime[ts], obj)
+	}
+	if len(byTime) == 0 {
+		return nil, errors.New("truth CSV has no rows")
+	}
+
+	s := &TruthSeries{}
+	for ts := range byTime {
+		s.times = append(s.times, ts)
+	}
+	sort.Slice(s.times, func(i, j int) bool { return s.times[i] < s.times[j] })
+	for _, ts := range s.times {
+		s.samples = append(s.samples, byTime[ts])
+	}
+	return s, nil
+}
+
+// LoadTruthCSV reads a ground-truth CSV file; see ReadTruthCSV.
+func LoadTruthCSV(path string) (*TruthSeries, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := ReadTruthCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Span returns the first and last sample times.
+func (s *TruthSeries) Span() (first, last int64) {
+	return s.times[0], s.times[len(s.times)-1]
+}
+
+// At returns the truth at tsNanos, interpolating positions linearly
+// between the samples either side. An object in only one of the two
+// samples is present when that sample is the nearer. Outside the series
+// there is no truth.
+func (s *TruthSeries) At(tsNanos int64) []TruthObject {
+	first, last := s.Span()
+	if tsNanos < first || tsNanos > last {
+		return nil
+	}
+	i := sort.Search(len(s.times), func(i int) bool { return s.times[i] >= tsNanos })
+	if s.times[i] == tsNanos {
+		return s.samples[i]
+	}
+	before, after := s.samples[i-1], s.samples[i]
+	f := float64(tsNanos-s.times[i-1]) / float64(s.times[i]-s.times[i-1])
+
+	next := make(map[string]TruthObject, len(after))
+	for _, o := range after {
+		next[o.ID] = o
+	}
+	var out []TruthObject
+	for _, o := range before {
+		if n, ok := next[o.ID]; ok {
+			o.X += f * (n.X - o.X)
+			o.Y += f * (n.Y - o.Y)
+			out = append(out, o)
+			delete(next, o.ID)
+		} else if f < 0.5 {
+			out = append(out, o)
+		}
+	}
+	if f >= 0.5 {
+		for _, o := range after {
+			if _, ok := next[o.ID]; ok {
+				out = append(out, o)
+			}
+		}
+	}
+	return out
+}
+
+// MOTFrameResult is the evaluation of one frame.
+type MOTFrameResult struct {
+	Frame          int     `json:"frame"`
+	Timestamp      string  `json:"timestamp"`
+	Truth          int     `json:"truth"`
+	Tracks         int     `json:"tracks"`
+	Matches        int     `json:"matches"`
+	Misses         int     `json:"misses"`
+	FalsePositives int     `json:"false_positives"`
+	IDSwitches     int     `json:"id_switches"`
+	DistanceSum    float64 `json:"distance_sum_m"`
+
+	// Pairs maps each matched truth ID to its track ID.
+	Pairs map[string]string `json:"pairs,omitempty"`
+}
+
+// MOTSummary is the aggregate over an evaluation run.
+type MOTSummary struct {
+	Frames         int     `json:"frames"`
+	TruthObjects   int     `json:"truth_objects"`
+	Matches        int     `json:"matches"`
+	Misses         int     `json:"misses"`
+	FalsePositives int     `json:"false_positives"`
+	IDSwitches     int     `json:"id_switches"`
+	MOTA           float64 `json:"mota"`
+	MOTPMetres     float64 `json:"motp_m"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	TruthIDs       int     `json:"truth_ids"`
+	MostlyTracked  int     `json:"mostly_tracked"`
+	MostlyLost     int     `json:"mostly_lost"`
+}
+
+// MOTEvaluator accumulates CLEAR MOT metrics over the frames of a run. It
+// is not safe for concurrent use.
+type MOTEvaluator struct {
+	truth     *TruthSeries
+	matchDist float64
+
+	last    map[string]string // truth ID → track ID it last matched
+	present map[string]int    // truth ID → frames present
+	matched map[string]int    // truth ID → frames matched
+	frames  []MOTFrameResult
+	sum     MOTSummary
+	distSum float64
+}
+
+// NewMOTEvaluator scores frames against truth. matchDist ≤ 0 uses
+// DefaultMOTMatchDistanceMetres.
+func NewMOTEvaluator(truth *TruthSeries, matchDist float64) *MOTEvaluator {
+	if matchDist <= 0 {
+		matchDist = DefaultMOTMatchDistanceMetres
+	}
+	return &MOTEvaluator{
+		truth:     truth,
+		matchDist: matchDist,
+		last:      map[string]string{},
+		present:   map[string]int{},
+		matched:   map[string]int{},
+	}
+}
+
+// MatchDistance returns the truth-to-track match distance in metres.
+func (e *MOTEvaluator) MatchDistance() float64 { return e.matchDist }
+
+// Frame scores the confirmed tracks among tracks against the truth at
+// ts. Frames outside the truth series are skipped and return false.
+func (e *MOTEvaluator) Frame(frame int, ts time.Time, tracks []*l5tracks.TrackedObject) (MOTFrameResult, bool) {
+	first, last := e.truth.Span()
+	if ts.UnixNano() < first || ts.UnixNano() > last {
+		return MOTFrameResult{}, false
+	}
+	truth := e.truth.At(ts.UnixNano())
+	var confirmed []*l5tracks.TrackedObject
+	for _, t := range tracks {
+		if t.TrackState == l5tracks.TrackConfirmed {
+			confirmed = append(confirmed, t)
+		}
+	}
+
+	dist := func(o TruthObject, t *l5tracks.TrackedObject) float64 {
+		return math.Hypot(float64(t.X)-o.X, float64(t.Y)-o.Y)
+	}
+	assigned := make([]int, len(truth)) // truth index → track index, or -1
+	used := make([]bool, len(confirmed))
+	for i, o := range truth {
+		assigned[i] = -1
+		prev, ok := e.last[o.ID]
+		if !ok {
+			continue
+		}
+		for j, t := range confirmed {
+			if t.TrackID == prev && !used[j] && dist(o, t) <= e.matchDist {
+				assigned[i], used[j] = j, true
+				break
+			}
+		}
+	}
+
+	// Pair the rest by minimum total distance.
+	var rows, cols []int
+	for i := range truth {
+		if assigned[i] < 0 {
+			rows = append(rows, i)
+		}
+	}
+	for j := range confirmed {
+		if !used[j] {
+			cols = append(cols, j)
+		}
+	}
+	if len(rows) > 0 && len(cols) > 0 {
+		cost := make([][]float32, len(rows))
+		for r, i := range rows {
+			cost[r] = make([]float32, len(cols))
+			for c, j := range cols {
+				if d := dist(truth[i], confirmed[j]); d <= e.matchDist {
+					cost[r][c] = float32(d)
+				} else {
+					cost[r][c] = 1e18 // forbidden, as in matchTracks
+				}
+			}
+		}
+		for r, c := range l5tracks.HungarianAssign(cost) {
+			if c >= 0 && cost[r][c] < 1e18 {
+				assigned[rows[r]], used[cols[c]] = cols[c], true
+			}
+		}
+	}
+
+	res := MOTFrameResult{
+		Frame:     frame,
+		Timestamp: ts.UTC().Format(time.RFC3339Nano),
+		Truth:     len(truth),
+		Tracks:    len(confirmed),
+	}
+	for i, o := range truth {
+		e.present[o.ID]++
+		j := assigned[i]
+		if j < 0 {
+			res.Misses++
+			continue
+		}
+		t := confirmed[j]
+		res.Matches++
+		res.DistanceSum += dist(o, t)
+		e.matched[o.ID]++
+		if prev, ok := e.last[o.ID]; ok && prev != t.TrackID {
+			res.IDSwitches++
+		}
+		e.last[o.ID] = t.TrackID
+		if res.Pairs == nil {
+			res.Pairs = map[string]string{}
+		}
+		res.Pairs[o.ID] = t.TrackID
+	}
+	res.FalsePositives = len(confirmed) - res.Matches
+
+	e.frames = append(e.frames, res)
+	e.sum.Frames++
+	e.sum.TruthObjects += res.Truth
+	e.sum.Matches += res.Matches
+	e.sum.Misses += res.Misses
+	e.sum.FalsePositives += res.FalsePositives
+	e.sum.IDSwitches += res.IDSwitches
+	e.distSum += res.DistanceSum
+	return res, true
+}
+
+// Frames returns the per-frame results so far.
+func (e *MOTEvaluator) Frames() []MOTFrameResult { return e.frames }
+
+// Summary returns the aggregate metrics so far.
+func (e *MOTEvaluator) Summary() MOTSummary {
+	s := e.sum
+	if s.TruthObjects > 0 {
+		s.MOTA = 1 - float64(s.Misses+s.FalsePositives+s.IDSwitches)/float64(s.TruthObjects)
+		s.Recall = float64(s.Matches) / float64(s.TruthObjects)
+	}
+	if s.Matches > 0 {
+		s.MOTPMetres = e.distSum / float64(s.Matches)
+	}
+	if n := s.Matches + s.FalsePositives; n > 0 {
+		s.Precision = float64(s.Matches) / float64(n)
+	}
+	s.TruthIDs = len(e.present)
+	for id, n := range e.present {
+		f := float64(e.matched[id]) / float64(n)
+		switch {
+		case f >= mostlyTrackedFraction:
+			s.MostlyTracked++
+		case f <= mostlyLostFraction:
+			s.MostlyLost++
+		}
+	}
+	return s
+}
+
+// MOTEvaluation is the evaluation JSON document.
+type MOTEvaluation struct {
+	TruthFile           string           `json:"truth_file,omitempty"`
+	MatchDistanceMetres float64          `json:"match_distance_m"`
+	Summary             MOTSummary       `json:"summary"`
+	Frames              []MOTFrameResult `json:"frames"`
+}
+
+// WriteJSON writes the summary and per-frame results as indented JSON.
+func (e *MOTEvaluator) WriteJSON(w io.Writer, truthFile string) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(MOTEvaluation{
+		TruthFile:           truthFile,
+		MatchDistanceMetres: e.matchDist,
+		Summary:             e.Summary(),
+		Frames:              e.frames,
+	})
+}
@@ -0,0 +1,141 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// evalTruthCSV has objects A and B at four 100 ms samples; A runs along
+// y = 0 and B stands at (10, 5).
+const evalTruthCSV = `t_secs,timestamp_unix_nanos,id,class,x,y
+0.0,1000000000,A,car,0,0
+0.0,1000000000,B,pedestrian,10,5
+0.1,1100000000,A,car,1,0
+0.1,1100000000,B,pedestrian,10,5
+0.2,1200000000,A,car,2,0
+0.2,1200000000,B,pedestrian,10,5
+0.3,1300000000,B,pedestrian,10,5
+0.3,1300000000,A,car,3,0
+`
+
+func evalTrack(id string, x, y float32) *l5tracks.TrackedObject {
+	t := &l5tracks.TrackedObject{TrackID: id}
+	t.TrackState = l5tracks.TrackConfirmed
+	t.X, t.Y = x, y
+	return t
+}
+
+func TestMOTEvaluator_ScriptedFrames(t *testing.T) {
+	truth, err := ReadTruthCSV(strings.NewReader(evalTruthCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tentative := evalTrack("t7", 10, 5)
+	tentative.TrackState = l5tracks.TrackTentative
+	e := NewMOTEvaluator(truth, 0)
+	frames := []struct {
+		tracks []*l5tracks.TrackedObject
+		want   MOTFrameResult
+	}{
+		// Both found; the tentative track is ignored.
+		{[]*l5tracks.TrackedObject{evalTrack("t1", 0.5, 0), evalTrack("t2", 10, 6), tentative},
+			MOTFrameResult{Truth: 2, Tracks: 2, Matches: 2, DistanceSum: 1.5}},
+		// B missed, and a track far from both is a false positive.
+		{[]*l5tracks.TrackedObject{evalTrack("t1", 1, 0), evalTrack("t9", 30, 30)},
+			MOTFrameResult{Truth: 2, Tracks: 2, Matches: 1, Misses: 1, FalsePositives: 1}},
+		// A's track is replaced: an ID switch.
+		{[]*l5tracks.TrackedObject{evalTrack("t3", 2, 0.5), evalTrack("t2", 10, 5)},
+			MOTFrameResult{Truth: 2, Tracks: 2, Matches: 2, IDSwitches: 1, DistanceSum: 0.5}},
+		// t1 comes back closer to A, but A keeps t3 while it is in range.
+		{[]*l5tracks.TrackedObject{evalTrack("t1", 3, 0), evalTrack("t3", 3, 1), evalTrack("t2", 10, 5)},
+			MOTFrameResult{Truth: 2, Tracks: 3, Matches: 2, FalsePositives: 1, DistanceSum: 1}},
+	}
+	for i, f := range frames {
+		ts := time.Unix(0, 1_000_000_000+int64(i)*100_000_000)
+		got, ok := e.Frame(i+1, ts, f.tracks)
+		if !ok {
+			t.Fatalf("frame %d skipped", i+1)
+		}
+		w := f.want
+		if got.Truth != w.Truth || got.Tracks != w.Tracks || got.Matches != w.Matches || got.Misses != w.Misses ||
+			got.FalsePositives != w.FalsePositives || got.IDSwitches != w.IDSwitches || math.Abs(got.DistanceSum-w.DistanceSum) > 1e-6 {
+			t.Errorf("frame %d = %+v, want %+v", i+1, got, w)
+		}
+	}
+	if got := e.Frames()[3].Pairs["A"]; got != "t3" {
+		t.Errorf("frame 4 paired A with %q, want t3", got)
+	}
+	if _, ok := e.Frame(5, time.Unix(2, 0), nil); ok {
+		t.Error("frame after the truth series was scored")
+	}
+
+	s := e.Summary()
+	want := MOTSummary{
+		Frames: 4, TruthObjects: 8, Matches: 7, Misses: 1, FalsePositives: 2, IDSwitches: 1,
+		MOTA: 0.5, MOTPMetres: 3.0 / 7, Precision: 7.0 / 9, Recall: 7.0 / 8,
+		TruthIDs: 2, MostlyTracked: 1, MostlyLost: 0,
+	}
+	if s.Frames != want.Frames || s.TruthObjects != want.TruthObjects || s.Matches != want.Matches ||
+		s.Misses != want.Misses || s.FalsePositives != want.FalsePositives || s.IDSwitches != want.IDSwitches ||
+		math.Abs(s.MOTA-want.MOTA) > 1e-9 || math.Abs(s.MOTPMetres-want.MOTPMetres) > 1e-9 ||
+		math.Abs(s.Precision-want.Precision) > 1e-9 || math.Abs(s.Recall-want.Recall) > 1e-9 ||
+		s.TruthIDs != want.TruthIDs || s.MostlyTracked != want.MostlyTracked || s.MostlyLost != want.MostlyLost {
+		t.Errorf("summary = %+v\nwant      %+v", s, want)
+	}
+
+	var buf bytes.Buffer
+	if err := e.WriteJSON(&buf, "truth.csv"); err != nil {
+		t.Fatal(err)
+	}
+	var doc MOTEvaluation
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.TruthFile != "truth.csv" || doc.MatchDistanceMetres != DefaultMOTMatchDistanceMetres || len(doc.Frames) != 4 || doc.Summary.IDSwitches != 1 {
+		t.Errorf("evaluation JSON = %s", buf.String())
+	}
+}
+
+func TestTruthSeries_At(t *testing.T) {
+	truth, err := ReadTruthCSV(strings.NewReader(`timestamp_unix_nanos,id,x,y
+0,A,0,0
+0,gone,5,5
+100,A,2,4
+100,new,7,7
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func(objs []TruthObject) string {
+		var s []string
+		for _, o := range objs {
+			s = append(s, o.ID)
+		}
+		return strings.Join(s, ",")
+	}
+	if objs := truth.At(25); ids(objs) != "A,gone" || objs[0].X != 0.5 || objs[0].Y != 1 {
+		t.Errorf("At(25) = %+v", objs)
+	}
+	if objs := truth.At(75); ids(objs) != "A,new" {
+		t.Errorf("At(75) = %+v", objs)
+	}
+	if objs := truth.At(101); objs != nil {
+		t.Errorf("At(101) = %+v, want nothing past the series", objs)
+	}
+
+	for name, in := range map[string]string{
+		"no id column": "timestamp_unix_nanos,x,y\n0,1,2\n",
+		"bad x":        "timestamp_unix_nanos,id,x,y\n0,A,one,2\n",
+		"empty":        "timestamp_unix_nanos,id,x,y\n",
+	} {
+		if _, err := ReadTruthCSV(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package adapters
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// MOTChallenge track export.
+//
+// Writes tracker output in the MOTChallenge text format so runs can be scored
+// with standard tools such as py-motmetrics (loadtxt(fmt="mot15-2D")):
+//
+//	frame,id,bb_left,bb_top,bb_width,bb_height,conf,x,y,z
+//
+// Plane convention: boxes are drawn in the bird's-eye-view ground plane of
+// the tracker's world frame (the site frame when extrinsics are set, else
+// the sensor frame), in metres. The "image" u axis is world X and the v axis
+// is world Y, so bb_left is the minimum X and bb_top the minimum Y of the
+// box; v grows with Y rather than downward as in camera images, which does
+// not change IoU. Each box is the axis-aligned envelope of the track's
+// oriented box (Kalman centre, latest OBB length and width, smoothed OBB
+// heading), so ground truth must use the same frame and convention.
+//
+// Frames are numbered from 1 as MOTChallenge requires. Track IDs are mapped
+// to positive integers in order of first appearance; WriteIDMap writes the
+// mapping back to tracker IDs. conf is 1 because tracker output carries no
+// per-frame detection score. x,y,z are the track centre in world
+// coordinates.
+
+// MOTRow is one line of a MOTChallenge result file.
+type MOTRow struct {
+	Frame  int
+	ID     int
+	Left   float64
+	Top    float64
+	Width  float64
+	Height float64
+	Conf   float64
+	X      float64
+	Y      float64
+	Z      float64
+}
+
+// MOTBox returns the ground-plane axis-aligned envelope of a track's
+// oriented box as (left, top, width, height). The latest reported
+// dimensions are used (see TrackedObject.ReportedDimensions), falling back
+// to the running averages when a track has no OBB yet.
+func MOTBox(track *l5tracks.TrackedObject) (left, top, width, height float64) {
+	l, wd, _ := track.ReportedDimensions()
+	length := float64(l)
+	if length <= 0 {
+		length = float64(track.BoundingBoxLengthAvg)
+	}
+	w := float64(wd)
+	if w <= 0 {
+		w = float64(track.BoundingBoxWidthAvg)
+	}
+
+	sin, cos := math.Sincos(float64(track.OBBHeadingRad))
+	halfX := math.Abs(length/2*cos) + math.Abs(w/2*sin)
+	halfY := math.Abs(length/2*sin) + math.Abs(w/2*cos)
+	return float64(track.X) - halfX, float64(track.Y) - halfY, 2 * halfX, 2 * halfY
+}
+
+// MOTWriter writes per-frame tracker output as MOTChallenge rows. It is not
+// safe for concurrent use.
+type MOTWriter struct {
+	// IncludeCoasting also writes tracks that were not associated with a
+	// cluster this frame (Misses > 0), at their predicted position. Off by
+	// default so only observed boxes are scored.
+	IncludeCoasting bool
+
+	w     *bufio.Writer
+	ids   map[string]int
+	order []string
+	rows  int
+}
+
+// NewMOTWriter creates a writer that emits rows to w. Call Flush when done.
+func NewMOTWriter(w io.Writer) *MOTWriter {
+	return &MOTWriter{w: bufio.NewWriter(w), ids: make(map[string]int)}
+}
+
+// WriteFrame writes one row per confirmed track for a 1-based frame number.
+// Tentative and deleted tracks are skipped.
+func (m *MOTWriter) WriteFrame(frame int, tracks []*l5tracks.TrackedObject) error {
+	if frame < 1 {
+		return fmt.Errorf("MOT frame numbers start at 1, got %d", frame)
+	}
+	for _, track := range tracks {
+		if track.TrackState != l5tracks.TrackConfirmed {
+			continue
+		}
+		if track.Misses > 0 && !m.IncludeCoasting {
+			continue
+		}
+		left, top, width, height := MOTBox(track)
+		row := MOTRow{
+			Frame:  frame,
+			ID:     m.id(track.TrackID),
+			Left:   left,
+			Top:    top,
+			Width:  width,
+			Height: height,
+			Conf:   1,
+			X:      float64(track.X),
+			Y:      float64(track.Y),
+			Z:      float64(track.LatestZ),
+		}
+		if err := m.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRow writes a single row.
+func (m *MOTWriter) WriteRow(row MOTRow) error {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	_, err := fmt.Fprintf(m.w, "%d,%d,%s,%s,%s,%s,%s,%s,%s,%s\n",
+		row.Frame, row.ID, f(row.Left), f(row.Top), f(row.Width), f(row.Height),
+		f(row.Conf), f(row.X), f(row.Y), f(row.Z))
+	if err != nil {
+		return err
+	}
+	m.rows++
+	return nil
+}
+
+// Rows returns the number of rows written.
+func (m *MOTWriter) Rows() int {
+	return m.rows
+}
+
+// Flush writes any buffered rows.
+func (m *MOTWriter) Flush() error {
+	return m.w.Flush()
+}
+
+// WriteIDMap writes a mot_id,track_id CSV mapping MOT integer IDs back to
+// tracker IDs, in ID order.
+func (m *MOTWriter) WriteIDMap(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"mot_id", "track_id"}); err != nil {
+		return err
+	}
+	for i, trackID := range m.order {
+		if err := cw.Write([]string{strconv.Itoa(i + 1), trackID}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (m *MOTWriter) id(trackID string) int {
+	if id, ok := m.ids[trackID]; ok {
+		return id
+	}
+	m.order = append(m.order, trackID)
+	id := len(m.order)
+	m.ids[trackID] = id
+	return id
+}
@@ -0,0 +1,143 @@
+package adapters
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func motTrack(id string, state l5tracks.TrackState, x, y, heading float32) *l5tracks.TrackedObject {
+	track := &l5tracks.TrackedObject{
+		TrackID:       id,
+		X:             x,
+		Y:             y,
+		OBBLength:     4,
+		OBBWidth:      2,
+		OBBHeadingRad: heading,
+		LatestZ:       0.2,
+	}
+	track.TrackState = state
+	return track
+}
+
+func TestMOTBox(t *testing.T) {
+	tests := []struct {
+		name                     string
+		heading                  float32
+		left, top, width, height float64
+	}{
+		{"along X", 0, 8, 4, 4, 2},
+		{"along Y", math.Pi / 2, 9, 3, 2, 4},
+		{"diagonal", math.Pi / 4, 10 - 3*math.Sqrt2/2, 5 - 3*math.Sqrt2/2, 3 * math.Sqrt2, 3 * math.Sqrt2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, top, width, height := MOTBox(motTrack("a", l5tracks.TrackConfirmed, 10, 5, tt.heading))
+			for _, c := range []struct{ got, want float64 }{{left, tt.left}, {top, tt.top}, {width, tt.width}, {height, tt.height}} {
+				if math.Abs(c.got-c.want) > 1e-4 {
+					t.Errorf("got box (%.4f, %.4f, %.4f, %.4f), want (%.4f, %.4f, %.4f, %.4f)",
+						left, top, width, height, tt.left, tt.top, tt.width, tt.height)
+					return
+				}
+			}
+		})
+	}
+
+	// Without an OBB the running averages are used.
+	track := motTrack("b", l5tracks.TrackConfirmed, 0, 0, 0)
+	track.OBBLength, track.OBBWidth = 0, 0
+	track.BoundingBoxLengthAvg, track.BoundingBoxWidthAvg = 5, 1
+	if _, _, w, h := MOTBox(track); w != 5 || h != 1 {
+		t.Errorf("expected averaged 5x1 box, got %vx%v", w, h)
+	}
+}
+
+func TestMOTWriter_SyntheticTracks(t *testing.T) {
+	var out bytes.Buffer
+	mw := NewMOTWriter(&out)
+
+	car := motTrack("trk_car", l5tracks.TrackConfirmed, 0, 0, 0)
+	ped := motTrack("trk_ped", l5tracks.TrackConfirmed, 3, 10, math.Pi/2)
+	tentative := motTrack("trk_new", l5tracks.TrackTentative, 20, 20, 0)
+
+	for frame := 1; frame <= 3; frame++ {
+		car.X = float32(frame) * 1.5
+		ped.Misses = 0
+		if frame == 2 {
+			ped.Misses = 1 // coasting: skipped by default
+		}
+		if err := mw.WriteFrame(frame, []*l5tracks.TrackedObject{car, ped, tentative}); err != nil {
+			t.Fatalf("WriteFrame(%d): %v", frame, err)
+		}
+	}
+	if err := mw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := mw.WriteFrame(0, nil); err == nil {
+		t.Error("expected error for frame 0")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || mw.Rows() != 5 {
+		t.Fatalf("expected 5 rows (car x3, ped x2), got %d:\n%s", len(lines), out.String())
+	}
+
+	seen := map[[2]int]bool{}
+	for _, line := range lines {
+		fields := strings.Split(line, ",")
+		if len(fields) != 10 {
+			t.Fatalf("expected 10 fields, got %d in %q", len(fields), line)
+		}
+		frame, err1 := strconv.Atoi(fields[0])
+		id, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil || frame < 1 || id < 1 {
+			t.Fatalf("frame and id must be positive integers: %q", line)
+		}
+		if seen[[2]int{frame, id}] {
+			t.Fatalf("duplicate id %d in frame %d", id, frame)
+		}
+		seen[[2]int{frame, id}] = true
+		for _, f := range fields[2:] {
+			if _, err := strconv.ParseFloat(f, 64); err != nil {
+				t.Fatalf("non-numeric field %q in %q", f, line)
+			}
+		}
+		if w, _ := strconv.ParseFloat(fields[4], 64); w <= 0 {
+			t.Fatalf("non-positive width in %q", line)
+		}
+	}
+	if lines[0] != "1,1,-0.500,-1.000,4.000,2.000,1.000,1.500,0.000,0.200" {
+		t.Errorf("unexpected first row %q", lines[0])
+	}
+	if lines[1] != "1,2,2.000,8.000,2.000,4.000,1.000,3.000,10.000,0.200" {
+		t.Errorf("unexpected second row %q", lines[1])
+	}
+
+	var ids bytes.Buffer
+	if err := mw.WriteIDMap(&ids); err != nil {
+		t.Fatalf("WriteIDMap: %v", err)
+	}
+	if got, want := ids.String(), "mot_id,track_id\n1,trk_car\n2,trk_ped\n"; got != want {
+		t.Errorf("id map = %q, want %q", got, want)
+	}
+
+	// Coasting tracks are written at their predicted position when asked.
+	var coastOut bytes.Buffer
+	coast := NewMOTWriter(&coastOut)
+	coast.IncludeCoasting = true
+	if err := coast.WriteFrame(1, []*l5tracks.TrackedObject{ped}); err != nil {
+		t.Fatal(err)
+	}
+	ped.Misses = 1
+	if err := coast.WriteFrame(2, []*l5tracks.TrackedObject{ped}); err != nil {
+		t.Fatal(err)
+	}
+	coast.Flush()
+	if coast.Rows() != 2 {
+		t.Errorf("expected coasting row to be written, got %d rows", coast.Rows())
+	}
+}
@@ -70,6 +70,8 @@ const CompactPointSize = 8
 
 // EncodeForegroundBlob encodes foreground points to a compact binary blob.
 // Format: Each point is 8 bytes: distance(2) + azimuth(2) + elevation(2) + intensity(1) + ring(1)
+// Each frame is encoded independently; see ForegroundDeltaEncoder for the
+// delta-compressed variant used for long, mostly static sequences.
 func EncodeForegroundBlob(points []l2frames.PointPolar) []byte {
 	blob := make([]byte, len(points)*CompactPointSize)
 
@@ -105,9 +107,7 @@ func DecodeForegroundBlob(blob []byte) []l2frames.PointPolar {
 	numPoints := len(blob) / CompactPointSize
 
 	// Limit maximum points to prevent excessive memory allocation from untrusted input.
-	// At 40+ bytes per PointPolar struct, 1M points = ~40MB memory.
-	const maxPoints = 1000000
-	if numPoints > maxPoints || numPoints < 0 {
+	if numPoints > maxForegroundBlobPoints || numPoints < 0 {
 		return nil
 	}
 
@@ -18,6 +18,15 @@ type UDPListener = network.UDPListener
 // UDPListenerConfig configures the UDP listener.
 type UDPListenerConfig = network.UDPListenerConfig
 
+// PacketSource supplies raw packets to the ingest path from any transport.
+type PacketSource = network.PacketSource
+
+// MemoryPacket is one packet held by a MemoryPacketSource.
+type MemoryPacket = network.MemoryPacket
+
+// MemoryPacketSource replays a fixed list of packets.
+type MemoryPacketSource = network.MemoryPacketSource
+
 // PCAPPacket represents a single captured network packet.
 type PCAPPacket = network.PCAPPacket
 
@@ -32,6 +41,9 @@ type PCAPReaderFactory = network.PCAPReaderFactory
 // NewUDPListener creates a configured UDP packet listener.
 var NewUDPListener = network.NewUDPListener
 
+// NewMemoryPacketSource creates a source that replays packets in order.
+var NewMemoryPacketSource = network.NewMemoryPacketSource
+
 // Parsing types (from parse/).
 
 // Pandar40PParser parses Hesai Pandar40P LiDAR packets.
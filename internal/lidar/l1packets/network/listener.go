@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"time"
@@ -42,12 +43,16 @@ type UDPListener struct {
 	conn           UDPSocket
 	stats          PacketStatsInterface
 	forwarder      *PacketForwarder
+	pcapRing       *PCAPRingRecorder
 	parser         Parser
 	frameBuilder   FrameBuilder
 	db             *db.DB
 	disableParsing bool
 	udpPort        int
 	socketFactory  UDPSocketFactory
+	source         PacketSource
+	useSourceTime  bool
+	sourceClose    sync.Once
 }
 
 // UDPListenerConfig contains configuration options for the UDP listener
@@ -57,12 +62,22 @@ type UDPListenerConfig struct {
 	LogInterval    time.Duration
 	Stats          PacketStatsInterface
 	Forwarder      *PacketForwarder
+	PCAPRing       *PCAPRingRecorder // Optional: always-on rolling raw packet capture
 	Parser         Parser
 	FrameBuilder   FrameBuilder
 	DB             *db.DB
 	DisableParsing bool
 	UDPPort        int              // UDP port for normal operation (also used for PCAP filtering)
 	SocketFactory  UDPSocketFactory // Optional: factory for creating UDP sockets (for testing)
+
+	// Source, when set, supplies packets instead of a UDP socket on
+	// Address, so a TCP relay, capture service or in-memory list feeds the
+	// same parse and frame-building path. Start owns it and closes it on
+	// return.
+	Source PacketSource
+	// UseSourceTime stamps points with the source's packet times rather
+	// than the sensor clock, for sources replaying recorded packets.
+	UseSourceTime bool
 }
 
 // NewUDPListener creates a new UDP listener with the provided configuration
@@ -94,12 +109,15 @@ func NewUDPListener(config UDPListenerConfig) *UDPListener {
 		logInterval:    logInterval,
 		stats:          stats,
 		forwarder:      config.Forwarder,
+		pcapRing:       config.PCAPRing,
 		parser:         config.Parser,
 		frameBuilder:   config.FrameBuilder,
 		db:             config.DB,
 		disableParsing: config.DisableParsing,
 		udpPort:        config.UDPPort,
 		socketFactory:  socketFactory,
+		source:         config.Source,
+		useSourceTime:  config.UseSourceTime,
 	}
 }
 
@@ -112,76 +130,79 @@ func (n *noopStats) AddDropped()                {}
 func (n *noopStats) AddPoints(count int)        {}
 func (n *noopStats) LogStats(parsePackets bool) {}
 
-// Start begins listening for UDP packets and processing them
+// Start begins listening for UDP packets, or reading the configured
+// Source, and processing them. It returns nil when the source is exhausted.
 func (l *UDPListener) Start(ctx context.Context) error {
-	// Normal UDP socket listening
-	addr, err := net.ResolveUDPAddr("udp", l.address)
-	if err != nil {
-		return fmt.Errorf("failed to resolve UDP address: %w", err)
-	}
+	src := l.source
+	var udpSrc *UDPPacketSource
+	if src == nil {
+		// Normal UDP socket listening
+		addr, err := net.ResolveUDPAddr("udp", l.address)
+		if err != nil {
+			return fmt.Errorf("failed to resolve UDP address: %w", err)
+		}
 
-	conn, err := l.socketFactory.ListenUDP("udp", addr)
-	if err != nil {
-		return fmt.Errorf("failed to listen on UDP address: %w", err)
-	}
-	l.setConn(conn)
-	defer conn.Close()
+		conn, err := l.socketFactory.ListenUDP("udp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on UDP address: %w", err)
+		}
+		l.setConn(conn)
+		defer conn.Close()
 
-	// Set receive buffer size
-	if err := conn.SetReadBuffer(l.rcvBuf); err != nil {
-		opsf("Warning: Failed to set UDP receive buffer size to %d: %v", l.rcvBuf, err)
-	}
+		// Set receive buffer size
+		if err := conn.SetReadBuffer(l.rcvBuf); err != nil {
+			opsf("Warning: Failed to set UDP receive buffer size to %d: %v", l.rcvBuf, err)
+		}
 
-	diagf("UDP listener started on %s with receive buffer %d bytes", l.address, l.rcvBuf)
+		diagf("UDP listener started on %s with receive buffer %d bytes", l.address, l.rcvBuf)
+		udpSrc = NewUDPPacketSource(conn)
+		src = udpSrc
+	} else {
+		defer l.closeSource()
+		diagf("Packet listener started on %T source", src)
+	}
 
 	// Start forwarder if configured
 	if l.forwarder != nil {
 		l.forwarder.Start(ctx)
 	}
 
+	// Start rolling capture if configured
+	if l.pcapRing != nil {
+		l.pcapRing.Start(ctx)
+	}
+
 	// Start statistics logging
 	go l.startStatsLogging(ctx)
 
-	// Prepare buffer for incoming packets
-	buffer := make([]byte, 2048) // Pandar40P packets are 1262 bytes + some margin
-	var deadlineErrLogged bool
-
 	for {
-		select {
-		case <-ctx.Done():
-			diagf("UDP listener stopping due to context cancellation")
-			return ctx.Err()
-		default:
-			// Set read deadline to allow checking context cancellation
-			if err := conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
-				if !deadlineErrLogged {
-					opsf("failed to set read deadline: %v", err)
-					deadlineErrLogged = true
-				}
-				// Continue anyway - this is non-fatal
+		packet, ts, err := src.NextPacket(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				diagf("UDP listener stopping due to context cancellation")
+				return ctx.Err()
 			}
-
-			n, addr, err := conn.ReadFromUDP(buffer)
-			if err != nil {
-				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					continue // Continue on timeout to check context
-				}
-				// Connection closed or context cancelled — clean shutdown.
-				if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
-					if ctx.Err() != nil {
-						return ctx.Err()
-					}
-					return nil
-				}
-				opsf("UDP read error: %v", err)
-				continue
+			// Connection closed or source exhausted — clean shutdown.
+			if errors.Is(err, io.EOF) {
+				return nil
 			}
+			return fmt.Errorf("read packet: %w", err)
+		}
 
-			// Handle the received packet
-			packet := buffer[:n]
-			if err := l.handlePacket(packet); err != nil {
-				opsf("Error handling packet from %v: %v", addr, err)
+		if l.pcapRing != nil {
+			var addr *net.UDPAddr
+			if udpSrc != nil {
+				addr = udpSrc.LastAddr()
 			}
+			l.pcapRing.RecordAsync(packet, addr)
+		}
+		if l.useSourceTime && l.parser != nil {
+			if tsParser, ok := l.parser.(interface{ SetPacketTime(time.Time) }); ok {
+				tsParser.SetPacketTime(ts)
+			}
+		}
+		if err := l.handlePacket(packet); err != nil {
+			opsf("Error handling packet: %v", err)
 		}
 	}
 }
@@ -262,9 +283,18 @@ func (l *UDPListener) GetConn() UDPSocket {
 	return l.conn
 }
 
+// closeSource closes the configured Source exactly once.
+func (l *UDPListener) closeSource() (err error) {
+	l.sourceClose.Do(func() { err = l.source.Close() })
+	return err
+}
+
 // Close closes the UDP listener and releases resources.
 // It is safe to call Close multiple times.
 func (l *UDPListener) Close() error {
+	if l.source != nil {
+		return l.closeSource()
+	}
 	l.connMu.Lock()
 	conn := l.conn
 	l.conn = nil
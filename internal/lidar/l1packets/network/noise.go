@@ -0,0 +1,134 @@
+package network
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+// NoiseConfig controls synthetic perturbations applied to a clean point
+// stream for robustness testing. All rates are per point; zero values
+// leave the stream untouched.
+type NoiseConfig struct {
+	Seed int64 // RNG seed; identical seeds reproduce identical perturbations
+
+	DropoutRate  float64 // Probability [0,1] that a return is discarded
+	RangeJitterM float64 // Std-dev (metres) of Gaussian noise added to Distance
+
+	// SpuriousRate is the expected number of spurious returns injected per
+	// real input point. Spurious returns copy the channel and timing of a
+	// random point in the same packet and get a uniform random distance in
+	// [SpuriousMinRangeM, SpuriousMaxRangeM].
+	SpuriousRate      float64
+	SpuriousMinRangeM float64
+	SpuriousMaxRangeM float64
+}
+
+// Enabled reports whether any perturbation is configured.
+func (c NoiseConfig) Enabled() bool {
+	return c.DropoutRate > 0 || c.RangeJitterM > 0 || c.SpuriousRate > 0
+}
+
+// Validate checks rates and ranges are within sensible bounds.
+func (c NoiseConfig) Validate() error {
+	if c.DropoutRate < 0 || c.DropoutRate > 1 {
+		return fmt.Errorf("noise dropout rate must be in [0,1], got %v", c.DropoutRate)
+	}
+	if c.RangeJitterM < 0 {
+		return fmt.Errorf("noise range jitter must be >= 0, got %v", c.RangeJitterM)
+	}
+	if c.SpuriousRate < 0 {
+		return fmt.Errorf("noise spurious rate must be >= 0, got %v", c.SpuriousRate)
+	}
+	if c.SpuriousRate > 0 && (c.SpuriousMinRangeM < 0 || c.SpuriousMaxRangeM <= c.SpuriousMinRangeM) {
+		return fmt.Errorf("noise spurious range must satisfy 0 <= min < max, got [%v, %v]",
+			c.SpuriousMinRangeM, c.SpuriousMaxRangeM)
+	}
+	return nil
+}
+
+// NoiseInjector is a FrameBuilder that perturbs each batch of polar points
+// before forwarding it to the wrapped FrameBuilder. Insert it between the
+// packet reader and frame assembly to replay a clean PCAP under degraded
+// conditions. Not safe for concurrent AddPointsPolar calls, matching the
+// single-reader PCAP and UDP paths.
+type NoiseInjector struct {
+	next   FrameBuilder
+	config NoiseConfig
+	rng    *rand.Rand
+
+	dropped  int
+	spurious int
+}
+
+// NewNoiseInjector wraps next with the given perturbations.
+func NewNoiseInjector(next FrameBuilder, config NoiseConfig) (*NoiseInjector, error) {
+	if next == nil {
+		return nil, fmt.Errorf("noise injector requires a downstream frame builder")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &NoiseInjector{
+		next:   next,
+		config: config,
+		rng:    rand.New(rand.NewSource(config.Seed)),
+	}, nil
+}
+
+// AddPointsPolar perturbs points and forwards the result. The input slice
+// is not modified.
+func (n *NoiseInjector) AddPointsPolar(points []l2frames.PointPolar) {
+	if len(points) == 0 {
+		n.next.AddPointsPolar(points)
+		return
+	}
+	cfg := n.config
+	// A fresh slice per batch: downstream builders may retain it.
+	out := make([]l2frames.PointPolar, 0, len(points))
+
+	for _, p := range points {
+		if cfg.DropoutRate > 0 && n.rng.Float64() < cfg.DropoutRate {
+			n.dropped++
+			continue
+		}
+		if cfg.RangeJitterM > 0 {
+			p.Distance += n.rng.NormFloat64() * cfg.RangeJitterM
+			if p.Distance < 0 {
+				p.Distance = 0
+			}
+		}
+		out = append(out, p)
+	}
+
+	if cfg.SpuriousRate > 0 {
+		// The whole part of the expected count is injected directly and
+		// the fractional part by one Bernoulli trial, so the long-run
+		// rate matches SpuriousRate.
+		expected := cfg.SpuriousRate * float64(len(points))
+		count := int(expected)
+		if n.rng.Float64() < expected-float64(count) {
+			count++
+		}
+		for i := 0; i < count; i++ {
+			p := points[n.rng.Intn(len(points))]
+			p.Distance = cfg.SpuriousMinRangeM + n.rng.Float64()*(cfg.SpuriousMaxRangeM-cfg.SpuriousMinRangeM)
+			p.Intensity = uint8(n.rng.Intn(256))
+			out = append(out, p)
+		}
+		n.spurious += count
+	}
+
+	n.next.AddPointsPolar(out)
+}
+
+// SetMotorSpeed forwards to the wrapped FrameBuilder.
+func (n *NoiseInjector) SetMotorSpeed(rpm uint16) {
+	n.next.SetMotorSpeed(rpm)
+}
+
+// Counts returns the number of points dropped and spurious points added.
+func (n *NoiseInjector) Counts() (dropped, spurious int) {
+	return n.dropped, n.spurious
+}
@@ -0,0 +1,140 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+func noiseTestPoints(n int) []l2frames.PointPolar {
+	points := make([]l2frames.PointPolar, n)
+	for i := range points {
+		points[i] = l2frames.PointPolar{
+			Channel:   i % 40,
+			Azimuth:   float64(i) * 0.2,
+			Distance:  10.0,
+			Intensity: 50,
+			Timestamp: int64(i),
+		}
+	}
+	return points
+}
+
+func runNoise(t *testing.T, cfg NoiseConfig, points []l2frames.PointPolar) (*MockFrameBuilder, *NoiseInjector) {
+	t.Helper()
+	mock := &MockFrameBuilder{}
+	inj, err := NewNoiseInjector(mock, cfg)
+	if err != nil {
+		t.Fatalf("NewNoiseInjector: %v", err)
+	}
+	inj.AddPointsPolar(points)
+	return mock, inj
+}
+
+func TestNoiseInjector_DisabledIsPassThrough(t *testing.T) {
+	points := noiseTestPoints(100)
+	cfg := NoiseConfig{Seed: 1}
+	if cfg.Enabled() {
+		t.Fatal("zero config should not be enabled")
+	}
+	mock, _ := runNoise(t, cfg, points)
+	if !reflect.DeepEqual(mock.points, points) {
+		t.Error("expected unperturbed pass-through")
+	}
+}
+
+func TestNoiseInjector_SeedReproducible(t *testing.T) {
+	points := noiseTestPoints(1000)
+	cfg := NoiseConfig{
+		Seed:              42,
+		DropoutRate:       0.1,
+		RangeJitterM:      0.05,
+		SpuriousRate:      0.02,
+		SpuriousMinRangeM: 1,
+		SpuriousMaxRangeM: 50,
+	}
+	a, _ := runNoise(t, cfg, points)
+	b, _ := runNoise(t, cfg, points)
+	if !reflect.DeepEqual(a.points, b.points) {
+		t.Error("same seed produced different output")
+	}
+
+	cfg.Seed = 43
+	c, _ := runNoise(t, cfg, points)
+	if reflect.DeepEqual(a.points, c.points) {
+		t.Error("different seeds produced identical output")
+	}
+
+	// Input must not be mutated.
+	if points[0].Distance != 10.0 {
+		t.Errorf("input mutated: distance = %v", points[0].Distance)
+	}
+}
+
+func TestNoiseInjector_Rates(t *testing.T) {
+	points := noiseTestPoints(10000)
+
+	mock, inj := runNoise(t, NoiseConfig{Seed: 7, DropoutRate: 0.25}, points)
+	dropped, spurious := inj.Counts()
+	if dropped < 2200 || dropped > 2800 {
+		t.Errorf("dropout: dropped %d of 10000, want ~2500", dropped)
+	}
+	if spurious != 0 || len(mock.points) != len(points)-dropped {
+		t.Errorf("dropout: got %d points, spurious %d", len(mock.points), spurious)
+	}
+
+	mock, inj = runNoise(t, NoiseConfig{Seed: 7, SpuriousRate: 0.05, SpuriousMinRangeM: 2, SpuriousMaxRangeM: 4}, points)
+	_, spurious = inj.Counts()
+	if spurious != 500 {
+		t.Errorf("spurious: added %d, want 500", spurious)
+	}
+	for _, p := range mock.points[len(points):] {
+		if p.Distance < 2 || p.Distance >= 4 {
+			t.Fatalf("spurious distance %v outside [2,4)", p.Distance)
+		}
+	}
+
+	mock, _ = runNoise(t, NoiseConfig{Seed: 7, RangeJitterM: 0.1}, points)
+	var sum, sumSq float64
+	for _, p := range mock.points {
+		d := p.Distance - 10.0
+		sum += d
+		sumSq += d * d
+	}
+	n := float64(len(mock.points))
+	variance := sumSq/n - (sum/n)*(sum/n)
+	if variance < 0.008 || variance > 0.012 {
+		t.Errorf("jitter: variance %v, want ~0.01", variance)
+	}
+}
+
+func TestNoiseInjector_SetMotorSpeedForwards(t *testing.T) {
+	mock := &MockFrameBuilder{}
+	inj, err := NewNoiseInjector(mock, NoiseConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inj.SetMotorSpeed(1200)
+	if mock.motorSpeed != 1200 {
+		t.Errorf("motor speed = %d, want 1200", mock.motorSpeed)
+	}
+}
+
+func TestNewNoiseInjector_Validation(t *testing.T) {
+	bad := []NoiseConfig{
+		{DropoutRate: -0.1},
+		{DropoutRate: 1.5},
+		{RangeJitterM: -1},
+		{SpuriousRate: -1},
+		{SpuriousRate: 0.1, SpuriousMinRangeM: 5, SpuriousMaxRangeM: 5},
+	}
+	for _, cfg := range bad {
+		if _, err := NewNoiseInjector(&MockFrameBuilder{}, cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+	if _, err := NewNoiseInjector(nil, NoiseConfig{}); err == nil {
+		t.Error("expected error for nil downstream")
+	}
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+// udpReadPollInterval bounds how long a UDP read blocks before the source
+// re-checks its context.
+const udpReadPollInterval = 100 * time.Millisecond
+
+// PacketSource delivers raw LiDAR packets to the ingest path whatever the
+// transport. UDPPacketSource reads a live socket, PCAPPacketSource a capture
+// file and MemoryPacketSource a fixed list; a TCP relay or any other custom
+// transport only needs its two methods to feed the same parse and
+// frame-building path through UDPListenerConfig.Source, or the offline
+// l2frames.FrameIterator through Run. It is an alias of
+// l2frames.PacketSource, which documents the contract.
+type PacketSource = l2frames.PacketSource
+
+// UDPPacketSource reads packets from a UDP socket, stamped with their
+// receive time. Reads poll with a short deadline so cancellation is noticed
+// promptly; closing the socket ends the source with io.EOF.
+type UDPPacketSource struct {
+	conn              UDPSocket
+	buffer            []byte
+	lastAddr          *net.UDPAddr
+	deadlineErrLogged bool
+}
+
+// NewUDPPacketSource returns a source reading from conn.
+func NewUDPPacketSource(conn UDPSocket) *UDPPacketSource {
+	return &UDPPacketSource{
+		conn:   conn,
+		buffer: make([]byte, 2048), // Pandar40P packets are 1262 bytes + some margin
+	}
+}
+
+// NextPacket returns the next datagram. Read errors other than timeouts and
+// a closed socket are logged and skipped.
+func (s *UDPPacketSource) NextPacket(ctx context.Context) ([]byte, time.Time, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, time.Time{}, err
+		}
+		// Set read deadline to allow checking context cancellation
+		if err := s.conn.SetReadDeadline(time.Now().Add(udpReadPollInterval)); err != nil {
+			if !s.deadlineErrLogged {
+				opsf("failed to set read deadline: %v", err)
+				s.deadlineErrLogged = true
+			}
+			// Continue anyway - this is non-fatal
+		}
+
+		n, addr, err := s.conn.ReadFromUDP(s.buffer)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue // Continue on timeout to check context
+			}
+			if ctx.Err() != nil {
+				return nil, time.Time{}, ctx.Err()
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil, time.Time{}, io.EOF
+			}
+			opsf("UDP read error: %v", err)
+			continue
+		}
+		s.lastAddr = addr
+		return s.buffer[:n], time.Now(), nil
+	}
+}
+
+// LastAddr returns the sender of the packet most recently returned.
+func (s *UDPPacketSource) LastAddr() *net.UDPAddr {
+	return s.lastAddr
+}
+
+// Close closes the socket.
+func (s *UDPPacketSource) Close() error {
+	return s.conn.Close()
+}
+
+// MemoryPacket is one packet held by a MemoryPacketSource.
+type MemoryPacket struct {
+	Data      []byte
+	Timestamp time.Time
+}
+
+// MemoryPacketSource replays a fixed list of packets, for tests and for
+// packets already fetched by other means. It never blocks; once the list is
+// exhausted or the source is closed NextPacket returns io.EOF.
+type MemoryPacketSource struct {
+	mu      sync.Mutex
+	packets []MemoryPacket
+	next    int
+	closed  bool
+}
+
+// NewMemoryPacketSource returns a source yielding packets in order.
+func NewMemoryPacketSource(packets []MemoryPacket) *MemoryPacketSource {
+	return &MemoryPacketSource{packets: packets}
+}
+
+// NextPacket returns the next packet in the list.
+func (s *MemoryPacketSource) NextPacket(ctx context.Context) ([]byte, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.next >= len(s.packets) {
+		return nil, time.Time{}, io.EOF
+	}
+	p := s.packets[s.next]
+	s.next++
+	return p.Data, p.Timestamp, nil
+}
+
+// Remaining returns the number of packets not yet read.
+func (s *MemoryPacketSource) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0
+	}
+	return len(s.packets) - s.next
+}
+
+// Close ends the source early. It is safe to call more than once.
+func (s *MemoryPacketSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
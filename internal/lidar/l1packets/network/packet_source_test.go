@@ -0,0 +1,197 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/parse"
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+// pandarPacket builds a Pandar40P packet whose ten blocks start at
+// startAzimuth (degrees) and step one degree apart, every channel
+// reporting a 10 m return.
+func pandarPacket(startAzimuth int) []byte {
+	packet := make([]byte, parse.PACKET_SIZE_STANDARD)
+	offset := 0
+	for block := 0; block < parse.BLOCKS_PER_PACKET; block++ {
+		binary.LittleEndian.PutUint16(packet[offset:], 0xEEFF)
+		azimuth := (startAzimuth + block) % 360
+		binary.LittleEndian.PutUint16(packet[offset+2:], uint16(azimuth*100))
+		offset += parse.BLOCK_PREAMBLE_SIZE + parse.AZIMUTH_SIZE
+		for channel := 0; channel < parse.CHANNELS_PER_BLOCK; channel++ {
+			binary.LittleEndian.PutUint16(packet[offset:], uint16(10.0/parse.DISTANCE_RESOLUTION))
+			packet[offset+2] = 100
+			offset += parse.BYTES_PER_CHANNEL
+		}
+	}
+	binary.LittleEndian.PutUint16(packet[parse.TAIL_START+8:], 600) // motor speed
+	return packet
+}
+
+func TestMemoryPacketSource(t *testing.T) {
+	t0 := time.Unix(1_700_000_000, 0)
+	src := NewMemoryPacketSource([]MemoryPacket{
+		{Data: []byte{1}, Timestamp: t0},
+		{Data: []byte{2}, Timestamp: t0.Add(time.Millisecond)},
+		{Data: []byte{3}, Timestamp: t0.Add(2 * time.Millisecond)},
+	})
+	ctx := context.Background()
+
+	data, ts, err := src.NextPacket(ctx)
+	if err != nil || data[0] != 1 || !ts.Equal(t0) {
+		t.Fatalf("first packet = %v, %v, %v", data, ts, err)
+	}
+	if src.Remaining() != 2 {
+		t.Errorf("Remaining = %d, want 2", src.Remaining())
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := src.NextPacket(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled NextPacket err = %v", err)
+	}
+
+	if data, _, _ := src.NextPacket(ctx); data[0] != 2 {
+		t.Errorf("second packet = %v", data)
+	}
+	src.Close()
+	if _, _, err := src.NextPacket(ctx); !errors.Is(err, io.EOF) {
+		t.Errorf("NextPacket after Close err = %v, want io.EOF", err)
+	}
+	if src.Remaining() != 0 {
+		t.Errorf("Remaining after Close = %d", src.Remaining())
+	}
+}
+
+func TestUDPPacketSource(t *testing.T) {
+	sender := &net.UDPAddr{IP: net.ParseIP("192.168.1.201"), Port: 10000}
+	socket := NewMockUDPSocket([]MockUDPPacket{{Data: []byte{7, 8}, Addr: sender}})
+	socket.ReadError = errors.New("transient")
+	src := NewUDPPacketSource(socket)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	data, ts, err := src.NextPacket(ctx)
+	if err != nil || len(data) != 2 || data[0] != 7 || ts.IsZero() {
+		t.Fatalf("NextPacket = %v, %v, %v", data, ts, err)
+	}
+	if src.LastAddr() != sender {
+		t.Errorf("LastAddr = %v, want %v", src.LastAddr(), sender)
+	}
+
+	// With no packets left the mock times out until the context ends.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if _, _, err := src.NextPacket(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("NextPacket err = %v, want context.Canceled", err)
+	}
+
+	src.Close()
+	if _, _, err := src.NextPacket(context.Background()); !errors.Is(err, io.EOF) {
+		t.Errorf("NextPacket on a closed socket err = %v, want io.EOF", err)
+	}
+}
+
+// TestUDPListener_MemorySourcePipeline drives the live ingest path —
+// listener, Pandar40P parser and FrameBuilder — from an in-memory source.
+func TestUDPListener_MemorySourcePipeline(t *testing.T) {
+	config, err := parse.LoadEmbeddedPandar40PConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var frames []*l2frames.LiDARFrame
+	fb := l2frames.NewFrameBuilder(l2frames.FrameBuilderConfig{
+		SensorID: "memory",
+		FrameCallback: func(f *l2frames.LiDARFrame) {
+			mu.Lock()
+			frames = append(frames, f)
+			mu.Unlock()
+		},
+	})
+	defer fb.Close()
+
+	// 80 packets of ten one-degree blocks: two full rotations and a
+	// partial third, 100 ms per rotation.
+	t0 := time.Unix(1_700_000_000, 0)
+	var packets []MemoryPacket
+	for i := 0; i < 80; i++ {
+		packets = append(packets, MemoryPacket{
+			Data:      pandarPacket(i * parse.BLOCKS_PER_PACKET),
+			Timestamp: t0.Add(time.Duration(i) * 100 * time.Millisecond / 36),
+		})
+	}
+
+	stats := &MockFullPacketStats{}
+	listener := NewUDPListener(UDPListenerConfig{
+		Source:        NewMemoryPacketSource(packets),
+		UseSourceTime: true,
+		Stats:         stats,
+		Parser:        parse.NewPandar40PParser(*config),
+		FrameBuilder:  fb,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := listener.Start(ctx); err != nil {
+		t.Fatalf("Start returned %v, want nil at the end of the source", err)
+	}
+	if stats.GetPacketCount() != len(packets) {
+		t.Errorf("stats counted %d packets, want %d", stats.GetPacketCount(), len(packets))
+	}
+
+	fb.Flush()
+	fb.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(frames) < 2 {
+		t.Fatalf("got %d frames, want at least the two complete rotations", len(frames))
+	}
+	first := frames[0]
+	if first.PointCount < 300*parse.CHANNELS_PER_BLOCK {
+		t.Errorf("first frame has %d points, want most of a rotation", first.PointCount)
+	}
+	// Points carry the source's timestamps, not the wall clock.
+	if d := first.StartTimestamp.Sub(t0); d < -time.Millisecond || d > time.Second {
+		t.Errorf("first frame starts at %v, want near source time %v", first.StartTimestamp, t0)
+	}
+}
+
+// TestFrameIterator_RunsMemorySource drives the offline frame iterator from
+// the same in-memory source type the live listener reads.
+func TestFrameIterator_RunsMemorySource(t *testing.T) {
+	config, err := parse.LoadEmbeddedPandar40PConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var packets []MemoryPacket
+	for i := 0; i < 80; i++ {
+		packets = append(packets, MemoryPacket{Data: pandarPacket(i * parse.BLOCKS_PER_PACKET)})
+	}
+
+	var points int
+	it := l2frames.NewFrameIterator("memory", func(f *l2frames.LiDARFrame) { points += f.PointCount })
+	src := NewMemoryPacketSource(packets)
+	defer src.Close()
+	if err := it.Run(context.Background(), src, parse.NewPandar40PParser(*config)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if src.Remaining() != 0 {
+		t.Errorf("%d packets left unread", src.Remaining())
+	}
+	want := len(packets) * parse.BLOCKS_PER_PACKET * parse.CHANNELS_PER_BLOCK
+	if points != want {
+		t.Errorf("frames carried %d points, want all %d", points, want)
+	}
+}
@@ -5,37 +5,28 @@ package network
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"io"
 	"time"
 
 	"github.com/banshee-data/velocity.report/internal/lidar"
-	"github.com/google/gopacket"
-	"github.com/google/gopacket/layers"
-	"github.com/google/gopacket/pcap"
 )
 
 // ReadPCAPFile reads and processes LiDAR packets from a PCAP file.
 // If forwarder is not nil, packets are forwarded to the configured destination.
 // This function is only available when building with the 'pcap' build tag.
+// udpPort 0, or a port with no traffic in the capture, auto-detects the
+// LiDAR port (see ResolvePCAPPort).
 // startSeconds and durationSeconds allow subsection replay (startSeconds=0, durationSeconds=-1 means full file).
 // packetOffset allows seeking to a specific 0-based packet index before processing.
 // onProgress is called periodically with (currentPacket, totalPackets) for progress reporting.
 func ReadPCAPFile(ctx context.Context, pcapFile string, udpPort int, parser Parser, frameBuilder FrameBuilder, stats PacketStatsInterface, forwarder *PacketForwarder, startSeconds float64, durationSeconds float64, packetOffset uint64, totalPackets uint64, onProgress func(current, total uint64)) error {
-	// Open PCAP file
-	handle, err := pcap.OpenOffline(pcapFile)
+	src, err := OpenPCAPPacketSource(pcapFile, udpPort)
 	if err != nil {
-		return fmt.Errorf("failed to open PCAP file %s: %w", pcapFile, err)
+		return err
 	}
-	defer handle.Close()
+	defer src.Close()
 
-	// Set BPF filter to only capture UDP packets on the specified port
-	filterStr := fmt.Sprintf("udp port %d", udpPort)
-	if err := handle.SetBPFFilter(filterStr); err != nil {
-		return fmt.Errorf("failed to set BPF filter '%s': %w", filterStr, err)
-	}
-	diagf("PCAP BPF filter set: %s", filterStr)
-
-	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
 	var packetIndex uint64 // 0-based index across all matching packets
 	packetCount := 0
 	totalPoints := 0
@@ -48,141 +39,129 @@ func ReadPCAPFile(ctx context.Context, pcapFile string, udpPort int, parser Pars
 	skippingToStart := startSeconds > 0
 
 	for {
-		select {
-		case <-ctx.Done():
+		payload, captureTime, err := src.NextPacket(ctx)
+		if ctx.Err() != nil {
 			diagf("PCAP reader stopping due to context cancellation (processed %d packets)", packetCount)
 			return ctx.Err()
-		case packet := <-packetSource.Packets():
-			if packet == nil {
-				// End of PCAP file
-				elapsed := time.Since(startTime)
-				diagf("PCAP file reading complete: %d packets processed in %v", packetCount, elapsed)
-				if onProgress != nil {
-					onProgress(packetIndex, totalPackets)
-				}
-				return nil
+		}
+		if errors.Is(err, io.EOF) {
+			// End of PCAP file
+			elapsed := time.Since(startTime)
+			diagf("PCAP file reading complete: %d packets processed in %v", packetCount, elapsed)
+			if onProgress != nil {
+				onProgress(packetIndex, totalPackets)
 			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-			packetIndex++
+		packetIndex++
 
-			// Offset-based seek: skip packets until we reach the requested offset
-			if skippingToOffset && packetIndex < packetOffset {
-				continue
+		// Offset-based seek: skip packets until we reach the requested offset
+		if skippingToOffset && packetIndex < packetOffset {
+			continue
+		}
+		if skippingToOffset {
+			skippingToOffset = false
+			diagf("PCAP replay: seeked to packet offset %d", packetOffset)
+		}
+
+		// Report progress periodically (every 100 packets)
+		if onProgress != nil && packetIndex%100 == 0 {
+			onProgress(packetIndex, totalPackets)
+		}
+
+		// Calculate start/end thresholds on first packet
+		if firstPacketTime.IsZero() {
+			firstPacketTime = captureTime
+			if startSeconds > 0 {
+				startThreshold = firstPacketTime.Add(time.Duration(startSeconds * float64(time.Second)))
+			} else {
+				// When no start offset, use first packet time as the baseline
+				// so duration thresholds are relative to the actual capture.
+				startThreshold = firstPacketTime
 			}
-			if skippingToOffset {
-				skippingToOffset = false
-				diagf("PCAP replay: seeked to packet offset %d", packetOffset)
+			if durationSeconds > 0 {
+				endThreshold = startThreshold.Add(time.Duration(durationSeconds * float64(time.Second)))
 			}
+		}
 
-			// Report progress periodically (every 100 packets)
-			if onProgress != nil && packetIndex%100 == 0 {
-				onProgress(packetIndex, totalPackets)
-			}
+		// Skip packets before start threshold
+		if skippingToStart && !startThreshold.IsZero() && captureTime.Before(startThreshold) {
+			continue
+		}
+		if skippingToStart {
+			skippingToStart = false
+			diagf("PCAP replay: started at %.2fs offset", startSeconds)
+		}
 
-			// Calculate start/end thresholds on first packet
-			captureTime := packet.Metadata().Timestamp
-			if firstPacketTime.IsZero() {
-				firstPacketTime = captureTime
-				if startSeconds > 0 {
-					startThreshold = firstPacketTime.Add(time.Duration(startSeconds * float64(time.Second)))
-				} else {
-					// When no start offset, use first packet time as the baseline
-					// so duration thresholds are relative to the actual capture.
-					startThreshold = firstPacketTime
-				}
-				if durationSeconds > 0 {
-					endThreshold = startThreshold.Add(time.Duration(durationSeconds * float64(time.Second)))
-				}
-			}
+		// Stop if we've reached the end threshold
+		if !endThreshold.IsZero() && captureTime.After(endThreshold) {
+			elapsed := time.Since(startTime)
+			diagf("PCAP replay complete: reached duration limit of %.2fs (processed %d packets in %v)", durationSeconds, packetCount, elapsed)
+			return nil
+		}
 
-			// Skip packets before start threshold
-			if skippingToStart && !startThreshold.IsZero() && captureTime.Before(startThreshold) {
-				continue
-			}
-			if skippingToStart {
-				skippingToStart = false
-				diagf("PCAP replay: started at %.2fs offset", startSeconds)
-			}
+		packetCount++
 
-			// Stop if we've reached the end threshold
-			if !endThreshold.IsZero() && captureTime.After(endThreshold) {
-				elapsed := time.Since(startTime)
-				diagf("PCAP replay complete: reached duration limit of %.2fs (processed %d packets in %v)", durationSeconds, packetCount, elapsed)
-				return nil
-			}
+		if len(payload) == 0 {
+			continue
+		}
 
-			packetCount++
+		// Record packet statistics
+		if stats != nil {
+			stats.AddPacket(len(payload))
+		}
 
-			// Extract UDP layer
-			udpLayer := packet.Layer(layers.LayerTypeUDP)
-			if udpLayer == nil {
-				continue // Skip non-UDP packets (shouldn't happen with BPF filter)
-			}
+		// Forward packet if forwarder is configured
+		if forwarder != nil {
+			forwarder.ForwardAsync(payload)
+		}
 
-			udp, ok := udpLayer.(*layers.UDP)
-			if !ok {
-				continue
+		// Parse and process the packet if parser is provided
+		if parser != nil {
+			// When replaying from PCAP, prefer capture timestamps over device clock
+			if tsParser, ok := parser.(interface{ SetPacketTime(time.Time) }); ok {
+				tsParser.SetPacketTime(captureTime)
 			}
-
-			// Extract payload (LiDAR data)
-			payload := udp.Payload
-			if len(payload) == 0 {
+			points, err := parser.ParsePacket(payload)
+			if err != nil {
+				opsf("Error parsing PCAP packet %d: %v", packetCount, err)
 				continue
 			}
 
-			// Record packet statistics
-			if stats != nil {
-				stats.AddPacket(len(payload))
+			// Diagnostic: report parsed point counts to help debug empty backgrounds
+			if len(points) == 0 {
+				lidar.Tracef("PCAP packet %d parsed -> 0 points", packetCount)
+			} else {
+				totalPoints += len(points)
+				// Debug-level: every 1000 packets
+				if packetCount%1000 == 0 {
+					lidar.Tracef("PCAP parsed points: packet=%d, points_this_packet=%d, total_parsed_points=%d",
+						packetCount, len(points), totalPoints)
+				}
 			}
 
-			// Forward packet if forwarder is configured
-			if forwarder != nil {
-				forwarder.ForwardAsync(payload)
+			if stats != nil {
+				stats.AddPoints(len(points))
 			}
 
-			// Parse and process the packet if parser is provided
-			if parser != nil {
-				// When replaying from PCAP, prefer capture timestamps over device clock
-				if tsParser, ok := parser.(interface{ SetPacketTime(time.Time) }); ok {
-					tsParser.SetPacketTime(packet.Metadata().Timestamp)
-				}
-				points, err := parser.ParsePacket(payload)
-				if err != nil {
-					opsf("Error parsing PCAP packet %d: %v", packetCount, err)
-					continue
-				}
-
-				// Diagnostic: report parsed point counts to help debug empty backgrounds
-				if len(points) == 0 {
-					lidar.Tracef("PCAP packet %d parsed -> 0 points", packetCount)
-				} else {
-					totalPoints += len(points)
-					// Debug-level: every 1000 packets
-					if packetCount%1000 == 0 {
-						lidar.Tracef("PCAP parsed points: packet=%d, points_this_packet=%d, total_parsed_points=%d",
-							packetCount, len(points), totalPoints)
-					}
-				}
-
-				if stats != nil {
-					stats.AddPoints(len(points))
-				}
-
-				if frameBuilder != nil {
-					frameBuilder.AddPointsPolar(points)
-					motorSpeed := parser.GetLastMotorSpeed()
-					if motorSpeed > 0 {
-						frameBuilder.SetMotorSpeed(motorSpeed)
-					}
+			if frameBuilder != nil {
+				frameBuilder.AddPointsPolar(points)
+				motorSpeed := parser.GetLastMotorSpeed()
+				if motorSpeed > 0 {
+					frameBuilder.SetMotorSpeed(motorSpeed)
 				}
 			}
+		}
 
-			// Log progress periodically
-			if packetCount%10000 == 0 {
-				elapsed := time.Since(startTime)
-				tracef("PCAP progress: %d packets processed in %v (%.0f pkt/s)",
-					packetCount, elapsed, float64(packetCount)/elapsed.Seconds())
-			}
+		// Log progress periodically
+		if packetCount%10000 == 0 {
+			elapsed := time.Since(startTime)
+			tracef("PCAP progress: %d packets processed in %v (%.0f pkt/s)",
+				packetCount, elapsed, float64(packetCount)/elapsed.Seconds())
 		}
 	}
 }
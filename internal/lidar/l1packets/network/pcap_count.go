@@ -7,7 +7,6 @@ import (
 	"fmt"
 
 	"github.com/google/gopacket"
-	"github.com/google/gopacket/pcap"
 )
 
 // PCAPCountResult holds the result of counting packets in a PCAP file.
@@ -19,14 +18,20 @@ type PCAPCountResult struct {
 
 // CountPCAPPackets counts the total number of UDP packets matching the given
 // port in a PCAP file and captures the first/last packet timestamps.
-// This enables progress reporting and timeline display.
+// This enables progress reporting and timeline display. The port is
+// resolved as for ReadPCAPFile.
 func CountPCAPPackets(pcapFile string, udpPort int) (PCAPCountResult, error) {
-	handle, err := pcap.OpenOffline(pcapFile)
+	handle, err := openPCAPSource(pcapFile)
 	if err != nil {
 		return PCAPCountResult{}, fmt.Errorf("failed to open PCAP file %s for counting: %w", pcapFile, err)
 	}
 	defer handle.Close()
 
+	udpPort, err = ResolvePCAPPort(pcapFile, udpPort)
+	if err != nil {
+		return PCAPCountResult{}, err
+	}
+
 	filterStr := fmt.Sprintf("udp port %d", udpPort)
 	if err := handle.SetBPFFilter(filterStr); err != nil {
 		return PCAPCountResult{}, fmt.Errorf("failed to set BPF filter '%s': %w", filterStr, err)
@@ -0,0 +1,56 @@
+//go:build pcap
+// +build pcap
+
+package network
+
+import (
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+// pcapSource is the subset of *pcap.Handle used by the PCAP readers, so
+// compressed captures can be read through the same code path.
+type pcapSource interface {
+	gopacket.PacketDataSource
+	LinkType() layers.LinkType
+	SetBPFFilter(expr string) error
+	Close()
+}
+
+// openPCAPSource opens a capture for offline reading. Uncompressed files go
+// straight to libpcap; gzip and zstd files are decompressed as a stream and
+// filtered with the same BPF engine, never expanded on disk.
+func openPCAPSource(path string) (pcapSource, error) {
+	compression, err := DetectPCAPCompression(path)
+	if err != nil {
+		return nil, err
+	}
+	if compression == PCAPCompressionNone {
+		handle, err := pcap.OpenOffline(path)
+		if err != nil {
+			return nil, err
+		}
+		return handle, nil
+	}
+	stream, err := OpenPCAPStream(path)
+	if err != nil {
+		return nil, err
+	}
+	diagf("PCAP reader: streaming %s-compressed capture %s", compression, path)
+	return &bpfPCAPStream{PCAPStreamReader: stream}, nil
+}
+
+// bpfPCAPStream adds libpcap BPF filtering to a PCAPStreamReader.
+type bpfPCAPStream struct {
+	*PCAPStreamReader
+}
+
+func (s *bpfPCAPStream) SetBPFFilter(expr string) error {
+	bpf, err := pcap.NewBPF(s.LinkType(), 65536, expr)
+	if err != nil {
+		return err
+	}
+	s.filter = bpf.Matches
+	return nil
+}
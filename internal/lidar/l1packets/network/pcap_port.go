@@ -0,0 +1,108 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// PCAPPortScanPackets is how many leading packets ResolvePCAPPort inspects.
+const PCAPPortScanPackets = 5000
+
+// PCAPPortScan summarises the UDP traffic in the first packets of a capture.
+type PCAPPortScan struct {
+	Packets     int         // Packets inspected
+	LiDARByPort map[int]int // Pandar40PSignature payloads by UDP destination port
+	UDPByPort   map[int]int // All UDP packets by port, source or destination
+}
+
+// DominantLiDARPort returns the destination port carrying the most
+// LiDAR-shaped payloads, the lowest port winning ties, or 0 if none was seen.
+func (s PCAPPortScan) DominantLiDARPort() int {
+	ports := make([]int, 0, len(s.LiDARByPort))
+	for port := range s.LiDARByPort {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	best := 0
+	for _, port := range ports {
+		if best == 0 || s.LiDARByPort[port] > s.LiDARByPort[best] {
+			best = port
+		}
+	}
+	return best
+}
+
+// ScanPCAPPorts reads up to maxPackets packets from the start of a capture,
+// which may be compressed, and tallies their UDP ports. It needs no libpcap.
+func ScanPCAPPorts(pcapFile string, maxPackets int) (PCAPPortScan, error) {
+	stream, err := OpenPCAPStream(pcapFile)
+	if err != nil {
+		return PCAPPortScan{}, err
+	}
+	defer stream.Close()
+
+	scan := PCAPPortScan{LiDARByPort: make(map[int]int), UDPByPort: make(map[int]int)}
+	decode := gopacket.DecodeOptions{Lazy: true, NoCopy: true}
+	for scan.Packets < maxPackets {
+		data, _, err := stream.ReadPacketData()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return scan, fmt.Errorf("scan %s: %w", pcapFile, err)
+		}
+		scan.Packets++
+
+		udp, ok := gopacket.NewPacket(data, stream.LinkType(), decode).Layer(layers.LayerTypeUDP).(*layers.UDP)
+		if !ok {
+			continue
+		}
+		src, dst := int(udp.SrcPort), int(udp.DstPort)
+		scan.UDPByPort[dst]++
+		if src != dst {
+			scan.UDPByPort[src]++
+		}
+		if Pandar40PSignature.Match(udp.Payload) {
+			scan.LiDARByPort[dst]++
+		}
+	}
+	return scan, nil
+}
+
+// ResolvePCAPPort picks the UDP port to replay from a capture. A non-zero
+// udpPort is used whenever the first PCAPPortScanPackets packets include
+// traffic on it. Otherwise, with no port given or a port that matches
+// nothing, the destination port carrying the most LiDAR-shaped payloads is
+// used and logged. If no LiDAR traffic is found a given port is returned
+// unchanged, and a zero port is an error.
+func ResolvePCAPPort(pcapFile string, udpPort int) (int, error) {
+	scan, err := ScanPCAPPorts(pcapFile, PCAPPortScanPackets)
+	if err != nil {
+		return udpPort, err
+	}
+	if udpPort > 0 && scan.UDPByPort[udpPort] > 0 {
+		return udpPort, nil
+	}
+
+	detected := scan.DominantLiDARPort()
+	switch {
+	case detected == 0 && udpPort > 0:
+		opsf("PCAP %s: no packets on UDP port %d and no LiDAR traffic in the first %d packets",
+			pcapFile, udpPort, scan.Packets)
+		return udpPort, nil
+	case detected == 0:
+		return 0, fmt.Errorf("no LiDAR packets found in the first %d packets of %s", scan.Packets, pcapFile)
+	case udpPort > 0:
+		opsf("PCAP %s: no packets on UDP port %d; using detected LiDAR port %d (%d of %d packets)",
+			pcapFile, udpPort, detected, scan.LiDARByPort[detected], scan.Packets)
+	default:
+		opsf("PCAP %s: detected LiDAR UDP port %d (%d of %d packets)",
+			pcapFile, detected, scan.LiDARByPort[detected], scan.Packets)
+	}
+	return detected, nil
+}
@@ -0,0 +1,147 @@
+package network
+
+import (
+	"compress/gzip"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// writeUDPRecord appends one Ethernet/IPv4/UDP packet carrying payload to
+// a capture.
+func writeUDPRecord(t *testing.T, w *pcapgo.Writer, ts time.Time, srcPort, dstPort int, payload []byte) {
+	t.Helper()
+	eth := &layers.Ethernet{SrcMAC: net.HardwareAddr{2, 0, 0, 0, 0, 1}, DstMAC: layers.EthernetBroadcast,
+		EthernetType: layers.EthernetTypeIPv4}
+	ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolUDP,
+		SrcIP: net.IPv4(192, 168, 1, 201).To4(), DstIP: net.IPv4(192, 168, 1, 10).To4()}
+	udp := &layers.UDP{SrcPort: layers.UDPPort(srcPort), DstPort: layers.UDPPort(dstPort)}
+	if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+		t.Fatal(err)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, eth, ip, udp, gopacket.Payload(payload)); err != nil {
+		t.Fatal(err)
+	}
+	ci := gopacket.CaptureInfo{Timestamp: ts, CaptureLength: len(buf.Bytes()), Length: len(buf.Bytes())}
+	if err := w.WritePacket(ci, buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writePortFixture writes a capture with 30 Pandar40P payloads to UDP port
+// 2370, interleaved with DNS traffic, small packets to 2369 and
+// Pandar-sized payloads without the block preamble to 6000, and returns its
+// path. With compress set the file is gzipped.
+func writePortFixture(t *testing.T, compress bool) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "lidar_2370.pcap")
+	if compress {
+		path += ".gz"
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var dst io.Writer = f
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(f)
+		dst = gz
+	}
+	w := pcapgo.NewWriter(dst)
+	if err := w.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	write := func(i, srcPort, dstPort int, payload []byte) {
+		writeUDPRecord(t, w, base.Add(time.Duration(i)*time.Millisecond), srcPort, dstPort, payload)
+	}
+	for i := 0; i < 30; i++ {
+		write(i, 10000, 2370, pandarPacket(i*10))
+		if i%5 == 0 {
+			write(i, 5353, 53, make([]byte, 64))
+			write(i, 40000, 2369, make([]byte, 32))
+			write(i, 6000, 6000, make([]byte, 1262))
+		}
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestScanPCAPPorts(t *testing.T) {
+	scan, err := ScanPCAPPorts(writePortFixture(t, false), PCAPPortScanPackets)
+	if err != nil {
+		t.Fatalf("ScanPCAPPorts: %v", err)
+	}
+	if scan.Packets != 48 {
+		t.Errorf("Packets = %d, want 48", scan.Packets)
+	}
+	if scan.LiDARByPort[2370] != 30 || len(scan.LiDARByPort) != 1 {
+		t.Errorf("LiDARByPort = %v, want only 30 on 2370 (not the preamble-less 6000)", scan.LiDARByPort)
+	}
+	if scan.UDPByPort[2369] != 6 || scan.UDPByPort[53] != 6 {
+		t.Errorf("UDPByPort = %v, want 6 packets each on 2369 and 53", scan.UDPByPort)
+	}
+	if got := scan.DominantLiDARPort(); got != 2370 {
+		t.Errorf("DominantLiDARPort = %d, want 2370", got)
+	}
+
+	scan, err = ScanPCAPPorts(writePortFixture(t, false), 10)
+	if err != nil || scan.Packets != 10 {
+		t.Errorf("limited scan: Packets = %d, err = %v, want 10", scan.Packets, err)
+	}
+}
+
+func TestResolvePCAPPort(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		path := writePortFixture(t, compress)
+		tests := []struct {
+			name string
+			port int
+			want int
+		}{
+			{"no port detects the LiDAR port", 0, 2370},
+			{"port with no traffic falls back to detection", 2368, 2370},
+			{"explicit port with traffic overrides detection", 2369, 2369},
+			{"explicit LiDAR port", 2370, 2370},
+		}
+		for _, tt := range tests {
+			got, err := ResolvePCAPPort(path, tt.port)
+			if err != nil || got != tt.want {
+				t.Errorf("%s (%s): ResolvePCAPPort(%d) = %d, %v, want %d",
+					filepath.Base(path), tt.name, tt.port, got, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestResolvePCAPPort_NoLiDARTraffic(t *testing.T) {
+	// The stream fixture carries only small payloads.
+	if got, err := ResolvePCAPPort(streamFixturePlain, 2369); err != nil || got != 2369 {
+		t.Errorf("ResolvePCAPPort(2369) = %d, %v, want the given port kept", got, err)
+	}
+	if got, err := ResolvePCAPPort(streamFixturePlain, 2368); err != nil || got != 2368 {
+		t.Errorf("ResolvePCAPPort(2368) = %d, %v, want the given port kept", got, err)
+	}
+	if _, err := ResolvePCAPPort(streamFixturePlain, 0); err == nil {
+		t.Error("ResolvePCAPPort(0) succeeded with no LiDAR traffic, want an error")
+	}
+	if _, err := ResolvePCAPPort(filepath.Join(t.TempDir(), "missing.pcap"), 2369); err == nil {
+		t.Error("ResolvePCAPPort on a missing file succeeded")
+	}
+}
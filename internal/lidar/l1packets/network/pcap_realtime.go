@@ -5,15 +5,13 @@ package network
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"io"
 	"time"
 
 	"github.com/banshee-data/velocity.report/internal/lidar"
 	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
 	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
-	"github.com/google/gopacket"
-	"github.com/google/gopacket/layers"
-	"github.com/google/gopacket/pcap"
 )
 
 // RealtimeReplayConfig configures real-time PCAP replay behavior.
@@ -94,27 +92,20 @@ const (
 // ReadPCAPFileRealtime reads and replays a PCAP file in real-time, respecting original packet timing.
 // This allows live network forwarding of PCAP data for real-time analysis.
 // Packets are forwarded via PacketForwarder if configured.
+// The port is resolved as for ReadPCAPFile.
 func ReadPCAPFileRealtime(ctx context.Context, pcapFile string, udpPort int, parser Parser, frameBuilder FrameBuilder, stats PacketStatsInterface, config RealtimeReplayConfig) error {
 	// Default speed multiplier
 	if config.SpeedMultiplier <= 0 {
 		config.SpeedMultiplier = 1.0
 	}
 
-	// Open PCAP file
-	handle, err := pcap.OpenOffline(pcapFile)
+	src, err := OpenPCAPPacketSource(pcapFile, udpPort)
 	if err != nil {
-		return fmt.Errorf("failed to open PCAP file %s: %w", pcapFile, err)
+		return err
 	}
-	defer handle.Close()
+	defer src.Close()
+	diagf("PCAP real-time replay: reading udp port %d (speed: %.2fx)", src.Port(), config.SpeedMultiplier)
 
-	// Set BPF filter to only capture UDP packets on the specified port
-	filterStr := fmt.Sprintf("udp port %d", udpPort)
-	if err := handle.SetBPFFilter(filterStr); err != nil {
-		return fmt.Errorf("failed to set BPF filter '%s': %w", filterStr, err)
-	}
-	diagf("PCAP real-time replay: BPF filter set: %s (speed: %.2fx)", filterStr, config.SpeedMultiplier)
-
-	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
 	pcapLog := lidar.SubLogger("pcap")
 	var packetIndex uint64 // 0-based index across all matching packets
 	packetCount := 0
@@ -148,378 +139,365 @@ func ReadPCAPFileRealtime(ctx context.Context, pcapFile string, udpPort int, par
 	var bufferedPackets int
 
 	for {
-		select {
-		case <-ctx.Done():
+		payload, captureTime, err := src.NextPacket(ctx)
+		if ctx.Err() != nil {
 			// Flush remaining foreground buffer on exit
 			if config.ForegroundForwarder != nil && len(foregroundBuffer) > 0 {
 				config.ForegroundForwarder.ForwardForeground(foregroundBuffer)
 			}
 			diagf("PCAP real-time replay stopping due to context cancellation (processed %d packets)", packetCount)
 			return ctx.Err()
-		case packet := <-packetSource.Packets():
-			if packet == nil {
-				// End of PCAP file
-				elapsed := time.Since(startTime)
-				diagf("PCAP real-time replay complete: %d packets processed in %v (speed: %.2fx)", packetCount, elapsed, config.SpeedMultiplier)
-				if backoffCount > 0 {
-					backoffPct := float64(totalBackoffPackets) / float64(max(packetCount, 1)) * 100
-					pcapLog("Replay backoff summary: %d total backoffs, max_behind=%.3fs, backoff_packets=%d/%d (%.1f%%), cumulative_yield=%.3fs, time_breakdown: parse=%.3fs frame=%.3fs pacing=%.3fs",
-						backoffCount, maxBehindBy.Seconds(), totalBackoffPackets, packetCount, backoffPct, cumulativeYield.Seconds(),
-						cumulativeParseTime.Seconds(), cumulativeFrameTime.Seconds(), cumulativePacingTime.Seconds())
-				}
-				// Final progress callback
-				if config.OnProgress != nil {
-					config.OnProgress(packetIndex, config.TotalPackets)
-				}
-				return nil
-			}
-
-			packetIndex++
-			packetCount++
-
-			// Offset-based seek: skip packets until we reach the requested offset
-			if skippingToOffset && packetIndex < config.PacketOffset {
-				continue
-			}
-			if skippingToOffset {
-				skippingToOffset = false
-				diagf("PCAP replay: seeked to packet offset %d", config.PacketOffset)
-				replayStartTime = time.Now()
+		}
+		if errors.Is(err, io.EOF) {
+			// End of PCAP file
+			elapsed := time.Since(startTime)
+			diagf("PCAP real-time replay complete: %d packets processed in %v (speed: %.2fx)", packetCount, elapsed, config.SpeedMultiplier)
+			if backoffCount > 0 {
+				backoffPct := float64(totalBackoffPackets) / float64(max(packetCount, 1)) * 100
+				pcapLog("Replay backoff summary: %d total backoffs, max_behind=%.3fs, backoff_packets=%d/%d (%.1f%%), cumulative_yield=%.3fs, time_breakdown: parse=%.3fs frame=%.3fs pacing=%.3fs",
+					backoffCount, maxBehindBy.Seconds(), totalBackoffPackets, packetCount, backoffPct, cumulativeYield.Seconds(),
+					cumulativeParseTime.Seconds(), cumulativeFrameTime.Seconds(), cumulativePacingTime.Seconds())
 			}
-
-			// Report progress periodically (every 100 packets)
-			if config.OnProgress != nil && packetIndex%100 == 0 {
+			// Final progress callback
+			if config.OnProgress != nil {
 				config.OnProgress(packetIndex, config.TotalPackets)
 			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-			// Calculate timing for real-time replay
-			captureTime := packet.Metadata().Timestamp
-
-			if firstPacketTime.IsZero() {
-				firstPacketTime = captureTime
-				// Set start and end thresholds based on config
-				if config.StartSeconds > 0 {
-					startThreshold = firstPacketTime.Add(time.Duration(config.StartSeconds * float64(time.Second)))
-				} else {
-					// When no start offset, use first packet time as the baseline
-					startThreshold = firstPacketTime
-					// Reset replay start time for accurate pacing when not skipping
-					replayStartTime = time.Now()
-				}
-				if config.DurationSeconds > 0 {
-					// Duration is always relative to the effective start threshold
-					endThreshold = startThreshold.Add(time.Duration(config.DurationSeconds * float64(time.Second)))
-				}
-				tracef("PCAP start: first_packet=%v, start_threshold=%v, end_threshold=%v, skipping_to_start=%v",
-					firstPacketTime, startThreshold, endThreshold, skippingToStart)
-			}
+		packetIndex++
+		packetCount++
 
-			// Skip packets before start threshold
-			if skippingToStart && !startThreshold.IsZero() && captureTime.Before(startThreshold) {
-				continue
+		// Offset-based seek: skip packets until we reach the requested offset
+		if skippingToOffset && packetIndex < config.PacketOffset {
+			continue
+		}
+		if skippingToOffset {
+			skippingToOffset = false
+			diagf("PCAP replay: seeked to packet offset %d", config.PacketOffset)
+			replayStartTime = time.Now()
+		}
+
+		// Report progress periodically (every 100 packets)
+		if config.OnProgress != nil && packetIndex%100 == 0 {
+			config.OnProgress(packetIndex, config.TotalPackets)
+		}
+
+		// Calculate timing for real-time replay
+		if firstPacketTime.IsZero() {
+			firstPacketTime = captureTime
+			// Set start and end thresholds based on config
+			if config.StartSeconds > 0 {
+				startThreshold = firstPacketTime.Add(time.Duration(config.StartSeconds * float64(time.Second)))
+			} else {
+				// When no start offset, use first packet time as the baseline
+				startThreshold = firstPacketTime
+				// Reset replay start time for accurate pacing when not skipping
+				replayStartTime = time.Now()
 			}
-			if skippingToStart {
-				skippingToStart = false
-				diagf("PCAP replay: started at %.2fs offset", config.StartSeconds)
-				replayStartTime = time.Now() // Reset start time for accurate speed reporting
+			if config.DurationSeconds > 0 {
+				// Duration is always relative to the effective start threshold
+				endThreshold = startThreshold.Add(time.Duration(config.DurationSeconds * float64(time.Second)))
 			}
+			tracef("PCAP start: first_packet=%v, start_threshold=%v, end_threshold=%v, skipping_to_start=%v",
+				firstPacketTime, startThreshold, endThreshold, skippingToStart)
+		}
 
-			// Stop if we've reached the end threshold
-			if !endThreshold.IsZero() && captureTime.After(endThreshold) {
-				diagf("PCAP replay complete: reached duration limit of %.2fs (packet_ts=%v, end_threshold=%v, packets=%d, delta=%.3fs)",
-					config.DurationSeconds, captureTime, endThreshold, packetCount,
-					captureTime.Sub(endThreshold).Seconds())
-				return nil
-			}
+		// Skip packets before start threshold
+		if skippingToStart && !startThreshold.IsZero() && captureTime.Before(startThreshold) {
+			continue
+		}
+		if skippingToStart {
+			skippingToStart = false
+			diagf("PCAP replay: started at %.2fs offset", config.StartSeconds)
+			replayStartTime = time.Now() // Reset start time for accurate speed reporting
+		}
 
-			// Log first few packets for debugging timestamp issues
-			if packetCount <= 3 {
-				tracef("PCAP packet #%d: capture_time=%v, first_packet=%v, delta_from_first=%.3fs",
-					packetCount, captureTime, firstPacketTime, captureTime.Sub(firstPacketTime).Seconds())
-			}
+		// Stop if we've reached the end threshold
+		if !endThreshold.IsZero() && captureTime.After(endThreshold) {
+			diagf("PCAP replay complete: reached duration limit of %.2fs (packet_ts=%v, end_threshold=%v, packets=%d, delta=%.3fs)",
+				config.DurationSeconds, captureTime, endThreshold, packetCount,
+				captureTime.Sub(endThreshold).Seconds())
+			return nil
+		}
 
-			// Real-time pacing: compare wall clock elapsed with PCAP time elapsed.
-			// This accounts for processing time and avoids cumulative lag.
-			//
-			// For high speed multipliers (>= 100x), skip
-			// timing-based pacing entirely. Back-pressure comes from the
-			// FrameBuilder's blocking frame channel instead: when the pipeline
-			// can't keep up, AddPointsPolar blocks, naturally throttling the
-			// PCAP reader to match pipeline throughput.
-			//
-			// Dynamic backoff: when the pipeline falls behind schedule
-			// (waitTime < 0), insert a proportional yield instead of
-			// firing packets at full speed. This prevents catch-up bursts
-			// that flood the FrameBuilder channel, spike CPU to 150%+,
-			// and cause track breaks from dropped frames.
-			if config.SpeedMultiplier < 100 && firstPacketTime != captureTime {
-				// How much PCAP time has elapsed since the effective start?
-				pcapElapsed := captureTime.Sub(startThreshold)
-				// How much wall clock time should have elapsed at this speed?
-				targetWallElapsed := time.Duration(float64(pcapElapsed) / config.SpeedMultiplier)
-				// How much wall clock time has actually elapsed?
-				// Subtract cumulative backoff yield to prevent death spiral:
-				// without this, each backoff sleep inflates actualWallElapsed
-				// without advancing pcapElapsed, making behindBy grow
-				// monotonically and trapping the reader in permanent backoff.
-				actualWallElapsed := time.Since(replayStartTime) - cumulativeYield
-				// Wait for the difference (if we're ahead of schedule)
-				waitTime := targetWallElapsed - actualWallElapsed
-
-				if waitTime > 0 {
-					if inBackoff {
-						backoffSpan := packetCount - backoffPacketStart
-						totalBackoffPackets += backoffSpan
-						pcapLog("Backoff cleared: pipeline caught up after %d backoffs, span=%d packets (pcap=%.3fs wall=%.3fs)",
-							backoffCount, backoffSpan, pcapElapsed.Seconds(), actualWallElapsed.Seconds())
-						inBackoff = false
-					}
-					pacingStart := time.Now()
-					select {
-					case <-ctx.Done():
-						return ctx.Err()
-					case <-time.After(waitTime):
-						// Continue
-					}
-					cumulativePacingTime += time.Since(pacingStart)
-				} else if waitTime < -pcapBackoffThreshold && time.Since(replayStartTime) > pcapStartupGracePeriod {
-					// Pipeline is behind schedule. Apply dynamic backoff:
-					// yield proportionally to how far behind we are, capped
-					// at pcapBackoffMaxYield. This lets the pipeline drain
-					// without a full stop and avoids the positive feedback
-					// loop (slow frame → burst → more slow frames).
-					behindBy := -waitTime
-					yield := behindBy / 4 // 25% of lag as yield
-					if yield > pcapBackoffMaxYield {
-						yield = pcapBackoffMaxYield
-					}
-					if yield < pcapBackoffMinYield {
-						yield = pcapBackoffMinYield
-					}
-					backoffCount++
-					if behindBy > maxBehindBy {
-						maxBehindBy = behindBy
-					}
-					if !inBackoff {
-						// First backoff in this sequence — log with time breakdown
-						diagPackets := packetCount - lastDiagPacket
-						pcapLog("Backoff: pipeline behind by %.3fs, yielding %.3fs (packets=%d pcap=%.3fs wall=%.3fs, since_last_diag=%d: parse=%.3fs frame=%.3fs pacing=%.3fs)",
-							behindBy.Seconds(), yield.Seconds(), packetCount, pcapElapsed.Seconds(), actualWallElapsed.Seconds(),
-							diagPackets, cumulativeParseTime.Seconds(), cumulativeFrameTime.Seconds(), cumulativePacingTime.Seconds())
-						inBackoff = true
-						backoffPacketStart = packetCount
-						// Reset diagnostic counters
-						lastDiagPacket = packetCount
-						cumulativeParseTime = 0
-						cumulativeFrameTime = 0
-						cumulativePacingTime = 0
-					} else if backoffCount%50 == 0 {
-						// Sustained backoff — log periodically
-						pcapLog("Backoff: pipeline behind by %.3fs, yielding %.3fs (total backoffs: %d packets=%d pcap=%.3fs wall=%.3fs)",
-							behindBy.Seconds(), yield.Seconds(), backoffCount, packetCount, pcapElapsed.Seconds(), actualWallElapsed.Seconds())
-					}
-					select {
-					case <-ctx.Done():
-						return ctx.Err()
-					case <-time.After(yield):
-						cumulativeYield += yield
-					}
-				} else if inBackoff {
-					// waitTime is between -threshold and 0: still slightly behind
-					// but within tolerance. Log recovery with span info.
+		// Log first few packets for debugging timestamp issues
+		if packetCount <= 3 {
+			tracef("PCAP packet #%d: capture_time=%v, first_packet=%v, delta_from_first=%.3fs",
+				packetCount, captureTime, firstPacketTime, captureTime.Sub(firstPacketTime).Seconds())
+		}
+
+		// Real-time pacing: compare wall clock elapsed with PCAP time elapsed.
+		// This accounts for processing time and avoids cumulative lag.
+		//
+		// For high speed multipliers (>= 100x), skip
+		// timing-based pacing entirely. Back-pressure comes from the
+		// FrameBuilder's blocking frame channel instead: when the pipeline
+		// can't keep up, AddPointsPolar blocks, naturally throttling the
+		// PCAP reader to match pipeline throughput.
+		//
+		// Dynamic backoff: when the pipeline falls behind schedule
+		// (waitTime < 0), insert a proportional yield instead of
+		// firing packets at full speed. This prevents catch-up bursts
+		// that flood the FrameBuilder channel, spike CPU to 150%+,
+		// and cause track breaks from dropped frames.
+		if config.SpeedMultiplier < 100 && firstPacketTime != captureTime {
+			// How much PCAP time has elapsed since the effective start?
+			pcapElapsed := captureTime.Sub(startThreshold)
+			// How much wall clock time should have elapsed at this speed?
+			targetWallElapsed := time.Duration(float64(pcapElapsed) / config.SpeedMultiplier)
+			// How much wall clock time has actually elapsed?
+			// Subtract cumulative backoff yield to prevent death spiral:
+			// without this, each backoff sleep inflates actualWallElapsed
+			// without advancing pcapElapsed, making behindBy grow
+			// monotonically and trapping the reader in permanent backoff.
+			actualWallElapsed := time.Since(replayStartTime) - cumulativeYield
+			// Wait for the difference (if we're ahead of schedule)
+			waitTime := targetWallElapsed - actualWallElapsed
+
+			if waitTime > 0 {
+				if inBackoff {
 					backoffSpan := packetCount - backoffPacketStart
 					totalBackoffPackets += backoffSpan
 					pcapLog("Backoff cleared: pipeline caught up after %d backoffs, span=%d packets (pcap=%.3fs wall=%.3fs)",
 						backoffCount, backoffSpan, pcapElapsed.Seconds(), actualWallElapsed.Seconds())
 					inBackoff = false
 				}
+				pacingStart := time.Now()
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(waitTime):
+					// Continue
+				}
+				cumulativePacingTime += time.Since(pacingStart)
+			} else if waitTime < -pcapBackoffThreshold && time.Since(replayStartTime) > pcapStartupGracePeriod {
+				// Pipeline is behind schedule. Apply dynamic backoff:
+				// yield proportionally to how far behind we are, capped
+				// at pcapBackoffMaxYield. This lets the pipeline drain
+				// without a full stop and avoids the positive feedback
+				// loop (slow frame → burst → more slow frames).
+				behindBy := -waitTime
+				yield := behindBy / 4 // 25% of lag as yield
+				if yield > pcapBackoffMaxYield {
+					yield = pcapBackoffMaxYield
+				}
+				if yield < pcapBackoffMinYield {
+					yield = pcapBackoffMinYield
+				}
+				backoffCount++
+				if behindBy > maxBehindBy {
+					maxBehindBy = behindBy
+				}
+				if !inBackoff {
+					// First backoff in this sequence — log with time breakdown
+					diagPackets := packetCount - lastDiagPacket
+					pcapLog("Backoff: pipeline behind by %.3fs, yielding %.3fs (packets=%d pcap=%.3fs wall=%.3fs, since_last_diag=%d: parse=%.3fs frame=%.3fs pacing=%.3fs)",
+						behindBy.Seconds(), yield.Seconds(), packetCount, pcapElapsed.Seconds(), actualWallElapsed.Seconds(),
+						diagPackets, cumulativeParseTime.Seconds(), cumulativeFrameTime.Seconds(), cumulativePacingTime.Seconds())
+					inBackoff = true
+					backoffPacketStart = packetCount
+					// Reset diagnostic counters
+					lastDiagPacket = packetCount
+					cumulativeParseTime = 0
+					cumulativeFrameTime = 0
+					cumulativePacingTime = 0
+				} else if backoffCount%50 == 0 {
+					// Sustained backoff — log periodically
+					pcapLog("Backoff: pipeline behind by %.3fs, yielding %.3fs (total backoffs: %d packets=%d pcap=%.3fs wall=%.3fs)",
+						behindBy.Seconds(), yield.Seconds(), backoffCount, packetCount, pcapElapsed.Seconds(), actualWallElapsed.Seconds())
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(yield):
+					cumulativeYield += yield
+				}
+			} else if inBackoff {
+				// waitTime is between -threshold and 0: still slightly behind
+				// but within tolerance. Log recovery with span info.
+				backoffSpan := packetCount - backoffPacketStart
+				totalBackoffPackets += backoffSpan
+				pcapLog("Backoff cleared: pipeline caught up after %d backoffs, span=%d packets (pcap=%.3fs wall=%.3fs)",
+					backoffCount, backoffSpan, pcapElapsed.Seconds(), actualWallElapsed.Seconds())
+				inBackoff = false
 			}
+		}
 
-			// Extract UDP layer
-			udpLayer := packet.Layer(layers.LayerTypeUDP)
-			if udpLayer == nil {
-				continue // Skip non-UDP packets
-			}
+		if len(payload) == 0 {
+			continue
+		}
 
-			udp, ok := udpLayer.(*layers.UDP)
-			if !ok {
-				continue
-			}
+		// Record packet statistics
+		if stats != nil {
+			stats.AddPacket(len(payload))
+		}
 
-			// Extract payload (LiDAR data)
-			payload := udp.Payload
-			if len(payload) == 0 {
-				continue
-			}
+		// Forward packet to UDP destination if configured
+		if config.PacketForwarder != nil {
+			config.PacketForwarder.ForwardAsync(payload)
+		}
 
-			// Record packet statistics
-			if stats != nil {
-				stats.AddPacket(len(payload))
+		// Parse and process the packet if parser is provided
+		if parser != nil {
+			// Use capture timestamp for time-aware processing
+			if tsParser, ok := parser.(interface{ SetPacketTime(time.Time) }); ok {
+				tsParser.SetPacketTime(captureTime)
 			}
 
-			// Forward packet to UDP destination if configured
-			if config.PacketForwarder != nil {
-				config.PacketForwarder.ForwardAsync(payload)
+			parseStart := time.Now()
+			points, err := parser.ParsePacket(payload)
+			cumulativeParseTime += time.Since(parseStart)
+			if err != nil {
+				opsf("Error parsing PCAP packet %d: %v", packetCount, err)
+				continue
 			}
 
-			// Parse and process the packet if parser is provided
-			if parser != nil {
-				// Use capture timestamp for time-aware processing
-				if tsParser, ok := parser.(interface{ SetPacketTime(time.Time) }); ok {
-					tsParser.SetPacketTime(captureTime)
-				}
+			if len(points) == 0 {
+				lidar.Tracef("PCAP real-time replay: packet %d parsed -> 0 points", packetCount)
+			} else {
+				totalPoints += len(points)
 
-				parseStart := time.Now()
-				points, err := parser.ParsePacket(payload)
-				cumulativeParseTime += time.Since(parseStart)
-				if err != nil {
-					opsf("Error parsing PCAP packet %d: %v", packetCount, err)
-					continue
-				}
-
-				if len(points) == 0 {
-					lidar.Tracef("PCAP real-time replay: packet %d parsed -> 0 points", packetCount)
-				} else {
-					totalPoints += len(points)
-
-					// Log progress every 1000 packets
-					if packetCount%1000 == 0 {
-						elapsed := time.Since(replayStartTime)
-						originalDuration := captureTime.Sub(firstPacketTime)
-						compressionRatio := float64(originalDuration) / float64(elapsed)
+				// Log progress every 1000 packets
+				if packetCount%1000 == 0 {
+					elapsed := time.Since(replayStartTime)
+					originalDuration := captureTime.Sub(firstPacketTime)
+					compressionRatio := float64(originalDuration) / float64(elapsed)
 
-						lidar.Tracef("PCAP real-time replay: packet=%d, points=%d, total_points=%d, elapsed=%v, original_duration=%v, compression=%.1fx",
-							packetCount, len(points), totalPoints, elapsed, originalDuration, compressionRatio)
-					}
+					lidar.Tracef("PCAP real-time replay: packet=%d, points=%d, total_points=%d, elapsed=%v, original_duration=%v, compression=%.1fx",
+						packetCount, len(points), totalPoints, elapsed, originalDuration, compressionRatio)
 				}
+			}
 
-				if stats != nil {
-					stats.AddPoints(len(points))
-				}
+			if stats != nil {
+				stats.AddPoints(len(points))
+			}
 
-				if frameBuilder != nil {
-					frameStart := time.Now()
-					frameBuilder.AddPointsPolar(points)
-					motorSpeed := parser.GetLastMotorSpeed()
-					if motorSpeed > 0 {
-						frameBuilder.SetMotorSpeed(motorSpeed)
-					}
-					cumulativeFrameTime += time.Since(frameStart)
+			if frameBuilder != nil {
+				frameStart := time.Now()
+				frameBuilder.AddPointsPolar(points)
+				motorSpeed := parser.GetLastMotorSpeed()
+				if motorSpeed > 0 {
+					frameBuilder.SetMotorSpeed(motorSpeed)
 				}
+				cumulativeFrameTime += time.Since(frameStart)
+			}
 
-				// Foreground extraction & snapshot caching if background manager is available
-				// IMPORTANT: Skip if frameBuilder is set, because the FrameBuilder callback
-				// (from TrackingPipelineConfig) already handles background processing,
-				// foreground extraction, snapshot caching, and forwarding. Processing here
-				// would cause double-processing of points through the grid, corrupting
-				// the running averages and causing false positives (trails).
-				if config.BackgroundManager != nil && frameBuilder == nil {
-					foregroundMask, err := config.BackgroundManager.ProcessFramePolarWithMask(points)
-					if err != nil {
-						opsf("Error extracting foreground points: %v", err)
-					} else if len(foregroundMask) > 0 {
-						foregroundPoints := l3grid.ExtractForegroundPoints(points, foregroundMask)
-
-						backgroundPolar := make([]l2frames.PointPolar, 0, len(points)-len(foregroundPoints))
-						for i, isForeground := range foregroundMask {
-							if !isForeground {
-								backgroundPolar = append(backgroundPolar, points[i])
-							}
+			// Foreground extraction & snapshot caching if background manager is available
+			// IMPORTANT: Skip if frameBuilder is set, because the FrameBuilder callback
+			// (from TrackingPipelineConfig) already handles background processing,
+			// foreground extraction, snapshot caching, and forwarding. Processing here
+			// would cause double-processing of points through the grid, corrupting
+			// the running averages and causing false positives (trails).
+			if config.BackgroundManager != nil && frameBuilder == nil {
+				foregroundMask, err := config.BackgroundManager.ProcessFramePolarWithMask(points)
+				if err != nil {
+					opsf("Error extracting foreground points: %v", err)
+				} else if len(foregroundMask) > 0 {
+					foregroundPoints := l3grid.ExtractForegroundPoints(points, foregroundMask)
+
+					backgroundPolar := make([]l2frames.PointPolar, 0, len(points)-len(foregroundPoints))
+					for i, isForeground := range foregroundMask {
+						if !isForeground {
+							backgroundPolar = append(backgroundPolar, points[i])
 						}
-						const maxBackgroundChartPoints = 5000
-						if len(backgroundPolar) > maxBackgroundChartPoints {
-							stride := len(backgroundPolar) / maxBackgroundChartPoints
-							if stride < 1 {
-								stride = 1
-							}
-							downsampled := make([]l2frames.PointPolar, 0, maxBackgroundChartPoints)
-							for i := 0; i < len(backgroundPolar); i += stride {
-								downsampled = append(downsampled, backgroundPolar[i])
-								if len(downsampled) >= maxBackgroundChartPoints {
-									break
-								}
+					}
+					const maxBackgroundChartPoints = 5000
+					if len(backgroundPolar) > maxBackgroundChartPoints {
+						stride := len(backgroundPolar) / maxBackgroundChartPoints
+						if stride < 1 {
+							stride = 1
+						}
+						downsampled := make([]l2frames.PointPolar, 0, maxBackgroundChartPoints)
+						for i := 0; i < len(backgroundPolar); i += stride {
+							downsampled = append(downsampled, backgroundPolar[i])
+							if len(downsampled) >= maxBackgroundChartPoints {
+								break
 							}
-							backgroundPolar = downsampled
 						}
+						backgroundPolar = downsampled
+					}
 
-						snapshotTS := time.Now()
-						if len(points) > 0 && points[0].Timestamp > 0 {
-							snapshotTS = time.Unix(0, points[0].Timestamp)
-						}
-						if config.SensorID != "" {
-							l3grid.StoreForegroundSnapshot(config.SensorID, snapshotTS, foregroundPoints, backgroundPolar, len(points), len(foregroundPoints))
-						}
+					snapshotTS := time.Now()
+					if len(points) > 0 && points[0].Timestamp > 0 {
+						snapshotTS = time.Unix(0, points[0].Timestamp)
+					}
+					if config.SensorID != "" {
+						l3grid.StoreForegroundSnapshot(config.SensorID, snapshotTS, foregroundPoints, backgroundPolar, len(points), len(foregroundPoints))
+					}
 
-						// Warmup: seed background without forwarding until warmupRemaining hits zero
-						if config.ForegroundForwarder != nil {
-							if warmupRemaining > 0 {
-								warmupRemaining--
-								if len(foregroundPoints) > 0 && (warmupRemaining%100 == 0 || warmupRemaining < 5) {
-									lidar.Tracef("[ForegroundForwarder] warmup skipping frame: remaining_packets=%d fg_points=%d total_points=%d", warmupRemaining, len(foregroundPoints), len(points))
-								}
-							} else if len(foregroundPoints) > 0 {
-								// Filter by debug range if configured
-								pointsToForward := foregroundPoints
-								if config.BackgroundManager != nil {
-									params := config.BackgroundManager.GetParams()
-									if params.HasDebugRange() {
-										filtered := make([]l2frames.PointPolar, 0, len(foregroundPoints))
-										for _, p := range foregroundPoints {
-											// Channel is 1-based in PointPolar, ring is 0-based in params
-											if params.IsInDebugRange(p.Channel-1, p.Azimuth) {
-												filtered = append(filtered, p)
-											}
+					// Warmup: seed background without forwarding until warmupRemaining hits zero
+					if config.ForegroundForwarder != nil {
+						if warmupRemaining > 0 {
+							warmupRemaining--
+							if len(foregroundPoints) > 0 && (warmupRemaining%100 == 0 || warmupRemaining < 5) {
+								lidar.Tracef("[ForegroundForwarder] warmup skipping frame: remaining_packets=%d fg_points=%d total_points=%d", warmupRemaining, len(foregroundPoints), len(points))
+							}
+						} else if len(foregroundPoints) > 0 {
+							// Filter by debug range if configured
+							pointsToForward := foregroundPoints
+							if config.BackgroundManager != nil {
+								params := config.BackgroundManager.GetParams()
+								if params.HasDebugRange() {
+									filtered := make([]l2frames.PointPolar, 0, len(foregroundPoints))
+									for _, p := range foregroundPoints {
+										// Channel is 1-based in PointPolar, ring is 0-based in params
+										if params.IsInDebugRange(p.Channel-1, p.Azimuth) {
+											filtered = append(filtered, p)
 										}
-										pointsToForward = filtered
 									}
+									pointsToForward = filtered
 								}
+							}
 
-								if len(pointsToForward) > 0 {
-									// Accumulate points in buffer
-									// Stripping UDPSequence to allow better packing is done implicitly
-									// if the Forwarder chooses to ignore it, but we can help by
-									// aggregating multiple source packets into one Forward call.
-									// However, we must ensure we don't hold them too long.
+							if len(pointsToForward) > 0 {
+								// Accumulate points in buffer
+								// Stripping UDPSequence to allow better packing is done implicitly
+								// if the Forwarder chooses to ignore it, but we can help by
+								// aggregating multiple source packets into one Forward call.
+								// However, we must ensure we don't hold them too long.
 
-									// Clear UDPSequence to aid packing in Forwarder
-									for i := range pointsToForward {
-										pointsToForward[i].UDPSequence = 0
-									}
+								// Clear UDPSequence to aid packing in Forwarder
+								for i := range pointsToForward {
+									pointsToForward[i].UDPSequence = 0
+								}
 
-									foregroundBuffer = append(foregroundBuffer, pointsToForward...)
-									bufferedPackets++
+								foregroundBuffer = append(foregroundBuffer, pointsToForward...)
+								bufferedPackets++
 
-									// Flush if buffer is full or enough distinct packets collected
-									if len(foregroundBuffer) >= maxForegroundBufferPoints || bufferedPackets >= maxForegroundBufferPackets {
-										config.ForegroundForwarder.ForwardForeground(foregroundBuffer)
-										foregroundBuffer = nil // Reallocate or clear? nil lets GC handle old slice
-										foregroundBuffer = make([]l2frames.PointPolar, 0, maxForegroundBufferPoints)
-										bufferedPackets = 0
-									}
+								// Flush if buffer is full or enough distinct packets collected
+								if len(foregroundBuffer) >= maxForegroundBufferPoints || bufferedPackets >= maxForegroundBufferPackets {
+									config.ForegroundForwarder.ForwardForeground(foregroundBuffer)
+									foregroundBuffer = nil // Reallocate or clear? nil lets GC handle old slice
+									foregroundBuffer = make([]l2frames.PointPolar, 0, maxForegroundBufferPoints)
+									bufferedPackets = 0
 								}
+							}
 
-								if packetCount%1000 == 0 {
-									fgRatio := float64(len(foregroundPoints)) / float64(len(points))
-									lidar.Tracef("Foreground extraction: %d/%d points (%.1f%%)",
-										len(foregroundPoints), len(points), fgRatio*100)
-								}
+							if packetCount%1000 == 0 {
+								fgRatio := float64(len(foregroundPoints)) / float64(len(points))
+								lidar.Tracef("Foreground extraction: %d/%d points (%.1f%%)",
+									len(foregroundPoints), len(points), fgRatio*100)
 							}
 						}
+					}
 
-						// Call frame callback for grid sampling (e.g., for plotting)
-						if config.OnFrameCallback != nil {
-							config.OnFrameCallback(config.BackgroundManager, points)
-						}
+					// Call frame callback for grid sampling (e.g., for plotting)
+					if config.OnFrameCallback != nil {
+						config.OnFrameCallback(config.BackgroundManager, points)
 					}
 				}
 			}
+		}
 
-			// Log progress periodically
-			if packetCount%10000 == 0 {
-				elapsed := time.Since(startTime)
-				lidar.Tracef("PCAP real-time replay progress: %d packets in %v (%.0f pkt/s, speed: %.2fx)",
-					packetCount, elapsed, float64(packetCount)/elapsed.Seconds(), config.SpeedMultiplier)
-			}
+		// Log progress periodically
+		if packetCount%10000 == 0 {
+			elapsed := time.Since(startTime)
+			lidar.Tracef("PCAP real-time replay progress: %d packets in %v (%.0f pkt/s, speed: %.2fx)",
+				packetCount, elapsed, float64(packetCount)/elapsed.Seconds(), config.SpeedMultiplier)
 		}
 	}
 }
@@ -0,0 +1,393 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// Ring file naming. The timestamp is the file's first packet time (UTC),
+// so lexical order is chronological.
+const (
+	pcapRingPrefix     = "ring-"
+	pcapRingSuffix     = ".pcap"
+	pcapRingTimeLayout = "20060102T150405.000Z"
+)
+
+// Defaults applied by NewPCAPRingRecorder for zero-valued config fields.
+const (
+	DefaultPCAPRingFileDuration = time.Minute
+	DefaultPCAPRingRetention    = 10 * time.Minute
+	DefaultPCAPRingQueueSize    = 4096 // ~2 s of Pandar40P packets at 10 Hz
+)
+
+// pcapRingFlushInterval bounds how stale the newest file can be on disk,
+// so the last seconds before an event are available immediately.
+const pcapRingFlushInterval = time.Second
+
+// PCAPRingConfig configures a PCAPRingRecorder.
+type PCAPRingConfig struct {
+	Dir string // Output directory; created if missing
+
+	// FileDuration starts a new file after this much wall time.
+	FileDuration time.Duration
+	// MaxFileBytes, when > 0, also starts a new file once the current one
+	// reaches this size.
+	MaxFileBytes int64
+
+	// Retention deletes closed files whose last packet is older than this.
+	Retention time.Duration
+	// MaxTotalBytes, when > 0, additionally deletes the oldest closed
+	// files until the ring fits within this budget.
+	MaxTotalBytes int64
+
+	// DstPort is written as the UDP destination port so replays through
+	// the PCAP reader's port filter work unchanged.
+	DstPort int
+
+	// QueueSize bounds packets awaiting the writer; further packets are
+	// dropped rather than blocking the receive loop.
+	QueueSize int
+}
+
+type ringPacket struct {
+	ts   time.Time
+	src  *net.UDPAddr
+	data []byte
+}
+
+// PCAPRingRecorder tees raw LiDAR UDP packets into a rolling set of PCAP
+// files so the minutes before an interesting event can be pulled after
+// the fact. It sits beside the live pipeline at the packet layer and is
+// independent of the .vrlog recorder. Packets are framed as
+// Ethernet/IPv4/UDP so the files replay through the normal PCAP path.
+//
+// RecordAsync never blocks: packets are queued to a single writer
+// goroutine and dropped if the queue is full (e.g. slow storage).
+type PCAPRingRecorder struct {
+	cfg PCAPRingConfig
+	now func() time.Time
+
+	queue chan ringPacket
+	stop  chan struct{}
+	once  sync.Once
+	wg    sync.WaitGroup
+
+	// writerMu is held for a writer goroutine's lifetime so a restarted
+	// listener's writer waits for the previous one to close its file.
+	writerMu sync.Mutex
+
+	recorded atomic.Uint64
+	dropped  atomic.Uint64
+}
+
+// NewPCAPRingRecorder validates cfg and creates the output directory.
+// Call Start to begin writing.
+func NewPCAPRingRecorder(cfg PCAPRingConfig) (*PCAPRingRecorder, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("pcap ring requires an output directory")
+	}
+	if cfg.FileDuration <= 0 {
+		cfg.FileDuration = DefaultPCAPRingFileDuration
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultPCAPRingRetention
+	}
+	if cfg.Retention < cfg.FileDuration {
+		return nil, fmt.Errorf("pcap ring retention %v is shorter than file duration %v", cfg.Retention, cfg.FileDuration)
+	}
+	if cfg.MaxFileBytes < 0 || cfg.MaxTotalBytes < 0 {
+		return nil, errors.New("pcap ring byte limits must be >= 0")
+	}
+	if cfg.DstPort <= 0 || cfg.DstPort > 65535 {
+		return nil, fmt.Errorf("pcap ring destination port %d out of range", cfg.DstPort)
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultPCAPRingQueueSize
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create pcap ring directory: %w", err)
+	}
+	return &PCAPRingRecorder{
+		cfg:   cfg,
+		now:   time.Now,
+		queue: make(chan ringPacket, cfg.QueueSize),
+		stop:  make(chan struct{}),
+	}, nil
+}
+
+// Start launches the writer goroutine. It stops, flushing and closing the
+// current file, when ctx is cancelled or Close is called; Start may be
+// called again afterwards (the listener restarts on data source changes).
+func (r *PCAPRingRecorder) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.writerMu.Lock()
+		defer r.writerMu.Unlock()
+		r.run(ctx)
+	}()
+	diagf("[PCAPRing] Recording packets to %s (file %v, retention %v)", r.cfg.Dir, r.cfg.FileDuration, r.cfg.Retention)
+}
+
+// RecordAsync queues a copy of packet without blocking.
+func (r *PCAPRingRecorder) RecordAsync(packet []byte, src *net.UDPAddr) {
+	data := make([]byte, len(packet))
+	copy(data, packet)
+	select {
+	case r.queue <- ringPacket{ts: r.now(), src: src, data: data}:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Stats reports packets written and packets dropped because the writer
+// fell behind or failed.
+func (r *PCAPRingRecorder) Stats() (recorded, dropped uint64) {
+	return r.recorded.Load(), r.dropped.Load()
+}
+
+// Close stops the writer and waits for it to close the current file.
+func (r *PCAPRingRecorder) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	r.wg.Wait()
+	return nil
+}
+
+// Files returns the ring's files that may contain packets at or after
+// since, oldest first. Pass the zero time for all files.
+func (r *PCAPRingRecorder) Files(since time.Time) ([]string, error) {
+	files, err := r.listFiles()
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for i, f := range files {
+		// A file spans until the next one starts.
+		if i+1 < len(files) && !since.IsZero() && !files[i+1].start.After(since) {
+			continue
+		}
+		out = append(out, f.path)
+	}
+	return out, nil
+}
+
+// ringWriter is the writer goroutine's state for the open file.
+type ringWriter struct {
+	file    *os.File
+	buf     *bufio.Writer
+	pcap    *pcapgo.Writer
+	path    string
+	started time.Time
+	bytes   int64
+}
+
+func (r *PCAPRingRecorder) run(ctx context.Context) {
+	var w *ringWriter
+	defer func() {
+		// Drain anything already queued so a clean stop loses nothing.
+		for {
+			select {
+			case pkt := <-r.queue:
+				w = r.write(w, pkt)
+			default:
+				r.closeFile(w)
+				return
+			}
+		}
+	}()
+
+	flush := time.NewTicker(pcapRingFlushInterval)
+	defer flush.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stop:
+			return
+		case pkt := <-r.queue:
+			w = r.write(w, pkt)
+		case <-flush.C:
+			if w != nil {
+				if err := w.buf.Flush(); err != nil {
+					opsf("[PCAPRing] flush %s: %v", w.path, err)
+				}
+			}
+		}
+	}
+}
+
+// write appends pkt, rotating first if the current file is full. It
+// returns the (possibly new) open file, or nil if opening failed.
+func (r *PCAPRingRecorder) write(w *ringWriter, pkt ringPacket) *ringWriter {
+	if w != nil && (pkt.ts.Sub(w.started) >= r.cfg.FileDuration ||
+		(r.cfg.MaxFileBytes > 0 && w.bytes >= r.cfg.MaxFileBytes)) {
+		r.closeFile(w)
+		w = nil
+		r.prune(pkt.ts)
+	}
+	if w == nil {
+		var err error
+		if w, err = r.openFile(pkt.ts); err != nil {
+			opsf("[PCAPRing] %v", err)
+			r.dropped.Add(1)
+			return nil
+		}
+	}
+
+	frame, err := r.frame(pkt)
+	if err != nil {
+		opsf("[PCAPRing] encode packet: %v", err)
+		r.dropped.Add(1)
+		return w
+	}
+	ci := gopacket.CaptureInfo{Timestamp: pkt.ts, CaptureLength: len(frame), Length: len(frame)}
+	if err := w.pcap.WritePacket(ci, frame); err != nil {
+		opsf("[PCAPRing] write %s: %v", w.path, err)
+		r.dropped.Add(1)
+		return w
+	}
+	w.bytes += int64(16 + len(frame)) // record header + data
+	r.recorded.Add(1)
+	return w
+}
+
+// frame wraps the UDP payload in synthetic Ethernet/IPv4/UDP headers.
+func (r *PCAPRingRecorder) frame(pkt ringPacket) ([]byte, error) {
+	srcIP := net.IPv4zero.To4()
+	srcPort := r.cfg.DstPort
+	if pkt.src != nil {
+		if ip4 := pkt.src.IP.To4(); ip4 != nil {
+			srcIP = ip4
+		}
+		srcPort = pkt.src.Port
+	}
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0x02, 0, 0, 0, 0, 0x01},
+		DstMAC:       layers.EthernetBroadcast,
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolUDP,
+		SrcIP:    srcIP,
+		DstIP:    net.IPv4bcast.To4(),
+	}
+	udp := &layers.UDP{SrcPort: layers.UDPPort(srcPort), DstPort: layers.UDPPort(r.cfg.DstPort)}
+	if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+		return nil, err
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, eth, ip, udp, gopacket.Payload(pkt.data)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *PCAPRingRecorder) openFile(start time.Time) (*ringWriter, error) {
+	path := filepath.Join(r.cfg.Dir, pcapRingPrefix+start.UTC().Format(pcapRingTimeLayout)+pcapRingSuffix)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("create %s: %w", path, err)
+	}
+	buf := bufio.NewWriterSize(f, 256*1024)
+	pw := pcapgo.NewWriterNanos(buf)
+	if err := pw.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write header %s: %w", path, err)
+	}
+	return &ringWriter{file: f, buf: buf, pcap: pw, path: path, started: start, bytes: 24}, nil
+}
+
+func (r *PCAPRingRecorder) closeFile(w *ringWriter) {
+	if w == nil {
+		return
+	}
+	if err := w.buf.Flush(); err != nil {
+		opsf("[PCAPRing] flush %s: %v", w.path, err)
+	}
+	if err := w.file.Close(); err != nil {
+		opsf("[PCAPRing] close %s: %v", w.path, err)
+	}
+}
+
+type ringFile struct {
+	path  string
+	start time.Time
+	size  int64
+	mtime time.Time
+}
+
+// listFiles returns the ring's files, oldest first.
+func (r *PCAPRingRecorder) listFiles() ([]ringFile, error) {
+	entries, err := os.ReadDir(r.cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []ringFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, pcapRingPrefix) || !strings.HasSuffix(name, pcapRingSuffix) {
+			continue
+		}
+		start, err := time.Parse(pcapRingTimeLayout, strings.TrimSuffix(strings.TrimPrefix(name, pcapRingPrefix), pcapRingSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, ringFile{path: filepath.Join(r.cfg.Dir, name), start: start, size: info.Size(), mtime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].start.Before(files[j].start) })
+	return files, nil
+}
+
+// prune deletes closed files outside the retention window or byte
+// budget. It runs on rotation, before the next file is opened, so every
+// file it sees is closed.
+func (r *PCAPRingRecorder) prune(now time.Time) {
+	files, err := r.listFiles()
+	if err != nil {
+		opsf("[PCAPRing] list %s: %v", r.cfg.Dir, err)
+		return
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	cutoff := now.Add(-r.cfg.Retention)
+	for i, f := range files {
+		// A file's last packet is no later than the next file's start.
+		end := f.mtime
+		if i+1 < len(files) && files[i+1].start.Before(end) {
+			end = files[i+1].start
+		}
+		overBudget := r.cfg.MaxTotalBytes > 0 && total > r.cfg.MaxTotalBytes
+		if !end.Before(cutoff) && !overBudget {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			opsf("[PCAPRing] remove %s: %v", f.path, err)
+			continue
+		}
+		total -= f.size
+		tracef("[PCAPRing] pruned %s", filepath.Base(f.path))
+	}
+}
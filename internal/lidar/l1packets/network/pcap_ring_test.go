@@ -0,0 +1,388 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// fakeClock is a settable clock for driving ring rotation in tests.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func newTestRing(t *testing.T, cfg PCAPRingConfig) (*PCAPRingRecorder, *fakeClock) {
+	t.Helper()
+	if cfg.Dir == "" {
+		cfg.Dir = filepath.Join(t.TempDir(), "ring")
+	}
+	if cfg.DstPort == 0 {
+		cfg.DstPort = 2369
+	}
+	r, err := NewPCAPRingRecorder(cfg)
+	if err != nil {
+		t.Fatalf("NewPCAPRingRecorder: %v", err)
+	}
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	r.now = clock.Now
+	return r, clock
+}
+
+// waitRecorded polls until the writer has written n packets.
+func waitRecorded(t *testing.T, r *PCAPRingRecorder, n uint64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if recorded, _ := r.Stats(); recorded >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	recorded, dropped := r.Stats()
+	t.Fatalf("timeout waiting for %d packets: recorded=%d dropped=%d", n, recorded, dropped)
+}
+
+type ringUDP struct {
+	ts      time.Time
+	srcIP   net.IP
+	srcPort int
+	dstPort int
+	payload []byte
+}
+
+func readRingFile(t *testing.T, path string) []ringUDP {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	rd, err := pcapgo.NewReader(f)
+	if err != nil {
+		t.Fatalf("pcap reader %s: %v", path, err)
+	}
+	if rd.LinkType() != layers.LinkTypeEthernet {
+		t.Fatalf("link type = %v, want Ethernet", rd.LinkType())
+	}
+	var out []ringUDP
+	for {
+		data, ci, err := rd.ReadPacketData()
+		if err != nil {
+			break
+		}
+		pkt := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+		ip, _ := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+		udp, _ := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+		if ip == nil || udp == nil {
+			t.Fatalf("packet in %s is not IPv4/UDP", path)
+		}
+		out = append(out, ringUDP{
+			ts:      ci.Timestamp,
+			srcIP:   ip.SrcIP,
+			srcPort: int(udp.SrcPort),
+			dstPort: int(udp.DstPort),
+			payload: udp.Payload,
+		})
+	}
+	return out
+}
+
+func TestPCAPRingRecorder_WritesReplayablePackets(t *testing.T) {
+	r, clock := newTestRing(t, PCAPRingConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Start(ctx)
+
+	src := &net.UDPAddr{IP: net.ParseIP("192.168.1.201"), Port: 10000}
+	packet := []byte("lidar-packet-0")
+	r.RecordAsync(packet, src)
+	// The recorder must copy the packet: the listener reuses its buffer.
+	copy(packet, "XXXXXXXXXXXXXX")
+	clock.Advance(100 * time.Millisecond)
+	r.RecordAsync([]byte("lidar-packet-1"), nil)
+	waitRecorded(t, r, 2)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	files, err := r.Files(time.Time{})
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	if got, want := filepath.Base(files[0]), "ring-20260301T120000.000Z.pcap"; got != want {
+		t.Errorf("file name = %q, want %q", got, want)
+	}
+	pkts := readRingFile(t, files[0])
+	if len(pkts) != 2 {
+		t.Fatalf("read %d packets, want 2", len(pkts))
+	}
+	if !bytes.Equal(pkts[0].payload, []byte("lidar-packet-0")) || !bytes.Equal(pkts[1].payload, []byte("lidar-packet-1")) {
+		t.Errorf("payloads = %q, %q", pkts[0].payload, pkts[1].payload)
+	}
+	if !pkts[0].srcIP.Equal(src.IP) || pkts[0].srcPort != 10000 {
+		t.Errorf("source = %v:%d, want %v", pkts[0].srcIP, pkts[0].srcPort, src)
+	}
+	if pkts[0].dstPort != 2369 || pkts[1].dstPort != 2369 {
+		t.Errorf("destination ports = %d, %d, want 2369", pkts[0].dstPort, pkts[1].dstPort)
+	}
+	if got := pkts[1].ts.Sub(pkts[0].ts); got != 100*time.Millisecond {
+		t.Errorf("timestamp delta = %v, want 100ms", got)
+	}
+}
+
+func TestPCAPRingRecorder_RotatesAndPrunes(t *testing.T) {
+	r, clock := newTestRing(t, PCAPRingConfig{
+		FileDuration: time.Minute,
+		Retention:    2 * time.Minute,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Start(ctx)
+
+	// One packet per minute for five minutes: each opens a new file.
+	for i := 0; i < 5; i++ {
+		r.RecordAsync([]byte{byte(i)}, nil)
+		waitRecorded(t, r, uint64(i+1))
+		// Closed files' mtimes follow the fake clock so retention can
+		// be evaluated against it.
+		files, _ := r.listFiles()
+		for _, f := range files[:len(files)-1] {
+			os.Chtimes(f.path, f.start, f.start.Add(time.Second))
+		}
+		clock.Advance(time.Minute)
+	}
+	r.Close()
+
+	files, err := r.Files(time.Time{})
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	// At the last rotation (12:04) files ending before 12:02 are gone:
+	// 12:00 and 12:01 are pruned, 12:02 and 12:03 kept, 12:04 is open.
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	want := []string{
+		"ring-20260301T120200.000Z.pcap",
+		"ring-20260301T120300.000Z.pcap",
+		"ring-20260301T120400.000Z.pcap",
+	}
+	if len(names) != len(want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("files[%d] = %s, want %s", i, names[i], want[i])
+		}
+	}
+
+	// Files(since) skips files that end before since.
+	recent, err := r.Files(time.Date(2026, 3, 1, 12, 3, 30, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Files(since): %v", err)
+	}
+	if len(recent) != 2 || filepath.Base(recent[0]) != want[1] {
+		t.Errorf("Files(12:03:30) = %v, want last two files", recent)
+	}
+}
+
+func TestPCAPRingRecorder_ByteLimits(t *testing.T) {
+	payload := bytes.Repeat([]byte{0xAB}, 1000)
+	r, clock := newTestRing(t, PCAPRingConfig{
+		FileDuration:  time.Hour,
+		Retention:     time.Hour,
+		MaxFileBytes:  2000, // rotates after two 1 kB packets
+		MaxTotalBytes: 5000, // two full files kept
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Start(ctx)
+
+	for i := 0; i < 10; i++ {
+		r.RecordAsync(payload, nil)
+		clock.Advance(time.Millisecond)
+	}
+	waitRecorded(t, r, 10)
+	r.Close()
+
+	files, err := r.listFiles()
+	if err != nil {
+		t.Fatalf("listFiles: %v", err)
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+		if f.size > 2000+1100 {
+			t.Errorf("%s is %d bytes, exceeds file limit by more than one packet", filepath.Base(f.path), f.size)
+		}
+	}
+	// The open file is not counted against the budget until it closes.
+	if len(files) != 3 {
+		t.Errorf("got %d files, want 3", len(files))
+	}
+	if total > 5000+2000+1100 {
+		t.Errorf("ring holds %d bytes, over budget", total)
+	}
+	// The newest packets survive.
+	last := readRingFile(t, files[len(files)-1].path)
+	if len(last) == 0 || !bytes.Equal(last[len(last)-1].payload, payload) {
+		t.Error("newest file does not end with the last packet")
+	}
+}
+
+func TestPCAPRingRecorder_DropsWhenQueueFull(t *testing.T) {
+	r, _ := newTestRing(t, PCAPRingConfig{QueueSize: 2})
+	// Not started: nothing drains the queue, and RecordAsync must not block.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			r.RecordAsync([]byte{byte(i)}, nil)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RecordAsync blocked on a full queue")
+	}
+	if recorded, dropped := r.Stats(); recorded != 0 || dropped != 3 {
+		t.Errorf("recorded=%d dropped=%d, want 0, 3", recorded, dropped)
+	}
+
+	// Queued packets are still written when the writer stops.
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx)
+	cancel()
+	r.Close()
+	if recorded, _ := r.Stats(); recorded != 2 {
+		t.Errorf("recorded = %d after drain, want 2", recorded)
+	}
+}
+
+func TestPCAPRingRecorder_RestartContinues(t *testing.T) {
+	r, clock := newTestRing(t, PCAPRingConfig{})
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	r.Start(ctx1)
+	r.RecordAsync([]byte("a"), nil)
+	waitRecorded(t, r, 1)
+	cancel1()
+
+	// A listener restart starts a fresh writer on the same recorder.
+	clock.Advance(time.Second)
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	r.Start(ctx2)
+	r.RecordAsync([]byte("b"), nil)
+	waitRecorded(t, r, 2)
+	r.Close()
+
+	files, err := r.Files(time.Time{})
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	var n int
+	for _, f := range files {
+		n += len(readRingFile(t, f))
+	}
+	if n != 2 {
+		t.Errorf("read %d packets across %d files, want 2", n, len(files))
+	}
+}
+
+func TestNewPCAPRingRecorder_Validation(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]PCAPRingConfig{
+		"no dir":          {DstPort: 2369},
+		"bad port":        {Dir: dir, DstPort: 70000},
+		"short retention": {Dir: dir, DstPort: 2369, FileDuration: time.Minute, Retention: time.Second},
+		"negative bytes":  {Dir: dir, DstPort: 2369, MaxTotalBytes: -1},
+	}
+	for name, cfg := range cases {
+		if _, err := NewPCAPRingRecorder(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	r, err := NewPCAPRingRecorder(PCAPRingConfig{Dir: filepath.Join(dir, "a", "b"), DstPort: 2369})
+	if err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+	if r.cfg.FileDuration != DefaultPCAPRingFileDuration || r.cfg.Retention != DefaultPCAPRingRetention || cap(r.queue) != DefaultPCAPRingQueueSize {
+		t.Errorf("defaults not applied: %+v", r.cfg)
+	}
+	if _, err := os.Stat(r.cfg.Dir); err != nil {
+		t.Errorf("directory not created: %v", err)
+	}
+}
+
+func TestUDPListener_TeesToPCAPRing(t *testing.T) {
+	src := &net.UDPAddr{IP: net.ParseIP("192.168.1.201"), Port: 2368}
+	mockSocket := NewMockUDPSocket([]MockUDPPacket{
+		{Data: []byte("packet1"), Addr: src},
+		{Data: []byte("packet2"), Addr: src},
+	})
+	stats := &MockFullPacketStats{}
+	ring, _ := newTestRing(t, PCAPRingConfig{DstPort: 2368})
+
+	listener := NewUDPListener(UDPListenerConfig{
+		Address:       "127.0.0.1:2368",
+		RcvBuf:        65536,
+		SocketFactory: NewMockUDPSocketFactory(mockSocket),
+		Stats:         stats,
+		PCAPRing:      ring,
+		LogInterval:   time.Hour,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- listener.Start(ctx)
+	}()
+	waitRecorded(t, ring, 2)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("listener did not stop")
+	}
+	ring.Close()
+
+	// Both the ring and the live path saw the packets.
+	if stats.GetPacketCount() < 2 {
+		t.Errorf("live path saw %d packets, want 2", stats.GetPacketCount())
+	}
+	files, err := ring.Files(time.Time{})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Files = %v, %v", files, err)
+	}
+	pkts := readRingFile(t, files[0])
+	if len(pkts) != 2 || string(pkts[1].payload) != "packet2" {
+		t.Errorf("ring contents = %+v", pkts)
+	}
+}
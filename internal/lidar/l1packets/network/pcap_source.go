@@ -0,0 +1,88 @@
+//go:build pcap
+// +build pcap
+
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// PCAPPacketSource reads the UDP payloads sent to one port from a capture
+// file, stamped with their capture times. Compressed captures are streamed
+// as by ReadPCAPFile. This type is only available when building with the
+// 'pcap' build tag.
+type PCAPPacketSource struct {
+	handle  pcapSource
+	packets *gopacket.PacketSource
+	port    int
+}
+
+// OpenPCAPPacketSource opens pcapFile for reading. udpPort is resolved as
+// for ReadPCAPFile, so 0 auto-detects the LiDAR port.
+func OpenPCAPPacketSource(pcapFile string, udpPort int) (*PCAPPacketSource, error) {
+	handle, err := openPCAPSource(pcapFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PCAP file %s: %w", pcapFile, err)
+	}
+
+	udpPort, err = ResolvePCAPPort(pcapFile, udpPort)
+	if err != nil {
+		handle.Close()
+		return nil, err
+	}
+
+	// Set BPF filter to only capture UDP packets on the specified port
+	filterStr := fmt.Sprintf("udp port %d", udpPort)
+	if err := handle.SetBPFFilter(filterStr); err != nil {
+		handle.Close()
+		return nil, fmt.Errorf("failed to set BPF filter '%s': %w", filterStr, err)
+	}
+	diagf("PCAP BPF filter set: %s", filterStr)
+
+	return &PCAPPacketSource{
+		handle:  handle,
+		packets: gopacket.NewPacketSource(handle, handle.LinkType()),
+		port:    udpPort,
+	}, nil
+}
+
+// Port returns the UDP port being read, after auto-detection.
+func (s *PCAPPacketSource) Port() int {
+	return s.port
+}
+
+// NextPacket returns the next UDP payload on the port. Empty payloads are
+// returned too, so packet indices match CountPCAPPackets. A truncated final
+// record ends the capture like io.EOF.
+func (s *PCAPPacketSource) NextPacket(ctx context.Context) ([]byte, time.Time, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, time.Time{}, err
+		}
+		packet, err := s.packets.NextPacket()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, time.Time{}, io.EOF
+		}
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("read PCAP packet: %w", err)
+		}
+		udp, ok := packet.Layer(layers.LayerTypeUDP).(*layers.UDP)
+		if !ok {
+			continue // Skip non-UDP packets (shouldn't happen with BPF filter)
+		}
+		return udp.Payload, packet.Metadata().Timestamp, nil
+	}
+}
+
+// Close closes the capture.
+func (s *PCAPPacketSource) Close() error {
+	s.handle.Close()
+	return nil
+}
@@ -0,0 +1,30 @@
+//go:build !pcap
+// +build !pcap
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PCAPPacketSource is a stub when PCAP support is disabled.
+// Build with -tags=pcap to enable PCAP file reading
+type PCAPPacketSource struct{}
+
+// OpenPCAPPacketSource is a stub implementation when PCAP support is disabled
+func OpenPCAPPacketSource(pcapFile string, udpPort int) (*PCAPPacketSource, error) {
+	return nil, fmt.Errorf("PCAP support not enabled: rebuild with -tags=pcap to enable PCAP file reading")
+}
+
+// Port returns 0.
+func (s *PCAPPacketSource) Port() int { return 0 }
+
+// NextPacket always fails.
+func (s *PCAPPacketSource) NextPacket(ctx context.Context) ([]byte, time.Time, error) {
+	return nil, time.Time{}, fmt.Errorf("PCAP support not enabled")
+}
+
+// Close does nothing.
+func (s *PCAPPacketSource) Close() error { return nil }
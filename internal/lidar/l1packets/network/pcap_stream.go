@@ -0,0 +1,187 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/klauspost/compress/zstd"
+)
+
+// PCAP compression formats recognised by DetectPCAPCompression.
+const (
+	PCAPCompressionNone = ""
+	PCAPCompressionGzip = "gzip"
+	PCAPCompressionZstd = "zstd"
+)
+
+var (
+	gzipMagic   = []byte{0x1f, 0x8b}
+	zstdMagic   = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+)
+
+// pcapCompressionExts maps compressed-capture suffixes to their format.
+var pcapCompressionExts = map[string]string{
+	".gz":  PCAPCompressionGzip,
+	".zst": PCAPCompressionZstd,
+}
+
+// IsPCAPFileName reports whether name looks like a capture the PCAP reader
+// can open: .pcap or .pcapng, optionally compressed as .gz or .zst.
+func IsPCAPFileName(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if _, ok := pcapCompressionExts[ext]; ok {
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(name, filepath.Ext(name))))
+	}
+	return ext == ".pcap" || ext == ".pcapng"
+}
+
+// PCAPBaseName returns the file name of path without its capture and
+// compression extensions, e.g. "capture" for "dir/capture.pcap.gz".
+func PCAPBaseName(path string) string {
+	base := filepath.Base(path)
+	if _, ok := pcapCompressionExts[strings.ToLower(filepath.Ext(base))]; ok {
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// DetectPCAPCompression reports how the file at path is compressed. The
+// file's magic bytes decide; a compression extension on a file without the
+// matching magic is an error, as the file is probably truncated or mislabelled.
+func DetectPCAPCompression(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return PCAPCompressionNone, err
+	}
+	defer f.Close()
+	head := make([]byte, len(zstdMagic))
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return PCAPCompressionNone, err
+	}
+	return detectCompression(path, head[:n])
+}
+
+func detectCompression(path string, head []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return PCAPCompressionGzip, nil
+	case bytes.HasPrefix(head, zstdMagic):
+		return PCAPCompressionZstd, nil
+	}
+	if format, ok := pcapCompressionExts[strings.ToLower(filepath.Ext(path))]; ok {
+		return PCAPCompressionNone, fmt.Errorf("%s has a %s extension but no %s header", path, filepath.Ext(path), format)
+	}
+	return PCAPCompressionNone, nil
+}
+
+// pcapPacketReader is satisfied by pcapgo.Reader and pcapgo.NgReader.
+type pcapPacketReader interface {
+	ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	LinkType() layers.LinkType
+}
+
+// PCAPStreamReader reads a pcap or pcapng capture, decompressing gzip or
+// zstd on the fly so multi-gigabyte archives are never expanded on disk.
+// It implements gopacket.PacketDataSource and reads strictly sequentially.
+type PCAPStreamReader struct {
+	file        *os.File
+	decoder     io.Closer // nil for uncompressed files
+	reader      pcapPacketReader
+	compression string
+	filter      func(gopacket.CaptureInfo, []byte) bool
+}
+
+// OpenPCAPStream opens path, which may be gzip or zstd compressed.
+func OpenPCAPStream(path string) (*PCAPStreamReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &PCAPStreamReader{file: f}
+	if err := s.init(path); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("open PCAP stream %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *PCAPStreamReader) init(path string) error {
+	raw := bufio.NewReaderSize(s.file, 1<<20)
+	head, err := raw.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if s.compression, err = detectCompression(path, head); err != nil {
+		return err
+	}
+
+	var data io.Reader = raw
+	switch s.compression {
+	case PCAPCompressionGzip:
+		gz, err := gzip.NewReader(raw)
+		if err != nil {
+			return err
+		}
+		s.decoder = gz
+		data = gz
+	case PCAPCompressionZstd:
+		zr, err := zstd.NewReader(raw)
+		if err != nil {
+			return err
+		}
+		s.decoder = zr.IOReadCloser()
+		data = zr
+	}
+
+	// pcapgo reads the file header itself, so peek to choose the format.
+	buffered := bufio.NewReader(data)
+	magic, err := buffered.Peek(len(pcapngMagic))
+	if err != nil {
+		return fmt.Errorf("read capture header: %w", err)
+	}
+	if bytes.Equal(magic, pcapngMagic) {
+		s.reader, err = pcapgo.NewNgReader(buffered, pcapgo.DefaultNgReaderOptions)
+	} else {
+		s.reader, err = pcapgo.NewReader(buffered)
+	}
+	return err
+}
+
+// ReadPacketData returns the next packet that passes the filter, if any.
+func (s *PCAPStreamReader) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	for {
+		data, ci, err := s.reader.ReadPacketData()
+		if err != nil || s.filter == nil || s.filter(ci, data) {
+			return data, ci, err
+		}
+	}
+}
+
+// LinkType returns the capture's link-layer type.
+func (s *PCAPStreamReader) LinkType() layers.LinkType {
+	return s.reader.LinkType()
+}
+
+// Compression returns the detected compression format.
+func (s *PCAPStreamReader) Compression() string {
+	return s.compression
+}
+
+// Close releases the decompressor and the underlying file.
+func (s *PCAPStreamReader) Close() {
+	if s.decoder != nil {
+		s.decoder.Close()
+	}
+	s.file.Close()
+}
@@ -0,0 +1,175 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// Fixtures: the same 40 Ethernet/IPv4/UDP packets, 35 to port 2369 and 5
+// to port 53, stored plain, gzipped, and as zstd-compressed pcapng.
+const (
+	streamFixturePlain = "testdata/udp_2369.pcap"
+	streamFixtureGzip  = "testdata/udp_2369.pcap.gz"
+	streamFixtureZstd  = "testdata/udp_2369.pcapng.zst"
+)
+
+type streamPacket struct {
+	ci   gopacket.CaptureInfo
+	data []byte
+}
+
+func readStream(t *testing.T, path string, filter func(gopacket.CaptureInfo, []byte) bool) []streamPacket {
+	t.Helper()
+	s, err := OpenPCAPStream(path)
+	if err != nil {
+		t.Fatalf("OpenPCAPStream(%s): %v", path, err)
+	}
+	defer s.Close()
+	if s.LinkType() != layers.LinkTypeEthernet {
+		t.Fatalf("%s: link type = %v", path, s.LinkType())
+	}
+	s.filter = filter
+	var out []streamPacket
+	for {
+		data, ci, err := s.ReadPacketData()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("%s: read packet %d: %v", path, len(out), err)
+		}
+		out = append(out, streamPacket{ci: ci, data: append([]byte(nil), data...)})
+	}
+}
+
+func TestOpenPCAPStream_CompressedMatchesPlain(t *testing.T) {
+	want := readStream(t, streamFixturePlain, nil)
+	if len(want) != 40 {
+		t.Fatalf("plain fixture has %d packets, want 40", len(want))
+	}
+	for _, path := range []string{streamFixtureGzip, streamFixtureZstd} {
+		got := readStream(t, path, nil)
+		if len(got) != len(want) {
+			t.Fatalf("%s: %d packets, want %d", path, len(got), len(want))
+		}
+		for i := range want {
+			if !got[i].ci.Timestamp.Equal(want[i].ci.Timestamp) || !bytes.Equal(got[i].data, want[i].data) {
+				t.Fatalf("%s: packet %d differs from uncompressed capture", path, i)
+			}
+		}
+	}
+}
+
+func TestOpenPCAPStream_Filter(t *testing.T) {
+	port2369 := func(_ gopacket.CaptureInfo, data []byte) bool {
+		pkt := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.NoCopy)
+		udp, _ := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+		return udp != nil && udp.DstPort == 2369
+	}
+	plain := readStream(t, streamFixturePlain, port2369)
+	gz := readStream(t, streamFixtureGzip, port2369)
+	if len(plain) != 35 || len(gz) != 35 {
+		t.Errorf("filtered packets: plain=%d gzip=%d, want 35", len(plain), len(gz))
+	}
+}
+
+func TestDetectPCAPCompression(t *testing.T) {
+	for path, want := range map[string]string{
+		streamFixturePlain: PCAPCompressionNone,
+		streamFixtureGzip:  PCAPCompressionGzip,
+		streamFixtureZstd:  PCAPCompressionZstd,
+	} {
+		got, err := DetectPCAPCompression(path)
+		if err != nil || got != want {
+			t.Errorf("DetectPCAPCompression(%s) = %q, %v; want %q", path, got, err, want)
+		}
+	}
+
+	// Magic bytes win over the extension...
+	dir := t.TempDir()
+	plain, err := os.ReadFile(streamFixturePlain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzData, err := os.ReadFile(streamFixtureGzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := filepath.Join(dir, "archive.pcap")
+	if err := os.WriteFile(renamed, gzData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := DetectPCAPCompression(renamed); got != PCAPCompressionGzip {
+		t.Errorf("gzip data named .pcap detected as %q", got)
+	}
+	// ...and a compression extension without its magic is rejected.
+	mislabelled := filepath.Join(dir, "capture.pcap.gz")
+	if err := os.WriteFile(mislabelled, plain, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DetectPCAPCompression(mislabelled); err == nil {
+		t.Error("expected error for .gz file without gzip header")
+	}
+	if _, err := OpenPCAPStream(mislabelled); err == nil {
+		t.Error("expected OpenPCAPStream error for .gz file without gzip header")
+	}
+	if _, err := DetectPCAPCompression(filepath.Join(dir, "missing.pcap")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file error = %v", err)
+	}
+}
+
+func TestOpenPCAPStream_TruncatedGzip(t *testing.T) {
+	gzData, err := os.ReadFile(streamFixtureGzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "truncated.pcap.gz")
+	if err := os.WriteFile(path, gzData[:len(gzData)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenPCAPStream(path)
+	if err != nil {
+		t.Fatalf("OpenPCAPStream: %v", err)
+	}
+	defer s.Close()
+	for {
+		if _, _, err = s.ReadPacketData(); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		t.Error("truncated gzip stream ended cleanly; want a read error")
+	}
+}
+
+func TestPCAPFileNames(t *testing.T) {
+	for name, want := range map[string]bool{
+		"a.pcap":        true,
+		"a.PCAPNG":      true,
+		"a.pcap.gz":     true,
+		"a.pcapng.zst":  true,
+		"a.gz":          false,
+		"a.txt":         false,
+		"a.pcap.tar.gz": false,
+	} {
+		if got := IsPCAPFileName(name); got != want {
+			t.Errorf("IsPCAPFileName(%q) = %v, want %v", name, got, want)
+		}
+	}
+	for path, want := range map[string]string{
+		"dir/capture.pcap":        "capture",
+		"dir/capture.pcap.gz":     "capture",
+		"capture.2026.pcapng.zst": "capture.2026",
+	} {
+		if got := PCAPBaseName(path); got != want {
+			t.Errorf("PCAPBaseName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
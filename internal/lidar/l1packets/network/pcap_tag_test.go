@@ -5,6 +5,8 @@ package network
 
 import (
 	"context"
+	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -85,6 +87,37 @@ func TestCountPCAPPackets_Errors(t *testing.T) {
 	}
 }
 
+func TestReadPCAPFile_WithTag_CompressedMatchesPlain(t *testing.T) {
+	run := func(path string) (PCAPCountResult, *replayTestParser, int) {
+		t.Helper()
+		count, err := CountPCAPPackets(path, 2369)
+		if err != nil {
+			t.Fatalf("CountPCAPPackets(%s): %v", path, err)
+		}
+		parser := &replayTestParser{}
+		stats := &MockFullPacketStats{}
+		if err := ReadPCAPFile(context.Background(), path, 2369, parser, &MockFrameBuilder{}, stats, nil, 0, -1, 0, count.Count, nil); err != nil {
+			t.Fatalf("ReadPCAPFile(%s): %v", path, err)
+		}
+		return count, parser, stats.GetPacketCount()
+	}
+
+	wantCount, wantParser, wantPackets := run(streamFixturePlain)
+	if wantCount.Count != 35 {
+		t.Fatalf("plain fixture matched %d packets on port 2369, want 35", wantCount.Count)
+	}
+	for _, path := range []string{streamFixtureGzip, streamFixtureZstd} {
+		count, parser, packets := run(path)
+		if count != wantCount {
+			t.Errorf("%s: count %+v, want %+v", path, count, wantCount)
+		}
+		if parser.parseCalls != wantParser.parseCalls || !parser.lastTS.Equal(wantParser.lastTS) || packets != wantPackets {
+			t.Errorf("%s: parsed %d packets (last %v, stats %d), want %d (last %v, stats %d)",
+				path, parser.parseCalls, parser.lastTS, packets, wantParser.parseCalls, wantParser.lastTS, wantPackets)
+		}
+	}
+}
+
 func TestReadPCAPFile_WithTag_Success(t *testing.T) {
 	parser := &replayTestParser{motorSpeed: 600}
 	frameBuilder := &MockFrameBuilder{}
@@ -257,3 +290,43 @@ func TestReadPCAPFileRealtime_WithTag_ErrorsAndCancel(t *testing.T) {
 		t.Fatal("expected BPF filter error for invalid UDP port")
 	}
 }
+
+func TestPCAPPacketSource_WithTag(t *testing.T) {
+	want := readStream(t, streamFixturePlain, nil)
+	src, err := OpenPCAPPacketSource(streamFixtureZstd, 2369)
+	if err != nil {
+		t.Fatalf("OpenPCAPPacketSource: %v", err)
+	}
+	defer src.Close()
+
+	var n int
+	var first time.Time
+	for {
+		payload, ts, err := src.NextPacket(context.Background())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPacket %d: %v", n, err)
+		}
+		if n == 0 {
+			first = ts
+		}
+		if len(payload) == 0 {
+			t.Errorf("packet %d has an empty payload", n)
+		}
+		n++
+	}
+	if n != 35 {
+		t.Errorf("read %d packets on port 2369, want 35", n)
+	}
+	if !first.Equal(want[0].ci.Timestamp) {
+		t.Errorf("first timestamp %v, want capture time %v", first, want[0].ci.Timestamp)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := src.NextPacket(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled NextPacket err = %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package network
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/banshee-data/velocity.report/internal/config"
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+// RangeWindow is the accepted return distance in metres. A zero MinM or
+// MaxM leaves that side open.
+type RangeWindow struct {
+	MinM float64
+	MaxM float64
+}
+
+// Validate checks the bounds are non-negative and ordered.
+func (w RangeWindow) Validate() error {
+	if w.MinM < 0 || w.MaxM < 0 {
+		return fmt.Errorf("range bounds must be >= 0, got [%v, %v]", w.MinM, w.MaxM)
+	}
+	if w.MaxM > 0 && w.MinM >= w.MaxM {
+		return fmt.Errorf("range minimum %v must be below maximum %v", w.MinM, w.MaxM)
+	}
+	return nil
+}
+
+// RangeClipConfig drops returns outside a distance window before frame
+// assembly: near returns off the sensor dome and far returns beyond the
+// area of interest. PerRing overrides Global for individual rings, keyed by
+// the 1-based channel number; a zero bound in a ring window inherits the
+// Global bound.
+type RangeClipConfig struct {
+	Global  RangeWindow
+	PerRing map[int]RangeWindow
+}
+
+// Enabled reports whether any bound is configured.
+func (c RangeClipConfig) Enabled() bool {
+	if c.Global != (RangeWindow{}) {
+		return true
+	}
+	for _, w := range c.PerRing {
+		if w != (RangeWindow{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the global window and every resolved ring window.
+func (c RangeClipConfig) Validate() error {
+	if err := c.Global.Validate(); err != nil {
+		return fmt.Errorf("global %w", err)
+	}
+	for ring := range c.PerRing {
+		if ring < 1 {
+			return fmt.Errorf("ring range clip: channel numbers start at 1, got %d", ring)
+		}
+		if err := c.windowFor(ring).Validate(); err != nil {
+			return fmt.Errorf("ring %d %w", ring, err)
+		}
+	}
+	return nil
+}
+
+// windowFor returns the window for a ring with Global filled in.
+func (c RangeClipConfig) windowFor(ring int) RangeWindow {
+	w, ok := c.PerRing[ring]
+	if !ok {
+		return c.Global
+	}
+	if w.MinM == 0 {
+		w.MinM = c.Global.MinM
+	}
+	if w.MaxM == 0 {
+		w.MaxM = c.Global.MaxM
+	}
+	return w
+}
+
+// RangeClipConfigFromTuning builds the range windows from the L1 tuning
+// keys min_range_metres, max_range_metres and ring_range_clip.
+func RangeClipConfigFromTuning(l1 config.L1Config) RangeClipConfig {
+	cfg := RangeClipConfig{
+		Global:  RangeWindow{MinM: l1.MinRangeMetres, MaxM: l1.MaxRangeMetres},
+		PerRing: make(map[int]RangeWindow, len(l1.RingRangeClip)),
+	}
+	for ring, w := range l1.RingRangeClip {
+		cfg.PerRing[ring] = RangeWindow{MinM: w.MinMetres, MaxM: w.MaxMetres}
+	}
+	return cfg
+}
+
+// RangeClipper is a FrameBuilder that drops returns outside the configured
+// range windows before forwarding each batch to the wrapped FrameBuilder.
+// Clipped points never reach frame assembly, background learning or
+// clustering.
+type RangeClipper struct {
+	next   FrameBuilder
+	global RangeWindow
+	rings  []RangeWindow // indexed by channel; rings beyond the slice use global
+
+	near atomic.Int64
+	far  atomic.Int64
+}
+
+// NewRangeClipper wraps next with the given range windows.
+func NewRangeClipper(next FrameBuilder, config RangeClipConfig) (*RangeClipper, error) {
+	if next == nil {
+		return nil, fmt.Errorf("range clipper requires a downstream frame builder")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	maxRing := 0
+	for ring := range config.PerRing {
+		maxRing = max(maxRing, ring)
+	}
+	rings := make([]RangeWindow, maxRing+1)
+	for ring := range rings {
+		rings[ring] = config.windowFor(ring)
+	}
+	return &RangeClipper{next: next, global: config.Global, rings: rings}, nil
+}
+
+// AddPointsPolar forwards the in-window points. The input slice is not
+// modified; a batch with nothing clipped is forwarded as is.
+func (c *RangeClipper) AddPointsPolar(points []l2frames.PointPolar) {
+	var out []l2frames.PointPolar
+	for i, p := range points {
+		w := c.global
+		if p.Channel >= 0 && p.Channel < len(c.rings) {
+			w = c.rings[p.Channel]
+		}
+		keep := true
+		switch {
+		case w.MinM > 0 && p.Distance < w.MinM:
+			c.near.Add(1)
+			keep = false
+		case w.MaxM > 0 && p.Distance > w.MaxM:
+			c.far.Add(1)
+			keep = false
+		}
+		if out == nil && !keep {
+			// First clipped point: copy the kept prefix.
+			out = make([]l2frames.PointPolar, i, len(points))
+			copy(out, points[:i])
+		} else if out != nil && keep {
+			out = append(out, p)
+		}
+	}
+	if out == nil {
+		out = points
+	}
+	c.next.AddPointsPolar(out)
+}
+
+// SetMotorSpeed forwards to the wrapped FrameBuilder.
+func (c *RangeClipper) SetMotorSpeed(rpm uint16) {
+	c.next.SetMotorSpeed(rpm)
+}
+
+// Counts returns the number of points dropped below and above the windows.
+func (c *RangeClipper) Counts() (near, far int64) {
+	return c.near.Load(), c.far.Load()
+}
@@ -0,0 +1,193 @@
+package network
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/config"
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+// clipTestRotation returns one rotation cycling through dome reflections
+// (0.4 m), in-window returns (10 m and 20 m) and far returns (95 m).
+func clipTestRotation() []l2frames.PointPolar {
+	distances := []float64{0.4, 10, 20, 95}
+	start := time.Now().UnixNano()
+	var points []l2frames.PointPolar
+	for i := 0; i < 1800; i++ {
+		points = append(points, l2frames.PointPolar{
+			Channel:   i%40 + 1,
+			Azimuth:   float64(i) * 0.2,
+			Distance:  distances[i%len(distances)],
+			Intensity: 50,
+			Timestamp: start + int64(i)*int64(time.Microsecond),
+		})
+	}
+	return points
+}
+
+func TestRangeClipper_ClippedPointsNeverReachFrames(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		frames []*l2frames.LiDARFrame
+	)
+	fb := l2frames.NewFrameBuilderDI(l2frames.FrameBuilderConfig{
+		SensorID:       "range-clip-test",
+		MinFramePoints: 1,
+		BufferTimeout:  time.Hour,
+		FrameCallback: func(f *l2frames.LiDARFrame) {
+			mu.Lock()
+			defer mu.Unlock()
+			frames = append(frames, f)
+		},
+	})
+
+	// Ring 3 sits closest to the dome seam, so it also drops the 10 m returns.
+	cfg := RangeClipConfig{
+		Global:  RangeWindow{MinM: 1, MaxM: 80},
+		PerRing: map[int]RangeWindow{3: {MinM: 15}},
+	}
+	clipper, err := NewRangeClipper(fb, cfg)
+	if err != nil {
+		t.Fatalf("NewRangeClipper: %v", err)
+	}
+
+	input := clipTestRotation()
+	wantKept := 0
+	for _, p := range input {
+		if w := cfg.windowFor(p.Channel); p.Distance >= w.MinM && p.Distance <= w.MaxM {
+			wantKept++
+		}
+	}
+	// Packets arrive in small batches.
+	for i := 0; i < len(input); i += 40 {
+		clipper.AddPointsPolar(input[i : i+40])
+	}
+	fb.Flush()
+	fb.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(frames) == 0 {
+		t.Fatal("expected a frame")
+	}
+	kept := 0
+	for _, f := range frames {
+		for _, p := range f.PolarPoints {
+			w := cfg.windowFor(p.Channel)
+			if p.Distance < w.MinM || p.Distance > w.MaxM {
+				t.Fatalf("ring %d point at %.1f m reached the frame; window is [%v, %v]",
+					p.Channel, p.Distance, w.MinM, w.MaxM)
+			}
+			kept++
+		}
+	}
+	if kept != wantKept {
+		t.Errorf("frames hold %d points, want all %d in-window points", kept, wantKept)
+	}
+
+	near, far := clipper.Counts()
+	if int(near+far) != len(input)-wantKept {
+		t.Errorf("counts near=%d far=%d, want %d clipped in total", near, far, len(input)-wantKept)
+	}
+	if far != int64(len(input)/4) {
+		t.Errorf("far = %d, want every 95 m return (%d)", far, len(input)/4)
+	}
+}
+
+func TestRangeClipper_PassThrough(t *testing.T) {
+	input := clipTestRotation()[:40]
+	snapshot := append([]l2frames.PointPolar(nil), input...)
+
+	mock := &MockFrameBuilder{}
+	clipper, err := NewRangeClipper(mock, RangeClipConfig{Global: RangeWindow{MaxM: 200}})
+	if err != nil {
+		t.Fatalf("NewRangeClipper: %v", err)
+	}
+	clipper.AddPointsPolar(input)
+	if !reflect.DeepEqual(mock.points, input) {
+		t.Error("points inside the window should pass through unchanged")
+	}
+
+	mock.points = nil
+	clipper, _ = NewRangeClipper(mock, RangeClipConfig{Global: RangeWindow{MinM: 1}})
+	clipper.AddPointsPolar(input)
+	if len(mock.points) != 30 {
+		t.Errorf("expected the 10 dome returns dropped, got %d points", len(mock.points))
+	}
+	if !reflect.DeepEqual(input, snapshot) {
+		t.Error("input slice was modified")
+	}
+
+	clipper.SetMotorSpeed(600)
+	if mock.motorSpeed != 600 {
+		t.Errorf("motor speed not forwarded, got %d", mock.motorSpeed)
+	}
+}
+
+func TestRangeClipConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RangeClipConfig
+		enabled bool
+		wantErr string
+	}{
+		{name: "zero", cfg: RangeClipConfig{}},
+		{name: "global", cfg: RangeClipConfig{Global: RangeWindow{MinM: 1, MaxM: 80}}, enabled: true},
+		{name: "ring only", cfg: RangeClipConfig{PerRing: map[int]RangeWindow{5: {MinM: 2}}}, enabled: true},
+		{name: "negative", cfg: RangeClipConfig{Global: RangeWindow{MinM: -1}}, enabled: true, wantErr: ">= 0"},
+		{name: "inverted", cfg: RangeClipConfig{Global: RangeWindow{MinM: 80, MaxM: 1}}, enabled: true, wantErr: "below maximum"},
+		{
+			name:    "ring min above inherited max",
+			cfg:     RangeClipConfig{Global: RangeWindow{MaxM: 80}, PerRing: map[int]RangeWindow{2: {MinM: 90}}},
+			enabled: true,
+			wantErr: "ring 2",
+		},
+		{name: "ring zero", cfg: RangeClipConfig{PerRing: map[int]RangeWindow{0: {MinM: 1}}}, enabled: true, wantErr: "start at 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.Enabled(); got != tt.enabled {
+				t.Errorf("Enabled() = %v, want %v", got, tt.enabled)
+			}
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRangeClipConfigFromTuning(t *testing.T) {
+	got := RangeClipConfigFromTuning(config.L1Config{
+		MinRangeMetres: 1,
+		MaxRangeMetres: 120,
+		RingRangeClip: map[int]config.RangeClipWindow{
+			1:  {MinMetres: 2.5, MaxMetres: 80},
+			40: {MaxMetres: 60},
+		},
+	})
+	want := RangeClipConfig{
+		Global:  RangeWindow{MinM: 1, MaxM: 120},
+		PerRing: map[int]RangeWindow{1: {2.5, 80}, 40: {MaxM: 60}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	if cfg := RangeClipConfigFromTuning(config.L1Config{}); cfg.Enabled() {
+		t.Errorf("empty L1 block should leave clipping off, got %+v", cfg)
+	}
+}
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+)
+
+// PacketSignature recognises a sensor's data packets by UDP payload size
+// and the magic bytes at a fixed offset. It is cheap enough to run on every
+// packet ahead of the parser.
+type PacketSignature struct {
+	Name        string
+	Sizes       []int  // accepted payload sizes
+	Magic       []byte // bytes expected at MagicOffset
+	MagicOffset int
+}
+
+// Pandar40PSignature matches Pandar40P data packets: 1262 bytes, or 1266
+// with the UDP sequence number, starting with the first block's preamble
+// (FF EE on the wire; see parse.PACKET_SIZE_*).
+var Pandar40PSignature = PacketSignature{
+	Name:  "Pandar40P",
+	Sizes: []int{1262, 1266},
+	Magic: []byte{0xFF, 0xEE},
+}
+
+// Match reports whether payload has one of the signature's sizes and its
+// magic bytes.
+func (s PacketSignature) Match(payload []byte) bool {
+	sized := false
+	for _, n := range s.Sizes {
+		if len(payload) == n {
+			sized = true
+			break
+		}
+	}
+	if !sized {
+		return false
+	}
+	end := s.MagicOffset + len(s.Magic)
+	return end <= len(payload) && bytes.Equal(payload[s.MagicOffset:end], s.Magic)
+}
+
+// SignatureFilter is a Parser that passes only packets matching a
+// signature to the wrapped Parser. Anything else (ARP replies, DNS and other
+// UDP sharing the port or the capture) is counted and skipped without an
+// error, so mixed traffic neither floods the log with parse failures nor
+// costs a parse attempt.
+type SignatureFilter struct {
+	next      Parser
+	signature PacketSignature
+
+	passed  atomic.Int64
+	skipped atomic.Int64
+}
+
+// NewSignatureFilter wraps next with signature.
+func NewSignatureFilter(next Parser, signature PacketSignature) (*SignatureFilter, error) {
+	if next == nil {
+		return nil, fmt.Errorf("signature filter requires a downstream parser")
+	}
+	if len(signature.Sizes) == 0 {
+		return nil, fmt.Errorf("signature %q has no packet sizes", signature.Name)
+	}
+	return &SignatureFilter{next: next, signature: signature}, nil
+}
+
+// ParsePacket parses packet if it matches the signature and otherwise
+// returns no points and no error.
+func (f *SignatureFilter) ParsePacket(packet []byte) ([]l2frames.PointPolar, error) {
+	if !f.signature.Match(packet) {
+		f.skipped.Add(1)
+		return nil, nil
+	}
+	f.passed.Add(1)
+	return f.next.ParsePacket(packet)
+}
+
+// GetLastMotorSpeed forwards to the wrapped Parser.
+func (f *SignatureFilter) GetLastMotorSpeed() uint16 {
+	return f.next.GetLastMotorSpeed()
+}
+
+// SetPacketTime forwards capture times to the wrapped Parser when it takes
+// them, as the replay paths expect of a parser.
+func (f *SignatureFilter) SetPacketTime(t time.Time) {
+	if p, ok := f.next.(interface{ SetPacketTime(time.Time) }); ok {
+		p.SetPacketTime(t)
+	}
+}
+
+// Unwrap returns the wrapped Parser, for callers that configure it
+// directly.
+func (f *SignatureFilter) Unwrap() Parser {
+	return f.next
+}
+
+// Counts returns the number of packets passed to the parser and skipped.
+func (f *SignatureFilter) Counts() (passed, skipped int64) {
+	return f.passed.Load(), f.skipped.Load()
+}
+
+// UnwrapParser follows Unwrap through any wrapping parsers and returns
+// the innermost one.
+func UnwrapParser(p Parser) Parser {
+	for {
+		w, ok := p.(interface{ Unwrap() Parser })
+		if !ok {
+			return p
+		}
+		p = w.Unwrap()
+	}
+}
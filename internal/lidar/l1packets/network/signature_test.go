@@ -0,0 +1,182 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/parse"
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// countingParser records the payloads that reach the wrapped parser.
+type countingParser struct {
+	*parse.Pandar40PParser
+	packets  int
+	errs     int
+	lastTime time.Time
+}
+
+func (p *countingParser) ParsePacket(packet []byte) ([]l2frames.PointPolar, error) {
+	p.packets++
+	points, err := p.Pandar40PParser.ParsePacket(packet)
+	if err != nil {
+		p.errs++
+	}
+	return points, err
+}
+
+func (p *countingParser) SetPacketTime(t time.Time) {
+	p.lastTime = t
+	p.Pandar40PParser.SetPacketTime(t)
+}
+
+// writeMixedFixture writes a capture of 40 Pandar40P packets on port 2369
+// interleaved with 25 other UDP packets, some on the same port and some
+// Pandar-sized, and returns its path.
+func writeMixedFixture(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mixed.pcap")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := pcapgo.NewWriter(f)
+	if err := w.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 40; i++ {
+		ts := base.Add(time.Duration(i) * time.Millisecond)
+		writeUDPRecord(t, w, ts, 10000, 2369, pandarPacket(i*10))
+		if i%8 == 0 {
+			writeUDPRecord(t, w, ts, 5353, 53, make([]byte, 48))            // DNS
+			writeUDPRecord(t, w, ts, 9347, 2369, make([]byte, 512))         // status on the data port
+			writeUDPRecord(t, w, ts, 10000, 2369, make([]byte, 1262))       // right size, no preamble
+			writeUDPRecord(t, w, ts, 10000, 2369, make([]byte, 1266))       // sequence size, no preamble
+			writeUDPRecord(t, w, ts, 67, 68, make([]byte, parse.TAIL_SIZE)) // DHCP
+		}
+	}
+	return path
+}
+
+// readUDPPayloads returns every UDP payload in a capture, whatever its
+// port, as a mixed-traffic source would deliver them.
+func readUDPPayloads(t *testing.T, path string) []MemoryPacket {
+	t.Helper()
+	stream, err := OpenPCAPStream(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	var packets []MemoryPacket
+	for {
+		data, ci, err := stream.ReadPacketData()
+		if errors.Is(err, io.EOF) {
+			return packets
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkt := gopacket.NewPacket(data, stream.LinkType(), gopacket.Default)
+		if udp, ok := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP); ok {
+			packets = append(packets, MemoryPacket{Data: udp.Payload, Timestamp: ci.Timestamp})
+		}
+	}
+}
+
+func TestSignatureFilter_MixedCapture(t *testing.T) {
+	config, err := parse.LoadEmbeddedPandar40PConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets := readUDPPayloads(t, writeMixedFixture(t))
+	if len(packets) != 65 {
+		t.Fatalf("fixture has %d UDP packets, want 65", len(packets))
+	}
+
+	inner := &countingParser{Pandar40PParser: parse.NewPandar40PParser(*config)}
+	filter, err := NewSignatureFilter(inner, Pandar40PSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := &MockFullPacketStats{}
+	listener := NewUDPListener(UDPListenerConfig{
+		Source:        NewMemoryPacketSource(packets),
+		UseSourceTime: true,
+		Stats:         stats,
+		Parser:        filter,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := listener.Start(ctx); err != nil {
+		t.Fatalf("Start returned %v", err)
+	}
+
+	if inner.packets != 40 || inner.errs != 0 {
+		t.Errorf("parser saw %d packets with %d errors, want the 40 LiDAR packets and no errors", inner.packets, inner.errs)
+	}
+	if passed, skipped := filter.Counts(); passed != 40 || skipped != 25 {
+		t.Errorf("Counts = %d passed, %d skipped; want 40, 25", passed, skipped)
+	}
+	if stats.GetPacketCount() != 65 {
+		t.Errorf("stats counted %d packets, want all 65", stats.GetPacketCount())
+	}
+	if last := packets[len(packets)-1].Timestamp; !inner.lastTime.Equal(last) {
+		t.Errorf("packet time %v did not reach the wrapped parser, want %v", inner.lastTime, last)
+	}
+}
+
+func TestPacketSignature_Match(t *testing.T) {
+	lidar := pandarPacket(0)
+	withSeq := append(append([]byte{}, lidar...), 1, 0, 0, 0)
+	tests := []struct {
+		name    string
+		payload []byte
+		want    bool
+	}{
+		{"standard packet", lidar, true},
+		{"packet with sequence number", withSeq, true},
+		{"right size, no preamble", make([]byte, 1262), false},
+		{"preamble, wrong size", lidar[:1000], false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		if got := Pandar40PSignature.Match(tt.payload); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSignatureFilter_Unwrap(t *testing.T) {
+	config, err := parse.LoadEmbeddedPandar40PConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := parse.NewPandar40PParser(*config)
+	filter, err := NewSignatureFilter(inner, Pandar40PSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := UnwrapParser(filter); got != Parser(inner) {
+		t.Errorf("UnwrapParser = %T, want the Pandar40P parser", got)
+	}
+	if got := UnwrapParser(inner); got != Parser(inner) {
+		t.Errorf("UnwrapParser of an unwrapped parser = %T", got)
+	}
+	if _, err := NewSignatureFilter(nil, Pandar40PSignature); err == nil {
+		t.Error("NewSignatureFilter accepted a nil parser")
+	}
+	if _, err := NewSignatureFilter(inner, PacketSignature{Name: "empty"}); err == nil {
+		t.Error("NewSignatureFilter accepted a signature with no sizes")
+	}
+}
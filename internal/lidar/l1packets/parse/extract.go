@@ -3,6 +3,7 @@ package parse
 import (
 	"encoding/binary"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
@@ -106,6 +107,9 @@ const (
 
 	// Debug logging control constants for development and troubleshooting
 	DEBUG_LOG_INTERVAL = 100 // Debug log every Nth packet after initial packets (reduces log volume)
+
+	// Tail ReturnMode value for dual-return operation (Last + Strongest)
+	RETURN_MODE_DUAL = 0x39
 )
 
 // Pandar40P configuration containing calibration data embedded in the binary
@@ -204,16 +208,19 @@ const (
 // and provides multiple timestamp modes for different synchronization requirements.
 // Motor speed tracking enables real-time frame timing adaptation for variable RPM operation.
 type Pandar40PParser struct {
-	config          Pandar40PConfig // Sensor-specific calibration parameters (angles & firetimes)
-	timestampMode   TimestampMode   // How to interpret timestamp field (affects frame timing accuracy)
-	bootTime        time.Time       // Device boot time reference for internal timestamp mode
-	packetCount     int             // Packet counter for debugging and diagnostic purposes
-	lastTimestamp   uint32          // Previous timestamp for static detection in PTP/GPS modes
-	staticCount     int             // Counter for static timestamp detection and fallback logic
-	debugPackets    int             // Number of initial packets to trace log (prevents log spam)
-	lastMotorSpeed  uint16          // Last parsed motor speed in RPM (cached for frame builder integration)
-	externalTime    time.Time       // Optional override from capture metadata (e.g., PCAP) for replay
-	externalTimeSet bool            // Tracks when an external time override is available
+	config          Pandar40PConfig                 // Sensor-specific calibration parameters (angles & firetimes)
+	timestampMode   TimestampMode                   // How to interpret timestamp field (affects frame timing accuracy)
+	bootTime        time.Time                       // Device boot time reference for internal timestamp mode
+	packetCount     int                             // Packet counter for debugging and diagnostic purposes
+	lastTimestamp   uint32                          // Previous timestamp for static detection in PTP/GPS modes
+	staticCount     int                             // Counter for static timestamp detection and fallback logic
+	debugPackets    int                             // Number of initial packets to trace log (prevents log spam)
+	lastMotorSpeed  uint16                          // Last parsed motor speed in RPM (cached for frame builder integration)
+	externalTime    time.Time                       // Optional override from capture metadata (e.g., PCAP) for replay
+	externalTimeSet bool                            // Tracks when an external time override is available
+	keepSensorTime  bool                            // When true, an external capture time does not replace the sensor time
+	returnSelection ReturnSelection                 // Which returns of a dual-return firing to emit (default all)
+	jitter          atomic.Pointer[timestampJitter] // Optional packet timestamp jitter correction
 }
 
 // NewPandar40PParser creates a new parser instance with the provided calibration configuration
@@ -221,10 +228,11 @@ type Pandar40PParser struct {
 // Parser initializes with system time mode for reliability and configurable debug packet count.
 func NewPandar40PParser(config Pandar40PConfig) *Pandar40PParser {
 	return &Pandar40PParser{
-		config:        config,
-		timestampMode: TimestampModeSystemTime, // Default to system time for reliability
-		bootTime:      time.Now(),              // Initialise boot time reference for internal mode
-		debugPackets:  10,                      // Default to 10 initial packets for debug logging
+		config:          config,
+		timestampMode:   TimestampModeSystemTime, // Default to system time for reliability
+		bootTime:        time.Now(),              // Initialise boot time reference for internal mode
+		debugPackets:    10,                      // Default to 10 initial packets for debug logging
+		returnSelection: ReturnSelectionAll,
 	}
 }
 
@@ -248,6 +256,16 @@ func (p *Pandar40PParser) SetPacketTime(ts time.Time) {
 	p.externalTimeSet = true
 }
 
+// SetKeepSensorTime controls whether a capture time passed to SetPacketTime
+// replaces the point timestamps resolved by the timestamp mode. By default
+// it does, so replays keep their capture timing. With keep set, points keep
+// the sensor-derived time and the capture time is carried only in
+// PointPolar.CaptureTimestamp, so capture latency can be measured on a
+// PCAP. The capture timestamp is recorded either way.
+func (p *Pandar40PParser) SetKeepSensorTime(keep bool) {
+	p.keepSensorTime = keep
+}
+
 // GetLastMotorSpeed returns the motor speed from the last parsed packet
 // Used by frame builder for real-time motor speed-based frame timing calculations
 func (p *Pandar40PParser) GetLastMotorSpeed() uint16 {
@@ -302,6 +320,14 @@ func (p *Pandar40PParser) ParsePacket(data []byte) ([]l2frames.PointPolar, error
 	// This enables real-time adaptation to variable RPM settings (600-1200+ RPM)
 	p.lastMotorSpeed = tail.MotorSpeed
 
+	// Capture time is when the host received the packet: the PCAP record
+	// time on replay, otherwise the parse time (the listener parses as it
+	// reads). It is kept apart from the point time for latency analysis.
+	captureTime := time.Now()
+	if p.externalTimeSet {
+		captureTime = p.externalTime.UTC()
+	}
+
 	// Resolve packet timestamp once for all blocks to ensure consistency
 	// This prevents mixed timestamps (e.g. PCAP time + System time) within a single packet
 	// and fixes the static timestamp detection logic which falsely triggered on block iterations.
@@ -329,6 +355,7 @@ func (p *Pandar40PParser) ParsePacket(data []byte) ([]l2frames.PointPolar, error
 	// Track non-zero channel counts per block for diagnostics when parsing yields no points
 	blockNonZero := make([]int, 0, BLOCKS_PER_PACKET)
 
+	blocks := make([]*DataBlock, 0, BLOCKS_PER_PACKET)
 	for blockIdx := 0; blockIdx < BLOCKS_PER_PACKET; blockIdx++ {
 		// Calculate block size: 2 bytes preamble + 2 bytes azimuth + (40 channels × 3 bytes each) = 124 bytes
 		blockSize := BLOCK_SIZE
@@ -350,12 +377,22 @@ func (p *Pandar40PParser) ParsePacket(data []byte) ([]l2frames.PointPolar, error
 			}
 		}
 		blockNonZero = append(blockNonZero, nonZero)
+		blocks = append(blocks, block)
 
+		dataOffset += blockSize
+	}
+
+	// Keep one return per firing when a single-return stream was requested
+	if tail.ReturnMode == RETURN_MODE_DUAL && p.returnSelection != ReturnSelectionAll {
+		for i := 0; i+1 < len(blocks); i += 2 {
+			selectDualReturn(blocks[i], blocks[i+1], p.returnSelection)
+		}
+	}
+
+	for blockIdx, block := range blocks {
 		// Convert raw measurements to calibrated 3D points with accurate timing and motor speed compensation
-		blockPoints := p.blockToPoints(block, blockIdx, tail, packetTime)
+		blockPoints := p.blockToPoints(block, blockIdx, tail, packetTime, captureTime)
 		points = append(points, blockPoints...)
-
-		dataOffset += blockSize
 	}
 
 	// Diagnostic: if parsing succeeded but produced zero points, log per-block non-zero counts
@@ -499,11 +536,18 @@ func (p *Pandar40PParser) resolvePacketTime(tail *PacketTail) time.Time {
 	}
 
 	// Prefer externally provided capture timestamps when available (e.g., PCAP replay)
+	// unless the caller asked to keep the sensor time (see SetKeepSensorTime)
 	if p.externalTimeSet {
-		packetTime = p.externalTime.UTC()
+		if !p.keepSensorTime {
+			packetTime = p.externalTime.UTC()
+		}
 		p.externalTimeSet = false
 	}
 
+	if j := p.jitter.Load(); j != nil {
+		packetTime = j.correct(packetTime)
+	}
+
 	return packetTime
 }
 
@@ -511,13 +555,20 @@ func (p *Pandar40PParser) resolvePacketTime(tail *PacketTail) time.Time {
 // Applies sensor-specific calibrations, motor speed compensation, and coordinate transformation.
 // Each block can produce up to 40 points (one per channel), excluding invalid measurements.
 // Uses actual motor speed from packet tail for precise firetime-based azimuth corrections.
-func (p *Pandar40PParser) blockToPoints(block *DataBlock, blockIdx int, tail *PacketTail, packetTime time.Time) []l2frames.PointPolar {
+func (p *Pandar40PParser) blockToPoints(block *DataBlock, blockIdx int, tail *PacketTail, packetTime, captureTime time.Time) []l2frames.PointPolar {
 	// Pre-allocate slice with capacity for maximum possible points to avoid reallocations
 	points := make([]l2frames.PointPolar, 0, CHANNELS_PER_BLOCK)
 
 	// Extract base azimuth angle from block data (in 0.01-degree units, range 0-35999)
 	baseAzimuth := float64(block.Azimuth) * AZIMUTH_RESOLUTION
 
+	// In dual-return mode blocks arrive in pairs sharing one azimuth; the
+	// second block of each pair carries the other return of the same firing.
+	var returnIndex uint8
+	if tail.ReturnMode == RETURN_MODE_DUAL && blockIdx%2 == 1 {
+		returnIndex = 1
+	}
+
 	// Process each of the 40 channels in this block for 3D point generation
 	for channelIdx := 0; channelIdx < CHANNELS_PER_BLOCK; channelIdx++ {
 		channelData := block.Channels[channelIdx]
@@ -583,15 +634,17 @@ func (p *Pandar40PParser) blockToPoints(block *DataBlock, blockIdx int, tail *Pa
 
 		// Create polar point (sensor-frame) - conversion to Cartesian will be done in frame builder
 		point := l2frames.PointPolar{
-			Channel:         channelNum,
-			Azimuth:         azimuth,
-			Elevation:       elevation,
-			Distance:        distance,
-			Intensity:       channelData.Reflectivity,
-			Timestamp:       pointTime.UnixNano(),
-			BlockID:         blockIdx,
-			UDPSequence:     tail.UDPSequence,
-			RawBlockAzimuth: block.Azimuth,
+			Channel:          channelNum,
+			Azimuth:          azimuth,
+			Elevation:        elevation,
+			Distance:         distance,
+			Intensity:        channelData.Reflectivity,
+			Timestamp:        pointTime.UnixNano(),
+			CaptureTimestamp: captureTime.UnixNano(),
+			BlockID:          blockIdx,
+			UDPSequence:      tail.UDPSequence,
+			RawBlockAzimuth:  block.Azimuth,
+			ReturnIndex:      returnIndex,
 		}
 
 		points = append(points, point)
@@ -1,9 +1,17 @@
 package parse
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
+	"net"
 	"testing"
 	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
 )
 
 // Test constants for edge case testing
@@ -320,6 +328,45 @@ func TestParsePacket_TailReturnMode(t *testing.T) {
 	}
 }
 
+// TestParsePacket_ReturnIndex checks that only the second block of each
+// dual-return pair is tagged as the second return.
+func TestParsePacket_ReturnIndex(t *testing.T) {
+	config := createTestMockConfig()
+
+	for _, tc := range []struct {
+		mode     byte
+		wantOdd  uint8
+		wantEven uint8
+	}{
+		{0x37, 0, 0},
+		{0x38, 0, 0},
+		{RETURN_MODE_DUAL, 1, 0},
+	} {
+		t.Run(fmt.Sprintf("mode_0x%02X", tc.mode), func(t *testing.T) {
+			parser := NewPandar40PParser(*config)
+			packet := createTestMockPacket()
+			packet[1240+14] = tc.mode
+
+			points, err := parser.ParsePacket(packet)
+			if err != nil {
+				t.Fatalf("ParsePacket: %v", err)
+			}
+			if len(points) == 0 {
+				t.Fatal("expected points")
+			}
+			for _, p := range points {
+				want := tc.wantEven
+				if p.BlockID%2 == 1 {
+					want = tc.wantOdd
+				}
+				if p.ReturnIndex != want {
+					t.Fatalf("block %d: ReturnIndex = %d, want %d", p.BlockID, p.ReturnIndex, want)
+				}
+			}
+		})
+	}
+}
+
 // TestParsePacket_WithSequenceNumber tests parsing sequence-enabled packets
 func TestParsePacket_WithSequenceNumber(t *testing.T) {
 	config := createTestMockConfig()
@@ -407,10 +454,15 @@ func TestSetPacketTime(t *testing.T) {
 	parser.SetPacketTime(externalTime)
 
 	packet := createTestMockPacket()
-	_, err := parser.ParsePacket(packet)
+	points, err := parser.ParsePacket(packet)
 	if err != nil {
 		t.Fatalf("ParsePacket failed with external time: %v", err)
 	}
+	// By default the capture time also replaces the point time.
+	if len(points) == 0 || points[0].CaptureTimestamp != externalTime.UnixNano() ||
+		time.Unix(0, points[0].Timestamp).Sub(externalTime).Abs() > time.Millisecond {
+		t.Fatalf("expected point and capture times at the external time %v", externalTime)
+	}
 
 	// External time should be cleared after one use
 	// (second parse should use normal timestamp mode)
@@ -420,6 +472,117 @@ func TestSetPacketTime(t *testing.T) {
 	}
 }
 
+// TestCaptureTimestamp_PCAPOffset replays a PCAP whose capture times trail
+// the sensor clock by a known 45 ms and checks that points keep the sensor
+// time while the capture time reaches the frame as capture latency.
+func TestCaptureTimestamp_PCAPOffset(t *testing.T) {
+	const offset = 45 * time.Millisecond
+	// Mock packet tail DateTime: 2017-09-06 14:33:38 UTC.
+	sensorBase := time.Date(2017, 9, 6, 14, 33, 38, 0, time.UTC)
+
+	// Write five packets 1 ms apart on the sensor clock.
+	var capture bytes.Buffer
+	w := pcapgo.NewWriterNanos(&capture)
+	if err := w.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		t.Fatalf("WriteFileHeader: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		payload := createTestMockPacket()
+		tail := testPacketStandard - testTailSize
+		binary.LittleEndian.PutUint32(payload[tail+10:tail+14], uint32(i*1000)) // microseconds
+		for b := 0; b < testBlockCount; b++ {
+			binary.LittleEndian.PutUint16(payload[b*testBlockBytes+2:], uint16(i*3600+b*360))
+		}
+
+		eth := &layers.Ethernet{SrcMAC: net.HardwareAddr{2, 0, 0, 0, 0, 1}, DstMAC: layers.EthernetBroadcast,
+			EthernetType: layers.EthernetTypeIPv4}
+		ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolUDP,
+			SrcIP: net.IPv4(192, 168, 1, 201).To4(), DstIP: net.IPv4bcast.To4()}
+		udp := &layers.UDP{SrcPort: 2369, DstPort: 2369}
+		if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+			t.Fatal(err)
+		}
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := gopacket.SerializeLayers(buf, opts, eth, ip, udp, gopacket.Payload(payload)); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		ci := gopacket.CaptureInfo{
+			Timestamp:     sensorBase.Add(time.Duration(i)*time.Millisecond + offset),
+			CaptureLength: len(data),
+			Length:        len(data),
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatalf("WritePacket: %v", err)
+		}
+	}
+
+	parser := NewPandar40PParser(*createTestMockConfig())
+	parser.SetTimestampMode(TimestampModeLiDAR)
+	parser.SetKeepSensorTime(true)
+
+	var frames []*l2frames.LiDARFrame
+	fb := l2frames.NewFrameBuilderDI(l2frames.FrameBuilderConfig{
+		SensorID:       "capture-offset-test",
+		MinFramePoints: 1,
+		FrameCallback:  func(f *l2frames.LiDARFrame) { frames = append(frames, f) },
+	})
+
+	r, err := pcapgo.NewReader(&capture)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	for {
+		data, ci, err := r.ReadPacketData()
+		if err != nil {
+			break
+		}
+		pkt := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+		udp, ok := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+		if !ok {
+			t.Fatal("packet has no UDP layer")
+		}
+		parser.SetPacketTime(ci.Timestamp)
+		points, err := parser.ParsePacket(udp.Payload)
+		if err != nil {
+			t.Fatalf("ParsePacket: %v", err)
+		}
+		for _, p := range points {
+			pointTime, captured := time.Unix(0, p.Timestamp), time.Unix(0, p.CaptureTimestamp)
+			// Sensor time within the 5 ms burst (firetimes shift it by < 1 ms).
+			if pointTime.Before(sensorBase.Add(-time.Millisecond)) || pointTime.After(sensorBase.Add(5*time.Millisecond)) {
+				t.Fatalf("point time %v outside the sensor clock window from %v", pointTime, sensorBase)
+			}
+			if !captured.Equal(ci.Timestamp) {
+				t.Fatalf("capture time %v, want the PCAP record time %v", captured, ci.Timestamp)
+			}
+		}
+		fb.AddPointsPolar(points)
+	}
+	fb.Flush()
+	fb.Close()
+
+	if len(frames) == 0 {
+		t.Fatal("no frame delivered")
+	}
+	frame := frames[0]
+	if frame.StartTimestamp.Equal(frame.StartCaptureTime) {
+		t.Fatal("point and capture timestamps collapsed")
+	}
+	if !frame.StartCaptureTime.Equal(sensorBase.Add(offset)) {
+		t.Errorf("StartCaptureTime = %v, want %v", frame.StartCaptureTime, sensorBase.Add(offset))
+	}
+	// Latency is the offset plus each channel's firetime lead (< 100 µs here).
+	for name, got := range map[string]time.Duration{
+		"min": frame.MinCaptureLatency, "mean": frame.MeanCaptureLatency(), "max": frame.MaxCaptureLatency,
+	} {
+		if got < offset || got > offset+100*time.Microsecond {
+			t.Errorf("%s capture latency = %v, want %v (+ firetime < 100µs)", name, got, offset)
+		}
+	}
+}
+
 // TestParsePacket_ConsecutivePackets tests parsing many packets in sequence
 func TestParsePacket_ConsecutivePackets(t *testing.T) {
 	config := createTestMockConfig()
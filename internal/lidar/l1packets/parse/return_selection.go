@@ -0,0 +1,71 @@
+package parse
+
+import "fmt"
+
+// ReturnSelection chooses which returns of a multi-return firing the parser
+// emits, so downstream stages can see a single-return stream from a sensor
+// running in dual-return mode.
+type ReturnSelection string
+
+const (
+	// ReturnSelectionAll emits every return in the packet (default).
+	ReturnSelectionAll ReturnSelection = "all"
+	// ReturnSelectionStrongest keeps the return with the higher reflectivity.
+	ReturnSelectionStrongest ReturnSelection = "strongest"
+	// ReturnSelectionLast keeps the last (furthest) return.
+	ReturnSelectionLast ReturnSelection = "last"
+	// ReturnSelectionFirst keeps the nearer return.
+	ReturnSelectionFirst ReturnSelection = "first"
+)
+
+// ParseReturnSelection parses a return-mode name; an empty string means all.
+func ParseReturnSelection(s string) (ReturnSelection, error) {
+	switch sel := ReturnSelection(s); sel {
+	case "":
+		return ReturnSelectionAll, nil
+	case ReturnSelectionAll, ReturnSelectionStrongest, ReturnSelectionLast, ReturnSelectionFirst:
+		return sel, nil
+	}
+	return "", fmt.Errorf("unknown return mode %q: want all, strongest, last or first", s)
+}
+
+// SetReturnSelection sets which returns of a dual-return firing are emitted.
+// Single-return packets (ReturnMode 0x37 strongest, 0x38 last) carry one
+// return per firing, already chosen by the sensor, and are unaffected.
+func (p *Pandar40PParser) SetReturnSelection(sel ReturnSelection) {
+	if sel == "" {
+		sel = ReturnSelectionAll
+	}
+	p.returnSelection = sel
+}
+
+// selectDualReturn reduces a dual-return block pair to one return per
+// channel by zeroing the distance of the return not selected, which
+// blockToPoints then skips. In Pandar40P dual-return mode (0x39) the first
+// block of each pair holds the last return and the second the strongest;
+// when the last return is also the strongest, the second block carries the
+// second-strongest. A channel with a return in only one block keeps it.
+func selectDualReturn(last, strongest *DataBlock, sel ReturnSelection) {
+	for ch := range last.Channels {
+		a, b := &last.Channels[ch], &strongest.Channels[ch]
+		if a.Distance == 0 || b.Distance == 0 {
+			continue
+		}
+		var keepLast bool
+		switch sel {
+		case ReturnSelectionLast:
+			keepLast = true
+		case ReturnSelectionFirst:
+			keepLast = a.Distance < b.Distance
+		case ReturnSelectionStrongest:
+			keepLast = a.Reflectivity > b.Reflectivity
+		default:
+			continue
+		}
+		if keepLast {
+			b.Distance = 0
+		} else {
+			a.Distance = 0
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package parse
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// createDualReturnPacket builds a dual-return (0x39) packet whose block
+// pairs carry, per channel, the last return in the first block and the
+// strongest in the second:
+//
+//	channel 1: last 20 m refl 50,  strongest 10 m refl 200
+//	channel 2: last 30 m refl 220, second-strongest 12 m refl 90
+//	channel 3: no last return,     strongest 15 m refl 120
+//
+// Every other channel has no return.
+func createDualReturnPacket() []byte {
+	packet := createTestMockPacket()
+	packet[TAIL_START+14] = RETURN_MODE_DUAL
+
+	type ret struct {
+		metres float64
+		refl   uint8
+	}
+	pair := [2][3]ret{
+		{{20, 50}, {30, 220}, {0, 0}},
+		{{10, 200}, {12, 90}, {15, 120}},
+	}
+	for block := 0; block < testBlocksPerPacket; block++ {
+		offset := block*BLOCK_SIZE + BLOCK_PREAMBLE_SIZE
+		binary.LittleEndian.PutUint16(packet[offset:], uint16((block/2)*1000))
+		offset += AZIMUTH_SIZE
+		for ch := 0; ch < testChannelsPerBlock; ch++ {
+			var r ret
+			if ch < 3 {
+				r = pair[block%2][ch]
+			}
+			binary.LittleEndian.PutUint16(packet[offset:], uint16(r.metres/DISTANCE_RESOLUTION+0.5))
+			packet[offset+2] = r.refl
+			offset += BYTES_PER_CHANNEL
+		}
+	}
+	return packet
+}
+
+func TestParsePacket_ReturnSelection(t *testing.T) {
+	tests := []struct {
+		sel  ReturnSelection
+		want []string // per channel "channel:metres", one block pair
+	}{
+		{ReturnSelectionAll, []string{"1:10", "1:20", "2:12", "2:30", "3:15"}},
+		{ReturnSelectionStrongest, []string{"1:10", "2:30", "3:15"}},
+		{ReturnSelectionLast, []string{"1:20", "2:30", "3:15"}},
+		{ReturnSelectionFirst, []string{"1:10", "2:12", "3:15"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sel), func(t *testing.T) {
+			parser := NewPandar40PParser(*createTestMockConfig())
+			parser.SetReturnSelection(tt.sel)
+			points, err := parser.ParsePacket(createDualReturnPacket())
+			if err != nil {
+				t.Fatalf("ParsePacket: %v", err)
+			}
+			pairs := testBlocksPerPacket / 2
+			if len(points) != len(tt.want)*pairs {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.want)*pairs)
+			}
+			var got []string
+			for _, p := range points {
+				if p.BlockID/2 == 0 {
+					got = append(got, fmt.Sprintf("%d:%.0f", p.Channel, p.Distance))
+				}
+			}
+			sort.Strings(got)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("first block pair points = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePacket_ReturnSelectionIgnoresSingleReturn(t *testing.T) {
+	parser := NewPandar40PParser(*createTestMockConfig())
+	want, err := parser.ParsePacket(createTestMockPacket())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.SetReturnSelection(ReturnSelectionFirst)
+	got, err := parser.ParsePacket(createTestMockPacket())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("single-return packet: %d points with selection, want %d", len(got), len(want))
+	}
+}
+
+func TestParseReturnSelection(t *testing.T) {
+	for in, want := range map[string]ReturnSelection{
+		"":          ReturnSelectionAll,
+		"all":       ReturnSelectionAll,
+		"strongest": ReturnSelectionStrongest,
+		"last":      ReturnSelectionLast,
+		"first":     ReturnSelectionFirst,
+	} {
+		if got, err := ParseReturnSelection(in); err != nil || got != want {
+			t.Errorf("ParseReturnSelection(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseReturnSelection("dual"); err == nil {
+		t.Error("expected error for unknown return mode")
+	}
+}
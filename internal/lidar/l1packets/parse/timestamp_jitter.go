@@ -0,0 +1,95 @@
+package parse
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Packet timestamp jitter correction.
+//
+// Some capture NICs stamp packets with a few hundred microseconds of
+// jitter, so consecutive packets occasionally step backwards in time. Frame
+// timing is derived from packet times, and each backward step shows up as
+// a short frame followed by a long one. With correction enabled, a packet
+// that is earlier than the previous one by no more than MaxBackstep is
+// clamped to the previous packet's time, so packet times never decrease.
+// A larger backward jump is a genuine reordering or a replay restart, not
+// jitter: it is passed through unchanged and becomes the new reference.
+
+// TimestampJitterConfig configures packet timestamp jitter correction.
+type TimestampJitterConfig struct {
+	// MaxBackstep is the largest backward step treated as jitter. Zero
+	// disables correction.
+	MaxBackstep time.Duration
+}
+
+// Enabled reports whether jitter correction is configured.
+func (c TimestampJitterConfig) Enabled() bool {
+	return c.MaxBackstep > 0
+}
+
+// TimestampJitterStats counts the packets seen by jitter correction.
+type TimestampJitterStats struct {
+	Clamped      int64 `json:"clamped"`        // backward steps within MaxBackstep, clamped to the previous time
+	LargeJumps   int64 `json:"large_jumps"`    // backward steps beyond MaxBackstep, passed through
+	MaxClampedNs int64 `json:"max_clamped_ns"` // largest backward step clamped
+}
+
+// timestampJitter is the correction state of one packet stream.
+type timestampJitter struct {
+	cfg  TimestampJitterConfig
+	last time.Time
+
+	clamped    atomic.Int64
+	largeJumps atomic.Int64
+	maxClamped atomic.Int64
+}
+
+// correct returns ts, clamped to the previous packet time when it is a
+// small backward step.
+func (j *timestampJitter) correct(ts time.Time) time.Time {
+	if j.last.IsZero() {
+		j.last = ts
+		return ts
+	}
+	step := j.last.Sub(ts)
+	switch {
+	case step <= 0:
+		j.last = ts
+		return ts
+	case step <= j.cfg.MaxBackstep:
+		j.clamped.Add(1)
+		if int64(step) > j.maxClamped.Load() {
+			j.maxClamped.Store(int64(step))
+		}
+		return j.last
+	default:
+		j.largeJumps.Add(1)
+		j.last = ts
+		return ts
+	}
+}
+
+// SetTimestampJitter enables or, with a zero config, disables packet
+// timestamp jitter correction. Changing it resets the counters.
+func (p *Pandar40PParser) SetTimestampJitter(cfg TimestampJitterConfig) {
+	if !cfg.Enabled() {
+		p.jitter.Store(nil)
+		return
+	}
+	p.jitter.Store(&timestampJitter{cfg: cfg})
+}
+
+// TimestampJitterStats returns the corrections made since jitter
+// correction was enabled. It is safe to call while packets are parsed.
+func (p *Pandar40PParser) TimestampJitterStats() TimestampJitterStats {
+	j := p.jitter.Load()
+	if j == nil {
+		return TimestampJitterStats{}
+	}
+	return TimestampJitterStats{
+		Clamped:      j.clamped.Load(),
+		LargeJumps:   j.largeJumps.Load(),
+		MaxClampedNs: j.maxClamped.Load(),
+	}
+}
@@ -0,0 +1,93 @@
+package parse
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTimestampJitter_ClampsSmallBackstepsKeepsReorderings feeds packet
+// times about 50 µs apart with capture jitter that twice steps back, then a
+// 50 ms backward jump and a replay restart. The jitter comes out
+// non-decreasing; the two large jumps pass through untouched.
+func TestTimestampJitter_ClampsSmallBackstepsKeepsReorderings(t *testing.T) {
+	parser := NewPandar40PParser(*createTestMockConfig())
+	parser.SetTimestampMode(TimestampModeLiDAR)
+	parser.SetTimestampJitter(TimestampJitterConfig{MaxBackstep: time.Millisecond})
+	packet := createTestMockPacket()
+
+	base := time.Unix(1_700_000_000, 0)
+	var in []time.Time
+	for _, us := range []int{0, 90, 65, 160, 200, 185, 300, 350} {
+		in = append(in, base.Add(time.Duration(us)*time.Microsecond))
+	}
+	reorder := len(in)
+	in = append(in, base.Add(-50*time.Millisecond)) // genuine reordering
+	in = append(in, base.Add(-50*time.Millisecond+100*time.Microsecond))
+	restart := len(in)
+	in = append(in, base.Add(-time.Hour)) // replay restarted
+
+	var out []int64
+	for _, ts := range in {
+		parser.SetPacketTime(ts)
+		points, err := parser.ParsePacket(packet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(points) == 0 {
+			t.Fatal("mock packet parsed to no points")
+		}
+		out = append(out, points[0].Timestamp)
+	}
+	// Point times are the packet time plus a fixed firing offset.
+	offset := out[0] - in[0].UnixNano()
+
+	for i := 1; i < reorder; i++ {
+		if out[i] < out[i-1] {
+			t.Errorf("packet %d: time went back %v after correction", i, time.Duration(out[i-1]-out[i]))
+		}
+	}
+	// Jittered packets that did not step back are unchanged, and a clamped
+	// packet holds the previous time rather than jumping ahead.
+	if got := time.Duration(out[3] - out[1]); got != 70*time.Microsecond {
+		t.Errorf("packet 3 is %v after packet 1, want its own 70 µs", got)
+	}
+	if out[2] != out[1] {
+		t.Errorf("packet 2 = %d, want clamped to packet 1's %d", out[2], out[1])
+	}
+	if out[reorder]-offset != in[reorder].UnixNano() || out[restart]-offset != in[restart].UnixNano() {
+		t.Error("large backward jumps were masked")
+	}
+	if got := time.Duration(out[reorder+1] - out[reorder]); got != 100*time.Microsecond {
+		t.Errorf("after a reordering the next packet is %v later, want 100 µs", got)
+	}
+
+	stats := parser.TimestampJitterStats()
+	if stats.Clamped != 2 || stats.LargeJumps != 2 || stats.MaxClampedNs != int64(25*time.Microsecond) {
+		t.Errorf("stats = %+v, want 2 clamped (max 25 µs) and 2 large jumps", stats)
+	}
+}
+
+func TestTimestampJitter_Disabled(t *testing.T) {
+	parser := NewPandar40PParser(*createTestMockConfig())
+	parser.SetTimestampJitter(TimestampJitterConfig{MaxBackstep: time.Millisecond})
+	parser.SetTimestampJitter(TimestampJitterConfig{})
+	packet := createTestMockPacket()
+
+	base := time.Unix(1_700_000_000, 0)
+	var first int64
+	for i, ts := range []time.Time{base, base.Add(-20 * time.Microsecond)} {
+		parser.SetPacketTime(ts)
+		points, err := parser.ParsePacket(packet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = points[0].Timestamp
+		} else if got := first - points[0].Timestamp; got != int64(20*time.Microsecond) {
+			t.Errorf("backward step = %v, want the raw 20 µs", time.Duration(got))
+		}
+	}
+	if stats := parser.TimestampJitterStats(); stats != (TimestampJitterStats{}) {
+		t.Errorf("disabled stats = %+v, want zero", stats)
+	}
+}
@@ -0,0 +1,77 @@
+package synth_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/adapters"
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/synth"
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// TestPipeline_MOTEvaluationAgainstTruth scores the tracker on a scripted
+// car and pedestrian against the scene's own truth CSV, as pcap-analyse
+// -truth does on a gen-pcap capture. The only errors expected are the
+// frames before each track is confirmed (misses) and the pedestrian's
+// track coasting for a frame or two after the pedestrian vanishes at the
+// end of the path (false positives).
+func TestPipeline_MOTEvaluationAgainstTruth(t *testing.T) {
+	if testing.Short() {
+		t.Skip("renders and tracks seven seconds of packets")
+	}
+	scene := synth.Scene{
+		DurationSecs: 7,
+		RangeJitter:  0.01,
+		Seed:         1,
+		Objects: []synth.Object{
+			{ID: "car", Class: "car", Waypoints: synth.LinearPath(-25, 18, 25, 18, 10, 1.5)},
+			{ID: "walker", Class: "pedestrian", Waypoints: synth.LinearPath(6, -8, -2, -8, 1.5, 1)},
+		},
+	}
+	var csv bytes.Buffer
+	if err := scene.WriteTruthCSV(&csv, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	truth, err := adapters.ReadTruthCSV(&csv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	eval := adapters.NewMOTEvaluator(truth, 0)
+	frame := 0
+	runScene(t, scene, func(f *l2frames.LiDARFrame, _ time.Time, tracker *l5tracks.Tracker) {
+		mu.Lock()
+		defer mu.Unlock()
+		frame++
+		eval.Frame(frame, f.StartTimestamp, tracker.GetConfirmedTracks())
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	s := eval.Summary()
+	t.Logf("frames=%d truth=%d matches=%d misses=%d fp=%d idsw=%d MOTA=%.3f MOTP=%.2fm MT=%d ML=%d",
+		s.Frames, s.TruthObjects, s.Matches, s.Misses, s.FalsePositives, s.IDSwitches, s.MOTA, s.MOTPMetres, s.MostlyTracked, s.MostlyLost)
+	if s.TruthIDs != 2 || s.MostlyTracked != 2 {
+		t.Errorf("truth IDs %d, mostly tracked %d: want both objects mostly tracked", s.TruthIDs, s.MostlyTracked)
+	}
+	if s.IDSwitches != 0 {
+		t.Errorf("%d ID switches, want none", s.IDSwitches)
+	}
+	if s.Misses > 2*l5tracks.DefaultTrackerConfig().HitsToConfirm {
+		t.Errorf("%d misses, want no more than the confirmation latency of both tracks", s.Misses)
+	}
+	if s.FalsePositives > 3 {
+		t.Errorf("%d false positives, want at most a short coast", s.FalsePositives)
+	}
+	if s.MOTA < 0.9 {
+		t.Errorf("MOTA %.3f, want ≥ 0.9", s.MOTA)
+	}
+	// Track centres sit on the near faces the sensor sees.
+	if s.MOTPMetres > 1.5 {
+		t.Errorf("MOTP %.2f m, want ≤ 1.5", s.MOTPMetres)
+	}
+}
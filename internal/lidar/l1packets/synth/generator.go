@@ -0,0 +1,209 @@
+package synth
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/parse"
+)
+
+// blockPeriod is the Pandar40P firing cycle: one block of 40 returns every
+// 55.56 µs whatever the motor speed, giving 0.2° azimuth steps at 600 RPM.
+const blockPeriod = 1e6 / 18000.0 // microseconds
+
+// Return intensities by surface, so tests can tell them apart.
+const (
+	groundIntensity   = 20
+	backdropIntensity = 60
+	objectIntensity   = 200
+)
+
+// Generator renders a Scene as Pandar40P packets in capture order. It
+// satisfies network.PacketSource, so it can feed a UDPListener directly,
+// and WritePCAP saves its output for replay.
+type Generator struct {
+	scene Scene
+	cfg   parse.Pandar40PConfig
+	rng   *rand.Rand
+
+	azStep    float64 // degrees per block
+	degPerUs  float64 // rotation rate for firetime offsets
+	total     int     // packets in the scene
+	next      int     // index of the next packet
+	packetBuf []byte
+}
+
+// NewGenerator validates scene and returns a generator using the given
+// calibration, or the embedded Pandar40P calibration when cfg is nil.
+func NewGenerator(scene Scene, cfg *parse.Pandar40PConfig) (*Generator, error) {
+	if err := scene.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		embedded, err := parse.LoadEmbeddedPandar40PConfig()
+		if err != nil {
+			return nil, fmt.Errorf("load embedded calibration: %w", err)
+		}
+		cfg = embedded
+	}
+	scene = scene.withDefaults()
+	degPerUs := 360 * float64(scene.MotorRPM) / 60 / 1e6
+	g := &Generator{
+		scene:     scene,
+		cfg:       *cfg,
+		rng:       rand.New(rand.NewSource(scene.Seed)),
+		azStep:    degPerUs * blockPeriod,
+		degPerUs:  degPerUs,
+		packetBuf: make([]byte, parse.PACKET_SIZE_STANDARD),
+	}
+	g.total = int(scene.DurationSecs * 1e6 / (blockPeriod * parse.BLOCKS_PER_PACKET))
+	return g, nil
+}
+
+// Scene returns the scene being rendered, with defaults applied.
+func (g *Generator) Scene() Scene {
+	return g.scene
+}
+
+// Packets returns the number of packets the scene renders to.
+func (g *Generator) Packets() int {
+	return g.total
+}
+
+// Next renders the next packet and returns it with its capture time, or
+// false once the scene is over. The payload is reused by the next call.
+func (g *Generator) Next() ([]byte, time.Time, bool) {
+	if g.next >= g.total {
+		return nil, time.Time{}, false
+	}
+	pkt := g.packetBuf
+	clear(pkt)
+	firstBlock := g.next * parse.BLOCKS_PER_PACKET
+	packetUs := float64(firstBlock) * blockPeriod
+	offset := 0
+	for b := 0; b < parse.BLOCKS_PER_PACKET; b++ {
+		k := firstBlock + b
+		raw := uint16(math.Round(math.Mod(float64(k)*g.azStep, 360)/parse.AZIMUTH_RESOLUTION)) % 36000
+		binary.LittleEndian.PutUint16(pkt[offset:], 0xEEFF)
+		binary.LittleEndian.PutUint16(pkt[offset+2:], raw)
+		offset += parse.BLOCK_PREAMBLE_SIZE + parse.AZIMUTH_SIZE
+		blockAz := float64(raw) * parse.AZIMUTH_RESOLUTION
+		blockSecs := float64(k) * blockPeriod / 1e6
+		for ch := 0; ch < parse.CHANNELS_PER_BLOCK; ch++ {
+			fire := g.cfg.FiretimeCorrections[ch].FireTime
+			az := blockAz + g.cfg.AngleCorrections[ch].Azimuth + fire*g.degPerUs
+			el := g.cfg.AngleCorrections[ch].Elevation
+			if dist, intensity, ok := g.cast(az, el, blockSecs+fire/1e6); ok {
+				if g.scene.RangeJitter > 0 {
+					dist += g.rng.NormFloat64() * g.scene.RangeJitter
+				}
+				if units := math.Round(dist / parse.DISTANCE_RESOLUTION); units >= 1 && units <= math.MaxUint16 {
+					binary.LittleEndian.PutUint16(pkt[offset:], uint16(units))
+					pkt[offset+2] = intensity
+				}
+			}
+			offset += parse.BYTES_PER_CHANNEL
+		}
+	}
+
+	ts := g.scene.Start.Add(time.Duration(packetUs * float64(time.Microsecond)))
+	tail := pkt[parse.TAIL_START:]
+	binary.LittleEndian.PutUint16(tail[8:], uint16(g.scene.MotorRPM))
+	utc := ts.UTC()
+	binary.LittleEndian.PutUint32(tail[10:], uint32(utc.Nanosecond()/1000))
+	tail[14] = 0x37 // strongest return
+	tail[15] = 0x42 // factory info
+	tail[16] = byte(utc.Year() - 2000)
+	tail[17] = byte(utc.Month())
+	tail[18] = byte(utc.Day())
+	tail[19] = byte(utc.Hour())
+	tail[20] = byte(utc.Minute())
+	tail[21] = byte(utc.Second())
+
+	g.next++
+	return pkt, ts, true
+}
+
+// NextPacket implements network.PacketSource.
+func (g *Generator) NextPacket(ctx context.Context) ([]byte, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+	data, ts, ok := g.Next()
+	if !ok {
+		return nil, time.Time{}, io.EOF
+	}
+	return data, ts, nil
+}
+
+// Close ends the scene early.
+func (g *Generator) Close() error {
+	g.next = g.total
+	return nil
+}
+
+// cast returns the range and intensity of the nearest surface along the
+// ray at azimuth and elevation (degrees, parser conventions) t seconds
+// into the scene, and false when the ray escapes.
+func (g *Generator) cast(azDeg, elDeg, t float64) (float64, uint8, bool) {
+	az, el := azDeg*math.Pi/180, elDeg*math.Pi/180
+	// l2frames.SphericalToCartesian: azimuth runs from +Y towards +X.
+	horiz := math.Cos(el)
+	dx, dy, dz := horiz*math.Sin(az), horiz*math.Cos(az), math.Sin(el)
+
+	best, intensity := math.Inf(1), uint8(0)
+	if r := g.scene.BackdropRange; r > 0 && horiz > 0 {
+		best, intensity = r/horiz, backdropIntensity
+	}
+	if h := g.scene.SensorHeight; h > 0 && dz < 0 {
+		if r := -h / dz; r < best {
+			best, intensity = r, groundIntensity
+		}
+	}
+	for _, o := range g.scene.Objects {
+		st, ok := o.At(t)
+		if !ok {
+			continue
+		}
+		if r, hit := g.hitBox(o, st, dx, dy, dz); hit && r < best {
+			best, intensity = r, objectIntensity
+		}
+	}
+	return best, intensity, !math.IsInf(best, 1)
+}
+
+// hitBox intersects a ray from the sensor with the object's box standing
+// on the ground at st, using the slab method in the box's own frame.
+func (g *Generator) hitBox(o Object, st ObjectState, dx, dy, dz float64) (float64, bool) {
+	sin, cos := math.Sincos(-st.HeadingRad)
+	ox, oy := -st.X, -st.Y
+	origin := [3]float64{ox*cos - oy*sin, ox*sin + oy*cos, 0}
+	dir := [3]float64{dx*cos - dy*sin, dx*sin + dy*cos, dz}
+	ground := -g.scene.SensorHeight
+	lo := [3]float64{-o.Length / 2, -o.Width / 2, ground}
+	hi := [3]float64{o.Length / 2, o.Width / 2, ground + o.Height}
+
+	tmin, tmax := 0.0, math.Inf(1)
+	for i := 0; i < 3; i++ {
+		if dir[i] == 0 {
+			if origin[i] < lo[i] || origin[i] > hi[i] {
+				return 0, false
+			}
+			continue
+		}
+		t1, t2 := (lo[i]-origin[i])/dir[i], (hi[i]-origin[i])/dir[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin, tmax = math.Max(tmin, t1), math.Min(tmax, t2)
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, tmin > 0
+}
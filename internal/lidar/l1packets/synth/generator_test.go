@@ -0,0 +1,196 @@
+package synth
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/parse"
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// parkedCar is one rotation of a car parked 10 m out along +X, facing +Y.
+func parkedCar() Scene {
+	return Scene{
+		DurationSecs: 0.1,
+		Objects: []Object{{
+			ID: "car", Class: "car",
+			Waypoints: []Waypoint{{T: 0, X: 10, Y: 0}, {T: 2, X: 10, Y: 0.001}},
+		}},
+	}
+}
+
+func TestObject_At(t *testing.T) {
+	o := Object{ID: "a", Waypoints: []Waypoint{{T: 1, X: 0, Y: 0}, {T: 3, X: 10, Y: 0}, {T: 4, X: 10, Y: 5}}}
+	if _, ok := o.At(0.5); ok {
+		t.Error("object present before its first waypoint")
+	}
+	st, ok := o.At(2)
+	if !ok || st.X != 5 || st.Speed() != 5 || st.HeadingRad != 0 {
+		t.Errorf("At(2) = %+v, %v", st, ok)
+	}
+	st, _ = o.At(3.5)
+	if st.Y != 2.5 || st.Speed() != 5 || math.Abs(st.HeadingRad-math.Pi/2) > 1e-9 {
+		t.Errorf("At(3.5) = %+v", st)
+	}
+	if _, ok := o.At(4.01); ok {
+		t.Error("object present after its last waypoint")
+	}
+}
+
+func TestScene_Validate(t *testing.T) {
+	for name, s := range map[string]Scene{
+		"missing id":      {Objects: []Object{{Class: "car", Waypoints: LinearPath(0, 0, 1, 0, 1, 0)}}},
+		"duplicate id":    {Objects: []Object{{ID: "a", Class: "car", Waypoints: LinearPath(0, 0, 1, 0, 1, 0)}, {ID: "a", Class: "car", Waypoints: LinearPath(0, 0, 1, 0, 1, 0)}}},
+		"unknown class":   {Objects: []Object{{ID: "a", Class: "tram", Waypoints: LinearPath(0, 0, 1, 0, 1, 0)}}},
+		"one waypoint":    {Objects: []Object{{ID: "a", Class: "car", Waypoints: []Waypoint{{T: 0}}}}},
+		"time goes back":  {Objects: []Object{{ID: "a", Class: "car", Waypoints: []Waypoint{{T: 1}, {T: 1}}}}},
+		"no duration":     {},
+		"motor too fast":  {DurationSecs: 1, MotorRPM: 2400},
+		"negative jitter": {DurationSecs: 1, RangeJitter: -1},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	// A class without defaults is fine with explicit dimensions.
+	tram := Scene{Objects: []Object{{ID: "a", Class: "tram", Length: 30, Width: 2.6, Height: 3.5, Waypoints: LinearPath(0, 0, 1, 0, 1, 0)}}}
+	if err := tram.Validate(); err != nil {
+		t.Errorf("sized object: %v", err)
+	}
+}
+
+func TestLoadScene(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scene.json")
+	os.WriteFile(path, []byte(`{"seed":3,"objects":[{"id":"p1","class":"pedestrian","waypoints":[{"t":0,"x":5,"y":0},{"t":4,"x":5,"y":6}]}]}`), 0o644)
+	s, err := LoadScene(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Seed != 3 || len(s.Objects) != 1 || s.withDefaults().DurationSecs != 5 {
+		t.Errorf("LoadScene = %+v", s)
+	}
+	os.WriteFile(path, []byte(`{"objects":[{"id":"p1","class":"pedestrian","waypoints":[]}]}`), 0o644)
+	if _, err := LoadScene(path); err == nil {
+		t.Error("expected a validation error")
+	}
+}
+
+func TestScene_WriteTruthCSV(t *testing.T) {
+	s := Scene{DurationSecs: 1, Objects: []Object{{ID: "c", Class: "car", Waypoints: LinearPath(0, 0, 10, 0, 10, 0)}}}
+	var sb strings.Builder
+	if err := s.WriteTruthCSV(&sb, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != 4 || lines[2] != "0.500,"+strconv.FormatInt(defaultStart.Add(500*time.Millisecond).UnixNano(), 10)+",c,car,5.000,0.000,10.000,0.000,10.000" {
+		t.Errorf("truth CSV:\n%s", sb.String())
+	}
+}
+
+// TestGenerator_ParserRoundTrip parses one rotation of a parked car with
+// the real parser and checks every return lands on the surface it was cast
+// against.
+func TestGenerator_ParserRoundTrip(t *testing.T) {
+	g, err := NewGenerator(parkedCar(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Packets() != 180 {
+		t.Fatalf("0.1 s at 600 RPM = %d packets, want 180", g.Packets())
+	}
+	cfg, _ := parse.LoadEmbeddedPandar40PConfig()
+	parser := parse.NewPandar40PParser(*cfg)
+	parser.SetTimestampMode(parse.TimestampModeLiDAR)
+
+	var car, ground, backdrop int
+	for {
+		data, ts, ok := g.Next()
+		if !ok {
+			break
+		}
+		points, err := parser.ParsePacket(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range points {
+			if d := time.Unix(0, p.Timestamp).Sub(ts); d < -time.Millisecond || d > time.Millisecond {
+				t.Fatalf("point time %v is %v from packet time", time.Unix(0, p.Timestamp), d)
+			}
+			x, y, z := l2frames.SphericalToCartesian(p.Distance, p.Azimuth, p.Elevation)
+			const tol = 0.02
+			switch p.Intensity {
+			case objectIntensity:
+				car++
+				// Facing +Y: 1.8 m wide in X, 4.5 m long in Y, 1.5 m tall.
+				if x < 9.1-tol || x > 10.9+tol || math.Abs(y) > 2.25+tol || z < -3-tol || z > -1.5+tol {
+					t.Fatalf("car return at (%.2f, %.2f, %.2f) is off the box", x, y, z)
+				}
+			case groundIntensity:
+				ground++
+				if math.Abs(z+3) > tol {
+					t.Fatalf("ground return at z=%.3f", z)
+				}
+			case backdropIntensity:
+				backdrop++
+				if r := math.Hypot(x, y); math.Abs(r-DefaultBackdropRange) > tol {
+					t.Fatalf("backdrop return at range %.3f", r)
+				}
+			default:
+				t.Fatalf("unexpected intensity %d", p.Intensity)
+			}
+		}
+	}
+	if car < 200 || ground == 0 || backdrop == 0 {
+		t.Errorf("returns: car %d, ground %d, backdrop %d", car, ground, backdrop)
+	}
+}
+
+func TestGenerator_DeterministicPCAP(t *testing.T) {
+	scene := parkedCar()
+	scene.RangeJitter = 0.02
+	scene.Seed = 7
+
+	var a, b bytes.Buffer
+	for _, buf := range []*bytes.Buffer{&a, &b} {
+		g, err := NewGenerator(scene, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := WritePCAP(buf, g, 2369); err != nil || n != 180 {
+			t.Fatalf("WritePCAP = %d, %v", n, err)
+		}
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Fatal("the same scene and seed rendered different captures")
+	}
+
+	r, err := pcapgo.NewReader(&a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, _ := NewGenerator(scene, nil)
+	for i := 0; ; i++ {
+		frame, ci, err := r.ReadPacketData()
+		if err != nil {
+			if i != 180 {
+				t.Errorf("read %d packets back: %v", i, err)
+			}
+			break
+		}
+		want, ts, _ := g.Next()
+		if !ci.Timestamp.Equal(ts) || !bytes.HasSuffix(frame, want) {
+			t.Fatalf("packet %d differs from the generator's", i)
+		}
+	}
+
+	if _, err := WritePCAP(&a, g, 0); err == nil {
+		t.Error("expected an error for port 0")
+	}
+}
@@ -0,0 +1,64 @@
+package synth
+
+import (
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// sensorIP is the source address of written packets: the Pandar40P's
+// factory default.
+var sensorIP = net.IPv4(192, 168, 1, 201).To4()
+
+// WritePCAP renders the generator's remaining packets into w as a
+// nanosecond pcap capture of UDP datagrams from the sensor to port, and
+// returns the number written. The file replays through pcap-analyse and
+// the server's PCAP source like a real recording.
+func WritePCAP(w io.Writer, g *Generator, port int) (int, error) {
+	if port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid UDP port %d", port)
+	}
+	pw := pcapgo.NewWriterNanos(w)
+	if err := pw.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		return 0, err
+	}
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0x02, 0, 0, 0, 0, 0x01},
+		DstMAC:       layers.EthernetBroadcast,
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolUDP,
+		SrcIP:    sensorIP,
+		DstIP:    net.IPv4bcast.To4(),
+	}
+	udp := &layers.UDP{SrcPort: 10000, DstPort: layers.UDPPort(port)}
+	if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+		return 0, err
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+
+	n := 0
+	for {
+		data, ts, ok := g.Next()
+		if !ok {
+			return n, nil
+		}
+		if err := gopacket.SerializeLayers(buf, opts, eth, ip, udp, gopacket.Payload(data)); err != nil {
+			return n, err
+		}
+		frame := buf.Bytes()
+		ci := gopacket.CaptureInfo{Timestamp: ts, CaptureLength: len(frame), Length: len(frame)}
+		if err := pw.WritePacket(ci, frame); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
@@ -0,0 +1,161 @@
+package synth_test
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/parse"
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/synth"
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+	"github.com/banshee-data/velocity.report/internal/lidar/pipeline"
+)
+
+// trackSample is a confirmed track's state at the end of one frame.
+type trackSample struct {
+	t            float64 // seconds into the scene
+	x, y, vx, vy float64
+}
+
+// TestPipeline_RecoversScriptedTracks renders a car and a pedestrian
+// crossing an otherwise empty scene, pushes the packets through the live
+// ingest path and tracking pipeline, and checks a confirmed track follows
+// each scripted object's position and speed.
+func TestPipeline_RecoversScriptedTracks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("renders and tracks seven seconds of packets")
+	}
+	scene := synth.Scene{
+		DurationSecs: 7,
+		RangeJitter:  0.01,
+		Seed:         1,
+		Objects: []synth.Object{
+			// Objects appear after the first second so the background
+			// seeds from the empty scene.
+			{ID: "car", Class: "car", Waypoints: synth.LinearPath(-25, 12, 25, 12, 10, 1.5)},
+			{ID: "walker", Class: "pedestrian", Waypoints: synth.LinearPath(6, -8, -2, -8, 1.5, 1)},
+		},
+	}
+
+	var mu sync.Mutex
+	samples := make(map[string][]trackSample)
+	runScene(t, scene, func(f *l2frames.LiDARFrame, start time.Time, tracker *l5tracks.Tracker) {
+		t := f.EndTimestamp.Sub(start).Seconds()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, tr := range tracker.GetConfirmedTracks() {
+			samples[tr.TrackID] = append(samples[tr.TrackID], trackSample{t, float64(tr.X), float64(tr.Y), float64(tr.VX), float64(tr.VY)})
+		}
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, obj := range scene.Objects {
+		id, errPos, errSpeed, n := bestTrack(obj, samples)
+		if id == "" {
+			t.Errorf("%s: no confirmed track followed it", obj.ID)
+			continue
+		}
+		truth, _ := obj.At(obj.Waypoints[0].T)
+		t.Logf("%s → track %s over %d frames: mean position error %.2f m, mean speed error %.2f m/s (truth %.1f m/s)",
+			obj.ID, id, n, errPos, errSpeed, truth.Speed())
+		if n < 20 {
+			t.Errorf("%s: followed for only %d frames", obj.ID, n)
+		}
+		// The sensor only sees an object's near faces, so cluster centres
+		// sit up to half a width short of the true box centre.
+		if errPos > 1.5 {
+			t.Errorf("%s: mean position error %.2f m, want ≤ 1.5", obj.ID, errPos)
+		}
+		if errSpeed > 0.15*truth.Speed()+0.3 {
+			t.Errorf("%s: mean speed error %.2f m/s against %.1f m/s", obj.ID, errSpeed, truth.Speed())
+		}
+	}
+}
+
+// bestTrack returns the confirmed track that stayed within 2 m of the
+// object for the most frames, with its mean position and speed error over
+// those frames.
+func bestTrack(obj synth.Object, samples map[string][]trackSample) (id string, errPos, errSpeed float64, n int) {
+	for trackID, ss := range samples {
+		var sumPos, sumSpeed float64
+		matched := 0
+		for _, s := range ss {
+			truth, ok := obj.At(s.t)
+			if !ok {
+				continue
+			}
+			d := math.Hypot(s.x-truth.X, s.y-truth.Y)
+			if d > 2 {
+				continue
+			}
+			matched++
+			sumPos += d
+			sumSpeed += math.Abs(math.Hypot(s.vx, s.vy) - truth.Speed())
+		}
+		if matched > n {
+			id, n = trackID, matched
+			errPos, errSpeed = sumPos/float64(matched), sumSpeed/float64(matched)
+		}
+	}
+	return id, errPos, errSpeed, n
+}
+
+// runScene renders scene, pushes the packets through the live ingest path
+// and tracking pipeline, and calls onFrame with the scene's start time
+// after each frame is tracked.
+func runScene(t *testing.T, scene synth.Scene, onFrame func(f *l2frames.LiDARFrame, start time.Time, tracker *l5tracks.Tracker)) {
+	t.Helper()
+	gen, err := synth.NewGenerator(scene, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := gen.Scene().Start
+
+	const sensorID = "synth"
+	bg := l3grid.NewBackgroundManagerDI(sensorID, 40, 1800, l3grid.BackgroundParams{
+		BackgroundUpdateFraction:       0.02,
+		ClosenessSensitivityMultiplier: 3.0,
+		SafetyMarginMetres:             0.5,
+		NoiseRelativeFraction:          0.02,
+		SeedFromFirstObservation:       true,
+	}, nil)
+	tracker := l5tracks.NewTracker(l5tracks.DefaultTrackerConfig())
+	pipe := &pipeline.TrackingPipelineConfig{
+		SensorID:          sensorID,
+		BackgroundManager: bg,
+		Tracker:           tracker,
+		Classifier:        l6objects.NewTrackClassifier(),
+		RemoveGround:      true,
+	}
+	process := pipe.NewFrameCallback()
+
+	fb := l2frames.NewFrameBuilder(l2frames.FrameBuilderConfig{
+		SensorID: sensorID,
+		FrameCallback: func(f *l2frames.LiDARFrame) {
+			process(f)
+			onFrame(f, start, tracker)
+		},
+	})
+
+	cfg, _ := parse.LoadEmbeddedPandar40PConfig()
+	listener := network.NewUDPListener(network.UDPListenerConfig{
+		Source:        gen,
+		UseSourceTime: true,
+		Parser:        parse.NewPandar40PParser(*cfg),
+		FrameBuilder:  fb,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := listener.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	fb.Flush()
+	fb.Close()
+}
@@ -2,6 +2,8 @@
 //
 // Responsibilities: assembling raw points into complete rotation frames,
 // coordinate geometry (polar ↔ Cartesian), and frame-level export.
+// FrameBuilder serves live ingest; FrameIterator is the synchronous,
+// deterministic assembler for offline tools replaying captures.
 // Key types: Point, FrameID, Pose.
 //
 // Dependency rule: L2 may depend on L1, but never on L3+.
@@ -1,9 +1,11 @@
 package l2frames
 
 import (
+	"bufio"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -73,26 +75,38 @@ func ExportPointsToASC(points []PointASC, extraHeader string) (string, error) {
 	}
 	defer f.Close()
 
+	if err := WritePointsASC(f, points, extraHeader); err != nil {
+		return "", err
+	}
+	diagf("Exported %d points to %s", len(points), exportPath)
+	return exportPath, nil
+}
+
+// WritePointsASC writes points in the CloudCompare-compatible ASC layout
+// used by ExportPointsToASC, for callers that choose their own destination.
+// extraHeader is a string describing extra columns (optional).
+func WritePointsASC(w io.Writer, points []PointASC, extraHeader string) error {
+	bw := bufio.NewWriter(w)
+
 	// Write header
-	fmt.Fprintf(f, "# Exported points\n")
-	fmt.Fprintf(f, "# Format: X Y Z Intensity%s\n", extraHeader)
+	fmt.Fprintf(bw, "# Exported points\n")
+	fmt.Fprintf(bw, "# Format: X Y Z Intensity%s\n", extraHeader)
 
 	for _, p := range points {
-		fmt.Fprintf(f, "%.6f %.6f %.6f %d", p.X, p.Y, p.Z, p.Intensity)
+		fmt.Fprintf(bw, "%.6f %.6f %.6f %d", p.X, p.Y, p.Z, p.Intensity)
 		for _, col := range p.Extra {
 			switch v := col.(type) {
 			case int:
-				fmt.Fprintf(f, " %d", v)
+				fmt.Fprintf(bw, " %d", v)
 			case float64:
-				fmt.Fprintf(f, " %.6f", v)
+				fmt.Fprintf(bw, " %.6f", v)
 			case string:
-				fmt.Fprintf(f, " %s", v)
+				fmt.Fprintf(bw, " %s", v)
 			default:
-				fmt.Fprintf(f, " %v", v)
+				fmt.Fprintf(bw, " %v", v)
 			}
 		}
-		fmt.Fprintln(f)
+		fmt.Fprintln(bw)
 	}
-	diagf("Exported %d points to %s", len(points), exportPath)
-	return exportPath, nil
+	return bw.Flush()
 }
@@ -40,6 +40,26 @@ type LiDARFrame struct {
 	PacketGaps        int             // count of missing packets
 	CompletenessRatio float64         // ratio of received/expected packets
 	AzimuthCoverage   float64         // degrees of azimuth covered (0-360)
+
+	// Packet capture timing, from points that carry a capture timestamp;
+	// zero when none do. Capture latency is a point's capture time minus its
+	// Timestamp: sensor-to-host delay plus any buffering, or clock offset
+	// when the sensor and host clocks disagree.
+	StartCaptureTime  time.Time     // earliest packet capture time in frame
+	EndCaptureTime    time.Time     // latest packet capture time in frame
+	MinCaptureLatency time.Duration // smallest capture latency in frame
+	MaxCaptureLatency time.Duration // largest capture latency in frame
+	captureLatencySum time.Duration
+	capturedPoints    int
+}
+
+// MeanCaptureLatency returns the mean capture latency over the frame's
+// points that carry a capture timestamp, or zero if none do.
+func (f *LiDARFrame) MeanCaptureLatency() time.Duration {
+	if f.capturedPoints == 0 {
+		return 0
+	}
+	return f.captureLatencySum / time.Duration(f.capturedPoints)
 }
 
 // FrameBuilder accumulates points from multiple packets into complete rotational frames
@@ -258,19 +278,25 @@ func (fb *FrameBuilder) AddPointsPolar(polar []PointPolar) {
 	pts := make([]Point, 0, len(polar))
 	for _, p := range polar {
 		x, y, z := SphericalToCartesian(p.Distance, p.Azimuth, p.Elevation)
+		var captured time.Time
+		if p.CaptureTimestamp != 0 {
+			captured = time.Unix(0, p.CaptureTimestamp)
+		}
 		pts = append(pts, Point{
-			X:               x,
-			Y:               y,
-			Z:               z,
-			Intensity:       p.Intensity,
-			Distance:        p.Distance,
-			Azimuth:         p.Azimuth,
-			Elevation:       p.Elevation,
-			Channel:         p.Channel,
-			Timestamp:       time.Unix(0, p.Timestamp),
-			BlockID:         p.BlockID,
-			UDPSequence:     p.UDPSequence,
-			RawBlockAzimuth: p.RawBlockAzimuth,
+			X:                x,
+			Y:                y,
+			Z:                z,
+			Intensity:        p.Intensity,
+			Distance:         p.Distance,
+			Azimuth:          p.Azimuth,
+			Elevation:        p.Elevation,
+			Channel:          p.Channel,
+			Timestamp:        time.Unix(0, p.Timestamp),
+			BlockID:          p.BlockID,
+			UDPSequence:      p.UDPSequence,
+			RawBlockAzimuth:  p.RawBlockAzimuth,
+			ReturnIndex:      p.ReturnIndex,
+			CaptureTimestamp: captured,
 		})
 	}
 
@@ -438,6 +464,26 @@ func (fb *FrameBuilder) addPointToCurrentFrame(point Point) {
 		frame.EndTimestamp = point.Timestamp
 	}
 
+	// Update capture timing
+	if !point.CaptureTimestamp.IsZero() {
+		latency := point.CaptureTimestamp.Sub(point.Timestamp)
+		if frame.capturedPoints == 0 {
+			frame.StartCaptureTime, frame.EndCaptureTime = point.CaptureTimestamp, point.CaptureTimestamp
+			frame.MinCaptureLatency, frame.MaxCaptureLatency = latency, latency
+		} else {
+			if point.CaptureTimestamp.Before(frame.StartCaptureTime) {
+				frame.StartCaptureTime = point.CaptureTimestamp
+			}
+			if point.CaptureTimestamp.After(frame.EndCaptureTime) {
+				frame.EndCaptureTime = point.CaptureTimestamp
+			}
+			frame.MinCaptureLatency = min(frame.MinCaptureLatency, latency)
+			frame.MaxCaptureLatency = max(frame.MaxCaptureLatency, latency)
+		}
+		frame.captureLatencySum += latency
+		frame.capturedPoints++
+	}
+
 	// Update azimuth range
 	if point.Azimuth < frame.MinAzimuth {
 		frame.MinAzimuth = point.Azimuth
@@ -2,6 +2,7 @@ package l2frames
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -54,6 +55,42 @@ func (fb *FrameBuilder) Close() {
 	}
 }
 
+// Flush finalises every buffered frame (oldest first) followed by the
+// in-progress frame so they reach the frame callback instead of being
+// discarded on shutdown. Frames are sent with back-pressure rather than
+// dropped. Call Flush once packet input has stopped, then Close to wait
+// for the callback worker to drain. Flush is a no-op after Close.
+func (fb *FrameBuilder) Flush() {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	if fb.closed {
+		return
+	}
+
+	prevBlock := fb.blockOnFrameChannel
+	fb.blockOnFrameChannel = true
+	defer func() { fb.blockOnFrameChannel = prevBlock }()
+
+	buffered := make([]*LiDARFrame, 0, len(fb.frameBuffer))
+	for frameID, frame := range fb.frameBuffer {
+		buffered = append(buffered, frame)
+		delete(fb.frameBuffer, frameID)
+	}
+	sort.Slice(buffered, func(i, j int) bool {
+		return buffered[i].StartTimestamp.Before(buffered[j].StartTimestamp)
+	})
+	for _, frame := range buffered {
+		fb.finalizeFrame(frame, "flush")
+	}
+	flushed := len(buffered)
+	if fb.currentFrame != nil && fb.currentFrame.PointCount >= fb.minFramePoints {
+		flushed++
+	}
+	fb.finalizeCurrentFrame()
+
+	diagf("[FrameBuilder] Flushed %d pending frames for sensor=%s", flushed, fb.sensorID)
+}
+
 // DroppedFrames returns the number of frames dropped due to a full
 // callback channel. Useful for post-run diagnostics.
 func (fb *FrameBuilder) DroppedFrames() uint64 {
@@ -325,14 +362,17 @@ func (fb *FrameBuilder) finalizeFrame(frame *LiDARFrame, reason string) {
 	}
 
 	// lightweight frame-completion logging
-	tracef("[FrameBuilder] Frame completed - ID: %s, Points: %d, Azimuth: %.1f°-%.1f°, Duration: %v, Sensor: %s, reason=%s",
+	tracef("[FrameBuilder] Frame completed - ID: %s, Points: %d, Azimuth: %.1f°-%.1f°, Duration: %v, Sensor: %s, reason=%s, capture_latency=%v/%v/%v",
 		frame.FrameID,
 		frame.PointCount,
 		frame.MinAzimuth,
 		frame.MaxAzimuth,
 		frame.EndTimestamp.Sub(frame.StartTimestamp),
 		frame.SensorID,
-		reason)
+		reason,
+		frame.MinCaptureLatency,
+		frame.MeanCaptureLatency(),
+		frame.MaxCaptureLatency)
 
 	// Determine rotation completeness before export
 	coverage := frameAzimuthCoverage(frame)
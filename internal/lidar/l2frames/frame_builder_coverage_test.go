@@ -883,3 +883,115 @@ func TestBlockOnFrameChannel_ResetNotBlocked(t *testing.T) {
 	<-finalizeDone
 	fb.Close()
 }
+
+func TestFlush_DeliversBufferedAndCurrentFrames(t *testing.T) {
+	var mu sync.Mutex
+	var delivered []string
+
+	fb := NewFrameBuilderDI(FrameBuilderConfig{
+		SensorID:       "test-flush",
+		MinFramePoints: 1,
+		BufferTimeout:  time.Hour, // never finalise via the cleanup timer
+		FrameCallback: func(f *LiDARFrame) {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, f.FrameID)
+		},
+	})
+
+	now := time.Now()
+	fb.mu.Lock()
+	fb.frameBuffer["newer"] = &LiDARFrame{FrameID: "newer", StartTimestamp: now.Add(time.Second), PointCount: 5}
+	fb.frameBuffer["older"] = &LiDARFrame{FrameID: "older", StartTimestamp: now, PointCount: 5}
+	fb.currentFrame = &LiDARFrame{FrameID: "current", StartTimestamp: now.Add(2 * time.Second), PointCount: 5}
+	fb.mu.Unlock()
+
+	fb.Flush()
+	fb.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"older", "newer", "current"}
+	if len(delivered) != len(want) {
+		t.Fatalf("expected %d delivered frames, got %v", len(want), delivered)
+	}
+	for i := range want {
+		if delivered[i] != want[i] {
+			t.Errorf("frame %d: expected %s, got %s", i, want[i], delivered[i])
+		}
+	}
+	if fb.DroppedFrames() != 0 {
+		t.Errorf("expected no dropped frames, got %d", fb.DroppedFrames())
+	}
+}
+
+func TestFlush_AfterCloseIsNoop(t *testing.T) {
+	fb := NewFrameBuilderDI(FrameBuilderConfig{
+		SensorID:      "test-flush-closed",
+		FrameCallback: func(f *LiDARFrame) { t.Errorf("unexpected frame %s", f.FrameID) },
+	})
+	fb.Close()
+	fb.mu.Lock()
+	fb.currentFrame = &LiDARFrame{FrameID: "late", PointCount: 5000}
+	fb.mu.Unlock()
+	fb.Flush()
+}
+
+func TestAddPointsPolar_PreservesIntensityAndReturnIndex(t *testing.T) {
+	fb := NewFrameBuilder(FrameBuilderConfig{SensorID: "return-index-test"})
+	nowNanos := time.Now().UnixNano()
+	fb.AddPointsPolar([]PointPolar{
+		{Distance: 10, Azimuth: 1, Intensity: 42, Timestamp: nowNanos, Channel: 1},
+		{Distance: 12, Azimuth: 1, Intensity: 7, Timestamp: nowNanos, Channel: 1, ReturnIndex: 1},
+	})
+
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	if fb.currentFrame == nil || len(fb.currentFrame.Points) != 2 {
+		t.Fatal("expected both points in the current frame")
+	}
+	for i, p := range fb.currentFrame.Points {
+		polar := fb.currentFrame.PolarPoints[i]
+		if p.Intensity != polar.Intensity || p.ReturnIndex != polar.ReturnIndex {
+			t.Errorf("point %d: intensity/return %d/%d, want %d/%d",
+				i, p.Intensity, p.ReturnIndex, polar.Intensity, polar.ReturnIndex)
+		}
+	}
+	if fb.currentFrame.Points[1].ReturnIndex != 1 {
+		t.Error("second return index lost in Cartesian conversion")
+	}
+}
+
+func TestAddPointsPolar_CaptureTiming(t *testing.T) {
+	fb := NewFrameBuilder(FrameBuilderConfig{SensorID: "capture-timing-test"})
+	sensor := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) int64 { return sensor.Add(d).UnixNano() }
+	fb.AddPointsPolar([]PointPolar{
+		{Distance: 10, Azimuth: 1, Channel: 1, Timestamp: at(0), CaptureTimestamp: at(20 * time.Millisecond)},
+		{Distance: 10, Azimuth: 2, Channel: 1, Timestamp: at(time.Millisecond), CaptureTimestamp: at(41 * time.Millisecond)},
+		{Distance: 10, Azimuth: 3, Channel: 1, Timestamp: at(2 * time.Millisecond)}, // no capture time
+	})
+
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	frame := fb.currentFrame
+	if frame == nil || frame.PointCount != 3 {
+		t.Fatal("expected all points in the current frame")
+	}
+	if !frame.Points[0].CaptureTimestamp.Equal(sensor.Add(20*time.Millisecond)) || !frame.Points[2].CaptureTimestamp.IsZero() {
+		t.Errorf("capture timestamps not carried to Cartesian points: %v, %v",
+			frame.Points[0].CaptureTimestamp, frame.Points[2].CaptureTimestamp)
+	}
+	if !frame.StartCaptureTime.Equal(sensor.Add(20*time.Millisecond)) || !frame.EndCaptureTime.Equal(sensor.Add(41*time.Millisecond)) {
+		t.Errorf("capture range = %v..%v, want +20ms..+41ms", frame.StartCaptureTime, frame.EndCaptureTime)
+	}
+	if frame.MinCaptureLatency != 20*time.Millisecond || frame.MaxCaptureLatency != 40*time.Millisecond {
+		t.Errorf("capture latency min/max = %v/%v, want 20ms/40ms", frame.MinCaptureLatency, frame.MaxCaptureLatency)
+	}
+	if got := frame.MeanCaptureLatency(); got != 30*time.Millisecond {
+		t.Errorf("MeanCaptureLatency = %v, want 30ms over the points with a capture time", got)
+	}
+	if !frame.StartTimestamp.Equal(sensor) {
+		t.Errorf("StartTimestamp = %v, want the point time %v", frame.StartTimestamp, sensor)
+	}
+}
@@ -0,0 +1,212 @@
+package l2frames
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Azimuth-wrap thresholds for FrameIterator: a frame ends when the azimuth
+// drops from the last quadrant into the first, once the frame has passed
+// through the middle half of the rotation.
+const (
+	iteratorWrapFromAzimuth = 270.0
+	iteratorWrapToAzimuth   = 90.0
+)
+
+// PacketParser turns one sensor packet into polar points. It matches
+// network.Parser, so parse.Pandar40PParser satisfies it.
+type PacketParser interface {
+	ParsePacket(packet []byte) ([]PointPolar, error)
+	GetLastMotorSpeed() uint16
+}
+
+// PacketSource delivers raw sensor packets in capture order, whatever the
+// transport. It is the single packet-source contract: network.PacketSource
+// is an alias, so the UDP, PCAP, memory and synthetic sources that feed
+// live ingest also feed FrameIterator.Run.
+type PacketSource interface {
+	// NextPacket blocks until a packet is available and returns its payload
+	// with the time it was captured or received. The payload may be reused
+	// by the next call. It returns io.EOF once the source is exhausted and
+	// the context's error if ctx ends first.
+	NextPacket(ctx context.Context) ([]byte, time.Time, error)
+
+	// Close releases the source's resources.
+	Close() error
+}
+
+// RPMStats summarises the motor speeds reported to a FrameIterator.
+// Min and Mean ignore zero (unknown) readings.
+type RPMStats struct {
+	Samples int     // Number of SetMotorSpeed calls
+	Last    uint16  // Most recent reading
+	Min     uint16  // Lowest non-zero reading
+	Max     uint16  // Highest reading
+	Mean    float64 // Mean of non-zero readings
+	Changes int     // Times the reading changed between calls
+}
+
+// FrameIterator assembles parsed points into complete rotations for
+// offline tools. Unlike FrameBuilder it has no timers, buffering or
+// background goroutines: a frame is handed to the callback synchronously,
+// inside AddPointsPolar, as soon as the azimuth wraps from above 270° to
+// below 90°, so replay output is deterministic. A wrap is only armed once
+// the frame has seen an azimuth between 90° and 270°, so jitter around 0°
+// cannot split a rotation into slivers. Call Flush at the end of the input
+// to emit the final partial rotation with SpinComplete false.
+//
+// Frames carry polar points only (Points is nil) and own their slices.
+// FrameIterator implements network.FrameBuilder and is safe for
+// concurrent use; the callback must not call back into the iterator.
+type FrameIterator struct {
+	sensorID string
+	onFrame  func(*LiDARFrame)
+
+	mu          sync.Mutex
+	points      []PointPolar
+	lastAzimuth float64
+	wrapArmed   bool // the frame has passed through (90°, 270°)
+	frameIndex  int
+
+	rpm    RPMStats
+	rpmSum float64
+	rpmN   int
+}
+
+// NewFrameIterator returns an iterator that calls onFrame for every frame
+// assembled for sensorID.
+func NewFrameIterator(sensorID string, onFrame func(*LiDARFrame)) *FrameIterator {
+	return &FrameIterator{sensorID: sensorID, onFrame: onFrame}
+}
+
+// AddPointsPolar appends points in arrival order, emitting a frame at each
+// azimuth wrap.
+func (it *FrameIterator) AddPointsPolar(points []PointPolar) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	for _, p := range points {
+		if it.wrapArmed && it.lastAzimuth > iteratorWrapFromAzimuth && p.Azimuth < iteratorWrapToAzimuth && len(it.points) > 0 {
+			it.emitLocked(true)
+		}
+		it.points = append(it.points, p)
+		it.lastAzimuth = p.Azimuth
+		if p.Azimuth > iteratorWrapToAzimuth && p.Azimuth < iteratorWrapFromAzimuth {
+			it.wrapArmed = true
+		}
+	}
+}
+
+// Flush emits the points gathered since the last wrap as a partial frame.
+func (it *FrameIterator) Flush() {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if len(it.points) > 0 {
+		it.emitLocked(false)
+	}
+}
+
+// SetMotorSpeed records the motor speed reported by the parser.
+func (it *FrameIterator) SetMotorSpeed(rpm uint16) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	if it.rpm.Samples > 0 && rpm != it.rpm.Last && it.rpm.Last != 0 {
+		it.rpm.Changes++
+	}
+	it.rpm.Samples++
+	it.rpm.Last = rpm
+	if rpm > it.rpm.Max {
+		it.rpm.Max = rpm
+	}
+	if rpm > 0 {
+		if it.rpm.Min == 0 || rpm < it.rpm.Min {
+			it.rpm.Min = rpm
+		}
+		it.rpmSum += float64(rpm)
+		it.rpmN++
+		it.rpm.Mean = it.rpmSum / float64(it.rpmN)
+	}
+}
+
+// RPMStats returns a summary of the motor speeds seen so far.
+func (it *FrameIterator) RPMStats() RPMStats {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.rpm
+}
+
+// FramesEmitted returns the number of frames passed to the callback,
+// including a partial frame emitted by Flush.
+func (it *FrameIterator) FramesEmitted() int {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.frameIndex
+}
+
+// Run parses every packet from src, feeds the points through the iterator
+// and flushes the final partial frame. It returns nil at io.EOF, the
+// context error on cancellation, or the first source error. Packets the
+// parser rejects are skipped. The caller still owns src and closes it.
+func (it *FrameIterator) Run(ctx context.Context, src PacketSource, parser PacketParser) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		packet, _, err := src.NextPacket(ctx)
+		if errors.Is(err, io.EOF) {
+			it.Flush()
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return fmt.Errorf("read packet: %w", err)
+		}
+		points, err := parser.ParsePacket(packet)
+		if err != nil {
+			diagf("FrameIterator: skipping packet: %v", err)
+			continue
+		}
+		it.SetMotorSpeed(parser.GetLastMotorSpeed())
+		it.AddPointsPolar(points)
+	}
+}
+
+// emitLocked hands the gathered points to the callback as one frame and
+// starts the next. MUST be called while holding it.mu.
+func (it *FrameIterator) emitLocked(spinComplete bool) {
+	frame := &LiDARFrame{
+		FrameID:        fmt.Sprintf("%s-frame-%d", it.sensorID, it.frameIndex),
+		SensorID:       it.sensorID,
+		StartTimestamp: time.Unix(0, it.points[0].Timestamp),
+		EndTimestamp:   time.Unix(0, it.points[len(it.points)-1].Timestamp),
+		PolarPoints:    it.points,
+		PointCount:     len(it.points),
+		MinAzimuth:     360.0,
+		SpinComplete:   spinComplete,
+	}
+	for _, p := range it.points {
+		if p.Azimuth < frame.MinAzimuth {
+			frame.MinAzimuth = p.Azimuth
+		}
+		if p.Azimuth > frame.MaxAzimuth {
+			frame.MaxAzimuth = p.Azimuth
+		}
+	}
+	frame.AzimuthCoverage = frame.MaxAzimuth - frame.MinAzimuth
+
+	it.frameIndex++
+	it.wrapArmed = false
+	// The frame keeps the slice; the next frame starts a fresh one sized
+	// like this rotation.
+	it.points = make([]PointPolar, 0, cap(it.points))
+	if it.onFrame != nil {
+		it.onFrame(frame)
+	}
+}
@@ -0,0 +1,175 @@
+package l2frames
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// rotationPoints returns n points sweeping one rotation from startAz,
+// timestamped 1 ms apart from start.
+func rotationPoints(start time.Time, startAz float64, n int) []PointPolar {
+	points := make([]PointPolar, n)
+	for i := range points {
+		az := startAz + float64(i)*360/float64(n)
+		for az >= 360 {
+			az -= 360
+		}
+		points[i] = PointPolar{Channel: 1, Azimuth: az, Distance: 10, Timestamp: start.Add(time.Duration(i) * time.Millisecond).UnixNano()}
+	}
+	return points
+}
+
+func TestFrameIterator_SplitsOnAzimuthWrap(t *testing.T) {
+	var frames []*LiDARFrame
+	it := NewFrameIterator("iter", func(f *LiDARFrame) { frames = append(frames, f) })
+
+	start := time.Unix(1700000000, 0)
+	var input []PointPolar
+	for r := 0; r < 3; r++ {
+		input = append(input, rotationPoints(start.Add(time.Duration(r)*100*time.Millisecond), 0, 100)...)
+	}
+	input = append(input, rotationPoints(start.Add(300*time.Millisecond), 0, 40)[:40]...) // partial
+	// Packets of 7 points, so wraps fall mid-packet.
+	for i := 0; i < len(input); i += 7 {
+		it.AddPointsPolar(input[i:min(i+7, len(input))])
+	}
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames before Flush, want the 3 complete rotations", len(frames))
+	}
+	it.Flush()
+	it.Flush() // nothing left
+
+	if len(frames) != 4 || it.FramesEmitted() != 4 {
+		t.Fatalf("got %d frames after Flush, want 4", len(frames))
+	}
+	total := 0
+	for i, f := range frames {
+		total += f.PointCount
+		if f.PolarPoints[0].Azimuth != 0 {
+			t.Errorf("frame %d starts at azimuth %v, want 0", i, f.PolarPoints[0].Azimuth)
+		}
+		if want := start.Add(time.Duration(i) * 100 * time.Millisecond); !f.StartTimestamp.Equal(want) {
+			t.Errorf("frame %d starts at %v, want %v", i, f.StartTimestamp, want)
+		}
+		if f.SpinComplete != (i < 3) {
+			t.Errorf("frame %d SpinComplete = %v", i, f.SpinComplete)
+		}
+	}
+	if total != len(input) {
+		t.Errorf("frames hold %d points, want every input point (%d)", total, len(input))
+	}
+	if frames[3].PointCount != 40 {
+		t.Errorf("partial frame has %d points, want 40", frames[3].PointCount)
+	}
+	// Frames own their points: later input must not overwrite them.
+	if &frames[0].PolarPoints[0] == &frames[1].PolarPoints[0] {
+		t.Error("frames share a backing array")
+	}
+}
+
+func TestFrameIterator_IgnoresJitterAroundZero(t *testing.T) {
+	var frames []*LiDARFrame
+	it := NewFrameIterator("iter", func(f *LiDARFrame) { frames = append(frames, f) })
+
+	// A rotation ends with azimuths dithering across 0°, then the next
+	// rotation dithers again before sweeping on.
+	var input []PointPolar
+	for _, az := range []float64{
+		10, 120, 240, 350, // rotation 1
+		2, 359.5, 1, 359.8, 3, 150, 300, // rotation 2, jitter at the start
+		0.5, 359.9, 1.5, // rotation 3
+	} {
+		input = append(input, PointPolar{Azimuth: az, Timestamp: int64(len(input))})
+	}
+	it.AddPointsPolar(input)
+	it.Flush()
+
+	want := []int{4, 7, 3}
+	if len(frames) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(frames), len(want))
+	}
+	for i, f := range frames {
+		if f.PointCount != want[i] {
+			t.Errorf("frame %d has %d points, want %d", i, f.PointCount, want[i])
+		}
+	}
+}
+
+func TestFrameIterator_RPMStats(t *testing.T) {
+	it := NewFrameIterator("iter", nil)
+	for _, rpm := range []uint16{0, 600, 600, 1200, 0, 1200} {
+		it.SetMotorSpeed(rpm)
+	}
+	got := it.RPMStats()
+	want := RPMStats{Samples: 6, Last: 1200, Min: 600, Max: 1200, Mean: 900, Changes: 2}
+	if got != want {
+		t.Errorf("RPMStats = %+v, want %+v", got, want)
+	}
+}
+
+type slicePacketSource struct {
+	packets [][]byte
+	err     error
+}
+
+func (s *slicePacketSource) NextPacket(ctx context.Context) ([]byte, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+	if len(s.packets) == 0 {
+		if s.err != nil {
+			return nil, time.Time{}, s.err
+		}
+		return nil, time.Time{}, io.EOF
+	}
+	p := s.packets[0]
+	s.packets = s.packets[1:]
+	return p, time.Time{}, nil
+}
+
+func (s *slicePacketSource) Close() error { return nil }
+
+// byteParser turns each payload byte into one point at that azimuth × 10°.
+type byteParser struct{ rpm uint16 }
+
+func (p *byteParser) ParsePacket(packet []byte) ([]PointPolar, error) {
+	if len(packet) == 0 {
+		return nil, errors.New("empty packet")
+	}
+	points := make([]PointPolar, len(packet))
+	for i, b := range packet {
+		points[i] = PointPolar{Channel: 1, Azimuth: float64(b) * 10, Distance: 5}
+	}
+	p.rpm += 600
+	return points, nil
+}
+
+func (p *byteParser) GetLastMotorSpeed() uint16 { return p.rpm }
+
+func TestFrameIterator_Run(t *testing.T) {
+	var sizes []int
+	it := NewFrameIterator("iter", func(f *LiDARFrame) { sizes = append(sizes, f.PointCount) })
+	src := &slicePacketSource{packets: [][]byte{{0, 9, 18}, {}, {30, 35, 0}, {9}}}
+	if err := it.Run(context.Background(), src, &byteParser{}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(sizes) != 2 || sizes[0] != 5 || sizes[1] != 2 {
+		t.Errorf("frame sizes = %v, want [5 2]", sizes)
+	}
+	if rpm := it.RPMStats(); rpm.Samples != 3 || rpm.Last != 1800 {
+		t.Errorf("RPMStats = %+v, want 3 samples ending at 1800", rpm)
+	}
+
+	boom := errors.New("boom")
+	if err := NewFrameIterator("iter", nil).Run(context.Background(), &slicePacketSource{err: boom}, &byteParser{}); !errors.Is(err, boom) {
+		t.Errorf("source error = %v, want it wrapped", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewFrameIterator("iter", nil).Run(ctx, &slicePacketSource{}, &byteParser{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled Run = %v", err)
+	}
+}
@@ -58,3 +58,21 @@ func TestApplyPose_90DegRotationZ(t *testing.T) {
 		t.Errorf("90-deg Z rotation failed: got (%.6f, %.6f, %.6f), want (0.00, 1.00, 0.00)", wx, wy, wz)
 	}
 }
+
+func TestApplyInversePose_RoundTrip(t *testing.T) {
+	// Rotation by 30° about Z and 10° about X, then translation.
+	sz, cz := math.Sincos(30 * math.Pi / 180)
+	sx, cx := math.Sincos(10 * math.Pi / 180)
+	T := [16]float64{
+		cz, -sz * cx, sz * sx, 12,
+		sz, cz * cx, -cz * sx, -4,
+		0, sx, cx, 3,
+		0, 0, 0, 1,
+	}
+	wx, wy, wz := l2frames.ApplyPose(1.5, -2.0, 0.5, T)
+	x, y, z := l2frames.ApplyInversePose(wx, wy, wz, T)
+	const eps = 1e-9
+	if math.Abs(x-1.5) > eps || math.Abs(y+2.0) > eps || math.Abs(z-0.5) > eps {
+		t.Errorf("inverse pose round trip failed: got (%.6f, %.6f, %.6f), want (1.50, -2.00, 0.50)", x, y, z)
+	}
+}
@@ -17,6 +17,12 @@ type PointPolar struct {
 	BlockID         int
 	UDPSequence     uint32
 	RawBlockAzimuth uint16 // Original block azimuth from packet (0.01 deg units)
+	ReturnIndex     uint8  // 0 for single-return mode or the first return of a dual-return pair, 1 for the second
+
+	// CaptureTimestamp is when the host received the point's packet (unix
+	// nanos): the PCAP record time on replay, the receive time live. Unlike
+	// Timestamp it never comes from the sensor clock. Zero if unknown.
+	CaptureTimestamp int64
 }
 
 // Point represents a point in sensor Cartesian coordinates.
@@ -38,6 +44,12 @@ type Point struct {
 	// Packet tracking for completeness validation
 	UDPSequence     uint32 // UDP sequence number for gap detection
 	RawBlockAzimuth uint16 // Original block azimuth from packet (0.01 deg units)
+
+	// Multi-return metadata
+	ReturnIndex uint8 // See PointPolar.ReturnIndex
+
+	// Host packet capture time; zero if unknown (see PointPolar.CaptureTimestamp)
+	CaptureTimestamp time.Time
 }
 
 // SphericalToCartesian converts spherical coordinates to Cartesian.
@@ -65,3 +77,13 @@ func ApplyPose(x, y, z float64, T [16]float64) (wx, wy, wz float64) {
 	wz = T[8]*x + T[9]*y + T[10]*z + T[11]
 	return
 }
+
+// ApplyInversePose maps point (x,y,z) back through a rigid 4x4 row-major
+// transform T: the inverse of ApplyPose for a rotation plus translation.
+func ApplyInversePose(x, y, z float64, T [16]float64) (sx, sy, sz float64) {
+	dx, dy, dz := x-T[3], y-T[7], z-T[11]
+	sx = T[0]*dx + T[4]*dy + T[8]*dz
+	sy = T[1]*dx + T[5]*dy + T[9]*dz
+	sz = T[2]*dx + T[6]*dy + T[10]*dz
+	return
+}
@@ -59,6 +59,39 @@ type BackgroundParams struct {
 	// LockedBaselineMultiplier) are considered background. Default: 4.0.
 	LockedBaselineMultiplier float32
 
+	// VarianceThresholdK, when > 0, enables the per-cell variance gate: an
+	// observation within RangeMeanMeters ± VarianceThresholdK·σ of a cell's
+	// windowed Welford statistics is accepted as background, in addition to
+	// the global closeness test. Helps cells with inherently jittery
+	// backgrounds (foliage). Zero disables the gate. Typical value: 3.0.
+	VarianceThresholdK float32
+
+	// AzimuthToleranceDeg, when > 0, makes the closeness test anisotropic.
+	// A return is also background if it falls inside the ellipse
+	// (Δr/r_tol)² + (Δθ/AzimuthToleranceDeg)² ≤ 1 of its own cell or a
+	// nearby cell on the same ring, where Δθ is the angle to that cell's
+	// nearest edge. Range noise and azimuth jitter get separate tolerances,
+	// so a cell beside a depth edge is not triggered when the near
+	// surface's return wobbles into it. Zero disables the test.
+	AzimuthToleranceDeg float32
+	// RangeToleranceSigmaK sets the ellipse's range semi-axis r_tol to K·σ
+	// of the compared cell's windowed statistics plus the safety margin.
+	// Zero, or a cell with too few samples, uses that cell's isotropic
+	// closeness threshold instead.
+	RangeToleranceSigmaK float32
+
+	// RegionWarmupMinFrames, when > 0, adds a per-region warmup after
+	// regions are identified: a region emits no foreground until it has seen
+	// this many quiet frames, scaled by its variance category (see
+	// assignRegionParams). Busy regions see fewer quiet frames and so warm
+	// up later than static ones. Zero disables per-region warmup.
+	RegionWarmupMinFrames int
+
+	// ModelType selects the per-cell background model: the running mean
+	// (empty or BackgroundModelMean), a running median or a Gaussian
+	// mixture. See background_model.go.
+	ModelType BackgroundModelType
+
 	// M3.5 Split Streaming parameters
 	// SensorMovementForegroundThreshold is the fraction of points that must be
 	// classified as foreground to trigger sensor movement detection. Default: 0.20 (20%).
@@ -90,9 +123,11 @@ type BackgroundParams struct {
 
 // RegionParams defines parameters that can vary per region
 type RegionParams struct {
-	NoiseRelativeFraction      float32 `json:"noise_relative_fraction"`     // noise threshold for this region
-	NeighbourConfirmationCount int     `json:"neighbor_confirmation_count"` // neighbour confirmation for this region
-	SettleUpdateFraction       float32 `json:"settle_update_fraction"`      // alpha during settling for this region
+	NoiseRelativeFraction      float32 `json:"noise_relative_fraction"`         // noise threshold for this region
+	NeighbourConfirmationCount int     `json:"neighbor_confirmation_count"`     // neighbour confirmation for this region
+	SettleUpdateFraction       float32 `json:"settle_update_fraction"`          // alpha during settling for this region
+	WarmupMinFrames            int     `json:"warmup_min_frames,omitempty"`     // quiet frames before this region emits foreground (0 = none)
+	AzimuthToleranceDeg        float32 `json:"azimuth_tolerance_deg,omitempty"` // anisotropic azimuth tolerance for this region (0 = global)
 }
 
 // Region represents a contiguous spatial region with distinct parameters
@@ -104,6 +139,23 @@ type Region struct {
 	// Statistics for region characterization
 	MeanVariance float64 // mean variance of cells in this region during settling
 	CellCount    int     // number of cells in this region
+	// WarmupQuietFrames counts quiet frames seen since identification,
+	// up to Params.WarmupMinFrames, and WarmupFrames counts every frame in
+	// which the region observed points (runtime only, not persisted).
+	WarmupQuietFrames int
+	WarmupFrames      int
+}
+
+// regionWarmupMaxFactor caps per-region warmup: a region that has observed
+// this many times its WarmupMinFrames without enough quiet ones completes
+// anyway, so a road that is never quiet still emits foreground.
+const regionWarmupMaxFactor = 4
+
+// WarmupComplete reports whether the region has seen enough quiet frames
+// to emit foreground, or has reached the warmup cap.
+func (r *Region) WarmupComplete() bool {
+	return r.WarmupQuietFrames >= r.Params.WarmupMinFrames ||
+		r.WarmupFrames >= regionWarmupMaxFactor*r.Params.WarmupMinFrames
 }
 
 // RegionManager handles dynamic region identification and management
@@ -177,6 +229,50 @@ type BackgroundCell struct {
 	// LockedAtCount is the TimesSeenCount when baseline was last locked.
 	// Used to detect when we should update the locked values.
 	LockedAtCount uint32
+
+	// Windowed Welford statistics over background-classified ranges. Unlike
+	// RangeSpreadMeters (an EMA of absolute deviation) these give a true
+	// per-cell variance; see RangeStdDevMeters and VarianceThresholdK.
+	RangeSampleCount uint32  // Samples accumulated, capped at rangeVarianceWindow
+	RangeMeanMeters  float32 // Running mean range (metres)
+	RangeM2          float32 // Running sum of squared deviations (m²)
+}
+
+// rangeVarianceWindow caps the Welford sample count so the statistics keep
+// adapting to slow scene change, behaving like an EMA with alpha 1/N once
+// full.
+const rangeVarianceWindow = 1000
+
+// minVarianceSamples is the number of samples a cell needs before its
+// variance gate is trusted.
+const minVarianceSamples = 20
+
+// addRangeSample folds a background range observation into the cell's
+// windowed Welford statistics.
+func (c *BackgroundCell) addRangeSample(r float64) {
+	n := float64(c.RangeSampleCount)
+	m2 := float64(c.RangeM2)
+	if c.RangeSampleCount >= rangeVarianceWindow {
+		// Window full: decay M2 so the oldest sample's share drops out.
+		m2 *= (n - 1) / n
+	} else {
+		c.RangeSampleCount++
+		n++
+	}
+	delta := r - float64(c.RangeMeanMeters)
+	mean := float64(c.RangeMeanMeters) + delta/n
+	m2 += delta * (r - mean)
+	c.RangeMeanMeters = float32(mean)
+	c.RangeM2 = float32(math.Max(m2, 0))
+}
+
+// RangeStdDevMeters returns the sample standard deviation of background
+// ranges observed in this cell, or 0 with fewer than two samples.
+func (c *BackgroundCell) RangeStdDevMeters() float32 {
+	if c.RangeSampleCount < 2 {
+		return 0
+	}
+	return float32(math.Sqrt(float64(c.RangeM2) / float64(c.RangeSampleCount-1)))
 }
 
 // BackgroundGrid enhanced for schema persistence and 100-track performance
@@ -223,6 +319,12 @@ type BackgroundGrid struct {
 	// Optional per-ring elevation angles (degrees) for converting polar->cartesian.
 	// If populated (len == Rings) ToASCPoints will use these to compute Z = r*sin(elev).
 	RingElevations []float64
+	// observedElevations collects per-ring elevations from incoming points
+	// until every ring has been seen, when RingElevations was not set
+	// explicitly. observedRings marks the rings filled so far.
+	observedElevations []float64
+	observedRings      []bool
+	observedRingCount  int
 	// LastObservedNoiseRel tracks the last noise_relative value observed by
 	// ProcessFramePolar so we can log when the runtime value changes.
 	LastObservedNoiseRel float32
@@ -239,6 +341,11 @@ type BackgroundGrid struct {
 	// prevRegionIDs stores per-cell region assignments from the previous
 	// EvaluateSettling call for computing RegionStability.
 	prevRegionIDs []int
+
+	// model is the per-cell background model for modelType, nil for the
+	// running mean. Built on first use; guarded by mu.
+	model     cellModel
+	modelType BackgroundModelType
 }
 
 // Helper to index Cells: idx = ring*AzimuthBins + azBin
@@ -350,3 +457,53 @@ func (g *BackgroundGrid) effectiveCellParams(cellIdx int, defaultNoiseRel float6
 	}
 	return
 }
+
+// effectiveAzimuthTolerance returns the anisotropic azimuth tolerance for a
+// cell: its region's override when one is set, otherwise the default.
+func (g *BackgroundGrid) effectiveAzimuthTolerance(cellIdx int, defaultTol float64) float64 {
+	if g.RegionMgr == nil || !g.RegionMgr.IdentificationComplete {
+		return defaultTol
+	}
+	if rp := g.RegionMgr.GetRegionParams(g.RegionMgr.GetRegionForCell(cellIdx)); rp != nil && rp.AzimuthToleranceDeg > 0 {
+		return float64(rp.AzimuthToleranceDeg)
+	}
+	return defaultTol
+}
+
+// withinClosenessEllipse reports whether a return at az degrees and dist
+// metres on ring lies inside the anisotropic acceptance ellipse of its own
+// cell (azBin) or of a same-ring cell whose nearest edge is within azTol
+// degrees. The range semi-axis is rangeK·σ of the compared cell plus
+// safety when rangeK > 0 and the cell has enough samples, and otherwise
+// the cell's isotropic closeness threshold.
+func (g *BackgroundGrid) withinClosenessEllipse(ring, azBin int, az, dist, azTol, rangeK, closenessMultiplier, noiseRel, safety float64) bool {
+	binWidth := 360.0 / float64(g.AzimuthBins)
+	offset := az - float64(azBin)*binWidth
+	reach := int(math.Ceil(azTol / binWidth))
+	for d := -reach; d <= reach; d++ {
+		dAz := 0.0
+		if d > 0 {
+			dAz = float64(d)*binWidth - offset
+		} else if d < 0 {
+			dAz = offset + float64(-d-1)*binWidth
+		}
+		if dAz > azTol {
+			continue
+		}
+		n := &g.Cells[g.Idx(ring, ((azBin+d)%g.AzimuthBins+g.AzimuthBins)%g.AzimuthBins)]
+		if n.TimesSeenCount == 0 {
+			continue
+		}
+		rTol := closenessMultiplier*(float64(n.RangeSpreadMeters)+noiseRel*float64(n.AverageRangeMeters)+0.01) + safety
+		if rangeK > 0 && n.RangeSampleCount >= minVarianceSamples {
+			rTol = rangeK*float64(n.RangeStdDevMeters()) + safety
+		}
+		rTol = math.Max(rTol, 0.01)
+		dr := (dist - float64(n.AverageRangeMeters)) / rTol
+		da := dAz / azTol
+		if dr*dr+da*da <= 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package l3grid
+
+// GridCellValue is the per-cell state streamed to live grid views.
+type GridCellValue struct {
+	Range     float32
+	TimesSeen uint32
+}
+
+// GridCellValues copies every cell's range and times-seen count, indexed by
+// ring*azimuthBins + azimuthBin. It returns nil values for a nil manager.
+func (bm *BackgroundManager) GridCellValues() (rings, azimuthBins int, values []GridCellValue) {
+	if bm == nil || bm.Grid == nil {
+		return 0, 0, nil
+	}
+
+	g := bm.Grid
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	values = make([]GridCellValue, len(g.Cells))
+	for i := range g.Cells {
+		values[i] = GridCellValue{Range: g.Cells[i].AverageRangeMeters, TimesSeen: g.Cells[i].TimesSeenCount}
+	}
+	return g.Rings, g.AzimuthBins, values
+}
+
+// GridDeltaOptions controls which cell changes DiffGridCellValues reports.
+// Range converges by EMA and times-seen counts climb on every observation,
+// so without thresholds a settled grid would report every cell every frame.
+type GridDeltaOptions struct {
+	// MinRangeDelta is the smallest range change reported (metres).
+	MinRangeDelta float32
+	// TimesSeenCap clamps times-seen before comparison, so cells stop
+	// reporting once they reach it; 0 compares counts unclamped.
+	TimesSeenCap uint32
+}
+
+func (o GridDeltaOptions) clampSeen(n uint32) uint32 {
+	if o.TimesSeenCap > 0 && n > o.TimesSeenCap {
+		return o.TimesSeenCap
+	}
+	return n
+}
+
+// DiffGridCellValues appends to dst the indices of cells in cur that differ
+// from sent beyond opts, and copies those cells into sent, so sent always
+// holds the last reported state. Comparing against the last reported value
+// rather than the previous sample keeps slow drift from going unreported.
+// sent and cur must have the same length.
+func DiffGridCellValues(sent, cur []GridCellValue, opts GridDeltaOptions, dst []int) []int {
+	for i := range cur {
+		prev, next := sent[i], cur[i]
+		dr := next.Range - prev.Range
+		if dr < 0 {
+			dr = -dr
+		}
+		rangeChanged := dr > 0 && dr >= opts.MinRangeDelta
+		// Emptying or first filling a cell is always reported.
+		filled := (prev.TimesSeen == 0) != (next.TimesSeen == 0)
+		if !rangeChanged && !filled && opts.clampSeen(prev.TimesSeen) == opts.clampSeen(next.TimesSeen) {
+			continue
+		}
+		sent[i] = next
+		dst = append(dst, i)
+	}
+	return dst
+}
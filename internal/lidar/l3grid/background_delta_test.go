@@ -0,0 +1,77 @@
+package l3grid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGridCellValues(t *testing.T) {
+	var nilBM *BackgroundManager
+	if rings, az, values := nilBM.GridCellValues(); rings != 0 || az != 0 || values != nil {
+		t.Errorf("nil manager returned %d, %d, %v", rings, az, values)
+	}
+
+	grid := &BackgroundGrid{Rings: 2, AzimuthBins: 3, Cells: make([]BackgroundCell, 6)}
+	grid.Cells[4] = BackgroundCell{AverageRangeMeters: 7.5, TimesSeenCount: 12, RangeSpreadMeters: 0.2}
+	bm := &BackgroundManager{Grid: grid}
+
+	rings, az, values := bm.GridCellValues()
+	if rings != 2 || az != 3 || len(values) != 6 {
+		t.Fatalf("got rings=%d az=%d len=%d", rings, az, len(values))
+	}
+	if values[4] != (GridCellValue{Range: 7.5, TimesSeen: 12}) {
+		t.Errorf("cell 4 = %+v", values[4])
+	}
+	// The copy is independent of the grid.
+	values[4].Range = 0
+	if grid.Cells[4].AverageRangeMeters != 7.5 {
+		t.Error("GridCellValues aliased the grid")
+	}
+}
+
+func TestDiffGridCellValues(t *testing.T) {
+	opts := GridDeltaOptions{MinRangeDelta: 0.05, TimesSeenCap: 10}
+	sent := []GridCellValue{
+		{Range: 5, TimesSeen: 3},   // 0: range drifts in small steps
+		{Range: 5, TimesSeen: 3},   // 1: still learning
+		{Range: 5, TimesSeen: 10},  // 2: settled, count keeps climbing
+		{Range: 5, TimesSeen: 2},   // 3: cleared
+		{},                         // 4: first observation
+		{Range: 5, TimesSeen: 100}, // 5: unchanged
+	}
+	cur := []GridCellValue{
+		{Range: 5.03, TimesSeen: 3},
+		{Range: 5, TimesSeen: 4},
+		{Range: 5, TimesSeen: 250},
+		{},
+		{Range: 0.01, TimesSeen: 1},
+		{Range: 5, TimesSeen: 100},
+	}
+
+	got := DiffGridCellValues(sent, cur, opts, nil)
+	if want := []int{1, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("changed = %v, want %v", got, want)
+	}
+	if sent[1].TimesSeen != 4 || sent[3] != (GridCellValue{}) || sent[4].TimesSeen != 1 {
+		t.Errorf("sent not updated for reported cells: %+v", sent)
+	}
+	if sent[0].Range != 5 || sent[2].TimesSeen != 10 {
+		t.Errorf("sent updated for unreported cells: %+v", sent)
+	}
+
+	// Drift below the threshold accumulates against the last reported
+	// value until it is large enough to report.
+	cur[0].Range = 5.06
+	if got := DiffGridCellValues(sent, cur, opts, nil); !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("accumulated drift: changed = %v, want [0]", got)
+	}
+	if got := DiffGridCellValues(sent, cur, opts, nil); len(got) != 0 {
+		t.Errorf("second diff of identical state reported %v", got)
+	}
+
+	// With zero options every change is reported.
+	cur[2].TimesSeen = 251
+	if got := DiffGridCellValues(sent, cur, GridDeltaOptions{}, nil); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("unthresholded diff = %v, want [2]", got)
+	}
+}
@@ -262,7 +262,11 @@ type RegionInfo struct {
 	CellCount    int          `json:"cell_count"`
 	MeanVariance float64      `json:"mean_variance"`
 	Params       RegionParams `json:"params"`
-	Cells        []struct {
+	// Per-region warmup progress: quiet frames seen against
+	// params.warmup_min_frames.
+	WarmupQuietFrames int  `json:"warmup_quiet_frames"`
+	WarmupComplete    bool `json:"warmup_complete"`
+	Cells             []struct {
 		Ring       int     `json:"ring"`
 		AzimuthDeg float32 `json:"azimuth_deg"`
 	} `json:"cells,omitempty"` // Optional: can be large, include only on request
@@ -318,6 +322,9 @@ func (bm *BackgroundManager) GetRegionDebugInfo(includeCells bool) *RegionDebugI
 			CellCount:    region.CellCount,
 			MeanVariance: region.MeanVariance,
 			Params:       region.Params,
+
+			WarmupQuietFrames: region.WarmupQuietFrames,
+			WarmupComplete:    region.WarmupComplete(),
 		}
 
 		// Optionally include cell list (can be large)
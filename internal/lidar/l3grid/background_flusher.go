@@ -14,6 +14,14 @@ type Persister interface {
 	Persist(store BgStore, reason string) error
 }
 
+// SnapshotPolicy is implemented by Persisters that decide when a snapshot
+// is worth taking. A flusher whose manager implements it treats its interval
+// as a polling cadence and persists only when SnapshotDue reports a snapshot
+// is due, using the returned reason when it is not empty.
+type SnapshotPolicy interface {
+	SnapshotDue(now, lastFlush time.Time) (reason string, due bool)
+}
+
 type flusherLogger interface {
 	Printf(format string, args ...interface{})
 }
@@ -36,6 +44,11 @@ type BackgroundFlusher struct {
 	running  bool
 	stopCh   chan struct{}
 	doneCh   chan struct{}
+
+	// lastFlush is when the flusher started or last persisted successfully,
+	// passed to a SnapshotPolicy. Protected by mu.
+	lastFlush time.Time
+	now       func() time.Time
 }
 
 // BackgroundFlusherConfig contains configuration for BackgroundFlusher.
@@ -44,7 +57,9 @@ type BackgroundFlusherConfig struct {
 	Manager Persister
 	// Store is the database store for persistence
 	Store BgStore
-	// Interval is how often to flush (e.g., 60*time.Second)
+	// Interval is how often to flush (e.g., 60*time.Second). When Manager
+	// implements SnapshotPolicy it is how often to check whether a flush
+	// is due.
 	Interval time.Duration
 	// Reason is the reason string to use for flushes (e.g., "periodic_flush")
 	Reason string
@@ -68,6 +83,7 @@ func NewBackgroundFlusher(cfg BackgroundFlusherConfig) *BackgroundFlusher {
 		logger:   logger,
 		stopCh:   make(chan struct{}),
 		doneCh:   make(chan struct{}),
+		now:      time.Now,
 	}
 }
 
@@ -87,6 +103,7 @@ func (f *BackgroundFlusher) Run(ctx context.Context) error {
 		return nil // already running
 	}
 	f.running = true
+	f.lastFlush = f.now()
 	f.stopCh = make(chan struct{})
 	f.doneCh = make(chan struct{})
 	f.mu.Unlock()
@@ -119,7 +136,7 @@ func (f *BackgroundFlusher) Run(ctx context.Context) error {
 			f.flushFinal()
 			return nil
 		case <-ticker.C:
-			f.flush()
+			f.flushIfDue()
 		}
 	}
 }
@@ -155,11 +172,40 @@ func (f *BackgroundFlusher) flush() {
 	if f.manager == nil || f.store == nil {
 		return
 	}
-	if err := f.manager.Persist(f.store, f.reason); err != nil {
+	f.persist(f.reason)
+}
+
+// flushIfDue flushes on a tick, first consulting the manager's
+// SnapshotPolicy if it has one.
+func (f *BackgroundFlusher) flushIfDue() {
+	if f.manager == nil || f.store == nil {
+		return
+	}
+	reason := f.reason
+	if policy, ok := f.manager.(SnapshotPolicy); ok {
+		f.mu.Lock()
+		lastFlush := f.lastFlush
+		f.mu.Unlock()
+		policyReason, due := policy.SnapshotDue(f.now(), lastFlush)
+		if !due {
+			return
+		}
+		if policyReason != "" {
+			reason = policyReason
+		}
+	}
+	f.persist(reason)
+}
+
+func (f *BackgroundFlusher) persist(reason string) {
+	if err := f.manager.Persist(f.store, reason); err != nil {
 		f.logger.Printf("BackgroundFlusher: error flushing: %v", err)
-	} else {
-		f.logger.Printf("BackgroundFlusher: grid flushed to database")
+		return
 	}
+	f.mu.Lock()
+	f.lastFlush = f.now()
+	f.mu.Unlock()
+	f.logger.Printf("BackgroundFlusher: grid flushed to database (reason=%s)", reason)
 }
 
 // flushFinal performs a final flush before shutdown.
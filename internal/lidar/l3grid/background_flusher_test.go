@@ -353,3 +353,71 @@ func TestBackgroundFlusher_NilStore(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestBackgroundFlusher_AdaptiveSnapshotCadence(t *testing.T) {
+	// Poll once a minute for two hours with a 100-cell change threshold and
+	// a 1h maximum interval, adding changesPerPoll changed cells per poll.
+	run := func(changesPerPoll int) []string {
+		params := DefaultBackgroundConfig().ToBackgroundParams()
+		params.ChangeThresholdForSnapshot = 100
+		params.SnapshotIntervalNanos = time.Hour.Nanoseconds()
+		bm := NewBackgroundManagerDI("adaptive-snapshot", 2, 8, params, nil)
+		store := &mockPersistBgStore{}
+
+		clock := time.Now()
+		flusher := NewBackgroundFlusher(BackgroundFlusherConfig{
+			Manager:  bm,
+			Store:    store,
+			Interval: time.Minute,
+			Logger:   log.New(&bytes.Buffer{}, "", 0),
+		})
+		flusher.now = func() time.Time { return clock }
+		flusher.lastFlush = clock
+
+		for poll := 0; poll < 120; poll++ {
+			clock = clock.Add(time.Minute)
+			bm.Grid.mu.Lock()
+			bm.Grid.ChangesSinceSnapshot += changesPerPoll
+			bm.Grid.mu.Unlock()
+			flusher.flushIfDue()
+		}
+
+		var reasons []string
+		for _, s := range store.snapshots {
+			reasons = append(reasons, s.SnapshotReason)
+		}
+		return reasons
+	}
+
+	static := run(0)
+	if len(static) != 2 {
+		t.Fatalf("static scene: %d snapshots (%v), want 2", len(static), static)
+	}
+	for _, r := range static {
+		if r != SnapshotReasonMaxInterval {
+			t.Errorf("static scene snapshot reason = %q, want %q", r, SnapshotReasonMaxInterval)
+		}
+	}
+
+	busy := run(40) // threshold reached every third poll
+	if len(busy) != 40 {
+		t.Fatalf("changing scene: %d snapshots, want 40", len(busy))
+	}
+	for _, r := range busy {
+		if r != SnapshotReasonChangeThreshold {
+			t.Errorf("changing scene snapshot reason = %q, want %q", r, SnapshotReasonChangeThreshold)
+		}
+	}
+}
+
+func TestBackgroundManager_SnapshotDueDisabled(t *testing.T) {
+	params := DefaultBackgroundConfig().ToBackgroundParams()
+	params.ChangeThresholdForSnapshot = 0
+	params.SnapshotIntervalNanos = 0
+	bm := NewBackgroundManagerDI("snapshot-due-disabled", 2, 8, params, nil)
+
+	// With both triggers disabled the flusher stays periodic.
+	if reason, due := bm.SnapshotDue(time.Now(), time.Now()); !due || reason != "" {
+		t.Errorf("SnapshotDue = (%q, %v), want (\"\", true)", reason, due)
+	}
+}
@@ -3,6 +3,7 @@ package l3grid
 import (
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,6 +22,11 @@ type BackgroundManager struct {
 
 	// Persistence callback to main app - should save to schema lidar_bg_snapshot table
 	PersistCallback func(snapshot *BgSnapshot) error
+	// PointDebugCallback, when set, receives the foreground decision for
+	// every point ProcessFramePolarWithMask sees inside the debug region
+	// (BackgroundParams.IsInDebugRange), regardless of EnableDiagnostics.
+	// It runs under the grid lock and must not call back into the manager.
+	PointDebugCallback func(rec PointDebugRecord)
 	// store retains the BgStore reference for region persistence and restoration.
 	// When the store also implements RegionStore, regions are persisted on settling
 	// completion and can be restored on subsequent PCAP runs.
@@ -46,6 +52,15 @@ type BackgroundManager struct {
 	// Protected by sourceMu.
 	sourcePath string
 	sourceMu   sync.RWMutex
+
+	// learningFrozen suppresses all background cell updates while set, so
+	// foreground is classified against a fixed model. Unlike the per-cell
+	// FrozenUntilUnixNanos it is an operator control that persists until
+	// ThawLearning. Accessed atomically.
+	learningFrozen atomic.Bool
+	// ringElevationsKnown is set once the grid holds a complete elevation
+	// table, from SetRingElevations or learned from points. Accessed atomically.
+	ringElevationsKnown atomic.Bool
 }
 
 // GetParams returns a copy of the BackgroundParams for the manager's grid.
@@ -179,6 +194,34 @@ func (bm *BackgroundManager) GetEnableDiagnostics() bool {
 	return bm.enableDiagnostics.Load()
 }
 
+// FreezeLearning stops the background model adapting: frames are still
+// classified against the current cells, but no cell is updated until
+// ThawLearning. Safe for concurrent use.
+func (bm *BackgroundManager) FreezeLearning() {
+	if bm == nil {
+		return
+	}
+	bm.learningFrozen.Store(true)
+}
+
+// ThawLearning resumes background model updates after FreezeLearning.
+// Safe for concurrent use.
+func (bm *BackgroundManager) ThawLearning() {
+	if bm == nil {
+		return
+	}
+	bm.learningFrozen.Store(false)
+}
+
+// IsLearningFrozen reports whether FreezeLearning is in effect.
+// Safe for concurrent use.
+func (bm *BackgroundManager) IsLearningFrozen() bool {
+	if bm == nil {
+		return false
+	}
+	return bm.learningFrozen.Load()
+}
+
 // SetSourcePath sets the current data source path (e.g., PCAP filename).
 // This is used for region restoration: when processing the same PCAP file
 // again, regions can be restored immediately by matching the source path.
@@ -285,6 +328,7 @@ func (bm *BackgroundManager) GridStatus() map[string]interface{} {
 		"times_seen_dist":  timesSeenDist,
 		"foreground_count": g.ForegroundCount,
 		"background_count": g.BackgroundCount,
+		"learning_frozen":  bm.learningFrozen.Load(),
 	}
 }
 
@@ -325,17 +369,9 @@ func (bm *BackgroundManager) ResetGrid() error {
 	}
 
 	for i := range g.Cells {
-		g.Cells[i].AverageRangeMeters = 0
-		g.Cells[i].RangeSpreadMeters = 0
-		g.Cells[i].TimesSeenCount = 0
-		g.Cells[i].LastUpdateUnixNanos = 0
-		g.Cells[i].FrozenUntilUnixNanos = 0
-		// Also reset foreground tracking and locked baseline fields
-		g.Cells[i].RecentForegroundCount = 0
-		g.Cells[i].LockedBaseline = 0
-		g.Cells[i].LockedSpread = 0
-		g.Cells[i].LockedAtCount = 0
+		resetCell(&g.Cells[i])
 	}
+	g.model, g.modelType = nil, ""
 	for i := range g.AcceptByRangeBuckets {
 		g.AcceptByRangeBuckets[i] = 0
 		g.RejectByRangeBuckets[i] = 0
@@ -396,6 +432,18 @@ func GetBackgroundManager(sensorID string) *BackgroundManager {
 	return bgMgrRegistry[sensorID]
 }
 
+// RegisteredSensorIDs returns the sensor IDs with a registered manager, sorted.
+func RegisteredSensorIDs() []string {
+	bgMgrRegistryMu.RLock()
+	defer bgMgrRegistryMu.RUnlock()
+	ids := make([]string, 0, len(bgMgrRegistry))
+	for id := range bgMgrRegistry {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // NewBackgroundManager creates a BackgroundGrid and manager, registers it under sensorID,
 // and optionally wires a BgStore for persistence (sets PersistCallback to call Persist).
 func NewBackgroundManager(sensorID string, rings, azBins int, params BackgroundParams, store BgStore) *BackgroundManager {
@@ -486,6 +534,7 @@ func (bm *BackgroundManager) SetRingElevations(elevations []float64) error {
 	}
 	if elevations == nil {
 		bm.Grid.RingElevations = nil
+		bm.ringElevationsKnown.Store(false)
 		return nil
 	}
 	if len(elevations) != bm.Grid.Rings {
@@ -496,6 +545,7 @@ func (bm *BackgroundManager) SetRingElevations(elevations []float64) error {
 	bm.Grid.RingElevations = make([]float64, len(elevations))
 	copy(bm.Grid.RingElevations, elevations)
 	bm.Grid.mu.Unlock()
+	bm.ringElevationsKnown.Store(true)
 	return nil
 }
 
@@ -512,6 +562,9 @@ func (bm *BackgroundManager) ProcessFramePolar(points []PointPolar) {
 	if bm == nil || bm.Grid == nil || len(points) == 0 {
 		return
 	}
+	if bm.learningFrozen.Load() {
+		return
+	}
 
 	// Read diagnostics flag once per frame (atomic — no lock needed).
 	enableDiag := bm.enableDiagnostics.Load()
@@ -528,6 +581,7 @@ func (bm *BackgroundManager) ProcessFramePolar(points []PointPolar) {
 	if rings <= 0 || azBins <= 0 || len(g.Cells) != rings*azBins {
 		return
 	}
+	bm.observeRingElevations(points)
 
 	now := time.Now()
 	nowNanos := now.UnixNano()
@@ -0,0 +1,283 @@
+package l3grid
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Background model types.
+//
+// The running mean (the default) keeps one range per cell, so a cell with
+// two genuine backgrounds, such as a gate that is sometimes open and
+// sometimes shut, is learned as a range between them and one of the two
+// comes out as foreground. The alternative models replace the per-cell
+// foreground decision; the running mean is still maintained from
+// background returns so snapshots, heatmaps and drift checks keep working.
+//
+//   - median: the median of the cell's recent background ranges, with a
+//     spread from their median absolute deviation. A few outliers learned
+//     as background cannot drag it the way they drag a mean.
+//   - gmm: a small Gaussian mixture per cell (Stauffer–Grimson). Every
+//     return updates the mixture; the heaviest, tightest components that
+//     together carry most of the weight are background, so each mode of a
+//     multimodal cell that recurs often enough is background.
+//
+// Both models seed an empty cell from its first return and do not freeze
+// cells; the locked-baseline, variance, ellipse and neighbour gates apply
+// only to the running mean.
+
+// BackgroundModelType selects the per-cell background model.
+type BackgroundModelType string
+
+const (
+	BackgroundModelMean   BackgroundModelType = "mean"
+	BackgroundModelMedian BackgroundModelType = "median"
+	BackgroundModelGMM    BackgroundModelType = "gmm"
+)
+
+// ParseBackgroundModelType returns the model type named by s; empty means
+// BackgroundModelMean.
+func ParseBackgroundModelType(s string) (BackgroundModelType, error) {
+	switch t := BackgroundModelType(s); t {
+	case "":
+		return BackgroundModelMean, nil
+	case BackgroundModelMean, BackgroundModelMedian, BackgroundModelGMM:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown background model %q: want mean, median or gmm", s)
+}
+
+const (
+	// medianModelWindow is the number of recent background ranges per cell.
+	medianModelWindow = 15
+	// medianAdoptFrames is how many consecutive foreground returns a cell
+	// sees before the median model starts learning them, so a lasting
+	// change such as a parked car becomes background.
+	medianAdoptFrames = 100
+	// madToSigma scales a median absolute deviation to a normal σ.
+	madToSigma = 1.4826
+
+	// gmmComponents is the number of Gaussians per cell.
+	gmmComponents = 3
+	// gmmMatchSigmas is how many σ from a component's mean a return may
+	// fall and still match it.
+	gmmMatchSigmas = 2.5
+	// gmmBackgroundWeight is the share of weight the background components
+	// carry: components are taken heaviest and tightest first until their
+	// weights exceed it.
+	gmmBackgroundWeight = 0.7
+	// gmmInitialSigmaMetres is the σ a new component starts with.
+	gmmInitialSigmaMetres = 0.5
+)
+
+// modelGate carries the per-point thresholds a cell model classifies
+// against, computed as for the running mean.
+type modelGate struct {
+	closeness float64 // closeness multiplier
+	noiseRel  float64 // per-cell noise fraction of range
+	warmup    float64 // warmup multiplier for young cells
+	safety    float64 // safety margin (metres)
+	alpha     float64 // per-cell update fraction
+	learn     bool    // false while learning is frozen
+}
+
+// cellModel is a per-cell background model that takes over the
+// foreground decision from the running mean. Methods are called with the
+// grid lock held.
+type cellModel interface {
+	// observe reports whether r is background for cell idx and, when
+	// gate.learn is set, updates the cell with it.
+	observe(idx int, cell *BackgroundCell, r float64, gate modelGate) bool
+	// reset clears cell idx.
+	reset(idx int)
+}
+
+// newCellModel returns the model for t over cells cells, or nil for the
+// running mean.
+func newCellModel(t BackgroundModelType, cells int) cellModel {
+	switch t {
+	case BackgroundModelMedian:
+		return &medianModel{
+			ranges: make([]float32, cells*medianModelWindow),
+			count:  make([]uint8, cells),
+			next:   make([]uint8, cells),
+		}
+	case BackgroundModelGMM:
+		return &gmmModel{comps: make([]gmmComponent, cells*gmmComponents)}
+	}
+	return nil
+}
+
+// cellModelLocked returns the grid's cell model for its configured type,
+// building it on first use or after the type changes. Caller holds g.mu.
+func (g *BackgroundGrid) cellModelLocked() cellModel {
+	t := g.Params.ModelType
+	if t == "" {
+		t = BackgroundModelMean
+	}
+	if t != g.modelType {
+		g.modelType = t
+		g.model = newCellModel(t, len(g.Cells))
+		if g.model != nil {
+			opsf("[BackgroundManager] sensor=%s using %s background model", g.SensorID, t)
+		}
+	}
+	return g.model
+}
+
+// medianModel keeps a ring buffer of recent background ranges per cell.
+type medianModel struct {
+	ranges []float32 // medianModelWindow per cell
+	count  []uint8
+	next   []uint8
+	buf    [medianModelWindow]float64
+}
+
+func (m *medianModel) observe(idx int, cell *BackgroundCell, r float64, gate modelGate) bool {
+	n := int(m.count[idx])
+	if n == 0 {
+		if gate.learn {
+			m.push(idx, r)
+		}
+		return true
+	}
+	window := m.ranges[idx*medianModelWindow : idx*medianModelWindow+n]
+	median, mad := m.medianAndMAD(window)
+	threshold := gate.closeness*(madToSigma*mad+gate.noiseRel*r+0.01)*gate.warmup + gate.safety
+	background := math.Abs(r-median) <= threshold
+	if gate.learn && (background || cell.RecentForegroundCount >= medianAdoptFrames) {
+		m.push(idx, r)
+	}
+	return background
+}
+
+func (m *medianModel) push(idx int, r float64) {
+	m.ranges[idx*medianModelWindow+int(m.next[idx])] = float32(r)
+	m.next[idx] = uint8((int(m.next[idx]) + 1) % medianModelWindow)
+	if m.count[idx] < medianModelWindow {
+		m.count[idx]++
+	}
+}
+
+// medianAndMAD returns the median of window and the median absolute
+// deviation from it.
+func (m *medianModel) medianAndMAD(window []float32) (median, mad float64) {
+	s := m.buf[:len(window)]
+	for i, v := range window {
+		s[i] = float64(v)
+	}
+	sort.Float64s(s)
+	median = middle(s)
+	for i := range s {
+		s[i] = math.Abs(s[i] - median)
+	}
+	sort.Float64s(s)
+	return median, middle(s)
+}
+
+func middle(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func (m *medianModel) reset(idx int) {
+	m.count[idx] = 0
+	m.next[idx] = 0
+}
+
+// gmmComponent is one Gaussian of a cell's mixture. A zero weight marks an
+// unused component.
+type gmmComponent struct {
+	weight   float32
+	mean     float32
+	variance float32
+}
+
+func (c gmmComponent) fitness() float32 {
+	if c.weight == 0 {
+		return 0
+	}
+	return c.weight / float32(math.Sqrt(float64(c.variance)))
+}
+
+// gmmModel keeps gmmComponents Gaussians per cell, ordered by fitness
+// (weight over σ), fittest first.
+type gmmModel struct {
+	comps []gmmComponent
+}
+
+func (m *gmmModel) observe(idx int, _ *BackgroundCell, r float64, gate modelGate) bool {
+	comps := m.comps[idx*gmmComponents : (idx+1)*gmmComponents]
+	if comps[0].weight == 0 {
+		if gate.learn {
+			comps[0] = gmmComponent{weight: 1, mean: float32(r), variance: gmmInitialSigmaMetres * gmmInitialSigmaMetres}
+		}
+		return true
+	}
+
+	minSigma := gate.noiseRel*r + 0.01
+	matched := -1
+	var before float64 // weight of the components fitter than the match
+	for k, c := range comps {
+		if c.weight == 0 {
+			break
+		}
+		sigma := math.Max(math.Sqrt(float64(c.variance)), minSigma)
+		if math.Abs(r-float64(c.mean)) <= gmmMatchSigmas*sigma {
+			matched = k
+			break
+		}
+		before += float64(c.weight)
+	}
+	background := matched >= 0 && before < gmmBackgroundWeight
+	if !gate.learn {
+		return background
+	}
+
+	alpha := float32(gate.alpha)
+	for k := range comps {
+		comps[k].weight *= 1 - alpha
+	}
+	if matched >= 0 {
+		c := &comps[matched]
+		c.weight += alpha
+		rho := alpha / c.weight
+		if rho > 1 {
+			rho = 1
+		}
+		d := float32(r) - c.mean
+		c.mean += rho * d
+		c.variance += rho * (d*d - c.variance)
+		if floor := float32(minSigma * minSigma); c.variance < floor {
+			c.variance = floor
+		}
+	} else {
+		// Replace the least fit (or an unused) component.
+		comps[gmmComponents-1] = gmmComponent{weight: alpha, mean: float32(r), variance: gmmInitialSigmaMetres * gmmInitialSigmaMetres}
+	}
+
+	var total float32
+	for _, c := range comps {
+		total += c.weight
+	}
+	for k := range comps {
+		comps[k].weight /= total
+	}
+	// Insertion sort: the mixture is tiny and this runs per return.
+	for i := 1; i < len(comps); i++ {
+		for j := i; j > 0 && comps[j].fitness() > comps[j-1].fitness(); j-- {
+			comps[j], comps[j-1] = comps[j-1], comps[j]
+		}
+	}
+	return background
+}
+
+func (m *gmmModel) reset(idx int) {
+	for k := idx * gmmComponents; k < (idx+1)*gmmComponents; k++ {
+		m.comps[k] = gmmComponent{}
+	}
+}
@@ -0,0 +1,146 @@
+package l3grid
+
+import "testing"
+
+// newModelTestManager returns a manager over a small grid with the
+// production closeness settings, no warmup and no freeze, so the mean
+// model is judged on its gates alone, and the given model.
+func newModelTestManager(t *testing.T, model BackgroundModelType) *BackgroundManager {
+	t.Helper()
+	params := BackgroundParams{
+		BackgroundUpdateFraction:       0.02,
+		ClosenessSensitivityMultiplier: 3,
+		SafetyMarginMetres:             0.15,
+		FreezeDurationNanos:            0,
+		FreezeThresholdMultiplier:      3,
+		NeighbourConfirmationCount:     3,
+		NoiseRelativeFraction:          0.02,
+		SeedFromFirstObservation:       true,
+		ReacquisitionBoostMultiplier:   5,
+		MinConfidenceFloor:             3,
+		LockedBaselineThreshold:        50,
+		LockedBaselineMultiplier:       4,
+		ModelType:                      model,
+	}
+	bm := NewBackgroundManager("model-test", 2, 8, params, nil)
+	if bm == nil {
+		t.Fatal("NewBackgroundManager returned nil")
+	}
+	return bm
+}
+
+// observeRange feeds one return to cell (0, 0) and reports whether it came
+// out as foreground.
+func observeRange(t *testing.T, bm *BackgroundManager, r float64) bool {
+	t.Helper()
+	mask, err := bm.ProcessFramePolarWithMask([]PointPolar{{Channel: 1, Azimuth: 1, Distance: r}})
+	if err != nil || len(mask) != 1 {
+		t.Fatalf("ProcessFramePolarWithMask: mask %v, err %v", mask, err)
+	}
+	return mask[0]
+}
+
+// gateSequence is a cell behind a gate that is shut (5 m) and open (the
+// wall behind it at 20 m) in alternating spells of ten frames, with a
+// little range noise.
+func gateSequence(frame int) (r float64, open bool) {
+	jitter := []float64{0, 0.02, -0.01, 0.03, -0.02}[frame%5]
+	if (frame/10)%2 == 1 {
+		return 20 + jitter, true
+	}
+	return 5 + jitter, false
+}
+
+// gateForegroundRates trains bm on the gate for 600 frames and returns the
+// fraction of each mode classified as foreground over the next 400.
+func gateForegroundRates(t *testing.T, bm *BackgroundManager) (shut, open float64) {
+	t.Helper()
+	for i := 0; i < 600; i++ {
+		r, _ := gateSequence(i)
+		observeRange(t, bm, r)
+	}
+	var fg, seen [2]int
+	for i := 600; i < 1000; i++ {
+		r, isOpen := gateSequence(i)
+		mode := 0
+		if isOpen {
+			mode = 1
+		}
+		seen[mode]++
+		if observeRange(t, bm, r) {
+			fg[mode]++
+		}
+	}
+	return float64(fg[0]) / float64(seen[0]), float64(fg[1]) / float64(seen[1])
+}
+
+func TestBackgroundModel_GMMLearnsBothGateModes(t *testing.T) {
+	meanShut, meanOpen := gateForegroundRates(t, newModelTestManager(t, BackgroundModelMean))
+	if meanShut < 0.5 && meanOpen < 0.5 {
+		t.Errorf("mean model foreground rates shut=%.2f open=%.2f: expected it to reject one gate mode", meanShut, meanOpen)
+	}
+
+	gmm := newModelTestManager(t, BackgroundModelGMM)
+	gmmShut, gmmOpen := gateForegroundRates(t, gmm)
+	if gmmShut > 0.02 || gmmOpen > 0.02 {
+		t.Errorf("gmm model foreground rates shut=%.2f open=%.2f: want both modes background", gmmShut, gmmOpen)
+	}
+	t.Logf("foreground rates: mean shut=%.2f open=%.2f, gmm shut=%.2f open=%.2f", meanShut, meanOpen, gmmShut, gmmOpen)
+
+	// Someone walking through the gateway is neither mode.
+	for i := 0; i < 5; i++ {
+		if !observeRange(t, gmm, 12) {
+			t.Fatalf("gmm: person at 12 m in frame %d classified as background", i)
+		}
+	}
+}
+
+func TestBackgroundModel_MedianIgnoresOutliersAndAdoptsChanges(t *testing.T) {
+	bm := newModelTestManager(t, BackgroundModelMedian)
+	for i := 0; i < 50; i++ {
+		observeRange(t, bm, 10+[]float64{0, 0.02, -0.02}[i%3])
+	}
+	if observeRange(t, bm, 10.01) {
+		t.Error("wall return classified as foreground")
+	}
+	if !observeRange(t, bm, 6) {
+		t.Error("return at 6 m in front of a 10 m wall classified as background")
+	}
+
+	// A car parks in front of the wall: foreground until the median model
+	// adopts it.
+	adopted := -1
+	for i := 0; i < medianAdoptFrames+medianModelWindow; i++ {
+		if !observeRange(t, bm, 6) {
+			adopted = i
+			break
+		}
+	}
+	if adopted < medianAdoptFrames-2 {
+		t.Errorf("parked car adopted after %d frames, want about %d", adopted, medianAdoptFrames)
+	}
+
+	// A scoped reset forgets the learned ranges.
+	azMin, azMax := 0.0, 45.0
+	if _, err := bm.ResetGridScope(GridResetScope{AzimuthMinDeg: &azMin, AzimuthMaxDeg: &azMax}); err != nil {
+		t.Fatal(err)
+	}
+	if m := bm.Grid.model.(*medianModel); m.count[bm.Grid.Idx(0, 0)] != 0 {
+		t.Error("scoped reset kept the median window")
+	}
+}
+
+func TestParseBackgroundModelType(t *testing.T) {
+	for in, want := range map[string]BackgroundModelType{"": BackgroundModelMean, "mean": BackgroundModelMean, "median": BackgroundModelMedian, "gmm": BackgroundModelGMM} {
+		if got, err := ParseBackgroundModelType(in); err != nil || got != want {
+			t.Errorf("ParseBackgroundModelType(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseBackgroundModelType("kde"); err == nil {
+		t.Error("expected an error for an unknown model")
+	}
+	cfg := DefaultBackgroundConfig().WithModelType("kde")
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate accepted an unknown model type")
+	}
+}
@@ -3,8 +3,9 @@ package l3grid
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/gob"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -60,6 +61,51 @@ type RegionStore interface {
 	GetBgSnapshotByID(snapshotID int64) (*BgSnapshot, error)
 }
 
+// Snapshot reasons recorded by the adaptive snapshot policy.
+const (
+	SnapshotReasonChangeThreshold = "change_threshold"
+	SnapshotReasonMaxInterval     = "max_interval"
+)
+
+// SnapshotReasonShutdown marks the final snapshot taken on a clean shutdown.
+const SnapshotReasonShutdown = "shutdown"
+
+// SnapshotDue implements SnapshotPolicy. A snapshot is due once
+// ChangeThresholdForSnapshot cells have changed since the last one, or once
+// SnapshotIntervalNanos has passed since the later of lastFlush and the last
+// snapshot, whichever comes first. A quiet scene therefore snapshots only on
+// the interval while a changing one snapshots as often as the flusher polls.
+// Either trigger is disabled when its parameter is zero or less; with both
+// disabled every poll is due.
+func (bm *BackgroundManager) SnapshotDue(now, lastFlush time.Time) (string, bool) {
+	if bm == nil || bm.Grid == nil {
+		return "", false
+	}
+	g := bm.Grid
+	g.mu.RLock()
+	threshold := g.Params.ChangeThresholdForSnapshot
+	interval := time.Duration(g.Params.SnapshotIntervalNanos)
+	changes := g.ChangesSinceSnapshot
+	last := g.LastSnapshotTime
+	g.mu.RUnlock()
+
+	if threshold <= 0 && interval <= 0 {
+		return "", true
+	}
+	if threshold > 0 && changes >= threshold {
+		diagf("[BackgroundManager] Snapshot due: sensor=%s, changed_cells=%d, threshold=%d", g.SensorID, changes, threshold)
+		return SnapshotReasonChangeThreshold, true
+	}
+	if lastFlush.After(last) {
+		last = lastFlush
+	}
+	if interval > 0 && now.Sub(last) >= interval {
+		diagf("[BackgroundManager] Snapshot due: sensor=%s, since_last=%v, interval=%v, changed_cells=%d", g.SensorID, now.Sub(last), interval, changes)
+		return SnapshotReasonMaxInterval, true
+	}
+	return "", false
+}
+
 // Persist serializes the BackgroundGrid and writes a BgSnapshot via the provided store.
 // It updates grid snapshot metadata on success.
 // If the store also implements RegionStore and regions have been identified,
@@ -77,11 +123,7 @@ func (bm *BackgroundManager) Persist(store BgStore, reason string) error {
 	cellsCopy := make([]BackgroundCell, len(g.Cells))
 	copy(cellsCopy, g.Cells)
 	changesSince := g.ChangesSinceSnapshot
-	var ringElevCopy []float64
-	if len(g.RingElevations) == g.Rings {
-		ringElevCopy = make([]float64, len(g.RingElevations))
-		copy(ringElevCopy, g.RingElevations)
-	}
+	ringElevJSON := g.ringElevationsForSnapshotLocked()
 	g.mu.RUnlock()
 
 	// Serialize and compress grid cells
@@ -99,13 +141,9 @@ func (bm *BackgroundManager) Persist(store BgStore, reason string) error {
 		GridBlob:          blob,
 		ChangedCellsCount: changesSince,
 		SnapshotReason:    reason,
-	}
-
-	// If ring elevations were present at the time of copy, serialize the copied slice.
-	if len(ringElevCopy) == snap.Rings {
-		if b, err := json.Marshal(ringElevCopy); err == nil {
-			snap.RingElevationsJSON = string(b)
-		}
+		// Always store the elevation table the cells were binned with so
+		// the snapshot can be projected to world coordinates on its own.
+		RingElevationsJSON: ringElevJSON,
 	}
 
 	id, err := store.InsertBgSnapshot(snap)
@@ -165,6 +203,54 @@ func (bm *BackgroundManager) Persist(store BgStore, reason string) error {
 	return nil
 }
 
+// SnapshotOnShutdown persists the grid through PersistCallback with reason
+// SnapshotReasonShutdown, so a restart resumes from the model as it stood
+// at exit rather than from the last periodic snapshot. It does nothing when
+// the manager has no PersistCallback (persistence disabled).
+func (bm *BackgroundManager) SnapshotOnShutdown() error {
+	if bm == nil || bm.Grid == nil || bm.PersistCallback == nil {
+		return nil
+	}
+	g := bm.Grid
+	g.mu.RLock()
+	snap := &BgSnapshot{
+		SensorID:          g.SensorID,
+		TakenUnixNanos:    time.Now().UnixNano(),
+		Rings:             g.Rings,
+		AzimuthBins:       g.AzimuthBins,
+		ParamsJSON:        "{}",
+		ChangedCellsCount: g.ChangesSinceSnapshot,
+		SnapshotReason:    SnapshotReasonShutdown,
+	}
+	g.mu.RUnlock()
+	if err := bm.PersistCallback(snap); err != nil {
+		return fmt.Errorf("shutdown snapshot for sensor %s: %w", snap.SensorID, err)
+	}
+	opsf("[BackgroundManager] Shutdown snapshot persisted: sensor=%s, changed_cells=%d", snap.SensorID, snap.ChangedCellsCount)
+	return nil
+}
+
+// SnapshotOnShutdown takes a shutdown snapshot of the registered manager of
+// each sensor in sensorIDs, or of every registered sensor when none are
+// given. It suits a pipeline.DrainStep: errors are collected so one
+// sensor's failure does not cost the others their snapshot, and sensors
+// not yet reached when ctx ends are skipped.
+func SnapshotOnShutdown(ctx context.Context, sensorIDs ...string) error {
+	if len(sensorIDs) == 0 {
+		sensorIDs = RegisteredSensorIDs()
+	}
+	var errs []error
+	for _, id := range sensorIDs {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := GetBackgroundManager(id).SnapshotOnShutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // RestoreRegions restores the region manager from a previously saved snapshot.
 // This allows skipping the settling period when the scene hash matches.
 // On success, SettlingComplete is set to true.
@@ -388,11 +474,7 @@ func (bm *BackgroundManager) persistRegionsOnSettleLocked() {
 	// Copy cells and ring elevations while holding the lock
 	cellsCopy := make([]BackgroundCell, len(g.Cells))
 	copy(cellsCopy, g.Cells)
-	var ringElevCopy []float64
-	if len(g.RingElevations) == g.Rings {
-		ringElevCopy = make([]float64, len(g.RingElevations))
-		copy(ringElevCopy, g.RingElevations)
-	}
+	ringElevJSON := g.ringElevationsForSnapshotLocked()
 
 	// Build region snapshot while holding the lock
 	gridHash := g.sceneSignatureUnlocked()
@@ -416,19 +498,15 @@ func (bm *BackgroundManager) persistRegionsOnSettleLocked() {
 
 	// Create and insert grid snapshot
 	bgSnap := &BgSnapshot{
-		SensorID:          g.SensorID,
-		TakenUnixNanos:    time.Now().UnixNano(),
-		Rings:             g.Rings,
-		AzimuthBins:       g.AzimuthBins,
-		ParamsJSON:        "{}",
-		GridBlob:          blob,
-		ChangedCellsCount: 0,
-		SnapshotReason:    "region_settle",
-	}
-	if len(ringElevCopy) == g.Rings {
-		if b, err := json.Marshal(ringElevCopy); err == nil {
-			bgSnap.RingElevationsJSON = string(b)
-		}
+		SensorID:           g.SensorID,
+		TakenUnixNanos:     time.Now().UnixNano(),
+		Rings:              g.Rings,
+		AzimuthBins:        g.AzimuthBins,
+		ParamsJSON:         "{}",
+		GridBlob:           blob,
+		ChangedCellsCount:  0,
+		SnapshotReason:     "region_settle",
+		RingElevationsJSON: ringElevJSON,
 	}
 
 	snapshotID, err := regionStore.InsertBgSnapshot(bgSnap)
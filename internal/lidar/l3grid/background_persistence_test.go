@@ -3,6 +3,7 @@ package l3grid
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -194,6 +195,12 @@ func TestSerializeDeserializeGrid(t *testing.T) {
 				{AverageRangeMeters: 10.5, RangeSpreadMeters: 0.3, TimesSeenCount: 15},
 			},
 		},
+		{
+			name: "cell with variance statistics",
+			cells: []BackgroundCell{
+				{AverageRangeMeters: 12.0, TimesSeenCount: 40, RangeSampleCount: 40, RangeMeanMeters: 12.1, RangeM2: 3.9},
+			},
+		},
 		{
 			name: "multiple cells with varying values",
 			cells: []BackgroundCell{
@@ -1412,3 +1419,75 @@ func TestDeserializeGrid_DecodeError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to decode")
 }
+
+// shutdownStore keeps region snapshots by grid hash, as the database does,
+// so a restart can find the snapshot taken at shutdown.
+type shutdownStore struct {
+	*mockRegionStore
+}
+
+func (s shutdownStore) InsertRegionSnapshot(snap *RegionSnapshot) (int64, error) {
+	id, err := s.mockRegionStore.InsertRegionSnapshot(snap)
+	if err == nil {
+		s.addRegionSnapshotByGridHash(snap.SensorID, snap.GridHash, snap)
+	}
+	return id, err
+}
+
+func TestSnapshotOnShutdown_RestartRestoresGrid(t *testing.T) {
+	const rings, azBins = 4, 8
+	store := shutdownStore{newMockRegionStore()}
+	mgr := NewBackgroundManager("shutdown-snapshot", rings, azBins, BackgroundParams{}, store)
+	require.NotNil(t, mgr)
+	defer RegisterBackgroundManager("shutdown-snapshot", nil)
+
+	// A settled grid with regions, changed since its last periodic snapshot.
+	g := mgr.Grid
+	for i := range g.Cells {
+		g.Cells[i] = BackgroundCell{
+			AverageRangeMeters: float32(10+i%5) + 0.3,
+			RangeSpreadMeters:  0.03,
+			TimesSeenCount:     uint32(50 + i),
+		}
+	}
+	g.ChangesSinceSnapshot = 12
+	g.RegionMgr.Regions = []*Region{{ID: 0, CellList: []int{0, 1, 2}, CellCount: 3}}
+	g.RegionMgr.IdentificationComplete = true
+	before := append([]BackgroundCell(nil), g.Cells...)
+
+	require.NoError(t, SnapshotOnShutdown(context.Background(), "shutdown-snapshot"))
+	require.Len(t, store.bgSnapshots, 1)
+	snap := store.bgSnapshots[1]
+	assert.Equal(t, SnapshotReasonShutdown, snap.SnapshotReason)
+	assert.Equal(t, 12, snap.ChangedCellsCount)
+	assert.Equal(t, 0, g.ChangesSinceSnapshot)
+
+	// After a restart the grid relearns the same scene coarsely; matching
+	// it restores the grid exactly as it was at shutdown.
+	restarted := NewBackgroundManagerDI("shutdown-snapshot", rings, azBins, BackgroundParams{}, store)
+	for i := range restarted.Grid.Cells {
+		restarted.Grid.Cells[i] = BackgroundCell{
+			AverageRangeMeters: float32(10 + i%5),
+			RangeSpreadMeters:  0.04,
+			TimesSeenCount:     3,
+		}
+	}
+	require.True(t, restarted.TryRestoreRegionsByGridHash(store))
+	assert.Equal(t, before, restarted.Grid.Cells)
+}
+
+func TestSnapshotOnShutdown_NoPersistence(t *testing.T) {
+	t.Parallel()
+	mgr := NewBackgroundManagerDI("shutdown-no-store", 2, 4, BackgroundParams{}, nil)
+	assert.NoError(t, mgr.SnapshotOnShutdown())
+
+	var nilMgr *BackgroundManager
+	assert.NoError(t, nilMgr.SnapshotOnShutdown())
+
+	failing := NewBackgroundManagerDI("shutdown-failing", 2, 4, BackgroundParams{}, &mockPersistBgStore{insertErr: assert.AnError})
+	assert.ErrorIs(t, failing.SnapshotOnShutdown(), assert.AnError)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, SnapshotOnShutdown(ctx, "shutdown-failing"), context.Canceled)
+}
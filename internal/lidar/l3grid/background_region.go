@@ -3,8 +3,11 @@ package l3grid
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
 )
 
 // NewRegionManager creates a RegionManager for the grid
@@ -204,6 +207,10 @@ func (rm *RegionManager) assignRegionParams(category int, baseParams BackgroundP
 	if baseAlpha <= 0 {
 		baseAlpha = 0.02
 	}
+	baseWarmup := baseParams.RegionWarmupMinFrames
+	if baseWarmup < 0 {
+		baseWarmup = 0
+	}
 
 	switch category {
 	case 0: // stable (low variance) - EXTREMELY high tolerance to eliminate false positives, faster settling
@@ -211,24 +218,28 @@ func (rm *RegionManager) assignRegionParams(category int, baseParams BackgroundP
 			NoiseRelativeFraction:      baseNoise * 4.0, // EXTREMELY high tolerance - stable surfaces should almost never flag FG
 			NeighbourConfirmationCount: baseNeighbour,   // standard neighbour check
 			SettleUpdateFraction:       baseAlpha * 1.5, // faster settling
+			WarmupMinFrames:            baseWarmup / 2,  // converges quickly
 		}
 	case 1: // variable (medium variance) - significantly higher tolerance
 		return RegionParams{
 			NoiseRelativeFraction:      baseNoise * 3.0, // significantly higher tolerance
 			NeighbourConfirmationCount: baseNeighbour,
 			SettleUpdateFraction:       baseAlpha,
+			WarmupMinFrames:            baseWarmup,
 		}
 	case 2: // volatile (high variance - trees, glass) - extremely high tolerance, slower settling
 		return RegionParams{
 			NoiseRelativeFraction:      baseNoise * 8.0,   // EXTREMELY high tolerance for dynamic surfaces
 			NeighbourConfirmationCount: baseNeighbour + 2, // require more neighbours
 			SettleUpdateFraction:       baseAlpha * 0.5,   // slower settling to handle variance
+			WarmupMinFrames:            baseWarmup * 2,    // needs longer to learn its spread
 		}
 	default:
 		return RegionParams{
 			NoiseRelativeFraction:      baseNoise,
 			NeighbourConfirmationCount: baseNeighbour,
 			SettleUpdateFraction:       baseAlpha,
+			WarmupMinFrames:            baseWarmup,
 		}
 	}
 }
@@ -326,6 +337,7 @@ func (rm *RegionManager) createDefaultRegion(grid *BackgroundGrid) error {
 			NoiseRelativeFraction:      grid.Params.NoiseRelativeFraction,
 			NeighbourConfirmationCount: grid.Params.NeighbourConfirmationCount,
 			SettleUpdateFraction:       grid.Params.BackgroundUpdateFraction,
+			WarmupMinFrames:            grid.Params.RegionWarmupMinFrames,
 		},
 	}
 	for i := 0; i < totalCells; i++ {
@@ -356,6 +368,129 @@ func (rm *RegionManager) GetRegionParams(regionID int) *RegionParams {
 	return &rm.Regions[regionID].Params
 }
 
+// regionWarmupQuietFraction is the largest share of a region's points in
+// a frame that may be foreground for the frame to count as quiet.
+const regionWarmupQuietFraction = 0.01
+
+// regionWarmupPending reports whether any region is still warming up.
+func (rm *RegionManager) regionWarmupPending() bool {
+	if !rm.IdentificationComplete {
+		return false
+	}
+	for _, r := range rm.Regions {
+		if !r.WarmupComplete() {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRegionWarmup advances per-region warmup from one frame's
+// classification and clears the foreground mask for points in regions still
+// warming up. pointRegion holds each point's region ID (-1 for none). A
+// region's frame counts as quiet when it observed points and at most
+// regionWarmupQuietFraction of them were foreground; a region that never
+// gets enough quiet frames completes after regionWarmupMaxFactor times its
+// target. It returns the number of foreground points suppressed.
+func (rm *RegionManager) applyRegionWarmup(pointRegion []int, mask []bool) int64 {
+	observed := make([]int, len(rm.Regions))
+	foreground := make([]int, len(rm.Regions))
+	for i, id := range pointRegion {
+		if id < 0 || id >= len(rm.Regions) {
+			continue
+		}
+		observed[id]++
+		if mask[i] {
+			foreground[id]++
+		}
+	}
+	for id, r := range rm.Regions {
+		if r.WarmupComplete() || observed[id] == 0 {
+			continue
+		}
+		r.WarmupFrames++
+		if float64(foreground[id]) <= regionWarmupQuietFraction*float64(observed[id]) {
+			r.WarmupQuietFrames++
+		}
+		if r.WarmupComplete() {
+			diagf("[RegionManager] Region %d warmup complete after %d frames (%d quiet)",
+				r.ID, r.WarmupFrames, r.WarmupQuietFrames)
+		}
+	}
+	var suppressed int64
+	for i, id := range pointRegion {
+		if mask[i] && id >= 0 && id < len(rm.Regions) && !rm.Regions[id].WarmupComplete() {
+			mask[i] = false
+			suppressed++
+		}
+	}
+	return suppressed
+}
+
+// RegionAt returns the region covering sensor-frame position (x, y), or -1
+// if regions are not yet identified. A ground position has no ring, so the
+// region is the one holding most rings in the azimuth column through the
+// position. It satisfies l5tracks.RegionLookup when the tracker's world
+// frame is the sensor frame; otherwise use WorldRegionLookup.
+func (bm *BackgroundManager) RegionAt(x, y float32) int {
+	if bm == nil || bm.Grid == nil {
+		return -1
+	}
+	g := bm.Grid
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	rm := g.RegionMgr
+	if rm == nil || !rm.IdentificationComplete || g.AzimuthBins <= 0 {
+		return -1
+	}
+	// Sensor frame: X = r·sin(az), Y = r·cos(az).
+	az := math.Mod(math.Atan2(float64(x), float64(y))*180/math.Pi, 360.0)
+	if az < 0 {
+		az += 360.0
+	}
+	azBin := int((az / 360.0) * float64(g.AzimuthBins))
+	if azBin >= g.AzimuthBins {
+		azBin = g.AzimuthBins - 1
+	}
+
+	votes := make(map[int]int)
+	for ring := 0; ring < g.Rings; ring++ {
+		if id := rm.GetRegionForCell(g.Idx(ring, azBin)); id >= 0 {
+			votes[id]++
+		}
+	}
+	best, bestVotes := -1, 0
+	for id, n := range votes {
+		if n > bestVotes || (n == bestVotes && id < best) {
+			best, bestVotes = id, n
+		}
+	}
+	return best
+}
+
+// WorldRegionLookup looks up background regions for world-frame positions.
+// It satisfies l5tracks.RegionLookup when clusters are placed in the world
+// by a sensor pose.
+type WorldRegionLookup struct {
+	Manager *BackgroundManager
+	// SensorToWorld is the row-major sensor→world transform of the pose
+	// clusters were placed with; nil when the world frame is the sensor
+	// frame.
+	SensorToWorld *[16]float64
+}
+
+// RegionAt returns the region covering world position (x, y), or -1 if
+// regions are not yet identified. The position is taken at world Z=0 and
+// moved into the sensor frame before the azimuth lookup.
+func (l WorldRegionLookup) RegionAt(x, y float32) int {
+	if l.SensorToWorld == nil {
+		return l.Manager.RegionAt(x, y)
+	}
+	sx, sy, _ := l2frames.ApplyInversePose(float64(x), float64(y), 0, *l.SensorToWorld)
+	return l.Manager.RegionAt(float32(sx), float32(sy))
+}
+
 // ToSnapshot serialises the RegionManager's region data into a form suitable for database persistence.
 // Returns nil if regions have not yet been identified.
 func (rm *RegionManager) ToSnapshot(sensorID string, snapshotID int64) *RegionSnapshot {
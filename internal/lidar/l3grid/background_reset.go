@@ -0,0 +1,141 @@
+package l3grid
+
+import (
+	"fmt"
+	"time"
+)
+
+// GridResetScope selects the cells cleared by ResetGridScope. A cell is
+// cleared when it matches every bound that is set; unset bounds are open.
+// An azimuth window with AzimuthMinDeg > AzimuthMaxDeg wraps through 0°.
+// Range bounds apply to the cell's learned average range, so a scope can
+// target the cells that learned a parked vehicle without touching the
+// wall behind it.
+type GridResetScope struct {
+	AzimuthMinDeg  *float64 `json:"azimuth_min_deg,omitempty"`
+	AzimuthMaxDeg  *float64 `json:"azimuth_max_deg,omitempty"`
+	MinRangeMeters *float64 `json:"min_range_m,omitempty"`
+	MaxRangeMeters *float64 `json:"max_range_m,omitempty"`
+	RegionID       *int     `json:"region_id,omitempty"`
+}
+
+// IsZero reports whether no bound is set, i.e. the scope is the whole grid.
+func (s GridResetScope) IsZero() bool {
+	return s.AzimuthMinDeg == nil && s.AzimuthMaxDeg == nil &&
+		s.MinRangeMeters == nil && s.MaxRangeMeters == nil && s.RegionID == nil
+}
+
+// Validate checks the bounds are in range and paired.
+func (s GridResetScope) Validate() error {
+	if (s.AzimuthMinDeg == nil) != (s.AzimuthMaxDeg == nil) {
+		return fmt.Errorf("azimuth window needs both a minimum and a maximum")
+	}
+	for _, az := range []*float64{s.AzimuthMinDeg, s.AzimuthMaxDeg} {
+		if az != nil && (*az < 0 || *az > 360) {
+			return fmt.Errorf("azimuth %v outside [0, 360]", *az)
+		}
+	}
+	for _, r := range []*float64{s.MinRangeMeters, s.MaxRangeMeters} {
+		if r != nil && *r < 0 {
+			return fmt.Errorf("range %v must be >= 0", *r)
+		}
+	}
+	if s.MinRangeMeters != nil && s.MaxRangeMeters != nil && *s.MinRangeMeters > *s.MaxRangeMeters {
+		return fmt.Errorf("min range %v exceeds max range %v", *s.MinRangeMeters, *s.MaxRangeMeters)
+	}
+	if s.RegionID != nil && *s.RegionID < 0 {
+		return fmt.Errorf("region id must be >= 0, got %d", *s.RegionID)
+	}
+	return nil
+}
+
+// containsAzimuth reports whether az (degrees) falls in the azimuth window.
+func (s GridResetScope) containsAzimuth(az float64) bool {
+	if s.AzimuthMinDeg == nil {
+		return true
+	}
+	lo, hi := *s.AzimuthMinDeg, *s.AzimuthMaxDeg
+	if lo <= hi {
+		return az >= lo && az <= hi
+	}
+	return az >= lo || az <= hi
+}
+
+// resetCell zeros one cell's learned statistics, foreground tracking and
+// locked baseline.
+func resetCell(c *BackgroundCell) {
+	c.AverageRangeMeters = 0
+	c.RangeSpreadMeters = 0
+	c.TimesSeenCount = 0
+	c.LastUpdateUnixNanos = 0
+	c.FrozenUntilUnixNanos = 0
+	c.RecentForegroundCount = 0
+	c.LockedBaseline = 0
+	c.LockedSpread = 0
+	c.LockedAtCount = 0
+	c.RangeSampleCount = 0
+	c.RangeMeanMeters = 0
+	c.RangeM2 = 0
+}
+
+// ResetGridScope clears only the cells in scope, so the rest of the learned
+// background survives, e.g. after a vehicle parked in view during learning
+// has left. Settling state, regions and acceptance counters are kept; the
+// cleared cells relearn like new cells. It returns the number of cells
+// cleared. A zero scope is rejected: use ResetGrid for a full reset.
+func (bm *BackgroundManager) ResetGridScope(scope GridResetScope) (int, error) {
+	if bm == nil || bm.Grid == nil {
+		return 0, fmt.Errorf("background manager or grid nil")
+	}
+	if scope.IsZero() {
+		return 0, fmt.Errorf("reset scope has no bounds")
+	}
+	if err := scope.Validate(); err != nil {
+		return 0, err
+	}
+	g := bm.Grid
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if scope.RegionID != nil && (g.RegionMgr == nil || g.RegionMgr.GetRegionParams(*scope.RegionID) == nil) {
+		return 0, fmt.Errorf("region %d not found", *scope.RegionID)
+	}
+
+	binDeg := 360.0 / float64(g.AzimuthBins)
+	cleared := 0
+	for ring := 0; ring < g.Rings; ring++ {
+		for bin := 0; bin < g.AzimuthBins; bin++ {
+			if !scope.containsAzimuth((float64(bin) + 0.5) * binDeg) {
+				continue
+			}
+			idx := g.Idx(ring, bin)
+			cell := &g.Cells[idx]
+			if cell.TimesSeenCount == 0 && cell.AverageRangeMeters == 0 {
+				continue
+			}
+			avg := float64(cell.AverageRangeMeters)
+			if scope.MinRangeMeters != nil && avg < *scope.MinRangeMeters {
+				continue
+			}
+			if scope.MaxRangeMeters != nil && avg > *scope.MaxRangeMeters {
+				continue
+			}
+			if scope.RegionID != nil && g.RegionMgr.GetRegionForCell(idx) != *scope.RegionID {
+				continue
+			}
+			if cell.TimesSeenCount > 0 && g.nonzeroCellCount > 0 {
+				g.nonzeroCellCount--
+			}
+			resetCell(cell)
+			if g.model != nil {
+				g.model.reset(idx)
+			}
+			cleared++
+		}
+	}
+	g.ChangesSinceSnapshot += cleared
+
+	diagf("[ResetGridScope] sensor=%s cells_cleared=%d total_cells=%d timestamp=%d",
+		g.SensorID, cleared, len(g.Cells), time.Now().UnixNano())
+	return cleared, nil
+}
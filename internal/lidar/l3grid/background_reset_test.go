@@ -0,0 +1,105 @@
+package l3grid
+
+import "testing"
+
+// learntGrid returns a manager whose 2x8 grid has every cell learnt: ring 0
+// at 10 m and ring 1 at 30 m. Each azimuth bin spans 45°.
+func learntGrid() *BackgroundManager {
+	grid := &BackgroundGrid{SensorID: "reset-scope", Rings: 2, AzimuthBins: 8}
+	grid.Cells = make([]BackgroundCell, grid.Rings*grid.AzimuthBins)
+	for ring := 0; ring < grid.Rings; ring++ {
+		for bin := 0; bin < grid.AzimuthBins; bin++ {
+			c := &grid.Cells[grid.Idx(ring, bin)]
+			c.AverageRangeMeters = 10 + 20*float32(ring)
+			c.RangeSpreadMeters = 0.2
+			c.TimesSeenCount = 50
+			c.LastUpdateUnixNanos = 1000
+		}
+	}
+	grid.nonzeroCellCount = len(grid.Cells)
+	return &BackgroundManager{Grid: grid}
+}
+
+func TestResetGridScope_ClearsOnlyCellsInScope(t *testing.T) {
+	bm := learntGrid()
+	g := bm.Grid
+	az := func(v float64) *float64 { return &v }
+
+	// Bins 7 and 0 (centres 337.5° and 22.5°), near ring only.
+	cleared, err := bm.ResetGridScope(GridResetScope{
+		AzimuthMinDeg: az(315), AzimuthMaxDeg: az(45), MaxRangeMeters: az(20),
+	})
+	if err != nil {
+		t.Fatalf("ResetGridScope: %v", err)
+	}
+	if cleared != 2 {
+		t.Errorf("cleared %d cells, want 2", cleared)
+	}
+	for ring := 0; ring < g.Rings; ring++ {
+		for bin := 0; bin < g.AzimuthBins; bin++ {
+			c := g.Cells[g.Idx(ring, bin)]
+			inScope := ring == 0 && (bin == 0 || bin == 7)
+			if inScope && (c.TimesSeenCount != 0 || c.AverageRangeMeters != 0 || c.LastUpdateUnixNanos != 0) {
+				t.Errorf("ring %d bin %d in scope kept %+v", ring, bin, c)
+			}
+			if !inScope && (c.TimesSeenCount != 50 || c.AverageRangeMeters != 10+20*float32(ring)) {
+				t.Errorf("ring %d bin %d out of scope lost its model: %+v", ring, bin, c)
+			}
+		}
+	}
+	if g.nonzeroCellCount != len(g.Cells)-2 {
+		t.Errorf("nonzero cell count = %d, want %d", g.nonzeroCellCount, len(g.Cells)-2)
+	}
+	if g.ChangesSinceSnapshot != 2 {
+		t.Errorf("ChangesSinceSnapshot = %d, want 2", g.ChangesSinceSnapshot)
+	}
+}
+
+func TestResetGridScope_Region(t *testing.T) {
+	bm := learntGrid()
+	g := bm.Grid
+	rm := NewRegionManager(g.Rings, g.AzimuthBins)
+	rm.Regions = []*Region{{ID: 0}, {ID: 1}}
+	for i := range rm.CellToRegionID {
+		rm.CellToRegionID[i] = i % 2
+	}
+	rm.IdentificationComplete = true
+	g.RegionMgr = rm
+
+	region := 1
+	cleared, err := bm.ResetGridScope(GridResetScope{RegionID: &region})
+	if err != nil {
+		t.Fatalf("ResetGridScope: %v", err)
+	}
+	if cleared != len(g.Cells)/2 {
+		t.Errorf("cleared %d cells, want %d", cleared, len(g.Cells)/2)
+	}
+	for i, c := range g.Cells {
+		if want := i%2 == 0; (c.TimesSeenCount != 0) != want {
+			t.Errorf("cell %d in region %d: times seen %d", i, i%2, c.TimesSeenCount)
+		}
+	}
+
+	missing := 5
+	if _, err := bm.ResetGridScope(GridResetScope{RegionID: &missing}); err == nil {
+		t.Error("expected an error for an unknown region")
+	}
+}
+
+func TestResetGridScope_Rejects(t *testing.T) {
+	bm := learntGrid()
+	lo, hi := 30.0, 10.0
+	for name, scope := range map[string]GridResetScope{
+		"empty":          {},
+		"half azimuth":   {AzimuthMinDeg: &lo},
+		"inverted range": {MinRangeMeters: &lo, MaxRangeMeters: &hi},
+	} {
+		if _, err := bm.ResetGridScope(scope); err == nil {
+			t.Errorf("%s scope: expected an error", name)
+		}
+	}
+	var nilBM *BackgroundManager
+	if _, err := nilBM.ResetGridScope(GridResetScope{MinRangeMeters: &lo}); err == nil {
+		t.Error("expected an error for a nil manager")
+	}
+}
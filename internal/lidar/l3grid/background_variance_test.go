@@ -0,0 +1,128 @@
+package l3grid
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBackgroundCell_AddRangeSample(t *testing.T) {
+	var c BackgroundCell
+	if c.RangeStdDevMeters() != 0 {
+		t.Fatal("empty cell should have zero std-dev")
+	}
+	for _, r := range []float64{8, 9, 10, 11, 12} {
+		c.addRangeSample(r)
+	}
+	if c.RangeSampleCount != 5 {
+		t.Errorf("sample count = %d, want 5", c.RangeSampleCount)
+	}
+	if math.Abs(float64(c.RangeMeanMeters)-10) > 1e-5 {
+		t.Errorf("mean = %v, want 10", c.RangeMeanMeters)
+	}
+	// Sample variance of {8..12} is 2.5.
+	if got := c.RangeStdDevMeters(); math.Abs(float64(got)-math.Sqrt(2.5)) > 1e-5 {
+		t.Errorf("std-dev = %v, want %v", got, math.Sqrt(2.5))
+	}
+}
+
+func TestBackgroundCell_AddRangeSample_WindowAdapts(t *testing.T) {
+	var c BackgroundCell
+	for i := 0; i < rangeVarianceWindow*2; i++ {
+		c.addRangeSample(10)
+	}
+	if c.RangeSampleCount != rangeVarianceWindow {
+		t.Fatalf("sample count = %d, want capped at %d", c.RangeSampleCount, rangeVarianceWindow)
+	}
+	// The mean must follow a step change once the window is full.
+	for i := 0; i < rangeVarianceWindow*5; i++ {
+		c.addRangeSample(20)
+	}
+	if math.Abs(float64(c.RangeMeanMeters)-20) > 0.1 {
+		t.Errorf("mean = %v, want ≈20 after step change", c.RangeMeanMeters)
+	}
+}
+
+// makeNoisyCellManager trains a single cell on a jittery background
+// (foliage-like returns spread ±1.5 m around 10 m) and then tightens the
+// global closeness test to its operating value.
+func makeNoisyCellManager(t *testing.T, varianceK float32) (*BackgroundManager, int) {
+	t.Helper()
+	g := &BackgroundGrid{
+		SensorID:    "noisy-cell",
+		SensorFrame: "sensor/test",
+		Rings:       2,
+		AzimuthBins: 8,
+		Cells:       make([]BackgroundCell, 2*8),
+		Params: BackgroundParams{
+			BackgroundUpdateFraction:       0.02,
+			ClosenessSensitivityMultiplier: 10.0, // permissive while learning
+			SafetyMarginMetres:             0.5,
+			FreezeDurationNanos:            int64(time.Second),
+			NeighbourConfirmationCount:     5,
+			NoiseRelativeFraction:          0.01,
+			SeedFromFirstObservation:       true,
+			LockedBaselineMultiplier:       1.0,
+			VarianceThresholdK:             varianceK,
+		},
+	}
+	g.Manager = &BackgroundManager{Grid: g}
+
+	jitter := []float64{0, 0.75, -0.75, 1.5, -1.5}
+	for i := 0; i < 300; i++ {
+		if _, err := g.Manager.ProcessFramePolarWithMask([]PointPolar{
+			{Channel: 1, Azimuth: 0, Distance: 10 + jitter[i%len(jitter)]},
+		}); err != nil {
+			t.Fatalf("training frame %d: %v", i, err)
+		}
+	}
+	g.Params.ClosenessSensitivityMultiplier = 2.0
+	return g.Manager, g.Idx(0, 0)
+}
+
+func TestProcessFramePolarWithMask_VarianceGateNoisyCell(t *testing.T) {
+	probe := []PointPolar{
+		{Channel: 1, Azimuth: 0, Distance: 13.0}, // jittery background tail
+		{Channel: 1, Azimuth: 0, Distance: 5.0},  // real object
+	}
+
+	fixed, _ := makeNoisyCellManager(t, 0)
+	mask, err := fixed.ProcessFramePolarWithMask(probe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mask[0] {
+		t.Error("without variance gate: expected 13 m jitter to be rejected as foreground")
+	}
+	if !mask[1] {
+		t.Error("without variance gate: expected 5 m object to be foreground")
+	}
+
+	adaptive, idx := makeNoisyCellManager(t, 3.0)
+	cell := adaptive.Grid.Cells[idx]
+	wantStd := math.Sqrt((0 + 2*0.5625 + 2*2.25) / 5)
+	if got := float64(cell.RangeStdDevMeters()); math.Abs(got-wantStd) > 0.02 {
+		t.Errorf("cell std-dev = %.3f, want ≈%.3f", got, wantStd)
+	}
+	mask, err = adaptive.ProcessFramePolarWithMask(probe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mask[0] {
+		t.Error("with variance gate: expected 13 m jitter to be accepted as background")
+	}
+	if !mask[1] {
+		t.Error("with variance gate: expected 5 m object to remain foreground")
+	}
+}
+
+func TestBackgroundConfig_VarianceThresholdK(t *testing.T) {
+	cfg := DefaultBackgroundConfig().WithVarianceThresholdK(2.5)
+	if got := cfg.ToBackgroundParams().VarianceThresholdK; got != 2.5 {
+		t.Errorf("VarianceThresholdK = %v, want 2.5", got)
+	}
+	cfg.VarianceThresholdK = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative VarianceThresholdK")
+	}
+}
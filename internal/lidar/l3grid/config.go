@@ -37,6 +37,10 @@ type BackgroundConfig struct {
 	ReacquisitionBoostMultiplier      float32 // Boost for re-acquiring background (default: 5.0)
 	LockedBaselineThreshold           uint32  // Min count before locking baseline (default: 50)
 	LockedBaselineMultiplier          float32 // Spread multiplier for locked baseline (default: 4.0)
+	VarianceThresholdK                float32 // Per-cell σ multiplier for the variance gate; 0 disables (default: 0)
+	AzimuthToleranceDeg               float32 // Azimuth half-width of the anisotropic closeness ellipse; 0 disables (default: 0)
+	RangeToleranceSigmaK              float32 // Range half-width of the ellipse in cell σ; 0 uses the closeness threshold (default: 0)
+	RegionWarmupMinFrames             int     // Quiet frames each region needs after identification; 0 disables (default: 0)
 	SensorMovementForegroundThreshold float32 // Fraction of foreground points that indicates sensor movement
 	BackgroundDriftThresholdMetres    float32 // Drift distance threshold for locked baseline checks
 	BackgroundDriftRatioThreshold     float32 // Fraction of settled cells that must drift to trigger reset
@@ -45,6 +49,9 @@ type BackgroundConfig struct {
 	SettlingMinRegionStability        float32 // Minimum region stability for settling convergence
 	SettlingMinConfidence             float32 // Minimum confidence for settling convergence
 
+	// Background model
+	ModelType BackgroundModelType // Per-cell model: mean, median or gmm (default: mean)
+
 	// Foreground filtering
 	ForegroundMinClusterPoints int     // Min points for cluster (default: 0)
 	ForegroundDBSCANEps        float32 // DBSCAN epsilon (default: 0)
@@ -93,6 +100,11 @@ func BackgroundConfigFromTuning(l3cfg *config.L3EmaBaselineV1, l4cfg *config.L4D
 		SettlingMaxSpreadDelta:            float32(l3cfg.SettlingMaxSpreadDelta),
 		SettlingMinRegionStability:        float32(l3cfg.SettlingMinRegionStability),
 		SettlingMinConfidence:             float32(l3cfg.SettlingMinConfidence),
+		AzimuthToleranceDeg:               float32(l3cfg.AzimuthToleranceDeg),
+		RangeToleranceSigmaK:              float32(l3cfg.RangeToleranceSigmaK),
+		ModelType:                         BackgroundModelType(l3cfg.BackgroundModel),
+		VarianceThresholdK:                float32(l3cfg.VarianceThresholdK),
+		RegionWarmupMinFrames:             l3cfg.RegionWarmupMinFrames,
 
 		ForegroundMinClusterPoints: l4cfg.ForegroundMinClusterPoints,
 		ForegroundDBSCANEps:        float32(l4cfg.ForegroundDBSCANEps),
@@ -168,6 +180,21 @@ func (c *BackgroundConfig) Validate() error {
 	if c.SettlingMinConfidence < 0 {
 		return fmt.Errorf("SettlingMinConfidence must be non-negative, got %f", c.SettlingMinConfidence)
 	}
+	if c.VarianceThresholdK < 0 {
+		return fmt.Errorf("VarianceThresholdK must be non-negative, got %f", c.VarianceThresholdK)
+	}
+	if c.AzimuthToleranceDeg < 0 || c.AzimuthToleranceDeg > 5 {
+		return fmt.Errorf("AzimuthToleranceDeg must be in [0, 5], got %f", c.AzimuthToleranceDeg)
+	}
+	if c.RangeToleranceSigmaK < 0 {
+		return fmt.Errorf("RangeToleranceSigmaK must be non-negative, got %f", c.RangeToleranceSigmaK)
+	}
+	if c.RegionWarmupMinFrames < 0 {
+		return fmt.Errorf("RegionWarmupMinFrames must be non-negative, got %d", c.RegionWarmupMinFrames)
+	}
+	if _, err := ParseBackgroundModelType(string(c.ModelType)); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -192,6 +219,11 @@ func (c *BackgroundConfig) ToBackgroundParams() BackgroundParams {
 		ReacquisitionBoostMultiplier:      c.ReacquisitionBoostMultiplier,
 		LockedBaselineThreshold:           c.LockedBaselineThreshold,
 		LockedBaselineMultiplier:          c.LockedBaselineMultiplier,
+		VarianceThresholdK:                c.VarianceThresholdK,
+		AzimuthToleranceDeg:               c.AzimuthToleranceDeg,
+		RangeToleranceSigmaK:              c.RangeToleranceSigmaK,
+		RegionWarmupMinFrames:             c.RegionWarmupMinFrames,
+		ModelType:                         c.ModelType,
 		SensorMovementForegroundThreshold: c.SensorMovementForegroundThreshold,
 		BackgroundDriftThresholdMetres:    c.BackgroundDriftThresholdMetres,
 		BackgroundDriftRatioThreshold:     c.BackgroundDriftRatioThreshold,
@@ -295,3 +327,25 @@ func (c *BackgroundConfig) WithForegroundMaxInputPoints(n int) *BackgroundConfig
 	c.ForegroundMaxInputPoints = n
 	return c
 }
+
+// WithVarianceThresholdK enables the per-cell variance gate with the given
+// standard-deviation multiplier. Zero disables it.
+func (c *BackgroundConfig) WithVarianceThresholdK(k float32) *BackgroundConfig {
+	c.VarianceThresholdK = k
+	return c
+}
+
+// WithRegionWarmupMinFrames enables per-region warmup: each region needs n
+// quiet frames, scaled by its variance category, after identification
+// before it emits foreground. Zero disables it.
+func (c *BackgroundConfig) WithRegionWarmupMinFrames(n int) *BackgroundConfig {
+	c.RegionWarmupMinFrames = n
+	return c
+}
+
+// WithModelType selects the per-cell background model. Empty or
+// BackgroundModelMean keeps the running mean.
+func (c *BackgroundConfig) WithModelType(t BackgroundModelType) *BackgroundConfig {
+	c.ModelType = t
+	return c
+}
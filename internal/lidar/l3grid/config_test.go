@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/banshee-data/velocity.report/internal/config"
 )
 
 func TestDefaultBackgroundConfig(t *testing.T) {
@@ -422,3 +424,24 @@ func TestBackgroundConfig_Validate_NewFieldErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestBackgroundConfigFromTuning_ReadsModelKeys(t *testing.T) {
+	tuning := config.MustLoadDefaultConfig()
+	tuning.L3.EmaBaselineV1.BackgroundModel = "gmm"
+	tuning.L3.EmaBaselineV1.VarianceThresholdK = 3
+	tuning.L3.EmaBaselineV1.RegionWarmupMinFrames = 50
+
+	cfg := BackgroundConfigFromTuning(tuning.L3.EmaBaselineV1, tuning.L4.DbscanXyV1)
+	if cfg.ModelType != BackgroundModelGMM {
+		t.Errorf("ModelType = %q, want %q", cfg.ModelType, BackgroundModelGMM)
+	}
+	if cfg.VarianceThresholdK != 3 {
+		t.Errorf("VarianceThresholdK = %v, want 3", cfg.VarianceThresholdK)
+	}
+	if cfg.RegionWarmupMinFrames != 50 {
+		t.Errorf("RegionWarmupMinFrames = %d, want 50", cfg.RegionWarmupMinFrames)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
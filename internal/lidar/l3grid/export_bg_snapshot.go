@@ -4,13 +4,14 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/gob"
-	"encoding/json"
+	"errors"
 	"fmt"
 )
 
 // ExportBgSnapshotToASC decodes a BgSnapshot's grid blob, constructs a temporary
 // BackgroundGrid and BackgroundManager, supplies per-ring elevations (preferring
-// a live BackgroundManager and falling back to embedded parser config), and
+// the table stored with the snapshot, then the caller's, then a live
+// BackgroundManager's), and
 // exports the resulting points to an ASC file.
 // Returns the path where the file was written.
 func ExportBgSnapshotToASC(snap *BgSnapshot, ringElevations []float64) (string, error) {
@@ -39,16 +40,15 @@ func ExportBgSnapshotToASC(snap *BgSnapshot, ringElevations []float64) (string,
 	mgr := &BackgroundManager{Grid: grid}
 
 	// Prefer snapshot-stored elevations, then caller-supplied, then live manager copy.
-	if snap.RingElevationsJSON != "" {
-		var elevs []float64
-		if err := json.Unmarshal([]byte(snap.RingElevationsJSON), &elevs); err == nil && len(elevs) == grid.Rings {
-			if err := mgr.SetRingElevations(elevs); err != nil {
-				diagf("Export: failed to set snapshot-stored ring elevations for sensor %s: %v", snap.SensorID, err)
-			} else {
-				diagf("Export: used ring elevations embedded in snapshot for sensor %s", snap.SensorID)
-				return mgr.ExportBackgroundGridToASC()
-			}
+	if elevs, err := snap.RingElevations(); err == nil {
+		if err := mgr.SetRingElevations(elevs); err != nil {
+			diagf("Export: failed to set snapshot-stored ring elevations for sensor %s: %v", snap.SensorID, err)
+		} else {
+			diagf("Export: used ring elevations embedded in snapshot for sensor %s", snap.SensorID)
+			return mgr.ExportBackgroundGridToASC()
 		}
+	} else if !errors.Is(err, ErrNoRingElevations) {
+		diagf("Export: ignoring ring elevations stored with snapshot for sensor %s: %v", snap.SensorID, err)
 	}
 
 	if ringElevations != nil && len(ringElevations) == grid.Rings {
@@ -36,6 +36,22 @@ const (
 	regionRestoreMinFrames = 10
 )
 
+// PointDebugRecord is the foreground decision for one point in the debug
+// region, passed to BackgroundManager.PointDebugCallback. The background
+// fields are the cell's values the point was compared against, before this
+// point updated them.
+type PointDebugRecord struct {
+	Ring             int     // Zero-based ring index
+	AzimuthDeg       float64 // Normalised to [0, 360)
+	RangeM           float64 // Raw range of the point
+	BackgroundRangeM float32 // Cell EMA background range
+	SpreadM          float32 // Cell EMA range spread
+	ThresholdM       float64 // Closeness threshold (0 when frozen)
+	TimesSeen        uint32  // Cell confidence count
+	Frozen           bool    // Cell frozen; the point is foreground without comparison
+	Foreground       bool
+}
+
 // ProcessFramePolarWithMask classifies each point as foreground/background in polar coordinates.
 // Returns a mask where true indicates foreground (object), false indicates background (static).
 // Foreground extraction stage of the tracking pipeline.
@@ -56,6 +72,7 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 	if rings <= 0 || azBins <= 0 || len(g.Cells) != rings*azBins {
 		return nil, nil
 	}
+	bm.observeRingElevations(points)
 
 	// Reuse mask buffer to avoid allocating ~69 KB every frame.
 	// The buffer is zeroed (all false) before use since we only set true
@@ -73,6 +90,9 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 
 	// Read diagnostics flag once per frame (atomic — no lock needed).
 	enableDiag := bm.enableDiagnostics.Load()
+	// While learning is frozen every point is classified but no cell changes.
+	learningFrozen := bm.learningFrozen.Load()
+	debugPoint := bm.PointDebugCallback
 
 	now := time.Now()
 	nowNanos := now.UnixNano()
@@ -141,6 +161,10 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 	if lockedMultiplier <= 0 {
 		lockedMultiplier = DefaultLockedBaselineMultiplier
 	}
+	varianceK := float64(g.Params.VarianceThresholdK)
+	model := g.cellModelLocked()
+	azTol := float64(g.Params.AzimuthToleranceDeg)
+	rangeK := float64(g.Params.RangeToleranceSigmaK)
 
 	// Warmup gating: suppress foreground output until duration and/or frames satisfied.
 	postSettleAlpha := float64(g.Params.PostSettleUpdateFraction)
@@ -199,6 +223,15 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 		effectiveAlpha = alpha
 	}
 
+	// Per-region warmup runs after the global warmup, once regions exist.
+	var pointRegion []int
+	if !warmupActive && g.RegionMgr != nil && g.RegionMgr.regionWarmupPending() {
+		pointRegion = make([]int, len(points))
+		for i := range pointRegion {
+			pointRegion[i] = -1
+		}
+	}
+
 	// Process each point individually
 	foregroundCount := int64(0)
 	backgroundCount := int64(0)
@@ -227,6 +260,10 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 
 		cellIdx := g.Idx(ring, azBin)
 		cell := &g.Cells[cellIdx]
+		if pointRegion != nil {
+			pointRegion[i] = g.RegionMgr.GetRegionForCell(cellIdx)
+		}
+		debugThisPoint := debugPoint != nil && g.Params.IsInDebugRange(ring, az)
 
 		// Region-adaptive parameter overrides (if identified) must apply on the
 		// mask path as well, since this is the production runtime path.
@@ -244,6 +281,13 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 				tracef("[FG_FROZEN] r=%d az=%.1f dist=%.3f avg=%.3f remainingFreezeSec=%.2f recFg=%d",
 					ring, az, p.Distance, cell.AverageRangeMeters, remainingFreeze, cell.RecentForegroundCount)
 			}
+			if debugThisPoint {
+				debugPoint(PointDebugRecord{
+					Ring: ring, AzimuthDeg: az, RangeM: p.Distance,
+					BackgroundRangeM: cell.AverageRangeMeters, SpreadM: cell.RangeSpreadMeters,
+					TimesSeen: cell.TimesSeenCount, Frozen: true, Foreground: true,
+				})
+			}
 			continue
 		}
 
@@ -253,7 +297,7 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 		// Note: We only trigger thaw when the freeze was meaningful (expired at least 1ms ago).
 		// This avoids false triggers when FreezeDurationNanos=0 causes immediate "expiry".
 		// Limitation: Thaw is only detected when a point observation hits this cell.
-		if cell.FrozenUntilUnixNanos > 0 && cell.FrozenUntilUnixNanos+ThawGracePeriodNanos <= nowNanos {
+		if !learningFrozen && cell.FrozenUntilUnixNanos > 0 && cell.FrozenUntilUnixNanos+ThawGracePeriodNanos <= nowNanos {
 			if enableDiag && g.Params.IsInDebugRange(ring, az) {
 				tracef("[FG_THAW] r=%d az=%.1f thawed after freeze, resetting recFg from %d to 0",
 					ring, az, cell.RecentForegroundCount)
@@ -264,7 +308,7 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 
 		// Same-ring neighbour confirmation
 		neighbourConfirmCount := 0
-		if cellNeighbourConfirm > 0 {
+		if model == nil && cellNeighbourConfirm > 0 {
 			// Search radius should be at least equal to the required confirmation count
 			// to make it possible to satisfy the condition.
 			searchRadius := cellNeighbourConfirm
@@ -306,6 +350,14 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 
 		closenessThreshold := closenessMultiplier*(float64(cell.RangeSpreadMeters)+cellNoiseRel*p.Distance+0.01)*warmupMultiplier + safety
 		cellDiff := math.Abs(float64(cell.AverageRangeMeters) - p.Distance)
+		var debugRec PointDebugRecord
+		if debugThisPoint {
+			debugRec = PointDebugRecord{
+				Ring: ring, AzimuthDeg: az, RangeM: p.Distance,
+				BackgroundRangeM: cell.AverageRangeMeters, SpreadM: cell.RangeSpreadMeters,
+				ThresholdM: closenessThreshold, TimesSeen: cell.TimesSeenCount,
+			}
+		}
 
 		// Locked baseline classification: if cell has a locked baseline, use it for classification
 		// This protects against EMA drift during transits
@@ -322,10 +374,33 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 			isWithinLockedRange = lockedDiff <= lockedWindow
 		}
 
+		// Per-cell variance gate: accept within mean ± k·σ of the cell's own
+		// background statistics, so noisy cells get a wider window than
+		// the global closeness multiplier alone allows.
+		isWithinVarianceRange := false
+		if varianceK > 0 && cell.RangeSampleCount >= minVarianceSamples {
+			varianceDiff := math.Abs(float64(cell.RangeMeanMeters) - p.Distance)
+			isWithinVarianceRange = varianceDiff <= varianceK*float64(cell.RangeStdDevMeters())
+		}
+
+		// Anisotropic closeness: separate range and azimuth tolerances, so
+		// azimuth jitter across a depth edge matches the neighbouring cell.
+		isWithinEllipse := false
+		if cellAzTol := g.effectiveAzimuthTolerance(cellIdx, azTol); cellAzTol > 0 {
+			isWithinEllipse = g.withinClosenessEllipse(ring, azBin, az, p.Distance, cellAzTol, rangeK, closenessMultiplier, cellNoiseRel, safety)
+		}
+
 		// Classification decision: prioritize locked baseline if available
-		isBackgroundLike := isWithinLockedRange ||
+		isBackgroundLike := isWithinLockedRange || isWithinVarianceRange || isWithinEllipse ||
 			cellDiff <= closenessThreshold ||
 			(cellNeighbourConfirm > 0 && neighbourConfirmCount >= cellNeighbourConfirm)
+		if model != nil {
+			// A median or mixture model replaces the running-mean gates.
+			isBackgroundLike = model.observe(cellIdx, cell, p.Distance, modelGate{
+				closeness: closenessMultiplier, noiseRel: cellNoiseRel, warmup: warmupMultiplier,
+				safety: safety, alpha: cellAlpha, learn: !learningFrozen,
+			})
+		}
 
 		// Deadlock Breaker:
 		// If a cell is persistently classified as foreground (RecentForegroundCount high)
@@ -334,7 +409,7 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 		// we assume our background model is stale/corrupted and force an update.
 		// This fixes "ghost trails" where the model learns a transient edge value
 		// and then rejects the true background because it differs from that edge.
-		if !isBackgroundLike && cell.TimesSeenCount <= minConfFloor && cell.RecentForegroundCount > 4 {
+		if model == nil && !isBackgroundLike && cell.TimesSeenCount <= minConfFloor && cell.RecentForegroundCount > 4 {
 			// Only force-learn if we wouldn't otherwise freeze this cell (avoid learning dynamic obstacles)
 			freezeThresh := freezeThresholdMultiplier * closenessThreshold
 			if cellDiff <= freezeThresh {
@@ -349,7 +424,14 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 		}
 
 		// Set mask value: true = foreground, false = background
-		if isBackgroundLike || initIfEmpty {
+		if learningFrozen {
+			foregroundMask[i] = !(isBackgroundLike || initIfEmpty)
+			if foregroundMask[i] {
+				foregroundCount++
+			} else {
+				backgroundCount++
+			}
+		} else if isBackgroundLike || initIfEmpty {
 			foregroundMask[i] = false
 			backgroundCount++
 
@@ -364,6 +446,10 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 				cell.LockedBaseline = 0
 				cell.LockedSpread = 0
 				cell.LockedAtCount = 0
+				cell.RangeSampleCount = 0
+				cell.RangeMeanMeters = 0
+				cell.RangeM2 = 0
+				cell.addRangeSample(p.Distance)
 			} else {
 				// Fast re-acquisition: if cell recently saw foreground, use boosted alpha
 				// to quickly re-converge to background after object passes
@@ -379,6 +465,7 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 				cell.AverageRangeMeters = float32(newAvg)
 				cell.RangeSpreadMeters = float32(newSpread)
 				cell.TimesSeenCount++
+				cell.addRangeSample(p.Distance)
 
 				// Lock the baseline once we've seen enough observations
 				// This provides a stable reference that doesn't drift during transits
@@ -425,7 +512,7 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 			// Freeze cell if divergence is very large, but only if we are not confident
 			// (TimesSeenCount < 100). If we have a solid background (e.g. static road),
 			// a passing object should not freeze the background model.
-			if cell.TimesSeenCount < 100 && cellDiff > freezeThresholdMultiplier*closenessThreshold {
+			if model == nil && cell.TimesSeenCount < 100 && cellDiff > freezeThresholdMultiplier*closenessThreshold {
 				if enableDiag && g.Params.IsInDebugRange(ring, az) {
 					tracef("[FG_FREEZE] r=%d az=%.1f froze for %.1fs, dist=%.3f avg=%.3f cellDiff=%.3f freezeThresh=%.3f recFg=%d",
 						ring, az, float64(freezeDur)/1e9, p.Distance, cell.AverageRangeMeters,
@@ -443,8 +530,14 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 				cellDiff, closenessThreshold, cell.TimesSeenCount, cell.RecentForegroundCount,
 				cell.FrozenUntilUnixNanos > nowNanos, !foregroundMask[i])
 		}
+		if debugThisPoint {
+			debugRec.Foreground = foregroundMask[i]
+			debugPoint(debugRec)
+		}
 
-		g.ChangesSinceSnapshot++
+		if !learningFrozen {
+			g.ChangesSinceSnapshot++
+		}
 
 		// Update per-range acceptance metrics (mirrors ProcessFramePolar logic).
 		// This is essential for the sweep tool to measure background-model fit.
@@ -475,6 +568,16 @@ func (bm *BackgroundManager) ProcessFramePolarWithMask(points []PointPolar) (for
 		}
 	}
 
+	// Suppress foreground from regions still in their per-region warmup.
+	if pointRegion != nil {
+		suppressedFg := g.RegionMgr.applyRegionWarmup(pointRegion, foregroundMask)
+		foregroundCount -= suppressedFg
+		backgroundCount += suppressedFg
+		if enableDiag && suppressedFg > 0 {
+			tracef("[Foreground] region warmup active: suppressed_fg=%d total_points=%d", suppressedFg, len(points))
+		}
+	}
+
 	// Update telemetry counters
 	g.ForegroundCount = foregroundCount
 	g.BackgroundCount = backgroundCount
@@ -0,0 +1,114 @@
+package l3grid
+
+import (
+	"testing"
+	"time"
+)
+
+// makeDepthEdgeManager trains one ring of 0.2° cells on a wall 30 m away
+// with a pole 10 m away filling the single cell at 90.0–90.2°.
+func makeDepthEdgeManager(t *testing.T, azTol, rangeK float32) *BackgroundManager {
+	t.Helper()
+	g := &BackgroundGrid{
+		SensorID:    "depth-edge",
+		SensorFrame: "sensor/test",
+		Rings:       1,
+		AzimuthBins: 1800,
+		Cells:       make([]BackgroundCell, 1800),
+		Params: BackgroundParams{
+			BackgroundUpdateFraction:       0.02,
+			ClosenessSensitivityMultiplier: 3.0,
+			SafetyMarginMetres:             0.3,
+			FreezeDurationNanos:            int64(time.Second),
+			NeighbourConfirmationCount:     3,
+			NoiseRelativeFraction:          0.01,
+			SeedFromFirstObservation:       true,
+			AzimuthToleranceDeg:            azTol,
+			RangeToleranceSigmaK:           rangeK,
+		},
+	}
+	g.Manager = &BackgroundManager{Grid: g}
+
+	jitter := []float64{0, 0.02, -0.02, 0.01, -0.01}
+	for i := 0; i < 300; i++ {
+		var frame []PointPolar
+		for bin := 440; bin <= 460; bin++ {
+			r := 30.0
+			if bin == 450 {
+				r = 10.0
+			}
+			frame = append(frame, PointPolar{Channel: 1, Azimuth: float64(bin)*0.2 + 0.1, Distance: r + jitter[(i+bin)%len(jitter)]})
+		}
+		if _, err := g.Manager.ProcessFramePolarWithMask(frame); err != nil {
+			t.Fatalf("training frame %d: %v", i, err)
+		}
+	}
+	return g.Manager
+}
+
+// TestProcessFramePolarWithMask_AnisotropicDepthEdge checks that a pole
+// return wobbling 0.05° into the wall's cell is foreground under the
+// isotropic test but background with an azimuth tolerance, while real
+// objects and returns well clear of the edge stay foreground.
+func TestProcessFramePolarWithMask_AnisotropicDepthEdge(t *testing.T) {
+	probe := []PointPolar{
+		{Channel: 1, Azimuth: 90.25, Distance: 10.0}, // pole return, 0.05° past its cell
+		{Channel: 1, Azimuth: 90.25, Distance: 20.0}, // object in front of the wall
+		{Channel: 1, Azimuth: 90.39, Distance: 10.0}, // 0.19° from the pole: not jitter
+		{Channel: 1, Azimuth: 90.31, Distance: 30.0}, // the wall itself
+	}
+	for _, tc := range []struct {
+		name          string
+		azTol, rangeK float32
+		want          []bool
+	}{
+		{"isotropic", 0, 0, []bool{true, true, true, false}},
+		{"anisotropic", 0.1, 0, []bool{false, true, true, false}},
+		{"anisotropic σ range", 0.1, 3, []bool{false, true, true, false}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mask, err := makeDepthEdgeManager(t, tc.azTol, tc.rangeK).ProcessFramePolarWithMask(probe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tc.want {
+				if mask[i] != want {
+					t.Errorf("probe %d (az %.2f°, %.0f m): foreground = %v, want %v",
+						i, probe[i].Azimuth, probe[i].Distance, mask[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestBackgroundGrid_EffectiveAzimuthTolerance(t *testing.T) {
+	g := &BackgroundGrid{Rings: 2, AzimuthBins: 4, Cells: make([]BackgroundCell, 8)}
+	if got := g.effectiveAzimuthTolerance(0, 0.1); got != 0.1 {
+		t.Errorf("no regions: tolerance = %v, want the default 0.1", got)
+	}
+	g.RegionMgr = NewRegionManager(2, 4)
+	g.RegionMgr.IdentificationComplete = true
+	g.RegionMgr.Regions = []*Region{{ID: 0, Params: RegionParams{AzimuthToleranceDeg: 0.4}}}
+	for i := range g.RegionMgr.CellToRegionID {
+		g.RegionMgr.CellToRegionID[i] = 0
+	}
+	if got := g.effectiveAzimuthTolerance(0, 0.1); got != float64(float32(0.4)) {
+		t.Errorf("region override: tolerance = %v, want 0.4", got)
+	}
+}
+
+func TestBackgroundConfig_AnisotropicCloseness(t *testing.T) {
+	cfg := DefaultBackgroundConfig()
+	cfg.AzimuthToleranceDeg, cfg.RangeToleranceSigmaK = 0.2, 3
+	if p := cfg.ToBackgroundParams(); p.AzimuthToleranceDeg != 0.2 || p.RangeToleranceSigmaK != 3 {
+		t.Errorf("params = %v°, %vσ", p.AzimuthToleranceDeg, p.RangeToleranceSigmaK)
+	}
+	cfg.AzimuthToleranceDeg = 6
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a 6° azimuth tolerance")
+	}
+	cfg.AzimuthToleranceDeg, cfg.RangeToleranceSigmaK = 0.2, -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative RangeToleranceSigmaK")
+	}
+}
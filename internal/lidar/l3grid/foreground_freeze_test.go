@@ -0,0 +1,68 @@
+package l3grid
+
+import "testing"
+
+func TestProcessFramePolarWithMask_FrozenLearning(t *testing.T) {
+	g := &BackgroundGrid{
+		SensorID:    "freeze-test",
+		SensorFrame: "sensor/test",
+		Rings:       2,
+		AzimuthBins: 8,
+		Cells:       make([]BackgroundCell, 2*8),
+		Params: BackgroundParams{
+			BackgroundUpdateFraction:       0.1,
+			ClosenessSensitivityMultiplier: 3.0,
+			SafetyMarginMetres:             0.5,
+			NoiseRelativeFraction:          0.01,
+			SeedFromFirstObservation:       true,
+		},
+	}
+	bm := &BackgroundManager{Grid: g}
+	g.Manager = bm
+
+	wall := PointPolar{Channel: 1, Azimuth: 0, Distance: 10}
+	for i := 0; i < 50; i++ {
+		if _, err := bm.ProcessFramePolarWithMask([]PointPolar{wall}); err != nil {
+			t.Fatalf("training frame %d: %v", i, err)
+		}
+	}
+
+	bm.FreezeLearning()
+	if !bm.IsLearningFrozen() || bm.GridStatus()["learning_frozen"] != true {
+		t.Fatal("expected learning to report frozen")
+	}
+	idx := g.Idx(0, 0)
+	before := g.Cells[idx]
+	changesBefore := g.ChangesSinceSnapshot
+
+	// The wall drifts by 0.3 m, which the model would normally absorb, and
+	// an object passes in front of it.
+	drifted := PointPolar{Channel: 1, Azimuth: 0, Distance: 10.3}
+	object := PointPolar{Channel: 1, Azimuth: 1, Distance: 4}
+	for i := 0; i < 20; i++ {
+		mask, err := bm.ProcessFramePolarWithMask([]PointPolar{drifted, object})
+		if err != nil {
+			t.Fatalf("frozen frame %d: %v", i, err)
+		}
+		if mask[0] || !mask[1] {
+			t.Fatalf("frozen frame %d: mask = %v, want the wall as background and the object as foreground", i, mask)
+		}
+		if g.ForegroundCount != 1 || g.BackgroundCount != 1 {
+			t.Fatalf("frozen frame %d: counts fg=%d bg=%d, want 1 and 1", i, g.ForegroundCount, g.BackgroundCount)
+		}
+	}
+	if after := g.Cells[idx]; after != before {
+		t.Errorf("frozen cell changed:\nbefore %+v\nafter  %+v", before, after)
+	}
+	if g.ChangesSinceSnapshot != changesBefore {
+		t.Errorf("ChangesSinceSnapshot advanced from %d to %d while frozen", changesBefore, g.ChangesSinceSnapshot)
+	}
+
+	bm.ThawLearning()
+	if _, err := bm.ProcessFramePolarWithMask([]PointPolar{drifted}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Cells[idx].AverageRangeMeters <= before.AverageRangeMeters {
+		t.Errorf("thawed cell did not adapt: average %v -> %v", before.AverageRangeMeters, g.Cells[idx].AverageRangeMeters)
+	}
+}
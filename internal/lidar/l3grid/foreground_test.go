@@ -657,3 +657,32 @@ func TestProcessFramePolarWithMask_AcceptanceCounting(t *testing.T) {
 	t.Logf("Acceptance counting: accept=%d, reject=%d, total=%d, rate=%.2f%%",
 		totalAccept, totalReject, total, float64(totalAccept)/float64(total)*100)
 }
+
+func TestProcessFramePolarWithMask_PointDebugCallback(t *testing.T) {
+	g := makeTestGridStrict(2, 8)
+	g.Params.DebugRingMin, g.Params.DebugRingMax = 1, 1
+	bm := g.Manager
+	var recs []PointDebugRecord
+	bm.PointDebugCallback = func(rec PointDebugRecord) { recs = append(recs, rec) }
+
+	seed := []PointPolar{{Channel: 1, Azimuth: 0, Distance: 10}, {Channel: 2, Azimuth: 0, Distance: 10}}
+	for i := 0; i < 3; i++ {
+		_, _ = bm.ProcessFramePolarWithMask(seed)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected one record per frame for ring 1 only, got %d", len(recs))
+	}
+
+	recs = nil
+	mask, _ := bm.ProcessFramePolarWithMask([]PointPolar{{Channel: 1, Azimuth: 0, Distance: 3}, {Channel: 2, Azimuth: 0, Distance: 3}})
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+	rec := recs[0]
+	if rec.Ring != 1 || rec.RangeM != 3 || !rec.Foreground || rec.Foreground != mask[1] {
+		t.Errorf("unexpected record %+v", rec)
+	}
+	if rec.BackgroundRangeM != 10 || rec.TimesSeen == 0 || rec.ThresholdM <= 0 {
+		t.Errorf("record should carry the pre-update background: %+v", rec)
+	}
+}
@@ -0,0 +1,32 @@
+package l3grid
+
+import "strconv"
+
+// RangeBucket is an acceptance range-bucket boundary in metres.
+//
+// Its JSON form is always a plain number: the shortest decimal that
+// round-trips, never in exponent notation, so integral boundaries are
+// written as integers (4, not 4.0) and fractional ones keep exactly the
+// digits they need (2.5). String returns the same text, which sweep tools
+// use as bucket labels in CSV headers, so a label always matches the value
+// the acceptance API reported.
+type RangeBucket float64
+
+// String formats the boundary as it appears in JSON.
+func (b RangeBucket) String() string {
+	return strconv.FormatFloat(float64(b), 'f', -1, 64)
+}
+
+// MarshalJSON writes the boundary as a plain JSON number.
+func (b RangeBucket) MarshalJSON() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// RangeBuckets converts bucket boundaries for JSON export.
+func RangeBuckets(meters []float64) []RangeBucket {
+	out := make([]RangeBucket, len(meters))
+	for i, m := range meters {
+		out[i] = RangeBucket(m)
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package l3grid
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRangeBucket_JSONRepresentation(t *testing.T) {
+	buckets := RangeBuckets([]float64{1, 2.5, 0.1, 200, 1e-7, 1e21})
+	data, err := json.Marshal(buckets)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	const want = `[1,2.5,0.1,200,0.0000001,1000000000000000000000]`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+
+	var back []float64
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	for i, b := range buckets {
+		if back[i] != float64(b) {
+			t.Errorf("bucket %d round-tripped to %v, want %v", i, back[i], float64(b))
+		}
+		if got, _ := json.Marshal(b); string(got) != b.String() {
+			t.Errorf("String() = %q, JSON = %s", b.String(), got)
+		}
+	}
+}
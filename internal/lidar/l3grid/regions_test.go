@@ -564,3 +564,225 @@ func TestIdentifyRegionsMaxRegionsStrict(t *testing.T) {
 		t.Errorf("Expected at most %d regions, got %d", maxRegions, len(rm.Regions))
 	}
 }
+
+func TestBackgroundManagerRegionAt(t *testing.T) {
+	rings := 3
+	azBins := 4 // 90° per bin
+	grid := makeTestGrid(rings, azBins)
+	bm := grid.Manager
+	rm := grid.RegionMgr
+
+	if got := bm.RegionAt(0, 10); got != -1 {
+		t.Fatalf("RegionAt before identification = %d, want -1", got)
+	}
+
+	// Bin 0 (az 0-90°): rings split 0, 1, 1 → majority region 1.
+	// Bin 1 (az 90-180°): every ring in region 2. Other bins unassigned.
+	for i := range rm.CellToRegionID {
+		rm.CellToRegionID[i] = -1
+	}
+	rm.CellToRegionID[grid.Idx(0, 0)] = 0
+	rm.CellToRegionID[grid.Idx(1, 0)] = 1
+	rm.CellToRegionID[grid.Idx(2, 0)] = 1
+	for ring := 0; ring < rings; ring++ {
+		rm.CellToRegionID[grid.Idx(ring, 1)] = 2
+	}
+	rm.IdentificationComplete = true
+
+	tests := []struct {
+		name string
+		x, y float32
+		want int
+	}{
+		{"ahead, az 45", 5, 5, 1},
+		{"right, az 135", 5, -5, 2},
+		{"behind-left, az 270", -5, 0, -1},
+	}
+	for _, tt := range tests {
+		if got := bm.RegionAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: RegionAt(%v, %v) = %d, want %d", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+// TestRegionWarmup_LowTrafficRegionCompletesFirst drives a static building
+// ring and a busy road ring through the same frames: the building region
+// finishes its per-region warmup while traffic still holds the road back.
+func TestRegionWarmup_LowTrafficRegionCompletesFirst(t *testing.T) {
+	const rings, azBins, warmup = 2, 20, 5
+	bm := NewBackgroundManager("region-warmup", rings, azBins, BackgroundParams{
+		BackgroundUpdateFraction:       0.5,
+		ClosenessSensitivityMultiplier: 2.0,
+		SafetyMarginMetres:             0.1,
+		NeighbourConfirmationCount:     1,
+		NoiseRelativeFraction:          0.01,
+		SeedFromFirstObservation:       true,
+	}, nil)
+	grid := bm.Grid
+
+	frame := func(car bool) []PointPolar {
+		points := make([]PointPolar, 0, rings*azBins)
+		for ring := 0; ring < rings; ring++ {
+			for azBin := 0; azBin < azBins; azBin++ {
+				dist := 10.0 + float64(ring)*5
+				if car && ring == 1 && azBin >= 8 && azBin < 11 {
+					dist = 6.0
+				}
+				points = append(points, PointPolar{
+					Channel:  ring + 1,
+					Azimuth:  (float64(azBin) + 0.5) * 360.0 / azBins,
+					Distance: dist,
+				})
+			}
+		}
+		return points
+	}
+	process := func(car bool) int {
+		mask, err := bm.ProcessFramePolarWithMask(frame(car))
+		if err != nil {
+			t.Fatalf("ProcessFramePolarWithMask: %v", err)
+		}
+		fg := 0
+		for _, m := range mask {
+			if m {
+				fg++
+			}
+		}
+		return fg
+	}
+
+	// Learn the background, then split the grid into a building region
+	// (ring 0) and a road region (ring 1) with the same warmup threshold.
+	for i := 0; i < 10; i++ {
+		process(false)
+	}
+	if fg := process(true); fg == 0 {
+		t.Fatal("a passing car should be foreground before region warmup is set up")
+	}
+	for i := 0; i < 10; i++ {
+		process(false)
+	}
+	rm := grid.RegionMgr
+	rm.Regions = nil
+	for ring := 0; ring < rings; ring++ {
+		region := &Region{ID: ring, CellMask: make([]bool, len(grid.Cells)), Params: RegionParams{WarmupMinFrames: warmup}}
+		for azBin := 0; azBin < azBins; azBin++ {
+			idx := grid.Idx(ring, azBin)
+			region.CellMask[idx] = true
+			region.CellList = append(region.CellList, idx)
+			rm.CellToRegionID[idx] = ring
+		}
+		region.CellCount = len(region.CellList)
+		rm.Regions = append(rm.Regions, region)
+	}
+
+	// Traffic on every other frame: the road sees fewer quiet frames.
+	for i := 0; i < warmup; i++ {
+		if fg := process(i%2 == 0); fg != 0 {
+			t.Fatalf("frame %d: %d foreground points emitted from a warming region", i, fg)
+		}
+	}
+	info := bm.GetRegionDebugInfo(false)
+	building, road := info.Regions[0], info.Regions[1]
+	if !building.WarmupComplete || building.WarmupQuietFrames != warmup {
+		t.Errorf("building region: complete=%v quiet=%d, want complete after %d quiet frames",
+			building.WarmupComplete, building.WarmupQuietFrames, warmup)
+	}
+	if road.WarmupComplete || road.WarmupQuietFrames != warmup/2 {
+		t.Errorf("road region: complete=%v quiet=%d, want still warming with %d quiet frames",
+			road.WarmupComplete, road.WarmupQuietFrames, warmup/2)
+	}
+
+	// Once the road catches up, its foreground is trusted again.
+	for i := warmup / 2; i < warmup; i++ {
+		process(false)
+	}
+	if !rm.Regions[1].WarmupComplete() {
+		t.Fatal("road region should complete warmup after its last quiet frame")
+	}
+	if fg := process(true); fg == 0 {
+		t.Error("a passing car should be foreground once the road region has warmed up")
+	}
+}
+
+// TestRegionWarmup_BusyRegionCompletesAtCap keeps a region busy on every
+// frame: it never sees a quiet frame, so only the warmup cap completes it.
+func TestRegionWarmup_BusyRegionCompletesAtCap(t *testing.T) {
+	const warmup = 3
+	rm := NewRegionManager(1, 4)
+	rm.Regions = []*Region{{ID: 0, Params: RegionParams{WarmupMinFrames: warmup}}}
+	pointRegion := []int{0, 0, 0, 0}
+
+	for i := 1; i <= regionWarmupMaxFactor*warmup; i++ {
+		mask := []bool{true, true, false, false}
+		suppressed := rm.applyRegionWarmup(pointRegion, mask)
+		complete := rm.Regions[0].WarmupComplete()
+		if i < regionWarmupMaxFactor*warmup {
+			if complete || suppressed != 2 {
+				t.Fatalf("frame %d: complete=%v suppressed=%d, want still warming with 2 suppressed", i, complete, suppressed)
+			}
+			continue
+		}
+		if !complete || suppressed != 0 {
+			t.Fatalf("frame %d: complete=%v suppressed=%d, want complete at the cap", i, complete, suppressed)
+		}
+	}
+	if q := rm.Regions[0].WarmupQuietFrames; q != 0 {
+		t.Errorf("quiet frames = %d, want 0", q)
+	}
+}
+
+func TestAssignRegionParams_RegionWarmupScalesWithVariance(t *testing.T) {
+	rm := NewRegionManager(1, 1)
+	base := BackgroundParams{RegionWarmupMinFrames: 100}
+	stable, variable, volatile := rm.assignRegionParams(0, base), rm.assignRegionParams(1, base), rm.assignRegionParams(2, base)
+	if stable.WarmupMinFrames != 50 || variable.WarmupMinFrames != 100 || volatile.WarmupMinFrames != 200 {
+		t.Errorf("warmup frames = %d/%d/%d, want 50/100/200",
+			stable.WarmupMinFrames, variable.WarmupMinFrames, volatile.WarmupMinFrames)
+	}
+	if p := rm.assignRegionParams(2, BackgroundParams{}); p.WarmupMinFrames != 0 {
+		t.Errorf("warmup frames without a base = %d, want 0 (disabled)", p.WarmupMinFrames)
+	}
+}
+
+func TestWorldRegionLookup(t *testing.T) {
+	grid := makeTestGrid(3, 4) // 90° per azimuth bin
+	rm := grid.RegionMgr
+	for i := range rm.CellToRegionID {
+		rm.CellToRegionID[i] = -1
+	}
+	for ring := 0; ring < 3; ring++ {
+		rm.CellToRegionID[grid.Idx(ring, 0)] = 1 // az 0-90°
+		rm.CellToRegionID[grid.Idx(ring, 1)] = 2 // az 90-180°
+	}
+	rm.IdentificationComplete = true
+
+	// Sensor yawed 90° anticlockwise and placed at (-100, 50), 3 m up.
+	pose := [16]float64{
+		0, -1, 0, -100,
+		1, 0, 0, 50,
+		0, 0, 1, 3,
+		0, 0, 0, 1,
+	}
+	posed := WorldRegionLookup{Manager: grid.Manager, SensorToWorld: &pose}
+	unposed := WorldRegionLookup{Manager: grid.Manager}
+
+	tests := []struct {
+		name      string
+		x, y      float32
+		want, raw int
+	}{
+		// Sensor (5, 5), az 45° → world (-105, 55).
+		{"sensor az 45", -105, 55, 1, -1},
+		// Sensor (5, -5), az 135° → world (-95, 55).
+		{"sensor az 135", -95, 55, 2, -1},
+	}
+	for _, tt := range tests {
+		if got := posed.RegionAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: posed RegionAt(%v, %v) = %d, want %d", tt.name, tt.x, tt.y, got, tt.want)
+		}
+		if got := unposed.RegionAt(tt.x, tt.y); got != tt.raw {
+			t.Errorf("%s: unposed RegionAt(%v, %v) = %d, want %d", tt.name, tt.x, tt.y, got, tt.raw)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package l3grid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoRingElevations is returned by BgSnapshot.RingElevations when the
+// snapshot predates elevation persistence and carries no table.
+var ErrNoRingElevations = errors.New("snapshot has no ring elevation table")
+
+// RingElevations decodes the per-ring elevation table (degrees) stored with
+// the snapshot. It returns ErrNoRingElevations when none was stored and an
+// error when the table does not cover every ring of the snapshot's grid.
+func (s *BgSnapshot) RingElevations() ([]float64, error) {
+	if s == nil || s.RingElevationsJSON == "" {
+		return nil, ErrNoRingElevations
+	}
+	var elevs []float64
+	if err := json.Unmarshal([]byte(s.RingElevationsJSON), &elevs); err != nil {
+		return nil, fmt.Errorf("decode ring elevations: %w", err)
+	}
+	if len(elevs) != s.Rings {
+		return nil, fmt.Errorf("ring elevations length %d does not match rings %d", len(elevs), s.Rings)
+	}
+	return elevs, nil
+}
+
+// observeRingElevations fills the grid's elevation table from the points
+// themselves when none was configured through SetRingElevations, so every
+// snapshot records the elevations its cells were binned with. Each point
+// carries the elevation of its channel; once every ring has been seen the
+// table is complete and later frames skip the check.
+func (bm *BackgroundManager) observeRingElevations(points []PointPolar) {
+	if bm.ringElevationsKnown.Load() {
+		return
+	}
+	g := bm.Grid
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.RingElevations) == g.Rings {
+		bm.ringElevationsKnown.Store(true)
+		return
+	}
+	if len(g.observedElevations) != g.Rings {
+		g.observedElevations = make([]float64, g.Rings)
+		g.observedRings = make([]bool, g.Rings)
+		g.observedRingCount = 0
+	}
+	for _, p := range points {
+		ring := p.Channel - 1
+		if ring < 0 || ring >= g.Rings || g.observedRings[ring] {
+			continue
+		}
+		g.observedElevations[ring] = p.Elevation
+		g.observedRings[ring] = true
+		g.observedRingCount++
+	}
+	if g.observedRingCount < g.Rings {
+		return
+	}
+	g.RingElevations = g.observedElevations
+	g.observedElevations, g.observedRings = nil, nil
+	bm.ringElevationsKnown.Store(true)
+	diagf("[BackgroundManager] Learned ring elevations for sensor %s from %d observed rings", g.SensorID, g.Rings)
+}
+
+// ringElevationsForSnapshotLocked returns the JSON elevation table to store
+// with a snapshot, or "" (with a log line) when the grid has none yet. The
+// caller must hold g.mu.
+func (g *BackgroundGrid) ringElevationsForSnapshotLocked() string {
+	if len(g.RingElevations) != g.Rings {
+		opsf("[BackgroundManager] Snapshot for sensor %s has no ring elevation table (%d of %d rings known)", g.SensorID, g.observedRingCount, g.Rings)
+		return ""
+	}
+	b, err := json.Marshal(g.RingElevations)
+	if err != nil {
+		opsf("[BackgroundManager] Failed to encode ring elevations for sensor %s: %v", g.SensorID, err)
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,83 @@
+package l3grid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBgSnapshotRingElevations(t *testing.T) {
+	snap := &BgSnapshot{Rings: 3, RingElevationsJSON: `[-10,0,10.5]`}
+	got, err := snap.RingElevations()
+	if err != nil {
+		t.Fatalf("RingElevations: %v", err)
+	}
+	if len(got) != 3 || got[0] != -10 || got[2] != 10.5 {
+		t.Errorf("elevations = %v", got)
+	}
+
+	if _, err := (&BgSnapshot{Rings: 3}).RingElevations(); !errors.Is(err, ErrNoRingElevations) {
+		t.Errorf("empty table: err = %v, want ErrNoRingElevations", err)
+	}
+	if _, err := (&BgSnapshot{Rings: 3, RingElevationsJSON: `[]`}).RingElevations(); err == nil {
+		t.Error("expected error when the table does not cover every ring")
+	}
+	if _, err := (&BgSnapshot{Rings: 3, RingElevationsJSON: `{`}).RingElevations(); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
+
+func TestObserveRingElevations_CompletesAcrossFrames(t *testing.T) {
+	store := &captureBgStore{}
+	mgr := NewBackgroundManager("observe-elev", 3, 36, BackgroundParams{}, store)
+
+	mgr.ProcessFramePolar([]PointPolar{
+		{Channel: 1, Azimuth: 5, Elevation: -4, Distance: 10},
+		{Channel: 3, Azimuth: 5, Elevation: 6, Distance: 10},
+	})
+	if err := mgr.Persist(store, "manual"); err != nil {
+		t.Fatalf("Persist: %v", err)
+	}
+	if store.last.RingElevationsJSON != "" {
+		t.Errorf("partial table persisted: %s", store.last.RingElevationsJSON)
+	}
+
+	mgr.ProcessFramePolar([]PointPolar{{Channel: 2, Azimuth: 5, Elevation: 1, Distance: 10}})
+	if err := mgr.Persist(store, "manual"); err != nil {
+		t.Fatalf("Persist: %v", err)
+	}
+	got, err := store.last.RingElevations()
+	if err != nil {
+		t.Fatalf("RingElevations: %v", err)
+	}
+	if got[0] != -4 || got[1] != 1 || got[2] != 6 {
+		t.Errorf("learned elevations = %v, want [-4 1 6]", got)
+	}
+}
+
+func TestObserveRingElevations_ConfiguredTableWins(t *testing.T) {
+	store := &captureBgStore{}
+	mgr := NewBackgroundManager("configured-elev", 2, 36, BackgroundParams{}, store)
+	if err := mgr.SetRingElevations([]float64{-1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	mgr.ProcessFramePolar([]PointPolar{
+		{Channel: 1, Azimuth: 5, Elevation: -9, Distance: 10},
+		{Channel: 2, Azimuth: 5, Elevation: 9, Distance: 10},
+	})
+	if err := mgr.Persist(store, "manual"); err != nil {
+		t.Fatalf("Persist: %v", err)
+	}
+	if store.last.RingElevationsJSON != "[-1,1]" {
+		t.Errorf("stored elevations = %s, want the configured [-1,1]", store.last.RingElevationsJSON)
+	}
+}
+
+// captureBgStore records the last snapshot inserted.
+type captureBgStore struct {
+	last *BgSnapshot
+}
+
+func (c *captureBgStore) InsertBgSnapshot(s *BgSnapshot) (int64, error) {
+	c.last = s
+	return 1, nil
+}
@@ -0,0 +1,220 @@
+package l4perception
+
+import (
+	"math"
+)
+
+// Defaults for BloomFilterConfig. MinIntensity has no default: it is the
+// switch that enables the filter.
+const (
+	DefaultBloomCellSize        = 0.25
+	DefaultBloomMaxExtent       = 1.0
+	DefaultBloomMinPoints       = 5
+	DefaultBloomMinStaticFrames = 10
+	DefaultBloomMaxDrift        = 0.3
+	DefaultBloomHalo            = 0.2
+	DefaultBloomMaxGapFrames    = 2
+)
+
+// BloomFilterConfig configures removal of retro-reflective blooms. Street
+// signs and number plates return at saturated intensity and smear into a
+// tight blob of points that the background model cannot always absorb; the
+// blob is clustered and spawns phantom tracks. A bloom is recognised by
+// three properties together: its points are saturated, it is spatially
+// tight, and it stays put. Brightness and size alone also match a moving
+// car's number plate, so a blob is removed only once it has been seen
+// within MaxDrift of where it first appeared for MinStaticFrames frames.
+type BloomFilterConfig struct {
+	MinIntensity    uint8   // Lowest intensity counted as saturated; zero disables the filter
+	CellSize        float64 // XY grid cell used to group saturated points (metres); zero means DefaultBloomCellSize
+	MaxExtent       float64 // Largest XY extent of a bloom (metres); zero means DefaultBloomMaxExtent
+	MinPoints       int     // Fewest saturated points in a bloom; zero means DefaultBloomMinPoints
+	MinStaticFrames int     // Frames a blob must stay put before it is removed; zero means DefaultBloomMinStaticFrames
+	MaxDrift        float64 // Largest distance from where a blob first appeared to still count as static (metres); zero means DefaultBloomMaxDrift
+	Halo            float64 // Margin around a bloom's box whose points are removed with it, catching its unsaturated fringe (metres); zero means DefaultBloomHalo
+}
+
+// Enabled reports whether bloom removal is configured.
+func (c BloomFilterConfig) Enabled() bool {
+	return c.MinIntensity > 0
+}
+
+func (c BloomFilterConfig) withDefaults() BloomFilterConfig {
+	if c.CellSize <= 0 {
+		c.CellSize = DefaultBloomCellSize
+	}
+	if c.MaxExtent <= 0 {
+		c.MaxExtent = DefaultBloomMaxExtent
+	}
+	if c.MinPoints <= 0 {
+		c.MinPoints = DefaultBloomMinPoints
+	}
+	if c.MinStaticFrames <= 0 {
+		c.MinStaticFrames = DefaultBloomMinStaticFrames
+	}
+	if c.MaxDrift <= 0 {
+		c.MaxDrift = DefaultBloomMaxDrift
+	}
+	if c.Halo <= 0 {
+		c.Halo = DefaultBloomHalo
+	}
+	return c
+}
+
+// bloomSite is a saturated blob followed across frames.
+type bloomSite struct {
+	anchorX, anchorY float64 // where the blob first appeared
+	frames           int     // frames it has been seen near the anchor
+	missed           int     // consecutive frames it has not been seen
+	seen             bool    // matched in the current frame
+}
+
+// bloomBlob is one frame's group of connected saturated points.
+type bloomBlob struct {
+	minX, minY, maxX, maxY float64
+	sumX, sumY             float64
+	n                      int
+}
+
+// BloomFilter removes retro-reflective blooms from successive frames of
+// world points. It keeps the sites of recent saturated blobs between
+// frames, so one filter must see every frame of one sensor in order. It is
+// not safe for concurrent use.
+type BloomFilter struct {
+	cfg   BloomFilterConfig
+	sites []*bloomSite
+}
+
+// NewBloomFilter returns a filter for cfg, or nil when cfg is not enabled.
+func NewBloomFilter(cfg BloomFilterConfig) *BloomFilter {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &BloomFilter{cfg: cfg.withDefaults()}
+}
+
+// Filter returns points without the blooms found in this frame. The input
+// slice is not modified. A nil filter returns points unchanged.
+func (f *BloomFilter) Filter(points []WorldPoint) []WorldPoint {
+	if f == nil {
+		return points
+	}
+	blobs := f.blobs(points)
+
+	for _, s := range f.sites {
+		s.seen = false
+	}
+	var static []bloomBlob
+	for _, b := range blobs {
+		cx, cy := b.sumX/float64(b.n), b.sumY/float64(b.n)
+		site := f.nearestSite(cx, cy)
+		if site == nil {
+			f.sites = append(f.sites, &bloomSite{anchorX: cx, anchorY: cy, frames: 1, seen: true})
+			continue
+		}
+		site.frames++
+		site.missed = 0
+		site.seen = true
+		if site.frames >= f.cfg.MinStaticFrames {
+			static = append(static, b)
+		}
+	}
+
+	// A bloom can flicker below saturation for a frame or two; keep its
+	// site through short gaps.
+	kept := f.sites[:0]
+	for _, s := range f.sites {
+		if !s.seen {
+			s.missed++
+		}
+		if s.missed <= DefaultBloomMaxGapFrames {
+			kept = append(kept, s)
+		}
+	}
+	for i := len(kept); i < len(f.sites); i++ {
+		f.sites[i] = nil
+	}
+	f.sites = kept
+
+	if len(static) == 0 {
+		return points
+	}
+	halo := f.cfg.Halo
+	out := make([]WorldPoint, 0, len(points))
+next:
+	for _, p := range points {
+		for _, b := range static {
+			if p.X >= b.minX-halo && p.X <= b.maxX+halo && p.Y >= b.minY-halo && p.Y <= b.maxY+halo {
+				continue next
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// blobs groups the saturated points into 8-connected grid cells and
+// returns the groups small and dense enough to be a bloom.
+func (f *BloomFilter) blobs(points []WorldPoint) []bloomBlob {
+	type cellKey struct{ x, y int64 }
+	cells := make(map[cellKey][]int)
+	for i, p := range points {
+		if p.Intensity < f.cfg.MinIntensity {
+			continue
+		}
+		k := cellKey{int64(math.Floor(p.X / f.cfg.CellSize)), int64(math.Floor(p.Y / f.cfg.CellSize))}
+		cells[k] = append(cells[k], i)
+	}
+
+	var blobs []bloomBlob
+	visited := make(map[cellKey]bool, len(cells))
+	for start := range cells {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		b := bloomBlob{minX: math.Inf(1), minY: math.Inf(1), maxX: math.Inf(-1), maxY: math.Inf(-1)}
+		queue := []cellKey{start}
+		for len(queue) > 0 {
+			k := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			for _, i := range cells[k] {
+				p := points[i]
+				b.minX, b.maxX = math.Min(b.minX, p.X), math.Max(b.maxX, p.X)
+				b.minY, b.maxY = math.Min(b.minY, p.Y), math.Max(b.maxY, p.Y)
+				b.sumX += p.X
+				b.sumY += p.Y
+				b.n++
+			}
+			for dx := int64(-1); dx <= 1; dx++ {
+				for dy := int64(-1); dy <= 1; dy++ {
+					nk := cellKey{k.x + dx, k.y + dy}
+					if _, ok := cells[nk]; ok && !visited[nk] {
+						visited[nk] = true
+						queue = append(queue, nk)
+					}
+				}
+			}
+		}
+		if b.n >= f.cfg.MinPoints && b.maxX-b.minX <= f.cfg.MaxExtent && b.maxY-b.minY <= f.cfg.MaxExtent {
+			blobs = append(blobs, b)
+		}
+	}
+	return blobs
+}
+
+// nearestSite returns the unmatched site whose anchor is closest to
+// (x, y) within MaxDrift, or nil.
+func (f *BloomFilter) nearestSite(x, y float64) *bloomSite {
+	var best *bloomSite
+	bestDist := f.cfg.MaxDrift
+	for _, s := range f.sites {
+		if s.seen {
+			continue
+		}
+		if d := math.Hypot(x-s.anchorX, y-s.anchorY); d <= bestDist {
+			best, bestDist = s, d
+		}
+	}
+	return best
+}
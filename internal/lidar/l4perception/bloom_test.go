@@ -0,0 +1,100 @@
+package l4perception
+
+import "testing"
+
+// brightBlob returns a 0.4 m square of points at (x, y) at the given
+// intensity, with a 0.1 m fringe of unsaturated returns on the +X side.
+func brightBlob(x, y float64, intensity uint8) []WorldPoint {
+	var points []WorldPoint
+	for dx := 0.0; dx <= 0.4+1e-9; dx += 0.1 {
+		for dy := 0.0; dy <= 0.4+1e-9; dy += 0.2 {
+			points = append(points, WorldPoint{X: x + dx, Y: y + dy, Z: 1, Intensity: intensity})
+		}
+	}
+	return append(points, WorldPoint{X: x + 0.5, Y: y + 0.2, Z: 1, Intensity: 60})
+}
+
+func countNear(points []WorldPoint, x, y, r float64) int {
+	n := 0
+	for _, p := range points {
+		if p.X >= x-r && p.X <= x+r && p.Y >= y-r && p.Y <= y+r {
+			n++
+		}
+	}
+	return n
+}
+
+// TestBloomFilter_RemovesStaticBloomKeepsMovingObject feeds a sign bloom
+// that stays put and a bright number plate driving past at 10 m/s. The
+// bloom, fringe included, is removed once it has been static for
+// MinStaticFrames; the plate is as bright and as tight but never stays
+// put, so it survives every frame.
+func TestBloomFilter_RemovesStaticBloomKeepsMovingObject(t *testing.T) {
+	f := NewBloomFilter(BloomFilterConfig{MinIntensity: 250, MinStaticFrames: 5})
+	// An ordinary dim object parked next to the sign is never touched.
+	parked := boxPoints(20, 21, 0, 1, 0.25)
+
+	for frame := 0; frame < 12; frame++ {
+		plateX := -10 + float64(frame)
+		var points []WorldPoint
+		points = append(points, brightBlob(10, 5, 255)...)
+		points = append(points, brightBlob(plateX, -3, 255)...)
+		points = append(points, parked...)
+
+		out := f.Filter(points)
+
+		bloom := countNear(out, 10.25, 5.2, 0.5)
+		if frame < 4 && bloom != 16 {
+			t.Errorf("frame %d: %d bloom points kept before it was confirmed static, want 16", frame, bloom)
+		}
+		if frame >= 4 && bloom != 0 {
+			t.Errorf("frame %d: %d bloom points kept, want the bloom removed", frame, bloom)
+		}
+		if plate := countNear(out, plateX+0.25, -2.8, 0.5); plate != 16 {
+			t.Errorf("frame %d: %d of the moving plate's 16 points kept", frame, plate)
+		}
+		if n := countNear(out, 20.5, 0.5, 0.6); n != len(parked) {
+			t.Errorf("frame %d: %d of %d parked points kept", frame, n, len(parked))
+		}
+	}
+}
+
+func TestBloomFilter_IgnoresLargeOrDimBlobs(t *testing.T) {
+	f := NewBloomFilter(BloomFilterConfig{MinIntensity: 250, MinStaticFrames: 2})
+	// A saturated lorry side 3 m long is too large to be a bloom, and a
+	// dim blob is not saturated.
+	wide := boxPoints(0, 3, 0, 0.3, 0.1)
+	for i := range wide {
+		wide[i].Intensity = 255
+	}
+	frame := append(wide, brightBlob(10, 0, 200)...)
+	for i := 0; i < 5; i++ {
+		if out := f.Filter(frame); len(out) != len(frame) {
+			t.Fatalf("frame %d: kept %d of %d points", i, len(out), len(frame))
+		}
+	}
+}
+
+// TestBloomFilter_ToleratesFlicker checks a bloom that drops below
+// saturation for a frame keeps its site and stays removed.
+func TestBloomFilter_ToleratesFlicker(t *testing.T) {
+	f := NewBloomFilter(BloomFilterConfig{MinIntensity: 250, MinStaticFrames: 3})
+	for i := 0; i < 3; i++ {
+		f.Filter(brightBlob(0, 0, 255))
+	}
+	f.Filter(brightBlob(0, 0, 240)) // below saturation this frame
+	if out := f.Filter(brightBlob(0, 0, 255)); len(out) != 0 {
+		t.Errorf("after a one-frame gap: kept %d points, want the bloom removed", len(out))
+	}
+}
+
+func TestBloomFilter_Disabled(t *testing.T) {
+	f := NewBloomFilter(BloomFilterConfig{})
+	if f != nil {
+		t.Fatal("zero config should disable the filter")
+	}
+	points := brightBlob(0, 0, 255)
+	if out := f.Filter(points); len(out) != len(points) {
+		t.Errorf("nil filter kept %d of %d points", len(out), len(points))
+	}
+}
@@ -52,12 +52,13 @@ func TransformToWorld(polarPoints []PointPolar, pose *Pose, sensorID string) []W
 		worldX, worldY, worldZ := ApplyPose(sensorX, sensorY, sensorZ, T)
 
 		worldPoints[i] = WorldPoint{
-			X:         worldX,
-			Y:         worldY,
-			Z:         worldZ,
-			Intensity: p.Intensity,
-			Timestamp: time.Unix(0, p.Timestamp),
-			SensorID:  sensorID,
+			X:           worldX,
+			Y:           worldY,
+			Z:           worldZ,
+			Intensity:   p.Intensity,
+			ReturnIndex: p.ReturnIndex,
+			Timestamp:   time.Unix(0, p.Timestamp),
+			SensorID:    sensorID,
 		}
 	}
 
@@ -83,12 +84,13 @@ func TransformPointsToWorld(points []Point, pose *Pose) []WorldPoint {
 		worldX, worldY, worldZ := ApplyPose(p.X, p.Y, p.Z, T)
 
 		worldPoints[i] = WorldPoint{
-			X:         worldX,
-			Y:         worldY,
-			Z:         worldZ,
-			Intensity: p.Intensity,
-			Timestamp: p.Timestamp,
-			SensorID:  "", // Will be set by caller if needed
+			X:           worldX,
+			Y:           worldY,
+			Z:           worldZ,
+			Intensity:   p.Intensity,
+			ReturnIndex: p.ReturnIndex,
+			Timestamp:   p.Timestamp,
+			SensorID:    "", // Will be set by caller if needed
 		}
 	}
 
@@ -166,6 +168,13 @@ func (si *SpatialIndex) getCellID(x, y float64) int64 {
 // RegionQuery returns indices of all points within eps distance of points[idx].
 // Uses 2D (x, y) Euclidean distance for neighborhood queries.
 func (si *SpatialIndex) RegionQuery(points []WorldPoint, idx int, eps float64) []int {
+	return si.RegionQueryWeighted(points, idx, eps, FeatureWeights{})
+}
+
+// RegionQueryWeighted is RegionQuery with the feature-weighted distance
+// described on FeatureWeights. Weighted terms only add to the XY distance,
+// so the 3x3 cell search still finds every neighbour.
+func (si *SpatialIndex) RegionQueryWeighted(points []WorldPoint, idx int, eps float64, w FeatureWeights) []int {
 	p := points[idx]
 	neighbors := []int{}
 	eps2 := eps * eps // Use squared distance to avoid sqrt
@@ -207,6 +216,14 @@ func (si *SpatialIndex) RegionQuery(points []WorldPoint, idx int, eps float64) [
 				dx := candidate.X - p.X
 				dy := candidate.Y - p.Y
 				dist2 := dx*dx + dy*dy
+				if w.Height > 0 {
+					dz := w.Height * (candidate.Z - p.Z)
+					dist2 += dz * dz
+				}
+				if w.Intensity > 0 {
+					di := w.Intensity * (float64(candidate.Intensity) - float64(p.Intensity))
+					dist2 += di * di
+				}
 
 				if dist2 <= eps2 {
 					neighbors = append(neighbors, candidateIdx)
@@ -231,6 +248,103 @@ type DBSCANParams struct {
 	// is applied to keep runtime bounded. Zero or negative disables the
 	// cap. Typical value: 8000.
 	MaxInputPoints int
+
+	// MinPtsReferenceRange enables range-adaptive MinPts when > 0. Points
+	// within this horizontal range (metres) of the sensor (SensorX,
+	// SensorY) use MinPts; beyond it the core-point threshold falls with
+	// the square of range, matching the point density of a fixed
+	// angular-resolution sensor, so sparse distant objects are not
+	// discarded as noise. Zero disables adaptation.
+	MinPtsReferenceRange float64
+
+	// MinPtsFloor is the lower bound for adaptive MinPts. Values below 2
+	// are raised to 2 so single points never form clusters.
+	MinPtsFloor int
+
+	// SensorX and SensorY are the sensor's position in the world frame
+	// (metres). Range-adaptive MinPts and the sampling density model
+	// measure range from here; the zero value suits the identity pose,
+	// where the sensor is the world origin.
+	SensorX, SensorY float64
+
+	// FeatureWeights adds height and intensity differences to the
+	// neighbourhood distance. The zero value clusters on XY alone.
+	FeatureWeights FeatureWeights
+
+	// Merge joins clusters that are fragments of one object before the
+	// size filters run. The zero value leaves DBSCAN's clusters as they are.
+	Merge ClusterMerge
+
+	// Density, when enabled, sets PointsCountNormalized on each cluster so
+	// point-count thresholds can be applied independently of range and
+	// voxel downsampling. The zero value leaves it unset.
+	Density SamplingDensity
+
+	// Workers spreads the region-query and core-point phases over this
+	// many goroutines; label propagation stays sequential, so the clusters
+	// match the serial run exactly. 0 or 1 runs serially, which suits
+	// deployments pinned to a single core.
+	Workers int
+}
+
+// FeatureWeights scales non-positional point features into the DBSCAN
+// neighbourhood distance, so objects that touch in XY but differ physically
+// (a pedestrian's torso above a car bumper) fall outside each other's eps:
+//
+//	d² = dx² + dy² + (Height·dz)² + (Intensity·dI)²
+//
+// Each weight converts its feature into metres of equivalent XY distance.
+// Zero weights leave the feature out.
+type FeatureWeights struct {
+	Height    float64 // Metres per metre of Z difference
+	Intensity float64 // Metres per unit of return intensity (0–255)
+}
+
+// MinPtsAtRange returns the core-point threshold for a point at the given
+// horizontal range from the sensor. Without a reference range it is MinPts.
+func (p DBSCANParams) MinPtsAtRange(rangeMetres float64) int {
+	if p.MinPtsReferenceRange <= 0 || rangeMetres <= p.MinPtsReferenceRange {
+		return p.MinPts
+	}
+	floor := p.MinPtsFloor
+	if floor < 2 {
+		floor = 2
+	}
+	ratio := p.MinPtsReferenceRange / rangeMetres
+	minPts := int(math.Ceil(float64(p.MinPts) * ratio * ratio))
+	if minPts < floor {
+		minPts = floor
+	}
+	if minPts > p.MinPts {
+		minPts = p.MinPts
+	}
+	return minPts
+}
+
+// minPtsAt returns the core-point threshold for a world point, measuring
+// range in the XY plane from the sensor.
+func (p DBSCANParams) minPtsAt(pt WorldPoint) int {
+	if p.MinPtsReferenceRange <= 0 {
+		return p.MinPts
+	}
+	return p.MinPtsAtRange(p.sensorRange(pt.X, pt.Y))
+}
+
+// sensorRange returns the horizontal distance of (x, y) from the sensor.
+func (p DBSCANParams) sensorRange(x, y float64) float64 {
+	return math.Hypot(x-p.SensorX, y-p.SensorY)
+}
+
+// WithSensorPose returns p with SensorX and SensorY set to the translation
+// of pose, so range is measured from a sensor that is not at the world
+// origin. A nil pose leaves the sensor at the origin.
+func (p DBSCANParams) WithSensorPose(pose *Pose) DBSCANParams {
+	if pose == nil {
+		p.SensorX, p.SensorY = 0, 0
+		return p
+	}
+	p.SensorX, p.SensorY = pose.T[3], pose.T[7]
+	return p
 }
 
 // DefaultDBSCANParams returns DBSCAN parameters loaded from the canonical
@@ -255,11 +369,24 @@ func DBSCANParamsFromTuning(l4cfg *config.L4DbscanXyV1) DBSCANParams {
 		MaxClusterDiameter:    l4cfg.MaxClusterDiameter,
 		MinClusterDiameter:    l4cfg.MinClusterDiameter,
 		MaxClusterAspectRatio: l4cfg.MaxClusterAspectRatio,
+		MinPtsReferenceRange:  l4cfg.MinPtsReferenceRange,
+		MinPtsFloor:           l4cfg.MinPtsFloor,
+		FeatureWeights: FeatureWeights{
+			Height:    l4cfg.ClusterHeightWeight,
+			Intensity: l4cfg.ClusterIntensityWeight,
+		},
+		Merge: ClusterMerge{
+			Separation: l4cfg.ClusterMergeSeparation,
+			MaxLength:  l4cfg.ClusterMergeMaxLength,
+			MaxWidth:   l4cfg.ClusterMergeMaxWidth,
+		},
+		Workers: l4cfg.ClusterWorkers,
 	}
 }
 
 // DBSCAN performs density-based clustering on world points.
-// Uses 2D (x, y) Euclidean distance. Z is used only for cluster features.
+// Uses 2D (x, y) Euclidean distance, plus any terms enabled by
+// params.FeatureWeights; otherwise Z is used only for cluster features.
 // Returns a slice of WorldCluster objects representing detected clusters.
 //
 // When the input exceeds MaxInputPoints, uniform random subsampling is
@@ -272,8 +399,9 @@ func DBSCAN(points []WorldPoint, params DBSCANParams) []WorldCluster {
 	}
 
 	inputPoints := len(points)
-	tracef("DBSCAN start: points=%d eps=%.3f min_pts=%d max_input_points=%d",
-		inputPoints, params.Eps, params.MinPts, params.MaxInputPoints)
+	tracef("DBSCAN start: points=%d eps=%.3f min_pts=%d min_pts_ref_range=%.1f max_input_points=%d height_weight=%.2f intensity_weight=%.3f",
+		inputPoints, params.Eps, params.MinPts, params.MinPtsReferenceRange, params.MaxInputPoints,
+		params.FeatureWeights.Height, params.FeatureWeights.Intensity)
 
 	// Safety cap: subsample when point count exceeds the threshold to
 	// prevent O(n²) worst-case DBSCAN on unexpectedly dense frames.
@@ -291,20 +419,28 @@ func DBSCAN(points []WorldPoint, params DBSCANParams) []WorldCluster {
 	spatialIndex := NewSpatialIndex(params.Eps)
 	spatialIndex.Build(points)
 
+	query := func(i int) ([]int, bool) {
+		neighbors := spatialIndex.RegionQueryWeighted(points, i, params.Eps, params.FeatureWeights)
+		return neighbors, len(neighbors) >= params.minPtsAt(points[i])
+	}
+	if workers := parallelWorkers(params.Workers, n); workers > 1 {
+		neighbors, core := regionQueriesParallel(points, spatialIndex, params, workers)
+		query = func(i int) ([]int, bool) { return neighbors[i], core[i] }
+	}
+
 	for i := 0; i < n; i++ {
 		if labels[i] != 0 {
 			continue // Already processed
 		}
 
-		neighbors := spatialIndex.RegionQuery(points, i, params.Eps)
-
-		if len(neighbors) < params.MinPts {
+		neighbors, core := query(i)
+		if !core {
 			labels[i] = -1 // Mark as noise
 			continue
 		}
 
 		clusterID++
-		expandCluster(points, spatialIndex, labels, i, neighbors, clusterID, params.Eps, params.MinPts)
+		expandCluster(query, labels, i, neighbors, clusterID)
 	}
 
 	clusters := buildClusters(points, labels, clusterID, params)
@@ -352,9 +488,10 @@ func uniformSubsample(points []WorldPoint, n int) []WorldPoint {
 	return result
 }
 
-// expandCluster expands a cluster from a core point.
-func expandCluster(points []WorldPoint, si *SpatialIndex, labels []int,
-	seedIdx int, neighbors []int, clusterID int, eps float64, minPts int) {
+// expandCluster expands a cluster from a core point. query returns a
+// point's neighbours and whether it is a core point.
+func expandCluster(query func(int) ([]int, bool), labels []int,
+	seedIdx int, neighbors []int, clusterID int) {
 
 	labels[seedIdx] = clusterID
 
@@ -371,9 +508,9 @@ func expandCluster(points []WorldPoint, si *SpatialIndex, labels []int,
 		}
 
 		labels[idx] = clusterID
-		newNeighbors := si.RegionQuery(points, idx, eps)
+		newNeighbors, core := query(idx)
 
-		if len(newNeighbors) >= minPts {
+		if core {
 			// Core point - add its neighbors to the queue
 			neighbors = append(neighbors, newNeighbors...)
 		}
@@ -391,14 +528,21 @@ func buildClusters(points []WorldPoint, labels []int, maxClusterID int, params D
 			buckets[label] = append(buckets[label], points[i])
 		}
 	}
+	if params.Merge.Enabled() {
+		buckets = mergeFragments(buckets, params.Merge)
+	}
 
 	clusters := make([]WorldCluster, 0, maxClusterID)
 	for cid := 1; cid <= maxClusterID; cid++ {
 		clusterPoints := buckets[cid]
-		if len(clusterPoints) == 0 {
+		if len(clusterPoints) == 0 || !hasCurrentPoint(clusterPoints) {
 			continue
 		}
 		cluster := computeClusterMetrics(clusterPoints, int64(cid))
+		if params.Density.Enabled() {
+			r := params.sensorRange(float64(cluster.CentroidX), float64(cluster.CentroidY))
+			cluster.PointsCountNormalized = params.Density.NormalizedCount(cluster.PointsCount, r)
+		}
 
 		// Reject extreme-size and extreme-aspect clusters to filter out
 		// environmental artefacts (walls, hedges, speckle noise).
@@ -428,19 +572,45 @@ func buildClusters(points []WorldPoint, labels []int, maxClusterID int, params D
 	return clusters
 }
 
+// hasCurrentPoint reports whether any point was observed in the current
+// frame. A cluster made only of points carried over by slow-mover
+// accumulation has no evidence the object is still there.
+func hasCurrentPoint(points []WorldPoint) bool {
+	for _, p := range points {
+		if !p.Accumulated {
+			return true
+		}
+	}
+	return false
+}
+
 // computeClusterMetrics computes metrics for a cluster of world points.
 func computeClusterMetrics(points []WorldPoint, clusterID int64) WorldCluster {
-	n := float64(len(points))
-
 	// Compute centroid as medoid: the actual cluster point closest to the
 	// arithmetic mean (task 3.2). For non-convex clusters (L-shapes, arcs)
 	// the arithmetic mean can fall outside the point cloud, causing unstable
 	// association. The medoid is guaranteed to lie on a real measurement.
-	var sumX, sumY, sumZ float64
+	// Points carried from earlier frames by slow-mover accumulation are
+	// left out, so the centroid the tracker sees is where the object is
+	// now rather than where it has been.
+	var sumX, sumY, sumZ, n float64
 	for _, p := range points {
+		if p.Accumulated {
+			continue
+		}
 		sumX += p.X
 		sumY += p.Y
 		sumZ += p.Z
+		n++
+	}
+	allAccumulated := n == 0
+	if allAccumulated {
+		for _, p := range points {
+			sumX += p.X
+			sumY += p.Y
+			sumZ += p.Z
+		}
+		n = float64(len(points))
 	}
 	meanX := sumX / n
 	meanY := sumY / n
@@ -449,6 +619,9 @@ func computeClusterMetrics(points []WorldPoint, clusterID int64) WorldCluster {
 	bestIdx := 0
 	bestDist := math.MaxFloat64
 	for i, p := range points {
+		if p.Accumulated && !allAccumulated {
+			continue
+		}
 		dx := p.X - meanX
 		dy := p.Y - meanY
 		dz := p.Z - meanZ
@@ -529,7 +702,7 @@ func computeClusterMetrics(points []WorldPoint, clusterID int64) WorldCluster {
 		BoundingBoxHeight: bboxHeight,
 		PointsCount:       len(points),
 		HeightP95:         float32(heights[p95Idx]),
-		IntensityMean:     float32(sumIntensity / uint64(len(points))),
+		IntensityMean:     float32(float64(sumIntensity) / float64(len(points))),
 		OBB:               &obb,
 	}
 }
@@ -0,0 +1,124 @@
+package l4perception
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultClusterIdentityMargin is the default slack added to a cluster's
+// footprint when matching it to the previous frame.
+const DefaultClusterIdentityMargin = 0.5
+
+// ClusterIdentityConfig configures stable cluster IDs. DBSCAN numbers its
+// clusters afresh every frame, so ClusterID says nothing about which
+// cluster in the last frame is the same object. With identities enabled
+// each cluster also gets a StableID carried over from the previous frame's
+// cluster it overlaps. It is much lighter than tracking (no motion model,
+// no confirmation, one frame of memory) and is meant for the visualiser
+// and debugging, not as a substitute for track IDs.
+type ClusterIdentityConfig struct {
+	Enabled bool    // Assign StableID; the zero value leaves it unset
+	Margin  float64 // Slack added to the footprint radius when matching (metres); zero means DefaultClusterIdentityMargin
+}
+
+func (c ClusterIdentityConfig) withDefaults() ClusterIdentityConfig {
+	if c.Margin <= 0 {
+		c.Margin = DefaultClusterIdentityMargin
+	}
+	return c
+}
+
+// clusterFootprint is the part of a cluster kept for the next frame.
+type clusterFootprint struct {
+	x, y   float64
+	radius float64
+	id     int64
+}
+
+func footprintOf(c WorldCluster) clusterFootprint {
+	return clusterFootprint{
+		x:      float64(c.CentroidX),
+		y:      float64(c.CentroidY),
+		radius: math.Max(float64(c.BoundingBoxLength), float64(c.BoundingBoxWidth)) / 2,
+		id:     c.StableID,
+	}
+}
+
+// ClusterIdentifier assigns stable IDs to each frame's clusters. A cluster
+// and one in the previous frame overlap when either centroid lies within
+// the other's footprint: half the larger box side, plus Margin. Overlapping
+// pairs are matched one to one, closest centroids first, and a matched
+// cluster inherits the earlier ID. When an object splits, the fragment
+// nearest the old centroid keeps the ID and the rest get new ones; when
+// objects merge, the merged cluster keeps the nearest ID and the others
+// lapse. IDs are never reused. One identifier must see every clustered
+// frame of one sensor in order. It is not safe for concurrent use.
+type ClusterIdentifier struct {
+	cfg    ClusterIdentityConfig
+	prev   []clusterFootprint
+	lastID int64
+}
+
+// NewClusterIdentifier returns an identifier for cfg, or nil when cfg is
+// not enabled.
+func NewClusterIdentifier(cfg ClusterIdentityConfig) *ClusterIdentifier {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &ClusterIdentifier{cfg: cfg.withDefaults()}
+}
+
+// Assign sets StableID on each of clusters, in place, and remembers them
+// for the next frame. A frame without clusters forgets the previous ones,
+// so an object that reappears later gets a new ID. A nil identifier does
+// nothing.
+func (ci *ClusterIdentifier) Assign(clusters []WorldCluster) {
+	if ci == nil {
+		return
+	}
+
+	type pair struct {
+		prev, cur int
+		dist      float64
+	}
+	var pairs []pair
+	for j := range clusters {
+		cur := footprintOf(clusters[j])
+		for i, prev := range ci.prev {
+			dist := math.Hypot(cur.x-prev.x, cur.y-prev.y)
+			if dist <= math.Max(cur.radius, prev.radius)+ci.cfg.Margin {
+				pairs = append(pairs, pair{prev: i, cur: j, dist: dist})
+			}
+		}
+	}
+	sort.Slice(pairs, func(a, b int) bool {
+		if pairs[a].dist != pairs[b].dist {
+			return pairs[a].dist < pairs[b].dist
+		}
+		if pairs[a].cur != pairs[b].cur {
+			return pairs[a].cur < pairs[b].cur
+		}
+		return pairs[a].prev < pairs[b].prev
+	})
+
+	for j := range clusters {
+		clusters[j].StableID = 0
+	}
+	prevUsed := make([]bool, len(ci.prev))
+	for _, p := range pairs {
+		if prevUsed[p.prev] || clusters[p.cur].StableID != 0 {
+			continue
+		}
+		prevUsed[p.prev] = true
+		clusters[p.cur].StableID = ci.prev[p.prev].id
+	}
+
+	ci.prev = ci.prev[:0]
+	for j := range clusters {
+		if clusters[j].StableID == 0 {
+			ci.lastID++
+			clusters[j].StableID = ci.lastID
+		}
+		ci.prev = append(ci.prev, footprintOf(clusters[j]))
+	}
+}
@@ -0,0 +1,114 @@
+package l4perception
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// blob returns n points scattered over a w × l box centred on (x, y).
+func blob(x, y, l, w float64, n int, rng *rand.Rand) []WorldPoint {
+	points := make([]WorldPoint, n)
+	for i := range points {
+		points[i] = WorldPoint{X: x + l*(rng.Float64()-0.5), Y: y + w*(rng.Float64()-0.5), Z: 0.5 + rng.Float64()}
+	}
+	return points
+}
+
+// clusterNear returns the cluster whose centroid is within r of (x, y).
+func clusterNear(t *testing.T, clusters []WorldCluster, x, y, r float32) WorldCluster {
+	t.Helper()
+	for _, c := range clusters {
+		if c.CentroidX >= x-r && c.CentroidX <= x+r && c.CentroidY >= y-r && c.CentroidY <= y+r {
+			return c
+		}
+	}
+	t.Fatalf("no cluster near (%.1f, %.1f) in %d clusters", x, y, len(clusters))
+	return WorldCluster{}
+}
+
+func TestClusterIdentifier_MovingClusterKeepsID(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	params := DefaultDBSCANParams()
+	ids := NewClusterIdentifier(ClusterIdentityConfig{Enabled: true})
+
+	// A car crosses at 1.2 m per frame; a pedestrian steps out at frame 5.
+	var carID, walkerID int64
+	for frame := 0; frame < 10; frame++ {
+		carX := float32(-5 + 1.2*float64(frame))
+		points := blob(float64(carX), 4, 4.5, 1.8, 120, rng)
+		if frame >= 5 {
+			points = append(points, blob(2, -4, 0.5, 0.5, 25, rng)...)
+		}
+		clusters := DBSCAN(points, params)
+		ids.Assign(clusters)
+
+		car := clusterNear(t, clusters, carX, 4, 1)
+		if car.StableID == 0 {
+			t.Fatalf("frame %d: car has no stable ID", frame)
+		}
+		if frame == 0 {
+			carID = car.StableID
+		} else if car.StableID != carID {
+			t.Fatalf("frame %d: car ID %d, want %d", frame, car.StableID, carID)
+		}
+		if frame < 5 {
+			continue
+		}
+		walker := clusterNear(t, clusters, 2, -4, 1)
+		if frame == 5 {
+			if walker.StableID == carID || walker.StableID == 0 {
+				t.Fatalf("new pedestrian got ID %d, want a fresh one", walker.StableID)
+			}
+			walkerID = walker.StableID
+		} else if walker.StableID != walkerID {
+			t.Fatalf("frame %d: pedestrian ID %d, want %d", frame, walker.StableID, walkerID)
+		}
+	}
+}
+
+func TestClusterIdentifier_SplitAndMerge(t *testing.T) {
+	ids := NewClusterIdentifier(ClusterIdentityConfig{Enabled: true})
+	box := func(x float32, l float32) WorldCluster {
+		return WorldCluster{CentroidX: x, BoundingBoxLength: l, BoundingBoxWidth: 1}
+	}
+
+	frame := []WorldCluster{box(0, 8)}
+	ids.Assign(frame)
+	lorry := frame[0].StableID
+
+	// The lorry splits into cab and trailer: the nearer fragment keeps
+	// the ID, the other gets a fresh one.
+	frame = []WorldCluster{box(-2.5, 3), box(1.5, 5)}
+	ids.Assign(frame)
+	if frame[1].StableID != lorry || frame[0].StableID == lorry || frame[0].StableID == 0 {
+		t.Fatalf("split IDs = %d, %d; want a new ID and %d", frame[0].StableID, frame[1].StableID, lorry)
+	}
+	cab := frame[0].StableID
+
+	// They merge again: one ID survives and the other lapses.
+	frame = []WorldCluster{box(0.2, 8)}
+	ids.Assign(frame)
+	if frame[0].StableID != lorry {
+		t.Errorf("merged ID = %d, want %d", frame[0].StableID, lorry)
+	}
+
+	// After an empty frame nothing carries over.
+	ids.Assign(nil)
+	frame = []WorldCluster{box(0.2, 8)}
+	ids.Assign(frame)
+	if id := frame[0].StableID; id == lorry || id == cab || id == 0 {
+		t.Errorf("ID after an empty frame = %d, want a fresh one", id)
+	}
+}
+
+func TestClusterIdentifier_Disabled(t *testing.T) {
+	ids := NewClusterIdentifier(ClusterIdentityConfig{})
+	if ids != nil {
+		t.Fatal("identifier created for a disabled config")
+	}
+	clusters := []WorldCluster{{CentroidX: 1}}
+	ids.Assign(clusters)
+	if clusters[0].StableID != 0 {
+		t.Errorf("nil identifier set StableID %d", clusters[0].StableID)
+	}
+}
@@ -0,0 +1,139 @@
+package l4perception
+
+import (
+	"math"
+	"sort"
+)
+
+// Defaults for ClusterMerge size bounds: a rigid bus or lorry.
+const (
+	DefaultMergeMaxLength = 12.0
+	DefaultMergeMaxWidth  = 3.0
+)
+
+// ClusterMerge joins DBSCAN clusters that are fragments of one object. A
+// long vehicle with a sparse middle, or a lorry with a gap between cab and
+// trailer, can split into head and tail clusters even with a well-tuned
+// eps. Clusters whose centroids lie within Separation are merged, closest
+// pairs first, as long as the merged cluster's oriented box stays within
+// MaxLength × MaxWidth. It is the merging counterpart to FeatureWeights,
+// which separates touching objects.
+type ClusterMerge struct {
+	Separation float64 // Largest centroid distance to merge (metres); zero disables merging
+	MaxLength  float64 // Largest merged box length (metres); zero means DefaultMergeMaxLength
+	MaxWidth   float64 // Largest merged box width (metres); zero means DefaultMergeMaxWidth
+}
+
+// Enabled reports whether merging is configured.
+func (m ClusterMerge) Enabled() bool {
+	return m.Separation > 0
+}
+
+func (m ClusterMerge) withDefaults() ClusterMerge {
+	if m.MaxLength <= 0 {
+		m.MaxLength = DefaultMergeMaxLength
+	}
+	if m.MaxWidth <= 0 {
+		m.MaxWidth = DefaultMergeMaxWidth
+	}
+	return m
+}
+
+// mergeFragments merges the point buckets of clusters that are fragments
+// of one object and returns the resulting buckets; merged-away buckets are
+// left empty. Candidate pairs come from a 2D k-d tree over the cluster
+// mean positions, so only clusters within Separation of each other are
+// compared. Pairs are measured between the original fragments, and each is
+// accepted only if the union of the two groups fits the size bound.
+func mergeFragments(buckets [][]WorldPoint, merge ClusterMerge) [][]WorldPoint {
+	merge = merge.withDefaults()
+
+	// Mean position of each non-empty bucket, and its bucket index.
+	var centroids []WorldPoint
+	var owner []int
+	for i, bucket := range buckets {
+		if len(bucket) == 0 {
+			continue
+		}
+		var sumX, sumY float64
+		for _, p := range bucket {
+			sumX += p.X
+			sumY += p.Y
+		}
+		n := float64(len(bucket))
+		centroids = append(centroids, WorldPoint{X: sumX / n, Y: sumY / n})
+		owner = append(owner, i)
+	}
+	if len(centroids) < 2 {
+		return buckets
+	}
+
+	type pair struct {
+		a, b int // bucket indices
+		dist float64
+	}
+	var pairs []pair
+	tree := NewKDTree(centroids, 2)
+	var near []int
+	for i := range centroids {
+		near = tree.RadiusQueryPoint(i, merge.Separation, near[:0])
+		for _, j := range near {
+			if j <= i {
+				continue
+			}
+			dist := math.Hypot(centroids[j].X-centroids[i].X, centroids[j].Y-centroids[i].Y)
+			pairs = append(pairs, pair{a: owner[i], b: owner[j], dist: dist})
+		}
+	}
+	if len(pairs) == 0 {
+		return buckets
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].dist != pairs[j].dist {
+			return pairs[i].dist < pairs[j].dist
+		}
+		if pairs[i].a != pairs[j].a {
+			return pairs[i].a < pairs[j].a
+		}
+		return pairs[i].b < pairs[j].b
+	})
+
+	// Union-find over bucket indices; each root's bucket holds the group.
+	parent := make([]int, len(buckets))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	merged := 0
+	for _, p := range pairs {
+		ra, rb := find(p.a), find(p.b)
+		if ra == rb {
+			continue
+		}
+		union := make([]WorldPoint, 0, len(buckets[ra])+len(buckets[rb]))
+		union = append(append(union, buckets[ra]...), buckets[rb]...)
+		obb := EstimateOBBFromCluster(union)
+		longest := math.Max(float64(obb.Length), float64(obb.Width))
+		shortest := math.Min(float64(obb.Length), float64(obb.Width))
+		if longest > merge.MaxLength || shortest > merge.MaxWidth {
+			continue
+		}
+		if rb < ra {
+			ra, rb = rb, ra
+		}
+		parent[rb] = ra
+		buckets[ra], buckets[rb] = union, nil
+		merged++
+	}
+	if merged > 0 {
+		tracef("DBSCAN fragment merge: candidate_pairs=%d merged=%d separation=%.2f", len(pairs), merged, merge.Separation)
+	}
+	return buckets
+}
@@ -0,0 +1,90 @@
+package l4perception
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// boxPoints fills an axis-aligned footprint [x0, x1] × [y0, y1] with points
+// on a regular grid at the given spacing, at heights 0.5 and 1.5 m.
+func boxPoints(x0, x1, y0, y1, spacing float64) []WorldPoint {
+	var points []WorldPoint
+	for x := x0; x <= x1+1e-9; x += spacing {
+		for y := y0; y <= y1+1e-9; y += spacing {
+			points = append(points, WorldPoint{X: x, Y: y, Z: 0.5}, WorldPoint{X: x, Y: y, Z: 1.5})
+		}
+	}
+	return points
+}
+
+func TestDBSCAN_MergeFragmentedLongVehicle(t *testing.T) {
+	// An 11 m bus along x whose middle returned nothing: two 4.5 m
+	// fragments with a 2 m gap, wider than eps. Their centroids are 6.5 m
+	// apart.
+	bus := append(boxPoints(0, 4.5, 0, 2.5, 0.25), boxPoints(6.5, 11, 0, 2.5, 0.25)...)
+	params := testDBSCANParams(0.6, 4)
+
+	if clusters := DBSCAN(bus, params); len(clusters) != 2 {
+		t.Fatalf("without merging: got %d clusters, want the bus in 2 fragments", len(clusters))
+	}
+
+	params.Merge = ClusterMerge{Separation: 7}
+	clusters := DBSCAN(bus, params)
+	if len(clusters) != 1 {
+		t.Fatalf("with merging: got %d clusters, want 1", len(clusters))
+	}
+	c := clusters[0]
+	if c.PointsCount != len(bus) {
+		t.Errorf("PointsCount = %d, want all %d points", c.PointsCount, len(bus))
+	}
+	length := math.Max(float64(c.BoundingBoxLength), float64(c.BoundingBoxWidth))
+	if math.Abs(length-11) > 0.1 {
+		t.Errorf("merged length = %.2f, want about 11 m", length)
+	}
+
+	// A length bound below the joined extent keeps the fragments apart.
+	params.Merge.MaxLength = 10
+	if clusters := DBSCAN(bus, params); len(clusters) != 2 {
+		t.Errorf("MaxLength 10: got %d clusters, want the fragments left apart", len(clusters))
+	}
+}
+
+func TestDBSCAN_MergeRespectsWidthBound(t *testing.T) {
+	// Two cars side by side in adjacent lanes, 1.8 m wide with a 1.2 m
+	// gap: close enough to merge on separation, but 4.8 m wide together.
+	cars := append(boxPoints(0, 4.5, 0, 1.8, 0.25), boxPoints(0, 4.5, 3, 4.8, 0.25)...)
+	params := testDBSCANParams(0.6, 4)
+	params.Merge = ClusterMerge{Separation: 5}
+
+	if clusters := DBSCAN(cars, params); len(clusters) != 2 {
+		t.Errorf("got %d clusters, want the two cars kept apart by the width bound", len(clusters))
+	}
+}
+
+func TestMergeFragments_ClosestPairsFirst(t *testing.T) {
+	// Three 2 m fragments in a row with gaps of 0.5 and 1 m. The first two
+	// merge first; the third would take the group past MaxLength.
+	buckets := [][]WorldPoint{
+		nil,
+		boxPoints(0, 2, 0, 1, 0.25),
+		boxPoints(2.5, 4.5, 0, 1, 0.25),
+		boxPoints(5.5, 7.5, 0, 1, 0.25),
+	}
+	sizes := []int{0, len(buckets[1]), len(buckets[2]), len(buckets[3])}
+
+	out := mergeFragments(buckets, ClusterMerge{Separation: 3, MaxLength: 5})
+	if len(out[1]) != sizes[1]+sizes[2] || len(out[2]) != 0 || len(out[3]) != sizes[3] {
+		t.Errorf("bucket sizes = %d, %d, %d; want first two merged into bucket 1",
+			len(out[1]), len(out[2]), len(out[3]))
+	}
+}
+
+func TestDBSCANClusterer_Merge(t *testing.T) {
+	bus := append(boxPoints(0, 4.5, 0, 2.5, 0.25), boxPoints(6.5, 11, 0, 2.5, 0.25)...)
+	clusterer := NewDBSCANClusterer(0.6, 4)
+	clusterer.SetParams(ClusteringParams{Eps: 0.6, MinPts: 4, Merge: ClusterMerge{Separation: 7}})
+	if got := clusterer.Cluster(bus, "test", time.Now()); len(got) != 1 {
+		t.Errorf("got %d clusters, want the merged bus", len(got))
+	}
+}
@@ -409,6 +409,21 @@ func TestDefaultDBSCANParams(t *testing.T) {
 	if params.MinPts != cfg.GetForegroundMinClusterPoints() {
 		t.Errorf("expected MinPts=%d, got %d", cfg.GetForegroundMinClusterPoints(), params.MinPts)
 	}
+	if params.MinPtsReferenceRange != cfg.GetMinPtsReferenceRange() || params.MinPtsFloor != cfg.GetMinPtsFloor() {
+		t.Errorf("expected adaptive MinPts %v/%d, got %v/%d",
+			cfg.GetMinPtsReferenceRange(), cfg.GetMinPtsFloor(), params.MinPtsReferenceRange, params.MinPtsFloor)
+	}
+	wantMerge := ClusterMerge{
+		Separation: cfg.GetClusterMergeSeparation(),
+		MaxLength:  cfg.GetClusterMergeMaxLength(),
+		MaxWidth:   cfg.GetClusterMergeMaxWidth(),
+	}
+	if params.Merge != wantMerge {
+		t.Errorf("expected Merge=%+v, got %+v", wantMerge, params.Merge)
+	}
+	if params.Workers != cfg.GetClusterWorkers() {
+		t.Errorf("expected Workers=%d, got %d", cfg.GetClusterWorkers(), params.Workers)
+	}
 }
 
 func TestDBSCANParamsFromTuning_NilConfig(t *testing.T) {
@@ -417,3 +432,161 @@ func TestDBSCANParamsFromTuning_NilConfig(t *testing.T) {
 		t.Fatalf("expected zero-value params, got %+v", params)
 	}
 }
+
+func TestDBSCANParams_MinPtsAtRange(t *testing.T) {
+	params := DBSCANParams{MinPts: 8, MinPtsReferenceRange: 10, MinPtsFloor: 3}
+
+	cases := []struct {
+		rangeM float64
+		want   int
+	}{
+		{0, 8},
+		{10, 8},
+		{14.2, 4}, // 8 * (10/14.2)^2 ≈ 3.97 → ceil 4
+		{20, 3},   // 8 * 0.25 = 2 → floor 3
+		{100, 3},
+	}
+	for _, tc := range cases {
+		if got := params.MinPtsAtRange(tc.rangeM); got != tc.want {
+			t.Errorf("MinPtsAtRange(%.1f) = %d, want %d", tc.rangeM, got, tc.want)
+		}
+	}
+
+	fixed := DBSCANParams{MinPts: 8}
+	if got := fixed.MinPtsAtRange(100); got != 8 {
+		t.Errorf("fixed MinPtsAtRange(100) = %d, want 8", got)
+	}
+
+	// Floor below 2 is raised so isolated points never form clusters.
+	lowFloor := DBSCANParams{MinPts: 5, MinPtsReferenceRange: 1}
+	if got := lowFloor.MinPtsAtRange(1000); got != 2 {
+		t.Errorf("low floor MinPtsAtRange(1000) = %d, want 2", got)
+	}
+}
+
+func TestDBSCAN_AdaptiveMinPtsKeepsDistantSparseCluster(t *testing.T) {
+	// A dense near object and a sparse object at 40 m, as a fixed
+	// angular-resolution sensor would see them.
+	var points []WorldPoint
+	for i := 0; i < 6; i++ {
+		points = append(points, WorldPoint{X: 5 + float64(i)*0.1, Y: 0, Z: 0.5})
+	}
+	points = append(points,
+		WorldPoint{X: 40.0, Y: 0, Z: 0.5},
+		WorldPoint{X: 40.3, Y: 0, Z: 0.5},
+		WorldPoint{X: 40.6, Y: 0, Z: 0.5},
+	)
+
+	fixed := DefaultDBSCANParams()
+	fixed.Eps = 0.8
+	fixed.MinPts = 5
+	clusters := DBSCAN(points, fixed)
+	if len(clusters) != 1 {
+		t.Fatalf("fixed MinPts: expected 1 cluster (near object only), got %d", len(clusters))
+	}
+	if clusters[0].CentroidX > 10 {
+		t.Errorf("fixed MinPts: expected the near cluster, got centroid X=%.2f", clusters[0].CentroidX)
+	}
+
+	adaptive := fixed
+	adaptive.MinPtsReferenceRange = 10
+	adaptive.MinPtsFloor = 2
+	clusters = DBSCAN(points, adaptive)
+	if len(clusters) != 2 {
+		t.Fatalf("adaptive MinPts: expected 2 clusters, got %d", len(clusters))
+	}
+	var far *WorldCluster
+	for i := range clusters {
+		if clusters[i].CentroidX > 30 {
+			far = &clusters[i]
+		}
+	}
+	if far == nil {
+		t.Fatal("adaptive MinPts: distant sparse cluster was dropped")
+	}
+	if far.PointsCount != 3 {
+		t.Errorf("adaptive MinPts: expected 3 points in distant cluster, got %d", far.PointsCount)
+	}
+
+	// With the sensor beside the sparse object, range is measured from the
+	// sensor: the sparse object is close and needs the full MinPts, while
+	// the dense object is now the distant one.
+	pose := SensorExtrinsics{TranslationM: [3]float64{36, 0, 0}}.Pose("s")
+	offset := adaptive.WithSensorPose(pose)
+	if offset.SensorX != 36 || offset.SensorY != 0 {
+		t.Fatalf("WithSensorPose: sensor at (%.1f, %.1f), want (36, 0)", offset.SensorX, offset.SensorY)
+	}
+	clusters = DBSCAN(points, offset)
+	if len(clusters) != 1 || clusters[0].CentroidX > 10 {
+		t.Fatalf("offset sensor: expected only the near-origin cluster, got %d clusters", len(clusters))
+	}
+}
+
+func TestDBSCAN_HeightWeightingSeparatesTouchingObjects(t *testing.T) {
+	// A low, wide car body and a pedestrian's torso standing at its rear
+	// edge, 0.1 m away in XY. The pedestrian's legs are hidden by the car,
+	// so its returns start 0.2 m above the car's highest.
+	var points []WorldPoint
+	for ix := 0; ix <= 20; ix++ {
+		for iy := 0; iy <= 10; iy++ {
+			for iz := 0; iz < 4; iz++ {
+				points = append(points, WorldPoint{
+					X: float64(ix) * 0.1, Y: float64(iy) * 0.1, Z: 0.3 + float64(iz)*0.1,
+				})
+			}
+		}
+	}
+	carPoints := len(points)
+	for ix := 0; ix < 3; ix++ {
+		for iy := 0; iy < 3; iy++ {
+			for iz := 0; iz <= 10; iz++ {
+				points = append(points, WorldPoint{
+					X: 2.1 + float64(ix)*0.1, Y: 0.4 + float64(iy)*0.1, Z: 0.8 + float64(iz)*0.1,
+				})
+			}
+		}
+	}
+	pedestrianPoints := len(points) - carPoints
+
+	params := testDBSCANParams(0.3, 4)
+	if clusters := DBSCAN(points, params); len(clusters) != 1 {
+		t.Fatalf("XY only: expected the objects to merge into 1 cluster, got %d", len(clusters))
+	}
+
+	params.FeatureWeights = FeatureWeights{Height: 2}
+	clusters := DBSCAN(points, params)
+	if len(clusters) != 2 {
+		t.Fatalf("height weighted: expected 2 clusters, got %d", len(clusters))
+	}
+	counts := map[int]bool{carPoints: false, pedestrianPoints: false}
+	for _, c := range clusters {
+		counts[c.PointsCount] = true
+	}
+	if !counts[carPoints] || !counts[pedestrianPoints] {
+		t.Errorf("height weighted: expected clusters of %d and %d points, got %d and %d",
+			carPoints, pedestrianPoints, clusters[0].PointsCount, clusters[1].PointsCount)
+	}
+}
+
+func TestSpatialIndex_RegionQueryWeighted_Intensity(t *testing.T) {
+	points := []WorldPoint{
+		{X: 0, Y: 0, Intensity: 10},
+		{X: 0.1, Y: 0, Intensity: 12},
+		{X: 0.1, Y: 0.1, Intensity: 200},
+	}
+	si := NewSpatialIndex(0.5)
+	si.Build(points)
+
+	if got := si.RegionQuery(points, 0, 0.5); len(got) != 3 {
+		t.Errorf("unweighted: expected 3 neighbours, got %d", len(got))
+	}
+	got := si.RegionQueryWeighted(points, 0, 0.5, FeatureWeights{Intensity: 0.01})
+	if len(got) != 2 {
+		t.Fatalf("intensity weighted: expected 2 neighbours, got %d", len(got))
+	}
+	for _, idx := range got {
+		if idx == 2 {
+			t.Error("intensity weighted: high-intensity point should not be a neighbour")
+		}
+	}
+}
@@ -24,4 +24,22 @@ type ClustererInterface interface {
 type ClusteringParams struct {
 	Eps    float64 // Neighbourhood radius in metres (for DBSCAN)
 	MinPts int     // Minimum points to form a cluster
+
+	// Range-adaptive MinPts (see DBSCANParams.MinPtsAtRange).
+	// A zero reference range keeps MinPts fixed.
+	MinPtsReferenceRange float64 // Range in metres beyond which MinPts decays
+	MinPtsFloor          int     // Lower bound for adaptive MinPts
+	SensorX, SensorY     float64 // Sensor position in the world frame, for range
+
+	// Height and intensity weighting (see FeatureWeights).
+	FeatureWeights FeatureWeights
+
+	// Post-clustering fragment merging (see ClusterMerge).
+	Merge ClusterMerge
+
+	// Range-normalised point counts (see DBSCANParams.Density).
+	Density SamplingDensity
+
+	// Goroutines for the region-query phase (see DBSCANParams.Workers).
+	Workers int
 }
@@ -41,6 +41,13 @@ func (c *DBSCANClusterer) Cluster(points []WorldPoint, sensorID string, timestam
 	dbscanParams := DefaultDBSCANParams()
 	dbscanParams.Eps = c.params.Eps
 	dbscanParams.MinPts = c.params.MinPts
+	dbscanParams.MinPtsReferenceRange = c.params.MinPtsReferenceRange
+	dbscanParams.MinPtsFloor = c.params.MinPtsFloor
+	dbscanParams.SensorX, dbscanParams.SensorY = c.params.SensorX, c.params.SensorY
+	dbscanParams.FeatureWeights = c.params.FeatureWeights
+	dbscanParams.Merge = c.params.Merge
+	dbscanParams.Density = c.params.Density
+	dbscanParams.Workers = c.params.Workers
 
 	// Run DBSCAN clustering
 	clusters := DBSCAN(points, dbscanParams)
@@ -175,3 +175,42 @@ func TestDBSCANClusterer_Interface(t *testing.T) {
 	// Compile-time check that DBSCANClusterer implements ClustererInterface
 	var _ ClustererInterface = (*DBSCANClusterer)(nil)
 }
+
+func TestDBSCANClusterer_Cluster_AdaptiveMinPts(t *testing.T) {
+	points := []WorldPoint{
+		{X: 40.0, Y: 0, Z: 0.5},
+		{X: 40.3, Y: 0, Z: 0.5},
+		{X: 40.6, Y: 0, Z: 0.5},
+	}
+
+	clusterer := NewDBSCANClusterer(0.8, 5)
+	if got := clusterer.Cluster(points, "test-sensor", time.Now()); len(got) != 0 {
+		t.Fatalf("fixed MinPts: expected sparse distant points to be noise, got %d clusters", len(got))
+	}
+
+	clusterer.SetParams(ClusteringParams{Eps: 0.8, MinPts: 5, MinPtsReferenceRange: 10, MinPtsFloor: 2})
+	if got := clusterer.Cluster(points, "test-sensor", time.Now()); len(got) != 1 {
+		t.Fatalf("adaptive MinPts: expected 1 cluster, got %d", len(got))
+	}
+}
+
+func TestDBSCANClusterer_Cluster_FeatureWeights(t *testing.T) {
+	// Two stacks touching in XY, separated by 0.5 m in height.
+	var points []WorldPoint
+	for i := 0; i < 5; i++ {
+		points = append(points,
+			WorldPoint{X: float64(i) * 0.1, Y: 0, Z: 0.2},
+			WorldPoint{X: float64(i) * 0.1, Y: 0.1, Z: 0.7},
+		)
+	}
+
+	clusterer := NewDBSCANClusterer(0.3, 3)
+	if got := clusterer.Cluster(points, "test-sensor", time.Now()); len(got) != 1 {
+		t.Fatalf("XY only: expected 1 cluster, got %d", len(got))
+	}
+
+	clusterer.SetParams(ClusteringParams{Eps: 0.3, MinPts: 3, FeatureWeights: FeatureWeights{Height: 1}})
+	if got := clusterer.Cluster(points, "test-sensor", time.Now()); len(got) != 2 {
+		t.Fatalf("height weighted: expected 2 clusters, got %d", len(got))
+	}
+}
@@ -0,0 +1,64 @@
+package l4perception
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// parallelDBSCANMinPoints is the smallest input worth fanning out: below it
+// goroutine start-up costs more than the region queries it spreads.
+const parallelDBSCANMinPoints = 1024
+
+// parallelDBSCANBatch is the number of points a worker queries per claim.
+const parallelDBSCANBatch = 256
+
+// parallelWorkers returns how many goroutines DBSCAN should use for n
+// points given the configured worker count; 1 means run serially.
+func parallelWorkers(workers, n int) int {
+	if workers <= 1 || n < parallelDBSCANMinPoints {
+		return 1
+	}
+	if workers > n {
+		return n
+	}
+	return workers
+}
+
+// regionQueriesParallel runs the region query for every point on workers
+// goroutines and classifies each as core or not. Only core points keep
+// their neighbour lists, since expansion discards a border point's
+// neighbours; each list is capped at its length so expansion's append
+// never writes into a shared backing array. The spatial index and points
+// are only read, so the workers share them without locking.
+func regionQueriesParallel(points []WorldPoint, si *SpatialIndex, params DBSCANParams, workers int) ([][]int, []bool) {
+	n := len(points)
+	neighbors := make([][]int, n)
+	core := make([]bool, n)
+
+	// Workers claim fixed-size batches so dense regions, whose queries
+	// cost more, do not leave one goroutine with all the work.
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				start := int(next.Add(parallelDBSCANBatch)) - parallelDBSCANBatch
+				if start >= n {
+					return
+				}
+				end := min(start+parallelDBSCANBatch, n)
+				for i := start; i < end; i++ {
+					nb := si.RegionQueryWeighted(points, i, params.Eps, params.FeatureWeights)
+					if len(nb) >= params.minPtsAt(points[i]) {
+						neighbors[i] = nb[:len(nb):len(nb)]
+						core[i] = true
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return neighbors, core
+}
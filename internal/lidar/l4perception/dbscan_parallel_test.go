@@ -0,0 +1,80 @@
+package l4perception
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestDBSCAN_ParallelMatchesSerial(t *testing.T) {
+	points := benchmarkFrame()
+	base := DBSCANParams{
+		Eps:                   0.6,
+		MinPts:                8,
+		MaxClusterDiameter:    100,
+		MaxClusterAspectRatio: 1000,
+	}
+	tests := []struct {
+		name   string
+		params func(DBSCANParams) DBSCANParams
+	}{
+		{"xy", func(p DBSCANParams) DBSCANParams { return p }},
+		{"adaptive min pts", func(p DBSCANParams) DBSCANParams {
+			p.MinPtsReferenceRange, p.MinPtsFloor = 10, 3
+			return p
+		}},
+		{"feature weights", func(p DBSCANParams) DBSCANParams {
+			p.FeatureWeights = FeatureWeights{Height: 0.5, Intensity: 0.01}
+			return p
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serial := DBSCAN(points, tt.params(base))
+			if len(serial) == 0 {
+				t.Fatal("serial DBSCAN found no clusters; the test frame is too sparse")
+			}
+			for _, workers := range []int{2, 3, 8} {
+				p := tt.params(base)
+				p.Workers = workers
+				if got := DBSCAN(points, p); !reflect.DeepEqual(got, serial) {
+					t.Errorf("workers=%d: %d clusters differ from the %d serial clusters", workers, len(got), len(serial))
+				}
+			}
+		})
+	}
+}
+
+func TestParallelWorkers(t *testing.T) {
+	tests := []struct {
+		workers, n, want int
+	}{
+		{0, 5000, 1},
+		{1, 5000, 1},
+		{4, 5000, 4},
+		{4, parallelDBSCANMinPoints - 1, 1},
+		{-2, 5000, 1},
+	}
+	for _, tt := range tests {
+		if got := parallelWorkers(tt.workers, tt.n); got != tt.want {
+			t.Errorf("parallelWorkers(%d, %d) = %d, want %d", tt.workers, tt.n, got, tt.want)
+		}
+	}
+}
+
+// BenchmarkDBSCAN_Workers clusters a dense 20k-point frame serially and
+// across workers; the parallel region queries are what cluster_time_ms
+// in pcap-analyse benchmarks measures.
+func BenchmarkDBSCAN_Workers(b *testing.B) {
+	points := benchmarkFrame()
+	params := DBSCANParams{Eps: 0.6, MinPts: 8, MaxClusterDiameter: 100, MaxClusterAspectRatio: 1000}
+	for _, workers := range []int{1, 2, 4, runtime.GOMAXPROCS(0)} {
+		params.Workers = workers
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				DBSCAN(points, params)
+			}
+		})
+	}
+}
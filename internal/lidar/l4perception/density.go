@@ -0,0 +1,61 @@
+package l4perception
+
+import "math"
+
+// SamplingDensity models how densely the foreground cloud samples a surface
+// facing the sensor, so cluster point counts can be compared across range.
+//
+// A fixed angular-resolution sensor puts 1/(r²·Δaz·Δel) points on each
+// square metre of surface at range r. Voxel downsampling keeps at most one
+// point per leaf, capping the density at roughly 1/leaf² per square metre,
+// so nearby objects lose a larger share of their points than distant ones.
+// The effective density is the lower of the two.
+//
+// Range is the horizontal distance of the cluster centroid from the sensor
+// position in DBSCANParams, as for range-adaptive MinPts.
+type SamplingDensity struct {
+	AzimuthResDeg   float64 // Horizontal angular step between returns
+	ElevationResDeg float64 // Vertical angular step between rings
+	VoxelLeafSize   float64 // Voxel leaf in metres; zero when downsampling is off
+	ReferenceRange  float64 // Range in metres at which normalised equals raw count
+}
+
+// DefaultSamplingDensity returns the Pandar40P model: 0.2° azimuth steps
+// at 10 Hz and 0.33° ring spacing in the dense band around the horizon,
+// normalised to 10 m without voxel downsampling.
+func DefaultSamplingDensity() SamplingDensity {
+	return SamplingDensity{
+		AzimuthResDeg:   0.2,
+		ElevationResDeg: 0.33,
+		ReferenceRange:  10,
+	}
+}
+
+// Enabled reports whether the model has the resolutions and reference range
+// it needs. The zero value is disabled.
+func (d SamplingDensity) Enabled() bool {
+	return d.AzimuthResDeg > 0 && d.ElevationResDeg > 0 && d.ReferenceRange > 0
+}
+
+// PointsPerSquareMetre returns the effective sampling density at range
+// metres. Ranges below 0.1 m are clamped to avoid dividing by zero.
+func (d SamplingDensity) PointsPerSquareMetre(rangeMetres float64) float64 {
+	const deg = math.Pi / 180
+	r := math.Max(rangeMetres, 0.1)
+	density := 1 / (r * d.AzimuthResDeg * deg * r * d.ElevationResDeg * deg)
+	if d.VoxelLeafSize > 0 {
+		density = math.Min(density, 1/(d.VoxelLeafSize*d.VoxelLeafSize))
+	}
+	return density
+}
+
+// NormalizedCount scales a point count observed at rangeMetres to the count
+// the same surface would produce at ReferenceRange. It returns 0 when the
+// model is disabled.
+func (d SamplingDensity) NormalizedCount(count int, rangeMetres float64) float32 {
+	if !d.Enabled() {
+		return 0
+	}
+	scale := d.PointsPerSquareMetre(d.ReferenceRange) / d.PointsPerSquareMetre(rangeMetres)
+	return float32(float64(count) * scale)
+}
@@ -0,0 +1,97 @@
+package l4perception
+
+import (
+	"math"
+	"testing"
+)
+
+// scanFace samples a 2 m × 1.5 m surface facing the sensor at the given
+// range with the model's angular resolution, as the sensor would.
+func scanFace(d SamplingDensity, rangeMetres float64) []WorldPoint {
+	const deg = math.Pi / 180
+	dx := rangeMetres * d.AzimuthResDeg * deg
+	dz := rangeMetres * d.ElevationResDeg * deg
+	var pts []WorldPoint
+	for x := -1.0; x < 1.0; x += dx {
+		for z := 0.0; z < 1.5; z += dz {
+			pts = append(pts, WorldPoint{X: x, Y: rangeMetres, Z: z, SensorID: "test"})
+		}
+	}
+	return pts
+}
+
+func TestSamplingDensity_PointsPerSquareMetre(t *testing.T) {
+	d := DefaultSamplingDensity()
+	near, far := d.PointsPerSquareMetre(10), d.PointsPerSquareMetre(20)
+	if ratio := near / far; math.Abs(ratio-4) > 1e-9 {
+		t.Errorf("sensor-limited density should fall with r²: 10 m/20 m ratio = %v, want 4", ratio)
+	}
+
+	d.VoxelLeafSize = 0.1
+	if got := d.PointsPerSquareMetre(2); math.Abs(got-100) > 1e-9 {
+		t.Errorf("voxel-limited density at 2 m = %v, want 1/leaf² = 100", got)
+	}
+	if got := d.PointsPerSquareMetre(0); math.IsInf(got, 0) || got <= 0 {
+		t.Errorf("zero range should be clamped, got %v", got)
+	}
+}
+
+func TestSamplingDensity_Disabled(t *testing.T) {
+	if (SamplingDensity{}).Enabled() {
+		t.Fatal("zero value should be disabled")
+	}
+	if got := (SamplingDensity{}).NormalizedCount(100, 25); got != 0 {
+		t.Errorf("disabled model normalised count = %v, want 0", got)
+	}
+	if got := DefaultSamplingDensity().NormalizedCount(100, 10); got != 100 {
+		t.Errorf("count at the reference range = %v, want unchanged 100", got)
+	}
+}
+
+// TestDBSCAN_NormalizedCountIsRangeInvariant clusters the same object at
+// 10 m and 40 m. Raw counts differ several-fold; normalised counts agree.
+func TestDBSCAN_NormalizedCountIsRangeInvariant(t *testing.T) {
+	for _, leaf := range []float64{0, 0.1} {
+		density := DefaultSamplingDensity()
+		density.VoxelLeafSize = leaf
+
+		params := DBSCANParams{
+			Eps: 0.5, MinPts: 3,
+			MaxClusterDiameter: 10, MaxClusterAspectRatio: 100,
+			Density: density,
+		}
+		cluster := func(r float64) WorldCluster {
+			pts := scanFace(density, r)
+			if leaf > 0 {
+				pts = VoxelGrid(pts, leaf)
+			}
+			clusters := DBSCAN(pts, params)
+			if len(clusters) != 1 {
+				t.Fatalf("leaf=%v range=%v: expected one cluster, got %d", leaf, r, len(clusters))
+			}
+			return clusters[0]
+		}
+		near, far := cluster(10), cluster(40)
+
+		if near.PointsCount < 2*far.PointsCount {
+			t.Errorf("leaf=%v: expected raw counts to differ strongly, near=%d far=%d",
+				leaf, near.PointsCount, far.PointsCount)
+		}
+		ratio := float64(near.PointsCountNormalized / far.PointsCountNormalized)
+		if math.Abs(ratio-1) > 0.15 {
+			t.Errorf("leaf=%v: normalised counts near=%.1f far=%.1f differ by more than 15%%",
+				leaf, near.PointsCountNormalized, far.PointsCountNormalized)
+		}
+	}
+}
+
+func TestDBSCAN_NoDensityModelLeavesNormalizedUnset(t *testing.T) {
+	params := DBSCANParams{Eps: 0.5, MinPts: 3, MaxClusterDiameter: 10, MaxClusterAspectRatio: 100}
+	clusters := DBSCAN(scanFace(DefaultSamplingDensity(), 10), params)
+	if len(clusters) != 1 {
+		t.Fatalf("expected one cluster, got %d", len(clusters))
+	}
+	if clusters[0].PointsCountNormalized != 0 {
+		t.Errorf("PointsCountNormalized = %v without a density model, want 0", clusters[0].PointsCountNormalized)
+	}
+}
@@ -1,8 +1,9 @@
 // Package l4perception owns Layer 4 (Perception) of the LiDAR data model.
 //
 // Responsibilities: polar-to-world coordinate transformation, ground
-// removal, voxel downsampling, and DBSCAN clustering.
-// Key types: WorldPoint, WorldCluster.
+// removal, voxel downsampling, DBSCAN clustering, and the KDTree spatial
+// index for radius and nearest-neighbour queries.
+// Key types: WorldPoint, WorldCluster, KDTree.
 //
 // Dependency rule: L4 may depend on L1-L3, but never on L5+.
 // No SQL/database code is allowed in this package.
@@ -0,0 +1,103 @@
+package l4perception
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// SiteFrame is the world frame produced by SensorExtrinsics poses.
+const SiteFrame FrameID = "site"
+
+// SensorExtrinsics places a sensor in the site world frame.
+//
+// Angles use the sensor frame of SphericalToCartesian (+Y forward at
+// azimuth 0, +X right, +Z up) and are applied roll, then pitch, then yaw:
+//   - Roll about +Y: positive lowers the right-hand (+X) side
+//   - Pitch about +X: positive tilts the forward axis up
+//   - Yaw about +Z: positive turns the forward axis anticlockwise seen from above
+//
+// The rotated point is then translated. MountHeightM is the sensor's height
+// above the ground and is added to TranslationM[2], so with a zero Z
+// translation the site frame has Z=0 at ground level.
+type SensorExtrinsics struct {
+	TranslationM [3]float64        `json:"translation_m"`
+	RotationDeg  ExtrinsicRotation `json:"rotation_deg"`
+	MountHeightM float64           `json:"mount_height_m"`
+}
+
+// ExtrinsicRotation holds the sensor orientation in degrees.
+type ExtrinsicRotation struct {
+	Roll  float64 `json:"roll"`
+	Pitch float64 `json:"pitch"`
+	Yaw   float64 `json:"yaw"`
+}
+
+// Transform returns the row-major sensor→site transform.
+func (e SensorExtrinsics) Transform() [16]float64 {
+	const deg = math.Pi / 180
+	sr, cr := math.Sincos(e.RotationDeg.Roll * deg)
+	sp, cp := math.Sincos(e.RotationDeg.Pitch * deg)
+	sy, cy := math.Sincos(e.RotationDeg.Yaw * deg)
+
+	// R = Rz(yaw) · Rx(pitch) · Ry(roll)
+	return [16]float64{
+		cy*cr - sy*sp*sr, -sy * cp, cy*sr + sy*sp*cr, e.TranslationM[0],
+		sy*cr + cy*sp*sr, cy * cp, sy*sr - cy*sp*cr, e.TranslationM[1],
+		-cp * sr, sp, cp * cr, e.TranslationM[2] + e.MountHeightM,
+		0, 0, 0, 1,
+	}
+}
+
+// Pose returns the extrinsics as a sensor→site Pose for TransformToWorld.
+func (e SensorExtrinsics) Pose(sensorID string) *Pose {
+	return &Pose{
+		SensorID:  sensorID,
+		FromFrame: FrameID("sensor/" + sensorID),
+		ToFrame:   SiteFrame,
+		T:         e.Transform(),
+		Method:    "extrinsics",
+	}
+}
+
+// LoadSensorExtrinsics reads a JSON object of SensorExtrinsics keyed by
+// sensor ID, e.g.
+//
+//	{"hesai-pandar40p": {"translation_m": [12.5, -3, 0],
+//	  "rotation_deg": {"roll": 0, "pitch": 0, "yaw": 90}, "mount_height_m": 3.1}}
+//
+// Unknown fields are rejected so a misspelt key is not silently ignored.
+func LoadSensorExtrinsics(path string) (map[string]SensorExtrinsics, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read extrinsics: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var all map[string]SensorExtrinsics
+	if err := dec.Decode(&all); err != nil {
+		return nil, fmt.Errorf("parse extrinsics %s: %w", path, err)
+	}
+	for id, e := range all {
+		if e.MountHeightM < 0 {
+			return nil, fmt.Errorf("extrinsics for sensor %q: mount_height_m must not be negative", id)
+		}
+	}
+	return all, nil
+}
+
+// LoadSensorPose loads the extrinsics file and returns the pose for one
+// sensor. It is an error for the file to have no entry for sensorID.
+func LoadSensorPose(path, sensorID string) (*Pose, error) {
+	all, err := LoadSensorExtrinsics(path)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := all[sensorID]
+	if !ok {
+		return nil, fmt.Errorf("extrinsics file %s has no entry for sensor %q", path, sensorID)
+	}
+	return e.Pose(sensorID), nil
+}
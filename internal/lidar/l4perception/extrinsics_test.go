@@ -0,0 +1,92 @@
+package l4perception
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSensorExtrinsics_TransformKnownPoint(t *testing.T) {
+	// Sensor 3 m above the ground at site (100, 50), yawed 90°. A return
+	// 10 m straight ahead (azimuth 0 = sensor +Y) lands 10 m along site −X.
+	e := SensorExtrinsics{
+		TranslationM: [3]float64{100, 50, 0},
+		RotationDeg:  ExtrinsicRotation{Yaw: 90},
+		MountHeightM: 3,
+	}
+	world := TransformToWorld([]PointPolar{{Distance: 10, Azimuth: 0, Elevation: 0, Intensity: 9}}, e.Pose("s1"), "s1")
+	if len(world) != 1 {
+		t.Fatalf("expected 1 point, got %d", len(world))
+	}
+	got := world[0]
+	want := [3]float64{90, 50, 3}
+	if math.Abs(got.X-want[0]) > 1e-9 || math.Abs(got.Y-want[1]) > 1e-9 || math.Abs(got.Z-want[2]) > 1e-9 {
+		t.Errorf("world point = (%.6f, %.6f, %.6f), want %v", got.X, got.Y, got.Z, want)
+	}
+	if got.Intensity != 9 || got.SensorID != "s1" {
+		t.Errorf("metadata not carried: %+v", got)
+	}
+}
+
+func TestSensorExtrinsics_PitchAndRoll(t *testing.T) {
+	// Pitched 90° down, a return 5 m ahead hits the ground under a sensor
+	// mounted 5 m up.
+	down := SensorExtrinsics{RotationDeg: ExtrinsicRotation{Pitch: -90}, MountHeightM: 5}
+	x, y, z := ApplyPose(0, 5, 0, down.Transform())
+	if math.Abs(x) > 1e-9 || math.Abs(y) > 1e-9 || math.Abs(z) > 1e-9 {
+		t.Errorf("pitch: expected the ground point below the sensor, got (%.6f, %.6f, %.6f)", x, y, z)
+	}
+
+	// Rolled 90° right, a return 2 m to the right ends up 2 m below.
+	right := SensorExtrinsics{RotationDeg: ExtrinsicRotation{Roll: 90}}
+	x, y, z = ApplyPose(2, 0, 0, right.Transform())
+	if math.Abs(x) > 1e-9 || math.Abs(y) > 1e-9 || math.Abs(z+2) > 1e-9 {
+		t.Errorf("roll: expected (0, 0, -2), got (%.6f, %.6f, %.6f)", x, y, z)
+	}
+}
+
+func TestSensorExtrinsics_ZeroIsIdentity(t *testing.T) {
+	if got := (SensorExtrinsics{}).Transform(); got != IdentityTransform4x4 {
+		t.Errorf("zero extrinsics should be the identity, got %v", got)
+	}
+}
+
+func TestLoadSensorPose(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "extrinsics.json")
+	data := `{"hesai-pandar40p": {"translation_m": [1, 2, 0], "rotation_deg": {"yaw": 180}, "mount_height_m": 3.5}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pose, err := LoadSensorPose(path, "hesai-pandar40p")
+	if err != nil {
+		t.Fatalf("LoadSensorPose: %v", err)
+	}
+	if pose.FromFrame != "sensor/hesai-pandar40p" || pose.ToFrame != SiteFrame {
+		t.Errorf("unexpected frames %q → %q", pose.FromFrame, pose.ToFrame)
+	}
+	if pose.T[3] != 1 || pose.T[7] != 2 || pose.T[11] != 3.5 {
+		t.Errorf("unexpected translation in %v", pose.T)
+	}
+
+	if _, err := LoadSensorPose(path, "other"); err == nil || !strings.Contains(err.Error(), `"other"`) {
+		t.Errorf("expected a missing-sensor error, got %v", err)
+	}
+
+	for name, bad := range map[string]string{
+		"unknown field":  `{"s": {"mount_height": 3}}`,
+		"negative mount": `{"s": {"mount_height_m": -1}}`,
+		"not an object":  `[]`,
+	} {
+		p := filepath.Join(dir, strings.ReplaceAll(name, " ", "_")+".json")
+		if err := os.WriteFile(p, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSensorExtrinsics(p); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package l4perception
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+)
+
+// TestForegroundIntensityReachesClusters runs a frame with known
+// intensities through foreground extraction, the world transform and
+// DBSCAN, and checks that per-point intensity and return index survive and
+// that each cluster's IntensityMean is the mean of its own points.
+func TestForegroundIntensityReachesClusters(t *testing.T) {
+	ts := time.Now().UnixNano()
+	var polar []PointPolar
+	var mask []bool
+	addObject := func(azimuth float64, firstIntensity uint8, n int) {
+		for i := 0; i < n; i++ {
+			polar = append(polar, PointPolar{
+				Channel:     1 + i%2,
+				Azimuth:     azimuth + float64(i/2)*0.2,
+				Elevation:   float64(i % 2),
+				Distance:    10,
+				Intensity:   firstIntensity + uint8(i),
+				Timestamp:   ts,
+				ReturnIndex: uint8(i % 2),
+			})
+			mask = append(mask, true)
+		}
+	}
+	addBackground := func(azimuth float64, n int) {
+		for i := 0; i < n; i++ {
+			polar = append(polar, PointPolar{
+				Azimuth:   azimuth + float64(i)*0.2,
+				Distance:  10,
+				Intensity: 255,
+				Timestamp: ts,
+			})
+			mask = append(mask, false)
+		}
+	}
+
+	addObject(10, 10, 8) // intensities 10..17, mean 13.5
+	addBackground(45, 6)
+	addObject(90, 200, 8) // intensities 200..207, mean 203.5
+
+	fg := l3grid.ExtractForegroundPoints(polar, mask)
+	if len(fg) != 16 {
+		t.Fatalf("expected 16 foreground points, got %d", len(fg))
+	}
+	world := TransformToWorld(fg, nil, "test-sensor")
+	for i, p := range world {
+		if p.Intensity != fg[i].Intensity || p.ReturnIndex != fg[i].ReturnIndex {
+			t.Fatalf("point %d: intensity/return %d/%d, want %d/%d",
+				i, p.Intensity, p.ReturnIndex, fg[i].Intensity, fg[i].ReturnIndex)
+		}
+	}
+
+	clusters := DBSCAN(world, testDBSCANParams(0.5, 3))
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+	for _, c := range clusters {
+		// Azimuth 10° lies near +Y, azimuth 90° on +X.
+		want := float32(13.5)
+		if c.CentroidX > c.CentroidY {
+			want = 203.5
+		}
+		if math.Abs(float64(c.IntensityMean-want)) > 1e-4 {
+			t.Errorf("cluster at (%.1f, %.1f): IntensityMean %v, want %v",
+				c.CentroidX, c.CentroidY, c.IntensityMean, want)
+		}
+	}
+}
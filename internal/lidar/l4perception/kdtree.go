@@ -0,0 +1,251 @@
+package l4perception
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// KDTree is a static k-d tree over a slice of WorldPoints for radius and
+// k-nearest-neighbour queries, in either the ground plane (X, Y) or full 3D.
+// Queries return indices into the slice the tree was built from, which must
+// not be modified while the tree is in use.
+//
+// The tree is stored implicitly: Build orders an index permutation so each
+// subrange's median is its splitting node, with no per-node allocations.
+// Build is O(n log n); a radius query visits O(√n + m) points for m
+// results in 2D. It is safe for concurrent queries once built.
+//
+// SpatialIndex remains the fixed-radius grid used by DBSCAN, whose eps is
+// known up front; KDTree suits queries with varying radius or k. Fragment
+// merging (mergeFragments) uses it over cluster mean positions, where the
+// separation is unrelated to eps and the points are few and scattered.
+type KDTree struct {
+	points []WorldPoint
+	idx    []int
+	dims   int
+}
+
+// NewKDTree builds a tree over points. dims is 2 to index X and Y only, or
+// 3 to include Z; other values are treated as 3.
+func NewKDTree(points []WorldPoint, dims int) *KDTree {
+	if dims != 2 {
+		dims = 3
+	}
+	t := &KDTree{points: points, idx: make([]int, len(points)), dims: dims}
+	for i := range t.idx {
+		t.idx[i] = i
+	}
+	t.build(0, len(t.idx), 0)
+	return t
+}
+
+// Len returns the number of indexed points.
+func (t *KDTree) Len() int { return len(t.idx) }
+
+// coord returns the axis'th coordinate of point i.
+func (t *KDTree) coord(i, axis int) float64 {
+	p := &t.points[i]
+	switch axis {
+	case 0:
+		return p.X
+	case 1:
+		return p.Y
+	default:
+		return p.Z
+	}
+}
+
+// build orders idx[lo:hi] around its median on the depth's axis and recurses.
+func (t *KDTree) build(lo, hi, depth int) {
+	if hi-lo <= 1 {
+		return
+	}
+	axis := depth % t.dims
+	mid := (lo + hi) / 2
+	t.selectNth(lo, hi, mid, axis)
+	t.build(lo, mid, depth+1)
+	t.build(mid+1, hi, depth+1)
+}
+
+// selectNth partially orders idx[lo:hi] on axis so that idx[n] holds the
+// element that would be there if sorted, with no larger element before it
+// and no smaller one after it (quickselect, median-of-three pivot).
+func (t *KDTree) selectNth(lo, hi, n, axis int) {
+	idx := t.idx
+	for hi-lo > 16 {
+		mid := (lo + hi) / 2
+		// Move the median of idx[lo], idx[mid] and idx[hi-1] to hi-1 as the pivot.
+		if t.coord(idx[mid], axis) < t.coord(idx[lo], axis) {
+			idx[mid], idx[lo] = idx[lo], idx[mid]
+		}
+		if t.coord(idx[hi-1], axis) < t.coord(idx[lo], axis) {
+			idx[hi-1], idx[lo] = idx[lo], idx[hi-1]
+		}
+		if t.coord(idx[mid], axis) < t.coord(idx[hi-1], axis) {
+			idx[mid], idx[hi-1] = idx[hi-1], idx[mid]
+		}
+		pivot := t.coord(idx[hi-1], axis)
+		store := lo
+		for i := lo; i < hi-1; i++ {
+			if t.coord(idx[i], axis) < pivot {
+				idx[i], idx[store] = idx[store], idx[i]
+				store++
+			}
+		}
+		idx[store], idx[hi-1] = idx[hi-1], idx[store]
+		switch {
+		case n == store:
+			return
+		case n < store:
+			hi = store
+		default:
+			lo = store + 1
+		}
+	}
+	// Insertion sort the short remainder.
+	for i := lo + 1; i < hi; i++ {
+		for j := i; j > lo && t.coord(idx[j], axis) < t.coord(idx[j-1], axis); j-- {
+			idx[j], idx[j-1] = idx[j-1], idx[j]
+		}
+	}
+}
+
+// dist2 is the squared distance from (x, y, z) to point i in the tree's
+// dimensions.
+func (t *KDTree) dist2(i int, x, y, z float64) float64 {
+	p := &t.points[i]
+	dx, dy := p.X-x, p.Y-y
+	d := dx*dx + dy*dy
+	if t.dims == 3 {
+		dz := p.Z - z
+		d += dz * dz
+	}
+	return d
+}
+
+// RadiusQuery appends to dst the indices of points within radius of
+// (x, y, z), in no particular order, and returns the extended slice. z is
+// ignored by a 2D tree. Pass a reused dst[:0] to avoid allocating per query.
+func (t *KDTree) RadiusQuery(x, y, z, radius float64, dst []int) []int {
+	if radius < 0 || len(t.idx) == 0 {
+		return dst
+	}
+	q := [3]float64{x, y, z}
+	return t.radius(0, len(t.idx), 0, q, radius, radius*radius, dst)
+}
+
+func (t *KDTree) radius(lo, hi, depth int, q [3]float64, r, r2 float64, dst []int) []int {
+	for hi > lo {
+		mid := (lo + hi) / 2
+		i := t.idx[mid]
+		if t.dist2(i, q[0], q[1], q[2]) <= r2 {
+			dst = append(dst, i)
+		}
+		if hi-lo == 1 {
+			break
+		}
+		axis := depth % t.dims
+		diff := q[axis] - t.coord(i, axis)
+		depth++
+		// Recurse into the side the query is on; loop on the other side
+		// only when the splitting plane is within the radius.
+		if diff <= 0 {
+			if diff >= -r {
+				dst = t.radius(mid+1, hi, depth, q, r, r2, dst)
+			}
+			hi = mid
+		} else {
+			if diff <= r {
+				dst = t.radius(lo, mid, depth, q, r, r2, dst)
+			}
+			lo = mid + 1
+		}
+	}
+	return dst
+}
+
+// RadiusQueryPoint is RadiusQuery centred on points[idx]; the result
+// includes idx itself, as SpatialIndex.RegionQuery does.
+func (t *KDTree) RadiusQueryPoint(idx int, radius float64, dst []int) []int {
+	p := t.points[idx]
+	return t.RadiusQuery(p.X, p.Y, p.Z, radius, dst)
+}
+
+// Neighbour is one k-nearest-neighbour result.
+type Neighbour struct {
+	Index    int     // Index into the points the tree was built from
+	Distance float64 // Euclidean distance in the tree's dimensions
+}
+
+// neighbourHeap is a max-heap on (distance, index) holding the best k so
+// far.
+type neighbourHeap []Neighbour
+
+func (h neighbourHeap) Len() int { return len(h) }
+func (h neighbourHeap) Less(i, j int) bool {
+	if h[i].Distance != h[j].Distance {
+		return h[i].Distance > h[j].Distance
+	}
+	return h[i].Index > h[j].Index
+}
+func (h neighbourHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighbourHeap) Push(x interface{}) { *h = append(*h, x.(Neighbour)) }
+func (h *neighbourHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// KNearest returns the k points nearest (x, y, z), closest first, with ties
+// broken by index. Fewer are returned when the tree holds fewer than k
+// points. z is ignored by a 2D tree.
+func (t *KDTree) KNearest(x, y, z float64, k int) []Neighbour {
+	if k <= 0 || len(t.idx) == 0 {
+		return nil
+	}
+	h := make(neighbourHeap, 0, k)
+	t.nearest(0, len(t.idx), 0, [3]float64{x, y, z}, k, &h)
+	// Distances are squared during the search.
+	out := []Neighbour(h)
+	for i := range out {
+		out[i].Distance = math.Sqrt(out[i].Distance)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Distance != out[j].Distance {
+			return out[i].Distance < out[j].Distance
+		}
+		return out[i].Index < out[j].Index
+	})
+	return out
+}
+
+func (t *KDTree) nearest(lo, hi, depth int, q [3]float64, k int, h *neighbourHeap) {
+	if hi <= lo {
+		return
+	}
+	mid := (lo + hi) / 2
+	i := t.idx[mid]
+	d2 := t.dist2(i, q[0], q[1], q[2])
+	switch {
+	case h.Len() < k:
+		heap.Push(h, Neighbour{Index: i, Distance: d2})
+	case d2 < (*h)[0].Distance || (d2 == (*h)[0].Distance && i < (*h)[0].Index):
+		(*h)[0] = Neighbour{Index: i, Distance: d2}
+		heap.Fix(h, 0)
+	}
+	if hi-lo == 1 {
+		return
+	}
+	axis := depth % t.dims
+	diff := q[axis] - t.coord(i, axis)
+	near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+	if diff > 0 {
+		near, far = far, near
+	}
+	t.nearest(near[0], near[1], depth+1, q, k, h)
+	if h.Len() < k || diff*diff <= (*h)[0].Distance {
+		t.nearest(far[0], far[1], depth+1, q, k, h)
+	}
+}
@@ -0,0 +1,210 @@
+package l4perception
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomWorldPoints(rng *rand.Rand, n int, extent float64) []WorldPoint {
+	points := make([]WorldPoint, n)
+	for i := range points {
+		points[i] = WorldPoint{
+			X: (rng.Float64() - 0.5) * extent,
+			Y: (rng.Float64() - 0.5) * extent,
+			Z: rng.Float64() * 3,
+		}
+	}
+	return points
+}
+
+func bruteRadius(points []WorldPoint, x, y, z, r float64, dims int) []int {
+	var out []int
+	for i, p := range points {
+		d := (p.X-x)*(p.X-x) + (p.Y-y)*(p.Y-y)
+		if dims == 3 {
+			d += (p.Z - z) * (p.Z - z)
+		}
+		if d <= r*r {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func TestKDTree_RadiusQueryMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	points := randomWorldPoints(rng, 2000, 40)
+	// Duplicates and points on splitting planes must not be lost.
+	points = append(points, points[:50]...)
+
+	for _, dims := range []int{2, 3} {
+		tree := NewKDTree(points, dims)
+		if tree.Len() != len(points) {
+			t.Fatalf("Len = %d, want %d", tree.Len(), len(points))
+		}
+		var buf []int
+		for q := 0; q < 200; q++ {
+			x, y, z := (rng.Float64()-0.5)*40, (rng.Float64()-0.5)*40, rng.Float64()*3
+			r := rng.Float64() * 3
+			buf = tree.RadiusQuery(x, y, z, r, buf[:0])
+			got := append([]int(nil), buf...)
+			sort.Ints(got)
+			want := bruteRadius(points, x, y, z, r, dims)
+			if len(got) != len(want) {
+				t.Fatalf("dims=%d query %d: got %d points, want %d", dims, q, len(got), len(want))
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("dims=%d query %d: got %v, want %v", dims, q, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestKDTree_RadiusQueryPointMatchesSpatialIndex(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	points := randomWorldPoints(rng, 1500, 30)
+	const eps = 0.8
+	tree := NewKDTree(points, 2)
+	si := NewSpatialIndex(eps)
+	si.Build(points)
+
+	for i := 0; i < len(points); i += 7 {
+		got := tree.RadiusQueryPoint(i, eps, nil)
+		want := si.RegionQuery(points, i, eps)
+		sort.Ints(got)
+		sort.Ints(want)
+		if len(got) != len(want) {
+			t.Fatalf("point %d: kd-tree %d neighbours, grid %d", i, len(got), len(want))
+		}
+		for j := range got {
+			if got[j] != want[j] {
+				t.Fatalf("point %d: kd-tree %v, grid %v", i, got, want)
+			}
+		}
+	}
+}
+
+func TestKDTree_KNearestMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	points := randomWorldPoints(rng, 1000, 20)
+	for _, dims := range []int{2, 3} {
+		tree := NewKDTree(points, dims)
+		for q := 0; q < 100; q++ {
+			x, y, z := (rng.Float64()-0.5)*20, (rng.Float64()-0.5)*20, rng.Float64()*3
+			k := 1 + rng.Intn(12)
+			got := tree.KNearest(x, y, z, k)
+
+			type cand struct {
+				i int
+				d float64
+			}
+			all := make([]cand, len(points))
+			for i, p := range points {
+				d := (p.X-x)*(p.X-x) + (p.Y-y)*(p.Y-y)
+				if dims == 3 {
+					d += (p.Z - z) * (p.Z - z)
+				}
+				all[i] = cand{i, math.Sqrt(d)}
+			}
+			sort.Slice(all, func(a, b int) bool {
+				if all[a].d != all[b].d {
+					return all[a].d < all[b].d
+				}
+				return all[a].i < all[b].i
+			})
+			if len(got) != k {
+				t.Fatalf("dims=%d: got %d neighbours, want %d", dims, len(got), k)
+			}
+			for j := range got {
+				if got[j].Index != all[j].i || math.Abs(got[j].Distance-all[j].d) > 1e-12 {
+					t.Fatalf("dims=%d query %d rank %d: got %+v, want index %d at %.6f", dims, q, j, got[j], all[j].i, all[j].d)
+				}
+			}
+		}
+	}
+}
+
+func TestKDTree_EdgeCases(t *testing.T) {
+	empty := NewKDTree(nil, 2)
+	if empty.Len() != 0 || len(empty.RadiusQuery(0, 0, 0, 10, nil)) != 0 || empty.KNearest(0, 0, 0, 3) != nil {
+		t.Error("empty tree should return no results")
+	}
+
+	points := []WorldPoint{{X: 1}, {X: 2}, {X: 3}}
+	tree := NewKDTree(points, 7) // treated as 3D
+	if got := tree.KNearest(0, 0, 0, 10); len(got) != 3 || got[0].Index != 0 || got[2].Index != 2 {
+		t.Errorf("k > n: got %+v", got)
+	}
+	if got := tree.KNearest(0, 0, 0, 0); got != nil {
+		t.Errorf("k = 0: got %+v", got)
+	}
+	if got := tree.RadiusQuery(2, 0, 0, -1, nil); len(got) != 0 {
+		t.Errorf("negative radius: got %v", got)
+	}
+	if got := tree.RadiusQuery(2, 0, 0, 0, nil); len(got) != 1 || got[0] != 1 {
+		t.Errorf("zero radius should match the coincident point, got %v", got)
+	}
+}
+
+// benchmarkFrame returns a foreground-sized cloud shaped like a street
+// scene: dense clumps (vehicles, pedestrians) scattered along a 60 m road
+// plus sparse noise.
+func benchmarkFrame() []WorldPoint {
+	rng := rand.New(rand.NewSource(42))
+	points := make([]WorldPoint, 0, 20000)
+	for obj := 0; obj < 40; obj++ {
+		cx, cy := (rng.Float64()-0.5)*60, (rng.Float64()-0.5)*12
+		for i := 0; i < 450; i++ {
+			points = append(points, WorldPoint{
+				X: cx + rng.NormFloat64()*0.8,
+				Y: cy + rng.NormFloat64()*0.4,
+				Z: rng.Float64() * 1.8,
+			})
+		}
+	}
+	return append(points, randomWorldPoints(rng, 2000, 80)...)
+}
+
+func BenchmarkKDTree_Build(b *testing.B) {
+	points := benchmarkFrame()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewKDTree(points, 2)
+	}
+}
+
+func BenchmarkKDTree_RadiusQuery(b *testing.B) {
+	points := benchmarkFrame()
+	tree := NewKDTree(points, 2)
+	var buf []int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = tree.RadiusQueryPoint(i%len(points), 0.6, buf[:0])
+	}
+}
+
+func BenchmarkKDTree_KNearest(b *testing.B) {
+	points := benchmarkFrame()
+	tree := NewKDTree(points, 3)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := points[i%len(points)]
+		tree.KNearest(p.X, p.Y, p.Z, 8)
+	}
+}
+
+// BenchmarkSpatialIndex_RegionQuery is the grid-index equivalent of
+// BenchmarkKDTree_RadiusQuery, for comparison.
+func BenchmarkSpatialIndex_RegionQuery(b *testing.B) {
+	points := benchmarkFrame()
+	si := NewSpatialIndex(0.6)
+	si.Build(points)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		si.RegionQuery(points, i%len(points), 0.6)
+	}
+}
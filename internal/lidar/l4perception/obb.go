@@ -208,3 +208,82 @@ func SmoothOBBHeading(prevHeading, newHeading, alpha float32) float32 {
 
 	return smoothed
 }
+
+// FootprintIoU returns the intersection over union of two boxes' ground
+// footprints (their X-Y rectangles, ignoring Z), in [0, 1]. Boxes with no
+// area have an IoU of zero.
+func FootprintIoU(a, b OrientedBoundingBox) float32 {
+	areaA := float64(a.Length) * float64(a.Width)
+	areaB := float64(b.Length) * float64(b.Width)
+	if areaA <= 0 || areaB <= 0 {
+		return 0
+	}
+	inter := polygonArea(clipConvex(footprint(a), footprint(b)))
+	union := areaA + areaB - inter
+	if union <= 0 {
+		return 0
+	}
+	return float32(math.Min(1, inter/union))
+}
+
+// footprint returns the box's X-Y corners in counter-clockwise order.
+func footprint(b OrientedBoundingBox) [][2]float64 {
+	sin, cos := math.Sincos(float64(b.HeadingRad))
+	hl, hw := float64(b.Length)/2, float64(b.Width)/2
+	cx, cy := float64(b.CenterX), float64(b.CenterY)
+	corners := make([][2]float64, 0, 4)
+	for _, c := range [4][2]float64{{hl, hw}, {-hl, hw}, {-hl, -hw}, {hl, -hw}} {
+		corners = append(corners, [2]float64{cx + c[0]*cos - c[1]*sin, cy + c[0]*sin + c[1]*cos})
+	}
+	return corners
+}
+
+// clipConvex clips the convex polygon subject to the convex polygon clip
+// (both counter-clockwise) by Sutherland–Hodgman.
+func clipConvex(subject, clip [][2]float64) [][2]float64 {
+	out := subject
+	for i := range clip {
+		if len(out) == 0 {
+			break
+		}
+		a, b := clip[i], clip[(i+1)%len(clip)]
+		inside := func(p [2]float64) bool {
+			return (b[0]-a[0])*(p[1]-a[1])-(b[1]-a[1])*(p[0]-a[0]) >= 0
+		}
+		in := out
+		out = make([][2]float64, 0, len(in)+1)
+		for j := range in {
+			cur, prev := in[j], in[(j+len(in)-1)%len(in)]
+			curIn, prevIn := inside(cur), inside(prev)
+			if curIn != prevIn {
+				out = append(out, lineIntersect(prev, cur, a, b))
+			}
+			if curIn {
+				out = append(out, cur)
+			}
+		}
+	}
+	return out
+}
+
+// lineIntersect returns where segment p→q crosses the line through a and b.
+func lineIntersect(p, q, a, b [2]float64) [2]float64 {
+	d1 := [2]float64{q[0] - p[0], q[1] - p[1]}
+	d2 := [2]float64{b[0] - a[0], b[1] - a[1]}
+	den := d1[0]*d2[1] - d1[1]*d2[0]
+	if den == 0 {
+		return q
+	}
+	s := ((a[0]-p[0])*d2[1] - (a[1]-p[1])*d2[0]) / den
+	return [2]float64{p[0] + s*d1[0], p[1] + s*d1[1]}
+}
+
+// polygonArea returns the area of a simple polygon by the shoelace formula.
+func polygonArea(poly [][2]float64) float64 {
+	var sum float64
+	for i := range poly {
+		j := (i + 1) % len(poly)
+		sum += poly[i][0]*poly[j][1] - poly[j][0]*poly[i][1]
+	}
+	return math.Abs(sum) / 2
+}
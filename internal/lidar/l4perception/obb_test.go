@@ -328,3 +328,26 @@ func TestEstimateOBBFromCluster_RectangleDimensionsCorrect(t *testing.T) {
 		})
 	}
 }
+
+func TestFootprintIoU(t *testing.T) {
+	car := OrientedBoundingBox{Length: 4, Width: 2}
+	for _, tc := range []struct {
+		name string
+		b    OrientedBoundingBox
+		want float32
+	}{
+		{"identical", car, 1},
+		{"half shifted", OrientedBoundingBox{CenterX: 2, Length: 4, Width: 2}, 4.0 / 12.0},
+		{"disjoint", OrientedBoundingBox{CenterX: 10, Length: 4, Width: 2}, 0},
+		{"turned a half turn", OrientedBoundingBox{Length: 4, Width: 2, HeadingRad: math.Pi}, 1},
+		// A 2×2 square inside the 4×2 box.
+		{"contained", OrientedBoundingBox{Length: 2, Width: 2}, 0.5},
+		// Crossed at right angles: the overlap is the central 2×2 square.
+		{"crossed", OrientedBoundingBox{Length: 4, Width: 2, HeadingRad: math.Pi / 2}, 4.0 / 12.0},
+		{"no area", OrientedBoundingBox{Length: 4}, 0},
+	} {
+		if got := FootprintIoU(car, tc.b); math.Abs(float64(got-tc.want)) > 1e-5 {
+			t.Errorf("%s: IoU = %.4f, want %.4f", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package l4perception
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RingROI restricts perception to a band of laser rings and/or elevation
+// angles, so clustering skips sky and roof returns that can never hold a
+// road user. Unset bounds are open: a zero ring bound or a nil elevation
+// bound does not limit that side.
+type RingROI struct {
+	MinRing         int      `json:"min_ring,omitempty"`          // Lowest channel kept (1-based)
+	MaxRing         int      `json:"max_ring,omitempty"`          // Highest channel kept (1-based)
+	MinElevationDeg *float64 `json:"min_elevation_deg,omitempty"` // Lowest elevation kept, degrees
+	MaxElevationDeg *float64 `json:"max_elevation_deg,omitempty"` // Highest elevation kept, degrees
+
+	// WholePipeline also drops out-of-band points before background
+	// subtraction, not just before clustering. The background grid then
+	// never learns the excluded rings.
+	WholePipeline bool `json:"whole_pipeline,omitempty"`
+}
+
+// Validate checks the bounds are ordered and ring numbers are positive.
+func (r RingROI) Validate() error {
+	if r.MinRing < 0 || r.MaxRing < 0 {
+		return fmt.Errorf("ring bounds must be positive channel numbers, got %d-%d", r.MinRing, r.MaxRing)
+	}
+	if r.MinRing > 0 && r.MaxRing > 0 && r.MinRing > r.MaxRing {
+		return fmt.Errorf("min_ring %d is above max_ring %d", r.MinRing, r.MaxRing)
+	}
+	if r.MinElevationDeg != nil && r.MaxElevationDeg != nil && *r.MinElevationDeg > *r.MaxElevationDeg {
+		return fmt.Errorf("min_elevation_deg %.2f is above max_elevation_deg %.2f", *r.MinElevationDeg, *r.MaxElevationDeg)
+	}
+	return nil
+}
+
+// Contains reports whether p lies inside the band.
+func (r RingROI) Contains(p PointPolar) bool {
+	if r.MinRing > 0 && p.Channel < r.MinRing {
+		return false
+	}
+	if r.MaxRing > 0 && p.Channel > r.MaxRing {
+		return false
+	}
+	if r.MinElevationDeg != nil && p.Elevation < *r.MinElevationDeg {
+		return false
+	}
+	if r.MaxElevationDeg != nil && p.Elevation > *r.MaxElevationDeg {
+		return false
+	}
+	return true
+}
+
+// Filter returns the points inside the band in a new slice, leaving points
+// untouched. A nil ROI keeps every point and returns points itself.
+func (r *RingROI) Filter(points []PointPolar) []PointPolar {
+	if r == nil {
+		return points
+	}
+	out := make([]PointPolar, 0, len(points))
+	for _, p := range points {
+		if r.Contains(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// LoadRingROIs reads a JSON object of RingROI keyed by sensor ID, e.g.
+//
+//	{"hesai-pandar40p": {"min_ring": 1, "max_ring": 24, "max_elevation_deg": 2}}
+//
+// Unknown fields are rejected so a misspelt key is not silently ignored.
+func LoadRingROIs(path string) (map[string]RingROI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ring ROI: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var all map[string]RingROI
+	if err := dec.Decode(&all); err != nil {
+		return nil, fmt.Errorf("parse ring ROI %s: %w", path, err)
+	}
+	for id, roi := range all {
+		if err := roi.Validate(); err != nil {
+			return nil, fmt.Errorf("ring ROI for sensor %q: %w", id, err)
+		}
+	}
+	return all, nil
+}
+
+// LoadSensorRingROI loads the ROI file and returns the entry for one
+// sensor. It is an error for the file to have no entry for sensorID.
+func LoadSensorRingROI(path, sensorID string) (*RingROI, error) {
+	all, err := LoadRingROIs(path)
+	if err != nil {
+		return nil, err
+	}
+	roi, ok := all[sensorID]
+	if !ok {
+		return nil, fmt.Errorf("ring ROI file %s has no entry for sensor %q", path, sensorID)
+	}
+	return &roi, nil
+}
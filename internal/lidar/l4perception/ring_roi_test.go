@@ -0,0 +1,111 @@
+package l4perception
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// roiTestFrame returns a frame with a road-level object on the low rings
+// (channels 1-10, looking down) and a roof return on the high rings
+// (channels 31-40, looking up) at a different azimuth.
+func roiTestFrame() []PointPolar {
+	var points []PointPolar
+	for ch := 1; ch <= 10; ch++ {
+		for az := 0; az < 4; az++ {
+			points = append(points, PointPolar{
+				Channel: ch, Elevation: -12 + float64(ch), Azimuth: 30 + 0.2*float64(az), Distance: 12, Intensity: 50,
+			})
+		}
+	}
+	for ch := 31; ch <= 40; ch++ {
+		for az := 0; az < 4; az++ {
+			points = append(points, PointPolar{
+				Channel: ch, Elevation: float64(ch - 28), Azimuth: 120 + 0.2*float64(az), Distance: 15, Intensity: 80,
+			})
+		}
+	}
+	return points
+}
+
+func TestRingROI_FilterExcludesOutOfBandPoints(t *testing.T) {
+	ceiling := 0.0
+	for name, roi := range map[string]*RingROI{
+		"rings":     {MinRing: 1, MaxRing: 24},
+		"elevation": {MaxElevationDeg: &ceiling},
+	} {
+		kept := roi.Filter(roiTestFrame())
+		if len(kept) != 40 {
+			t.Errorf("%s: kept %d points, want the 40 road-level points", name, len(kept))
+		}
+		for _, p := range kept {
+			if p.Channel > 10 {
+				t.Errorf("%s: kept out-of-band point on channel %d", name, p.Channel)
+			}
+		}
+	}
+
+	var none *RingROI
+	if got := none.Filter(roiTestFrame()); len(got) != 80 {
+		t.Errorf("nil ROI kept %d of 80 points", len(got))
+	}
+}
+
+func TestRingROI_ClustersMatchFullPipelineInBand(t *testing.T) {
+	params := DefaultDBSCANParams()
+	full := DBSCAN(TransformToWorld(roiTestFrame(), nil, "s"), params)
+	roi := &RingROI{MinRing: 1, MaxRing: 24}
+	banded := DBSCAN(TransformToWorld(roi.Filter(roiTestFrame()), nil, "s"), params)
+
+	if len(full) != 2 {
+		t.Fatalf("full pipeline found %d clusters, want road object and roof", len(full))
+	}
+	if len(banded) != 1 {
+		t.Fatalf("ROI pipeline found %d clusters, want only the road object", len(banded))
+	}
+
+	// The road object is the full-pipeline cluster below the sensor.
+	road := full[0]
+	if full[1].CentroidZ < road.CentroidZ {
+		road = full[1]
+	}
+	got := banded[0]
+	if got.PointsCount != road.PointsCount || got.CentroidX != road.CentroidX || got.CentroidY != road.CentroidY ||
+		got.CentroidZ != road.CentroidZ || got.BoundingBoxLength != road.BoundingBoxLength ||
+		got.BoundingBoxWidth != road.BoundingBoxWidth || got.BoundingBoxHeight != road.BoundingBoxHeight {
+		t.Errorf("in-band cluster differs from the full pipeline:\n got %+v\nwant %+v", got, road)
+	}
+}
+
+func TestLoadSensorRingROI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roi.json")
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"hesai-01": {"min_ring": 1, "max_ring": 24, "min_elevation_deg": -15, "whole_pipeline": true}}`)
+	roi, err := LoadSensorRingROI(path, "hesai-01")
+	if err != nil {
+		t.Fatalf("LoadSensorRingROI: %v", err)
+	}
+	if roi.MaxRing != 24 || roi.MinElevationDeg == nil || *roi.MinElevationDeg != -15 || roi.MaxElevationDeg != nil || !roi.WholePipeline {
+		t.Errorf("loaded %+v", roi)
+	}
+	if _, err := LoadSensorRingROI(path, "other"); err == nil {
+		t.Error("expected an error for a sensor with no entry")
+	}
+
+	for _, bad := range []string{
+		`{"s": {"min_ring": 30, "max_ring": 10}}`,
+		`{"s": {"min_elevation_deg": 5, "max_elevation_deg": -5}}`,
+		`{"s": {"max_rings": 10}}`,
+	} {
+		write(bad)
+		if _, err := LoadRingROIs(path); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
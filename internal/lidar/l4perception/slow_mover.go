@@ -0,0 +1,172 @@
+package l4perception
+
+import (
+	"math"
+
+	"github.com/banshee-data/velocity.report/internal/config"
+)
+
+// Defaults for SlowMoverConfig. Window has no default: it is the switch
+// that enables accumulation.
+const (
+	DefaultSlowMoverMinFrames = 6
+	DefaultSlowMoverCellSize  = 0.5
+)
+
+// SlowMoverConfig configures accumulation of foreground across frames for
+// slow movers. A pedestrian walking very slowly barely differs from the
+// background in any one frame, so each frame holds only a few of their
+// points, too few for DBSCAN. Their foreground keeps turning up in the
+// same place, though, while transient noise such as rain or edge flicker
+// lands somewhere new every frame. With accumulation enabled, only
+// foreground seen in at least MinFrames of the last Window frames reaches
+// clustering: the current frame's points in such cells, plus the earlier
+// frames' points in them. Anything that does not stay put for MinFrames
+// frames, fast movers included, is rejected with the noise, so the mode
+// suits sites where slow movers matter more than through traffic.
+type SlowMoverConfig struct {
+	Window    int     // Frames of foreground kept, the current one included; zero or one disables accumulation
+	MinFrames int     // Frames in the window a cell needs foreground in to count as persistent; zero means DefaultSlowMoverMinFrames
+	CellSize  float64 // XY grid cell used to judge persistence (metres); zero means DefaultSlowMoverCellSize
+}
+
+// Enabled reports whether accumulation is configured.
+func (c SlowMoverConfig) Enabled() bool {
+	return c.Window > 1
+}
+
+// DefaultSlowMoverConfig returns the slow-mover settings from the canonical
+// tuning defaults file (config/tuning.defaults.json).
+// Panics if the file cannot be found.
+func DefaultSlowMoverConfig() SlowMoverConfig {
+	return SlowMoverConfigFromTuning(config.MustLoadDefaultConfig().L4.DbscanXyV1)
+}
+
+// SlowMoverConfigFromTuning builds the slow-mover settings from the active
+// L4 engine block. A nil block disables accumulation.
+func SlowMoverConfigFromTuning(l4cfg *config.L4DbscanXyV1) SlowMoverConfig {
+	if l4cfg == nil {
+		return SlowMoverConfig{}
+	}
+	return SlowMoverConfig{
+		Window:    l4cfg.SlowMoverWindow,
+		MinFrames: l4cfg.SlowMoverMinFrames,
+	}
+}
+
+func (c SlowMoverConfig) withDefaults() SlowMoverConfig {
+	if c.MinFrames <= 0 {
+		c.MinFrames = DefaultSlowMoverMinFrames
+	}
+	if c.MinFrames > c.Window {
+		c.MinFrames = c.Window
+	}
+	if c.CellSize <= 0 {
+		c.CellSize = DefaultSlowMoverCellSize
+	}
+	return c
+}
+
+type slowMoverCell struct{ x, y int64 }
+
+// slowMoverFrame is one frame of foreground held in the window.
+type slowMoverFrame struct {
+	points []WorldPoint
+	// cells that had foreground in this frame or a neighbouring cell, so
+	// an object drifting across a cell boundary stays persistent
+	cells map[slowMoverCell]struct{}
+}
+
+// SlowMoverAccumulator adds persistent foreground from recent frames to
+// each frame. It keeps the window between frames, so one accumulator must
+// see every frame of one sensor in order. It is not safe for concurrent
+// use.
+type SlowMoverAccumulator struct {
+	cfg    SlowMoverConfig
+	frames []slowMoverFrame
+	counts map[slowMoverCell]int // frames in the window each cell is marked in
+}
+
+// NewSlowMoverAccumulator returns an accumulator for cfg, or nil when cfg
+// is not enabled.
+func NewSlowMoverAccumulator(cfg SlowMoverConfig) *SlowMoverAccumulator {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &SlowMoverAccumulator{cfg: cfg.withDefaults(), counts: make(map[slowMoverCell]int)}
+}
+
+// Accumulate records points as the newest frame and returns those of its
+// points whose cell is persistent, followed by the points of earlier
+// frames in the window whose cell is persistent and still has foreground
+// in this frame. Earlier points are marked Accumulated, so they add
+// density to clustering without moving the cluster centroid. The input
+// slice is not modified. A nil accumulator returns points unchanged.
+func (a *SlowMoverAccumulator) Accumulate(points []WorldPoint) []WorldPoint {
+	if a == nil {
+		return points
+	}
+	frame := slowMoverFrame{
+		points: append([]WorldPoint(nil), points...),
+		cells:  make(map[slowMoverCell]struct{}),
+	}
+	for _, p := range points {
+		k := a.cell(p)
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				frame.cells[slowMoverCell{k.x + dx, k.y + dy}] = struct{}{}
+			}
+		}
+	}
+	for k := range frame.cells {
+		a.counts[k]++
+	}
+	a.frames = append(a.frames, frame)
+	if len(a.frames) > a.cfg.Window {
+		a.evict(a.frames[0])
+		a.frames[0] = slowMoverFrame{}
+		a.frames = a.frames[1:]
+	}
+
+	var out []WorldPoint
+	for _, p := range points {
+		if a.counts[a.cell(p)] >= a.cfg.MinFrames {
+			out = append(out, p)
+		}
+	}
+	for _, f := range a.frames[:len(a.frames)-1] {
+		for _, p := range f.points {
+			k := a.cell(p)
+			if a.counts[k] < a.cfg.MinFrames {
+				continue
+			}
+			if _, present := frame.cells[k]; !present {
+				continue
+			}
+			p.Accumulated = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Reset discards the window, e.g. when the source changes.
+func (a *SlowMoverAccumulator) Reset() {
+	if a == nil {
+		return
+	}
+	a.frames = nil
+	a.counts = make(map[slowMoverCell]int)
+}
+
+func (a *SlowMoverAccumulator) evict(f slowMoverFrame) {
+	for k := range f.cells {
+		if a.counts[k]--; a.counts[k] <= 0 {
+			delete(a.counts, k)
+		}
+	}
+}
+
+func (a *SlowMoverAccumulator) cell(p WorldPoint) slowMoverCell {
+	return slowMoverCell{int64(math.Floor(p.X / a.cfg.CellSize)), int64(math.Floor(p.Y / a.cfg.CellSize))}
+}
@@ -0,0 +1,176 @@
+package l4perception
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/config"
+)
+
+// slowWalkerFrame returns the three points a slow pedestrian leaves in
+// one frame: they creep along +X at 0.03 m per frame, and the sensor
+// catches a different few returns from them each time.
+func slowWalkerFrame(frame int, rng *rand.Rand) []WorldPoint {
+	x := 8 + 0.03*float64(frame)
+	var points []WorldPoint
+	for i := 0; i < 3; i++ {
+		points = append(points, WorldPoint{X: x + 0.4*rng.Float64(), Y: 3 + 0.4*rng.Float64(), Z: 1 + 0.6*rng.Float64()})
+	}
+	return points
+}
+
+// transientNoise returns n points scattered over a 40 m square, landing
+// somewhere new every frame like rain. It keeps clear of the walker so
+// that only accumulation can change whether they cluster.
+func transientNoise(n int, rng *rand.Rand) []WorldPoint {
+	points := make([]WorldPoint, 0, n)
+	for len(points) < n {
+		p := WorldPoint{X: -20 + 40*rng.Float64(), Y: -20 + 40*rng.Float64(), Z: 1}
+		if p.X > 6 && p.X < 11 && p.Y > 1 && p.Y < 5.5 {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+func clustersNear(clusters []WorldCluster, x, y, r float32) int {
+	n := 0
+	for _, c := range clusters {
+		if c.CentroidX >= x-r && c.CentroidX <= x+r && c.CentroidY >= y-r && c.CentroidY <= y+r {
+			n++
+		}
+	}
+	return n
+}
+
+// TestSlowMoverAccumulator_DetectsSlowWalkerRejectsNoise feeds frames with
+// a pedestrian too sparse to cluster on their own, among transient noise.
+// Per-frame clustering never finds the pedestrian. With accumulation, they
+// form a cluster once their foreground has persisted, while the noise,
+// never in one place long enough, is dropped from the current frame too.
+func TestSlowMoverAccumulator_DetectsSlowWalkerRejectsNoise(t *testing.T) {
+	params := DefaultDBSCANParams()
+	acc := NewSlowMoverAccumulator(SlowMoverConfig{Window: 10, MinFrames: 6})
+	rng := rand.New(rand.NewSource(7))
+
+	detected := 0
+	for frame := 0; frame < 20; frame++ {
+		walker := slowWalkerFrame(frame, rng)
+		noise := transientNoise(30, rng)
+		points := append(append([]WorldPoint(nil), walker...), noise...)
+
+		perFrame := DBSCAN(points, params)
+		if n := clustersNear(perFrame, 8.5, 3.2, 1); n != 0 {
+			t.Fatalf("frame %d: per-frame clustering found the walker; the test needs it sparser", frame)
+		}
+
+		out := acc.Accumulate(points)
+		if frame < 5 && len(out) != 0 {
+			t.Errorf("frame %d: %d points passed before anything persisted", frame, len(out))
+		}
+		for _, p := range out {
+			if p.X < 7.5 || p.X > 9.5 || p.Y < 2.5 || p.Y > 4 {
+				t.Errorf("frame %d: transient point passed at (%.1f, %.1f)", frame, p.X, p.Y)
+			}
+		}
+		accumulated := DBSCAN(out, params)
+		if frame < 5 && clustersNear(accumulated, 8.5, 3.2, 1) != 0 {
+			t.Errorf("frame %d: walker clustered before persisting for MinFrames", frame)
+		}
+		if frame >= 9 {
+			if clustersNear(accumulated, 8.5+0.03*float32(frame), 3.2, 1) == 1 {
+				detected++
+			}
+			if len(accumulated) != 1 {
+				t.Errorf("frame %d: %d clusters with accumulation, want just the walker", frame, len(accumulated))
+			}
+		}
+	}
+	if detected != 11 {
+		t.Errorf("walker clustered in %d of the 11 frames after the window filled", detected)
+	}
+}
+
+func TestSlowMoverAccumulator_DisabledAndReset(t *testing.T) {
+	if acc := NewSlowMoverAccumulator(SlowMoverConfig{Window: 1}); acc != nil {
+		t.Error("a one-frame window should disable accumulation")
+	}
+	var acc *SlowMoverAccumulator
+	points := []WorldPoint{{X: 1}}
+	if out := acc.Accumulate(points); len(out) != 1 {
+		t.Errorf("nil accumulator returned %d points, want the input", len(out))
+	}
+
+	acc = NewSlowMoverAccumulator(SlowMoverConfig{Window: 3, MinFrames: 2})
+	if out := acc.Accumulate(points); len(out) != 0 {
+		t.Errorf("first frame = %d points, want none before the cell persists", len(out))
+	}
+	out := acc.Accumulate(points)
+	if len(out) != 2 || out[0].Accumulated || !out[1].Accumulated {
+		t.Errorf("second frame = %+v, want the current point then the accumulated previous one", out)
+	}
+	acc.Reset()
+	if out := acc.Accumulate(points); len(out) != 0 {
+		t.Errorf("after Reset = %d points, want none", len(out))
+	}
+}
+
+// TestSlowMoverAccumulator_StaleCellsDropped checks that persistent
+// foreground from earlier frames is not added once the current frame has
+// nothing there: an object that has left must not leave a cluster behind.
+func TestSlowMoverAccumulator_StaleCellsDropped(t *testing.T) {
+	acc := NewSlowMoverAccumulator(SlowMoverConfig{Window: 5, MinFrames: 2})
+	here := []WorldPoint{{X: 1, Y: 1}}
+	acc.Accumulate(here)
+	acc.Accumulate(here)
+	if out := acc.Accumulate([]WorldPoint{{X: 20, Y: 20}}); len(out) != 0 {
+		t.Errorf("frame after the object left = %+v, want nothing", out)
+	}
+}
+
+// TestClusterCentroid_IgnoresAccumulatedPoints checks accumulated points
+// add density without dragging the centroid back along the object's path,
+// and that a cluster of only accumulated points is not reported.
+func TestClusterCentroid_IgnoresAccumulatedPoints(t *testing.T) {
+	var points []WorldPoint
+	for i := 0; i < 4; i++ {
+		points = append(points, WorldPoint{X: 10 + 0.05*float64(i), Y: 0, Z: 1})
+	}
+	for i := 0; i < 8; i++ {
+		points = append(points, WorldPoint{X: 9.2 + 0.05*float64(i), Y: 0, Z: 1, Accumulated: true})
+	}
+	c := computeClusterMetrics(points, 1)
+	if c.CentroidX < 10 {
+		t.Errorf("centroid x = %.2f, want on the current-frame points at x >= 10", c.CentroidX)
+	}
+
+	params := DefaultDBSCANParams()
+	if clusters := DBSCAN(points, params); len(clusters) != 1 {
+		t.Fatalf("%d clusters from mixed points, want 1", len(clusters))
+	}
+	stale := make([]WorldPoint, len(points))
+	for i, p := range points {
+		p.Accumulated = true
+		stale[i] = p
+	}
+	if clusters := DBSCAN(stale, params); len(clusters) != 0 {
+		t.Errorf("%d clusters from accumulated points alone, want none", len(clusters))
+	}
+}
+
+func TestSlowMoverConfigFromTuning(t *testing.T) {
+	if got := SlowMoverConfigFromTuning(nil); got != (SlowMoverConfig{}) {
+		t.Fatalf("nil block: got %+v, want zero config", got)
+	}
+	cfg := config.MustLoadDefaultConfig()
+	cfg.L4.DbscanXyV1.SlowMoverWindow = 8
+	cfg.L4.DbscanXyV1.SlowMoverMinFrames = 5
+	got := SlowMoverConfigFromTuning(cfg.L4.DbscanXyV1)
+	if got.Window != 8 || got.MinFrames != 5 {
+		t.Fatalf("got %+v, want window 8, min frames 5", got)
+	}
+	if DefaultSlowMoverConfig().Enabled() {
+		t.Fatal("tuning defaults should leave slow-mover accumulation off")
+	}
+}
@@ -9,10 +9,12 @@ import (
 // WorldPoint represents a point in Cartesian world coordinates (site frame).
 // This is the canonical definition; internal/lidar aliases it for backward compatibility.
 type WorldPoint struct {
-	X, Y, Z   float64   // World frame position (meters)
-	Intensity uint8     // Laser return intensity
-	Timestamp time.Time // Acquisition time
-	SensorID  string    // Source sensor
+	X, Y, Z     float64   // World frame position (meters)
+	Intensity   uint8     // Laser return intensity
+	ReturnIndex uint8     // Return within a multi-return firing (see l2frames.PointPolar)
+	Timestamp   time.Time // Acquisition time
+	SensorID    string    // Source sensor
+	Accumulated bool      // Carried from an earlier frame by slow-mover accumulation; kept out of the cluster centroid
 }
 
 // FrameID is a human-readable name like "sensor/hesai-01" or "site/main-st-001".
@@ -53,6 +55,10 @@ type WorldCluster struct {
 	HeightP95         float32 // matches height_p95 REAL
 	IntensityMean     float32 // matches intensity_mean REAL
 
+	// Point count rescaled to the reference range (see SamplingDensity);
+	// zero when no density model was configured.
+	PointsCountNormalized float32 // matches points_count_normalized REAL
+
 	// Debug hints matching schema optional fields
 	SensorRingHint  *int     // matches sensor_ring_hint INTEGER
 	SensorAzDegHint *float32 // matches sensor_azimuth_deg_hint REAL
@@ -60,6 +66,10 @@ type WorldCluster struct {
 	// Optional in-memory only fields (not persisted to schema)
 	SamplePoints [][3]float32         // for debugging/thumbnails
 	OBB          *OrientedBoundingBox // Oriented bounding box (computed via PCA)
+
+	// Identity carried across frames by ClusterIdentifier; zero when
+	// stable IDs are not enabled.
+	StableID int64
 }
 
 // PointPolar is a backward-compatible alias for the canonical definition in l2frames.
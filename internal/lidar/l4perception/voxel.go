@@ -2,58 +2,89 @@ package l4perception
 
 import "math"
 
-// VoxelGrid performs 3D voxel grid downsampling on world-frame points.
-// Each occupied voxel retains a single representative point (the one closest
-// to the voxel centroid), reducing point density while preserving spatial
-// structure better than uniform stride decimation.
+// VoxelGridConfig configures voxel grid downsampling.
+type VoxelGridConfig struct {
+	// LeafSize is the side-length (metres) of each cubic voxel; <= 0
+	// disables downsampling. Typical value: 0.05–0.15 m for street-level
+	// LiDAR.
+	LeafSize float64
+
+	// OriginX, OriginY and OriginZ place a voxel corner in the world frame.
+	// Voxels tile space from this fixed point, so the same world position
+	// falls in the same voxel every frame whatever else is in the cloud.
+	// The zero value aligns the grid to the world origin.
+	OriginX, OriginY, OriginZ float64
+
+	// SnapToCentre emits each occupied voxel's centre instead of its most
+	// central real point. A static surface then yields identical points
+	// from frame to frame, rather than whichever noisy return happened to
+	// land nearest the voxel's centroid, which steadies cluster centroids.
+	// Intensity, timestamp and sensor ID come from the most central point.
+	SnapToCentre bool
+}
+
+// VoxelGrid performs 3D voxel grid downsampling on world-frame points with
+// the grid aligned to the world origin. Each occupied voxel retains a single
+// representative point (the one closest to the voxel centroid), reducing
+// point density while preserving spatial structure better than uniform
+// stride decimation.
 //
 // leafSize is the side-length (metres) of each cubic voxel.
-// Typical value: 0.05–0.15 m for street-level LiDAR.
 func VoxelGrid(points []WorldPoint, leafSize float64) []WorldPoint {
-	if len(points) == 0 || leafSize <= 0 {
+	return VoxelGridWithConfig(points, VoxelGridConfig{LeafSize: leafSize})
+}
+
+// VoxelGridWithConfig performs voxel grid downsampling as configured by cfg.
+// Output points are in the order their voxels were first occupied, so the
+// same input always gives the same output.
+func VoxelGridWithConfig(points []WorldPoint, cfg VoxelGridConfig) []WorldPoint {
+	if len(points) == 0 || cfg.LeafSize <= 0 {
 		return points
 	}
 
-	invLeaf := 1.0 / leafSize
+	invLeaf := 1.0 / cfg.LeafSize
+	voxelKey := func(p WorldPoint) [3]int64 {
+		return [3]int64{
+			int64(math.Floor((p.X - cfg.OriginX) * invLeaf)),
+			int64(math.Floor((p.Y - cfg.OriginY) * invLeaf)),
+			int64(math.Floor((p.Z - cfg.OriginZ) * invLeaf)),
+		}
+	}
 
 	// Each voxel accumulates sum(X,Y,Z) and count so we can compute the
 	// centroid, then we pick the original point closest to that centroid.
 	type voxelAccum struct {
+		key              [3]int64
 		sumX, sumY, sumZ float64
 		count            int
 		bestIdx          int     // index of point closest to centroid (resolved lazily)
 		bestDist2        float64 // squared distance to centroid
+		current          bool    // some point was observed this frame (not Accumulated)
 	}
 
 	voxels := make(map[[3]int64]*voxelAccum, len(points)/4)
+	order := make([]*voxelAccum, 0, len(points)/4)
 
 	// Pass 1: accumulate per-voxel statistics.
 	for i, p := range points {
-		key := [3]int64{
-			int64(math.Floor(p.X * invLeaf)),
-			int64(math.Floor(p.Y * invLeaf)),
-			int64(math.Floor(p.Z * invLeaf)),
-		}
+		key := voxelKey(p)
 		acc, exists := voxels[key]
 		if !exists {
-			acc = &voxelAccum{bestIdx: i, bestDist2: math.MaxFloat64}
+			acc = &voxelAccum{key: key, bestIdx: i, bestDist2: math.MaxFloat64}
 			voxels[key] = acc
+			order = append(order, acc)
 		}
 		acc.sumX += p.X
 		acc.sumY += p.Y
 		acc.sumZ += p.Z
 		acc.count++
+		acc.current = acc.current || !p.Accumulated
 	}
 
 	// Pass 2: for each voxel, compute centroid and pick closest point.
 	// We iterate points again but only for occupied voxels.
 	for i, p := range points {
-		key := [3]int64{
-			int64(math.Floor(p.X * invLeaf)),
-			int64(math.Floor(p.Y * invLeaf)),
-			int64(math.Floor(p.Z * invLeaf)),
-		}
-		acc := voxels[key]
+		acc := voxels[voxelKey(p)]
 		cx := acc.sumX / float64(acc.count)
 		cy := acc.sumY / float64(acc.count)
 		cz := acc.sumZ / float64(acc.count)
@@ -68,9 +99,16 @@ func VoxelGrid(points []WorldPoint, leafSize float64) []WorldPoint {
 	}
 
 	// Collect survivors.
-	result := make([]WorldPoint, 0, len(voxels))
-	for _, acc := range voxels {
-		result = append(result, points[acc.bestIdx])
+	result := make([]WorldPoint, 0, len(order))
+	for _, acc := range order {
+		p := points[acc.bestIdx]
+		if cfg.SnapToCentre {
+			p.X = cfg.OriginX + (float64(acc.key[0])+0.5)*cfg.LeafSize
+			p.Y = cfg.OriginY + (float64(acc.key[1])+0.5)*cfg.LeafSize
+			p.Z = cfg.OriginZ + (float64(acc.key[2])+0.5)*cfg.LeafSize
+		}
+		p.Accumulated = !acc.current
+		result = append(result, p)
 	}
 
 	return result
@@ -1,6 +1,8 @@
 package l4perception
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -169,3 +171,106 @@ func TestVoxelGrid_3DSeparation(t *testing.T) {
 		t.Errorf("expected 2 points (different Z voxels), got %d", len(result))
 	}
 }
+
+// staticWall returns one frame of a static 1 m × 1.5 m wall 10 m from the
+// sensor, sampled every 2 cm with up to ±1 cm of range noise per axis. The
+// wall's edges sit 3 cm inside 10 cm voxel boundaries, so noise never moves
+// a point into a voxel the wall does not occupy.
+func staticWall(rng *rand.Rand) []WorldPoint {
+	noise := func() float64 { return (rng.Float64()*2 - 1) * 0.01 }
+	var points []WorldPoint
+	for y := 2.03; y < 2.98; y += 0.02 {
+		for z := 0.03; z < 1.48; z += 0.02 {
+			points = append(points, WorldPoint{X: 10.05 + noise(), Y: y + noise(), Z: z + noise()})
+		}
+	}
+	return points
+}
+
+func voxelCentroid(points []WorldPoint) [3]float64 {
+	var c [3]float64
+	for _, p := range points {
+		c[0] += p.X
+		c[1] += p.Y
+		c[2] += p.Z
+	}
+	n := float64(len(points))
+	return [3]float64{c[0] / n, c[1] / n, c[2] / n}
+}
+
+func TestVoxelGridWithConfig_AlignedSnapIsStableAcrossFrames(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const leaf, frames = 0.1, 10
+
+	var aligned, unaligned, nearest [frames][3]float64
+	for f := 0; f < frames; f++ {
+		points := staticWall(rng)
+
+		aligned[f] = voxelCentroid(VoxelGridWithConfig(points, VoxelGridConfig{LeafSize: leaf, SnapToCentre: true}))
+
+		// Unaligned: the grid starts at this frame's bounding-box minimum,
+		// as a cloud-relative voxel filter would place it.
+		minX, minY, minZ := math.Inf(1), math.Inf(1), math.Inf(1)
+		for _, p := range points {
+			minX, minY, minZ = math.Min(minX, p.X), math.Min(minY, p.Y), math.Min(minZ, p.Z)
+		}
+		unaligned[f] = voxelCentroid(VoxelGridWithConfig(points, VoxelGridConfig{
+			LeafSize: leaf, OriginX: minX, OriginY: minY, OriginZ: minZ, SnapToCentre: true,
+		}))
+
+		nearest[f] = voxelCentroid(VoxelGrid(points, leaf))
+	}
+
+	for f := 1; f < frames; f++ {
+		if aligned[f] != aligned[0] {
+			t.Fatalf("aligned centroid changed between frames: %v then %v", aligned[0], aligned[f])
+		}
+	}
+	varies := func(c [frames][3]float64) bool {
+		for f := 1; f < frames; f++ {
+			if c[f] != c[0] {
+				return true
+			}
+		}
+		return false
+	}
+	if !varies(unaligned) {
+		t.Error("unaligned centroid should vary with the per-frame bounding box")
+	}
+	if !varies(nearest) {
+		t.Error("nearest-point centroid should vary with range noise")
+	}
+}
+
+func TestVoxelGridWithConfig_Origin(t *testing.T) {
+	points := []WorldPoint{
+		{X: 0.02, Y: 0, Z: 0},
+		{X: 0.05, Y: 0, Z: 0, Intensity: 7, SensorID: "s1"},
+		{X: 0.08, Y: 0, Z: 0},
+	}
+	// Aligned to the world origin all three share the [0, 0.1) voxel, and
+	// the middle point, nearest the centroid, supplies the metadata.
+	got := VoxelGridWithConfig(points, VoxelGridConfig{LeafSize: 0.1, SnapToCentre: true})
+	if len(got) != 1 || math.Abs(got[0].X-0.05) > 1e-12 || got[0].Intensity != 7 || got[0].SensorID != "s1" {
+		t.Fatalf("world-aligned voxels = %+v, want one point at the voxel centre 0.05", got)
+	}
+	// An origin at x=0.05 puts a voxel boundary after the first point.
+	got = VoxelGridWithConfig(points, VoxelGridConfig{LeafSize: 0.1, OriginX: 0.05, SnapToCentre: true})
+	if len(got) != 2 || math.Abs(got[0].X-0.0) > 1e-12 || math.Abs(got[1].X-0.1) > 1e-12 {
+		t.Errorf("offset voxels = %+v, want centres 0.0 and 0.1", got)
+	}
+}
+
+func TestVoxelGridWithConfig_DeterministicOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	points := staticWall(rng)
+	first := VoxelGridWithConfig(points, VoxelGridConfig{LeafSize: 0.1})
+	for i := 0; i < 5; i++ {
+		again := VoxelGridWithConfig(points, VoxelGridConfig{LeafSize: 0.1})
+		for j := range first {
+			if again[j] != first[j] {
+				t.Fatalf("run %d: output order differs at %d", i, j)
+			}
+		}
+	}
+}
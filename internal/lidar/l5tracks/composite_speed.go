@@ -0,0 +1,65 @@
+package l5tracks
+
+import "math"
+
+// Composite speed.
+//
+// Reporting needs one headline speed per vehicle. The peak is set by the
+// noisiest frame, and the plain average counts frames where the Kalman
+// velocity is poorly determined as fully as well-observed ones. The
+// composite is a weighted mean of the per-frame Kalman speeds, each frame
+// weighted by
+//
+//	w = c · v
+//	c = σ_ref² / (σ_ref² + σ_speed²)                 velocity confidence
+//	v = 1/(1 + m) · min(1, NIS_ref / NIS)            observation validity
+//
+// where σ_speed is the Kalman speed standard deviation after the update
+// (SpeedStdDev), m is the number of frames the track coasted through
+// before this observation, and NIS is the normalised innovation squared of
+// the measurement. A frame re-acquired after an occlusion, or whose
+// centroid jumped because only part of the object was seen, disagrees
+// with the prediction; it and the frames while the filter recovers count
+// for little, so the velocity spike they produce barely moves the
+// composite. The first observation has no measured velocity and weight
+// zero.
+//
+// The composite is the canonical reported speed; AvgSpeedMps, MaxSpeedMps
+// and the speed history are unchanged.
+
+const (
+	// compositeSpeedRefStdDevMps is the speed standard deviation at which
+	// a frame's velocity confidence is one half.
+	compositeSpeedRefStdDevMps = 0.5
+	// compositeSpeedNISRef is the NIS above which a frame's validity falls
+	// off: the 95th percentile of χ² with two degrees of freedom.
+	compositeSpeedNISRef = 5.99
+)
+
+// compositeSpeedWeight returns the weight of the track's current frame,
+// observed after coasting through gap frames with innovation nis.
+func compositeSpeedWeight(track *TrackedObject, gap int, nis float32) float64 {
+	if track.ObservationCount <= 1 {
+		return 0
+	}
+	sd := float64(track.SpeedStdDev())
+	ref := compositeSpeedRefStdDevMps * compositeSpeedRefStdDevMps
+	confidence := ref / (ref + sd*sd)
+	validity := 1 / (1 + float64(max(gap, 0)))
+	if float64(nis) > compositeSpeedNISRef {
+		validity *= compositeSpeedNISRef / float64(nis)
+	}
+	return confidence * validity
+}
+
+// observeCompositeSpeed adds the current frame's Kalman speed to the
+// track's composite speed.
+func (track *TrackedObject) observeCompositeSpeed(speed float32, gap int, nis float32) {
+	w := compositeSpeedWeight(track, gap, nis)
+	if w <= 0 || math.IsNaN(w) {
+		return
+	}
+	track.CompositeWeightSum += w
+	track.CompositeWeightedSum += w * float64(speed)
+	track.CompositeSpeedMps = float32(track.CompositeWeightedSum / track.CompositeWeightSum)
+}
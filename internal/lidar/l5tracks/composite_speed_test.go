@@ -0,0 +1,112 @@
+package l5tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// compositeFrame is one scripted update: the Kalman speed and its σ, the
+// frames coasted through before it and its NIS.
+type compositeFrame struct {
+	speed, sd, nis float32
+	gap            int
+}
+
+// TestCompositeSpeed_DownWeightsOcclusionSpike scripts a car at a steady
+// 10 m/s that is missed for three frames and re-acquired with its centroid
+// thrown forward by the partial view: the re-acquisition and the frames
+// while the filter recovers spike the speed and disagree with the
+// prediction. The composite stays with the sustained speed while the plain
+// average is pulled off it.
+func TestCompositeSpeed_DownWeightsOcclusionSpike(t *testing.T) {
+	var frames []compositeFrame
+	for i := 0; i < 20; i++ {
+		frames = append(frames, compositeFrame{speed: 10 + 0.1*float32(i%3-1), sd: 0.4, nis: 1})
+	}
+	frames = append(frames,
+		compositeFrame{speed: 16, sd: 0.6, nis: 40, gap: 3},
+		compositeFrame{speed: 14, sd: 0.5, nis: 18},
+		compositeFrame{speed: 12, sd: 0.45, nis: 8},
+	)
+	for i := 0; i < 5; i++ {
+		frames = append(frames, compositeFrame{speed: 10, sd: 0.4, nis: 1})
+	}
+
+	track := &TrackedObject{}
+	track.ObservationCount = 1
+	var sum float32
+	for _, f := range frames {
+		track.ObservationCount++
+		track.P[2*4+2] = f.sd * f.sd
+		track.observeCompositeSpeed(f.speed, f.gap, f.nis)
+		sum += f.speed
+	}
+	avg := sum / float32(len(frames))
+
+	const trueSpeed = 10.0
+	compositeErr := math.Abs(float64(track.CompositeSpeedMps) - trueSpeed)
+	if compositeErr > 0.15 {
+		t.Errorf("composite %.2f m/s is %.2f off the true %.0f m/s (average %.2f)", track.CompositeSpeedMps, compositeErr, trueSpeed, avg)
+	}
+	if avgErr := math.Abs(float64(avg) - trueSpeed); avgErr < 3*compositeErr {
+		t.Errorf("average %.2f is %.2f off, composite %.2f off: spike not down-weighted", avg, avgErr, compositeErr)
+	}
+}
+
+// TestCompositeSpeed_Tracker drives a car at 10 m/s through the tracker.
+// The Kalman speed ramps up from zero over the first frames, which disagree
+// with the prediction and have a wide speed σ, so the composite sits closer
+// to the true speed than the running average.
+func TestCompositeSpeed_Tracker(t *testing.T) {
+	cfg := DefaultTrackerConfig()
+	cfg.HitsToConfirm = 2
+	tracker := NewTracker(cfg)
+
+	const trueSpeed = 10.0
+	start := time.Unix(1_700_000_000, 0)
+	for i := 0; i < 40; i++ {
+		now := start.Add(time.Duration(i) * 100 * time.Millisecond)
+		cluster := WorldCluster{
+			CentroidX: float32(10 + trueSpeed*0.1*float64(i)), CentroidZ: 0.8, SensorID: "test",
+			BoundingBoxLength: 4.5, BoundingBoxWidth: 1.8, BoundingBoxHeight: 1.5, PointsCount: 120,
+		}
+		tracker.Update([]WorldCluster{cluster}, now)
+	}
+
+	tracks := tracker.GetConfirmedTracks()
+	if len(tracks) != 1 {
+		t.Fatalf("got %d confirmed tracks, want 1", len(tracks))
+	}
+	track := tracks[0]
+	compositeErr := math.Abs(float64(track.CompositeSpeedMps) - trueSpeed)
+	avgErr := math.Abs(float64(track.AvgSpeedMps) - trueSpeed)
+	if compositeErr > 0.75 || compositeErr >= avgErr {
+		t.Errorf("composite %.2f m/s is %.2f off the true speed, average %.2f is %.2f off", track.CompositeSpeedMps, compositeErr, track.AvgSpeedMps, avgErr)
+	}
+}
+
+func TestCompositeSpeedWeight(t *testing.T) {
+	track := &TrackedObject{VX: 10}
+	track.ObservationCount = 1
+	track.P[2*4+2] = 0.25 // σ = 0.5 m/s, the reference
+	if w := compositeSpeedWeight(track, 0, 1); w != 0 {
+		t.Errorf("first observation weight = %v, want 0", w)
+	}
+	track.ObservationCount = 5
+	if w := compositeSpeedWeight(track, 0, 1); math.Abs(w-0.5) > 1e-6 {
+		t.Errorf("weight at the reference σ = %v, want 0.5", w)
+	}
+	if w := compositeSpeedWeight(track, 3, 1); math.Abs(w-0.125) > 1e-6 {
+		t.Errorf("weight after a 3-frame gap = %v, want 0.125", w)
+	}
+	if w := compositeSpeedWeight(track, 0, 2*compositeSpeedNISRef); math.Abs(w-0.25) > 1e-6 {
+		t.Errorf("weight at twice the reference NIS = %v, want 0.25", w)
+	}
+
+	track.observeCompositeSpeed(10, 0, 1) // weight 0.5
+	track.observeCompositeSpeed(20, 3, 1) // weight 0.125
+	if want := float32((0.5*10 + 0.125*20) / 0.625); math.Abs(float64(track.CompositeSpeedMps-want)) > 1e-5 {
+		t.Errorf("composite = %v, want %v", track.CompositeSpeedMps, want)
+	}
+}
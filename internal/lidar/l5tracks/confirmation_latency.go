@@ -0,0 +1,205 @@
+package l5tracks
+
+import (
+	"math"
+	"sort"
+)
+
+// Confirmation latency.
+//
+// HitsToConfirm trades responsiveness against false positives: a higher
+// threshold rejects more short-lived noise but leaves real objects
+// unconfirmed for longer after they appear. The tracker records, for every
+// track it confirms, how many frames and seconds passed between the
+// track's first observation and its confirmation. When birth zones are
+// configured it also estimates how long an object born inside one had
+// already been in the scene before it was first detected, by tracing its
+// early motion back to the zone edge it entered through.
+
+// minEntrySpeedMps is the slowest early motion an entry estimate is made
+// for; slower tracks give a direction too noisy to trace back.
+const minEntrySpeedMps = 0.5
+
+// ConfirmationLatency is how long one track took to be confirmed.
+type ConfirmationLatency struct {
+	ConfirmedUnixNanos int64 `json:"confirmed_unix_nanos"`
+	// Frames from first observation to confirmation, both included:
+	// HitsToConfirm for a track observed on every frame, more when it
+	// was born outside the birth zones or missed frames on the way.
+	Frames int     `json:"frames"`
+	Secs   float64 `json:"secs"`
+	// BirthZone is the birth zone the track was born in; empty without
+	// birth zones or for a track born outside them.
+	BirthZone string `json:"birth_zone,omitempty"`
+	// EntrySecs estimates how long the object had been inside BirthZone
+	// before its first detection: the distance from its first position
+	// back to the zone edge along its mean velocity up to confirmation,
+	// over its speed. Nil without a birth zone or when the object moved
+	// slower than 0.5 m/s.
+	EntrySecs *float64 `json:"entry_secs,omitempty"`
+}
+
+// ConfirmationLatencyStats accumulates confirmation latencies over every
+// track the tracker has confirmed.
+type ConfirmationLatencyStats struct {
+	Count        int
+	Penalised    int         // confirmed tracks born outside the birth zones
+	FrameCounts  map[int]int // frames to confirm → tracks
+	SecsSum      float64
+	SecsMax      float64
+	EntryCount   int
+	EntrySecsSum float64
+	EntrySecsMax float64
+}
+
+// ConfirmationLatencySummary is the aggregate view of
+// ConfirmationLatencyStats reported by the tracking metrics and analysis
+// results.
+type ConfirmationLatencySummary struct {
+	HitsToConfirm int     `json:"hits_to_confirm"`
+	Confirmed     int     `json:"confirmed"`
+	Penalised     int     `json:"penalised,omitempty"`
+	MeanFrames    float64 `json:"mean_frames"`
+	P50Frames     int     `json:"p50_frames"`
+	P95Frames     int     `json:"p95_frames"`
+	MaxFrames     int     `json:"max_frames"`
+	MeanSecs      float64 `json:"mean_secs"`
+	MaxSecs       float64 `json:"max_secs"`
+	// Entry-to-detection estimates, over tracks born in a birth zone.
+	EntryEstimated int     `json:"entry_estimated,omitempty"`
+	MeanEntrySecs  float64 `json:"mean_entry_secs,omitempty"`
+	MaxEntrySecs   float64 `json:"max_entry_secs,omitempty"`
+}
+
+// recordConfirmation stores the latency of track, confirmed at nowNanos
+// by cluster, and adds it to the tracker's totals. Entry estimates use the
+// measured centroids rather than the filtered position, which lags while
+// the filter's velocity is still settling. The caller holds t.mu.
+func (t *Tracker) recordConfirmation(track *TrackedObject, cluster WorldCluster, nowNanos int64) {
+	lat := &ConfirmationLatency{
+		ConfirmedUnixNanos: nowNanos,
+		Frames:             track.ObservationCount + track.CoastedFrames,
+		Secs:               float64(nowNanos-track.StartUnixNanos) / 1e9,
+		BirthZone:          track.birthZone,
+	}
+	if lat.BirthZone != "" && lat.Secs > 0 {
+		if zone, ok := t.birthZoneByID(lat.BirthZone); ok {
+			vx := float64(cluster.CentroidX-track.birthX) / lat.Secs
+			vy := float64(cluster.CentroidY-track.birthY) / lat.Secs
+			if speed := math.Hypot(vx, vy); speed >= minEntrySpeedMps {
+				d := distanceToZoneEdge(zone, float64(track.birthX), float64(track.birthY), -vx/speed, -vy/speed)
+				entry := d / speed
+				lat.EntrySecs = &entry
+			}
+		}
+	}
+	track.Confirmation = lat
+
+	s := &t.ConfirmationStats
+	if s.FrameCounts == nil {
+		s.FrameCounts = make(map[int]int)
+	}
+	s.Count++
+	if track.birthPenaltyHits > 0 {
+		s.Penalised++
+	}
+	s.FrameCounts[lat.Frames]++
+	s.SecsSum += lat.Secs
+	s.SecsMax = math.Max(s.SecsMax, lat.Secs)
+	if lat.EntrySecs != nil {
+		s.EntryCount++
+		s.EntrySecsSum += *lat.EntrySecs
+		s.EntrySecsMax = math.Max(s.EntrySecsMax, *lat.EntrySecs)
+	}
+}
+
+// ConfirmationLatencySummary summarises the confirmation latencies of
+// every track confirmed so far, or returns nil if none has been.
+func (t *Tracker) ConfirmationLatencySummary() *ConfirmationLatencySummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.ConfirmationStats.summary(t.Config.HitsToConfirm)
+}
+
+func (s ConfirmationLatencyStats) summary(hitsToConfirm int) *ConfirmationLatencySummary {
+	if s.Count == 0 {
+		return nil
+	}
+	sum := &ConfirmationLatencySummary{
+		HitsToConfirm: hitsToConfirm,
+		Confirmed:     s.Count,
+		Penalised:     s.Penalised,
+		MeanSecs:      s.SecsSum / float64(s.Count),
+		MaxSecs:       s.SecsMax,
+	}
+
+	frames := make([]int, 0, len(s.FrameCounts))
+	for f := range s.FrameCounts {
+		frames = append(frames, f)
+	}
+	sort.Ints(frames)
+	total, seen := 0, 0
+	for _, f := range frames {
+		total += f * s.FrameCounts[f]
+	}
+	sum.MeanFrames = float64(total) / float64(s.Count)
+	sum.MaxFrames = frames[len(frames)-1]
+	p50, p95 := (s.Count+1)/2, int(math.Ceil(0.95*float64(s.Count)))
+	for _, f := range frames {
+		before := seen
+		seen += s.FrameCounts[f]
+		if before < p50 && seen >= p50 {
+			sum.P50Frames = f
+		}
+		if before < p95 && seen >= p95 {
+			sum.P95Frames = f
+		}
+	}
+
+	if s.EntryCount > 0 {
+		sum.EntryEstimated = s.EntryCount
+		sum.MeanEntrySecs = s.EntrySecsSum / float64(s.EntryCount)
+		sum.MaxEntrySecs = s.EntrySecsMax
+	}
+	return sum
+}
+
+// birthZoneAt returns the ID of the first birth zone containing (x, y).
+func (t *Tracker) birthZoneAt(x, y float32) string {
+	if lz := t.Config.LifecycleZones; lz != nil {
+		for _, z := range lz.BirthZones {
+			if z.Contains(x, y) {
+				return z.ID
+			}
+		}
+	}
+	return ""
+}
+
+func (t *Tracker) birthZoneByID(id string) (LifecycleZone, bool) {
+	if lz := t.Config.LifecycleZones; lz != nil {
+		for _, z := range lz.BirthZones {
+			if z.ID == id {
+				return z, true
+			}
+		}
+	}
+	return LifecycleZone{}, false
+}
+
+// distanceToZoneEdge returns how far the ray from (x, y) along the unit
+// direction (dx, dy) travels before leaving zone. (x, y) must lie inside.
+func distanceToZoneEdge(zone LifecycleZone, x, y, dx, dy float64) float64 {
+	d := math.Inf(1)
+	if dx > 0 {
+		d = math.Min(d, (float64(zone.MaxX)-x)/dx)
+	} else if dx < 0 {
+		d = math.Min(d, (float64(zone.MinX)-x)/dx)
+	}
+	if dy > 0 {
+		d = math.Min(d, (float64(zone.MaxY)-y)/dy)
+	} else if dy < 0 {
+		d = math.Min(d, (float64(zone.MinY)-y)/dy)
+	}
+	return d
+}
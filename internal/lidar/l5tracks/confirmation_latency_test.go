@@ -0,0 +1,116 @@
+package l5tracks
+
+import (
+	"math"
+	"testing"
+)
+
+// driveTrack feeds one object moving along +X at 10 m/s from x0, observed
+// on every frame except those in skip.
+func driveTrack(t *testing.T, tracker *Tracker, x0 float32, frames int, skip map[int]bool) {
+	t.Helper()
+	for frame := 0; frame < frames; frame++ {
+		var clusters []WorldCluster
+		if !skip[frame] {
+			clusters = []WorldCluster{{CentroidX: x0 + float32(frame), CentroidY: 0, SensorID: "test"}}
+		}
+		tracker.Update(clusters, lifecycleFrameTime(frame))
+	}
+}
+
+func confirmedTrack(t *testing.T, tracker *Tracker) *TrackedObject {
+	t.Helper()
+	confirmed := tracker.GetConfirmedTracks()
+	if len(confirmed) != 1 {
+		t.Fatalf("confirmed tracks = %d, want 1", len(confirmed))
+	}
+	if confirmed[0].Confirmation == nil {
+		t.Fatal("confirmed track has no confirmation latency")
+	}
+	return confirmed[0]
+}
+
+// TestTracker_ConfirmationLatencyMatchesHitsToConfirm checks a track seen
+// on every frame is confirmed after exactly HitsToConfirm frames, so the
+// delay is HitsToConfirm-1 frame intervals.
+func TestTracker_ConfirmationLatencyMatchesHitsToConfirm(t *testing.T) {
+	for _, hits := range []int{2, 3, 5} {
+		tracker := newLifecycleTestTracker(nil)
+		tracker.Config.HitsToConfirm = hits
+		driveTrack(t, tracker, 0, hits+2, nil)
+
+		lat := confirmedTrack(t, tracker).Confirmation
+		wantSecs := float64(hits-1) * 0.1
+		if lat.Frames != hits || math.Abs(lat.Secs-wantSecs) > 1e-9 {
+			t.Errorf("HitsToConfirm=%d: confirmed after %d frames, %.3f s; want %d frames, %.3f s",
+				hits, lat.Frames, lat.Secs, hits, wantSecs)
+		}
+		if lat.ConfirmedUnixNanos != lifecycleFrameTime(hits-1).UnixNano() {
+			t.Errorf("HitsToConfirm=%d: confirmed at %d, want frame %d", hits, lat.ConfirmedUnixNanos, hits-1)
+		}
+		if lat.BirthZone != "" || lat.EntrySecs != nil {
+			t.Errorf("entry estimate without birth zones: %+v", lat)
+		}
+
+		sum := tracker.ConfirmationLatencySummary()
+		if sum == nil || sum.Confirmed != 1 || sum.HitsToConfirm != hits || sum.P50Frames != hits || sum.MeanFrames != float64(hits) {
+			t.Errorf("HitsToConfirm=%d: summary %+v", hits, sum)
+		}
+		if m := tracker.GetTrackingMetrics(); m.ConfirmationLatency == nil || m.ConfirmationLatency.MaxFrames != hits {
+			t.Errorf("tracking metrics latency = %+v", m.ConfirmationLatency)
+		}
+	}
+}
+
+func TestTracker_ConfirmationLatencyCountsMissedFrames(t *testing.T) {
+	tracker := newLifecycleTestTracker(nil)
+	// Tentative tracks restart their hit count after a miss: hits on
+	// frames 0, 2, 3, 4 confirm on frame 4.
+	driveTrack(t, tracker, 0, 6, map[int]bool{1: true})
+	lat := confirmedTrack(t, tracker).Confirmation
+	if lat.Frames != 5 || math.Abs(lat.Secs-0.4) > 1e-9 {
+		t.Errorf("confirmed after %d frames, %.3f s; want 5 frames, 0.4 s", lat.Frames, lat.Secs)
+	}
+}
+
+// TestTracker_ConfirmationLatencyEntryEstimate checks the time from scene
+// entry to first detection is traced back to the birth zone's edge.
+func TestTracker_ConfirmationLatencyEntryEstimate(t *testing.T) {
+	tracker := newLifecycleTestTracker(&LifecycleZoneConfig{BirthZones: edgeZones})
+	// Enters the west zone at x=-20 and is first seen at x=-18, 0.2 s later.
+	driveTrack(t, tracker, -18, 5, nil)
+
+	lat := confirmedTrack(t, tracker).Confirmation
+	if lat.BirthZone != "west" || lat.EntrySecs == nil {
+		t.Fatalf("latency = %+v, want an entry estimate for the west zone", lat)
+	}
+	if math.Abs(*lat.EntrySecs-0.2) > 0.01 {
+		t.Errorf("entry to detection = %.3f s, want 0.2", *lat.EntrySecs)
+	}
+	sum := tracker.ConfirmationLatencySummary()
+	if sum.EntryEstimated != 1 || math.Abs(sum.MeanEntrySecs-0.2) > 0.01 || sum.Penalised != 0 {
+		t.Errorf("summary = %+v", sum)
+	}
+
+	// A mid-scene birth is penalised and has no entry estimate.
+	tracker = newLifecycleTestTracker(&LifecycleZoneConfig{BirthZones: edgeZones})
+	driveTrack(t, tracker, -5, 8, nil)
+	lat = confirmedTrack(t, tracker).Confirmation
+	if lat.Frames != 6 || lat.BirthZone != "" || lat.EntrySecs != nil {
+		t.Errorf("penalised latency = %+v, want 6 frames and no entry estimate", lat)
+	}
+	if sum := tracker.ConfirmationLatencySummary(); sum.Penalised != 1 || sum.EntryEstimated != 0 {
+		t.Errorf("penalised summary = %+v", sum)
+	}
+}
+
+func TestConfirmationLatencyStats_Summary(t *testing.T) {
+	if (ConfirmationLatencyStats{}).summary(3) != nil {
+		t.Error("summary of no confirmations should be nil")
+	}
+	s := ConfirmationLatencyStats{Count: 20, FrameCounts: map[int]int{3: 15, 4: 4, 9: 1}, SecsSum: 6, SecsMax: 0.8}
+	sum := s.summary(3)
+	if sum.P50Frames != 3 || sum.P95Frames != 4 || sum.MaxFrames != 9 || sum.MeanFrames != 3.5 || sum.MeanSecs != 0.3 {
+		t.Errorf("summary = %+v", sum)
+	}
+}
@@ -0,0 +1,128 @@
+package l5tracks
+
+import (
+	"math"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
+)
+
+var carPrior = &DimensionPriorConfig{Priors: map[string]DimensionPrior{
+	"car": {Length: 4.5, Width: 1.8, Height: 1.5, FullConfidencePoints: 60},
+}}
+
+// carCluster is a car-sized observation at x with the given box and point
+// count, heading along +X.
+func carCluster(x float32, length, width, height float32, points int) WorldCluster {
+	return WorldCluster{
+		CentroidX:   x,
+		CentroidY:   0,
+		PointsCount: points,
+		SensorID:    "test",
+		OBB: &l4perception.OrientedBoundingBox{
+			CenterX: x, Length: length, Width: width, Height: height,
+		},
+	}
+}
+
+func near(a, b float32) bool { return math.Abs(float64(a-b)) < 1e-3 }
+
+// TestTracker_DimensionPrior_RegularisesOccludedFrame follows a car driving
+// along X. A frame where it is half hidden is pulled toward the car prior
+// in proportion to how sparse it is, while well-observed frames report
+// exactly what was measured and the raw box is always kept.
+func TestTracker_DimensionPrior_RegularisesOccludedFrame(t *testing.T) {
+	config := DefaultTrackerConfig()
+	config.DimensionPriors = carPrior
+	tracker := NewTracker(config)
+
+	tracker.Update([]WorldCluster{carCluster(0, 4.2, 1.7, 1.4, 80)}, lifecycleFrameTime(0))
+	active := tracker.GetActiveTracks()
+	if len(active) != 1 {
+		t.Fatalf("active tracks = %d, want 1", len(active))
+	}
+	id := active[0].TrackID
+	track := tracker.Tracks[id]
+	track.ObjectClass = "car"
+
+	// A strong observation overrides the prior.
+	tracker.Update([]WorldCluster{carCluster(1, 4.2, 1.7, 1.4, 80)}, lifecycleFrameTime(1))
+	l, w, h := track.ReportedDimensions()
+	if !track.DimensionPriorApplied || track.DimensionConfidence != 1 {
+		t.Fatalf("strong frame: applied=%v confidence=%.2f, want the prior applied at full confidence",
+			track.DimensionPriorApplied, track.DimensionConfidence)
+	}
+	if !near(l, track.OBBLength) || !near(w, track.OBBWidth) || !near(h, track.OBBHeight) {
+		t.Errorf("strong frame reported %.2f×%.2f×%.2f, want the measured %.2f×%.2f×%.2f",
+			l, w, h, track.OBBLength, track.OBBWidth, track.OBBHeight)
+	}
+
+	// Occluded: 15 of 60 points and a box a fraction of the car.
+	tracker.Update([]WorldCluster{carCluster(2, 1.5, 0.6, 0.8, 15)}, lifecycleFrameTime(2))
+	if track.DimensionConfidence != 0.25 {
+		t.Fatalf("occluded confidence = %.2f, want 0.25", track.DimensionConfidence)
+	}
+	if !near(track.OBBHeight, 0.8) {
+		t.Errorf("raw height = %.2f, want the measured 0.8 kept", track.OBBHeight)
+	}
+	l, w, h = track.ReportedDimensions()
+	for _, d := range []struct {
+		name               string
+		got, raw, priorDim float32
+	}{
+		{"length", l, track.OBBLength, 4.5},
+		{"width", w, track.OBBWidth, 1.8},
+		{"height", h, track.OBBHeight, 1.5},
+	} {
+		want := 0.25*d.raw + 0.75*d.priorDim
+		if !near(d.got, want) {
+			t.Errorf("occluded %s = %.3f, want %.3f (raw %.2f)", d.name, d.got, want, d.raw)
+		}
+	}
+	if h < 1.3 {
+		t.Errorf("occluded height %.2f should stay car-like", h)
+	}
+
+	// Fully seen again: the measurement wins outright.
+	tracker.Update([]WorldCluster{carCluster(3, 4.3, 1.8, 1.45, 70)}, lifecycleFrameTime(3))
+	if _, _, h := track.ReportedDimensions(); !near(h, 1.45) {
+		t.Errorf("recovered height = %.2f, want the measured 1.45", h)
+	}
+}
+
+func TestTracker_DimensionPrior_UnlistedOrUnclassified(t *testing.T) {
+	config := DefaultTrackerConfig()
+	config.DimensionPriors = carPrior
+	tracker := NewTracker(config)
+
+	tracker.Update([]WorldCluster{carCluster(0, 0.4, 0.4, 1.0, 5)}, lifecycleFrameTime(0))
+	track := tracker.Tracks[tracker.GetActiveTracks()[0].TrackID]
+
+	// No class yet.
+	tracker.Update([]WorldCluster{carCluster(0.1, 0.4, 0.4, 1.0, 5)}, lifecycleFrameTime(1))
+	if track.DimensionPriorApplied {
+		t.Error("unclassified track should report its measured box")
+	}
+
+	// A class without a prior.
+	track.ObjectClass = "pedestrian"
+	tracker.Update([]WorldCluster{carCluster(0.2, 0.4, 0.4, 1.0, 5)}, lifecycleFrameTime(2))
+	if _, _, h := track.ReportedDimensions(); track.DimensionPriorApplied || !near(h, 1.0) {
+		t.Errorf("pedestrian without a prior: applied=%v height=%.2f, want the measured 1.0", track.DimensionPriorApplied, h)
+	}
+}
+
+func TestDimensionPrior_ObservationConfidence(t *testing.T) {
+	p := DimensionPrior{FullConfidencePoints: 40}
+	for _, tc := range []struct {
+		points int
+		want   float32
+	}{{0, 0}, {10, 0.25}, {40, 1}, {100, 1}} {
+		if got := p.observationConfidence(tc.points); got != tc.want {
+			t.Errorf("observationConfidence(%d) = %.2f, want %.2f", tc.points, got, tc.want)
+		}
+	}
+	if got := (DimensionPrior{}).observationConfidence(1); got != 1 {
+		t.Errorf("without FullConfidencePoints confidence = %.2f, want 1", got)
+	}
+}
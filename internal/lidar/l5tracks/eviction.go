@@ -0,0 +1,137 @@
+package l5tracks
+
+import "sort"
+
+// EvictionPolicy selects which track is dropped when a new cluster would
+// take the tracker past TrackerConfig.MaxTracks.
+type EvictionPolicy string
+
+const (
+	// EvictionPolicyNone refuses new tracks while the tracker is at the cap,
+	// so unassociated clusters are dropped. This is the default.
+	EvictionPolicyNone EvictionPolicy = ""
+	// EvictionPolicyLowestQuality evicts the track with the lowest
+	// evictionQuality: ghosts, then tentative tracks, then confirmed tracks,
+	// each ranked by observation count and detection reliability.
+	EvictionPolicyLowestQuality EvictionPolicy = "lowest_quality"
+	// EvictionPolicyOldestCoasting evicts the track that has coasted the
+	// longest (most consecutive misses), falling back to the lowest-quality
+	// track when none is coasting.
+	EvictionPolicyOldestCoasting EvictionPolicy = "oldest_coasting"
+)
+
+// ParseEvictionPolicy maps a tuning eviction_policy value to an
+// EvictionPolicy; "none" and unknown values give EvictionPolicyNone.
+func ParseEvictionPolicy(s string) EvictionPolicy {
+	switch p := EvictionPolicy(s); p {
+	case EvictionPolicyLowestQuality, EvictionPolicyOldestCoasting:
+		return p
+	default:
+		return EvictionPolicyNone
+	}
+}
+
+// String returns the tuning name of the policy.
+func (p EvictionPolicy) String() string {
+	if p == EvictionPolicyNone {
+		return "none"
+	}
+	return string(p)
+}
+
+// evictionQuality scores a track for eviction in [0, 2): confirmed tracks
+// score at least 1 and ghosts score 0. Within a state the score grows with
+// observation count, saturating at a few multiples of hitsToConfirm, and is
+// scaled by DetectionReliability so tracks that keep coasting rank lower.
+func (track *TrackedObject) evictionQuality(hitsToConfirm int) float32 {
+	if track.GhostOf != "" {
+		return 0
+	}
+	if hitsToConfirm < 1 {
+		hitsToConfirm = 1
+	}
+	n := float32(track.ObservationCount)
+	q := track.DetectionReliability() * n / (n + float32(hitsToConfirm))
+	if track.TrackState == TrackConfirmed {
+		q++
+	}
+	return q
+}
+
+// activeTrackCount returns the number of tracks not yet deleted. Deleted
+// tracks awaiting cleanup do not count against MaxTracks.
+func (t *Tracker) activeTrackCount() int {
+	n := 0
+	for _, track := range t.Tracks {
+		if track.TrackState != TrackDeleted {
+			n++
+		}
+	}
+	return n
+}
+
+// evictionCandidate returns the active track the configured policy would
+// evict, or nil if the policy is EvictionPolicyNone or no track is
+// eligible. Tracks in exempt (those created this frame) are skipped so new
+// clusters cannot evict each other. Ties are broken by track ID so the
+// choice is deterministic.
+func (t *Tracker) evictionCandidate(exempt map[string]bool) *TrackedObject {
+	policy := t.Config.EvictionPolicy
+	if policy != EvictionPolicyLowestQuality && policy != EvictionPolicyOldestCoasting {
+		return nil
+	}
+
+	candidates := make([]*TrackedObject, 0, len(t.Tracks))
+	for id, track := range t.Tracks {
+		if track.TrackState != TrackDeleted && !exempt[id] {
+			candidates = append(candidates, track)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	hits := t.Config.HitsToConfirm
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if policy == EvictionPolicyOldestCoasting && a.Misses != b.Misses {
+			return a.Misses > b.Misses
+		}
+		qa, qb := a.evictionQuality(hits), b.evictionQuality(hits)
+		if qa != qb {
+			return qa < qb
+		}
+		return a.TrackID < b.TrackID
+	})
+	return candidates[0]
+}
+
+// evictTrack marks track deleted to make room for a new one.
+func (t *Tracker) evictTrack(track *TrackedObject, nowNanos int64) {
+	quality := track.evictionQuality(t.Config.HitsToConfirm)
+	track.TrackState = TrackDeleted
+	track.EndUnixNanos = nowNanos
+	t.TracksEvicted++
+	diagf("Track evicted: track_id=%s policy=%s quality=%.3f misses=%d observations=%d",
+		track.TrackID, t.Config.EvictionPolicy, quality, track.Misses, track.ObservationCount)
+}
+
+// logTrackCap reports a frame's cap pressure. The operator log notes when
+// the tracker starts and stops hitting MaxTracks; each limited frame is
+// logged at diagnostic level.
+func (t *Tracker) logTrackCap(evicted, dropped int) {
+	if evicted == 0 && dropped == 0 {
+		if t.capLimited {
+			t.capLimited = false
+			opsf("Track cap cleared: max_tracks=%d tracks_evicted_total=%d", t.Config.MaxTracks, t.TracksEvicted)
+		}
+		return
+	}
+	if !t.capLimited {
+		t.capLimited = true
+		opsf("Track cap reached: max_tracks=%d policy=%q evicted=%d dropped_clusters=%d",
+			t.Config.MaxTracks, t.Config.EvictionPolicy, evicted, dropped)
+	}
+	diagf("Track cap limited frame: evicted=%d dropped_clusters=%d tracks_evicted_total=%d",
+		evicted, dropped, t.TracksEvicted)
+}
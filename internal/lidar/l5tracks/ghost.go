@@ -0,0 +1,235 @@
+package l5tracks
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// Multipath ghost suppression.
+//
+// A flat reflective surface such as a metal fence acts as a mirror: the
+// sensor sees a phantom copy of a real object at the object's mirror image
+// behind the surface, moving with the mirrored velocity. The pass below
+// looks for concurrent track pairs that match that geometry frame after
+// frame and demotes the far-side track. It is deliberately conservative:
+// both tracks must be observed (not coasting) and moving, the sensor's line
+// of sight to the ghost must cross the reflector, and the match must hold
+// for MinMatchedFrames consecutive observed frames.
+
+// Reflector is a vertical reflective surface, given as a line segment in
+// the tracker's world XY frame.
+type Reflector struct {
+	X1 float32 `json:"x1"`
+	Y1 float32 `json:"y1"`
+	X2 float32 `json:"x2"`
+	Y2 float32 `json:"y2"`
+}
+
+// GhostSuppressionConfig configures multipath ghost suppression. The zero
+// value disables it; zero tolerances and thresholds take the defaults
+// listed below when Enabled is set.
+type GhostSuppressionConfig struct {
+	Enabled    bool        `json:"-"`
+	Reflectors []Reflector `json:"reflectors"`
+
+	// Sensor position in the world frame; (0, 0) in sensor frame.
+	SensorX float32 `json:"sensor_x,omitempty"`
+	SensorY float32 `json:"sensor_y,omitempty"`
+
+	PositionToleranceM   float32 `json:"position_tolerance_m,omitempty"`   // Mirrored position vs ghost position (default 1.0)
+	VelocityToleranceMps float32 `json:"velocity_tolerance_mps,omitempty"` // Mirrored velocity vs ghost velocity (default 1.0)
+	MinSpeedMps          float32 `json:"min_speed_mps,omitempty"`          // Both tracks must move at least this fast (default 1.0)
+	MinMatchedFrames     int     `json:"min_matched_frames,omitempty"`     // Consecutive matching frames before demotion (default 5)
+}
+
+const (
+	defaultGhostPositionToleranceM   = 1.0
+	defaultGhostVelocityToleranceMps = 1.0
+	defaultGhostMinSpeedMps          = 1.0
+	defaultGhostMinMatchedFrames     = 5
+)
+
+func (c GhostSuppressionConfig) withDefaults() GhostSuppressionConfig {
+	if c.PositionToleranceM <= 0 {
+		c.PositionToleranceM = defaultGhostPositionToleranceM
+	}
+	if c.VelocityToleranceMps <= 0 {
+		c.VelocityToleranceMps = defaultGhostVelocityToleranceMps
+	}
+	if c.MinSpeedMps <= 0 {
+		c.MinSpeedMps = defaultGhostMinSpeedMps
+	}
+	if c.MinMatchedFrames <= 0 {
+		c.MinMatchedFrames = defaultGhostMinMatchedFrames
+	}
+	return c
+}
+
+// Validate checks the reflectors and thresholds.
+func (c GhostSuppressionConfig) Validate() error {
+	if len(c.Reflectors) == 0 {
+		return fmt.Errorf("at least one reflector is required")
+	}
+	for i, r := range c.Reflectors {
+		if r.X1 == r.X2 && r.Y1 == r.Y2 {
+			return fmt.Errorf("reflector %d: endpoints must differ", i)
+		}
+	}
+	if c.PositionToleranceM < 0 || c.VelocityToleranceMps < 0 || c.MinSpeedMps < 0 || c.MinMatchedFrames < 0 {
+		return fmt.Errorf("tolerances, min speed and min matched frames must be >= 0")
+	}
+	return nil
+}
+
+// LoadGhostSuppressionConfig reads reflectors and thresholds from a JSON
+// file, e.g.
+//
+//	{"reflectors": [{"x1": -20, "y1": 8, "x2": 20, "y2": 8}], "min_matched_frames": 5}
+//
+// and returns the config enabled.
+func LoadGhostSuppressionConfig(path string) (*GhostSuppressionConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg GhostSuppressionConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse ghost reflectors %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("ghost reflectors %s: %w", path, err)
+	}
+	cfg.Enabled = true
+	return &cfg, nil
+}
+
+// mirror reflects point (px, py) and vector (vx, vy) across the reflector's
+// line. ok is false for a degenerate (zero-length) reflector.
+func (r Reflector) mirror(px, py, vx, vy float64) (mx, my, mvx, mvy float64, ok bool) {
+	dx, dy := float64(r.X2-r.X1), float64(r.Y2-r.Y1)
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return 0, 0, 0, 0, false
+	}
+	dx, dy = dx/length, dy/length
+
+	// Point: A + 2·proj − v, with v relative to the segment start A.
+	rx, ry := px-float64(r.X1), py-float64(r.Y1)
+	dot := rx*dx + ry*dy
+	mx = float64(r.X1) + 2*dot*dx - rx
+	my = float64(r.Y1) + 2*dot*dy - ry
+
+	// Vector: same reflection without the translation.
+	dot = vx*dx + vy*dy
+	return mx, my, 2*dot*dx - vx, 2*dot*dy - vy, true
+}
+
+// side returns the sign of (x, y) relative to the reflector's line.
+func (r Reflector) side(x, y float64) float64 {
+	return float64(r.X2-r.X1)*(y-float64(r.Y1)) - float64(r.Y2-r.Y1)*(x-float64(r.X1))
+}
+
+// crosses reports whether the segment (ax, ay)→(bx, by) crosses the reflector.
+func (r Reflector) crosses(ax, ay, bx, by float64) bool {
+	if r.side(ax, ay)*r.side(bx, by) >= 0 {
+		return false
+	}
+	d1 := cross2(bx-ax, by-ay, float64(r.X1)-ax, float64(r.Y1)-ay)
+	d2 := cross2(bx-ax, by-ay, float64(r.X2)-ax, float64(r.Y2)-ay)
+	return d1*d2 < 0
+}
+
+func cross2(ax, ay, bx, by float64) float64 { return ax*by - ay*bx }
+
+// isGhostOf reports whether ghost is a mirror image of real across r as
+// seen from the sensor at (sx, sy).
+func (c GhostSuppressionConfig) isGhostOf(ghost, real *TrackedObject, r Reflector) bool {
+	sx, sy := float64(c.SensorX), float64(c.SensorY)
+	gx, gy := float64(ghost.X), float64(ghost.Y)
+	rx, ry := float64(real.X), float64(real.Y)
+
+	// The real object is on the sensor's side and the ghost is seen
+	// through the reflector.
+	if r.side(rx, ry)*r.side(sx, sy) <= 0 || !r.crosses(sx, sy, gx, gy) {
+		return false
+	}
+	mx, my, mvx, mvy, ok := r.mirror(rx, ry, float64(real.VX), float64(real.VY))
+	if !ok {
+		return false
+	}
+	if math.Hypot(mx-gx, my-gy) > float64(c.PositionToleranceM) {
+		return false
+	}
+	return math.Hypot(mvx-float64(ghost.VX), mvy-float64(ghost.VY)) <= float64(c.VelocityToleranceMps)
+}
+
+// suppressGhosts runs the ghost pass over tracks observed this frame.
+// Caller must hold t.mu.
+func (t *Tracker) suppressGhosts(observed map[string]bool) {
+	if t.Config.GhostSuppression == nil {
+		return
+	}
+	cfg := *t.Config.GhostSuppression
+	if !cfg.Enabled || len(cfg.Reflectors) == 0 {
+		return
+	}
+	cfg = cfg.withDefaults()
+	minSpeed := float64(cfg.MinSpeedMps)
+	moving := func(tr *TrackedObject) bool {
+		return math.Hypot(float64(tr.VX), float64(tr.VY)) >= minSpeed
+	}
+
+	// Walk tracks in ID order so the partner found for a ghost with
+	// several candidates does not depend on map iteration order.
+	ids := make([]string, 0, len(observed))
+	for id := range observed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, ghostID := range ids {
+		ghost := t.Tracks[ghostID]
+		if ghost.GhostOf != "" || !moving(ghost) {
+			continue
+		}
+		partner := ""
+		for _, realID := range ids {
+			real := t.Tracks[realID]
+			if realID == ghostID || real.GhostOf != "" || !moving(real) {
+				continue
+			}
+			for _, r := range cfg.Reflectors {
+				if cfg.isGhostOf(ghost, real, r) {
+					partner = realID
+					break
+				}
+			}
+			if partner != "" {
+				break
+			}
+		}
+
+		if partner == "" || partner != ghost.ghostCandidate {
+			ghost.ghostCandidate = partner
+			ghost.ghostMatchFrames = 0
+		}
+		if partner == "" {
+			continue
+		}
+		ghost.ghostMatchFrames++
+		if ghost.ghostMatchFrames < cfg.MinMatchedFrames {
+			continue
+		}
+
+		prevState := ghost.TrackState
+		ghost.GhostOf = partner
+		if ghost.TrackState == TrackConfirmed {
+			ghost.TrackState = TrackTentative
+		}
+		diagf("Track demoted as multipath ghost: track_id=%s ghost_of=%s previous_state=%s frames=%d",
+			ghost.TrackID, partner, prevState, ghost.ghostMatchFrames)
+	}
+}
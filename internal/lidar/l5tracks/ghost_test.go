@@ -0,0 +1,183 @@
+package l5tracks
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReflector_Mirror(t *testing.T) {
+	// Diagonal reflector y = x: (3, 1) ↔ (1, 3), velocity (2, 0) ↔ (0, 2).
+	r := Reflector{X1: 0, Y1: 0, X2: 10, Y2: 10}
+	mx, my, mvx, mvy, ok := r.mirror(3, 1, 2, 0)
+	if !ok {
+		t.Fatal("expected a valid reflector")
+	}
+	for _, c := range []struct{ got, want float64 }{{mx, 1}, {my, 3}, {mvx, 0}, {mvy, 2}} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Fatalf("mirror = (%v, %v) v=(%v, %v), want (1, 3) v=(0, 2)", mx, my, mvx, mvy)
+		}
+	}
+	if _, _, _, _, ok := (Reflector{X1: 1, Y1: 1, X2: 1, Y2: 1}).mirror(0, 0, 0, 0); ok {
+		t.Error("zero-length reflector should be rejected")
+	}
+}
+
+// runMirrorScene drives three cars past a fence along y = 10 with the sensor
+// at the origin: a real car at y = 6, its multipath mirror at y = 14, and an
+// unrelated car at y = 20 driving the other way. It returns the active
+// tracks keyed by their nominal lane.
+func runMirrorScene(t *testing.T, ghost *GhostSuppressionConfig) map[string]*TrackedObject {
+	t.Helper()
+	config := DefaultTrackerConfig()
+	config.HitsToConfirm = 3
+	config.GhostSuppression = ghost
+	tracker := NewTracker(config)
+
+	now := time.Unix(1_700_000_000, 0)
+	const speed = 5.0 // m/s
+	for frame := 0; frame < 30; frame++ {
+		dt := float32(frame) * 0.1
+		x := -10 + speed*dt
+		tracker.Update([]WorldCluster{
+			{CentroidX: x, CentroidY: 6, SensorID: "test"},
+			{CentroidX: x, CentroidY: 14, SensorID: "test"},
+			{CentroidX: 15 - speed*dt, CentroidY: 20, SensorID: "test"},
+		}, now.Add(time.Duration(frame)*100*time.Millisecond))
+	}
+
+	byLane := make(map[string]*TrackedObject)
+	for _, tr := range tracker.GetActiveTracks() {
+		switch {
+		case math.Abs(float64(tr.Y-6)) < 1:
+			byLane["real"] = tr
+		case math.Abs(float64(tr.Y-14)) < 1:
+			byLane["ghost"] = tr
+		case math.Abs(float64(tr.Y-20)) < 1:
+			byLane["other"] = tr
+		}
+	}
+	if len(byLane) != 3 {
+		t.Fatalf("expected three tracks, got %d", len(byLane))
+	}
+	return byLane
+}
+
+func TestTracker_GhostSuppression_DemotesOnlyTheMirror(t *testing.T) {
+	tracks := runMirrorScene(t, &GhostSuppressionConfig{
+		Enabled:    true,
+		Reflectors: []Reflector{{X1: -50, Y1: 10, X2: 50, Y2: 10}},
+	})
+
+	if real := tracks["real"]; real.TrackState != TrackConfirmed || real.GhostOf != "" {
+		t.Errorf("real track should stay confirmed, got state=%s ghost_of=%q", real.TrackState, real.GhostOf)
+	}
+	if other := tracks["other"]; other.TrackState != TrackConfirmed || other.GhostOf != "" {
+		t.Errorf("unrelated far-side track should stay confirmed, got state=%s ghost_of=%q", other.TrackState, other.GhostOf)
+	}
+	ghost := tracks["ghost"]
+	if ghost.TrackState != TrackTentative {
+		t.Errorf("ghost should be demoted to tentative, got %s", ghost.TrackState)
+	}
+	if ghost.GhostOf != tracks["real"].TrackID {
+		t.Errorf("ghost_of = %q, want the real track %q", ghost.GhostOf, tracks["real"].TrackID)
+	}
+}
+
+func TestTracker_GhostSuppression_OffByDefault(t *testing.T) {
+	for lane, tr := range runMirrorScene(t, nil) {
+		if tr.TrackState != TrackConfirmed || tr.GhostOf != "" {
+			t.Errorf("%s: expected a confirmed track with suppression off, got state=%s ghost_of=%q",
+				lane, tr.TrackState, tr.GhostOf)
+		}
+	}
+}
+
+func TestTracker_GhostSuppression_ReflectorMustBeInLineOfSight(t *testing.T) {
+	// The same fence, but only 10 m long and well off to the side: the
+	// sensor's line of sight to the far-side car never crosses it.
+	tracks := runMirrorScene(t, &GhostSuppressionConfig{
+		Enabled:    true,
+		Reflectors: []Reflector{{X1: 40, Y1: 10, X2: 50, Y2: 10}},
+	})
+	if ghost := tracks["ghost"]; ghost.GhostOf != "" {
+		t.Errorf("track seen without crossing the reflector was demoted as ghost of %q", ghost.GhostOf)
+	}
+}
+
+func TestTracker_GhostSuppression_PartnerIsDeterministic(t *testing.T) {
+	// Two fences, y = 10 and y = 12, make the car at y = 14 a mirror of
+	// both the car at y = 6 and the car at y = 10. The partner must not
+	// depend on map iteration order: the lower track ID wins every run.
+	for run := 0; run < 20; run++ {
+		config := DefaultTrackerConfig()
+		config.HitsToConfirm = 3
+		config.GhostSuppression = &GhostSuppressionConfig{
+			Enabled: true,
+			Reflectors: []Reflector{
+				{X1: -50, Y1: 10, X2: 50, Y2: 10},
+				{X1: -50, Y1: 12, X2: 50, Y2: 12},
+			},
+		}
+		tracker := NewTracker(config)
+
+		now := time.Unix(1_700_000_000, 0)
+		for frame := 0; frame < 30; frame++ {
+			x := -10 + 5*float32(frame)*0.1
+			tracker.Update([]WorldCluster{
+				{CentroidX: x, CentroidY: 6, SensorID: "test"},
+				{CentroidX: x, CentroidY: 10, SensorID: "test"},
+				{CentroidX: x, CentroidY: 14, SensorID: "test"},
+			}, now.Add(time.Duration(frame)*100*time.Millisecond))
+		}
+
+		var ghost *TrackedObject
+		var reals []string
+		for _, tr := range tracker.GetActiveTracks() {
+			if math.Abs(float64(tr.Y-14)) < 1 {
+				ghost = tr
+			} else {
+				reals = append(reals, tr.TrackID)
+			}
+		}
+		if ghost == nil || len(reals) != 2 {
+			t.Fatalf("run %d: expected a ghost and two real tracks", run)
+		}
+		want := min(reals[0], reals[1])
+		if ghost.GhostOf != want {
+			t.Fatalf("run %d: ghost_of = %q, want %q", run, ghost.GhostOf, want)
+		}
+	}
+}
+
+func TestLoadGhostSuppressionConfig(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "reflectors.json")
+	if err := os.WriteFile(good, []byte(`{"reflectors":[{"x1":-20,"y1":8,"x2":20,"y2":8}],"min_matched_frames":3}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadGhostSuppressionConfig(good)
+	if err != nil {
+		t.Fatalf("LoadGhostSuppressionConfig: %v", err)
+	}
+	if !cfg.Enabled || len(cfg.Reflectors) != 1 || cfg.Reflectors[0].X2 != 20 || cfg.MinMatchedFrames != 3 {
+		t.Errorf("loaded config = %+v", cfg)
+	}
+
+	for name, body := range map[string]string{
+		"no reflectors": `{"reflectors":[]}`,
+		"zero length":   `{"reflectors":[{"x1":1,"y1":1,"x2":1,"y2":1}]}`,
+		"negative":      `{"reflectors":[{"x1":0,"y1":0,"x2":1,"y2":0}],"min_speed_mps":-1}`,
+		"bad json":      `{"reflectors":`,
+	} {
+		path := filepath.Join(dir, "bad.json")
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadGhostSuppressionConfig(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
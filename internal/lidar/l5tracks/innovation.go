@@ -0,0 +1,119 @@
+package l5tracks
+
+import "sort"
+
+// Kalman innovation diagnostics.
+//
+// The innovation y = z − Hx̂ is the gap between a cluster measurement and
+// the track's predicted position, and S = HPHᵀ + R is its predicted
+// covariance. The normalised innovation squared NIS = yᵀS⁻¹y follows a
+// chi-square distribution with two degrees of freedom when the process and
+// measurement noise match reality, so its mean should sit near 2 and about
+// 95% of samples inside [NISLower95, NISUpper95]. A mean well above 2 means
+// the filter is over-confident (noise set too low); well below 2 means it
+// is under-confident (noise set too high).
+
+const (
+	// MaxInnovationSamples bounds the innovations kept per track.
+	MaxInnovationSamples = 100
+
+	// NISLower95 and NISUpper95 are the 2.5% and 97.5% quantiles of the
+	// chi-square distribution with two degrees of freedom.
+	NISLower95 = 0.0506
+	NISUpper95 = 7.3778
+)
+
+// InnovationSample is one Kalman update's innovation.
+type InnovationSample struct {
+	TimestampNanos int64   `json:"timestamp_ns"`
+	InnovationX    float32 `json:"innovation_x"` // Measured minus predicted X (metres)
+	InnovationY    float32 `json:"innovation_y"` // Measured minus predicted Y (metres)
+	NIS            float32 `json:"nis"`          // yᵀS⁻¹y
+}
+
+// NISSummary summarises the NIS of a set of innovation samples.
+type NISSummary struct {
+	Samples        int     `json:"samples"`
+	MeanNIS        float32 `json:"mean_nis"`
+	FractionInBand float32 `json:"fraction_in_band"` // Share of samples within [NISLower95, NISUpper95]
+}
+
+// TrackInnovationDiagnostics holds one track's recorded innovations.
+type TrackInnovationDiagnostics struct {
+	TrackID string `json:"track_id"`
+	State   string `json:"state"`
+	NISSummary
+	Innovations []InnovationSample `json:"innovations,omitempty"`
+}
+
+// InnovationDiagnostics is the tracker-wide innovation export.
+type InnovationDiagnostics struct {
+	Enabled   bool    `json:"enabled"`
+	BandLower float32 `json:"band_lower"`
+	BandUpper float32 `json:"band_upper"`
+	NISSummary
+	PerTrack []TrackInnovationDiagnostics `json:"per_track"`
+}
+
+// normalisedInnovationSquared returns yᵀS⁻¹y for innovation (yX, yY) and
+// inverse innovation covariance invS (row-major 2x2).
+func normalisedInnovationSquared(yX, yY, invS00, invS01, invS10, invS11 float32) float32 {
+	return yX*(invS00*yX+invS01*yY) + yY*(invS10*yX+invS11*yY)
+}
+
+// recordInnovation appends a sample, keeping the most recent
+// MaxInnovationSamples.
+func (track *TrackedObject) recordInnovation(s InnovationSample) {
+	track.innovations = append(track.innovations, s)
+	if len(track.innovations) > MaxInnovationSamples {
+		track.innovations = track.innovations[len(track.innovations)-MaxInnovationSamples:]
+	}
+}
+
+// add folds samples into the summary.
+func (s *NISSummary) add(samples []InnovationSample) {
+	if len(samples) == 0 {
+		return
+	}
+	total := s.MeanNIS * float32(s.Samples)
+	inBand := s.FractionInBand * float32(s.Samples)
+	for _, sample := range samples {
+		total += sample.NIS
+		if sample.NIS >= NISLower95 && sample.NIS <= NISUpper95 {
+			inBand++
+		}
+	}
+	s.Samples += len(samples)
+	s.MeanNIS = total / float32(s.Samples)
+	s.FractionInBand = inBand / float32(s.Samples)
+}
+
+// GetInnovationDiagnostics summarises the innovations recorded on tracks
+// not yet cleaned up, ordered by track ID. With includeSamples the
+// per-update samples are copied out as well. Tracks are only recorded while
+// TrackerConfig.RecordInnovations is set.
+func (t *Tracker) GetInnovationDiagnostics(includeSamples bool) InnovationDiagnostics {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	diag := InnovationDiagnostics{
+		Enabled:   t.Config.RecordInnovations,
+		BandLower: NISLower95,
+		BandUpper: NISUpper95,
+		PerTrack:  []TrackInnovationDiagnostics{},
+	}
+	for _, track := range t.Tracks {
+		if len(track.innovations) == 0 {
+			continue
+		}
+		per := TrackInnovationDiagnostics{TrackID: track.TrackID, State: string(track.TrackState)}
+		per.add(track.innovations)
+		if includeSamples {
+			per.Innovations = append([]InnovationSample(nil), track.innovations...)
+		}
+		diag.add(track.innovations)
+		diag.PerTrack = append(diag.PerTrack, per)
+	}
+	sort.Slice(diag.PerTrack, func(i, j int) bool { return diag.PerTrack[i].TrackID < diag.PerTrack[j].TrackID })
+	return diag
+}
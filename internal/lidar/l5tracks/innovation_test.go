@@ -0,0 +1,117 @@
+package l5tracks
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNormalisedInnovationSquared(t *testing.T) {
+	// S = diag(0.5, 2): NIS = 1²/0.5 + 2²/2 = 4.
+	if got := normalisedInnovationSquared(1, 2, 2, 0, 0, 0.5); math.Abs(float64(got-4)) > 1e-6 {
+		t.Errorf("diagonal: NIS = %v, want 4", got)
+	}
+	// Correlated S = [[2, 1], [1, 2]], S⁻¹ = [[2, -1], [-1, 2]] / 3; y = (1, 1): NIS = 2/3.
+	if got := normalisedInnovationSquared(1, 1, 2.0/3, -1.0/3, -1.0/3, 2.0/3); math.Abs(float64(got-2.0/3)) > 1e-6 {
+		t.Errorf("correlated: NIS = %v, want 2/3", got)
+	}
+}
+
+// runSyntheticTrack drives one target through the tracker with its motion
+// and measurements drawn from the process and measurement noise in
+// config, i.e. a target the filter models exactly, measured with
+// measurementNoise. It returns the innovation diagnostics.
+func runSyntheticTrack(t *testing.T, config TrackerConfig, measurementNoise float64, frames int) InnovationDiagnostics {
+	t.Helper()
+	config.RecordInnovations = true
+	tracker := NewTracker(config)
+
+	const dt = 0.1
+	rng := rand.New(rand.NewSource(7))
+	posSigma := math.Sqrt(float64(config.ProcessNoisePos) * dt)
+	velSigma := math.Sqrt(float64(config.ProcessNoiseVel) * dt)
+	measSigma := math.Sqrt(measurementNoise)
+
+	x, y, vx, vy := 0.0, 0.0, 5.0, 1.0
+	now := time.Unix(1_700_000_000, 0)
+	for frame := 0; frame < frames; frame++ {
+		if frame > 0 {
+			x += vx*dt + rng.NormFloat64()*posSigma
+			y += vy*dt + rng.NormFloat64()*posSigma
+			vx += rng.NormFloat64() * velSigma
+			vy += rng.NormFloat64() * velSigma
+		}
+		cluster := WorldCluster{
+			ClusterID: int64(frame),
+			CentroidX: float32(x + rng.NormFloat64()*measSigma),
+			CentroidY: float32(y + rng.NormFloat64()*measSigma),
+			CentroidZ: 0.75, BoundingBoxLength: 4.5, BoundingBoxWidth: 1.8, BoundingBoxHeight: 1.5,
+			PointsCount: 200, HeightP95: 1.4,
+		}
+		tracker.Update([]WorldCluster{cluster}, now.Add(time.Duration(frame)*100*time.Millisecond))
+	}
+	if tracker.TracksCreated != 1 {
+		t.Fatalf("TracksCreated = %d, want the synthetic target on one track", tracker.TracksCreated)
+	}
+	return tracker.GetInnovationDiagnostics(true)
+}
+
+func TestInnovationDiagnostics_SyntheticTrack(t *testing.T) {
+	config := DefaultTrackerConfig()
+	config.MaxTrackHistoryLength = 10
+
+	t.Run("consistent filter sits in the chi-square band", func(t *testing.T) {
+		diag := runSyntheticTrack(t, config, float64(config.MeasurementNoise), 500)
+		if !diag.Enabled || len(diag.PerTrack) != 1 {
+			t.Fatalf("enabled=%v tracks=%d, want one recorded track", diag.Enabled, len(diag.PerTrack))
+		}
+		track := diag.PerTrack[0]
+		// Only the last MaxInnovationSamples updates are kept, which also
+		// leaves out the updates before the covariance settles.
+		if track.Samples != MaxInnovationSamples || len(track.Innovations) != MaxInnovationSamples {
+			t.Errorf("samples=%d innovations=%d, want the last %d updates",
+				track.Samples, len(track.Innovations), MaxInnovationSamples)
+		}
+		// E[NIS] = 2 for two degrees of freedom.
+		if track.MeanNIS < 1.5 || track.MeanNIS > 2.5 {
+			t.Errorf("mean NIS = %.2f, want about 2", track.MeanNIS)
+		}
+		if track.FractionInBand < 0.85 {
+			t.Errorf("fraction in 95%% band = %.2f, want about 0.95", track.FractionInBand)
+		}
+	})
+
+	t.Run("over-confident filter has high NIS", func(t *testing.T) {
+		// The measurement variance is 2.5x what the filter assumes.
+		diag := runSyntheticTrack(t, config, 2.5*float64(config.MeasurementNoise), 500)
+		if mean := diag.PerTrack[0].MeanNIS; mean < 3.5 {
+			t.Errorf("mean NIS = %.2f, want well above 2 for under-stated measurement noise", mean)
+		}
+	})
+}
+
+func TestInnovationDiagnostics_Disabled(t *testing.T) {
+	tracker := NewTracker(DefaultTrackerConfig())
+	now := time.Unix(1_700_000_000, 0)
+	for frame := 0; frame < 5; frame++ {
+		tracker.Update([]WorldCluster{{CentroidX: 0.5 * float32(frame), CentroidY: 2,
+			BoundingBoxLength: 4.5, BoundingBoxWidth: 1.8, PointsCount: 100}},
+			now.Add(time.Duration(frame)*100*time.Millisecond))
+	}
+	diag := tracker.GetInnovationDiagnostics(true)
+	if diag.Enabled || diag.Samples != 0 || len(diag.PerTrack) != 0 {
+		t.Errorf("diagnostics = %+v, want nothing recorded by default", diag)
+	}
+}
+
+func TestRecordInnovation_Bounded(t *testing.T) {
+	track := &TrackedObject{}
+	for i := 0; i < MaxInnovationSamples+10; i++ {
+		track.recordInnovation(InnovationSample{TimestampNanos: int64(i)})
+	}
+	if len(track.innovations) != MaxInnovationSamples || track.innovations[0].TimestampNanos != 10 {
+		t.Errorf("kept %d samples starting at %d, want the last %d",
+			len(track.innovations), track.innovations[0].TimestampNanos, MaxInnovationSamples)
+	}
+}
@@ -0,0 +1,158 @@
+package l5tracks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Birth and death zones.
+//
+// Real objects enter and leave the scene at its edges: the road ends, a
+// driveway, the edge of the sensor's view. A track born in the middle of
+// the scene is usually a split fragment, a reflection or background noise,
+// and a confirmed track lost in the middle is usually occluded rather than
+// gone. Birth zones make mid-scene births prove themselves (or forbid
+// them), and death zones give mid-scene losses a longer coast so the
+// object can be re-acquired by the same track.
+
+// LifecycleZone is an axis-aligned box in the tracker's world XY frame,
+// in metres.
+type LifecycleZone struct {
+	ID   string  `json:"id"`
+	MinX float32 `json:"min_x"`
+	MinY float32 `json:"min_y"`
+	MaxX float32 `json:"max_x"`
+	MaxY float32 `json:"max_y"`
+}
+
+// Contains reports whether (x, y) lies inside the zone, edges included.
+func (z LifecycleZone) Contains(x, y float32) bool {
+	return x >= z.MinX && x <= z.MaxX && y >= z.MinY && y <= z.MaxY
+}
+
+// BirthPolicy chooses what happens to a cluster that would start a track
+// outside every birth zone.
+type BirthPolicy string
+
+const (
+	// BirthPolicyPenalise starts the track but requires
+	// BirthPenaltyHits more hits before it is confirmed (default).
+	BirthPolicyPenalise BirthPolicy = "penalise"
+	// BirthPolicyDisallow starts no track.
+	BirthPolicyDisallow BirthPolicy = "disallow"
+)
+
+// LifecycleZoneConfig configures birth and death zones. An empty zone list
+// leaves that half of the lifecycle unrestricted; zero counts take the
+// defaults listed below.
+type LifecycleZoneConfig struct {
+	BirthZones  []LifecycleZone `json:"birth_zones,omitempty"`
+	BirthPolicy BirthPolicy     `json:"birth_policy,omitempty"`
+	// Extra consecutive hits a track born outside the birth zones needs
+	// before confirmation (default: HitsToConfirm, doubling the hits).
+	BirthPenaltyHits int `json:"birth_penalty_hits,omitempty"`
+
+	DeathZones []LifecycleZone `json:"death_zones,omitempty"`
+	// Extra misses a confirmed track whose predicted position is outside
+	// the death zones may coast before deletion (default:
+	// MaxMissesConfirmed, doubling the coast).
+	ExtraCoastMisses int `json:"extra_coast_misses,omitempty"`
+}
+
+// Validate checks the zone boxes, IDs and policy.
+func (c LifecycleZoneConfig) Validate() error {
+	switch c.BirthPolicy {
+	case "", BirthPolicyPenalise, BirthPolicyDisallow:
+	default:
+		return fmt.Errorf("birth policy must be %s or %s, got %q",
+			BirthPolicyPenalise, BirthPolicyDisallow, c.BirthPolicy)
+	}
+	if c.BirthPenaltyHits < 0 || c.ExtraCoastMisses < 0 {
+		return fmt.Errorf("birth penalty hits and extra coast misses must be >= 0")
+	}
+	for _, zones := range []struct {
+		kind  string
+		zones []LifecycleZone
+	}{{"birth", c.BirthZones}, {"death", c.DeathZones}} {
+		seen := make(map[string]bool, len(zones.zones))
+		for i, z := range zones.zones {
+			if z.ID == "" {
+				return fmt.Errorf("%s zone %d: id is required", zones.kind, i)
+			}
+			if seen[z.ID] {
+				return fmt.Errorf("%s zone %q: duplicate id", zones.kind, z.ID)
+			}
+			seen[z.ID] = true
+			if z.MaxX <= z.MinX || z.MaxY <= z.MinY {
+				return fmt.Errorf("%s zone %q: max must exceed min on both axes", zones.kind, z.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadLifecycleZoneConfig reads a LifecycleZoneConfig from a JSON file.
+func LoadLifecycleZoneConfig(path string) (*LifecycleZoneConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg LifecycleZoneConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse lifecycle zones %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("lifecycle zones %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// inAnyZone reports whether (x, y) lies in one of zones.
+func inAnyZone(zones []LifecycleZone, x, y float32) bool {
+	for _, z := range zones {
+		if z.Contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// birthAllowed decides whether a cluster at (x, y) may start a track and
+// how many extra hits that track needs before confirmation.
+func (t *Tracker) birthAllowed(x, y float32) (allowed bool, penaltyHits int) {
+	lz := t.Config.LifecycleZones
+	if lz == nil || len(lz.BirthZones) == 0 || inAnyZone(lz.BirthZones, x, y) {
+		return true, 0
+	}
+	if lz.BirthPolicy == BirthPolicyDisallow {
+		return false, 0
+	}
+	penaltyHits = lz.BirthPenaltyHits
+	if penaltyHits <= 0 {
+		penaltyHits = t.Config.HitsToConfirm
+	}
+	return true, penaltyHits
+}
+
+// maxMissesFor returns the consecutive misses track may reach before it is
+// deleted: MaxMisses while tentative, MaxMissesConfirmed once confirmed,
+// plus the extra coast for a confirmed track lost outside the death zones.
+func (t *Tracker) maxMissesFor(track *TrackedObject) int {
+	maxMisses := t.Config.MaxMisses
+	if track.TrackState != TrackConfirmed {
+		return maxMisses
+	}
+	if t.Config.MaxMissesConfirmed > 0 {
+		maxMisses = t.Config.MaxMissesConfirmed
+	}
+	lz := t.Config.LifecycleZones
+	if lz == nil || len(lz.DeathZones) == 0 || inAnyZone(lz.DeathZones, track.X, track.Y) {
+		return maxMisses
+	}
+	extra := lz.ExtraCoastMisses
+	if extra <= 0 {
+		extra = maxMisses
+	}
+	return maxMisses + extra
+}
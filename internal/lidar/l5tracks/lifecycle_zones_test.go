@@ -0,0 +1,183 @@
+package l5tracks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// edgeZones are the two ends of a road running along X from -20 to 20.
+var edgeZones = []LifecycleZone{
+	{ID: "west", MinX: -20, MinY: -5, MaxX: -15, MaxY: 5},
+	{ID: "east", MinX: 15, MinY: -5, MaxX: 20, MaxY: 5},
+}
+
+func newLifecycleTestTracker(zones *LifecycleZoneConfig) *Tracker {
+	config := DefaultTrackerConfig()
+	config.HitsToConfirm = 3
+	config.MaxMisses = 3
+	config.MaxMissesConfirmed = 5
+	config.LifecycleZones = zones
+	return NewTracker(config)
+}
+
+func lifecycleFrameTime(frame int) time.Time {
+	return time.Unix(1_700_000_000, 0).Add(time.Duration(frame) * 100 * time.Millisecond)
+}
+
+func TestTracker_LifecycleZones_SuppressesMidSceneBirth(t *testing.T) {
+	mid := WorldCluster{CentroidX: 0, CentroidY: 2, SensorID: "test"}
+	edge := WorldCluster{CentroidX: -18, CentroidY: 0, SensorID: "test"}
+
+	t.Run("disallow", func(t *testing.T) {
+		tracker := newLifecycleTestTracker(&LifecycleZoneConfig{BirthZones: edgeZones, BirthPolicy: BirthPolicyDisallow})
+		for frame := 0; frame < 5; frame++ {
+			tracker.Update([]WorldCluster{mid, edge}, lifecycleFrameTime(frame))
+		}
+		active := tracker.GetActiveTracks()
+		if len(active) != 1 {
+			t.Fatalf("active tracks = %d, want only the edge track", len(active))
+		}
+		if active[0].X > -15 || active[0].TrackState != TrackConfirmed {
+			t.Errorf("surviving track at x=%.1f state=%s, want the confirmed edge track", active[0].X, active[0].TrackState)
+		}
+	})
+
+	t.Run("penalise", func(t *testing.T) {
+		tracker := newLifecycleTestTracker(&LifecycleZoneConfig{BirthZones: edgeZones})
+		// A spurious blob that holds for twice HitsToConfirm minus one
+		// frames would be confirmed without zones, but not here.
+		for frame := 0; frame < 5; frame++ {
+			tracker.Update([]WorldCluster{mid, edge}, lifecycleFrameTime(frame))
+		}
+		for _, tr := range tracker.GetActiveTracks() {
+			inEdge := tr.X < -15
+			if inEdge && tr.TrackState != TrackConfirmed {
+				t.Errorf("edge track should be confirmed, got %s", tr.TrackState)
+			}
+			if !inEdge && tr.TrackState != TrackTentative {
+				t.Errorf("mid-scene track should still be tentative after 5 hits, got %s", tr.TrackState)
+			}
+		}
+		// An object that persists is still confirmed, only later.
+		tracker.Update([]WorldCluster{mid, edge}, lifecycleFrameTime(5))
+		if got := tracker.GetConfirmedTracks(); len(got) != 2 {
+			t.Errorf("confirmed tracks after 6 hits = %d, want 2", len(got))
+		}
+	})
+
+	t.Run("no zones", func(t *testing.T) {
+		tracker := newLifecycleTestTracker(nil)
+		for frame := 0; frame < 3; frame++ {
+			tracker.Update([]WorldCluster{mid}, lifecycleFrameTime(frame))
+		}
+		if got := tracker.GetConfirmedTracks(); len(got) != 1 {
+			t.Errorf("confirmed tracks without zones = %d, want 1", len(got))
+		}
+	})
+}
+
+// driveAndVanish drives a car east from x=-18 at 5 m/s for drive frames,
+// then withholds it for gap frames. It returns the tracker and the ID of
+// the car's track.
+func driveAndVanish(t *testing.T, zones *LifecycleZoneConfig, drive, gap int) (*Tracker, string) {
+	t.Helper()
+	tracker := newLifecycleTestTracker(zones)
+	for frame := 0; frame < drive; frame++ {
+		x := -18 + 0.5*float32(frame)
+		tracker.Update([]WorldCluster{{CentroidX: x, CentroidY: 0, SensorID: "test"}}, lifecycleFrameTime(frame))
+	}
+	confirmed := tracker.GetConfirmedTracks()
+	if len(confirmed) != 1 {
+		t.Fatalf("confirmed tracks before the gap = %d, want 1", len(confirmed))
+	}
+	for frame := drive; frame < drive+gap; frame++ {
+		tracker.Update(nil, lifecycleFrameTime(frame))
+	}
+	return tracker, confirmed[0].TrackID
+}
+
+func TestTracker_LifecycleZones_CoastsMidSceneDisappearance(t *testing.T) {
+	// The car vanishes at x≈-3, mid-scene, for one frame more than
+	// MaxMissesConfirmed.
+	const drive, gap = 30, 6
+
+	tracker, id := driveAndVanish(t, nil, drive, gap)
+	if tr := tracker.GetTrack(id); tr != nil && tr.TrackState != TrackDeleted {
+		t.Fatalf("without zones the track should be deleted after %d misses", gap)
+	}
+
+	tracker, id = driveAndVanish(t, &LifecycleZoneConfig{DeathZones: edgeZones}, drive, gap)
+	tr := tracker.GetTrack(id)
+	if tr == nil || tr.TrackState != TrackConfirmed {
+		t.Fatalf("mid-scene disappearance should still be coasting, got %+v", tr)
+	}
+	// The car reappears where it would be and is re-acquired by its track.
+	x := -18 + 0.5*float32(drive+gap)
+	tracker.Update([]WorldCluster{{CentroidX: x, CentroidY: 0, SensorID: "test"}}, lifecycleFrameTime(drive+gap))
+	if tr = tracker.GetTrack(id); tr.Misses != 0 || len(tracker.GetActiveTracks()) != 1 {
+		t.Errorf("reappearing car should be re-associated: misses=%d active=%d", tr.Misses, len(tracker.GetActiveTracks()))
+	}
+
+	// Extended coast ends too: MaxMissesConfirmed plus ExtraCoastMisses.
+	for frame := drive + gap + 1; frame < drive+gap+12; frame++ {
+		tracker.Update(nil, lifecycleFrameTime(frame))
+	}
+	if tr = tracker.GetTrack(id); tr != nil && tr.TrackState != TrackDeleted {
+		t.Errorf("track should be deleted after the extended coast, got %s", tr.TrackState)
+	}
+}
+
+func TestTracker_LifecycleZones_EdgeDeathNotExtended(t *testing.T) {
+	// The car leaves through the east zone: x≈16.5 when it vanishes.
+	tracker, id := driveAndVanish(t, &LifecycleZoneConfig{DeathZones: edgeZones}, 70, 5)
+	if tr := tracker.GetTrack(id); tr != nil && tr.TrackState != TrackDeleted {
+		t.Errorf("track lost in a death zone should be deleted after MaxMissesConfirmed, got %s", tr.TrackState)
+	}
+}
+
+func TestLifecycleZoneConfig_Validate(t *testing.T) {
+	bad := []LifecycleZoneConfig{
+		{BirthPolicy: "drop"},
+		{BirthPenaltyHits: -1},
+		{ExtraCoastMisses: -1},
+		{BirthZones: []LifecycleZone{{MaxX: 1, MaxY: 1}}},
+		{DeathZones: []LifecycleZone{{ID: "z", MinX: 1, MaxX: 0, MaxY: 1}}},
+		{DeathZones: []LifecycleZone{{ID: "z", MaxX: 1, MaxY: 1}, {ID: "z", MaxX: 1, MaxY: 1}}},
+	}
+	for i, cfg := range bad {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("config %d: expected validation error", i)
+		}
+	}
+	ok := LifecycleZoneConfig{BirthZones: edgeZones, DeathZones: edgeZones, BirthPolicy: BirthPolicyDisallow}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("same IDs in birth and death zones should be valid: %v", err)
+	}
+}
+
+func TestLoadLifecycleZoneConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zones.json")
+	data := `{"birth_zones":[{"id":"west","min_x":-20,"min_y":-5,"max_x":-15,"max_y":5}],"birth_policy":"disallow","extra_coast_misses":10}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadLifecycleZoneConfig(path)
+	if err != nil {
+		t.Fatalf("LoadLifecycleZoneConfig: %v", err)
+	}
+	if len(cfg.BirthZones) != 1 || cfg.BirthPolicy != BirthPolicyDisallow || cfg.ExtraCoastMisses != 10 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if !cfg.BirthZones[0].Contains(-15, 5) || cfg.BirthZones[0].Contains(-14.9, 0) {
+		t.Error("zone edges should be inclusive")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"birth_policy":"never"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLifecycleZoneConfig(path); err == nil {
+		t.Error("expected an invalid birth policy to be rejected")
+	}
+}
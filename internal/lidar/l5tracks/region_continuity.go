@@ -0,0 +1,230 @@
+package l5tracks
+
+import (
+	"math"
+	"sort"
+)
+
+// Region-aware track continuity.
+//
+// The background grid tunes its parameters per region, so the same object
+// can be segmented differently on either side of a region boundary. A
+// vehicle straddling the boundary, or idling on it, then often arrives as
+// two clusters split along the boundary: one fragment keeps the existing
+// track and the other spawns a new one, and the shrunken box on the track
+// unsettles its class. The pass below rejoins such fragments before
+// association. It only joins clusters that lie in different regions, sit
+// within MergeGapM of each other, together span no more than
+// MaxMergedSpanM, and have an existing track predicted near their joint
+// centre, so neighbouring objects within one region are never merged.
+
+// RegionLookup maps a world XY position to a region ID, or -1 when the
+// position is outside every region or regions are not yet identified.
+type RegionLookup interface {
+	RegionAt(x, y float32) int
+}
+
+// RegionContinuityConfig configures region-aware fragment merging. The zero
+// value disables it; zero thresholds take the defaults listed below when
+// Enabled is set.
+type RegionContinuityConfig struct {
+	Enabled bool
+	Regions RegionLookup
+
+	MergeGapM      float32 // Largest gap between fragment extents (default 1.0)
+	MaxMergedSpanM float32 // Largest extent of the joined cluster (default 8.0)
+	MergeGateM     float32 // Joined centre vs a track's predicted position (default 2.0)
+}
+
+const (
+	defaultRegionMergeGapM      = 1.0
+	defaultRegionMaxMergedSpanM = 8.0
+	defaultRegionMergeGateM     = 2.0
+)
+
+func (c RegionContinuityConfig) withDefaults() RegionContinuityConfig {
+	if c.MergeGapM <= 0 {
+		c.MergeGapM = defaultRegionMergeGapM
+	}
+	if c.MaxMergedSpanM <= 0 {
+		c.MaxMergedSpanM = defaultRegionMaxMergedSpanM
+	}
+	if c.MergeGateM <= 0 {
+		c.MergeGateM = defaultRegionMergeGateM
+	}
+	return c
+}
+
+// regionFragmentPair is a candidate pair of cluster fragments to join.
+type regionFragmentPair struct {
+	i, j   int
+	gap    float32
+	merged WorldCluster
+}
+
+// mergeRegionFragments joins cluster fragments split across a region
+// boundary. It returns the clusters to associate and, for each of them, the
+// indices of the input clusters it covers. With continuity disabled, or
+// nothing to join, it returns clusters unchanged and a nil origin.
+func (t *Tracker) mergeRegionFragments(clusters []WorldCluster) ([]WorldCluster, [][]int) {
+	rc := t.Config.RegionContinuity
+	if rc == nil || !rc.Enabled || rc.Regions == nil || len(clusters) < 2 {
+		return clusters, nil
+	}
+	cfg := rc.withDefaults()
+
+	regions := make([]int, len(clusters))
+	for i := range clusters {
+		regions[i] = cfg.Regions.RegionAt(clusters[i].CentroidX, clusters[i].CentroidY)
+	}
+
+	var pairs []regionFragmentPair
+	for i := range clusters {
+		for j := i + 1; j < len(clusters); j++ {
+			if regions[i] < 0 || regions[j] < 0 || regions[i] == regions[j] {
+				continue
+			}
+			merged, gap, span := joinFragments(clusters[i], clusters[j])
+			if gap > cfg.MergeGapM || span > cfg.MaxMergedSpanM {
+				continue
+			}
+			if !t.trackNear(merged.CentroidX, merged.CentroidY, cfg.MergeGateM) {
+				continue
+			}
+			pairs = append(pairs, regionFragmentPair{i: i, j: j, gap: gap, merged: merged})
+		}
+	}
+	if len(pairs) == 0 {
+		return clusters, nil
+	}
+
+	// Join the closest pairs first; each cluster joins at most once.
+	sort.Slice(pairs, func(a, b int) bool {
+		if pairs[a].gap != pairs[b].gap {
+			return pairs[a].gap < pairs[b].gap
+		}
+		if pairs[a].i != pairs[b].i {
+			return pairs[a].i < pairs[b].i
+		}
+		return pairs[a].j < pairs[b].j
+	})
+	partner := make(map[int]int)
+	mergedAt := make(map[int]WorldCluster)
+	for _, p := range pairs {
+		if _, taken := partner[p.i]; taken {
+			continue
+		}
+		if _, taken := partner[p.j]; taken {
+			continue
+		}
+		partner[p.i], partner[p.j] = p.j, p.i
+		mergedAt[p.i] = p.merged
+		diagf("Region fragments merged: cluster_ids=%d,%d regions=%d,%d gap=%.2f",
+			clusters[p.i].ClusterID, clusters[p.j].ClusterID, regions[p.i], regions[p.j], p.gap)
+	}
+
+	out := make([]WorldCluster, 0, len(clusters)-len(mergedAt))
+	origin := make([][]int, 0, cap(out))
+	for i := range clusters {
+		j, joined := partner[i]
+		switch {
+		case !joined:
+			out = append(out, clusters[i])
+			origin = append(origin, []int{i})
+		case i < j:
+			out = append(out, mergedAt[i])
+			origin = append(origin, []int{i, j})
+		}
+	}
+	return out, origin
+}
+
+// trackNear reports whether an active track's predicted position lies
+// within gate metres of (x, y).
+func (t *Tracker) trackNear(x, y, gate float32) bool {
+	for _, track := range t.Tracks {
+		if track.TrackState == TrackDeleted {
+			continue
+		}
+		dx, dy := track.X-x, track.Y-y
+		if dx*dx+dy*dy <= gate*gate {
+			return true
+		}
+	}
+	return false
+}
+
+// joinFragments builds the cluster covering fragments a and b. gap is the
+// free space between the fragments along the line joining their centroids
+// and span is the joined extent along that line. The joined centre is the
+// middle of that span rather than the point-weighted centroid, which would
+// lean towards the denser fragment.
+func joinFragments(a, b WorldCluster) (merged WorldCluster, gap, span float32) {
+	dx, dy := float64(b.CentroidX-a.CentroidX), float64(b.CentroidY-a.CentroidY)
+	dist := math.Hypot(dx, dy)
+	var ux, uy float64
+	if dist > 0 {
+		ux, uy = dx/dist, dy/dist
+	}
+	extA, extB := halfExtent(a, ux, uy), halfExtent(b, ux, uy)
+	gap = float32(dist - extA - extB)
+	span = float32(dist + extA + extB)
+	// Span runs from extA behind a to extB beyond b; its middle is offset
+	// from a by (dist + extB - extA) / 2.
+	offset := (dist + extB - extA) / 2
+
+	// Identity and debug hints come from the larger fragment.
+	merged = a
+	if b.PointsCount > a.PointsCount {
+		merged = b
+	}
+	total := float32(a.PointsCount + b.PointsCount)
+	wa, wb := float32(0.5), float32(0.5)
+	if total > 0 {
+		wa, wb = float32(a.PointsCount)/total, float32(b.PointsCount)/total
+	}
+
+	merged.CentroidX = a.CentroidX + float32(offset*ux)
+	merged.CentroidY = a.CentroidY + float32(offset*uy)
+	merged.CentroidZ = wa*a.CentroidZ + wb*b.CentroidZ
+	merged.BoundingBoxLength = max(span, a.BoundingBoxLength, b.BoundingBoxLength)
+	merged.BoundingBoxWidth = max(a.BoundingBoxWidth, b.BoundingBoxWidth)
+	merged.BoundingBoxHeight = max(a.BoundingBoxHeight, b.BoundingBoxHeight)
+	merged.PointsCount = a.PointsCount + b.PointsCount
+	merged.PointsCountNormalized = a.PointsCountNormalized + b.PointsCountNormalized
+	merged.HeightP95 = max(a.HeightP95, b.HeightP95)
+	merged.IntensityMean = wa*a.IntensityMean + wb*b.IntensityMean
+	merged.SamplePoints = append(append([][3]float32(nil), a.SamplePoints...), b.SamplePoints...)
+	// Neither fragment's oriented box describes the whole object.
+	merged.OBB = nil
+	return merged, gap, span
+}
+
+// halfExtent returns the half-width of cluster c's box along unit vector
+// (ux, uy). Without an oriented box the heading is unknown and half the
+// longer side is used, which can only overstate the extent.
+func halfExtent(c WorldCluster, ux, uy float64) float64 {
+	if c.OBB == nil {
+		return float64(max(c.BoundingBoxLength, c.BoundingBoxWidth)) / 2
+	}
+	sin, cos := math.Sincos(float64(c.OBB.HeadingRad))
+	along := math.Abs(ux*cos + uy*sin)
+	across := math.Abs(-ux*sin + uy*cos)
+	return (float64(c.OBB.Length)*along + float64(c.OBB.Width)*across) / 2
+}
+
+// expandAssociations maps associations over merged clusters back to the
+// input cluster indices, so GetLastAssociations stays indexed by the
+// clusters passed to Update. A nil origin means nothing was merged.
+func expandAssociations(associations []string, origin [][]int, n int) []string {
+	if origin == nil {
+		return associations
+	}
+	out := make([]string, n)
+	for k, trackID := range associations {
+		for _, i := range origin[k] {
+			out[i] = trackID
+		}
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package l5tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
+)
+
+// splitAtX is a RegionLookup with region 0 for x < X and region 1 beyond.
+type splitAtX struct{ X float32 }
+
+func (s splitAtX) RegionAt(x, _ float32) int {
+	if x < s.X {
+		return 0
+	}
+	return 1
+}
+
+// carFragment returns the piece of a 1.8 m wide car at y = 5 spanning
+// length metres along x from centre x. The OBB follows PCA: a piece shorter
+// than the car is wide turns its principal axis across the road.
+func carFragment(id int64, x, length float32, points int) WorldCluster {
+	obb := &l4perception.OrientedBoundingBox{CenterX: x, CenterY: 5, Length: length, Width: 1.8, Height: 1.5}
+	if length < 1.8 {
+		obb.Length, obb.Width, obb.HeadingRad = 1.8, length, math.Pi/2
+	}
+	return WorldCluster{ClusterID: id, CentroidX: x, CentroidY: 5, CentroidZ: 0.75,
+		BoundingBoxLength: obb.Length, BoundingBoxWidth: obb.Width, BoundingBoxHeight: 1.5,
+		PointsCount: points, HeightP95: 1.4, OBB: obb}
+}
+
+func TestJoinFragments(t *testing.T) {
+	// A 4 m car along x split at x = 10 with a 0.4 m gap: 2.5 m and
+	// 1.1 m fragments.
+	a := carFragment(1, 8.55, 2.5, 100)
+	a.BoundingBoxHeight, a.HeightP95, a.IntensityMean = 1.4, 1.3, 40
+	b := carFragment(2, 10.75, 1.1, 60)
+	b.HeightP95, b.IntensityMean = 1.45, 80
+
+	merged, gap, span := joinFragments(a, b)
+	near := func(got, want float32) bool { return math.Abs(float64(got-want)) < 1e-4 }
+	if !near(gap, 0.4) || !near(span, 4.0) {
+		t.Fatalf("gap=%v span=%v, want 0.4 and 4.0", gap, span)
+	}
+	if !near(merged.CentroidX, 9.3) || !near(merged.CentroidY, 5) {
+		t.Errorf("centre = (%v, %v), want the span middle (9.3, 5)", merged.CentroidX, merged.CentroidY)
+	}
+	if !near(merged.BoundingBoxLength, 4.0) || !near(merged.BoundingBoxWidth, 1.8) || !near(merged.BoundingBoxHeight, 1.5) {
+		t.Errorf("box = %v x %v x %v, want 4.0 x 1.8 x 1.5",
+			merged.BoundingBoxLength, merged.BoundingBoxWidth, merged.BoundingBoxHeight)
+	}
+	if merged.PointsCount != 160 || !near(merged.HeightP95, 1.45) || !near(merged.IntensityMean, 55) {
+		t.Errorf("points=%d p95=%v intensity=%v, want 160, 1.45, 55",
+			merged.PointsCount, merged.HeightP95, merged.IntensityMean)
+	}
+	if merged.ClusterID != 1 {
+		t.Errorf("ClusterID = %d, want the larger fragment's 1", merged.ClusterID)
+	}
+}
+
+func TestMergeRegionFragments(t *testing.T) {
+	fragments := []WorldCluster{
+		carFragment(1, 8.55, 2.5, 100),
+		carFragment(2, 10.75, 1.1, 60),
+		{ClusterID: 3, CentroidX: 30, CentroidY: -5, BoundingBoxLength: 0.5, BoundingBoxWidth: 0.5, PointsCount: 20},
+	}
+	trackAt := func(x, y float32) *Tracker {
+		config := DefaultTrackerConfig()
+		config.RegionContinuity = &RegionContinuityConfig{Enabled: true, Regions: splitAtX{X: 10}}
+		tracker := NewTracker(config)
+		track := &TrackedObject{X: x, Y: y}
+		track.TrackID = "track_a"
+		track.TrackState = TrackConfirmed
+		tracker.Tracks[track.TrackID] = track
+		return tracker
+	}
+
+	t.Run("joins fragments across the boundary near a track", func(t *testing.T) {
+		out, origin := trackAt(9, 5).mergeRegionFragments(fragments)
+		if len(out) != 2 || len(origin) != 2 {
+			t.Fatalf("got %d clusters, want 2", len(out))
+		}
+		if len(origin[0]) != 2 || origin[0][0] != 0 || origin[0][1] != 1 || len(origin[1]) != 1 || origin[1][0] != 2 {
+			t.Fatalf("origin = %v, want [[0 1] [2]]", origin)
+		}
+		got := expandAssociations([]string{"track_a", ""}, origin, len(fragments))
+		if got[0] != "track_a" || got[1] != "track_a" || got[2] != "" {
+			t.Errorf("expanded associations = %q, want both fragments on track_a", got)
+		}
+	})
+
+	t.Run("no track near the joined centre", func(t *testing.T) {
+		if out, origin := trackAt(20, 5).mergeRegionFragments(fragments); len(out) != 3 || origin != nil {
+			t.Errorf("got %d clusters, want the input unchanged", len(out))
+		}
+	})
+
+	t.Run("same region", func(t *testing.T) {
+		tracker := trackAt(9, 5)
+		tracker.Config.RegionContinuity.Regions = splitAtX{X: 100}
+		if out, _ := tracker.mergeRegionFragments(fragments); len(out) != 3 {
+			t.Errorf("got %d clusters, want fragments in one region left alone", len(out))
+		}
+	})
+
+	t.Run("gap too wide", func(t *testing.T) {
+		tracker := trackAt(9, 5)
+		tracker.Config.RegionContinuity.MergeGapM = 0.2
+		if out, _ := tracker.mergeRegionFragments(fragments); len(out) != 3 {
+			t.Errorf("got %d clusters, want a 0.4 m gap over a 0.2 m limit left alone", len(out))
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tracker := trackAt(9, 5)
+		tracker.Config.RegionContinuity = nil
+		if out, origin := tracker.mergeRegionFragments(fragments); len(out) != 3 || origin != nil {
+			t.Errorf("got %d clusters, want the input unchanged", len(out))
+		}
+	})
+}
+
+func TestTracker_RegionContinuityAssociations(t *testing.T) {
+	config := DefaultTrackerConfig()
+	config.RegionContinuity = &RegionContinuityConfig{Enabled: true, Regions: splitAtX{X: 10}}
+	tracker := NewTracker(config)
+
+	now := time.Unix(1_700_000_000, 0)
+	tracker.Update([]WorldCluster{carFragment(0, 9.3, 4, 160)}, now)
+
+	// The next frame arrives split across the boundary.
+	tracker.Update([]WorldCluster{carFragment(1, 8.55, 2.5, 100), carFragment(2, 10.75, 1.1, 60)},
+		now.Add(100*time.Millisecond))
+
+	assoc := tracker.GetLastAssociations()
+	if len(assoc) != 2 || assoc[0] == "" || assoc[0] != assoc[1] {
+		t.Fatalf("associations = %q, want both fragments on the existing track", assoc)
+	}
+	if tracker.TracksCreated != 1 {
+		t.Errorf("TracksCreated = %d, want 1", tracker.TracksCreated)
+	}
+}
@@ -0,0 +1,102 @@
+package l5tracks
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Road-relative velocity.
+//
+// Tracks carry velocity in the tracker's world axes. For a known road the
+// useful numbers are how fast an object moves along it and how fast it
+// drifts across it, so a RoadAxis decomposes velocity into those two
+// components without downstream trigonometry.
+
+// RoadPoint is a position in the tracker's world XY frame, in metres.
+type RoadPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// RoadAxis is the direction of a road in the tracker's world XY frame.
+// Give either HeadingDeg or both From and To.
+type RoadAxis struct {
+	// HeadingDeg is the positive along-road direction, in degrees
+	// anticlockwise from +X (the convention of track headings).
+	HeadingDeg *float64 `json:"heading_deg,omitempty"`
+	// From and To are two reference points on the road; travel from From
+	// towards To is positive along-road.
+	From *RoadPoint `json:"from,omitempty"`
+	To   *RoadPoint `json:"to,omitempty"`
+}
+
+// RoadVelocity is a velocity decomposed against a RoadAxis, in m/s.
+type RoadVelocity struct {
+	// AlongMps is positive in the axis direction.
+	AlongMps float32 `json:"along_mps"`
+	// CrossMps is positive to the left of the axis direction.
+	CrossMps float32 `json:"cross_mps"`
+	// SpeedMps is the magnitude of the decomposed velocity.
+	SpeedMps float32 `json:"speed_mps"`
+}
+
+// Validate checks that exactly one form of the axis is given and that the
+// reference points are distinct.
+func (a RoadAxis) Validate() error {
+	points := a.From != nil || a.To != nil
+	switch {
+	case a.HeadingDeg != nil && points:
+		return fmt.Errorf("road axis: give heading_deg or from/to, not both")
+	case a.HeadingDeg != nil:
+		if math.IsNaN(*a.HeadingDeg) || math.IsInf(*a.HeadingDeg, 0) {
+			return fmt.Errorf("road axis: heading_deg must be finite")
+		}
+		return nil
+	case a.From == nil || a.To == nil:
+		return fmt.Errorf("road axis: heading_deg or both from and to are required")
+	case a.From.X == a.To.X && a.From.Y == a.To.Y:
+		return fmt.Errorf("road axis: from and to must differ")
+	}
+	return nil
+}
+
+// LoadRoadAxis reads a RoadAxis from a JSON file.
+func LoadRoadAxis(path string) (*RoadAxis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var axis RoadAxis
+	if err := json.Unmarshal(data, &axis); err != nil {
+		return nil, fmt.Errorf("parse road axis %s: %w", path, err)
+	}
+	if err := axis.Validate(); err != nil {
+		return nil, fmt.Errorf("road axis %s: %w", path, err)
+	}
+	return &axis, nil
+}
+
+// unit returns the unit vector of the axis direction.
+func (a RoadAxis) unit() (ux, uy float64) {
+	if a.HeadingDeg != nil {
+		rad := *a.HeadingDeg * math.Pi / 180
+		return math.Cos(rad), math.Sin(rad)
+	}
+	dx, dy := a.To.X-a.From.X, a.To.Y-a.From.Y
+	n := math.Hypot(dx, dy)
+	return dx / n, dy / n
+}
+
+// Decompose splits the world-frame velocity (vx, vy) into along-road and
+// cross-road components.
+func (a RoadAxis) Decompose(vx, vy float32) RoadVelocity {
+	ux, uy := a.unit()
+	x, y := float64(vx), float64(vy)
+	return RoadVelocity{
+		AlongMps: float32(x*ux + y*uy),
+		CrossMps: float32(ux*y - uy*x),
+		SpeedMps: float32(math.Hypot(x, y)),
+	}
+}
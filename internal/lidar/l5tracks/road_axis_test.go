@@ -0,0 +1,75 @@
+package l5tracks
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoadAxis_DecomposeDiagonalVelocity(t *testing.T) {
+	heading := 30.0
+	axes := map[string]RoadAxis{
+		"heading": {HeadingDeg: &heading},
+		"points":  {From: &RoadPoint{X: 10, Y: 5}, To: &RoadPoint{X: 10 + math.Sqrt(3), Y: 6}},
+	}
+	// 10 m/s at 75° is 45° left of a road heading 30°.
+	vx, vy := float32(10*math.Cos(75*math.Pi/180)), float32(10*math.Sin(75*math.Pi/180))
+	want := 10 * math.Sqrt2 / 2
+	for name, axis := range axes {
+		if err := axis.Validate(); err != nil {
+			t.Fatalf("%s: Validate: %v", name, err)
+		}
+		got := axis.Decompose(vx, vy)
+		if math.Abs(float64(got.AlongMps)-want) > 1e-4 || math.Abs(float64(got.CrossMps)-want) > 1e-4 {
+			t.Errorf("%s: along=%.4f cross=%.4f, want %.4f and %.4f", name, got.AlongMps, got.CrossMps, want, want)
+		}
+		if math.Abs(float64(got.SpeedMps)-10) > 1e-4 {
+			t.Errorf("%s: speed=%.4f, want 10", name, got.SpeedMps)
+		}
+	}
+
+	// Travelling against the axis and drifting right are both negative.
+	got := axes["heading"].Decompose(-vx, -vy)
+	if got.AlongMps >= 0 || got.CrossMps >= 0 {
+		t.Errorf("reversed velocity: along=%.4f cross=%.4f, want both negative", got.AlongMps, got.CrossMps)
+	}
+}
+
+func TestRoadAxis_Validate(t *testing.T) {
+	heading, nan := 90.0, math.NaN()
+	p := &RoadPoint{X: 1, Y: 2}
+	bad := []RoadAxis{
+		{},
+		{From: p},
+		{From: p, To: &RoadPoint{X: 1, Y: 2}},
+		{HeadingDeg: &heading, From: p, To: &RoadPoint{}},
+		{HeadingDeg: &nan},
+	}
+	for i, axis := range bad {
+		if err := axis.Validate(); err == nil {
+			t.Errorf("axis %d: expected validation error", i)
+		}
+	}
+}
+
+func TestLoadRoadAxis(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "road.json")
+	if err := os.WriteFile(path, []byte(`{"from":{"x":0,"y":0},"to":{"x":0,"y":50}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	axis, err := LoadRoadAxis(path)
+	if err != nil {
+		t.Fatalf("LoadRoadAxis: %v", err)
+	}
+	if got := axis.Decompose(1, 4); got.AlongMps != 4 || got.CrossMps != -1 {
+		t.Errorf("along=%v cross=%v, want 4 and -1", got.AlongMps, got.CrossMps)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"heading_deg":10,"from":{"x":0,"y":0}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRoadAxis(path); err == nil {
+		t.Error("expected a mixed axis to be rejected")
+	}
+}
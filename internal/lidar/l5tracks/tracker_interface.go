@@ -10,11 +10,12 @@ type TrackerInterface interface {
 	// This is the main entry point for the tracking pipeline.
 	Update(clusters []WorldCluster, timestamp time.Time)
 
-	// GetActiveTracks returns currently active (non-deleted) tracks.
+	// GetActiveTracks returns currently active (non-deleted) tracks, in
+	// SortTracks order.
 	// Active tracks include both tentative and confirmed states.
 	GetActiveTracks() []*TrackedObject
 
-	// GetConfirmedTracks returns only confirmed tracks.
+	// GetConfirmedTracks returns only confirmed tracks, in SortTracks order.
 	// These are tracks that have accumulated sufficient hits to be reliable.
 	GetConfirmedTracks() []*TrackedObject
 
@@ -25,7 +26,8 @@ type TrackerInterface interface {
 	// Returns total, tentative, confirmed, and deleted counts.
 	GetTrackCount() (total, tentative, confirmed, deleted int)
 
-	// GetAllTracks returns all tracks including deleted ones.
+	// GetAllTracks returns all tracks including deleted ones, ordered by
+	// first observation time then track ID (see SortTracks).
 	// Useful for debugging and comprehensive state inspection.
 	GetAllTracks() []*TrackedObject
 
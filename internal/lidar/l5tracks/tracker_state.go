@@ -0,0 +1,147 @@
+package l5tracks
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+)
+
+// trackerStateVersion is the MarshalState blob format version. Bump it when
+// trackerState or trackStateV1 change incompatibly.
+const trackerStateVersion = 1
+
+// trackerStateMagic prefixes every MarshalState blob.
+const trackerStateMagic = "VRTS"
+
+// trackerState is the serialised form of a Tracker: its tracks, ID counter,
+// last frame timestamp and lifecycle/scene counters. The configuration is
+// not included; the restoring tracker keeps its own.
+type trackerState struct {
+	Version               int
+	NextTrackID           int64
+	LastUpdateNanos       int64
+	TracksCreated         int
+	TracksConfirmed       int
+	TracksEvicted         int
+	TotalForegroundPoints int64
+	ClusteredPoints       int64
+	EmptyBoxFrames        int64
+	TotalBoxFrames        int64
+	ConfirmationStats     ConfirmationLatencyStats
+	Tracks                []trackStateV1
+}
+
+// trackStateV1 carries a track's exported fields plus the unexported state
+// that gob would otherwise drop.
+type trackStateV1 struct {
+	Track            TrackedObject
+	SpeedHistory     []float32
+	RawSpeedWindow   []float32
+	GhostCandidate   string
+	GhostMatchFrames int
+	BirthPenaltyHits int
+	BirthZone        string
+	BirthX, BirthY   float32
+	Innovations      []InnovationSample
+}
+
+// MarshalState serialises the tracker's tracks (including deleted tracks
+// still in their grace period), their Kalman states and the lifecycle
+// counters to a versioned binary blob. A fresh tracker with the same
+// configuration restored from it with UnmarshalState produces the same
+// output as this one for the same subsequent frames, apart from the random
+// IDs given to tracks created after the checkpoint.
+func (t *Tracker) MarshalState() ([]byte, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	state := trackerState{
+		Version:               trackerStateVersion,
+		NextTrackID:           t.NextTrackID,
+		LastUpdateNanos:       t.LastUpdateNanos,
+		TracksCreated:         t.TracksCreated,
+		TracksConfirmed:       t.TracksConfirmed,
+		TracksEvicted:         t.TracksEvicted,
+		TotalForegroundPoints: t.TotalForegroundPoints,
+		ClusteredPoints:       t.ClusteredPoints,
+		EmptyBoxFrames:        t.EmptyBoxFrames,
+		TotalBoxFrames:        t.TotalBoxFrames,
+		ConfirmationStats:     t.ConfirmationStats,
+		Tracks:                make([]trackStateV1, 0, len(t.Tracks)),
+	}
+	for _, track := range t.Tracks {
+		state.Tracks = append(state.Tracks, trackStateV1{
+			Track:            *track,
+			SpeedHistory:     track.speedHistory,
+			RawSpeedWindow:   track.rawSpeedWindow,
+			GhostCandidate:   track.ghostCandidate,
+			GhostMatchFrames: track.ghostMatchFrames,
+			BirthPenaltyHits: track.birthPenaltyHits,
+			BirthZone:        track.birthZone,
+			BirthX:           track.birthX,
+			BirthY:           track.birthY,
+			Innovations:      track.innovations,
+		})
+	}
+	sort.Slice(state.Tracks, func(i, j int) bool {
+		return state.Tracks[i].Track.TrackID < state.Tracks[j].Track.TrackID
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString(trackerStateMagic)
+	if err := gob.NewEncoder(&buf).Encode(&state); err != nil {
+		return nil, fmt.Errorf("encode tracker state: %w", err)
+	}
+	diagf("Tracker state marshalled: tracks=%d next_track_id=%d bytes=%d",
+		len(state.Tracks), state.NextTrackID, buf.Len())
+	return buf.Bytes(), nil
+}
+
+// UnmarshalState replaces the tracker's tracks and counters with those in a
+// MarshalState blob. The configuration and debug collector are unchanged.
+// On error the tracker is left as it was.
+func (t *Tracker) UnmarshalState(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(trackerStateMagic)) {
+		return fmt.Errorf("tracker state: missing %q header", trackerStateMagic)
+	}
+	var state trackerState
+	if err := gob.NewDecoder(bytes.NewReader(data[len(trackerStateMagic):])).Decode(&state); err != nil {
+		return fmt.Errorf("decode tracker state: %w", err)
+	}
+	if state.Version != trackerStateVersion {
+		return fmt.Errorf("tracker state version %d not supported (want %d)", state.Version, trackerStateVersion)
+	}
+
+	tracks := make(map[string]*TrackedObject, len(state.Tracks))
+	for i := range state.Tracks {
+		s := &state.Tracks[i]
+		track := s.Track
+		track.speedHistory = s.SpeedHistory
+		track.rawSpeedWindow = s.RawSpeedWindow
+		track.ghostCandidate = s.GhostCandidate
+		track.ghostMatchFrames = s.GhostMatchFrames
+		track.birthPenaltyHits = s.BirthPenaltyHits
+		track.birthZone = s.BirthZone
+		track.birthX, track.birthY = s.BirthX, s.BirthY
+		track.innovations = s.Innovations
+		tracks[track.TrackID] = &track
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Tracks = tracks
+	t.NextTrackID = state.NextTrackID
+	t.LastUpdateNanos = state.LastUpdateNanos
+	t.TracksCreated = state.TracksCreated
+	t.TracksConfirmed = state.TracksConfirmed
+	t.TracksEvicted = state.TracksEvicted
+	t.TotalForegroundPoints = state.TotalForegroundPoints
+	t.ClusteredPoints = state.ClusteredPoints
+	t.EmptyBoxFrames = state.EmptyBoxFrames
+	t.TotalBoxFrames = state.TotalBoxFrames
+	t.ConfirmationStats = state.ConfirmationStats
+	t.lastAssociations = nil
+	diagf("Tracker state restored: tracks=%d next_track_id=%d", len(tracks), state.NextTrackID)
+	return nil
+}
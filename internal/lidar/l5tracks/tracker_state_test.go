@@ -0,0 +1,188 @@
+package l5tracks
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stateTestFrame returns the clusters for frame i of a synthetic scene: a
+// car crossing in X, a pedestrian walking in Y who is missed every fifth
+// frame, and short-lived objects in frames 10-24 and 45-59 so tracks are
+// created, confirmed and deleted on both sides of the checkpoint.
+func stateTestFrame(i int) []WorldCluster {
+	f := float32(i)
+	clusters := []WorldCluster{{
+		CentroidX: -20 + 1.2*f, CentroidY: 3, CentroidZ: 0.8,
+		BoundingBoxLength: 4.2, BoundingBoxWidth: 1.8, BoundingBoxHeight: 1.5,
+		PointsCount: 120, SensorID: "state-test",
+	}}
+	if i%5 != 4 {
+		clusters = append(clusters, WorldCluster{
+			CentroidX: 6, CentroidY: -8 + 0.14*f, CentroidZ: 0.9,
+			BoundingBoxLength: 0.6, BoundingBoxWidth: 0.5, BoundingBoxHeight: 1.7,
+			PointsCount: 30, SensorID: "state-test",
+		})
+	}
+	if i >= 10 && i < 25 {
+		clusters = append(clusters, WorldCluster{
+			CentroidX: 15 - 0.8*(f-10), CentroidY: 12, CentroidZ: 0.7,
+			BoundingBoxLength: 1.8, BoundingBoxWidth: 0.6, BoundingBoxHeight: 1.4,
+			PointsCount: 45, SensorID: "state-test",
+		})
+	}
+	if i >= 45 && i < 60 {
+		clusters = append(clusters, WorldCluster{
+			CentroidX: -5, CentroidY: 20 - 0.9*(f-45), CentroidZ: 0.7,
+			BoundingBoxLength: 1.8, BoundingBoxWidth: 0.6, BoundingBoxHeight: 1.4,
+			PointsCount: 45, SensorID: "state-test",
+		})
+	}
+	return clusters
+}
+
+func TestTracker_MarshalState_RestoredContinuationMatches(t *testing.T) {
+	cfg := DefaultTrackerConfig()
+	cfg.SpeedSmoothingFrames = 3
+	checkRestoredContinuation(t, cfg, 30)
+}
+
+func TestTracker_MarshalState_BirthPenaltySurvivesRestore(t *testing.T) {
+	// Only the car is born inside a birth zone. At frame 12 the object that
+	// appeared at frame 10 is tentative with two hits and still owes its
+	// birth penalty, so a restore that forgot the penalty would confirm it
+	// early.
+	cfg := DefaultTrackerConfig()
+	cfg.LifecycleZones = &LifecycleZoneConfig{
+		BirthZones: []LifecycleZone{{ID: "west", MinX: -25, MinY: -50, MaxX: -15, MaxY: 50}},
+	}
+	checkRestoredContinuation(t, cfg, 12)
+}
+
+func TestTracker_MarshalState_InnovationsSurviveRestore(t *testing.T) {
+	cfg := DefaultTrackerConfig()
+	cfg.RecordInnovations = true
+	restored := checkRestoredContinuation(t, cfg, 30)
+
+	// The car has been tracked since frame 0, so its diagnostics include
+	// innovations recorded before the checkpoint.
+	diag := restored.GetInnovationDiagnostics(true)
+	if len(diag.PerTrack) == 0 {
+		t.Fatal("restored tracker has no innovation diagnostics")
+	}
+	var oldest int64
+	for _, tr := range diag.PerTrack {
+		for _, s := range tr.Innovations {
+			if oldest == 0 || s.TimestampNanos < oldest {
+				oldest = s.TimestampNanos
+			}
+		}
+	}
+	if checkpoint := time.Unix(1_700_000_000, 0).Add(3 * time.Second).UnixNano(); oldest == 0 || oldest >= checkpoint {
+		t.Errorf("oldest innovation at %d, want one recorded before the checkpoint (%d)", oldest, checkpoint)
+	}
+}
+
+// checkRestoredContinuation runs the state test scene through one tracker
+// uninterrupted and through a second restored from the first's checkpoint,
+// and fails if their state differs on any later frame. It returns the
+// restored tracker.
+func checkRestoredContinuation(t *testing.T, cfg TrackerConfig, checkpoint int) *Tracker {
+	t.Helper()
+	const frames = 80
+	start := time.Unix(1_700_000_000, 0)
+	frameTime := func(i int) time.Time { return start.Add(time.Duration(i) * 100 * time.Millisecond) }
+
+	// Track IDs are random UUIDs; number them so tracks created after the
+	// checkpoint get the same ID in both trackers.
+	defer func(orig func(int64) string) { newTrackID = orig }(newTrackID)
+	newTrackID = func(seq int64) string { return fmt.Sprintf("trk_%d", seq) }
+
+	uninterrupted := NewTracker(cfg)
+	for i := 0; i < checkpoint; i++ {
+		uninterrupted.Update(stateTestFrame(i), frameTime(i))
+	}
+	if len(uninterrupted.GetActiveTracks()) == 0 {
+		t.Fatal("expected active tracks at the checkpoint")
+	}
+
+	blob, err := uninterrupted.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState: %v", err)
+	}
+	restored := NewTracker(cfg)
+	if err := restored.UnmarshalState(blob); err != nil {
+		t.Fatalf("UnmarshalState: %v", err)
+	}
+	again, err := restored.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState after restore: %v", err)
+	}
+	if !sameState(t, blob, again) {
+		t.Fatal("restored tracker does not re-serialise to the same blob")
+	}
+
+	for i := checkpoint; i < frames; i++ {
+		uninterrupted.Update(stateTestFrame(i), frameTime(i))
+		restored.Update(stateTestFrame(i), frameTime(i))
+
+		if a, b := uninterrupted.GetLastAssociations(), restored.GetLastAssociations(); !reflect.DeepEqual(a, b) {
+			t.Fatalf("frame %d: associations differ: %v vs %v", i, a, b)
+		}
+		want, _ := uninterrupted.MarshalState()
+		got, _ := restored.MarshalState()
+		if !sameState(t, want, got) {
+			t.Fatalf("frame %d: restored tracker state diverged", i)
+		}
+	}
+
+	if uninterrupted.TracksCreated != restored.TracksCreated || uninterrupted.NextTrackID != restored.NextTrackID {
+		t.Errorf("counters differ: created %d/%d next_id %d/%d",
+			uninterrupted.TracksCreated, restored.TracksCreated, uninterrupted.NextTrackID, restored.NextTrackID)
+	}
+	return restored
+}
+
+// sameState reports whether two MarshalState blobs hold the same state.
+// gob writes maps in random order, so equal states need not be equal bytes.
+func sameState(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	decode := func(blob []byte) trackerState {
+		var s trackerState
+		if err := gob.NewDecoder(bytes.NewReader(blob[len(trackerStateMagic):])).Decode(&s); err != nil {
+			t.Fatalf("decode tracker state: %v", err)
+		}
+		return s
+	}
+	return reflect.DeepEqual(decode(a), decode(b))
+}
+
+func TestTracker_UnmarshalState_Rejects(t *testing.T) {
+	tracker := NewTracker(DefaultTrackerConfig())
+	tracker.Update(stateTestFrame(0), time.Unix(1_700_000_000, 0))
+	before, _ := tracker.MarshalState()
+
+	if err := tracker.UnmarshalState([]byte("not a tracker state")); err == nil {
+		t.Error("expected error for a blob without the header")
+	}
+	if err := tracker.UnmarshalState([]byte(trackerStateMagic + "garbage")); err == nil {
+		t.Error("expected error for a corrupt blob")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(trackerStateMagic)
+	if err := gob.NewEncoder(&buf).Encode(trackerState{Version: trackerStateVersion + 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.UnmarshalState(buf.Bytes()); err == nil {
+		t.Error("expected error for an unsupported version")
+	}
+
+	after, _ := tracker.MarshalState()
+	if !sameState(t, before, after) {
+		t.Error("failed UnmarshalState modified the tracker")
+	}
+}
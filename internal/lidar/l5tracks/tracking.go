@@ -53,6 +53,15 @@ type TrackedObject struct {
 	// Latest Z from the associated cluster OBB (ground-level, used for rendering)
 	LatestZ float32
 
+	// Dimensions regularised toward the class prior when the observation
+	// is sparse (see dimension_prior.go); valid only while
+	// DimensionPriorApplied. OBBLength/Width/Height keep the measurement.
+	PriorLength           float32
+	PriorWidth            float32
+	PriorHeight           float32
+	DimensionConfidence   float32 // Weight of the latest measurement against the prior, [0, 1]
+	DimensionPriorApplied bool
+
 	// Track quality metrics
 	TrackLengthMeters  float32 // Total distance traveled (meters)
 	TrackDurationSecs  float32 // Total lifetime (seconds)
@@ -61,6 +70,10 @@ type TrackedObject struct {
 	SpatialCoverage    float32 // % of bounding box covered by observations
 	NoisePointRatio    float32 // Ratio of noise points to cluster points
 
+	// CoastedFrames counts frames the track coasted through before being
+	// re-associated. Misses after the last observation are not included.
+	CoastedFrames int
+
 	// Velocity-Trail Alignment Metrics
 	// Measures how well the Kalman velocity vector aligns with the actual
 	// direction of travel computed from recent trail positions. A perfectly
@@ -81,6 +94,26 @@ type TrackedObject struct {
 	SpeedJitterCount int     // Number of speed delta samples
 	PrevSpeedMps     float32 // Previous frame speed for delta computation
 
+	// Speed smoothing
+	// InstantSpeedMps is the unsmoothed Kalman speed from the latest
+	// update. AvgSpeedMps, MaxSpeedMps and the speed history use the mean
+	// over the last TrackerConfig.SpeedSmoothingFrames speeds instead.
+	InstantSpeedMps float32
+	rawSpeedWindow  []float32
+
+	// Composite speed (see composite_speed.go): the confidence-weighted
+	// mean of the per-frame Kalman speeds, the canonical reported speed.
+	CompositeSpeedMps    float32
+	CompositeWeightSum   float64 // Sum of frame weights
+	CompositeWeightedSum float64 // Sum of frame weight × speed
+
+	// Size Stability Metrics
+	// Welford second-moment accumulators for per-frame bounding box
+	// dimensions. The running means are the BoundingBox*Avg fields.
+	BBoxLengthM2 float64 // Sum of squared deviations of length (m²)
+	BBoxWidthM2  float64 // Sum of squared deviations of width (m²)
+	BBoxHeightM2 float64 // Sum of squared deviations of height (m²)
+
 	// Merge/split coherence (task 3.3)
 	// When a cluster is significantly larger than the track's historical
 	// OBB, it may be a merge of two objects. When a confirmed track's
@@ -90,6 +123,28 @@ type TrackedObject struct {
 	MergeCandidate bool   // true when current cluster area ≫ historical average
 	SplitCandidate bool   // true when current cluster area ≪ historical average while nearby new track appears
 	LinkedTrackID  string // if non-empty, the track this one was split from or merged with
+
+	// Multipath ghost suppression (see ghost.go). GhostOf is the real track
+	// this one mirrors; a ghost is held tentative and never confirmed.
+	GhostOf          string
+	ghostCandidate   string // partner matched on the previous observed frame
+	ghostMatchFrames int    // consecutive observed frames matching ghostCandidate
+
+	// birthPenaltyHits is the extra hits this track needs before
+	// confirmation because it was born outside the birth zones (see
+	// lifecycle_zones.go).
+	birthPenaltyHits int
+
+	// Confirmation is how long the track took to be confirmed; nil while
+	// tentative (see confirmation_latency.go). birthZone and birthX/Y are
+	// the birth zone and position of its first observation.
+	Confirmation   *ConfirmationLatency
+	birthZone      string
+	birthX, birthY float32
+
+	// Kalman innovations (see innovation.go), recorded only while
+	// TrackerConfig.RecordInnovations is set.
+	innovations []InnovationSample
 }
 
 // Tracker manages multi-object tracking with explicit lifecycle states.
@@ -105,6 +160,16 @@ type Tracker struct {
 	TracksCreated   int
 	TracksConfirmed int
 
+	// TracksEvicted counts tracks deleted by the EvictionPolicy to stay
+	// within MaxTracks. capLimited is set while the cap is turning away or
+	// evicting tracks, so the operator log marks only the transitions.
+	TracksEvicted int
+	capLimited    bool
+
+	// ConfirmationStats accumulates how long confirmed tracks took to be
+	// confirmed (see confirmation_latency.go).
+	ConfirmationStats ConfirmationLatencyStats
+
 	// Scene-level foreground capture accumulators.
 	// Updated via RecordFrameStats() from the tracking pipeline.
 	TotalForegroundPoints int64 // Running total of foreground points entering DBSCAN
@@ -168,6 +233,7 @@ func (t *Tracker) Reset() {
 	t.ClusteredPoints = 0
 	t.EmptyBoxFrames = 0
 	t.TotalBoxFrames = 0
+	t.capLimited = false
 	diagf("Tracker reset: cleared_tracks=%d", clearedTracks)
 }
 
@@ -178,6 +244,11 @@ func NewTracker(config TrackerConfig) *Tracker {
 		NextTrackID: 1,
 		Config:      config,
 	}
+	switch config.GatingMode {
+	case "", GatingModeIsotropic, GatingModeMahalanobis:
+	default:
+		opsf("Unknown gating mode %q, using %s", config.GatingMode, GatingModeIsotropic)
+	}
 	diagf("Tracker created: max_tracks=%d hits_to_confirm=%d max_misses=%d max_misses_confirmed=%d",
 		config.MaxTracks, config.HitsToConfirm, config.MaxMisses, config.MaxMissesConfirmed)
 	return tracker
@@ -191,22 +262,35 @@ func (t *Tracker) Update(clusters []WorldCluster, timestamp time.Time) {
 
 	nowNanos := timestamp.UnixNano()
 
-	// Compute dt (time delta since last update)
-	var dt float32
-	if t.LastUpdateNanos > 0 {
-		dt = float32(nowNanos-t.LastUpdateNanos) / 1e9 // Convert to seconds
-	} else {
-		dt = 0.1 // Default 100ms for first frame
+	// Compute dt from the actual frame timestamps, so RPM changes and
+	// dropped frames do not distort velocity. The nominal interval only
+	// covers the first frame. A duplicate or out-of-order timestamp
+	// predicts with dt 0 and leaves LastUpdateNanos alone, so tracks are
+	// not extrapolated for time that has not passed.
+	var elapsed float32
+	switch {
+	case t.LastUpdateNanos == 0:
+		elapsed = t.Config.NominalFrameDt
+		if elapsed <= 0 {
+			elapsed = DefaultNominalFrameDt
+		}
+		t.LastUpdateNanos = nowNanos
+	case nowNanos > t.LastUpdateNanos:
+		elapsed = float32(nowNanos-t.LastUpdateNanos) / 1e9 // Convert to seconds
+		t.LastUpdateNanos = nowNanos
+	default:
+		diagf("Non-increasing frame timestamp: ts=%d last=%d, using dt=0", nowNanos, t.LastUpdateNanos)
 	}
-	// Clamp dt to MaxPredictDt so throttle-induced gaps (e.g. 250 ms at
-	// 12 fps cap) don't create an inflated time step for association gating.
-	// Predict() also clamps independently, but the raw dt flows into
-	// associate() where it affects implied-speed plausibility checks
-	// (task 7.1).
+	// A gap longer than MaxPredictDt (dropped frames, throttling) is not
+	// extrapolated: the state advances by MaxPredictDt and the remainder
+	// inflates the position covariance instead (see inflateForGap), so the
+	// next measurement moves the position without yanking the velocity.
+	// The clamped dt also bounds association gating (task 7.1).
+	dt := elapsed
 	if dt > t.Config.MaxPredictDt {
 		dt = t.Config.MaxPredictDt
 	}
-	t.LastUpdateNanos = nowNanos
+	gap := elapsed - dt
 
 	if traceLogger != nil {
 		activeBefore := 0
@@ -215,20 +299,29 @@ func (t *Tracker) Update(clusters []WorldCluster, timestamp time.Time) {
 				activeBefore++
 			}
 		}
-		tracef("Update start: ts=%d clusters=%d active_tracks=%d dt=%.3f",
-			nowNanos, len(clusters), activeBefore, dt)
+		tracef("Update start: ts=%d clusters=%d active_tracks=%d dt=%.3f gap=%.3f",
+			nowNanos, len(clusters), activeBefore, dt, gap)
 	}
 
 	// Step 1: Predict all active tracks to current time
 	for _, track := range t.Tracks {
 		if track.TrackState != TrackDeleted {
 			t.predict(track, dt)
+			if gap > 0 {
+				t.inflateForGap(track, gap)
+			}
 		}
 	}
 
+	// Step 1b: Rejoin fragments split across a region boundary (off
+	// unless configured). Later steps work on the joined clusters;
+	// lastAssociations stays indexed by the clusters passed in.
+	inputCount := len(clusters)
+	clusters, origin := t.mergeRegionFragments(clusters)
+
 	// Step 2: Associate clusters to tracks using gating
 	associations := t.associate(clusters, dt)
-	t.lastAssociations = associations
+	t.lastAssociations = expandAssociations(associations, origin, inputCount)
 
 	// Step 3: Update matched tracks
 	matchedTracks := make(map[string]bool)
@@ -238,13 +331,15 @@ func (t *Tracker) Update(clusters []WorldCluster, timestamp time.Time) {
 			track := t.Tracks[trackID]
 			t.update(track, clusters[clusterIdx], nowNanos)
 			track.Hits++
+			track.CoastedFrames += track.Misses
 			track.Misses = 0
 			matchedTracks[trackID] = true
 
 			// Promote tentative → confirmed
-			if track.TrackState == TrackTentative && track.Hits >= t.Config.HitsToConfirm {
+			if track.TrackState == TrackTentative && track.GhostOf == "" && track.Hits >= t.Config.HitsToConfirm+track.birthPenaltyHits {
 				track.TrackState = TrackConfirmed
 				t.TracksConfirmed++
+				t.recordConfirmation(track, clusters[clusterIdx], nowNanos)
 				newlyConfirmed++
 				diagf("Track confirmed: track_id=%s hits=%d observations=%d cluster_id=%d",
 					track.TrackID, track.Hits, track.ObservationCount, clusters[clusterIdx].ClusterID)
@@ -277,6 +372,9 @@ func (t *Tracker) Update(clusters []WorldCluster, timestamp time.Time) {
 		track.SplitCandidate = ratio < splitSizeRatio
 	}
 
+	// Step 3c: Multipath ghost suppression (off unless configured).
+	t.suppressGhosts(matchedTracks)
+
 	// Step 4: Handle unmatched tracks with occlusion-aware coasting.
 	// Confirmed tracks are allowed more miss frames (MaxMissesConfirmed)
 	// than tentative tracks (MaxMisses). During occlusion the Kalman
@@ -321,11 +419,9 @@ func (t *Tracker) Update(clusters []WorldCluster, timestamp time.Time) {
 				}
 			}
 
-			// Determine miss limit based on track maturity.
-			maxMisses := t.Config.MaxMisses
-			if track.TrackState == TrackConfirmed && t.Config.MaxMissesConfirmed > 0 {
-				maxMisses = t.Config.MaxMissesConfirmed
-			}
+			// Determine miss limit based on track maturity and where
+			// the track was lost.
+			maxMisses := t.maxMissesFor(track)
 			if track.Misses >= maxMisses {
 				prevState := track.TrackState
 				track.TrackState = TrackDeleted
@@ -349,13 +445,47 @@ func (t *Tracker) Update(clusters []WorldCluster, timestamp time.Time) {
 	t.TotalBoxFrames += activeCount
 	t.EmptyBoxFrames += activeCount - matchedCount
 
-	// Step 5: Initialise new tracks from unassociated clusters
-	newTracks := 0
+	// Step 5: Initialise new tracks from unassociated clusters, never going
+	// beyond MaxTracks, evicting an existing track if the policy allows.
+	// Clusters outside the birth zones start penalised tracks or none.
+	newTracks, evicted, dropped, unborn := 0, 0, 0, 0
+	active := t.activeTrackCount()
+	var created map[string]bool
 	for clusterIdx, trackID := range associations {
-		if trackID == "" && len(t.Tracks) < t.Config.MaxTracks {
-			t.initTrack(clusters[clusterIdx], nowNanos)
-			newTracks++
+		if trackID != "" {
+			continue
+		}
+		allowed, penaltyHits := t.birthAllowed(clusters[clusterIdx].CentroidX, clusters[clusterIdx].CentroidY)
+		if !allowed {
+			unborn++
+			continue
+		}
+		if t.Config.MaxTracks > 0 && active >= t.Config.MaxTracks {
+			victim := t.evictionCandidate(created)
+			if victim == nil {
+				dropped++
+				continue
+			}
+			t.evictTrack(victim, nowNanos)
+			evicted++
+			active--
 		}
+		track := t.initTrack(clusters[clusterIdx], nowNanos)
+		track.birthPenaltyHits = penaltyHits
+		track.birthZone = t.birthZoneAt(track.X, track.Y)
+		track.birthX, track.birthY = track.X, track.Y
+		if t.Config.MaxTracks > 0 {
+			if created == nil {
+				created = make(map[string]bool)
+			}
+			created[track.TrackID] = true
+		}
+		newTracks++
+		active++
+	}
+	t.logTrackCap(evicted, dropped)
+	if unborn > 0 {
+		tracef("Births outside birth zones suppressed: ts=%d clusters=%d", nowNanos, unborn)
 	}
 
 	// Step 6: Cleanup deleted tracks (keep for grace period, then remove)
@@ -373,11 +503,17 @@ func (t *Tracker) Update(clusters []WorldCluster, timestamp time.Time) {
 	}
 }
 
+// newTrackID returns the ID for the seq'th track a tracker creates. Track
+// IDs are globally unique UUIDs to prevent collisions across tracker
+// resets, server restarts, and long-running deployments; tests replace it
+// to make IDs reproducible.
+var newTrackID = func(seq int64) string {
+	return fmt.Sprintf("trk_%s", uuid.NewString())
+}
+
 // initTrack creates a new track from an unassociated cluster.
-// Track IDs are globally unique UUIDs to prevent collisions across tracker
-// resets, server restarts, and long-running deployments.
 func (t *Tracker) initTrack(cluster WorldCluster, nowNanos int64) *TrackedObject {
-	trackID := fmt.Sprintf("trk_%s", uuid.NewString())
+	trackID := newTrackID(t.NextTrackID)
 	t.NextTrackID++
 
 	track := &TrackedObject{
@@ -479,10 +615,7 @@ func (t *Tracker) AdvanceMisses(timestamp time.Time) {
 		track.Misses++
 		track.Hits = 0
 
-		maxMisses := t.Config.MaxMisses
-		if track.TrackState == TrackConfirmed && t.Config.MaxMissesConfirmed > 0 {
-			maxMisses = t.Config.MaxMissesConfirmed
-		}
+		maxMisses := t.maxMissesFor(track)
 		if track.Misses >= maxMisses {
 			prevState := track.TrackState
 			track.TrackState = TrackDeleted
@@ -2,6 +2,8 @@ package l5tracks
 
 import (
 	"math"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
 )
 
 // Internal numerical stability constants — not user-tunable.
@@ -50,6 +52,25 @@ func (t *Tracker) clampVelocity(track *TrackedObject) {
 	}
 }
 
+// inflateForGap widens a track's position covariance for the part of a
+// frame gap beyond MaxPredictDt that predict did not extrapolate. Over that
+// remainder the object may have kept its speed or stopped, so σ²_pos grows
+// by the squared distance it could have covered, (|v|² + P_vv)·gap², plus
+// ProcessNoisePos·gap. The position-velocity cross terms are left alone:
+// the larger innovation covariance then routes the unexplained
+// displacement into position rather than velocity, which a single clamped
+// or fixed-dt step would corrupt.
+func (t *Tracker) inflateForGap(track *TrackedObject, gap float32) {
+	speed2 := track.VX*track.VX + track.VY*track.VY
+	for _, i := range [2]int{0, 1} {
+		velVar := speed2 + track.P[(i+2)*4+(i+2)]
+		track.P[i*4+i] += velVar*gap*gap + t.Config.ProcessNoisePos*gap
+		if track.P[i*4+i] > t.Config.MaxCovarianceDiag {
+			track.P[i*4+i] = t.Config.MaxCovarianceDiag
+		}
+	}
+}
+
 // predict applies the Kalman prediction step using constant velocity model.
 func (t *Tracker) predict(track *TrackedObject, dt float32) {
 	// Clamp dt to prevent covariance explosion on frame gaps.
@@ -144,8 +165,10 @@ func (t *Tracker) predict(track *TrackedObject, dt float32) {
 // earlier greedy nearest-neighbour approach which could cause track splitting
 // when two clusters competed for the same track.
 //
-// The cost matrix is built from squared Mahalanobis distances; entries
-// exceeding the gating threshold are set to +Inf (forbidden).
+// The cost matrix is built from squared Mahalanobis distances, plus the
+// shape term when ShapeAssociationWeight is set (see shapeCost); entries
+// exceeding the gating threshold (see gateThreshold) are set to +Inf
+// (forbidden). Gating stays on position alone.
 // Returns a slice indexed by cluster index: each element is the trackID
 // the cluster was associated with, or "" if unassociated.
 func (t *Tracker) associate(clusters []WorldCluster, dt float32) []string {
@@ -183,10 +206,10 @@ func (t *Tracker) associate(clusters []WorldCluster, dt float32) []string {
 		for tj, trackID := range activeTrackIDs {
 			track := t.Tracks[trackID]
 			dist2 := t.mahalanobisDistanceSquared(track, clusters[ci], dt)
-			if dist2 >= SingularDistanceRejection || dist2 >= float32(hungarianlnf) || dist2 > t.Config.GatingDistanceSquared {
+			if dist2 >= SingularDistanceRejection || dist2 >= float32(hungarianlnf) || dist2 > t.gateThreshold() {
 				costMatrix[ci][tj] = float32(hungarianlnf)
 			} else {
-				costMatrix[ci][tj] = dist2
+				costMatrix[ci][tj] = dist2 + t.shapeCost(track, clusters[ci])
 			}
 		}
 	}
@@ -216,6 +239,56 @@ func (t *Tracker) associate(clusters []WorldCluster, dt float32) []string {
 	return associations
 }
 
+// shapeCost returns ShapeAssociationWeight·gate·(1 − IoU) for the
+// footprints of the track's predicted box and the cluster's box, or zero
+// when shape association is off or either box is unknown. Scaling by the
+// gate keeps the weight meaningful across gating modes.
+func (t *Tracker) shapeCost(track *TrackedObject, cluster WorldCluster) float32 {
+	w := t.Config.ShapeAssociationWeight
+	if w <= 0 {
+		return 0
+	}
+	trackBox, ok := predictedBox(track)
+	if !ok {
+		return 0
+	}
+	var clusterBox l4perception.OrientedBoundingBox
+	if cluster.OBB != nil {
+		clusterBox = *cluster.OBB
+	} else {
+		clusterBox = l4perception.OrientedBoundingBox{
+			CenterX: cluster.CentroidX,
+			CenterY: cluster.CentroidY,
+			Length:  cluster.BoundingBoxLength,
+			Width:   cluster.BoundingBoxWidth,
+		}
+	}
+	if clusterBox.Length <= 0 || clusterBox.Width <= 0 {
+		return 0
+	}
+	return w * t.gateThreshold() * (1 - l4perception.FootprintIoU(trackBox, clusterBox))
+}
+
+// predictedBox returns the track's box at its predicted position: the
+// latest OBB dimensions along the smoothed heading, or the running average
+// dimensions before any OBB has been seen.
+func predictedBox(track *TrackedObject) (l4perception.OrientedBoundingBox, bool) {
+	length, width := track.OBBLength, track.OBBWidth
+	if length <= 0 || width <= 0 {
+		length, width = track.BoundingBoxLengthAvg, track.BoundingBoxWidthAvg
+	}
+	if length <= 0 || width <= 0 {
+		return l4perception.OrientedBoundingBox{}, false
+	}
+	return l4perception.OrientedBoundingBox{
+		CenterX:    track.X,
+		CenterY:    track.Y,
+		Length:     length,
+		Width:      width,
+		HeadingRad: track.OBBHeadingRad,
+	}, true
+}
+
 // mahalanobisDistanceSquared computes the squared Mahalanobis distance for gating.
 // Uses only position (x, y) for distance computation.
 // Also performs physical plausibility checks to reject spurious associations.
@@ -246,6 +319,25 @@ func (t *Tracker) mahalanobisDistanceSquared(track *TrackedObject, cluster World
 	S10 := track.P[1*4+0]
 	S11 := track.P[1*4+1] + t.Config.MeasurementNoise
 
+	// The filter's position covariance is isotropic, so on its own the gate
+	// is a circle. In Mahalanobis mode, add a term along the predicted
+	// velocity for speed change between frames: σ_along = f·|v|·dt.
+	if t.Config.GatingMode == GatingModeMahalanobis && dt > 0 {
+		speed := float32(math.Sqrt(float64(track.VX*track.VX + track.VY*track.VY)))
+		if speed > 1e-3 {
+			fraction := t.Config.GatingAlongTrackFraction
+			if fraction <= 0 {
+				fraction = DefaultGatingAlongTrackFraction
+			}
+			sigma := fraction * speed * dt
+			ux, uy := track.VX/speed, track.VY/speed
+			S00 += sigma * sigma * ux * ux
+			S01 += sigma * sigma * ux * uy
+			S10 += sigma * sigma * ux * uy
+			S11 += sigma * sigma * uy * uy
+		}
+	}
+
 	// Compute determinant and inverse
 	det := S00*S11 - S01*S10
 	if det < MinDeterminantThreshold {
@@ -273,7 +365,7 @@ func (t *Tracker) mahalanobisDistanceSquared(track *TrackedObject, cluster World
 
 		// Semi-axes are sqrt(eigenvalues) scaled by gating threshold
 		// For chi-squared distribution with 2 DOF, gating threshold determines confidence
-		gatingThreshold := float32(math.Sqrt(float64(t.Config.GatingDistanceSquared)))
+		gatingThreshold := float32(math.Sqrt(float64(t.gateThreshold())))
 		semiMajor := gatingThreshold * float32(math.Sqrt(float64(lambda1)))
 		semiMinor := gatingThreshold * float32(math.Sqrt(float64(lambda2)))
 
@@ -295,3 +387,15 @@ func (t *Tracker) mahalanobisDistanceSquared(track *TrackedObject, cluster World
 
 	return dist2
 }
+
+// gateThreshold returns the maximum squared Mahalanobis distance accepted
+// by the configured gating mode.
+func (t *Tracker) gateThreshold() float32 {
+	if t.Config.GatingMode == GatingModeMahalanobis {
+		if t.Config.GatingChiSquare > 0 {
+			return t.Config.GatingChiSquare
+		}
+		return DefaultGatingChiSquare
+	}
+	return t.Config.GatingDistanceSquared
+}
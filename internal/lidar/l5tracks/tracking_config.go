@@ -28,9 +28,36 @@ const (
 	HeadingSourceLocked       HeadingSource = 3 // Heading locked (aspect ratio guard or jump rejection)
 )
 
+// GatingMode selects how cluster-track candidates are gated.
+type GatingMode string
+
+const (
+	// GatingModeIsotropic compares the squared Mahalanobis distance over the
+	// filter's position covariance with GatingDistanceSquared. The filter's
+	// covariance has no preferred direction, so the gate is a circle. This
+	// is the default.
+	GatingModeIsotropic GatingMode = "isotropic"
+	// GatingModeMahalanobis stretches the innovation covariance along the
+	// track's predicted velocity to cover speed changes between frames,
+	// and gates on a chi-square threshold (GatingChiSquare). Fast tracks
+	// get an ellipse elongated along their direction of travel while the
+	// cross-track width stays tight, rejecting crossing traffic.
+	GatingModeMahalanobis GatingMode = "mahalanobis"
+)
+
+// Defaults applied when the corresponding Mahalanobis gating fields are zero.
+const (
+	DefaultGatingChiSquare          = 9.21 // χ²(2 DOF) at 99%
+	DefaultGatingAlongTrackFraction = 0.5
+)
+
+// DefaultNominalFrameDt is the frame interval assumed when Update has no
+// previous timestamp: 10 Hz, the Pandar40P default rotation rate.
+const DefaultNominalFrameDt = 0.1
+
 // TrackerConfig holds configuration parameters for the tracker.
 type TrackerConfig struct {
-	MaxTracks               int           // Maximum number of concurrent tracks
+	MaxTracks               int           // Maximum number of concurrent tracks (<= 0: unlimited)
 	MaxMisses               int           // Consecutive misses before tentative track deletion
 	MaxMissesConfirmed      int           // Consecutive misses before confirmed track deletion (coasting)
 	HitsToConfirm           int           // Consecutive hits needed for confirmation
@@ -41,6 +68,22 @@ type TrackerConfig struct {
 	OcclusionCovInflation   float32       // Extra covariance inflation per occluded frame
 	DeletedTrackGracePeriod time.Duration // How long to keep deleted tracks before cleanup
 
+	// Gate shape (GatingDistanceSquared applies to GatingModeIsotropic only)
+	GatingMode               GatingMode // Empty means GatingModeIsotropic
+	GatingChiSquare          float32    // Chi-square gate on d² for GatingModeMahalanobis (default 9.21)
+	GatingAlongTrackFraction float32    // Along-track 1σ as a fraction of |v|·dt for GatingModeMahalanobis (default 0.5)
+
+	// Shape-aware association. ShapeAssociationWeight adds
+	// weight·gate·(1 − IoU) to each gated candidate's cost, where gate is
+	// the squared-distance gate threshold and IoU the ground-footprint
+	// overlap of the track's predicted box and the cluster's box, so a
+	// cluster whose centroid drifted under partial occlusion still matches
+	// the track whose box it overlaps. At 1 a cluster with no overlap costs
+	// as much as one on the edge of the gate. Candidates without a box on
+	// either side are costed on position alone. Zero (the default)
+	// associates on centroid distance only.
+	ShapeAssociationWeight float32
+
 	// Kinematics/physics limits
 	MaxReasonableSpeedMps float32 // Maximum reasonable speed (m/s; ~108 km/h at 30.0)
 	MaxPositionJumpMetres float32 // Maximum position jump between observations (metres)
@@ -62,6 +105,49 @@ type TrackerConfig struct {
 
 	// Classification
 	MinObservationsForClassification int // Minimum observations before classification
+
+	// Multipath ghost suppression; nil (the default) disables it. A pointer
+	// keeps TrackerConfig comparable despite the reflector list.
+	GhostSuppression *GhostSuppressionConfig
+
+	// Region-aware continuity: rejoins cluster fragments split across a
+	// background region boundary before association; nil (the default)
+	// disables it.
+	RegionContinuity *RegionContinuityConfig
+
+	// Birth and death zones: tracks born outside the birth zones are
+	// penalised or disallowed, and confirmed tracks lost outside the death
+	// zones coast longer; nil (the default) disables both.
+	LifecycleZones *LifecycleZoneConfig
+
+	// Class dimension priors: reported box dimensions of classified tracks
+	// are pulled toward their class's typical box when the observation is
+	// sparse; nil (the default) reports measured dimensions.
+	DimensionPriors *DimensionPriorConfig
+
+	// Frame timing. Update derives dt from successive frame timestamps;
+	// NominalFrameDt (seconds) only covers the first frame; a timestamp
+	// that does not advance predicts with dt 0. Zero means
+	// DefaultNominalFrameDt.
+	NominalFrameDt float32
+
+	// Speed smoothing. SpeedSmoothingFrames is the number of recent
+	// instantaneous speeds averaged into the speed fed to the average,
+	// peak and speed history, so a single-frame Kalman spike cannot set
+	// a track's peak. Zero or one disables smoothing.
+	SpeedSmoothingFrames int
+
+	// Overload handling. When a new cluster would take the active track
+	// count past MaxTracks, EvictionPolicy chooses an existing track to
+	// delete in its place; EvictionPolicyNone (the default) drops the
+	// cluster instead.
+	EvictionPolicy EvictionPolicy
+
+	// Diagnostics. RecordInnovations keeps each update's Kalman innovation
+	// and normalised innovation squared on the track for
+	// GetInnovationDiagnostics. Off by default to spare the per-update
+	// allocation.
+	RecordInnovations bool
 }
 
 // DefaultTrackerConfig returns tracker configuration loaded from the
@@ -103,6 +189,13 @@ func TrackerConfigFromTuning(l5cfg *config.L5CvKfV1) TrackerConfig {
 		MergeSizeRatio:                   float32(l5cfg.MergeSizeRatio),
 		SplitSizeRatio:                   float32(l5cfg.SplitSizeRatio),
 		MinObservationsForClassification: l5cfg.MinObservationsForClassification,
+		EvictionPolicy:                   ParseEvictionPolicy(l5cfg.EvictionPolicy),
+		GatingMode:                       GatingMode(l5cfg.GatingMode),
+		GatingChiSquare:                  float32(l5cfg.GatingChiSquare),
+		GatingAlongTrackFraction:         float32(l5cfg.GatingAlongTrackFraction),
+		SpeedSmoothingFrames:             l5cfg.SpeedSmoothingFrames,
+		ShapeAssociationWeight:           float32(l5cfg.ShapeAssociationWeight),
+		NominalFrameDt:                   DefaultNominalFrameDt,
 	}
 }
 
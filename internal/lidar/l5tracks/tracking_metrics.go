@@ -2,6 +2,7 @@ package l5tracks
 
 import (
 	"math"
+	"sort"
 	"time"
 )
 
@@ -29,6 +30,10 @@ type TrackingMetrics struct {
 	// Total tracks created and confirmed since last reset
 	TracksCreated   int `json:"tracks_created"`
 	TracksConfirmed int `json:"tracks_confirmed"`
+	// Tracks deleted by the eviction policy to stay within MaxTracks
+	TracksEvicted int `json:"tracks_evicted"`
+	// How long confirmed tracks took to be confirmed; nil until one is
+	ConfirmationLatency *ConfirmationLatencySummary `json:"confirmation_latency,omitempty"`
 
 	// Scene-level foreground capture metrics
 	// ForegroundCaptureRatio is the fraction of foreground points assigned to
@@ -98,7 +103,8 @@ func (t *Tracker) GetDeletedTrackGracePeriod() time.Duration {
 	return t.Config.DeletedTrackGracePeriod
 }
 
-// GetActiveTracks returns a slice of currently active (non-deleted) tracks.
+// GetActiveTracks returns a slice of currently active (non-deleted) tracks,
+// in SortTracks order.
 // Each returned TrackedObject is a shallow copy with a deep-copied History slice,
 // making it safe for callers to read History without holding the tracker lock.
 // This prevents data races between the visualiser adapter (reading History) and
@@ -120,10 +126,11 @@ func (t *Tracker) GetActiveTracks() []*TrackedObject {
 			active = append(active, &copied)
 		}
 	}
+	SortTracks(active)
 	return active
 }
 
-// GetConfirmedTracks returns only confirmed tracks.
+// GetConfirmedTracks returns only confirmed tracks, in SortTracks order.
 // Each returned TrackedObject is a shallow copy with deep-copied slices,
 // making it safe for callers to read without holding the tracker lock.
 // This prevents data races between the persistence pipeline (reading fields)
@@ -146,9 +153,14 @@ func (t *Tracker) GetConfirmedTracks() []*TrackedObject {
 				copied.speedHistory = make([]float32, len(track.speedHistory))
 				copy(copied.speedHistory, track.speedHistory)
 			}
+			if len(track.rawSpeedWindow) > 0 {
+				copied.rawSpeedWindow = make([]float32, len(track.rawSpeedWindow))
+				copy(copied.rawSpeedWindow, track.rawSpeedWindow)
+			}
 			confirmed = append(confirmed, &copied)
 		}
 	}
+	SortTracks(confirmed)
 	return confirmed
 }
 
@@ -174,6 +186,10 @@ func (t *Tracker) GetTrack(trackID string) *TrackedObject {
 		copied.speedHistory = make([]float32, len(track.speedHistory))
 		copy(copied.speedHistory, track.speedHistory)
 	}
+	if len(track.rawSpeedWindow) > 0 {
+		copied.rawSpeedWindow = make([]float32, len(track.rawSpeedWindow))
+		copy(copied.rawSpeedWindow, track.rawSpeedWindow)
+	}
 
 	return &copied
 }
@@ -197,8 +213,9 @@ func (t *Tracker) GetTrackCount() (total, tentative, confirmed, deleted int) {
 	return
 }
 
-// GetAllTracks returns a slice of all tracks including deleted ones.
-// This is useful for analysis and reporting after processing is complete.
+// GetAllTracks returns a slice of all tracks including deleted ones, in
+// SortTracks order. This is useful for analysis and reporting after
+// processing is complete.
 func (t *Tracker) GetAllTracks() []*TrackedObject {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -207,9 +224,22 @@ func (t *Tracker) GetAllTracks() []*TrackedObject {
 	for _, track := range t.Tracks {
 		all = append(all, track)
 	}
+	SortTracks(all)
 	return all
 }
 
+// SortTracks orders tracks by first observation time, then by track ID.
+// Tracks are held in a map, so without it exports would list the same
+// tracks in a different order on every run.
+func SortTracks(tracks []*TrackedObject) {
+	sort.Slice(tracks, func(i, j int) bool {
+		if tracks[i].StartUnixNanos != tracks[j].StartUnixNanos {
+			return tracks[i].StartUnixNanos < tracks[j].StartUnixNanos
+		}
+		return tracks[i].TrackID < tracks[j].TrackID
+	})
+}
+
 // GetRecentlyDeletedTracks returns deleted tracks still within the grace period.
 // Each returned TrackedObject is a shallow copy with a deep-copied History slice.
 // Used by the visualiser adapter for fade-out rendering.
@@ -264,6 +294,35 @@ func (track *TrackedObject) Heading() float32 {
 	return float32(math.Atan2(float64(track.VY), float64(track.VX)))
 }
 
+// VelocityStdDev returns the Kalman standard deviation of the VX and VY
+// estimates (m/s), taken from the diagonal of the state covariance. It
+// grows while a track coasts without measurements.
+func (track *TrackedObject) VelocityStdDev() (vx, vy float32) {
+	vx = float32(math.Sqrt(math.Max(float64(track.P[2*4+2]), 0)))
+	vy = float32(math.Sqrt(math.Max(float64(track.P[3*4+3]), 0)))
+	return vx, vy
+}
+
+// SpeedStdDev returns the standard deviation of Speed() (m/s): the velocity
+// covariance projected onto the direction of travel. For a near-stationary
+// track, where direction is undefined, the mean of the two axis variances
+// is used instead.
+func (track *TrackedObject) SpeedStdDev() float32 {
+	pxx := float64(track.P[2*4+2])
+	pyy := float64(track.P[3*4+3])
+	pxy := float64(track.P[2*4+3])
+	speed := float64(track.Speed())
+	var variance float64
+	if speed < 1e-3 {
+		variance = (pxx + pyy) / 2
+	} else {
+		ux := float64(track.VX) / speed
+		uy := float64(track.VY) / speed
+		variance = ux*ux*pxx + 2*ux*uy*pxy + uy*uy*pyy
+	}
+	return float32(math.Sqrt(math.Max(variance, 0)))
+}
+
 // SpeedHistory returns a copy of the track's speed history for classification
 // and jitter/variance analysis. Speed percentiles are never computed per-track;
 // they are aggregate-only (see speed-percentile-aggregation-alignment-plan.md).
@@ -276,6 +335,31 @@ func (track *TrackedObject) SpeedHistory() []float32 {
 	return result
 }
 
+// BoundingBoxStdDev returns the sample standard deviation of the per-frame
+// bounding box length, width and height over the track's observations.
+// Returns zeros until at least two observations have been accumulated.
+func (track *TrackedObject) BoundingBoxStdDev() (length, width, height float32) {
+	if track.ObservationCount < 2 {
+		return 0, 0, 0
+	}
+	denom := float64(track.ObservationCount - 1)
+	length = float32(math.Sqrt(math.Max(track.BBoxLengthM2, 0) / denom))
+	width = float32(math.Sqrt(math.Max(track.BBoxWidthM2, 0) / denom))
+	height = float32(math.Sqrt(math.Max(track.BBoxHeightM2, 0) / denom))
+	return length, width, height
+}
+
+// DetectionReliability returns the fraction of frames between the track's
+// first and last observation in which it was observed rather than coasted,
+// in [0, 1]. It measures detection continuity only: frames coasted after
+// the object was last seen, e.g. while awaiting deletion, do not count.
+func (track *TrackedObject) DetectionReliability() float32 {
+	if track.ObservationCount == 0 {
+		return 0
+	}
+	return float32(track.ObservationCount) / float32(track.ObservationCount+track.CoastedFrames)
+}
+
 // ComputeQualityMetrics calculates track quality metrics.
 // This should be called when a track is finalized (state changes to deleted or when exporting).
 func (track *TrackedObject) ComputeQualityMetrics() {
@@ -417,6 +501,8 @@ func (t *Tracker) GetTrackingMetrics() TrackingMetrics {
 	// Fragmentation: fraction of created tracks that never confirmed
 	metrics.TracksCreated = t.TracksCreated
 	metrics.TracksConfirmed = t.TracksConfirmed
+	metrics.TracksEvicted = t.TracksEvicted
+	metrics.ConfirmationLatency = t.ConfirmationStats.summary(t.Config.HitsToConfirm)
 	if t.TracksCreated > 0 {
 		metrics.FragmentationRatio = 1.0 - float32(t.TracksConfirmed)/float32(t.TracksCreated)
 	}
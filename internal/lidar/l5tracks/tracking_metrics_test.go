@@ -1,6 +1,8 @@
 package l5tracks
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -330,3 +332,183 @@ func TestRecordFrameStatsAndSceneMetrics(t *testing.T) {
 		assert.Equal(t, float32(0), metrics.EmptyBoxRatio)
 	})
 }
+
+func TestTrackedObject_BoundingBoxStdDev(t *testing.T) {
+	tracker := NewTracker(DefaultTrackerConfig())
+	now := time.Now()
+	lengths := []float32{4.0, 5.0, 4.0, 5.0}
+	for i, l := range lengths {
+		cluster := WorldCluster{
+			CentroidX:         5.0 + 0.1*float32(i),
+			CentroidY:         10.0,
+			SensorID:          "test",
+			BoundingBoxLength: l,
+			BoundingBoxWidth:  2.0,
+			BoundingBoxHeight: 1.5,
+		}
+		tracker.Update([]WorldCluster{cluster}, now.Add(time.Duration(i)*100*time.Millisecond))
+	}
+
+	tracks := tracker.GetActiveTracks()
+	if len(tracks) != 1 {
+		t.Fatalf("expected 1 track, got %d", len(tracks))
+	}
+	length, width, height := tracks[0].BoundingBoxStdDev()
+	// Sample std-dev of {4,5,4,5} is sqrt(1/3).
+	if math.Abs(float64(length)-math.Sqrt(1.0/3.0)) > 1e-4 {
+		t.Errorf("expected length std-dev %.4f, got %.4f", math.Sqrt(1.0/3.0), length)
+	}
+	if width != 0 || height != 0 {
+		t.Errorf("expected zero width/height std-dev, got %.4f/%.4f", width, height)
+	}
+
+	single := &TrackedObject{TrackMeasurement: TrackMeasurement{ObservationCount: 1}}
+	if l, w, h := single.BoundingBoxStdDev(); l != 0 || w != 0 || h != 0 {
+		t.Errorf("expected zeros for a single observation, got %f/%f/%f", l, w, h)
+	}
+}
+
+func TestTrackedObject_DetectionReliability(t *testing.T) {
+	tracker := NewTracker(DefaultTrackerConfig())
+	now := time.Now()
+	// Observed on 7 of 10 frames: two single misses and one double miss
+	// bridged by re-association, then two trailing misses.
+	pattern := []bool{true, true, false, true, true, false, false, true, true, true, false, false}
+	for i, seen := range pattern {
+		var clusters []WorldCluster
+		if seen {
+			clusters = []WorldCluster{{
+				CentroidX: 5.0 + 0.1*float32(i),
+				CentroidY: 10.0,
+				SensorID:  "test",
+			}}
+		}
+		tracker.Update(clusters, now.Add(time.Duration(i)*100*time.Millisecond))
+	}
+
+	tracks := tracker.GetAllTracks()
+	if len(tracks) != 1 {
+		t.Fatalf("expected 1 track, got %d", len(tracks))
+	}
+	track := tracks[0]
+	if track.ObservationCount != 7 || track.CoastedFrames != 3 {
+		t.Fatalf("expected 7 observations and 3 coasted frames, got %d and %d",
+			track.ObservationCount, track.CoastedFrames)
+	}
+	if got := track.DetectionReliability(); math.Abs(float64(got)-0.7) > 1e-6 {
+		t.Errorf("expected reliability 0.7, got %.4f", got)
+	}
+
+	if got := (&TrackedObject{}).DetectionReliability(); got != 0 {
+		t.Errorf("expected 0 for an unobserved track, got %.4f", got)
+	}
+}
+
+func TestTrackedObject_SpeedStdDevGrowsWhileOccluded(t *testing.T) {
+	tracker := NewTracker(DefaultTrackerConfig())
+	now := time.Now()
+	frame := 0
+	step := func(clusters []WorldCluster) {
+		tracker.Update(clusters, now.Add(time.Duration(frame)*100*time.Millisecond))
+		frame++
+	}
+
+	// Confirm a track moving at ~5 m/s along X.
+	for i := 0; i < 10; i++ {
+		step([]WorldCluster{{
+			CentroidX:         5.0 + 0.5*float32(i),
+			CentroidY:         10.0,
+			SensorID:          "test",
+			BoundingBoxLength: 4.0,
+			BoundingBoxWidth:  2.0,
+			BoundingBoxHeight: 1.5,
+		}})
+	}
+	var track *TrackedObject
+	for _, tr := range tracker.Tracks {
+		track = tr
+	}
+	if track == nil || track.TrackState != TrackConfirmed {
+		t.Fatal("expected a confirmed track")
+	}
+
+	prev := track.SpeedStdDev()
+	if prev <= 0 {
+		t.Fatalf("expected positive speed std-dev on an observed track, got %v", prev)
+	}
+	// Coast through a miss streak: uncertainty must grow every frame.
+	for miss := 1; miss <= 5; miss++ {
+		step(nil)
+		if track.Misses != miss {
+			t.Fatalf("expected %d misses, got %d", miss, track.Misses)
+		}
+		got := track.SpeedStdDev()
+		if got <= prev {
+			t.Errorf("miss %d: speed std-dev did not grow (%v -> %v)", miss, prev, got)
+		}
+		prev = got
+	}
+
+	vx, vy := track.VelocityStdDev()
+	if vx <= 0 || vy <= 0 {
+		t.Errorf("expected positive velocity std-dev, got (%v, %v)", vx, vy)
+	}
+}
+
+func TestTrackedObject_SpeedStdDevProjection(t *testing.T) {
+	track := &TrackedObject{VX: 3, VY: 0}
+	track.P[2*4+2] = 4 // var(vx)
+	track.P[3*4+3] = 9 // var(vy)
+	if got := track.SpeedStdDev(); math.Abs(float64(got)-2) > 1e-6 {
+		t.Errorf("moving along X: expected std-dev 2, got %v", got)
+	}
+
+	track.VX, track.VY = 0, 0
+	if got := track.SpeedStdDev(); math.Abs(float64(got)-math.Sqrt(6.5)) > 1e-6 {
+		t.Errorf("stationary: expected std-dev %v, got %v", math.Sqrt(6.5), got)
+	}
+}
+
+// TestTrackExportOrder_StableAcrossInsertionOrder fills the tracker's map in
+// a different order on each run and checks the exported order is always
+// first observation time, then track ID.
+func TestTrackExportOrder_StableAcrossInsertionOrder(t *testing.T) {
+	t.Parallel()
+
+	specs := []struct {
+		id    string
+		start int64
+		state TrackState
+	}{
+		{"trk_c", 300, TrackConfirmed},
+		{"trk_a", 100, TrackConfirmed},
+		{"trk_e", 200, TrackTentative},
+		{"trk_b", 200, TrackConfirmed}, // same first frame as trk_e
+		{"trk_d", 400, TrackDeleted},
+	}
+	wantAll := []string{"trk_a", "trk_b", "trk_e", "trk_c", "trk_d"}
+	wantActive := []string{"trk_a", "trk_b", "trk_e", "trk_c"}
+	wantConfirmed := []string{"trk_a", "trk_b", "trk_c"}
+
+	ids := func(tracks []*TrackedObject) []string {
+		out := make([]string, len(tracks))
+		for i, tr := range tracks {
+			out[i] = tr.TrackID
+		}
+		return out
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for run := 0; run < 20; run++ {
+		tracker := NewTracker(DefaultTrackerConfig())
+		for _, i := range rng.Perm(len(specs)) {
+			s := specs[i]
+			track := &TrackedObject{}
+			track.TrackID, track.StartUnixNanos, track.TrackState = s.id, s.start, s.state
+			tracker.Tracks[s.id] = track
+		}
+		require.Equal(t, wantAll, ids(tracker.GetAllTracks()), "run %d", run)
+		require.Equal(t, wantActive, ids(tracker.GetActiveTracks()), "run %d", run)
+		require.Equal(t, wantConfirmed, ids(tracker.GetConfirmedTracks()), "run %d", run)
+	}
+}
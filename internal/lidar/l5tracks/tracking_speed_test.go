@@ -129,3 +129,55 @@ func TestTracker_AvgSpeedMps_IsRunningMean(t *testing.T) {
 			track.AvgSpeedMps, expectedAvg, len(history), track.ObservationCount)
 	}
 }
+
+// spikeTrackPeak drives a 10 m/s track with one displaced observation and
+// returns the confirmed track.
+func spikeTrackPeak(t *testing.T, smoothingFrames int) *TrackedObject {
+	t.Helper()
+	cfg := DefaultTrackerConfig()
+	cfg.HitsToConfirm = 2
+	cfg.ProcessNoiseVel = 2 // responsive filter, so the glitch shows in the speed
+	cfg.SpeedSmoothingFrames = smoothingFrames
+	tracker := NewTracker(cfg)
+
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 30; i++ {
+		x := 10.0 + float32(i)*1.0
+		if i == 20 {
+			x += 1.5 // one-frame position glitch
+		}
+		tracker.Update([]WorldCluster{{
+			CentroidX: x, CentroidZ: 1.0, SensorID: "test",
+			BoundingBoxLength: 4.0, BoundingBoxWidth: 2.0, BoundingBoxHeight: 1.5,
+			PointsCount: 100,
+		}}, now)
+		now = now.Add(100 * time.Millisecond)
+	}
+	confirmed := tracker.GetConfirmedTracks()
+	if len(confirmed) != 1 {
+		t.Fatalf("expected one confirmed track, got %d", len(confirmed))
+	}
+	return confirmed[0]
+}
+
+func TestTracker_SpeedSmoothing_SpikeDoesNotSetPeak(t *testing.T) {
+	raw := spikeTrackPeak(t, 0)
+	smoothed := spikeTrackPeak(t, 5)
+
+	if raw.MaxSpeedMps < 12 {
+		t.Fatalf("unsmoothed peak %.2f m/s: the glitch should spike the speed", raw.MaxSpeedMps)
+	}
+	rawExcess := raw.MaxSpeedMps - 10
+	if excess := smoothed.MaxSpeedMps - 10; excess > rawExcess/2 {
+		t.Errorf("smoothed peak %.2f m/s still dominated by the spike (unsmoothed %.2f m/s)",
+			smoothed.MaxSpeedMps, raw.MaxSpeedMps)
+	}
+	if smoothed.InstantSpeedMps == 0 {
+		t.Error("InstantSpeedMps should keep the raw latest speed")
+	}
+	for _, s := range smoothed.SpeedHistory() {
+		if s > smoothed.MaxSpeedMps {
+			t.Errorf("speed history sample %.2f exceeds the smoothed peak %.2f", s, smoothed.MaxSpeedMps)
+		}
+	}
+}
@@ -3,8 +3,13 @@ package l5tracks
 import (
 	"io"
 	"math"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
+
+	"github.com/banshee-data/velocity.report/internal/config"
+	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
 )
 
 func TestNewTracker(t *testing.T) {
@@ -56,6 +61,45 @@ func TestTrackerConfigFromTuning_NilConfig(t *testing.T) {
 	}
 }
 
+func TestTrackerConfigFromTuning_Gating(t *testing.T) {
+	tuning := config.MustLoadDefaultConfig()
+	tuning.L5.CvKfV1.GatingMode = "mahalanobis"
+	tuning.L5.CvKfV1.GatingChiSquare = 5.99
+	tuning.L5.CvKfV1.GatingAlongTrackFraction = 0.8
+
+	cfg := TrackerConfigFromTuning(tuning.L5.CvKfV1)
+	if cfg.GatingMode != GatingModeMahalanobis {
+		t.Errorf("GatingMode = %q, want %q", cfg.GatingMode, GatingModeMahalanobis)
+	}
+	if cfg.GatingChiSquare != float32(5.99) {
+		t.Errorf("GatingChiSquare = %v, want 5.99", cfg.GatingChiSquare)
+	}
+	if cfg.GatingAlongTrackFraction != float32(0.8) {
+		t.Errorf("GatingAlongTrackFraction = %v, want 0.8", cfg.GatingAlongTrackFraction)
+	}
+}
+
+func TestTrackerConfigFromTuning_SpeedSmoothing(t *testing.T) {
+	tuning := config.MustLoadDefaultConfig()
+	if cfg := TrackerConfigFromTuning(tuning.L5.CvKfV1); cfg.SpeedSmoothingFrames != 0 {
+		t.Errorf("default SpeedSmoothingFrames = %d, want 0", cfg.SpeedSmoothingFrames)
+	}
+	tuning.L5.CvKfV1.SpeedSmoothingFrames = 5
+	if cfg := TrackerConfigFromTuning(tuning.L5.CvKfV1); cfg.SpeedSmoothingFrames != 5 {
+		t.Errorf("SpeedSmoothingFrames = %d, want 5", cfg.SpeedSmoothingFrames)
+	}
+}
+
+func TestTrackerConfigFromTuning_ShapeAssociation(t *testing.T) {
+	tuning := config.MustLoadDefaultConfig()
+	tuning.L5.CvKfV1.ShapeAssociationWeight = 0.75
+
+	cfg := TrackerConfigFromTuning(tuning.L5.CvKfV1)
+	if cfg.ShapeAssociationWeight != 0.75 {
+		t.Errorf("ShapeAssociationWeight = %v, want 0.75", cfg.ShapeAssociationWeight)
+	}
+}
+
 func TestTracker_GetConfigReturnsSnapshot(t *testing.T) {
 	tracker := NewTracker(DefaultTrackerConfig())
 	tracker.UpdateConfig(func(cfg *TrackerConfig) {
@@ -339,6 +383,112 @@ func TestTracker_MaxTracks(t *testing.T) {
 	}
 }
 
+func TestTracker_MaxTracksEviction(t *testing.T) {
+	// A and B are seen for six frames and confirmed; C appears on frame 6.
+	// On frame 7 B is missed (coasting) and a new cluster D arrives with
+	// the tracker at its cap of three.
+	at := func(x float32) WorldCluster { return WorldCluster{CentroidX: x, SensorID: "test"} }
+	a, b, c, d := at(0), at(10), at(20), at(30)
+
+	tests := []struct {
+		name    string
+		policy  EvictionPolicy
+		want    []float32 // X of the surviving active tracks
+		evicted int
+	}{
+		{"none drops the new cluster", EvictionPolicyNone, []float32{0, 10, 20}, 0},
+		{"lowest quality evicts the tentative track", EvictionPolicyLowestQuality, []float32{0, 10, 30}, 1},
+		{"oldest coasting evicts the missed track", EvictionPolicyOldestCoasting, []float32{0, 20, 30}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultTrackerConfig()
+			config.MaxTracks = 3
+			config.EvictionPolicy = tt.policy
+			tracker := NewTracker(config)
+
+			now := time.Unix(1_700_000_000, 0)
+			for frame := 1; frame <= 6; frame++ {
+				clusters := []WorldCluster{a, b}
+				if frame == 6 {
+					clusters = append(clusters, c)
+				}
+				tracker.Update(clusters, now)
+				now = now.Add(100 * time.Millisecond)
+			}
+			tracker.Update([]WorldCluster{a, c, d}, now)
+
+			var got []float32
+			for _, track := range tracker.GetActiveTracks() {
+				got = append(got, float32(math.Round(float64(track.X))))
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("active tracks at X = %v, want %v", got, tt.want)
+			}
+			if tracker.TracksEvicted != tt.evicted {
+				t.Errorf("TracksEvicted = %d, want %d", tracker.TracksEvicted, tt.evicted)
+			}
+			if m := tracker.GetTrackingMetrics(); m.TracksEvicted != tt.evicted {
+				t.Errorf("metrics TracksEvicted = %d, want %d", m.TracksEvicted, tt.evicted)
+			}
+		})
+	}
+}
+
+func TestTracker_MaxTracksEvictionSparesNewTracks(t *testing.T) {
+	config := DefaultTrackerConfig()
+	config.MaxTracks = 3
+	config.EvictionPolicy = EvictionPolicyLowestQuality
+	tracker := NewTracker(config)
+
+	// Clusters arriving in the same frame cannot evict each other.
+	var clusters []WorldCluster
+	for i := 0; i < 5; i++ {
+		clusters = append(clusters, WorldCluster{CentroidX: float32(i * 10), SensorID: "test"})
+	}
+	tracker.Update(clusters, time.Now())
+
+	if total, _, _, _ := tracker.GetTrackCount(); total != 3 {
+		t.Errorf("expected 3 tracks, got %d", total)
+	}
+	if tracker.TracksEvicted != 0 {
+		t.Errorf("TracksEvicted = %d, want 0", tracker.TracksEvicted)
+	}
+}
+
+func TestParseEvictionPolicy(t *testing.T) {
+	for in, want := range map[string]EvictionPolicy{
+		"none":            EvictionPolicyNone,
+		"":                EvictionPolicyNone,
+		"lowest_quality":  EvictionPolicyLowestQuality,
+		"oldest_coasting": EvictionPolicyOldestCoasting,
+	} {
+		if got := ParseEvictionPolicy(in); got != want {
+			t.Errorf("ParseEvictionPolicy(%q) = %q, want %q", in, got, want)
+		}
+		if in != "" && want.String() != in {
+			t.Errorf("%q.String() = %q, want %q", want, want.String(), in)
+		}
+	}
+}
+
+func TestTracker_UnlimitedTracks(t *testing.T) {
+	config := DefaultTrackerConfig()
+	config.MaxTracks = 0
+	tracker := NewTracker(config)
+
+	var clusters []WorldCluster
+	for i := 0; i < 150; i++ {
+		clusters = append(clusters, WorldCluster{CentroidX: float32(i * 10), SensorID: "test"})
+	}
+	tracker.Update(clusters, time.Now())
+
+	if total, _, _, _ := tracker.GetTrackCount(); total != 150 {
+		t.Errorf("MaxTracks 0 should not cap tracks, got %d", total)
+	}
+}
+
 func TestTracker_VelocityEstimation(t *testing.T) {
 	config := DefaultTrackerConfig()
 	config.HitsToConfirm = 1
@@ -367,6 +517,136 @@ func TestTracker_VelocityEstimation(t *testing.T) {
 	}
 }
 
+// trackSpeedOverIntervals drives one object at 10 m/s along X, with the
+// true positions sampled after each real interval but the tracker told the
+// given frame timestamps. It returns the tracker's VX after each frame.
+func trackSpeedOverIntervals(t *testing.T, real, reported []time.Duration) []float32 {
+	t.Helper()
+	const speed = 10.0
+	config := DefaultTrackerConfig()
+	config.HitsToConfirm = 1
+	tracker := NewTracker(config)
+
+	start := time.Now()
+	var realT, reportedT time.Duration
+	var vx []float32
+	for i := range real {
+		realT += real[i]
+		reportedT += reported[i]
+		cluster := WorldCluster{CentroidX: float32(speed * realT.Seconds()), SensorID: "test"}
+		tracker.Update([]WorldCluster{cluster}, start.Add(reportedT))
+		tracks := tracker.GetActiveTracks()
+		if len(tracks) != 1 {
+			t.Fatalf("frame %d: expected 1 track, got %d", i, len(tracks))
+		}
+		vx = append(vx, tracks[0].VX)
+	}
+	return vx
+}
+
+// TestTracker_VelocityWithIrregularFrameTiming runs at 10 Hz, speeds up to
+// 20 Hz (RPM change) and drops 0.8 s of frames. With dt taken from the
+// frame timestamps the velocity stays at 10 m/s throughout; assuming a
+// fixed 100 ms interval halves it at 20 Hz.
+func TestTracker_VelocityWithIrregularFrameTiming(t *testing.T) {
+	var intervals []time.Duration
+	for i := 0; i < 40; i++ {
+		switch {
+		case i < 15:
+			intervals = append(intervals, 100*time.Millisecond)
+		case i == 30:
+			intervals = append(intervals, 800*time.Millisecond) // dropped frames
+		default:
+			intervals = append(intervals, 50*time.Millisecond)
+		}
+	}
+
+	vx := trackSpeedOverIntervals(t, intervals, intervals)
+	for i := 12; i < len(vx); i++ { // after the filter has converged
+		if math.Abs(float64(vx[i])-10) > 0.5 {
+			t.Errorf("frame %d (interval %v): VX = %.2f, want 10 ± 0.5", i, intervals[i], vx[i])
+		}
+	}
+
+	// The control stops before the gap, which under a fixed 100 ms step
+	// would look like a teleport and spawn a second track.
+	fixed := make([]time.Duration, 30)
+	for i := range fixed {
+		fixed[i] = 100 * time.Millisecond
+	}
+	fixedVX := trackSpeedOverIntervals(t, intervals[:30], fixed)
+	if got := fixedVX[29]; got > 7 {
+		t.Errorf("fixed-dt control: VX = %.2f at 20 Hz; expected the 100 ms assumption to underestimate", got)
+	}
+}
+
+// TestTracker_GapInflatesCovarianceNotVelocity checks a gap longer than
+// MaxPredictDt leaves the velocity alone and widens the position covariance.
+func TestTracker_GapInflatesCovarianceNotVelocity(t *testing.T) {
+	config := DefaultTrackerConfig()
+	tracker := NewTracker(config)
+	now := time.Now()
+	tracker.Update([]WorldCluster{{SensorID: "test"}}, now)
+	trackID := tracker.GetActiveTracks()[0].TrackID
+
+	tracker.mu.Lock()
+	track := tracker.Tracks[trackID]
+	track.VX = 10
+	before := track.P
+	tracker.mu.Unlock()
+
+	gap := time.Duration(float64(config.MaxPredictDt)*float64(time.Second)) + time.Second
+	tracker.Update(nil, now.Add(gap))
+
+	got := tracker.GetTrack(trackID)
+	if got.VX != 10 {
+		t.Errorf("VX = %v after a gap with no measurement, want 10", got.VX)
+	}
+	// X advances by at most MaxPredictDt worth of motion.
+	if want := 10 * config.MaxPredictDt; math.Abs(float64(got.X-want)) > 1e-3 {
+		t.Errorf("X = %v, want %v", got.X, want)
+	}
+	// Beyond what a single MaxPredictDt step adds (≤ 2·P_xv·dt + P_vv·dt² + Q)
+	stepGrowth := 2*before[0*4+2]*config.MaxPredictDt + before[2*4+2]*config.MaxPredictDt*config.MaxPredictDt + config.ProcessNoisePos*config.MaxPredictDt
+	if growth := got.P[0] - before[0]; growth <= stepGrowth+before[2*4+2]*0.5 {
+		t.Errorf("position variance grew by %v; expected gap inflation beyond one predict step (%v)", growth, stepGrowth)
+	}
+	if got.P[2*4+2] > before[2*4+2]+config.ProcessNoiseVel*config.MaxPredictDt+1e-6 {
+		t.Errorf("velocity variance grew by more than one predict step: %v -> %v", before[2*4+2], got.P[2*4+2])
+	}
+}
+
+func TestTracker_NonAdvancingTimestampDoesNotPredict(t *testing.T) {
+	config := DefaultTrackerConfig()
+	config.MaxMisses = 10
+	tracker := NewTracker(config)
+	now := time.Now()
+	tracker.Update([]WorldCluster{{SensorID: "test"}}, now)
+	trackID := tracker.GetActiveTracks()[0].TrackID
+
+	tracker.mu.Lock()
+	tracker.Tracks[trackID].VX = 10
+	tracker.mu.Unlock()
+
+	// A duplicate and then an out-of-order timestamp: no time has passed,
+	// so the track must not move and the last timestamp must not change.
+	for _, ts := range []time.Time{now, now.Add(-50 * time.Millisecond)} {
+		tracker.Update(nil, ts)
+		if got := tracker.GetTrack(trackID); got.X != 0 {
+			t.Errorf("X = %v after timestamp %v, want 0", got.X, ts.Sub(now))
+		}
+		if tracker.LastUpdateNanos != now.UnixNano() {
+			t.Errorf("LastUpdateNanos = %d after timestamp %v, want %d", tracker.LastUpdateNanos, ts.Sub(now), now.UnixNano())
+		}
+	}
+
+	// The next advancing frame measures dt from the last good timestamp.
+	tracker.Update(nil, now.Add(100*time.Millisecond))
+	if got := tracker.GetTrack(trackID); math.Abs(float64(got.X-1)) > 1e-3 {
+		t.Errorf("X = %v after 100ms at 10 m/s, want 1", got.X)
+	}
+}
+
 func TestTracker_GetConfirmedTracks(t *testing.T) {
 	config := DefaultTrackerConfig()
 	config.HitsToConfirm = 2
@@ -436,6 +716,84 @@ func TestTracker_GatingRejectsDistantClusters(t *testing.T) {
 	}
 }
 
+func TestTracker_MahalanobisGatingFollowsVelocity(t *testing.T) {
+	const dt = 0.1
+	// Fast track heading north-east at 25 m/s, already predicted forward.
+	speed := float32(25)
+	ux, uy := float32(math.Sqrt2/2), float32(math.Sqrt2/2)
+	newTrack := func() *TrackedObject {
+		track := &TrackedObject{
+			TrackID: "trk_fast",
+			X:       10,
+			Y:       10,
+			VX:      speed * ux,
+			VY:      speed * uy,
+			P: [16]float32{
+				0.3, 0, 0, 0,
+				0, 0.3, 0, 0,
+				0, 0, 1, 0,
+				0, 0, 0, 1,
+			},
+		}
+		track.TrackState = TrackConfirmed
+		return track
+	}
+	// 2.5 m from the prediction, once ahead along the velocity and once
+	// across it (crossing traffic).
+	ahead := WorldCluster{ClusterID: 1, CentroidX: 10 + 2.5*ux, CentroidY: 10 + 2.5*uy}
+	across := WorldCluster{ClusterID: 2, CentroidX: 10 - 2.5*uy, CentroidY: 10 + 2.5*ux}
+
+	config := DefaultTrackerConfig()
+	config.MeasurementNoise = 0.1
+	config.GatingDistanceSquared = 9
+
+	for _, tc := range []struct {
+		mode         GatingMode
+		wantAhead    bool
+		wantCrossing bool
+	}{
+		// Isotropic: d² = 2.5²/0.4 ≈ 15.6 in every direction.
+		{mode: GatingModeIsotropic, wantAhead: false, wantCrossing: false},
+		// Mahalanobis: along-track σ² = (0.5·25·0.1)² adds 1.56 m², so
+		// d² ≈ 3.2 ahead, while across-track stays at 15.6 > 9.21.
+		{mode: GatingModeMahalanobis, wantAhead: true, wantCrossing: false},
+	} {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			cfg := config
+			cfg.GatingMode = tc.mode
+			for _, c := range []struct {
+				cluster WorldCluster
+				want    bool
+			}{{ahead, tc.wantAhead}, {across, tc.wantCrossing}} {
+				tracker := NewTracker(cfg)
+				tracker.Tracks["trk_fast"] = newTrack()
+				got := tracker.associate([]WorldCluster{c.cluster}, dt)[0] == "trk_fast"
+				if got != c.want {
+					d2 := tracker.mahalanobisDistanceSquared(newTrack(), c.cluster, dt)
+					t.Errorf("cluster %d associated=%v, want %v (d²=%.2f, gate=%.2f)",
+						c.cluster.ClusterID, got, c.want, d2, tracker.gateThreshold())
+				}
+			}
+		})
+	}
+}
+
+func TestTracker_GateThreshold(t *testing.T) {
+	config := DefaultTrackerConfig()
+	config.GatingDistanceSquared = 25
+	if got := NewTracker(config).gateThreshold(); got != 25 {
+		t.Errorf("isotropic gate = %v, want GatingDistanceSquared", got)
+	}
+	config.GatingMode = GatingModeMahalanobis
+	if got := NewTracker(config).gateThreshold(); got != DefaultGatingChiSquare {
+		t.Errorf("mahalanobis gate = %v, want default %v", got, DefaultGatingChiSquare)
+	}
+	config.GatingChiSquare = 13.82
+	if got := NewTracker(config).gateThreshold(); got != 13.82 {
+		t.Errorf("mahalanobis gate = %v, want 13.82", got)
+	}
+}
+
 func TestTracker_CleanupDeletedTracks(t *testing.T) {
 	config := DefaultTrackerConfig()
 	config.HitsToConfirm = 1
@@ -676,3 +1034,59 @@ func TestTracker_Update_TraceLogging(t *testing.T) {
 		t.Fatalf("expected 1 active track, got %d", len(tracks))
 	}
 }
+
+// TestTracker_ShapeAssociationUnderOcclusion hides the back of a truck so
+// its cluster's centroid drifts towards a car in the next lane. Centroid
+// distance alone hands the cluster to the car; footprint overlap with the
+// truck's predicted box keeps it on the truck.
+func TestTracker_ShapeAssociationUnderOcclusion(t *testing.T) {
+	const dt = 0.2
+	newTrack := func(id string, x, y, length, width float32) *TrackedObject {
+		return &TrackedObject{
+			TrackID:          id,
+			TrackMeasurement: TrackMeasurement{TrackState: TrackConfirmed},
+			X:                x, Y: y,
+			OBBLength: length, OBBWidth: width,
+			P: [16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
+		}
+	}
+	// Only the front 4 m of the 10 m truck is visible. The points lie on
+	// its near side, pulling the centroid towards the car.
+	cluster := WorldCluster{
+		ClusterID: 1, CentroidX: 3.5, CentroidY: 0.5,
+		OBB: &l4perception.OrientedBoundingBox{CenterX: 3, Length: 4, Width: 2.5},
+	}
+
+	config := DefaultTrackerConfig()
+	config.MeasurementNoise = 0.05
+	config.GatingDistanceSquared = 36
+	for _, tc := range []struct {
+		weight float32
+		want   string
+	}{
+		// d² is 11.9 to the truck and 6.9 to the car.
+		{0, "car"},
+		// IoU is 0.4 with the truck and 0 with the car: the shape term
+		// adds 10.8 to the truck and 18 to the car.
+		{0.5, "truck"},
+	} {
+		cfg := config
+		cfg.ShapeAssociationWeight = tc.weight
+		tracker := NewTracker(cfg)
+		tracker.Tracks["truck"] = newTrack("truck", 0, 0, 10, 2.5)
+		tracker.Tracks["car"] = newTrack("car", 4.5, 3, 4.5, 1.8)
+		if got := tracker.associate([]WorldCluster{cluster}, dt)[0]; got != tc.want {
+			t.Errorf("weight %.1f: cluster went to %q, want %q", tc.weight, got, tc.want)
+		}
+	}
+
+	// Without a box on either side the shape term drops out.
+	tracker := NewTracker(config)
+	tracker.Config.ShapeAssociationWeight = 0.5
+	if c := tracker.shapeCost(&TrackedObject{}, cluster); c != 0 {
+		t.Errorf("shape cost for a track with no box = %v, want 0", c)
+	}
+	if c := tracker.shapeCost(newTrack("t", 0, 0, 4, 2), WorldCluster{}); c != 0 {
+		t.Errorf("shape cost for a cluster with no box = %v, want 0", c)
+	}
+}
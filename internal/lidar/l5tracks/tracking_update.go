@@ -39,6 +39,16 @@ func (t *Tracker) update(track *TrackedObject, cluster WorldCluster, nowNanos in
 	invS10 := -S10 / det
 	invS11 := S00 / det
 
+	nis := normalisedInnovationSquared(yX, yY, invS00, invS01, invS10, invS11)
+	if t.Config.RecordInnovations {
+		track.recordInnovation(InnovationSample{
+			TimestampNanos: nowNanos,
+			InnovationX:    yX,
+			InnovationY:    yY,
+			NIS:            nis,
+		})
+	}
+
 	// Kalman gain K = P * H^T * S^-1
 	// K is 4x2 matrix
 	// K[i,0] = P[i,0]*invS00 + P[i,1]*invS10
@@ -119,11 +129,17 @@ func (t *Tracker) update(track *TrackedObject, cluster WorldCluster, nowNanos in
 	// Update aggregated features
 	track.ObservationCount++
 
-	// Running average for bounding box
+	// Running average for bounding box, with Welford variance accumulators
 	n := float32(track.ObservationCount)
+	prevLength := track.BoundingBoxLengthAvg
+	prevWidth := track.BoundingBoxWidthAvg
+	prevHeight := track.BoundingBoxHeightAvg
 	track.BoundingBoxLengthAvg = ((n-1)*track.BoundingBoxLengthAvg + cluster.BoundingBoxLength) / n
 	track.BoundingBoxWidthAvg = ((n-1)*track.BoundingBoxWidthAvg + cluster.BoundingBoxWidth) / n
 	track.BoundingBoxHeightAvg = ((n-1)*track.BoundingBoxHeightAvg + cluster.BoundingBoxHeight) / n
+	track.BBoxLengthM2 += float64(cluster.BoundingBoxLength-prevLength) * float64(cluster.BoundingBoxLength-track.BoundingBoxLengthAvg)
+	track.BBoxWidthM2 += float64(cluster.BoundingBoxWidth-prevWidth) * float64(cluster.BoundingBoxWidth-track.BoundingBoxWidthAvg)
+	track.BBoxHeightM2 += float64(cluster.BoundingBoxHeight-prevHeight) * float64(cluster.BoundingBoxHeight-track.BoundingBoxHeightAvg)
 	track.IntensityMeanAvg = ((n-1)*track.IntensityMeanAvg + cluster.IntensityMean) / n
 
 	// Max height P95
@@ -133,10 +149,14 @@ func (t *Tracker) update(track *TrackedObject, cluster WorldCluster, nowNanos in
 
 	// Update speed statistics
 	speed := float32(math.Sqrt(float64(track.VX*track.VX + track.VY*track.VY)))
-	track.AvgSpeedMps = ((n-1)*track.AvgSpeedMps + speed) / n
-	if speed > track.MaxSpeedMps {
-		track.MaxSpeedMps = speed
+	track.InstantSpeedMps = speed
+	reportedSpeed := t.smoothedSpeed(track, speed)
+	track.AvgSpeedMps = ((n-1)*track.AvgSpeedMps + reportedSpeed) / n
+	if reportedSpeed > track.MaxSpeedMps {
+		track.MaxSpeedMps = reportedSpeed
 	}
+	// Misses still holds the frames coasted through before this one.
+	track.observeCompositeSpeed(speed, track.Misses, nis)
 
 	// Speed jitter: measure frame-to-frame speed change
 	if track.ObservationCount > 1 {
@@ -174,7 +194,7 @@ func (t *Tracker) update(track *TrackedObject, cluster WorldCluster, nowNanos in
 	}
 
 	// Store speed history for jitter/variance analysis
-	track.speedHistory = append(track.speedHistory, speed)
+	track.speedHistory = append(track.speedHistory, reportedSpeed)
 	if len(track.speedHistory) > t.Config.MaxSpeedHistoryLength {
 		track.speedHistory = track.speedHistory[1:]
 	}
@@ -366,5 +386,25 @@ func (t *Tracker) update(track *TrackedObject, cluster WorldCluster, nowNanos in
 			track.OBBHeight = cluster.OBB.Height
 		}
 		track.LatestZ = cluster.OBB.CenterZ
+		t.applyDimensionPrior(track, cluster.PointsCount)
+	}
+}
+
+// smoothedSpeed records speed in the track's raw speed window and returns
+// the mean of the last SpeedSmoothingFrames speeds. With smoothing
+// disabled it returns speed unchanged.
+func (t *Tracker) smoothedSpeed(track *TrackedObject, speed float32) float32 {
+	window := t.Config.SpeedSmoothingFrames
+	if window <= 1 {
+		return speed
+	}
+	track.rawSpeedWindow = append(track.rawSpeedWindow, speed)
+	if len(track.rawSpeedWindow) > window {
+		track.rawSpeedWindow = track.rawSpeedWindow[len(track.rawSpeedWindow)-window:]
+	}
+	var sum float32
+	for _, s := range track.rawSpeedWindow {
+		sum += s
 	}
+	return sum / float32(len(track.rawSpeedWindow))
 }
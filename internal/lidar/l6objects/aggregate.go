@@ -0,0 +1,112 @@
+package l6objects
+
+import "sort"
+
+// unclassifiedClass is the bucket used by ComputeClassStats for tracks
+// without an ObjectClass.
+const unclassifiedClass = "other"
+
+// ClassStats holds aggregate statistics for one classification category.
+type ClassStats struct {
+	Count           int     `json:"count"`
+	AvgSpeed        float32 `json:"avg_speed_mps"`
+	AvgDuration     float32 `json:"avg_duration_secs"`
+	AvgObservations float32 `json:"avg_observations"`
+}
+
+// SpeedStatistics holds distribution statistics over a set of speed samples.
+type SpeedStatistics struct {
+	MinSpeed float32 `json:"min_speed_mps"`
+	MaxSpeed float32 `json:"max_speed_mps"`
+	AvgSpeed float32 `json:"avg_speed_mps"`
+	P50Speed float32 `json:"p50_speed_mps"`
+	P85Speed float32 `json:"p85_speed_mps"`
+	P95Speed float32 `json:"p95_speed_mps"`
+}
+
+// ComputeClassStats groups tracks by ObjectClass and averages each track's
+// mean speed, duration and observation count per class. Tracks without a
+// class are grouped under "other". Tracks are not classified here; callers
+// that want classes filled in should run a TrackClassifier first.
+func ComputeClassStats(tracks []*TrackedObject) map[string]ClassStats {
+	return ComputeClassStatsBy(tracks, func(t *TrackedObject) string {
+		if t.ObjectClass == "" {
+			return unclassifiedClass
+		}
+		return t.ObjectClass
+	})
+}
+
+// ComputeClassStatsBy is ComputeClassStats with the class of each track
+// given by classOf, such as FallbackClassConfig.ReportClass.
+func ComputeClassStatsBy(tracks []*TrackedObject, classOf func(*TrackedObject) string) map[string]ClassStats {
+	stats := make(map[string]ClassStats)
+	byClass := make(map[string][]*TrackedObject)
+
+	for _, t := range tracks {
+		class := classOf(t)
+		byClass[class] = append(byClass[class], t)
+	}
+
+	for class, classTracks := range byClass {
+		var sumSpeed, sumDuration float32
+		var sumObs int
+		for _, t := range classTracks {
+			sumSpeed += t.AvgSpeedMps
+			sumDuration += float32(float64(t.EndUnixNanos-t.StartUnixNanos) / 1e9)
+			sumObs += t.ObservationCount
+		}
+		n := float32(len(classTracks))
+		stats[class] = ClassStats{
+			Count:           len(classTracks),
+			AvgSpeed:        sumSpeed / n,
+			AvgDuration:     sumDuration / n,
+			AvgObservations: float32(sumObs) / n,
+		}
+	}
+
+	return stats
+}
+
+// TrackSpeedSamples returns the positive per-track mean speeds, in track
+// order, for use with ComputeSpeedStatistics. Stationary tracks are skipped
+// so they do not drag the percentiles towards zero.
+func TrackSpeedSamples(tracks []*TrackedObject) []float32 {
+	var samples []float32
+	for _, t := range tracks {
+		if t.AvgSpeedMps > 0 {
+			samples = append(samples, t.AvgSpeedMps)
+		}
+	}
+	return samples
+}
+
+// ComputeSpeedStatistics returns min, max, mean and floor-indexed
+// percentiles (see ComputeSpeedPercentiles) of samples. An empty input
+// yields the zero value.
+func ComputeSpeedStatistics(samples []float32) SpeedStatistics {
+	if len(samples) == 0 {
+		return SpeedStatistics{}
+	}
+
+	sorted := make([]float32, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum float32
+	for _, s := range sorted {
+		sum += s
+	}
+
+	p50, p85, p95 := ComputeSpeedPercentiles(samples)
+
+	n := len(sorted)
+	return SpeedStatistics{
+		MinSpeed: sorted[0],
+		MaxSpeed: sorted[n-1],
+		AvgSpeed: sum / float32(n),
+		P50Speed: p50,
+		P85Speed: p85,
+		P95Speed: p95,
+	}
+}
@@ -0,0 +1,186 @@
+package l6objects
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// classReportFixture covers multiple classes, unclassified tracks,
+// stationary tracks and speeds whose float32 sums depend on ordering.
+func classReportFixture() []*TrackedObject {
+	const s = int64(1e9)
+	mk := aggregateTrack
+	return []*TrackedObject{
+		mk("car", 12.34, 100*s, 104*s+250e6, 43),
+		mk("pedestrian", 1.31, 100*s, 121*s, 210),
+		mk("car", 9.87, 130*s, 133*s+100e6, 31),
+		mk("", 0, 140*s, 141*s, 5),
+		mk("cyclist", 5.55, 150*s, 160*s+333e6, 104),
+		mk("car", 15.1, 170*s, 172*s+900e6, 29),
+		mk("", 0.42, 180*s, 180*s+500e6, 3),
+		mk("pedestrian", 1.07, 190*s, 215*s, 251),
+		mk("bus", 8.2, 200*s, 209*s, 90),
+		mk("car", 11.11, 210*s, 214*s+10e6, 41),
+	}
+}
+
+func aggregateTrack(class string, avg float32, startNs, endNs int64, obs int) *TrackedObject {
+	t := &TrackedObject{}
+	t.ObjectClass = class
+	t.AvgSpeedMps = avg
+	t.StartUnixNanos = startNs
+	t.EndUnixNanos = endNs
+	t.ObservationCount = obs
+	return t
+}
+
+func TestClassReport_Golden(t *testing.T) {
+	tracks := classReportFixture()
+	report := struct {
+		Classes map[string]ClassStats `json:"classification_distribution"`
+		Speed   SpeedStatistics       `json:"speed_statistics"`
+	}{
+		Classes: ComputeClassStats(tracks),
+		Speed:   ComputeSpeedStatistics(TrackSpeedSamples(tracks)),
+	}
+	got, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	got = append(got, '\n')
+
+	const golden = "testdata/class_report_golden.json"
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("writing golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("reading golden %s (run with -update to create): %v", golden, err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("class report differs from %s:\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
+
+// TestClassReport_GoldenIsHandDerived pins the golden file to values
+// worked out by hand from classReportFixture, so regenerating it with
+// -update cannot quietly accept a regression.
+func TestClassReport_GoldenIsHandDerived(t *testing.T) {
+	raw, err := os.ReadFile("testdata/class_report_golden.json")
+	if err != nil {
+		t.Fatalf("reading golden: %v", err)
+	}
+	var report struct {
+		Classes map[string]ClassStats `json:"classification_distribution"`
+		Speed   SpeedStatistics       `json:"speed_statistics"`
+	}
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatalf("decoding golden: %v", err)
+	}
+
+	want := map[string]ClassStats{
+		// (12.34 + 9.87 + 15.1 + 11.11)/4 m/s; (4.25 + 3.1 + 2.9 + 4.01)/4 s;
+		// (43 + 31 + 29 + 41)/4 observations.
+		"car": {Count: 4, AvgSpeed: 12.105, AvgDuration: 3.565, AvgObservations: 36},
+		// (1.31 + 1.07)/2; (21 + 25)/2; (210 + 251)/2.
+		"pedestrian": {Count: 2, AvgSpeed: 1.19, AvgDuration: 23, AvgObservations: 230.5},
+		// Unclassified: (0 + 0.42)/2; (1 + 0.5)/2; (5 + 3)/2.
+		"other":   {Count: 2, AvgSpeed: 0.21, AvgDuration: 0.75, AvgObservations: 4},
+		"cyclist": {Count: 1, AvgSpeed: 5.55, AvgDuration: 10.333, AvgObservations: 104},
+		"bus":     {Count: 1, AvgSpeed: 8.2, AvgDuration: 9, AvgObservations: 90},
+	}
+	near := func(a, b float32) bool { return math.Abs(float64(a-b)) < 1e-4 }
+	if len(report.Classes) != len(want) {
+		t.Errorf("golden has %d classes, want %d", len(report.Classes), len(want))
+	}
+	for class, w := range want {
+		g := report.Classes[class]
+		if g.Count != w.Count || !near(g.AvgSpeed, w.AvgSpeed) ||
+			!near(g.AvgDuration, w.AvgDuration) || !near(g.AvgObservations, w.AvgObservations) {
+			t.Errorf("golden %s = %+v, want %+v", class, g, w)
+		}
+	}
+
+	// The nine moving tracks sorted: 0.42 1.07 1.31 5.55 8.2 9.87 11.11
+	// 12.34 15.1, summing to 64.97. Floor-indexed percentiles take
+	// indices 4, 7 and 8.
+	wantSpeed := SpeedStatistics{
+		MinSpeed: 0.42, MaxSpeed: 15.1, AvgSpeed: 64.97 / 9,
+		P50Speed: 8.2, P85Speed: 12.34, P95Speed: 15.1,
+	}
+	g := report.Speed
+	if !near(g.MinSpeed, wantSpeed.MinSpeed) || !near(g.MaxSpeed, wantSpeed.MaxSpeed) ||
+		!near(g.AvgSpeed, wantSpeed.AvgSpeed) || !near(g.P50Speed, wantSpeed.P50Speed) ||
+		!near(g.P85Speed, wantSpeed.P85Speed) || !near(g.P95Speed, wantSpeed.P95Speed) {
+		t.Errorf("golden speed statistics = %+v, want %+v", g, wantSpeed)
+	}
+}
+
+func TestComputeClassStats_MultipleClasses(t *testing.T) {
+	tracks := []*TrackedObject{
+		aggregateTrack("car", 12, 0, 4e9, 40),
+		aggregateTrack("pedestrian", 1.5, 0, 20e9, 200),
+		aggregateTrack("car", 8, 0, 6e9, 60),
+		aggregateTrack("", 3, 0, 0, 0),
+	}
+
+	stats := ComputeClassStats(tracks)
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 classes, got %d: %+v", len(stats), stats)
+	}
+	car := stats["car"]
+	if car.Count != 2 || car.AvgSpeed != 10 || car.AvgDuration != 5 || car.AvgObservations != 50 {
+		t.Errorf("unexpected car stats: %+v", car)
+	}
+	if p := stats["pedestrian"]; p.Count != 1 || math.Abs(float64(p.AvgSpeed)-1.5) > 1e-6 {
+		t.Errorf("unexpected pedestrian stats: %+v", p)
+	}
+	if o := stats["other"]; o.Count != 1 || o.AvgSpeed != 3 {
+		t.Errorf("unclassified tracks should be grouped as other: %+v", o)
+	}
+
+	if got := ComputeClassStats(nil); len(got) != 0 {
+		t.Errorf("expected empty stats for nil input, got %+v", got)
+	}
+}
+
+func TestComputeSpeedStatistics(t *testing.T) {
+	samples := make([]float32, 10)
+	for i := range samples {
+		samples[i] = float32(10 - i)
+	}
+	stats := ComputeSpeedStatistics(samples)
+	want := SpeedStatistics{MinSpeed: 1, MaxSpeed: 10, AvgSpeed: 5.5, P50Speed: 6, P85Speed: 9, P95Speed: 10}
+	if stats != want {
+		t.Errorf("got %+v, want %+v", stats, want)
+	}
+	if samples[0] != 10 {
+		t.Error("input samples were reordered")
+	}
+
+	if got := ComputeSpeedStatistics(nil); got != (SpeedStatistics{}) {
+		t.Errorf("expected zero value for nil input, got %+v", got)
+	}
+	if got := ComputeSpeedStatistics([]float32{7.5}); got.MinSpeed != 7.5 || got.MaxSpeed != 7.5 || got.P95Speed != 7.5 {
+		t.Errorf("unexpected single-sample stats: %+v", got)
+	}
+}
+
+func TestTrackSpeedSamples_SkipsStationary(t *testing.T) {
+	tracks := []*TrackedObject{
+		aggregateTrack("", 2, 0, 0, 0),
+		aggregateTrack("", 0, 0, 0, 0),
+		aggregateTrack("", 4, 0, 0, 0),
+	}
+	got := TrackSpeedSamples(tracks)
+	if len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Errorf("got %v, want [2 4]", got)
+	}
+}
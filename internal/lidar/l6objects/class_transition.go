@@ -0,0 +1,210 @@
+package l6objects
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ClassTransition is a directed change of reported class.
+type ClassTransition struct {
+	From ObjectClass
+	To   ObjectClass
+}
+
+// ClassTransitionConfig sets how much evidence a track must show before its
+// reported class may change. Each classification pass that agrees on a
+// candidate class adds its confidence to that candidate's evidence; the
+// reported class switches once the evidence reaches the transition cost and
+// the candidate has won at least MinConsecutive passes in a row. A pass
+// that disagrees with the candidate resets the evidence.
+//
+// Physically plausible confusions (cyclist↔pedestrian, car↔truck) are
+// cheap; implausible ones (car↔pedestrian) need several confident passes.
+type ClassTransitionConfig struct {
+	// Costs holds the evidence needed for specific transitions.
+	Costs map[ClassTransition]float32
+	// DefaultCost applies to transitions missing from Costs.
+	DefaultCost float32
+	// MinConsecutive is the number of consecutive passes a candidate must
+	// win before it can replace the reported class.
+	MinConsecutive int
+	// StaleAfter drops state for tracks not seen for this long, measured
+	// on track time. Stale state is swept at most once per StaleAfter.
+	// Zero keeps state until Forget is called.
+	StaleAfter time.Duration
+}
+
+// DefaultClassTransitionConfig returns the transition costs used by
+// NewTrackClassifier. Classification confidence lies between LowConfidence
+// and HighConfidence, so a cost of 0.6 flips on two agreeing passes while
+// a cost of 3.0 needs four to six.
+func DefaultClassTransitionConfig() ClassTransitionConfig {
+	cfg := ClassTransitionConfig{
+		Costs:          make(map[ClassTransition]float32),
+		DefaultCost:    1.5,
+		MinConsecutive: 2,
+		StaleAfter:     time.Minute,
+	}
+	cfg.SetCost(ClassCyclist, ClassPedestrian, 0.6)
+	cfg.SetCost(ClassCyclist, ClassMotorcyclist, 0.6)
+	cfg.SetCost(ClassCar, ClassTruck, 0.8)
+	cfg.SetCost(ClassTruck, ClassBus, 0.8)
+	cfg.SetCost(ClassCar, ClassMotorcyclist, 1.2)
+	cfg.SetCost(ClassCar, ClassBus, 2.0)
+	cfg.SetCost(ClassCar, ClassCyclist, 2.5)
+	cfg.SetCost(ClassCar, ClassPedestrian, 3.0)
+	cfg.SetCost(ClassTruck, ClassPedestrian, 3.0)
+	cfg.SetCost(ClassBus, ClassPedestrian, 3.0)
+	cfg.SetCost(ClassBird, ClassCar, 3.0)
+	for _, c := range []ObjectClass{ClassCar, ClassTruck, ClassBus, ClassPedestrian, ClassCyclist, ClassMotorcyclist, ClassBird} {
+		// Leaving the unclassified fallback is free; falling back to it
+		// from a real class should not happen on a weak pass.
+		cfg.Costs[ClassTransition{From: ClassDynamic, To: c}] = 0
+		cfg.Costs[ClassTransition{From: c, To: ClassDynamic}] = 2.0
+	}
+	return cfg
+}
+
+// SetCost sets the cost of changing between a and b in both directions.
+func (c *ClassTransitionConfig) SetCost(a, b ObjectClass, cost float32) {
+	if c.Costs == nil {
+		c.Costs = make(map[ClassTransition]float32)
+	}
+	c.Costs[ClassTransition{From: a, To: b}] = cost
+	c.Costs[ClassTransition{From: b, To: a}] = cost
+}
+
+// Cost returns the evidence needed to change from one class to another.
+func (c ClassTransitionConfig) Cost(from, to ObjectClass) float32 {
+	if cost, ok := c.Costs[ClassTransition{From: from, To: to}]; ok {
+		return cost
+	}
+	return c.DefaultCost
+}
+
+// Validate checks that costs are non-negative.
+func (c ClassTransitionConfig) Validate() error {
+	if c.DefaultCost < 0 {
+		return fmt.Errorf("class transition default cost must be >= 0, got %v", c.DefaultCost)
+	}
+	for t, cost := range c.Costs {
+		if cost < 0 {
+			return fmt.Errorf("class transition %s->%s cost must be >= 0, got %v", t.From, t.To, cost)
+		}
+	}
+	if c.MinConsecutive < 0 {
+		return fmt.Errorf("class transition min consecutive must be >= 0, got %d", c.MinConsecutive)
+	}
+	return nil
+}
+
+// classTrackState is the per-track state of a ClassStabiliser.
+type classTrackState struct {
+	stable     ObjectClass
+	confidence float32 // Confidence of the latest pass agreeing with stable
+
+	candidate ObjectClass
+	evidence  float32
+	streak    int
+
+	lastNanos int64
+}
+
+// ClassStabiliser turns per-pass classification results into a stable
+// reported class per track, following a ClassTransitionConfig. It is safe
+// for concurrent use.
+type ClassStabiliser struct {
+	config ClassTransitionConfig
+
+	mu             sync.Mutex
+	tracks         map[string]*classTrackState
+	lastPruneNanos int64
+}
+
+// NewClassStabiliser creates a stabiliser with the given transition costs.
+func NewClassStabiliser(config ClassTransitionConfig) (*ClassStabiliser, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &ClassStabiliser{config: config, tracks: make(map[string]*classTrackState)}, nil
+}
+
+// Update records one classification pass for a track and returns the
+// result to report: the raw result while the track has no stable class or
+// when it agrees, otherwise the stable class with its last confidence.
+func (s *ClassStabiliser) Update(trackID string, nowNanos int64, result ClassificationResult) ClassificationResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(nowNanos)
+
+	st, ok := s.tracks[trackID]
+	if ok && s.staleLocked(st, nowNanos) {
+		ok = false // not yet swept, but starts afresh all the same
+	}
+	if !ok {
+		s.tracks[trackID] = &classTrackState{stable: result.Class, confidence: result.Confidence, lastNanos: nowNanos}
+		return result
+	}
+	st.lastNanos = nowNanos
+
+	if result.Class == st.stable {
+		st.confidence = result.Confidence
+		st.candidate, st.evidence, st.streak = "", 0, 0
+		return result
+	}
+
+	if result.Class != st.candidate {
+		st.candidate, st.evidence, st.streak = result.Class, 0, 0
+	}
+	st.evidence += result.Confidence
+	st.streak++
+
+	if st.streak >= s.config.MinConsecutive && st.evidence >= s.config.Cost(st.stable, st.candidate) {
+		diagf("Class transition accepted: track_id=%s class=%s->%s evidence=%.2f passes=%d",
+			trackID, st.stable, st.candidate, st.evidence, st.streak)
+		st.stable, st.confidence = st.candidate, result.Confidence
+		st.candidate, st.evidence, st.streak = "", 0, 0
+		return result
+	}
+
+	tracef("Class transition held: track_id=%s class=%s candidate=%s evidence=%.2f/%.2f passes=%d",
+		trackID, st.stable, st.candidate, st.evidence, s.config.Cost(st.stable, st.candidate), st.streak)
+	held := result
+	held.Class = st.stable
+	held.Confidence = st.confidence
+	return held
+}
+
+// Forget drops the state for a track, e.g. once it has been finalised.
+func (s *ClassStabiliser) Forget(trackID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tracks, trackID)
+}
+
+// staleLocked reports whether st was last updated more than StaleAfter
+// before nowNanos.
+func (s *ClassStabiliser) staleLocked(st *classTrackState, nowNanos int64) bool {
+	return s.config.StaleAfter > 0 && st.lastNanos < nowNanos-s.config.StaleAfter.Nanoseconds()
+}
+
+// pruneLocked drops tracks not updated within StaleAfter of nowNanos. The
+// sweep is O(tracks), so it runs at most once per StaleAfter of track time
+// (or whenever time steps backwards, as on a replay seek) rather than on
+// every update.
+func (s *ClassStabiliser) pruneLocked(nowNanos int64) {
+	if s.config.StaleAfter <= 0 {
+		return
+	}
+	if since := nowNanos - s.lastPruneNanos; since >= 0 && since < s.config.StaleAfter.Nanoseconds() {
+		return
+	}
+	s.lastPruneNanos = nowNanos
+	for id, st := range s.tracks {
+		if s.staleLocked(st, nowNanos) {
+			delete(s.tracks, id)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package l6objects
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// setTrackShape overwrites a track's averaged dimensions and speeds so the
+// next classification pass sees a different object.
+func setTrackShape(track *TrackedObject, height, length, width, speed float32) {
+	track.BoundingBoxHeightAvg = height
+	track.BoundingBoxLengthAvg = length
+	track.BoundingBoxWidthAvg = width
+	track.HeightP95Max = height
+	track.AvgSpeedMps = speed
+	track.MaxSpeedMps = speed * 1.2
+	speeds := make([]float32, 20)
+	for i := range speeds {
+		speeds[i] = speed
+	}
+	track.SetSpeedHistory(speeds)
+}
+
+func TestClassifyAndUpdate_OneFrameSpuriousClassKeepsStableClass(t *testing.T) {
+	classifier := NewTrackClassifier()
+	track := &TrackedObject{
+		TrackID:          "spurious",
+		TrackMeasurement: l5tracks.TrackMeasurement{ObservationCount: 20},
+	}
+	asCar := func() { setTrackShape(track, 1.5, 4.5, 1.9, 12) }
+	asPedestrian := func() { setTrackShape(track, 1.7, 0.5, 0.5, 1.2) }
+	pass := func() ObjectClass {
+		track.EndUnixNanos += int64(500 * time.Millisecond)
+		classifier.ClassifyAndUpdate(track)
+		return ObjectClass(track.ObjectClass)
+	}
+
+	asPedestrian()
+	if raw := classifier.Classify(track).Class; raw != ClassPedestrian {
+		t.Fatalf("test shape classifies as %s, want pedestrian", raw)
+	}
+
+	asCar()
+	if got := pass(); got != ClassCar {
+		t.Fatalf("first pass = %s, want car", got)
+	}
+	carConfidence := track.ObjectConfidence
+
+	asPedestrian()
+	if got := pass(); got != ClassCar {
+		t.Errorf("one spurious pedestrian pass changed the class to %s", got)
+	}
+	if track.ObjectConfidence != carConfidence {
+		t.Errorf("held class should keep its confidence %.2f, got %.2f", carConfidence, track.ObjectConfidence)
+	}
+
+	asCar()
+	for i := 0; i < 3; i++ {
+		if got := pass(); got != ClassCar {
+			t.Fatalf("pass %d = %s, want car", i, got)
+		}
+	}
+
+	// Sustained evidence does change the class.
+	asPedestrian()
+	passes := 0
+	for pass() != ClassPedestrian {
+		passes++
+		if passes > 10 {
+			t.Fatal("sustained pedestrian evidence never changed the class")
+		}
+	}
+	if passes < 2 {
+		t.Errorf("car->pedestrian flipped after %d held passes; expected it to be expensive", passes)
+	}
+}
+
+func TestClassStabiliser_CheapAndExpensiveTransitions(t *testing.T) {
+	s, err := NewClassStabiliser(DefaultClassTransitionConfig())
+	if err != nil {
+		t.Fatalf("NewClassStabiliser: %v", err)
+	}
+	result := func(c ObjectClass) ClassificationResult {
+		return ClassificationResult{Class: c, Confidence: MediumConfidence}
+	}
+	passesToFlip := func(id string, from, to ObjectClass) int {
+		s.Update(id, 0, result(from))
+		for n := 1; n <= 20; n++ {
+			if s.Update(id, 0, result(to)).Class == to {
+				return n
+			}
+		}
+		return -1
+	}
+
+	if n := passesToFlip("bike", ClassCyclist, ClassPedestrian); n != 2 {
+		t.Errorf("cyclist->pedestrian took %d passes, want 2", n)
+	}
+	if n := passesToFlip("car", ClassCar, ClassPedestrian); n != 5 {
+		t.Errorf("car->pedestrian took %d passes, want 5", n)
+	}
+	if n := passesToFlip("new", ClassDynamic, ClassCar); n != 2 {
+		t.Errorf("dynamic->car took %d passes, want MinConsecutive", n)
+	}
+
+	// Alternating candidates never accumulate evidence.
+	s.Update("noisy", 0, result(ClassCar))
+	for i := 0; i < 10; i++ {
+		c := ClassPedestrian
+		if i%2 == 1 {
+			c = ClassCyclist
+		}
+		if got := s.Update("noisy", 0, result(c)).Class; got != ClassCar {
+			t.Fatalf("pass %d: alternating candidates changed the class to %s", i, got)
+		}
+	}
+}
+
+func TestClassStabiliser_ForgetAndStale(t *testing.T) {
+	cfg := DefaultClassTransitionConfig()
+	cfg.StaleAfter = time.Second
+	s, err := NewClassStabiliser(cfg)
+	if err != nil {
+		t.Fatalf("NewClassStabiliser: %v", err)
+	}
+	car := ClassificationResult{Class: ClassCar, Confidence: HighConfidence}
+	ped := ClassificationResult{Class: ClassPedestrian, Confidence: HighConfidence}
+
+	s.Update("a", 0, car)
+	s.Forget("a")
+	if got := s.Update("a", 0, ped).Class; got != ClassPedestrian {
+		t.Errorf("forgotten track should start afresh, got %s", got)
+	}
+
+	s.Update("b", 0, car)
+	s.Update("c", int64(2*time.Second), car)
+	if got := s.Update("b", int64(2*time.Second), ped).Class; got != ClassPedestrian {
+		t.Errorf("stale track should start afresh, got %s", got)
+	}
+}
+
+func TestClassStabiliser_PrunesPeriodically(t *testing.T) {
+	cfg := DefaultClassTransitionConfig()
+	cfg.StaleAfter = time.Second
+	s, err := NewClassStabiliser(cfg)
+	if err != nil {
+		t.Fatalf("NewClassStabiliser: %v", err)
+	}
+	car := ClassificationResult{Class: ClassCar, Confidence: HighConfidence}
+	sec := int64(time.Second)
+
+	s.Update("a", 0, car)
+	s.Update("b", sec/2, car)
+	// The first sweep is due one StaleAfter after t=0, at c's update.
+	s.Update("c", sec+sec/2, car)
+	if len(s.tracks) != 2 {
+		t.Errorf("after sweep at 1.5 s: %d tracks, want 2 (a dropped)", len(s.tracks))
+	}
+	s.Update("c", 2*sec, car)
+	if len(s.tracks) != 2 {
+		t.Errorf("no sweep within StaleAfter of the last: %d tracks, want 2", len(s.tracks))
+	}
+	s.Update("c", 3*sec, car)
+	if len(s.tracks) != 1 {
+		t.Errorf("after sweep at 3 s: %d tracks, want 1 (b dropped)", len(s.tracks))
+	}
+}
+
+func TestClassTransitionConfig_Validate(t *testing.T) {
+	if err := DefaultClassTransitionConfig().Validate(); err != nil {
+		t.Fatalf("defaults invalid: %v", err)
+	}
+	cfg := DefaultClassTransitionConfig()
+	cfg.SetCost(ClassCar, ClassBird, -1)
+	if _, err := NewClassStabiliser(cfg); err == nil {
+		t.Error("expected an error for a negative cost")
+	}
+	if got := DefaultClassTransitionConfig().Cost(ClassPedestrian, ClassCyclist); got != 0.6 {
+		t.Errorf("costs should be symmetric, pedestrian->cyclist = %v", got)
+	}
+}
@@ -76,6 +76,13 @@ const (
 	HighConfidence   = 0.85
 	MediumConfidence = 0.70
 	LowConfidence    = 0.50
+
+	// DefaultSizeInstabilityWeight scales the confidence penalty applied to
+	// tracks whose bounding box dimensions vary frame to frame. A weight of
+	// 0.5 halves confidence when the mean dimension coefficient of variation
+	// reaches 1.0; zero disables the gate. Tuning sets it with
+	// l5.cv_kf_v1.size_instability_weight.
+	DefaultSizeInstabilityWeight = 0.5
 )
 
 // ClassificationResult holds the result of track classification.
@@ -94,6 +101,11 @@ type ClassificationFeatures struct {
 	AvgWidth  float32 // Average bounding box width
 	HeightP95 float32 // Maximum P95 height
 
+	// Size stability features (sample std-dev of per-frame dimensions)
+	LengthStdDev float32
+	WidthStdDev  float32
+	HeightStdDev float32
+
 	// Kinematic features
 	AvgSpeed float32 // Average speed
 	MaxSpeed float32 // Max speed
@@ -122,12 +134,28 @@ func clampConfidence(value, min, max float32) float32 {
 type TrackClassifier struct {
 	ModelVersion    string
 	MinObservations int // Minimum observations before classification
+
+	// SizeInstabilityWeight scales the confidence penalty for temporally
+	// unstable bounding box dimensions. Zero disables the penalty.
+	SizeInstabilityWeight float32
+
+	// Stabiliser holds the reported class steady against brief
+	// misclassifications in ClassifyAndUpdate. Nil reports every pass
+	// as is.
+	Stabiliser *ClassStabiliser
+
+	// ExplanationSink, when set, receives an explanation of every
+	// ClassifyAndUpdate decision (see ClassificationExplanation). Nil, the
+	// default, builds none.
+	ExplanationSink func(ClassificationExplanation)
 }
 
 // NewTrackClassifier creates a new track classifier.
 func NewTrackClassifier() *TrackClassifier {
 	cfg := config.MustLoadDefaultConfig()
-	return NewTrackClassifierWithMinObservations(cfg.GetMinObservationsForClassification())
+	classifier := NewTrackClassifierWithMinObservations(cfg.GetMinObservationsForClassification())
+	classifier.SizeInstabilityWeight = float32(cfg.GetSizeInstabilityWeight())
+	return classifier
 }
 
 // NewTrackClassifierWithMinObservations creates a new classifier with an
@@ -136,9 +164,15 @@ func NewTrackClassifierWithMinObservations(minObservations int) *TrackClassifier
 	if minObservations <= 0 {
 		minObservations = 1
 	}
+	stabiliser, err := NewClassStabiliser(DefaultClassTransitionConfig())
+	if err != nil {
+		panic(err) // the defaults are valid
+	}
 	classifier := &TrackClassifier{
-		ModelVersion:    "rule-based-v1.2",
-		MinObservations: minObservations,
+		ModelVersion:          "rule-based-v1.2",
+		MinObservations:       minObservations,
+		SizeInstabilityWeight: DefaultSizeInstabilityWeight,
+		Stabiliser:            stabiliser,
 	}
 	diagf("Track classifier created: model=%s min_observations=%d",
 		classifier.ModelVersion, classifier.MinObservations)
@@ -163,7 +197,7 @@ func (tc *TrackClassifier) ClassifyFeatures(features ClassificationFeatures) Cla
 	}
 	finish := func(class ObjectClass, confidence float32) ClassificationResult {
 		result.Class = class
-		result.Confidence = confidence
+		result.Confidence = tc.applySizeInstabilityPenalty(confidence, features)
 		if traceLogger != nil {
 			tracef("Classification result: class=%s confidence=%.2f observations=%d avg_speed=%.2f length=%.2f width=%.2f height=%.2f",
 				result.Class, result.Confidence, features.ObservationCount, features.AvgSpeed,
@@ -225,6 +259,12 @@ func (tc *TrackClassifier) ClassifyFeatures(features ClassificationFeatures) Cla
 
 // extractFeatures extracts classification features from a track.
 func (tc *TrackClassifier) extractFeatures(track *TrackedObject) ClassificationFeatures {
+	return ExtractClassificationFeatures(track)
+}
+
+// ExtractClassificationFeatures returns the features the rule-based
+// classifier sees for a track.
+func ExtractClassificationFeatures(track *TrackedObject) ClassificationFeatures {
 	features := ClassificationFeatures{
 		AvgHeight:        track.BoundingBoxHeightAvg,
 		AvgLength:        track.BoundingBoxLengthAvg,
@@ -235,6 +275,8 @@ func (tc *TrackClassifier) extractFeatures(track *TrackedObject) ClassificationF
 		ObservationCount: track.ObservationCount,
 	}
 
+	features.LengthStdDev, features.WidthStdDev, features.HeightStdDev = track.BoundingBoxStdDev()
+
 	// Compute speed percentiles from history using shared function
 	speedHistory := track.SpeedHistory()
 	if len(speedHistory) > 0 {
@@ -249,6 +291,40 @@ func (tc *TrackClassifier) extractFeatures(track *TrackedObject) ClassificationF
 	return features
 }
 
+// SizeInstability returns the mean coefficient of variation of the bounding
+// box dimensions (std-dev / mean), averaged over dimensions with a positive
+// mean. Stable objects score near zero; clustering artefacts whose extent
+// swings frame to frame score high.
+func (f ClassificationFeatures) SizeInstability() float32 {
+	var sum float32
+	var n int
+	for _, d := range [][2]float32{
+		{f.LengthStdDev, f.AvgLength},
+		{f.WidthStdDev, f.AvgWidth},
+		{f.HeightStdDev, f.AvgHeight},
+	} {
+		if d[1] > 0 {
+			sum += d[0] / d[1]
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float32(n)
+}
+
+// applySizeInstabilityPenalty scales confidence down by the weighted size
+// instability so a single confidence threshold can filter unstable tracks
+// whose mean dimensions still match a class.
+func (tc *TrackClassifier) applySizeInstabilityPenalty(confidence float32, f ClassificationFeatures) float32 {
+	if tc.SizeInstabilityWeight <= 0 {
+		return confidence
+	}
+	penalty := clampConfidence(tc.SizeInstabilityWeight*f.SizeInstability(), 0, 1)
+	return clampConfidence(confidence*(1-penalty), 0, 1)
+}
+
 // isBird checks if features match bird classification.
 func (tc *TrackClassifier) isBird(f ClassificationFeatures) bool {
 	return f.AvgHeight < BirdHeightMax &&
@@ -494,10 +570,22 @@ func (tc *TrackClassifier) pedestrianConfidence(f ClassificationFeatures) float3
 }
 
 // ClassifyAndUpdate classifies a track and updates its classification fields.
-// This should be called periodically or when track state changes.
+// This should be called periodically or when track state changes. With a
+// Stabiliser, the reported class only changes once the transition cost is
+// met (see ClassTransitionConfig).
 func (tc *TrackClassifier) ClassifyAndUpdate(track *TrackedObject) {
 	prevClass := track.ObjectClass
-	result := tc.Classify(track)
+	raw := tc.Classify(track)
+	result := raw
+	if tc.Stabiliser != nil {
+		result = tc.Stabiliser.Update(track.TrackID, track.EndUnixNanos, result)
+	}
+	if tc.ExplanationSink != nil {
+		e := tc.explain(raw)
+		e.TrackID = track.TrackID
+		e.ReportedClass = result.Class
+		tc.ExplanationSink(e)
+	}
 	track.ObjectClass = string(result.Class)
 	track.ObjectConfidence = result.Confidence
 	track.ClassificationModel = result.Model
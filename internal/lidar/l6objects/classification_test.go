@@ -602,3 +602,62 @@ func TestTrackClassifier_Classify_WithDuration(t *testing.T) {
 		t.Error("expected a non-empty classification")
 	}
 }
+
+func TestTrackClassifier_SizeInstabilityPenalty(t *testing.T) {
+	classifier := NewTrackClassifierWithMinObservations(5)
+
+	newCarTrack := func(id string, lengths, widths, heights []float32) *TrackedObject {
+		track := &TrackedObject{TrackID: id}
+		for i := range lengths {
+			n := float32(i + 1)
+			prevL, prevW, prevH := track.BoundingBoxLengthAvg, track.BoundingBoxWidthAvg, track.BoundingBoxHeightAvg
+			track.BoundingBoxLengthAvg += (lengths[i] - prevL) / n
+			track.BoundingBoxWidthAvg += (widths[i] - prevW) / n
+			track.BoundingBoxHeightAvg += (heights[i] - prevH) / n
+			track.BBoxLengthM2 += float64(lengths[i]-prevL) * float64(lengths[i]-track.BoundingBoxLengthAvg)
+			track.BBoxWidthM2 += float64(widths[i]-prevW) * float64(widths[i]-track.BoundingBoxWidthAvg)
+			track.BBoxHeightM2 += float64(heights[i]-prevH) * float64(heights[i]-track.BoundingBoxHeightAvg)
+			track.ObservationCount++
+		}
+		track.AvgSpeedMps = 10
+		track.MaxSpeedMps = 12
+		return track
+	}
+
+	stable := newCarTrack("stable",
+		[]float32{4.5, 4.5, 4.6, 4.4, 4.5, 4.5, 4.6, 4.4},
+		[]float32{2.0, 2.0, 2.0, 1.9, 2.1, 2.0, 2.0, 2.0},
+		[]float32{1.5, 1.5, 1.5, 1.5, 1.5, 1.5, 1.5, 1.5})
+	// Same mean dimensions, but the extent swings wildly frame to frame.
+	jittery := newCarTrack("jittery",
+		[]float32{1.5, 7.5, 2.0, 7.0, 1.5, 7.5, 2.0, 7.0},
+		[]float32{0.8, 3.2, 1.0, 3.0, 0.8, 3.2, 1.0, 3.0},
+		[]float32{0.5, 2.5, 0.7, 2.3, 0.5, 2.5, 0.7, 2.3})
+
+	stableResult := classifier.Classify(stable)
+	jitteryResult := classifier.Classify(jittery)
+
+	if stableResult.Class != ClassCar || jitteryResult.Class != ClassCar {
+		t.Fatalf("expected both tracks to classify as car, got stable=%s jittery=%s",
+			stableResult.Class, jitteryResult.Class)
+	}
+	if jitteryResult.Confidence >= stableResult.Confidence {
+		t.Errorf("expected jittery confidence (%.3f) < stable confidence (%.3f)",
+			jitteryResult.Confidence, stableResult.Confidence)
+	}
+	if jitteryResult.Confidence >= MediumConfidence {
+		t.Errorf("expected jittery confidence below %.2f, got %.3f", MediumConfidence, jitteryResult.Confidence)
+	}
+
+	// Disabling the gate restores the unpenalised confidence.
+	classifier.SizeInstabilityWeight = 0
+	if got := classifier.Classify(jittery).Confidence; got < stableResult.Confidence {
+		t.Errorf("expected unpenalised confidence >= %.3f, got %.3f", stableResult.Confidence, got)
+	}
+}
+
+func TestClassificationFeatures_SizeInstability_ZeroMeans(t *testing.T) {
+	if got := (ClassificationFeatures{LengthStdDev: 1}).SizeInstability(); got != 0 {
+		t.Errorf("expected 0 instability with zero mean dimensions, got %f", got)
+	}
+}
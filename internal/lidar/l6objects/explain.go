@@ -0,0 +1,198 @@
+package l6objects
+
+import "math"
+
+// Classification explanations.
+//
+// With TrackClassifier.ExplanationSink set, ClassifyAndUpdate reports every
+// decision: the features the classifier saw, each rule of the cascade up to
+// the one that fired with its threshold checks and their margins, and the
+// deciding check. Explanations are built only when a sink is set.
+//
+// A rule is a conjunction of clauses and a clause a disjunction of
+// threshold checks, restating the is* predicates so they can be inspected.
+// A check's margin is its distance from the threshold as a fraction of the
+// threshold, positive when it passes; a clause takes the margin of its best
+// check and a rule that of its worst clause. The deciding check sets the
+// fired rule's margin: of the checks the rule needed, the one nearest its
+// threshold, so the feature whose change would first overturn the
+// decision. When no rule fires the track is dynamic by default and nothing
+// decides it.
+
+// ClassificationCheck is one threshold test of a feature.
+type ClassificationCheck struct {
+	Feature   string  `json:"feature"`
+	Op        string  `json:"op"` // <, <=, > or >=
+	Value     float32 `json:"value"`
+	Threshold float32 `json:"threshold"`
+	Passed    bool    `json:"passed"`
+	Margin    float32 `json:"margin"`
+}
+
+// ClassificationRule is one step of the cascade. It fires when every
+// clause has a passing check.
+type ClassificationRule struct {
+	Class   ObjectClass             `json:"class"`
+	Fired   bool                    `json:"fired"`
+	Margin  float32                 `json:"margin"`
+	Clauses [][]ClassificationCheck `json:"clauses"`
+}
+
+// ClassificationExplanation records one classification decision.
+type ClassificationExplanation struct {
+	TrackID    string             `json:"track_id,omitempty"`
+	Model      string             `json:"model"`
+	Features   map[string]float32 `json:"features"`
+	Class      ObjectClass        `json:"class"`
+	Confidence float32            `json:"confidence"`
+	// ReportedClass is the class after the stabiliser, which may hold
+	// the track's previous class.
+	ReportedClass ObjectClass `json:"reported_class,omitempty"`
+	// Rules are in cascade order, ending with the rule that fired.
+	Rules    []ClassificationRule `json:"rules"`
+	Deciding *ClassificationCheck `json:"deciding,omitempty"`
+}
+
+// explainCheck is a check in a rule table.
+type explainCheck struct {
+	feature   string
+	op        string
+	threshold float32
+}
+
+// explainRule is a rule in the cascade table.
+type explainRule struct {
+	class   ObjectClass
+	clauses [][]explainCheck
+}
+
+// explainCascade restates the enabled rules of ClassifyFeatures after the
+// observation gate, in order. Keep it in step with the is* predicates;
+// TestExplainFeatures_AgreesWithClassifier checks that it is.
+var explainCascade = []explainRule{
+	{ClassBird, [][]explainCheck{
+		{{"avg_height", "<", BirdHeightMax}},
+		{{"avg_speed", "<", BirdSpeedMax}},
+		{{"avg_length", "<", 1.0}},
+		{{"avg_width", "<", 1.0}},
+	}},
+	{ClassBus, [][]explainCheck{
+		{{"avg_length", ">", BusLengthMin}},
+		{{"avg_width", ">", BusWidthMin}},
+		{{"avg_speed", ">", VehicleSpeedMin}, {"max_speed", ">", VehicleSpeedMin * 1.5}, {"avg_height", ">", VehicleHeightMin}},
+	}},
+	{ClassCar, [][]explainCheck{
+		{{"avg_length", ">", VehicleLengthMin}, {"avg_width", ">", VehicleWidthMin}},
+		{{"avg_speed", ">", VehicleSpeedMin}, {"max_speed", ">", VehicleSpeedMin * 1.5}, {"avg_height", ">", VehicleHeightMin}},
+	}},
+	{ClassCyclist, [][]explainCheck{
+		{{"avg_height", ">=", CyclistHeightMin}},
+		{{"avg_height", "<=", CyclistHeightMax}},
+		{{"avg_speed", ">=", CyclistSpeedMin}},
+		{{"avg_speed", "<=", CyclistSpeedMax}},
+		{{"avg_width", "<", CyclistWidthMax}},
+		{{"avg_length", "<", CyclistLengthMax}},
+	}},
+	{ClassPedestrian, [][]explainCheck{
+		{{"avg_height", ">=", PedestrianHeightMin}},
+		{{"avg_height", "<=", PedestrianHeightMax}},
+		{{"avg_speed", "<=", PedestrianSpeedMax}},
+		{{"avg_length", "<", VehicleLengthMin}},
+		{{"avg_width", "<", VehicleWidthMin}},
+	}},
+}
+
+// featureValues returns the features by the names checks use.
+func featureValues(f ClassificationFeatures) map[string]float32 {
+	return map[string]float32{
+		"avg_height":        f.AvgHeight,
+		"avg_length":        f.AvgLength,
+		"avg_width":         f.AvgWidth,
+		"height_p95":        f.HeightP95,
+		"avg_speed":         f.AvgSpeed,
+		"max_speed":         f.MaxSpeed,
+		"p50_speed":         f.P50Speed,
+		"p85_speed":         f.P85Speed,
+		"p95_speed":         f.P95Speed,
+		"observation_count": float32(f.ObservationCount),
+		"duration_secs":     f.DurationSecs,
+		"size_instability":  f.SizeInstability(),
+	}
+}
+
+// evaluate runs c against the feature values.
+func (c explainCheck) evaluate(values map[string]float32) ClassificationCheck {
+	v := values[c.feature]
+	out := ClassificationCheck{Feature: c.feature, Op: c.op, Value: v, Threshold: c.threshold}
+	diff := v - c.threshold
+	switch c.op {
+	case "<":
+		out.Passed, diff = v < c.threshold, -diff
+	case "<=":
+		out.Passed, diff = v <= c.threshold, -diff
+	case ">":
+		out.Passed = v > c.threshold
+	case ">=":
+		out.Passed = v >= c.threshold
+	}
+	if c.threshold != 0 {
+		diff /= float32(math.Abs(float64(c.threshold)))
+	}
+	out.Margin = diff
+	return out
+}
+
+// evaluate runs r and returns it with the check that sets its margin.
+func (r explainRule) evaluate(values map[string]float32) (ClassificationRule, *ClassificationCheck) {
+	out := ClassificationRule{Class: r.class, Fired: true, Margin: float32(math.Inf(1))}
+	var deciding *ClassificationCheck
+	for _, clause := range r.clauses {
+		checks := make([]ClassificationCheck, len(clause))
+		best := -1
+		passed := false
+		for i, c := range clause {
+			checks[i] = c.evaluate(values)
+			passed = passed || checks[i].Passed
+			if best < 0 || checks[i].Margin > checks[best].Margin {
+				best = i
+			}
+		}
+		out.Fired = out.Fired && passed
+		if checks[best].Margin < out.Margin {
+			out.Margin = checks[best].Margin
+			deciding = &checks[best]
+		}
+		out.Clauses = append(out.Clauses, checks)
+	}
+	return out, deciding
+}
+
+// ExplainFeatures classifies features as ClassifyFeatures does and explains
+// the decision.
+func (tc *TrackClassifier) ExplainFeatures(features ClassificationFeatures) ClassificationExplanation {
+	return tc.explain(tc.ClassifyFeatures(features))
+}
+
+// explain explains a result of ClassifyFeatures.
+func (tc *TrackClassifier) explain(result ClassificationResult) ClassificationExplanation {
+	values := featureValues(result.Features)
+	e := ClassificationExplanation{
+		Model:      result.Model,
+		Features:   values,
+		Class:      result.Class,
+		Confidence: result.Confidence,
+	}
+
+	gate := explainRule{ClassDynamic, [][]explainCheck{
+		{{"observation_count", "<", float32(tc.MinObservations)}},
+	}}
+	for _, rule := range append([]explainRule{gate}, explainCascade...) {
+		evaluated, deciding := rule.evaluate(values)
+		e.Rules = append(e.Rules, evaluated)
+		if evaluated.Fired {
+			e.Deciding = deciding
+			break
+		}
+	}
+	return e
+}
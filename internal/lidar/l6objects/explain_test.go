@@ -0,0 +1,114 @@
+package l6objects
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func TestClassifyAndUpdate_ExplainsCarAndPedestrian(t *testing.T) {
+	classifier := NewTrackClassifierWithMinObservations(5)
+	var explanations []ClassificationExplanation
+	classifier.ExplanationSink = func(e ClassificationExplanation) {
+		explanations = append(explanations, e)
+	}
+
+	car := &TrackedObject{TrackID: "car", TrackMeasurement: l5tracks.TrackMeasurement{
+		ObservationCount:     30,
+		BoundingBoxLengthAvg: 4.5,
+		BoundingBoxWidthAvg:  1.8,
+		BoundingBoxHeightAvg: 1.5,
+		AvgSpeedMps:          12,
+		MaxSpeedMps:          15,
+	}}
+	walker := &TrackedObject{TrackID: "walker", TrackMeasurement: l5tracks.TrackMeasurement{
+		ObservationCount:     30,
+		BoundingBoxLengthAvg: 0.6,
+		BoundingBoxWidthAvg:  0.5,
+		BoundingBoxHeightAvg: 1.7,
+		AvgSpeedMps:          1.3,
+		MaxSpeedMps:          1.8,
+	}}
+	classifier.ClassifyAndUpdate(car)
+	classifier.ClassifyAndUpdate(walker)
+	if len(explanations) != 2 {
+		t.Fatalf("got %d explanations, want 2", len(explanations))
+	}
+
+	// The car needs to be large (length or width) and fast or tall; its
+	// length carries the size clause and is the check nearest its
+	// threshold, so it decides.
+	e := explanations[0]
+	if e.TrackID != "car" || e.Class != ClassCar || e.ReportedClass != ObjectClass(car.ObjectClass) {
+		t.Fatalf("car explanation = %s %s reported %s", e.TrackID, e.Class, e.ReportedClass)
+	}
+	if e.Deciding == nil || e.Deciding.Feature != "avg_length" || !e.Deciding.Passed {
+		t.Errorf("car deciding check = %+v, want a passed avg_length check", e.Deciding)
+	}
+	if last := e.Rules[len(e.Rules)-1]; last.Class != ClassCar || !last.Fired || last.Margin != e.Deciding.Margin {
+		t.Errorf("last rule = %+v, want the fired car rule with the deciding margin", last)
+	}
+
+	// The walker reaches the pedestrian rule because the car rule found
+	// it neither long nor wide.
+	e = explanations[1]
+	if e.Class != ClassPedestrian || e.Deciding == nil || e.Deciding.Feature != "avg_height" {
+		t.Fatalf("walker explanation = %s deciding %+v, want pedestrian decided by avg_height", e.Class, e.Deciding)
+	}
+	var carRule *ClassificationRule
+	for i := range e.Rules {
+		if e.Rules[i].Class == ClassCar {
+			carRule = &e.Rules[i]
+		}
+	}
+	if carRule == nil || carRule.Fired {
+		t.Fatalf("walker explanation has no rejected car rule: %+v", e.Rules)
+	}
+	size := carRule.Clauses[0]
+	if size[0].Feature != "avg_length" || size[0].Passed || size[1].Feature != "avg_width" || size[1].Passed {
+		t.Errorf("car size clause = %+v, want failed avg_length and avg_width checks", size)
+	}
+	if carRule.Margin >= 0 {
+		t.Errorf("rejected car rule margin = %v, want negative", carRule.Margin)
+	}
+}
+
+func TestExplainFeatures_AgreesWithClassifier(t *testing.T) {
+	classifier := NewTrackClassifierWithMinObservations(5)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		f := ClassificationFeatures{
+			AvgLength:        rng.Float32() * 12,
+			AvgWidth:         rng.Float32() * 3.5,
+			AvgHeight:        rng.Float32() * 3.5,
+			AvgSpeed:         rng.Float32() * 20,
+			ObservationCount: rng.Intn(12),
+		}
+		f.MaxSpeed = f.AvgSpeed * (1 + rng.Float32())
+		e := classifier.ExplainFeatures(f)
+		want := classifier.ClassifyFeatures(f).Class
+		if e.Class != want {
+			t.Fatalf("%+v: explanation class %s, classifier %s", f, e.Class, want)
+		}
+		last := e.Rules[len(e.Rules)-1]
+		if last.Fired != (e.Deciding != nil) {
+			t.Fatalf("%+v: last rule fired %v but deciding %+v", f, last.Fired, e.Deciding)
+		}
+		if last.Fired && last.Class != want {
+			t.Fatalf("%+v: fired rule %s, classifier %s", f, last.Class, want)
+		}
+		if !last.Fired && want != ClassDynamic {
+			t.Fatalf("%+v: no rule fired but classifier said %s", f, want)
+		}
+	}
+}
+
+func TestExplainFeatures_ObservationGate(t *testing.T) {
+	e := NewTrackClassifierWithMinObservations(5).ExplainFeatures(ClassificationFeatures{
+		AvgLength: 4.5, AvgWidth: 1.8, AvgHeight: 1.5, AvgSpeed: 12, ObservationCount: 2,
+	})
+	if e.Class != ClassDynamic || len(e.Rules) != 1 || e.Deciding == nil || e.Deciding.Feature != "observation_count" {
+		t.Errorf("explanation = %s, %d rules, deciding %+v; want dynamic decided by observation_count", e.Class, len(e.Rules), e.Deciding)
+	}
+}
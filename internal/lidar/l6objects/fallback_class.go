@@ -0,0 +1,122 @@
+package l6objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Fallback classes for unclassified tracks.
+//
+// A track the classifier never labelled used to be reported as "other",
+// which mixes two very different populations: plausible objects the
+// classifier had too little evidence for, and artefacts that could never
+// have been a road user. Plausibility gates on observation count,
+// duration, size and speed separate them. A track that fails any gate is
+// "noise"; one that passes them all is "unknown". Both fold back into
+// "other" for the combined view.
+
+const (
+	// ClassUnknown is a plausible object with too little evidence to classify.
+	ClassUnknown ObjectClass = "unknown"
+	// ClassNoise is an unclassified track that failed a plausibility gate.
+	ClassNoise ObjectClass = "noise"
+)
+
+// FallbackClassConfig holds the plausibility gates that split unclassified
+// tracks into unknown and noise. A zero gate is not checked.
+type FallbackClassConfig struct {
+	MinObservations int     `json:"min_observations"`
+	MinDurationSecs float64 `json:"min_duration_secs"`
+	MinHeightM      float32 `json:"min_height_m"`  // average box height
+	MaxHeightM      float32 `json:"max_height_m"`  // average box height
+	MaxLengthM      float32 `json:"max_length_m"`  // average box length
+	MaxSpeedMps     float32 `json:"max_speed_mps"` // peak speed
+}
+
+// DefaultFallbackClassConfig returns gates that pass anything from a
+// child on foot to an articulated lorry at motorway speed.
+func DefaultFallbackClassConfig() FallbackClassConfig {
+	return FallbackClassConfig{
+		MinObservations: 3,
+		MinDurationSecs: 0.5,
+		MinHeightM:      0.3,
+		MaxHeightM:      4.5,
+		MaxLengthM:      20,
+		MaxSpeedMps:     60,
+	}
+}
+
+// Validate checks that no gate is negative and that the height range is
+// not inverted.
+func (c FallbackClassConfig) Validate() error {
+	if c.MinObservations < 0 || c.MinDurationSecs < 0 || c.MinHeightM < 0 ||
+		c.MaxHeightM < 0 || c.MaxLengthM < 0 || c.MaxSpeedMps < 0 {
+		return fmt.Errorf("fallback class gates must be >= 0")
+	}
+	if c.MaxHeightM > 0 && c.MinHeightM > c.MaxHeightM {
+		return fmt.Errorf("fallback class min_height_m %g exceeds max_height_m %g", c.MinHeightM, c.MaxHeightM)
+	}
+	return nil
+}
+
+// LoadFallbackClassConfig reads a FallbackClassConfig from a JSON file.
+// Gates missing from the file keep their defaults.
+func LoadFallbackClassConfig(path string) (FallbackClassConfig, error) {
+	cfg := DefaultFallbackClassConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse fallback class config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("fallback class config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Plausible reports whether track passes every gate.
+func (c FallbackClassConfig) Plausible(track *TrackedObject) bool {
+	if c.MinObservations > 0 && track.ObservationCount < c.MinObservations {
+		return false
+	}
+	if c.MinDurationSecs > 0 && float64(track.EndUnixNanos-track.StartUnixNanos)/1e9 < c.MinDurationSecs {
+		return false
+	}
+	if c.MinHeightM > 0 && track.BoundingBoxHeightAvg < c.MinHeightM {
+		return false
+	}
+	if c.MaxHeightM > 0 && track.BoundingBoxHeightAvg > c.MaxHeightM {
+		return false
+	}
+	if c.MaxLengthM > 0 && track.BoundingBoxLengthAvg > c.MaxLengthM {
+		return false
+	}
+	if c.MaxSpeedMps > 0 && track.MaxSpeedMps > c.MaxSpeedMps {
+		return false
+	}
+	return true
+}
+
+// ReportClass returns the class to report for track: its ObjectClass if
+// classified, otherwise "unknown" or "noise" by the gates.
+func (c FallbackClassConfig) ReportClass(track *TrackedObject) string {
+	if track.ObjectClass != "" {
+		return track.ObjectClass
+	}
+	if c.Plausible(track) {
+		return string(ClassUnknown)
+	}
+	return string(ClassNoise)
+}
+
+// CombinedClass folds the unknown and noise fallback classes back into
+// "other", the single fallback reported before they were split.
+func CombinedClass(class string) string {
+	if class == "" || class == string(ClassUnknown) || class == string(ClassNoise) {
+		return unclassifiedClass
+	}
+	return class
+}
@@ -0,0 +1,86 @@
+package l6objects
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func unclassifiedTrack(observations int, secs float64, height, length, maxSpeed float32) *TrackedObject {
+	return &TrackedObject{
+		TrackID: "t",
+		TrackMeasurement: l5tracks.TrackMeasurement{
+			TrackState:           TrackConfirmed,
+			ObservationCount:     observations,
+			StartUnixNanos:       1_000_000_000,
+			EndUnixNanos:         1_000_000_000 + int64(secs*1e9),
+			BoundingBoxHeightAvg: height,
+			BoundingBoxLengthAvg: length,
+			MaxSpeedMps:          maxSpeed,
+		},
+	}
+}
+
+func TestFallbackClassConfig_SplitsUnknownAndNoise(t *testing.T) {
+	cfg := DefaultFallbackClassConfig()
+	for _, tc := range []struct {
+		name  string
+		track *TrackedObject
+		want  ObjectClass
+	}{
+		{"plausible but ambiguous", unclassifiedTrack(12, 3, 1.2, 2.0, 8), ClassUnknown},
+		{"too few observations", unclassifiedTrack(2, 3, 1.2, 2.0, 8), ClassNoise},
+		{"too brief", unclassifiedTrack(12, 0.2, 1.2, 2.0, 8), ClassNoise},
+		{"too flat", unclassifiedTrack(12, 3, 0.1, 2.0, 8), ClassNoise},
+		{"too tall", unclassifiedTrack(12, 3, 6, 2.0, 8), ClassNoise},
+		{"too long", unclassifiedTrack(12, 3, 1.2, 35, 8), ClassNoise},
+		{"too fast", unclassifiedTrack(12, 3, 1.2, 2.0, 90), ClassNoise},
+	} {
+		if got := cfg.ReportClass(tc.track); got != string(tc.want) {
+			t.Errorf("%s: class = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	classified := unclassifiedTrack(2, 0.1, 0.1, 2.0, 8)
+	classified.ObjectClass = "car"
+	if got := cfg.ReportClass(classified); got != "car" {
+		t.Errorf("classified track reported as %q, want its own class", got)
+	}
+	if got := (FallbackClassConfig{}).ReportClass(unclassifiedTrack(0, 0, 0, 0, 0)); got != string(ClassUnknown) {
+		t.Errorf("with no gates class = %q, want unknown", got)
+	}
+}
+
+func TestCombinedClass(t *testing.T) {
+	for class, want := range map[string]string{"": "other", "unknown": "other", "noise": "other", "car": "car"} {
+		if got := CombinedClass(class); got != want {
+			t.Errorf("CombinedClass(%q) = %q, want %q", class, got, want)
+		}
+	}
+}
+
+func TestLoadFallbackClassConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gates.json")
+	if err := os.WriteFile(path, []byte(`{"min_observations": 8, "max_speed_mps": 0}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFallbackClassConfig(path)
+	if err != nil {
+		t.Fatalf("LoadFallbackClassConfig: %v", err)
+	}
+	def := DefaultFallbackClassConfig()
+	if cfg.MinObservations != 8 || cfg.MaxSpeedMps != 0 || cfg.MinHeightM != def.MinHeightM {
+		t.Errorf("cfg = %+v, want min_observations 8, speed gate off, other defaults kept", cfg)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"min_height_m": 3, "max_height_m": 2}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFallbackClassConfig(bad); err == nil {
+		t.Error("expected an error for an inverted height range")
+	}
+}
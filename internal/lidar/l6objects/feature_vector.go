@@ -0,0 +1,75 @@
+package l6objects
+
+// FeatureVectorVersion identifies the FeatureVectorColumns layout. Columns
+// are only ever appended; the version is bumped whenever the set changes so
+// external models can refuse vectors they were not trained on.
+const FeatureVectorVersion = 1
+
+// featureVectorInputs holds the per-track values shared by several columns.
+type featureVectorInputs struct {
+	track  *TrackedObject
+	cls    ClassificationFeatures
+	speeds []float32
+}
+
+// featureVectorColumns defines each exported column once, so names and
+// values cannot drift out of order.
+var featureVectorColumns = []struct {
+	name  string
+	value func(in *featureVectorInputs) float32
+}{
+	// Dimensions: per-frame bounding box means and sample std-devs.
+	{"length_avg_m", func(in *featureVectorInputs) float32 { return in.cls.AvgLength }},
+	{"width_avg_m", func(in *featureVectorInputs) float32 { return in.cls.AvgWidth }},
+	{"height_avg_m", func(in *featureVectorInputs) float32 { return in.cls.AvgHeight }},
+	{"length_std_m", func(in *featureVectorInputs) float32 { return in.cls.LengthStdDev }},
+	{"width_std_m", func(in *featureVectorInputs) float32 { return in.cls.WidthStdDev }},
+	{"height_std_m", func(in *featureVectorInputs) float32 { return in.cls.HeightStdDev }},
+
+	// Height profile.
+	{"height_p95_max_m", func(in *featureVectorInputs) float32 { return in.cls.HeightP95 }},
+
+	// Intensity signature.
+	{"intensity_mean", func(in *featureVectorInputs) float32 { return in.track.IntensityMeanAvg }},
+
+	// Speed statistics.
+	{"speed_avg_mps", func(in *featureVectorInputs) float32 { return in.cls.AvgSpeed }},
+	{"speed_max_mps", func(in *featureVectorInputs) float32 { return in.cls.MaxSpeed }},
+	{"speed_p50_mps", func(in *featureVectorInputs) float32 { return in.cls.P50Speed }},
+	{"speed_p85_mps", func(in *featureVectorInputs) float32 { return in.cls.P85Speed }},
+	{"speed_p95_mps", func(in *featureVectorInputs) float32 { return in.cls.P95Speed }},
+	{"speed_variance", func(in *featureVectorInputs) float32 { return computeVariance(in.speeds) }},
+	{"heading_variance", func(in *featureVectorInputs) float32 { return computeHeadingVariance(in.track.History) }},
+
+	// Temporal.
+	{"observation_count", func(in *featureVectorInputs) float32 { return float32(in.cls.ObservationCount) }},
+	{"duration_secs", func(in *featureVectorInputs) float32 { return in.cls.DurationSecs }},
+}
+
+// FeatureVectorColumns returns the column names of TrackFeatureVector, in
+// order.
+func FeatureVectorColumns() []string {
+	names := make([]string, len(featureVectorColumns))
+	for i, c := range featureVectorColumns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// TrackFeatureVector returns a track's classification features as a flat
+// numeric vector in FeatureVectorColumns order. It is built from the same
+// ClassificationFeatures the rule-based classifier uses, plus the intensity
+// and motion terms an external model can use, so a model trained on exported
+// vectors sees exactly what the Go pipeline computes.
+func TrackFeatureVector(track *TrackedObject) []float32 {
+	in := featureVectorInputs{
+		track:  track,
+		cls:    ExtractClassificationFeatures(track),
+		speeds: track.SpeedHistory(),
+	}
+	vec := make([]float32, len(featureVectorColumns))
+	for i, c := range featureVectorColumns {
+		vec[i] = c.value(&in)
+	}
+	return vec
+}
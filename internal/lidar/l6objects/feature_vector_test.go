@@ -0,0 +1,76 @@
+package l6objects
+
+import (
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func TestFeatureVectorColumns_Stable(t *testing.T) {
+	// External models index vectors by position: changing this list is a
+	// breaking change and must bump FeatureVectorVersion.
+	want := []string{
+		"length_avg_m", "width_avg_m", "height_avg_m",
+		"length_std_m", "width_std_m", "height_std_m",
+		"height_p95_max_m", "intensity_mean",
+		"speed_avg_mps", "speed_max_mps", "speed_p50_mps", "speed_p85_mps", "speed_p95_mps",
+		"speed_variance", "heading_variance",
+		"observation_count", "duration_secs",
+	}
+	got := FeatureVectorColumns()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d columns, got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("column %d: want %q, got %q", i, want[i], got[i])
+		}
+	}
+	if FeatureVectorVersion != 1 {
+		t.Errorf("FeatureVectorVersion changed to %d: update this test with the new layout", FeatureVectorVersion)
+	}
+}
+
+func TestTrackFeatureVector_MatchesClassifierFeatures(t *testing.T) {
+	track := &TrackedObject{
+		TrackID: "fv-car",
+		TrackMeasurement: l5tracks.TrackMeasurement{
+			ObservationCount:     10,
+			StartUnixNanos:       1_000_000_000,
+			EndUnixNanos:         3_500_000_000,
+			BoundingBoxLengthAvg: 4.5,
+			BoundingBoxWidthAvg:  1.8,
+			BoundingBoxHeightAvg: 1.5,
+			HeightP95Max:         1.6,
+			IntensityMeanAvg:     42,
+			AvgSpeedMps:          12,
+			MaxSpeedMps:          14,
+		},
+	}
+	track.SetSpeedHistory([]float32{10, 11, 12, 13, 14})
+
+	vec := TrackFeatureVector(track)
+	cols := FeatureVectorColumns()
+	if len(vec) != len(cols) {
+		t.Fatalf("vector length %d != column count %d", len(vec), len(cols))
+	}
+	byName := make(map[string]float32, len(cols))
+	for i, c := range cols {
+		byName[c] = vec[i]
+	}
+
+	cls := NewTrackClassifier().Classify(track).Features
+	for name, want := range map[string]float32{
+		"length_avg_m":      cls.AvgLength,
+		"height_p95_max_m":  cls.HeightP95,
+		"speed_p85_mps":     cls.P85Speed,
+		"observation_count": float32(cls.ObservationCount),
+		"duration_secs":     2.5,
+		"intensity_mean":    42,
+		"speed_variance":    2, // population variance of 10..14
+	} {
+		if byName[name] != want {
+			t.Errorf("%s: want %v, got %v", name, want, byName[name])
+		}
+	}
+}
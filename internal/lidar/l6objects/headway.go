@@ -0,0 +1,289 @@
+package l6objects
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Headway and pedestrian gap analysis over count-line crossings.
+//
+// The headway of a vehicle is the time since the previous vehicle crossed
+// the same reference line. The headway series shows how platooned traffic
+// is, and the gaps in it are what a pedestrian waiting at the kerb has to
+// choose from. Each pedestrian crossing attempt is matched to the vehicle
+// gap it fell in: the lag from the attempt to the next vehicle is the time
+// the pedestrian actually had, and a lag of at least the critical gap
+// counts as usable.
+
+// DefaultHeadwayCriticalGapSecs is the lag a pedestrian needs to cross a
+// two-lane street at walking pace with a start-up margin.
+const DefaultHeadwayCriticalGapSecs = 5.0
+
+// defaultHeadwayVehicleClasses are the classes whose crossings make up the
+// headway series when HeadwayConfig.VehicleClasses is empty.
+var defaultHeadwayVehicleClasses = []string{
+	string(ClassCar), string(ClassTruck), string(ClassBus), string(ClassMotorcyclist),
+}
+
+// HeadwayConfig selects the crossings a headway analysis uses.
+type HeadwayConfig struct {
+	LineID          string            // reference line vehicles cross
+	CrosswalkID     string            // line pedestrians cross the road on; empty means LineID
+	Direction       CrossingDirection // vehicle direction to analyse; empty means both
+	CriticalGapSecs float64           // shortest usable lag; zero means DefaultHeadwayCriticalGapSecs
+	VehicleClasses  []string          // empty means car, truck, bus and motorcyclist
+}
+
+// Validate checks a reference line is set and the direction and critical
+// gap are valid.
+func (c HeadwayConfig) Validate() error {
+	if c.LineID == "" {
+		return fmt.Errorf("headway reference line is required")
+	}
+	if c.Direction != "" && c.Direction != CrossingLeftToRight && c.Direction != CrossingRightToLeft {
+		return fmt.Errorf("headway direction %q must be %s or %s", c.Direction, CrossingLeftToRight, CrossingRightToLeft)
+	}
+	if c.CriticalGapSecs < 0 {
+		return fmt.Errorf("headway critical gap must be >= 0, got %v", c.CriticalGapSecs)
+	}
+	return nil
+}
+
+func (c HeadwayConfig) withDefaults() HeadwayConfig {
+	if c.CrosswalkID == "" {
+		c.CrosswalkID = c.LineID
+	}
+	if c.CriticalGapSecs == 0 {
+		c.CriticalGapSecs = DefaultHeadwayCriticalGapSecs
+	}
+	if len(c.VehicleClasses) == 0 {
+		c.VehicleClasses = defaultHeadwayVehicleClasses
+	}
+	return c
+}
+
+// Headway is the time between two successive vehicles crossing the
+// reference line.
+type Headway struct {
+	TrackID        string  `json:"track_id"`
+	Class          string  `json:"class"`
+	LeaderTrackID  string  `json:"leader_track_id"`
+	LeaderClass    string  `json:"leader_class"`
+	TimestampNanos int64   `json:"timestamp_unix_nanos"` // follower's crossing
+	Secs           float64 `json:"headway_secs"`
+}
+
+// PedestrianGap matches one pedestrian crossing attempt to the vehicle gap
+// it fell in. PrevTrackID or NextTrackID is empty when no vehicle crossed
+// before or after the attempt; GapSecs is then zero, and so is LagSecs
+// when there is no next vehicle.
+type PedestrianGap struct {
+	TrackID        string  `json:"track_id"`
+	TimestampNanos int64   `json:"timestamp_unix_nanos"`
+	PrevTrackID    string  `json:"prev_track_id,omitempty"`
+	NextTrackID    string  `json:"next_track_id,omitempty"`
+	GapSecs        float64 `json:"gap_secs"` // previous to next vehicle
+	LagSecs        float64 `json:"lag_secs"` // attempt to next vehicle
+	// Usable is true when the lag is at least the critical gap or no
+	// vehicle followed.
+	Usable bool `json:"usable"`
+}
+
+// HeadwaySummary is the distribution of a headway series. Percentiles are
+// nearest-rank, as for speed percentiles.
+type HeadwaySummary struct {
+	Count    int     `json:"count"`
+	MeanSecs float64 `json:"mean_secs"`
+	MinSecs  float64 `json:"min_secs"`
+	P15Secs  float64 `json:"p15_secs"`
+	P50Secs  float64 `json:"p50_secs"`
+	P85Secs  float64 `json:"p85_secs"`
+	MaxSecs  float64 `json:"max_secs"`
+}
+
+// HeadwayReport is the result of AnalyseHeadways.
+type HeadwayReport struct {
+	Config      HeadwayConfig   `json:"-"`
+	Headways    []Headway       `json:"headways"`
+	Summary     HeadwaySummary  `json:"summary"`
+	UsableGaps  int             `json:"usable_gaps"` // headways of at least the critical gap
+	Pedestrians []PedestrianGap `json:"pedestrians"`
+	// PedestriansUsable counts attempts made in a usable gap.
+	PedestriansUsable int `json:"pedestrians_usable"`
+}
+
+// AnalyseHeadways computes the vehicle headway series on the configured
+// reference line and matches pedestrian crossings of the crosswalk line to
+// the vehicle gaps. crossings may hold any lines and be in any order.
+func AnalyseHeadways(crossings []LineCrossing, cfg HeadwayConfig) (HeadwayReport, error) {
+	if err := cfg.Validate(); err != nil {
+		return HeadwayReport{}, err
+	}
+	cfg = cfg.withDefaults()
+	vehicleClass := make(map[string]bool, len(cfg.VehicleClasses))
+	for _, class := range cfg.VehicleClasses {
+		vehicleClass[class] = true
+	}
+
+	var vehicles, pedestrians []LineCrossing
+	for _, c := range crossings {
+		switch {
+		case c.LineID == cfg.LineID && vehicleClass[c.Class] && (cfg.Direction == "" || c.Direction == cfg.Direction):
+			vehicles = append(vehicles, c)
+		case c.LineID == cfg.CrosswalkID && c.Class == string(ClassPedestrian):
+			pedestrians = append(pedestrians, c)
+		}
+	}
+	byTime := func(s []LineCrossing) {
+		sort.SliceStable(s, func(i, j int) bool { return s[i].TimestampNanos < s[j].TimestampNanos })
+	}
+	byTime(vehicles)
+	byTime(pedestrians)
+
+	report := HeadwayReport{Config: cfg}
+	secs := make([]float64, 0, len(vehicles))
+	for i := 1; i < len(vehicles); i++ {
+		leader, follower := vehicles[i-1], vehicles[i]
+		h := Headway{
+			TrackID:        follower.TrackID,
+			Class:          follower.Class,
+			LeaderTrackID:  leader.TrackID,
+			LeaderClass:    leader.Class,
+			TimestampNanos: follower.TimestampNanos,
+			Secs:           nanosToSecs(follower.TimestampNanos - leader.TimestampNanos),
+		}
+		report.Headways = append(report.Headways, h)
+		secs = append(secs, h.Secs)
+		if h.Secs >= cfg.CriticalGapSecs {
+			report.UsableGaps++
+		}
+	}
+	report.Summary = summariseHeadways(secs)
+
+	for _, p := range pedestrians {
+		// First vehicle crossing at or after the attempt.
+		next := sort.Search(len(vehicles), func(i int) bool { return vehicles[i].TimestampNanos >= p.TimestampNanos })
+		gap := PedestrianGap{TrackID: p.TrackID, TimestampNanos: p.TimestampNanos, Usable: true}
+		if next > 0 {
+			gap.PrevTrackID = vehicles[next-1].TrackID
+		}
+		if next < len(vehicles) {
+			gap.NextTrackID = vehicles[next].TrackID
+			gap.LagSecs = nanosToSecs(vehicles[next].TimestampNanos - p.TimestampNanos)
+			gap.Usable = gap.LagSecs >= cfg.CriticalGapSecs
+			if next > 0 {
+				gap.GapSecs = nanosToSecs(vehicles[next].TimestampNanos - vehicles[next-1].TimestampNanos)
+			}
+		}
+		if gap.Usable {
+			report.PedestriansUsable++
+		}
+		report.Pedestrians = append(report.Pedestrians, gap)
+	}
+	return report, nil
+}
+
+func nanosToSecs(nanos int64) float64 {
+	return float64(nanos) / float64(time.Second)
+}
+
+// summariseHeadways returns the distribution of secs.
+func summariseHeadways(secs []float64) HeadwaySummary {
+	n := len(secs)
+	if n == 0 {
+		return HeadwaySummary{}
+	}
+	sorted := append([]float64(nil), secs...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, s := range sorted {
+		sum += s
+	}
+	rank := func(q float64) float64 {
+		return sorted[min(int(math.Floor(float64(n)*q)), n-1)]
+	}
+	return HeadwaySummary{
+		Count:    n,
+		MeanSecs: sum / float64(n),
+		MinSecs:  sorted[0],
+		P15Secs:  rank(0.15),
+		P50Secs:  rank(0.50),
+		P85Secs:  rank(0.85),
+		MaxSecs:  sorted[n-1],
+	}
+}
+
+// WriteCSV writes the headway series followed by the pedestrian attempts,
+// one row each, in time order within each record type. For a headway row
+// prev_track_id is the leading vehicle and lag_secs is empty.
+func (r HeadwayReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"record", "timestamp", "track_id", "class", "prev_track_id", "next_track_id", "gap_secs", "lag_secs", "usable"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, h := range r.Headways {
+		row := []string{"headway", formatCrossingTime(h.TimestampNanos), h.TrackID, h.Class, h.LeaderTrackID, "",
+			formatSecs(h.Secs), "", strconv.FormatBool(h.Secs >= r.Config.CriticalGapSecs)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	for _, p := range r.Pedestrians {
+		gap, lag := "", ""
+		if p.PrevTrackID != "" && p.NextTrackID != "" {
+			gap = formatSecs(p.GapSecs)
+		}
+		if p.NextTrackID != "" {
+			lag = formatSecs(p.LagSecs)
+		}
+		row := []string{"pedestrian", formatCrossingTime(p.TimestampNanos), p.TrackID, string(ClassPedestrian),
+			p.PrevTrackID, p.NextTrackID, gap, lag, strconv.FormatBool(p.Usable)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSummaryCSV writes the headway distribution and gap counts as
+// metric,value rows.
+func (r HeadwayReport) WriteSummaryCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	s := r.Summary
+	rows := [][]string{
+		{"metric", "value"},
+		{"line_id", r.Config.LineID},
+		{"crosswalk_id", r.Config.CrosswalkID},
+		{"direction", string(r.Config.Direction)},
+		{"critical_gap_secs", formatSecs(r.Config.CriticalGapSecs)},
+		{"headways", strconv.Itoa(s.Count)},
+		{"mean_secs", formatSecs(s.MeanSecs)},
+		{"min_secs", formatSecs(s.MinSecs)},
+		{"p15_secs", formatSecs(s.P15Secs)},
+		{"p50_secs", formatSecs(s.P50Secs)},
+		{"p85_secs", formatSecs(s.P85Secs)},
+		{"max_secs", formatSecs(s.MaxSecs)},
+		{"usable_gaps", strconv.Itoa(r.UsableGaps)},
+		{"pedestrian_attempts", strconv.Itoa(len(r.Pedestrians))},
+		{"pedestrians_usable", strconv.Itoa(r.PedestriansUsable)},
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatCrossingTime(nanos int64) string {
+	return time.Unix(0, nanos).UTC().Format(time.RFC3339Nano)
+}
+
+func formatSecs(secs float64) string {
+	return strconv.FormatFloat(secs, 'f', 3, 64)
+}
@@ -0,0 +1,177 @@
+package l6objects
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scriptedCrossing(line, track, class string, secs float64) LineCrossing {
+	return LineCrossing{
+		LineID:         line,
+		TrackID:        track,
+		Class:          class,
+		Direction:      CrossingLeftToRight,
+		TimestampNanos: int64(secs * float64(time.Second)),
+	}
+}
+
+// headwayScript is five vehicles on the north line with a cyclist among
+// them, a car on another line, and pedestrians on the zebra crossing
+// before, during and after the traffic. It is out of time order.
+var headwayScript = []LineCrossing{
+	scriptedCrossing("north", "c3", "truck", 12.2),
+	scriptedCrossing("north", "c1", "car", 0),
+	scriptedCrossing("zebra", "p1", "pedestrian", 5),
+	scriptedCrossing("north", "bike", "cyclist", 8),
+	scriptedCrossing("north", "c2", "car", 3.2),
+	scriptedCrossing("south", "s1", "car", 1),
+	scriptedCrossing("zebra", "p2", "pedestrian", 13),
+	scriptedCrossing("north", "c5", "car", 20),
+	scriptedCrossing("north", "c4", "bus", 14),
+	scriptedCrossing("north", "walker", "pedestrian", 16),
+	scriptedCrossing("zebra", "p3", "pedestrian", 25),
+	scriptedCrossing("zebra", "p0", "pedestrian", -1),
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestAnalyseHeadways_ScriptedCrossings(t *testing.T) {
+	report, err := AnalyseHeadways(headwayScript, HeadwayConfig{LineID: "north", CrosswalkID: "zebra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		track, leader string
+		secs          float64
+	}{{"c2", "c1", 3.2}, {"c3", "c2", 9}, {"c4", "c3", 1.8}, {"c5", "c4", 6}}
+	if len(report.Headways) != len(want) {
+		t.Fatalf("got %d headways, want %d: %+v", len(report.Headways), len(want), report.Headways)
+	}
+	for i, w := range want {
+		h := report.Headways[i]
+		if h.TrackID != w.track || h.LeaderTrackID != w.leader || !approx(h.Secs, w.secs) {
+			t.Errorf("headway %d = %s after %s %.3fs, want %s after %s %.3fs", i, h.TrackID, h.LeaderTrackID, h.Secs, w.track, w.leader, w.secs)
+		}
+	}
+
+	// Sorted headways 1.8, 3.2, 6, 9: nearest-rank p15 is the first, p50
+	// the third and p85 the fourth.
+	s := report.Summary
+	if s.Count != 4 || !approx(s.MeanSecs, 5) || !approx(s.MinSecs, 1.8) || !approx(s.P15Secs, 1.8) ||
+		!approx(s.P50Secs, 6) || !approx(s.P85Secs, 9) || !approx(s.MaxSecs, 9) {
+		t.Errorf("summary = %+v", s)
+	}
+	if report.UsableGaps != 2 {
+		t.Errorf("usable gaps = %d, want 2 (6 s and 9 s against the 5 s default)", report.UsableGaps)
+	}
+
+	wantPeds := []PedestrianGap{
+		{TrackID: "p0", NextTrackID: "c1", LagSecs: 1},
+		{TrackID: "p1", PrevTrackID: "c2", NextTrackID: "c3", GapSecs: 9, LagSecs: 7.2, Usable: true},
+		{TrackID: "p2", PrevTrackID: "c3", NextTrackID: "c4", GapSecs: 1.8, LagSecs: 1},
+		{TrackID: "p3", PrevTrackID: "c5", Usable: true},
+	}
+	if len(report.Pedestrians) != len(wantPeds) {
+		t.Fatalf("got %d pedestrian attempts, want %d: %+v", len(report.Pedestrians), len(wantPeds), report.Pedestrians)
+	}
+	for i, w := range wantPeds {
+		p := report.Pedestrians[i]
+		if p.TrackID != w.TrackID || p.PrevTrackID != w.PrevTrackID || p.NextTrackID != w.NextTrackID ||
+			!approx(p.GapSecs, w.GapSecs) || !approx(p.LagSecs, w.LagSecs) || p.Usable != w.Usable {
+			t.Errorf("pedestrian %d = %+v, want %+v", i, p, w)
+		}
+	}
+	if report.PedestriansUsable != 2 {
+		t.Errorf("pedestrians usable = %d, want 2", report.PedestriansUsable)
+	}
+}
+
+func TestAnalyseHeadways_FiltersAndDefaults(t *testing.T) {
+	script := append([]LineCrossing(nil), headwayScript...)
+	script[8].Direction = CrossingRightToLeft // c4, the bus
+
+	report, err := AnalyseHeadways(script, HeadwayConfig{
+		LineID:          "north",
+		Direction:       CrossingLeftToRight,
+		CriticalGapSecs: 10,
+		VehicleClasses:  []string{"car", "truck", "bus", "cyclist"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// c1, c2, bike, c3, c5: the bus went the other way.
+	var got []float64
+	for _, h := range report.Headways {
+		got = append(got, h.Secs)
+	}
+	want := []float64{3.2, 4.8, 4.2, 7.8}
+	if len(got) != len(want) {
+		t.Fatalf("headways = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !approx(got[i], want[i]) {
+			t.Fatalf("headways = %v, want %v", got, want)
+		}
+	}
+	if report.UsableGaps != 0 {
+		t.Errorf("usable gaps = %d, want none against a 10 s critical gap", report.UsableGaps)
+	}
+	// The crosswalk defaults to the reference line, where one pedestrian
+	// walked across at 16 s.
+	if len(report.Pedestrians) != 1 || report.Pedestrians[0].TrackID != "walker" || !approx(report.Pedestrians[0].LagSecs, 4) {
+		t.Errorf("pedestrians = %+v, want the walker with a 4 s lag", report.Pedestrians)
+	}
+
+	for name, cfg := range map[string]HeadwayConfig{
+		"no line":       {},
+		"bad direction": {LineID: "north", Direction: "up"},
+		"negative gap":  {LineID: "north", CriticalGapSecs: -1},
+	} {
+		if _, err := AnalyseHeadways(script, cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestHeadwayReport_WriteCSV(t *testing.T) {
+	report, err := AnalyseHeadways(headwayScript, HeadwayConfig{LineID: "north", CrosswalkID: "zebra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var series bytes.Buffer
+	if err := report.WriteCSV(&series); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&series).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1+4+4 {
+		t.Fatalf("got %d rows, want a header, 4 headways and 4 pedestrians:\n%s", len(rows), series.String())
+	}
+	if got := strings.Join(rows[2], ","); got != "headway,1970-01-01T00:00:12.2Z,c3,truck,c2,,9.000,,true" {
+		t.Errorf("headway row = %s", got)
+	}
+	if got := strings.Join(rows[5], ","); got != "pedestrian,1969-12-31T23:59:59Z,p0,pedestrian,,c1,,1.000,false" {
+		t.Errorf("first pedestrian row = %s", got)
+	}
+	if got := strings.Join(rows[8], ","); got != "pedestrian,1970-01-01T00:00:25Z,p3,pedestrian,c5,,,,true" {
+		t.Errorf("last pedestrian row = %s", got)
+	}
+
+	var summary bytes.Buffer
+	if err := report.WriteSummaryCSV(&summary); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"headways,4\n", "p15_secs,1.800\n", "p50_secs,6.000\n", "p85_secs,9.000\n", "usable_gaps,2\n", "pedestrians_usable,2\n"} {
+		if !strings.Contains(summary.String(), want) {
+			t.Errorf("summary missing %q:\n%s", want, summary.String())
+		}
+	}
+}
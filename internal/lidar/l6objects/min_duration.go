@@ -0,0 +1,91 @@
+package l6objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Per-class minimum durations.
+//
+// A confirmed track that lasts less than a plausible transit for its class
+// is almost always an artefact: a "car" that exists for 0.3 s cannot cross
+// any part of the scene at our geometry. Unlike the quality score, which
+// grades every track on the same scale, this filter is class-aware and
+// applied only when tracks are counted and reported.
+
+// MinDurationConfig is the file form of the per-class minimum durations.
+type MinDurationConfig struct {
+	// MinDurationSecs maps an object class to the shortest track
+	// of that class to report, in seconds. Unclassified tracks use the key
+	// "other". Classes not listed are reported whatever their duration.
+	MinDurationSecs map[string]float64 `json:"min_duration_secs"`
+}
+
+// Validate checks that every minimum is non-negative and names a class.
+func (c MinDurationConfig) Validate() error {
+	for class, secs := range c.MinDurationSecs {
+		if class == "" {
+			return fmt.Errorf("min duration: class name is required")
+		}
+		if secs < 0 {
+			return fmt.Errorf("min duration for %s must be >= 0, got %g", class, secs)
+		}
+	}
+	return nil
+}
+
+// LoadMinDurationConfig reads a MinDurationConfig from a JSON file.
+func LoadMinDurationConfig(path string) (MinDurationConfig, error) {
+	var cfg MinDurationConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse min duration config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("min duration config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// IsFlicker reports whether track lasted less than the minimum for its
+// class. Tracks still tentative may yet grow and are never flicker; a
+// confirmed or deleted track is judged on the span it has lived.
+func (c MinDurationConfig) IsFlicker(track *TrackedObject) bool {
+	if track == nil || track.TrackState == TrackTentative {
+		return false
+	}
+	class := track.ObjectClass
+	if class == "" {
+		class = unclassifiedClass
+	}
+	minSecs, ok := c.MinDurationSecs[class]
+	if !ok || minSecs <= 0 {
+		return false
+	}
+	return float64(track.EndUnixNanos-track.StartUnixNanos)/1e9 < minSecs
+}
+
+// FilterFlickerTracks splits tracks into those to report and the number of
+// flicker tracks dropped per class. The input order is kept.
+func (c MinDurationConfig) FilterFlickerTracks(tracks []*TrackedObject) (kept []*TrackedObject, dropped map[string]int) {
+	kept = make([]*TrackedObject, 0, len(tracks))
+	for _, t := range tracks {
+		if !c.IsFlicker(t) {
+			kept = append(kept, t)
+			continue
+		}
+		if dropped == nil {
+			dropped = make(map[string]int)
+		}
+		class := t.ObjectClass
+		if class == "" {
+			class = unclassifiedClass
+		}
+		dropped[class]++
+	}
+	return kept, dropped
+}
@@ -0,0 +1,79 @@
+package l6objects
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func durationTrack(id, class string, state TrackState, secs float64, speed float32) *TrackedObject {
+	return &TrackedObject{
+		TrackID: id,
+		TrackMeasurement: l5tracks.TrackMeasurement{
+			TrackState:     state,
+			ObjectClass:    class,
+			StartUnixNanos: 1_000_000_000,
+			EndUnixNanos:   1_000_000_000 + int64(secs*1e9),
+			AvgSpeedMps:    speed,
+		},
+	}
+}
+
+func TestMinDurationConfig_DropsSubThresholdCar(t *testing.T) {
+	cfg := MinDurationConfig{MinDurationSecs: map[string]float64{"car": 1.0, "other": 0.5}}
+	tracks := []*TrackedObject{
+		durationTrack("flicker-car", "car", TrackConfirmed, 0.3, 25),
+		durationTrack("real-car", "car", TrackConfirmed, 4.0, 12),
+		durationTrack("short-ped", "pedestrian", TrackConfirmed, 0.3, 1.4), // class not listed
+		durationTrack("young-car", "car", TrackTentative, 0.2, 10),         // may still grow
+		durationTrack("gone-blob", "", TrackDeleted, 0.2, 3),
+	}
+
+	kept, dropped := cfg.FilterFlickerTracks(tracks)
+	if len(kept) != 3 || kept[0].TrackID != "real-car" || kept[1].TrackID != "short-ped" || kept[2].TrackID != "young-car" {
+		ids := make([]string, len(kept))
+		for i, k := range kept {
+			ids[i] = k.TrackID
+		}
+		t.Fatalf("kept = %v, want [real-car short-ped young-car]", ids)
+	}
+	if dropped["car"] != 1 || dropped["other"] != 1 || len(dropped) != 2 {
+		t.Errorf("dropped = %v, want car:1 other:1", dropped)
+	}
+
+	// The flicker car no longer reaches class counts or speed percentiles.
+	stats := ComputeClassStats(kept)
+	if stats["car"].Count != 2 || stats["car"].AvgSpeed != 11 {
+		t.Errorf("car stats = %+v, want the real and tentative cars only", stats["car"])
+	}
+	if s := ComputeSpeedStatistics(TrackSpeedSamples(kept)); s.MaxSpeed != 12 {
+		t.Errorf("max speed = %v, want 12 without the 25 m/s flicker", s.MaxSpeed)
+	}
+
+	if _, dropped := (MinDurationConfig{}).FilterFlickerTracks(tracks); dropped != nil {
+		t.Errorf("empty config dropped %v", dropped)
+	}
+}
+
+func TestLoadMinDurationConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "min-durations.json")
+	if err := os.WriteFile(path, []byte(`{"min_duration_secs":{"car":1.5,"bus":2}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadMinDurationConfig(path)
+	if err != nil {
+		t.Fatalf("LoadMinDurationConfig: %v", err)
+	}
+	if cfg.MinDurationSecs["car"] != 1.5 || cfg.MinDurationSecs["bus"] != 2 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"min_duration_secs":{"car":-1}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMinDurationConfig(path); err == nil {
+		t.Error("expected a negative minimum to be rejected")
+	}
+}
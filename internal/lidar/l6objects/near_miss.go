@@ -0,0 +1,265 @@
+package l6objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Near-miss defaults.
+const (
+	DefaultNearMissThresholdMetres = 2.0
+	DefaultNearMissMinSpeedMps     = 0.5
+)
+
+// ClassPair is an unordered pair of object classes, e.g. car and pedestrian.
+type ClassPair struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+func (p ClassPair) matches(a, b string) bool {
+	return (p.A == a && p.B == b) || (p.A == b && p.B == a)
+}
+
+// NearMissConfig is the file form of the near-miss detector settings.
+type NearMissConfig struct {
+	// ThresholdMetres is the separation below which two tracks are in an
+	// encounter. Zero means DefaultNearMissThresholdMetres.
+	ThresholdMetres float64 `json:"threshold_m,omitempty"`
+	// MinSpeedMps is the speed both tracks must exceed, so parked vehicles
+	// and people standing together are ignored. Zero means
+	// DefaultNearMissMinSpeedMps.
+	MinSpeedMps float64 `json:"min_speed_mps,omitempty"`
+	// MinRelativeSpeedMps is the relative speed the pair must reach, so
+	// tracks travelling together (a cyclist beside a car in a queue) are
+	// not flagged. Zero accepts any relative speed.
+	MinRelativeSpeedMps float64 `json:"min_relative_speed_mps,omitempty"`
+	// ClassPairs restricts detection to these class pairs; empty checks
+	// every pair of tracks.
+	ClassPairs []ClassPair `json:"class_pairs,omitempty"`
+}
+
+// Validate checks the thresholds are non-negative and class pairs named.
+func (c NearMissConfig) Validate() error {
+	if c.ThresholdMetres < 0 || c.MinSpeedMps < 0 || c.MinRelativeSpeedMps < 0 {
+		return fmt.Errorf("near-miss thresholds must be >= 0")
+	}
+	for i, p := range c.ClassPairs {
+		if p.A == "" || p.B == "" {
+			return fmt.Errorf("class pair %d: both classes are required", i)
+		}
+	}
+	return nil
+}
+
+// LoadNearMissConfig reads a NearMissConfig from a JSON file.
+func LoadNearMissConfig(path string) (NearMissConfig, error) {
+	var cfg NearMissConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse near-miss config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("near-miss config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// NearMiss records one encounter between two tracks, from the first frame
+// they were within the threshold to the last. The distance, relative
+// speed, time and midpoint are taken at the closest approach. TrackA sorts
+// before TrackB.
+type NearMiss struct {
+	TrackA           string  `json:"track_a"`
+	TrackB           string  `json:"track_b"`
+	ClassA           string  `json:"class_a,omitempty"`
+	ClassB           string  `json:"class_b,omitempty"`
+	SensorID         string  `json:"sensor_id,omitempty"`
+	MinDistanceM     float32 `json:"min_distance_m"`
+	RelativeSpeedMps float32 `json:"relative_speed_mps"`
+	TimestampNanos   int64   `json:"timestamp_unix_nanos"`
+	StartNanos       int64   `json:"start_unix_nanos"`
+	EndNanos         int64   `json:"end_unix_nanos"`
+	X                float32 `json:"x"`
+	Y                float32 `json:"y"`
+}
+
+type nearMissPair struct{ a, b string }
+
+// NearMissDetector flags pairs of moving tracks that come within a
+// threshold distance of each other. Each frame it compares only tracks in
+// neighbouring cells of a grid sized to the threshold, so the cost grows
+// with local density rather than with the square of the track count. An
+// encounter is reported once, when it ends. It is safe for concurrent use.
+type NearMissDetector struct {
+	mu   sync.Mutex
+	cfg  NearMissConfig
+	open map[nearMissPair]*NearMiss
+}
+
+// NewNearMissDetector creates a detector, filling in default thresholds.
+func NewNearMissDetector(cfg NearMissConfig) (*NearMissDetector, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.ThresholdMetres == 0 {
+		cfg.ThresholdMetres = DefaultNearMissThresholdMetres
+	}
+	if cfg.MinSpeedMps == 0 {
+		cfg.MinSpeedMps = DefaultNearMissMinSpeedMps
+	}
+	return &NearMissDetector{cfg: cfg, open: make(map[nearMissPair]*NearMiss)}, nil
+}
+
+// Config returns the detector settings with defaults filled in.
+func (d *NearMissDetector) Config() NearMissConfig {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cfg
+}
+
+type nearMissCell struct{ x, y int }
+
+// Observe checks the tracks of one frame and returns the encounters that
+// ended: the pair moved apart, stopped meeting the speed conditions, or a
+// track is no longer present. Events are ordered by track IDs.
+func (d *NearMissDetector) Observe(tracks []*TrackedObject) []NearMiss {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	threshold := d.cfg.ThresholdMetres
+	grid := make(map[nearMissCell][]*TrackedObject)
+	for _, t := range tracks {
+		if speedOf(t) < d.cfg.MinSpeedMps || !d.classOfInterest(t.ObjectClass) {
+			continue
+		}
+		cell := nearMissCell{int(math.Floor(float64(t.X) / threshold)), int(math.Floor(float64(t.Y) / threshold))}
+		grid[cell] = append(grid[cell], t)
+	}
+
+	seen := make(map[nearMissPair]bool)
+	for cell, members := range grid {
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				for _, a := range members {
+					for _, b := range grid[nearMissCell{cell.x + dx, cell.y + dy}] {
+						if a.TrackID < b.TrackID {
+							d.checkPairLocked(a, b, seen)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	var ended []NearMiss
+	for key, ev := range d.open {
+		if !seen[key] {
+			ended = append(ended, *ev)
+			delete(d.open, key)
+		}
+	}
+	sortNearMisses(ended)
+	for _, ev := range ended {
+		diagf("Near miss: tracks=%s/%s classes=%s/%s min_distance=%.2fm relative_speed=%.1f",
+			ev.TrackA, ev.TrackB, ev.ClassA, ev.ClassB, ev.MinDistanceM, ev.RelativeSpeedMps)
+	}
+	return ended
+}
+
+// Flush ends every open encounter and returns them, e.g. at shutdown.
+func (d *NearMissDetector) Flush() []NearMiss {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var ended []NearMiss
+	for key, ev := range d.open {
+		ended = append(ended, *ev)
+		delete(d.open, key)
+	}
+	sortNearMisses(ended)
+	return ended
+}
+
+// checkPairLocked opens or updates the encounter between a and b when
+// they are within the threshold and approaching fast enough.
+func (d *NearMissDetector) checkPairLocked(a, b *TrackedObject, seen map[nearMissPair]bool) {
+	if len(d.cfg.ClassPairs) > 0 && !d.pairOfInterest(a.ObjectClass, b.ObjectClass) {
+		return
+	}
+	dist := math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+	if dist > d.cfg.ThresholdMetres {
+		return
+	}
+	rel := math.Hypot(float64(a.VX-b.VX), float64(a.VY-b.VY))
+	if rel < d.cfg.MinRelativeSpeedMps {
+		return
+	}
+
+	key := nearMissPair{a.TrackID, b.TrackID}
+	seen[key] = true
+	now := a.EndUnixNanos
+	if b.EndUnixNanos > now {
+		now = b.EndUnixNanos
+	}
+	ev := d.open[key]
+	if ev == nil {
+		ev = &NearMiss{
+			TrackA: a.TrackID, TrackB: b.TrackID,
+			SensorID:     a.SensorID,
+			MinDistanceM: float32(math.Inf(1)),
+			StartNanos:   now,
+		}
+		d.open[key] = ev
+	}
+	ev.ClassA, ev.ClassB = a.ObjectClass, b.ObjectClass
+	ev.EndNanos = now
+	if float32(dist) < ev.MinDistanceM {
+		ev.MinDistanceM = float32(dist)
+		ev.RelativeSpeedMps = float32(rel)
+		ev.TimestampNanos = now
+		ev.X, ev.Y = (a.X+b.X)/2, (a.Y+b.Y)/2
+	}
+}
+
+// classOfInterest reports whether a track of this class can take part in
+// any configured pair.
+func (d *NearMissDetector) classOfInterest(class string) bool {
+	if len(d.cfg.ClassPairs) == 0 {
+		return true
+	}
+	for _, p := range d.cfg.ClassPairs {
+		if p.A == class || p.B == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *NearMissDetector) pairOfInterest(a, b string) bool {
+	for _, p := range d.cfg.ClassPairs {
+		if p.matches(a, b) {
+			return true
+		}
+	}
+	return false
+}
+
+func speedOf(t *TrackedObject) float64 {
+	return math.Hypot(float64(t.VX), float64(t.VY))
+}
+
+func sortNearMisses(events []NearMiss) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].TrackA != events[j].TrackA {
+			return events[i].TrackA < events[j].TrackA
+		}
+		return events[i].TrackB < events[j].TrackB
+	})
+}
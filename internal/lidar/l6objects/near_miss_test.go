@@ -0,0 +1,99 @@
+package l6objects
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestNearMissDetector(t *testing.T, cfg NearMissConfig) *NearMissDetector {
+	t.Helper()
+	d, err := NewNearMissDetector(cfg)
+	if err != nil {
+		t.Fatalf("NewNearMissDetector: %v", err)
+	}
+	return d
+}
+
+func TestNearMissDetector_CarPassesPedestrian(t *testing.T) {
+	d := newTestNearMissDetector(t, NearMissConfig{
+		ThresholdMetres:     2,
+		MinRelativeSpeedMps: 3,
+		ClassPairs:          []ClassPair{{A: "car", B: "pedestrian"}},
+	})
+	car := crossingTestTrack("car-1", "car")
+	ped := crossingTestTrack("ped-1", "pedestrian")
+	friend := crossingTestTrack("ped-2", "pedestrian")
+	parked := crossingTestTrack("car-2", "car")
+
+	var events []NearMiss
+	for i := 0; i <= 25; i++ {
+		ts := float64(i) * 0.1
+		// The car overtakes a walker 1.5 m to its side; a second walker
+		// keeps pace beside the first and a car is parked alongside.
+		frame := []*TrackedObject{
+			moveTo(car, float32(-10+10*ts), 0, 10, ts),
+			moveTo(ped, float32(ts), 1.5, 1, ts),
+			moveTo(friend, float32(ts), 2.2, 1, ts),
+			moveTo(parked, float32(ts)+0.5, 3, 0, ts),
+		}
+		events = append(events, d.Observe(frame)...)
+	}
+	events = append(events, d.Flush()...)
+
+	if len(events) != 1 {
+		t.Fatalf("got %d near misses, want 1: %+v", len(events), events)
+	}
+	ev := events[0]
+	if ev.TrackA != "car-1" || ev.TrackB != "ped-1" || ev.ClassA != "car" || ev.ClassB != "pedestrian" {
+		t.Errorf("event pair = %s(%s)/%s(%s)", ev.TrackA, ev.ClassA, ev.TrackB, ev.ClassB)
+	}
+	if math.Abs(float64(ev.MinDistanceM)-1.5) > 0.15 {
+		t.Errorf("min distance = %.2f m, want about 1.5 m", ev.MinDistanceM)
+	}
+	if math.Abs(float64(ev.RelativeSpeedMps)-9) > 0.01 {
+		t.Errorf("relative speed = %.2f m/s, want 9", ev.RelativeSpeedMps)
+	}
+	closest := time.Duration(ev.TimestampNanos)
+	if closest < 1000*time.Millisecond || closest > 1200*time.Millisecond {
+		t.Errorf("closest approach at %v, want about 1.1s", closest)
+	}
+	if ev.StartNanos >= ev.EndNanos || ev.TimestampNanos < ev.StartNanos || ev.TimestampNanos > ev.EndNanos {
+		t.Errorf("encounter times out of order: start=%d closest=%d end=%d", ev.StartNanos, ev.TimestampNanos, ev.EndNanos)
+	}
+}
+
+func TestNearMissDetector_GatingAndTrackLoss(t *testing.T) {
+	d := newTestNearMissDetector(t, NearMissConfig{ThresholdMetres: 2})
+	a := crossingTestTrack("a", "cyclist")
+	b := crossingTestTrack("b", "car")
+
+	// Either side of a grid cell boundary, but 0.4 m apart.
+	if got := d.Observe([]*TrackedObject{moveTo(a, 1.8, 0, 5, 0), moveTo(b, 2.2, 0, -5, 0)}); len(got) != 0 {
+		t.Fatalf("open encounter reported early: %+v", got)
+	}
+	// b drops out of the confirmed set: the encounter ends.
+	got := d.Observe([]*TrackedObject{moveTo(a, 2.3, 0, 5, 0.1)})
+	if len(got) != 1 || got[0].TrackA != "a" || got[0].TrackB != "b" {
+		t.Fatalf("expected the a/b encounter to end, got %+v", got)
+	}
+	if math.Abs(float64(got[0].MinDistanceM)-0.4) > 1e-4 {
+		t.Errorf("min distance = %v, want 0.4", got[0].MinDistanceM)
+	}
+	if len(d.Flush()) != 0 {
+		t.Error("no encounter should remain open")
+	}
+}
+
+func TestNearMissConfig_Validate(t *testing.T) {
+	if _, err := NewNearMissDetector(NearMissConfig{ThresholdMetres: -1}); err == nil {
+		t.Error("expected an error for a negative threshold")
+	}
+	if _, err := NewNearMissDetector(NearMissConfig{ClassPairs: []ClassPair{{A: "car"}}}); err == nil {
+		t.Error("expected an error for an incomplete class pair")
+	}
+	d := newTestNearMissDetector(t, NearMissConfig{})
+	if cfg := d.Config(); cfg.ThresholdMetres != DefaultNearMissThresholdMetres || cfg.MinSpeedMps != DefaultNearMissMinSpeedMps {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+}
@@ -0,0 +1,308 @@
+package l6objects
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Origin–destination matrices.
+//
+// Each completed track is placed in the edge zone it entered through and
+// the zone it left by, and the pairs are counted per hour into an
+// origin–destination (OD) matrix for planning. A track whose first (or
+// last) positions lie in no zone was born (or died) mid-scene — a split
+// fragment, an occlusion, an object that parked — and is counted under
+// ODMidScene rather than dropped, so every track is accounted for.
+
+// ODMidScene is the origin or destination of a track that did not enter
+// or leave through any zone.
+const ODMidScene = "mid_scene"
+
+// DefaultODEdgeSamples is how many positions at each end of a track are
+// searched for its entry and exit zone, so a track first seen just outside
+// a zone drawn inside the field of view still enters through it.
+const DefaultODEdgeSamples = 5
+
+// ODZone is an axis-aligned world-frame area at a scene edge, in metres.
+// SensorID restricts the zone to one sensor's tracks; empty applies it to
+// all.
+type ODZone struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name,omitempty"`
+	SensorID string  `json:"sensor_id,omitempty"`
+	MinX     float64 `json:"min_x"`
+	MinY     float64 `json:"min_y"`
+	MaxX     float64 `json:"max_x"`
+	MaxY     float64 `json:"max_y"`
+}
+
+func (z ODZone) contains(x, y float64) bool {
+	return x >= z.MinX && x <= z.MaxX && y >= z.MinY && y <= z.MaxY
+}
+
+// ODConfig is the file form of the origin–destination zones.
+type ODConfig struct {
+	Zones []ODZone `json:"zones"`
+	// EdgeSamples is how many positions at each end of a track are
+	// searched for a zone. Zero means DefaultODEdgeSamples.
+	EdgeSamples int `json:"edge_samples,omitempty"`
+	// Classes restricts counting to these object classes ("other" for
+	// unclassified tracks); empty counts every class.
+	Classes []string `json:"classes,omitempty"`
+}
+
+// Validate checks zone IDs are present and unique, every zone has area
+// and no zone claims the mid-scene bucket's name.
+func (c ODConfig) Validate() error {
+	if c.EdgeSamples < 0 {
+		return fmt.Errorf("OD edge samples must be >= 0, got %d", c.EdgeSamples)
+	}
+	seen := make(map[string]bool, len(c.Zones))
+	for i, z := range c.Zones {
+		if z.ID == "" {
+			return fmt.Errorf("OD zone %d: id is required", i)
+		}
+		if z.ID == ODMidScene {
+			return fmt.Errorf("OD zone %d: id %q is reserved", i, ODMidScene)
+		}
+		if seen[z.ID] {
+			return fmt.Errorf("OD zone %q: duplicate id", z.ID)
+		}
+		seen[z.ID] = true
+		if z.MaxX <= z.MinX || z.MaxY <= z.MinY {
+			return fmt.Errorf("OD zone %q: max must exceed min on both axes", z.ID)
+		}
+	}
+	return nil
+}
+
+// LoadODConfig reads an ODConfig from a JSON file.
+func LoadODConfig(path string) (ODConfig, error) {
+	var cfg ODConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse OD zones %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("OD zones %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ODTrip is one track's origin and destination.
+type ODTrip struct {
+	TrackID        string `json:"track_id"`
+	SensorID       string `json:"sensor_id,omitempty"`
+	Class          string `json:"class"`
+	Origin         string `json:"origin"`
+	Destination    string `json:"destination"`
+	EntryUnixNanos int64  `json:"entry_unix_nanos"`
+	ExitUnixNanos  int64  `json:"exit_unix_nanos"`
+}
+
+type odPair struct{ origin, destination string }
+
+// ODMatrix counts completed tracks by origin zone, destination zone and
+// the UTC hour they entered the scene. It is safe for concurrent use.
+type ODMatrix struct {
+	cfg     ODConfig
+	classes map[string]bool
+
+	mu     sync.Mutex
+	counts map[int64]map[odPair]int // hour start (Unix seconds) → pair → count
+}
+
+// NewODMatrix validates cfg and returns an empty matrix.
+func NewODMatrix(cfg ODConfig) (*ODMatrix, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.EdgeSamples == 0 {
+		cfg.EdgeSamples = DefaultODEdgeSamples
+	}
+	m := &ODMatrix{cfg: cfg, counts: make(map[int64]map[odPair]int)}
+	if len(cfg.Classes) > 0 {
+		m.classes = make(map[string]bool, len(cfg.Classes))
+		for _, c := range cfg.Classes {
+			m.classes[c] = true
+		}
+	}
+	return m, nil
+}
+
+// Trip returns a track's origin and destination without counting it, and
+// false for a nil or still-tentative track or one of a class not counted.
+func (m *ODMatrix) Trip(track *TrackedObject) (ODTrip, bool) {
+	if track == nil || track.TrackState == TrackTentative {
+		return ODTrip{}, false
+	}
+	class := track.ObjectClass
+	if class == "" {
+		class = unclassifiedClass
+	}
+	if m.classes != nil && !m.classes[class] {
+		return ODTrip{}, false
+	}
+
+	trip := ODTrip{
+		TrackID:        track.TrackID,
+		SensorID:       track.SensorID,
+		Class:          class,
+		Origin:         ODMidScene,
+		Destination:    ODMidScene,
+		EntryUnixNanos: track.StartUnixNanos,
+		ExitUnixNanos:  track.EndUnixNanos,
+	}
+	history := track.History
+	if len(history) == 0 {
+		// A track with no history is placed by its current position.
+		zone := m.zoneAt(track.SensorID, float64(track.X), float64(track.Y))
+		trip.Origin, trip.Destination = zone, zone
+		return trip, true
+	}
+	if trip.EntryUnixNanos == 0 {
+		trip.EntryUnixNanos = history[0].Timestamp
+	}
+	if trip.ExitUnixNanos == 0 {
+		trip.ExitUnixNanos = history[len(history)-1].Timestamp
+	}
+
+	// The entry and exit windows never overlap by more than the middle
+	// point, so a short track that stops mid-scene does not take its
+	// origin as its destination.
+	n := min(m.cfg.EdgeSamples, (len(history)+1)/2)
+	for _, p := range history[:n] {
+		if zone := m.zoneAt(track.SensorID, float64(p.X), float64(p.Y)); zone != ODMidScene {
+			trip.Origin = zone
+			break
+		}
+	}
+	for i := len(history) - 1; i >= len(history)-n; i-- {
+		p := history[i]
+		if zone := m.zoneAt(track.SensorID, float64(p.X), float64(p.Y)); zone != ODMidScene {
+			trip.Destination = zone
+			break
+		}
+	}
+	return trip, true
+}
+
+// Record counts a completed track and returns its trip, or false when
+// Trip would.
+func (m *ODMatrix) Record(track *TrackedObject) (ODTrip, bool) {
+	trip, ok := m.Trip(track)
+	if !ok {
+		return trip, false
+	}
+	hour := odHour(trip.EntryUnixNanos).Unix()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cells := m.counts[hour]
+	if cells == nil {
+		cells = make(map[odPair]int)
+		m.counts[hour] = cells
+	}
+	cells[odPair{trip.Origin, trip.Destination}]++
+	return trip, true
+}
+
+// zoneAt returns the first configured zone containing (x, y) for the
+// sensor, or ODMidScene.
+func (m *ODMatrix) zoneAt(sensorID string, x, y float64) string {
+	for _, z := range m.cfg.Zones {
+		if z.SensorID != "" && z.SensorID != sensorID {
+			continue
+		}
+		if z.contains(x, y) {
+			return z.ID
+		}
+	}
+	return ODMidScene
+}
+
+// Labels returns the matrix's row and column labels: the zone IDs in
+// configuration order, then ODMidScene.
+func (m *ODMatrix) Labels() []string {
+	labels := make([]string, 0, len(m.cfg.Zones)+1)
+	for _, z := range m.cfg.Zones {
+		labels = append(labels, z.ID)
+	}
+	return append(labels, ODMidScene)
+}
+
+// Hours returns the start of every hour with at least one trip, in order.
+func (m *ODMatrix) Hours() []time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hours := make([]time.Time, 0, len(m.counts))
+	for h := range m.counts {
+		hours = append(hours, time.Unix(h, 0).UTC())
+	}
+	sort.Slice(hours, func(i, j int) bool { return hours[i].Before(hours[j]) })
+	return hours
+}
+
+// Count returns the number of trips from origin to destination that
+// entered the scene in the hour containing t.
+func (m *ODMatrix) Count(t time.Time, origin, destination string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[t.UTC().Truncate(time.Hour).Unix()][odPair{origin, destination}]
+}
+
+// Total returns the number of trips counted.
+func (m *ODMatrix) Total() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	total := 0
+	for _, cells := range m.counts {
+		for _, n := range cells {
+			total += n
+		}
+	}
+	return total
+}
+
+// WriteCSV writes one square matrix per hour: a header of
+// hour_start, origin, each destination label and total, then a row per
+// origin label for every hour with trips. Hours are RFC 3339 in UTC.
+func (m *ODMatrix) WriteCSV(w io.Writer) error {
+	labels := m.Labels()
+	cw := csv.NewWriter(w)
+	header := append([]string{"hour_start", "origin"}, labels...)
+	if err := cw.Write(append(header, "total")); err != nil {
+		return err
+	}
+	for _, hour := range m.Hours() {
+		for _, origin := range labels {
+			row := []string{hour.Format(time.RFC3339), origin}
+			total := 0
+			for _, destination := range labels {
+				n := m.Count(hour, origin, destination)
+				total += n
+				row = append(row, strconv.Itoa(n))
+			}
+			if err := cw.Write(append(row, strconv.Itoa(total))); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// odHour returns the UTC hour containing a Unix-nanosecond timestamp.
+func odHour(nanos int64) time.Time {
+	return time.Unix(0, nanos).UTC().Truncate(time.Hour)
+}
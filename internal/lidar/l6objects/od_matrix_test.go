@@ -0,0 +1,180 @@
+package l6objects
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// odTestConfig has a west and an east edge zone either side of a 40 m
+// scene along X.
+func odTestConfig() ODConfig {
+	return ODConfig{Zones: []ODZone{
+		{ID: "west", MinX: -25, MinY: -5, MaxX: -15, MaxY: 5},
+		{ID: "east", MinX: 15, MinY: -5, MaxX: 25, MaxY: 5},
+	}}
+}
+
+// odTrack returns a confirmed car track moving along Y=0 from fromX to
+// toX in 1 m steps, one step per 100 ms starting at start.
+func odTrack(id string, fromX, toX float32, start time.Time) *TrackedObject {
+	track := &TrackedObject{
+		TrackID: id,
+		TrackMeasurement: l5tracks.TrackMeasurement{
+			TrackState:  TrackConfirmed,
+			ObjectClass: "car",
+		},
+	}
+	step := float32(1)
+	if toX < fromX {
+		step = -1
+	}
+	ts := start.UnixNano()
+	for x := fromX; (step > 0 && x <= toX) || (step < 0 && x >= toX); x += step {
+		track.History = append(track.History, TrackPoint{X: x, Timestamp: ts})
+		ts += int64(100 * time.Millisecond)
+	}
+	return track
+}
+
+func TestODMatrix_Trip(t *testing.T) {
+	m, err := NewODMatrix(odTestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 18, 8, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name, origin, destination string
+		fromX, toX                float32
+	}{
+		{"eastbound", "west", "east", -20, 20},
+		{"westbound", "east", "west", 22, -22},
+		// First and last positions beyond the zones, within the edge samples.
+		{"beyond the zones", "west", "east", -28, 28},
+		{"born mid-scene", ODMidScene, "east", 0, 20},
+		{"died mid-scene", "west", ODMidScene, -20, 5},
+		{"never entered", ODMidScene, ODMidScene, -5, 5},
+	} {
+		trip, ok := m.Trip(odTrack(tc.name, tc.fromX, tc.toX, start))
+		if !ok {
+			t.Fatalf("%s: not counted", tc.name)
+		}
+		if trip.Origin != tc.origin || trip.Destination != tc.destination {
+			t.Errorf("%s: %s → %s, want %s → %s", tc.name, trip.Origin, trip.Destination, tc.origin, tc.destination)
+		}
+		if trip.EntryUnixNanos != start.UnixNano() {
+			t.Errorf("%s: entry %d, want first history timestamp", tc.name, trip.EntryUnixNanos)
+		}
+	}
+
+	// A two-point track is not both entering and leaving by its first point.
+	short := odTrack("short", -20, -20, start)
+	short.History = append(short.History, TrackPoint{X: 0})
+	if trip, _ := m.Trip(short); trip.Origin != "west" || trip.Destination != ODMidScene {
+		t.Errorf("short track: %s → %s, want west → mid_scene", trip.Origin, trip.Destination)
+	}
+
+	tentative := odTrack("t", -20, 20, start)
+	tentative.TrackState = TrackTentative
+	if _, ok := m.Trip(tentative); ok {
+		t.Error("tentative track should not be counted")
+	}
+}
+
+func TestODMatrix_FiltersBySensorAndClass(t *testing.T) {
+	cfg := odTestConfig()
+	cfg.Zones[1].SensorID = "sensor-b"
+	cfg.Classes = []string{"car"}
+	m, err := NewODMatrix(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := odTrack("a", -20, 20, time.Unix(0, 0))
+	track.SensorID = "sensor-a"
+	if trip, _ := m.Trip(track); trip.Destination != ODMidScene {
+		t.Errorf("zone for sensor-b matched a sensor-a track: %+v", trip)
+	}
+	track.ObjectClass = "pedestrian"
+	if _, ok := m.Trip(track); ok {
+		t.Error("pedestrian counted despite class filter")
+	}
+}
+
+func TestODMatrix_HourlyCSV(t *testing.T) {
+	m, err := NewODMatrix(odTestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h8 := time.Date(2026, 10, 18, 8, 10, 0, 0, time.UTC)
+	h9 := h8.Add(time.Hour)
+	for i, tr := range []*TrackedObject{
+		odTrack("1", -20, 20, h8),
+		odTrack("2", -20, 20, h8.Add(40*time.Minute)),
+		odTrack("3", 20, -20, h8),
+		odTrack("4", 0, 20, h9),
+	} {
+		if _, ok := m.Record(tr); !ok {
+			t.Fatalf("track %d not recorded", i)
+		}
+	}
+
+	if got := m.Count(h8, "west", "east"); got != 2 {
+		t.Errorf("08:00 west→east = %d, want 2", got)
+	}
+	if got := m.Count(h9, ODMidScene, "east"); got != 1 {
+		t.Errorf("09:00 mid_scene→east = %d, want 1", got)
+	}
+	if m.Total() != 4 || len(m.Hours()) != 2 {
+		t.Errorf("total %d over %d hours, want 4 over 2", m.Total(), len(m.Hours()))
+	}
+
+	var sb strings.Builder
+	if err := m.WriteCSV(&sb); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	want := []string{
+		"hour_start,origin,west,east,mid_scene,total",
+		"2026-10-18T08:00:00Z,west,0,2,0,2",
+		"2026-10-18T08:00:00Z,east,1,0,0,1",
+		"2026-10-18T08:00:00Z,mid_scene,0,0,0,0",
+		"2026-10-18T09:00:00Z,west,0,0,0,0",
+		"2026-10-18T09:00:00Z,east,0,0,0,0",
+		"2026-10-18T09:00:00Z,mid_scene,0,1,0,1",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("CSV:\n%s", sb.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestLoadODConfig(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "od.json")
+	os.WriteFile(good, []byte(`{"zones":[{"id":"north","min_x":-5,"min_y":20,"max_x":5,"max_y":30}],"edge_samples":3}`), 0o644)
+	cfg, err := LoadODConfig(good)
+	if err != nil || len(cfg.Zones) != 1 || cfg.EdgeSamples != 3 {
+		t.Fatalf("LoadODConfig = %+v, %v", cfg, err)
+	}
+
+	for name, body := range map[string]string{
+		"reserved id": `{"zones":[{"id":"mid_scene","min_x":0,"min_y":0,"max_x":1,"max_y":1}]}`,
+		"duplicate":   `{"zones":[{"id":"a","min_x":0,"min_y":0,"max_x":1,"max_y":1},{"id":"a","min_x":0,"min_y":0,"max_x":1,"max_y":1}]}`,
+		"empty box":   `{"zones":[{"id":"a","min_x":1,"min_y":0,"max_x":1,"max_y":1}]}`,
+		"bad json":    `{`,
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_")+".json")
+		os.WriteFile(path, []byte(body), 0o644)
+		if _, err := LoadODConfig(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
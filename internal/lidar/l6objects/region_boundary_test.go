@@ -0,0 +1,105 @@
+package l6objects
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// boundaryAtX is a RegionLookup with region 0 for x < X and region 1 beyond.
+type boundaryAtX struct{ X float32 }
+
+func (b boundaryAtX) RegionAt(x, _ float32) int {
+	if x < b.X {
+		return 0
+	}
+	return 1
+}
+
+// boundaryCarFrame returns the clusters for a 4.5 × 1.8 m car centred at
+// (x, 5). While the car straddles the region boundary at x = 10 the two
+// regions segment it separately, leaving a 0.3 m gap at the boundary.
+func boundaryCarFrame(x float32) []l5tracks.WorldCluster {
+	const (
+		length, width, height = 4.5, 1.8, 1.5
+		boundary, halfGap     = 10.0, 0.15
+		pointsPerM            = 40
+	)
+	piece := func(from, to float32) l5tracks.WorldCluster {
+		l := to - from
+		return l5tracks.WorldCluster{
+			CentroidX: (from + to) / 2, CentroidY: 5, CentroidZ: height / 2,
+			BoundingBoxLength: max(l, width), BoundingBoxWidth: min(l, width), BoundingBoxHeight: height,
+			PointsCount: int(l * pointsPerM), HeightP95: height * 0.95,
+		}
+	}
+	rear, front := x-length/2, x+length/2
+	if front <= boundary || rear >= boundary {
+		return []l5tracks.WorldCluster{piece(rear, front)}
+	}
+	var out []l5tracks.WorldCluster
+	if boundary-halfGap-rear > 0.1 {
+		out = append(out, piece(rear, boundary-halfGap))
+	}
+	if front-(boundary+halfGap) > 0.1 {
+		out = append(out, piece(boundary+halfGap, front))
+	}
+	return out
+}
+
+// runBoundaryCrossing creeps a car across the boundary at 2 m/s, classifying
+// every track each frame, and returns the tracker and the classes reported
+// for the first track.
+func runBoundaryCrossing(t *testing.T, continuity *l5tracks.RegionContinuityConfig) (*l5tracks.Tracker, []string) {
+	t.Helper()
+	config := l5tracks.DefaultTrackerConfig()
+	config.RegionContinuity = continuity
+	tracker := l5tracks.NewTracker(config)
+	classifier := NewTrackClassifier()
+
+	now := time.Unix(1_700_000_000, 0)
+	var firstID string
+	var classes []string
+	for frame := 0; frame < 80; frame++ {
+		ts := now.Add(time.Duration(frame) * 100 * time.Millisecond)
+		tracker.Update(boundaryCarFrame(2+0.2*float32(frame)), ts)
+		for _, track := range tracker.GetActiveTracks() {
+			if firstID == "" {
+				firstID = track.TrackID
+			}
+			if track.ObservationCount < classifier.MinObservations {
+				continue
+			}
+			classifier.ClassifyAndUpdate(track)
+			if track.TrackID == firstID {
+				classes = append(classes, track.ObjectClass)
+			}
+		}
+	}
+	return tracker, classes
+}
+
+func TestRegionContinuity_BoundaryCrossingKeepsOneTrack(t *testing.T) {
+	tracker, classes := runBoundaryCrossing(t, &l5tracks.RegionContinuityConfig{
+		Enabled: true,
+		Regions: boundaryAtX{X: 10},
+	})
+	if tracker.TracksCreated != 1 {
+		t.Fatalf("TracksCreated = %d, want one continuous track across the boundary", tracker.TracksCreated)
+	}
+	if len(classes) == 0 {
+		t.Fatal("track was never classified")
+	}
+	for i, class := range classes {
+		if class != string(ClassCar) {
+			t.Fatalf("class at classification %d = %q, want %q throughout (classes %v)", i, class, ClassCar, classes)
+		}
+	}
+
+	// Without region continuity the straddling fragments split the object.
+	tracker, _ = runBoundaryCrossing(t, nil)
+	if tracker.TracksCreated < 2 {
+		t.Errorf("without continuity TracksCreated = %d, expected the boundary to fragment the track", tracker.TracksCreated)
+	}
+}
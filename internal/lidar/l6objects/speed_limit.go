@@ -0,0 +1,406 @@
+package l6objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Speed-limit tagging.
+//
+// Each confirmed vehicle track is compared against the speed limit that
+// applies where it was seen: the zone containing most of its positions,
+// else the limit configured for its sensor, else the default. The speed
+// compared is a sustained speed rather than the raw maximum, so a single
+// noisy velocity estimate does not tag a track as over the limit. Per-track
+// percentiles are not used: percentile labels are reserved for aggregates
+// across many tracks.
+
+// Speed-limit defaults.
+const (
+	DefaultSpeedLimitUnits            = "kmph"
+	DefaultSpeedLimitSustainedSamples = 5   // 0.5 s at 10 Hz
+	speedLimitMinDisplacementMetres   = 1.0 // net movement needed to name a direction from history
+	speedLimitMinDirectionSpeedMps    = 0.5 // else the velocity must reach this
+
+	// speedLimitEpsilon absorbs float32 rounding in speed samples, so a
+	// track held exactly at the limit is not tagged over it.
+	speedLimitEpsilon = 1e-3
+)
+
+// Speed metrics a track can be compared on.
+const (
+	// SpeedLimitMetricSustained is the highest speed held for
+	// SustainedSamples consecutive observations.
+	SpeedLimitMetricSustained = "sustained"
+	// SpeedLimitMetricMax is the raw maximum observed speed.
+	SpeedLimitMetricMax = "max"
+)
+
+// speedLimitVehicleClasses are tagged unless Classes overrides them.
+var speedLimitVehicleClasses = []string{
+	string(ClassCar), string(ClassTruck), string(ClassBus), string(ClassMotorcyclist),
+}
+
+// SpeedLimitZone is an axis-aligned world-frame area, in metres, with its
+// own speed limit. SensorID restricts the zone to one sensor's tracks;
+// empty applies it to all.
+type SpeedLimitZone struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name,omitempty"`
+	SensorID string  `json:"sensor_id,omitempty"`
+	MinX     float64 `json:"min_x"`
+	MinY     float64 `json:"min_y"`
+	MaxX     float64 `json:"max_x"`
+	MaxY     float64 `json:"max_y"`
+	Limit    float64 `json:"limit"`
+}
+
+func (z SpeedLimitZone) contains(x, y float64) bool {
+	return x >= z.MinX && x <= z.MaxX && y >= z.MinY && y <= z.MaxY
+}
+
+// SpeedLimitConfig is the file form of the speed-limit settings. Limits
+// and tolerance are in Units.
+type SpeedLimitConfig struct {
+	// Units is "kmph" or "mph". Empty means DefaultSpeedLimitUnits.
+	Units string `json:"units,omitempty"`
+	// DefaultLimit applies where no zone or sensor limit does. Zero leaves
+	// such tracks untagged.
+	DefaultLimit float64 `json:"default_limit,omitempty"`
+	// SensorLimits overrides DefaultLimit per sensor ID.
+	SensorLimits map[string]float64 `json:"sensor_limits,omitempty"`
+	// Zones override the sensor and default limits inside their bounds.
+	Zones []SpeedLimitZone `json:"zones,omitempty"`
+	// Tolerance is how far over the limit a track must be to be tagged
+	// over it. A track exactly at limit+Tolerance is not over.
+	Tolerance float64 `json:"tolerance,omitempty"`
+	// Metric is SpeedLimitMetricSustained (default) or SpeedLimitMetricMax.
+	Metric string `json:"metric,omitempty"`
+	// SustainedSamples is the observation count the sustained speed must
+	// be held for. Zero means DefaultSpeedLimitSustainedSamples.
+	SustainedSamples int `json:"sustained_samples,omitempty"`
+	// Classes restricts tagging to these object classes; empty tags car,
+	// truck, bus and motorcyclist.
+	Classes []string `json:"classes,omitempty"`
+	// NorthBearingDeg is the compass bearing of the world +Y axis, used to
+	// name the direction of travel. Zero means +Y points north.
+	NorthBearingDeg float64 `json:"north_bearing_deg,omitempty"`
+}
+
+// Validate checks units, metric and limits are usable and zone IDs unique.
+func (c SpeedLimitConfig) Validate() error {
+	switch c.Units {
+	case "", "kmph", "mph":
+	default:
+		return fmt.Errorf("speed-limit units must be kmph or mph, got %q", c.Units)
+	}
+	switch c.Metric {
+	case "", SpeedLimitMetricSustained, SpeedLimitMetricMax:
+	default:
+		return fmt.Errorf("speed-limit metric must be %s or %s, got %q",
+			SpeedLimitMetricSustained, SpeedLimitMetricMax, c.Metric)
+	}
+	if c.DefaultLimit < 0 || c.Tolerance < 0 || c.SustainedSamples < 0 {
+		return fmt.Errorf("speed-limit default, tolerance and sustained samples must be >= 0")
+	}
+	for id, limit := range c.SensorLimits {
+		if limit <= 0 {
+			return fmt.Errorf("speed limit for sensor %q must be > 0, got %v", id, limit)
+		}
+	}
+	seen := make(map[string]bool, len(c.Zones))
+	for i, z := range c.Zones {
+		if z.ID == "" {
+			return fmt.Errorf("speed-limit zone %d: id is required", i)
+		}
+		if seen[z.ID] {
+			return fmt.Errorf("speed-limit zone %q: duplicate id", z.ID)
+		}
+		seen[z.ID] = true
+		if z.MaxX <= z.MinX || z.MaxY <= z.MinY {
+			return fmt.Errorf("speed-limit zone %q: max must exceed min on both axes", z.ID)
+		}
+		if z.Limit <= 0 {
+			return fmt.Errorf("speed-limit zone %q: limit must be > 0, got %v", z.ID, z.Limit)
+		}
+	}
+	return nil
+}
+
+// LoadSpeedLimitConfig reads a SpeedLimitConfig from a JSON file.
+func LoadSpeedLimitConfig(path string) (SpeedLimitConfig, error) {
+	var cfg SpeedLimitConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse speed limits %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("speed limits %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SpeedLimitTag is the speed-limit verdict for one track. Speeds, limit
+// and margin are in Units; Margin is Speed minus Limit, so it is negative
+// under the limit.
+type SpeedLimitTag struct {
+	TrackID   string  `json:"track_id"`
+	SensorID  string  `json:"sensor_id,omitempty"`
+	Class     string  `json:"class"`
+	Zone      string  `json:"zone,omitempty"`
+	Units     string  `json:"units"`
+	Limit     float64 `json:"limit"`
+	Speed     float64 `json:"speed"`
+	Margin    float64 `json:"margin"`
+	OverLimit bool    `json:"over_limit"`
+	Direction string  `json:"direction,omitempty"`
+}
+
+// String reports the tag in words, e.g. "42 km/h in a 30 zone, northbound".
+func (t SpeedLimitTag) String() string {
+	unit := "km/h"
+	if t.Units == "mph" {
+		unit = "mph"
+	}
+	s := fmt.Sprintf("%.0f %s in a %.0f zone", t.Speed, unit, t.Limit)
+	if t.Direction != "" {
+		s += ", " + t.Direction
+	}
+	return s
+}
+
+// SpeedLimitCount is the number of tracks tagged, and tagged over the
+// limit, in one group.
+type SpeedLimitCount struct {
+	Tagged    int `json:"tagged"`
+	OverLimit int `json:"over_limit"`
+}
+
+// SpeedLimitSummary counts tagged tracks overall, per sensor, per zone
+// (sensor and default limits are counted under "") and per direction of
+// travel.
+type SpeedLimitSummary struct {
+	Tagged      int                        `json:"tagged"`
+	OverLimit   int                        `json:"over_limit"`
+	BySensor    map[string]SpeedLimitCount `json:"by_sensor"`
+	ByZone      map[string]SpeedLimitCount `json:"by_zone"`
+	ByDirection map[string]SpeedLimitCount `json:"by_direction"`
+}
+
+// SummariseSpeedLimitTags counts tags overall, by sensor, by zone and by
+// direction.
+func SummariseSpeedLimitTags(tags []SpeedLimitTag) SpeedLimitSummary {
+	s := SpeedLimitSummary{
+		BySensor:    make(map[string]SpeedLimitCount),
+		ByZone:      make(map[string]SpeedLimitCount),
+		ByDirection: make(map[string]SpeedLimitCount),
+	}
+	for _, t := range tags {
+		over := 0
+		if t.OverLimit {
+			over = 1
+		}
+		s.Tagged++
+		s.OverLimit += over
+		sc := s.BySensor[t.SensorID]
+		sc.Tagged++
+		sc.OverLimit += over
+		s.BySensor[t.SensorID] = sc
+		z := s.ByZone[t.Zone]
+		z.Tagged++
+		z.OverLimit += over
+		s.ByZone[t.Zone] = z
+		d := s.ByDirection[t.Direction]
+		d.Tagged++
+		d.OverLimit += over
+		s.ByDirection[t.Direction] = d
+	}
+	return s
+}
+
+// SpeedLimitTagger tags tracks against a SpeedLimitConfig. It holds no
+// per-track state and is safe for concurrent use.
+type SpeedLimitTagger struct {
+	cfg     SpeedLimitConfig
+	classes map[string]bool
+	toMps   float64 // multiply a limit in cfg.Units by this for m/s
+}
+
+// NewSpeedLimitTagger validates cfg and returns a tagger.
+func NewSpeedLimitTagger(cfg SpeedLimitConfig) (*SpeedLimitTagger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Units == "" {
+		cfg.Units = DefaultSpeedLimitUnits
+	}
+	if cfg.Metric == "" {
+		cfg.Metric = SpeedLimitMetricSustained
+	}
+	if cfg.SustainedSamples == 0 {
+		cfg.SustainedSamples = DefaultSpeedLimitSustainedSamples
+	}
+	classes := cfg.Classes
+	if len(classes) == 0 {
+		classes = speedLimitVehicleClasses
+	}
+	t := &SpeedLimitTagger{cfg: cfg, classes: make(map[string]bool, len(classes)), toMps: 1 / 3.6}
+	for _, c := range classes {
+		t.classes[c] = true
+	}
+	if cfg.Units == "mph" {
+		t.toMps = 0.44704
+	}
+	return t, nil
+}
+
+// Config returns the tagger's configuration with defaults applied.
+func (t *SpeedLimitTagger) Config() SpeedLimitConfig {
+	return t.cfg
+}
+
+// Tag returns the speed-limit tag for a track, and false when the track is
+// not a confirmed vehicle, has no speed samples, or no limit applies where
+// it was seen.
+func (t *SpeedLimitTagger) Tag(track *TrackedObject) (SpeedLimitTag, bool) {
+	if track == nil || track.TrackState != TrackConfirmed || !t.classes[track.ObjectClass] {
+		return SpeedLimitTag{}, false
+	}
+	zone, limit := t.limitFor(track)
+	if limit <= 0 {
+		return SpeedLimitTag{}, false
+	}
+	speedMps, ok := t.representativeSpeed(track)
+	if !ok {
+		return SpeedLimitTag{}, false
+	}
+
+	speed := speedMps / t.toMps
+	margin := speed - limit
+	return SpeedLimitTag{
+		TrackID:   track.TrackID,
+		SensorID:  track.SensorID,
+		Class:     track.ObjectClass,
+		Zone:      zone,
+		Units:     t.cfg.Units,
+		Limit:     limit,
+		Speed:     speed,
+		Margin:    margin,
+		OverLimit: margin > t.cfg.Tolerance+speedLimitEpsilon,
+		Direction: t.direction(track),
+	}, true
+}
+
+// TagAll tags every eligible track and returns the tags in input order.
+func (t *SpeedLimitTagger) TagAll(tracks []*TrackedObject) []SpeedLimitTag {
+	var tags []SpeedLimitTag
+	for _, track := range tracks {
+		if tag, ok := t.Tag(track); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// limitFor returns the zone (empty for a sensor or default limit) and the
+// limit that apply to a track. The zone is the one holding most of the
+// track's history positions, ties going to the first listed; a track with
+// no history is placed by its current position.
+func (t *SpeedLimitTagger) limitFor(track *TrackedObject) (string, float64) {
+	best, bestCount := -1, 0
+	for i, z := range t.cfg.Zones {
+		if z.SensorID != "" && z.SensorID != track.SensorID {
+			continue
+		}
+		count := 0
+		if len(track.History) == 0 {
+			if z.contains(float64(track.X), float64(track.Y)) {
+				count = 1
+			}
+		}
+		for _, p := range track.History {
+			if z.contains(float64(p.X), float64(p.Y)) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = i, count
+		}
+	}
+	if best >= 0 {
+		return t.cfg.Zones[best].ID, t.cfg.Zones[best].Limit
+	}
+	if limit, ok := t.cfg.SensorLimits[track.SensorID]; ok {
+		return "", limit
+	}
+	return "", t.cfg.DefaultLimit
+}
+
+// representativeSpeed returns the track speed compared with the limit, in
+// m/s.
+func (t *SpeedLimitTagger) representativeSpeed(track *TrackedObject) (float64, bool) {
+	if t.cfg.Metric == SpeedLimitMetricMax {
+		return float64(track.MaxSpeedMps), track.MaxSpeedMps > 0
+	}
+	return SustainedSpeed(track.SpeedHistory(), t.cfg.SustainedSamples)
+}
+
+// SustainedSpeed returns the highest speed held for n consecutive samples:
+// the maximum over every n-sample window of the window's minimum. With
+// fewer than n samples the minimum of all samples is used. It returns false
+// for an empty history.
+func SustainedSpeed(speeds []float32, n int) (float64, bool) {
+	if len(speeds) == 0 {
+		return 0, false
+	}
+	if n < 1 {
+		n = 1
+	}
+	if n > len(speeds) {
+		n = len(speeds)
+	}
+	best := math.Inf(-1)
+	for i := 0; i+n <= len(speeds); i++ {
+		low := math.Inf(1)
+		for _, v := range speeds[i : i+n] {
+			low = math.Min(low, float64(v))
+		}
+		best = math.Max(best, low)
+	}
+	return best, true
+}
+
+// direction names a track's direction of travel as northbound, eastbound,
+// southbound or westbound from its net displacement over its history, or
+// its velocity when the history is too short. It returns "" for a track
+// that has not moved far enough to tell.
+func (t *SpeedLimitTagger) direction(track *TrackedObject) string {
+	var dx, dy float64
+	if n := len(track.History); n >= 2 {
+		dx = float64(track.History[n-1].X - track.History[0].X)
+		dy = float64(track.History[n-1].Y - track.History[0].Y)
+	}
+	if math.Hypot(dx, dy) < speedLimitMinDisplacementMetres {
+		dx, dy = float64(track.VX), float64(track.VY)
+		if math.Hypot(dx, dy) < speedLimitMinDirectionSpeedMps {
+			return ""
+		}
+	}
+	bearing := math.Atan2(dx, dy)*180/math.Pi + t.cfg.NorthBearingDeg
+	bearing = math.Mod(math.Mod(bearing, 360)+360, 360)
+	switch {
+	case bearing < 45 || bearing >= 315:
+		return "northbound"
+	case bearing < 135:
+		return "eastbound"
+	case bearing < 225:
+		return "southbound"
+	default:
+		return "westbound"
+	}
+}
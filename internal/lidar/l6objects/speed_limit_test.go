@@ -0,0 +1,231 @@
+package l6objects
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// speedLimitTestTrack returns a confirmed track that drove along +Y from
+// (x, 0) to (x, 20), holding kph for every speed sample.
+func speedLimitTestTrack(id, class string, x float32, kph float64) *TrackedObject {
+	track := &TrackedObject{
+		TrackID: id,
+		TrackMeasurement: l5tracks.TrackMeasurement{
+			SensorID:    "sensor-a",
+			ObjectClass: class,
+			TrackState:  TrackConfirmed,
+		},
+	}
+	for i := 0; i <= 10; i++ {
+		track.History = append(track.History, TrackPoint{X: x, Y: float32(2 * i)})
+	}
+	speeds := make([]float32, 10)
+	for i := range speeds {
+		speeds[i] = float32(kph / 3.6)
+	}
+	track.SetSpeedHistory(speeds)
+	return track
+}
+
+func newTestSpeedLimitTagger(t *testing.T, cfg SpeedLimitConfig) *SpeedLimitTagger {
+	t.Helper()
+	tagger, err := NewSpeedLimitTagger(cfg)
+	if err != nil {
+		t.Fatalf("NewSpeedLimitTagger: %v", err)
+	}
+	return tagger
+}
+
+func TestSpeedLimitTagger_ThresholdBoundary(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance float64
+		kph       float64
+		wantOver  bool
+	}{
+		{"under the limit", 0, 29.9, false},
+		{"exactly at the limit", 0, 30, false},
+		{"just over the limit", 0, 30.1, true},
+		{"exactly at limit plus tolerance", 2, 32, false},
+		{"just over limit plus tolerance", 2, 32.1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagger := newTestSpeedLimitTagger(t, SpeedLimitConfig{DefaultLimit: 30, Tolerance: tt.tolerance})
+			tag, ok := tagger.Tag(speedLimitTestTrack("car-1", "car", 0, tt.kph))
+			if !ok {
+				t.Fatal("confirmed car was not tagged")
+			}
+			if tag.OverLimit != tt.wantOver {
+				t.Errorf("over_limit = %v at %.1f km/h, want %v", tag.OverLimit, tt.kph, tt.wantOver)
+			}
+			if math.Abs(tag.Margin-(tt.kph-30)) > 1e-4 {
+				t.Errorf("margin = %.4f, want %.4f", tag.Margin, tt.kph-30)
+			}
+		})
+	}
+}
+
+func TestSpeedLimitTagger_ReportsZoneAndDirection(t *testing.T) {
+	tagger := newTestSpeedLimitTagger(t, SpeedLimitConfig{
+		DefaultLimit: 50,
+		SensorLimits: map[string]float64{"sensor-b": 40},
+		Zones: []SpeedLimitZone{
+			{ID: "school", MinX: -5, MinY: -1, MaxX: 5, MaxY: 30, Limit: 30},
+		},
+	})
+
+	tag, ok := tagger.Tag(speedLimitTestTrack("car-1", "car", 0, 42))
+	if !ok {
+		t.Fatal("car in the zone was not tagged")
+	}
+	if tag.Zone != "school" || tag.Limit != 30 || !tag.OverLimit || tag.Direction != "northbound" {
+		t.Errorf("tag = %+v, want school zone, limit 30, over, northbound", tag)
+	}
+	if got := tag.String(); got != "42 km/h in a 30 zone, northbound" {
+		t.Errorf("String() = %q", got)
+	}
+
+	outside := speedLimitTestTrack("car-2", "car", 20, 42)
+	if tag, _ := tagger.Tag(outside); tag.Zone != "" || tag.Limit != 50 || tag.OverLimit {
+		t.Errorf("outside the zone: tag = %+v, want default limit 50, under", tag)
+	}
+	outside.SensorID = "sensor-b"
+	if tag, _ := tagger.Tag(outside); tag.Limit != 40 || !tag.OverLimit {
+		t.Errorf("sensor limit: tag = %+v, want limit 40, over", tag)
+	}
+
+	// Reverse the history: the car now drives towards -Y.
+	south := speedLimitTestTrack("car-3", "car", 0, 42)
+	for i, j := 0, len(south.History)-1; i < j; i, j = i+1, j-1 {
+		south.History[i], south.History[j] = south.History[j], south.History[i]
+	}
+	if tag, _ := tagger.Tag(south); tag.Direction != "southbound" {
+		t.Errorf("direction = %q, want southbound", tag.Direction)
+	}
+
+	// With +Y pointing east, the same track is eastbound.
+	rotated := newTestSpeedLimitTagger(t, SpeedLimitConfig{DefaultLimit: 50, NorthBearingDeg: 90})
+	if tag, _ := rotated.Tag(speedLimitTestTrack("car-4", "car", 20, 42)); tag.Direction != "eastbound" {
+		t.Errorf("rotated direction = %q, want eastbound", tag.Direction)
+	}
+}
+
+func TestSpeedLimitTagger_SkipsNonVehiclesAndUnconfirmed(t *testing.T) {
+	tagger := newTestSpeedLimitTagger(t, SpeedLimitConfig{DefaultLimit: 30})
+
+	if _, ok := tagger.Tag(speedLimitTestTrack("ped-1", "pedestrian", 0, 40)); ok {
+		t.Error("pedestrian should not be tagged")
+	}
+	tentative := speedLimitTestTrack("car-1", "car", 0, 40)
+	tentative.TrackState = TrackTentative
+	if _, ok := tagger.Tag(tentative); ok {
+		t.Error("tentative track should not be tagged")
+	}
+
+	noLimit := newTestSpeedLimitTagger(t, SpeedLimitConfig{})
+	if _, ok := noLimit.Tag(speedLimitTestTrack("car-2", "car", 0, 40)); ok {
+		t.Error("track should not be tagged where no limit applies")
+	}
+}
+
+func TestSpeedLimitTagger_SustainedIgnoresSpike(t *testing.T) {
+	track := speedLimitTestTrack("car-1", "car", 0, 28)
+	speeds := track.SpeedHistory()
+	speeds[4] = float32(60 / 3.6) // one noisy velocity estimate
+	track.SetSpeedHistory(speeds)
+	track.MaxSpeedMps = speeds[4]
+
+	sustained := newTestSpeedLimitTagger(t, SpeedLimitConfig{DefaultLimit: 30})
+	if tag, _ := sustained.Tag(track); tag.OverLimit {
+		t.Errorf("sustained speed %.1f km/h should be under 30", tag.Speed)
+	}
+	maxTagger := newTestSpeedLimitTagger(t, SpeedLimitConfig{DefaultLimit: 30, Metric: SpeedLimitMetricMax})
+	if tag, _ := maxTagger.Tag(track); !tag.OverLimit {
+		t.Errorf("max speed %.1f km/h should be over 30", tag.Speed)
+	}
+}
+
+func TestSustainedSpeed(t *testing.T) {
+	speeds := []float32{1, 5, 6, 7, 2, 9}
+	tests := []struct {
+		n    int
+		want float64
+	}{
+		{1, 9},
+		{3, 5},
+		{4, 2},
+		{10, 1},
+	}
+	for _, tt := range tests {
+		if got, _ := SustainedSpeed(speeds, tt.n); got != tt.want {
+			t.Errorf("SustainedSpeed(n=%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+	if _, ok := SustainedSpeed(nil, 3); ok {
+		t.Error("empty history should report no speed")
+	}
+}
+
+func TestSummariseSpeedLimitTags(t *testing.T) {
+	s := SummariseSpeedLimitTags([]SpeedLimitTag{
+		{SensorID: "a", Zone: "school", Direction: "northbound", OverLimit: true},
+		{SensorID: "b", Zone: "school", Direction: "southbound"},
+		{SensorID: "a", Direction: "northbound", OverLimit: true},
+	})
+	if s.Tagged != 3 || s.OverLimit != 2 {
+		t.Errorf("totals = %d tagged, %d over; want 3, 2", s.Tagged, s.OverLimit)
+	}
+	if got := s.BySensor["a"]; got != (SpeedLimitCount{Tagged: 2, OverLimit: 2}) {
+		t.Errorf("sensor a = %+v", got)
+	}
+	if got := s.ByZone["school"]; got != (SpeedLimitCount{Tagged: 2, OverLimit: 1}) {
+		t.Errorf("school zone = %+v", got)
+	}
+	if got := s.ByDirection["northbound"]; got != (SpeedLimitCount{Tagged: 2, OverLimit: 2}) {
+		t.Errorf("northbound = %+v", got)
+	}
+}
+
+func TestSpeedLimitConfig_Validate(t *testing.T) {
+	bad := []SpeedLimitConfig{
+		{Units: "mps"},
+		{Metric: "p85"},
+		{DefaultLimit: -1},
+		{SensorLimits: map[string]float64{"a": 0}},
+		{Zones: []SpeedLimitZone{{MinX: 0, MaxX: 1, MaxY: 1, Limit: 30}}},
+		{Zones: []SpeedLimitZone{{ID: "z", MinX: 1, MaxX: 0, MaxY: 1, Limit: 30}}},
+		{Zones: []SpeedLimitZone{{ID: "z", MaxX: 1, MaxY: 1}}},
+		{Zones: []SpeedLimitZone{{ID: "z", MaxX: 1, MaxY: 1, Limit: 30}, {ID: "z", MaxX: 1, MaxY: 1, Limit: 30}}},
+	}
+	for i, cfg := range bad {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("config %d: expected validation error", i)
+		}
+	}
+}
+
+func TestLoadSpeedLimitConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	data := `{"units":"mph","default_limit":25,"zones":[{"id":"school","min_x":-5,"min_y":0,"max_x":5,"max_y":40,"limit":15}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadSpeedLimitConfig(path)
+	if err != nil {
+		t.Fatalf("LoadSpeedLimitConfig: %v", err)
+	}
+	if cfg.Units != "mph" || cfg.DefaultLimit != 25 || len(cfg.Zones) != 1 || cfg.Zones[0].Limit != 15 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	tagger := newTestSpeedLimitTagger(t, cfg)
+	tag, ok := tagger.Tag(speedLimitTestTrack("car-1", "car", 0, 32.18688)) // 20 mph
+	if !ok || math.Abs(tag.Speed-20) > 1e-3 || !tag.OverLimit || tag.String() != "20 mph in a 15 zone, northbound" {
+		t.Errorf("mph tag = %+v (%s)", tag, tag)
+	}
+}
@@ -1,20 +1,21 @@
-//go:build pcap
-// +build pcap
-
-package main
+package l6objects
 
 import (
 	"math"
 	"testing"
 )
 
+// These cases came over from pcap-analyse with ComputeClassStats and
+// ComputeSpeedStatistics. Expected values are worked by hand in the
+// comments, not taken from the implementation.
+
 func TestComputeClassStats_SingleClass(t *testing.T) {
-	tracks := []*TrackExport{
-		{Class: "vehicle", AvgSpeedMps: 10.0, DurationSecs: 5.0, Observations: 50},
-		{Class: "vehicle", AvgSpeedMps: 20.0, DurationSecs: 10.0, Observations: 100},
+	tracks := []*TrackedObject{
+		aggregateTrack("vehicle", 10.0, 0, 5e9, 50),
+		aggregateTrack("vehicle", 20.0, 0, 10e9, 100),
 	}
 
-	stats := computeClassStats(tracks)
+	stats := ComputeClassStats(tracks)
 
 	if len(stats) != 1 {
 		t.Fatalf("expected 1 class, got %d", len(stats))
@@ -40,14 +41,14 @@ func TestComputeClassStats_SingleClass(t *testing.T) {
 	}
 }
 
-func TestComputeClassStats_MultipleClasses(t *testing.T) {
-	tracks := []*TrackExport{
-		{Class: "vehicle", AvgSpeedMps: 12.0, DurationSecs: 4.0, Observations: 40},
-		{Class: "pedestrian", AvgSpeedMps: 1.5, DurationSecs: 20.0, Observations: 200},
-		{Class: "vehicle", AvgSpeedMps: 8.0, DurationSecs: 6.0, Observations: 60},
+func TestComputeClassStats_TwoClasses(t *testing.T) {
+	tracks := []*TrackedObject{
+		aggregateTrack("vehicle", 12.0, 0, 4e9, 40),
+		aggregateTrack("pedestrian", 1.5, 0, 20e9, 200),
+		aggregateTrack("vehicle", 8.0, 0, 6e9, 60),
 	}
 
-	stats := computeClassStats(tracks)
+	stats := ComputeClassStats(tracks)
 
 	if len(stats) != 2 {
 		t.Fatalf("expected 2 classes, got %d", len(stats))
@@ -72,20 +73,38 @@ func TestComputeClassStats_MultipleClasses(t *testing.T) {
 }
 
 func TestComputeClassStats_Empty(t *testing.T) {
-	stats := computeClassStats(nil)
+	stats := ComputeClassStats(nil)
 	if len(stats) != 0 {
 		t.Errorf("expected empty stats for nil input, got %d entries", len(stats))
 	}
 }
 
-func TestComputeSpeedStats_BasicValues(t *testing.T) {
+func TestComputeClassStats_UsesAvg(t *testing.T) {
+	// Verify that ClassStats.AvgSpeed uses AvgSpeedMps (the running mean).
+	tracks := []*TrackedObject{
+		aggregateTrack("vehicle", 10.0, 0, 0, 0),
+		aggregateTrack("vehicle", 20.0, 0, 0, 0),
+	}
+	tracks[0].MaxSpeedMps = 30
+	tracks[1].MaxSpeedMps = 40
+
+	stats := ComputeClassStats(tracks)
+	v := stats["vehicle"]
+
+	// Should be mean of AvgSpeedMps: (10+20)/2 = 15
+	if math.Abs(float64(v.AvgSpeed)-15.0) > 0.01 {
+		t.Errorf("expected AvgSpeed=15.0 (from AvgSpeedMps), got %f", v.AvgSpeed)
+	}
+}
+
+func TestComputeSpeedStatistics_BasicValues(t *testing.T) {
 	// 10 samples: 1..10
 	samples := make([]float32, 10)
 	for i := range samples {
 		samples[i] = float32(i + 1)
 	}
 
-	stats := computeSpeedStats(samples)
+	stats := ComputeSpeedStatistics(samples)
 
 	if stats.MinSpeed != 1.0 {
 		t.Errorf("expected MinSpeed=1.0, got %f", stats.MinSpeed)
@@ -111,15 +130,15 @@ func TestComputeSpeedStats_BasicValues(t *testing.T) {
 	}
 }
 
-func TestComputeSpeedStats_Empty(t *testing.T) {
-	stats := computeSpeedStats(nil)
+func TestComputeSpeedStatistics_Empty(t *testing.T) {
+	stats := ComputeSpeedStatistics(nil)
 	if stats.MinSpeed != 0 || stats.MaxSpeed != 0 || stats.AvgSpeed != 0 {
 		t.Errorf("expected all zeros for nil input, got %+v", stats)
 	}
 }
 
-func TestComputeSpeedStats_SingleElement(t *testing.T) {
-	stats := computeSpeedStats([]float32{7.5})
+func TestComputeSpeedStatistics_SingleElement(t *testing.T) {
+	stats := ComputeSpeedStatistics([]float32{7.5})
 	if stats.MinSpeed != 7.5 {
 		t.Errorf("expected MinSpeed=7.5, got %f", stats.MinSpeed)
 	}
@@ -134,11 +153,11 @@ func TestComputeSpeedStats_SingleElement(t *testing.T) {
 	}
 }
 
-func TestComputeSpeedStats_AvgDiffersFromP50(t *testing.T) {
+func TestComputeSpeedStatistics_AvgDiffersFromP50(t *testing.T) {
 	// Skewed distribution: avg differs from median
 	samples := []float32{1.0, 2.0, 3.0, 4.0, 100.0}
 
-	stats := computeSpeedStats(samples)
+	stats := ComputeSpeedStatistics(samples)
 
 	// Avg: (1+2+3+4+100)/5 = 22.0
 	if math.Abs(float64(stats.AvgSpeed)-22.0) > 0.01 {
@@ -149,19 +168,3 @@ func TestComputeSpeedStats_AvgDiffersFromP50(t *testing.T) {
 		t.Errorf("expected P50Speed=3.0, got %f", stats.P50Speed)
 	}
 }
-
-func TestComputeClassStats_UsesAvg(t *testing.T) {
-	// Verify that ClassStats.AvgSpeed uses AvgSpeedMps (the running mean).
-	tracks := []*TrackExport{
-		{Class: "vehicle", AvgSpeedMps: 10.0},
-		{Class: "vehicle", AvgSpeedMps: 20.0},
-	}
-
-	stats := computeClassStats(tracks)
-	v := stats["vehicle"]
-
-	// Should be mean of AvgSpeedMps: (10+20)/2 = 15
-	if math.Abs(float64(v.AvgSpeed)-15.0) > 0.01 {
-		t.Errorf("expected AvgSpeed=15.0 (from AvgSpeedMps), got %f", v.AvgSpeed)
-	}
-}
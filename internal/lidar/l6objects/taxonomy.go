@@ -0,0 +1,153 @@
+package l6objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// TaxonomyConfig is the file form of per-class settings for the object
+// taxonomy.
+type TaxonomyConfig struct {
+	// DimensionPriors maps an object class to its typical bounding box.
+	// Sparse observations of a classified track are shrunk toward it.
+	// Classes not listed report their measured dimensions.
+	DimensionPriors map[string]DimensionPriorSpec `json:"dimension_priors"`
+
+	// ClassTransitions overrides the class stabiliser's transition costs.
+	// Nil keeps DefaultClassTransitionConfig.
+	ClassTransitions *ClassTransitionSpec `json:"class_transitions"`
+}
+
+// ClassTransitionSpec is the file form of a ClassTransitionConfig. Unset
+// fields keep their defaults, and Costs are applied over the default costs.
+type ClassTransitionSpec struct {
+	DefaultCost    *float32                  `json:"default_cost"`
+	MinConsecutive *int                      `json:"min_consecutive"`
+	StaleAfterS    *float64                  `json:"stale_after_s"`
+	Costs          []ClassTransitionCostSpec `json:"costs"`
+}
+
+// ClassTransitionCostSpec sets the evidence needed to change between two
+// classes, in both directions unless OneWay is set.
+type ClassTransitionCostSpec struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Cost   float32 `json:"cost"`
+	OneWay bool    `json:"one_way"`
+}
+
+// DimensionPriorSpec is the typical box of one class, in metres. A zero
+// dimension is not regularised.
+type DimensionPriorSpec struct {
+	LengthM float32 `json:"length_m"`
+	WidthM  float32 `json:"width_m"`
+	HeightM float32 `json:"height_m"`
+	// FullConfidencePoints is the cluster size at which a measurement is
+	// trusted outright; smaller clusters are weighted by their share of it.
+	FullConfidencePoints int `json:"full_confidence_points"`
+}
+
+// knownClasses are the classes the classifier can assign.
+var knownClasses = map[ObjectClass]bool{
+	ClassCar: true, ClassTruck: true, ClassBus: true, ClassPedestrian: true,
+	ClassCyclist: true, ClassMotorcyclist: true, ClassBird: true, ClassDynamic: true,
+}
+
+// Validate checks that every prior names a known class and has at least
+// one positive dimension.
+func (c TaxonomyConfig) Validate() error {
+	for class, p := range c.DimensionPriors {
+		if !knownClasses[ObjectClass(class)] {
+			return fmt.Errorf("dimension prior: unknown class %q", class)
+		}
+		if p.LengthM < 0 || p.WidthM < 0 || p.HeightM < 0 {
+			return fmt.Errorf("dimension prior for %s: dimensions must be >= 0", class)
+		}
+		if p.LengthM == 0 && p.WidthM == 0 && p.HeightM == 0 {
+			return fmt.Errorf("dimension prior for %s: at least one dimension is required", class)
+		}
+		if p.FullConfidencePoints <= 0 {
+			return fmt.Errorf("dimension prior for %s: full_confidence_points must be > 0, got %d", class, p.FullConfidencePoints)
+		}
+	}
+	if t := c.ClassTransitions; t != nil {
+		for _, cost := range t.Costs {
+			if !knownClasses[ObjectClass(cost.From)] || !knownClasses[ObjectClass(cost.To)] {
+				return fmt.Errorf("class transition %s->%s: unknown class", cost.From, cost.To)
+			}
+		}
+		if t.StaleAfterS != nil && *t.StaleAfterS < 0 {
+			return fmt.Errorf("class transitions: stale_after_s must be >= 0, got %v", *t.StaleAfterS)
+		}
+		if err := c.ClassTransitionConfig().Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadTaxonomyConfig reads a TaxonomyConfig from a JSON file.
+func LoadTaxonomyConfig(path string) (TaxonomyConfig, error) {
+	var cfg TaxonomyConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse taxonomy config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("taxonomy config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// TrackerDimensionPriors returns the priors in the tracker's form, or nil
+// when none are configured.
+func (c TaxonomyConfig) TrackerDimensionPriors() *l5tracks.DimensionPriorConfig {
+	if len(c.DimensionPriors) == 0 {
+		return nil
+	}
+	out := &l5tracks.DimensionPriorConfig{Priors: make(map[string]l5tracks.DimensionPrior, len(c.DimensionPriors))}
+	for class, p := range c.DimensionPriors {
+		out.Priors[class] = l5tracks.DimensionPrior{
+			Length:               p.LengthM,
+			Width:                p.WidthM,
+			Height:               p.HeightM,
+			FullConfidencePoints: p.FullConfidencePoints,
+		}
+	}
+	return out
+}
+
+// ClassTransitionConfig returns the default transition costs with any
+// overrides from ClassTransitions applied.
+func (c TaxonomyConfig) ClassTransitionConfig() ClassTransitionConfig {
+	cfg := DefaultClassTransitionConfig()
+	t := c.ClassTransitions
+	if t == nil {
+		return cfg
+	}
+	if t.DefaultCost != nil {
+		cfg.DefaultCost = *t.DefaultCost
+	}
+	if t.MinConsecutive != nil {
+		cfg.MinConsecutive = *t.MinConsecutive
+	}
+	if t.StaleAfterS != nil {
+		cfg.StaleAfter = time.Duration(*t.StaleAfterS * float64(time.Second))
+	}
+	for _, cost := range t.Costs {
+		from, to := ObjectClass(cost.From), ObjectClass(cost.To)
+		if cost.OneWay {
+			cfg.Costs[ClassTransition{From: from, To: to}] = cost.Cost
+		} else {
+			cfg.SetCost(from, to, cost.Cost)
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,96 @@
+package l6objects
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadTaxonomyConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "taxonomy.json")
+	body := `{"dimension_priors":{
+		"car":{"length_m":4.5,"width_m":1.8,"height_m":1.5,"full_confidence_points":60},
+		"pedestrian":{"length_m":0.5,"width_m":0.5,"height_m":1.7,"full_confidence_points":25}}}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadTaxonomyConfig(path)
+	if err != nil {
+		t.Fatalf("LoadTaxonomyConfig: %v", err)
+	}
+	priors := cfg.TrackerDimensionPriors()
+	if priors == nil || len(priors.Priors) != 2 {
+		t.Fatalf("priors = %+v, want car and pedestrian", priors)
+	}
+	if car := priors.Priors["car"]; car.Length != 4.5 || car.Width != 1.8 || car.Height != 1.5 || car.FullConfidencePoints != 60 {
+		t.Errorf("car prior = %+v", car)
+	}
+
+	if (TaxonomyConfig{}).TrackerDimensionPriors() != nil {
+		t.Error("empty taxonomy should give no tracker priors")
+	}
+}
+
+func TestLoadTaxonomyConfig_ClassTransitions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "taxonomy.json")
+	body := `{"class_transitions":{"default_cost":2,"min_consecutive":3,"stale_after_s":30,
+		"costs":[{"from":"car","to":"pedestrian","cost":5},
+		         {"from":"dynamic","to":"bird","cost":1,"one_way":true}]}}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadTaxonomyConfig(path)
+	if err != nil {
+		t.Fatalf("LoadTaxonomyConfig: %v", err)
+	}
+	tc := cfg.ClassTransitionConfig()
+	if tc.DefaultCost != 2 || tc.MinConsecutive != 3 || tc.StaleAfter != 30*time.Second {
+		t.Errorf("transition config = %+v", tc)
+	}
+	if tc.Cost(ClassCar, ClassPedestrian) != 5 || tc.Cost(ClassPedestrian, ClassCar) != 5 {
+		t.Errorf("car<->pedestrian cost not overridden both ways")
+	}
+	if tc.Cost(ClassDynamic, ClassBird) != 1 || tc.Cost(ClassBird, ClassDynamic) != 2.0 {
+		t.Errorf("one-way cost applied in reverse")
+	}
+	if tc.Cost(ClassCyclist, ClassPedestrian) != 0.6 {
+		t.Errorf("unlisted default cost changed")
+	}
+
+	if got := (TaxonomyConfig{}).ClassTransitionConfig(); got.DefaultCost != DefaultClassTransitionConfig().DefaultCost {
+		t.Error("no class_transitions should give the default costs")
+	}
+}
+
+func TestTaxonomyConfig_Validate(t *testing.T) {
+	cases := map[string]DimensionPriorSpec{
+		"negative":      {LengthM: -1, FullConfidencePoints: 10},
+		"no dimensions": {FullConfidencePoints: 10},
+		"no points":     {LengthM: 4.5},
+	}
+	for name, spec := range cases {
+		cfg := TaxonomyConfig{DimensionPriors: map[string]DimensionPriorSpec{"car": spec}}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+	unknown := TaxonomyConfig{DimensionPriors: map[string]DimensionPriorSpec{
+		"tractor": {LengthM: 4, FullConfidencePoints: 10},
+	}}
+	if err := unknown.Validate(); err == nil {
+		t.Error("unknown class: expected a validation error")
+	}
+
+	negative := float32(-1)
+	transitions := map[string]*ClassTransitionSpec{
+		"unknown class":    {Costs: []ClassTransitionCostSpec{{From: "car", To: "tractor", Cost: 1}}},
+		"negative cost":    {Costs: []ClassTransitionCostSpec{{From: "car", To: "bus", Cost: -1}}},
+		"negative default": {DefaultCost: &negative},
+	}
+	for name, spec := range transitions {
+		if err := (TaxonomyConfig{ClassTransitions: spec}).Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package l6objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultTripwireHysteresisMetres is the band either side of a count line
+// inside which a track's side is left unchanged, so position jitter along
+// a line that a track grazes never registers as a crossing.
+const DefaultTripwireHysteresisMetres = 0.5
+
+// tripwireStateTTL drops per-track crossing state for tracks not observed
+// for this long on track time.
+const tripwireStateTTL = time.Minute
+
+// tripwireHistoryLimit caps the crossings kept for headway analysis; the
+// oldest are dropped first.
+const tripwireHistoryLimit = 10000
+
+// Tripwire is a virtual count line: a world-frame segment from (X1, Y1) to
+// (X2, Y2), in metres. Looking from the first point to the second, the
+// left side is where the cross product is positive.
+type Tripwire struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name,omitempty"`
+	X1   float64 `json:"x1"`
+	Y1   float64 `json:"y1"`
+	X2   float64 `json:"x2"`
+	Y2   float64 `json:"y2"`
+}
+
+// signedDistance returns the distance of (x, y) from the line through the
+// tripwire, positive on the left, and the position of its projection
+// along the segment (0 at the first point, 1 at the second).
+func (w Tripwire) signedDistance(x, y float64) (dist, along float64) {
+	dx, dy := w.X2-w.X1, w.Y2-w.Y1
+	length := math.Hypot(dx, dy)
+	px, py := x-w.X1, y-w.Y1
+	return (dx*py - dy*px) / length, (dx*px + dy*py) / (length * length)
+}
+
+// CrossingDirection is the side a track moved from and to, looking along
+// the tripwire from its first point to its second.
+type CrossingDirection string
+
+const (
+	CrossingLeftToRight CrossingDirection = "left_to_right"
+	CrossingRightToLeft CrossingDirection = "right_to_left"
+)
+
+// LineCrossing records one track crossing a tripwire. The timestamp and
+// position are interpolated to the line.
+type LineCrossing struct {
+	LineID         string            `json:"line_id"`
+	TrackID        string            `json:"track_id"`
+	SensorID       string            `json:"sensor_id,omitempty"`
+	Class          string            `json:"class,omitempty"`
+	Direction      CrossingDirection `json:"direction"`
+	TimestampNanos int64             `json:"timestamp_unix_nanos"`
+	SpeedMps       float32           `json:"speed_mps"`
+	X              float32           `json:"x"`
+	Y              float32           `json:"y"`
+}
+
+// TripwireCount is the running crossing count for one line.
+type TripwireCount struct {
+	LineID      string         `json:"line_id"`
+	Name        string         `json:"name,omitempty"`
+	Total       int            `json:"total"`
+	LeftToRight int            `json:"left_to_right"`
+	RightToLeft int            `json:"right_to_left"`
+	ByClass     map[string]int `json:"by_class"`
+}
+
+// TripwireConfig is the file and API form of a set of count lines.
+type TripwireConfig struct {
+	// HysteresisMetres is the no-change band either side of each line.
+	// Zero means DefaultTripwireHysteresisMetres.
+	HysteresisMetres float64    `json:"hysteresis_m,omitempty"`
+	Lines            []Tripwire `json:"lines"`
+}
+
+// Validate checks line IDs are present and unique and every line has
+// length.
+func (c TripwireConfig) Validate() error {
+	if c.HysteresisMetres < 0 {
+		return fmt.Errorf("tripwire hysteresis must be >= 0, got %v", c.HysteresisMetres)
+	}
+	seen := make(map[string]bool, len(c.Lines))
+	for i, w := range c.Lines {
+		if w.ID == "" {
+			return fmt.Errorf("tripwire %d: id is required", i)
+		}
+		if seen[w.ID] {
+			return fmt.Errorf("tripwire %q: duplicate id", w.ID)
+		}
+		seen[w.ID] = true
+		if math.Hypot(w.X2-w.X1, w.Y2-w.Y1) < 1e-6 {
+			return fmt.Errorf("tripwire %q: start and end points coincide", w.ID)
+		}
+	}
+	return nil
+}
+
+// LoadTripwireConfig reads a TripwireConfig from a JSON file.
+func LoadTripwireConfig(path string) (TripwireConfig, error) {
+	var cfg TripwireConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse tripwires %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("tripwires %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// tripwireSide is a track's committed side of one line.
+type tripwireSide struct {
+	side    int     // +1 left, -1 right
+	x, y    float64 // last position committed to side
+	nanos   int64
+	counted bool
+}
+
+type tripwireTrack struct {
+	lastNanos int64
+	lines     map[string]*tripwireSide
+}
+
+// TripwireCounter detects tracks crossing count lines and keeps a running
+// count per line. Each track is counted at most once per line: grazing
+// within the hysteresis band never changes its side, and recrossing a
+// line it has already been counted on is ignored. It is safe for
+// concurrent use.
+type TripwireCounter struct {
+	mu         sync.Mutex
+	hysteresis float64
+	lines      []Tripwire
+	counts     map[string]*TripwireCount
+	tracks     map[string]*tripwireTrack
+	history    []LineCrossing // recent crossings, oldest first
+}
+
+// NewTripwireCounter creates a counter for the configured lines.
+func NewTripwireCounter(cfg TripwireConfig) (*TripwireCounter, error) {
+	c := &TripwireCounter{
+		counts: make(map[string]*TripwireCount),
+		tracks: make(map[string]*tripwireTrack),
+	}
+	if err := c.SetConfig(cfg); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetConfig replaces the count lines. Counts carry over for lines whose ID
+// and geometry are unchanged; other lines start from zero.
+func (c *TripwireCounter) SetConfig(cfg TripwireConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	kept := make(map[string]bool)
+	for _, w := range cfg.Lines {
+		for _, old := range c.lines {
+			if old == w {
+				kept[w.ID] = true
+			}
+		}
+	}
+	counts := make(map[string]*TripwireCount, len(cfg.Lines))
+	for _, w := range cfg.Lines {
+		if kept[w.ID] {
+			counts[w.ID] = c.counts[w.ID]
+			continue
+		}
+		counts[w.ID] = &TripwireCount{LineID: w.ID, Name: w.Name, ByClass: make(map[string]int)}
+	}
+	for _, tr := range c.tracks {
+		for id := range tr.lines {
+			if !kept[id] {
+				delete(tr.lines, id)
+			}
+		}
+	}
+
+	c.hysteresis = cfg.HysteresisMetres
+	if c.hysteresis == 0 {
+		c.hysteresis = DefaultTripwireHysteresisMetres
+	}
+	history := c.history[:0]
+	for _, crossing := range c.history {
+		if kept[crossing.LineID] {
+			history = append(history, crossing)
+		}
+	}
+	c.history = history
+
+	c.lines = append([]Tripwire(nil), cfg.Lines...)
+	c.counts = counts
+	return nil
+}
+
+// Config returns the current count lines.
+func (c *TripwireCounter) Config() TripwireConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return TripwireConfig{HysteresisMetres: c.hysteresis, Lines: append([]Tripwire(nil), c.lines...)}
+}
+
+// Counts returns a copy of the running counts, in line order.
+func (c *TripwireCounter) Counts() []TripwireCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]TripwireCount, 0, len(c.lines))
+	for _, w := range c.lines {
+		n := *c.counts[w.ID]
+		n.ByClass = make(map[string]int, len(c.counts[w.ID].ByClass))
+		for class, v := range c.counts[w.ID].ByClass {
+			n.ByClass[class] = v
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// Crossings returns a copy of the recent crossings on all lines, oldest
+// first. Crossings are kept for lines left unchanged by SetConfig, up to
+// the most recent 10000 or so.
+func (c *TripwireCounter) Crossings() []LineCrossing {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]LineCrossing(nil), c.history...)
+}
+
+// Observe checks each track's movement since it was last observed against
+// every line and returns the new crossings. Tracks are read at their
+// latest update time (EndUnixNanos).
+func (c *TripwireCounter) Observe(tracks []*TrackedObject) []LineCrossing {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var crossings []LineCrossing
+	var latest int64
+	for _, track := range tracks {
+		nowNanos := track.EndUnixNanos
+		if nowNanos > latest {
+			latest = nowNanos
+		}
+		tr := c.tracks[track.TrackID]
+		if tr == nil {
+			tr = &tripwireTrack{lines: make(map[string]*tripwireSide)}
+			c.tracks[track.TrackID] = tr
+		}
+		tr.lastNanos = nowNanos
+
+		x, y := float64(track.X), float64(track.Y)
+		for _, w := range c.lines {
+			dist, _ := w.signedDistance(x, y)
+			if math.Abs(dist) < c.hysteresis {
+				continue
+			}
+			side := 1
+			if dist < 0 {
+				side = -1
+			}
+			st := tr.lines[w.ID]
+			if st == nil {
+				tr.lines[w.ID] = &tripwireSide{side: side, x: x, y: y, nanos: nowNanos}
+				continue
+			}
+			if side != st.side {
+				if crossing, ok := c.crossingLocked(w, st, track, x, y, dist); ok {
+					crossings = append(crossings, crossing)
+				}
+			}
+			st.side, st.x, st.y, st.nanos = side, x, y, nowNanos
+		}
+	}
+
+	c.pruneLocked(latest)
+	return crossings
+}
+
+// crossingLocked interpolates where the move from the committed position
+// st to (x, y) meets the line and counts it when that point lies on the
+// segment and the track has not been counted on this line.
+func (c *TripwireCounter) crossingLocked(w Tripwire, st *tripwireSide, track *TrackedObject, x, y, dist float64) (LineCrossing, bool) {
+	if st.counted {
+		tracef("Tripwire recross ignored: line=%s track_id=%s", w.ID, track.TrackID)
+		return LineCrossing{}, false
+	}
+	fromDist, _ := w.signedDistance(st.x, st.y)
+	frac := fromDist / (fromDist - dist)
+	cx, cy := st.x+frac*(x-st.x), st.y+frac*(y-st.y)
+	if _, along := w.signedDistance(cx, cy); along < 0 || along > 1 {
+		return LineCrossing{}, false
+	}
+	st.counted = true
+
+	direction := CrossingLeftToRight
+	if st.side < 0 {
+		direction = CrossingRightToLeft
+	}
+	crossing := LineCrossing{
+		LineID:         w.ID,
+		TrackID:        track.TrackID,
+		SensorID:       track.SensorID,
+		Class:          track.ObjectClass,
+		Direction:      direction,
+		TimestampNanos: st.nanos + int64(frac*float64(track.EndUnixNanos-st.nanos)),
+		SpeedMps:       float32(math.Hypot(float64(track.VX), float64(track.VY))),
+		X:              float32(cx),
+		Y:              float32(cy),
+	}
+
+	n := c.counts[w.ID]
+	n.Total++
+	if direction == CrossingLeftToRight {
+		n.LeftToRight++
+	} else {
+		n.RightToLeft++
+	}
+	n.ByClass[crossing.Class]++
+	if len(c.history) >= tripwireHistoryLimit {
+		// Drop the oldest tenth at once so trimming is not paid per crossing.
+		c.history = append(c.history[:0], c.history[tripwireHistoryLimit/10:]...)
+	}
+	c.history = append(c.history, crossing)
+	diagf("Tripwire crossing: line=%s track_id=%s class=%s direction=%s speed=%.1f total=%d",
+		w.ID, track.TrackID, crossing.Class, direction, crossing.SpeedMps, n.Total)
+	return crossing, true
+}
+
+// Forget drops the crossing state for a track, e.g. once it has been
+// finalised.
+func (c *TripwireCounter) Forget(trackID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tracks, trackID)
+}
+
+// pruneLocked drops tracks not observed within tripwireStateTTL of nowNanos.
+func (c *TripwireCounter) pruneLocked(nowNanos int64) {
+	cutoff := nowNanos - tripwireStateTTL.Nanoseconds()
+	for id, tr := range c.tracks {
+		if tr.lastNanos < cutoff {
+			delete(c.tracks, id)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package l6objects
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// northLine runs along +Y at x = 0, so a track moving in +X crosses it
+// from left to right.
+var northLine = Tripwire{ID: "north", Name: "North lane", X1: 0, Y1: -5, X2: 0, Y2: 5}
+
+func crossingTestTrack(id, class string) *TrackedObject {
+	return &TrackedObject{TrackID: id, TrackMeasurement: l5tracks.TrackMeasurement{ObjectClass: class}}
+}
+
+// moveTo places the track at (x, y) at t seconds, moving at vx.
+func moveTo(track *TrackedObject, x, y, vx float32, t float64) *TrackedObject {
+	track.X, track.Y, track.VX = x, y, vx
+	track.EndUnixNanos = int64(t * float64(time.Second))
+	return track
+}
+
+func newTestTripwireCounter(t *testing.T, lines ...Tripwire) *TripwireCounter {
+	t.Helper()
+	c, err := NewTripwireCounter(TripwireConfig{Lines: lines})
+	if err != nil {
+		t.Fatalf("NewTripwireCounter: %v", err)
+	}
+	return c
+}
+
+func TestTripwireCounter_CountsCrossingWithDirection(t *testing.T) {
+	c := newTestTripwireCounter(t, northLine)
+	car := crossingTestTrack("car-1", "car")
+	ped := crossingTestTrack("ped-1", "pedestrian")
+
+	var crossings []LineCrossing
+	for i := 0; i <= 10; i++ {
+		ts := float64(i) * 0.1
+		crossings = append(crossings, c.Observe([]*TrackedObject{
+			moveTo(car, -5+float32(i), 1, 10, ts),
+			moveTo(ped, 3-0.6*float32(i), -2, -6, ts),
+		})...)
+	}
+
+	if len(crossings) != 2 {
+		t.Fatalf("got %d crossings, want 2: %+v", len(crossings), crossings)
+	}
+	byTrack := map[string]LineCrossing{}
+	for _, cr := range crossings {
+		byTrack[cr.TrackID] = cr
+	}
+	carCrossing := byTrack["car-1"]
+	if carCrossing.Direction != CrossingLeftToRight || carCrossing.Class != "car" || carCrossing.SpeedMps != 10 || carCrossing.LineID != "north" {
+		t.Errorf("unexpected car crossing %+v", carCrossing)
+	}
+	// The car reaches x = 0 at 0.5 s.
+	if d := time.Duration(carCrossing.TimestampNanos) - 500*time.Millisecond; d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("crossing time = %v, want 500ms", time.Duration(carCrossing.TimestampNanos))
+	}
+	if carCrossing.X > 1e-3 || carCrossing.X < -1e-3 || carCrossing.Y != 1 {
+		t.Errorf("crossing point = (%v, %v), want (0, 1)", carCrossing.X, carCrossing.Y)
+	}
+	if byTrack["ped-1"].Direction != CrossingRightToLeft {
+		t.Errorf("pedestrian direction = %s", byTrack["ped-1"].Direction)
+	}
+
+	counts := c.Counts()
+	if len(counts) != 1 || counts[0].Total != 2 || counts[0].LeftToRight != 1 || counts[0].RightToLeft != 1 ||
+		counts[0].ByClass["car"] != 1 || counts[0].ByClass["pedestrian"] != 1 || counts[0].Name != "North lane" {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestTripwireCounter_GrazeAndRecrossCountOnce(t *testing.T) {
+	c := newTestTripwireCounter(t, northLine)
+	track := crossingTestTrack("t1", "cyclist")
+
+	// Jitter within the hysteresis band never counts.
+	xs := []float32{-2, -0.3, 0.3, -0.2, 0.4, -0.4}
+	// Then a real crossing, a U-turn back across and a second pass.
+	xs = append(xs, 2, -2, 2)
+	total := 0
+	for i, x := range xs {
+		total += len(c.Observe([]*TrackedObject{moveTo(track, x, 0, 5, float64(i))}))
+	}
+	if total != 1 {
+		t.Errorf("got %d crossings, want the track counted once", total)
+	}
+}
+
+func TestTripwireCounter_IgnoresPassesBeyondSegment(t *testing.T) {
+	c := newTestTripwireCounter(t, northLine)
+	track := crossingTestTrack("t1", "car")
+	c.Observe([]*TrackedObject{moveTo(track, -3, 8, 10, 0)})
+	if got := c.Observe([]*TrackedObject{moveTo(track, 3, 8, 10, 0.6)}); len(got) != 0 {
+		t.Errorf("passing beyond the end of the line counted: %+v", got)
+	}
+}
+
+func TestTripwireCounter_SetConfigKeepsUnchangedCounts(t *testing.T) {
+	south := Tripwire{ID: "south", X1: 10, Y1: -5, X2: 10, Y2: 5}
+	c := newTestTripwireCounter(t, northLine, south)
+	track := crossingTestTrack("t1", "car")
+	for i, x := range []float32{-2, 2, 8, 12} {
+		c.Observe([]*TrackedObject{moveTo(track, x, 0, 10, float64(i))})
+	}
+
+	moved := south
+	moved.X1, moved.X2 = 20, 20
+	if err := c.SetConfig(TripwireConfig{Lines: []Tripwire{northLine, moved}}); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+	counts := c.Counts()
+	if counts[0].Total != 1 || counts[1].Total != 0 {
+		t.Errorf("counts after reconfigure = %+v; want north kept and moved south reset", counts)
+	}
+	if err := c.SetConfig(TripwireConfig{Lines: []Tripwire{northLine, northLine}}); err == nil {
+		t.Error("expected an error for duplicate line IDs")
+	}
+}
+
+func TestLoadTripwireConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tripwires.json")
+	if err := os.WriteFile(path, []byte(`{"hysteresis_m": 0.8, "lines": [{"id": "a", "x1": 0, "y1": 0, "x2": 1, "y2": 0}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadTripwireConfig(path)
+	if err != nil || cfg.HysteresisMetres != 0.8 || len(cfg.Lines) != 1 || cfg.Lines[0].X2 != 1 {
+		t.Errorf("LoadTripwireConfig = %+v, %v", cfg, err)
+	}
+
+	for name, body := range map[string]string{
+		"no id":     `{"lines": [{"x1": 0, "y1": 0, "x2": 1, "y2": 0}]}`,
+		"no length": `{"lines": [{"id": "a", "x1": 1, "y1": 1, "x2": 1, "y2": 1}]}`,
+		"bad json":  `{"lines": [`,
+	} {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTripwireConfig(path); err == nil || !strings.Contains(err.Error(), "tripwire") {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+}
+
+func TestTripwireCounter_KeepsCrossingsForUnchangedLines(t *testing.T) {
+	south := Tripwire{ID: "south", X1: 2, Y1: -5, X2: 2, Y2: 5}
+	c := newTestTripwireCounter(t, northLine, south)
+	car := crossingTestTrack("car-1", "car")
+	for i := 0; i <= 10; i++ {
+		c.Observe([]*TrackedObject{moveTo(car, -5+float32(i), 1, 10, float64(i)*0.1)})
+	}
+	if got := c.Crossings(); len(got) != 2 || got[0].LineID != "north" || got[1].LineID != "south" {
+		t.Fatalf("crossings = %+v, want north then south", got)
+	}
+
+	moved := south
+	moved.X1, moved.X2 = 3, 3
+	if err := c.SetConfig(TripwireConfig{Lines: []Tripwire{northLine, moved}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Crossings(); len(got) != 1 || got[0].LineID != "north" || got[0].TrackID != "car-1" {
+		t.Errorf("after moving south, crossings = %+v, want only north's", got)
+	}
+}
@@ -236,6 +236,7 @@ func (a *FrameAdapter) adaptUnassociatedClusters(worldClusters []l4perception.Wo
 
 		cluster := Cluster{
 			ClusterID:      wc.ClusterID,
+			StableID:       wc.StableID,
 			SensorID:       wc.SensorID,
 			TimestampNanos: wc.TSUnixNanos,
 			CentroidX:      wc.CentroidX,
@@ -280,6 +281,7 @@ func (a *FrameAdapter) adaptClusters(worldClusters []l4perception.WorldCluster,
 	for i, wc := range worldClusters {
 		cs.Clusters[i] = Cluster{
 			ClusterID:      wc.ClusterID,
+			StableID:       wc.StableID,
 			SensorID:       wc.SensorID,
 			TimestampNanos: wc.TSUnixNanos,
 			CentroidX:      wc.CentroidX,
@@ -309,6 +311,7 @@ func (a *FrameAdapter) adaptTracks(tracker l5tracks.TrackerInterface, timestamp
 	}
 
 	for _, t := range activeTracks {
+		length, width, height := t.ReportedDimensions()
 		track := Track{
 			TrackID:           t.TrackID,
 			SensorID:          t.SensorID,
@@ -326,9 +329,11 @@ func (a *FrameAdapter) adaptTracks(tracker l5tracks.TrackerInterface, timestamp
 			VZ:                0,
 			SpeedMps:          t.Speed(),
 			HeadingRad:        t.Heading(),
-			BBoxLength:        t.OBBLength,
-			BBoxWidth:         t.OBBWidth,
-			BBoxHeight:        t.OBBHeight,
+			CompositeSpeedMps: t.CompositeSpeedMps,
+			InstantSpeedMps:   t.InstantSpeedMps,
+			BBoxLength:        length,
+			BBoxWidth:         width,
+			BBoxHeight:        height,
 			BBoxHeadingRad:    t.OBBHeadingRad, // Smoothed OBB heading
 			HeightP95Max:      t.HeightP95Max,
 			IntensityMeanAvg:  t.IntensityMeanAvg,
@@ -341,11 +346,13 @@ func (a *FrameAdapter) adaptTracks(tracker l5tracks.TrackerInterface, timestamp
 			OcclusionCount:    t.OcclusionCount,
 			Alpha:             1.0, // Fully visible
 			HeadingSource:     int(t.HeadingSource),
+			GhostOf:           t.GhostOf,
 		}
 
 		// Copy covariance
 		if t.P != [16]float32{} {
 			track.Covariance4x4 = t.P[:]
+			track.SpeedStdDevMps = t.SpeedStdDev()
 		}
 
 		ts.Tracks = append(ts.Tracks, track)
@@ -389,6 +396,7 @@ func (a *FrameAdapter) adaptTracks(tracker l5tracks.TrackerInterface, timestamp
 			alpha = 0
 		}
 
+		length, width, height := t.ReportedDimensions()
 		track := Track{
 			TrackID:           t.TrackID,
 			SensorID:          t.SensorID,
@@ -406,9 +414,11 @@ func (a *FrameAdapter) adaptTracks(tracker l5tracks.TrackerInterface, timestamp
 			VZ:                0,
 			SpeedMps:          t.Speed(),
 			HeadingRad:        t.Heading(),
-			BBoxLength:        t.OBBLength,
-			BBoxWidth:         t.OBBWidth,
-			BBoxHeight:        t.OBBHeight,
+			CompositeSpeedMps: t.CompositeSpeedMps,
+			InstantSpeedMps:   t.InstantSpeedMps,
+			BBoxLength:        length,
+			BBoxWidth:         width,
+			BBoxHeight:        height,
 			BBoxHeadingRad:    t.OBBHeadingRad,
 			HeightP95Max:      t.HeightP95Max,
 			IntensityMeanAvg:  t.IntensityMeanAvg,
@@ -1503,6 +1503,9 @@ func TestFrameAdapter_AdaptTracks_WithCovariance(t *testing.T) {
 	if track.Covariance4x4 == nil {
 		t.Error("expected non-nil Covariance4x4")
 	}
+	if track.SpeedStdDevMps <= 0 {
+		t.Errorf("expected positive SpeedStdDevMps, got %v", track.SpeedStdDevMps)
+	}
 }
 
 func TestFrameAdapter_AdaptTracks_DeletedTracksFade(t *testing.T) {
@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 )
 
 // BenchmarkAdaptFrame_70kPoints benchmarks frame adaptation with 70,000 points.
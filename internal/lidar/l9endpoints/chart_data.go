@@ -17,6 +17,17 @@ type StatsSnapshot struct {
 	DroppedCount  int64
 	Timestamp     time.Time
 	ParseEnabled  bool
+
+	// Packet timestamp jitter correction totals since it was enabled.
+	TimestampsClamped   int64
+	TimestampLargeJumps int64
+
+	// Capture latency over the frames completed in the interval whose
+	// points carry a packet capture time; zero when none did.
+	CaptureFrames      int64
+	CaptureLatencyMin  time.Duration
+	CaptureLatencyMean time.Duration
+	CaptureLatencyMax  time.Duration
 }
 
 // ScatterPoint represents a single point in an XY scatter chart.
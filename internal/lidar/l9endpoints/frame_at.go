@@ -0,0 +1,246 @@
+package l9endpoints
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultFrameHistory is the number of published frames the publisher
+// keeps for FrameAt lookups: 30 s at the sensor's 10 Hz.
+const DefaultFrameHistory = 300
+
+// ErrNoFrames is returned by FrameAt before any frame has been published or
+// loaded.
+var ErrNoFrames = errors.New("no frames available: start a PCAP replay or load a VRLOG")
+
+// TimestampRangeError is returned when a requested timestamp lies outside
+// the frames available to FrameAt.
+type TimestampRangeError struct {
+	RequestedNs int64
+	StartNs     int64
+	EndNs       int64
+}
+
+func (e *TimestampRangeError) Error() string {
+	return fmt.Sprintf("timestamp %d is outside the available frames %d to %d", e.RequestedNs, e.StartNs, e.EndNs)
+}
+
+// TimestampFrameReader is implemented by frame readers that can return the
+// frame nearest a timestamp without moving the playback position.
+type TimestampFrameReader interface {
+	FrameAtTimestamp(timestampNs int64) (*FrameBundle, error)
+}
+
+// SnapshotPoint is one foreground point of a FrameSnapshot.
+type SnapshotPoint struct {
+	X         float32 `json:"x"`
+	Y         float32 `json:"y"`
+	Z         float32 `json:"z"`
+	Intensity uint8   `json:"intensity"`
+}
+
+// SnapshotTrack is the state of one track in a FrameSnapshot.
+type SnapshotTrack struct {
+	TrackID         string  `json:"track_id"`
+	State           string  `json:"state"`
+	X               float32 `json:"x"`
+	Y               float32 `json:"y"`
+	Z               float32 `json:"z"`
+	VX              float32 `json:"vx"`
+	VY              float32 `json:"vy"`
+	SpeedMps        float32 `json:"speed_mps"`
+	HeadingRad      float32 `json:"heading_rad"`
+	BBoxLength      float32 `json:"bbox_length"`
+	BBoxWidth       float32 `json:"bbox_width"`
+	BBoxHeight      float32 `json:"bbox_height"`
+	BBoxHeadingRad  float32 `json:"bbox_heading_rad"`
+	ObjectClass     string  `json:"object_class,omitempty"`
+	ClassConfidence float32 `json:"class_confidence,omitempty"`
+}
+
+// FrameSnapshot is one frame's foreground points and the track states
+// published with it, as returned by FrameAt. Points and tracks share the
+// frame's timestamp, so boxes can be drawn over the points directly.
+type FrameSnapshot struct {
+	Source         string          `json:"source"` // "vrlog" or "replay"
+	FrameID        uint64          `json:"frame_id"`
+	SensorID       string          `json:"sensor_id"`
+	TimestampNanos int64           `json:"timestamp_ns"`
+	RequestedNanos int64           `json:"requested_ns"`
+	OffsetNanos    int64           `json:"offset_ns"` // TimestampNanos - RequestedNanos
+	Points         []SnapshotPoint `json:"points"`
+	Tracks         []SnapshotTrack `json:"tracks"`
+}
+
+// NewFrameSnapshot copies the foreground points and tracks out of frame.
+// Points without a foreground classification are skipped, so full frames
+// and foreground-only frames give the same result.
+func NewFrameSnapshot(frame *FrameBundle, source string) *FrameSnapshot {
+	s := &FrameSnapshot{
+		Source:         source,
+		FrameID:        frame.FrameID,
+		SensorID:       frame.SensorID,
+		TimestampNanos: frame.TimestampNanos,
+		Points:         []SnapshotPoint{},
+		Tracks:         []SnapshotTrack{},
+	}
+	if pc := frame.PointCloud; pc != nil {
+		n := min(len(pc.X), len(pc.Y), len(pc.Z))
+		for i := 0; i < n; i++ {
+			if i < len(pc.Classification) && pc.Classification[i] != 1 {
+				continue
+			}
+			p := SnapshotPoint{X: pc.X[i], Y: pc.Y[i], Z: pc.Z[i]}
+			if i < len(pc.Intensity) {
+				p.Intensity = pc.Intensity[i]
+			}
+			s.Points = append(s.Points, p)
+		}
+	}
+	if frame.Tracks != nil {
+		for _, t := range frame.Tracks.Tracks {
+			s.Tracks = append(s.Tracks, SnapshotTrack{
+				TrackID:         t.TrackID,
+				State:           trackStateName(t.State),
+				X:               t.X,
+				Y:               t.Y,
+				Z:               t.Z,
+				VX:              t.VX,
+				VY:              t.VY,
+				SpeedMps:        t.SpeedMps,
+				HeadingRad:      t.HeadingRad,
+				BBoxLength:      t.BBoxLength,
+				BBoxWidth:       t.BBoxWidth,
+				BBoxHeight:      t.BBoxHeight,
+				BBoxHeadingRad:  t.BBoxHeadingRad,
+				ObjectClass:     t.ObjectClass,
+				ClassConfidence: t.ClassConfidence,
+			})
+		}
+	}
+	return s
+}
+
+func trackStateName(s TrackState) string {
+	switch s {
+	case TrackStateTentative:
+		return "tentative"
+	case TrackStateConfirmed:
+		return "confirmed"
+	case TrackStateDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// frameHistory is a ring of the most recently published frame snapshots,
+// in publication order.
+type frameHistory struct {
+	mu     sync.Mutex
+	frames []*FrameSnapshot
+	next   int
+	full   bool
+}
+
+func newFrameHistory(size int) *frameHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &frameHistory{frames: make([]*FrameSnapshot, size)}
+}
+
+func (h *frameHistory) add(s *FrameSnapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.frames[h.next] = s
+	h.next = (h.next + 1) % len(h.frames)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// reset drops every frame, so a new replay does not answer with frames
+// from the previous one.
+func (h *frameHistory) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clear(h.frames)
+	h.next, h.full = 0, false
+}
+
+// nearest returns the frame closest in time to timestampNs. Timestamps
+// before the oldest or after the newest frame are out of range.
+func (h *frameHistory) nearest(timestampNs int64) (*FrameSnapshot, error) {
+	h.mu.Lock()
+	ordered := make([]*FrameSnapshot, 0, len(h.frames))
+	if h.full {
+		ordered = append(ordered, h.frames[h.next:]...)
+	}
+	ordered = append(ordered, h.frames[:h.next]...)
+	h.mu.Unlock()
+
+	if len(ordered) == 0 {
+		return nil, ErrNoFrames
+	}
+	// Replays can restart, so sort by timestamp rather than trusting
+	// publication order.
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].TimestampNanos < ordered[j].TimestampNanos })
+	first, last := ordered[0].TimestampNanos, ordered[len(ordered)-1].TimestampNanos
+	if timestampNs < first || timestampNs > last {
+		return nil, &TimestampRangeError{RequestedNs: timestampNs, StartNs: first, EndNs: last}
+	}
+	i := sort.Search(len(ordered), func(i int) bool { return ordered[i].TimestampNanos >= timestampNs })
+	if i > 0 && timestampNs-ordered[i-1].TimestampNanos <= ordered[i].TimestampNanos-timestampNs {
+		i--
+	}
+	return ordered[i], nil
+}
+
+// FrameAt returns the foreground points and track states of the frame
+// nearest timestampNs. While a VRLOG is loaded the frame is read from the
+// log, anywhere in its range, without disturbing playback. Otherwise it
+// comes from the last Config.FrameHistory frames published, which covers
+// PCAP replay and live data. Timestamps outside the available frames
+// return a *TimestampRangeError.
+func (p *Publisher) FrameAt(timestampNs int64) (*FrameSnapshot, error) {
+	p.vrlogMu.RLock()
+	reader := p.vrlogReader
+	active := p.vrlogActive
+	p.vrlogMu.RUnlock()
+
+	if active {
+		tr, ok := reader.(TimestampFrameReader)
+		if !ok {
+			return nil, fmt.Errorf("the loaded VRLOG cannot be read by timestamp")
+		}
+		frame, err := tr.FrameAtTimestamp(timestampNs)
+		if err != nil {
+			return nil, err
+		}
+		s := NewFrameSnapshot(frame, "vrlog")
+		s.RequestedNanos, s.OffsetNanos = timestampNs, s.TimestampNanos-timestampNs
+		return s, nil
+	}
+
+	if p.history == nil {
+		return nil, fmt.Errorf("frame history is disabled")
+	}
+	stored, err := p.history.nearest(timestampNs)
+	if err != nil {
+		return nil, err
+	}
+	s := *stored
+	s.RequestedNanos, s.OffsetNanos = timestampNs, s.TimestampNanos-timestampNs
+	return &s, nil
+}
+
+// ResetFrameHistory forgets the frames kept for FrameAt. Call it when a new
+// replay starts.
+func (p *Publisher) ResetFrameHistory() {
+	if p.history != nil {
+		p.history.reset()
+	}
+}
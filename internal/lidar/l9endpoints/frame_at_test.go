@@ -0,0 +1,116 @@
+package l9endpoints
+
+import (
+	"errors"
+	"testing"
+)
+
+// frameAt builds a published frame at ts with one foreground and one
+// background point and a confirmed track at trackX.
+func frameAt(id uint64, ts int64, trackX float32) *FrameBundle {
+	return &FrameBundle{
+		FrameID:        id,
+		TimestampNanos: ts,
+		SensorID:       "hesai-01",
+		PointCloud: &PointCloudFrame{
+			X:              []float32{trackX, 50},
+			Y:              []float32{1, 50},
+			Z:              []float32{0.5, 0},
+			Intensity:      []uint8{80, 10},
+			Classification: []uint8{1, 0},
+			PointCount:     2,
+		},
+		Tracks: &TrackSet{Tracks: []Track{{
+			TrackID: "trk_1", State: TrackStateConfirmed, X: trackX, Y: 1,
+			BBoxLength: 4.5, BBoxWidth: 1.8, ObjectClass: "car",
+		}}},
+	}
+}
+
+func TestPublisher_FrameAtReplayHistory(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FrameHistory = 3
+	p := NewPublisher(cfg)
+	p.running.Store(true)
+
+	if _, err := p.FrameAt(0); !errors.Is(err, ErrNoFrames) {
+		t.Fatalf("empty history: err = %v, want ErrNoFrames", err)
+	}
+
+	// Five frames 100 ms apart; only the last three are kept.
+	for i := 0; i < 5; i++ {
+		p.Publish(frameAt(uint64(i), int64(i)*100e6, float32(i)))
+	}
+
+	s, err := p.FrameAt(260e6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.FrameID != 3 || s.Source != "replay" || s.RequestedNanos != 260e6 || s.OffsetNanos != 40e6 {
+		t.Errorf("snapshot = frame %d from %q, requested %d, offset %d; want frame 3 from replay, offset 40 ms",
+			s.FrameID, s.Source, s.RequestedNanos, s.OffsetNanos)
+	}
+	if len(s.Points) != 1 || s.Points[0].X != 3 {
+		t.Errorf("points = %+v, want frame 3's foreground point only", s.Points)
+	}
+	if len(s.Tracks) != 1 || s.Tracks[0].X != 3 || s.Tracks[0].State != "confirmed" || s.Tracks[0].ObjectClass != "car" {
+		t.Errorf("tracks = %+v, want the confirmed car at x=3", s.Tracks)
+	}
+
+	// Frames 0 and 1 have been evicted.
+	_, err = p.FrameAt(100e6)
+	var rangeErr *TimestampRangeError
+	if !errors.As(err, &rangeErr) || rangeErr.StartNs != 200e6 || rangeErr.EndNs != 400e6 {
+		t.Errorf("evicted timestamp: err = %v, want a 200–400 ms range error", err)
+	}
+
+	p.ResetFrameHistory()
+	if _, err := p.FrameAt(300e6); !errors.Is(err, ErrNoFrames) {
+		t.Errorf("after reset: err = %v, want ErrNoFrames", err)
+	}
+}
+
+// timestampReader is a mockFrameReader that can also be read by
+// timestamp, like the VRLOG replayer.
+type timestampReader struct {
+	*mockFrameReader
+}
+
+func (r timestampReader) FrameAtTimestamp(ts int64) (*FrameBundle, error) {
+	for _, f := range r.frames {
+		if f.TimestampNanos == ts {
+			return f, nil
+		}
+	}
+	return nil, &TimestampRangeError{RequestedNs: ts}
+}
+
+func TestPublisher_FrameAtVRLog(t *testing.T) {
+	p := NewPublisher(DefaultConfig())
+	p.running.Store(true)
+	frames := []*FrameBundle{frameAt(7, 700e6, 7), frameAt(8, 800e6, 8)}
+
+	p.vrlogMu.Lock()
+	p.vrlogReader, p.vrlogActive = timestampReader{newMockFrameReader(frames)}, true
+	p.vrlogMu.Unlock()
+
+	s, err := p.FrameAt(800e6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Source != "vrlog" || s.FrameID != 8 || len(s.Points) != 1 || len(s.Tracks) != 1 {
+		t.Errorf("snapshot = %+v, want frame 8 from the VRLOG", s)
+	}
+	var rangeErr *TimestampRangeError
+	if _, err := p.FrameAt(5); !errors.As(err, &rangeErr) {
+		t.Errorf("err = %v, want a range error from the reader", err)
+	}
+
+	// A reader without timestamp lookup is reported, not silently ignored.
+	p.vrlogMu.Lock()
+	p.vrlogReader = newMockFrameReader(frames)
+	p.vrlogMu.Unlock()
+	if _, err := p.FrameAt(800e6); err == nil {
+		t.Error("expected an error for a reader without timestamp lookup")
+	}
+}
@@ -2,7 +2,7 @@ package l9endpoints
 
 import (
 	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 )
 
 // replayClassifier re-classifies tracks during VRLOG replay when the
@@ -103,6 +103,7 @@ func frameBundleToProto(frame *FrameBundle, req *pb.StreamRequest) *pb.FrameBund
 		for i, c := range cs.Clusters {
 			pbCluster := &pb.Cluster{
 				ClusterId:   c.ClusterID,
+				StableId:    c.StableID,
 				SensorId:    c.SensorID,
 				TimestampNs: c.TimestampNanos,
 				CentroidX:   c.CentroidX,
@@ -157,6 +158,9 @@ func frameBundleToProto(frame *FrameBundle, req *pb.StreamRequest) *pb.FrameBund
 				SpeedMps:          t.SpeedMps,
 				HeadingRad:        t.HeadingRad,
 				Covariance_4X4:    t.Covariance4x4,
+				SpeedStdDevMps:    t.SpeedStdDevMps,
+				CompositeSpeedMps: t.CompositeSpeedMps,
+				InstantSpeedMps:   t.InstantSpeedMps,
 				BboxLength:        t.BBoxLength,
 				BboxWidth:         t.BBoxWidth,
 				BboxHeight:        t.BBoxHeight,
@@ -175,6 +179,7 @@ func frameBundleToProto(frame *FrameBundle, req *pb.StreamRequest) *pb.FrameBund
 				MotionModel:       pb.MotionModel(t.MotionModel),
 				Alpha:             t.Alpha,
 				HeadingSource:     int32(t.HeadingSource),
+				GhostOf:           t.GhostOf,
 			}
 		}
 
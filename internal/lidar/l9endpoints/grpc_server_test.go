@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
@@ -440,6 +440,7 @@ func TestFrameBundleToProto_WithClusters(t *testing.T) {
 			Clusters: []Cluster{
 				{
 					ClusterID: 1,
+					StableID:  42,
 					CentroidX: 10.0,
 					CentroidY: 20.0,
 					CentroidZ: 0.8,
@@ -472,6 +473,9 @@ func TestFrameBundleToProto_WithClusters(t *testing.T) {
 	if pbFrame.Clusters.Clusters[0].ClusterId != 1 {
 		t.Errorf("expected ClusterId=1, got %d", pbFrame.Clusters.Clusters[0].ClusterId)
 	}
+	if pbFrame.Clusters.Clusters[0].StableId != 42 {
+		t.Errorf("expected StableId=42, got %d", pbFrame.Clusters.Clusters[0].StableId)
+	}
 }
 
 func TestFrameBundleToProto_ClusterOBB(t *testing.T) {
@@ -639,21 +643,24 @@ func TestFrameBundleToProto_TrackFieldCompleteness(t *testing.T) {
 					Hits:   10,
 					Misses: 3,
 
-					FirstSeenNanos: 500_000_000,
-					LastSeenNanos:  1_000_000_000,
-					X:              12.0,
-					Y:              8.0,
-					Z:              0.5,
-					VX:             5.0,
-					VY:             0.3,
-					VZ:             0.1,
-					SpeedMps:       5.01,
-					HeadingRad:     0.06,
-					Covariance4x4:  []float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
-					BBoxLength:     4.5,
-					BBoxWidth:      1.8,
-					BBoxHeight:     1.5,
-					BBoxHeadingRad: 0.1,
+					FirstSeenNanos:    500_000_000,
+					LastSeenNanos:     1_000_000_000,
+					X:                 12.0,
+					Y:                 8.0,
+					Z:                 0.5,
+					VX:                5.0,
+					VY:                0.3,
+					VZ:                0.1,
+					SpeedMps:          5.01,
+					HeadingRad:        0.06,
+					Covariance4x4:     []float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
+					SpeedStdDevMps:    0.4,
+					CompositeSpeedMps: 4.9,
+					InstantSpeedMps:   5.3,
+					BBoxLength:        4.5,
+					BBoxWidth:         1.8,
+					BBoxHeight:        1.5,
+					BBoxHeadingRad:    0.1,
 
 					ClassConfidence:   0.92,
 					TrackLengthMetres: 55.0,
@@ -664,6 +671,7 @@ func TestFrameBundleToProto_TrackFieldCompleteness(t *testing.T) {
 					MotionModel:       1, // CV
 					Alpha:             0.8,
 					HeadingSource:     1,
+					GhostOf:           "track-0",
 				},
 			},
 			Trails: []TrackTrail{},
@@ -748,6 +756,15 @@ func TestFrameBundleToProto_TrackFieldCompleteness(t *testing.T) {
 	if len(tr.Covariance_4X4) != 16 {
 		t.Errorf("Covariance4x4 length: got %d, want 16", len(tr.Covariance_4X4))
 	}
+	if tr.SpeedStdDevMps != 0.4 {
+		t.Errorf("SpeedStdDevMps: got %f, want 0.4", tr.SpeedStdDevMps)
+	}
+	if tr.CompositeSpeedMps != 4.9 {
+		t.Errorf("CompositeSpeedMps: got %f, want 4.9", tr.CompositeSpeedMps)
+	}
+	if tr.InstantSpeedMps != 5.3 {
+		t.Errorf("InstantSpeedMps: got %f, want 5.3", tr.InstantSpeedMps)
+	}
 
 	// -- Bounding box ----------------------------------------------------
 	if tr.BboxLength != 4.5 {
@@ -806,6 +823,9 @@ func TestFrameBundleToProto_TrackFieldCompleteness(t *testing.T) {
 	if tr.HeadingSource != 1 {
 		t.Errorf("HeadingSource: got %d, want 1", tr.HeadingSource)
 	}
+	if tr.GhostOf != "track-0" {
+		t.Errorf("GhostOf: got %q, want track-0", tr.GhostOf)
+	}
 }
 
 func TestFrameBundleToProto_WithPlaybackInfo(t *testing.T) {
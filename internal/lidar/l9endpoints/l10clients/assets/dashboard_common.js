@@ -1,5 +1,17 @@
 /* dashboard_common.js — shared utilities for LiDAR monitor dashboards. */
 
+/**
+ * Return the URL prefix the monitor is served under behind a reverse proxy
+ * (e.g. "/sensor1"), read from the page's base-path meta tag. Returns "" at
+ * the root or when there is no document. Dashboards prepend it to every
+ * absolute path they fetch.
+ */
+function readBasePath() {
+  if (typeof document === "undefined") return "";
+  var meta = document.querySelector('meta[name="base-path"]');
+  return meta ? meta.content : "";
+}
+
 /**
  * Escape a value for safe insertion into HTML.
  * Converts &, <, >, ", and ' to their corresponding HTML entities.
@@ -68,5 +80,6 @@ if (typeof module !== "undefined" && module.exports) {
     escapeHTML: escapeHTML,
     parseDuration: parseDuration,
     formatDuration: formatDuration,
+    readBasePath: readBasePath,
   };
 }
@@ -12,8 +12,16 @@ var escapeHTML = _common
   : typeof window !== "undefined"
     ? window.escapeHTML
     : undefined;
+var readBasePath = _common
+  ? _common.readBasePath
+  : typeof window !== "undefined"
+    ? window.readBasePath
+    : undefined;
 /* c8 ignore stop */
 
+/* URL prefix behind a reverse proxy; "" when served at the root. */
+var basePath = readBasePath();
+
 var canvas = null;
 var ctx = null;
 var tooltip = null;
@@ -60,7 +68,9 @@ function cellAtPixel(canvasX, canvasY, canvasWidth, canvasHeight) {
 
 function loadRegions() {
   fetch(
-    "/debug/lidar/background/regions?sensor_id=" + encodeURIComponent(sensorId),
+    basePath +
+      "/debug/lidar/background/regions?sensor_id=" +
+      encodeURIComponent(sensorId),
   )
     .then(function (response) {
       return response.json();
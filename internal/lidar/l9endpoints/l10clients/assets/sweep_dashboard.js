@@ -23,8 +23,16 @@ var formatDuration = _common
   : typeof window !== "undefined"
     ? window.formatDuration
     : undefined;
+var readBasePath = _common
+  ? _common.readBasePath
+  : typeof window !== "undefined"
+    ? window.readBasePath
+    : undefined;
 /* c8 ignore stop */
 
+/* URL prefix behind a reverse proxy; "" when served at the root. */
+var basePath = readBasePath();
+
 var pollTimer = null;
 var stopRequested = false;
 var sweepMode = "manual"; // 'manual' or 'auto'
@@ -429,7 +437,7 @@ var currentCaseHasReference = false;
 function loadReplayCases() {
   var select = document.getElementById("replay_case_select");
   if (!select) return;
-  fetch("/api/lidar/scenes?sensor_id=" + encodeURIComponent(sensorId))
+  fetch(basePath + "/api/lidar/scenes?sensor_id=" + encodeURIComponent(sensorId))
     .then(function (r) {
       return r.json();
     })
@@ -1103,7 +1111,7 @@ function handleStartManualSweep() {
     return;
   }
 
-  fetch("/api/lidar/sweep/start", {
+  fetch(basePath + "/api/lidar/sweep/start", {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify(req),
@@ -1203,7 +1211,7 @@ function handleStartAutoTune() {
   // Hide previous recommendation
   document.getElementById("recommendation-card").style.display = "none";
 
-  fetch("/api/lidar/sweep/auto", {
+  fetch(basePath + "/api/lidar/sweep/auto", {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify(req),
@@ -1228,11 +1236,11 @@ function handleStop() {
 
   var stopUrl;
   if (sweepMode === "hint") {
-    stopUrl = "/api/lidar/sweep/hint/stop";
+    stopUrl = basePath + "/api/lidar/sweep/hint/stop";
   } else if (sweepMode === "auto") {
-    stopUrl = "/api/lidar/sweep/auto/stop";
+    stopUrl = basePath + "/api/lidar/sweep/auto/stop";
   } else {
-    stopUrl = "/api/lidar/sweep/stop";
+    stopUrl = basePath + "/api/lidar/sweep/stop";
   }
   fetch(stopUrl, { method: "POST" }).catch(function (e) {
     showError(e.message);
@@ -1240,7 +1248,7 @@ function handleStop() {
 }
 
 function handleSuspend() {
-  fetch("/api/lidar/sweep/auto/suspend", { method: "POST" })
+  fetch(basePath + "/api/lidar/sweep/auto/suspend", { method: "POST" })
     .then(function (r) {
       if (!r.ok)
         return r.text().then(function (t) {
@@ -1256,7 +1264,7 @@ function handleResume() {
   var body = pendingResumeSweepId
     ? JSON.stringify({ sweep_id: pendingResumeSweepId })
     : "{}";
-  fetch("/api/lidar/sweep/auto/resume", {
+  fetch(basePath + "/api/lidar/sweep/auto/resume", {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     body: body,
@@ -1282,7 +1290,7 @@ function handleResume() {
 // server was restarted after suspending — the in-memory state is lost but the
 // database still has the checkpoint.
 function checkSuspendedSweep() {
-  fetch("/api/lidar/sweep/auto/suspended")
+  fetch(basePath + "/api/lidar/sweep/auto/suspended")
     .then(function (r) {
       return r.json();
     })
@@ -1362,7 +1370,7 @@ function pollStatus() {
     pollAutoTuneStatus();
     return;
   }
-  fetch("/api/lidar/sweep/status")
+  fetch(basePath + "/api/lidar/sweep/status")
     .then(function (r) {
       return r.json();
     })
@@ -1452,7 +1460,7 @@ function pollStatus() {
 }
 
 function pollAutoTuneStatus() {
-  fetch("/api/lidar/sweep/auto")
+  fetch(basePath + "/api/lidar/sweep/auto")
     .then(function (r) {
       return r.json();
     })
@@ -1562,7 +1570,7 @@ function pollAutoTuneStatus() {
           renderRecommendation(st.recommendation, st.round_results);
         }
         // Fetch and display score explanation for the latest sweep
-        fetch("/api/lidar/sweeps?limit=1")
+        fetch(basePath + "/api/lidar/sweeps?limit=1")
           .then(function (resp) {
             return resp.json();
           })
@@ -1742,7 +1750,7 @@ function applyRecommendation() {
   var content = document.getElementById("recommendation-content");
   // Extract param values from the rendered recommendation
   // Re-fetch from auto-tune state
-  fetch("/api/lidar/sweep/auto")
+  fetch(basePath + "/api/lidar/sweep/auto")
     .then(function (r) {
       return r.json();
     })
@@ -1760,7 +1768,7 @@ function applyRecommendation() {
         return;
       }
 
-      fetch("/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId), {
+      fetch(basePath + "/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId), {
         method: "POST",
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify(tuningParams),
@@ -1814,7 +1822,7 @@ function applyReplayCaseParams() {
     return;
   }
 
-  fetch("/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId), {
+  fetch(basePath + "/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId), {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify(tuningParams),
@@ -1906,7 +1914,7 @@ function applyPastedParams() {
   statusEl.textContent = "Applying params...";
   btn.disabled = true;
 
-  fetch("/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId), {
+  fetch(basePath + "/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId), {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify(parsed),
@@ -1928,7 +1936,7 @@ function applyPastedParams() {
 }
 
 function loadCurrentIntoEditor() {
-  fetch("/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId))
+  fetch(basePath + "/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId))
     .then(function (r) {
       return r.json();
     })
@@ -2723,7 +2731,7 @@ window.addEventListener("resize", function () {
 // ---- Current params display ----
 
 function fetchCurrentParams() {
-  var baseUrl = "/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId);
+  var baseUrl = basePath + "/api/lidar/params?sensor_id=" + encodeURIComponent(sensorId);
 
   var fetches = [
     fetch(baseUrl).then(function (r) {
@@ -2975,7 +2983,7 @@ function showSaveChartsButton() {
 
 function saveChartConfigs() {
   if (!currentSweepId) return;
-  fetch("/api/lidar/sweeps/charts", {
+  fetch(basePath + "/api/lidar/sweeps/charts", {
     method: "PUT",
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify({
@@ -2999,7 +3007,7 @@ function saveChartConfigs() {
 // ---- Sweep history ----
 
 function loadSweepHistory() {
-  fetch("/api/lidar/sweeps?sensor_id=" + encodeURIComponent(sensorId))
+  fetch(basePath + "/api/lidar/sweeps?sensor_id=" + encodeURIComponent(sensorId))
     .then(function (r) {
       return r.json();
     })
@@ -3041,7 +3049,7 @@ function onSweepHistorySelected() {
 }
 
 function loadHistoricalSweep(sweepId) {
-  fetch("/api/lidar/sweeps/" + encodeURIComponent(sweepId))
+  fetch(basePath + "/api/lidar/sweeps/" + encodeURIComponent(sweepId))
     .then(function (r) {
       if (!r.ok) throw new Error("Failed to load sweep");
       return r.json();
@@ -3263,7 +3271,7 @@ function init() {
   loadSweepHistory();
 
   // Check for existing sweep on page load
-  fetch("/api/lidar/sweep/auto")
+  fetch(basePath + "/api/lidar/sweep/auto")
     .then(function (r) {
       return r.json();
     })
@@ -3295,7 +3303,7 @@ function init() {
         }
       } else {
         // No auto-tune running, check manual sweep
-        fetch("/api/lidar/sweep/status")
+        fetch(basePath + "/api/lidar/sweep/status")
           .then(function (r) {
             return r.json();
           })
@@ -3322,7 +3330,7 @@ function init() {
     })
     .catch(function () {
       // Auto-tune endpoint not available, fall back to manual sweep check
-      fetch("/api/lidar/sweep/status")
+      fetch(basePath + "/api/lidar/sweep/status")
         .then(function (r) {
           return r.json();
         })
@@ -3488,7 +3496,7 @@ function handleStartHINT() {
   document.getElementById("btn-stop").style.display = "block";
   showError("");
 
-  fetch("/api/lidar/sweep/hint", {
+  fetch(basePath + "/api/lidar/sweep/hint", {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify(req),
@@ -3531,7 +3539,7 @@ function pollHINTStatus() {
   if (hintLongPollAbort) {
     hintLongPollAbort.abort();
   }
-  fetch("/api/lidar/sweep/hint")
+  fetch(basePath + "/api/lidar/sweep/hint")
     .then(function (r) {
       return r.json();
     })
@@ -3570,7 +3578,8 @@ function pollHINTStatus() {
       // Long-poll: wait for the server to signal a state change.
       hintLongPollAbort = new AbortController();
       fetch(
-        "/api/lidar/sweep/hint?wait_for_change=" +
+        basePath +
+          "/api/lidar/sweep/hint?wait_for_change=" +
           encodeURIComponent(st.status),
         {
           signal: hintLongPollAbort.signal,
@@ -3738,7 +3747,7 @@ function renderHINTState(st) {
     }
 
     // Fetch and display score explanation for the latest sweep
-    fetch("/api/lidar/sweeps?limit=1")
+    fetch(basePath + "/api/lidar/sweeps?limit=1")
       .then(function (resp) {
         return resp.json();
       })
@@ -3794,7 +3803,7 @@ function handleHINTContinue() {
     parseInt(document.getElementById("hint-next-duration").value, 10) || 0;
   var addRound = document.getElementById("hint-add-round").checked;
 
-  fetch("/api/lidar/sweep/hint/continue", {
+  fetch(basePath + "/api/lidar/sweep/hint/continue", {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     body: JSON.stringify({
@@ -3820,7 +3829,7 @@ function handleHINTContinue() {
 
 function fetchSweepExplanation(sweepId) {
   if (!sweepId) return;
-  fetch("/api/lidar/sweep/explain/" + encodeURIComponent(sweepId))
+  fetch(basePath + "/api/lidar/sweep/explain/" + encodeURIComponent(sweepId))
     .then(function (resp) {
       if (!resp.ok) return null;
       return resp.json();
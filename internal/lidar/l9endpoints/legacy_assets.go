@@ -11,7 +11,7 @@ import (
 //go:embed l10clients/assets/*
 var legacyAssetsRaw embed.FS
 
-//go:embed l10clients/html/status.html
+//go:embed l10clients/html/status.html l10clients/html/sensors.html
 var legacyStatusRaw embed.FS
 
 //go:embed l10clients/html/dashboard.html
@@ -29,7 +29,8 @@ func LegacyAssetsFS() (fs.FS, error) {
 	return fs.Sub(legacyAssetsRaw, "l10clients/assets")
 }
 
-// LegacyStatusFS returns the embedded status HTML tree rooted at html/.
+// LegacyStatusFS returns the embedded status and sensor index HTML tree
+// rooted at html/.
 func LegacyStatusFS() (fs.FS, error) {
 	return fs.Sub(legacyStatusRaw, "l10clients/html")
 }
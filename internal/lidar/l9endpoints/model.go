@@ -150,6 +150,7 @@ const (
 // Cluster represents a detected foreground object.
 type Cluster struct {
 	ClusterID      int64
+	StableID       int64 // identity across frames; zero when not assigned
 	SensorID       string
 	TimestampNanos int64
 
@@ -235,6 +236,18 @@ type Track struct {
 	// Uncertainty (optional, row-major 4x4)
 	Covariance4x4 []float32
 
+	// SpeedStdDevMps is the 1σ uncertainty of SpeedMps derived from the
+	// velocity covariance. It grows while the track coasts through misses.
+	SpeedStdDevMps float32
+
+	// CompositeSpeedMps is the confidence-weighted speed to report for the
+	// track; zero until it has a Kalman speed observation.
+	CompositeSpeedMps float32
+
+	// InstantSpeedMps is the unsmoothed Kalman speed from the latest
+	// update; AvgSpeedMps, MaxSpeedMps and the speed history are smoothed.
+	InstantSpeedMps float32
+
 	// Bounding box dimensions (per-frame cluster OBB from DBSCAN)
 	BBoxLength     float32 // Per-frame cluster length (metres, along heading)
 	BBoxWidth      float32 // Per-frame cluster width (metres, perpendicular to heading)
@@ -262,6 +275,9 @@ type Track struct {
 	// Rendering hints
 	Alpha         float32 // Opacity [0,1]; 1.0 = fully visible, used for fade-out
 	HeadingSource int     // Source of heading: 0=PCA, 1=velocity, 2=displacement, 3=locked
+
+	// Multipath ghost suppression: ID of the real track this one mirrors.
+	GhostOf string
 }
 
 // UnmarshalJSON keeps legacy .vrlog frames readable after the raw speed-field
@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 )
 
 // TestObjectClassFromString tests the conversion of string labels to proto enums.
@@ -1,14 +1,19 @@
 package l9endpoints
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/banshee-data/velocity.report/internal/lidar"
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 	"google.golang.org/grpc"
 )
 
@@ -28,6 +33,16 @@ type Config struct {
 
 	// BackgroundInterval is how often to send background snapshots (default: 30s)
 	BackgroundInterval time.Duration
+
+	// Port fallback when ListenAddr is already in use. PortFallback is the
+	// number of following ports to try in turn; EphemeralFallback then asks
+	// the OS for a free port. Addr reports the address actually bound.
+	PortFallback      int
+	EphemeralFallback bool
+
+	// FrameHistory is the number of recently published frames kept for
+	// FrameAt lookups during PCAP replay (0 disables)
+	FrameHistory int
 }
 
 // DefaultConfig returns a default configuration.
@@ -38,6 +53,7 @@ func DefaultConfig() Config {
 		EnableDebug:        false,
 		MaxClients:         5,
 		BackgroundInterval: 30 * time.Second,
+		FrameHistory:       DefaultFrameHistory,
 	}
 }
 
@@ -62,6 +78,9 @@ type Publisher struct {
 	recorder   FrameRecorder
 	recorderMu sync.RWMutex
 
+	// Recent frames for FrameAt (nil when Config.FrameHistory is 0)
+	history *frameHistory
+
 	// VRLOG replay state
 	vrlogReader       FrameReader
 	vrlogStopCh       chan struct{}
@@ -117,6 +136,7 @@ func NewPublisher(cfg Config) *Publisher {
 		frameChan: make(chan *FrameBundle, 100),
 		clients:   make(map[string]*clientStream),
 		stopCh:    make(chan struct{}),
+		history:   newFrameHistory(cfg.FrameHistory),
 	}
 }
 
@@ -240,19 +260,85 @@ func (p *Publisher) sendBackgroundSnapshot() error {
 	return nil
 }
 
-// Start starts the gRPC server.
+// ListenError is returned by Start when no candidate address could be
+// bound. Tried lists the addresses attempted, in order; Err is the error
+// for the last one.
+type ListenError struct {
+	Tried []string
+	Err   error
+}
+
+func (e *ListenError) Error() string {
+	return fmt.Sprintf("failed to listen on %s: %v", strings.Join(e.Tried, ", "), e.Err)
+}
+
+func (e *ListenError) Unwrap() error { return e.Err }
+
+// AddrInUse reports whether the last address failed because it was taken.
+func (e *ListenError) AddrInUse() bool { return errors.Is(e.Err, syscall.EADDRINUSE) }
+
+// listenCandidates returns the addresses Start tries: ListenAddr, then up
+// to PortFallback following ports, then an ephemeral port if enabled.
+func (c Config) listenCandidates() []string {
+	candidates := []string{c.ListenAddr}
+	host, portStr, err := net.SplitHostPort(c.ListenAddr)
+	if err != nil {
+		return candidates
+	}
+	if port, err := strconv.Atoi(portStr); err == nil && port > 0 {
+		for i := 1; i <= c.PortFallback && port+i <= 65535; i++ {
+			candidates = append(candidates, net.JoinHostPort(host, strconv.Itoa(port+i)))
+		}
+	}
+	if c.EphemeralFallback && portStr != "0" {
+		candidates = append(candidates, net.JoinHostPort(host, "0"))
+	}
+	return candidates
+}
+
+// listen binds the first free candidate address. Only address-in-use
+// errors move on to the next candidate.
+func (c Config) listen() (net.Listener, error) {
+	lerr := &ListenError{}
+	for _, addr := range c.listenCandidates() {
+		lerr.Tried = append(lerr.Tried, addr)
+		diagf("[Visualiser] Attempting to bind to %s...", addr)
+		lis, err := net.Listen("tcp", addr)
+		if err == nil {
+			return lis, nil
+		}
+		lerr.Err = err
+		if !lerr.AddrInUse() {
+			break
+		}
+		opsf("[Visualiser] %s is in use", addr)
+	}
+	return nil, lerr
+}
+
+// Addr returns the address the gRPC server is bound to, or "" before Start.
+// With port fallback it may differ from Config.ListenAddr.
+func (p *Publisher) Addr() string {
+	if p.listener == nil {
+		return ""
+	}
+	return p.listener.Addr().String()
+}
+
+// Start starts the gRPC server. When no address can be bound it returns a
+// *ListenError.
 func (p *Publisher) Start() error {
 	if p.running.Load() {
 		return fmt.Errorf("publisher already running")
 	}
 
-	diagf("[Visualiser] Attempting to bind to %s...", p.config.ListenAddr)
-	lis, err := net.Listen("tcp", p.config.ListenAddr)
+	lis, err := p.config.listen()
 	if err != nil {
-		return fmt.Errorf("failed to listen: %w", err)
+		return err
 	}
-	diagf("[Visualiser] Successfully bound to %s", p.config.ListenAddr)
 	p.listener = lis
+	boundAddr := lis.Addr().String()
+	diagf("[Visualiser] Successfully bound to %s", boundAddr)
 
 	// Configure max message size for large point clouds (64k+ points).
 	// Default 4MB is insufficient; use 16MB to handle full-resolution frames.
@@ -273,7 +359,7 @@ func (p *Publisher) Start() error {
 	p.wg.Add(1)
 	go func() {
 		defer p.wg.Done()
-		diagf("[Visualiser] gRPC server listening on %s", p.config.ListenAddr)
+		diagf("[Visualiser] gRPC server listening on %s", boundAddr)
 		diagf("[Visualiser] Waiting for client connections...")
 		if err := p.server.Serve(lis); err != nil && p.running.Load() {
 			opsf("[Visualiser] gRPC server error: %v", err)
@@ -302,6 +388,34 @@ func (p *Publisher) Stop() {
 	diagf("[Visualiser] gRPC server stopped")
 }
 
+// Flush waits until every queued frame has been handed to the client
+// streams and each client has taken its frames, or until ctx is done. Call
+// it on shutdown before Stop so the last frames reach connected viewers.
+func (p *Publisher) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for p.running.Load() && p.pendingFrames() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("visualiser flush: %d frames still queued: %w", p.pendingFrames(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// pendingFrames counts frames queued for broadcast or waiting in a
+// client's stream buffer.
+func (p *Publisher) pendingFrames() int {
+	n := len(p.frameChan)
+	p.clientsMu.RLock()
+	defer p.clientsMu.RUnlock()
+	for _, client := range p.clients {
+		n += len(client.frameCh)
+	}
+	return n
+}
+
 // Publish sends a frame to all connected clients.
 func (p *Publisher) Publish(frame interface{}) {
 	if !p.running.Load() {
@@ -356,6 +470,11 @@ func (p *Publisher) Publish(frame interface{}) {
 		}
 	}
 
+	// VRLOG frames are read back from the log itself.
+	if p.history != nil && frameBundle.FrameType != FrameTypeBackground && !p.IsVRLogActive() {
+		p.history.add(NewFrameSnapshot(frameBundle, "replay"))
+	}
+
 	// Calculate frame size for diagnostics
 	pointCount := 0
 	if frameBundle.PointCloud != nil {
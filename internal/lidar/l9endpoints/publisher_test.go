@@ -2,11 +2,15 @@
 package l9endpoints
 
 import (
+	"context"
+	"errors"
+	"net"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -241,6 +245,32 @@ func TestPublisher_BroadcastToClients(t *testing.T) {
 	}
 }
 
+func TestPublisher_FlushWaitsForClients(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ListenAddr = "localhost:0"
+	pub := NewPublisher(cfg)
+
+	if err := pub.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer pub.Stop()
+
+	client := pub.addClient("client-1", &pb.StreamRequest{SensorId: "test"})
+	pub.Publish(NewFrameBundle(1, "test", time.Now()))
+
+	// The client has not taken its frame, so the flush runs out of time.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pub.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush with an unread frame = %v, want deadline exceeded", err)
+	}
+
+	<-client.frameCh
+	if err := pub.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush after the client read its frame = %v", err)
+	}
+}
+
 func TestPublisher_FrameDropOnSlowClient(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.ListenAddr = "localhost:0"
@@ -1283,3 +1313,51 @@ func TestPublisher_EmitFirstBackground_NonePresent(t *testing.T) {
 		t.Fatal("timed out waiting for first frame")
 	}
 }
+
+func TestPublisher_StartPortFallback(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	cfg := DefaultConfig()
+	cfg.ListenAddr = taken.Addr().String()
+
+	// Without fallback a taken port is a typed, address-in-use error.
+	pub := NewPublisher(cfg)
+	err = pub.Start()
+	var lerr *ListenError
+	if !errors.As(err, &lerr) || !lerr.AddrInUse() {
+		t.Fatalf("Start on a taken port = %v, want an address-in-use *ListenError", err)
+	}
+	if len(lerr.Tried) != 1 || lerr.Tried[0] != cfg.ListenAddr {
+		t.Errorf("tried %v, want only %s", lerr.Tried, cfg.ListenAddr)
+	}
+	if pub.Addr() != "" {
+		t.Errorf("Addr() = %q before a successful Start", pub.Addr())
+	}
+
+	// The ephemeral fallback always finds a port and reports it.
+	cfg.EphemeralFallback = true
+	pub = NewPublisher(cfg)
+	if err := pub.Start(); err != nil {
+		t.Fatalf("Start with ephemeral fallback: %v", err)
+	}
+	defer pub.Stop()
+	if addr := pub.Addr(); addr == "" || addr == cfg.ListenAddr {
+		t.Errorf("Addr() = %q, want a different bound address", addr)
+	}
+}
+
+func TestConfig_ListenCandidates(t *testing.T) {
+	cfg := Config{ListenAddr: "localhost:50051", PortFallback: 2, EphemeralFallback: true}
+	got := strings.Join(cfg.listenCandidates(), ",")
+	if want := "localhost:50051,localhost:50052,localhost:50053,localhost:0"; got != want {
+		t.Errorf("candidates = %s, want %s", got, want)
+	}
+	cfg = Config{ListenAddr: "localhost:0", PortFallback: 3, EphemeralFallback: true}
+	if got := cfg.listenCandidates(); len(got) != 1 {
+		t.Errorf("an ephemeral listen address needs no fallback, got %v", got)
+	}
+}
@@ -7,7 +7,7 @@ package recorder
 
 import (
 	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints"
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -70,6 +70,7 @@ func frameBundleToStorageProto(frame *l9endpoints.FrameBundle) *pb.FrameBundle {
 		for i, c := range cs.Clusters {
 			pbCluster := &pb.Cluster{
 				ClusterId:   c.ClusterID,
+				StableId:    c.StableID,
 				SensorId:    c.SensorID,
 				TimestampNs: c.TimestampNanos,
 				CentroidX:   c.CentroidX,
@@ -123,6 +124,9 @@ func frameBundleToStorageProto(frame *l9endpoints.FrameBundle) *pb.FrameBundle {
 				SpeedMps:          t.SpeedMps,
 				HeadingRad:        t.HeadingRad,
 				Covariance_4X4:    t.Covariance4x4,
+				SpeedStdDevMps:    t.SpeedStdDevMps,
+				CompositeSpeedMps: t.CompositeSpeedMps,
+				InstantSpeedMps:   t.InstantSpeedMps,
 				BboxLength:        t.BBoxLength,
 				BboxWidth:         t.BBoxWidth,
 				BboxHeight:        t.BBoxHeight,
@@ -141,6 +145,7 @@ func frameBundleToStorageProto(frame *l9endpoints.FrameBundle) *pb.FrameBundle {
 				MotionModel:       pb.MotionModel(t.MotionModel),
 				Alpha:             t.Alpha,
 				HeadingSource:     int32(t.HeadingSource),
+				GhostOf:           t.GhostOf,
 			}
 		}
 		pbTrails := make([]*pb.TrackTrail, len(ts.Trails))
@@ -245,6 +250,7 @@ func protoToFrameBundle(pbFrame *pb.FrameBundle) *l9endpoints.FrameBundle {
 		for i, c := range cs.Clusters {
 			clusters[i] = l9endpoints.Cluster{
 				ClusterID:      c.ClusterId,
+				StableID:       c.StableId,
 				SensorID:       c.SensorId,
 				TimestampNanos: c.TimestampNs,
 				CentroidX:      c.CentroidX,
@@ -297,6 +303,9 @@ func protoToFrameBundle(pbFrame *pb.FrameBundle) *l9endpoints.FrameBundle {
 				SpeedMps:          t.SpeedMps,
 				HeadingRad:        t.HeadingRad,
 				Covariance4x4:     t.Covariance_4X4,
+				SpeedStdDevMps:    t.SpeedStdDevMps,
+				CompositeSpeedMps: t.CompositeSpeedMps,
+				InstantSpeedMps:   t.InstantSpeedMps,
 				BBoxLength:        t.BboxLength,
 				BBoxWidth:         t.BboxWidth,
 				BBoxHeight:        t.BboxHeight,
@@ -315,6 +324,7 @@ func protoToFrameBundle(pbFrame *pb.FrameBundle) *l9endpoints.FrameBundle {
 				MotionModel:       l9endpoints.MotionModel(t.MotionModel),
 				Alpha:             t.Alpha,
 				HeadingSource:     int(t.HeadingSource),
+				GhostOf:           t.GhostOf,
 			}
 		}
 		trails := make([]l9endpoints.TrackTrail, len(ts.Trails))
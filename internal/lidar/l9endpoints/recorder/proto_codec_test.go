@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints"
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 )
 
 func TestSerializeDeserialize_FullFrame(t *testing.T) {
@@ -42,6 +42,7 @@ func TestSerializeDeserialize_FullFrame(t *testing.T) {
 			Clusters: []l9endpoints.Cluster{
 				{
 					ClusterID:      1,
+					StableID:       7,
 					SensorID:       "s1",
 					TimestampNanos: 123,
 					CentroidX:      1.0,
@@ -78,8 +79,11 @@ func TestSerializeDeserialize_FullFrame(t *testing.T) {
 					FirstSeenNanos: 1000, LastSeenNanos: 2000,
 					X: 1.0, Y: 2.0, Z: 3.0, VX: 0.5, VY: 0.3, VZ: 0.0,
 					SpeedMps: 5.0, HeadingRad: 1.2,
-					Covariance4x4: []float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
-					BBoxLength:    4.5, BBoxWidth: 2.0, BBoxHeight: 1.5, BBoxHeadingRad: 0.5,
+					Covariance4x4:     []float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
+					SpeedStdDevMps:    0.4,
+					CompositeSpeedMps: 4.9,
+					InstantSpeedMps:   5.3,
+					BBoxLength:        4.5, BBoxWidth: 2.0, BBoxHeight: 1.5, BBoxHeadingRad: 0.5,
 
 					ClassConfidence:   0.85,
 					TrackLengthMetres: 50.0, TrackDurationSecs: 10.0,
@@ -87,6 +91,7 @@ func TestSerializeDeserialize_FullFrame(t *testing.T) {
 					OcclusionState: l9endpoints.OcclusionPartial,
 					MotionModel:    l9endpoints.MotionModelCV,
 					Alpha:          1.0, HeadingSource: 1,
+					GhostOf: "t0",
 				},
 			},
 			Trails: []l9endpoints.TrackTrail{
@@ -176,6 +181,9 @@ func TestSerializeDeserialize_FullFrame(t *testing.T) {
 	if result.Clusters == nil || len(result.Clusters.Clusters) != 1 {
 		t.Fatal("expected 1 cluster")
 	}
+	if result.Clusters.Clusters[0].StableID != 7 {
+		t.Errorf("StableID: got %d, want 7", result.Clusters.Clusters[0].StableID)
+	}
 	if result.Clusters.Clusters[0].OBB == nil {
 		t.Fatal("cluster OBB should not be nil")
 	}
@@ -203,9 +211,21 @@ func TestSerializeDeserialize_FullFrame(t *testing.T) {
 	if trk.MotionModel != l9endpoints.MotionModelCV {
 		t.Errorf("MotionModel: got %d, want %d", trk.MotionModel, l9endpoints.MotionModelCV)
 	}
+	if trk.GhostOf != "t0" {
+		t.Errorf("GhostOf: got %q, want t0", trk.GhostOf)
+	}
 	if len(trk.Covariance4x4) != 16 {
 		t.Errorf("Covariance4x4 length: got %d, want 16", len(trk.Covariance4x4))
 	}
+	if trk.SpeedStdDevMps != 0.4 {
+		t.Errorf("SpeedStdDevMps: got %f, want 0.4", trk.SpeedStdDevMps)
+	}
+	if trk.CompositeSpeedMps != 4.9 {
+		t.Errorf("CompositeSpeedMps: got %f, want 4.9", trk.CompositeSpeedMps)
+	}
+	if trk.InstantSpeedMps != 5.3 {
+		t.Errorf("InstantSpeedMps: got %f, want 5.3", trk.InstantSpeedMps)
+	}
 
 	// Trails
 	if len(result.Tracks.Trails) != 1 || len(result.Tracks.Trails[0].Points) != 2 {
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -659,8 +660,73 @@ func (r *Replayer) ReadFrame() (*l9endpoints.FrameBundle, error) {
 		return nil, io.EOF
 	}
 
-	entry := r.index[r.currentFrame]
+	frame, err := r.decodeEntry(r.index[r.currentFrame])
+	if err != nil {
+		return nil, err
+	}
+
+	// Add playback info with current frame index (before incrementing)
+	frame.PlaybackInfo = &l9endpoints.PlaybackInfo{
+		IsLive:            false,
+		LogStartNs:        r.header.StartNs,
+		LogEndNs:          r.header.EndNs,
+		PlaybackRate:      r.rate,
+		Paused:            r.paused,
+		CurrentFrameIndex: r.currentFrame,
+		TotalFrames:       uint64(len(r.index)),
+		Seekable:          true,
+	}
+
+	r.currentFrame++
+	return frame, nil
+}
+
+// FrameAtTimestamp returns the perception frame nearest timestampNs
+// without moving the playback position. Background snapshot frames are
+// skipped. Timestamps before the first or after the last indexed frame
+// return a *l9endpoints.TimestampRangeError.
+func (r *Replayer) FrameAtTimestamp(timestampNs int64) (*l9endpoints.FrameBundle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.index) == 0 {
+		return nil, l9endpoints.ErrNoFrames
+	}
+	first, last := r.index[0].TimestampNs, r.index[len(r.index)-1].TimestampNs
+	if timestampNs < first || timestampNs > last {
+		return nil, &l9endpoints.TimestampRangeError{RequestedNs: timestampNs, StartNs: first, EndNs: last}
+	}
 
+	// The index is written in frame order, so it is sorted by timestamp.
+	i := sort.Search(len(r.index), func(i int) bool { return r.index[i].TimestampNs >= timestampNs })
+	if i > 0 && timestampNs-r.index[i-1].TimestampNs <= r.index[i].TimestampNs-timestampNs {
+		i--
+	}
+
+	// Try the nearest entries outwards until one is not a background
+	// snapshot, which share timestamps with the perception frames.
+	for step := 0; step < len(r.index); step++ {
+		for _, j := range [2]int{i - step, i + step} {
+			if j < 0 || j >= len(r.index) {
+				continue
+			}
+			frame, err := r.decodeEntry(r.index[j])
+			if err != nil {
+				return nil, err
+			}
+			if frame.FrameType != l9endpoints.FrameTypeBackground {
+				return frame, nil
+			}
+			if step == 0 {
+				break
+			}
+		}
+	}
+	return nil, l9endpoints.ErrNoFrames
+}
+
+// decodeEntry reads and decodes the frame at entry. The caller holds r.mu.
+func (r *Replayer) decodeEntry(entry IndexEntry) (*l9endpoints.FrameBundle, error) {
 	// Load chunk if needed
 	if int(entry.ChunkID) != r.currentChunk {
 		if err := r.loadChunk(int(entry.ChunkID)); err != nil {
@@ -692,20 +758,6 @@ func (r *Replayer) ReadFrame() (*l9endpoints.FrameBundle, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to deserialize frame: %w", err)
 	}
-
-	// Add playback info with current frame index (before incrementing)
-	frame.PlaybackInfo = &l9endpoints.PlaybackInfo{
-		IsLive:            false,
-		LogStartNs:        r.header.StartNs,
-		LogEndNs:          r.header.EndNs,
-		PlaybackRate:      r.rate,
-		Paused:            r.paused,
-		CurrentFrameIndex: r.currentFrame,
-		TotalFrames:       uint64(len(r.index)),
-		Seekable:          true,
-	}
-
-	r.currentFrame++
 	return frame, nil
 }
 
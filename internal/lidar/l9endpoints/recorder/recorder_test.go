@@ -4,6 +4,7 @@ package recorder
 import (
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
@@ -549,6 +550,65 @@ func TestReplayerSeekToTimestamp(t *testing.T) {
 	}
 }
 
+// TestReplayerFrameAtTimestamp checks lookups pick the nearest perception
+// frame, skip background snapshots, leave the playback position alone and
+// reject timestamps outside the log.
+func TestReplayerFrameAtTimestamp(t *testing.T) {
+	basePath := filepath.Join(t.TempDir(), "test-log")
+	rec, err := NewRecorder(basePath, "test-sensor")
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	baseTime := int64(1000000000000)
+	for i := 0; i < 10; i++ {
+		frame := testFrameBundle(uint64(i), baseTime+int64(i*100000000))
+		if i == 4 {
+			frame.FrameType = l9endpoints.FrameTypeBackground
+		}
+		rec.Record(frame)
+	}
+	rec.Close()
+
+	rep, err := NewReplayer(basePath)
+	if err != nil {
+		t.Fatalf("NewReplayer() error = %v", err)
+	}
+	defer rep.Close()
+	if err := rep.Seek(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		offsetNs int64
+		wantID   uint64
+	}{
+		{0, 0},
+		{640000000, 6},
+		{260000000, 3},
+		{390000000, 3}, // frame 4 is a background snapshot
+		{900000000, 9},
+	} {
+		frame, err := rep.FrameAtTimestamp(baseTime + tc.offsetNs)
+		if err != nil {
+			t.Fatalf("FrameAtTimestamp(+%d) error = %v", tc.offsetNs, err)
+		}
+		if frame.FrameID != tc.wantID {
+			t.Errorf("FrameAtTimestamp(+%d) = frame %d, want %d", tc.offsetNs, frame.FrameID, tc.wantID)
+		}
+	}
+	if rep.CurrentFrame() != 2 {
+		t.Errorf("CurrentFrame() = %d after lookups, want 2", rep.CurrentFrame())
+	}
+
+	for _, ts := range []int64{baseTime - 1, baseTime + 900000001} {
+		_, err := rep.FrameAtTimestamp(ts)
+		var rangeErr *l9endpoints.TimestampRangeError
+		if !errors.As(err, &rangeErr) || rangeErr.StartNs != baseTime || rangeErr.EndNs != baseTime+900000000 {
+			t.Errorf("FrameAtTimestamp(%d) error = %v, want a range error", ts, err)
+		}
+	}
+}
+
 func TestReplayerReadFrame(t *testing.T) {
 	tmpDir := t.TempDir()
 	basePath := filepath.Join(tmpDir, "test-log")
@@ -8,7 +8,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pb"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
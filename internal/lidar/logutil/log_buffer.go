@@ -0,0 +1,211 @@
+package logutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log levels, in order of increasing verbosity. They are the three logging
+// streams: a buffer's writer for a level captures that stream.
+const (
+	LevelOps   = "ops"
+	LevelDiag  = "diag"
+	LevelTrace = "trace"
+)
+
+// levelRank orders the levels so a filter can ask for "diag and below".
+var levelRank = map[string]int{LevelOps: 0, LevelDiag: 1, LevelTrace: 2}
+
+// ValidLevel reports whether level is ops, diag or trace.
+func ValidLevel(level string) bool {
+	_, ok := levelRank[level]
+	return ok
+}
+
+// subscriberQueue is how many entries a subscriber may fall behind by before
+// newer entries are dropped for it.
+const subscriberQueue = 256
+
+// ErrTooManySubscribers is returned by Subscribe when the buffer already has
+// its maximum number of subscribers.
+var ErrTooManySubscribers = errors.New("too many log subscribers")
+
+// LogEntry is one captured log line.
+type LogEntry struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Tag     string    `json:"tag,omitempty"` // package tag without brackets, e.g. "l3grid"
+	Message string    `json:"message"`
+}
+
+// LogFilter selects entries. The zero value matches everything.
+type LogFilter struct {
+	// Level is the most verbose level to include: "ops" keeps ops only,
+	// "diag" keeps ops and diag. Empty includes every level.
+	Level string
+	// Sensor keeps entries whose tag or message mentions this sensor ID.
+	// Log lines carry no sensor field, so this is a substring match.
+	Sensor string
+}
+
+// Match reports whether e passes the filter.
+func (f LogFilter) Match(e LogEntry) bool {
+	if f.Level != "" && levelRank[e.Level] > levelRank[f.Level] {
+		return false
+	}
+	if f.Sensor != "" && !strings.Contains(e.Message, f.Sensor) && !strings.Contains(e.Tag, f.Sensor) {
+		return false
+	}
+	return true
+}
+
+// LogBuffer keeps the most recent log entries in a fixed-size ring and fans
+// new entries out to a capped number of subscribers, so the monitor can show
+// its own logs to operators without shell access.
+type LogBuffer struct {
+	mu       sync.Mutex
+	entries  []LogEntry // ring; entries[next] is the oldest once full
+	next     int
+	full     bool
+	seq      uint64
+	maxSubs  int
+	nextID   int
+	subs     map[int]chan LogEntry
+	now      func() time.Time
+	location *time.Location
+}
+
+// NewLogBuffer creates a buffer holding up to capacity entries and serving
+// up to maxSubscribers concurrent subscribers. Both are clamped to at least 1.
+func NewLogBuffer(capacity, maxSubscribers int) *LogBuffer {
+	return &LogBuffer{
+		entries:  make([]LogEntry, max(capacity, 1)),
+		maxSubs:  max(maxSubscribers, 1),
+		subs:     make(map[int]chan LogEntry),
+		now:      time.Now,
+		location: time.Local,
+	}
+}
+
+// Writer returns an io.Writer that records each line written to it as an
+// entry at level. It parses lines in the TaggedLogger format (timestamp,
+// "[tag]", message) and also accepts standard log package lines with the
+// same timestamp and no tag; anything else is kept whole as the message.
+// Writes never fail, so the writer is safe to tee with io.MultiWriter.
+func (b *LogBuffer) Writer(level string) io.Writer {
+	return &levelWriter{buf: b, level: level}
+}
+
+type levelWriter struct {
+	buf   *LogBuffer
+	level string
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			w.buf.add(w.level, string(line))
+		}
+	}
+	return len(p), nil
+}
+
+// add parses line and appends it, then offers it to every subscriber. A
+// subscriber whose queue is full misses the entry rather than stalling the
+// logger; the gap shows in the sequence numbers.
+func (b *LogBuffer) add(level, line string) {
+	e := b.parse(level, line)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	e.Seq = b.seq
+	b.entries[b.next] = e
+	b.next++
+	if b.next == len(b.entries) {
+		b.next, b.full = 0, true
+	}
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (b *LogBuffer) parse(level, line string) LogEntry {
+	e := LogEntry{Level: level, Message: line}
+	if len(line) > len(timestampLayout) {
+		if ts, err := time.ParseInLocation(timestampLayout, line[:len(timestampLayout)], b.location); err == nil {
+			e.Time = ts
+			e.Message = strings.TrimPrefix(line[len(timestampLayout):], " ")
+		}
+	}
+	if e.Time.IsZero() {
+		e.Time = b.now()
+	}
+	if strings.HasPrefix(e.Message, "[") {
+		if end := strings.Index(e.Message, "] "); end > 0 {
+			e.Tag = e.Message[1:end]
+			e.Message = e.Message[end+2:]
+		}
+	}
+	return e
+}
+
+// Recent returns up to limit of the newest entries matching filter with a
+// sequence number above afterSeq, oldest first. A limit <= 0 returns every
+// match.
+func (b *LogBuffer) Recent(filter LogFilter, afterSeq uint64, limit int) []LogEntry {
+	b.mu.Lock()
+	ordered := make([]LogEntry, 0, len(b.entries))
+	if b.full {
+		ordered = append(ordered, b.entries[b.next:]...)
+	}
+	ordered = append(ordered, b.entries[:b.next]...)
+	b.mu.Unlock()
+
+	out := make([]LogEntry, 0, len(ordered))
+	for _, e := range ordered {
+		if e.Seq > afterSeq && filter.Match(e) {
+			out = append(out, e)
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+// Subscribe registers for entries added from now on. It returns the
+// channel, a cancel func that must be called to unsubscribe, and
+// ErrTooManySubscribers when the buffer is at its subscriber cap.
+func (b *LogBuffer) Subscribe() (<-chan LogEntry, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subs) >= b.maxSubs {
+		return nil, nil, ErrTooManySubscribers
+	}
+	b.nextID++
+	id := b.nextID
+	ch := make(chan LogEntry, subscriberQueue)
+	b.subs[id] = ch
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+		})
+	}, nil
+}
+
+// MaxSubscribers returns the subscriber cap.
+func (b *LogBuffer) MaxSubscribers() int {
+	return b.maxSubs
+}
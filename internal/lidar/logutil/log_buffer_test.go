@@ -0,0 +1,118 @@
+package logutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/timeutil"
+)
+
+func TestLogBuffer_ParsesTaggedLines(t *testing.T) {
+	t.Parallel()
+
+	buf := NewLogBuffer(10, 1)
+	buf.location = time.UTC
+	fixed := time.Date(2026, 3, 11, 15, 4, 5, 123456000, time.UTC)
+	logger := NewTaggedLoggerWithNow("[l3grid] ", buf.Writer(LevelDiag), timeutil.NewMockClock(fixed).Now)
+	logger.Printf("sensor %s settled", "hesai-01")
+	io.WriteString(buf.Writer(LevelOps), "2026/03/11 15:04:06.000000 plain stdlib line\n")
+	io.WriteString(buf.Writer(LevelOps), "no timestamp here\n")
+
+	got := buf.Recent(LogFilter{}, 0, 0)
+	if len(got) != 3 {
+		t.Fatalf("entries = %d, want 3", len(got))
+	}
+	if e := got[0]; e.Seq != 1 || e.Level != LevelDiag || e.Tag != "l3grid" || e.Message != "sensor hesai-01 settled" || !e.Time.Equal(fixed) {
+		t.Errorf("tagged entry = %+v", e)
+	}
+	if e := got[1]; e.Tag != "" || e.Message != "plain stdlib line" || e.Time.Second() != 6 {
+		t.Errorf("stdlib entry = %+v", e)
+	}
+	if e := got[2]; e.Message != "no timestamp here" || e.Time.IsZero() {
+		t.Errorf("untimestamped entry = %+v", e)
+	}
+}
+
+func TestLogBuffer_RingAndFilters(t *testing.T) {
+	t.Parallel()
+
+	buf := NewLogBuffer(4, 1)
+	for i := 0; i < 6; i++ {
+		level := LevelOps
+		if i%2 == 1 {
+			level = LevelTrace
+		}
+		fmt.Fprintf(buf.Writer(level), "[monitor] sensor-%d line %d\n", i%3, i)
+	}
+
+	all := buf.Recent(LogFilter{}, 0, 0)
+	if len(all) != 4 || all[0].Seq != 3 || all[3].Seq != 6 {
+		t.Fatalf("ring should keep seq 3-6 oldest first, got %+v", all)
+	}
+	if got := buf.Recent(LogFilter{Level: LevelOps}, 0, 0); len(got) != 2 || got[0].Seq != 3 || got[1].Seq != 5 {
+		t.Errorf("ops-only = %+v", got)
+	}
+	if got := buf.Recent(LogFilter{Level: LevelDiag}, 0, 0); len(got) != 2 {
+		t.Errorf("diag and below should exclude trace, got %d entries", len(got))
+	}
+	if got := buf.Recent(LogFilter{Sensor: "sensor-2"}, 0, 0); len(got) != 2 || got[0].Seq != 3 || got[1].Seq != 6 {
+		t.Errorf("sensor filter = %+v", got)
+	}
+	if got := buf.Recent(LogFilter{}, 4, 0); len(got) != 2 || got[0].Seq != 5 {
+		t.Errorf("after seq 4 = %+v", got)
+	}
+	if got := buf.Recent(LogFilter{}, 0, 1); len(got) != 1 || got[0].Seq != 6 {
+		t.Errorf("limit 1 should keep the newest, got %+v", got)
+	}
+}
+
+func TestLogBuffer_SubscriberCap(t *testing.T) {
+	t.Parallel()
+
+	buf := NewLogBuffer(8, 1)
+	ch, cancel, err := buf.Subscribe()
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if _, _, err := buf.Subscribe(); !errors.Is(err, ErrTooManySubscribers) {
+		t.Fatalf("second Subscribe err = %v, want ErrTooManySubscribers", err)
+	}
+
+	io.WriteString(buf.Writer(LevelOps), "[pipeline] frame dropped\n")
+	select {
+	case e := <-ch:
+		if e.Tag != "pipeline" || e.Message != "frame dropped" {
+			t.Errorf("streamed entry = %+v", e)
+		}
+	default:
+		t.Fatal("subscriber received nothing")
+	}
+
+	cancel()
+	cancel() // idempotent
+	if _, cancel2, err := buf.Subscribe(); err != nil {
+		t.Errorf("Subscribe after cancel: %v", err)
+	} else {
+		cancel2()
+	}
+}
+
+func TestLogBuffer_SlowSubscriberDoesNotBlock(t *testing.T) {
+	t.Parallel()
+
+	buf := NewLogBuffer(8, 1)
+	ch, cancel, err := buf.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	for i := 0; i < subscriberQueue+10; i++ {
+		io.WriteString(buf.Writer(LevelOps), "line\n")
+	}
+	if len(ch) != subscriberQueue {
+		t.Errorf("queued = %d, want %d with the rest dropped", len(ch), subscriberQueue)
+	}
+}
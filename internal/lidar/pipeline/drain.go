@@ -0,0 +1,156 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
+)
+
+// DefaultDrainTimeout bounds how long a graceful shutdown may spend
+// draining in-flight frames and flushing sinks before the process exits
+// regardless.
+const DefaultDrainTimeout = 10 * time.Second
+
+// DrainStep is one named action in a graceful shutdown sequence, such as
+// flushing the frame builder or finalising open tracks. A positive Timeout
+// bounds the step on its own: when it expires the step is abandoned and
+// the remaining steps still run.
+type DrainStep struct {
+	Name    string
+	Fn      func(ctx context.Context) error
+	Timeout time.Duration
+}
+
+// Drain runs steps in order under a single shared deadline. Step errors
+// are collected and the remaining steps still run; when the deadline
+// expires the step in progress is abandoned, the remaining steps are
+// skipped, and the returned error wraps context.DeadlineExceeded. A
+// non-positive timeout uses DefaultDrainTimeout.
+func Drain(timeout time.Duration, steps ...DrainStep) error {
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for i, step := range steps {
+		if step.Fn == nil {
+			continue
+		}
+		stepTimeout := timeout
+		if step.Timeout > 0 {
+			stepTimeout = step.Timeout
+		}
+		stepCtx, stepCancel := context.WithTimeout(ctx, stepTimeout)
+		done := make(chan error, 1)
+		started := time.Now()
+		go func() { done <- step.Fn(stepCtx) }()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				opsf("Drain step %q failed: %v", step.Name, err)
+				errs = append(errs, fmt.Errorf("%s: %w", step.Name, err))
+			} else {
+				diagf("Drain step %q completed in %v", step.Name, time.Since(started))
+			}
+		case <-stepCtx.Done():
+			if ctx.Err() != nil {
+				stepCancel()
+				opsf("Drain deadline %v exceeded during step %q; skipping %d remaining steps",
+					timeout, step.Name, len(steps)-i-1)
+				errs = append(errs, fmt.Errorf("%s: %w", step.Name, ctx.Err()))
+				return errors.Join(errs...)
+			}
+			opsf("Drain step %q exceeded its %v timeout; continuing", step.Name, step.Timeout)
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, stepCtx.Err()))
+		}
+		stepCancel()
+	}
+	return errors.Join(errs...)
+}
+
+// FinalizeOpenTracks ends every confirmed track as a clean end of stream:
+// each track receives a final classification, is marked deleted, and is
+// written to the track store (and the active analysis run, if any) so the
+// last transits survive a shutdown. Each is also delivered to TrackSink as
+// a TrackEventEnded event. Returns the number of tracks finalised.
+// Persistence honours DisableTrackPersistence.
+func (cfg *TrackingPipelineConfig) FinalizeOpenTracks() (int, error) {
+	if cfg.Tracker == nil {
+		return 0, nil
+	}
+	tracks := cfg.Tracker.GetConfirmedTracks()
+	cfg.flushNearMisses()
+	if len(tracks) == 0 {
+		return 0, nil
+	}
+
+	var runManager *sqlite.AnalysisRunManager
+	if cfg.AnalysisRunManager != nil {
+		runManager = cfg.AnalysisRunManager
+	} else {
+		runManager = sqlite.GetAnalysisRunManager(cfg.SensorID)
+	}
+
+	frameID := fmt.Sprintf("site/%s", cfg.SensorID)
+	for _, track := range tracks {
+		if cfg.Classifier != nil && track.ObservationCount >= cfg.Classifier.MinObservations {
+			cfg.Classifier.ClassifyAndUpdate(track)
+		}
+		if cfg.Classifier != nil && cfg.Classifier.Stabiliser != nil {
+			cfg.Classifier.Stabiliser.Forget(track.TrackID)
+		}
+		if cfg.Tripwires != nil {
+			cfg.Tripwires.Forget(track.TrackID)
+		}
+		track.TrackState = l5tracks.TrackDeleted
+
+		if runManager != nil && runManager.IsRunActive() {
+			runManager.RecordTrack(track)
+		}
+		if !isNilInterface(cfg.TrackSink) {
+			cfg.TrackSink.PublishTrackEvent(TrackEventEnded, track)
+		}
+	}
+
+	if cfg.DB != nil && (cfg.DisableTrackPersistence == nil || !cfg.DisableTrackPersistence.Load()) {
+		err := sqlite.WithTx(context.Background(), cfg.DB, func(tx *sqlite.SQLTx) error {
+			for _, track := range tracks {
+				if err := sqlite.InsertTrack(tx, track, frameID); err != nil {
+					return fmt.Errorf("finalise track %s: %w", track.TrackID, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	diagf("Finalised %d open tracks at end of stream", len(tracks))
+	return len(tracks), nil
+}
+
+// flushNearMisses delivers the encounters still open at shutdown.
+func (cfg *TrackingPipelineConfig) flushNearMisses() {
+	if cfg.NearMisses == nil {
+		return
+	}
+	var nearMissSink NearMissSink
+	if !isNilInterface(cfg.TrackSink) {
+		nearMissSink, _ = cfg.TrackSink.(NearMissSink)
+	}
+	for _, nearMiss := range cfg.NearMisses.Flush() {
+		if nearMiss.SensorID == "" {
+			nearMiss.SensorID = cfg.SensorID
+		}
+		if nearMissSink != nil {
+			nearMissSink.PublishNearMiss(nearMiss)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l2frames"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+)
+
+func TestDrain_RunsStepsInOrderAndCollectsErrors(t *testing.T) {
+	var order []string
+	errBoom := errors.New("boom")
+	err := Drain(time.Second,
+		DrainStep{Name: "first", Fn: func(ctx context.Context) error { order = append(order, "first"); return errBoom }},
+		DrainStep{Name: "nil-fn"},
+		DrainStep{Name: "second", Fn: func(ctx context.Context) error { order = append(order, "second"); return nil }},
+	)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected error to wrap step failure, got %v", err)
+	}
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("expected steps to run in order after a failure, got %v", order)
+	}
+}
+
+func TestDrain_DeadlineSkipsRemainingSteps(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var ranLast bool
+	start := time.Now()
+	err := Drain(20*time.Millisecond,
+		DrainStep{Name: "stuck", Fn: func(ctx context.Context) error { <-release; return nil }},
+		DrainStep{Name: "last", Fn: func(ctx context.Context) error { ranLast = true; return nil }},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if ranLast {
+		t.Error("expected steps after the deadline to be skipped")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("drain overran its deadline: %v", elapsed)
+	}
+}
+
+func TestDrain_StepTimeoutContinuesWithRemainingSteps(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var ranLast bool
+	err := Drain(time.Second,
+		DrainStep{Name: "stuck", Timeout: 20 * time.Millisecond, Fn: func(ctx context.Context) error { <-release; return nil }},
+		DrainStep{Name: "last", Fn: func(ctx context.Context) error { ranLast = true; return nil }},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the step timeout to be reported, got %v", err)
+	}
+	if !ranLast {
+		t.Error("expected steps after a step timeout to still run")
+	}
+}
+
+// TestDrain_FlushesPendingObservation simulates a shutdown with a frame
+// still being assembled and a confirmed track still open. Draining must
+// deliver the frame to the pipeline sink and persist the track as ended.
+func TestDrain_FlushesPendingObservation(t *testing.T) {
+	db := setupTestDB(t)
+
+	var mu sync.Mutex
+	var observed []string
+	fb := l2frames.NewFrameBuilderDI(l2frames.FrameBuilderConfig{
+		SensorID:       "drain-test",
+		MinFramePoints: 1,
+		BufferTimeout:  time.Hour, // only the drain may finalise the frame
+		FrameCallback: func(f *l2frames.LiDARFrame) {
+			mu.Lock()
+			defer mu.Unlock()
+			observed = append(observed, f.FrameID)
+		},
+	})
+
+	// A partial rotation: no azimuth wrap, so the frame stays in progress.
+	now := time.Now()
+	polar := make([]l2frames.PointPolar, 0, 10)
+	for i := 0; i < 10; i++ {
+		polar = append(polar, l2frames.PointPolar{
+			Channel:   1,
+			Azimuth:   float64(i * 10),
+			Distance:  5,
+			Timestamp: now.Add(time.Duration(i) * time.Millisecond).UnixNano(),
+		})
+	}
+	fb.AddPointsPolar(polar)
+
+	cfg := &TrackingPipelineConfig{
+		SensorID: "drain-test",
+		DB:       db,
+		Tracker: &mockTrackerCov{confirmedTracks: []*l5tracks.TrackedObject{{
+			TrackID: "drain-t1",
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				SensorID:         "drain-test",
+				TrackState:       l5tracks.TrackConfirmed,
+				StartUnixNanos:   now.UnixNano(),
+				EndUnixNanos:     now.Add(time.Second).UnixNano(),
+				ObservationCount: 10,
+			},
+		}}},
+	}
+
+	err := Drain(5*time.Second,
+		DrainStep{Name: "frames", Fn: func(ctx context.Context) error {
+			fb.Flush()
+			fb.Close()
+			return nil
+		}},
+		DrainStep{Name: "tracks", Fn: func(ctx context.Context) error {
+			_, err := cfg.FinalizeOpenTracks()
+			return err
+		}},
+	)
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+
+	mu.Lock()
+	if len(observed) != 1 {
+		t.Errorf("expected the in-progress frame to be flushed to the sink, got %v", observed)
+	}
+	mu.Unlock()
+
+	var state string
+	if err := db.QueryRow("SELECT track_state FROM lidar_tracks WHERE track_id = 'drain-t1'").Scan(&state); err != nil {
+		t.Fatalf("query finalised track: %v", err)
+	}
+	if state != string(l5tracks.TrackDeleted) {
+		t.Errorf("expected finalised track state %q, got %q", l5tracks.TrackDeleted, state)
+	}
+}
+
+func TestFinalizeOpenTracks_RespectsDisabledPersistence(t *testing.T) {
+	db := setupTestDB(t)
+	cfg := &TrackingPipelineConfig{
+		SensorID: "drain-disabled",
+		DB:       db,
+		Tracker: &mockTrackerCov{confirmedTracks: []*l5tracks.TrackedObject{{
+			TrackID:          "drain-t2",
+			TrackMeasurement: l5tracks.TrackMeasurement{SensorID: "drain-disabled", TrackState: l5tracks.TrackConfirmed},
+		}}},
+	}
+	disablePersist := &atomic.Bool{}
+	disablePersist.Store(true)
+	cfg.DisableTrackPersistence = disablePersist
+
+	n, err := cfg.FinalizeOpenTracks()
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 finalised track and no error, got %d, %v", n, err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lidar_tracks").Scan(&count); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no persisted tracks with persistence disabled, got %d", count)
+	}
+
+	if n, err := (&TrackingPipelineConfig{}).FinalizeOpenTracks(); n != 0 || err != nil {
+		t.Errorf("expected no-op without a tracker, got %d, %v", n, err)
+	}
+}
+
+// nearMissRecorder is a TrackSink that also records near misses.
+type nearMissRecorder struct {
+	nearMisses []l6objects.NearMiss
+}
+
+func (r *nearMissRecorder) PublishTrackEvent(string, *l5tracks.TrackedObject) {}
+
+func (r *nearMissRecorder) PublishNearMiss(nearMiss l6objects.NearMiss) {
+	r.nearMisses = append(r.nearMisses, nearMiss)
+}
+
+func TestFinalizeOpenTracks_FlushesOpenNearMisses(t *testing.T) {
+	detector, err := l6objects.NewNearMissDetector(l6objects.NearMissConfig{ThresholdMetres: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracks := []*l5tracks.TrackedObject{
+		{TrackID: "a", X: 0, VX: 5, TrackMeasurement: l5tracks.TrackMeasurement{TrackState: l5tracks.TrackConfirmed}},
+		{TrackID: "b", X: 1, VX: -5, TrackMeasurement: l5tracks.TrackMeasurement{TrackState: l5tracks.TrackConfirmed}},
+	}
+	if ended := detector.Observe(tracks); len(ended) != 0 {
+		t.Fatalf("encounter ended early: %+v", ended)
+	}
+
+	sink := &nearMissRecorder{}
+	cfg := &TrackingPipelineConfig{
+		SensorID:   "drain-near-miss",
+		Tracker:    &mockTrackerCov{confirmedTracks: tracks},
+		TrackSink:  sink,
+		NearMisses: detector,
+	}
+	if _, err := cfg.FinalizeOpenTracks(); err != nil {
+		t.Fatalf("FinalizeOpenTracks: %v", err)
+	}
+	if len(sink.nearMisses) != 1 || sink.nearMisses[0].SensorID != "drain-near-miss" {
+		t.Fatalf("expected the open encounter on shutdown, got %+v", sink.nearMisses)
+	}
+}
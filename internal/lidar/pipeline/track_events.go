@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+)
+
+// Track lifecycle events delivered to a TrackSink.
+const (
+	// TrackEventConfirmed fires once when a track first becomes confirmed.
+	TrackEventConfirmed = "track.confirmed"
+	// TrackEventEnded fires once when a confirmed track is deleted or
+	// finalised at end of stream; it carries the completed transit.
+	TrackEventEnded = "track.ended"
+)
+
+// TrackSink receives track lifecycle events for delivery to external
+// consumers such as a message broker. It is an adapter, like
+// PublishSink: implementations live outside the pipeline package.
+// PublishTrackEvent is called on the frame goroutine, so it must not
+// block, and must not retain track after returning.
+type TrackSink interface {
+	PublishTrackEvent(event string, track *l5tracks.TrackedObject)
+}
+
+// CrossingSink is optionally implemented by a TrackSink to also receive
+// count-line crossings from TrackingPipelineConfig.Tripwires. The same
+// rules apply: PublishCrossing must not block.
+type CrossingSink interface {
+	PublishCrossing(crossing l6objects.LineCrossing)
+}
+
+// NearMissSink is optionally implemented by a TrackSink to also receive
+// near-miss encounters from TrackingPipelineConfig.NearMisses. The same
+// rules apply: PublishNearMiss must not block.
+type NearMissSink interface {
+	PublishNearMiss(nearMiss l6objects.NearMiss)
+}
+
+// trackEventEmitter derives lifecycle events by diffing the confirmed
+// track set between frames. Not safe for concurrent use.
+type trackEventEmitter struct {
+	sink    TrackSink
+	tracker l5tracks.TrackerInterface
+	open    map[string]struct{}
+}
+
+func newTrackEventEmitter(sink TrackSink, tracker l5tracks.TrackerInterface) *trackEventEmitter {
+	return &trackEventEmitter{
+		sink:    sink,
+		tracker: tracker,
+		open:    make(map[string]struct{}),
+	}
+}
+
+// observe emits confirmed events for newly confirmed tracks and ended
+// events for previously confirmed tracks that are no longer confirmed.
+func (e *trackEventEmitter) observe(confirmed []*l5tracks.TrackedObject) {
+	current := make(map[string]struct{}, len(confirmed))
+	for _, track := range confirmed {
+		current[track.TrackID] = struct{}{}
+		if _, seen := e.open[track.TrackID]; !seen {
+			e.open[track.TrackID] = struct{}{}
+			e.sink.PublishTrackEvent(TrackEventConfirmed, track)
+		}
+	}
+	for trackID := range e.open {
+		if _, ok := current[trackID]; ok {
+			continue
+		}
+		delete(e.open, trackID)
+		// The tracker keeps deleted tracks for a grace period, so the
+		// final state is normally still available here.
+		if track := e.tracker.GetTrack(trackID); track != nil {
+			e.sink.PublishTrackEvent(TrackEventEnded, track)
+		} else {
+			diagf("Track %s ended but is no longer held by the tracker; skipping %s event", trackID, TrackEventEnded)
+		}
+	}
+}
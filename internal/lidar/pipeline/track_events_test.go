@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+type recordingTrackSink struct {
+	events []string
+}
+
+func (s *recordingTrackSink) PublishTrackEvent(event string, track *l5tracks.TrackedObject) {
+	s.events = append(s.events, event+":"+track.TrackID)
+}
+
+func eventTrack(id string, state l5tracks.TrackState) *l5tracks.TrackedObject {
+	return &l5tracks.TrackedObject{TrackID: id, TrackMeasurement: l5tracks.TrackMeasurement{TrackState: state}}
+}
+
+func TestTrackEventEmitter_Lifecycle(t *testing.T) {
+	a := eventTrack("a", l5tracks.TrackConfirmed)
+	b := eventTrack("b", l5tracks.TrackConfirmed)
+	tracker := &mockTrackerCov{allTracks: []*l5tracks.TrackedObject{a, b}}
+	sink := &recordingTrackSink{}
+	e := newTrackEventEmitter(sink, tracker)
+
+	e.observe([]*l5tracks.TrackedObject{a})
+	e.observe([]*l5tracks.TrackedObject{a, b})
+	e.observe([]*l5tracks.TrackedObject{a, b}) // no change, no events
+
+	a.TrackState = l5tracks.TrackDeleted
+	e.observe([]*l5tracks.TrackedObject{b})
+
+	// b disappears after the tracker has already dropped it.
+	tracker.allTracks = nil
+	e.observe(nil)
+
+	want := []string{"track.confirmed:a", "track.confirmed:b", "track.ended:a"}
+	if !reflect.DeepEqual(sink.events, want) {
+		t.Errorf("events = %v, want %v", sink.events, want)
+	}
+	if len(e.open) != 0 {
+		t.Errorf("expected no open tracks, got %v", e.open)
+	}
+}
+
+func TestFinalizeOpenTracks_PublishesEndedEvents(t *testing.T) {
+	sink := &recordingTrackSink{}
+	cfg := &TrackingPipelineConfig{
+		SensorID:  "events",
+		Tracker:   &mockTrackerCov{confirmedTracks: []*l5tracks.TrackedObject{eventTrack("x", l5tracks.TrackConfirmed)}},
+		TrackSink: sink,
+	}
+	if n, err := cfg.FinalizeOpenTracks(); n != 1 || err != nil {
+		t.Fatalf("FinalizeOpenTracks = %d, %v", n, err)
+	}
+	if want := []string{"track.ended:x"}; !reflect.DeepEqual(sink.events, want) {
+		t.Errorf("events = %v, want %v", sink.events, want)
+	}
+}
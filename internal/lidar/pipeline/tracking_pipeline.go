@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -17,6 +18,10 @@ import (
 	"github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
 )
 
+// trackPersistTimeout bounds how long one frame's track writes are retried
+// while the database is busy before they are dropped.
+const trackPersistTimeout = time.Second
+
 // ForegroundForwarder interface allows forwarding foreground points without importing network package.
 type ForegroundForwarder interface {
 	ForwardForeground(points []l2frames.PointPolar)
@@ -126,10 +131,13 @@ type TrackingPipelineConfig struct {
 	Classifier          *l6objects.TrackClassifier
 	DB                  *sqlite.SQLDB // Use sqlite.SQLDB to avoid importing database/sql directly
 	SensorID            string
-	AnalysisRunManager  *sqlite.AnalysisRunManager // Optional: for recording analysis runs
-	VisualiserPublisher VisualiserPublisher        // Optional: gRPC publisher
-	VisualiserAdapter   VisualiserAdapter          // Optional: adapter for gRPC
-	LidarViewAdapter    LidarViewAdapter           // Optional: adapter for UDP forwarding
+	AnalysisRunManager  *sqlite.AnalysisRunManager  // Optional: for recording analysis runs
+	VisualiserPublisher VisualiserPublisher         // Optional: gRPC publisher
+	VisualiserAdapter   VisualiserAdapter           // Optional: adapter for gRPC
+	LidarViewAdapter    LidarViewAdapter            // Optional: adapter for UDP forwarding
+	TrackSink           TrackSink                   // Optional: track lifecycle events (e.g. NATS JetStream)
+	Tripwires           *l6objects.TripwireCounter  // Optional: count-line crossings, delivered to TrackSink if it is a CrossingSink
+	NearMisses          *l6objects.NearMissDetector // Optional: close encounters between tracks, delivered to TrackSink if it is a NearMissSink
 
 	// MaxFrameRate caps the rate at which frames are fully processed through
 	// the tracking pipeline. When frames arrive faster than this rate (e.g.
@@ -148,6 +156,40 @@ type TrackingPipelineConfig struct {
 	// Zero disables voxel downsampling.
 	VoxelLeafSize float64
 
+	// VoxelOrigin is a world-frame voxel corner (x, y, z) in metres, from
+	// the L4 tuning key voxel_origin. The voxel grid is fixed to it rather
+	// than to the points in each frame; the zero value aligns it to the
+	// world origin.
+	VoxelOrigin [3]float64
+
+	// VoxelSnapToGrid, from the L4 tuning key voxel_snap_to_grid, replaces
+	// each voxel's representative point with the voxel centre so static
+	// objects produce identical points every frame, steadying cluster
+	// centroids.
+	VoxelSnapToGrid bool
+
+	// MinPtsReferenceRange and MinPtsFloor configure range-adaptive DBSCAN
+	// MinPts from the L4 tuning keys min_pts_reference_range and
+	// min_pts_floor (see l4perception.DBSCANParams). Range is measured from
+	// the sensor, which is the world origin under the identity pose used
+	// here. A zero reference range keeps the fixed MinPts.
+	MinPtsReferenceRange float64
+	MinPtsFloor          int
+
+	// ClusterFeatureWeights adds height and intensity differences to the
+	// DBSCAN neighbourhood distance so touching but physically different
+	// objects separate. The zero value clusters on XY position alone.
+	ClusterFeatureWeights l4perception.FeatureWeights
+
+	// ClusterMerge joins DBSCAN clusters whose centroids are close and
+	// whose union still fits a single-object size, undoing head/tail
+	// fragmentation of long vehicles. The zero value disables merging.
+	ClusterMerge l4perception.ClusterMerge
+
+	// ClusterWorkers spreads DBSCAN's region queries over this many
+	// goroutines. 0 or 1 clusters serially, for single-core deployments.
+	ClusterWorkers int
+
 	// FeatureExportFunc, when non-nil, is called for every confirmed track
 	// after classification. This hook allows exporting feature vectors for
 	// ML training data collection. The callback receives the track's
@@ -169,6 +211,29 @@ type TrackingPipelineConfig struct {
 	// clustering. Set to false to disable ground removal entirely.
 	RemoveGround bool
 
+	// RingROI, when non-nil, restricts clustering to this sensor's ring or
+	// elevation band; out-of-band foreground points are dropped before the
+	// world transform. With RingROI.WholePipeline they are dropped before
+	// background subtraction instead.
+	RingROI *l4perception.RingROI
+
+	// BloomFilter removes retro-reflective blooms from signs and number
+	// plates: saturated, tight blobs that stay put. It runs after ground
+	// removal and before clustering. The zero value disables it.
+	BloomFilter l4perception.BloomFilterConfig
+
+	// SlowMover adds foreground from recent frames where it has persisted,
+	// so very slow movers gather enough points to cluster while transient
+	// noise is not accumulated. It runs after bloom removal and before
+	// voxel downsampling. The zero value disables it.
+	SlowMover l4perception.SlowMoverConfig
+
+	// ClusterIdentity assigns each cluster a StableID carried over from the
+	// overlapping cluster of the previous frame, for the visualiser and
+	// debugging. Frames that stop before clustering leave the previous
+	// clusters in place. The zero value leaves StableID unset.
+	ClusterIdentity l4perception.ClusterIdentityConfig
+
 	// BenchmarkMode, when non-nil and true, enables per-frame performance
 	// tracing: stage timing via FrameTimer, slow-frame alerts, periodic
 	// health summaries (heap/goroutines), and pipeline lag detection.
@@ -193,10 +258,25 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 	// carry mutable state that the pipeline must see.
 	maxFrameRate := cfg.MaxFrameRate
 	voxelLeafSize := cfg.VoxelLeafSize
+	voxelGrid := l4perception.VoxelGridConfig{
+		LeafSize:     cfg.VoxelLeafSize,
+		OriginX:      cfg.VoxelOrigin[0],
+		OriginY:      cfg.VoxelOrigin[1],
+		OriginZ:      cfg.VoxelOrigin[2],
+		SnapToCentre: cfg.VoxelSnapToGrid,
+	}
 	heightBandFloor := cfg.HeightBandFloor
 	heightBandCeiling := cfg.HeightBandCeiling
 	removeGround := cfg.RemoveGround
 	sensorID := cfg.SensorID
+	var ringROI *l4perception.RingROI
+	if cfg.RingROI != nil {
+		roi := *cfg.RingROI
+		ringROI = &roi
+	}
+	bloomFilter := l4perception.NewBloomFilter(cfg.BloomFilter)
+	slowMover := l4perception.NewSlowMoverAccumulator(cfg.SlowMover)
+	clusterIdentity := l4perception.NewClusterIdentifier(cfg.ClusterIdentity)
 
 	// Get AnalysisRunManager from registry if not explicitly set
 	// This allows analysis runs to be started/stopped dynamically via webserver
@@ -230,10 +310,36 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 	var logFgForwarderNilOnce sync.Once
 	var logGroundDisabledOnce sync.Once
 
+	// Track lifecycle events for the optional external sink.
+	var trackEvents *trackEventEmitter
+	if !isNilInterface(cfg.TrackSink) && cfg.Tracker != nil {
+		trackEvents = newTrackEventEmitter(cfg.TrackSink, cfg.Tracker)
+	}
+	var crossingSink CrossingSink
+	var nearMissSink NearMissSink
+	if !isNilInterface(cfg.TrackSink) {
+		crossingSink, _ = cfg.TrackSink.(CrossingSink)
+		nearMissSink, _ = cfg.TrackSink.(NearMissSink)
+	}
+
+	// Points are clustered in the sensor frame: the identity pose puts the
+	// sensor at the world origin.
+	var sensorPose *l4perception.Pose
+
 	// Cache the default DBSCAN params once at callback creation time rather
 	// than loading from disk on every frame. The per-frame overrides
 	// (Eps, MinPts, MaxInputPoints) from BackgroundParams still apply.
 	defaultDBSCANParams := l4perception.DefaultDBSCANParams()
+	defaultDBSCANParams.MinPtsReferenceRange = cfg.MinPtsReferenceRange
+	defaultDBSCANParams.MinPtsFloor = cfg.MinPtsFloor
+	defaultDBSCANParams.FeatureWeights = cfg.ClusterFeatureWeights
+	defaultDBSCANParams.Merge = cfg.ClusterMerge
+	defaultDBSCANParams.Workers = cfg.ClusterWorkers
+	defaultDBSCANParams.Density = l4perception.DefaultSamplingDensity()
+	defaultDBSCANParams.Density.VoxelLeafSize = voxelLeafSize
+	// Range-adaptive MinPts and the density model measure range from the
+	// pose that TransformToWorld uses below, so the two stay consistent.
+	defaultDBSCANParams = defaultDBSCANParams.WithSensorPose(sensorPose)
 
 	// Pipeline performance tracing state.
 	const slowFrameThresholdMs = 50.0 // emit diagf alert when frame exceeds this
@@ -271,6 +377,10 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 		// frame builder populates PolarPoints alongside Points when
 		// AddPointsPolar is used, eliminating the per-frame rebuild.
 		polar := frame.PolarPoints
+		var roiKept []int // frame indices of polar when the ROI filters the whole pipeline
+		if ringROI != nil && ringROI.WholePipeline {
+			polar, roiKept = filterRingROI(ringROI, polar)
+		}
 
 		if cfg.BackgroundManager == nil {
 			publishEmptyFrame(frame)
@@ -466,7 +576,12 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 		if ft != nil {
 			ft.Stage("transform")
 		}
-		worldPoints := l4perception.TransformToWorld(foregroundPoints, nil, sensorID)
+		clusterInput := foregroundPoints
+		if ringROI != nil && !ringROI.WholePipeline {
+			clusterInput = ringROI.Filter(foregroundPoints)
+			tracef("Ring ROI: %d of %d foreground points in band", len(clusterInput), len(foregroundPoints))
+		}
+		worldPoints := l4perception.TransformToWorld(clusterInput, sensorPose, sensorID)
 
 		// Stage 2b: Ground removal (vertical filtering)
 		// Remove ground plane and overhead structure returns to reduce false clusters.
@@ -490,6 +605,22 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 			})
 		}
 
+		// Retro-reflective bloom removal (optional). The filter
+		// follows blobs across frames, so it sees every processed frame.
+		if bloomFilter != nil {
+			before := len(filteredPoints)
+			filteredPoints = bloomFilter.Filter(filteredPoints)
+			tracef("Bloom filter: removed %d of %d points", before-len(filteredPoints), before)
+		}
+
+		// Slow-mover accumulation (optional). Like the bloom filter it
+		// keeps state across frames, so it sees every processed frame.
+		if slowMover != nil {
+			before := len(filteredPoints)
+			filteredPoints = slowMover.Accumulate(filteredPoints)
+			tracef("Slow-mover accumulation: %d persistent points from %d in this frame", len(filteredPoints), before)
+		}
+
 		if len(filteredPoints) == 0 {
 			if emitTiming != nil {
 				emitTiming(len(foregroundPoints), 0, 0)
@@ -503,7 +634,7 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 		// tightens cluster boundaries and speeds up DBSCAN.
 		if voxelLeafSize > 0 {
 			before := len(filteredPoints)
-			filteredPoints = l4perception.VoxelGrid(filteredPoints, voxelLeafSize)
+			filteredPoints = l4perception.VoxelGridWithConfig(filteredPoints, voxelGrid)
 			tracef("Voxel downsample: %d → %d (leaf=%.3fm)",
 				before, len(filteredPoints), voxelLeafSize)
 		}
@@ -530,6 +661,7 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 		dbscanParams.MaxInputPoints = maxInputPoints
 
 		clusters := l4perception.DBSCAN(filteredPoints, dbscanParams)
+		clusterIdentity.Assign(clusters)
 		if len(clusters) == 0 {
 			// No clusters, but still record foreground stats (all points are noise)
 			if cfg.Tracker != nil {
@@ -581,22 +713,13 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 		confirmedTracks := cfg.Tracker.GetConfirmedTracks()
 		tracef("%d confirmed tracks to persist", len(confirmedTracks))
 
-		// Open a per-frame transaction for batching all track/observation writes.
-		// Skip entirely when DisableTrackPersistence is set (e.g. analysis replay)
-		// or when there are no confirmed tracks to persist.
-		var (
-			dbTx     *sqlite.SQLTx
-			frameID  string
-			txFailed bool
-		)
-		if len(confirmedTracks) > 0 && cfg.DB != nil && (cfg.DisableTrackPersistence == nil || !cfg.DisableTrackPersistence.Load()) {
-			frameID = fmt.Sprintf("site/%s", sensorID)
-			if tx, txErr := cfg.DB.Begin(); txErr != nil {
-				opsf("Failed to begin track persistence tx: %v", txErr)
-			} else {
-				dbTx = tx
-			}
-		}
+		// Track and observation writes are gathered during the loop and
+		// committed in one per-frame transaction afterwards. Skip entirely
+		// when DisableTrackPersistence is set (e.g. analysis replay) or when
+		// there are no confirmed tracks to persist.
+		persist := len(confirmedTracks) > 0 && cfg.DB != nil && (cfg.DisableTrackPersistence == nil || !cfg.DisableTrackPersistence.Load())
+		frameID := fmt.Sprintf("site/%s", sensorID)
+		var observations []*sqlite.TrackObservation
 
 		for _, track := range confirmedTracks {
 			// Re-classify periodically as more observations accumulate.
@@ -630,20 +753,15 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 				runManager.RecordTrack(track)
 			}
 
-			// Persist track to database
-			if dbTx != nil && !txFailed {
-				if err := sqlite.InsertTrack(dbTx, track, frameID); err != nil {
-					opsf("Failed to insert track %s: %v", track.TrackID, err)
-					txFailed = true
-				}
-
+			// Queue the observation for the per-frame transaction
+			if persist {
 				// Only persist observations for tracks that were matched to a
 				// cluster this frame (Misses == 0).  Coasting tracks have
 				// Misses > 0 and their position is a Kalman prediction, not a
 				// real measurement — persisting those creates phantom straight
 				// segments and contaminates quality metrics.
 				if track.Misses == 0 {
-					// Insert observation — use per-frame OBB dimensions (not running
+					// Observation uses per-frame OBB dimensions (not running
 					// averages) so each observation faithfully records the cluster
 					// shape at this instant. The averaged values are stored on the
 					// track record itself for classification/reporting.
@@ -664,21 +782,32 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 						HeightP95:         track.HeightP95Max,
 						IntensityMean:     track.IntensityMeanAvg,
 					}
-					if err := sqlite.InsertTrackObservation(dbTx, obs); err != nil {
-						opsf("Failed to insert observation for track %s: %v", track.TrackID, err)
-						txFailed = true
-					}
+					observations = append(observations, obs)
 				}
 			}
 		}
 
-		if dbTx != nil {
-			if txFailed {
-				if err := dbTx.Rollback(); err != nil {
-					opsf("Failed to rollback track persistence tx: %v", err)
+		if persist {
+			// Retried as a whole if SQLite is busy, but never for longer
+			// than trackPersistTimeout so a contended database cannot stall
+			// frame processing.
+			ctx, cancel := context.WithTimeout(context.Background(), trackPersistTimeout)
+			err := sqlite.WithTx(ctx, cfg.DB, func(tx *sqlite.SQLTx) error {
+				for _, track := range confirmedTracks {
+					if err := sqlite.InsertTrack(tx, track, frameID); err != nil {
+						return fmt.Errorf("insert track %s: %w", track.TrackID, err)
+					}
+				}
+				for _, obs := range observations {
+					if err := sqlite.InsertTrackObservation(tx, obs); err != nil {
+						return fmt.Errorf("insert observation for track %s: %w", obs.TrackID, err)
+					}
 				}
-			} else if err := dbTx.Commit(); err != nil {
-				opsf("Failed to commit track persistence tx: %v", err)
+				return nil
+			})
+			cancel()
+			if err != nil {
+				opsf("Failed to persist tracks: %v", err)
 			}
 		}
 
@@ -686,6 +815,32 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 			diagf("%d confirmed tracks active", len(confirmedTracks))
 		}
 
+		// Emit lifecycle events after classification so confirmed and
+		// ended events carry the latest label.
+		if trackEvents != nil {
+			trackEvents.observe(confirmedTracks)
+		}
+		if cfg.Tripwires != nil {
+			for _, crossing := range cfg.Tripwires.Observe(confirmedTracks) {
+				if crossing.SensorID == "" {
+					crossing.SensorID = sensorID
+				}
+				if crossingSink != nil {
+					crossingSink.PublishCrossing(crossing)
+				}
+			}
+		}
+		if cfg.NearMisses != nil {
+			for _, nearMiss := range cfg.NearMisses.Observe(confirmedTracks) {
+				if nearMiss.SensorID == "" {
+					nearMiss.SensorID = sensorID
+				}
+				if nearMissSink != nil {
+					nearMissSink.PublishNearMiss(nearMiss)
+				}
+			}
+		}
+
 		// Stage 6: Publish to visualiser (if enabled)
 		if ft != nil {
 			ft.Stage("publish")
@@ -694,7 +849,13 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 			// Adapt frame to FrameBundle
 			// Note: Debug collector is integrated in Tracker but requires explicit enablement
 			// via Tracker.SetDebugCollector(). Pass nil here as debug collection is optional.
-			frameBundle := cfg.VisualiserAdapter.AdaptFrame(frame, mask, clusters, cfg.Tracker, nil)
+			// The adapter indexes the mask against frame.Points, so a mask
+			// over ROI-filtered points is expanded back to frame indices.
+			frameMask := mask
+			if roiKept != nil {
+				frameMask = expandMask(mask, roiKept, len(frame.Points))
+			}
+			frameBundle := cfg.VisualiserAdapter.AdaptFrame(frame, frameMask, clusters, cfg.Tracker, nil)
 
 			// Publish to gRPC stream
 			cfg.VisualiserPublisher.Publish(frameBundle)
@@ -731,3 +892,29 @@ func (cfg *TrackingPipelineConfig) NewFrameCallback() func(*l2frames.LiDARFrame)
 		}
 	}
 }
+
+// filterRingROI returns the points inside roi together with each kept
+// point's index in points.
+func filterRingROI(roi *l4perception.RingROI, points []l2frames.PointPolar) ([]l2frames.PointPolar, []int) {
+	out := make([]l2frames.PointPolar, 0, len(points))
+	kept := make([]int, 0, len(points))
+	for i, p := range points {
+		if roi.Contains(p) {
+			out = append(out, p)
+			kept = append(kept, i)
+		}
+	}
+	return out, kept
+}
+
+// expandMask maps a mask over filtered points back onto n frame points.
+// Points the filter dropped are reported as background.
+func expandMask(mask []bool, kept []int, n int) []bool {
+	out := make([]bool, n)
+	for j, i := range kept {
+		if j < len(mask) && i < n {
+			out[i] = mask[j]
+		}
+	}
+	return out
+}
@@ -504,10 +504,12 @@ func (m *mockVisualiserPublisher) Publish(frame interface{}) {
 type mockVisualiserAdapter struct {
 	adaptCalls      int
 	adaptEmptyCalls int
+	lastMask        []bool
 }
 
 func (m *mockVisualiserAdapter) AdaptFrame(frame *l2frames.LiDARFrame, foregroundMask []bool, clusters []l4perception.WorldCluster, tracker l5tracks.TrackerInterface, debugFrame interface{}) interface{} {
 	m.adaptCalls++
+	m.lastMask = foregroundMask
 	return struct{}{}
 }
 
@@ -1113,6 +1115,11 @@ func (m *mockTrackerCov) Update(clusters []l5tracks.WorldCluster, timestamp time
 func (m *mockTrackerCov) GetActiveTracks() []*l5tracks.TrackedObject    { return m.activeTracks }
 func (m *mockTrackerCov) GetConfirmedTracks() []*l5tracks.TrackedObject { return m.confirmedTracks }
 func (m *mockTrackerCov) GetTrack(trackID string) *l5tracks.TrackedObject {
+	for _, t := range m.allTracks {
+		if t.TrackID == trackID {
+			return t
+		}
+	}
 	return nil
 }
 func (m *mockTrackerCov) GetTrackCount() (total, tentative, confirmed, deleted int) {
@@ -1954,3 +1961,87 @@ func TestTrackingPipelineConfig_BenchmarkMode_LagDetection(t *testing.T) {
 		t.Errorf("expected [Benchmark] output in lag detection test")
 	}
 }
+
+// TestRingROI_RestrictsClusteringInput checks out-of-band foreground points
+// never reach clustering, and that WholePipeline also keeps them from the
+// foreground forwarder.
+func TestRingROI_RestrictsClusteringInput(t *testing.T) {
+	run := func(roi *l4perception.RingROI) (clusterInput, forwarded int) {
+		t.Helper()
+		bm := l3grid.NewBackgroundManagerDI("test-roi", 40, 1800, l3grid.BackgroundParams{
+			ClosenessSensitivityMultiplier: 3.0,
+			SafetyMarginMetres:             0.5,
+			BackgroundUpdateFraction:       0.02,
+			ForegroundMinClusterPoints:     999, // no clusters: stats record the clustering input
+			ForegroundDBSCANEps:            2.0,
+		}, nil)
+		for i := range bm.Grid.Cells {
+			bm.Grid.Cells[i].AverageRangeMeters = 10.0
+			bm.Grid.Cells[i].TimesSeenCount = 100
+		}
+		bm.HasSettled = true
+
+		tracker := &mockTrackerCov{}
+		fwd := &mockFgForwarderDetailed{}
+		cfg := &TrackingPipelineConfig{
+			BackgroundManager: bm,
+			Tracker:           tracker,
+			FgForwarder:       fwd,
+			RingROI:           roi,
+		}
+		cfg.NewFrameCallback()(clusterFramePrePopulated())
+		return tracker.lastForeground, len(fwd.lastPoints)
+	}
+
+	if in, fwd := run(nil); in != 10 || fwd != 10 {
+		t.Fatalf("without ROI: %d clustered, %d forwarded; want all 10", in, fwd)
+	}
+	if in, fwd := run(&l4perception.RingROI{MaxRing: 4}); in != 4 || fwd != 10 {
+		t.Errorf("clustering ROI: %d clustered, %d forwarded; want 4 and 10", in, fwd)
+	}
+	if in, fwd := run(&l4perception.RingROI{MaxRing: 4, WholePipeline: true}); in != 4 || fwd != 4 {
+		t.Errorf("whole-pipeline ROI: %d clustered, %d forwarded; want 4 and 4", in, fwd)
+	}
+}
+
+// TestRingROI_WholePipelineMaskMatchesFramePoints checks the visualiser gets
+// a mask indexed like frame.Points when the ROI drops rings before
+// background subtraction.
+func TestRingROI_WholePipelineMaskMatchesFramePoints(t *testing.T) {
+	bm := l3grid.NewBackgroundManagerDI("test-roi-mask", 40, 1800, l3grid.BackgroundParams{
+		ClosenessSensitivityMultiplier: 3.0,
+		SafetyMarginMetres:             0.5,
+		BackgroundUpdateFraction:       0.02,
+		ForegroundMinClusterPoints:     2,
+		ForegroundDBSCANEps:            2.0,
+	}, nil)
+	for i := range bm.Grid.Cells {
+		bm.Grid.Cells[i].AverageRangeMeters = 10.0
+		bm.Grid.Cells[i].TimesSeenCount = 100
+	}
+	bm.HasSettled = true
+
+	adapter := &mockVisualiserAdapter{}
+	cfg := &TrackingPipelineConfig{
+		BackgroundManager:   bm,
+		Tracker:             &mockTrackerCov{},
+		VisualiserAdapter:   adapter,
+		VisualiserPublisher: &mockVisualiserPublisher{},
+		// Drop rings 1-6 so the kept points do not start at index 0.
+		RingROI: &l4perception.RingROI{MinRing: 7, WholePipeline: true},
+	}
+	frame := clusterFramePrePopulated()
+	cfg.NewFrameCallback()(frame)
+
+	if adapter.adaptCalls != 1 {
+		t.Fatalf("AdaptFrame called %d times, want 1", adapter.adaptCalls)
+	}
+	if len(adapter.lastMask) != len(frame.Points) {
+		t.Fatalf("mask has %d entries for %d frame points", len(adapter.lastMask), len(frame.Points))
+	}
+	for i, fg := range adapter.lastMask {
+		if want := frame.Points[i].Channel >= 7; fg != want {
+			t.Errorf("point %d (ring %d) foreground = %v, want %v", i, frame.Points[i].Channel, fg, want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
+	"github.com/google/uuid"
+)
+
+// One-shot PCAP analysis API.
+//
+// Routes:
+// - POST /api/lidar/analyze — queue an analysis of a PCAP, returns the run ID
+// - GET /api/lidar/analyze/{run_id} — run status and replay progress
+//
+// A queued run is recorded in lidar_run_records as "pending" while it waits
+// for the replay slot (a sweep or another replay may hold it), becomes
+// "running" when the analysis replay starts and ends "completed" or
+// "failed". The monitor returns to live data once the replay finishes.
+
+var startAnalysisReplay = func(ws *Server, pcapFile string, config ReplayConfig, beforeStart func() error) error {
+	return ws.startPCAPWhenFree(pcapFile, config, 0, beforeStart)
+}
+
+// analyzeRequest is the POST /api/lidar/analyze body. Params is a runtime
+// tuning patch in the form accepted by POST /api/lidar/params; it is applied
+// for the duration of the replay and recorded as the run's requested params.
+type analyzeRequest struct {
+	PCAPFile        string          `json:"pcap_file"`
+	Params          json.RawMessage `json:"params,omitempty"`
+	StartSeconds    float64         `json:"start_seconds"`
+	DurationSeconds *float64        `json:"duration_seconds,omitempty"`
+}
+
+// handleAnalyze queues a one-shot analysis replay and returns its run ID
+// without waiting for the replay to start.
+func (ws *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	var req analyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if errors.Is(err, io.EOF) {
+			ws.writeJSONError(w, http.StatusBadRequest, "request body is missing: send JSON with pcap_file")
+			return
+		}
+		ws.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("the request body is not valid JSON: %v", err))
+		return
+	}
+	if strings.TrimSpace(req.PCAPFile) == "" {
+		ws.writeJSONError(w, http.StatusBadRequest, "missing 'pcap_file' in request body")
+		return
+	}
+	if req.StartSeconds < 0 {
+		ws.writeJSONError(w, http.StatusBadRequest, "start_seconds must not be negative")
+		return
+	}
+	durationSeconds := -1.0
+	if req.DurationSeconds != nil {
+		durationSeconds = *req.DurationSeconds
+	}
+
+	resolvedPath, err := ws.resolvePCAPPath(req.PCAPFile)
+	if err != nil {
+		var sErr *switchError
+		if errors.As(err, &sErr) {
+			ws.writeJSONError(w, sErr.status, sErr.Error())
+		} else {
+			ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	patch, err := ws.validateAnalyzeParams(req.Params)
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid params: %v", err))
+		return
+	}
+
+	runID := "analyze-" + uuid.NewString()
+	store := sqlite.NewAnalysisRunStore(ws.db)
+	if err := store.InsertRun(&sqlite.AnalysisRun{
+		RunID:      runID,
+		CreatedAt:  time.Now(),
+		SourceType: "pcap",
+		SourcePath: resolvedPath,
+		SensorID:   ws.sensorID,
+		Status:     "pending",
+	}); err != nil {
+		ws.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("could not record analysis run: %v", err))
+		return
+	}
+
+	config := ReplayConfig{
+		StartSeconds:    req.StartSeconds,
+		DurationSeconds: durationSeconds,
+		SpeedMode:       "analysis",
+		SpeedRatio:      1.0,
+		AnalysisMode:    true,
+		SensorID:        ws.sensorID,
+		PreferredRunID:  runID,
+	}
+	if len(patch) > 0 {
+		config.RequestedParamsJSON = req.Params
+	}
+	go ws.runAnalysisJob(runID, req.PCAPFile, config, patch)
+
+	ws.writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"run_id":     runID,
+		"status":     "pending",
+		"pcap_file":  req.PCAPFile,
+		"status_url": ws.basePath + "/api/lidar/analyze/" + runID,
+	})
+}
+
+// validateAnalyzeParams normalises a tuning patch and checks it against a
+// copy of the runtime config, so a bad patch is rejected before a run is
+// queued. An absent or null patch is valid and returns nil.
+func (ws *Server) validateAnalyzeParams(raw json.RawMessage) (map[string]interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, fmt.Errorf("params must be a JSON object: %w", err)
+	}
+	patch, err := normaliseTuningPatch(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	if l3grid.GetBackgroundManager(ws.sensorID) == nil {
+		return nil, fmt.Errorf("no background manager for sensor %s", ws.sensorID)
+	}
+
+	cfg := ws.runtimeTuningConfig(nil)
+	editable := 0
+	for path, value := range patch {
+		if validateRuntimeTuningPath(path) != nil {
+			continue
+		}
+		if err := setConfigValueByPath(cfg, path, value); err != nil {
+			return nil, err
+		}
+		editable++
+	}
+	if editable == 0 {
+		return nil, fmt.Errorf("no runtime-editable parameters in patch")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// runAnalysisJob waits for the replay slot, applies the tuning patch, runs
+// the analysis replay to completion and restores the previous tuning and
+// the live listener. The replay marks the run running, then completed or
+// failed; a run that never starts is marked failed here.
+func (ws *Server) runAnalysisJob(runID, pcapFile string, config ReplayConfig, patch map[string]interface{}) {
+	var restoreTuning func()
+	beforeStart := func() error {
+		if len(patch) == 0 {
+			return nil
+		}
+		restore, err := ws.applyAnalysisTuning(patch, DataSourcePCAP)
+		if err != nil {
+			return fmt.Errorf("apply params: %w", err)
+		}
+		restoreTuning = restore
+		return nil
+	}
+
+	err := startAnalysisReplay(ws, pcapFile, config, beforeStart)
+	if err != nil {
+		opsf("[Analyze] run %s did not start: %v", runID, err)
+		ws.failQueuedAnalysisRun(runID, err.Error())
+	} else {
+		diagf("[Analyze] run %s started for %s", runID, pcapFile)
+		if done := ws.PCAPDone(); done != nil {
+			<-done
+		}
+		if stopErr := ws.StopPCAPForSweep(); stopErr != nil {
+			opsf("[Analyze] run %s: could not restore live data source: %v", runID, stopErr)
+		}
+	}
+
+	if restoreTuning != nil {
+		restoreTuning()
+	}
+}
+
+// applyAnalysisTuning applies patch to the runtime tuning and returns a
+// function that puts the patched paths back to their previous values. It
+// runs under dataSourceMu, so the source is passed in rather than read.
+func (ws *Server) applyAnalysisTuning(patch map[string]interface{}, source DataSource) (func(), error) {
+	bm := l3grid.GetBackgroundManager(ws.sensorID)
+	if bm == nil {
+		return nil, fmt.Errorf("no background manager for sensor %s", ws.sensorID)
+	}
+
+	raw, err := json.Marshal(ws.runtimeTuningConfigForSource(bm, source))
+	if err != nil {
+		return nil, err
+	}
+	var nested map[string]interface{}
+	if err := json.Unmarshal(raw, &nested); err != nil {
+		return nil, err
+	}
+	previous, err := normaliseTuningPatch(nested)
+	if err != nil {
+		return nil, err
+	}
+	restorePatch := make(map[string]interface{}, len(patch))
+	for path := range patch {
+		if value, ok := previous[path]; ok {
+			restorePatch[path] = value
+		}
+	}
+
+	if err := applyRuntimeTuningPatchForSource(ws, bm, patch, source); err != nil {
+		return nil, err
+	}
+	return func() {
+		if len(restorePatch) == 0 {
+			return
+		}
+		if err := applyRuntimeTuningPatch(ws, bm, restorePatch); err != nil {
+			opsf("[Analyze] could not restore tuning after analysis: %v", err)
+		}
+	}, nil
+}
+
+// failQueuedAnalysisRun marks a run failed when its replay could not start.
+func (ws *Server) failQueuedAnalysisRun(runID, errMsg string) {
+	store := sqlite.NewAnalysisRunStore(ws.db)
+	if err := store.UpdateRunStatus(runID, "failed", errMsg); err != nil {
+		opsf("[Analyze] could not mark run %s as failed: %v", runID, err)
+	}
+}
+
+// analyzeProgress is the live replay progress of a running analysis.
+type analyzeProgress struct {
+	CurrentPacket uint64  `json:"current_packet"`
+	TotalPackets  uint64  `json:"total_packets"`
+	Fraction      float64 `json:"fraction"`
+	Frames        int     `json:"frames"`
+	Tracks        int     `json:"tracks"`
+}
+
+// handleAnalyzeStatus reports a run's status, its stored statistics and,
+// while it is the active replay, packet and frame progress.
+func (ws *Server) handleAnalyzeStatus(w http.ResponseWriter, r *http.Request) {
+	runID := strings.TrimRight(strings.TrimPrefix(r.URL.Path, "/api/lidar/analyze/"), "/")
+	if runID == "" {
+		ws.writeJSONError(w, http.StatusBadRequest, "missing run_id in path")
+		return
+	}
+
+	store := sqlite.NewAnalysisRunStore(ws.db)
+	run, err := store.GetRun(runID)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			ws.writeJSONError(w, http.StatusNotFound, "run not found")
+		} else {
+			ws.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("could not retrieve run: %v", err))
+		}
+		return
+	}
+
+	resp := map[string]interface{}{
+		"run_id":      run.RunID,
+		"status":      run.Status,
+		"source_path": run.SourcePath,
+		"sensor_id":   run.SensorID,
+		"created_at":  run.CreatedAt,
+	}
+	if run.CompletedAt != nil {
+		resp["completed_at"] = run.CompletedAt
+	}
+	if run.ErrorMessage != "" {
+		resp["error"] = run.ErrorMessage
+	}
+	switch run.Status {
+	case "running":
+		if progress := ws.analyzeProgress(runID); progress != nil {
+			resp["progress"] = progress
+		}
+	case "completed":
+		resp["duration_secs"] = run.DurationSecs
+		resp["total_frames"] = run.TotalFrames
+		resp["total_clusters"] = run.TotalClusters
+		resp["total_tracks"] = run.TotalTracks
+		resp["processing_time_ms"] = run.ProcessingTimeMs
+	}
+	ws.writeJSON(w, http.StatusOK, resp)
+}
+
+// analyzeProgress returns the replay progress of runID if it is the active
+// analysis replay, or nil.
+func (ws *Server) analyzeProgress(runID string) *analyzeProgress {
+	ws.pcapMu.Lock()
+	active := ws.pcapInProgress && ws.pcapLastRunID == runID
+	current, total := ws.pcapCurrentPacket, ws.pcapTotalPackets
+	ws.pcapMu.Unlock()
+	if !active {
+		return nil
+	}
+
+	progress := &analyzeProgress{CurrentPacket: current, TotalPackets: total}
+	if total > 0 {
+		progress.Fraction = float64(current) / float64(total)
+	}
+	if ws.analysisRunManager != nil {
+		if id, frames, tracks := ws.analysisRunManager.CurrentRunProgress(); id == runID {
+			progress.Frames, progress.Tracks = frames, tracks
+		}
+	}
+	return progress
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cfgpkg "github.com/banshee-data/velocity.report/internal/config"
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
+)
+
+func setupAnalyzeServer(t *testing.T, sensorID string) (*Server, *http.ServeMux) {
+	t.Helper()
+	restore := restoreDatasourceHandlerSeams()
+	t.Cleanup(restore)
+
+	dbWrapped, cleanupDB := setupTestDBWrapped(t)
+	t.Cleanup(cleanupDB)
+	_ = l3grid.NewBackgroundManager(sensorID, 16, 360, l3grid.DefaultBackgroundConfig().ToBackgroundParams(), nil)
+	t.Cleanup(func() { l3grid.RegisterBackgroundManager(sensorID, nil) })
+
+	tmpDir := resolveSymlinks(t, t.TempDir())
+	if err := os.WriteFile(filepath.Join(tmpDir, "street.pcap"), testPCAPHeader, 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	countPCAPPackets = func(string, int) (network.PCAPCountResult, error) {
+		return network.PCAPCountResult{Count: 10}, nil
+	}
+
+	ws := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		SensorID:          sensorID,
+		DB:                dbWrapped,
+		PCAPSafeDir:       tmpDir,
+		Parser:            &mockTimestampParser{},
+		UDPListenerConfig: network.UDPListenerConfig{Address: "127.0.0.1:0"},
+	})
+	ws.storeTuningConfig(cfgpkg.MustLoadDefaultConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ws.setBaseContext(ctx)
+
+	mux := http.NewServeMux()
+	ws.RegisterRoutes(mux)
+	return ws, mux
+}
+
+func postAnalyze(t *testing.T, mux *http.ServeMux, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/lidar/analyze", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func getAnalyzeStatus(t *testing.T, mux *http.ServeMux, runID string) map[string]interface{} {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/lidar/analyze/"+runID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	return resp
+}
+
+func waitForAnalyzeStatus(t *testing.T, mux *http.ServeMux, runID, want string) map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp := getAnalyzeStatus(t, mux, runID)
+		if resp["status"] == want {
+			return resp
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run %s status %v, want %s", runID, resp["status"], want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHandleAnalyze_RunLifecycle(t *testing.T) {
+	const sensorID = "analyze-lifecycle"
+	ws, mux := setupAnalyzeServer(t, sensorID)
+	bm := l3grid.GetBackgroundManager(sensorID)
+	if err := applyRuntimeTuningPatch(ws, bm, map[string]interface{}{
+		"l3.ema_baseline_v1.closeness_multiplier": 2.5,
+	}); err != nil {
+		t.Fatalf("seed tuning: %v", err)
+	}
+
+	entered := make(chan float32, 1)
+	release := make(chan struct{})
+	readPCAPFile = func(_ context.Context, _ string, _ int, _ network.Parser, _ network.FrameBuilder,
+		_ network.PacketStatsInterface, _ *network.PacketForwarder, _, _ float64, _, _ uint64,
+		onProgress func(current, total uint64)) error {
+		onProgress(4, 10)
+		entered <- bm.GetParams().ClosenessSensitivityMultiplier
+		<-release
+		onProgress(10, 10)
+		return nil
+	}
+
+	rec := postAnalyze(t, mux, `{"pcap_file":"street.pcap","params":{"l3":{"ema_baseline_v1":{"closeness_multiplier":4}}}}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST = %d: %s", rec.Code, rec.Body.String())
+	}
+	var accepted map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &accepted); err != nil {
+		t.Fatalf("decode POST: %v", err)
+	}
+	runID, _ := accepted["run_id"].(string)
+	if runID == "" || accepted["status"] != "pending" {
+		t.Fatalf("POST response = %v", accepted)
+	}
+
+	select {
+	case closeness := <-entered:
+		if closeness != 4 {
+			t.Errorf("closeness multiplier during replay = %v, want 4", closeness)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("replay did not start")
+	}
+
+	running := getAnalyzeStatus(t, mux, runID)
+	if running["status"] != "running" {
+		t.Fatalf("status during replay = %v", running["status"])
+	}
+	progress, _ := running["progress"].(map[string]interface{})
+	if progress == nil || progress["current_packet"] != 4.0 || progress["fraction"] != 0.4 {
+		t.Errorf("progress = %v", running["progress"])
+	}
+
+	close(release)
+	done := waitForAnalyzeStatus(t, mux, runID, "completed")
+	if _, ok := done["completed_at"]; !ok {
+		t.Errorf("completed run has no completed_at: %v", done)
+	}
+
+	run, err := sqlite.NewAnalysisRunStore(ws.db).GetRun(runID)
+	if err != nil {
+		t.Fatalf("GetRun: %v", err)
+	}
+	if run.RequestedParamSetID == "" {
+		t.Error("requested params were not recorded on the run")
+	}
+
+	// The job restores live mode and the previous tuning once the replay ends.
+	deadline := time.Now().Add(10 * time.Second)
+	for ws.CurrentSource() != DataSourceLive || bm.GetParams().ClosenessSensitivityMultiplier != 2.5 {
+		if time.Now().After(deadline) {
+			t.Fatalf("after analysis: source %s, closeness %v", ws.CurrentSource(), bm.GetParams().ClosenessSensitivityMultiplier)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHandleAnalyze_ReplayErrorFailsRun(t *testing.T) {
+	_, mux := setupAnalyzeServer(t, "analyze-replay-error")
+	readPCAPFile = func(_ context.Context, _ string, _ int, _ network.Parser, _ network.FrameBuilder,
+		_ network.PacketStatsInterface, _ *network.PacketForwarder, _, _ float64, _, _ uint64,
+		_ func(current, total uint64)) error {
+		return errors.New("truncated capture")
+	}
+
+	rec := postAnalyze(t, mux, `{"pcap_file":"street.pcap"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST = %d: %s", rec.Code, rec.Body.String())
+	}
+	var accepted map[string]string
+	_ = json.Unmarshal(rec.Body.Bytes(), &accepted)
+
+	failed := waitForAnalyzeStatus(t, mux, accepted["run_id"], "failed")
+	if failed["error"] != "truncated capture" {
+		t.Errorf("error = %v", failed["error"])
+	}
+}
+
+func TestHandleAnalyze_StartErrorFailsPendingRun(t *testing.T) {
+	_, mux := setupAnalyzeServer(t, "analyze-start-error")
+	orig := startAnalysisReplay
+	t.Cleanup(func() { startAnalysisReplay = orig })
+	startAnalysisReplay = func(*Server, string, ReplayConfig, func() error) error {
+		return errors.New("timeout waiting for PCAP replay slot")
+	}
+
+	rec := postAnalyze(t, mux, `{"pcap_file":"street.pcap"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST = %d: %s", rec.Code, rec.Body.String())
+	}
+	var accepted map[string]string
+	_ = json.Unmarshal(rec.Body.Bytes(), &accepted)
+
+	failed := waitForAnalyzeStatus(t, mux, accepted["run_id"], "failed")
+	if failed["error"] != "timeout waiting for PCAP replay slot" {
+		t.Errorf("error = %v", failed["error"])
+	}
+}
+
+func TestHandleAnalyze_Validation(t *testing.T) {
+	_, mux := setupAnalyzeServer(t, "analyze-validation")
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"empty body", ``, http.StatusBadRequest},
+		{"bad JSON", `{`, http.StatusBadRequest},
+		{"missing pcap_file", `{}`, http.StatusBadRequest},
+		{"negative start", `{"pcap_file":"street.pcap","start_seconds":-1}`, http.StatusBadRequest},
+		{"missing file", `{"pcap_file":"absent.pcap"}`, http.StatusNotFound},
+		{"escapes safe dir", `{"pcap_file":"../street.pcap"}`, http.StatusNotFound},
+		{"params not an object", `{"pcap_file":"street.pcap","params":[1]}`, http.StatusBadRequest},
+		{"no editable params", `{"pcap_file":"street.pcap","params":{"l1":{"sensor":"x"}}}`, http.StatusBadRequest},
+		{"invalid param value", `{"pcap_file":"street.pcap","params":{"l3":{"ema_baseline_v1":{"closeness_multiplier":"high"}}}}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postAnalyze(t, mux, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("POST = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/lidar/analyze/no-such-run", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET unknown run = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// normaliseBasePath turns a configured base path into the form the server
+// uses internally: a leading slash and no trailing slash ("sensor1/" becomes
+// "/sensor1"). An empty path or "/" means the server is at the root and
+// normalises to "".
+func normaliseBasePath(p string) string {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// withBasePath mounts next under basePath, extending the http.StripPrefix
+// pattern used for the static assets to the whole route tree: a request for
+// basePath+"/api/lidar/..." reaches the handler registered for
+// "/api/lidar/...". A request for basePath itself is redirected to
+// basePath+"/" so relative links resolve. Requests without the prefix still
+// reach next unchanged, so a proxy that strips the prefix before forwarding
+// (nginx "proxy_pass http://host:8081/;") works as well as one that keeps it.
+func withBasePath(basePath string, next http.Handler) http.Handler {
+	if basePath == "" {
+		return next
+	}
+	prefixed := http.StripPrefix(basePath, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == basePath:
+			target := basePath + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, basePath+"/"):
+			prefixed.ServeHTTP(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// assetsHost returns the URL prefix of the embedded static assets, including
+// the base path, for pages that load scripts and styles from them.
+func (ws *Server) assetsHost() string {
+	return ws.basePath + echartsAssetsPrefix
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+)
+
+func TestNormaliseBasePath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"/", ""},
+		{"  ", ""},
+		{"sensor1", "/sensor1"},
+		{"/sensor1", "/sensor1"},
+		{"/sensor1/", "/sensor1"},
+		{" /site/sensor1/ ", "/site/sensor1"},
+	}
+	for _, tt := range tests {
+		if got := normaliseBasePath(tt.in); got != tt.want {
+			t.Errorf("normaliseBasePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func newBasePathTestServer(t *testing.T, basePath string) *httptest.Server {
+	t.Helper()
+	ws := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		SensorID:          "base-path-sensor",
+		UDPListenerConfig: network.UDPListenerConfig{Address: ":0"},
+		BasePath:          basePath,
+	})
+	ts := httptest.NewServer(ws.server.Handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func getBody(t *testing.T, client *http.Client, url string) (int, string, http.Header) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", url, err)
+	}
+	return resp.StatusCode, string(body), resp.Header
+}
+
+func TestBasePath_RoutesThroughPrefixedMux(t *testing.T) {
+	ts := newBasePathTestServer(t, "sensor1/")
+	client := ts.Client()
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"api route", "/sensor1/health", http.StatusOK},
+		{"static asset", "/sensor1/assets/common.css", http.StatusOK},
+		{"status page", "/sensor1/lidar/server", http.StatusOK},
+		{"root of prefix", "/sensor1/", http.StatusOK},
+		{"prefix stripped by proxy", "/health", http.StatusOK},
+		{"prefix is not a bare string match", "/sensor1x/health", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body, _ := getBody(t, client, ts.URL+tt.path)
+			if code != tt.want {
+				t.Errorf("GET %s: status = %d, want %d; body: %s", tt.path, code, tt.want, body)
+			}
+		})
+	}
+}
+
+func TestBasePath_RedirectsBarePrefix(t *testing.T) {
+	ts := newBasePathTestServer(t, "/sensor1")
+	client := ts.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	code, _, header := getBody(t, client, ts.URL+"/sensor1?sensor_id=a")
+	if code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", code, http.StatusMovedPermanently)
+	}
+	if loc := header.Get("Location"); loc != "/sensor1/?sensor_id=a" {
+		t.Errorf("Location = %q, want /sensor1/?sensor_id=a", loc)
+	}
+}
+
+func TestBasePath_GeneratedLinksCarryPrefix(t *testing.T) {
+	ts := newBasePathTestServer(t, "/sensor1")
+	client := ts.Client()
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/sensor1/lidar/server", []string{
+			`href="/sensor1/assets/common.css"`,
+			`href="/sensor1/lidar/sensors"`,
+			`href="/sensor1/debug/lidar?sensor_id=base-path-sensor"`,
+		}},
+		{"/sensor1/lidar/sensors", []string{
+			`href="/sensor1/assets/common.css"`,
+			`href="/sensor1/assets/status_dashboard.css"`,
+		}},
+		{"/sensor1/debug/lidar", []string{
+			`href="/sensor1/assets/dashboard.css"`,
+			`src="/sensor1/debug/lidar/tracks?sensor_id=base-path-sensor"`,
+		}},
+		{"/sensor1/debug/lidar/sweep", []string{
+			`<meta name="base-path" content="/sensor1" />`,
+			`src="/sensor1/assets/sweep_dashboard.js"`,
+		}},
+		{"/sensor1/debug/lidar/background/regions/dashboard", []string{
+			`<meta name="base-path" content="/sensor1" />`,
+			`src="/sensor1/assets/regions_dashboard.js"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			code, body, _ := getBody(t, client, ts.URL+tt.path)
+			if code != http.StatusOK {
+				t.Fatalf("status = %d; body: %s", code, body)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %s", want)
+				}
+			}
+		})
+	}
+}
+
+func TestBasePath_EmptyLeavesLinksAtRoot(t *testing.T) {
+	ts := newBasePathTestServer(t, "")
+	code, body, _ := getBody(t, ts.Client(), ts.URL+"/lidar/server")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", code, body)
+	}
+	if !strings.Contains(body, `href="/assets/common.css"`) {
+		t.Error("status page should link assets at the root when no base path is set")
+	}
+}
@@ -7,10 +7,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"time"
 
+	"github.com/banshee-data/velocity.report/internal/lidar/adapters/httpretry"
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
 	"github.com/banshee-data/velocity.report/internal/lidar/sweep"
 )
 
@@ -21,7 +22,11 @@ type Client struct {
 	SensorID   string
 }
 
-// NewClient creates a new monitoring client.
+// NewClient creates a new monitoring client. A nil httpClient gets a plain
+// client with a 30 s timeout; long-running tools pass one built by
+// httpretry.NewClient so transient failures are retried. Requests that set
+// state (parameters, resets, stopping a replay) are marked idempotent so
+// they are retried too; starting a replay is not.
 func NewClient(httpClient *http.Client, baseURL, sensorID string) *Client {
 	if httpClient == nil {
 		httpClient = &http.Client{Timeout: 30 * time.Second}
@@ -39,49 +44,57 @@ func NewClient(httpClient *http.Client, baseURL, sensorID string) *Client {
 // paths (relative to the server's configured PCAP safe directory); the server
 // may reject absolute paths that fall outside its safe directory.
 func (c *Client) StartPCAPReplay(pcapFile string, maxRetries int) error {
-	url := fmt.Sprintf("%s/api/lidar/pcap/start?sensor_id=%s", c.BaseURL, c.SensorID)
-	// Use the full path as-is (relative to PCAP safe directory on the server)
-	payload := map[string]string{"pcap_file": pcapFile}
-	data, _ := json.Marshal(payload)
-
-	diagf("Requesting PCAP replay for sensor %s: file=%s", c.SensorID, pcapFile)
+	return c.StartPCAPReplayWithConfig(PCAPReplayConfig{PCAPFile: pcapFile, MaxRetries: maxRetries})
+}
 
-	if maxRetries <= 0 {
-		maxRetries = 60
+// post sends data (JSON, or no body when nil) to url and returns an error
+// unless the server answers 200 OK. Every endpoint it is used for sets
+// state, so repeating the request is harmless and it is marked idempotent.
+func (c *Client) post(url string, data []byte) error {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
 	}
-
-	for retry := 0; retry < maxRetries; retry++ {
-		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
-		if err != nil {
-			return fmt.Errorf("creating request: %w", err)
-		}
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	httpretry.Idempotent(req)
+	if data != nil {
 		req.Header.Set("Content-Type", "application/json")
+	}
 
-		resp, err := c.HTTPClient.Do(req)
-		if err != nil {
-			return err
-		}
-
-		if resp.StatusCode == http.StatusOK {
-			resp.Body.Close()
-			return nil
-		}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
 
-		if resp.StatusCode == http.StatusConflict {
-			if retry == 0 {
-				diagf("PCAP replay in progress, waiting...")
-			}
-			time.Sleep(5 * time.Second)
-			continue
-		}
+// getJSON fetches url and decodes its JSON object body.
+func (c *Client) getJSON(url string) (map[string]interface{}, error) {
+	resp, err := c.HTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
 	}
 
-	return fmt.Errorf("timeout waiting for PCAP replay slot")
+	var m map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
 // DefaultBuckets returns the default bucket configuration.
@@ -120,11 +133,7 @@ func (c *Client) FetchBuckets() []string {
 	for _, bi := range bm {
 		switch v := bi.(type) {
 		case float64:
-			if v == math.Trunc(v) {
-				buckets = append(buckets, fmt.Sprintf("%.0f", v))
-			} else {
-				buckets = append(buckets, fmt.Sprintf("%.6f", v))
-			}
+			buckets = append(buckets, l3grid.RangeBucket(v).String())
 		default:
 			buckets = append(buckets, fmt.Sprintf("%v", v))
 		}
@@ -134,22 +143,7 @@ func (c *Client) FetchBuckets() []string {
 
 // ResetGrid resets the background grid for the sensor.
 func (c *Client) ResetGrid() error {
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/lidar/grid_reset?sensor_id=%s", c.BaseURL, c.SensorID), nil)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
-	}
-	return nil
+	return c.post(fmt.Sprintf("%s/api/lidar/grid_reset?sensor_id=%s", c.BaseURL, c.SensorID), nil)
 }
 
 // BackgroundParams holds the parameters for the background model.
@@ -200,23 +194,9 @@ func (p *BackgroundParams) UnmarshalJSON(data []byte) error {
 // SetParams sets the background model parameters.
 func (c *Client) SetParams(params BackgroundParams) error {
 	data, _ := json.Marshal(params)
-
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/lidar/params?sensor_id=%s", c.BaseURL, c.SensorID), bytes.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
+	if err := c.post(fmt.Sprintf("%s/api/lidar/params?sensor_id=%s", c.BaseURL, c.SensorID), data); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
-	}
 
 	diagf("Applied: noise=%.4f, closeness=%.2f, neighbour=%d, seed=%v",
 		params.NoiseRelative, params.ClosenessMultiplier,
@@ -232,23 +212,9 @@ func (c *Client) SetTuningParams(params map[string]interface{}) error {
 		return fmt.Errorf("marshal tuning params: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/api/lidar/params?sensor_id=%s", c.BaseURL, c.SensorID)
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
+	if err := c.post(fmt.Sprintf("%s/api/lidar/params?sensor_id=%s", c.BaseURL, c.SensorID), data); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
-	}
 
 	diagf("Applied tuning params: %s", string(data))
 	return nil
@@ -334,22 +300,9 @@ func (c *Client) StartPCAPReplayWithConfig(cfg PCAPReplayConfig) error {
 
 // StopPCAPReplay stops any running PCAP replay for this sensor.
 func (c *Client) StopPCAPReplay() error {
-	url := fmt.Sprintf("%s/api/lidar/pcap/stop?sensor_id=%s", c.BaseURL, c.SensorID)
-	req, err := http.NewRequest(http.MethodPost, url, nil)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
+	if err := c.post(fmt.Sprintf("%s/api/lidar/pcap/stop?sensor_id=%s", c.BaseURL, c.SensorID), nil); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
-	}
 
 	diagf("Stopped PCAP replay for sensor %s", c.SensorID)
 	return nil
@@ -419,6 +372,7 @@ func (c *Client) ResetAcceptance() error {
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
+	httpretry.Idempotent(req)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -455,51 +409,20 @@ func (c *Client) WaitForGridSettle(timeout time.Duration) {
 
 // FetchAcceptanceMetrics fetches acceptance metrics from the server.
 func (c *Client) FetchAcceptanceMetrics() (map[string]interface{}, error) {
-	resp, err := c.HTTPClient.Get(fmt.Sprintf("%s/api/lidar/acceptance?sensor_id=%s", c.BaseURL, c.SensorID))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var m map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
-		return nil, err
-	}
-	return m, nil
+	return c.getJSON(fmt.Sprintf("%s/api/lidar/acceptance?sensor_id=%s", c.BaseURL, c.SensorID))
 }
 
 // FetchGridStatus fetches the grid status from the server.
 func (c *Client) FetchGridStatus() (map[string]interface{}, error) {
-	resp, err := c.HTTPClient.Get(fmt.Sprintf("%s/api/lidar/grid_status?sensor_id=%s", c.BaseURL, c.SensorID))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var gs map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&gs); err != nil {
-		return nil, err
-	}
-	return gs, nil
+	return c.getJSON(fmt.Sprintf("%s/api/lidar/grid_status?sensor_id=%s", c.BaseURL, c.SensorID))
 }
 
 // FetchTrackingMetrics fetches velocity-trail alignment metrics from the server.
 // Used by the sweep tool to evaluate tracking parameter quality.
 func (c *Client) FetchTrackingMetrics() (map[string]interface{}, error) {
-	resp, err := c.HTTPClient.Get(fmt.Sprintf("%s/api/lidar/tracks/metrics?include_per_track=false", c.BaseURL))
+	m, err := c.getJSON(fmt.Sprintf("%s/api/lidar/tracks/metrics?include_per_track=false", c.BaseURL))
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("tracking metrics returned %d: %s", resp.StatusCode, string(body))
-	}
-
-	var m map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("tracking metrics: %w", err)
 	}
 	return m, nil
 }
@@ -567,27 +490,12 @@ func (c *Client) SetTrackerConfig(params TrackingParams) error {
 		params.ProcessNoiseVel == nil && params.MeasurementNoise == nil {
 		return fmt.Errorf("SetTrackerConfig: no parameters set")
 	}
-	url := fmt.Sprintf("%s/api/lidar/params?sensor_id=%s", c.BaseURL, c.SensorID)
 	data, err := json.Marshal(params)
 	if err != nil {
 		return fmt.Errorf("marshal tracker config: %w", err)
 	}
-
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("set tracker config returned %d: %s", resp.StatusCode, string(body))
+	if err := c.post(fmt.Sprintf("%s/api/lidar/params?sensor_id=%s", c.BaseURL, c.SensorID), data); err != nil {
+		return fmt.Errorf("set tracker config: %w", err)
 	}
 	return nil
 }
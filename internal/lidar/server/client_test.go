@@ -8,6 +8,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/adapters/httpretry"
 )
 
 func TestNewClient(t *testing.T) {
@@ -80,8 +82,8 @@ func TestClient_FetchBuckets_WithFractionalValues(t *testing.T) {
 	if len(buckets) != 2 {
 		t.Errorf("Expected 2 buckets, got %d", len(buckets))
 	}
-	if buckets[0] != "1.500000" {
-		t.Errorf("First bucket should be '1.500000', got %s", buckets[0])
+	if buckets[0] != "1.5" {
+		t.Errorf("First bucket should be '1.5', got %s", buckets[0])
 	}
 }
 
@@ -1056,3 +1058,38 @@ func TestClient_WaitForPCAPComplete_DefaultTimeoutAndDecodeRetry(t *testing.T) {
 		}
 	})
 }
+
+// TestClient_RetryingTransport checks the sweep tool's client rides out a
+// monitor that answers 503 while restarting, and still reports a rejected
+// parameter set on the first try.
+func TestClient_RetryingTransport(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		var p BackgroundParams
+		json.NewDecoder(r.Body).Decode(&p)
+		switch {
+		case n <= 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case p.NoiseRelative < 0:
+			http.Error(w, "noise_relative must be non-negative", http.StatusBadRequest)
+		case p.NoiseRelative != 0.01:
+			t.Errorf("attempt %d body: noise %v", n, p.NoiseRelative)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(httpretry.NewClient(httpretry.Config{MaxAttempts: 4, InitialBackoff: time.Millisecond}), server.URL, "sensor1")
+	if err := c.SetParams(BackgroundParams{NoiseRelative: 0.01}); err != nil {
+		t.Fatalf("SetParams through a restart: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+
+	calls.Store(10)
+	err := c.SetParams(BackgroundParams{NoiseRelative: -1})
+	if err == nil || !strings.Contains(err.Error(), "status 400") || calls.Load() != 11 {
+		t.Errorf("bad params: err %v after %d calls", err, calls.Load()-10)
+	}
+}
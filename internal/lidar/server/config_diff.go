@@ -0,0 +1,126 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// paramDiff is one parameter whose running value differs from its default.
+// Param is the Go field path, with nested structs joined by dots
+// ("GhostSuppression.MinHits").
+type paramDiff struct {
+	Param   string      `json:"param"`
+	Default interface{} `json:"default"`
+	Current interface{} `json:"current"`
+}
+
+// paramsDiffResponse lists, per config struct, only the parameters that
+// differ from the built-in defaults. Tracker is omitted when no tracker is
+// attached to the server.
+type paramsDiffResponse struct {
+	SensorID   string      `json:"sensor_id"`
+	Background []paramDiff `json:"background"`
+	DBSCAN     []paramDiff `json:"dbscan"`
+	Tracker    []paramDiff `json:"tracker,omitempty"`
+}
+
+// handleParamsDiff returns the background, clustering and tracker
+// parameters that differ from their defaults, with both values, so support
+// can see what was changed on a deployment without reading the full config
+// from /api/lidar/params.
+// Method: GET. Query params: sensor_id (required)
+func (ws *Server) handleParamsDiff(w http.ResponseWriter, r *http.Request) {
+	sensorID := r.URL.Query().Get("sensor_id")
+	if sensorID == "" {
+		ws.writeJSONError(w, http.StatusBadRequest, "missing 'sensor_id' parameter")
+		return
+	}
+	bm := l3grid.GetBackgroundManager(sensorID)
+	if bm == nil || bm.Grid == nil {
+		ws.writeJSONError(w, http.StatusNotFound, "no background manager for sensor")
+		return
+	}
+
+	params := bm.GetParams()
+	resp := paramsDiffResponse{
+		SensorID:   sensorID,
+		Background: diffParams(l3grid.DefaultBackgroundConfig().ToBackgroundParams(), params),
+		DBSCAN:     diffParams(l4perception.DefaultDBSCANParams(), runtimeDBSCANParams(params)),
+	}
+	if ws.tracker != nil {
+		resp.Tracker = diffParams(l5tracks.DefaultTrackerConfig(), ws.tracker.GetConfig())
+	}
+	ws.writeJSON(w, http.StatusOK, resp)
+}
+
+// runtimeDBSCANParams applies the clustering overrides the tracking
+// pipeline takes from the background params to the default DBSCAN params.
+func runtimeDBSCANParams(p l3grid.BackgroundParams) l4perception.DBSCANParams {
+	d := l4perception.DefaultDBSCANParams()
+	if p.ForegroundMinClusterPoints > 0 {
+		d.MinPts = p.ForegroundMinClusterPoints
+	}
+	if p.ForegroundDBSCANEps > 0 {
+		d.Eps = roundTo6(float64(p.ForegroundDBSCANEps))
+	}
+	if p.ForegroundMaxInputPoints > 0 {
+		d.MaxInputPoints = p.ForegroundMaxInputPoints
+	}
+	return d
+}
+
+// diffParams compares two values of the same struct type field by field and
+// returns the fields that differ, recursing into nested structs and non-nil
+// struct pointers. Unexported and func fields are skipped.
+func diffParams(def, cur interface{}) []paramDiff {
+	diffs := []paramDiff{}
+	diffValues("", reflect.ValueOf(def), reflect.ValueOf(cur), &diffs)
+	return diffs
+}
+
+func diffValues(path string, def, cur reflect.Value, diffs *[]paramDiff) {
+	switch def.Kind() {
+	case reflect.Struct:
+		t := def.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || f.Type.Kind() == reflect.Func {
+				continue
+			}
+			name := f.Name
+			if path != "" {
+				name = path + "." + f.Name
+			}
+			diffValues(name, def.Field(i), cur.Field(i), diffs)
+		}
+		return
+	case reflect.Pointer:
+		if !def.IsNil() && !cur.IsNil() && def.Elem().Kind() == reflect.Struct {
+			diffValues(path, def.Elem(), cur.Elem(), diffs)
+			return
+		}
+	}
+	if reflect.DeepEqual(def.Interface(), cur.Interface()) {
+		return
+	}
+	*diffs = append(*diffs, paramDiff{Param: path, Default: diffDisplayValue(def), Current: diffDisplayValue(cur)})
+}
+
+// diffDisplayValue renders durations as strings and float32 values rounded
+// to six places, so the JSON reads as the config was written.
+func diffDisplayValue(v reflect.Value) interface{} {
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Float32:
+		return roundTo6(v.Float())
+	case v.Kind() == reflect.Pointer && v.IsNil():
+		return nil
+	}
+	return v.Interface()
+}
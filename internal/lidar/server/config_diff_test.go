@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func TestDiffParams(t *testing.T) {
+	type inner struct{ A, B int }
+	type cfg struct {
+		Same     int
+		Ratio    float32
+		Grace    time.Duration
+		Nested   inner
+		Optional *inner
+		Missing  *inner
+		List     []string
+		hidden   int
+		Callback func()
+	}
+	def := cfg{Same: 1, Ratio: 0.1, Grace: time.Second, Nested: inner{1, 2}, Optional: &inner{1, 2}, List: []string{"a"}}
+	cur := def
+	cur.Ratio = 0.25
+	cur.Grace = 5 * time.Second
+	cur.Nested.B = 3
+	cur.Optional = &inner{A: 9, B: 2}
+	cur.Missing = &inner{}
+	cur.List = []string{"a", "b"}
+	cur.hidden = 7
+	cur.Callback = func() {}
+
+	got := diffParams(def, cur)
+	want := []paramDiff{
+		{"Ratio", 0.1, 0.25},
+		{"Grace", "1s", "5s"},
+		{"Nested.B", 2, 3},
+		{"Optional.A", 1, 9},
+		{"Missing", nil, &inner{}},
+		{"List", []string{"a"}, []string{"a", "b"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffParams =\n%v\nwant\n%v", got, want)
+	}
+	if d := diffParams(def, def); len(d) != 0 {
+		t.Errorf("identical configs: diff = %v, want none", d)
+	}
+}
+
+func TestHandleParamsDiff(t *testing.T) {
+	sensorID := fmt.Sprintf("params-diff-%d", time.Now().UnixNano())
+	params := l3grid.DefaultBackgroundConfig().ToBackgroundParams()
+	params.ClosenessSensitivityMultiplier += 1
+	params.ForegroundDBSCANEps = 0.9
+	mgr := l3grid.NewBackgroundManager(sensorID, 4, 36, params, nil)
+	l3grid.RegisterBackgroundManager(sensorID, mgr)
+
+	trackerCfg := l5tracks.DefaultTrackerConfig()
+	trackerCfg.HitsToConfirm += 2
+	ws := &Server{tracker: l5tracks.NewTracker(trackerCfg)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/lidar/params/diff", ws.handleParamsDiff)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/lidar/params/diff?sensor_id="+sensorID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", w.Code, w.Body.String())
+	}
+	var resp paramsDiffResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	paramNames := func(diffs []paramDiff) []string {
+		var names []string
+		for _, d := range diffs {
+			names = append(names, d.Param)
+		}
+		return names
+	}
+	if got := paramNames(resp.Background); fmt.Sprint(got) != "[ClosenessSensitivityMultiplier ForegroundDBSCANEps]" {
+		t.Errorf("background diff = %v", resp.Background)
+	}
+	if got := paramNames(resp.DBSCAN); fmt.Sprint(got) != "[Eps]" || resp.DBSCAN[0].Current != 0.9 {
+		t.Errorf("dbscan diff = %v", resp.DBSCAN)
+	}
+	if len(resp.Tracker) != 1 || resp.Tracker[0].Param != "HitsToConfirm" ||
+		resp.Tracker[0].Current != float64(trackerCfg.HitsToConfirm) {
+		t.Errorf("tracker diff = %v", resp.Tracker)
+	}
+
+	for query, want := range map[string]int{
+		"":                   http.StatusBadRequest,
+		"?sensor_id=no-such": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/lidar/params/diff"+query, nil))
+		if w.Code != want {
+			t.Errorf("GET %q: status = %d, want %d", query, w.Code, want)
+		}
+	}
+}
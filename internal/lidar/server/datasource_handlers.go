@@ -31,6 +31,7 @@ var (
 	newForegroundForwarder = network.NewForegroundForwarder
 	absPath                = filepath.Abs
 	statPath               = os.Stat
+	pcapSlotRetryInterval  = 5 * time.Second
 	getReplayFrameBuilder  = func(sensorID string) replayFrameBuilder {
 		fb := l2frames.GetFrameBuilder(sensorID)
 		if fb == nil {
@@ -110,6 +111,22 @@ func (ws *Server) StopPCAPInternal() {
 func (ws *Server) StartPCAPForSweep(pcapFile string, analysisMode bool, speedMode string, speedRatio float64,
 	startSeconds, durationSeconds float64, maxRetries int, disableRecording bool) error {
 
+	return ws.startPCAPWhenFree(pcapFile, ReplayConfig{
+		StartSeconds:     startSeconds,
+		DurationSeconds:  durationSeconds,
+		SpeedMode:        speedMode,
+		SpeedRatio:       speedRatio,
+		AnalysisMode:     analysisMode,
+		DisableRecording: disableRecording,
+		SensorID:         ws.sensorID,
+	}, maxRetries, nil)
+}
+
+// startPCAPWhenFree waits for any PCAP replay to finish, then stops the live
+// listener, resets all state and starts a replay with config. beforeStart,
+// if set, runs under the data source lock once the slot is held; an error
+// from it aborts the start and restores the live listener.
+func (ws *Server) startPCAPWhenFree(pcapFile string, config ReplayConfig, maxRetries int, beforeStart func() error) error {
 	if maxRetries <= 0 {
 		maxRetries = 60
 	}
@@ -122,7 +139,7 @@ func (ws *Server) StartPCAPForSweep(pcapFile string, analysisMode bool, speedMod
 			if retry == 0 {
 				diagf("PCAP replay in progress, waiting...")
 			}
-			time.Sleep(5 * time.Second)
+			time.Sleep(pcapSlotRetryInterval)
 			continue
 		}
 
@@ -137,20 +154,20 @@ func (ws *Server) StartPCAPForSweep(pcapFile string, analysisMode bool, speedMod
 			return fmt.Errorf("reset state: %w", err)
 		}
 
+		if beforeStart != nil {
+			if err := beforeStart(); err != nil {
+				_ = ws.startLiveListenerLocked()
+				ws.dataSourceMu.Unlock()
+				return err
+			}
+		}
+
 		// Set source path on BackgroundManager for region restoration
 		if mgr := l3grid.GetBackgroundManager(ws.sensorID); mgr != nil {
 			mgr.SetSourcePath(pcapFile)
 		}
 
-		if err := ws.startPCAPLockedWithConfig(pcapFile, ReplayConfig{
-			StartSeconds:     startSeconds,
-			DurationSeconds:  durationSeconds,
-			SpeedMode:        speedMode,
-			SpeedRatio:       speedRatio,
-			AnalysisMode:     analysisMode,
-			DisableRecording: disableRecording,
-			SensorID:         ws.sensorID,
-		}); err != nil {
+		if err := ws.startPCAPLockedWithConfig(pcapFile, config); err != nil {
 			_ = ws.startLiveListenerLocked()
 			ws.dataSourceMu.Unlock()
 			return fmt.Errorf("start PCAP: %w", err)
@@ -375,9 +392,8 @@ func (ws *Server) resolvePCAPPath(candidate string) (string, error) {
 		return "", &switchError{status: http.StatusBadRequest, err: errors.New("pcap_file must be a regular file")}
 	}
 
-	ext := strings.ToLower(filepath.Ext(canonicalPath))
-	if ext != ".pcap" && ext != ".pcapng" {
-		return "", &switchError{status: http.StatusBadRequest, err: errors.New("pcap_file must have .pcap or .pcapng extension")}
+	if !network.IsPCAPFileName(canonicalPath) {
+		return "", &switchError{status: http.StatusBadRequest, err: errors.New("pcap_file must have .pcap or .pcapng extension, optionally with .gz or .zst")}
 	}
 
 	return canonicalPath, nil
@@ -582,7 +598,7 @@ func (ws *Server) startPCAPLockedWithConfig(pcapFile string, config ReplayConfig
 
 		// Configure parser to use LiDAR timestamps for PCAP replay
 		// This ensures that replayed data has original timestamps, not current system time
-		if p, ok := ws.parser.(interface{ SetTimestampMode(parse.TimestampMode) }); ok {
+		if p, ok := network.UnwrapParser(ws.parser).(interface{ SetTimestampMode(parse.TimestampMode) }); ok {
 			diagf("Switching parser to TimestampModeLiDAR for PCAP replay")
 			p.SetTimestampMode(parse.TimestampModeLiDAR)
 			defer func() {
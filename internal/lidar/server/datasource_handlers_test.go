@@ -433,6 +433,25 @@ func TestResolvePCAPPath_PcapngExtension(t *testing.T) {
 	}
 }
 
+func TestResolvePCAPPath_CompressedExtension(t *testing.T) {
+	tmpDir := resolveSymlinks(t, t.TempDir())
+
+	ws := &Server{pcapSafeDir: tmpDir}
+	for _, name := range []string{"capture.pcap.gz", "capture.pcapng.zst", "capture.gz"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"capture.pcap.gz", "capture.pcapng.zst"} {
+		if _, err := ws.resolvePCAPPath(name); err != nil {
+			t.Errorf("expected %s to be accepted, got error: %v", name, err)
+		}
+	}
+	if _, err := ws.resolvePCAPPath("capture.gz"); err == nil {
+		t.Error("expected compressed non-PCAP file to be rejected")
+	}
+}
+
 func TestResolvePCAPPath_PathTraversal(t *testing.T) {
 	tmpDir := resolveSymlinks(t, t.TempDir())
 
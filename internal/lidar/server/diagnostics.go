@@ -0,0 +1,169 @@
+package server
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/banshee-data/velocity.report/internal/version"
+)
+
+// maxParamHistory bounds the in-memory runtime tuning change log.
+const maxParamHistory = 100
+
+// ParamChange records one successful runtime tuning update.
+type ParamChange struct {
+	Timestamp time.Time              `json:"timestamp"`
+	SensorID  string                 `json:"sensor_id,omitempty"`
+	Changes   map[string]interface{} `json:"changes"`
+}
+
+// recordParamChange appends an applied tuning patch to the bounded
+// change log included in diagnostic bundles.
+func (ws *Server) recordParamChange(sensorID string, changes map[string]interface{}) {
+	entry := ParamChange{
+		Timestamp: time.Now().UTC(),
+		SensorID:  sensorID,
+		Changes:   make(map[string]interface{}, len(changes)),
+	}
+	for k, v := range changes {
+		entry.Changes[k] = v
+	}
+
+	ws.paramHistoryMu.Lock()
+	defer ws.paramHistoryMu.Unlock()
+	ws.paramHistory = append(ws.paramHistory, entry)
+	if len(ws.paramHistory) > maxParamHistory {
+		ws.paramHistory = ws.paramHistory[len(ws.paramHistory)-maxParamHistory:]
+	}
+}
+
+// ParamHistory returns a copy of the recent runtime tuning changes, oldest first.
+func (ws *Server) ParamHistory() []ParamChange {
+	ws.paramHistoryMu.Lock()
+	defer ws.paramHistoryMu.Unlock()
+	out := make([]ParamChange, len(ws.paramHistory))
+	copy(out, ws.paramHistory)
+	return out
+}
+
+// handleDiagnostics streams a zip bundle for attaching to bug reports:
+// effective tuning config, latest background snapshot, packet/grid/track
+// stats, and recent runtime parameter changes. It only reads state, so it
+// is safe to trigger remotely; concurrent requests are rejected with 429
+// to keep it cheap. Sections that are unavailable are listed in
+// manifest.json rather than failing the request.
+// Method: GET. Query params: sensor_id (optional, defaults to this server's sensor)
+func (ws *Server) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if !ws.diagnosticsInProgress.CompareAndSwap(false, true) {
+		ws.writeJSONError(w, http.StatusTooManyRequests, "diagnostic bundle already in progress")
+		return
+	}
+	defer ws.diagnosticsInProgress.Store(false)
+
+	sensorID := r.URL.Query().Get("sensor_id")
+	if sensorID == "" {
+		sensorID = ws.sensorID
+	}
+	now := time.Now().UTC()
+
+	filename := fmt.Sprintf("lidar-diagnostics-%s-%s.zip", sensorID, now.Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	zw := zip.NewWriter(w)
+	var missing []string
+	writeJSON := func(name string, v interface{}) {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			opsf("diagnostics: create %s: %v", name, err)
+			return
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			opsf("diagnostics: encode %s: %v", name, err)
+		}
+	}
+
+	bm := l3grid.GetBackgroundManager(sensorID)
+
+	writeJSON("config.json", ws.runtimeTuningConfig(bm))
+
+	stats := map[string]interface{}{}
+	if ws.stats != nil {
+		stats["packets"] = ws.stats.GetLatestSnapshot()
+		stats["uptime_secs"] = ws.stats.GetUptime().Seconds()
+	} else {
+		missing = append(missing, "stats.packets: no packet stats")
+	}
+	if bm != nil {
+		stats["grid"] = bm.GridStatus()
+	} else {
+		missing = append(missing, "stats.grid: no background manager for sensor")
+	}
+	if ws.tracker != nil {
+		stats["tracking"] = ws.tracker.GetTrackingMetrics()
+		stats["active_tracks"] = len(ws.tracker.GetActiveTracks())
+	} else {
+		missing = append(missing, "stats.tracking: no tracker")
+	}
+	writeJSON("stats.json", stats)
+
+	writeJSON("param_history.json", ws.ParamHistory())
+
+	if ws.db == nil {
+		missing = append(missing, "background_snapshot: no database")
+	} else if snap, err := ws.db.GetLatestBgSnapshot(sensorID); err != nil {
+		missing = append(missing, fmt.Sprintf("background_snapshot: %v", err))
+	} else if snap == nil {
+		missing = append(missing, "background_snapshot: none recorded")
+	} else {
+		var snapID interface{}
+		if snap.SnapshotID != nil {
+			snapID = *snap.SnapshotID
+		}
+		writeJSON("background_snapshot.json", map[string]interface{}{
+			"snapshot_id":          snapID,
+			"sensor_id":            snap.SensorID,
+			"taken":                time.Unix(0, snap.TakenUnixNanos).UTC().Format(time.RFC3339Nano),
+			"rings":                snap.Rings,
+			"azimuth_bins":         snap.AzimuthBins,
+			"params_json":          snap.ParamsJSON,
+			"ring_elevations_json": snap.RingElevationsJSON,
+			"changed_cells_count":  snap.ChangedCellsCount,
+			"snapshot_reason":      snap.SnapshotReason,
+			"blob_bytes":           len(snap.GridBlob),
+		})
+		// The grid blob is already compressed; store it as-is.
+		if f, err := zw.CreateHeader(&zip.FileHeader{Name: "background_snapshot.grid.gz", Method: zip.Store, Modified: now}); err != nil {
+			opsf("diagnostics: create grid blob: %v", err)
+		} else if _, err := f.Write(snap.GridBlob); err != nil {
+			opsf("diagnostics: write grid blob: %v", err)
+		}
+	}
+
+	ws.dataSourceMu.RLock()
+	source := ws.currentSource
+	pcapFile := ws.currentPCAPFile
+	ws.dataSourceMu.RUnlock()
+
+	writeJSON("manifest.json", map[string]interface{}{
+		"generated":   now.Format(time.RFC3339Nano),
+		"sensor_id":   sensorID,
+		"version":     version.Version,
+		"git_sha":     version.GitSHA,
+		"data_source": string(source),
+		"pcap_file":   pcapFile,
+		"missing":     missing,
+	})
+
+	if err := zw.Close(); err != nil {
+		opsf("diagnostics: finalise zip: %v", err)
+		return
+	}
+	diagf("Diagnostic bundle served for sensor %s (%d sections missing)", sensorID, len(missing))
+}
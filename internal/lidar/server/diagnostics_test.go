@@ -0,0 +1,170 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func readZipEntries(t *testing.T, body []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	entries := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		entries[f.Name] = data
+	}
+	return entries
+}
+
+func TestHandleDiagnostics_Bundle(t *testing.T) {
+	sensorID := "diag-bundle"
+	bm := l3grid.NewBackgroundManager(sensorID, 10, 36, l3grid.BackgroundParams{}, nil)
+	l3grid.RegisterBackgroundManager(sensorID, bm)
+	defer l3grid.RegisterBackgroundManager(sensorID, nil)
+
+	ws := &Server{
+		sensorID: sensorID,
+		stats:    NewPacketStats(),
+		tracker:  l5tracks.NewTracker(l5tracks.DefaultTrackerConfig()),
+	}
+
+	// Apply a runtime tuning change so it shows up in the history.
+	patch := `{"l4":{"dbscan_xy_v1":{"foreground_dbscan_eps":0.6}}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/lidar/params?sensor_id="+sensorID, strings.NewReader(patch))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ws.handleTuningParams(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("tuning POST status = %d; body: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/lidar/diagnostics", nil)
+	w = httptest.NewRecorder()
+	ws.handleDiagnostics(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Content-Type = %q, want application/zip", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "lidar-diagnostics-diag-bundle-") {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+
+	entries := readZipEntries(t, w.Body.Bytes())
+	for _, name := range []string{"config.json", "stats.json", "param_history.json", "manifest.json"} {
+		if _, ok := entries[name]; !ok {
+			t.Errorf("bundle missing %s", name)
+		}
+	}
+
+	var history []ParamChange
+	if err := json.Unmarshal(entries["param_history.json"], &history); err != nil {
+		t.Fatalf("decode param history: %v", err)
+	}
+	if len(history) != 1 || history[0].Changes["l4.dbscan_xy_v1.foreground_dbscan_eps"] != 0.6 {
+		t.Errorf("unexpected param history: %+v", history)
+	}
+
+	var stats map[string]interface{}
+	if err := json.Unmarshal(entries["stats.json"], &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	for _, key := range []string{"packets", "grid", "tracking"} {
+		if _, ok := stats[key]; !ok {
+			t.Errorf("stats.json missing %q", key)
+		}
+	}
+
+	// No database: the snapshot is reported missing rather than failing.
+	var manifest struct {
+		SensorID string   `json:"sensor_id"`
+		Missing  []string `json:"missing"`
+	}
+	if err := json.Unmarshal(entries["manifest.json"], &manifest); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if manifest.SensorID != sensorID {
+		t.Errorf("manifest sensor_id = %q, want %q", manifest.SensorID, sensorID)
+	}
+	if len(manifest.Missing) != 1 || !strings.HasPrefix(manifest.Missing[0], "background_snapshot") {
+		t.Errorf("unexpected missing sections: %v", manifest.Missing)
+	}
+}
+
+func TestHandleDiagnostics_WithSnapshot(t *testing.T) {
+	testDB, cleanup := setupTestDBWrapped(t)
+	defer cleanup()
+	ws := &Server{db: testDB, sensorID: "diag-snapshot"}
+	blob := []byte{0x1f, 0x8b, 0x01, 0x02}
+	if _, err := ws.db.InsertBgSnapshot(&l3grid.BgSnapshot{
+		SensorID:       ws.sensorID,
+		TakenUnixNanos: 1,
+		Rings:          40,
+		AzimuthBins:    1800,
+		ParamsJSON:     "{}",
+		GridBlob:       blob,
+		SnapshotReason: "manual",
+	}); err != nil {
+		t.Fatalf("insert snapshot: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/lidar/diagnostics", nil)
+	w := httptest.NewRecorder()
+	ws.handleDiagnostics(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	entries := readZipEntries(t, w.Body.Bytes())
+	if !bytes.Equal(entries["background_snapshot.grid.gz"], blob) {
+		t.Errorf("grid blob mismatch: %x", entries["background_snapshot.grid.gz"])
+	}
+	if !strings.Contains(string(entries["background_snapshot.json"]), `"snapshot_reason": "manual"`) {
+		t.Errorf("unexpected snapshot metadata: %s", entries["background_snapshot.json"])
+	}
+}
+
+func TestHandleDiagnostics_RejectsConcurrent(t *testing.T) {
+	ws := &Server{}
+	ws.diagnosticsInProgress.Store(true)
+	req := httptest.NewRequest(http.MethodGet, "/api/lidar/diagnostics", nil)
+	w := httptest.NewRecorder()
+	ws.handleDiagnostics(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429", w.Code)
+	}
+}
+
+func TestRecordParamChange_Bounded(t *testing.T) {
+	ws := &Server{}
+	for i := 0; i < maxParamHistory+10; i++ {
+		ws.recordParamChange("s", map[string]interface{}{"i": i})
+	}
+	history := ws.ParamHistory()
+	if len(history) != maxParamHistory {
+		t.Fatalf("history length = %d, want %d", len(history), maxParamHistory)
+	}
+	if history[0].Changes["i"] != 10 {
+		t.Errorf("oldest retained entry = %v, want 10", history[0].Changes["i"])
+	}
+}
@@ -48,11 +48,7 @@ func (d *DirectBackend) FetchBuckets() []string {
 	}
 	buckets := make([]string, 0, len(metrics.BucketsMeters))
 	for _, v := range metrics.BucketsMeters {
-		if v == float64(int(v)) {
-			buckets = append(buckets, fmt.Sprintf("%.0f", v))
-		} else {
-			buckets = append(buckets, fmt.Sprintf("%.6f", v))
-		}
+		buckets = append(buckets, l3grid.RangeBucket(v).String())
 	}
 	return buckets
 }
@@ -89,7 +89,7 @@ func (ws *Server) handleBackgroundGridPolar(w http.ResponseWriter, r *http.Reque
 	// Force a square plot by using equal width/height and symmetric axis ranges
 	scatter := charts.NewScatter()
 	scatter.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Background (Polar->XY)", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: echartsAssetsPrefix}),
+		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Background (Polar->XY)", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: ws.assetsHost()}),
 		charts.WithTitleOpts(opts.Title{Title: "LiDAR Background Grid", Subtitle: fmt.Sprintf("sensor=%s points=%d stride=%d", sensorID, len(data), stride)}),
 		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
 		charts.WithXAxisOpts(opts.XAxis{Min: -pad, Max: pad, Name: "X (m)", NameLocation: "middle", NameGap: 25}),
@@ -129,7 +129,7 @@ func (ws *Server) handleLidarDebugDashboard(w http.ResponseWriter, r *http.Reque
 	}
 	safeQs := html.EscapeString(qs)
 
-	doc := fmt.Sprintf(dashboardHTML, safeSensorID, safeQs)
+	doc := fmt.Sprintf(dashboardHTML, safeSensorID, safeQs, html.EscapeString(ws.basePath))
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	_, _ = w.Write([]byte(doc))
@@ -146,7 +146,7 @@ func (ws *Server) handleSweepDashboard(w http.ResponseWriter, r *http.Request) {
 	// sensorId from the DOM instead of a string literal.
 	safeSensorID := html.EscapeString(sensorID)
 
-	doc := fmt.Sprintf(sweepDashboardHTML, safeSensorID)
+	doc := fmt.Sprintf(sweepDashboardHTML, safeSensorID, html.EscapeString(ws.basePath))
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	_, _ = w.Write([]byte(doc))
@@ -174,7 +174,7 @@ func (ws *Server) handleTrafficChart(w http.ResponseWriter, r *http.Request) {
 
 	bar := charts.NewBar()
 	bar.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{Width: "100%", Height: "720px", AssetsHost: echartsAssetsPrefix}),
+		charts.WithInitializationOpts(opts.Initialization{Width: "100%", Height: "720px", AssetsHost: ws.assetsHost()}),
 		charts.WithTitleOpts(opts.Title{Title: "LiDAR Traffic", Subtitle: snap.Timestamp.Format(time.RFC3339)}),
 		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
 	)
@@ -184,7 +184,7 @@ func (ws *Server) handleTrafficChart(w http.ResponseWriter, r *http.Request) {
 		)
 
 	page := components.NewPage()
-	page.SetAssetsHost(echartsAssetsPrefix)
+	page.SetAssetsHost(ws.assetsHost())
 	page.AddCharts(bar)
 
 	var buf bytes.Buffer
@@ -288,7 +288,7 @@ func (ws *Server) handleBackgroundGridHeatmapChart(w http.ResponseWriter, r *htt
 
 	scatter := charts.NewScatter()
 	scatter.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Background Heatmap", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: echartsAssetsPrefix}),
+		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Background Heatmap", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: ws.assetsHost()}),
 		charts.WithTitleOpts(opts.Title{Title: "LiDAR Background Heatmap", Subtitle: fmt.Sprintf("sensor=%s buckets=%d az=%g", sensorID, len(points), azBucketDeg)}),
 		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
 		charts.WithXAxisOpts(opts.XAxis{Min: -pad, Max: pad, Name: "X (m)", NameLocation: "middle", NameGap: 25}),
@@ -382,7 +382,7 @@ func (ws *Server) handleClustersChart(w http.ResponseWriter, r *http.Request) {
 
 	scatter := charts.NewScatter()
 	scatter.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Clusters", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: echartsAssetsPrefix}),
+		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Clusters", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: ws.assetsHost()}),
 		charts.WithTitleOpts(opts.Title{Title: "Recent Clusters", Subtitle: fmt.Sprintf("sensor=%s count=%d", sensorID, len(pts))}),
 		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
 		charts.WithXAxisOpts(opts.XAxis{Min: -pad, Max: pad, Name: "X (m)", NameLocation: "middle", NameGap: 25}),
@@ -452,7 +452,7 @@ func (ws *Server) handleTracksChart(w http.ResponseWriter, r *http.Request) {
 
 	scatter := charts.NewScatter()
 	scatter.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Tracks", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: echartsAssetsPrefix}),
+		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Tracks", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: ws.assetsHost()}),
 		charts.WithTitleOpts(opts.Title{Title: "Active Tracks", Subtitle: fmt.Sprintf("sensor=%s count=%d", sensorID, len(pts))}),
 		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
 		charts.WithXAxisOpts(opts.XAxis{Min: -pad, Max: pad, Name: "X (m)", NameLocation: "middle", NameGap: 25}),
@@ -541,7 +541,7 @@ func (ws *Server) handleForegroundFrameChart(w http.ResponseWriter, r *http.Requ
 
 	scatter := charts.NewScatter()
 	scatter.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Foreground Frame", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: echartsAssetsPrefix}),
+		charts.WithInitializationOpts(opts.Initialization{PageTitle: "LiDAR Foreground Frame", Theme: "dark", Width: "900px", Height: "900px", AssetsHost: ws.assetsHost()}),
 		charts.WithTitleOpts(opts.Title{Title: "Foreground vs Background", Subtitle: subtitle}),
 		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
 		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
@@ -573,7 +573,7 @@ func (ws *Server) handleBackgroundRegionsDashboard(w http.ResponseWriter, r *htt
 
 	// Use escapedSensorID for both instances (title and meta tag)
 	// The template has been updated to use %[1]s in both places and handle decoding via DOM
-	doc := fmt.Sprintf(regionsDashboardHTML, escapedSensorID)
+	doc := fmt.Sprintf(regionsDashboardHTML, escapedSensorID, html.EscapeString(ws.basePath))
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Write([]byte(doc))
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// Live background grid stream limits. Each subscriber diffs the full grid
+// (72k cells on a Pandar40P) on every tick, so both the rate and the number
+// of concurrent subscribers are capped.
+const (
+	maxGridStreamSubscribers    = 4
+	defaultGridStreamHz         = 5.0
+	maxGridStreamHz             = 20.0
+	defaultGridStreamRangeDelta = 0.05 // metres
+	defaultGridStreamSeenCap    = 100
+	gridStreamWriteTimeout      = 5 * time.Second
+)
+
+// gridStreamHub tracks open grid streams so the subscriber cap can be
+// enforced and every stream ended on shutdown. The zero value is ready to use.
+type gridStreamHub struct {
+	mu      sync.Mutex
+	nextID  int
+	cancels map[int]context.CancelFunc
+}
+
+// join registers a stream's cancel func. It returns false when the hub is
+// already at maxGridStreamSubscribers.
+func (h *gridStreamHub) join(cancel context.CancelFunc) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.cancels) >= maxGridStreamSubscribers {
+		return 0, false
+	}
+	if h.cancels == nil {
+		h.cancels = make(map[int]context.CancelFunc)
+	}
+	h.nextID++
+	h.cancels[h.nextID] = cancel
+	return h.nextID, true
+}
+
+func (h *gridStreamHub) leave(id int) {
+	h.mu.Lock()
+	delete(h.cancels, id)
+	h.mu.Unlock()
+}
+
+// closeAll cancels every open stream. Hijacked WebSocket connections are not
+// tracked by http.Server, so Shutdown and Close do not end them on their own.
+func (h *gridStreamHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, cancel := range h.cancels {
+		cancel()
+	}
+}
+
+// gridStreamCell is one background grid cell in a stream message. Cells
+// that have been emptied (e.g. by a grid reset) are sent with zero range
+// and times-seen so the client can clear them.
+type gridStreamCell struct {
+	Ring        int     `json:"ring"`
+	AzimuthBin  int     `json:"azimuth_bin"`
+	AzimuthDeg  float32 `json:"azimuth_deg"`
+	RangeMeters float32 `json:"range_meters"`
+	TimesSeen   uint32  `json:"times_seen"`
+}
+
+// gridStreamMessage is sent as a "snapshot" with every non-empty cell when
+// the stream opens or the grid is resized, and as a "delta" with only the
+// changed cells afterwards.
+type gridStreamMessage struct {
+	Type        string           `json:"type"`
+	SensorID    string           `json:"sensor_id"`
+	Seq         uint64           `json:"seq"`
+	TimestampNs int64            `json:"timestamp_ns"`
+	Rings       int              `json:"rings,omitempty"`
+	AzimuthBins int              `json:"azimuth_bins,omitempty"`
+	Cells       []gridStreamCell `json:"cells"`
+}
+
+// handleBackgroundGridStream upgrades to a WebSocket and streams background
+// grid changes for a sensor at no more than max_hz messages per second.
+// Changes between ticks are coalesced: each delta carries the cells whose
+// range moved by at least min_range_delta, or whose times-seen count changed
+// below times_seen_cap, since they were last sent.
+func (ws *Server) handleBackgroundGridStream(w http.ResponseWriter, r *http.Request) {
+	sensorID := r.URL.Query().Get("sensor_id")
+	if sensorID == "" {
+		ws.writeJSONError(w, http.StatusBadRequest, "the sensor_id parameter is required")
+		return
+	}
+	bm := l3grid.GetBackgroundManager(sensorID)
+	if bm == nil || bm.Grid == nil {
+		ws.writeJSONError(w, http.StatusNotFound, fmt.Sprintf("no background data available for sensor '%s': check it is connected and active", sensorID))
+		return
+	}
+
+	maxHz := defaultGridStreamHz
+	if hzStr := r.URL.Query().Get("max_hz"); hzStr != "" {
+		if val, err := strconv.ParseFloat(hzStr, 64); err == nil && val > 0 {
+			maxHz = min(val, maxGridStreamHz)
+		}
+	}
+	opts := l3grid.GridDeltaOptions{
+		MinRangeDelta: defaultGridStreamRangeDelta,
+		TimesSeenCap:  defaultGridStreamSeenCap,
+	}
+	if rdStr := r.URL.Query().Get("min_range_delta"); rdStr != "" {
+		if val, err := strconv.ParseFloat(rdStr, 32); err == nil && val >= 0 {
+			opts.MinRangeDelta = float32(val)
+		}
+	}
+	if capStr := r.URL.Query().Get("times_seen_cap"); capStr != "" {
+		if val, err := strconv.ParseUint(capStr, 10, 32); err == nil {
+			opts.TimesSeenCap = uint32(val)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	id, ok := ws.gridStreams.join(cancel)
+	if !ok {
+		ws.writeJSONError(w, http.StatusServiceUnavailable, fmt.Sprintf("too many grid stream subscribers (max %d): close another view and retry", maxGridStreamSubscribers))
+		return
+	}
+	defer ws.gridStreams.leave(id)
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		// Accept has already written the HTTP error response.
+		diagf("grid stream: websocket accept failed for sensor %s: %v", sensorID, err)
+		return
+	}
+	defer conn.CloseNow()
+
+	// The stream is server-to-client only; CloseRead handles control frames
+	// and reports when the client goes away. It gets its own context because
+	// cancelling a read context closes the connection without a close frame.
+	clientGone := conn.CloseRead(context.Background())
+	stop := context.AfterFunc(clientGone, cancel)
+	defer stop()
+
+	interval := time.Duration(float64(time.Second) / maxHz)
+	err = streamBackgroundGrid(ctx, conn, sensorID, opts, interval)
+	switch {
+	case clientGone.Err() != nil:
+	case ctx.Err() != nil:
+		conn.Close(websocket.StatusGoingAway, "grid stream closed")
+	case err != nil:
+		diagf("grid stream: sensor %s: %v", sensorID, err)
+		conn.Close(websocket.StatusInternalError, "grid stream write failed")
+	}
+}
+
+// streamBackgroundGrid sends a snapshot and then a delta per tick until ctx
+// is cancelled or a write fails. The manager is looked up on every tick so
+// the stream follows a grid that is replaced, e.g. when switching to PCAP.
+// Ticks missed during a slow write are dropped, not queued, and the next
+// diff covers everything that changed in the meantime.
+func streamBackgroundGrid(ctx context.Context, conn *websocket.Conn, sensorID string, opts l3grid.GridDeltaOptions, interval time.Duration) error {
+	var (
+		seq         uint64
+		sent        []l3grid.GridCellValue
+		rings, bins int
+		changed     []int
+	)
+	send := func(msg gridStreamMessage) error {
+		seq++
+		msg.SensorID = sensorID
+		msg.Seq = seq
+		msg.TimestampNs = time.Now().UnixNano()
+		writeCtx, cancel := context.WithTimeout(ctx, gridStreamWriteTimeout)
+		defer cancel()
+		return wsjson.Write(writeCtx, conn, msg)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		r, b, cur := l3grid.GetBackgroundManager(sensorID).GridCellValues()
+		switch {
+		case cur == nil:
+			// Manager gone for now; keep the client's last view.
+		case sent == nil || r != rings || b != bins:
+			rings, bins, sent = r, b, cur
+			cells := make([]gridStreamCell, 0, len(cur)/4)
+			for i, v := range cur {
+				if v.TimesSeen > 0 {
+					cells = append(cells, newGridStreamCell(i, bins, v))
+				}
+			}
+			msg := gridStreamMessage{Type: "snapshot", Rings: rings, AzimuthBins: bins, Cells: cells}
+			if err := send(msg); err != nil {
+				return err
+			}
+		default:
+			changed = l3grid.DiffGridCellValues(sent, cur, opts, changed[:0])
+			if len(changed) > 0 {
+				cells := make([]gridStreamCell, len(changed))
+				for j, i := range changed {
+					cells[j] = newGridStreamCell(i, bins, cur[i])
+				}
+				if err := send(gridStreamMessage{Type: "delta", Cells: cells}); err != nil {
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func newGridStreamCell(idx, azimuthBins int, v l3grid.GridCellValue) gridStreamCell {
+	az := idx % azimuthBins
+	return gridStreamCell{
+		Ring:        idx / azimuthBins,
+		AzimuthBin:  az,
+		AzimuthDeg:  float32(az) * 360 / float32(azimuthBins),
+		RangeMeters: v.Range,
+		TimesSeen:   v.TimesSeen,
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/api"
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// newGridStreamTestServer registers a small grid with a few filled cells and
+// serves the routes through the logging middleware, as NewServer does.
+func newGridStreamTestServer(t *testing.T, sensorID string) (*Server, *l3grid.BackgroundManager, *httptest.Server) {
+	t.Helper()
+	bm := l3grid.NewBackgroundManager(sensorID, 4, 36, l3grid.BackgroundParams{}, nil)
+	if bm == nil {
+		t.Fatal("NewBackgroundManager returned nil")
+	}
+	t.Cleanup(func() { l3grid.RegisterBackgroundManager(sensorID, nil) })
+	// Filled before any stream reads the grid, so no lock is needed.
+	bm.Grid.Cells[0].AverageRangeMeters = 12.5
+	bm.Grid.Cells[0].TimesSeenCount = 7
+	bm.Grid.Cells[1*36+9].AverageRangeMeters = 30
+	bm.Grid.Cells[1*36+9].TimesSeenCount = 3
+
+	ws := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		SensorID:          sensorID,
+		UDPListenerConfig: network.UDPListenerConfig{Address: ":0"},
+	})
+	ts := httptest.NewServer(api.LoggingMiddleware(ws.setupRoutes()))
+	t.Cleanup(ts.Close)
+	return ws, bm, ts
+}
+
+func dialGridStream(ctx context.Context, ts *httptest.Server, query string) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/lidar/background/grid/stream?" + query
+	return websocket.Dial(ctx, url, nil)
+}
+
+func TestBackgroundGridStream_SnapshotThenDelta(t *testing.T) {
+	sensorID := "grid-stream-delta"
+	_, bm, ts := newGridStreamTestServer(t, sensorID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := dialGridStream(ctx, ts, "sensor_id="+sensorID+"&max_hz=20")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+
+	var snap gridStreamMessage
+	if err := wsjson.Read(ctx, conn, &snap); err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if snap.Type != "snapshot" || snap.Rings != 4 || snap.AzimuthBins != 36 || snap.Seq != 1 {
+		t.Fatalf("unexpected snapshot header: %+v", snap)
+	}
+	if len(snap.Cells) != 2 {
+		t.Fatalf("snapshot has %d cells, want 2", len(snap.Cells))
+	}
+	if c := snap.Cells[1]; c.Ring != 1 || c.AzimuthBin != 9 || c.AzimuthDeg != 90 || c.RangeMeters != 30 || c.TimesSeen != 3 {
+		t.Errorf("unexpected snapshot cell: %+v", c)
+	}
+
+	if err := bm.ResetGrid(); err != nil {
+		t.Fatalf("ResetGrid: %v", err)
+	}
+	var delta gridStreamMessage
+	if err := wsjson.Read(ctx, conn, &delta); err != nil {
+		t.Fatalf("read delta: %v", err)
+	}
+	if delta.Type != "delta" || delta.Seq != 2 {
+		t.Fatalf("unexpected delta header: %+v", delta)
+	}
+	if len(delta.Cells) != 2 {
+		t.Fatalf("delta has %d cells, want the 2 cleared cells", len(delta.Cells))
+	}
+	for _, c := range delta.Cells {
+		if c.RangeMeters != 0 || c.TimesSeen != 0 {
+			t.Errorf("cleared cell not zeroed: %+v", c)
+		}
+	}
+}
+
+func TestBackgroundGridStream_SubscriberCap(t *testing.T) {
+	sensorID := "grid-stream-cap"
+	_, _, ts := newGridStreamTestServer(t, sensorID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < maxGridStreamSubscribers; i++ {
+		conn, _, err := dialGridStream(ctx, ts, "sensor_id="+sensorID)
+		if err != nil {
+			t.Fatalf("dial %d: %v", i, err)
+		}
+		defer conn.CloseNow()
+		// Reading the snapshot guarantees the stream has joined the hub.
+		var msg gridStreamMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			t.Fatalf("read snapshot %d: %v", i, err)
+		}
+	}
+
+	_, resp, err := dialGridStream(ctx, ts, "sensor_id="+sensorID)
+	if err == nil {
+		t.Fatal("expected dial beyond the subscriber cap to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 beyond the subscriber cap, got %v", resp)
+	}
+}
+
+func TestBackgroundGridStream_Errors(t *testing.T) {
+	ws := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		UDPListenerConfig: network.UDPListenerConfig{Address: ":0"},
+	})
+
+	for _, tc := range []struct {
+		query string
+		want  int
+	}{
+		{"", http.StatusBadRequest},
+		{"sensor_id=grid-stream-missing", http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/lidar/background/grid/stream?"+tc.query, nil)
+		rec := httptest.NewRecorder()
+		ws.handleBackgroundGridStream(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("query %q: status %d, want %d", tc.query, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestBackgroundGridStream_CloseEndsStreams(t *testing.T) {
+	sensorID := "grid-stream-close"
+	ws, _, ts := newGridStreamTestServer(t, sensorID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := dialGridStream(ctx, ts, "sensor_id="+sensorID)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	var msg gridStreamMessage
+	if err := wsjson.Read(ctx, conn, &msg); err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+
+	ws.gridStreams.closeAll()
+	err = wsjson.Read(ctx, conn, &msg)
+	if websocket.CloseStatus(err) != websocket.StatusGoingAway {
+		t.Fatalf("expected going-away close after closeAll, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPLimits bounds how long the monitor waits on a client and how much
+// request body it will read. Zero fields take the DefaultHTTPLimits value.
+type HTTPLimits struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration // Whole request, including the body
+	WriteTimeout      time.Duration // From end of request headers to end of response
+	IdleTimeout       time.Duration // Keep-alive wait for the next request
+	MaxBodyBytes      int64         // Cap on POST/PUT/PATCH/DELETE bodies
+}
+
+// DefaultHTTPLimits returns limits that fit the monitor's small JSON
+// request bodies while leaving room for slow chart, export and diagnostics
+// responses. Hijacked connections such as the grid stream WebSocket have
+// their deadlines cleared by net/http, so the timeouts do not end them.
+func DefaultHTTPLimits() HTTPLimits {
+	return HTTPLimits{
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      2 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		MaxBodyBytes:      1 << 20,
+	}
+}
+
+func (l HTTPLimits) withDefaults() HTTPLimits {
+	def := DefaultHTTPLimits()
+	if l.ReadHeaderTimeout <= 0 {
+		l.ReadHeaderTimeout = def.ReadHeaderTimeout
+	}
+	if l.ReadTimeout <= 0 {
+		l.ReadTimeout = def.ReadTimeout
+	}
+	if l.WriteTimeout <= 0 {
+		l.WriteTimeout = def.WriteTimeout
+	}
+	if l.IdleTimeout <= 0 {
+		l.IdleTimeout = def.IdleTimeout
+	}
+	if l.MaxBodyBytes <= 0 {
+		l.MaxBodyBytes = def.MaxBodyBytes
+	}
+	return l
+}
+
+// limitRequestBody caps the body of mutating requests at maxBytes. Requests
+// that declare a larger Content-Length are rejected with 413 before the
+// handler runs. Bodies without a declared length are wrapped in
+// http.MaxBytesReader; a handler that reports the resulting read error as a
+// 400 has it sent as a 413 instead, so every endpoint answers oversize
+// bodies the same way without checking for *http.MaxBytesError itself.
+func limitRequestBody(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.ContentLength > maxBytes {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			fmt.Fprintf(w, "{\"error\":\"request body is %d bytes, the limit is %d\"}\n", r.ContentLength, maxBytes)
+			return
+		}
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, maxBytes)}
+		r.Body = body
+		next.ServeHTTP(&bodyLimitResponseWriter{ResponseWriter: w, body: body}, r)
+	})
+}
+
+// limitedBody records whether a read hit the MaxBytesReader limit.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded atomic.Bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		b.exceeded.Store(true)
+	}
+	return n, err
+}
+
+// bodyLimitResponseWriter turns a 400 into a 413 once the body limit was hit.
+type bodyLimitResponseWriter struct {
+	http.ResponseWriter
+	body *limitedBody
+}
+
+func (w *bodyLimitResponseWriter) WriteHeader(status int) {
+	if status == http.StatusBadRequest && w.body.exceeded.Load() {
+		status = http.StatusRequestEntityTooLarge
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *bodyLimitResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/api"
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/coder/websocket/wsjson"
+)
+
+func TestNewServer_AppliesHTTPLimits(t *testing.T) {
+	ws := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		UDPListenerConfig: network.UDPListenerConfig{Address: ":0"},
+		HTTPLimits:        HTTPLimits{ReadTimeout: 5 * time.Second},
+	})
+	def := DefaultHTTPLimits()
+	if ws.server.ReadTimeout != 5*time.Second {
+		t.Errorf("ReadTimeout: want 5s, got %v", ws.server.ReadTimeout)
+	}
+	if ws.server.ReadHeaderTimeout != def.ReadHeaderTimeout ||
+		ws.server.WriteTimeout != def.WriteTimeout ||
+		ws.server.IdleTimeout != def.IdleTimeout {
+		t.Errorf("unset limits should use defaults, got header=%v write=%v idle=%v",
+			ws.server.ReadHeaderTimeout, ws.server.WriteTimeout, ws.server.IdleTimeout)
+	}
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	decode := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(limitRequestBody(64, decode))
+	defer ts.Close()
+
+	big := `{"pad":"` + strings.Repeat("x", 100) + `"}`
+	tests := []struct {
+		name   string
+		method string
+		body   io.Reader
+		want   int
+	}{
+		{"small body", http.MethodPost, strings.NewReader(`{"a":1}`), http.StatusOK},
+		{"malformed body", http.MethodPut, strings.NewReader(`{`), http.StatusBadRequest},
+		{"declared length over cap", http.MethodPost, strings.NewReader(big), http.StatusRequestEntityTooLarge},
+		// io.MultiReader hides the length, so the request is sent chunked.
+		{"chunked body over cap", http.MethodPatch, io.MultiReader(strings.NewReader(big)), http.StatusRequestEntityTooLarge},
+		{"GET is not limited", http.MethodGet, strings.NewReader(big), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status: want %d, got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestLimitRequestBody_TuningEndpoint(t *testing.T) {
+	sensorID := "http-limits-tuning"
+	if l3grid.NewBackgroundManager(sensorID, 4, 36, l3grid.BackgroundParams{}, nil) == nil {
+		t.Fatal("NewBackgroundManager returned nil")
+	}
+	t.Cleanup(func() { l3grid.RegisterBackgroundManager(sensorID, nil) })
+	ws := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		SensorID:          sensorID,
+		UDPListenerConfig: network.UDPListenerConfig{Address: ":0"},
+		HTTPLimits:        HTTPLimits{MaxBodyBytes: 128},
+	})
+	ts := httptest.NewServer(ws.server.Handler)
+	defer ts.Close()
+
+	body := `{"noise_relative":` + strings.Repeat(" ", 200) + `0.05}`
+	resp, err := http.Post(ts.URL+"/api/lidar/params?sensor_id="+sensorID, "application/json", io.MultiReader(strings.NewReader(body)))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status: want 413, got %d", resp.StatusCode)
+	}
+}
+
+func TestBackgroundGridStream_OutlivesServerTimeouts(t *testing.T) {
+	sensorID := "grid-stream-timeouts"
+	ws, bm, plain := newGridStreamTestServer(t, sensorID)
+	plain.Close()
+
+	ts := httptest.NewUnstartedServer(api.LoggingMiddleware(ws.setupRoutes()))
+	ts.Config.ReadTimeout = 100 * time.Millisecond
+	ts.Config.WriteTimeout = 100 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := dialGridStream(ctx, ts, "sensor_id="+sensorID+"&max_hz=20")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+
+	var snap gridStreamMessage
+	if err := wsjson.Read(ctx, conn, &snap); err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if err := bm.ResetGrid(); err != nil {
+		t.Fatalf("ResetGrid: %v", err)
+	}
+	var delta gridStreamMessage
+	if err := wsjson.Read(ctx, conn, &delta); err != nil {
+		t.Fatalf("stream closed after server timeouts: %v", err)
+	}
+	if delta.Type != "delta" {
+		t.Errorf("expected a delta, got %q", delta.Type)
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultLiveStaleTimeout is how long the live feed may go without a frame
+// before it is marked stale. The sensor sends 10–20 frames a second, so
+// five seconds rides out brief network hiccups but not a reboot.
+const DefaultLiveStaleTimeout = 5 * time.Second
+
+// LiveWatchdog notices when the live UDP feed goes silent. The frame
+// callback reports every completed frame with Observe; once no frame has
+// arrived for the timeout the feed is marked stale, and the next frame
+// marks it live again. Without the watchdog a rebooting sensor leaves the
+// last tracks on screen as though they were current.
+//
+// Only the live source is watched: the server pauses the watchdog while a
+// PCAP or VRLOG source is active.
+type LiveWatchdog struct {
+	timeout time.Duration
+
+	mu          sync.Mutex
+	lastFrame   time.Time // zero until the first frame
+	since       time.Time // start of the current silence
+	stale       bool
+	staleSince  time.Time
+	staleEvents uint64
+	resumes     uint64
+	onChange    []func(stale bool)
+}
+
+// LiveWatchdogStatus is the watchdog state reported by the status API.
+type LiveWatchdogStatus struct {
+	State         string  `json:"state"` // "streaming" or "stale"
+	TimeoutSecs   float64 `json:"timeout_secs"`
+	LastFrameAt   string  `json:"last_frame_at,omitempty"`
+	StaleSince    string  `json:"stale_since,omitempty"`
+	StaleEvents   uint64  `json:"stale_events"`
+	ResumeEvents  uint64  `json:"resume_events"`
+	SecondsSilent float64 `json:"seconds_silent"`
+}
+
+// Live feed states.
+const (
+	LiveStateStreaming = "streaming"
+	LiveStateStale     = "stale"
+)
+
+// NewLiveWatchdog returns a watchdog that marks the feed stale after
+// timeout without a frame. The clock starts at now, so a sensor that
+// never sends anything is reported stale once the timeout has passed.
+func NewLiveWatchdog(timeout time.Duration, now time.Time) *LiveWatchdog {
+	return &LiveWatchdog{timeout: timeout, since: now}
+}
+
+// Timeout returns the configured no-frames timeout.
+func (w *LiveWatchdog) Timeout() time.Duration {
+	return w.timeout
+}
+
+// OnChange adds fn to the functions called on every transition, with true
+// when the feed goes stale and false when it resumes. They run in the
+// order added, outside the watchdog's lock.
+func (w *LiveWatchdog) OnChange(fn func(stale bool)) {
+	w.mu.Lock()
+	w.onChange = append(w.onChange, fn)
+	w.mu.Unlock()
+}
+
+func notifyLiveChange(fns []func(stale bool), stale bool) {
+	for _, fn := range fns {
+		fn(stale)
+	}
+}
+
+// Observe records a frame arriving at now, resuming a stale feed.
+func (w *LiveWatchdog) Observe(now time.Time) {
+	w.mu.Lock()
+	gap := now.Sub(w.since)
+	w.lastFrame, w.since = now, now
+	if !w.stale {
+		w.mu.Unlock()
+		return
+	}
+	w.stale = false
+	w.staleSince = time.Time{}
+	w.resumes++
+	fns := w.onChange
+	w.mu.Unlock()
+
+	opsf("live feed resumed after %.1fs without frames", gap.Seconds())
+	notifyLiveChange(fns, false)
+}
+
+// Check marks the feed stale if no frame has arrived within the timeout
+// of now, and reports whether it is stale.
+func (w *LiveWatchdog) Check(now time.Time) bool {
+	w.mu.Lock()
+	if w.stale || now.Sub(w.since) < w.timeout {
+		stale := w.stale
+		w.mu.Unlock()
+		return stale
+	}
+	w.stale = true
+	w.staleSince = now
+	w.staleEvents++
+	silent := now.Sub(w.since)
+	fns := w.onChange
+	w.mu.Unlock()
+
+	opsf("live feed stale: no frames for %.1fs (timeout %s), suppressing tracks until frames return", silent.Seconds(), w.timeout)
+	notifyLiveChange(fns, true)
+	return true
+}
+
+// Reset restarts the timeout from now without counting a resume. The
+// server calls it while another data source is active, so returning to
+// live gives the sensor a full timeout before being declared stale.
+func (w *LiveWatchdog) Reset(now time.Time) {
+	w.mu.Lock()
+	w.since = now
+	wasStale := w.stale
+	w.stale = false
+	w.staleSince = time.Time{}
+	fns := w.onChange
+	w.mu.Unlock()
+	if wasStale {
+		notifyLiveChange(fns, false)
+	}
+}
+
+// Stale reports whether the feed is currently marked stale.
+func (w *LiveWatchdog) Stale() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stale
+}
+
+// Status returns the watchdog state as of now.
+func (w *LiveWatchdog) Status(now time.Time) LiveWatchdogStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st := LiveWatchdogStatus{
+		State:         LiveStateStreaming,
+		TimeoutSecs:   w.timeout.Seconds(),
+		StaleEvents:   w.staleEvents,
+		ResumeEvents:  w.resumes,
+		SecondsSilent: now.Sub(w.since).Seconds(),
+	}
+	if !w.lastFrame.IsZero() {
+		st.LastFrameAt = w.lastFrame.UTC().Format(time.RFC3339Nano)
+	}
+	if w.stale {
+		st.State = LiveStateStale
+		st.StaleSince = w.staleSince.UTC().Format(time.RFC3339Nano)
+	}
+	return st
+}
+
+// run checks the watchdog every quarter timeout until ctx ends. While the
+// server is on another data source the watchdog is held reset.
+func (w *LiveWatchdog) run(ctx context.Context, isLive func() bool) {
+	interval := w.timeout / 4
+	if interval < 50*time.Millisecond {
+		interval = 50 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if isLive() {
+				w.Check(now)
+			} else {
+				w.Reset(now)
+			}
+		}
+	}
+}
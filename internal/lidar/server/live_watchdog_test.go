@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+// TestLiveWatchdog_FeedGapAndRecovery simulates a sensor reboot: frames
+// stop, the feed goes stale and active tracks are withheld, then frames
+// return and the tracks come back.
+func TestLiveWatchdog_FeedGapAndRecovery(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	wd := NewLiveWatchdog(2*time.Second, start)
+	var mu sync.Mutex
+	var changes []bool
+	wd.OnChange(func(stale bool) {
+		mu.Lock()
+		changes = append(changes, stale)
+		mu.Unlock()
+	})
+
+	tracker := l5tracks.NewTracker(l5tracks.DefaultTrackerConfig())
+	cluster := l4perception.WorldCluster{
+		SensorID: "test-sensor", CentroidY: 5, CentroidZ: 1,
+		BoundingBoxLength: 4, BoundingBoxWidth: 2, BoundingBoxHeight: 1.5,
+		PointsCount: 50, HeightP95: 1.4, IntensityMean: 100,
+	}
+	api := NewTrackAPI(nil, "test-sensor")
+	api.SetTracker(tracker)
+	api.liveWatchdog = wd
+	activeTracks := func() TracksListResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		api.handleActiveTracks(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/active", nil))
+		var resp TracksListResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// Ten frames a second for half a second.
+	now := start
+	for i := 0; i < 5; i++ {
+		now = now.Add(100 * time.Millisecond)
+		cluster.CentroidX = 10 + float32(i)*0.5
+		tracker.Update([]l4perception.WorldCluster{cluster}, now)
+		wd.Observe(now)
+		if wd.Check(now) {
+			t.Fatalf("stale at frame %d", i)
+		}
+	}
+	if resp := activeTracks(); resp.Count == 0 || resp.Stale {
+		t.Fatalf("streaming: count %d, stale %v", resp.Count, resp.Stale)
+	}
+
+	// The feed goes silent.
+	if wd.Check(now.Add(1900 * time.Millisecond)) {
+		t.Fatal("stale before the timeout")
+	}
+	gapAt := now.Add(2 * time.Second)
+	if !wd.Check(gapAt) || !wd.Stale() {
+		t.Fatal("not stale after the timeout")
+	}
+	wd.Check(gapAt.Add(time.Second)) // stays stale without a second event
+	st := wd.Status(gapAt.Add(3 * time.Second))
+	if st.State != LiveStateStale || st.StaleEvents != 1 || st.SecondsSilent != 5 || st.StaleSince == "" {
+		t.Errorf("stale status = %+v", st)
+	}
+	if resp := activeTracks(); resp.Count != 0 || len(resp.Tracks) != 0 || !resp.Stale {
+		t.Errorf("stale: count %d, stale %v", resp.Count, resp.Stale)
+	}
+
+	// Frames return.
+	now = gapAt.Add(10 * time.Second)
+	wd.Observe(now)
+	if wd.Check(now) {
+		t.Fatal("still stale after a frame")
+	}
+	st = wd.Status(now)
+	if st.State != LiveStateStreaming || st.ResumeEvents != 1 || st.StaleSince != "" {
+		t.Errorf("resumed status = %+v", st)
+	}
+	if resp := activeTracks(); resp.Count == 0 || resp.Stale {
+		t.Errorf("resumed: count %d, stale %v", resp.Count, resp.Stale)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("transitions = %v, want [true false]", changes)
+	}
+}
+
+// TestLiveWatchdog_RunOnlyWatchesLive checks the background loop leaves a
+// silent feed alone while another data source is active.
+func TestLiveWatchdog_RunOnlyWatchesLive(t *testing.T) {
+	wd := NewLiveWatchdog(100*time.Millisecond, time.Now())
+	var live atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go wd.run(ctx, live.Load)
+
+	time.Sleep(300 * time.Millisecond)
+	if wd.Stale() {
+		t.Fatal("went stale while replaying")
+	}
+
+	live.Store(true)
+	deadline := time.Now().Add(2 * time.Second)
+	for !wd.Stale() {
+		if time.Now().After(deadline) {
+			t.Fatal("live feed never went stale")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Switching away clears the stale state without counting a resume.
+	live.Store(false)
+	deadline = time.Now().Add(2 * time.Second)
+	for wd.Stale() {
+		if time.Now().After(deadline) {
+			t.Fatal("still stale after leaving live")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if st := wd.Status(time.Now()); st.ResumeEvents != 0 || st.StaleEvents != 1 {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestServer_LiveFeedStatus(t *testing.T) {
+	start := time.Now()
+	wd := NewLiveWatchdog(time.Second, start)
+	ws := NewServer(Config{Address: ":0", Stats: NewPacketStats(), LiveWatchdog: wd})
+	wd.Check(start.Add(2 * time.Second))
+
+	w := httptest.NewRecorder()
+	ws.handleDataSource(w, httptest.NewRequest(http.MethodGet, "/api/lidar/data_source", nil))
+	var resp struct {
+		DataSource string              `json:"data_source"`
+		LiveFeed   *LiveWatchdogStatus `json:"live_feed"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.DataSource != string(DataSourceLive) || resp.LiveFeed == nil || resp.LiveFeed.State != LiveStateStale {
+		t.Errorf("data source response = %+v", resp)
+	}
+
+	ws.dataSourceMu.Lock()
+	ws.currentSource = DataSourcePCAP
+	ws.dataSourceMu.Unlock()
+	if st := ws.liveFeedStatus(ws.CurrentSource()); st != nil {
+		t.Errorf("live feed reported during replay: %+v", st)
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/logutil"
+)
+
+const (
+	defaultLogLimit       = 200
+	logStreamPingInterval = 15 * time.Second
+)
+
+// logFilterFromRequest reads the level and sensor_id query parameters.
+func logFilterFromRequest(r *http.Request) (logutil.LogFilter, error) {
+	q := r.URL.Query()
+	filter := logutil.LogFilter{Level: q.Get("level"), Sensor: q.Get("sensor_id")}
+	if filter.Level != "" && !logutil.ValidLevel(filter.Level) {
+		return filter, fmt.Errorf("invalid level %q: use ops, diag or trace", filter.Level)
+	}
+	return filter, nil
+}
+
+// handleLogs returns the most recent captured log entries, oldest first.
+// Query parameters: level (most verbose level to include), sensor_id,
+// limit (default 200) and after_seq (only entries newer than this).
+func (ws *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if ws.logs == nil {
+		ws.writeJSONError(w, http.StatusServiceUnavailable, "log capture is not enabled: start with --lidar-log-buffer above 0")
+		return
+	}
+	filter, err := logFilterFromRequest(r)
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := defaultLogLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	var afterSeq uint64
+	if s := r.URL.Query().Get("after_seq"); s != "" {
+		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			afterSeq = v
+		}
+	}
+	ws.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": ws.logs.Recent(filter, afterSeq, limit),
+	})
+}
+
+// handleLogStream streams captured log entries as server-sent events, one
+// JSON entry per "log" event with the entry's sequence number as the event
+// ID. The stream opens with the recent backlog (up to limit entries), or
+// with everything after Last-Event-ID when an EventSource reconnects.
+// Subscribers are capped; beyond the cap the request gets a 503.
+func (ws *Server) handleLogStream(w http.ResponseWriter, r *http.Request) {
+	if ws.logs == nil {
+		ws.writeJSONError(w, http.StatusServiceUnavailable, "log capture is not enabled: start with --lidar-log-buffer above 0")
+		return
+	}
+	filter, err := logFilterFromRequest(r)
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := defaultLogLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v >= 0 {
+			limit = v
+		}
+	}
+	var lastSeq uint64
+	resuming := false
+	if s := r.Header.Get("Last-Event-ID"); s != "" {
+		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			lastSeq, resuming = v, true
+		}
+	}
+
+	// Subscribe before reading the backlog so nothing logged in between is
+	// missed; entries seen in both are skipped by sequence number.
+	entries, unsubscribe, err := ws.logs.Subscribe()
+	if errors.Is(err, logutil.ErrTooManySubscribers) {
+		ws.writeJSONError(w, http.StatusServiceUnavailable, fmt.Sprintf("too many log stream subscribers (max %d): close another view and retry", ws.logs.MaxSubscribers()))
+		return
+	}
+	defer unsubscribe()
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(e logutil.LogEntry) error {
+		if e.Seq <= lastSeq || !filter.Match(e) {
+			return nil
+		}
+		lastSeq = e.Seq
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", e.Seq, data)
+		return err
+	}
+
+	var backlog []logutil.LogEntry
+	switch {
+	case resuming:
+		backlog = ws.logs.Recent(filter, lastSeq, 0)
+	case limit > 0:
+		backlog = ws.logs.Recent(filter, 0, limit)
+	}
+	for _, e := range backlog {
+		if err := send(e); err != nil {
+			return
+		}
+	}
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	// Start ends the server's base context before shutting down, which
+	// would otherwise wait for this handler.
+	serverDone := ws.baseContext()
+	if serverDone == nil {
+		serverDone = context.Background()
+	}
+	ping := time.NewTicker(logStreamPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-serverDone.Done():
+			return
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e := <-entries:
+			if err := send(e); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
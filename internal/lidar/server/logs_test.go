@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/logutil"
+)
+
+func TestHandleLogs(t *testing.T) {
+	logs := logutil.NewLogBuffer(10, 1)
+	io.WriteString(logs.Writer(logutil.LevelOps), "[monitor] sensor hesai-01 connected\n")
+	io.WriteString(logs.Writer(logutil.LevelDiag), "[l3grid] sensor hesai-01 settling\n")
+	io.WriteString(logs.Writer(logutil.LevelOps), "[monitor] sensor hesai-02 connected\n")
+	ws := &Server{logs: logs}
+
+	for name, tc := range map[string]struct {
+		query string
+		want  []uint64
+	}{
+		"all":       {"", []uint64{1, 2, 3}},
+		"ops only":  {"level=ops", []uint64{1, 3}},
+		"sensor":    {"sensor_id=hesai-01", []uint64{1, 2}},
+		"limit":     {"limit=1", []uint64{3}},
+		"after seq": {"after_seq=2", []uint64{3}},
+	} {
+		w := httptest.NewRecorder()
+		ws.handleLogs(w, httptest.NewRequest(http.MethodGet, "/api/lidar/logs?"+tc.query, nil))
+		var resp struct {
+			Entries []logutil.LogEntry `json:"entries"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var got []uint64
+		for _, e := range resp.Entries {
+			got = append(got, e.Seq)
+		}
+		if len(got) != len(tc.want) || (len(got) > 0 && (got[0] != tc.want[0] || got[len(got)-1] != tc.want[len(tc.want)-1])) {
+			t.Errorf("%s: seqs %v, want %v", name, got, tc.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	ws.handleLogs(w, httptest.NewRequest(http.MethodGet, "/api/lidar/logs?level=debug", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad level: status %d, want 400", w.Code)
+	}
+	w = httptest.NewRecorder()
+	(&Server{}).handleLogs(w, httptest.NewRequest(http.MethodGet, "/api/lidar/logs", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("disabled: status %d, want 503", w.Code)
+	}
+}
+
+// readLogEvent reads SSE lines until the next "data:" line and decodes it.
+func readLogEvent(t *testing.T, r *bufio.Reader) logutil.LogEntry {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var e logutil.LogEntry
+			if err := json.Unmarshal([]byte(data), &e); err != nil {
+				t.Fatalf("decode %q: %v", data, err)
+			}
+			return e
+		}
+	}
+}
+
+func TestHandleLogStream(t *testing.T) {
+	logs := logutil.NewLogBuffer(10, 1)
+	io.WriteString(logs.Writer(logutil.LevelOps), "[monitor] before connect\n")
+	ws := &Server{logs: logs}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/lidar/logs/stream", ws.handleLogStream)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/lidar/logs/stream?level=ops", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+	r := bufio.NewReader(resp.Body)
+	if e := readLogEvent(t, r); e.Message != "before connect" {
+		t.Errorf("backlog entry = %+v", e)
+	}
+
+	// The subscriber cap is one, so a second viewer is turned away.
+	second, err := http.Get(ts.URL + "/api/lidar/logs/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("second subscriber: status %d, want 503", second.StatusCode)
+	}
+
+	io.WriteString(logs.Writer(logutil.LevelTrace), "[pipeline] filtered out\n")
+	io.WriteString(logs.Writer(logutil.LevelOps), "[pipeline] frame dropped\n")
+	if e := readLogEvent(t, r); e.Seq != 3 || e.Tag != "pipeline" || e.Message != "frame dropped" {
+		t.Errorf("live entry = %+v", e)
+	}
+}
+
+func TestHandleLogStream_ResumesAfterLastEventID(t *testing.T) {
+	logs := logutil.NewLogBuffer(10, 1)
+	for _, msg := range []string{"one", "two", "three"} {
+		io.WriteString(logs.Writer(logutil.LevelOps), msg+"\n")
+	}
+	ws := &Server{logs: logs}
+	ts := httptest.NewServer(http.HandlerFunc(ws.handleLogStream))
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"?limit=0", nil)
+	req.Header.Set("Last-Event-ID", "2")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if e := readLogEvent(t, bufio.NewReader(resp.Body)); e.Seq != 3 || e.Message != "three" {
+		t.Errorf("resumed entry = %+v, want seq 3", e)
+	}
+}
@@ -5,9 +5,9 @@ import (
 	"io/fs"
 	"net/http"
 	"path/filepath"
-	"strings"
 	"time"
 
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/network"
 	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
 )
 
@@ -68,8 +68,7 @@ func (ws *Server) handleListPCAPFiles(w http.ResponseWriter, r *http.Request) {
 		if d.IsDir() {
 			return nil
 		}
-		ext := strings.ToLower(filepath.Ext(path))
-		if ext != ".pcap" && ext != ".pcapng" {
+		if !network.IsPCAPFileName(path) {
 			return nil
 		}
 
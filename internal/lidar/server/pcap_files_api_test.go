@@ -62,6 +62,7 @@ func TestHandleListPCAPFiles_SuccessAndInUseFlags(t *testing.T) {
 
 	fileA := filepath.Join(safeDir, "a.pcap")
 	fileB := filepath.Join(nestedDir, "b.pcapng")
+	fileC := filepath.Join(safeDir, "c.pcap.gz")
 	fileIgnored := filepath.Join(safeDir, "ignored.txt")
 	if err := os.WriteFile(fileA, []byte("a"), 0o644); err != nil {
 		t.Fatalf("write fileA: %v", err)
@@ -69,6 +70,9 @@ func TestHandleListPCAPFiles_SuccessAndInUseFlags(t *testing.T) {
 	if err := os.WriteFile(fileB, []byte("b"), 0o644); err != nil {
 		t.Fatalf("write fileB: %v", err)
 	}
+	if err := os.WriteFile(fileC, []byte("c"), 0o644); err != nil {
+		t.Fatalf("write fileC: %v", err)
+	}
 	if err := os.WriteFile(fileIgnored, []byte("x"), 0o644); err != nil {
 		t.Fatalf("write fileIgnored: %v", err)
 	}
@@ -108,8 +112,8 @@ func TestHandleListPCAPFiles_SuccessAndInUseFlags(t *testing.T) {
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("decode response: %v", err)
 	}
-	if resp.Count != 2 {
-		t.Fatalf("expected count 2, got %d", resp.Count)
+	if resp.Count != 3 {
+		t.Fatalf("expected count 3, got %d", resp.Count)
 	}
 
 	filesByPath := make(map[string]PcapFileInfo, len(resp.Files))
@@ -123,6 +127,9 @@ func TestHandleListPCAPFiles_SuccessAndInUseFlags(t *testing.T) {
 	if !filesByPath[filepath.Join("nested", "b.pcapng")].InUse {
 		t.Fatalf("expected nested/b.pcapng to be marked in_use")
 	}
+	if fi, ok := filesByPath["c.pcap.gz"]; !ok || fi.InUse {
+		t.Fatalf("expected compressed c.pcap.gz to be listed and not in use, got %+v (listed=%v)", fi, ok)
+	}
 }
 
 func TestHandleListPCAPFiles_RespectsFileLimit(t *testing.T) {
@@ -6,7 +6,10 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints"
 )
 
 // TestHandlePlaybackStatus tests the GET /api/lidar/playback/status endpoint.
@@ -223,6 +226,67 @@ func TestHandlePlaybackSeek(t *testing.T) {
 	}
 }
 
+// TestHandlePlaybackFrameAt tests the GET /api/lidar/playback/frame_at endpoint.
+func TestHandlePlaybackFrameAt(t *testing.T) {
+	lookup := func(ts int64) (*l9endpoints.FrameSnapshot, error) {
+		switch {
+		case ts < 1000 || ts > 2000:
+			return nil, &l9endpoints.TimestampRangeError{RequestedNs: ts, StartNs: 1000, EndNs: 2000}
+		case ts == 1500:
+			return nil, errors.New("chunk unreadable")
+		}
+		return &l9endpoints.FrameSnapshot{
+			FrameID: 12, TimestampNanos: 1200, RequestedNanos: ts, OffsetNanos: 1200 - ts,
+			Points: []l9endpoints.SnapshotPoint{{X: 1, Y: 2, Z: 0.5, Intensity: 40}},
+			Tracks: []l9endpoints.SnapshotTrack{{TrackID: "trk_1", State: "confirmed", X: 1, Y: 2}},
+		}, nil
+	}
+	tests := []struct {
+		name           string
+		query          string
+		getFrameAt     func(int64) (*l9endpoints.FrameSnapshot, error)
+		expectedStatus int
+		wantError      string
+	}{
+		{"without callback returns not implemented", "?timestamp_ns=1200", nil, http.StatusNotImplemented, ""},
+		{"missing timestamp returns bad request", "", lookup, http.StatusBadRequest, "missing timestamp_ns"},
+		{"malformed timestamp returns bad request", "?timestamp_ns=soon", lookup, http.StatusBadRequest, "invalid timestamp_ns"},
+		{"timestamp before the frames returns not found", "?timestamp_ns=10", lookup, http.StatusNotFound, "run from 1000 to 2000"},
+		{"no frames returns conflict", "?timestamp_ns=1200",
+			func(int64) (*l9endpoints.FrameSnapshot, error) { return nil, l9endpoints.ErrNoFrames },
+			http.StatusConflict, "no frames available"},
+		{"read error returns internal error", "?timestamp_ns=1500", lookup, http.StatusInternalServerError, "chunk unreadable"},
+		{"timestamp in range returns the frame", "?timestamp_ns=1210", lookup, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &Server{getFrameAt: tt.getFrameAt}
+			req := httptest.NewRequest(http.MethodGet, "/api/lidar/playback/frame_at"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			ws.handlePlaybackFrameAt(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.wantError != "" && !strings.Contains(w.Body.String(), tt.wantError) {
+				t.Errorf("error body %q does not mention %q", w.Body.String(), tt.wantError)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var got l9endpoints.FrameSnapshot
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got.FrameID != 12 || got.RequestedNanos != 1210 || got.OffsetNanos != -10 || len(got.Points) != 1 || len(got.Tracks) != 1 {
+				t.Errorf("snapshot = %+v", got)
+			}
+		})
+	}
+}
+
 // TestHandlePlaybackRate tests the POST /api/lidar/playback/rate endpoint.
 func TestHandlePlaybackRate(t *testing.T) {
 	tests := []struct {
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints"
 	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
 )
 
@@ -512,6 +513,46 @@ func (ws *Server) handlePlaybackRate(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handlePlaybackFrameAt returns the foreground points and track states of
+// the replayed frame nearest a timestamp, so external labelling tools can
+// draw track boxes over the points they were fitted to.
+// GET /api/lidar/playback/frame_at?timestamp_ns=1234567890
+func (ws *Server) handlePlaybackFrameAt(w http.ResponseWriter, r *http.Request) {
+	if ws.getFrameAt == nil {
+		ws.writeJSONError(w, http.StatusNotImplemented, "frame lookup is not available: check server was started with the visualiser enabled")
+		return
+	}
+
+	raw := r.URL.Query().Get("timestamp_ns")
+	if raw == "" {
+		ws.writeJSONError(w, http.StatusBadRequest, "missing timestamp_ns query parameter")
+		return
+	}
+	timestampNs, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid timestamp_ns %q: must be integer nanoseconds since the epoch", raw))
+		return
+	}
+
+	snapshot, err := ws.getFrameAt(timestampNs)
+	var rangeErr *l9endpoints.TimestampRangeError
+	switch {
+	case errors.As(err, &rangeErr):
+		ws.writeJSONError(w, http.StatusNotFound, fmt.Sprintf("no frame at timestamp_ns %d: the available frames run from %d to %d",
+			timestampNs, rangeErr.StartNs, rangeErr.EndNs))
+		return
+	case errors.Is(err, l9endpoints.ErrNoFrames):
+		ws.writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		ws.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("could not read the frame: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
 // handleVRLogLoad loads a VRLOG for replay by run ID.
 // POST /api/lidar/vrlog/load
 // Body: {"run_id": "abc123"} or {"vrlog_path": "/path/to/vrlog"}
@@ -54,8 +54,12 @@ func (ws *Server) RegisterRoutes(mux *http.ServeMux) {
 		{"/health", ws.handleHealth},
 		{"/api/lidar/server", ws.handleStatus},
 		{"/api/lidar/monitor", ws.handleStatus},
+		{"GET /lidar/sensors", ws.handleSensorIndex},
 		{"GET /api/lidar/status", ws.handleLidarStatus},
 		{"POST /api/lidar/persist", ws.handleLidarPersist},
+		{"GET /api/lidar/diagnostics", ws.handleDiagnostics},
+		{"GET /api/lidar/logs", ws.handleLogs},
+		{"GET /api/lidar/logs/stream", ws.handleLogStream},
 	}
 
 	// Snapshot and export routes
@@ -75,6 +79,7 @@ func (ws *Server) RegisterRoutes(mux *http.ServeMux) {
 		{"GET /api/lidar/acceptance", ws.handleAcceptanceMetrics},
 		{"POST /api/lidar/acceptance/reset", ws.handleAcceptanceReset},
 		{"/api/lidar/params", ws.handleTuningParams},
+		{"GET /api/lidar/params/diff", ws.handleParamsDiff},
 	}
 
 	// Sweep and auto-tune routes
@@ -101,8 +106,13 @@ func (ws *Server) RegisterRoutes(mux *http.ServeMux) {
 		{"GET /api/lidar/grid_status", ws.handleGridStatus},
 		{"GET /api/lidar/settling_eval", ws.handleSettlingEval},
 		{"POST /api/lidar/grid_reset", ws.handleGridReset},
+		{"POST /api/lidar/grid/freeze", ws.handleGridFreeze},
+		{"POST /api/lidar/grid/thaw", ws.handleGridThaw},
 		{"GET /api/lidar/grid_heatmap", ws.handleGridHeatmap},
 		{"/api/lidar/background/grid", ws.handleBackgroundGrid},
+		{"GET /api/lidar/background/grid/stream", ws.handleBackgroundGridStream},
+		{"/api/lidar/tripwires", ws.handleTripwires},
+		{"GET /api/lidar/tripwires/headways", ws.handleTripwireHeadways},
 	}
 
 	// Data source and PCAP replay routes
@@ -112,6 +122,8 @@ func (ws *Server) RegisterRoutes(mux *http.ServeMux) {
 		{"POST /api/lidar/pcap/stop", ws.handlePCAPStop},
 		{"POST /api/lidar/pcap/resume_live", ws.handlePCAPResumeLive},
 		{"GET /api/lidar/pcap/files", ws.handleListPCAPFiles},
+		{"POST /api/lidar/analyze", ws.withDB(ws.handleAnalyze)},
+		{"GET /api/lidar/analyze/", ws.withDB(ws.handleAnalyzeStatus)},
 	}
 
 	// Chart API routes (structured JSON data for frontend charts)
@@ -148,6 +160,7 @@ func (ws *Server) RegisterRoutes(mux *http.ServeMux) {
 		{"POST /api/lidar/playback/play", ws.handlePlaybackPlay},
 		{"POST /api/lidar/playback/seek", ws.handlePlaybackSeek},
 		{"POST /api/lidar/playback/rate", ws.handlePlaybackRate},
+		{"GET /api/lidar/playback/frame_at", ws.handlePlaybackFrameAt},
 		{"POST /api/lidar/vrlog/load", ws.handleVRLogLoad},
 		{"POST /api/lidar/vrlog/stop", ws.handleVRLogStop},
 	}
@@ -174,8 +187,10 @@ func (ws *Server) RegisterRoutes(mux *http.ServeMux) {
 			{"/api/lidar/tracks/history", ws.trackAPI.handleListTracks},
 			{"/api/lidar/tracks/active", ws.trackAPI.handleActiveTracks},
 			{"/api/lidar/tracks/metrics", ws.trackAPI.handleTrackingMetrics},
+			{"/api/lidar/tracks/innovations", ws.trackAPI.handleTrackInnovations},
 			{"/api/lidar/tracks/", ws.trackAPI.handleTrackByID},
 			{"/api/lidar/tracks/summary", ws.trackAPI.handleTrackSummary},
+			{"/api/lidar/tracks/overlay.svg", ws.trackAPI.handleTrackOverlay},
 			{"/api/lidar/clusters", ws.trackAPI.handleListClusters},
 			{"/api/lidar/observations", ws.trackAPI.handleListObservations},
 			{"/api/lidar/tracks/clear", ws.trackAPI.handleClearTracks},
@@ -201,6 +216,10 @@ func (ws *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/lidar/scenes", ws.withDB(ws.handleScenes))
 	mux.HandleFunc("/api/lidar/scenes/", ws.withDB(ws.handleSceneByID))
 
+	// Tuning preset routes (named, reusable tuning configurations)
+	mux.HandleFunc("/api/lidar/presets", ws.withDB(ws.handleTuningPresets))
+	mux.HandleFunc("/api/lidar/presets/", ws.withDB(ws.handleTuningPresetByName))
+
 }
 
 // setupRoutes configures the HTTP routes and handlers for the lidar-only
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/banshee-data/velocity.report/internal/api"
 	"github.com/banshee-data/velocity.report/internal/lidar/adapters"
@@ -392,8 +393,9 @@ func (ws *Server) handleLabellingProgress(w http.ResponseWriter, r *http.Request
 	})
 }
 
-// handleListRuns lists analysis runs with optional filters.
-// GET /api/lidar/runs?limit=50&sensor_id=sensor1&status=completed
+// handleListRuns lists analysis runs with optional filters, newest first.
+// Each run's tracks are at /api/lidar/runs/{run_id}/tracks.
+// GET /api/lidar/runs?limit=50&offset=0&sensor_id=sensor1&status=failed&source_type=pcap&start_time=<ns>&end_time=<ns>
 func (ws *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		ws.writeJSONError(w, http.StatusMethodNotAllowed, "this endpoint only accepts GET requests")
@@ -402,42 +404,61 @@ func (ws *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
 
 	// Parse query parameters
 	query := r.URL.Query()
-	limitStr := query.Get("limit")
-	sensorID := query.Get("sensor_id")
-	status := query.Get("status")
-
-	// Default limit
-	limit := 50
-	if limitStr != "" {
+	filter := sqlite.RunFilter{
+		SensorID:   query.Get("sensor_id"),
+		Status:     query.Get("status"),
+		SourceType: query.Get("source_type"),
+		Limit:      50,
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
 		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
+			filter.Limit = parsedLimit
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			ws.writeJSONError(w, http.StatusBadRequest, "invalid offset")
+			return
 		}
+		filter.Offset = parsed
+	}
+	// Optional created_at window (nanoseconds since epoch)
+	if s := query.Get("start_time"); s != "" {
+		parsed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			ws.writeJSONError(w, http.StatusBadRequest, "invalid start_time")
+			return
+		}
+		filter.Since = time.Unix(0, parsed)
+	}
+	if e := query.Get("end_time"); e != "" {
+		parsed, err := strconv.ParseInt(e, 10, 64)
+		if err != nil {
+			ws.writeJSONError(w, http.StatusBadRequest, "invalid end_time")
+			return
+		}
+		filter.Until = time.Unix(0, parsed)
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Since.After(filter.Until) {
+		ws.writeJSONError(w, http.StatusBadRequest, "start_time must be <= end_time")
+		return
 	}
 
 	// Fetch runs from database
 	store := sqlite.NewAnalysisRunStore(ws.db)
-	runs, err := store.ListRuns(limit)
+	runs, err := store.ListRunsFiltered(filter)
 	if err != nil {
 		ws.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("could not list runs: %v", err))
 		return
 	}
 
-	// Apply filters (sensor_id, status)
-	var filteredRuns []*sqlite.AnalysisRun
-	for _, run := range runs {
-		if sensorID != "" && run.SensorID != sensorID {
-			continue
-		}
-		if status != "" && run.Status != status {
-			continue
-		}
-		filteredRuns = append(filteredRuns, run)
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"runs":  filteredRuns,
-		"count": len(filteredRuns),
+		"runs":   runs,
+		"count":  len(runs),
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
 	})
 }
 
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/banshee-data/velocity.report/internal/db"
 	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
@@ -1153,6 +1155,70 @@ func TestCov_HandleListRuns_StatusFilterNoMatch(t *testing.T) {
 	}
 }
 
+func TestCov_HandleListRuns_FilterBeforeLimit(t *testing.T) {
+	ws, cleanup := covSetupWS(t)
+	defer cleanup()
+
+	store := sqlite.NewAnalysisRunStore(ws.db.DB)
+	base := time.Unix(1700000000, 0)
+	// The failed run is the oldest; an in-memory filter after LIMIT 1 would miss it.
+	for i, status := range []string{"failed", "completed", "completed"} {
+		run := &sqlite.AnalysisRun{
+			RunID:      fmt.Sprintf("cov-history-%d", i),
+			CreatedAt:  base.Add(time.Duration(i) * time.Hour),
+			SourceType: "pcap",
+			SensorID:   "test-sensor",
+			Status:     status,
+		}
+		if err := store.InsertRun(run); err != nil {
+			t.Fatalf("InsertRun: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/lidar/runs/?status=failed&source_type=pcap&limit=1", nil)
+	w := httptest.NewRecorder()
+	ws.handleListRuns(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp struct {
+		Runs  []sqlite.AnalysisRun `json:"runs"`
+		Count int                  `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 1 || resp.Runs[0].RunID != "cov-history-0" {
+		t.Errorf("unexpected runs: %+v", resp.Runs)
+	}
+
+	url := fmt.Sprintf("/api/lidar/runs/?start_time=%d&end_time=%d&offset=1",
+		base.Add(30*time.Minute).UnixNano(), base.Add(3*time.Hour).UnixNano())
+	req = httptest.NewRequest(http.MethodGet, url, nil)
+	w = httptest.NewRecorder()
+	ws.handleListRuns(w, req)
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 1 || resp.Runs[0].RunID != "cov-history-1" {
+		t.Errorf("unexpected windowed runs: %+v", resp.Runs)
+	}
+}
+
+func TestCov_HandleListRuns_BadParams(t *testing.T) {
+	ws, cleanup := covSetupWS(t)
+	defer cleanup()
+
+	for _, q := range []string{"offset=-1", "offset=x", "start_time=x", "end_time=x", "start_time=10&end_time=5"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/lidar/runs/?"+q, nil)
+		w := httptest.NewRecorder()
+		ws.handleListRuns(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", q, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
 // --- handleGetRun non-ErrNoRows DB error ---
 
 func TestCov_HandleGetRun_DBError(t *testing.T) {
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+)
+
+func registerIndexTestSensors(t *testing.T) (string, string) {
+	t.Helper()
+	stamp := time.Now().UnixNano()
+	north := fmt.Sprintf("index-north-%d", stamp)
+	south := fmt.Sprintf("index-south-%d", stamp)
+	l3grid.NewBackgroundManager(north, 10, 36, l3grid.BackgroundParams{}, nil)
+	l3grid.NewBackgroundManager(south, 10, 36, l3grid.BackgroundParams{}, nil).FreezeLearning()
+	return north, south
+}
+
+func TestHandleSensorIndex_ListsRegisteredSensors(t *testing.T) {
+	north, south := registerIndexTestSensors(t)
+	ws := &Server{sensorID: north}
+
+	w := httptest.NewRecorder()
+	ws.handleSensorIndex(w, httptest.NewRequest(http.MethodGet, "/lidar/sensors", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		north + " (default)",
+		"/lidar/server?sensor_id=" + url.QueryEscape(south),
+		"/debug/lidar?sensor_id=" + url.QueryEscape(south),
+		"learning frozen",
+		"360",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("index page missing %q", want)
+		}
+	}
+}
+
+func TestRenderSensorIndex_NoSensors(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&Server{}).renderSensorIndex(w, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "No sensors registered") {
+		t.Errorf("status = %d; body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleStatus_FollowsSelectedSensor(t *testing.T) {
+	north, south := registerIndexTestSensors(t)
+	ws := &Server{sensorID: north, stats: NewPacketStats(), latestFgCounts: map[string]int{}}
+
+	w := httptest.NewRecorder()
+	ws.handleStatus(w, httptest.NewRequest(http.MethodGet, "/?sensor_id="+url.QueryEscape(south), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "LiDAR Monitor - "+south) || !strings.Contains(body, "/api/lidar/params?sensor_id="+south) {
+		t.Error("status page should render the selected sensor")
+	}
+	if !strings.Contains(body, `href="/lidar/server?sensor_id=`+url.QueryEscape(north)+`"`) {
+		t.Error("status page should link to the other registered sensor")
+	}
+
+	w = httptest.NewRecorder()
+	ws.handleStatus(w, httptest.NewRequest(http.MethodGet, "/?sensor_id=no-such-sensor", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unknown sensor: status = %d", w.Code)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
 	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
 	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints"
+	"github.com/banshee-data/velocity.report/internal/lidar/logutil"
 	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
 )
 
@@ -53,6 +54,7 @@ func (e *switchError) Unwrap() error { return e.err }
 // It provides endpoints for health checks and real-time status information
 type Server struct {
 	address           string
+	basePath          string // URL prefix when served behind a reverse proxy, e.g. "/sensor1"; "" at the root
 	stats             *PacketStats
 	server            *http.Server
 	forwardingEnabled bool
@@ -106,6 +108,18 @@ type Server struct {
 	// Optional classifier reference for live threshold updates.
 	classifier *l6objects.TrackClassifier
 
+	// Optional count-line counter shared with the tracking pipeline.
+	tripwires *l6objects.TripwireCounter
+
+	// Optional ring buffer of captured log lines for the log endpoints.
+	logs *logutil.LogBuffer
+
+	// Optional live feed staleness watchdog.
+	liveWatchdog *LiveWatchdog
+
+	// Optional sensor-status webhook.
+	statusWebhook *StatusWebhook
+
 	// Analysis run manager for PCAP analysis mode
 	analysisRunManager *sqlite.AnalysisRunManager
 
@@ -128,6 +142,11 @@ type Server struct {
 	onPCAPProgress   func(currentPacket, totalPackets uint64)
 	onPCAPTimestamps func(startNs, endNs int64)
 
+	// Runtime tuning change log and diagnostic bundle guard
+	paramHistoryMu        sync.Mutex
+	paramHistory          []ParamChange
+	diagnosticsInProgress atomic.Bool
+
 	// Recording lifecycle callbacks
 	onRecordingStart func(runID string)
 	onRecordingStop  func(runID string) string
@@ -140,6 +159,7 @@ type Server struct {
 	onVRLogLoad       func(vrlogPath string) (string, error)
 	onVRLogStop       func()
 	getPlaybackStatus func() *PlaybackStatusInfo
+	getFrameAt        func(timestampNs int64) (*l9endpoints.FrameSnapshot, error)
 
 	// Sweep runner for web-triggered parameter sweeps
 	sweepRunner SweepRunner
@@ -152,6 +172,9 @@ type Server struct {
 
 	// Sweep store for persisting sweep results
 	sweepStore *sqlite.SweepStore
+
+	// Open live background grid WebSocket streams
+	gridStreams gridStreamHub
 }
 
 // PlaybackStatusInfo represents the current playback state for API responses.
@@ -182,13 +205,26 @@ type Config struct {
 	Parser            network.Parser
 	FrameBuilder      network.FrameBuilder
 	Classifier        *l6objects.TrackClassifier
-	PCAPSafeDir       string // Safe directory for PCAP file access (restricts path traversal)
-	VRLogSafeDir      string // Safe directory for VRLOG file access (restricts path traversal)
+	Tripwires         *l6objects.TripwireCounter // Count lines served at /api/lidar/tripwires; nil disables
+	Logs              *logutil.LogBuffer         // Captured log lines served at /api/lidar/logs; nil disables
+	LiveWatchdog      *LiveWatchdog              // Marks the live feed stale when frames stop; nil disables
+	StatusWebhook     *StatusWebhook             // Posts source, staleness and error transitions; nil disables
+	PCAPSafeDir       string                     // Safe directory for PCAP file access (restricts path traversal)
+	VRLogSafeDir      string                     // Safe directory for VRLOG file access (restricts path traversal)
 	PacketForwarder   *network.PacketForwarder
 	UDPListenerConfig network.UDPListenerConfig
 	PlotsBaseDir      string // Base directory for plot output (e.g., "plots")
 	TuningConfig      *cfgpkg.TuningConfig
 
+	// BasePath mounts the server under a URL prefix, for running behind a
+	// reverse proxy at e.g. /sensor1/. Routes, static assets and the links
+	// on rendered pages all carry the prefix. Empty serves from the root.
+	BasePath string
+
+	// HTTPLimits sets the HTTP server timeouts and request body cap.
+	// Zero fields use DefaultHTTPLimits.
+	HTTPLimits HTTPLimits
+
 	// DataSourceManager allows injecting a custom data source manager.
 	// If nil, a RealDataSourceManager is created automatically.
 	// Inject a MockDataSourceManager for testing.
@@ -226,6 +262,10 @@ type Config struct {
 	OnVRLogLoad       func(vrlogPath string) (string, error)
 	OnVRLogStop       func()
 	GetPlaybackStatus func() *PlaybackStatusInfo
+
+	// GetFrameAt returns the foreground points and track states of the
+	// replayed frame nearest a timestamp, for external labelling tools.
+	GetFrameAt func(timestampNs int64) (*l9endpoints.FrameSnapshot, error)
 }
 
 // NewServer creates a new web server with the provided configuration
@@ -259,6 +299,7 @@ func NewServer(config Config) *Server {
 
 	ws := &Server{
 		address:           config.Address,
+		basePath:          normaliseBasePath(config.BasePath),
 		stats:             config.Stats,
 		forwardingEnabled: config.ForwardingEnabled,
 		forwardAddr:       config.ForwardAddr,
@@ -270,6 +311,10 @@ func NewServer(config Config) *Server {
 		parser:            config.Parser,
 		frameBuilder:      config.FrameBuilder,
 		classifier:        config.Classifier,
+		tripwires:         config.Tripwires,
+		logs:              config.Logs,
+		liveWatchdog:      config.LiveWatchdog,
+		statusWebhook:     config.StatusWebhook,
 		pcapSafeDir:       config.PCAPSafeDir,
 		vrlogSafeDir:      vrlogSafeDir,
 		packetForwarder:   config.PacketForwarder,
@@ -290,9 +335,14 @@ func NewServer(config Config) *Server {
 		onPlaybackRate:    config.OnPlaybackRate,
 		onVRLogLoad:       config.OnVRLogLoad,
 		onVRLogStop:       config.OnVRLogStop,
+		getFrameAt:        config.GetFrameAt,
 		getPlaybackStatus: config.GetPlaybackStatus,
 	}
 
+	if config.StatusWebhook != nil && config.LiveWatchdog != nil {
+		config.LiveWatchdog.OnChange(config.StatusWebhook.LiveChanged)
+	}
+
 	// Initialize DataSourceManager - use provided one or create RealDataSourceManager
 	if config.DataSourceManager != nil {
 		ws.dataSourceManager = config.DataSourceManager
@@ -303,15 +353,22 @@ func NewServer(config Config) *Server {
 	// Initialise TrackAPI if database is configured
 	if config.DB != nil {
 		ws.trackAPI = NewTrackAPI(config.DB.DB, config.SensorID)
+		ws.trackAPI.liveWatchdog = config.LiveWatchdog
 		// Initialize AnalysisRunManager for PCAP analysis runs
 		ws.analysisRunManager = sqlite.NewAnalysisRunManager(config.DB, config.SensorID)
 		sqlite.RegisterAnalysisRunManager(config.SensorID, ws.analysisRunManager)
 	}
 
+	limits := config.HTTPLimits.withDefaults()
 	ws.server = &http.Server{
-		Addr:    ws.address,
-		Handler: api.LoggingMiddleware(ws.setupRoutes()),
+		Addr:              ws.address,
+		Handler:           api.LoggingMiddleware(limitRequestBody(limits.MaxBodyBytes, withBasePath(ws.basePath, ws.setupRoutes()))),
+		ReadHeaderTimeout: limits.ReadHeaderTimeout,
+		ReadTimeout:       limits.ReadTimeout,
+		WriteTimeout:      limits.WriteTimeout,
+		IdleTimeout:       limits.IdleTimeout,
 	}
+	ws.server.RegisterOnShutdown(ws.gridStreams.closeAll)
 
 	return ws
 }
@@ -351,6 +408,17 @@ func (ws *Server) Start(ctx context.Context) error {
 	}
 	ws.dataSourceMu.Unlock()
 
+	if ws.liveWatchdog != nil {
+		go ws.liveWatchdog.run(ctx, func() bool { return ws.CurrentSource() == DataSourceLive })
+	}
+	if ws.statusWebhook != nil {
+		errorTotal := func() int64 { return 0 }
+		if ws.stats != nil {
+			errorTotal = ws.stats.DroppedTotal
+		}
+		go ws.statusWebhook.run(ctx, statusWebhookInterval, ws.CurrentSource, errorTotal)
+	}
+
 	// Start server in a goroutine so it doesn't block
 	go func() {
 		diagf("Starting HTTP server on %s", ws.address)
@@ -416,6 +484,7 @@ func (ws *Server) Close() error {
 	if done != nil {
 		<-done
 	}
+	ws.gridStreams.closeAll()
 	if ws.server != nil {
 		return ws.server.Close()
 	}
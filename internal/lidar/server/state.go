@@ -53,6 +53,30 @@ func (ws *Server) SetTracker(tracker *l5tracks.Tracker) {
 	}
 }
 
+// SetMinDuration sets the per-class minimum track durations applied by the
+// track summary endpoint.
+func (ws *Server) SetMinDuration(cfg *l6objects.MinDurationConfig) {
+	if ws.trackAPI != nil {
+		ws.trackAPI.SetMinDuration(cfg)
+	}
+}
+
+// SetRoadAxis sets the road axis that track API velocities are decomposed
+// against.
+func (ws *Server) SetRoadAxis(axis *l5tracks.RoadAxis) {
+	if ws.trackAPI != nil {
+		ws.trackAPI.SetRoadAxis(axis)
+	}
+}
+
+// SetSpeedLimits sets the speed-limit tagger applied to track API
+// responses and the track summary.
+func (ws *Server) SetSpeedLimits(tagger *l6objects.SpeedLimitTagger) {
+	if ws.trackAPI != nil {
+		ws.trackAPI.SetSpeedLimits(tagger)
+	}
+}
+
 // SetClassifier sets the classifier reference used by the tracking pipeline.
 // This allows live updates of classification thresholds through /api/lidar/params.
 func (ws *Server) SetClassifier(classifier *l6objects.TrackClassifier) {
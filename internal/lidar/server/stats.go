@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/parse"
 	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints"
 )
 
@@ -17,10 +18,20 @@ type PacketStats struct {
 	packetCount    int64
 	byteCount      int64
 	droppedCount   int64
+	droppedTotal   int64 // never reset, for StatusWebhook error thresholds
 	pointCount     int64
 	lastReset      time.Time
 	startTime      time.Time
 	latestSnapshot *StatsSnapshot
+
+	jitterSource func() parse.TimestampJitterStats
+	lastClamped  int64
+
+	// Capture latency of the frames completed since the last snapshot.
+	captureFrames  int64
+	captureMin     time.Duration
+	captureMax     time.Duration
+	captureMeanSum time.Duration
 }
 
 // NewPacketStats creates a new PacketStats instance
@@ -45,6 +56,14 @@ func (ps *PacketStats) AddDropped() {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 	ps.droppedCount++
+	ps.droppedTotal++
+}
+
+// DroppedTotal returns the packets dropped since the stats were created.
+func (ps *PacketStats) DroppedTotal() int64 {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.droppedTotal
 }
 
 // AddPoints increments parsed point count
@@ -54,6 +73,32 @@ func (ps *PacketStats) AddPoints(count int) {
 	ps.pointCount += int64(count)
 }
 
+// AddCaptureLatency records a completed frame's capture latency: the
+// smallest, mean and largest difference between its points' packet capture
+// times and their timestamps. Only frames whose points carry a capture time
+// should be recorded.
+func (ps *PacketStats) AddCaptureLatency(minLatency, meanLatency, maxLatency time.Duration) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.captureFrames == 0 {
+		ps.captureMin, ps.captureMax = minLatency, maxLatency
+	} else {
+		ps.captureMin = min(ps.captureMin, minLatency)
+		ps.captureMax = max(ps.captureMax, maxLatency)
+	}
+	ps.captureMeanSum += meanLatency
+	ps.captureFrames++
+}
+
+// SetTimestampJitterSource registers the parser's timestamp jitter
+// counters, so their totals are included in snapshots and clamped packets
+// are logged.
+func (ps *PacketStats) SetTimestampJitterSource(source func() parse.TimestampJitterStats) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.jitterSource = source
+}
+
 // GetAndReset returns current stats and resets counters
 func (ps *PacketStats) GetAndReset() (packets int64, bytes int64, dropped int64, points int64, duration time.Duration) {
 	ps.mu.Lock()
@@ -85,13 +130,32 @@ func (ps *PacketStats) LogStats(parsePackets bool) {
 
 		// Store snapshot for web interface
 		ps.mu.Lock()
+		var jitter parse.TimestampJitterStats
+		if ps.jitterSource != nil {
+			jitter = ps.jitterSource()
+		}
+		clampedSinceLast := jitter.Clamped - ps.lastClamped
+		ps.lastClamped = jitter.Clamped
+		captureFrames := ps.captureFrames
+		var captureMin, captureMean, captureMax time.Duration
+		if captureFrames > 0 {
+			captureMin, captureMax = ps.captureMin, ps.captureMax
+			captureMean = ps.captureMeanSum / time.Duration(captureFrames)
+		}
+		ps.captureFrames, ps.captureMin, ps.captureMax, ps.captureMeanSum = 0, 0, 0, 0
 		ps.latestSnapshot = &StatsSnapshot{
-			PacketsPerSec: packetsPerSec,
-			MBPerSec:      mbPerSec,
-			PointsPerSec:  pointsPerSec,
-			DroppedCount:  dropped,
-			Timestamp:     time.Now(),
-			ParseEnabled:  parsePackets,
+			PacketsPerSec:       packetsPerSec,
+			MBPerSec:            mbPerSec,
+			PointsPerSec:        pointsPerSec,
+			DroppedCount:        dropped,
+			Timestamp:           time.Now(),
+			ParseEnabled:        parsePackets,
+			TimestampsClamped:   jitter.Clamped,
+			TimestampLargeJumps: jitter.LargeJumps,
+			CaptureFrames:       captureFrames,
+			CaptureLatencyMin:   captureMin,
+			CaptureLatencyMean:  captureMean,
+			CaptureLatencyMax:   captureMax,
 		}
 		ps.mu.Unlock()
 
@@ -107,6 +171,14 @@ func (ps *PacketStats) LogStats(parsePackets bool) {
 		if dropped > 0 {
 			logMsg += fmt.Sprintf(", %d dropped on forward", dropped)
 		}
+		if clampedSinceLast > 0 {
+			logMsg += fmt.Sprintf(", %d packet timestamps clamped", clampedSinceLast)
+		}
+
+		if captureFrames > 0 {
+			logMsg += fmt.Sprintf(", capture latency %v mean (%v-%v) over %d frames",
+				captureMean, captureMin, captureMax, captureFrames)
+		}
 
 		diagf("%s", logMsg)
 	}
@@ -4,6 +4,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l1packets/parse"
 )
 
 func TestNewPacketStats(t *testing.T) {
@@ -174,6 +176,47 @@ func TestPacketStats_GetLatestSnapshot(t *testing.T) {
 	}
 }
 
+func TestPacketStats_TimestampJitter(t *testing.T) {
+	stats := NewPacketStats()
+	stats.SetTimestampJitterSource(func() parse.TimestampJitterStats {
+		return parse.TimestampJitterStats{Clamped: 12, LargeJumps: 1}
+	})
+	stats.AddPacket(1262)
+	stats.LogStats(true)
+
+	snapshot := stats.GetLatestSnapshot()
+	if snapshot == nil || snapshot.TimestampsClamped != 12 || snapshot.TimestampLargeJumps != 1 {
+		t.Errorf("snapshot = %+v, want 12 clamped and 1 large jump", snapshot)
+	}
+}
+
+func TestPacketStats_CaptureLatency(t *testing.T) {
+	stats := NewPacketStats()
+	stats.AddCaptureLatency(2*time.Millisecond, 4*time.Millisecond, 9*time.Millisecond)
+	stats.AddCaptureLatency(1*time.Millisecond, 6*time.Millisecond, 7*time.Millisecond)
+	stats.AddPacket(1262)
+	stats.LogStats(true)
+
+	snapshot := stats.GetLatestSnapshot()
+	if snapshot == nil {
+		t.Fatal("Expected snapshot after LogStats, got nil")
+	}
+	if snapshot.CaptureFrames != 2 ||
+		snapshot.CaptureLatencyMin != time.Millisecond ||
+		snapshot.CaptureLatencyMean != 5*time.Millisecond ||
+		snapshot.CaptureLatencyMax != 9*time.Millisecond {
+		t.Errorf("snapshot = %+v, want 2 frames, 1ms min, 5ms mean, 9ms max", snapshot)
+	}
+
+	// The next interval starts empty.
+	stats.AddPacket(1262)
+	stats.LogStats(true)
+	snapshot = stats.GetLatestSnapshot()
+	if snapshot.CaptureFrames != 0 || snapshot.CaptureLatencyMax != 0 {
+		t.Errorf("snapshot = %+v, want capture latency reset", snapshot)
+	}
+}
+
 func TestPacketStats_ThreadSafety(t *testing.T) {
 	stats := NewPacketStats()
 
@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -100,6 +101,14 @@ func (ws *Server) handleTrafficStats(w http.ResponseWriter, r *http.Request) {
 		"parse_enabled":   snap.ParseEnabled,
 		"timestamp":       snap.Timestamp.Format(time.RFC3339Nano),
 		"uptime_secs":     uptime,
+
+		"timestamps_clamped":    snap.TimestampsClamped,
+		"timestamp_large_jumps": snap.TimestampLargeJumps,
+
+		"capture_frames":          snap.CaptureFrames,
+		"capture_latency_min_ms":  durationMs(snap.CaptureLatencyMin),
+		"capture_latency_mean_ms": durationMs(snap.CaptureLatencyMean),
+		"capture_latency_max_ms":  durationMs(snap.CaptureLatencyMax),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -108,7 +117,11 @@ func (ws *Server) handleTrafficStats(w http.ResponseWriter, r *http.Request) {
 
 // handleGridReset zeros the BackgroundGrid stats (times seen, averages, spreads)
 // and acceptance counters. This is intended only for testing A/B sweeps.
-// Method: POST. Query params: sensor_id (required)
+// With any scope bound set, only the cells in scope are cleared and the rest
+// of the model, the frame builder and the tracker are left alone.
+// Method: POST. Query params: sensor_id (required); optional scope bounds
+// az_min and az_max (degrees, wrapping when az_min > az_max), range_min and
+// range_max (metres, on the cell's learned range), region_id.
 func (ws *Server) handleGridReset(w http.ResponseWriter, r *http.Request) {
 	sensorID := r.URL.Query().Get("sensor_id")
 	if sensorID == "" {
@@ -121,6 +134,23 @@ func (ws *Server) handleGridReset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scope, err := parseGridResetScope(r.URL.Query())
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !scope.IsZero() {
+		cleared, err := mgr.ResetGridScope(scope)
+		if err != nil {
+			ws.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("could not reset grid region: %v", err))
+			return
+		}
+		diagf("[API:grid_reset] sensor=%s scoped cells_cleared=%d", sensorID, cleared)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "sensor_id": sensorID, "cells_reset": cleared})
+		return
+	}
+
 	// Log C: API call timing for grid_reset
 	beforeNanos := time.Now().UnixNano()
 
@@ -150,6 +180,80 @@ func (ws *Server) handleGridReset(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "sensor_id": sensorID})
 }
 
+// parseGridResetScope reads the optional grid_reset scope bounds.
+func parseGridResetScope(q url.Values) (l3grid.GridResetScope, error) {
+	var scope l3grid.GridResetScope
+	floats := []struct {
+		name string
+		dst  **float64
+	}{
+		{"az_min", &scope.AzimuthMinDeg},
+		{"az_max", &scope.AzimuthMaxDeg},
+		{"range_min", &scope.MinRangeMeters},
+		{"range_max", &scope.MaxRangeMeters},
+	}
+	for _, f := range floats {
+		v := q.Get(f.name)
+		if v == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return scope, fmt.Errorf("invalid %s: %q", f.name, v)
+		}
+		*f.dst = &parsed
+	}
+	if v := q.Get("region_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return scope, fmt.Errorf("invalid region_id: %q", v)
+		}
+		scope.RegionID = &id
+	}
+	return scope, scope.Validate()
+}
+
+// handleGridFreeze stops the background model learning: foreground is still
+// classified against the grid, but no cell is updated until thawed. Used to
+// hold the background fixed while varying clustering or tracking params.
+// Method: POST. Query params: sensor_id (required)
+func (ws *Server) handleGridFreeze(w http.ResponseWriter, r *http.Request) {
+	ws.setGridLearningFrozen(w, r, true)
+}
+
+// handleGridThaw resumes background learning after handleGridFreeze.
+// Method: POST. Query params: sensor_id (required)
+func (ws *Server) handleGridThaw(w http.ResponseWriter, r *http.Request) {
+	ws.setGridLearningFrozen(w, r, false)
+}
+
+func (ws *Server) setGridLearningFrozen(w http.ResponseWriter, r *http.Request, frozen bool) {
+	sensorID := r.URL.Query().Get("sensor_id")
+	if sensorID == "" {
+		ws.writeJSONError(w, http.StatusBadRequest, "the sensor_id parameter is required")
+		return
+	}
+	mgr := l3grid.GetBackgroundManager(sensorID)
+	if mgr == nil {
+		ws.writeJSONError(w, http.StatusNotFound, fmt.Sprintf("no background data available for sensor '%s': check it is connected and active", sensorID))
+		return
+	}
+
+	if frozen {
+		mgr.FreezeLearning()
+	} else {
+		mgr.ThawLearning()
+	}
+	opsf("[API:grid_learning] sensor=%s learning_frozen=%v", sensorID, frozen)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":          "ok",
+		"sensor_id":       sensorID,
+		"learning_frozen": mgr.IsLearningFrozen(),
+	})
+}
+
 // handleGridHeatmap returns aggregated grid metrics in coarse spatial buckets
 // for visualization and analysis of filled vs settled cells.
 // Query params:
@@ -234,11 +338,24 @@ func (ws *Server) handleDataSource(w http.ResponseWriter, r *http.Request) {
 		"analysis_mode":    analysisMode,
 		"last_run_id":      lastRunID,
 	}
+	if feed := ws.liveFeedStatus(currentSource); feed != nil {
+		response["live_feed"] = feed
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// liveFeedStatus returns the live feed watchdog state while the live
+// source is active, or nil when there is no watchdog or another source is.
+func (ws *Server) liveFeedStatus(source DataSource) *LiveWatchdogStatus {
+	if ws.liveWatchdog == nil || source != DataSourceLive {
+		return nil
+	}
+	st := ws.liveWatchdog.Status(time.Now())
+	return &st
+}
+
 // handleHealth handles the health check endpoint
 func (ws *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -266,20 +383,22 @@ func (ws *Server) handleLidarStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := struct {
-		Status           string         `json:"status"`
-		SensorID         string         `json:"sensor_id"`
-		UDPPort          int            `json:"udp_port"`
-		Forwarding       bool           `json:"forwarding_enabled"`
-		ForwardAddr      string         `json:"forward_addr,omitempty"`
-		ForwardPort      int            `json:"forward_port,omitempty"`
-		ParsingEnabled   bool           `json:"parsing_enabled"`
-		DataSource       string         `json:"data_source"`
-		PCAPFile         string         `json:"pcap_file,omitempty"`
-		PCAPInProgress   bool           `json:"pcap_in_progress"`
-		Uptime           string         `json:"uptime"`
-		Stats            *StatsSnapshot `json:"stats,omitempty"`
-		PCAPSafeDir      string         `json:"pcap_safe_dir,omitempty"`
-		BackgroundSensor string         `json:"background_sensor_id,omitempty"`
+		Status           string              `json:"status"`
+		SensorID         string              `json:"sensor_id"`
+		UDPPort          int                 `json:"udp_port"`
+		Forwarding       bool                `json:"forwarding_enabled"`
+		ForwardAddr      string              `json:"forward_addr,omitempty"`
+		ForwardPort      int                 `json:"forward_port,omitempty"`
+		ParsingEnabled   bool                `json:"parsing_enabled"`
+		DataSource       string              `json:"data_source"`
+		PCAPFile         string              `json:"pcap_file,omitempty"`
+		PCAPInProgress   bool                `json:"pcap_in_progress"`
+		Uptime           string              `json:"uptime"`
+		Stats            *StatsSnapshot      `json:"stats,omitempty"`
+		PCAPSafeDir      string              `json:"pcap_safe_dir,omitempty"`
+		BackgroundSensor string              `json:"background_sensor_id,omitempty"`
+		LiveFeed         *LiveWatchdogStatus `json:"live_feed,omitempty"`
+		Webhook          *WebhookStatus      `json:"status_webhook,omitempty"`
 	}{
 		Status:           "ok",
 		SensorID:         ws.sensorID,
@@ -295,6 +414,11 @@ func (ws *Server) handleLidarStatus(w http.ResponseWriter, r *http.Request) {
 		Stats:            statsSnapshot,
 		PCAPSafeDir:      ws.pcapSafeDir,
 		BackgroundSensor: ws.sensorID,
+		LiveFeed:         ws.liveFeedStatus(currentSource),
+	}
+	if ws.statusWebhook != nil {
+		st := ws.statusWebhook.Status()
+		response.Webhook = &st
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -309,6 +433,13 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Content-Type", "text/html")
 
+	// The page follows the selected sensor; links on it carry the same
+	// sensor_id so dashboards and charts stay on that sensor.
+	sensorID := r.URL.Query().Get("sensor_id")
+	if sensorID == "" {
+		sensorID = ws.sensorID
+	}
+
 	// Determine forwarding status
 	forwardingStatus := "disabled"
 	if ws.forwardingEnabled {
@@ -328,6 +459,9 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		mode = "PCAP Replay"
 	case DataSourceLive:
 		mode = "Live UDP"
+		if ws.liveWatchdog.Stale() {
+			mode = "Live UDP (stale: no frames)"
+		}
 	}
 	currentPCAPFile := ws.currentPCAPFile
 	ws.dataSourceMu.RUnlock()
@@ -343,7 +477,8 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	var bgParamsJSON string
 	var bgParamsJSONLines int
 
-	if mgr := l3grid.GetBackgroundManager(ws.sensorID); mgr != nil {
+	mgr := l3grid.GetBackgroundManager(sensorID)
+	if mgr != nil {
 		params := mgr.GetParams()
 		bgParams = &params
 
@@ -355,7 +490,7 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Refresh foreground snapshot counts for status rendering.
-	ws.updateLatestFgCounts(ws.sensorID)
+	ws.updateLatestFgCounts(sensorID)
 
 	// Load and parse the HTML template from embedded filesystem
 	statusFS, statusFSErr := l9endpoints.LegacyStatusFS()
@@ -371,6 +506,7 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 
 	// Template data
 	data := struct {
+		BasePath          string
 		Version           string
 		GitSHA            string
 		BuildTime         string
@@ -383,6 +519,8 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		Uptime            string
 		Stats             *StatsSnapshot
 		SensorID          string
+		Sensors           []string
+		SensorRegistered  bool
 		BGParams          *l3grid.BackgroundParams
 		BGParamsJSON      string
 		BGParamsJSONLines int
@@ -391,7 +529,9 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		PCAPSpeedMode     string
 		PCAPSpeedRatio    float64
 		FgSnapshotCounts  map[string]int
+		LogsEnabled       bool
 	}{
+		BasePath:          ws.basePath,
 		Version:           version.Version,
 		GitSHA:            version.GitSHA,
 		BuildTime:         version.BuildTime,
@@ -403,7 +543,9 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		PCAPSafeDir:       ws.pcapSafeDir,
 		Uptime:            ws.stats.GetUptime().Round(time.Second).String(),
 		Stats:             ws.stats.GetLatestSnapshot(),
-		SensorID:          ws.sensorID,
+		SensorID:          sensorID,
+		Sensors:           l3grid.RegisteredSensorIDs(),
+		SensorRegistered:  mgr != nil,
 		BGParams:          bgParams,
 		BGParamsJSON:      bgParamsJSON,
 		BGParamsJSONLines: bgParamsJSONLines,
@@ -412,6 +554,7 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		PCAPSpeedMode:     pcapSpeedMode,
 		PCAPSpeedRatio:    pcapSpeedRatio,
 		FgSnapshotCounts:  ws.getLatestFgCounts(),
+		LogsEnabled:       ws.logs != nil,
 	}
 
 	if err := tmpl.Execute(w, data); err != nil {
@@ -420,6 +563,65 @@ func (ws *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// sensorSummary is one row of the sensor index page.
+type sensorSummary struct {
+	SensorID        string
+	Default         bool
+	Settled         bool
+	LearningFrozen  bool
+	TotalCells      int
+	ForegroundCount int64
+	BackgroundCount int64
+}
+
+// handleSensorIndex renders the sensor selection page: every sensor with a
+// registered background manager, with links to its monitor and dashboards.
+// With no sensors registered it explains why rather than failing.
+func (ws *Server) handleSensorIndex(w http.ResponseWriter, r *http.Request) {
+	var sensors []sensorSummary
+	for _, id := range l3grid.RegisteredSensorIDs() {
+		mgr := l3grid.GetBackgroundManager(id)
+		if mgr == nil {
+			continue
+		}
+		summary := sensorSummary{
+			SensorID:       id,
+			Default:        id == ws.sensorID,
+			Settled:        mgr.IsSettlingComplete(),
+			LearningFrozen: mgr.IsLearningFrozen(),
+		}
+		status := mgr.GridStatus()
+		summary.TotalCells, _ = status["total_cells"].(int)
+		summary.ForegroundCount, _ = status["foreground_count"].(int64)
+		summary.BackgroundCount, _ = status["background_count"].(int64)
+		sensors = append(sensors, summary)
+	}
+	ws.renderSensorIndex(w, sensors)
+}
+
+// renderSensorIndex writes the sensor index page for sensors.
+func (ws *Server) renderSensorIndex(w http.ResponseWriter, sensors []sensorSummary) {
+	statusFS, err := l9endpoints.LegacyStatusFS()
+	if err != nil {
+		http.Error(w, "could not load status assets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tmpl, err := template.ParseFS(statusFS, "sensors.html")
+	if err != nil {
+		http.Error(w, "could not load sensor index template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	data := struct {
+		BasePath string
+		Sensors  []sensorSummary
+	}{ws.basePath, sensors}
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "could not render sensor index: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // handleLidarPersist triggers manual persistence of a BackgroundGrid snapshot.
 // Expects POST with form value or query param `sensor_id`.
 func (ws *Server) handleLidarPersist(w http.ResponseWriter, r *http.Request) {
@@ -485,11 +687,11 @@ func (ws *Server) handleAcceptanceMetrics(w http.ResponseWriter, r *http.Request
 
 	// Build richer response including totals and computed rates for convenience
 	type RichAcceptance struct {
-		BucketsMeters   []float64 `json:"BucketsMeters"`
-		AcceptCounts    []int64   `json:"AcceptCounts"`
-		RejectCounts    []int64   `json:"RejectCounts"`
-		Totals          []int64   `json:"Totals"`
-		AcceptanceRates []float64 `json:"AcceptanceRates"`
+		BucketsMeters   []l3grid.RangeBucket `json:"BucketsMeters"`
+		AcceptCounts    []int64              `json:"AcceptCounts"`
+		RejectCounts    []int64              `json:"RejectCounts"`
+		Totals          []int64              `json:"Totals"`
+		AcceptanceRates []float64            `json:"AcceptanceRates"`
 	}
 
 	totals := make([]int64, len(metrics.BucketsMeters))
@@ -511,7 +713,7 @@ func (ws *Server) handleAcceptanceMetrics(w http.ResponseWriter, r *http.Request
 	}
 
 	resp := RichAcceptance{
-		BucketsMeters:   metrics.BucketsMeters,
+		BucketsMeters:   l3grid.RangeBuckets(metrics.BucketsMeters),
 		AcceptCounts:    metrics.AcceptCounts,
 		RejectCounts:    metrics.RejectCounts,
 		Totals:          totals,
@@ -688,3 +890,8 @@ func (ws *Server) handleBackgroundRegions(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(info)
 }
+
+// durationMs converts d to fractional milliseconds for JSON responses.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
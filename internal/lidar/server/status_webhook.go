@@ -0,0 +1,351 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/adapters/httpretry"
+)
+
+// Sensor-status webhooks.
+//
+// Rather than poll the status endpoints, a NOC can register a URL that is
+// sent a JSON event whenever the sensor's state changes: the data source
+// switches, the live feed goes stale or resumes, or dropped packets cross
+// a threshold. Events are queued and delivered one at a time by a single
+// worker, retried with exponential backoff, and counted as dead letters
+// when every attempt fails or the queue is full. Delivery never blocks
+// the code that raised the event.
+
+// Status webhook event types.
+const (
+	WebhookEventSourceChanged   = "source_changed"
+	WebhookEventFeedStale       = "feed_stale"
+	WebhookEventFeedResumed     = "feed_resumed"
+	WebhookEventErrorsExceeded  = "errors_exceeded"
+	WebhookEventErrorsRecovered = "errors_recovered"
+)
+
+var webhookEvents = map[string]bool{
+	WebhookEventSourceChanged:   true,
+	WebhookEventFeedStale:       true,
+	WebhookEventFeedResumed:     true,
+	WebhookEventErrorsExceeded:  true,
+	WebhookEventErrorsRecovered: true,
+}
+
+// Webhook delivery headers. The signature is the hex HMAC-SHA256 of the
+// request body keyed by the shared secret, prefixed "sha256=".
+const (
+	WebhookSignatureHeader = "X-Velocity-Signature"
+	WebhookEventHeader     = "X-Velocity-Event"
+)
+
+// Defaults for StatusWebhookConfig.
+const (
+	DefaultWebhookErrorWindow    = time.Minute
+	DefaultWebhookMaxAttempts    = 5
+	DefaultWebhookInitialBackoff = time.Second
+	DefaultWebhookMaxBackoff     = 30 * time.Second
+	DefaultWebhookTimeout        = 10 * time.Second
+	webhookQueueSize             = 64
+
+	// statusWebhookInterval is how often the source and error count are
+	// sampled for transitions.
+	statusWebhookInterval = time.Second
+)
+
+// StatusWebhookConfig is the file form of a sensor-status webhook.
+type StatusWebhookConfig struct {
+	URL string `json:"url"`
+	// Events to send; empty sends every event type.
+	Events []string `json:"events,omitempty"`
+	// Secret signs each body with HMAC-SHA256; empty sends unsigned.
+	Secret string `json:"secret,omitempty"`
+	// ErrorThreshold is the number of dropped packets within ErrorWindow
+	// that raises errors_exceeded; zero disables the error events.
+	ErrorThreshold  int64   `json:"error_threshold,omitempty"`
+	ErrorWindowSecs float64 `json:"error_window_secs,omitempty"`
+
+	// Delivery retries; zero fields use the defaults.
+	MaxAttempts        int     `json:"max_attempts,omitempty"`
+	InitialBackoffSecs float64 `json:"initial_backoff_secs,omitempty"`
+	MaxBackoffSecs     float64 `json:"max_backoff_secs,omitempty"`
+	TimeoutSecs        float64 `json:"timeout_secs,omitempty"` // per attempt
+}
+
+// Validate checks the URL, event names and limits.
+func (c StatusWebhookConfig) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook url must be an http or https URL, got %q", c.URL)
+	}
+	for _, e := range c.Events {
+		if !webhookEvents[e] {
+			return fmt.Errorf("unknown webhook event %q", e)
+		}
+	}
+	if c.ErrorThreshold < 0 || c.ErrorWindowSecs < 0 || c.MaxAttempts < 0 ||
+		c.InitialBackoffSecs < 0 || c.MaxBackoffSecs < 0 || c.TimeoutSecs < 0 {
+		return fmt.Errorf("webhook limits must be >= 0")
+	}
+	return nil
+}
+
+// webhookDuration converts seconds to a duration, or def when unset.
+func webhookDuration(secs float64, def time.Duration) time.Duration {
+	if secs <= 0 {
+		return def
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+// LoadStatusWebhookConfig reads a StatusWebhookConfig from a JSON file.
+func LoadStatusWebhookConfig(path string) (StatusWebhookConfig, error) {
+	var cfg StatusWebhookConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse status webhook config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("status webhook config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// StatusEvent is the JSON body of one webhook delivery.
+type StatusEvent struct {
+	Event    string `json:"event"`
+	SensorID string `json:"sensor_id"`
+	Time     string `json:"time"` // RFC 3339, UTC
+	Seq      uint64 `json:"seq"`  // increases by one per event raised, so gaps show lost deliveries
+
+	DataSource     string `json:"data_source,omitempty"`
+	PreviousSource string `json:"previous_source,omitempty"`
+	ErrorCount     int64  `json:"error_count,omitempty"` // dropped packets in the error window
+	ErrorThreshold int64  `json:"error_threshold,omitempty"`
+}
+
+// WebhookStatus is the delivery state reported by the status API.
+type WebhookStatus struct {
+	Delivered   uint64 `json:"delivered"`
+	DeadLetters uint64 `json:"dead_letters"` // events abandoned after every attempt failed or the queue was full
+	Queued      int    `json:"queued"`
+	LastError   string `json:"last_error,omitempty"`
+}
+
+// StatusWebhook delivers sensor-status events to one URL.
+type StatusWebhook struct {
+	cfg         StatusWebhookConfig
+	errorWindow time.Duration
+	sensorID    string
+	events      map[string]bool // nil sends every event
+	client      *http.Client
+	queue       chan StatusEvent
+
+	seq         atomic.Uint64
+	delivered   atomic.Uint64
+	deadLetters atomic.Uint64
+
+	mu        sync.Mutex
+	lastError string
+
+	// state for run's transitions
+	lastSource   DataSource
+	errorsHigh   bool
+	errorHistory []errorSample
+}
+
+type errorSample struct {
+	at    time.Time
+	total int64
+}
+
+// NewStatusWebhook returns a webhook for a validated cfg. Events are
+// queued until Start runs the delivery worker.
+func NewStatusWebhook(cfg StatusWebhookConfig, sensorID string) *StatusWebhook {
+	attempts := cfg.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultWebhookMaxAttempts
+	}
+	h := &StatusWebhook{
+		cfg:         cfg,
+		errorWindow: webhookDuration(cfg.ErrorWindowSecs, DefaultWebhookErrorWindow),
+		sensorID:    sensorID,
+		queue:       make(chan StatusEvent, webhookQueueSize),
+		client: httpretry.NewClient(httpretry.Config{
+			MaxAttempts:    attempts,
+			InitialBackoff: webhookDuration(cfg.InitialBackoffSecs, DefaultWebhookInitialBackoff),
+			MaxBackoff:     webhookDuration(cfg.MaxBackoffSecs, DefaultWebhookMaxBackoff),
+			AttemptTimeout: webhookDuration(cfg.TimeoutSecs, DefaultWebhookTimeout),
+			// Delivery is at least once: a retried event may arrive
+			// twice, and receivers de-duplicate on seq.
+			RetryNonIdempotent: true,
+			Logf:               diagf,
+		}),
+	}
+	if len(cfg.Events) > 0 {
+		h.events = make(map[string]bool, len(cfg.Events))
+		for _, e := range cfg.Events {
+			h.events[e] = true
+		}
+	}
+	return h
+}
+
+// Raise queues ev for delivery if its type is configured, filling in the
+// sensor, time and sequence number. It never blocks: when the queue is
+// full the event is counted as a dead letter.
+func (h *StatusWebhook) Raise(ev StatusEvent, now time.Time) {
+	if h == nil || (h.events != nil && !h.events[ev.Event]) {
+		return
+	}
+	ev.SensorID = h.sensorID
+	ev.Time = now.UTC().Format(time.RFC3339Nano)
+	ev.Seq = h.seq.Add(1)
+	select {
+	case h.queue <- ev:
+	default:
+		h.deadLetters.Add(1)
+		h.setLastError("queue full")
+		opsf("status webhook: queue full, dropped %s event %d", ev.Event, ev.Seq)
+	}
+}
+
+// LiveChanged raises feed_stale or feed_resumed; register it with
+// LiveWatchdog.OnChange.
+func (h *StatusWebhook) LiveChanged(stale bool) {
+	ev := WebhookEventFeedResumed
+	if stale {
+		ev = WebhookEventFeedStale
+	}
+	h.Raise(StatusEvent{Event: ev, DataSource: string(DataSourceLive)}, time.Now())
+}
+
+// Status returns the delivery counters.
+func (h *StatusWebhook) Status() WebhookStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return WebhookStatus{
+		Delivered:   h.delivered.Load(),
+		DeadLetters: h.deadLetters.Load(),
+		Queued:      len(h.queue),
+		LastError:   h.lastError,
+	}
+}
+
+// observe compares the data source and dropped-packet total at now with
+// the previous call and raises events for the transitions.
+func (h *StatusWebhook) observe(now time.Time, source DataSource, errorTotal int64) {
+	if h.lastSource != "" && source != h.lastSource {
+		h.Raise(StatusEvent{Event: WebhookEventSourceChanged, DataSource: string(source), PreviousSource: string(h.lastSource)}, now)
+	}
+	h.lastSource = source
+
+	if h.cfg.ErrorThreshold <= 0 {
+		return
+	}
+	h.errorHistory = append(h.errorHistory, errorSample{at: now, total: errorTotal})
+	for len(h.errorHistory) > 1 && now.Sub(h.errorHistory[1].at) >= h.errorWindow {
+		h.errorHistory = h.errorHistory[1:]
+	}
+	inWindow := errorTotal - h.errorHistory[0].total
+	switch {
+	case !h.errorsHigh && inWindow >= h.cfg.ErrorThreshold:
+		h.errorsHigh = true
+		h.Raise(StatusEvent{Event: WebhookEventErrorsExceeded, DataSource: string(source), ErrorCount: inWindow, ErrorThreshold: h.cfg.ErrorThreshold}, now)
+	case h.errorsHigh && inWindow < h.cfg.ErrorThreshold:
+		h.errorsHigh = false
+		h.Raise(StatusEvent{Event: WebhookEventErrorsRecovered, DataSource: string(source), ErrorCount: inWindow, ErrorThreshold: h.cfg.ErrorThreshold}, now)
+	}
+}
+
+// run delivers queued events and samples source and errorTotal every
+// interval until ctx ends.
+func (h *StatusWebhook) run(ctx context.Context, interval time.Duration, source func() DataSource, errorTotal func() int64) {
+	go h.deliverAll(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.observe(time.Now(), source(), errorTotal())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *StatusWebhook) deliverAll(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-h.queue:
+			if err := h.deliver(ctx, ev); err != nil {
+				h.deadLetters.Add(1)
+				h.setLastError(err.Error())
+				opsf("status webhook: %s event %d dead-lettered: %v", ev.Event, ev.Seq, err)
+				continue
+			}
+			h.delivered.Add(1)
+		}
+	}
+}
+
+// deliver POSTs one event; transient failures are retried by the client.
+func (h *StatusWebhook) deliver(ctx context.Context, ev StatusEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, ev.Event)
+	if h.cfg.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookBody(h.cfg.Secret, body))
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (h *StatusWebhook) setLastError(msg string) {
+	h.mu.Lock()
+	h.lastError = msg
+	h.mu.Unlock()
+}
+
+// SignWebhookBody returns the signature header value for body: "sha256="
+// and the hex HMAC-SHA256 keyed by secret. Receivers recompute it over the
+// raw body and compare with hmac.Equal.
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
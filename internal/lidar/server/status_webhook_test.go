@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver records the events posted to it whose signature checks
+// out against secret, and counts those that do not.
+type webhookReceiver struct {
+	secret string
+	status int // response code; zero means 204
+
+	mu       sync.Mutex
+	events   []StatusEvent
+	badSigs  int
+	received chan struct{}
+}
+
+func newWebhookReceiver(t *testing.T, secret string, status int) (*webhookReceiver, *httptest.Server) {
+	rcv := &webhookReceiver{secret: secret, status: status, received: make(chan struct{}, 16)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.mu.Lock()
+		want := SignWebhookBody(rcv.secret, body)
+		if !hmac.Equal([]byte(r.Header.Get(WebhookSignatureHeader)), []byte(want)) {
+			rcv.badSigs++
+		} else {
+			var ev StatusEvent
+			if err := json.Unmarshal(body, &ev); err != nil {
+				t.Errorf("decode event: %v", err)
+			}
+			if r.Header.Get(WebhookEventHeader) != ev.Event {
+				t.Errorf("event header %q, body event %q", r.Header.Get(WebhookEventHeader), ev.Event)
+			}
+			rcv.events = append(rcv.events, ev)
+		}
+		rcv.mu.Unlock()
+		if rcv.status != 0 {
+			w.WriteHeader(rcv.status)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		rcv.received <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return rcv, srv
+}
+
+func (rcv *webhookReceiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-rcv.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d of %d deliveries", i, n)
+		}
+	}
+}
+
+// waitForWebhook polls until cond holds on the webhook's status, which
+// is updated just after the receiver has answered.
+func waitForWebhook(t *testing.T, hook *StatusWebhook, cond func(WebhookStatus) bool) WebhookStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st := hook.Status()
+		if cond(st) || time.Now().After(deadline) {
+			return st
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestStatusWebhook_FiresSignedEventOnStaleness simulates the live feed
+// going silent and resuming. The receiver gets a feed_stale and then a
+// feed_resumed event, each signed with the shared secret.
+func TestStatusWebhook_FiresSignedEventOnStaleness(t *testing.T) {
+	rcv, srv := newWebhookReceiver(t, "noc-secret", 0)
+	hook := NewStatusWebhook(StatusWebhookConfig{URL: srv.URL, Secret: "noc-secret"}, "hesai-01")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hook.deliverAll(ctx)
+
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	wd := NewLiveWatchdog(2*time.Second, start)
+	wd.OnChange(hook.LiveChanged)
+
+	wd.Observe(start.Add(100 * time.Millisecond))
+	if wd.Check(start.Add(time.Second)) {
+		t.Fatal("stale before the timeout")
+	}
+	if !wd.Check(start.Add(3 * time.Second)) {
+		t.Fatal("not stale after the timeout")
+	}
+	rcv.wait(t, 1)
+	wd.Observe(start.Add(4 * time.Second))
+	rcv.wait(t, 1)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	if rcv.badSigs != 0 {
+		t.Errorf("%d deliveries with a bad signature", rcv.badSigs)
+	}
+	if len(rcv.events) != 2 {
+		t.Fatalf("got %d events, want 2", len(rcv.events))
+	}
+	stale, resumed := rcv.events[0], rcv.events[1]
+	if stale.Event != WebhookEventFeedStale || stale.SensorID != "hesai-01" || stale.DataSource != "live" || stale.Seq != 1 {
+		t.Errorf("first event = %+v, want feed_stale from hesai-01, seq 1", stale)
+	}
+	if resumed.Event != WebhookEventFeedResumed || resumed.Seq != 2 {
+		t.Errorf("second event = %+v, want feed_resumed, seq 2", resumed)
+	}
+	if st := waitForWebhook(t, hook, func(st WebhookStatus) bool { return st.Delivered == 2 }); st.Delivered != 2 || st.DeadLetters != 0 {
+		t.Errorf("status = %+v, want 2 delivered", st)
+	}
+}
+
+func TestStatusWebhook_SourceAndErrorTransitions(t *testing.T) {
+	hook := NewStatusWebhook(StatusWebhookConfig{
+		URL:             "http://noc.invalid/hook",
+		Events:          []string{WebhookEventSourceChanged, WebhookEventErrorsExceeded, WebhookEventErrorsRecovered},
+		ErrorThreshold:  10,
+		ErrorWindowSecs: 60,
+	}, "s")
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	step := func(d time.Duration, source DataSource, errors int64) {
+		now = now.Add(d)
+		hook.observe(now, source, errors)
+	}
+	step(0, DataSourceLive, 0)
+	step(time.Second, DataSourceLive, 4)
+	step(time.Second, DataSourcePCAP, 12) // 12 dropped within the minute
+	step(30*time.Second, DataSourcePCAP, 13)
+	step(40*time.Second, DataSourceLive, 14) // only 2 in the last minute
+	hook.LiveChanged(true)                   // not subscribed
+
+	var got []StatusEvent
+	for len(hook.queue) > 0 {
+		got = append(got, <-hook.queue)
+	}
+	want := []string{WebhookEventSourceChanged, WebhookEventErrorsExceeded, WebhookEventSourceChanged, WebhookEventErrorsRecovered}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events %+v, want %v", len(got), got, want)
+	}
+	for i, ev := range got {
+		if ev.Event != want[i] {
+			t.Errorf("event %d = %s, want %s", i, ev.Event, want[i])
+		}
+	}
+	if got[0].PreviousSource != "live" || got[0].DataSource != "pcap" {
+		t.Errorf("source change = %+v, want live to pcap", got[0])
+	}
+	if got[1].ErrorCount != 12 || got[1].ErrorThreshold != 10 {
+		t.Errorf("errors exceeded = %+v, want 12 of threshold 10", got[1])
+	}
+}
+
+func TestStatusWebhook_DeadLetter(t *testing.T) {
+	rcv, srv := newWebhookReceiver(t, "", http.StatusServiceUnavailable)
+	hook := NewStatusWebhook(StatusWebhookConfig{URL: srv.URL, MaxAttempts: 3, InitialBackoffSecs: 0.001}, "s")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hook.deliverAll(ctx)
+
+	hook.Raise(StatusEvent{Event: WebhookEventFeedStale}, time.Now())
+	rcv.wait(t, 3) // retried to the attempt limit
+
+	if st := waitForWebhook(t, hook, func(st WebhookStatus) bool { return st.DeadLetters > 0 }); st.DeadLetters != 1 || st.Delivered != 0 || st.LastError == "" {
+		t.Errorf("status = %+v, want one dead letter with its error", st)
+	}
+}
+
+func TestLoadStatusWebhookConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	cfg, err := LoadStatusWebhookConfig(write("ok.json", `{"url": "https://noc.example/hook", "events": ["feed_stale"], "secret": "x", "error_threshold": 50}`))
+	if err != nil {
+		t.Fatalf("LoadStatusWebhookConfig: %v", err)
+	}
+	if cfg.URL != "https://noc.example/hook" || cfg.ErrorThreshold != 50 || len(cfg.Events) != 1 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	for name, body := range map[string]string{
+		"no-url.json":    `{"events": ["feed_stale"]}`,
+		"bad-event.json": `{"url": "https://noc.example/hook", "events": ["reboot"]}`,
+		"negative.json":  `{"url": "https://noc.example/hook", "max_attempts": -1}`,
+	} {
+		if _, err := LoadStatusWebhookConfig(write(name, body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
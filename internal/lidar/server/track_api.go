@@ -9,7 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/banshee-data/velocity.report/internal/lidar/adapters"
 	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
 	"github.com/banshee-data/velocity.report/internal/lidar/l8analytics"
 	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
 )
@@ -20,6 +22,22 @@ type TrackAPI struct {
 	db       *sqlite.SQLDB
 	sensorID string
 	tracker  *l5tracks.Tracker // Optional: in-memory tracker for real-time queries
+
+	// minDuration, when set, drops tracks shorter than their class minimum
+	// from the summary (see l6objects.MinDurationConfig).
+	minDuration *l6objects.MinDurationConfig
+
+	// roadAxis, when set, adds along- and cross-road velocity to track
+	// responses.
+	roadAxis *l5tracks.RoadAxis
+
+	// speedLimits, when set, tags confirmed vehicle tracks as over or
+	// under their speed limit in track responses and the summary.
+	speedLimits *l6objects.SpeedLimitTagger
+
+	// liveWatchdog, when set, empties the active track list while the
+	// live feed is stale so silent sensors do not show frozen tracks.
+	liveWatchdog *LiveWatchdog
 }
 
 // NewTrackAPI creates a new TrackAPI instance.
@@ -35,6 +53,24 @@ func (api *TrackAPI) SetTracker(tracker *l5tracks.Tracker) {
 	api.tracker = tracker
 }
 
+// SetMinDuration sets the per-class minimum track durations applied to the
+// track summary. Nil reports every track.
+func (api *TrackAPI) SetMinDuration(cfg *l6objects.MinDurationConfig) {
+	api.minDuration = cfg
+}
+
+// SetRoadAxis sets the road axis that track velocities are decomposed
+// against. Nil omits road_velocity from responses.
+func (api *TrackAPI) SetRoadAxis(axis *l5tracks.RoadAxis) {
+	api.roadAxis = axis
+}
+
+// SetSpeedLimits sets the tagger that compares confirmed vehicle tracks
+// with their speed limit. Nil omits speed_limit from responses.
+func (api *TrackAPI) SetSpeedLimits(tagger *l6objects.SpeedLimitTagger) {
+	api.speedLimits = tagger
+}
+
 // handleClearTracks deletes all tracks, observations, and clusters for a sensor.
 // Method: POST (or GET for convenience). Query param: sensor_id (required).
 func (api *TrackAPI) handleClearTracks(w http.ResponseWriter, r *http.Request) {
@@ -110,12 +146,19 @@ func (api *TrackAPI) handleClearRuns(w http.ResponseWriter, r *http.Request) {
 //   - start (optional): start timestamp (unix seconds)
 //   - end (optional): end timestamp (unix seconds)
 //   - limit (optional): max results (default 100)
+//   - format (optional): json, csv or msgpack; overrides the Accept header
 func (api *TrackAPI) handleListTracks(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		api.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
+	format, err := adapters.NegotiateFormat(r)
+	if err != nil {
+		api.writeJSONError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+
 	sensorID := r.URL.Query().Get("sensor_id")
 	if sensorID == "" {
 		sensorID = api.sensorID
@@ -161,8 +204,6 @@ func (api *TrackAPI) handleListTracks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var tracks []*l5tracks.TrackedObject
-	var err error
-
 	if startParam != "" || endParam != "" {
 		tracks, err = sqlite.GetTracksInRange(api.db, sensorID, state, startNanos, endNanos, limit)
 	} else {
@@ -176,18 +217,23 @@ func (api *TrackAPI) handleListTracks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	stale := api.liveWatchdog.Stale()
+	if stale {
+		tracks = nil
+	}
+
 	response := TracksListResponse{
 		Tracks:    make([]TrackResponse, 0, len(tracks)),
 		Count:     len(tracks),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Stale:     stale,
 	}
 
 	for _, track := range tracks {
 		response.Tracks = append(response.Tracks, api.trackToResponse(track))
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	api.writeExport(w, format, response, response.Tracks)
 }
 
 // handleListObservations handles GET /api/lidar/observations
@@ -197,12 +243,19 @@ func (api *TrackAPI) handleListTracks(w http.ResponseWriter, r *http.Request) {
 //   - track_id (optional): limit to a single track
 //   - start_time, end_time (unix nanoseconds; defaults to last 5 minutes)
 //   - limit (optional, default 1000, max 5000)
+//   - format (optional): json, csv or msgpack; overrides the Accept header
 func (api *TrackAPI) handleListObservations(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		api.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
+	format, err := adapters.NegotiateFormat(r)
+	if err != nil {
+		api.writeJSONError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+
 	if api.db == nil {
 		api.writeJSONError(w, http.StatusServiceUnavailable, "database not configured")
 		return
@@ -293,8 +346,7 @@ func (api *TrackAPI) handleListObservations(w http.ResponseWriter, r *http.Reque
 		})
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	api.writeExport(w, format, response, response.Observations)
 }
 
 // handleActiveTracks handles GET /api/lidar/tracks/active
@@ -302,61 +354,78 @@ func (api *TrackAPI) handleListObservations(w http.ResponseWriter, r *http.Reque
 // Query params:
 //   - sensor_id (optional)
 //   - state (optional): confirmed, tentative, all (default: all non-deleted)
+//   - format (optional): json, csv or msgpack; overrides the Accept header
 func (api *TrackAPI) handleActiveTracks(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		api.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
+	format, err := adapters.NegotiateFormat(r)
+	if err != nil {
+		api.writeJSONError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+
 	sensorID := r.URL.Query().Get("sensor_id")
 	if sensorID == "" {
 		sensorID = api.sensorID
 	}
 
-	state := r.URL.Query().Get("state")
+	tracks, status, err := api.activeTracks(sensorID, r.URL.Query().Get("state"))
+	if err != nil {
+		api.writeJSONError(w, status, err.Error())
+		return
+	}
 
-	var tracks []*l5tracks.TrackedObject
+	stale := api.liveWatchdog.Stale()
+	if stale {
+		tracks = nil
+	}
+
+	response := TracksListResponse{
+		Tracks:    make([]TrackResponse, 0, len(tracks)),
+		Count:     len(tracks),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Stale:     stale,
+	}
+
+	for _, track := range tracks {
+		response.Tracks = append(response.Tracks, api.trackToResponse(track))
+	}
 
-	// Prefer in-memory tracker for real-time data
+	api.writeExport(w, format, response, response.Tracks)
+}
+
+// activeTracks returns the current tracks for sensorID, optionally filtered
+// by state ("confirmed" or "tentative"). The in-memory tracker is preferred
+// for real-time data; the database is the fallback. On error the returned
+// status is the HTTP status to report.
+func (api *TrackAPI) activeTracks(sensorID, state string) ([]*l5tracks.TrackedObject, int, error) {
 	if api.tracker != nil {
 		switch state {
 		case "confirmed":
-			tracks = api.tracker.GetConfirmedTracks()
+			return api.tracker.GetConfirmedTracks(), http.StatusOK, nil
 		case "tentative":
-			allActive := api.tracker.GetActiveTracks()
-			for _, t := range allActive {
+			var tracks []*l5tracks.TrackedObject
+			for _, t := range api.tracker.GetActiveTracks() {
 				if t.TrackState == l5tracks.TrackTentative {
 					tracks = append(tracks, t)
 				}
 			}
+			return tracks, http.StatusOK, nil
 		default:
-			tracks = api.tracker.GetActiveTracks()
+			return api.tracker.GetActiveTracks(), http.StatusOK, nil
 		}
-	} else if api.db != nil {
-		// Fall back to database
-		dbTracks, err := sqlite.GetActiveTracks(api.db, sensorID, state)
+	}
+	if api.db != nil {
+		tracks, err := sqlite.GetActiveTracks(api.db, sensorID, state)
 		if err != nil {
-			api.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to get tracks: %v", err))
-			return
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to get tracks: %v", err)
 		}
-		tracks = dbTracks
-	} else {
-		api.writeJSONError(w, http.StatusServiceUnavailable, "no tracker or database configured")
-		return
-	}
-
-	response := TracksListResponse{
-		Tracks:    make([]TrackResponse, 0, len(tracks)),
-		Count:     len(tracks),
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		return tracks, http.StatusOK, nil
 	}
-
-	for _, track := range tracks {
-		response.Tracks = append(response.Tracks, api.trackToResponse(track))
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return nil, http.StatusServiceUnavailable, fmt.Errorf("no tracker or database configured")
 }
 
 // handleTrackByID handles:
@@ -507,13 +576,20 @@ func (api *TrackAPI) handleUpdateTrack(w http.ResponseWriter, r *http.Request, t
 	json.NewEncoder(w).Encode(response)
 }
 
-// handleTrackObservations returns the observation history for a track.
+// handleTrackObservations returns the observation history for a track, in
+// the format chosen by adapters.NegotiateFormat.
 func (api *TrackAPI) handleTrackObservations(w http.ResponseWriter, r *http.Request, trackID string) {
 	if api.db == nil {
 		api.writeJSONError(w, http.StatusServiceUnavailable, "database not configured")
 		return
 	}
 
+	format, err := adapters.NegotiateFormat(r)
+	if err != nil {
+		api.writeJSONError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+
 	limit := 100
 	if l := r.URL.Query().Get("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 1000 {
@@ -587,8 +663,7 @@ func (api *TrackAPI) handleTrackObservations(w http.ResponseWriter, r *http.Requ
 		})
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	api.writeExport(w, format, response, response.Observations)
 }
 
 // handleTrackSummary handles GET /api/lidar/tracks/summary
@@ -598,6 +673,11 @@ func (api *TrackAPI) handleTrackObservations(w http.ResponseWriter, r *http.Requ
 //   - start (optional): start timestamp (unix seconds)
 //   - end (optional): end timestamp (unix seconds)
 //   - group_by (optional): "object_class" (default)
+//   - include_flicker (optional): "true" keeps tracks shorter than their
+//     class minimum duration in the counts (debugging)
+//
+// With speed limits set, speed_limits counts tagged and over-limit tracks
+// by sensor, zone and direction.
 func (api *TrackAPI) handleTrackSummary(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		api.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -633,6 +713,11 @@ func (api *TrackAPI) handleTrackSummary(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	var flicker map[string]int
+	if api.minDuration != nil && r.URL.Query().Get("include_flicker") != "true" {
+		tracks, flicker = api.minDuration.FilterFlickerTracks(tracks)
+	}
+
 	summary := l8analytics.ComputeTrackSummary(tracks)
 
 	response := TrackSummaryResponse{
@@ -640,8 +725,14 @@ func (api *TrackAPI) handleTrackSummary(w http.ResponseWriter, r *http.Request)
 		ByClass:   summary.ByClass,
 		ByState:   summary.ByState,
 		Overall:   summary.Overall,
+		Speed:     l6objects.ComputeSpeedStatistics(l6objects.TrackSpeedSamples(tracks)),
+		Flicker:   flicker,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	}
+	if api.speedLimits != nil {
+		sl := l6objects.SummariseSpeedLimitTags(api.speedLimits.TagAll(tracks))
+		response.SpeedLimits = &sl
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -722,6 +813,8 @@ func (api *TrackAPI) handleListClusters(w http.ResponseWriter, r *http.Request)
 			PointsCount:   cluster.PointsCount,
 			HeightP95:     cluster.HeightP95,
 			IntensityMean: cluster.IntensityMean,
+
+			PointsCountNormalized: cluster.PointsCountNormalized,
 		}
 		cr.BoundingBox.Length = cluster.BoundingBoxLength
 		cr.BoundingBox.Width = cluster.BoundingBoxWidth
@@ -761,3 +854,39 @@ func (api *TrackAPI) handleTrackingMetrics(w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(metrics)
 }
+
+// handleTrackInnovations exports the Kalman innovation diagnostics used to
+// tune process and measurement noise: per-track mean NIS and the share of
+// updates inside the 95% chi-square band.
+//
+// GET /api/lidar/tracks/innovations
+// Optional query parameter: include_samples=true to include each update's innovation
+//
+// POST /api/lidar/tracks/innovations with {"enabled": true|false} turns
+// recording on or off.
+func (api *TrackAPI) handleTrackInnovations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		api.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed; use GET or POST")
+		return
+	}
+
+	if api.tracker == nil {
+		api.writeJSONError(w, http.StatusServiceUnavailable, "in-memory tracker not available")
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var req struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+			api.writeJSONError(w, http.StatusBadRequest, "body must be {\"enabled\": true|false}")
+			return
+		}
+		api.tracker.UpdateConfig(func(c *l5tracks.TrackerConfig) { c.RecordInnovations = *req.Enabled })
+	}
+
+	includeSamples := r.URL.Query().Get("include_samples") == "true"
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.tracker.GetInnovationDiagnostics(includeSamples))
+}
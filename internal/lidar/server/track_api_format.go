@@ -6,32 +6,45 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/banshee-data/velocity.report/internal/lidar/adapters"
 	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
 	"github.com/banshee-data/velocity.report/internal/lidar/l8analytics"
 )
 
 // TrackResponse represents a track in JSON API responses.
 type TrackResponse struct {
-	TrackID             string               `json:"track_id"`
-	SensorID            string               `json:"sensor_id"`
-	State               string               `json:"state"`
-	Position            Position             `json:"position"`
-	Velocity            Velocity             `json:"velocity"`
-	SpeedMps            float32              `json:"speed_mps"`
-	HeadingRad          float32              `json:"heading_rad"`
-	ObjectClass         string               `json:"object_class,omitempty"`
-	ObjectConfidence    float32              `json:"object_confidence,omitempty"`
-	ClassificationModel string               `json:"classification_model,omitempty"`
-	ObservationCount    int                  `json:"observation_count"`
-	AgeSeconds          float64              `json:"age_seconds"`
-	AvgSpeedMps         float32              `json:"avg_speed_mps"`
-	MaxSpeedMps         float32              `json:"max_speed_mps"`
-	BoundingBox         BBox                 `json:"bounding_box"`
-	OBBHeadingRad       float32              `json:"obb_heading_rad"`
-	HeadingSource       int                  `json:"heading_source,omitempty"` // 0=PCA, 1=velocity, 2=displacement, 3=locked
-	FirstSeen           string               `json:"first_seen"`
-	LastSeen            string               `json:"last_seen"`
-	History             []TrackPointResponse `json:"history,omitempty"`
+	TrackID              string                 `json:"track_id"`
+	SensorID             string                 `json:"sensor_id"`
+	State                string                 `json:"state"`
+	Position             Position               `json:"position"`
+	Velocity             Velocity               `json:"velocity"`
+	VelocityStdDev       *Velocity              `json:"velocity_std_dev,omitempty"` // Kalman 1σ; absent for tracks without live covariance
+	SpeedMps             float32                `json:"speed_mps"`
+	SpeedStdDevMps       float32                `json:"speed_std_dev_mps,omitempty"`
+	RoadVelocity         *l5tracks.RoadVelocity `json:"road_velocity,omitempty"` // along/cross-road components; only with a road axis
+	SpeedLimit           *SpeedLimitTag         `json:"speed_limit,omitempty"`   // over_limit and margin against the applicable limit; only with speed limits
+	HeadingRad           float32                `json:"heading_rad"`
+	ObjectClass          string                 `json:"object_class,omitempty"`
+	ObjectConfidence     float32                `json:"object_confidence,omitempty"`
+	ClassificationModel  string                 `json:"classification_model,omitempty"`
+	ObservationCount     int                    `json:"observation_count"`
+	CoastedFrames        int                    `json:"coasted_frames"`                  // frames coasted between the first and last observation
+	DetectionReliability float32                `json:"detection_reliability,omitempty"` // observed share of those frames, in [0, 1]
+	AgeSeconds           float64                `json:"age_seconds"`
+	AvgSpeedMps          float32                `json:"avg_speed_mps"`
+	MaxSpeedMps          float32                `json:"max_speed_mps"`
+	CompositeSpeedMps    float32                `json:"composite_speed_mps,omitempty"` // confidence-weighted speed to report; absent for tracks stored before migration 000045
+	InstantSpeedMps      float32                `json:"instant_speed_mps,omitempty"`   // unsmoothed Kalman speed from the latest update; live tracks only
+	BoundingBox          BBox                   `json:"bounding_box"`
+	MeasuredBoundingBox  *BBox                  `json:"measured_bounding_box,omitempty"` // raw cluster box; only when bounding_box was regularised by a class prior
+	DimensionConfidence  float32                `json:"dimension_confidence,omitempty"`  // weight of the measurement against the class prior
+	OBBHeadingRad        float32                `json:"obb_heading_rad"`
+	HeadingSource        int                    `json:"heading_source,omitempty"` // 0=PCA, 1=velocity, 2=displacement, 3=locked
+	GhostOf              string                 `json:"ghost_of,omitempty"`       // real track this one mirrors; set only for multipath ghosts
+	FirstSeen            string                 `json:"first_seen"`
+	LastSeen             string                 `json:"last_seen"`
+	History              []TrackPointResponse   `json:"history,omitempty"`
 }
 
 // TrackPointResponse represents a point in a track's history.
@@ -55,7 +68,8 @@ type Velocity struct {
 }
 
 // BBox represents bounding box dimensions for rendering.
-// These are per-frame cluster dimensions (from DBSCAN OBB), not running averages.
+// These are per-frame cluster dimensions (from DBSCAN OBB), not running averages,
+// pulled toward the class prior for sparse observations when priors are configured.
 type BBox struct {
 	Length float32 `json:"length"`
 	Width  float32 `json:"width"`
@@ -76,6 +90,9 @@ type ClusterResponse struct {
 	PointsCount   int     `json:"points_count"`
 	HeightP95     float32 `json:"height_p95"`
 	IntensityMean float32 `json:"intensity_mean"`
+
+	// Point count rescaled to the density model's reference range
+	PointsCountNormalized float32 `json:"points_count_normalized,omitempty"`
 }
 
 // TracksListResponse is the JSON response for listing tracks.
@@ -83,6 +100,7 @@ type TracksListResponse struct {
 	Tracks    []TrackResponse `json:"tracks"`
 	Count     int             `json:"count"`
 	Timestamp string          `json:"timestamp"`
+	Stale     bool            `json:"stale,omitempty"` // live feed silent; tracks withheld
 }
 
 // ClustersListResponse is the JSON response for listing clusters.
@@ -124,7 +142,13 @@ type TrackSummaryResponse struct {
 	ByClass   map[string]ClassSummary `json:"by_class"`
 	ByState   map[string]int          `json:"by_state"`
 	Overall   OverallSummary          `json:"overall"`
-	Timestamp string                  `json:"timestamp"`
+	Speed     SpeedStatistics         `json:"speed_stats"`
+	// Flicker counts, per class, the tracks dropped for lasting less than
+	// their class minimum duration.
+	Flicker map[string]int `json:"flicker_by_class,omitempty"`
+	// SpeedLimits counts speed-limit verdicts; only with speed limits set.
+	SpeedLimits *l6objects.SpeedLimitSummary `json:"speed_limits,omitempty"`
+	Timestamp   string                       `json:"timestamp"`
 }
 
 // ClassSummary is a type alias for l8analytics.TrackClassSummary.
@@ -133,12 +157,26 @@ type ClassSummary = l8analytics.TrackClassSummary
 // OverallSummary is a type alias for l8analytics.TrackOverallSummary.
 type OverallSummary = l8analytics.TrackOverallSummary
 
+// SpeedStatistics is a type alias for l6objects.SpeedStatistics.
+type SpeedStatistics = l6objects.SpeedStatistics
+
+// SpeedLimitTag is a type alias for l6objects.SpeedLimitTag.
+type SpeedLimitTag = l6objects.SpeedLimitTag
+
 func (api *TrackAPI) writeJSONError(w http.ResponseWriter, status int, msg string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(map[string]string{"error": msg})
 }
 
+// writeExport writes a list response in the negotiated format: the whole
+// response for JSON and MessagePack, or one CSV row per element of rows.
+func (api *TrackAPI) writeExport(w http.ResponseWriter, format adapters.ExportFormat, response, rows interface{}) {
+	if err := adapters.WriteExport(w, format, response, rows); err != nil {
+		opsf("[TrackAPI] %s export failed: %v", format, err)
+	}
+}
+
 // toDisplayFrame aligns stored track coordinates (sensor frame with azimuth 0 along +Y)
 // to the UI's display frame (azimuth 0 along +X) by swapping X/Y.
 func toDisplayFrame(x, y float32) (float32, float32) {
@@ -179,7 +217,7 @@ func (api *TrackAPI) trackToResponse(track *l5tracks.TrackedObject) TrackRespons
 		})
 	}
 
-	return TrackResponse{
+	resp := TrackResponse{
 		TrackID:  track.TrackID,
 		SensorID: track.SensorID,
 		State:    string(track.TrackState),
@@ -194,38 +232,61 @@ func (api *TrackAPI) trackToResponse(track *l5tracks.TrackedObject) TrackRespons
 			VX: velX,
 			VY: velY,
 		},
-		SpeedMps:            speed,
-		HeadingRad:          heading,
-		ObjectClass:         track.ObjectClass,
-		ObjectConfidence:    track.ObjectConfidence,
-		ClassificationModel: track.ClassificationModel,
-		ObservationCount:    track.ObservationCount,
-		AgeSeconds:          spanSeconds,
-		AvgSpeedMps:         track.AvgSpeedMps,
-		MaxSpeedMps:         track.MaxSpeedMps,
-		BoundingBox:         bboxFromTrack(track),
-		OBBHeadingRad:       track.OBBHeadingRad,
-		HeadingSource:       int(track.HeadingSource),
-		FirstSeen:           time.Unix(0, first).UTC().Format(time.RFC3339Nano),
-		LastSeen:            time.Unix(0, last).UTC().Format(time.RFC3339Nano),
-		History:             history,
+		SpeedMps:             speed,
+		HeadingRad:           heading,
+		ObjectClass:          track.ObjectClass,
+		ObjectConfidence:     track.ObjectConfidence,
+		ClassificationModel:  track.ClassificationModel,
+		ObservationCount:     track.ObservationCount,
+		CoastedFrames:        track.CoastedFrames,
+		DetectionReliability: track.DetectionReliability(),
+		AgeSeconds:           spanSeconds,
+		AvgSpeedMps:          track.AvgSpeedMps,
+		MaxSpeedMps:          track.MaxSpeedMps,
+		CompositeSpeedMps:    track.CompositeSpeedMps,
+		InstantSpeedMps:      track.InstantSpeedMps,
+		BoundingBox:          bboxFromTrack(track),
+		OBBHeadingRad:        track.OBBHeadingRad,
+		HeadingSource:        int(track.HeadingSource),
+		GhostOf:              track.GhostOf,
+		FirstSeen:            time.Unix(0, first).UTC().Format(time.RFC3339Nano),
+		LastSeen:             time.Unix(0, last).UTC().Format(time.RFC3339Nano),
+		History:              history,
+	}
+	if track.DimensionPriorApplied {
+		resp.MeasuredBoundingBox = &BBox{Length: track.OBBLength, Width: track.OBBWidth, Height: track.OBBHeight}
+		resp.DimensionConfidence = track.DimensionConfidence
+	}
+	if track.P != [16]float32{} {
+		sdX, sdY := toDisplayFrame(track.VelocityStdDev())
+		resp.VelocityStdDev = &Velocity{VX: sdX, VY: sdY}
+		resp.SpeedStdDevMps = track.SpeedStdDev()
+	}
+	if api.roadAxis != nil {
+		// The road axis is in the tracker's world frame, not the display frame.
+		rv := api.roadAxis.Decompose(track.VX, track.VY)
+		resp.RoadVelocity = &rv
+	}
+	if api.speedLimits != nil {
+		if tag, ok := api.speedLimits.Tag(track); ok {
+			resp.SpeedLimit = &tag
+		}
 	}
+	return resp
 }
 
 // bboxFromTrack returns a BBox populated from the best available dimensions.
-// Per-frame cluster dims (OBBLength/Width/Height) are preferred; when they
-// are zero (e.g. tracks loaded from DB where only the historical value is
-// stored), BoundingBoxLengthAvg is used as fallback.
+// Per-frame reported dims (TrackedObject.ReportedDimensions) are preferred;
+// when they are zero (e.g. tracks loaded from DB where only the historical
+// value is stored), BoundingBoxLengthAvg is used as fallback.
 func bboxFromTrack(track *l5tracks.TrackedObject) BBox {
-	l := track.OBBLength
+	l, w, h := track.ReportedDimensions()
 	if l == 0 {
 		l = track.BoundingBoxLengthAvg
 	}
-	w := track.OBBWidth
 	if w == 0 {
 		w = track.BoundingBoxWidthAvg
 	}
-	h := track.OBBHeight
 	if h == 0 {
 		h = track.BoundingBoxHeightAvg
 	}
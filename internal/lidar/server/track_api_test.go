@@ -2,12 +2,15 @@ package server
 
 import (
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -15,7 +18,9 @@ import (
 	dbpkg "github.com/banshee-data/velocity.report/internal/db"
 	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
 	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
 	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
+	"github.com/vmihailenco/msgpack/v5"
 	_ "modernc.org/sqlite"
 )
 
@@ -275,6 +280,10 @@ func TestTrackAPI_HandleTrackSummary(t *testing.T) {
 	if response.Overall.TotalTracks == 0 {
 		t.Error("expected at least one track in summary")
 	}
+	want := l6objects.ComputeSpeedStatistics(l6objects.TrackSpeedSamples(tracker.GetActiveTracks()))
+	if response.Speed != want {
+		t.Errorf("speed_stats = %+v, want %+v", response.Speed, want)
+	}
 }
 
 func TestTrackAPI_HandleListClusters_NoDB(t *testing.T) {
@@ -809,9 +818,10 @@ func TestTrackAPI_TrackToResponse(t *testing.T) {
 			BoundingBoxLengthAvg: 4.5,
 			BoundingBoxWidthAvg:  2.0,
 			BoundingBoxHeightAvg: 1.5}, X: 10.0,
-		Y:  5.0,
-		VX: 1.0,
-		VY: 0.5,
+		Y:             5.0,
+		VX:            1.0,
+		VY:            0.5,
+		CoastedFrames: 2,
 
 		History: []l5tracks.TrackPoint{
 			{X: 9.0, Y: 4.5, Timestamp: now.Add(-500 * time.Millisecond).UnixNano()},
@@ -852,6 +862,66 @@ func TestTrackAPI_TrackToResponse(t *testing.T) {
 	if response.AgeSeconds < 0.9 || response.AgeSeconds > 1.1 {
 		t.Errorf("expected AgeSeconds ~1.0, got %f", response.AgeSeconds)
 	}
+
+	if response.CoastedFrames != 2 {
+		t.Errorf("expected CoastedFrames 2, got %d", response.CoastedFrames)
+	}
+	// 10 observed of 12 frames
+	if math.Abs(float64(response.DetectionReliability)-10.0/12.0) > 1e-6 {
+		t.Errorf("expected DetectionReliability ~0.833, got %f", response.DetectionReliability)
+	}
+
+	// No live covariance: uncertainty is omitted.
+	if response.VelocityStdDev != nil || response.SpeedStdDevMps != 0 {
+		t.Errorf("expected no velocity uncertainty, got %+v / %f", response.VelocityStdDev, response.SpeedStdDevMps)
+	}
+}
+
+func TestTrackAPI_TrackToResponse_VelocityUncertainty(t *testing.T) {
+	api := NewTrackAPI(nil, "test-sensor")
+	track := &l5tracks.TrackedObject{TrackID: "t1", VX: 2.0}
+	track.P[2*4+2] = 0.25 // var(vx)
+	track.P[3*4+3] = 1.0  // var(vy)
+
+	response := api.trackToResponse(track)
+
+	if response.VelocityStdDev == nil {
+		t.Fatal("expected velocity_std_dev to be populated")
+	}
+	// Display frame swaps X/Y.
+	if response.VelocityStdDev.VX != 1.0 || response.VelocityStdDev.VY != 0.5 {
+		t.Errorf("expected velocity std-dev (1.0, 0.5), got (%f, %f)",
+			response.VelocityStdDev.VX, response.VelocityStdDev.VY)
+	}
+	if response.SpeedStdDevMps != 0.5 {
+		t.Errorf("expected speed std-dev 0.5, got %f", response.SpeedStdDevMps)
+	}
+}
+
+func TestTrackAPI_TrackToResponse_CompositeSpeed(t *testing.T) {
+	api := NewTrackAPI(nil, "test-sensor")
+	track := &l5tracks.TrackedObject{TrackID: "t1", VX: 12, CompositeSpeedMps: 9.8}
+	track.AvgSpeedMps = 10.4
+	track.MaxSpeedMps = 14
+
+	response := api.trackToResponse(track)
+
+	if response.CompositeSpeedMps != 9.8 || response.AvgSpeedMps != 10.4 || response.MaxSpeedMps != 14 {
+		t.Errorf("expected composite/avg/max 9.8/10.4/14, got %f/%f/%f",
+			response.CompositeSpeedMps, response.AvgSpeedMps, response.MaxSpeedMps)
+	}
+}
+
+func TestTrackAPI_TrackToResponse_InstantSpeed(t *testing.T) {
+	api := NewTrackAPI(nil, "test-sensor")
+	track := &l5tracks.TrackedObject{TrackID: "t1", VX: 12, InstantSpeedMps: 12.6}
+	track.MaxSpeedMps = 11
+
+	response := api.trackToResponse(track)
+
+	if response.InstantSpeedMps != 12.6 || response.MaxSpeedMps != 11 {
+		t.Errorf("expected instant/max 12.6/11, got %f/%f", response.InstantSpeedMps, response.MaxSpeedMps)
+	}
 }
 
 // ====== Database-backed handler tests ======
@@ -888,6 +958,59 @@ func TestTrackAPI_HandleListTracks_WithDB(t *testing.T) {
 	}
 }
 
+func TestTrackAPI_HandleListTracks_ContentNegotiation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now().UnixNano()
+	insertTestTrack(t, db, "track-001", "sensor-A", "confirmed", now-1e9, now)
+	insertTestTrack(t, db, "track-002", "sensor-A", "tentative", now-2e9, now)
+	api := NewTrackAPI(db, "sensor-A")
+
+	get := func(target, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		api.handleListTracks(w, req)
+		return w
+	}
+
+	w := get("/api/lidar/tracks", "text/csv")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("CSV: status %d, Content-Type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "track_id" || !slices.Contains(rows[0], "position_x") {
+		t.Errorf("CSV = %v", rows)
+	}
+
+	w = get("/api/lidar/tracks?format=msgpack", "application/json")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/msgpack" {
+		t.Fatalf("msgpack: status %d, Content-Type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var decoded struct {
+		Count  int `msgpack:"count"`
+		Tracks []struct {
+			TrackID string `msgpack:"track_id"`
+		} `msgpack:"tracks"`
+	}
+	if err := msgpack.Unmarshal(w.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode msgpack: %v", err)
+	}
+	if decoded.Count != 2 || len(decoded.Tracks) != 2 || decoded.Tracks[0].TrackID == "" {
+		t.Errorf("msgpack response = %+v", decoded)
+	}
+
+	if w = get("/api/lidar/tracks", "application/xml"); w.Code != http.StatusNotAcceptable {
+		t.Errorf("unsupported Accept: status %d, want 406", w.Code)
+	}
+}
+
 func TestTrackAPI_HandleListTracks_FilterByState(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1625,3 +1748,193 @@ func TestTrackAPI_HandleListTracks_History_ResponseShape(t *testing.T) {
 		t.Errorf("last_seen not valid RFC3339Nano: %v", err)
 	}
 }
+
+// ====== handleTrackInnovations tests ======
+
+func TestTrackAPI_HandleTrackInnovations(t *testing.T) {
+	api := NewTrackAPI(nil, "test-sensor")
+	w := httptest.NewRecorder()
+	api.handleTrackInnovations(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/innovations", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("no tracker: expected status 503, got %d", w.Code)
+	}
+
+	tracker := l5tracks.NewTracker(l5tracks.DefaultTrackerConfig())
+	api.SetTracker(tracker)
+
+	w = httptest.NewRecorder()
+	api.handleTrackInnovations(w, httptest.NewRequest(http.MethodDelete, "/api/lidar/tracks/innovations", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE: expected status 405, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	api.handleTrackInnovations(w, httptest.NewRequest(http.MethodPost, "/api/lidar/tracks/innovations", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST without enabled: expected status 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	api.handleTrackInnovations(w, httptest.NewRequest(http.MethodPost, "/api/lidar/tracks/innovations", strings.NewReader(`{"enabled": true}`)))
+	if w.Code != http.StatusOK || !tracker.GetConfig().RecordInnovations {
+		t.Fatalf("POST enable: status %d, RecordInnovations=%v", w.Code, tracker.GetConfig().RecordInnovations)
+	}
+
+	cluster := l4perception.WorldCluster{SensorID: "test-sensor", CentroidX: 10, CentroidY: 5, CentroidZ: 1,
+		BoundingBoxLength: 4, BoundingBoxWidth: 2, BoundingBoxHeight: 1.5, PointsCount: 50}
+	ts := time.Now()
+	for i := 0; i < 5; i++ {
+		cluster.CentroidX = 10 + float32(i)*0.5
+		ts = ts.Add(100 * time.Millisecond)
+		tracker.Update([]l4perception.WorldCluster{cluster}, ts)
+	}
+
+	w = httptest.NewRecorder()
+	api.handleTrackInnovations(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/innovations?include_samples=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp l5tracks.InnovationDiagnostics
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Enabled || resp.Samples != 4 || len(resp.PerTrack) != 1 || len(resp.PerTrack[0].Innovations) != 4 {
+		t.Errorf("diagnostics = %+v, want 4 samples on one track", resp)
+	}
+}
+
+func TestTrackAPI_HandleTrackSummary_MinDuration(t *testing.T) {
+	tracker := l5tracks.NewTracker(l5tracks.DefaultTrackerConfig())
+	for id, secs := range map[string]float64{"flicker": 0.3, "real": 5} {
+		tracker.Tracks[id] = &l5tracks.TrackedObject{
+			TrackID: id,
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				TrackState:     l5tracks.TrackConfirmed,
+				ObjectClass:    "car",
+				StartUnixNanos: 1_000_000_000,
+				EndUnixNanos:   1_000_000_000 + int64(secs*1e9),
+			},
+		}
+	}
+	api := NewTrackAPI(nil, "test-sensor")
+	api.SetTracker(tracker)
+	api.SetMinDuration(&l6objects.MinDurationConfig{MinDurationSecs: map[string]float64{"car": 1}})
+
+	summary := func(query string) TrackSummaryResponse {
+		w := httptest.NewRecorder()
+		api.handleTrackSummary(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/summary"+query, nil))
+		var resp TrackSummaryResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	resp := summary("")
+	if resp.Overall.TotalTracks != 1 || resp.Flicker["car"] != 1 {
+		t.Errorf("filtered summary: total=%d flicker=%v, want 1 and car:1", resp.Overall.TotalTracks, resp.Flicker)
+	}
+	resp = summary("?include_flicker=true")
+	if resp.Overall.TotalTracks != 2 || resp.Flicker != nil {
+		t.Errorf("include_flicker summary: total=%d flicker=%v, want 2 and none", resp.Overall.TotalTracks, resp.Flicker)
+	}
+}
+
+func TestTrackAPI_TrackToResponse_RoadVelocity(t *testing.T) {
+	api := NewTrackAPI(nil, "test-sensor")
+	track := &l5tracks.TrackedObject{TrackID: "t1", TrackMeasurement: l5tracks.TrackMeasurement{TrackState: l5tracks.TrackConfirmed}}
+	track.VX, track.VY = 3, 4
+
+	if resp := api.trackToResponse(track); resp.RoadVelocity != nil {
+		t.Errorf("road velocity without an axis = %+v", resp.RoadVelocity)
+	}
+	api.SetRoadAxis(&l5tracks.RoadAxis{From: &l5tracks.RoadPoint{X: 0, Y: 0}, To: &l5tracks.RoadPoint{X: 10, Y: 0}})
+	rv := api.trackToResponse(track).RoadVelocity
+	if rv == nil || rv.AlongMps != 3 || rv.CrossMps != 4 || rv.SpeedMps != 5 {
+		t.Errorf("road velocity = %+v, want along 3, cross 4, speed 5 in the tracker frame", rv)
+	}
+}
+
+func TestTrackAPI_SpeedLimits(t *testing.T) {
+	tracker := l5tracks.NewTracker(l5tracks.DefaultTrackerConfig())
+	for id, speed := range map[string]float32{"fast": 15, "slow": 5} {
+		tracker.Tracks[id] = &l5tracks.TrackedObject{
+			TrackID: id,
+			TrackMeasurement: l5tracks.TrackMeasurement{
+				SensorID:    "test-sensor",
+				TrackState:  l5tracks.TrackConfirmed,
+				ObjectClass: "car",
+				MaxSpeedMps: speed,
+			},
+		}
+	}
+	tagger, err := l6objects.NewSpeedLimitTagger(l6objects.SpeedLimitConfig{DefaultLimit: 36, Metric: l6objects.SpeedLimitMetricMax})
+	if err != nil {
+		t.Fatalf("NewSpeedLimitTagger: %v", err)
+	}
+	api := NewTrackAPI(nil, "test-sensor")
+	api.SetTracker(tracker)
+
+	if resp := api.trackToResponse(tracker.Tracks["fast"]); resp.SpeedLimit != nil {
+		t.Errorf("speed limit without a tagger = %+v", resp.SpeedLimit)
+	}
+	api.SetSpeedLimits(tagger)
+	tag := api.trackToResponse(tracker.Tracks["fast"]).SpeedLimit
+	if tag == nil || !tag.OverLimit || tag.Margin < 17.999 || tag.Margin > 18.001 {
+		t.Errorf("fast track tag = %+v, want over the limit by 18 km/h", tag)
+	}
+
+	w := httptest.NewRecorder()
+	api.handleTrackSummary(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/summary", nil))
+	var summary TrackSummaryResponse
+	if err := json.NewDecoder(w.Body).Decode(&summary); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if summary.SpeedLimits == nil || summary.SpeedLimits.BySensor["test-sensor"] != (l6objects.SpeedLimitCount{Tagged: 2, OverLimit: 1}) {
+		t.Errorf("summary speed limits = %+v, want 2 tagged and 1 over for test-sensor", summary.SpeedLimits)
+	}
+
+	w = httptest.NewRecorder()
+	api.handleActiveTracks(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/active?format=csv", nil))
+	header, _, _ := strings.Cut(w.Body.String(), "\n")
+	for _, col := range []string{"speed_limit_over_limit", "speed_limit_margin"} {
+		if !strings.Contains(header, col) {
+			t.Errorf("CSV header lacks %s: %s", col, header)
+		}
+	}
+}
+
+// TestTrackToResponse_DimensionPrior checks a prior-regularised box is
+// reported as bounding_box with the raw cluster box alongside it.
+func TestTrackToResponse_DimensionPrior(t *testing.T) {
+	api := NewTrackAPI(nil, "test-sensor")
+	track := &l5tracks.TrackedObject{
+		TrackID: "occluded-car",
+		TrackMeasurement: l5tracks.TrackMeasurement{
+			TrackState:  l5tracks.TrackConfirmed,
+			ObjectClass: "car",
+		},
+		OBBLength:             1.5,
+		OBBWidth:              0.6,
+		OBBHeight:             0.8,
+		PriorLength:           3.75,
+		PriorWidth:            1.5,
+		PriorHeight:           1.325,
+		DimensionConfidence:   0.25,
+		DimensionPriorApplied: true,
+	}
+
+	resp := api.trackToResponse(track)
+	if resp.BoundingBox.Length != 3.75 || resp.BoundingBox.Height != 1.325 {
+		t.Errorf("bounding_box = %+v, want the regularised box", resp.BoundingBox)
+	}
+	if resp.MeasuredBoundingBox == nil || resp.MeasuredBoundingBox.Length != 1.5 || resp.DimensionConfidence != 0.25 {
+		t.Errorf("measured_bounding_box = %+v confidence %.2f, want the raw 1.5 m box at 0.25",
+			resp.MeasuredBoundingBox, resp.DimensionConfidence)
+	}
+
+	track.DimensionPriorApplied = false
+	if resp := api.trackToResponse(track); resp.MeasuredBoundingBox != nil || resp.BoundingBox.Length != 1.5 {
+		t.Errorf("without a prior: bounding_box = %+v measured = %+v", resp.BoundingBox, resp.MeasuredBoundingBox)
+	}
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/report/chart"
+)
+
+// trackOverlayColours mirrors TRACK_COLORS in web/src/lib/types/lidar.ts so
+// the overlay matches the web track map.
+var trackOverlayColours = map[string]string{
+	"pedestrian":   "#4CAF50",
+	"car":          "#FF5722",
+	"truck":        "#F97316",
+	"bus":          "#7B1FA2",
+	"cyclist":      "#00BCD4",
+	"motorcyclist": "#EC4899",
+	"bird":         "#FFC107",
+	"dynamic":      "#9E9E9E",
+	"tentative":    "#FF9800",
+}
+
+const (
+	defaultOverlayScale   = 8.0  // px per metre
+	minOverlayExtentM     = 20.0 // Auto extent never zooms in further than ±20 m
+	maxOverlayExtentM     = 500.0
+	maxOverlaySizePx      = 4096.0
+	overlayArrowSeconds   = 1.0 // Velocity arrows show one second of travel
+	overlayMarginPx       = 24.0
+	overlayTrackRadiusPx  = 4.0
+	overlayArrowHeadPx    = 6.0
+	overlayMinArrowSpeedM = 0.2 // m/s; slower tracks get no arrow
+)
+
+// trackOverlayView is the world-frame window rendered by the overlay.
+type trackOverlayView struct {
+	XMin, XMax, YMin, YMax float64 // metres
+	Scale                  float64 // px per metre
+}
+
+func (v trackOverlayView) widthPx() float64  { return (v.XMax-v.XMin)*v.Scale + 2*overlayMarginPx }
+func (v trackOverlayView) heightPx() float64 { return (v.YMax-v.YMin)*v.Scale + 2*overlayMarginPx }
+
+// px maps a world point to canvas pixels, with +Y up.
+func (v trackOverlayView) px(x, y float64) (float64, float64) {
+	return overlayMarginPx + (x-v.XMin)*v.Scale, overlayMarginPx + (v.YMax-y)*v.Scale
+}
+
+// parseTrackOverlayView reads the view from the query string:
+//   - x_min, x_max, y_min, y_max: explicit window in metres (all four or none)
+//   - extent: half-width in metres of a square window centred on the sensor
+//   - scale: pixels per metre (default 8)
+//
+// With neither a window nor an extent, the window fits the tracks.
+func parseTrackOverlayView(q url.Values, tracks []*l5tracks.TrackedObject) (trackOverlayView, error) {
+	v := trackOverlayView{Scale: defaultOverlayScale}
+	if s := q.Get("scale"); s != "" {
+		scale, err := strconv.ParseFloat(s, 64)
+		if err != nil || scale <= 0 || math.IsInf(scale, 0) {
+			return v, fmt.Errorf("invalid scale %q: must be a positive number of pixels per metre", s)
+		}
+		v.Scale = scale
+	}
+
+	bounds := []string{"x_min", "x_max", "y_min", "y_max"}
+	var box [4]float64
+	given := 0
+	for i, name := range bounds {
+		s := q.Get(name)
+		if s == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return v, fmt.Errorf("invalid %s %q", name, s)
+		}
+		box[i] = f
+		given++
+	}
+
+	switch {
+	case given == len(bounds):
+		if box[0] >= box[1] || box[2] >= box[3] {
+			return v, fmt.Errorf("x_min must be below x_max and y_min below y_max")
+		}
+		v.XMin, v.XMax, v.YMin, v.YMax = box[0], box[1], box[2], box[3]
+	case given > 0:
+		return v, fmt.Errorf("x_min, x_max, y_min and y_max must be given together")
+	default:
+		extent, err := overlayExtent(q.Get("extent"), tracks)
+		if err != nil {
+			return v, err
+		}
+		v.XMin, v.XMax, v.YMin, v.YMax = -extent, extent, -extent, extent
+	}
+
+	if v.widthPx() > maxOverlaySizePx || v.heightPx() > maxOverlaySizePx {
+		return v, fmt.Errorf("image would be %.0fx%.0f px, the limit is %.0f px per side: reduce extent or scale",
+			v.widthPx(), v.heightPx(), maxOverlaySizePx)
+	}
+	return v, nil
+}
+
+// overlayExtent parses an explicit extent or fits one around the tracks
+// and their trajectories.
+func overlayExtent(s string, tracks []*l5tracks.TrackedObject) (float64, error) {
+	if s != "" {
+		extent, err := strconv.ParseFloat(s, 64)
+		if err != nil || extent <= 0 || extent > maxOverlayExtentM {
+			return 0, fmt.Errorf("invalid extent %q: must be between 0 and %.0f metres", s, maxOverlayExtentM)
+		}
+		return extent, nil
+	}
+	maxAbs := 0.0
+	grow := func(x, y float32) {
+		maxAbs = math.Max(maxAbs, math.Max(math.Abs(float64(x)), math.Abs(float64(y))))
+	}
+	for _, t := range tracks {
+		grow(t.X, t.Y)
+		for _, p := range t.History {
+			grow(p.X, p.Y)
+		}
+	}
+	return math.Min(math.Max(maxAbs*1.1, minOverlayExtentM), maxOverlayExtentM), nil
+}
+
+// overlayTrackColour picks the track's class colour, or the tentative colour
+// for unconfirmed tracks.
+func overlayTrackColour(t *l5tracks.TrackedObject) string {
+	if t.TrackState == l5tracks.TrackTentative {
+		return trackOverlayColours["tentative"]
+	}
+	if c, ok := trackOverlayColours[t.ObjectClass]; ok {
+		return c
+	}
+	return trackOverlayColours["dynamic"]
+}
+
+// overlayGridStep returns a round grid spacing giving roughly ten lines
+// across span metres.
+func overlayGridStep(span float64) float64 {
+	for _, step := range []float64{1, 2, 5, 10, 20, 50, 100} {
+		if span/step <= 12 {
+			return step
+		}
+	}
+	return 200
+}
+
+// renderTrackOverlay draws tracks top-down in the world frame: grid, sensor
+// origin, trajectories, positions, velocity arrows and a class legend.
+func renderTrackOverlay(tracks []*l5tracks.TrackedObject, v trackOverlayView, title string) []byte {
+	w, h := v.widthPx(), v.heightPx()
+	c := chart.NewCanvas(w*25.4/96, h*25.4/96)
+	c.Rect(0, 0, w, h, `fill="#ffffff"`)
+
+	// Grid and axis labels.
+	c.BeginGroup(`stroke="#e0e0e0" stroke-width="1"`)
+	step := overlayGridStep(math.Max(v.XMax-v.XMin, v.YMax-v.YMin))
+	for x := math.Ceil(v.XMin/step) * step; x <= v.XMax; x += step {
+		x1, y1 := v.px(x, v.YMin)
+		_, y2 := v.px(x, v.YMax)
+		c.Line(x1, y1, x1, y2, "")
+	}
+	for y := math.Ceil(v.YMin/step) * step; y <= v.YMax; y += step {
+		x1, y1 := v.px(v.XMin, y)
+		x2, _ := v.px(v.XMax, y)
+		c.Line(x1, y1, x2, y1, "")
+	}
+	c.EndGroup()
+	c.BeginGroup(`font-family="sans-serif" font-size="10" fill="#757575"`)
+	for x := math.Ceil(v.XMin/step) * step; x <= v.XMax; x += step {
+		px, py := v.px(x, v.YMin)
+		c.Text(px, py+14, fmt.Sprintf("%g", x), `text-anchor="middle"`)
+	}
+	for y := math.Ceil(v.YMin/step) * step; y <= v.YMax; y += step {
+		px, py := v.px(v.XMin, y)
+		c.Text(px-4, py+3, fmt.Sprintf("%g", y), `text-anchor="end"`)
+	}
+	c.EndGroup()
+
+	// Sensor origin.
+	if sx, sy := v.px(0, 0); v.XMin <= 0 && v.XMax >= 0 && v.YMin <= 0 && v.YMax >= 0 {
+		c.Polyline([][2]float64{{sx, sy - 7}, {sx - 6, sy + 5}, {sx + 6, sy + 5}, {sx, sy - 7}},
+			`fill="#212121" stroke="none"`)
+	}
+
+	// Tracks in a stable order so identical state renders identically.
+	sorted := append([]*l5tracks.TrackedObject(nil), tracks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TrackID < sorted[j].TrackID })
+	present := make(map[string]bool)
+	for _, t := range sorted {
+		colour := overlayTrackColour(t)
+		if t.TrackState == l5tracks.TrackTentative {
+			present["tentative"] = true
+		} else if _, ok := trackOverlayColours[t.ObjectClass]; ok {
+			present[t.ObjectClass] = true
+		} else {
+			present["dynamic"] = true
+		}
+
+		if len(t.History) > 1 {
+			pts := make([][2]float64, len(t.History))
+			for i, p := range t.History {
+				pts[i][0], pts[i][1] = v.px(float64(p.X), float64(p.Y))
+			}
+			c.Polyline(pts, fmt.Sprintf(`fill="none" stroke="%s" stroke-width="1.5" stroke-opacity="0.6"`, colour))
+		}
+
+		x, y := v.px(float64(t.X), float64(t.Y))
+		speed := math.Hypot(float64(t.VX), float64(t.VY))
+		if speed >= overlayMinArrowSpeedM {
+			ex, ey := v.px(float64(t.X)+float64(t.VX)*overlayArrowSeconds, float64(t.Y)+float64(t.VY)*overlayArrowSeconds)
+			attrs := fmt.Sprintf(`stroke="%s" stroke-width="2"`, colour)
+			c.Line(x, y, ex, ey, attrs)
+			angle := math.Atan2(ey-y, ex-x)
+			for _, da := range []float64{math.Pi * 5 / 6, -math.Pi * 5 / 6} {
+				c.Line(ex, ey, ex+overlayArrowHeadPx*math.Cos(angle+da), ey+overlayArrowHeadPx*math.Sin(angle+da), attrs)
+			}
+		}
+		c.Circle(x, y, overlayTrackRadiusPx, fmt.Sprintf(`fill="%s" stroke="#212121" stroke-width="0.5"`, colour))
+		c.Text(x+overlayTrackRadiusPx+2, y-overlayTrackRadiusPx-2,
+			fmt.Sprintf("%s %.1f m/s", t.TrackID, speed),
+			`font-family="sans-serif" font-size="10" fill="#212121"`)
+	}
+
+	// Title and legend.
+	c.Text(overlayMarginPx, overlayMarginPx-8, title, `font-family="sans-serif" font-size="12" fill="#212121"`)
+	classes := make([]string, 0, len(present))
+	for class := range present {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for i, class := range classes {
+		ly := h - overlayMarginPx - float64(len(classes)-1-i)*14 - 4
+		c.Circle(overlayMarginPx+8, ly-3, 4, fmt.Sprintf(`fill="%s"`, trackOverlayColours[class]))
+		c.Text(overlayMarginPx+16, ly, class, `font-family="sans-serif" font-size="10" fill="#212121"`)
+	}
+	return c.Bytes()
+}
+
+// handleTrackOverlay renders the current tracks as a top-down SVG image.
+//
+//	GET /api/lidar/tracks/overlay.svg?sensor_id=&state=confirmed&extent=60&scale=8
+//	GET /api/lidar/tracks/overlay.svg?x_min=-20&x_max=80&y_min=-10&y_max=30
+func (api *TrackAPI) handleTrackOverlay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	sensorID := q.Get("sensor_id")
+	if sensorID == "" {
+		sensorID = api.sensorID
+	}
+	tracks, status, err := api.activeTracks(sensorID, q.Get("state"))
+	if err != nil {
+		api.writeJSONError(w, status, err.Error())
+		return
+	}
+	view, err := parseTrackOverlayView(q, tracks)
+	if err != nil {
+		api.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	title := fmt.Sprintf("sensor=%s tracks=%d %s", sensorID, len(tracks), time.Now().UTC().Format(time.RFC3339))
+	svg := renderTrackOverlay(tracks, view, title)
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(svg)
+}
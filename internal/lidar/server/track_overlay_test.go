@@ -0,0 +1,174 @@
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l4perception"
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+)
+
+func overlayTestTracks() []*l5tracks.TrackedObject {
+	car := &l5tracks.TrackedObject{
+		TrackID: "track_car",
+		X:       5, Y: 5, VX: 3, VY: 0,
+		History: []l5tracks.TrackPoint{{X: -1, Y: 5}, {X: 2, Y: 5}, {X: 5, Y: 5}},
+	}
+	car.TrackState = l5tracks.TrackConfirmed
+	car.ObjectClass = "car"
+	walker := &l5tracks.TrackedObject{
+		TrackID: "track_new",
+		X:       -4, Y: -2,
+	}
+	walker.TrackState = l5tracks.TrackTentative
+	return []*l5tracks.TrackedObject{walker, car}
+}
+
+// assertWellFormedXML fails the test if svg does not parse as XML.
+func assertWellFormedXML(t *testing.T, svg []byte) {
+	t.Helper()
+	dec := xml.NewDecoder(bytes.NewReader(svg))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("overlay is not well-formed XML: %v\n%s", err, svg)
+		}
+	}
+}
+
+func TestRenderTrackOverlay(t *testing.T) {
+	view := trackOverlayView{XMin: -10, XMax: 10, YMin: -10, YMax: 10, Scale: 10}
+	svg := renderTrackOverlay(overlayTestTracks(), view, "sensor=test tracks=2")
+	assertWellFormedXML(t, svg)
+	out := string(svg)
+
+	// (5, 5) with +Y up: x = 24 + 15·10, y = 24 + 5·10.
+	if !strings.Contains(out, `cx="174.0000" cy="74.0000"`) {
+		t.Errorf("car marker not at the expected pixel position:\n%s", out)
+	}
+	for _, want := range []string{
+		trackOverlayColours["car"],       // class colour
+		trackOverlayColours["tentative"], // unconfirmed colour
+		"<polyline",                      // trajectory / sensor marker
+		"track_car 3.0 m/s",              // label
+		"sensor=test tracks=2",           // title
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("overlay missing %q", want)
+		}
+	}
+	// The car moves at 3 m/s along +X, so its arrow ends 30 px to the right.
+	if !strings.Contains(out, `x1="174.0000" y1="74.0000" x2="204.0000" y2="74.0000"`) {
+		t.Errorf("velocity arrow missing or misplaced:\n%s", out)
+	}
+
+	// Rendering is deterministic regardless of input order.
+	tracks := overlayTestTracks()
+	tracks[0], tracks[1] = tracks[1], tracks[0]
+	if again := renderTrackOverlay(tracks, view, "sensor=test tracks=2"); !bytes.Equal(again, svg) {
+		t.Error("overlay output depends on track order")
+	}
+}
+
+func TestParseTrackOverlayView(t *testing.T) {
+	far := []*l5tracks.TrackedObject{{X: 40, Y: -10, History: []l5tracks.TrackPoint{{X: 0, Y: -60}}}}
+
+	tests := []struct {
+		name    string
+		query   string
+		tracks  []*l5tracks.TrackedObject
+		want    trackOverlayView
+		wantErr string
+	}{
+		{name: "defaults to minimum extent", want: trackOverlayView{-20, 20, -20, 20, 8}},
+		{name: "fits tracks and history", tracks: far, want: trackOverlayView{-66, 66, -66, 66, 8}},
+		{name: "explicit extent and scale", query: "extent=50&scale=4", tracks: far, want: trackOverlayView{-50, 50, -50, 50, 4}},
+		{name: "explicit window", query: "x_min=-5&x_max=45&y_min=0&y_max=20", want: trackOverlayView{-5, 45, 0, 20, 8}},
+		{name: "partial window", query: "x_min=0&x_max=10", wantErr: "must be given together"},
+		{name: "inverted window", query: "x_min=10&x_max=0&y_min=0&y_max=1", wantErr: "x_min must be below x_max"},
+		{name: "bad scale", query: "scale=-1", wantErr: "invalid scale"},
+		{name: "bad extent", query: "extent=abc", wantErr: "invalid extent"},
+		{name: "too large", query: "extent=400&scale=20", wantErr: "the limit is 4096 px"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.query)
+			got, err := parseTrackOverlayView(q, tt.tracks)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			near := func(a, b float64) bool { return math.Abs(a-b) < 1e-3 }
+			if !near(got.XMin, tt.want.XMin) || !near(got.XMax, tt.want.XMax) ||
+				!near(got.YMin, tt.want.YMin) || !near(got.YMax, tt.want.YMax) || got.Scale != tt.want.Scale {
+				t.Errorf("view = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackAPI_HandleTrackOverlay(t *testing.T) {
+	tracker := l5tracks.NewTracker(l5tracks.DefaultTrackerConfig())
+	cluster := l4perception.WorldCluster{SensorID: "test-sensor", CentroidY: 5, PointsCount: 50}
+	ts := time.Now()
+	for i := 0; i < 5; i++ {
+		cluster.CentroidX = 10 + float32(i)*0.5
+		ts = ts.Add(100 * time.Millisecond)
+		tracker.Update([]l4perception.WorldCluster{cluster}, ts)
+	}
+	api := NewTrackAPI(nil, "test-sensor")
+	api.SetTracker(tracker)
+
+	t.Run("renders svg", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		api.handleTrackOverlay(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/overlay.svg?extent=30", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		assertWellFormedXML(t, w.Body.Bytes())
+		if !strings.Contains(w.Body.String(), "sensor=test-sensor tracks=1") {
+			t.Errorf("title should report the sensor and one track:\n%s", w.Body.String())
+		}
+	})
+
+	t.Run("bad view", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		api.handleTrackOverlay(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/overlay.svg?scale=0", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("method", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		api.handleTrackOverlay(w, httptest.NewRequest(http.MethodPost, "/api/lidar/tracks/overlay.svg", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("status = %d, want 405", w.Code)
+		}
+	})
+
+	t.Run("no track source", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NewTrackAPI(nil, "test-sensor").handleTrackOverlay(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tracks/overlay.svg", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", w.Code)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+)
+
+// tripwiresResponse is the body of GET and PUT /api/lidar/tripwires.
+type tripwiresResponse struct {
+	l6objects.TripwireConfig
+	Counts []l6objects.TripwireCount `json:"counts"`
+}
+
+// handleTripwires serves the count lines and their running counts.
+// GET returns the configuration and counts. PUT replaces the lines with a
+// TripwireConfig body; counts carry over for lines left unchanged.
+func (ws *Server) handleTripwires(w http.ResponseWriter, r *http.Request) {
+	if ws.tripwires == nil {
+		ws.writeJSONError(w, http.StatusServiceUnavailable, "count lines are not enabled: start with --lidar-tripwires")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var cfg l6objects.TripwireConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			ws.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %v", err))
+			return
+		}
+		if err := ws.tripwires.SetConfig(cfg); err != nil {
+			ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		opsf("[API:tripwires] configured %d count lines", len(cfg.Lines))
+	default:
+		ws.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tripwiresResponse{
+		TripwireConfig: ws.tripwires.Config(),
+		Counts:         ws.tripwires.Counts(),
+	})
+}
+
+// handleTripwireHeadways serves the vehicle headway series on one count
+// line, with pedestrian crossing attempts matched to the vehicle gaps, as
+// CSV. Query parameters: line (required), crosswalk (the line pedestrians
+// cross; default line), direction, critical_gap_secs and classes (comma
+// separated vehicle classes). view=summary returns the summary percentiles
+// and gap counts instead of the series.
+func (ws *Server) handleTripwireHeadways(w http.ResponseWriter, r *http.Request) {
+	if ws.tripwires == nil {
+		ws.writeJSONError(w, http.StatusServiceUnavailable, "count lines are not enabled: start with --lidar-tripwires")
+		return
+	}
+
+	q := r.URL.Query()
+	cfg := l6objects.HeadwayConfig{
+		LineID:      q.Get("line"),
+		CrosswalkID: q.Get("crosswalk"),
+		Direction:   l6objects.CrossingDirection(q.Get("direction")),
+	}
+	if v := q.Get("critical_gap_secs"); v != "" {
+		gap, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			ws.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid critical_gap_secs: %v", err))
+			return
+		}
+		cfg.CriticalGapSecs = gap
+	}
+	if v := q.Get("classes"); v != "" {
+		cfg.VehicleClasses = strings.Split(v, ",")
+	}
+	report, err := l6objects.AnalyseHeadways(ws.tripwires.Crossings(), cfg)
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	if q.Get("view") == "summary" {
+		err = report.WriteSummaryCSV(w)
+	} else {
+		err = report.WriteCSV(w)
+	}
+	if err != nil {
+		opsf("[API:tripwires] write headways: %v", err)
+	}
+}
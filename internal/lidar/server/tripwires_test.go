@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l5tracks"
+	"github.com/banshee-data/velocity.report/internal/lidar/l6objects"
+)
+
+func TestHandleTripwires(t *testing.T) {
+	counter, err := l6objects.NewTripwireCounter(l6objects.TripwireConfig{
+		Lines: []l6objects.Tripwire{{ID: "gate", X1: 0, Y1: -5, X2: 0, Y2: 5}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := &l5tracks.TrackedObject{TrackID: "t1"}
+	track.ObjectClass = "car"
+	for i, x := range []float32{-2, 2} {
+		track.X, track.EndUnixNanos = x, int64(i)*1e8
+		counter.Observe([]*l5tracks.TrackedObject{track})
+	}
+
+	ws := &Server{tripwires: counter}
+	serve := func(method, body string) (*httptest.ResponseRecorder, tripwiresResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		ws.handleTripwires(w, httptest.NewRequest(method, "/api/lidar/tripwires", strings.NewReader(body)))
+		var resp tripwiresResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w, resp
+	}
+
+	w, resp := serve(http.MethodGet, "")
+	if w.Code != http.StatusOK || len(resp.Lines) != 1 || len(resp.Counts) != 1 || resp.Counts[0].Total != 1 ||
+		resp.Counts[0].ByClass["car"] != 1 || resp.HysteresisMetres != l6objects.DefaultTripwireHysteresisMetres {
+		t.Fatalf("GET: status %d, response %+v", w.Code, resp)
+	}
+
+	w, resp = serve(http.MethodPut, `{"lines": [{"id": "gate", "x1": 0, "y1": -5, "x2": 0, "y2": 5}, {"id": "kerb", "x1": 3, "y1": -5, "x2": 3, "y2": 5}]}`)
+	if w.Code != http.StatusOK || len(resp.Counts) != 2 || resp.Counts[0].Total != 1 || resp.Counts[1].Total != 0 {
+		t.Fatalf("PUT: status %d, response %+v", w.Code, resp)
+	}
+
+	for name, tc := range map[string]struct {
+		ws     *Server
+		method string
+		body   string
+		want   int
+	}{
+		"disabled":     {&Server{}, http.MethodGet, "", http.StatusServiceUnavailable},
+		"bad json":     {ws, http.MethodPut, `{"lines": [`, http.StatusBadRequest},
+		"duplicate id": {ws, http.MethodPut, `{"lines": [{"id": "a", "x2": 1}, {"id": "a", "x2": 1}]}`, http.StatusBadRequest},
+		"method":       {ws, http.MethodDelete, "", http.StatusMethodNotAllowed},
+	} {
+		w := httptest.NewRecorder()
+		tc.ws.handleTripwires(w, httptest.NewRequest(tc.method, "/api/lidar/tripwires", strings.NewReader(tc.body)))
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", name, w.Code, tc.want)
+		}
+	}
+	if got := ws.tripwires.Config().Lines; len(got) != 2 {
+		t.Errorf("rejected PUTs changed the lines: %+v", got)
+	}
+}
+
+func TestHandleTripwireHeadways(t *testing.T) {
+	counter, err := l6objects.NewTripwireCounter(l6objects.TripwireConfig{
+		Lines: []l6objects.Tripwire{{ID: "gate", X1: 0, Y1: -5, X2: 0, Y2: 5}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Three cars cross the gate 2 s and then 6 s apart.
+	for i, start := range []float32{0, 2, 8} {
+		track := &l5tracks.TrackedObject{TrackID: fmt.Sprintf("car-%d", i)}
+		track.ObjectClass = "car"
+		for j, x := range []float32{-1, 1} {
+			track.X, track.EndUnixNanos = x, int64((start+float32(j)*0.2)*1e9)
+			counter.Observe([]*l5tracks.TrackedObject{track})
+		}
+	}
+
+	ws := &Server{tripwires: counter}
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ws.handleTripwireHeadways(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tripwires/headways?"+query, nil))
+		return w
+	}
+
+	w := get("line=gate")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("series: status %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 3 ||
+		!strings.Contains(lines[1], ",car-1,car,car-0,,2.000,,false") || !strings.Contains(lines[2], ",car-2,car,car-1,,6.000,,true") {
+		t.Errorf("series:\n%s", w.Body.String())
+	}
+
+	w = get("line=gate&view=summary&critical_gap_secs=1")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "p50_secs,6.000\n") ||
+		!strings.Contains(w.Body.String(), "usable_gaps,2\n") {
+		t.Errorf("summary: status %d\n%s", w.Code, w.Body.String())
+	}
+
+	for name, tc := range map[string]struct {
+		ws    *Server
+		query string
+		want  int
+	}{
+		"disabled":     {&Server{}, "line=gate", http.StatusServiceUnavailable},
+		"no line":      {ws, "", http.StatusBadRequest},
+		"bad gap":      {ws, "line=gate&critical_gap_secs=soon", http.StatusBadRequest},
+		"negative gap": {ws, "line=gate&critical_gap_secs=-2", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		tc.ws.handleTripwireHeadways(w, httptest.NewRequest(http.MethodGet, "/api/lidar/tripwires/headways?"+tc.query, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", name, w.Code, tc.want)
+		}
+	}
+}
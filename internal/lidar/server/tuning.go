@@ -106,7 +106,7 @@ func (ws *Server) handleTuningParams(w http.ResponseWriter, r *http.Request) {
 
 		// If this was a form submission, redirect back to status page
 		if r.FormValue("config_json") != "" {
-			http.Redirect(w, r, fmt.Sprintf("/lidar/server?sensor_id=%s", sensorID), http.StatusSeeOther)
+			http.Redirect(w, r, fmt.Sprintf("%s/lidar/server?sensor_id=%s", ws.basePath, sensorID), http.StatusSeeOther)
 			return
 		}
 
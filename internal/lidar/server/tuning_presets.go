@@ -0,0 +1,238 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
+)
+
+// REST API for named tuning presets
+// Presets are a reusable library of known-good tuning configurations,
+// separate from the per-run parameter provenance recorded with each run.
+//
+// Routes:
+// - GET /api/lidar/presets — list presets
+// - POST /api/lidar/presets — save (create or overwrite) a preset
+// - GET /api/lidar/presets/{name} — get one preset
+// - DELETE /api/lidar/presets/{name} — delete a preset
+// - POST /api/lidar/presets/{name}/apply?sensor_id= — apply a preset
+
+// SaveTuningPresetRequest is the body of POST /api/lidar/presets. Params
+// takes the same nested or dotted-path shape as POST /api/lidar/params.
+type SaveTuningPresetRequest struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// handleTuningPresets handles /api/lidar/presets (list and save).
+func (ws *Server) handleTuningPresets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ws.handleListTuningPresets(w, r)
+	case http.MethodPost:
+		ws.handleSaveTuningPreset(w, r)
+	default:
+		ws.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleTuningPresetByName handles /api/lidar/presets/{name}/* routes.
+func (ws *Server) handleTuningPresetByName(w http.ResponseWriter, r *http.Request) {
+	name, action := parseTuningPresetPath(r.URL.Path)
+	if name == "" {
+		ws.writeJSONError(w, http.StatusBadRequest, "missing preset name in path")
+		return
+	}
+
+	switch action {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			ws.handleGetTuningPreset(w, name)
+		case http.MethodDelete:
+			ws.handleDeleteTuningPreset(w, name)
+		default:
+			ws.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	case "apply":
+		if r.Method == http.MethodPost {
+			ws.handleApplyTuningPreset(w, r, name)
+		} else {
+			ws.writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	default:
+		ws.writeJSONError(w, http.StatusNotFound, "endpoint not found")
+	}
+}
+
+// parseTuningPresetPath extracts the preset name and action from
+// /api/lidar/presets/{name}/{action}.
+func parseTuningPresetPath(path string) (name string, action string) {
+	trimmed := strings.TrimPrefix(path, "/api/lidar/presets/")
+	if trimmed == path {
+		return "", ""
+	}
+	parts := strings.SplitN(trimmed, "/", 2)
+	name = parts[0]
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	return
+}
+
+// handleListTuningPresets lists all presets ordered by name.
+func (ws *Server) handleListTuningPresets(w http.ResponseWriter, r *http.Request) {
+	store := sqlite.NewTuningPresetStore(ws.db)
+	presets, err := store.List()
+	if err != nil {
+		ws.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to list presets: %v", err))
+		return
+	}
+	if presets == nil {
+		presets = []*sqlite.TuningPreset{}
+	}
+
+	ws.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"presets": presets,
+		"count":   len(presets),
+	})
+}
+
+// handleSaveTuningPreset creates or overwrites a preset. The params are
+// checked for shape and for at least one runtime-editable path so a preset
+// that could never be applied is rejected at save time; full config
+// validation happens on apply against the live config.
+func (ws *Server) handleSaveTuningPreset(w http.ResponseWriter, r *http.Request) {
+	var req SaveTuningPresetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %v", err))
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		ws.writeJSONError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if strings.Contains(name, "/") {
+		ws.writeJSONError(w, http.StatusBadRequest, "name must not contain '/'")
+		return
+	}
+	if len(req.Params) == 0 {
+		ws.writeJSONError(w, http.StatusBadRequest, "params is required")
+		return
+	}
+
+	patch, err := normaliseTuningPatch(req.Params)
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	editable := false
+	for path := range patch {
+		if validateRuntimeTuningPath(path) == nil {
+			editable = true
+			break
+		}
+	}
+	if !editable {
+		ws.writeJSONError(w, http.StatusBadRequest, "no runtime-editable parameters in params")
+		return
+	}
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid params: %v", err))
+		return
+	}
+
+	preset := &sqlite.TuningPreset{Name: name, Params: params}
+	store := sqlite.NewTuningPresetStore(ws.db)
+	if err := store.Save(preset); err != nil {
+		ws.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to save preset: %v", err))
+		return
+	}
+
+	ws.writeJSON(w, http.StatusOK, preset)
+}
+
+// handleGetTuningPreset returns one preset by name.
+func (ws *Server) handleGetTuningPreset(w http.ResponseWriter, name string) {
+	store := sqlite.NewTuningPresetStore(ws.db)
+	preset, err := store.Get(name)
+	if err != nil {
+		ws.writeTuningPresetError(w, "load", err)
+		return
+	}
+	ws.writeJSON(w, http.StatusOK, preset)
+}
+
+// handleDeleteTuningPreset deletes a preset by name.
+func (ws *Server) handleDeleteTuningPreset(w http.ResponseWriter, name string) {
+	store := sqlite.NewTuningPresetStore(ws.db)
+	if err := store.Delete(name); err != nil {
+		ws.writeTuningPresetError(w, "delete", err)
+		return
+	}
+	ws.writeJSON(w, http.StatusOK, map[string]string{
+		"message": "preset deleted",
+	})
+}
+
+// handleApplyTuningPreset applies a stored preset to the sensor's runtime
+// config through the same validate-then-apply path as POST /api/lidar/params,
+// so either every parameter in the preset takes effect or none does.
+func (ws *Server) handleApplyTuningPreset(w http.ResponseWriter, r *http.Request, name string) {
+	sensorID := r.URL.Query().Get("sensor_id")
+	if sensorID == "" {
+		sensorID = ws.sensorID
+	}
+	bm := l3grid.GetBackgroundManager(sensorID)
+	if bm == nil || bm.Grid == nil {
+		ws.writeJSONError(w, http.StatusNotFound, "no background manager for sensor")
+		return
+	}
+
+	store := sqlite.NewTuningPresetStore(ws.db)
+	preset, err := store.Get(name)
+	if err != nil {
+		ws.writeTuningPresetError(w, "load", err)
+		return
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(preset.Params, &body); err != nil {
+		ws.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("stored preset %q is not a JSON object: %v", name, err))
+		return
+	}
+	patch, err := normaliseTuningPatch(body)
+	if err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := applyRuntimeTuningPatch(ws, bm, patch); err != nil {
+		ws.writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opsf("applied tuning preset %q to sensor %s", name, sensorID)
+
+	ws.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"preset": preset.Name,
+		"config": ws.runtimeTuningConfig(bm),
+	})
+}
+
+// writeTuningPresetError maps store errors to 404 for missing presets and
+// 500 otherwise.
+func (ws *Server) writeTuningPresetError(w http.ResponseWriter, op string, err error) {
+	if errors.Is(err, sqlite.ErrNotFound) {
+		ws.writeJSONError(w, http.StatusNotFound, "preset not found")
+		return
+	}
+	ws.writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to %s preset: %v", op, err))
+}
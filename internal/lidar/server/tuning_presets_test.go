@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l3grid"
+	sqlite "github.com/banshee-data/velocity.report/internal/lidar/storage/sqlite"
+)
+
+func serveTuningPreset(ws *Server, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	w := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/lidar/presets", ws.withDB(ws.handleTuningPresets))
+	mux.HandleFunc("/api/lidar/presets/", ws.withDB(ws.handleTuningPresetByName))
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestTuningPresets_SaveListApplyDelete(t *testing.T) {
+	testDB, cleanup := setupTestDBWrapped(t)
+	defer cleanup()
+
+	sensorID := "preset-sensor"
+	bm := l3grid.NewBackgroundManager(sensorID, 1, 1, l3grid.BackgroundParams{NoiseRelativeFraction: 0.01}, nil)
+	if bm == nil {
+		t.Skip("could not create background manager")
+	}
+	ws := &Server{db: testDB, sensorID: sensorID}
+
+	w := serveTuningPreset(ws, http.MethodPost, "/api/lidar/presets", SaveTuningPresetRequest{
+		Name:   "night",
+		Params: map[string]interface{}{"l3": map[string]interface{}{"ema_baseline_v1": map[string]interface{}{"noise_relative": 0.05}}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("save: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveTuningPreset(ws, http.MethodGet, "/api/lidar/presets", nil)
+	var list struct {
+		Presets []sqlite.TuningPreset `json:"presets"`
+		Count   int                   `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if list.Count != 1 || list.Presets[0].Name != "night" {
+		t.Fatalf("unexpected list: %s", w.Body.String())
+	}
+
+	w = serveTuningPreset(ws, http.MethodGet, "/api/lidar/presets/night", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: expected 200, got %d", w.Code)
+	}
+
+	w = serveTuningPreset(ws, http.MethodPost, "/api/lidar/presets/night/apply?sensor_id="+sensorID, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("apply: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := bm.GetParams().NoiseRelativeFraction; got != 0.05 {
+		t.Errorf("expected NoiseRelativeFraction 0.05 after apply, got %v", got)
+	}
+
+	w = serveTuningPreset(ws, http.MethodDelete, "/api/lidar/presets/night", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d", w.Code)
+	}
+	w = serveTuningPreset(ws, http.MethodPost, "/api/lidar/presets/night/apply?sensor_id="+sensorID, nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("apply deleted preset: expected 404, got %d", w.Code)
+	}
+	w = serveTuningPreset(ws, http.MethodDelete, "/api/lidar/presets/night", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("delete missing preset: expected 404, got %d", w.Code)
+	}
+}
+
+func TestTuningPresets_ApplyIsAtomic(t *testing.T) {
+	testDB, cleanup := setupTestDBWrapped(t)
+	defer cleanup()
+
+	sensorID := "preset-atomic-sensor"
+	bm := l3grid.NewBackgroundManager(sensorID, 1, 1, l3grid.BackgroundParams{NoiseRelativeFraction: 0.01}, nil)
+	if bm == nil {
+		t.Skip("could not create background manager")
+	}
+	ws := &Server{db: testDB, sensorID: sensorID}
+
+	// One valid and one invalid value: validation must reject the whole
+	// preset before anything is applied.
+	store := sqlite.NewTuningPresetStore(testDB)
+	if err := store.Save(&sqlite.TuningPreset{
+		Name:   "broken",
+		Params: json.RawMessage(`{"l3.ema_baseline_v1.noise_relative":0.05,"l3.ema_baseline_v1.background_update_fraction":-1}`),
+	}); err != nil {
+		t.Fatalf("seed preset: %v", err)
+	}
+
+	w := serveTuningPreset(ws, http.MethodPost, "/api/lidar/presets/broken/apply?sensor_id="+sensorID, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := bm.GetParams().NoiseRelativeFraction; got != 0.01 {
+		t.Errorf("expected NoiseRelativeFraction unchanged at 0.01, got %v", got)
+	}
+}
+
+func TestTuningPresets_SaveValidation(t *testing.T) {
+	testDB, cleanup := setupTestDBWrapped(t)
+	defer cleanup()
+	ws := &Server{db: testDB}
+
+	tests := []struct {
+		name string
+		body interface{}
+	}{
+		{"missing name", SaveTuningPresetRequest{Params: map[string]interface{}{"l3.ema_baseline_v1.noise_relative": 0.05}}},
+		{"slash in name", SaveTuningPresetRequest{Name: "a/b", Params: map[string]interface{}{"l3.ema_baseline_v1.noise_relative": 0.05}}},
+		{"missing params", SaveTuningPresetRequest{Name: "empty"}},
+		{"no editable params", SaveTuningPresetRequest{Name: "static", Params: map[string]interface{}{"l1.sensor": "x"}}},
+		{"malformed body", "not an object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveTuningPreset(ws, http.MethodPost, "/api/lidar/presets", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if w := serveTuningPreset(&Server{}, http.MethodGet, "/api/lidar/presets", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a database, got %d", w.Code)
+	}
+	if w := serveTuningPreset(ws, http.MethodPut, "/api/lidar/presets", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+	if w := serveTuningPreset(ws, http.MethodGet, "/api/lidar/presets/x/unknown", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown action, got %d", w.Code)
+	}
+}
@@ -124,6 +124,14 @@ func (ws *Server) runtimeTuningConfigForSource(bm *l3grid.BackgroundManager, sou
 			l3.SettlingMaxSpreadDelta = roundTo6(float64(params.SettlingMaxSpreadDelta))
 			l3.SettlingMinRegionStability = roundTo6(float64(params.SettlingMinRegionStability))
 			l3.SettlingMinConfidence = roundTo6(float64(params.SettlingMinConfidence))
+			l3.AzimuthToleranceDeg = roundTo6(float64(params.AzimuthToleranceDeg))
+			l3.RangeToleranceSigmaK = roundTo6(float64(params.RangeToleranceSigmaK))
+			l3.VarianceThresholdK = roundTo6(float64(params.VarianceThresholdK))
+			l3.RegionWarmupMinFrames = params.RegionWarmupMinFrames
+			l3.BackgroundModel = string(params.ModelType)
+			if l3.BackgroundModel == "" {
+				l3.BackgroundModel = string(l3grid.BackgroundModelMean)
+			}
 		}
 		if l4 != nil {
 			l4.ForegroundDBSCANEps = roundTo6(float64(params.ForegroundDBSCANEps))
@@ -164,6 +172,24 @@ func (ws *Server) runtimeTuningConfigForSource(bm *l3grid.BackgroundManager, sou
 			l5.SplitSizeRatio = roundTo6(float64(trackerCfg.SplitSizeRatio))
 			l5.DeletedTrackGracePeriod = compactDuration(trackerCfg.DeletedTrackGracePeriod)
 			l5.MinObservationsForClassification = trackerCfg.MinObservationsForClassification
+			l5.EvictionPolicy = trackerCfg.EvictionPolicy.String()
+			l5.GatingMode = string(trackerCfg.GatingMode)
+			if l5.GatingMode == "" {
+				l5.GatingMode = string(l5tracks.GatingModeIsotropic)
+			}
+			l5.GatingChiSquare = roundTo6(float64(trackerCfg.GatingChiSquare))
+			if l5.GatingChiSquare <= 0 {
+				l5.GatingChiSquare = l5tracks.DefaultGatingChiSquare
+			}
+			l5.GatingAlongTrackFraction = roundTo6(float64(trackerCfg.GatingAlongTrackFraction))
+			if l5.GatingAlongTrackFraction <= 0 {
+				l5.GatingAlongTrackFraction = l5tracks.DefaultGatingAlongTrackFraction
+			}
+			l5.SpeedSmoothingFrames = trackerCfg.SpeedSmoothingFrames
+			l5.ShapeAssociationWeight = roundTo6(float64(trackerCfg.ShapeAssociationWeight))
+			if ws.classifier != nil {
+				l5.SizeInstabilityWeight = roundTo6(float64(ws.classifier.SizeInstabilityWeight))
+			}
 		}
 	}
 
@@ -279,7 +305,13 @@ func lastTuningPathSegment(path string) string {
 }
 
 func applyRuntimeTuningPatch(ws *Server, bm *l3grid.BackgroundManager, paths map[string]interface{}) error {
-	cfg := ws.runtimeTuningConfig(bm)
+	return applyRuntimeTuningPatchForSource(ws, bm, paths, "")
+}
+
+// applyRuntimeTuningPatchForSource is applyRuntimeTuningPatch with the data
+// source given rather than read, for callers that hold dataSourceMu.
+func applyRuntimeTuningPatchForSource(ws *Server, bm *l3grid.BackgroundManager, paths map[string]interface{}, source DataSource) error {
+	cfg := ws.runtimeTuningConfigForSource(bm, source)
 
 	orderedPaths := make([]string, 0, len(paths))
 	for path := range paths {
@@ -315,6 +347,16 @@ func applyRuntimeTuningPatch(ws *Server, bm *l3grid.BackgroundManager, paths map
 	}
 
 	ws.storeTuningConfig(cfg)
+
+	applied := make(map[string]interface{}, len(editablePaths))
+	for _, path := range editablePaths {
+		applied[path] = paths[path]
+	}
+	sensorID := ws.sensorID
+	if bm != nil && bm.Grid != nil {
+		sensorID = bm.Grid.SensorID
+	}
+	ws.recordParamChange(sensorID, applied)
 	return nil
 }
 
@@ -333,6 +375,9 @@ func validateRuntimeTuningPath(path string) error {
 	case strings.HasPrefix(path, "l5.cv_kf_v1."):
 		return nil
 	case path == "l1.sensor", path == "l1.data_source",
+		path == "l1.min_range_metres", path == "l1.max_range_metres",
+		path == "l1.ring_range_clip", strings.HasPrefix(path, "l1.ring_range_clip."),
+		path == "l1.timestamp_max_backstep",
 		path == "pipeline.buffer_timeout", path == "pipeline.min_frame_points",
 		path == "pipeline.flush_interval", path == "pipeline.background_flush",
 		path == "version", path == "l3.engine", path == "l4.engine", path == "l5.engine":
@@ -404,6 +449,16 @@ func applyRuntimeTuningPath(ws *Server, bm *l3grid.BackgroundManager, cfg *cfgpk
 			params.SettlingMinRegionStability = float32(l3.SettlingMinRegionStability)
 		case "l3.ema_baseline_v1.settling_min_confidence":
 			params.SettlingMinConfidence = float32(l3.SettlingMinConfidence)
+		case "l3.ema_baseline_v1.azimuth_tolerance_deg":
+			params.AzimuthToleranceDeg = float32(l3.AzimuthToleranceDeg)
+		case "l3.ema_baseline_v1.range_tolerance_sigma_k":
+			params.RangeToleranceSigmaK = float32(l3.RangeToleranceSigmaK)
+		case "l3.ema_baseline_v1.background_model":
+			params.ModelType = l3grid.BackgroundModelType(l3.BackgroundModel)
+		case "l3.ema_baseline_v1.variance_threshold_k":
+			params.VarianceThresholdK = float32(l3.VarianceThresholdK)
+		case "l3.ema_baseline_v1.region_warmup_min_frames":
+			params.RegionWarmupMinFrames = l3.RegionWarmupMinFrames
 		case "l4.dbscan_xy_v1.foreground_dbscan_eps":
 			params.ForegroundDBSCANEps = float32(l4.ForegroundDBSCANEps)
 		case "l4.dbscan_xy_v1.foreground_min_cluster_points":
@@ -442,6 +497,8 @@ func applyRuntimeTuningPath(ws *Server, bm *l3grid.BackgroundManager, cfg *cfgpk
 				trackerCfg.MaxMissesConfirmed = l5.MaxMissesConfirmed
 			case "l5.cv_kf_v1.max_tracks":
 				trackerCfg.MaxTracks = l5.MaxTracks
+			case "l5.cv_kf_v1.eviction_policy":
+				trackerCfg.EvictionPolicy = l5tracks.ParseEvictionPolicy(l5.EvictionPolicy)
 			case "l5.cv_kf_v1.max_reasonable_speed_mps":
 				trackerCfg.MaxReasonableSpeedMps = float32(l5.MaxReasonableSpeedMps)
 			case "l5.cv_kf_v1.max_position_jump_metres":
@@ -468,6 +525,18 @@ func applyRuntimeTuningPath(ws *Server, bm *l3grid.BackgroundManager, cfg *cfgpk
 				trackerCfg.DeletedTrackGracePeriod = gracePeriod
 			case "l5.cv_kf_v1.min_observations_for_classification":
 				trackerCfg.MinObservationsForClassification = l5.MinObservationsForClassification
+			case "l5.cv_kf_v1.gating_mode":
+				trackerCfg.GatingMode = l5tracks.GatingMode(l5.GatingMode)
+			case "l5.cv_kf_v1.gating_chi_square":
+				trackerCfg.GatingChiSquare = float32(l5.GatingChiSquare)
+			case "l5.cv_kf_v1.gating_along_track_fraction":
+				trackerCfg.GatingAlongTrackFraction = float32(l5.GatingAlongTrackFraction)
+			case "l5.cv_kf_v1.speed_smoothing_frames":
+				trackerCfg.SpeedSmoothingFrames = l5.SpeedSmoothingFrames
+			case "l5.cv_kf_v1.shape_association_weight":
+				trackerCfg.ShapeAssociationWeight = float32(l5.ShapeAssociationWeight)
+			case "l5.cv_kf_v1.size_instability_weight":
+				// Classifier-only; applied below.
 			default:
 				gracePeriodErr = fmt.Errorf("unsupported runtime path %q", path)
 			}
@@ -475,8 +544,13 @@ func applyRuntimeTuningPath(ws *Server, bm *l3grid.BackgroundManager, cfg *cfgpk
 		if gracePeriodErr != nil {
 			return gracePeriodErr
 		}
-		if path == "l5.cv_kf_v1.min_observations_for_classification" && ws.classifier != nil {
-			ws.classifier.MinObservations = l5.MinObservationsForClassification
+		if ws.classifier != nil {
+			switch path {
+			case "l5.cv_kf_v1.min_observations_for_classification":
+				ws.classifier.MinObservations = l5.MinObservationsForClassification
+			case "l5.cv_kf_v1.size_instability_weight":
+				ws.classifier.SizeInstabilityWeight = float32(l5.SizeInstabilityWeight)
+			}
 		}
 		return nil
 	}
@@ -213,6 +213,8 @@ func TestNormaliseTuningPatchAndValidateRuntimePath(t *testing.T) {
 	disallowed := map[string]string{
 		"pipeline.buffer_timeout":           "not runtime-updatable",
 		"l4.dbscan_xy_v1.height_band_floor": "not runtime-updatable",
+		"l1.max_range_metres":               "not runtime-updatable",
+		"l1.ring_range_clip.1.min_metres":   "not runtime-updatable",
 		"l1.udp_port":                       "unknown tuning path",
 		"unknown.path":                      "unknown tuning path",
 	}
@@ -344,10 +346,22 @@ func TestApplyRuntimeTuningPatchAndPathErrors(t *testing.T) {
 		"l3.ema_baseline_v1.settling_max_spread_delta":            0.01,
 		"l3.ema_baseline_v1.settling_min_region_stability":        0.9,
 		"l3.ema_baseline_v1.settling_min_confidence":              2.0,
+		"l3.ema_baseline_v1.azimuth_tolerance_deg":                0.3,
+		"l3.ema_baseline_v1.range_tolerance_sigma_k":              3.0,
+		"l3.ema_baseline_v1.background_model":                     "median",
+		"l3.ema_baseline_v1.variance_threshold_k":                 2.5,
+		"l3.ema_baseline_v1.region_warmup_min_frames":             40,
 		"l4.dbscan_xy_v1.foreground_max_input_points":             5000,
 		"l5.cv_kf_v1.min_observations_for_classification":         10,
 		"l5.cv_kf_v1.deleted_track_grace_period":                  "3s",
 		"l5.cv_kf_v1.max_tracks":                                  55,
+		"l5.cv_kf_v1.eviction_policy":                             "oldest_coasting",
+		"l5.cv_kf_v1.size_instability_weight":                     0.25,
+		"l5.cv_kf_v1.gating_mode":                                 "mahalanobis",
+		"l5.cv_kf_v1.gating_chi_square":                           5.99,
+		"l5.cv_kf_v1.gating_along_track_fraction":                 0.8,
+		"l5.cv_kf_v1.speed_smoothing_frames":                      5,
+		"l5.cv_kf_v1.shape_association_weight":                    0.5,
 	}
 	if err := applyRuntimeTuningPatch(ws, bm, patch); err != nil {
 		t.Fatalf("applyRuntimeTuningPatch returned error: %v", err)
@@ -367,12 +381,37 @@ func TestApplyRuntimeTuningPatchAndPathErrors(t *testing.T) {
 	if got := bm.GetParams().SettlingMinConfidence; !approxEqualFloat64(float64(got), 2.0) {
 		t.Fatalf("background manager settling_min_confidence = %v, want 2.0", got)
 	}
+	if got := bm.GetParams(); !approxEqualFloat64(float64(got.AzimuthToleranceDeg), 0.3) || got.RangeToleranceSigmaK != 3 {
+		t.Fatalf("background manager anisotropic closeness = %v°, %vσ, want 0.3°, 3σ", got.AzimuthToleranceDeg, got.RangeToleranceSigmaK)
+	}
+	if got := bm.GetParams().ModelType; got != l3grid.BackgroundModelMedian {
+		t.Fatalf("background manager model = %q, want median", got)
+	}
+	if got := bm.GetParams().VarianceThresholdK; got != 2.5 {
+		t.Fatalf("background manager variance k = %v, want 2.5", got)
+	}
+	if got := bm.GetParams().RegionWarmupMinFrames; got != 40 {
+		t.Fatalf("background manager region warmup = %d, want 40", got)
+	}
 	if tracker.Config.MinObservationsForClassification != 10 || classifier.MinObservations != 10 {
 		t.Fatalf("expected min observations 10, got tracker=%d classifier=%d", tracker.Config.MinObservationsForClassification, classifier.MinObservations)
 	}
-	if tracker.Config.DeletedTrackGracePeriod != 3*time.Second || tracker.Config.MaxTracks != 55 {
+	if classifier.SizeInstabilityWeight != 0.25 {
+		t.Fatalf("classifier size instability weight = %v, want 0.25", classifier.SizeInstabilityWeight)
+	}
+	if tracker.Config.DeletedTrackGracePeriod != 3*time.Second || tracker.Config.MaxTracks != 55 ||
+		tracker.Config.EvictionPolicy != l5tracks.EvictionPolicyOldestCoasting {
 		t.Fatalf("unexpected tracker runtime update: %+v", tracker.Config)
 	}
+	if tracker.Config.GatingMode != l5tracks.GatingModeMahalanobis ||
+		!approxEqualFloat64(float64(tracker.Config.GatingChiSquare), 5.99) ||
+		!approxEqualFloat64(float64(tracker.Config.GatingAlongTrackFraction), 0.8) {
+		t.Fatalf("unexpected tracker gating update: %+v", tracker.Config)
+	}
+	if tracker.Config.SpeedSmoothingFrames != 5 || tracker.Config.ShapeAssociationWeight != 0.5 {
+		t.Fatalf("tracker speed smoothing = %d, shape weight = %v, want 5, 0.5",
+			tracker.Config.SpeedSmoothingFrames, tracker.Config.ShapeAssociationWeight)
+	}
 	if ws.snapshotTuningConfig().L3.EmaBaselineV1.NoiseRelative != 0.2 {
 		t.Fatal("stored tuning config was not updated")
 	}
@@ -409,7 +409,8 @@ func setupCov2Server(t *testing.T) *Server {
 			bounding_box_width REAL DEFAULT 0,
 			bounding_box_height REAL DEFAULT 0,
 			height_p95 REAL DEFAULT 0,
-			intensity_mean REAL DEFAULT 0
+			intensity_mean REAL DEFAULT 0,
+			points_count_normalized REAL
 		)`,
 		`CREATE TABLE IF NOT EXISTS lidar_clusters (
 			cluster_id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -1654,6 +1655,8 @@ func setupCov3Server(t *testing.T) *Server {
 			changed_cells_count INTEGER DEFAULT 0,
 			snapshot_reason TEXT DEFAULT ''
 		)`,
+		bgSnapshotLatestDDL,
+		bgSnapshotLatestTriggerDDL,
 		`CREATE TABLE IF NOT EXISTS lidar_tracked_objects (
 			track_id TEXT PRIMARY KEY,
 			sensor_id TEXT NOT NULL DEFAULT '',
@@ -1691,7 +1694,8 @@ func setupCov3Server(t *testing.T) *Server {
 			bounding_box_width REAL DEFAULT 0,
 			bounding_box_height REAL DEFAULT 0,
 			height_p95 REAL DEFAULT 0,
-			intensity_mean REAL DEFAULT 0
+			intensity_mean REAL DEFAULT 0,
+			points_count_normalized REAL
 		)`,
 		`CREATE TABLE IF NOT EXISTS lidar_clusters (
 			cluster_id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -1779,6 +1783,20 @@ func makeGridBlob(t *testing.T, cells []l3grid.BackgroundCell) []byte {
 	return buf.Bytes()
 }
 
+// bgSnapshotLatestDDL mirrors the lidar_bg_snapshot_latest pointer table
+// that GetLatestBgSnapshot reads through.
+const bgSnapshotLatestDDL = `CREATE TABLE IF NOT EXISTS lidar_bg_snapshot_latest (
+	sensor_id TEXT PRIMARY KEY,
+	snapshot_id INTEGER NOT NULL
+)`
+
+// bgSnapshotLatestTriggerDDL moves the pointer on raw test inserts, as
+// InsertBgSnapshot does in production.
+const bgSnapshotLatestTriggerDDL = `CREATE TRIGGER IF NOT EXISTS test_bg_snapshot_latest AFTER INSERT ON lidar_bg_snapshot BEGIN
+	INSERT INTO lidar_bg_snapshot_latest (sensor_id, snapshot_id) VALUES (NEW.sensor_id, NEW.snapshot_id)
+	ON CONFLICT (sensor_id) DO UPDATE SET snapshot_id = excluded.snapshot_id;
+END`
+
 // insertSnapshot inserts a bg_snapshot row into the test DB.
 func insertSnapshot(t *testing.T, sqlDB *sql.DB, sensorID string, rings, azBins int, blob []byte) {
 	t.Helper()
@@ -3363,7 +3381,8 @@ func setupTrackDB(t *testing.T) *sql.DB {
 		bounding_box_height REAL,
 		points_count INTEGER,
 		height_p95 REAL,
-		intensity_mean REAL
+		intensity_mean REAL,
+		points_count_normalized REAL
 	)`)
 	if err != nil {
 		t.Fatalf("create lidar_clusters table: %v", err)
@@ -3385,11 +3404,17 @@ func setupTrackDB(t *testing.T) *sql.DB {
 		intensity_mean_avg REAL,
 		object_class TEXT,
 		object_confidence REAL,
-		classification_model TEXT
+		classification_model TEXT,
+		archived_unix_nanos INTEGER,
+		composite_speed_mps REAL
 	)`)
 	if err != nil {
 		t.Fatalf("create lidar_tracks table: %v", err)
 	}
+	_, err = rawDB.Exec(`CREATE VIEW lidar_tracks_all AS SELECT *, 'main' AS partition_source FROM lidar_tracks`)
+	if err != nil {
+		t.Fatalf("create lidar_tracks_all view: %v", err)
+	}
 	return rawDB
 }
 
@@ -3959,6 +3984,8 @@ func setupCov4Server(t *testing.T) *Server {
 			changed_cells_count INTEGER DEFAULT 0,
 			snapshot_reason TEXT DEFAULT ''
 		)`,
+		bgSnapshotLatestDDL,
+		bgSnapshotLatestTriggerDDL,
 		`CREATE TABLE IF NOT EXISTS lidar_tracked_objects (
 			track_id TEXT PRIMARY KEY,
 			sensor_id TEXT NOT NULL DEFAULT '',
@@ -4003,7 +4030,8 @@ func setupCov4Server(t *testing.T) *Server {
 			bounding_box_height REAL DEFAULT 0,
 			points_count INTEGER DEFAULT 0,
 			height_p95 REAL DEFAULT 0,
-			intensity_mean REAL DEFAULT 0
+			intensity_mean REAL DEFAULT 0,
+			points_count_normalized REAL
 		)`,
 		`CREATE TABLE IF NOT EXISTS lidar_tracks (
 			track_id TEXT PRIMARY KEY,
@@ -4022,8 +4050,12 @@ func setupCov4Server(t *testing.T) *Server {
 			intensity_mean_avg REAL DEFAULT 0,
 			object_class TEXT DEFAULT '',
 			object_confidence REAL DEFAULT 0,
-			classification_model TEXT DEFAULT ''
+			classification_model TEXT DEFAULT '',
+			archived_unix_nanos INTEGER,
+			composite_speed_mps REAL
 		)`,
+		`CREATE VIEW IF NOT EXISTS lidar_tracks_all AS SELECT *, 'main' AS partition_source FROM lidar_tracks`,
+		`CREATE VIEW IF NOT EXISTS lidar_track_observations_all AS SELECT *, 'main' AS partition_source FROM lidar_track_observations`,
 		`CREATE TABLE IF NOT EXISTS lidar_run_records (
 			run_id TEXT PRIMARY KEY,
 			created_at INTEGER NOT NULL DEFAULT 0,
@@ -5189,6 +5221,10 @@ func setupCov6Server(t *testing.T, sensorID string) (*Server, *sql.DB) {
 		snapshot_reason TEXT
 	)`)
 	require.NoError(t, err)
+	for _, ddl := range []string{bgSnapshotLatestDDL, bgSnapshotLatestTriggerDDL} {
+		_, err = sqlDB.Exec(ddl)
+		require.NoError(t, err)
+	}
 
 	// Tables needed for TrackAPI
 	tables := []string{
@@ -6214,3 +6250,47 @@ func TestHandleStatus_WrongPath(t *testing.T) {
 		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
 	}
 }
+
+// --- handleGridFreeze / handleGridThaw ---
+
+func TestHandleGridFreezeThaw(t *testing.T) {
+	sensorID := fmt.Sprintf("grid-freeze-%d", time.Now().UnixNano())
+	mgr := l3grid.NewBackgroundManager(sensorID, 10, 36, l3grid.BackgroundParams{}, nil)
+	l3grid.RegisterBackgroundManager(sensorID, mgr)
+	ws := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/lidar/grid/freeze", ws.handleGridFreeze)
+	mux.HandleFunc("POST /api/lidar/grid/thaw", ws.handleGridThaw)
+
+	post := func(path string) map[string]interface{} {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path+"?sensor_id="+sensorID, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d; body: %s", path, w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := post("/api/lidar/grid/freeze"); resp["learning_frozen"] != true || !mgr.IsLearningFrozen() {
+		t.Errorf("freeze: response %v, manager frozen=%v", resp, mgr.IsLearningFrozen())
+	}
+	if resp := post("/api/lidar/grid/thaw"); resp["learning_frozen"] != false || mgr.IsLearningFrozen() {
+		t.Errorf("thaw: response %v, manager frozen=%v", resp, mgr.IsLearningFrozen())
+	}
+
+	for path, want := range map[string]int{
+		"/api/lidar/grid/freeze":                        http.StatusBadRequest,
+		"/api/lidar/grid/thaw?sensor_id=no-such-sensor": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, w.Code, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -4049,6 +4050,34 @@ func TestServer_HandleTuningParams_POST_WithTracker(t *testing.T) {
 	}
 }
 
+func TestServer_HandleTuningParams_POST_UnlimitedMaxTracks(t *testing.T) {
+	cleanup := setupTestBackgroundManager(t, "params-unlimited-max-tracks")
+	defer cleanup()
+
+	server := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		SensorID:          "params-unlimited-max-tracks",
+		UDPListenerConfig: network.UDPListenerConfig{Address: ":0"},
+	})
+	tracker := l5tracks.NewTracker(l5tracks.DefaultTrackerConfig())
+	server.SetTracker(tracker)
+
+	body := strings.NewReader(`{"l5":{"cv_kf_v1":{"max_tracks": 0, "eviction_policy": "lowest_quality"}}}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/lidar/params?sensor_id=params-unlimited-max-tracks", body)
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	server.handleTuningParams(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if tracker.Config.MaxTracks != 0 || tracker.Config.EvictionPolicy != l5tracks.EvictionPolicyLowestQuality {
+		t.Errorf("tracker max_tracks=%d eviction_policy=%q, want 0 and lowest_quality",
+			tracker.Config.MaxTracks, tracker.Config.EvictionPolicy)
+	}
+}
+
 func TestServer_HandleTuningParams_POST_InvalidMaxTracks(t *testing.T) {
 	cleanup := setupTestBackgroundManager(t, "params-invalid-max-tracks")
 	defer cleanup()
@@ -4068,9 +4097,9 @@ func TestServer_HandleTuningParams_POST_InvalidMaxTracks(t *testing.T) {
 		name string
 		body string
 	}{
-		{"zero", `{"l5":{"cv_kf_v1":{"max_tracks": 0}}}`},
 		{"negative", `{"l5":{"cv_kf_v1":{"max_tracks": -1}}}`},
 		{"too_large", `{"l5":{"cv_kf_v1":{"max_tracks": 1001}}}`},
+		{"unknown_eviction_policy", `{"l5":{"cv_kf_v1":{"eviction_policy": "newest"}}}`},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/api/lidar/params?sensor_id=params-invalid-max-tracks", strings.NewReader(tc.body))
@@ -4760,3 +4789,83 @@ func TestClose_WithRunningListener(t *testing.T) {
 		t.Errorf("Close returned error: %v", err)
 	}
 }
+
+func TestServer_HandleGridReset_Scoped(t *testing.T) {
+	const sensorID = "grid-reset-scoped"
+	mgr := l3grid.NewBackgroundManager(sensorID, 2, 8, l3grid.BackgroundParams{}, nil)
+	defer l3grid.RegisterBackgroundManager(sensorID, nil)
+	for i := range mgr.Grid.Cells {
+		mgr.Grid.Cells[i].AverageRangeMeters = 12
+		mgr.Grid.Cells[i].TimesSeenCount = 20
+	}
+
+	server := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		UDPListenerConfig: network.UDPListenerConfig{Address: ":0"},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/lidar/grid_reset?sensor_id="+sensorID+"&az_min=0&az_max=90", nil)
+	rr := httptest.NewRecorder()
+	server.handleGridReset(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		CellsReset int `json:"cells_reset"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// Bins 0 and 1 (centres 22.5° and 67.5°) on both rings.
+	if resp.CellsReset != 4 {
+		t.Errorf("cells_reset = %d, want 4", resp.CellsReset)
+	}
+	if got := mgr.Grid.Cells[mgr.Grid.Idx(0, 4)].TimesSeenCount; got != 20 {
+		t.Errorf("out-of-scope cell times seen = %d, want 20", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/lidar/grid_reset?sensor_id="+sensorID+"&range_min=abc", nil)
+	rr = httptest.NewRecorder()
+	server.handleGridReset(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid bound: expected 400, got %d", rr.Code)
+	}
+}
+
+func TestServer_HandleAcceptanceMetrics_BucketRepresentation(t *testing.T) {
+	const sensorID = "acceptance-bucket-sensor"
+	cleanup := setupTestBackgroundManager(t, sensorID)
+	defer cleanup()
+	l3grid.GetBackgroundManager(sensorID).Grid.AcceptanceBucketsMeters = []float64{1, 2.5, 4, 12.125}
+
+	server := NewServer(Config{
+		Address:           ":0",
+		Stats:             NewPacketStats(),
+		SensorID:          sensorID,
+		UDPListenerConfig: network.UDPListenerConfig{Address: ":0"},
+	})
+	ts := httptest.NewServer(http.HandlerFunc(server.handleAcceptanceMetrics))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/lidar/acceptance?sensor_id=" + sensorID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `"BucketsMeters":[1,2.5,4,12.125]`) {
+		t.Errorf("BucketsMeters not in canonical form: %s", body)
+	}
+
+	// HTTP and direct sweep backends label buckets identically.
+	want := []string{"1", "2.5", "4", "12.125"}
+	for name, got := range map[string][]string{
+		"http":   NewClient(ts.Client(), ts.URL, sensorID).FetchBuckets(),
+		"direct": NewDirectBackend(sensorID, nil).FetchBuckets(),
+	} {
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s backend buckets = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlgorithmEvalRun is one evaluation-harness comparison of foreground
+// extractors over a capture. Agreement is the fraction of points on which
+// the extractors agree; IoU is the intersection over union of their
+// foreground sets. ForegroundRatios maps each extractor to the fraction of
+// points it marked foreground.
+type AlgorithmEvalRun struct {
+	RunID            string             `json:"run_id"`
+	SourcePath       string             `json:"source_path,omitempty"`
+	SensorID         string             `json:"sensor_id,omitempty"`
+	Extractors       []string           `json:"extractors"`
+	MergeMode        string             `json:"merge_mode,omitempty"`
+	FrameCount       int                `json:"frame_count"`
+	MeanAgreement    float64            `json:"mean_agreement"`
+	MeanIoU          float64            `json:"mean_iou"`
+	ForegroundRatios map[string]float64 `json:"foreground_ratios,omitempty"`
+	CreatedAt        int64              `json:"created_at"`
+}
+
+// AlgorithmFrameResult is the comparison of the extractors on one frame.
+type AlgorithmFrameResult struct {
+	FrameIndex       int                `json:"frame_index"`
+	TimestampNs      int64              `json:"timestamp_ns"`
+	Agreement        float64            `json:"agreement"`
+	IoU              float64            `json:"iou"`
+	ForegroundRatios map[string]float64 `json:"foreground_ratios,omitempty"`
+}
+
+// AlgorithmEvalStore provides persistence for evaluation-harness results.
+type AlgorithmEvalStore struct {
+	db DBClient
+}
+
+// NewAlgorithmEvalStore creates a new AlgorithmEvalStore.
+func NewAlgorithmEvalStore(db DBClient) *AlgorithmEvalStore {
+	return &AlgorithmEvalStore{db: db}
+}
+
+// InsertRun persists a run and its per-frame results in one transaction.
+// If RunID is empty, a UUID is generated; if CreatedAt is zero, the current
+// time is used; if FrameCount is zero, it is set to len(frames).
+func (s *AlgorithmEvalStore) InsertRun(run *AlgorithmEvalRun, frames []AlgorithmFrameResult) error {
+	if len(run.Extractors) == 0 {
+		return fmt.Errorf("algorithm evaluation: at least one extractor is required")
+	}
+	if run.RunID == "" {
+		run.RunID = uuid.New().String()
+	}
+	if run.CreatedAt == 0 {
+		run.CreatedAt = time.Now().UnixNano()
+	}
+	if run.FrameCount == 0 {
+		run.FrameCount = len(frames)
+	}
+	extractors, err := json.Marshal(run.Extractors)
+	if err != nil {
+		return fmt.Errorf("marshal extractors: %w", err)
+	}
+	ratios, err := marshalRatios(run.ForegroundRatios)
+	if err != nil {
+		return err
+	}
+
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		if _, err := tx.Exec(`
+			INSERT INTO lidar_algorithm_runs (
+				run_id, source_path, sensor_id, extractors_json, merge_mode,
+				frame_count, mean_agreement, mean_iou, foreground_ratios_json, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			run.RunID, nullString(run.SourcePath), nullString(run.SensorID), string(extractors), nullString(run.MergeMode),
+			run.FrameCount, run.MeanAgreement, run.MeanIoU, ratios, run.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("insert algorithm run: %w", err)
+		}
+		stmt, err := tx.Prepare(`
+			INSERT INTO lidar_algorithm_frame_results (
+				run_id, frame_index, timestamp_ns, agreement, iou, foreground_ratios_json
+			) VALUES (?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return fmt.Errorf("prepare frame insert: %w", err)
+		}
+		defer stmt.Close()
+		for _, f := range frames {
+			ratios, err := marshalRatios(f.ForegroundRatios)
+			if err != nil {
+				return err
+			}
+			if _, err := stmt.Exec(run.RunID, f.FrameIndex, f.TimestampNs, f.Agreement, f.IoU, ratios); err != nil {
+				return fmt.Errorf("insert frame %d: %w", f.FrameIndex, err)
+			}
+		}
+		return nil
+	})
+}
+
+const algorithmRunColumns = `run_id, source_path, sensor_id, extractors_json, merge_mode,
+		       frame_count, mean_agreement, mean_iou, foreground_ratios_json, created_at`
+
+// GetRun returns a single run by ID, without its frames.
+func (s *AlgorithmEvalStore) GetRun(runID string) (*AlgorithmEvalRun, error) {
+	row := s.db.QueryRow(`SELECT `+algorithmRunColumns+`
+		FROM lidar_algorithm_runs
+		WHERE run_id = ?`, runID)
+	run, err := scanAlgorithmRun(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("algorithm run %s: %w", runID, ErrNotFound)
+	}
+	return run, err
+}
+
+// ListRuns returns the most recent runs, newest first. A limit of zero or
+// less returns every run.
+func (s *AlgorithmEvalStore) ListRuns(limit int) ([]*AlgorithmEvalRun, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(`SELECT `+algorithmRunColumns+`
+		FROM lidar_algorithm_runs
+		ORDER BY created_at DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query algorithm runs: %w", err)
+	}
+	return collectAlgorithmRuns(rows)
+}
+
+// AgreementTrend returns the runs that compared exactly extractors, in the
+// same order, with mergeMode, oldest first, so their agreement can be
+// followed across captures. An empty mergeMode matches any.
+func (s *AlgorithmEvalStore) AgreementTrend(extractors []string, mergeMode string) ([]*AlgorithmEvalRun, error) {
+	key, err := json.Marshal(extractors)
+	if err != nil {
+		return nil, fmt.Errorf("marshal extractors: %w", err)
+	}
+	rows, err := s.db.Query(`SELECT `+algorithmRunColumns+`
+		FROM lidar_algorithm_runs
+		WHERE extractors_json = ? AND (? = '' OR merge_mode = ?)
+		ORDER BY created_at ASC`, string(key), mergeMode, mergeMode)
+	if err != nil {
+		return nil, fmt.Errorf("query agreement trend: %w", err)
+	}
+	return collectAlgorithmRuns(rows)
+}
+
+// ListFrames returns a run's per-frame results in frame order.
+func (s *AlgorithmEvalStore) ListFrames(runID string) ([]AlgorithmFrameResult, error) {
+	rows, err := s.db.Query(`
+		SELECT frame_index, timestamp_ns, agreement, iou, foreground_ratios_json
+		FROM lidar_algorithm_frame_results
+		WHERE run_id = ?
+		ORDER BY frame_index`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("query algorithm frames: %w", err)
+	}
+	defer rows.Close()
+
+	var frames []AlgorithmFrameResult
+	for rows.Next() {
+		var f AlgorithmFrameResult
+		var ts sql.NullInt64
+		var agreement, iou sql.NullFloat64
+		var ratios sql.NullString
+		if err := rows.Scan(&f.FrameIndex, &ts, &agreement, &iou, &ratios); err != nil {
+			return nil, fmt.Errorf("scan algorithm frame: %w", err)
+		}
+		f.TimestampNs, f.Agreement, f.IoU = ts.Int64, agreement.Float64, iou.Float64
+		if f.ForegroundRatios, err = unmarshalRatios(ratios); err != nil {
+			return nil, err
+		}
+		frames = append(frames, f)
+	}
+	return frames, rows.Err()
+}
+
+// DeleteRun removes a run and its per-frame results.
+func (s *AlgorithmEvalStore) DeleteRun(runID string) error {
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		if _, err := tx.Exec(`DELETE FROM lidar_algorithm_frame_results WHERE run_id = ?`, runID); err != nil {
+			return fmt.Errorf("delete algorithm frames: %w", err)
+		}
+		result, err := tx.Exec(`DELETE FROM lidar_algorithm_runs WHERE run_id = ?`, runID)
+		if err != nil {
+			return fmt.Errorf("delete algorithm run: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("rows affected: %w", err)
+		}
+		if affected == 0 {
+			return fmt.Errorf("algorithm run %s: %w", runID, ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func collectAlgorithmRuns(rows *sql.Rows) ([]*AlgorithmEvalRun, error) {
+	defer rows.Close()
+	var runs []*AlgorithmEvalRun
+	for rows.Next() {
+		run, err := scanAlgorithmRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// scanAlgorithmRun scans a row selected with algorithmRunColumns. It
+// returns sql.ErrNoRows unwrapped.
+func scanAlgorithmRun(row interface{ Scan(...any) error }) (*AlgorithmEvalRun, error) {
+	var run AlgorithmEvalRun
+	var sourcePath, sensorID, mergeMode, ratios sql.NullString
+	var meanAgreement, meanIoU sql.NullFloat64
+	var extractors string
+	err := row.Scan(
+		&run.RunID, &sourcePath, &sensorID, &extractors, &mergeMode,
+		&run.FrameCount, &meanAgreement, &meanIoU, &ratios, &run.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan algorithm run: %w", err)
+	}
+	run.SourcePath, run.SensorID, run.MergeMode = sourcePath.String, sensorID.String, mergeMode.String
+	run.MeanAgreement, run.MeanIoU = meanAgreement.Float64, meanIoU.Float64
+	if err := json.Unmarshal([]byte(extractors), &run.Extractors); err != nil {
+		return nil, fmt.Errorf("algorithm run %s: parse extractors: %w", run.RunID, err)
+	}
+	if run.ForegroundRatios, err = unmarshalRatios(ratios); err != nil {
+		return nil, fmt.Errorf("algorithm run %s: %w", run.RunID, err)
+	}
+	return &run, nil
+}
+
+func marshalRatios(ratios map[string]float64) (interface{}, error) {
+	if len(ratios) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(ratios)
+	if err != nil {
+		return nil, fmt.Errorf("marshal foreground ratios: %w", err)
+	}
+	return string(data), nil
+}
+
+func unmarshalRatios(s sql.NullString) (map[string]float64, error) {
+	if !s.Valid || s.String == "" {
+		return nil, nil
+	}
+	var ratios map[string]float64
+	if err := json.Unmarshal([]byte(s.String), &ratios); err != nil {
+		return nil, fmt.Errorf("parse foreground ratios: %w", err)
+	}
+	return ratios, nil
+}
@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAlgorithmEvalStore_RoundTrip(t *testing.T) {
+	sqlDB, cleanup := setupTrackingPipelineTestDB(t)
+	defer cleanup()
+
+	store := NewAlgorithmEvalStore(sqlDB)
+
+	run := &AlgorithmEvalRun{
+		SourcePath:       "captures/kerb-2026-10-01.pcap",
+		SensorID:         "hesai-01",
+		Extractors:       []string{"background", "velocity"},
+		MergeMode:        "union",
+		MeanAgreement:    0.94,
+		MeanIoU:          0.71,
+		ForegroundRatios: map[string]float64{"background": 0.031, "velocity": 0.027},
+	}
+	frames := []AlgorithmFrameResult{
+		{FrameIndex: 0, TimestampNs: 1_000, Agreement: 0.95, IoU: 0.70, ForegroundRatios: map[string]float64{"background": 0.03, "velocity": 0.02}},
+		{FrameIndex: 1, TimestampNs: 2_000, Agreement: 0.93, IoU: 0.72},
+	}
+	if err := store.InsertRun(run, frames); err != nil {
+		t.Fatalf("InsertRun: %v", err)
+	}
+	if run.RunID == "" || run.CreatedAt == 0 || run.FrameCount != 2 {
+		t.Fatalf("InsertRun did not fill defaults: %+v", run)
+	}
+
+	got, err := store.GetRun(run.RunID)
+	if err != nil {
+		t.Fatalf("GetRun: %v", err)
+	}
+	if got.SourcePath != run.SourcePath || got.SensorID != "hesai-01" || got.MergeMode != "union" ||
+		got.FrameCount != 2 || got.MeanAgreement != 0.94 || got.MeanIoU != 0.71 || got.CreatedAt != run.CreatedAt {
+		t.Errorf("GetRun = %+v, want %+v", got, run)
+	}
+	if len(got.Extractors) != 2 || got.Extractors[0] != "background" || got.Extractors[1] != "velocity" {
+		t.Errorf("extractors = %v", got.Extractors)
+	}
+	if got.ForegroundRatios["velocity"] != 0.027 {
+		t.Errorf("foreground ratios = %v", got.ForegroundRatios)
+	}
+
+	gotFrames, err := store.ListFrames(run.RunID)
+	if err != nil {
+		t.Fatalf("ListFrames: %v", err)
+	}
+	if len(gotFrames) != 2 || gotFrames[0].TimestampNs != 1_000 || gotFrames[1].IoU != 0.72 {
+		t.Fatalf("frames = %+v", gotFrames)
+	}
+	if gotFrames[0].ForegroundRatios["background"] != 0.03 || gotFrames[1].ForegroundRatios != nil {
+		t.Errorf("frame ratios = %v / %v", gotFrames[0].ForegroundRatios, gotFrames[1].ForegroundRatios)
+	}
+
+	if err := store.DeleteRun(run.RunID); err != nil {
+		t.Fatalf("DeleteRun: %v", err)
+	}
+	if _, err := store.GetRun(run.RunID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetRun after delete: err = %v, want ErrNotFound", err)
+	}
+	if frames, _ := store.ListFrames(run.RunID); len(frames) != 0 {
+		t.Errorf("%d frames left after delete", len(frames))
+	}
+	if err := store.DeleteRun(run.RunID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second DeleteRun: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAlgorithmEvalStore_AgreementTrend(t *testing.T) {
+	sqlDB, cleanup := setupTrackingPipelineTestDB(t)
+	defer cleanup()
+
+	store := NewAlgorithmEvalStore(sqlDB)
+	insert := func(source string, extractors []string, mode string, agreement float64, createdAt int64) {
+		t.Helper()
+		run := &AlgorithmEvalRun{SourcePath: source, Extractors: extractors, MergeMode: mode, MeanAgreement: agreement, CreatedAt: createdAt}
+		if err := store.InsertRun(run, nil); err != nil {
+			t.Fatalf("InsertRun %s: %v", source, err)
+		}
+	}
+	pair := []string{"background", "velocity"}
+	insert("b.pcap", pair, "union", 0.91, 200)
+	insert("a.pcap", pair, "union", 0.88, 100)
+	insert("c.pcap", pair, "intersection", 0.80, 300)
+	insert("d.pcap", []string{"velocity", "background"}, "union", 0.50, 400)
+
+	trend, err := store.AgreementTrend(pair, "union")
+	if err != nil {
+		t.Fatalf("AgreementTrend: %v", err)
+	}
+	if len(trend) != 2 || trend[0].SourcePath != "a.pcap" || trend[1].SourcePath != "b.pcap" {
+		t.Fatalf("union trend = %d runs, want a.pcap then b.pcap", len(trend))
+	}
+
+	all, err := store.AgreementTrend(pair, "")
+	if err != nil {
+		t.Fatalf("AgreementTrend any mode: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("any-mode trend = %d runs, want 3", len(all))
+	}
+
+	recent, err := store.ListRuns(2)
+	if err != nil {
+		t.Fatalf("ListRuns: %v", err)
+	}
+	if len(recent) != 2 || recent[0].SourcePath != "d.pcap" || recent[1].SourcePath != "c.pcap" {
+		t.Errorf("ListRuns(2) = %d runs, want d.pcap then c.pcap", len(recent))
+	}
+	if every, _ := store.ListRuns(0); len(every) != 4 {
+		t.Errorf("ListRuns(0) = %d runs, want 4", len(every))
+	}
+
+	if err := store.InsertRun(&AlgorithmEvalRun{}, nil); err == nil {
+		t.Error("expected an error for a run without extractors")
+	}
+}
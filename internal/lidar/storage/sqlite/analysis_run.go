@@ -2,7 +2,6 @@ package sqlite
 
 import (
 	"encoding/json"
-	"fmt"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -41,7 +40,7 @@ type AnalysisRun struct {
 	TotalTracks         int             `json:"total_tracks"`
 	ConfirmedTracks     int             `json:"confirmed_tracks"`
 	ProcessingTimeMs    int64           `json:"processing_time_ms"`
-	Status              string          `json:"status"` // "running", "completed", "failed"
+	Status              string          `json:"status"` // "pending", "running", "completed", "failed"
 	ErrorMessage        string          `json:"error_message,omitempty"`
 	ParentRunID         string          `json:"parent_run_id,omitempty"`
 	Notes               string          `json:"notes,omitempty"`
@@ -345,37 +344,13 @@ func NewAnalysisRunStore(db DBClient) *AnalysisRunStore {
 	return &AnalysisRunStore{db: db}
 }
 
-// isSQLiteBusy checks if an error is a SQLite SQLITE_BUSY error.
+// isSQLiteBusy checks if an error is a transient SQLite SQLITE_BUSY or
+// SQLITE_LOCKED error.
 func isSQLiteBusy(err error) bool {
 	if err == nil {
 		return false
 	}
 	errStr := err.Error()
-	return strings.Contains(errStr, "database is locked") || strings.Contains(errStr, "SQLITE_BUSY")
-}
-
-// retryOnBusy retries a database operation with exponential backoff on SQLITE_BUSY errors.
-// This handles SQLite's single-writer limitation when multiple goroutines try to write.
-func retryOnBusy(operation func() error) error {
-	const maxRetries = 5
-	const baseDelay = 10 * time.Millisecond
-
-	var err error
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		err = operation()
-		if err == nil {
-			return nil
-		}
-
-		if !isSQLiteBusy(err) {
-			return err
-		}
-
-		if attempt < maxRetries-1 {
-			delay := baseDelay * (1 << uint(attempt)) // Exponential backoff: 10ms, 20ms, 40ms, 80ms, 160ms
-			time.Sleep(delay)
-		}
-	}
-
-	return fmt.Errorf("operation failed after %d retries: %w", maxRetries, err)
+	return strings.Contains(errStr, "database is locked") || strings.Contains(errStr, "SQLITE_BUSY") ||
+		strings.Contains(errStr, "database table is locked") || strings.Contains(errStr, "SQLITE_LOCKED")
 }
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"reflect"
 	"testing"
 	"time"
 
@@ -253,6 +254,70 @@ func TestListRuns_Empty(t *testing.T) {
 	}
 }
 
+// TestListRunsFiltered tests SQL-side filtering and pagination of runs.
+func TestListRunsFiltered(t *testing.T) {
+	db, cleanup := setupAnalysisRunTestDB(t)
+	defer cleanup()
+
+	store := NewAnalysisRunStore(db)
+
+	base := time.Unix(1700000000, 0)
+	runs := []*AnalysisRun{
+		{RunID: "r0", CreatedAt: base, SourceType: "pcap", SensorID: "sensor-a", Status: "completed"},
+		{RunID: "r1", CreatedAt: base.Add(1 * time.Hour), SourceType: "pcap", SensorID: "sensor-a", Status: "failed", ErrorMessage: "boom"},
+		{RunID: "r2", CreatedAt: base.Add(2 * time.Hour), SourceType: "live", SensorID: "sensor-b", Status: "completed"},
+		{RunID: "r3", CreatedAt: base.Add(3 * time.Hour), SourceType: "pcap", SensorID: "sensor-a", Status: "completed"},
+	}
+	for _, run := range runs {
+		if err := store.InsertRun(run); err != nil {
+			t.Fatalf("InsertRun failed: %v", err)
+		}
+	}
+
+	ids := func(list []*AnalysisRun) []string {
+		out := make([]string, len(list))
+		for i, r := range list {
+			out[i] = r.RunID
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter RunFilter
+		want   []string
+	}{
+		{"all", RunFilter{}, []string{"r3", "r2", "r1", "r0"}},
+		{"sensor", RunFilter{SensorID: "sensor-a"}, []string{"r3", "r1", "r0"}},
+		{"status", RunFilter{Status: "failed"}, []string{"r1"}},
+		{"source type", RunFilter{SourceType: "live"}, []string{"r2"}},
+		{"time window", RunFilter{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)}, []string{"r2", "r1"}},
+		// The limit applies after filtering.
+		{"limit after filter", RunFilter{SensorID: "sensor-a", Status: "completed", Limit: 1}, []string{"r3"}},
+		{"offset", RunFilter{Limit: 2, Offset: 1}, []string{"r2", "r1"}},
+		{"offset without limit", RunFilter{Offset: 3}, []string{"r0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.ListRunsFiltered(tt.filter)
+			if err != nil {
+				t.Fatalf("ListRunsFiltered failed: %v", err)
+			}
+			if !reflect.DeepEqual(ids(got), tt.want) {
+				t.Errorf("got %v, want %v", ids(got), tt.want)
+			}
+		})
+	}
+
+	failed, err := store.ListRunsFiltered(RunFilter{Status: "failed"})
+	if err != nil || len(failed) != 1 {
+		t.Fatalf("ListRunsFiltered failed: %v", err)
+	}
+	if failed[0].ErrorMessage != "boom" {
+		t.Errorf("ErrorMessage = %q, want boom", failed[0].ErrorMessage)
+	}
+}
+
 // TestInsertAndGetRunTracks tests inserting and retrieving run tracks.
 func TestInsertAndGetRunTracks(t *testing.T) {
 	db, cleanup := setupAnalysisRunTestDB(t)
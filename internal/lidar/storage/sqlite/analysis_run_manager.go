@@ -174,11 +174,18 @@ func (m *AnalysisRunManager) startPreparedRun(run *AnalysisRun) (string, error)
 		run.Status = "running"
 	}
 
-	m.currentRun = run
-	if err := m.store.InsertRun(m.currentRun); err != nil {
-		m.currentRun = nil
+	// A run queued by the caller (status "pending") is promoted in place so
+	// its ID and created_at survive; otherwise the run is inserted.
+	claimed, err := m.store.ClaimPendingRun(run)
+	if err != nil {
 		return "", err
 	}
+	if !claimed {
+		if err := m.store.InsertRun(run); err != nil {
+			return "", err
+		}
+	}
+	m.currentRun = run
 
 	m.startTime = time.Now()
 	m.totalFrames = 0
@@ -313,6 +320,17 @@ func (m *AnalysisRunManager) FailRun(errMsg string) error {
 	return nil
 }
 
+// CurrentRunProgress returns the active run's ID with the frames and
+// distinct tracks recorded so far, or an empty ID if no run is active.
+func (m *AnalysisRunManager) CurrentRunProgress() (runID string, frames, tracks int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.currentRun == nil {
+		return "", 0, 0
+	}
+	return m.currentRun.RunID, m.totalFrames, len(m.tracksSeen)
+}
+
 // IsRunActive returns true if there's an active analysis run.
 func (m *AnalysisRunManager) IsRunActive() bool {
 	m.mu.RLock()
@@ -635,3 +635,76 @@ func TestCompleteRun_PersistsFrameBoundsWithImmutableConfig(t *testing.T) {
 		t.Fatalf("FrameEndNs = %v, want 250", run.FrameEndNs)
 	}
 }
+
+func TestStartRunWithConfig_ClaimsPendingRun(t *testing.T) {
+	db, cleanup := setupAnalysisRunDB(t)
+	defer cleanup()
+
+	manager := NewAnalysisRunManager(db, "pending-sensor")
+	store := NewAnalysisRunStore(db)
+	queuedAt := time.Unix(1_700_000_000, 0)
+	if err := store.InsertRun(&AnalysisRun{
+		RunID:      "queued-run",
+		CreatedAt:  queuedAt,
+		SourceType: "pcap",
+		SourcePath: "/tmp/queued.pcap",
+		SensorID:   "pending-sensor",
+		Status:     "pending",
+	}); err != nil {
+		t.Fatalf("InsertRun(pending) failed: %v", err)
+	}
+	if err := store.UpdateRunStatus("queued-run", "pending", ""); err != nil {
+		t.Fatalf("UpdateRunStatus(pending) failed: %v", err)
+	}
+	pending, err := store.GetRun("queued-run")
+	if err != nil {
+		t.Fatalf("GetRun(pending) failed: %v", err)
+	}
+	if pending.Status != "pending" || pending.CompletedAt != nil {
+		t.Fatalf("pending run = status %q completed_at %v", pending.Status, pending.CompletedAt)
+	}
+
+	cfg := cfgpkg.MustLoadDefaultConfig()
+	runID, err := manager.StartRunWithConfig(AnalysisRunStartOptions{
+		PreferredRunID:      "queued-run",
+		SourcePath:          "/data/queued.pcap",
+		RequestedParamsJSON: json.RawMessage(`{"l5":{"max_tracks":32}}`),
+		EffectiveConfig:     cfg,
+	})
+	if err != nil {
+		t.Fatalf("StartRunWithConfig failed: %v", err)
+	}
+	if runID != "queued-run" {
+		t.Fatalf("run ID = %q, want queued-run", runID)
+	}
+
+	run, err := store.GetRun(runID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if run.Status != "running" || run.SourcePath != "/data/queued.pcap" {
+		t.Errorf("claimed run = status %q source %q", run.Status, run.SourcePath)
+	}
+	if run.RunConfigID == "" || run.RequestedParamSetID == "" {
+		t.Errorf("claimed run missing provenance: run_config_id=%q requested_param_set_id=%q", run.RunConfigID, run.RequestedParamSetID)
+	}
+	if !run.CreatedAt.Equal(queuedAt) {
+		t.Errorf("created_at = %v, want the queue time %v", run.CreatedAt, queuedAt)
+	}
+
+	manager.RecordFrame(1)
+	if id, frames, _ := manager.CurrentRunProgress(); id != runID || frames != 1 {
+		t.Errorf("CurrentRunProgress = %q, %d frames", id, frames)
+	}
+	if err := manager.CompleteRun(); err != nil {
+		t.Fatalf("CompleteRun failed: %v", err)
+	}
+
+	// A completed run is not claimed again: the same ID now conflicts.
+	if _, err := manager.StartRunWithConfig(AnalysisRunStartOptions{
+		PreferredRunID:  "queued-run",
+		EffectiveConfig: cfg,
+	}); err == nil {
+		t.Error("expected an error restarting a completed run ID")
+	}
+}
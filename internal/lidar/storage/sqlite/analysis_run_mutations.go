@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -75,9 +76,8 @@ func (s *AnalysisRunStore) InsertRun(run *AnalysisRun) error {
 		) VALUES (%s)
 	`, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
 
-	// Retry on SQLITE_BUSY errors
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(query, args...)
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query, args...)
 		if err != nil {
 			return fmt.Errorf("insert analysis run: %w", err)
 		}
@@ -85,6 +85,55 @@ func (s *AnalysisRunStore) InsertRun(run *AnalysisRun) error {
 	})
 }
 
+// ClaimPendingRun moves a queued run (status "pending") to run.Status,
+// filling in the source, sensor and config provenance known once replay
+// starts. The pending row's created_at is kept. It returns false, and
+// changes nothing, when no pending run with run.RunID exists.
+func (s *AnalysisRunStore) ClaimPendingRun(run *AnalysisRun) (bool, error) {
+	caps, err := s.runRecordCapabilities()
+	if err != nil {
+		return false, err
+	}
+
+	setClauses := []string{"status = ?", "source_path = ?", "sensor_id = ?", "parent_run_id = ?"}
+	args := []any{run.Status, nullString(run.SourcePath), run.SensorID, nullString(run.ParentRunID)}
+	if caps.RunConfigID {
+		setClauses = append(setClauses, "run_config_id = ?")
+		args = append(args, nullString(run.RunConfigID))
+	}
+	if caps.RequestedParamSetID {
+		setClauses = append(setClauses, "requested_param_set_id = ?")
+		args = append(args, nullString(run.RequestedParamSetID))
+	}
+	if caps.ReplayCaseID {
+		setClauses = append(setClauses, "replay_case_id = ?")
+		args = append(args, nullString(run.ReplayCaseID))
+	}
+	args = append(args, run.RunID)
+
+	query := fmt.Sprintf(
+		`UPDATE lidar_run_records SET %s WHERE run_id = ? AND status = 'pending'`,
+		strings.Join(setClauses, ", "),
+	)
+	var claimed bool
+	err = WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("claim pending run: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("claim pending run: %w", err)
+		}
+		claimed = n > 0
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
 // UpdateRunStatus updates the status of an analysis run.
 func (s *AnalysisRunStore) UpdateRunStatus(runID, status, errorMsg string) error {
 	caps, err := s.runRecordCapabilities()
@@ -94,7 +143,7 @@ func (s *AnalysisRunStore) UpdateRunStatus(runID, status, errorMsg string) error
 
 	setClauses := []string{"status = ?", "error_message = ?"}
 	args := []any{status, nullString(errorMsg)}
-	if caps.CompletedAt && status != "running" {
+	if caps.CompletedAt && status != "running" && status != "pending" {
 		setClauses = append(setClauses, "completed_at = ?")
 		args = append(args, time.Now().UnixNano())
 	}
@@ -104,8 +153,8 @@ func (s *AnalysisRunStore) UpdateRunStatus(runID, status, errorMsg string) error
 		`UPDATE lidar_run_records SET %s WHERE run_id = ?`,
 		strings.Join(setClauses, ", "),
 	)
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(query, args...)
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query, args...)
 		if err != nil {
 			return fmt.Errorf("update run status: %w", err)
 		}
@@ -116,8 +165,8 @@ func (s *AnalysisRunStore) UpdateRunStatus(runID, status, errorMsg string) error
 // UpdateRunVRLogPath updates the vrlog_path of an analysis run.
 func (s *AnalysisRunStore) UpdateRunVRLogPath(runID, vrlogPath string) error {
 	query := `UPDATE lidar_run_records SET vrlog_path = ? WHERE run_id = ?`
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(query, nullString(vrlogPath), runID)
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query, nullString(vrlogPath), runID)
 		if err != nil {
 			return fmt.Errorf("update run vrlog path: %w", err)
 		}
@@ -174,9 +223,8 @@ func (s *AnalysisRunStore) CompleteRun(runID string, stats *AnalysisStats) error
 		WHERE run_id = ?
 	`, strings.Join(setClauses, ",\n\t\t\t"))
 
-	// Retry on SQLITE_BUSY errors
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(query, args...)
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query, args...)
 		if err != nil {
 			return fmt.Errorf("complete run: %w", err)
 		}
@@ -199,7 +247,6 @@ func nullableTimeUnixNano(value *time.Time) interface{} {
 }
 
 // InsertRunTrack inserts a track for an analysis run.
-// Uses retry logic to handle SQLITE_BUSY errors from concurrent writes.
 func (s *AnalysisRunStore) InsertRunTrack(track *RunTrack) error {
 	userLabel := normaliseRunTrackString(track.UserLabel)
 	qualityLabel := normaliseRunTrackQualityLabel(track.QualityLabel)
@@ -241,9 +288,8 @@ func (s *AnalysisRunStore) InsertRunTrack(track *RunTrack) error {
 		linkedJSON,
 	)
 
-	// Retry on SQLITE_BUSY errors
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(query, args...)
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query, args...)
 		if err != nil {
 			return fmt.Errorf("insert run track: %w", err)
 		}
@@ -274,8 +320,8 @@ func (s *AnalysisRunStore) UpdateTrackLabel(runID, trackID, userLabel, qualityLa
 		WHERE run_id = ? AND track_id = ?
 	`
 
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(query,
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query,
 			nullString(userLabel),
 			confidence,
 			nullString(labelerID),
@@ -310,8 +356,8 @@ func (s *AnalysisRunStore) UpdateTrackQualityFlags(runID, trackID string, isSpli
 		WHERE run_id = ? AND track_id = ?
 	`
 
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(query, isSplit, isMerge, linkedJSON, runID, trackID)
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query, isSplit, isMerge, linkedJSON, runID, trackID)
 		if err != nil {
 			return fmt.Errorf("update track quality flags: %w", err)
 		}
@@ -233,21 +233,70 @@ func (s *AnalysisRunStore) GetRun(runID string) (*AnalysisRun, error) {
 	return run, nil
 }
 
+// RunFilter narrows ListRunsFiltered. Zero-valued fields do not filter.
+type RunFilter struct {
+	SensorID   string
+	Status     string    // "pending", "running", "completed", "failed"
+	SourceType string    // "pcap", "live"
+	Since      time.Time // created_at >= Since
+	Until      time.Time // created_at < Until
+	Limit      int       // <= 0 means no limit
+	Offset     int
+}
+
 // ListRuns retrieves recent analysis runs.
 func (s *AnalysisRunStore) ListRuns(limit int) ([]*AnalysisRun, error) {
+	return s.ListRunsFiltered(RunFilter{Limit: limit})
+}
+
+// ListRunsFiltered retrieves analysis runs matching filter, newest first.
+// Filtering happens in SQL so Limit applies to the matching rows rather
+// than to the most recent runs overall.
+func (s *AnalysisRunStore) ListRunsFiltered(filter RunFilter) ([]*AnalysisRun, error) {
 	columns, caps, err := s.runRecordSelectColumns()
 	if err != nil {
 		return nil, err
 	}
 
-	query := fmt.Sprintf(`
-		SELECT %s
-		FROM lidar_run_records
-		ORDER BY created_at DESC
-		LIMIT ?
-	`, strings.Join(columns, ", "))
+	var where []string
+	var args []any
+	if filter.SensorID != "" {
+		where = append(where, "sensor_id = ?")
+		args = append(args, filter.SensorID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.SourceType != "" {
+		where = append(where, "source_type = ?")
+		args = append(args, filter.SourceType)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until.UnixNano())
+	}
 
-	rows, err := s.db.Query(query, limit)
+	query := fmt.Sprintf("SELECT %s FROM lidar_run_records", strings.Join(columns, ", "))
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+	if filter.Limit > 0 || filter.Offset > 0 {
+		// SQLite requires LIMIT before OFFSET; -1 means unbounded.
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, filter.Offset)
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list runs: %w", err)
 	}
@@ -23,6 +23,11 @@
 //   - track_measurement_sql.go — shared SQL column list, scan helpers, and insert args
 //   - lidar_all_tracks VIEW — UNION ALL for ad-hoc cross-table queries
 //
+// Track reads go through the lidar_tracks_all and lidar_track_observations_all
+// VIEWs rather than the tables. With track partitioning (track_partitions.go)
+// TEMP views of the same names shadow them and also cover the attached
+// partition files, so rotated history stays visible to every read path.
+//
 // See docs/lidar/architecture/LIDAR_ARCHITECTURE.md §L5/L8 for the
 // full layer context.
 //
@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -49,8 +50,8 @@ func (s *EvaluationStore) Insert(eval *Evaluation) error {
 		eval.CreatedAt = time.Now().UnixNano()
 	}
 
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(`
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(`
 			INSERT INTO lidar_replay_evaluations (
 				evaluation_id, replay_case_id, reference_run_id, candidate_run_id,
 				detection_rate, fragmentation, false_positive_rate, velocity_coverage,
@@ -125,8 +126,8 @@ func (s *EvaluationStore) Get(evaluationID string) (*Evaluation, error) {
 
 // Delete removes an evaluation by ID.
 func (s *EvaluationStore) Delete(evaluationID string) error {
-	return retryOnBusy(func() error {
-		result, err := s.db.Exec(`DELETE FROM lidar_replay_evaluations WHERE evaluation_id = ?`, evaluationID)
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		result, err := tx.Exec(`DELETE FROM lidar_replay_evaluations WHERE evaluation_id = ?`, evaluationID)
 		if err != nil {
 			return fmt.Errorf("delete evaluation: %w", err)
 		}
@@ -72,44 +72,25 @@ func setupTestEvaluationDB(t *testing.T) *sql.DB {
 	return db
 }
 
-type evaluationRowsAffectedErrorDB struct {
+type evaluationBeginErrorDB struct {
 	db  *sql.DB
 	err error
 }
 
-type evaluationRowsAffectedErrorResult struct {
-	err error
-}
-
-func (d *evaluationRowsAffectedErrorDB) Exec(query string, args ...any) (sql.Result, error) {
-	result, execErr := d.db.Exec(query, args...)
-	if execErr != nil {
-		return nil, execErr
-	}
-	if query == `DELETE FROM lidar_replay_evaluations WHERE evaluation_id = ?` {
-		return evaluationRowsAffectedErrorResult{err: d.err}, nil
-	}
-	return result, nil
+func (d *evaluationBeginErrorDB) Exec(query string, args ...any) (sql.Result, error) {
+	return d.db.Exec(query, args...)
 }
 
-func (d *evaluationRowsAffectedErrorDB) Query(query string, args ...any) (*sql.Rows, error) {
+func (d *evaluationBeginErrorDB) Query(query string, args ...any) (*sql.Rows, error) {
 	return d.db.Query(query, args...)
 }
 
-func (d *evaluationRowsAffectedErrorDB) QueryRow(query string, args ...any) *sql.Row {
+func (d *evaluationBeginErrorDB) QueryRow(query string, args ...any) *sql.Row {
 	return d.db.QueryRow(query, args...)
 }
 
-func (d *evaluationRowsAffectedErrorDB) Begin() (*sql.Tx, error) {
-	return d.db.Begin()
-}
-
-func (r evaluationRowsAffectedErrorResult) LastInsertId() (int64, error) {
-	return 0, nil
-}
-
-func (r evaluationRowsAffectedErrorResult) RowsAffected() (int64, error) {
-	return 0, r.err
+func (d *evaluationBeginErrorDB) Begin() (*sql.Tx, error) {
+	return nil, d.err
 }
 
 func seedEvaluationTestData(t *testing.T, db *sql.DB) {
@@ -341,7 +322,7 @@ func TestEvaluationStore_Delete_DBError(t *testing.T) {
 	}
 }
 
-func TestEvaluationStore_Delete_RowsAffectedError(t *testing.T) {
+func TestEvaluationStore_Delete_BeginError(t *testing.T) {
 	db := setupTestEvaluationDB(t)
 	defer db.Close()
 	seedEvaluationTestData(t, db)
@@ -356,10 +337,10 @@ func TestEvaluationStore_Delete_RowsAffectedError(t *testing.T) {
 		t.Fatalf("Insert failed: %v", err)
 	}
 
-	store = NewEvaluationStore(&evaluationRowsAffectedErrorDB{db: db, err: errors.New("rows boom")})
+	store = NewEvaluationStore(&evaluationBeginErrorDB{db: db, err: errors.New("begin boom")})
 	err := store.Delete(eval.EvaluationID)
-	if err == nil || err.Error() != "rows affected: rows boom" {
-		t.Fatalf("expected rows affected error, got %v", err)
+	if err == nil || err.Error() != "begin tx: begin boom" {
+		t.Fatalf("expected begin error, got %v", err)
 	}
 }
 
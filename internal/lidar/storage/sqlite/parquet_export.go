@@ -0,0 +1,326 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/snappy"
+)
+
+// ParquetTrackRow is one lidar_tracks row in Parquet form. The sensor ID
+// and date are partition keys (directory names), not columns. Nullable
+// database columns are optional.
+type ParquetTrackRow struct {
+	TrackID              string    `parquet:"track_id"`
+	FrameID              string    `parquet:"frame_id"`
+	TrackState           string    `parquet:"track_state"`
+	StartTime            time.Time `parquet:"start_time,timestamp(microsecond)"`
+	StartUnixNanos       int64     `parquet:"start_unix_nanos"`
+	EndUnixNanos         *int64    `parquet:"end_unix_nanos,optional"`
+	ObservationCount     *int32    `parquet:"observation_count,optional"`
+	AvgSpeedMps          *float32  `parquet:"avg_speed_mps,optional"`
+	MaxSpeedMps          *float32  `parquet:"max_speed_mps,optional"`
+	BoundingBoxLengthAvg *float32  `parquet:"bounding_box_length_avg,optional"`
+	BoundingBoxWidthAvg  *float32  `parquet:"bounding_box_width_avg,optional"`
+	BoundingBoxHeightAvg *float32  `parquet:"bounding_box_height_avg,optional"`
+	HeightP95Max         *float32  `parquet:"height_p95_max,optional"`
+	IntensityMeanAvg     *float32  `parquet:"intensity_mean_avg,optional"`
+	ObjectClass          *string   `parquet:"object_class,optional"`
+	ObjectConfidence     *float32  `parquet:"object_confidence,optional"`
+	ClassificationModel  *string   `parquet:"classification_model,optional"`
+	TrackLengthMeters    *float32  `parquet:"track_length_meters,optional"`
+	TrackDurationSecs    *float32  `parquet:"track_duration_secs,optional"`
+	OcclusionCount       *int32    `parquet:"occlusion_count,optional"`
+	MaxOcclusionFrames   *int32    `parquet:"max_occlusion_frames,optional"`
+	SpatialCoverage      *float32  `parquet:"spatial_coverage,optional"`
+	NoisePointRatio      *float32  `parquet:"noise_point_ratio,optional"`
+	CompositeSpeedMps    *float32  `parquet:"composite_speed_mps,optional"`
+}
+
+// ParquetObservationRow is one lidar_track_observations row in Parquet
+// form, partitioned by the owning track's sensor and the observation date.
+type ParquetObservationRow struct {
+	TrackID           string    `parquet:"track_id"`
+	Time              time.Time `parquet:"ts,timestamp(microsecond)"`
+	TSUnixNanos       int64     `parquet:"ts_unix_nanos"`
+	FrameID           string    `parquet:"frame_id"`
+	X                 *float32  `parquet:"x,optional"`
+	Y                 *float32  `parquet:"y,optional"`
+	Z                 *float32  `parquet:"z,optional"`
+	VelocityX         *float32  `parquet:"velocity_x,optional"`
+	VelocityY         *float32  `parquet:"velocity_y,optional"`
+	SpeedMps          *float32  `parquet:"speed_mps,optional"`
+	HeadingRad        *float32  `parquet:"heading_rad,optional"`
+	BoundingBoxLength *float32  `parquet:"bounding_box_length,optional"`
+	BoundingBoxWidth  *float32  `parquet:"bounding_box_width,optional"`
+	BoundingBoxHeight *float32  `parquet:"bounding_box_height,optional"`
+	HeightP95         *float32  `parquet:"height_p95,optional"`
+	IntensityMean     *float32  `parquet:"intensity_mean,optional"`
+}
+
+// ParquetExportOptions selects what ExportParquet writes.
+type ParquetExportOptions struct {
+	// Dir is the root of the dataset. Files are written Hive-style to
+	// <Dir>/<table>/sensor_id=<id>/date=<YYYY-MM-DD>/part-0.parquet.
+	Dir string
+	// SensorID restricts the export to one sensor; empty exports all.
+	// Archived tracks and their observations are never exported.
+	SensorID string
+	// Tracks starting, and observations taken, in [StartNanos, EndNanos).
+	StartNanos, EndNanos int64
+}
+
+// ParquetExportResult summarises an export.
+type ParquetExportResult struct {
+	Tracks       int
+	Observations int
+	Files        []string // Written files, relative to Dir, sorted
+}
+
+// parquetPartitionFile is the file written in each partition. Re-exporting
+// a range replaces it.
+const parquetPartitionFile = "part-0.parquet"
+
+// ExportParquet writes tracks and their observations in the time range to
+// a Parquet dataset partitioned by sensor and UTC date, which DuckDB,
+// pandas and Spark read as one table with sensor_id and date columns.
+// Observations are selected by their own timestamp, so a track spanning
+// midnight has observations in both date partitions.
+func ExportParquet(db DBClient, opts ParquetExportOptions) (ParquetExportResult, error) {
+	var result ParquetExportResult
+	if opts.Dir == "" {
+		return result, fmt.Errorf("parquet export: output directory is required")
+	}
+	if opts.EndNanos <= opts.StartNanos {
+		return result, fmt.Errorf("parquet export: end must be after start")
+	}
+
+	tracks := newParquetPartitions[ParquetTrackRow](opts.Dir, "tracks")
+	observations := newParquetPartitions[ParquetObservationRow](opts.Dir, "observations")
+
+	n, err := exportParquetTracks(db, opts, tracks)
+	result.Tracks = n
+	if err == nil {
+		result.Observations, err = exportParquetObservations(db, opts, observations)
+	}
+	if cerr := tracks.close(); err == nil {
+		err = cerr
+	}
+	if cerr := observations.close(); err == nil {
+		err = cerr
+	}
+	result.Files = append(tracks.files, observations.files...)
+	sort.Strings(result.Files)
+	return result, err
+}
+
+// trackFilter returns the WHERE fragment and args restricting a query on
+// lidar_tracks_all (aliased t) to opts.SensorID and leaving out archived tracks.
+func (opts ParquetExportOptions) trackFilter() (string, []any) {
+	const notArchived = " AND t.archived_unix_nanos IS NULL"
+	if opts.SensorID == "" {
+		return notArchived, nil
+	}
+	return notArchived + " AND t.sensor_id = ?", []any{opts.SensorID}
+}
+
+func exportParquetTracks(db DBClient, opts ParquetExportOptions, parts *parquetPartitions[ParquetTrackRow]) (int, error) {
+	filter, args := opts.trackFilter()
+	rows, err := db.Query(`
+		SELECT t.track_id, t.sensor_id, t.frame_id, t.track_state,
+			t.start_unix_nanos, t.end_unix_nanos, t.observation_count,
+			t.avg_speed_mps, t.max_speed_mps,
+			t.bounding_box_length_avg, t.bounding_box_width_avg, t.bounding_box_height_avg,
+			t.height_p95_max, t.intensity_mean_avg,
+			t.object_class, t.object_confidence, t.classification_model,
+			t.track_length_meters, t.track_duration_secs,
+			t.occlusion_count, t.max_occlusion_frames,
+			t.spatial_coverage, t.noise_point_ratio, t.composite_speed_mps
+		FROM lidar_tracks_all t
+		WHERE t.start_unix_nanos >= ? AND t.start_unix_nanos < ?`+filter+`
+		ORDER BY t.sensor_id, t.start_unix_nanos`,
+		append([]any{opts.StartNanos, opts.EndNanos}, args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("query tracks for parquet export: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var (
+			row                                      ParquetTrackRow
+			sensorID                                 string
+			end                                      sql.NullInt64
+			observations, occlusions, maxOcclusion   sql.NullInt64
+			avgSpeed, maxSpeed, length, width        sql.NullFloat64
+			height, heightP95, intensity, confidence sql.NullFloat64
+			trackLength, duration, coverage, noise   sql.NullFloat64
+			composite                                sql.NullFloat64
+			class, model                             sql.NullString
+		)
+		if err := rows.Scan(&row.TrackID, &sensorID, &row.FrameID, &row.TrackState,
+			&row.StartUnixNanos, &end, &observations,
+			&avgSpeed, &maxSpeed, &length, &width, &height,
+			&heightP95, &intensity, &class, &confidence, &model,
+			&trackLength, &duration, &occlusions, &maxOcclusion,
+			&coverage, &noise, &composite); err != nil {
+			return count, fmt.Errorf("scan track for parquet export: %w", err)
+		}
+		row.StartTime = time.Unix(0, row.StartUnixNanos).UTC()
+		if end.Valid {
+			row.EndUnixNanos = &end.Int64
+		}
+		row.ObservationCount = optionalInt32(observations)
+		row.AvgSpeedMps, row.MaxSpeedMps = optionalFloat32(avgSpeed), optionalFloat32(maxSpeed)
+		row.BoundingBoxLengthAvg = optionalFloat32(length)
+		row.BoundingBoxWidthAvg = optionalFloat32(width)
+		row.BoundingBoxHeightAvg = optionalFloat32(height)
+		row.HeightP95Max, row.IntensityMeanAvg = optionalFloat32(heightP95), optionalFloat32(intensity)
+		row.ObjectClass, row.ClassificationModel = optionalString(class), optionalString(model)
+		row.ObjectConfidence = optionalFloat32(confidence)
+		row.TrackLengthMeters, row.TrackDurationSecs = optionalFloat32(trackLength), optionalFloat32(duration)
+		row.OcclusionCount, row.MaxOcclusionFrames = optionalInt32(occlusions), optionalInt32(maxOcclusion)
+		row.SpatialCoverage, row.NoisePointRatio = optionalFloat32(coverage), optionalFloat32(noise)
+		row.CompositeSpeedMps = optionalFloat32(composite)
+
+		if err := parts.write(sensorID, row.StartTime, row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+func exportParquetObservations(db DBClient, opts ParquetExportOptions, parts *parquetPartitions[ParquetObservationRow]) (int, error) {
+	filter, args := opts.trackFilter()
+	rows, err := db.Query(`
+		SELECT o.track_id, t.sensor_id, o.ts_unix_nanos, o.frame_id,
+			o.x, o.y, o.z,
+			o.velocity_x, o.velocity_y, o.speed_mps, o.heading_rad,
+			o.bounding_box_length, o.bounding_box_width, o.bounding_box_height,
+			o.height_p95, o.intensity_mean
+		FROM lidar_track_observations_all o
+		JOIN lidar_tracks_all t ON o.track_id = t.track_id AND o.partition_source = t.partition_source
+		WHERE o.ts_unix_nanos >= ? AND o.ts_unix_nanos < ?`+filter+`
+		ORDER BY t.sensor_id, o.ts_unix_nanos`,
+		append([]any{opts.StartNanos, opts.EndNanos}, args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("query observations for parquet export: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var (
+			row                      ParquetObservationRow
+			sensorID                 string
+			x, y, z, vx, vy          sql.NullFloat64
+			speed, heading           sql.NullFloat64
+			length, width, height    sql.NullFloat64
+			heightP95, intensityMean sql.NullFloat64
+		)
+		if err := rows.Scan(&row.TrackID, &sensorID, &row.TSUnixNanos, &row.FrameID,
+			&x, &y, &z, &vx, &vy, &speed, &heading,
+			&length, &width, &height, &heightP95, &intensityMean); err != nil {
+			return count, fmt.Errorf("scan observation for parquet export: %w", err)
+		}
+		row.Time = time.Unix(0, row.TSUnixNanos).UTC()
+		row.X, row.Y, row.Z = optionalFloat32(x), optionalFloat32(y), optionalFloat32(z)
+		row.VelocityX, row.VelocityY = optionalFloat32(vx), optionalFloat32(vy)
+		row.SpeedMps, row.HeadingRad = optionalFloat32(speed), optionalFloat32(heading)
+		row.BoundingBoxLength = optionalFloat32(length)
+		row.BoundingBoxWidth = optionalFloat32(width)
+		row.BoundingBoxHeight = optionalFloat32(height)
+		row.HeightP95, row.IntensityMean = optionalFloat32(heightP95), optionalFloat32(intensityMean)
+
+		if err := parts.write(sensorID, row.Time, row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// parquetPartitions holds one open Parquet writer per sensor/date
+// partition of a table.
+type parquetPartitions[T any] struct {
+	dir, table string
+	open       map[string]*parquetPartition[T]
+	files      []string
+}
+
+type parquetPartition[T any] struct {
+	file   *os.File
+	writer *parquet.GenericWriter[T]
+}
+
+func newParquetPartitions[T any](dir, table string) *parquetPartitions[T] {
+	return &parquetPartitions[T]{dir: dir, table: table, open: make(map[string]*parquetPartition[T])}
+}
+
+// write appends row to the partition for sensorID and the UTC date of ts,
+// creating its file on first use.
+func (p *parquetPartitions[T]) write(sensorID string, ts time.Time, row T) error {
+	rel := filepath.Join(p.table, "sensor_id="+sensorID, "date="+ts.UTC().Format("2006-01-02"), parquetPartitionFile)
+	part := p.open[rel]
+	if part == nil {
+		path := filepath.Join(p.dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("create parquet partition: %w", err)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create parquet file: %w", err)
+		}
+		part = &parquetPartition[T]{file: f, writer: parquet.NewGenericWriter[T](f, parquet.Compression(&snappy.Codec{}))}
+		p.open[rel] = part
+		p.files = append(p.files, rel)
+	}
+	if _, err := part.writer.Write([]T{row}); err != nil {
+		return fmt.Errorf("write %s: %w", rel, err)
+	}
+	return nil
+}
+
+// close finishes every partition file, returning the first error.
+func (p *parquetPartitions[T]) close() error {
+	var first error
+	for rel, part := range p.open {
+		if err := part.writer.Close(); err != nil && first == nil {
+			first = fmt.Errorf("finish %s: %w", rel, err)
+		}
+		if err := part.file.Close(); err != nil && first == nil {
+			first = fmt.Errorf("close %s: %w", rel, err)
+		}
+	}
+	p.open = nil
+	return first
+}
+
+func optionalFloat32(v sql.NullFloat64) *float32 {
+	if !v.Valid {
+		return nil
+	}
+	f := float32(v.Float64)
+	return &f
+}
+
+func optionalInt32(v sql.NullInt64) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	i := int32(v.Int64)
+	return &i
+}
+
+func optionalString(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
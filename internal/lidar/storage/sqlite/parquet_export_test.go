@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// seedParquetExportDB inserts two tracks for sensor-a (one on each side of
+// midnight UTC starting 2026-03-02) and one for sensor-b, each with two
+// observations a second apart.
+func seedParquetExportDB(t *testing.T) DBClient {
+	t.Helper()
+	db, cleanup := setupTestDBWithSchema(t)
+	t.Cleanup(cleanup)
+
+	midnight := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).UnixNano()
+	seeds := []struct {
+		id, sensor string
+		start      int64
+	}{
+		{"track-a1", "sensor-a", midnight - int64(30*time.Minute)},
+		{"track-a2", "sensor-a", midnight + int64(30*time.Minute)},
+		{"track-b1", "sensor-b", midnight + int64(time.Hour)},
+	}
+	for _, s := range seeds {
+		track := &TrackedObject{
+			TrackID: s.id, CompositeSpeedMps: 6.25, TrackMeasurement: TrackMeasurement{SensorID: s.sensor,
+				TrackState:       TrackConfirmed,
+				StartUnixNanos:   s.start,
+				EndUnixNanos:     s.start + int64(time.Second),
+				ObservationCount: 2,
+				AvgSpeedMps:      6.5,
+				ObjectClass:      "car"},
+		}
+		if err := InsertTrack(db, track, "site/main"); err != nil {
+			t.Fatalf("InsertTrack: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			obs := &TrackObservation{
+				TrackID:     s.id,
+				TSUnixNanos: s.start + int64(i)*int64(time.Second),
+				FrameID:     "site/main",
+				X:           float32(i),
+				SpeedMps:    6.5,
+			}
+			if err := InsertTrackObservation(db, obs); err != nil {
+				t.Fatalf("InsertTrackObservation: %v", err)
+			}
+		}
+	}
+	return db
+}
+
+func TestExportParquet_PartitionsBySensorAndDate(t *testing.T) {
+	db := seedParquetExportDB(t)
+	dir := t.TempDir()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	result, err := ExportParquet(db, ParquetExportOptions{
+		Dir:        dir,
+		StartNanos: day.UnixNano(),
+		EndNanos:   day.Add(48 * time.Hour).UnixNano(),
+	})
+	if err != nil {
+		t.Fatalf("ExportParquet: %v", err)
+	}
+	if result.Tracks != 3 || result.Observations != 6 {
+		t.Fatalf("exported %d tracks, %d observations; want 3, 6", result.Tracks, result.Observations)
+	}
+	want := []string{
+		"observations/sensor_id=sensor-a/date=2026-03-01/part-0.parquet",
+		"observations/sensor_id=sensor-a/date=2026-03-02/part-0.parquet",
+		"observations/sensor_id=sensor-b/date=2026-03-02/part-0.parquet",
+		"tracks/sensor_id=sensor-a/date=2026-03-01/part-0.parquet",
+		"tracks/sensor_id=sensor-a/date=2026-03-02/part-0.parquet",
+		"tracks/sensor_id=sensor-b/date=2026-03-02/part-0.parquet",
+	}
+	if strings.Join(result.Files, "\n") != filepath.FromSlash(strings.Join(want, "\n")) {
+		t.Fatalf("files = %v, want %v", result.Files, want)
+	}
+
+	tracks, err := parquet.ReadFile[ParquetTrackRow](filepath.Join(dir, want[3]))
+	if err != nil {
+		t.Fatalf("read tracks: %v", err)
+	}
+	if len(tracks) != 1 || tracks[0].TrackID != "track-a1" {
+		t.Fatalf("tracks = %+v, want track-a1 only", tracks)
+	}
+	got := tracks[0]
+	if got.ObjectClass == nil || *got.ObjectClass != "car" {
+		t.Errorf("object_class = %v, want car", got.ObjectClass)
+	}
+	if got.AvgSpeedMps == nil || *got.AvgSpeedMps != 6.5 {
+		t.Errorf("avg_speed_mps = %v, want 6.5", got.AvgSpeedMps)
+	}
+	if got.CompositeSpeedMps == nil || *got.CompositeSpeedMps != 6.25 {
+		t.Errorf("composite_speed_mps = %v, want 6.25", got.CompositeSpeedMps)
+	}
+	if !got.StartTime.Equal(time.Unix(0, got.StartUnixNanos)) {
+		t.Errorf("start_time %v does not match start_unix_nanos %d", got.StartTime, got.StartUnixNanos)
+	}
+
+	obs, err := parquet.ReadFile[ParquetObservationRow](filepath.Join(dir, want[1]))
+	if err != nil {
+		t.Fatalf("read observations: %v", err)
+	}
+	if len(obs) != 2 || obs[0].TrackID != "track-a2" || obs[1].X == nil || *obs[1].X != 1 {
+		t.Fatalf("observations = %+v, want two track-a2 rows", obs)
+	}
+}
+
+func TestExportParquet_FiltersSensorAndRange(t *testing.T) {
+	db := seedParquetExportDB(t)
+	dir := t.TempDir()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	result, err := ExportParquet(db, ParquetExportOptions{
+		Dir:        dir,
+		SensorID:   "sensor-a",
+		StartNanos: day.UnixNano(),
+		EndNanos:   day.Add(24 * time.Hour).UnixNano(),
+	})
+	if err != nil {
+		t.Fatalf("ExportParquet: %v", err)
+	}
+	if result.Tracks != 1 || result.Observations != 2 {
+		t.Fatalf("exported %d tracks, %d observations; want 1, 2", result.Tracks, result.Observations)
+	}
+	for _, f := range result.Files {
+		if !strings.Contains(f, "sensor_id=sensor-a/date=2026-03-02") {
+			t.Errorf("unexpected partition %s", f)
+		}
+	}
+}
+
+func TestExportParquet_Validation(t *testing.T) {
+	db := seedParquetExportDB(t)
+	if _, err := ExportParquet(db, ParquetExportOptions{StartNanos: 0, EndNanos: 1}); err == nil {
+		t.Error("expected error without an output directory")
+	}
+	if _, err := ExportParquet(db, ParquetExportOptions{Dir: t.TempDir(), StartNanos: 5, EndNanos: 5}); err == nil {
+		t.Error("expected error for an empty range")
+	}
+}
+
+// TestExportParquet_OpensInDuckDB checks the dataset reads back as one
+// Hive-partitioned table in DuckDB. It is skipped when the duckdb CLI is
+// not installed.
+func TestExportParquet_OpensInDuckDB(t *testing.T) {
+	duckdb, err := exec.LookPath("duckdb")
+	if err != nil {
+		t.Skip("duckdb CLI not installed")
+	}
+	db := seedParquetExportDB(t)
+	dir := t.TempDir()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := ExportParquet(db, ParquetExportOptions{
+		Dir:        dir,
+		StartNanos: day.UnixNano(),
+		EndNanos:   day.Add(48 * time.Hour).UnixNano(),
+	}); err != nil {
+		t.Fatalf("ExportParquet: %v", err)
+	}
+
+	query := "SELECT count(*), count(DISTINCT sensor_id), count(DISTINCT date) FROM read_parquet('" +
+		filepath.ToSlash(filepath.Join(dir, "observations", "*", "*", "*.parquet")) + "', hive_partitioning = true)"
+	cmd := exec.Command(duckdb, "-csv", "-noheader", "-c", query)
+	cmd.Env = append(os.Environ(), "HOME="+t.TempDir())
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("duckdb: %v\n%s", err, out)
+	}
+	if got := strings.TrimSpace(string(out)); got != "6,2,2" {
+		t.Fatalf("duckdb counts = %q, want 6,2,2", got)
+	}
+}
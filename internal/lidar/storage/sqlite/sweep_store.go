@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -49,8 +50,8 @@ func (s *SweepStore) InsertSweep(record SweepRecord) error {
 			objective_name, objective_version
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	err := retryOnBusy(func() error {
-		_, err := s.db.Exec(query,
+	err := WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query,
 			record.SweepID,
 			record.SensorID,
 			record.Mode,
@@ -82,8 +83,8 @@ func (s *SweepStore) UpdateSweepResults(sweepID, status string, results, recomme
 		s := completedAt.UTC().Format(time.RFC3339)
 		completedAtStr = &s
 	}
-	err := retryOnBusy(func() error {
-		_, err := s.db.Exec(query,
+	err := WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query,
 			status,
 			nullJSON(results),
 			nullJSON(recommendation),
@@ -108,8 +109,8 @@ func (s *SweepStore) UpdateSweepResults(sweepID, status string, results, recomme
 // UpdateSweepCharts saves chart configuration for a sweep.
 func (s *SweepStore) UpdateSweepCharts(sweepID string, charts json.RawMessage) error {
 	query := `UPDATE lidar_tuning_sweeps SET charts = ? WHERE sweep_id = ?`
-	err := retryOnBusy(func() error {
-		_, err := s.db.Exec(query, string(charts), sweepID)
+	err := WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query, string(charts), sweepID)
 		return err
 	})
 	if err != nil {
@@ -262,8 +263,8 @@ func (s *SweepStore) ListSweeps(sensorID string, limit int) ([]SweepSummary, err
 
 // DeleteSweep removes a sweep record.
 func (s *SweepStore) DeleteSweep(sweepID string) error {
-	return retryOnBusy(func() error {
-		_, err := s.db.Exec(`DELETE FROM lidar_tuning_sweeps WHERE sweep_id = ?`, sweepID)
+	return WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(`DELETE FROM lidar_tuning_sweeps WHERE sweep_id = ?`, sweepID)
 		return err
 	})
 }
@@ -273,8 +274,8 @@ func (s *SweepStore) DeleteSweep(sweepID string) error {
 // database records stuck in "running" status with no in-memory counterpart.
 func (s *SweepStore) RecoverOrphanedSweeps() (int64, error) {
 	var affected int64
-	err := retryOnBusy(func() error {
-		res, err := s.db.Exec(`
+	err := WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		res, err := tx.Exec(`
 			UPDATE lidar_tuning_sweeps
 			SET status = 'failed',
 			    error = 'incomplete: server restarted',
@@ -323,8 +324,8 @@ func (s *SweepStore) SaveSweepCheckpoint(sweepID string, round int, bounds, resu
 		    checkpoint_request = ?
 		WHERE sweep_id = ?
 	`
-	err := retryOnBusy(func() error {
-		_, err := s.db.Exec(query, round, nullJSON(bounds), nullJSON(results), nullJSON(request), sweepID)
+	err := WithTx(context.Background(), s.db, func(tx *SQLTx) error {
+		_, err := tx.Exec(query, round, nullJSON(bounds), nullJSON(results), nullJSON(request), sweepID)
 		return err
 	})
 	if err != nil {
@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Track archiving.
+//
+// Archiving is a reversible soft delete: an archived track keeps its row and
+// observations but is left out of GetActiveTracks and GetTracksInRange, so an
+// operator can hide obviously bad tracks without losing them. Unarchiving
+// restores a track; PurgeArchivedTracks hard-deletes those archived long
+// enough ago that nobody has asked for them back.
+
+// ArchiveFilter chooses how track listings treat archived tracks.
+type ArchiveFilter int
+
+const (
+	// ExcludeArchived leaves archived tracks out (the default).
+	ExcludeArchived ArchiveFilter = iota
+	// IncludeArchived lists archived and live tracks together.
+	IncludeArchived
+	// OnlyArchived lists archived tracks only.
+	OnlyArchived
+)
+
+// sql returns the WHERE condition for f, prefixed with AND.
+func (f ArchiveFilter) sql() string {
+	switch f {
+	case IncludeArchived:
+		return ""
+	case OnlyArchived:
+		return " AND archived_unix_nanos IS NOT NULL"
+	default:
+		return " AND archived_unix_nanos IS NULL"
+	}
+}
+
+// ArchivedTrack is an archived track and when it was archived.
+type ArchivedTrack struct {
+	*TrackedObject
+	ArchivedUnixNanos int64
+}
+
+// ArchiveTracks marks the given tracks of a sensor as archived at the given
+// time. Tracks that are already archived keep their original archive time.
+// It returns the number of tracks newly archived.
+func ArchiveTracks(db DBClient, sensorID string, trackIDs []string, at time.Time) (int64, error) {
+	if sensorID == "" {
+		return 0, fmt.Errorf("sensorID is required to archive tracks")
+	}
+	if len(trackIDs) == 0 {
+		return 0, nil
+	}
+	args := append([]interface{}{at.UnixNano(), sensorID}, stringArgs(trackIDs)...)
+	res, err := db.Exec(`
+		UPDATE lidar_tracks SET archived_unix_nanos = ?
+		WHERE sensor_id = ? AND archived_unix_nanos IS NULL
+		  AND track_id IN (`+placeholders(len(trackIDs))+`)`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("archive tracks: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// UnarchiveTracks restores the given archived tracks of a sensor. It
+// returns the number of tracks restored.
+func UnarchiveTracks(db DBClient, sensorID string, trackIDs []string) (int64, error) {
+	if sensorID == "" {
+		return 0, fmt.Errorf("sensorID is required to unarchive tracks")
+	}
+	if len(trackIDs) == 0 {
+		return 0, nil
+	}
+	args := append([]interface{}{sensorID}, stringArgs(trackIDs)...)
+	res, err := db.Exec(`
+		UPDATE lidar_tracks SET archived_unix_nanos = NULL
+		WHERE sensor_id = ? AND archived_unix_nanos IS NOT NULL
+		  AND track_id IN (`+placeholders(len(trackIDs))+`)`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("unarchive tracks: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// GetArchivedTracks lists a sensor's archived tracks, most recently
+// archived first. A limit <= 0 defaults to 100.
+func GetArchivedTracks(db DBClient, sensorID string, limit int) ([]ArchivedTrack, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := db.Query(`
+		SELECT track_id, `+trackMeasurementColumns+`, composite_speed_mps, archived_unix_nanos
+		FROM lidar_tracks_all
+		WHERE sensor_id = ? AND archived_unix_nanos IS NOT NULL
+		ORDER BY archived_unix_nanos DESC, track_id
+		LIMIT ?`, sensorID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query archived tracks: %w", err)
+	}
+	defer rows.Close()
+
+	var tracks []ArchivedTrack
+	for rows.Next() {
+		track := ArchivedTrack{TrackedObject: &TrackedObject{}}
+		measDests, applyMeas := scanTrackMeasurementDests(&track.TrackMeasurement)
+		var compositeSpeed sql.NullFloat64
+		dests := append([]any{&track.TrackID}, measDests...)
+		dests = append(dests, &compositeSpeed, &track.ArchivedUnixNanos)
+		if err := rows.Scan(dests...); err != nil {
+			return nil, fmt.Errorf("scan archived track: %w", err)
+		}
+		applyMeas()
+		track.CompositeSpeedMps = float32(compositeSpeed.Float64)
+		tracks = append(tracks, track)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate archived tracks: %w", err)
+	}
+	return tracks, nil
+}
+
+// PurgeArchivedTracks hard-deletes a sensor's tracks archived before the
+// cutoff, with their observations. Live tracks are never touched.
+// Returns the number of tracks purged.
+func PurgeArchivedTracks(db DBClient, sensorID string, before time.Time) (int64, error) {
+	if sensorID == "" {
+		return 0, fmt.Errorf("sensorID is required to purge archived tracks")
+	}
+	cutoffNanos := before.UnixNano()
+
+	var purged int64
+	err := WithTx(context.Background(), db, func(tx *SQLTx) error {
+		_, err := tx.Exec(`
+			DELETE FROM lidar_track_observations
+			WHERE track_id IN (
+				SELECT track_id FROM lidar_tracks
+				WHERE sensor_id = ? AND archived_unix_nanos < ?
+			)`, sensorID, cutoffNanos)
+		if err != nil {
+			return fmt.Errorf("purge archived observations: %w", err)
+		}
+		res, err := tx.Exec(`
+			DELETE FROM lidar_tracks
+			WHERE sensor_id = ? AND archived_unix_nanos < ?`,
+			sensorID, cutoffNanos)
+		if err != nil {
+			return fmt.Errorf("purge archived tracks: %w", err)
+		}
+		purged, _ = res.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
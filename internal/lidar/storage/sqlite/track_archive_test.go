@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+)
+
+// insertArchiveTestTracks inserts confirmed tracks t1..t3 and a deleted
+// track t4 for sensor-001, each with one observation.
+func insertArchiveTestTracks(t *testing.T, db *sql.DB) {
+	t.Helper()
+	for i, id := range []string{"t1", "t2", "t3", "t4"} {
+		state := TrackConfirmed
+		if id == "t4" {
+			state = TrackDeleted
+		}
+		start := int64(1_000_000_000 * (i + 1))
+		track := &TrackedObject{TrackID: id, TrackMeasurement: TrackMeasurement{
+			SensorID: "sensor-001", TrackState: state,
+			StartUnixNanos: start, EndUnixNanos: start + 500_000_000,
+		}}
+		if err := InsertTrack(db, track, "site/main"); err != nil {
+			t.Fatalf("InsertTrack %s: %v", id, err)
+		}
+		if err := InsertTrackObservation(db, &TrackObservation{TrackID: id, TSUnixNanos: start, FrameID: "site/main"}); err != nil {
+			t.Fatalf("InsertTrackObservation %s: %v", id, err)
+		}
+	}
+}
+
+func trackIDs(tracks []*TrackedObject) map[string]bool {
+	ids := make(map[string]bool, len(tracks))
+	for _, tr := range tracks {
+		ids[tr.TrackID] = true
+	}
+	return ids
+}
+
+func TestArchiveTracks_Filtering(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertArchiveTestTracks(t, db)
+
+	archivedAt := time.Unix(1_700_000_000, 0)
+	n, err := ArchiveTracks(db, "sensor-001", []string{"t2", "t3", "missing"}, archivedAt)
+	if err != nil || n != 2 {
+		t.Fatalf("ArchiveTracks = %d, %v; want 2", n, err)
+	}
+	// Re-archiving keeps the original time.
+	if n, err := ArchiveTracks(db, "sensor-001", []string{"t2"}, archivedAt.Add(time.Hour)); err != nil || n != 0 {
+		t.Errorf("re-archive = %d, %v; want 0", n, err)
+	}
+	// Another sensor's archive call does not touch these tracks.
+	if n, _ := ArchiveTracks(db, "sensor-002", []string{"t1"}, archivedAt); n != 0 {
+		t.Errorf("cross-sensor archive touched %d tracks", n)
+	}
+
+	active, err := GetActiveTracks(db, "sensor-001", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := trackIDs(active); len(ids) != 1 || !ids["t1"] {
+		t.Errorf("active tracks = %v, want only t1", ids)
+	}
+
+	for filter, want := range map[ArchiveFilter][]string{
+		ExcludeArchived: {"t1"},
+		IncludeArchived: {"t1", "t2", "t3"},
+		OnlyArchived:    {"t2", "t3"},
+	} {
+		tracks, err := GetTracksInRangeFiltered(db, "sensor-001", "", 0, math.MaxInt64, 10, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := trackIDs(tracks)
+		if len(ids) != len(want) {
+			t.Errorf("filter %d: tracks %v, want %v", filter, ids, want)
+			continue
+		}
+		for _, id := range want {
+			if !ids[id] {
+				t.Errorf("filter %d: missing %s in %v", filter, id, ids)
+			}
+		}
+	}
+	if tracks, _ := GetTracksInRange(db, "sensor-001", "", 0, math.MaxInt64, 10); len(tracks) != 1 {
+		t.Errorf("GetTracksInRange should exclude archived tracks, got %d", len(tracks))
+	}
+
+	archived, err := GetArchivedTracks(db, "sensor-001", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 2 || archived[0].ArchivedUnixNanos != archivedAt.UnixNano() || archived[0].TrackState != TrackConfirmed {
+		t.Errorf("archived tracks = %+v", archived)
+	}
+
+	n, err = UnarchiveTracks(db, "sensor-001", []string{"t2", "t1"})
+	if err != nil || n != 1 {
+		t.Fatalf("UnarchiveTracks = %d, %v; want 1", n, err)
+	}
+	if active, _ := GetActiveTracks(db, "sensor-001", ""); len(active) != 2 {
+		t.Errorf("active tracks after unarchive = %d, want 2", len(active))
+	}
+}
+
+func TestPurgeArchivedTracks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertArchiveTestTracks(t, db)
+
+	old, recent := time.Unix(1_700_000_000, 0), time.Unix(1_700_086_400, 0)
+	if _, err := ArchiveTracks(db, "sensor-001", []string{"t2"}, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ArchiveTracks(db, "sensor-001", []string{"t3", "t4"}, recent); err != nil {
+		t.Fatal(err)
+	}
+
+	// Archived deleted tracks survive the deleted-track TTL prune.
+	if n, err := PruneDeletedTracks(db, "sensor-001", time.Nanosecond); err != nil || n != 0 {
+		t.Errorf("PruneDeletedTracks = %d, %v; want 0 with t4 archived", n, err)
+	}
+
+	n, err := PurgeArchivedTracks(db, "sensor-001", old.Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeArchivedTracks = %d, %v; want 1", n, err)
+	}
+	tracks, _ := GetTracksInRangeFiltered(db, "sensor-001", "", 0, math.MaxInt64, 10, IncludeArchived)
+	if ids := trackIDs(tracks); ids["t2"] || !ids["t1"] || !ids["t3"] {
+		t.Errorf("tracks after purge = %v, want t2 gone and the rest kept", ids)
+	}
+	if obs, _ := GetTrackObservations(db, "t2", 10); len(obs) != 0 {
+		t.Errorf("purged track kept %d observations", len(obs))
+	}
+	if obs, _ := GetTrackObservations(db, "t3", 10); len(obs) != 1 {
+		t.Errorf("archived track lost its observations: %d", len(obs))
+	}
+
+	// A cutoff after everything purges the rest of the archive but never
+	// the live track.
+	if n, err := PurgeArchivedTracks(db, "sensor-001", recent.Add(time.Hour)); err != nil || n != 2 {
+		t.Errorf("second purge = %d, %v; want 2", n, err)
+	}
+	if active, _ := GetActiveTracks(db, "sensor-001", ""); len(active) != 1 || active[0].TrackID != "t1" {
+		t.Errorf("live tracks after purge = %v", trackIDs(active))
+	}
+
+	if _, err := PurgeArchivedTracks(db, "", recent); err == nil {
+		t.Error("expected an error without a sensor ID")
+	}
+}
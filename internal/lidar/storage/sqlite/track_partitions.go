@@ -0,0 +1,767 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Track partitions.
+//
+// On a long-running deployment lidar_tracks and lidar_track_observations
+// grow without bound. With partitioning, tracks are moved out of the main
+// database a day or a month at a time into their own database file in a
+// partition directory. The file is then made read-only and attached to the
+// main connection, and two temporary union views, lidar_tracks_all and
+// lidar_track_observations_all, read across main and every attached
+// partition with a partition_source column naming where each row came from.
+// They shadow the main-only views of the same names that the track queries
+// read, so those queries see rotated tracks without change. Old partitions
+// are retired by deleting whole files.
+//
+// Rows move in batches of partitionBatchTracks tracks, each batch in its own
+// transaction, so live track writes wait for one batch at a time rather
+// than a whole period.
+//
+// A track belongs to the period its start_unix_nanos falls in (UTC) and its
+// observations move with it, so a track is never split across files. A
+// period is rotated once it has been over for the grace period, leaving
+// time for the last tracks of the period to end.
+//
+// Each partition is checked when attached. One that fails the check, or
+// cannot be attached at all, is left out of the views and reported with
+// its error; the main database and the other partitions are unaffected.
+//
+// ATTACH and TEMP views belong to a connection, so partitioning needs the
+// database's pool to hold a single connection, which is the default (see
+// db.DefaultPoolConfig), and pins it open: OpenTrackPartitions keeps it idle
+// and never recycles it. If the connection is replaced anyway, the next
+// Rotate or DropBefore re-attaches the partitions. SQLite attaches at most ten databases; only the
+// newest MaxAttached partitions are attached and older files stay on disk
+// until retention removes them.
+
+// PartitionPeriod is the span of time one partition holds.
+type PartitionPeriod string
+
+const (
+	PartitionDaily   PartitionPeriod = "daily"
+	PartitionMonthly PartitionPeriod = "monthly"
+)
+
+// Defaults for TrackPartitionConfig.
+const (
+	DefaultPartitionGrace        = time.Hour
+	DefaultMaxAttachedPartitions = 8
+)
+
+// maxAttachedPartitions is SQLite's compile-time SQLITE_MAX_ATTACHED.
+const maxAttachedPartitions = 10
+
+// partitionFilePrefix starts the name of every partition file.
+const partitionFilePrefix = "lidar_tracks_"
+
+// partitionBatchTracks is how many tracks, with their observations, one
+// rotation transaction moves.
+const partitionBatchTracks = 200
+
+// partitionedTables are moved into partitions, parents first.
+var partitionedTables = []string{"lidar_tracks", "lidar_track_observations"}
+
+// ParsePartitionPeriod parses "daily" or "monthly".
+func ParsePartitionPeriod(s string) (PartitionPeriod, error) {
+	switch p := PartitionPeriod(strings.ToLower(strings.TrimSpace(s))); p {
+	case PartitionDaily, PartitionMonthly:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown partition period %q: want daily or monthly", s)
+}
+
+// start returns the start of the period containing t, in UTC.
+func (p PartitionPeriod) start(t time.Time) time.Time {
+	t = t.UTC()
+	if p == PartitionMonthly {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// next returns the start of the period after the one starting at start.
+func (p PartitionPeriod) next(start time.Time) time.Time {
+	if p == PartitionMonthly {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// layout is the time layout of the period's partition names.
+func (p PartitionPeriod) layout() string {
+	if p == PartitionMonthly {
+		return "2006-01"
+	}
+	return "2006-01-02"
+}
+
+// TrackPartitionConfig configures track partitioning.
+type TrackPartitionConfig struct {
+	Dir         string          // Directory holding the partition files; created if missing
+	Period      PartitionPeriod // Span of each partition
+	Grace       time.Duration   // How long after a period ends before it is rotated; zero means DefaultPartitionGrace
+	MaxAttached int             // Newest partitions attached for the union views; zero means DefaultMaxAttachedPartitions, at most 10
+}
+
+// TrackPartition describes one partition file.
+type TrackPartition struct {
+	Name     string    `json:"name"` // period, e.g. 2026-03-01 or 2026-03
+	Path     string    `json:"path"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Attached bool      `json:"attached"`
+	// Error is why an attach was refused, such as a failed integrity check.
+	Error string `json:"error,omitempty"`
+
+	alias string
+}
+
+// TrackPartitions rotates tracks into partition files and keeps them
+// attached to the main database. Its methods are safe for concurrent use.
+type TrackPartitions struct {
+	db  *SQLDB
+	cfg TrackPartitionConfig
+
+	mu         sync.Mutex
+	partitions []TrackPartition // oldest first
+}
+
+// OpenTrackPartitions attaches the partitions already in cfg.Dir to db and
+// creates the union views. It does not rotate; call Rotate for that.
+func OpenTrackPartitions(db *SQLDB, cfg TrackPartitionConfig) (*TrackPartitions, error) {
+	if db == nil {
+		return nil, fmt.Errorf("track partitions require a database")
+	}
+	if db.Stats().MaxOpenConnections != 1 {
+		return nil, fmt.Errorf("track partitions require a single-connection pool, have max %d", db.Stats().MaxOpenConnections)
+	}
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("track partitions require a directory")
+	}
+	if _, err := ParsePartitionPeriod(string(cfg.Period)); err != nil {
+		return nil, err
+	}
+	if cfg.Grace <= 0 {
+		cfg.Grace = DefaultPartitionGrace
+	}
+	if cfg.MaxAttached <= 0 {
+		cfg.MaxAttached = DefaultMaxAttachedPartitions
+	}
+	if cfg.MaxAttached > maxAttachedPartitions {
+		return nil, fmt.Errorf("at most %d partitions can be attached, asked for %d", maxAttachedPartitions, cfg.MaxAttached)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create partition directory: %w", err)
+	}
+	// Closing the connection would silently drop the attachments and views.
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	p := &TrackPartitions{db: db, cfg: cfg}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.scanLocked(); err != nil {
+		return nil, err
+	}
+	if err := p.attachLocked(); err != nil {
+		_ = p.detachAllLocked()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Partitions returns the partitions on disk, oldest first.
+func (p *TrackPartitions) Partitions() []TrackPartition {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]TrackPartition(nil), p.partitions...)
+}
+
+// Close drops the union views and detaches every partition.
+func (p *TrackPartitions) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.detachAllLocked()
+}
+
+// Rotate moves the tracks of every period that ended at least Grace before
+// now out of the main database into that period's partition, and returns
+// the names of the partitions written. Tracks arriving late for a period
+// already rotated are added to its existing partition. A failed period is
+// left in the main database to be retried on the next call.
+func (p *TrackPartitions) Rotate(now time.Time) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.ensureAttachedLocked(); err != nil {
+		return nil, err
+	}
+
+	var first int64
+	var n int
+	if err := p.db.QueryRow(`SELECT COALESCE(MIN(start_unix_nanos), 0), COUNT(*) FROM main.lidar_tracks`).Scan(&first, &n); err != nil {
+		return nil, fmt.Errorf("find oldest track: %w", err)
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	var written []string
+	var errs []error
+	period := p.cfg.Period
+	for start := period.start(time.Unix(0, first)); !period.next(start).Add(p.cfg.Grace).After(now); start = period.next(start) {
+		name, moved, err := p.rotateLocked(start)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rotate %s: %w", name, err))
+			continue
+		}
+		if moved > 0 {
+			opsf("Rotated %d tracks into partition %s", moved, name)
+			written = append(written, name)
+		}
+	}
+	if len(written) > 0 || len(errs) > 0 {
+		if err := p.attachLocked(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return written, errors.Join(errs...)
+}
+
+// DropBefore deletes the partitions whose period ended at or before cutoff
+// and returns their names. Tracks still in the main database are not
+// touched; rotation moves them out first.
+func (p *TrackPartitions) DropBefore(cutoff time.Time) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.ensureAttachedLocked(); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, part := range p.partitions {
+		if part.End.After(cutoff) {
+			continue
+		}
+		names = append(names, part.Name)
+	}
+	return names, p.dropLocked(names)
+}
+
+// Drop deletes the named partition.
+func (p *TrackPartitions) Drop(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, part := range p.partitions {
+		if part.Name == name {
+			return p.dropLocked([]string{name})
+		}
+	}
+	return fmt.Errorf("no partition %q", name)
+}
+
+func (p *TrackPartitions) dropLocked(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if err := p.detachAllLocked(); err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range names {
+		path := p.path(name)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("delete partition %s: %w", name, err))
+			continue
+		}
+		for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+			_ = os.Remove(path + suffix)
+		}
+		opsf("Deleted track partition %s", name)
+	}
+	if err := p.scanLocked(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := p.attachLocked(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (p *TrackPartitions) path(name string) string {
+	return filepath.Join(p.cfg.Dir, partitionFilePrefix+name+".db")
+}
+
+// scanLocked lists the partition files of the configured period.
+func (p *TrackPartitions) scanLocked() error {
+	entries, err := os.ReadDir(p.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("list partitions: %w", err)
+	}
+	layout := p.cfg.Period.layout()
+	p.partitions = p.partitions[:0]
+	for _, e := range entries {
+		name, ok := strings.CutPrefix(e.Name(), partitionFilePrefix)
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, ".db")
+		if !ok {
+			continue
+		}
+		start, err := time.Parse(layout, name)
+		if err != nil || start.Format(layout) != name {
+			continue // another period's partition, or not a partition
+		}
+		p.partitions = append(p.partitions, TrackPartition{
+			Name:  name,
+			Path:  p.path(name),
+			Start: start,
+			End:   p.cfg.Period.next(start),
+			alias: partitionAlias(name),
+		})
+	}
+	sort.Slice(p.partitions, func(i, j int) bool { return p.partitions[i].Start.Before(p.partitions[j].Start) })
+	return nil
+}
+
+// partitionAlias is the schema name a partition is attached under. Names
+// come from parsed dates, so the alias is always a plain identifier.
+func partitionAlias(name string) string {
+	return "tracks_" + strings.ReplaceAll(name, "-", "_")
+}
+
+// ensureAttachedLocked re-attaches the partitions when the connection
+// holding them has been replaced, which shows as a missing TEMP view.
+func (p *TrackPartitions) ensureAttachedLocked() error {
+	var n int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM temp.sqlite_master WHERE type = 'view' AND name = 'lidar_tracks_all'`).Scan(&n); err != nil {
+		return fmt.Errorf("check partition views: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+	opsf("Track partition views missing, the connection was replaced; re-attaching")
+	return p.attachLocked()
+}
+
+// attachLocked detaches everything, attaches the newest MaxAttached
+// partitions read-only, checking each, and rebuilds the union views.
+func (p *TrackPartitions) attachLocked() error {
+	if err := p.detachAllLocked(); err != nil {
+		return err
+	}
+	first := len(p.partitions) - p.cfg.MaxAttached
+	for i := range p.partitions {
+		part := &p.partitions[i]
+		part.Attached, part.Error = false, ""
+		if i < first {
+			continue
+		}
+		if err := p.attachReadOnly(part); err != nil {
+			part.Error = err.Error()
+			opsf("Track partition %s left out: %v", part.Name, err)
+			continue
+		}
+		part.Attached = true
+	}
+	return p.createViewsLocked()
+}
+
+// attachReadOnly attaches part read-only and checks its integrity,
+// detaching it again if the check fails.
+func (p *TrackPartitions) attachReadOnly(part *TrackPartition) error {
+	uri := "file:" + filepath.ToSlash(part.Path) + "?mode=ro"
+	if _, err := p.db.Exec(`ATTACH DATABASE ? AS `+part.alias, uri); err != nil {
+		return fmt.Errorf("attach: %w", err)
+	}
+	var result string
+	err := p.db.QueryRow(`PRAGMA ` + part.alias + `.quick_check(1)`).Scan(&result)
+	if err == nil && result != "ok" {
+		err = fmt.Errorf("%s", result)
+	}
+	if err == nil {
+		for _, table := range partitionedTables {
+			cols, cerr := p.partitionColumns(part.alias, table)
+			if cerr != nil {
+				_, _ = p.db.Exec(`DETACH DATABASE ` + part.alias)
+				return cerr
+			}
+			if _, qerr := p.db.Exec(`SELECT ` + cols + ` FROM ` + part.alias + `.` + table + ` LIMIT 0`); qerr != nil {
+				err = qerr
+				break
+			}
+		}
+	}
+	if err != nil {
+		_, _ = p.db.Exec(`DETACH DATABASE ` + part.alias)
+		return fmt.Errorf("integrity check: %w", err)
+	}
+	return nil
+}
+
+// columns returns the main database's column list for table, which the
+// views and copies name explicitly rather than relying on SELECT *.
+func (p *TrackPartitions) columns(table string) (string, error) {
+	cols, err := p.columnNames("main", table)
+	if err != nil {
+		return "", err
+	}
+	if len(cols) == 0 {
+		return "", fmt.Errorf("no %s table in the main database", table)
+	}
+	quoted := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = `"` + col + `"`
+	}
+	return strings.Join(quoted, ", "), nil
+}
+
+// partitionColumns returns the select list that reads table from the
+// attached partition alias in the main database's column order. Columns
+// added to main by a migration after the partition was written read as
+// NULL, so older partitions stay readable without being rewritten.
+func (p *TrackPartitions) partitionColumns(alias, table string) (string, error) {
+	mainCols, err := p.columnNames("main", table)
+	if err != nil {
+		return "", err
+	}
+	partCols, err := p.columnNames(alias, table)
+	if err != nil {
+		return "", err
+	}
+	if len(partCols) == 0 {
+		return "", fmt.Errorf("no %s table in partition %s", table, alias)
+	}
+	present := make(map[string]bool, len(partCols))
+	for _, col := range partCols {
+		present[col] = true
+	}
+	sel := make([]string, len(mainCols))
+	for i, col := range mainCols {
+		if present[col] {
+			sel[i] = `"` + col + `"`
+		} else {
+			sel[i] = `NULL AS "` + col + `"`
+		}
+	}
+	return strings.Join(sel, ", "), nil
+}
+
+// columnNames lists table's columns in schema, in declaration order.
+func (p *TrackPartitions) columnNames(schema, table string) ([]string, error) {
+	rows, err := p.db.Query(`SELECT name FROM pragma_table_info(?, ?) ORDER BY cid`, table, schema)
+	if err != nil {
+		return nil, fmt.Errorf("read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return cols, rows.Err()
+}
+
+// addMissingColumns brings an existing partition's tables up to the main
+// database's columns before rows are copied into it, for partitions
+// written before a migration added a column.
+func (p *TrackPartitions) addMissingColumns(alias string) error {
+	for _, table := range partitionedTables {
+		partCols, err := p.columnNames(alias, table)
+		if err != nil {
+			return err
+		}
+		present := make(map[string]bool, len(partCols))
+		for _, col := range partCols {
+			present[col] = true
+		}
+		rows, err := p.db.Query(`SELECT name, type, dflt_value FROM pragma_table_info(?, 'main') ORDER BY cid`, table)
+		if err != nil {
+			return fmt.Errorf("read %s columns: %w", table, err)
+		}
+		var adds []string
+		for rows.Next() {
+			var name, typ string
+			var dflt sql.NullString
+			if err := rows.Scan(&name, &typ, &dflt); err != nil {
+				rows.Close()
+				return err
+			}
+			if present[name] {
+				continue
+			}
+			def := `"` + name + `" ` + typ
+			if dflt.Valid {
+				def += ` DEFAULT ` + dflt.String
+			}
+			adds = append(adds, def)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		for _, def := range adds {
+			if _, err := p.db.Exec(`ALTER TABLE ` + alias + `.` + table + ` ADD COLUMN ` + def); err != nil {
+				return fmt.Errorf("add partition column %s.%s: %w", table, def, err)
+			}
+		}
+	}
+	return nil
+}
+
+// detachAllLocked drops the views and detaches every partition database.
+func (p *TrackPartitions) detachAllLocked() error {
+	var errs []error
+	for _, table := range partitionedTables {
+		if _, err := p.db.Exec(`DROP VIEW IF EXISTS temp.` + table + `_all`); err != nil {
+			errs = append(errs, fmt.Errorf("drop view %s_all: %w", table, err))
+		}
+	}
+	rows, err := p.db.Query(`SELECT name FROM pragma_database_list WHERE name LIKE 'tracks\_%' ESCAPE '\'`)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("list attached databases: %w", err))...)
+	}
+	var aliases []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			rows.Close()
+			return errors.Join(append(errs, err)...)
+		}
+		aliases = append(aliases, alias)
+	}
+	rows.Close()
+	for _, alias := range aliases {
+		if _, err := p.db.Exec(`DETACH DATABASE ` + alias); err != nil {
+			errs = append(errs, fmt.Errorf("detach %s: %w", alias, err))
+		}
+	}
+	for i := range p.partitions {
+		p.partitions[i].Attached = false
+	}
+	return errors.Join(errs...)
+}
+
+// createViewsLocked creates the union views over main and the attached
+// partitions.
+func (p *TrackPartitions) createViewsLocked() error {
+	for _, table := range partitionedTables {
+		cols, err := p.columns(table)
+		if err != nil {
+			return err
+		}
+		selects := []string{`SELECT ` + cols + `, 'main' AS partition_source FROM main.` + table}
+		for _, part := range p.partitions {
+			if !part.Attached {
+				continue
+			}
+			partCols, err := p.partitionColumns(part.alias, table)
+			if err != nil {
+				return err
+			}
+			selects = append(selects, `SELECT `+partCols+`, '`+part.Name+`' FROM `+part.alias+`.`+table)
+		}
+		view := `CREATE TEMP VIEW ` + table + `_all AS ` + strings.Join(selects, ` UNION ALL `)
+		if _, err := p.db.Exec(view); err != nil {
+			return fmt.Errorf("create view %s_all: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// rotateLocked moves the tracks starting in the period at start into its
+// partition. Rows are copied and verified before they are deleted from the
+// main database, and the copy ignores rows already present, so a rotation
+// interrupted after the copy is completed by the next one.
+func (p *TrackPartitions) rotateLocked(start time.Time) (string, int, error) {
+	name := start.Format(p.cfg.Period.layout())
+	lo, hi := start.UnixNano(), p.cfg.Period.next(start).UnixNano()
+	var n int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM main.lidar_tracks WHERE start_unix_nanos >= ? AND start_unix_nanos < ?`, lo, hi).Scan(&n); err != nil {
+		return name, 0, err
+	}
+	if n == 0 {
+		return name, 0, nil
+	}
+
+	// Writing needs the partition attached read-write, which ATTACH
+	// cannot do while the read-only attachments are in place.
+	if err := p.detachAllLocked(); err != nil {
+		return name, 0, err
+	}
+	path := p.path(name)
+	_, statErr := os.Stat(path)
+	created := errors.Is(statErr, os.ErrNotExist)
+	if !created {
+		if err := os.Chmod(path, 0o644); err != nil {
+			return name, 0, fmt.Errorf("make partition writable: %w", err)
+		}
+	}
+	const alias = "tracks_rotating"
+	if _, err := p.db.Exec(`ATTACH DATABASE ? AS `+alias, path); err != nil {
+		return name, 0, fmt.Errorf("attach partition: %w", err)
+	}
+	err := p.copyAndDelete(alias, created, lo, hi, n)
+	if _, derr := p.db.Exec(`DETACH DATABASE ` + alias); derr != nil && err == nil {
+		err = fmt.Errorf("detach partition: %w", derr)
+	}
+	if err != nil && created {
+		_ = os.Remove(path)
+	}
+	if cerr := os.Chmod(path, 0o444); cerr != nil && err == nil && !errors.Is(cerr, os.ErrNotExist) {
+		err = fmt.Errorf("make partition read-only: %w", cerr)
+	}
+	if err != nil {
+		return name, 0, err
+	}
+	if created {
+		p.partitions = append(p.partitions, TrackPartition{
+			Name: name, Path: path, Start: start, End: p.cfg.Period.next(start), alias: partitionAlias(name),
+		})
+		sort.Slice(p.partitions, func(i, j int) bool { return p.partitions[i].Start.Before(p.partitions[j].Start) })
+	}
+	return name, n, nil
+}
+
+func (p *TrackPartitions) copyAndDelete(alias string, create bool, lo, hi int64, n int) error {
+	if create {
+		if err := p.createSchema(alias); err != nil {
+			return err
+		}
+	} else if err := p.addMissingColumns(alias); err != nil {
+		return err
+	}
+	trackCols, err := p.columns("lidar_tracks")
+	if err != nil {
+		return err
+	}
+	obsCols, err := p.columns("lidar_track_observations")
+	if err != nil {
+		return err
+	}
+	for moved := 0; moved < n; {
+		batch, err := p.moveBatch(alias, trackCols, obsCols, lo, hi)
+		if err != nil {
+			return err
+		}
+		if batch == 0 {
+			break // tracks deleted from main meanwhile
+		}
+		moved += batch
+	}
+	return nil
+}
+
+// moveBatch copies up to partitionBatchTracks of the period's tracks and
+// their observations into the partition, checks the copy, and deletes
+// them from the main database, all in one transaction. It returns how many
+// tracks it moved.
+func (p *TrackPartitions) moveBatch(alias, trackCols, obsCols string, lo, hi int64) (int, error) {
+	const batch = `SELECT track_id FROM main.lidar_tracks
+		WHERE start_unix_nanos >= ? AND start_unix_nanos < ?
+		ORDER BY track_id LIMIT ?`
+	args := []any{lo, hi, partitionBatchTracks}
+	var moved int
+	err := WithTx(context.Background(), p.db, func(tx *SQLTx) error {
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM (`+batch+`)`, args...).Scan(&moved); err != nil {
+			return fmt.Errorf("select batch: %w", err)
+		}
+		if moved == 0 {
+			return nil
+		}
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO `+alias+`.lidar_tracks (`+trackCols+`)
+			SELECT `+trackCols+` FROM main.lidar_tracks
+			WHERE track_id IN (`+batch+`)`, args...); err != nil {
+			return fmt.Errorf("copy tracks: %w", err)
+		}
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO `+alias+`.lidar_track_observations (`+obsCols+`)
+			SELECT `+obsCols+` FROM main.lidar_track_observations
+			WHERE track_id IN (`+batch+`)`, args...); err != nil {
+			return fmt.Errorf("copy observations: %w", err)
+		}
+
+		var copied, observations, copiedObservations int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM `+alias+`.lidar_tracks WHERE track_id IN (`+batch+`)`, args...).Scan(&copied); err != nil {
+			return fmt.Errorf("verify tracks: %w", err)
+		}
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM main.lidar_track_observations WHERE track_id IN (`+batch+`)`, args...).Scan(&observations); err != nil {
+			return fmt.Errorf("verify observations: %w", err)
+		}
+		if err := tx.QueryRow(`
+			SELECT COUNT(*) FROM main.lidar_track_observations m
+			JOIN `+alias+`.lidar_track_observations a USING (track_id, ts_unix_nanos)
+			WHERE m.track_id IN (`+batch+`)`, args...).Scan(&copiedObservations); err != nil {
+			return fmt.Errorf("verify observations: %w", err)
+		}
+		if copied != moved || copiedObservations != observations {
+			return fmt.Errorf("partition holds %d of %d tracks and %d of %d observations", copied, moved, copiedObservations, observations)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM main.lidar_track_observations WHERE track_id IN (`+batch+`)`, args...); err != nil {
+			return fmt.Errorf("delete rotated observations: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM main.lidar_tracks WHERE track_id IN (`+batch+`)`, args...); err != nil {
+			return fmt.Errorf("delete rotated tracks: %w", err)
+		}
+		return nil
+	})
+	return moved, err
+}
+
+// schemaNamePattern matches the start of a CREATE TABLE or CREATE INDEX
+// statement up to the object name, so it can be qualified with a schema.
+var schemaNamePattern = regexp.MustCompile(`(?is)^\s*CREATE\s+(UNIQUE\s+)?(TABLE|INDEX)\s+(IF\s+NOT\s+EXISTS\s+)?`)
+
+// createSchema copies the main database's definitions of the partitioned
+// tables and their indexes into the attached partition, so the two never
+// drift apart.
+func (p *TrackPartitions) createSchema(alias string) error {
+	rows, err := p.db.Query(`
+		SELECT sql FROM main.sqlite_master
+		WHERE tbl_name IN ('lidar_tracks', 'lidar_track_observations') AND sql IS NOT NULL
+		ORDER BY type = 'index', tbl_name <> 'lidar_tracks'`)
+	if err != nil {
+		return fmt.Errorf("read track schema: %w", err)
+	}
+	var stmts []string
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			rows.Close()
+			return err
+		}
+		loc := schemaNamePattern.FindStringIndex(stmt)
+		if loc == nil {
+			rows.Close()
+			return fmt.Errorf("unexpected track schema statement %q", stmt)
+		}
+		stmts = append(stmts, stmt[:loc[1]]+alias+"."+stmt[loc[1]:])
+	}
+	rows.Close()
+	for _, stmt := range stmts {
+		if _, err := p.db.Exec(stmt); err != nil {
+			return fmt.Errorf("create partition schema: %w", err)
+		}
+	}
+	return nil
+}
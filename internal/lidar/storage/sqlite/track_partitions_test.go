@@ -0,0 +1,410 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// insertPartitionTestTrack inserts a track starting at start with two
+// observations a second apart.
+func insertPartitionTestTrack(t *testing.T, db *sql.DB, id string, start time.Time) {
+	t.Helper()
+	track := &TrackedObject{TrackID: id, TrackMeasurement: TrackMeasurement{
+		SensorID: "sensor-001", TrackState: TrackDeleted,
+		StartUnixNanos: start.UnixNano(), EndUnixNanos: start.Add(time.Second).UnixNano(),
+	}}
+	if err := InsertTrack(db, track, "site/main"); err != nil {
+		t.Fatalf("InsertTrack %s: %v", id, err)
+	}
+	for i := 0; i < 2; i++ {
+		obs := &TrackObservation{TrackID: id, TSUnixNanos: start.Add(time.Duration(i) * time.Second).UnixNano(), FrameID: "site/main", X: float32(i)}
+		if err := InsertTrackObservation(db, obs); err != nil {
+			t.Fatalf("InsertTrackObservation %s: %v", id, err)
+		}
+	}
+}
+
+// partitionSources counts the rows of a view by partition_source.
+func partitionSources(t *testing.T, db *sql.DB, view string) map[string]int {
+	t.Helper()
+	rows, err := db.Query(`SELECT partition_source, COUNT(*) FROM ` + view + ` GROUP BY partition_source`)
+	if err != nil {
+		t.Fatalf("query %s: %v", view, err)
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var source string
+		var n int
+		if err := rows.Scan(&source, &n); err != nil {
+			t.Fatal(err)
+		}
+		counts[source] = n
+	}
+	return counts
+}
+
+func countRows(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+// setupPartitionedTracks inserts two tracks on each of 1, 2 and 3 March
+// 2026 and rotates on the 3rd, leaving the 3rd's tracks in main.
+func setupPartitionedTracks(t *testing.T) (*sql.DB, *TrackPartitions, string) {
+	t.Helper()
+	db, _ := setupTestDB(t)
+	for day := 1; day <= 3; day++ {
+		for i, hour := range []int{9, 17} {
+			id := "d" + string(rune('0'+day)) + "-" + string(rune('a'+i))
+			insertPartitionTestTrack(t, db, id, time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC))
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "partitions")
+	parts, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: dir, Period: PartitionDaily})
+	if err != nil {
+		t.Fatalf("OpenTrackPartitions: %v", err)
+	}
+	t.Cleanup(func() { parts.Close() })
+
+	written, err := parts.Rotate(time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if len(written) != 2 || written[0] != "2026-03-01" || written[1] != "2026-03-02" {
+		t.Fatalf("Rotate wrote %v, want 2026-03-01 and 2026-03-02", written)
+	}
+	return db, parts, dir
+}
+
+func TestTrackPartitions_RotateAndQueryAcrossPartitions(t *testing.T) {
+	db, parts, dir := setupPartitionedTracks(t)
+
+	// Only the 3rd's tracks are left in main.
+	if n := countRows(t, db, `SELECT COUNT(*) FROM lidar_tracks`); n != 2 {
+		t.Errorf("main holds %d tracks, want 2", n)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM lidar_track_observations`); n != 4 {
+		t.Errorf("main holds %d observations, want 4", n)
+	}
+	for _, name := range []string{"2026-03-01", "2026-03-02"} {
+		info, err := os.Stat(filepath.Join(dir, "lidar_tracks_"+name+".db"))
+		if err != nil {
+			t.Fatalf("partition file: %v", err)
+		}
+		if info.Mode().Perm() != 0o444 {
+			t.Errorf("partition %s mode %v, want read-only", name, info.Mode().Perm())
+		}
+	}
+
+	// The views see every track, each once, labelled by partition.
+	want := map[string]int{"main": 2, "2026-03-01": 2, "2026-03-02": 2}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Errorf("lidar_tracks_all by partition = %v, want %v", got, want)
+	}
+	want = map[string]int{"main": 4, "2026-03-01": 4, "2026-03-02": 4}
+	if got := partitionSources(t, db, "lidar_track_observations_all"); !equalCounts(got, want) {
+		t.Errorf("lidar_track_observations_all by partition = %v, want %v", got, want)
+	}
+
+	// A time range spanning the boundary, and a join across partitions.
+	lo := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC).UnixNano()
+	hi := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC).UnixNano()
+	if n := countRows(t, db, `SELECT COUNT(*) FROM lidar_tracks_all WHERE start_unix_nanos BETWEEN ? AND ?`, lo, hi); n != 4 {
+		t.Errorf("tracks in range = %d, want 4", n)
+	}
+	if n := countRows(t, db, `
+		SELECT COUNT(*) FROM lidar_track_observations_all o
+		JOIN lidar_tracks_all t USING (track_id)
+		WHERE t.track_id = 'd1-b' AND o.partition_source = t.partition_source`); n != 2 {
+		t.Errorf("observations of a rotated track = %d, want 2", n)
+	}
+
+	// Partitions are attached read-only.
+	if _, err := db.Exec(`DELETE FROM tracks_2026_03_01.lidar_tracks`); err == nil {
+		t.Error("deleting from a partition succeeded")
+	}
+
+	// A late track for a rotated day joins its existing partition.
+	insertPartitionTestTrack(t, db, "d2-late", time.Date(2026, 3, 2, 23, 59, 0, 0, time.UTC))
+	written, err := parts.Rotate(time.Date(2026, 3, 3, 13, 0, 0, 0, time.UTC))
+	if err != nil || len(written) != 1 || written[0] != "2026-03-02" {
+		t.Fatalf("late Rotate = %v, %v; want 2026-03-02", written, err)
+	}
+	want = map[string]int{"main": 2, "2026-03-01": 2, "2026-03-02": 3}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Errorf("after late rotation = %v, want %v", got, want)
+	}
+
+	// Nothing is due again until the 3rd is over.
+	if written, err := parts.Rotate(time.Date(2026, 3, 4, 0, 30, 0, 0, time.UTC)); err != nil || len(written) != 0 {
+		t.Errorf("Rotate within the grace period = %v, %v; want nothing", written, err)
+	}
+
+	// Reopening finds the partitions again.
+	if err := parts.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	reopened, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: dir, Period: PartitionDaily})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Errorf("after reopening = %v, want %v", got, want)
+	}
+}
+
+func TestTrackPartitions_TrackQueriesSeeRotatedTracks(t *testing.T) {
+	db, _, _ := setupPartitionedTracks(t)
+
+	lo := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	hi := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC).UnixNano()
+	tracks, err := GetTracksInRange(db, "sensor-001", string(TrackDeleted), lo, hi, 100)
+	if err != nil {
+		t.Fatalf("GetTracksInRange: %v", err)
+	}
+	if len(tracks) != 6 {
+		t.Fatalf("GetTracksInRange returned %d tracks, want all 6", len(tracks))
+	}
+	if tracks[0].TrackID != "d1-a" || len(tracks[0].History) != 2 {
+		t.Errorf("oldest track = %s with %d history points, want d1-a with 2", tracks[0].TrackID, len(tracks[0].History))
+	}
+	obs, err := GetTrackObservations(db, "d2-b", 10)
+	if err != nil || len(obs) != 2 {
+		t.Errorf("GetTrackObservations(d2-b) = %d, %v; want 2", len(obs), err)
+	}
+}
+
+func TestTrackPartitions_RotatesInBatches(t *testing.T) {
+	db, _ := setupTestDB(t)
+	const n = 2*partitionBatchTracks + 17
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		insertPartitionTestTrack(t, db, fmt.Sprintf("t%04d", i), day.Add(time.Duration(i)*time.Minute))
+	}
+	parts, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: t.TempDir(), Period: PartitionDaily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parts.Close()
+	if _, err := parts.Rotate(day.Add(48 * time.Hour)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	want := map[string]int{"2026-03-01": n}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Errorf("tracks by partition = %v, want %v", got, want)
+	}
+	want = map[string]int{"2026-03-01": 2 * n}
+	if got := partitionSources(t, db, "lidar_track_observations_all"); !equalCounts(got, want) {
+		t.Errorf("observations by partition = %v, want %v", got, want)
+	}
+}
+
+func TestTrackPartitions_ReattachAfterReconnect(t *testing.T) {
+	db, parts, _ := setupPartitionedTracks(t)
+
+	// Force the pool onto a fresh connection, which has no attachments.
+	db.SetMaxIdleConns(0)
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, map[string]int{"main": 2}) {
+		t.Fatalf("fresh connection sees %v, want main only", got)
+	}
+	db.SetMaxIdleConns(1)
+
+	if _, err := parts.Rotate(time.Date(2026, 3, 3, 13, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	want := map[string]int{"main": 2, "2026-03-01": 2, "2026-03-02": 2}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Errorf("after Rotate = %v, want %v", got, want)
+	}
+}
+
+func TestTrackPartitions_OlderPartitionMissingColumn(t *testing.T) {
+	db, parts, dir := setupPartitionedTracks(t)
+	if err := parts.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Rewind the 1 March partition to before composite_speed_mps was added.
+	path := filepath.Join(dir, "lidar_tracks_2026-03-01.db")
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Exec(`ALTER TABLE lidar_tracks DROP COLUMN composite_speed_mps`); err != nil {
+		t.Fatalf("drop column: %v", err)
+	}
+	old.Close()
+	if err := os.Chmod(path, 0o444); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: dir, Period: PartitionDaily})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	want := map[string]int{"main": 2, "2026-03-01": 2, "2026-03-02": 2}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Fatalf("with an older partition = %v, want %v", got, want)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM lidar_tracks_all WHERE partition_source = '2026-03-01' AND composite_speed_mps IS NULL`); n != 2 {
+		t.Errorf("older partition rows with NULL composite speed = %d, want 2", n)
+	}
+
+	// Rotating a late track into it adds the column first.
+	track := &TrackedObject{TrackID: "d1-late", CompositeSpeedMps: 4.5, TrackMeasurement: TrackMeasurement{
+		SensorID: "sensor-001", TrackState: TrackDeleted,
+		StartUnixNanos: time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC).UnixNano(),
+	}}
+	if err := InsertTrack(db, track, "site/main"); err != nil {
+		t.Fatalf("InsertTrack: %v", err)
+	}
+	if _, err := reopened.Rotate(time.Date(2026, 3, 3, 13, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	var speed float64
+	if err := db.QueryRow(`SELECT composite_speed_mps FROM lidar_tracks_all WHERE track_id = 'd1-late' AND partition_source = '2026-03-01'`).Scan(&speed); err != nil {
+		t.Fatalf("read rotated track: %v", err)
+	}
+	if speed != 4.5 {
+		t.Errorf("rotated composite_speed_mps = %v, want 4.5", speed)
+	}
+}
+
+func TestTrackPartitions_CorruptPartitionIsolated(t *testing.T) {
+	db, parts, dir := setupPartitionedTracks(t)
+	if err := parts.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "lidar_tracks_2026-03-01.db")
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	garbage := make([]byte, 8192)
+	for i := range garbage {
+		garbage[i] = byte(i * 7)
+	}
+	if err := os.WriteFile(path, garbage, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: dir, Period: PartitionDaily})
+	if err != nil {
+		t.Fatalf("a corrupt partition stopped OpenTrackPartitions: %v", err)
+	}
+	defer reopened.Close()
+	list := reopened.Partitions()
+	if len(list) != 2 {
+		t.Fatalf("Partitions = %+v, want 2", list)
+	}
+	if list[0].Attached || list[0].Error == "" {
+		t.Errorf("corrupt partition = %+v, want detached with an error", list[0])
+	}
+	if !list[1].Attached || list[1].Error != "" {
+		t.Errorf("healthy partition = %+v, want attached", list[1])
+	}
+
+	// The main database and the healthy partition still work.
+	insertPartitionTestTrack(t, db, "d3-c", time.Date(2026, 3, 3, 20, 0, 0, 0, time.UTC))
+	want := map[string]int{"main": 3, "2026-03-02": 2}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Errorf("views with a corrupt partition = %v, want %v", got, want)
+	}
+	var check string
+	if err := db.QueryRow(`PRAGMA main.quick_check`).Scan(&check); err != nil || check != "ok" {
+		t.Errorf("main quick_check = %q, %v", check, err)
+	}
+}
+
+func TestTrackPartitions_DropBefore(t *testing.T) {
+	db, parts, dir := setupPartitionedTracks(t)
+
+	dropped, err := parts.DropBefore(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(dropped) != 1 || dropped[0] != "2026-03-01" {
+		t.Fatalf("DropBefore = %v, %v; want 2026-03-01", dropped, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lidar_tracks_2026-03-01.db")); !os.IsNotExist(err) {
+		t.Errorf("dropped partition file still present: %v", err)
+	}
+	want := map[string]int{"main": 2, "2026-03-02": 2}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Errorf("after DropBefore = %v, want %v", got, want)
+	}
+
+	if err := parts.Drop("2026-03-02"); err != nil {
+		t.Fatalf("Drop: %v", err)
+	}
+	if err := parts.Drop("2026-03-02"); err == nil {
+		t.Error("dropping a missing partition succeeded")
+	}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, map[string]int{"main": 2}) {
+		t.Errorf("after Drop = %v, want main only", got)
+	}
+}
+
+func TestTrackPartitions_Monthly(t *testing.T) {
+	db, _ := setupTestDB(t)
+	insertPartitionTestTrack(t, db, "feb", time.Date(2026, 2, 27, 8, 0, 0, 0, time.UTC))
+	insertPartitionTestTrack(t, db, "mar", time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC))
+
+	parts, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: t.TempDir(), Period: PartitionMonthly})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parts.Close()
+	written, err := parts.Rotate(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(written) != 1 || written[0] != "2026-02" {
+		t.Fatalf("Rotate = %v, %v; want 2026-02", written, err)
+	}
+	want := map[string]int{"main": 1, "2026-02": 1}
+	if got := partitionSources(t, db, "lidar_tracks_all"); !equalCounts(got, want) {
+		t.Errorf("monthly partitions = %v, want %v", got, want)
+	}
+}
+
+func TestOpenTrackPartitions_Validation(t *testing.T) {
+	db, _ := setupTestDB(t)
+	if _, err := OpenTrackPartitions(db, TrackPartitionConfig{Period: PartitionDaily}); err == nil {
+		t.Error("accepted an empty directory")
+	}
+	if _, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: t.TempDir(), Period: "weekly"}); err == nil {
+		t.Error("accepted a weekly period")
+	}
+	if _, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: t.TempDir(), Period: PartitionDaily, MaxAttached: 11}); err == nil {
+		t.Error("accepted more attachments than SQLite allows")
+	}
+	db.SetMaxOpenConns(2)
+	if _, err := OpenTrackPartitions(db, TrackPartitionConfig{Dir: t.TempDir(), Period: PartitionDaily}); err == nil {
+		t.Error("accepted a multi-connection pool")
+	}
+	if p, err := ParsePartitionPeriod(" Monthly "); err != nil || p != PartitionMonthly {
+		t.Errorf("ParsePartitionPeriod = %q, %v", p, err)
+	}
+}
+
+func equalCounts(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
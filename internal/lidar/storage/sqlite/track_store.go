@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math"
@@ -21,6 +22,10 @@ type TrackStore interface {
 	GetTrackObservations(trackID string, limit int) ([]*TrackObservation, error)
 	GetTrackObservationsInRange(sensorID string, startNanos, endNanos int64, limit int, trackID string) ([]*TrackObservation, error)
 	GetRecentClusters(sensorID string, startNanos, endNanos int64, limit int) ([]*WorldCluster, error)
+	ArchiveTracks(sensorID string, trackIDs []string, at time.Time) (int64, error)
+	UnarchiveTracks(sensorID string, trackIDs []string) (int64, error)
+	GetArchivedTracks(sensorID string, limit int) ([]ArchivedTrack, error)
+	PurgeArchivedTracks(sensorID string, before time.Time) (int64, error)
 }
 
 // TrackObservation represents a single observation of a track at a point in time.
@@ -58,8 +63,8 @@ func InsertCluster(db DBClient, cluster *WorldCluster) (int64, error) {
 			sensor_id, frame_id, ts_unix_nanos,
 			centroid_x, centroid_y, centroid_z,
 			bounding_box_length, bounding_box_width, bounding_box_height,
-			points_count, height_p95, intensity_mean
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			points_count, height_p95, intensity_mean, points_count_normalized
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.Exec(query,
@@ -75,6 +80,7 @@ func InsertCluster(db DBClient, cluster *WorldCluster) (int64, error) {
 		cluster.PointsCount,
 		cluster.HeightP95,
 		cluster.IntensityMean,
+		nullPositiveFloat32(cluster.PointsCountNormalized),
 	)
 	if err != nil {
 		return 0, fmt.Errorf("insert cluster: %w", err)
@@ -94,14 +100,17 @@ func InsertTrack(exec Executor, track *TrackedObject, frameID string) error {
 	// (INSERT OR REPLACE would delete the row first, triggering cascade delete on lidar_track_observations)
 	query := `
 		INSERT INTO lidar_tracks (
-			track_id, frame_id, ` + trackMeasurementColumns + `
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			track_id, frame_id, ` + trackMeasurementColumns + `,
+			composite_speed_mps
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(track_id) DO UPDATE SET
-			frame_id = excluded.frame_id,` + trackMeasurementUpsertSet + `
+			frame_id = excluded.frame_id,` + trackMeasurementUpsertSet + `,
+			composite_speed_mps = excluded.composite_speed_mps
 	`
 
 	args := []any{track.TrackID, frameID}
 	args = append(args, trackMeasurementInsertArgs(&track.TrackMeasurement)...)
+	args = append(args, track.CompositeSpeedMps)
 
 	_, err := exec.Exec(query, args...)
 	if err != nil {
@@ -114,12 +123,13 @@ func InsertTrack(exec Executor, track *TrackedObject, frameID string) error {
 // UpdateTrack updates an existing track in the database.
 func UpdateTrack(db DBClient, track *TrackedObject) error {
 	query := `
-		UPDATE lidar_tracks SET` + trackMeasurementUpdateSet + `
+		UPDATE lidar_tracks SET` + trackMeasurementUpdateSet + `,
+			composite_speed_mps = ?
 		WHERE track_id = ?
 	`
 
 	args := trackMeasurementUpdateArgs(&track.TrackMeasurement)
-	args = append(args, track.TrackID)
+	args = append(args, track.CompositeSpeedMps, track.TrackID)
 
 	_, err := db.Exec(query, args...)
 	if err != nil {
@@ -160,7 +170,8 @@ func InsertTrackObservation(exec Executor, obs *TrackObservation) error {
 // PruneDeletedTracks removes tracks in the 'deleted' state (and their
 // observations) whose last update is older than the supplied TTL. This
 // prevents the database from growing unboundedly as the tracker
-// continuously creates and deletes short-lived spurious tracks.
+// continuously creates and deletes short-lived spurious tracks. Archived
+// tracks are left for PurgeArchivedTracks.
 // Returns the number of tracks pruned and any error encountered.
 func PruneDeletedTracks(db DBClient, sensorID string, ttl time.Duration) (int64, error) {
 	if sensorID == "" {
@@ -169,39 +180,36 @@ func PruneDeletedTracks(db DBClient, sensorID string, ttl time.Duration) (int64,
 
 	cutoffNanos := time.Now().Add(-ttl).UnixNano()
 
-	tx, err := db.Begin()
-	if err != nil {
-		return 0, fmt.Errorf("begin prune tx: %w", err)
-	}
+	var pruned int64
+	err := WithTx(context.Background(), db, func(tx *SQLTx) error {
+		// Delete orphaned observations first (foreign-key safe).
+		_, err := tx.Exec(`
+			DELETE FROM lidar_track_observations
+			WHERE track_id IN (
+				SELECT track_id FROM lidar_tracks
+				WHERE sensor_id = ? AND track_state = 'deleted'
+				  AND archived_unix_nanos IS NULL
+				  AND COALESCE(end_unix_nanos, start_unix_nanos) < ?
+			)`, sensorID, cutoffNanos)
+		if err != nil {
+			return fmt.Errorf("prune observations: %w", err)
+		}
 
-	// Delete orphaned observations first (foreign-key safe).
-	_, err = tx.Exec(`
-		DELETE FROM lidar_track_observations
-		WHERE track_id IN (
-			SELECT track_id FROM lidar_tracks
+		// Delete the track rows themselves.
+		res, err := tx.Exec(`
+			DELETE FROM lidar_tracks
 			WHERE sensor_id = ? AND track_state = 'deleted'
-			  AND COALESCE(end_unix_nanos, start_unix_nanos) < ?
-		)`, sensorID, cutoffNanos)
-	if err != nil {
-		tx.Rollback()
-		return 0, fmt.Errorf("prune observations: %w", err)
-	}
-
-	// Delete the track rows themselves.
-	res, err := tx.Exec(`
-		DELETE FROM lidar_tracks
-		WHERE sensor_id = ? AND track_state = 'deleted'
-		  AND COALESCE(end_unix_nanos, start_unix_nanos) < ?`,
-		sensorID, cutoffNanos)
+			  AND archived_unix_nanos IS NULL
+			  AND COALESCE(end_unix_nanos, start_unix_nanos) < ?`,
+			sensorID, cutoffNanos)
+		if err != nil {
+			return fmt.Errorf("prune tracks: %w", err)
+		}
+		pruned, _ = res.RowsAffected()
+		return nil
+	})
 	if err != nil {
-		tx.Rollback()
-		return 0, fmt.Errorf("prune tracks: %w", err)
-	}
-
-	pruned, _ := res.RowsAffected()
-
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("commit prune tx: %w", err)
+		return 0, err
 	}
 
 	return pruned, nil
@@ -214,11 +222,6 @@ func ClearTracks(db DBClient, sensorID string) error {
 		return fmt.Errorf("sensorID is required to clear tracks")
 	}
 
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin clear tracks tx: %w", err)
-	}
-
 	steps := []struct {
 		query string
 	}{
@@ -227,18 +230,14 @@ func ClearTracks(db DBClient, sensorID string) error {
 		{query: `DELETE FROM lidar_clusters WHERE sensor_id = ?`},
 	}
 
-	for _, step := range steps {
-		if _, err := tx.Exec(step.query, sensorID); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("clear tracks step failed: %w", err)
+	return WithTx(context.Background(), db, func(tx *SQLTx) error {
+		for _, step := range steps {
+			if _, err := tx.Exec(step.query, sensorID); err != nil {
+				return fmt.Errorf("clear tracks step failed: %w", err)
+			}
 		}
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit clear tracks tx: %w", err)
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // ClearRuns removes all analysis runs and their associated run tracks for a sensor.
@@ -249,23 +248,14 @@ func ClearRuns(db DBClient, sensorID string) error {
 		return fmt.Errorf("sensorID is required to clear runs")
 	}
 
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin clear runs tx: %w", err)
-	}
-
 	// Delete runs for this sensor (CASCADE will delete lidar_run_tracks)
 	query := `DELETE FROM lidar_run_records WHERE sensor_id = ?`
-	if _, err := tx.Exec(query, sensorID); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("clear runs failed: %w", err)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit clear runs tx: %w", err)
-	}
-
-	return nil
+	return WithTx(context.Background(), db, func(tx *SQLTx) error {
+		if _, err := tx.Exec(query, sensorID); err != nil {
+			return fmt.Errorf("clear runs failed: %w", err)
+		}
+		return nil
+	})
 }
 
 // DeleteRun removes a specific analysis run and its associated run tracks.
@@ -275,35 +265,24 @@ func DeleteRun(db DBClient, runID string) error {
 		return fmt.Errorf("runID is required to delete run")
 	}
 
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin delete run tx: %w", err)
-	}
-
 	// Delete the run (CASCADE will delete lidar_run_tracks)
 	query := `DELETE FROM lidar_run_records WHERE run_id = ?`
-	result, err := tx.Exec(query, runID)
-	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("delete run failed: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		tx.Rollback()
-		return fmt.Errorf("run not found: %s", runID)
-	}
+	return WithTx(context.Background(), db, func(tx *SQLTx) error {
+		result, err := tx.Exec(query, runID)
+		if err != nil {
+			return fmt.Errorf("delete run failed: %w", err)
+		}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit delete run tx: %w", err)
-	}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("get rows affected: %w", err)
+		}
 
-	return nil
+		if rowsAffected == 0 {
+			return fmt.Errorf("run not found: %s", runID)
+		}
+		return nil
+	})
 }
 
 // GetTrackObservationsInRange returns observations for a sensor within a time window (inclusive).
@@ -319,8 +298,8 @@ func GetTrackObservationsInRange(db DBClient, sensorID string, startNanos, endNa
 			o.velocity_x, o.velocity_y, o.speed_mps, o.heading_rad,
 			o.bounding_box_length, o.bounding_box_width, o.bounding_box_height,
 			o.height_p95, o.intensity_mean
-		FROM lidar_track_observations o
-		JOIN lidar_tracks t ON o.track_id = t.track_id
+		FROM lidar_track_observations_all o
+		JOIN lidar_tracks_all t ON o.track_id = t.track_id AND o.partition_source = t.partition_source
 		WHERE t.sensor_id = ? AND o.ts_unix_nanos BETWEEN ? AND ?
 	`
 	args := []interface{}{sensorID, startNanos, endNanos}
@@ -360,24 +339,25 @@ func GetTrackObservationsInRange(db DBClient, sensorID string, startNanos, endNa
 }
 
 // GetActiveTracks retrieves active tracks from the database.
-// If state is empty, returns all non-deleted tracks.
+// If state is empty, returns all non-deleted tracks. Archived tracks are
+// always excluded.
 func GetActiveTracks(db DBClient, sensorID string, state string) ([]*TrackedObject, error) {
 	var query string
 	var args []interface{}
 
 	selectClause := `
-			SELECT track_id, ` + trackMeasurementColumns + `
-			FROM lidar_tracks`
+			SELECT track_id, ` + trackMeasurementColumns + `, composite_speed_mps
+			FROM lidar_tracks_all`
 
 	if state != "" {
 		query = selectClause + `
-			WHERE sensor_id = ? AND track_state = ?
+			WHERE sensor_id = ? AND track_state = ? AND archived_unix_nanos IS NULL
 			ORDER BY start_unix_nanos DESC
 		`
 		args = []interface{}{sensorID, state}
 	} else {
 		query = selectClause + `
-			WHERE sensor_id = ? AND track_state != 'deleted'
+			WHERE sensor_id = ? AND track_state != 'deleted' AND archived_unix_nanos IS NULL
 			ORDER BY start_unix_nanos DESC
 		`
 		args = []interface{}{sensorID}
@@ -394,12 +374,15 @@ func GetActiveTracks(db DBClient, sensorID string, state string) ([]*TrackedObje
 		track := &TrackedObject{}
 		measDests, applyMeas := scanTrackMeasurementDests(&track.TrackMeasurement)
 
+		var compositeSpeed sql.NullFloat64
 		dests := append([]any{&track.TrackID}, measDests...)
+		dests = append(dests, &compositeSpeed)
 		err := rows.Scan(dests...)
 		if err != nil {
 			return nil, fmt.Errorf("scan track: %w", err)
 		}
 		applyMeas()
+		track.CompositeSpeedMps = float32(compositeSpeed.Float64)
 
 		tracks = append(tracks, track)
 	}
@@ -441,7 +424,14 @@ func GetActiveTracks(db DBClient, sensorID string, state string) ([]*TrackedObje
 // GetTracksInRange retrieves tracks whose lifespan overlaps the given time window (nanoseconds).
 // A track is included if its start is on/before endNanos and its end (or start when end is NULL) is on/after startNanos.
 // Deleted tracks are excluded by default unless state explicitly requests them.
+// Archived tracks are excluded; use GetTracksInRangeFiltered to include them.
 func GetTracksInRange(db DBClient, sensorID string, state string, startNanos, endNanos int64, limit int) ([]*TrackedObject, error) {
+	return GetTracksInRangeFiltered(db, sensorID, state, startNanos, endNanos, limit, ExcludeArchived)
+}
+
+// GetTracksInRangeFiltered is GetTracksInRange with archived tracks
+// treated as archived chooses.
+func GetTracksInRangeFiltered(db DBClient, sensorID string, state string, startNanos, endNanos int64, limit int, archived ArchiveFilter) ([]*TrackedObject, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -450,8 +440,8 @@ func GetTracksInRange(db DBClient, sensorID string, state string, startNanos, en
 	var args []interface{}
 
 	query.WriteString(`
-		SELECT track_id, ` + trackMeasurementColumns + `
-		FROM lidar_tracks
+		SELECT track_id, ` + trackMeasurementColumns + `, composite_speed_mps
+		FROM lidar_tracks_all
 		WHERE sensor_id = ?
 	`)
 	args = append(args, sensorID)
@@ -462,6 +452,7 @@ func GetTracksInRange(db DBClient, sensorID string, state string, startNanos, en
 	} else {
 		query.WriteString(" AND track_state != 'deleted'")
 	}
+	query.WriteString(archived.sql())
 
 	query.WriteString(`
 		AND start_unix_nanos <= ?
@@ -482,12 +473,15 @@ func GetTracksInRange(db DBClient, sensorID string, state string, startNanos, en
 		track := &TrackedObject{}
 		measDests, applyMeas := scanTrackMeasurementDests(&track.TrackMeasurement)
 
+		var compositeSpeed sql.NullFloat64
 		dests := append([]any{&track.TrackID}, measDests...)
+		dests = append(dests, &compositeSpeed)
 		err := rows.Scan(dests...)
 		if err != nil {
 			return nil, fmt.Errorf("scan track: %w", err)
 		}
 		applyMeas()
+		track.CompositeSpeedMps = float32(compositeSpeed.Float64)
 
 		tracks = append(tracks, track)
 	}
@@ -527,7 +521,7 @@ func GetTrackObservations(db DBClient, trackID string, limit int) ([]*TrackObser
 			velocity_x, velocity_y, speed_mps, heading_rad,
 			bounding_box_length, bounding_box_width, bounding_box_height,
 			height_p95, intensity_mean
-		FROM lidar_track_observations
+		FROM lidar_track_observations_all
 		WHERE track_id = ?
 		ORDER BY ts_unix_nanos DESC
 		LIMIT ?
@@ -570,7 +564,7 @@ func GetRecentClusters(db DBClient, sensorID string, startNanos, endNanos int64,
 		SELECT lidar_cluster_id, sensor_id, frame_id, ts_unix_nanos,
 			centroid_x, centroid_y, centroid_z,
 			bounding_box_length, bounding_box_width, bounding_box_height,
-			points_count, height_p95, intensity_mean
+			points_count, height_p95, intensity_mean, points_count_normalized
 		FROM lidar_clusters
 		WHERE sensor_id = ? AND ts_unix_nanos >= ? AND ts_unix_nanos <= ?
 		ORDER BY ts_unix_nanos DESC
@@ -586,6 +580,7 @@ func GetRecentClusters(db DBClient, sensorID string, startNanos, endNanos int64,
 	var clusters []*WorldCluster
 	for rows.Next() {
 		c := &WorldCluster{}
+		var pointsNormalized sql.NullFloat64
 		err := rows.Scan(
 			&c.ClusterID,
 			&c.SensorID,
@@ -593,11 +588,12 @@ func GetRecentClusters(db DBClient, sensorID string, startNanos, endNanos int64,
 			&c.TSUnixNanos,
 			&c.CentroidX, &c.CentroidY, &c.CentroidZ,
 			&c.BoundingBoxLength, &c.BoundingBoxWidth, &c.BoundingBoxHeight,
-			&c.PointsCount, &c.HeightP95, &c.IntensityMean,
+			&c.PointsCount, &c.HeightP95, &c.IntensityMean, &pointsNormalized,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan cluster: %w", err)
 		}
+		c.PointsCountNormalized = float32(pointsNormalized.Float64)
 		clusters = append(clusters, c)
 	}
 
@@ -623,3 +619,12 @@ func nullFloat32(f float32) interface{} {
 	}
 	return f
 }
+
+// nullPositiveFloat32 stores NULL for values that were never computed,
+// which the producers leave at zero or below.
+func nullPositiveFloat32(f float32) interface{} {
+	if !(f > 0) {
+		return nil
+	}
+	return f
+}
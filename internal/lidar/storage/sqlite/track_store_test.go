@@ -33,6 +33,8 @@ func TestInsertCluster(t *testing.T) {
 		PointsCount:       100,
 		HeightP95:         1.4,
 		IntensityMean:     128.5,
+
+		PointsCountNormalized: 412.5,
 	}
 
 	id, err := InsertCluster(db, cluster)
@@ -61,6 +63,34 @@ func TestInsertCluster(t *testing.T) {
 	if c.CentroidX != 10.5 {
 		t.Errorf("Expected centroid_x 10.5, got %f", c.CentroidX)
 	}
+	if c.PointsCountNormalized != 412.5 {
+		t.Errorf("Expected points_count_normalized 412.5, got %f", c.PointsCountNormalized)
+	}
+}
+
+func TestInsertCluster_UnsetNormalizedCountIsNull(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// No density model: PointsCountNormalized stays at zero.
+	cluster := &WorldCluster{
+		SensorID:    "sensor-001",
+		FrameID:     "site/main",
+		TSUnixNanos: 1234567890000000000,
+		PointsCount: 100,
+	}
+	id, err := InsertCluster(db, cluster)
+	if err != nil {
+		t.Fatalf("InsertCluster failed: %v", err)
+	}
+
+	var normalized sql.NullFloat64
+	if err := db.QueryRow(`SELECT points_count_normalized FROM lidar_clusters WHERE lidar_cluster_id = ?`, id).Scan(&normalized); err != nil {
+		t.Fatalf("query points_count_normalized: %v", err)
+	}
+	if normalized.Valid {
+		t.Errorf("Expected NULL points_count_normalized, got %f", normalized.Float64)
+	}
 }
 
 func TestInsertAndGetTrack(t *testing.T) {
@@ -139,6 +169,7 @@ func TestUpdateTrack(t *testing.T) {
 	track.EndUnixNanos = 1234567895000000000 // +5 seconds: exercises trackMeasurementUpdateArgs endNanos branch
 	track.ObjectClass = "pedestrian"
 	track.ObjectConfidence = 0.75
+	track.CompositeSpeedMps = 7.5
 	track.SetSpeedHistory([]float32{6, 7, 8, 9, 8, 7, 8, 9, 8, 7})
 
 	err = UpdateTrack(db, track)
@@ -166,6 +197,9 @@ func TestUpdateTrack(t *testing.T) {
 	if updated.ObjectClass != "pedestrian" {
 		t.Errorf("Expected object_class 'pedestrian', got '%s'", updated.ObjectClass)
 	}
+	if updated.CompositeSpeedMps != 7.5 {
+		t.Errorf("Expected composite_speed_mps 7.5, got %v", updated.CompositeSpeedMps)
+	}
 }
 
 func TestInsertAndGetTrackObservations(t *testing.T) {
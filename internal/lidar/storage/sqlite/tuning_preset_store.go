@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TuningPreset is a named tuning configuration saved from the monitor
+// dashboard. Params holds a partial or full tuning config in the same shape
+// accepted by POST /api/lidar/params.
+type TuningPreset struct {
+	Name        string          `json:"name"`
+	Params      json.RawMessage `json:"params"`
+	CreatedAtNs int64           `json:"created_at_ns"`
+	UpdatedAtNs int64           `json:"updated_at_ns"`
+}
+
+// TuningPresetStore provides persistence for named tuning presets.
+type TuningPresetStore struct {
+	db DBClient
+}
+
+// NewTuningPresetStore creates a new TuningPresetStore.
+func NewTuningPresetStore(db DBClient) *TuningPresetStore {
+	return &TuningPresetStore{db: db}
+}
+
+// Save creates or replaces the preset with preset.Name. Replacing an existing
+// preset keeps its original creation time. CreatedAtNs and UpdatedAtNs are
+// populated from the stored row on success.
+func (s *TuningPresetStore) Save(preset *TuningPreset) error {
+	preset.Name = strings.TrimSpace(preset.Name)
+	if preset.Name == "" {
+		return fmt.Errorf("tuning preset name is required")
+	}
+	if len(preset.Params) == 0 || !json.Valid(preset.Params) {
+		return fmt.Errorf("tuning preset %q: params must be valid JSON", preset.Name)
+	}
+
+	now := time.Now().UnixNano()
+	query := `
+		INSERT INTO lidar_tuning_presets (name, params_json, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			params_json = excluded.params_json,
+			updated_at = excluded.updated_at
+	`
+	if _, err := s.db.Exec(query, preset.Name, string(preset.Params), now, now); err != nil {
+		return fmt.Errorf("save tuning preset: %w", err)
+	}
+
+	stored, err := s.Get(preset.Name)
+	if err != nil {
+		return err
+	}
+	preset.CreatedAtNs = stored.CreatedAtNs
+	preset.UpdatedAtNs = stored.UpdatedAtNs
+	return nil
+}
+
+// Get retrieves a preset by name. The error wraps ErrNotFound when no preset
+// has that name.
+func (s *TuningPresetStore) Get(name string) (*TuningPreset, error) {
+	query := `
+		SELECT name, params_json, created_at, updated_at
+		FROM lidar_tuning_presets
+		WHERE name = ?
+	`
+	var (
+		preset TuningPreset
+		params string
+	)
+	err := s.db.QueryRow(query, name).Scan(&preset.Name, &params, &preset.CreatedAtNs, &preset.UpdatedAtNs)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("tuning preset %q: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tuning preset: %w", err)
+	}
+	preset.Params = json.RawMessage(params)
+	return &preset, nil
+}
+
+// List retrieves all presets ordered by name.
+func (s *TuningPresetStore) List() ([]*TuningPreset, error) {
+	query := `
+		SELECT name, params_json, created_at, updated_at
+		FROM lidar_tuning_presets
+		ORDER BY name
+	`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("list tuning presets: %w", err)
+	}
+	defer rows.Close()
+
+	var presets []*TuningPreset
+	for rows.Next() {
+		var (
+			preset TuningPreset
+			params string
+		)
+		if err := rows.Scan(&preset.Name, &params, &preset.CreatedAtNs, &preset.UpdatedAtNs); err != nil {
+			return nil, fmt.Errorf("scan tuning preset: %w", err)
+		}
+		preset.Params = json.RawMessage(params)
+		presets = append(presets, &preset)
+	}
+	return presets, rows.Err()
+}
+
+// Delete removes a preset by name. The error wraps ErrNotFound when no preset
+// has that name.
+func (s *TuningPresetStore) Delete(name string) error {
+	result, err := s.db.Exec(`DELETE FROM lidar_tuning_presets WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("delete tuning preset: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check delete result: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("tuning preset %q: %w", name, ErrNotFound)
+	}
+	return nil
+}
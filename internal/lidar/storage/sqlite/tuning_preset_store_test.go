@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestTuningPresetStore_CRUD(t *testing.T) {
+	sqlDB, cleanup := setupTrackingPipelineTestDB(t)
+	defer cleanup()
+
+	store := NewTuningPresetStore(sqlDB)
+
+	presets, err := store.List()
+	if err != nil {
+		t.Fatalf("List on empty table: %v", err)
+	}
+	if len(presets) != 0 {
+		t.Fatalf("expected no presets, got %d", len(presets))
+	}
+
+	night := &TuningPreset{Name: "  night  ", Params: json.RawMessage(`{"noise_relative":0.05}`)}
+	if err := store.Save(night); err != nil {
+		t.Fatalf("Save night: %v", err)
+	}
+	if night.Name != "night" {
+		t.Errorf("expected name to be trimmed, got %q", night.Name)
+	}
+	if night.CreatedAtNs == 0 || night.UpdatedAtNs != night.CreatedAtNs {
+		t.Errorf("expected created == updated on insert, got %d / %d", night.CreatedAtNs, night.UpdatedAtNs)
+	}
+	if err := store.Save(&TuningPreset{Name: "day", Params: json.RawMessage(`{"noise_relative":0.02}`)}); err != nil {
+		t.Fatalf("Save day: %v", err)
+	}
+
+	presets, err = store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(presets) != 2 || presets[0].Name != "day" || presets[1].Name != "night" {
+		t.Fatalf("expected [day night], got %+v", presets)
+	}
+
+	// Overwriting keeps the creation time and replaces the params.
+	updated := &TuningPreset{Name: "night", Params: json.RawMessage(`{"noise_relative":0.08}`)}
+	if err := store.Save(updated); err != nil {
+		t.Fatalf("Save overwrite: %v", err)
+	}
+	got, err := store.Get("night")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.CreatedAtNs != night.CreatedAtNs {
+		t.Errorf("created_at changed on overwrite: %d -> %d", night.CreatedAtNs, got.CreatedAtNs)
+	}
+	if got.UpdatedAtNs < got.CreatedAtNs {
+		t.Errorf("updated_at %d before created_at %d", got.UpdatedAtNs, got.CreatedAtNs)
+	}
+	if string(got.Params) != `{"noise_relative":0.08}` {
+		t.Errorf("unexpected params after overwrite: %s", got.Params)
+	}
+
+	if err := store.Delete("night"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get("night"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.Delete("night"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting missing preset, got %v", err)
+	}
+}
+
+func TestTuningPresetStore_SaveValidation(t *testing.T) {
+	sqlDB, cleanup := setupTrackingPipelineTestDB(t)
+	defer cleanup()
+
+	store := NewTuningPresetStore(sqlDB)
+
+	cases := []*TuningPreset{
+		{Name: "", Params: json.RawMessage(`{}`)},
+		{Name: "   ", Params: json.RawMessage(`{}`)},
+		{Name: "empty"},
+		{Name: "broken", Params: json.RawMessage(`{"noise_relative":`)},
+	}
+	for _, preset := range cases {
+		if err := store.Save(preset); err == nil {
+			t.Errorf("expected error saving %+v", preset)
+		}
+	}
+}
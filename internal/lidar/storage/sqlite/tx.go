@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Retry policy for WithTx.
+const (
+	// DefaultTxRetryWindow bounds how long WithTx keeps retrying a busy
+	// transaction when ctx carries no earlier deadline.
+	DefaultTxRetryWindow = 5 * time.Second
+
+	txRetryBaseDelay = 10 * time.Millisecond
+	txRetryMaxDelay  = 500 * time.Millisecond
+)
+
+// txBeginner is implemented by *sql.DB and *db.DB; WithTx uses it so the
+// transaction honours ctx.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// WithTx runs fn inside a transaction on db, committing if fn returns nil
+// and rolling back otherwise. When begin, fn or commit fails with SQLite
+// busy or locked, the whole transaction is rolled back and fn is run again
+// with exponential backoff until ctx is done or DefaultTxRetryWindow has
+// elapsed, so fn must be safe to repeat and must not have side effects
+// outside tx. Other errors are returned immediately.
+func WithTx(ctx context.Context, db DBClient, fn func(tx *SQLTx) error) error {
+	deadline := time.Now().Add(DefaultTxRetryWindow)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	delay := txRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, db, fn)
+		if err == nil || !isSQLiteBusy(err) {
+			return err
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("transaction still busy after %d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+		delay = min(delay*2, txRetryMaxDelay)
+	}
+}
+
+// runTx makes one attempt at the transaction.
+func runTx(ctx context.Context, db DBClient, fn func(tx *SQLTx) error) error {
+	var (
+		tx  *sql.Tx
+		err error
+	)
+	if b, ok := db.(txBeginner); ok {
+		tx, err = b.BeginTx(ctx, nil)
+	} else {
+		tx, err = db.Begin()
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
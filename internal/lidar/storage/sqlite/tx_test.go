@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func countTxTestRows(t *testing.T, db DBClient) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM tx_test`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestWithTx_RetriesBusyAndCommits(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	if _, err := db.Exec(`CREATE TABLE tx_test (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+
+	attempts := 0
+	err := WithTx(context.Background(), db, func(tx *SQLTx) error {
+		attempts++
+		// The insert must be rolled back with each busy attempt, or the
+		// retry would hit a primary key conflict.
+		if _, err := tx.Exec(`INSERT INTO tx_test (id) VALUES (1)`); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return errors.New("database is locked (5) (SQLITE_BUSY)")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("closure ran %d times, want 3", attempts)
+	}
+	if n := countTxTestRows(t, db); n != 1 {
+		t.Errorf("committed %d rows, want 1", n)
+	}
+}
+
+func TestWithTx_OtherErrorsRollBackWithoutRetry(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	if _, err := db.Exec(`CREATE TABLE tx_test (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+
+	wantErr := errors.New("constraint failed")
+	attempts := 0
+	err := WithTx(context.Background(), db, func(tx *SQLTx) error {
+		attempts++
+		if _, err := tx.Exec(`INSERT INTO tx_test (id) VALUES (1)`); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) || attempts != 1 {
+		t.Errorf("WithTx = %v after %d attempts, want %v after 1", err, attempts, wantErr)
+	}
+	if n := countTxTestRows(t, db); n != 0 {
+		t.Errorf("failed transaction left %d rows", n)
+	}
+}
+
+func TestWithTx_GivesUpAtDeadline(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	attempts := 0
+	start := time.Now()
+	err := WithTx(ctx, db, func(tx *SQLTx) error {
+		attempts++
+		return errors.New("SQLITE_BUSY")
+	})
+	if !isSQLiteBusy(err) {
+		t.Fatalf("WithTx = %v, want the busy error", err)
+	}
+	if attempts < 2 {
+		t.Errorf("closure ran %d times, want retries before giving up", attempts)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WithTx kept retrying for %v past a 100ms deadline", elapsed)
+	}
+}
@@ -903,7 +903,10 @@ type Cluster struct {
 	HeightP95     float32 `protobuf:"fixed32,12,opt,name=height_p95,json=heightP95,proto3" json:"height_p95,omitempty"`
 	IntensityMean float32 `protobuf:"fixed32,13,opt,name=intensity_mean,json=intensityMean,proto3" json:"intensity_mean,omitempty"`
 	// Optional: sample points for debug rendering (xyz interleaved)
-	SamplePoints  []float32 `protobuf:"fixed32,14,rep,packed,name=sample_points,json=samplePoints,proto3" json:"sample_points,omitempty"`
+	SamplePoints []float32 `protobuf:"fixed32,14,rep,packed,name=sample_points,json=samplePoints,proto3" json:"sample_points,omitempty"`
+	// Identity carried across frames for the same object; 0 when stable
+	// cluster IDs are disabled. cluster_id is only unique within a frame.
+	StableId      int64 `protobuf:"varint,15,opt,name=stable_id,json=stableId,proto3" json:"stable_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1036,6 +1039,13 @@ func (x *Cluster) GetSamplePoints() []float32 {
 	return nil
 }
 
+func (x *Cluster) GetStableId() int64 {
+	if x != nil {
+		return x.StableId
+	}
+	return 0
+}
+
 type ClusterSet struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FrameId       uint64                 `protobuf:"varint,1,opt,name=frame_id,json=frameId,proto3" json:"frame_id,omitempty"`
@@ -1155,8 +1165,20 @@ type Track struct {
 	// Heading source (for debug rendering: colour-code boxes by heading origin)
 	// 0=PCA (raw), 1=velocity-disambiguated, 2=displacement-disambiguated, 3=locked
 	HeadingSource int32 `protobuf:"varint,35,opt,name=heading_source,json=headingSource,proto3" json:"heading_source,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Multipath ghost suppression: the real track this one mirrors across a
+	// configured reflector; empty for tracks that are not ghosts.
+	GhostOf string `protobuf:"bytes,36,opt,name=ghost_of,json=ghostOf,proto3" json:"ghost_of,omitempty"`
+	// 1-sigma uncertainty of speed_mps from the velocity covariance (m/s);
+	// grows while the track coasts through misses.
+	SpeedStdDevMps float32 `protobuf:"fixed32,37,opt,name=speed_std_dev_mps,json=speedStdDevMps,proto3" json:"speed_std_dev_mps,omitempty"`
+	// Confidence-weighted speed to report for the track (m/s); zero until
+	// the track has a Kalman speed observation.
+	CompositeSpeedMps float32 `protobuf:"fixed32,38,opt,name=composite_speed_mps,json=compositeSpeedMps,proto3" json:"composite_speed_mps,omitempty"`
+	// Unsmoothed Kalman speed from the latest update (m/s); speed history,
+	// avg and max use the smoothed speed instead.
+	InstantSpeedMps float32 `protobuf:"fixed32,39,opt,name=instant_speed_mps,json=instantSpeedMps,proto3" json:"instant_speed_mps,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Track) Reset() {
@@ -1434,6 +1456,34 @@ func (x *Track) GetHeadingSource() int32 {
 	return 0
 }
 
+func (x *Track) GetGhostOf() string {
+	if x != nil {
+		return x.GhostOf
+	}
+	return ""
+}
+
+func (x *Track) GetSpeedStdDevMps() float32 {
+	if x != nil {
+		return x.SpeedStdDevMps
+	}
+	return 0
+}
+
+func (x *Track) GetCompositeSpeedMps() float32 {
+	if x != nil {
+		return x.CompositeSpeedMps
+	}
+	return 0
+}
+
+func (x *Track) GetInstantSpeedMps() float32 {
+	if x != nil {
+		return x.InstantSpeedMps
+	}
+	return 0
+}
+
 type TrackPoint struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	X             float32                `protobuf:"fixed32,1,opt,name=x,proto3" json:"x,omitempty"`
@@ -3198,7 +3248,7 @@ const file_visualiser_proto_rawDesc = "" +
 	"\x05width\x18\x05 \x01(\x02R\x05width\x12\x16\n" +
 	"\x06height\x18\x06 \x01(\x02R\x06height\x12\x1f\n" +
 	"\vheading_rad\x18\a \x01(\x02R\n" +
-	"headingRad\"\xf7\x03\n" +
+	"headingRad\"\x94\x04\n" +
 	"\aCluster\x12\x1d\n" +
 	"\n" +
 	"cluster_id\x18\x01 \x01(\x03R\tclusterId\x12\x1b\n" +
@@ -3222,13 +3272,14 @@ const file_visualiser_proto_rawDesc = "" +
 	"\n" +
 	"height_p95\x18\f \x01(\x02R\theightP95\x12%\n" +
 	"\x0eintensity_mean\x18\r \x01(\x02R\rintensityMean\x12'\n" +
-	"\rsample_points\x18\x0e \x03(\x02B\x02\x10\x01R\fsamplePoints\"\xc9\x01\n" +
+	"\rsample_points\x18\x0e \x03(\x02B\x02\x10\x01R\fsamplePoints\x12\x1b\n" +
+	"\tstable_id\x18\x0f \x01(\x03R\bstableId\"\xc9\x01\n" +
 	"\n" +
 	"ClusterSet\x12\x19\n" +
 	"\bframe_id\x18\x01 \x01(\x04R\aframeId\x12!\n" +
 	"\ftimestamp_ns\x18\x02 \x01(\x03R\vtimestampNs\x12;\n" +
 	"\bclusters\x18\x03 \x03(\v2\x1f.velocity.visualiser.v1.ClusterR\bclusters\x12@\n" +
-	"\x06method\x18\x04 \x01(\x0e2(.velocity.visualiser.v1.ClusteringMethodR\x06method\"\xf4\t\n" +
+	"\x06method\x18\x04 \x01(\x0e2(.velocity.visualiser.v1.ClusteringMethodR\x06method\"\x96\v\n" +
 	"\x05Track\x12\x19\n" +
 	"\btrack_id\x18\x01 \x01(\tR\atrackId\x12\x1b\n" +
 	"\tsensor_id\x18\x02 \x01(\tR\bsensorId\x128\n" +
@@ -3272,7 +3323,11 @@ const file_visualiser_proto_rawDesc = "" +
 	"\x0focclusion_state\x18  \x01(\x0e2&.velocity.visualiser.v1.OcclusionStateR\x0eocclusionState\x12F\n" +
 	"\fmotion_model\x18! \x01(\x0e2#.velocity.visualiser.v1.MotionModelR\vmotionModel\x12\x14\n" +
 	"\x05alpha\x18\" \x01(\x02R\x05alpha\x12%\n" +
-	"\x0eheading_source\x18# \x01(\x05R\rheadingSource\"K\n" +
+	"\x0eheading_source\x18# \x01(\x05R\rheadingSource\x12\x19\n" +
+	"\bghost_of\x18$ \x01(\tR\aghostOf\x12)\n" +
+	"\x11speed_std_dev_mps\x18% \x01(\x02R\x0espeedStdDevMps\x12.\n" +
+	"\x13composite_speed_mps\x18& \x01(\x02R\x11compositeSpeedMps\x12*\n" +
+	"\x11instant_speed_mps\x18' \x01(\x02R\x0finstantSpeedMps\"K\n" +
 	"\n" +
 	"TrackPoint\x12\f\n" +
 	"\x01x\x18\x01 \x01(\x02R\x01x\x12\f\n" +
@@ -3474,7 +3529,7 @@ const file_visualiser_proto_rawDesc = "" +
 	"\x0fSetOverlayModes\x12*.velocity.visualiser.v1.OverlayModeRequest\x1a+.velocity.visualiser.v1.OverlayModeResponse\x12l\n" +
 	"\x0fGetCapabilities\x12+.velocity.visualiser.v1.CapabilitiesRequest\x1a,.velocity.visualiser.v1.CapabilitiesResponse\x12c\n" +
 	"\x0eStartRecording\x12(.velocity.visualiser.v1.RecordingRequest\x1a'.velocity.visualiser.v1.RecordingStatus\x12b\n" +
-	"\rStopRecording\x12(.velocity.visualiser.v1.RecordingRequest\x1a'.velocity.visualiser.v1.RecordingStatusBGZEgithub.com/banshee-data/velocity.report/internal/lidar/l9endpoints/pbb\x06proto3"
+	"\rStopRecording\x12(.velocity.visualiser.v1.RecordingRequest\x1a'.velocity.visualiser.v1.RecordingStatusB;Z9github.com/banshee-data/velocity.report/pkg/visualiser/pbb\x06proto3"
 
 var (
 	file_visualiser_proto_rawDescOnce sync.Once
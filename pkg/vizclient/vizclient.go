@@ -0,0 +1,352 @@
+// Package vizclient is a Go client for the visualiser gRPC stream
+// (VisualiserService in proto/velocity_visualiser/v1/visualiser.proto).
+//
+// The synthetic, replay and live servers (cmd/tools/visualiser-server and
+// the radar's -lidar-forward-mode grpc) all speak the same service, and this
+// client treats them alike: Frames delivers every FrameBundle on a
+// channel, and Tracks reduces the stream to one TrackFrame per perception
+// frame, skipping background snapshots and reporting empty frames as
+// frames with no tracks. Replay servers pause at the end of a log rather
+// than closing the stream, so a finished replay simply stops delivering
+// until it is seeked or restarted.
+//
+// A dropped connection, a server restart or a stream the server ends is
+// retried with exponential backoff, reset by the first frame after a
+// reconnect. Errors that retrying cannot fix (an unimplemented service, a
+// rejected request, refused credentials) end the stream, and Err reports
+// them. Cancelling the context ends the stream with a nil Err.
+//
+// Frame channels are unbuffered beyond Config.Buffer: a slow consumer
+// holds up the receive loop and the server's own slow-client handling
+// skips frames for it, as it does for the macOS visualiser.
+package vizclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// ErrTooManyFailures ends a stream once Config.MaxFailures consecutive
+// connection attempts have failed.
+var ErrTooManyFailures = errors.New("visualiser stream: too many consecutive failures")
+
+// maxMsgSize matches the server's limit, so full-resolution point clouds
+// are not rejected by the client's 4 MB default.
+const maxMsgSize = 16 * 1024 * 1024
+
+// Config controls what is streamed and how the client reconnects.
+type Config struct {
+	// Request is sent to StreamFrames on every (re)connect. Tracks
+	// overrides its include flags.
+	Request *pb.StreamRequest
+	// InitialBackoff is the wait before the first reconnect; it doubles on
+	// each further failure up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxFailures is the number of consecutive failed attempts after which
+	// the stream gives up with ErrTooManyFailures. Zero retries forever.
+	MaxFailures int
+	// Buffer is the capacity of the frame and track channels.
+	Buffer int
+	// DialOptions are passed to grpc.NewClient by New. Without a
+	// credentials option the connection is plaintext, as the servers are.
+	DialOptions []grpc.DialOption
+	// Logf, when set, is called for each reconnect.
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultConfig streams points, clusters and tracks for all sensors and
+// reconnects from a quarter-second backoff up to five seconds, forever.
+func DefaultConfig() Config {
+	return Config{
+		Request: &pb.StreamRequest{
+			SensorId:        "all",
+			IncludePoints:   true,
+			IncludeClusters: true,
+			IncludeTracks:   true,
+		},
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Buffer:         8,
+	}
+}
+
+// Client is a connection to a visualiser server. It is safe for
+// concurrent use; each Frames or Tracks call opens its own stream.
+type Client struct {
+	rpc  pb.VisualiserServiceClient
+	conn *grpc.ClientConn // nil when built with NewFromConn
+	cfg  Config
+}
+
+// New connects to the server at addr, such as "localhost:50051". The
+// connection is made lazily, so New succeeds while the server is down and
+// streams retry until it is up.
+func New(addr string, cfg Config) (*Client, error) {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMsgSize)),
+	}
+	conn, err := grpc.NewClient(addr, append(opts, cfg.DialOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("visualiser client %s: %w", addr, err)
+	}
+	c := NewFromConn(conn, cfg)
+	c.conn = conn
+	return c, nil
+}
+
+// NewFromConn returns a client over an existing connection, which Close
+// leaves open.
+func NewFromConn(conn grpc.ClientConnInterface, cfg Config) *Client {
+	def := DefaultConfig()
+	if cfg.Request == nil {
+		cfg.Request = def.Request
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = def.InitialBackoff
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = max(def.MaxBackoff, cfg.InitialBackoff)
+	}
+	if cfg.Buffer < 0 {
+		cfg.Buffer = 0
+	}
+	return &Client{rpc: pb.NewVisualiserServiceClient(conn), cfg: cfg}
+}
+
+// Close closes the connection opened by New.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// RPC returns the underlying generated client, for calls this package
+// does not wrap.
+func (c *Client) RPC() pb.VisualiserServiceClient { return c.rpc }
+
+// Capabilities reports what the server supports.
+func (c *Client) Capabilities(ctx context.Context) (*pb.CapabilitiesResponse, error) {
+	return c.rpc.GetCapabilities(ctx, &pb.CapabilitiesRequest{})
+}
+
+// Pause pauses replay playback.
+func (c *Client) Pause(ctx context.Context) (*pb.PlaybackStatus, error) {
+	return c.rpc.Pause(ctx, &pb.PauseRequest{})
+}
+
+// Play resumes replay playback.
+func (c *Client) Play(ctx context.Context) (*pb.PlaybackStatus, error) {
+	return c.rpc.Play(ctx, &pb.PlayRequest{})
+}
+
+// SeekFrame seeks a seekable replay to frame index frame.
+func (c *Client) SeekFrame(ctx context.Context, frame uint64) (*pb.PlaybackStatus, error) {
+	return c.rpc.Seek(ctx, &pb.SeekRequest{Target: &pb.SeekRequest_FrameId{FrameId: frame}})
+}
+
+// SeekTime seeks a seekable replay to the frame at timestampNs.
+func (c *Client) SeekTime(ctx context.Context, timestampNs int64) (*pb.PlaybackStatus, error) {
+	return c.rpc.Seek(ctx, &pb.SeekRequest{Target: &pb.SeekRequest_TimestampNs{TimestampNs: timestampNs}})
+}
+
+// SetRate sets the replay playback rate, 1 being real time.
+func (c *Client) SetRate(ctx context.Context, rate float32) (*pb.PlaybackStatus, error) {
+	return c.rpc.SetRate(ctx, &pb.SetRateRequest{Rate: rate})
+}
+
+// streamState is the shared end-of-stream state of FrameStream and
+// TrackStream.
+type streamState struct {
+	mu         sync.Mutex
+	err        error
+	reconnects int
+}
+
+// Err returns why the stream ended once its channel is closed: nil after
+// the context was cancelled, otherwise the error that stopped it.
+func (s *streamState) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Reconnects returns how many times the stream has been reopened.
+func (s *streamState) Reconnects() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reconnects
+}
+
+// FrameStream delivers every frame the server sends.
+type FrameStream struct {
+	streamState
+	// C receives the frames and is closed when the stream ends.
+	C <-chan *pb.FrameBundle
+}
+
+// Frames streams frames with Config.Request until ctx is cancelled or a
+// permanent error occurs.
+func (c *Client) Frames(ctx context.Context) *FrameStream {
+	ch := make(chan *pb.FrameBundle, c.cfg.Buffer)
+	s := &FrameStream{C: ch}
+	go func() {
+		defer close(ch)
+		c.run(ctx, c.cfg.Request, &s.streamState, func(f *pb.FrameBundle) bool {
+			select {
+			case ch <- f:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return s
+}
+
+// TrackFrame is the tracks of one perception frame.
+type TrackFrame struct {
+	FrameID     uint64
+	TimestampNs int64
+	SensorID    string
+	// Live is false for frames from a replay.
+	Live bool
+	// ReplayEpoch changes each time the server loads a new replay.
+	ReplayEpoch uint64
+	Tracks      []*pb.Track
+}
+
+// TrackStream delivers the tracks of each perception frame.
+type TrackStream struct {
+	streamState
+	// C receives the track frames and is closed when the stream ends.
+	C <-chan TrackFrame
+}
+
+// Tracks streams tracks only: Config.Request is sent with points,
+// clusters and debug overlays turned off.
+func (c *Client) Tracks(ctx context.Context) *TrackStream {
+	req := &pb.StreamRequest{SensorId: c.cfg.Request.GetSensorId(), IncludeTracks: true}
+	ch := make(chan TrackFrame, c.cfg.Buffer)
+	s := &TrackStream{C: ch}
+	go func() {
+		defer close(ch)
+		c.run(ctx, req, &s.streamState, func(f *pb.FrameBundle) bool {
+			tf, ok := trackFrame(f)
+			if !ok {
+				return true
+			}
+			select {
+			case ch <- tf:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return s
+}
+
+// trackFrame reduces f to its tracks. Background snapshots carry no
+// tracks and are skipped.
+func trackFrame(f *pb.FrameBundle) (TrackFrame, bool) {
+	if f.GetFrameType() == pb.FrameType_FRAME_TYPE_BACKGROUND {
+		return TrackFrame{}, false
+	}
+	info := f.GetPlaybackInfo()
+	return TrackFrame{
+		FrameID:     f.GetFrameId(),
+		TimestampNs: f.GetTimestampNs(),
+		SensorID:    f.GetSensorId(),
+		Live:        info == nil || info.GetIsLive(),
+		ReplayEpoch: info.GetReplayEpoch(),
+		Tracks:      f.GetTracks().GetTracks(),
+	}, true
+}
+
+// run opens StreamFrames with req and hands each frame to emit until emit
+// returns false, ctx is cancelled or a permanent error occurs, which is
+// recorded in state.
+func (c *Client) run(ctx context.Context, req *pb.StreamRequest, state *streamState, emit func(*pb.FrameBundle) bool) {
+	backoff := c.cfg.InitialBackoff
+	failures := 0
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			state.mu.Lock()
+			state.reconnects++
+			state.mu.Unlock()
+		}
+		received, err := c.receive(ctx, req, emit)
+		if ctx.Err() != nil || err == nil {
+			return
+		}
+		if permanent(err) {
+			state.mu.Lock()
+			state.err = err
+			state.mu.Unlock()
+			return
+		}
+		if received {
+			backoff = c.cfg.InitialBackoff
+			failures = 0
+		}
+		failures++
+		if c.cfg.MaxFailures > 0 && failures >= c.cfg.MaxFailures {
+			state.mu.Lock()
+			state.err = fmt.Errorf("%w: %v", ErrTooManyFailures, err)
+			state.mu.Unlock()
+			return
+		}
+		if c.cfg.Logf != nil {
+			c.cfg.Logf("[vizclient] stream ended (%v); reconnecting in %v", err, backoff)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, c.cfg.MaxBackoff)
+	}
+}
+
+// receive runs one stream. It reports whether any frame arrived, and
+// returns nil only when emit asked to stop.
+func (c *Client) receive(ctx context.Context, req *pb.StreamRequest, emit func(*pb.FrameBundle) bool) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.StreamFrames(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	received := false
+	for {
+		frame, err := stream.Recv()
+		if err != nil {
+			return received, err
+		}
+		received = true
+		if !emit(frame) {
+			return true, nil
+		}
+	}
+}
+
+// permanent reports whether retrying err cannot help.
+func permanent(err error) bool {
+	switch status.Code(err) {
+	case codes.Unimplemented, codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated:
+		return true
+	}
+	return false
+}
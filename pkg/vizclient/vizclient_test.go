@@ -0,0 +1,207 @@
+package vizclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banshee-data/velocity.report/internal/lidar/l9endpoints"
+	"github.com/banshee-data/velocity.report/pkg/visualiser/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testNet is an in-process network whose listener can be replaced, so a
+// test can stop the server and bring up another at the same address.
+type testNet struct {
+	mu  sync.Mutex
+	lis *bufconn.Listener
+}
+
+func (n *testNet) listen() *bufconn.Listener {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lis = bufconn.Listen(1 << 20)
+	return n.lis
+}
+
+func (n *testNet) dial(ctx context.Context, _ string) (net.Conn, error) {
+	n.mu.Lock()
+	lis := n.lis
+	n.mu.Unlock()
+	return lis.DialContext(ctx)
+}
+
+// serveSynthetic starts a synthetic visualiser server on n, or a bare gRPC
+// server without the service when synthetic is false.
+func serveSynthetic(t *testing.T, n *testNet, synthetic bool) *grpc.Server {
+	t.Helper()
+	srv := grpc.NewServer()
+	if synthetic {
+		viz := l9endpoints.NewServer(nil)
+		viz.EnableSyntheticMode("test-sensor")
+		viz.SyntheticGenerator().FrameRate = 200
+		l9endpoints.RegisterService(srv, viz)
+	}
+	lis := n.listen()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func newTestClient(t *testing.T, n *testNet) *Client {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.InitialBackoff = 10 * time.Millisecond
+	cfg.MaxBackoff = 50 * time.Millisecond
+	cfg.DialOptions = []grpc.DialOption{grpc.WithContextDialer(n.dial)}
+	c, err := New("passthrough:///bufnet", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func nextFrame(t *testing.T, s *FrameStream) *pb.FrameBundle {
+	t.Helper()
+	select {
+	case f, ok := <-s.C:
+		if !ok {
+			t.Fatalf("stream ended: %v", s.Err())
+		}
+		return f
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a frame")
+	}
+	return nil
+}
+
+func TestClient_FramesReconnectAfterServerRestart(t *testing.T) {
+	n := &testNet{}
+	srv := serveSynthetic(t, n, true)
+	c := newTestClient(t, n)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := c.Frames(ctx)
+	for i := 0; i < 3; i++ {
+		if f := nextFrame(t, s); f.GetSensorId() != "test-sensor" || f.GetPointCloud() == nil {
+			t.Fatalf("frame %d = sensor %q, points %v", i, f.GetSensorId(), f.GetPointCloud() != nil)
+		}
+	}
+
+	srv.Stop()
+	serveSynthetic(t, n, true)
+	// Drain what was buffered before the restart, then expect new frames.
+	deadline := time.After(5 * time.Second)
+	for s.Reconnects() == 0 {
+		select {
+		case _, ok := <-s.C:
+			if !ok {
+				t.Fatalf("stream ended instead of reconnecting: %v", s.Err())
+			}
+		case <-deadline:
+			t.Fatal("no reconnect after the server restarted")
+		}
+	}
+	for i := 0; i < 3; i++ {
+		nextFrame(t, s)
+	}
+
+	cancel()
+	for range s.C {
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Err after cancel = %v, want nil", err)
+	}
+}
+
+func TestClient_Tracks(t *testing.T) {
+	n := &testNet{}
+	serveSynthetic(t, n, true)
+	c := newTestClient(t, n)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := c.Tracks(ctx)
+	select {
+	case tf, ok := <-s.C:
+		if !ok {
+			t.Fatalf("stream ended: %v", s.Err())
+		}
+		if len(tf.Tracks) == 0 || !tf.Live || tf.SensorID != "test-sensor" || tf.TimestampNs == 0 {
+			t.Errorf("track frame = %d tracks, live %v, sensor %q, ts %d", len(tf.Tracks), tf.Live, tf.SensorID, tf.TimestampNs)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for tracks")
+	}
+}
+
+func TestClient_PermanentErrorEndsStream(t *testing.T) {
+	n := &testNet{}
+	serveSynthetic(t, n, false)
+	c := newTestClient(t, n)
+
+	s := c.Frames(context.Background())
+	select {
+	case _, ok := <-s.C:
+		if ok {
+			t.Fatal("got a frame from a server without the service")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end")
+	}
+	if code := status.Code(s.Err()); code != codes.Unimplemented {
+		t.Errorf("Err = %v, want Unimplemented", s.Err())
+	}
+}
+
+func TestClient_MaxFailures(t *testing.T) {
+	n := &testNet{}
+	srv := serveSynthetic(t, n, true)
+	srv.Stop() // nothing listening from here on
+
+	cfg := DefaultConfig()
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = time.Millisecond
+	cfg.MaxFailures = 3
+	cfg.DialOptions = []grpc.DialOption{grpc.WithContextDialer(n.dial)}
+	c, err := New("passthrough:///bufnet", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	s := c.Frames(context.Background())
+	for range s.C {
+	}
+	if s.Reconnects() != 2 {
+		t.Errorf("reconnects = %d, want 2 after 3 failures", s.Reconnects())
+	}
+	if err := s.Err(); !errors.Is(err, ErrTooManyFailures) {
+		t.Errorf("Err = %v, want ErrTooManyFailures", err)
+	}
+}
+
+func TestTrackFrame(t *testing.T) {
+	if _, ok := trackFrame(&pb.FrameBundle{FrameType: pb.FrameType_FRAME_TYPE_BACKGROUND}); ok {
+		t.Error("background snapshot produced a track frame")
+	}
+	tf, ok := trackFrame(&pb.FrameBundle{FrameId: 7, FrameType: pb.FrameType_FRAME_TYPE_EMPTY})
+	if !ok || tf.FrameID != 7 || len(tf.Tracks) != 0 || !tf.Live {
+		t.Errorf("empty frame = %+v, %v; want a live frame with no tracks", tf, ok)
+	}
+	tf, _ = trackFrame(&pb.FrameBundle{
+		PlaybackInfo: &pb.PlaybackInfo{ReplayEpoch: 3},
+		Tracks:       &pb.TrackSet{Tracks: []*pb.Track{{TrackId: "a"}, {TrackId: "b"}}},
+	})
+	if tf.Live || tf.ReplayEpoch != 3 || len(tf.Tracks) != 2 {
+		t.Errorf("replay frame = %+v", tf)
+	}
+}
@@ -167,6 +167,7 @@ _RADAR_METHOD_OVERRIDES = {
     "/api/reports/": "GET/DELETE",
     "/api/transit_worker": "GET/POST",
     "/api/db_stats": "GET",
+    "/api/db_maintenance": "POST",
     "/api/charts/timeseries": "GET",
     "/api/charts/histogram": "GET",
     "/api/charts/comparison": "GET",
@@ -264,6 +265,7 @@ _SKIP_GENERIC_LIDAR_ROUTES = {
     "ws.trackAPI.handleTrackByID",
     "ws.handleRunTrackAPI",
     "ws.handleSceneByID",
+    "ws.handleTuningPresetByName",
 }
 
 _LIDAR_ROUTE_PATH_OVERRIDES = {
@@ -292,6 +294,8 @@ _LIDAR_METHOD_OVERRIDES = {
     "/api/lidar/tracks/history": "GET",
     "/api/lidar/tracks/active": "GET",
     "/api/lidar/tracks/metrics": "GET",
+    "/api/lidar/tracks/innovations": "GET/POST",
+    "/api/lidar/presets": "GET/POST",
     "/api/lidar/tracks/summary": "GET",
     "/api/lidar/clusters": "GET",
     "/api/lidar/observations": "GET",
@@ -316,6 +320,7 @@ def extract_lidar_http(root: Path) -> list[Endpoint]:
         root / "internal" / "lidar" / "server" / "track_api.go",
         root / "internal" / "lidar" / "server" / "run_track_api.go",
         root / "internal" / "lidar" / "server" / "scene_api.go",
+        root / "internal" / "lidar" / "server" / "tuning_presets.go",
         root / "internal" / "api" / "lidar_labels.go",
     ]
     results: list[Endpoint] = []
@@ -1,9 +1,10 @@
 /* eslint-disable @typescript-eslint/no-require-imports -- CommonJS module requires require() */
-const { escapeHTML, parseDuration, formatDuration } =
+const { escapeHTML, parseDuration, formatDuration, readBasePath } =
 	require('@monitor/assets/dashboard_common.js') as {
 		escapeHTML: (str: unknown) => string;
 		parseDuration: (s: string | null | undefined) => number;
 		formatDuration: (secs: number) => string;
+		readBasePath: () => string;
 	};
 /* eslint-enable @typescript-eslint/no-require-imports */
 
@@ -138,4 +139,19 @@ describe('formatDuration', () => {
 	});
 });
 
+describe('readBasePath', () => {
+	afterEach(() => {
+		document.head.innerHTML = '';
+	});
+
+	it('returns empty string without a base-path meta tag', () => {
+		expect(readBasePath()).toBe('');
+	});
+
+	it('returns the base-path meta content', () => {
+		document.head.innerHTML = '<meta name="base-path" content="/sensor1" />';
+		expect(readBasePath()).toBe('/sensor1');
+	});
+});
+
 export {};
@@ -33,6 +33,10 @@ export interface Track {
 	object_confidence?: number;
 	/** Number of observations for this track */
 	observation_count: number;
+	/** Frames coasted between the first and last observation */
+	coasted_frames?: number;
+	/** Observed share of the frames between the first and last observation (0-1) */
+	detection_reliability?: number;
 	/**
 	 * Age of track in seconds (computed as last_seen - first_seen on the backend).
 	 * This represents the duration the track has existed.
@@ -49,6 +53,8 @@ export interface Track {
 	 * 0=PCA (raw), 1=velocity-disambiguated, 2=displacement-disambiguated, 3=locked
 	 */
 	heading_source?: number;
+	/** Real track this one mirrors; set only for multipath ghosts */
+	ghost_of?: string;
 	/** Bounding box dimensions (meters, per-frame cluster dimensions) */
 	bounding_box: {
 		/** Length along heading direction (meters) */